@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/fixture"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	flagFixturesPolicy        = "policy"
+	flagFixturesCredentials   = "credentials"
+	flagFixturesJWTPrivateKey = "jwt.private-key"
+	flagFixturesJWTKeyID      = "jwt.key-id"
+	flagFixturesJWTClaims     = "jwt.claims"
+	flagFixturesJWTTTL        = "jwt.ttl"
+)
+
+type acpFixturesCmd struct {
+	flags []cli.Flag
+}
+
+func newACPFixturesCmd() acpFixturesCmd {
+	return acpFixturesCmd{
+		flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     flagFixturesPolicy,
+				Usage:    "Path to the AccessControlPolicy manifest, in YAML or JSON",
+				EnvVars:  []string{"FIXTURES_" + strcase.ToSNAKE(flagFixturesPolicy)},
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:    flagFixturesCredentials,
+				Usage:   "API keys or user:password basic auth credentials of the policy to generate valid requests for",
+				EnvVars: []string{"FIXTURES_" + strcase.ToSNAKE(flagFixturesCredentials)},
+			},
+			&cli.StringFlag{
+				Name:    flagFixturesJWTPrivateKey,
+				Usage:   "Path to the PEM-encoded private key signing the JWTs, when the policy verifies them with a public key or a JWK set",
+				EnvVars: []string{"FIXTURES_" + strcase.ToSNAKE(flagFixturesJWTPrivateKey)},
+			},
+			&cli.StringFlag{
+				Name:    flagFixturesJWTKeyID,
+				Usage:   "ID of the private key in the JWK set of the policy",
+				EnvVars: []string{"FIXTURES_" + strcase.ToSNAKE(flagFixturesJWTKeyID)},
+			},
+			&cli.StringFlag{
+				Name:    flagFixturesJWTClaims,
+				Usage:   "JSON object of claims added to the JWTs, such as their issuer",
+				EnvVars: []string{"FIXTURES_" + strcase.ToSNAKE(flagFixturesJWTClaims)},
+			},
+			&cli.DurationFlag{
+				Name:    flagFixturesJWTTTL,
+				Usage:   "Lifetime of the valid JWTs",
+				EnvVars: []string{"FIXTURES_" + strcase.ToSNAKE(flagFixturesJWTTTL)},
+				Value:   time.Hour,
+			},
+		},
+	}
+}
+
+func (c acpFixturesCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "acp-fixtures",
+		Usage:  "Generates requests with valid and invalid credentials to verify how an AccessControlPolicy is enforced",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c acpFixturesCmd) run(cliCtx *cli.Context) error {
+	raw, err := os.ReadFile(cliCtx.String(flagFixturesPolicy))
+	if err != nil {
+		return fmt.Errorf("read policy: %w", err)
+	}
+
+	var policy hubv1alpha1.AccessControlPolicy
+	if err = yaml.UnmarshalStrict(raw, &policy); err != nil {
+		return fmt.Errorf("unmarshal policy: %w", err)
+	}
+	if policy.Kind != "AccessControlPolicy" {
+		return errors.New("manifest is not an AccessControlPolicy")
+	}
+
+	opts := fixture.Options{
+		Credentials: cliCtx.StringSlice(flagFixturesCredentials),
+		KeyID:       cliCtx.String(flagFixturesJWTKeyID),
+		TTL:         cliCtx.Duration(flagFixturesJWTTTL),
+	}
+
+	if path := cliCtx.String(flagFixturesJWTPrivateKey); path != "" {
+		opts.PrivateKey, err = os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read private key: %w", err)
+		}
+	}
+
+	if claims := cliCtx.String(flagFixturesJWTClaims); claims != "" {
+		if err = json.Unmarshal([]byte(claims), &opts.Claims); err != nil {
+			return fmt.Errorf("unmarshal claims: %w", err)
+		}
+	}
+
+	fixtures, err := fixture.Generate(&policy, opts)
+	if err != nil {
+		return fmt.Errorf("generate fixtures: %w", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(fixtures)
+}
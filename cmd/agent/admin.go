@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"errors"
+	stdlog "log"
+	"net/http"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
+	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/shutdown"
+	"github.com/urfave/cli/v2"
+)
+
+const flagAdminListenAddr = "admin-listen-addr"
+
+// adminListenAddr is the default address of the admin listener. Its endpoints aren't authenticated, so it's only
+// reachable from the pod by default.
+const adminListenAddr = "127.0.0.1:9091"
+
+func adminFlags(envPrefix string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagAdminListenAddr,
+			Usage:   "Address on which the command serves its unauthenticated admin endpoints, /log-level and /diagnostics, keep it bound to the loopback interface unless its access is restricted",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagAdminListenAddr)},
+			Value:   adminListenAddr,
+		},
+	}
+}
+
+// newAdminMux returns the mux of the admin endpoints of a command, serving the diagnostics of the given process.
+func newAdminMux(process *diagnostics.Process) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/log-level", logger.LevelHandler())
+	mux.Handle(diagnosticsPath, process.Handler())
+
+	return mux
+}
+
+// startAdminServer serves the given admin endpoints on the admin listener until the shutdown, which stops it last so
+// that the log levels can still be changed while draining.
+func startAdminServer(cliCtx *cli.Context, coordinator *shutdown.Coordinator, mux *http.ServeMux) {
+	addr := cliCtx.String(flagAdminListenAddr)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
+		ReadHeaderTimeout: 2 * time.Second,
+	}
+
+	go func() {
+		log.Info().Str("addr", addr).Msg("Starting admin server")
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Err(err).Msg("Unable to listen and serve admin requests")
+		}
+	}()
+
+	coordinator.Register(shutdown.PhaseFlush, "admin server", shutdown.HTTPServer(server))
+}
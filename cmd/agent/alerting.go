@@ -19,14 +19,23 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/ettle/strcase"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/alerting"
+	"github.com/traefik/hub-agent-kubernetes/pkg/clock"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
 	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tokenfile"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"github.com/urfave/cli/v2"
 )
 
 const (
@@ -39,29 +48,130 @@ const (
 	alertSchedulerInterval = time.Minute
 )
 
-func runAlerting(ctx context.Context, token, platformURL string, store *metrics.Store, fetcher *state.Fetcher) error {
+const (
+	flagAlertingWebhookURLs                = "alerting.webhook-urls"
+	flagAlertingWebhookHeaders             = "alerting.webhook-headers"
+	flagAlertingSlackWebhookURL            = "alerting.slack-webhook-url"
+	flagAlertingPagerDutyRoutingKey        = "alerting.pagerduty-routing-key"
+	flagAlertingNotificationRepeatInterval = "alerting.notification-repeat-interval"
+)
+
+func alertingFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    flagAlertingWebhookURLs,
+			Usage:   "URLs of the webhooks alert notifications are posted to as JSON documents",
+			EnvVars: []string{strcase.ToSNAKE(flagAlertingWebhookURLs)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagAlertingWebhookHeaders,
+			Usage:   "Headers added to the requests sent to the alert notification webhooks, formatted as \"Name: value\"",
+			EnvVars: []string{strcase.ToSNAKE(flagAlertingWebhookHeaders)},
+		},
+		&cli.StringFlag{
+			Name:    flagAlertingSlackWebhookURL,
+			Usage:   "URL of the Slack incoming webhook alert notifications are posted to",
+			EnvVars: []string{strcase.ToSNAKE(flagAlertingSlackWebhookURL)},
+		},
+		&cli.StringFlag{
+			Name:    flagAlertingPagerDutyRoutingKey,
+			Usage:   "Routing key of the PagerDuty integration alert notifications are sent to",
+			EnvVars: []string{strcase.ToSNAKE(flagAlertingPagerDutyRoutingKey)},
+		},
+		&cli.DurationFlag{
+			Name:    flagAlertingNotificationRepeatInterval,
+			Usage:   "Interval at which notifications of alerts which keep firing are sent again",
+			EnvVars: []string{strcase.ToSNAKE(flagAlertingNotificationRepeatInterval)},
+			Value:   4 * time.Hour,
+		},
+	}
+}
+
+// newAlertDispatcher creates the dispatcher of local alert notifications, or returns nil if no notifier is configured.
+func newAlertDispatcher(cliCtx *cli.Context) (*alerting.Dispatcher, error) {
+	headers := make(map[string]string)
+	for _, header := range cliCtx.StringSlice(flagAlertingWebhookHeaders) {
+		name, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid webhook header %q: must be formatted as \"Name: value\"", header)
+		}
+
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	httpClient := newAlertingHTTPClient("alerting_notifier")
+
+	var notifiers []alerting.Notifier
+	for _, url := range cliCtx.StringSlice(flagAlertingWebhookURLs) {
+		notifiers = append(notifiers, alerting.NewWebhookNotifier(httpClient, url, headers))
+	}
+
+	if url := cliCtx.String(flagAlertingSlackWebhookURL); url != "" {
+		notifiers = append(notifiers, alerting.NewSlackNotifier(httpClient, url))
+	}
+
+	if routingKey := cliCtx.String(flagAlertingPagerDutyRoutingKey); routingKey != "" {
+		notifiers = append(notifiers, alerting.NewPagerDutyNotifier(httpClient, alerting.PagerDutyEventsURL, routingKey))
+	}
+
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+
+	return alerting.NewDispatcher(cliCtx.Duration(flagAlertingNotificationRepeatInterval), notifiers...), nil
+}
+
+func newAlertingHTTPClient(component string) *http.Client {
 	retryableClient := retryablehttp.NewClient()
 	retryableClient.RetryWaitMin = time.Second
 	retryableClient.RetryWaitMax = 10 * time.Second
 	retryableClient.RetryMax = 4
-	retryableClient.Logger = logger.NewRetryableHTTPWrapper(log.Logger.With().Str("component", "alerting_client").Logger())
+	retryableClient.Logger = logger.NewRetryableHTTPWrapper(log.Logger.With().Str("component", component).Logger())
 
-	httpClient := retryableClient.StandardClient()
+	return retryableClient.StandardClient()
+}
+
+func runAlerting(ctx context.Context, token string, tokens *tokenfile.Watcher, platformURL string, store *metrics.Store, fetcher *state.Fetcher, skew *clock.Skew, dispatcher *alerting.Dispatcher, hubClientSet hubclientset.Interface) error {
+	httpClient := newAlertingHTTPClient("alerting_client")
 
 	client, err := alerting.NewClient(httpClient, platformURL, token)
 	if err != nil {
 		return err
 	}
+	tokens.AddListener(client.SetToken)
+
+	threshProc := alerting.NewThresholdProcessor(metrics.NewDataPointView(store), fetcher, fetcher)
+	threshProc.SetClock(skew.Now)
+
+	anomalyProc := alerting.NewAnomalyProcessor(metrics.NewDataPointView(store), fetcher)
+	anomalyProc.SetClock(skew.Now)
 
-	threshProc := alerting.NewThresholdProcessor(metrics.NewDataPointView(store), fetcher)
+	absenceProc := alerting.NewAbsenceProcessor(metrics.NewDataPointView(store), fetcher)
+	absenceProc.SetClock(skew.Now)
 
 	mgr := alerting.NewManager(client,
 		map[string]alerting.Processor{
 			alerting.ThresholdType: threshProc,
+			alerting.AnomalyType:   anomalyProc,
+			alerting.AbsenceType:   absenceProc,
 		},
 		alertRefreshInterval,
 		alertSchedulerInterval,
 	)
 
+	// The cache isn't waited for: silences apply as soon as they are synced, and alerting keeps working on clusters
+	// where the AlertSilence CRD isn't installed yet.
+	hubInformer := hubinformers.NewSharedInformerFactory(hubClientSet, 5*time.Minute)
+	silencer := alerting.NewSilencer(hubInformer.Hub().V1alpha1().AlertSilences().Lister())
+	silencer.SetClock(skew.Now)
+	mgr.SetSilencer(silencer)
+
+	hubInformer.Start(ctx.Done())
+
+	if dispatcher != nil {
+		dispatcher.SetClock(skew.Now)
+		mgr.SetDispatcher(dispatcher)
+	}
+
 	return mgr.Run(ctx)
 }
@@ -18,32 +18,65 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package main
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	stdlog "log"
+	"net"
 	"net/http"
 	"time"
 
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oidc"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/capture"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
+	"github.com/traefik/hub-agent-kubernetes/pkg/health"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
 	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/shutdown"
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc"
 	kinformers "k8s.io/client-go/informers"
 	kclientset "k8s.io/client-go/kubernetes"
 )
 
+const (
+	flagMetricsListenAddr    = "metrics-listen-addr"
+	flagExtAuthzListenAddr   = "ext-authz-listen-addr"
+	flagCaptureListenAddr    = "capture.listen-addr"
+	flagCaptureBufferSize    = "capture.buffer-size"
+	flagRateLimitMaxFailures = "rate-limit.max-failures"
+	flagRateLimitWindow      = "rate-limit.window"
+	flagRateLimitBanDuration = "rate-limit.ban-duration"
+	flagACPEncryptionKeyFile = "acp.encryption-key-file"
+	flagOIDCPagesDir         = "oidc.pages-dir"
+
+	flagACPTransportMaxIdleConns        = "acp.transport.max-idle-conns"
+	flagACPTransportMaxIdleConnsPerHost = "acp.transport.max-idle-conns-per-host"
+	flagACPTransportIdleConnTimeout     = "acp.transport.idle-conn-timeout"
+	flagACPTransportTLSSessionCacheSize = "acp.transport.tls-session-cache-size"
+)
+
 type authServerCmd struct {
 	flags []cli.Flag
 }
 
 func newAuthServerCmd() authServerCmd {
+	transportCfg := httpclient.DefaultTransportConfig()
+
 	flgs := []cli.Flag{
 		&cli.StringFlag{
 			Name:    flagListenAddr,
@@ -51,9 +84,89 @@ func newAuthServerCmd() authServerCmd {
 			EnvVars: []string{"AUTH_SERVER_LISTEN_ADDR"},
 			Value:   "0.0.0.0:80",
 		},
+		&cli.StringFlag{
+			Name:    flagMetricsListenAddr,
+			Usage:   "Address on which the auth server exposes its Prometheus metrics and health checks",
+			EnvVars: []string{"AUTH_SERVER_METRICS_LISTEN_ADDR"},
+			Value:   "0.0.0.0:9090",
+		},
+		&cli.StringFlag{
+			Name:    flagExtAuthzListenAddr,
+			Usage:   "Address on which the auth server listens for Envoy external authorization gRPC requests",
+			EnvVars: []string{"AUTH_SERVER_EXT_AUTHZ_LISTEN_ADDR"},
+			Value:   "0.0.0.0:9000",
+		},
+		&cli.StringFlag{
+			Name:    flagCaptureListenAddr,
+			Usage:   "Address on which the auth server proxies the traffic of APIs having capture enabled",
+			EnvVars: []string{"AUTH_SERVER_CAPTURE_LISTEN_ADDR"},
+			Value:   "0.0.0.0:8080",
+		},
+		&cli.IntFlag{
+			Name:    flagCaptureBufferSize,
+			Usage:   "Number of captured exchanges kept per API, retrievable on the /capture endpoint of the metrics listener",
+			EnvVars: []string{"AUTH_SERVER_CAPTURE_BUFFER_SIZE"},
+			Value:   100,
+		},
+		&cli.IntFlag{
+			Name:    flagRateLimitMaxFailures,
+			Usage:   "Number of failed authentication attempts after which a client is banned from a Basic Auth or API Key ACP (0 to disable)",
+			EnvVars: []string{"AUTH_SERVER_RATE_LIMIT_MAX_FAILURES"},
+			Value:   10,
+		},
+		&cli.DurationFlag{
+			Name:    flagRateLimitWindow,
+			Usage:   "Sliding window in which failed authentication attempts are counted",
+			EnvVars: []string{"AUTH_SERVER_RATE_LIMIT_WINDOW"},
+			Value:   time.Minute,
+		},
+		&cli.DurationFlag{
+			Name:    flagRateLimitBanDuration,
+			Usage:   "Duration during which a banned client is rejected",
+			EnvVars: []string{"AUTH_SERVER_RATE_LIMIT_BAN_DURATION"},
+			Value:   5 * time.Minute,
+		},
+		&cli.StringFlag{
+			Name:    flagACPEncryptionKeyFile,
+			Usage:   "File containing the base64 encoded AES-256 key used to decrypt encrypted ACP values",
+			EnvVars: []string{"AUTH_SERVER_ACP_ENCRYPTION_KEY_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    flagOIDCPagesDir,
+			Usage:   "Directory containing custom OIDC login, error and logout page templates, laid out as <locale>/<page>.html",
+			EnvVars: []string{"AUTH_SERVER_OIDC_PAGES_DIR"},
+		},
+		&cli.IntFlag{
+			Name:    flagACPTransportMaxIdleConns,
+			Usage:   "Maximum number of idle connections kept for calls made while evaluating ACPs (OIDC providers, introspection endpoints, JWKS)",
+			EnvVars: []string{"AUTH_SERVER_ACP_TRANSPORT_MAX_IDLE_CONNS"},
+			Value:   transportCfg.MaxIdleConns,
+		},
+		&cli.IntFlag{
+			Name:    flagACPTransportMaxIdleConnsPerHost,
+			Usage:   "Maximum number of idle connections kept per host for calls made while evaluating ACPs",
+			EnvVars: []string{"AUTH_SERVER_ACP_TRANSPORT_MAX_IDLE_CONNS_PER_HOST"},
+			Value:   transportCfg.MaxIdleConnsPerHost,
+		},
+		&cli.DurationFlag{
+			Name:    flagACPTransportIdleConnTimeout,
+			Usage:   "Duration after which an idle connection used for calls made while evaluating ACPs is closed",
+			EnvVars: []string{"AUTH_SERVER_ACP_TRANSPORT_IDLE_CONN_TIMEOUT"},
+			Value:   transportCfg.IdleConnTimeout,
+		},
+		&cli.IntFlag{
+			Name:    flagACPTransportTLSSessionCacheSize,
+			Usage:   "Number of TLS sessions cached to resume connections used for calls made while evaluating ACPs (0 to disable)",
+			EnvVars: []string{"AUTH_SERVER_ACP_TRANSPORT_TLS_SESSION_CACHE_SIZE"},
+			Value:   transportCfg.TLSSessionCacheSize,
+		},
 	}
 
 	flgs = append(flgs, globalFlags()...)
+	flgs = append(flgs, tracingFlags("AUTH_SERVER_")...)
+	flgs = append(flgs, secretBackendFlags("AUTH_SERVER_")...)
+	flgs = append(flgs, shutdownFlags("AUTH_SERVER_", shutdown.DefaultGracePeriod)...)
+	flgs = append(flgs, adminFlags("AUTH_SERVER_")...)
 
 	return authServerCmd{
 		flags: flgs,
@@ -69,11 +182,44 @@ func (c authServerCmd) build() *cli.Command {
 	}
 }
 
+// newRateLimitConfig creates the configuration of the rate limiter of failed authentication attempts, from the rate
+// limit flags.
+func newRateLimitConfig(cliCtx *cli.Context) (auth.RateLimitConfig, error) {
+	cfg := auth.RateLimitConfig{
+		MaxFailures: cliCtx.Int(flagRateLimitMaxFailures),
+		Window:      cliCtx.Duration(flagRateLimitWindow),
+		BanDuration: cliCtx.Duration(flagRateLimitBanDuration),
+	}
+	if cfg.MaxFailures <= 0 {
+		return cfg, nil
+	}
+
+	if cfg.Window <= 0 {
+		return auth.RateLimitConfig{}, fmt.Errorf("flag %q must be positive when %q is set", flagRateLimitWindow, flagRateLimitMaxFailures)
+	}
+	if cfg.BanDuration <= 0 {
+		return auth.RateLimitConfig{}, fmt.Errorf("flag %q must be positive when %q is set", flagRateLimitBanDuration, flagRateLimitMaxFailures)
+	}
+
+	return cfg, nil
+}
+
 func (c authServerCmd) run(cliCtx *cli.Context) error {
+	cfgFile, err := loadConfigFile(cliCtx)
+	if err != nil {
+		return err
+	}
+	go cfgFile.Run(cliCtx.Context)
+
 	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
 
 	version.Log()
 
+	coordinator, err := newShutdownCoordinator(cliCtx)
+	if err != nil {
+		return err
+	}
+
 	config, err := kube.InClusterConfigWithRetrier(2)
 	if err != nil {
 		return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
@@ -89,19 +235,88 @@ func (c authServerCmd) run(cliCtx *cli.Context) error {
 		return fmt.Errorf("create Kube client set: %w", err)
 	}
 
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	authMetrics, err := auth.NewMetrics(registry)
+	if err != nil {
+		return fmt.Errorf("create auth server metrics: %w", err)
+	}
+
+	var decrypter *acp.Decrypter
+	if keyFile := cliCtx.String(flagACPEncryptionKeyFile); keyFile != "" {
+		decrypter, err = acp.NewDecrypterFromFile(keyFile)
+		if err != nil {
+			return fmt.Errorf("create ACP decrypter: %w", err)
+		}
+	}
+
+	rateLimitCfg, err := newRateLimitConfig(cliCtx)
+	if err != nil {
+		return err
+	}
+	limiter := auth.NewRateLimiter(rateLimitCfg, authMetrics)
+
+	quotas := apikey.NewQuotas()
+
+	pages, err := oidc.NewPages(cliCtx.String(flagOIDCPagesDir))
+	if err != nil {
+		return fmt.Errorf("load OIDC pages: %w", err)
+	}
+
+	transport := httpclient.NewTransport(httpclient.TransportConfig{
+		MaxIdleConns:        cliCtx.Int(flagACPTransportMaxIdleConns),
+		MaxIdleConnsPerHost: cliCtx.Int(flagACPTransportMaxIdleConnsPerHost),
+		IdleConnTimeout:     cliCtx.Duration(flagACPTransportIdleConnTimeout),
+		TLSSessionCacheSize: cliCtx.Int(flagACPTransportTLSSessionCacheSize),
+	})
+
 	switcher := auth.NewHandlerSwitcher()
 	kubeInformer := kinformers.NewSharedInformerFactory(kubeClientSet, 5*time.Minute)
 	hubInformer := hubinformers.NewSharedInformerFactory(hubClientSet, 5*time.Minute)
+	secretProvider, err := newSecretProvider(cliCtx)
+	if err != nil {
+		return err
+	}
+	secretResolver := newSecretResolver(secretProvider, kubeInformer.Core().V1().Secrets().Lister())
+
+	// Keys issued to portal users are stored by the dev portal in the namespace of the agent.
+	issuedKeys, err := apikey.NewIssuedKeys(kubeInformer.Core().V1().Secrets().Informer(), currentNamespace())
+	if err != nil {
+		return fmt.Errorf("create issued API keys: %w", err)
+	}
+
 	acpWatcher := auth.NewWatcher(
 		switcher,
 		hubInformer.Hub().V1alpha1().AccessControlPolicies().Lister(),
-		acp.NewKubeSecretValueGetter(kubeInformer.Core().V1().Secrets().Lister()),
+		secretResolver,
+		decrypter,
+		authMetrics,
+		limiter,
+		quotas,
+		issuedKeys,
+		transport,
+		pages,
 	)
 
 	if _, err = hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer().AddEventHandler(acpWatcher); err != nil {
 		return fmt.Errorf("add ACP watcher: %w", err)
 	}
 
+	// The capture proxy is only available with API management, as it proxies the traffic of APIs.
+	captureEnabled, err := hasAPIManagementCRDs(kubeClientSet.Discovery())
+	if err != nil {
+		return fmt.Errorf("check API management CRDs: %w", err)
+	}
+
+	apiInformer := hubInformer.Hub().V1alpha1().APIs()
+	serviceInformer := kubeInformer.Core().V1().Services()
+	if captureEnabled {
+		// Register the informers before starting the factories so they get started too.
+		apiInformer.Informer()
+		serviceInformer.Informer()
+	}
+
 	hubInformer.Start(cliCtx.Context.Done())
 
 	for t, ok := range hubInformer.WaitForCacheSync(cliCtx.Context.Done()) {
@@ -110,8 +325,8 @@ func (c authServerCmd) run(cliCtx *cli.Context) error {
 		}
 	}
 
-	if _, err = kubeInformer.Core().V1().Secrets().Informer().AddEventHandler(acpWatcher); err != nil {
-		return fmt.Errorf("add secret watcher: %w", err)
+	if _, err = kubeInformer.Core().V1().Secrets().Informer().AddEventHandler(secretResolver); err != nil {
+		return fmt.Errorf("add secret resolver: %w", err)
 	}
 
 	kubeInformer.Start(cliCtx.Context.Done())
@@ -122,20 +337,35 @@ func (c authServerCmd) run(cliCtx *cli.Context) error {
 		}
 	}
 
+	tracer, err := newTracer(cliCtx, "traefik-hub-agent-auth-server")
+	if err != nil {
+		return err
+	}
+	if tracer != nil {
+		go tracer.Run(cliCtx.Context)
+	}
+
 	go acpWatcher.Run(cliCtx.Context)
+	go limiter.Run(cliCtx.Context)
 
 	listenAddr := cliCtx.String(flagListenAddr)
 
 	mux := http.NewServeMux()
 
-	mux.Handle("/_live", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
-		rw.WriteHeader(http.StatusOK)
-	}))
-	mux.Handle("/_ready", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
-		rw.WriteHeader(http.StatusOK)
-	}))
+	// The auth server doesn't reach the platform: it's ready once its caches are synced.
+	checker := newHealthChecker(coordinator)
+	checker.AddCheck("informers", health.InformersSynced(hubInformer, kubeInformer))
+
+	process := diagnostics.NewProcess("auth-server", flagValues(cliCtx))
+	process.AddInformers(kubeInformer, kubeInformerFor(kubeInformer))
+	process.AddInformers(hubInformer, hubInformerFor(hubInformer))
 
-	mux.Handle("/", switcher)
+	// ACP names are served as paths, health endpoints are prefixed with an underscore so they can't collide with them.
+	mux.Handle("/_live", checker.LiveHandler())
+	mux.Handle("/_ready", checker.ReadyHandler())
+	mux.Handle(api.PathAllowedMethods, api.AllowedMethodsHandler{})
+
+	mux.Handle("/", tracer.Handler("auth", switcher))
 
 	server := &http.Server{
 		Addr:              listenAddr,
@@ -144,6 +374,49 @@ func (c authServerCmd) run(cliCtx *cli.Context) error {
 		ReadHeaderTimeout: 2 * time.Second,
 	}
 
+	metricsListenAddr := cliCtx.String(flagMetricsListenAddr)
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	metricsMux.Handle("/quotas", quotas)
+	metricsMux.Handle("/livez", checker.LiveHandler())
+	metricsMux.Handle("/readyz", checker.ReadyHandler())
+
+	startAdminServer(cliCtx, coordinator, newAdminMux(process))
+
+	captureListenAddr := cliCtx.String(flagCaptureListenAddr)
+
+	var captureServer *http.Server
+	if captureEnabled {
+		recorder := capture.NewRecorder(cliCtx.Int(flagCaptureBufferSize))
+		metricsMux.Handle("/capture", capture.NewHandler(recorder))
+		mux.Handle(api.PathRequestValidation, api.NewRequestValidationHandler(apiInformer.Lister(), openapi.NewFetcher(nil, secretResolver)))
+
+		captureServer = &http.Server{
+			Addr:              captureListenAddr,
+			Handler:           capture.NewProxy(apiInformer.Lister(), serviceInformer.Lister(), recorder),
+			ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
+			ReadHeaderTimeout: 2 * time.Second,
+		}
+	}
+
+	metricsServer := &http.Server{
+		Addr:              metricsListenAddr,
+		Handler:           metricsMux,
+		ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
+		ReadHeaderTimeout: 2 * time.Second,
+	}
+
+	extAuthzListenAddr := cliCtx.String(flagExtAuthzListenAddr)
+
+	extAuthzListener, err := net.Listen("tcp", extAuthzListenAddr)
+	if err != nil {
+		return fmt.Errorf("listen for ext_authz requests: %w", err)
+	}
+
+	extAuthzServer := grpc.NewServer()
+	authv3.RegisterAuthorizationServer(extAuthzServer, auth.NewExtAuthzServer(switcher))
+
 	srvDone := make(chan struct{})
 
 	go func() {
@@ -154,19 +427,60 @@ func (c authServerCmd) run(cliCtx *cli.Context) error {
 		close(srvDone)
 	}()
 
-	select {
-	case <-cliCtx.Context.Done():
-		gracefulCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
+	metricsSrvDone := make(chan struct{})
+
+	go func() {
+		log.Info().Str("addr", metricsListenAddr).Msg("Starting auth server metrics")
+		if errMetrics := metricsServer.ListenAndServe(); !errors.Is(errMetrics, http.ErrServerClosed) {
+			log.Err(errMetrics).Msg("Unable to listen and serve metrics requests")
+		}
+		close(metricsSrvDone)
+	}()
 
-		if err = server.Shutdown(gracefulCtx); err != nil {
-			log.Error().Err(err).Msg("Failed to shutdown auth server gracefully")
-			if err = server.Close(); err != nil {
-				return fmt.Errorf("close auth server: %w", err)
+	// Receiving from a nil channel blocks forever, which is what we want when the capture proxy is disabled.
+	var captureSrvDone chan struct{}
+
+	if captureServer != nil {
+		captureSrvDone = make(chan struct{})
+
+		go func() {
+			log.Info().Str("addr", captureListenAddr).Msg("Starting auth server capture proxy")
+			if errCapture := captureServer.ListenAndServe(); !errors.Is(errCapture, http.ErrServerClosed) {
+				log.Err(errCapture).Msg("Unable to listen and serve capture requests")
 			}
+			close(captureSrvDone)
+		}()
+	}
+
+	extAuthzSrvDone := make(chan struct{})
+
+	go func() {
+		log.Info().Str("addr", extAuthzListenAddr).Msg("Starting auth server ext_authz")
+		if errExtAuthz := extAuthzServer.Serve(extAuthzListener); errExtAuthz != nil {
+			log.Err(errExtAuthz).Msg("Unable to serve ext_authz requests")
 		}
+		close(extAuthzSrvDone)
+	}()
+
+	// The in-flight auth requests complete during the shutdown.
+	coordinator.Register(shutdown.PhaseDrain, "auth server", shutdown.HTTPServer(server))
+	coordinator.Register(shutdown.PhaseDrain, "ext_authz server", shutdown.GRPCServer(extAuthzServer))
+	coordinator.Register(shutdown.PhaseDrain, "metrics server", shutdown.HTTPServer(metricsServer))
+	if captureServer != nil {
+		coordinator.Register(shutdown.PhaseDrain, "capture proxy", shutdown.HTTPServer(captureServer))
+	}
+
+	select {
+	case <-cliCtx.Context.Done():
+		runShutdown(coordinator)
 	case <-srvDone:
 		return errors.New("auth server stopped")
+	case <-metricsSrvDone:
+		return errors.New("auth server metrics stopped")
+	case <-captureSrvDone:
+		return errors.New("auth server capture proxy stopped")
+	case <-extAuthzSrvDone:
+		return errors.New("auth server ext_authz stopped")
 	}
 
 	return nil
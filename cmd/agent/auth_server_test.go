@@ -0,0 +1,90 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
+	"github.com/urfave/cli/v2"
+)
+
+func TestNewRateLimitConfig(t *testing.T) {
+	tests := []struct {
+		desc    string
+		args    []string
+		want    auth.RateLimitConfig
+		wantErr string
+	}{
+		{
+			desc: "default values",
+			want: auth.RateLimitConfig{MaxFailures: 10, Window: time.Minute, BanDuration: 5 * time.Minute},
+		},
+		{
+			desc: "disabled rate limit ignores the window and ban duration",
+			args: []string{"--rate-limit.max-failures", "0", "--rate-limit.window", "0s", "--rate-limit.ban-duration", "0s"},
+			want: auth.RateLimitConfig{},
+		},
+		{
+			desc:    "null window",
+			args:    []string{"--rate-limit.window", "0s"},
+			wantErr: `flag "rate-limit.window" must be positive when "rate-limit.max-failures" is set`,
+		},
+		{
+			desc:    "negative window",
+			args:    []string{"--rate-limit.window", "-1m"},
+			wantErr: `flag "rate-limit.window" must be positive when "rate-limit.max-failures" is set`,
+		},
+		{
+			desc:    "null ban duration",
+			args:    []string{"--rate-limit.ban-duration", "0s"},
+			wantErr: `flag "rate-limit.ban-duration" must be positive when "rate-limit.max-failures" is set`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			app := &cli.App{
+				Commands: []*cli.Command{
+					{
+						Name:  "test",
+						Flags: newAuthServerCmd().flags,
+						Action: func(cliCtx *cli.Context) error {
+							cfg, err := newRateLimitConfig(cliCtx)
+							if test.wantErr != "" {
+								assert.EqualError(t, err, test.wantErr)
+								return nil
+							}
+
+							require.NoError(t, err)
+							assert.Equal(t, test.want, cfg)
+
+							return nil
+						},
+					},
+				},
+			}
+
+			require.NoError(t, app.Run(append([]string{"agent", "test"}, test.args...)))
+		})
+	}
+}
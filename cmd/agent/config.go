@@ -0,0 +1,239 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/configfile"
+	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/urfave/cli/v2"
+)
+
+const flagConfig = "config"
+
+// configFileCheckInterval is the interval at which the configuration file is checked for changes. Mounted ConfigMaps
+// are themselves refreshed by the kubelet about every minute.
+const configFileCheckInterval = 10 * time.Second
+
+func configFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    flagConfig,
+		Usage:   "Path to a YAML or TOML file the flags are read from, the flags set on the command line or in the environment take precedence, watched for changes to the reloadable settings",
+		EnvVars: []string{strcase.ToSNAKE(flagConfig)},
+	}
+}
+
+// reloadable is a setting applied again when the configuration file changes.
+type reloadable struct {
+	flags []string
+	apply func(cfg *cli.Context) error
+}
+
+// configFile is the configuration file of a command. It's nil when the command doesn't have any, in which case its
+// methods are no-ops.
+type configFile struct {
+	cliCtx   *cli.Context
+	watcher  *configfile.Watcher
+	explicit map[string]struct{}
+
+	reloadablesMu sync.Mutex
+	reloadables   []reloadable
+}
+
+// loadConfigFile sets the flags of the command which aren't set on the command line or in the environment from the
+// configuration file. The returned configuration file must be run to reload the settings when the file changes, unless
+// the command exits right away, it's nil when there's no configuration file.
+func loadConfigFile(cliCtx *cli.Context) (*configFile, error) {
+	path := cliCtx.String(flagConfig)
+	if path == "" {
+		return nil, nil
+	}
+
+	watcher, err := configfile.NewWatcher(path, configFileCheckInterval)
+	if err != nil {
+		return nil, fmt.Errorf("create configuration file watcher: %w", err)
+	}
+
+	// Flags set on the command line or in the environment are left untouched, when loading and reloading the file.
+	explicit := make(map[string]struct{})
+	for _, name := range cliCtx.FlagNames() {
+		explicit[name] = struct{}{}
+	}
+
+	f := &configFile{
+		cliCtx:   cliCtx,
+		watcher:  watcher,
+		explicit: explicit,
+	}
+
+	for name, values := range watcher.Values() {
+		if err = f.checkFlag(name); err != nil {
+			return nil, err
+		}
+		if _, ok := explicit[name]; ok {
+			continue
+		}
+
+		for _, value := range values {
+			if err = cliCtx.Set(name, value); err != nil {
+				return nil, fmt.Errorf("set flag %q from the configuration file: %w", name, err)
+			}
+		}
+	}
+
+	f.OnChange(func(cfg *cli.Context) error {
+		return logger.SetLevel(cfg.String(flagLogLevel))
+	}, flagLogLevel)
+
+	watcher.AddListener(f.reload)
+
+	return f, nil
+}
+
+// OnChange registers a function applying again the settings of the given flags, once the configuration file changed
+// them. The function gets the flags of the command, read again from the configuration file.
+func (f *configFile) OnChange(apply func(cfg *cli.Context) error, flags ...string) {
+	if f == nil {
+		return
+	}
+
+	f.reloadablesMu.Lock()
+	defer f.reloadablesMu.Unlock()
+
+	f.reloadables = append(f.reloadables, reloadable{flags: flags, apply: apply})
+}
+
+// Run watches the configuration file for changes.
+func (f *configFile) Run(ctx context.Context) {
+	if f == nil {
+		return
+	}
+
+	f.watcher.Run(ctx)
+}
+
+func (f *configFile) checkFlag(name string) error {
+	if name == flagConfig {
+		return fmt.Errorf("flag %q can't be set from the configuration file", name)
+	}
+
+	for _, flg := range f.cliCtx.Command.Flags {
+		for _, flagName := range flg.Names() {
+			if flagName == name {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("unknown flag %q in the configuration file", name)
+}
+
+func (f *configFile) reload(previous, current configfile.Values) {
+	changed := make(map[string]struct{})
+	for _, name := range previous.Changed(current) {
+		if err := f.checkFlag(name); err != nil {
+			log.Error().Err(err).Msg("Unable to reload the configuration file")
+			return
+		}
+		if _, ok := f.explicit[name]; ok {
+			continue
+		}
+
+		changed[name] = struct{}{}
+	}
+
+	if len(changed) == 0 {
+		return
+	}
+
+	cfg, err := f.parse(current)
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to reload the configuration file")
+		return
+	}
+
+	f.reloadablesMu.Lock()
+	defer f.reloadablesMu.Unlock()
+
+	for _, r := range f.reloadables {
+		var applies bool
+		for _, name := range r.flags {
+			if _, ok := changed[name]; ok {
+				applies = true
+				delete(changed, name)
+			}
+		}
+		if !applies {
+			continue
+		}
+
+		if err = r.apply(cfg); err != nil {
+			log.Error().Err(err).Strs("flags", r.flags).Msg("Unable to apply the reloaded settings")
+			continue
+		}
+
+		log.Info().Strs("flags", r.flags).Msg("Settings reloaded")
+	}
+
+	for name := range changed {
+		log.Warn().Str("flag", name).Msg("Setting changed in the configuration file, it's applied on the next restart")
+	}
+}
+
+// parse parses the flags of the command again, from the given values of the configuration file.
+func (f *configFile) parse(values configfile.Values) (*cli.Context, error) {
+	set := flag.NewFlagSet(f.cliCtx.Command.Name, flag.ContinueOnError)
+	for _, flg := range f.cliCtx.Command.Flags {
+		if err := flg.Apply(set); err != nil {
+			return nil, fmt.Errorf("apply flag %q: %w", flg.Names()[0], err)
+		}
+	}
+
+	// The flags set on the command line or in the environment keep their value.
+	values = maps.Clone(values)
+	for _, flg := range f.cliCtx.Command.Flags {
+		name := flg.Names()[0]
+		if _, ok := f.explicit[name]; !ok {
+			continue
+		}
+
+		if _, ok := flg.(*cli.StringSliceFlag); ok {
+			values[name] = f.cliCtx.StringSlice(name)
+			continue
+		}
+		values[name] = []string{fmt.Sprint(f.cliCtx.Value(name))}
+	}
+
+	for name, vals := range values {
+		for _, value := range vals {
+			if err := set.Set(name, value); err != nil {
+				return nil, fmt.Errorf("set flag %q: %w", name, err)
+			}
+		}
+	}
+
+	return cli.NewContext(f.cliCtx.App, set, nil), nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/configfile"
+	"github.com/urfave/cli/v2"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+topology:
+  min-patch-interval: 10s
+  namespaces: [default, apps]
+tunnel.connections: 3
+`), 0o600))
+
+	t.Setenv("TEST_TUNNEL_CONNECTIONS", "2")
+
+	var (
+		cfgFile    *configFile
+		namespaces []string
+		interval   time.Duration
+	)
+	runCommand(t, func(cliCtx *cli.Context) error {
+		var err error
+		cfgFile, err = loadConfigFile(cliCtx)
+		require.NoError(t, err)
+
+		// The command line and the environment take precedence over the configuration file.
+		assert.Equal(t, "warn", cliCtx.String(flagLogLevel))
+		assert.Equal(t, 2, cliCtx.Int("tunnel.connections"))
+		assert.Equal(t, 10*time.Second, cliCtx.Duration("topology.min-patch-interval"))
+		assert.Equal(t, []string{"default", "apps"}, cliCtx.StringSlice("topology.namespaces"))
+
+		cfgFile.OnChange(func(cfg *cli.Context) error {
+			namespaces = cfg.StringSlice("topology.namespaces")
+			return nil
+		}, "topology.namespaces")
+		cfgFile.OnChange(func(cfg *cli.Context) error {
+			interval = cfg.Duration("topology.min-patch-interval")
+			return nil
+		}, "topology.min-patch-interval")
+
+		return nil
+	}, "--config", path, "--log-level", "warn")
+
+	// Only the settings whose value changed are applied again.
+	cfgFile.reload(cfgFile.watcher.Values(), configfile.Values{
+		"topology.min-patch-interval": {"10s"},
+		"topology.namespaces":         {"apps"},
+		"tunnel.connections":          {"4"},
+		"log-level":                   {"debug"},
+	})
+
+	assert.Equal(t, []string{"apps"}, namespaces)
+	assert.Zero(t, interval)
+
+	// Removed settings get their default value back.
+	cfgFile.reload(cfgFile.watcher.Values(), configfile.Values{})
+
+	assert.Empty(t, namespaces)
+	assert.Equal(t, 5*time.Second, interval)
+}
+
+func TestLoadConfigFile_errors(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content string
+		wantErr string
+	}{
+		{
+			desc:    "unknown flag",
+			content: "topology.namespace: [default]",
+			wantErr: `unknown flag "topology.namespace" in the configuration file`,
+		},
+		{
+			desc:    "config flag",
+			content: "config: other.yaml",
+			wantErr: `flag "config" can't be set from the configuration file`,
+		},
+		{
+			desc:    "invalid value",
+			content: "tunnel.connections: many",
+			wantErr: `set flag "tunnel.connections" from the configuration file`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			runCommand(t, func(cliCtx *cli.Context) error {
+				_, err := loadConfigFile(cliCtx)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+
+				return nil
+			}, "--config", path)
+		})
+	}
+}
+
+func TestLoadConfigFile_noFile(t *testing.T) {
+	runCommand(t, func(cliCtx *cli.Context) error {
+		cfgFile, err := loadConfigFile(cliCtx)
+		require.NoError(t, err)
+		assert.Nil(t, cfgFile)
+
+		assert.NotPanics(t, func() {
+			cfgFile.OnChange(func(*cli.Context) error { return nil }, flagLogLevel)
+		})
+
+		return nil
+	})
+}
+
+// runCommand runs a command with a few flags of each kind, calling the given action with its context.
+func runCommand(t *testing.T, action cli.ActionFunc, args ...string) {
+	t.Helper()
+
+	flags := append(globalFlags(),
+		&cli.IntFlag{Name: "tunnel.connections", EnvVars: []string{"TEST_TUNNEL_CONNECTIONS"}, Value: 1},
+		&cli.DurationFlag{Name: "topology.min-patch-interval", Value: 5 * time.Second},
+		&cli.StringSliceFlag{Name: "topology.namespaces"},
+	)
+
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{Name: "test", Flags: flags, Action: action},
+		},
+	}
+
+	require.NoError(t, app.Run(append([]string{"agent", "test"}, args...)))
+}
@@ -20,34 +20,73 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/ettle/strcase"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/commands"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/install"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/heartbeat"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	"github.com/traefik/hub-agent-kubernetes/pkg/leader"
 	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/shutdown"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/store"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tracing"
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/sync/errgroup"
 	corev1 "k8s.io/api/core/v1"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	kclientset "k8s.io/client-go/kubernetes"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
 	flagPlatformURL                 = "platform-url"
 	flagPlatformIdentityProviderURL = "platform-idp-url"
+	flagPlatformFaultInjection      = "platform-fault-injection"
 	flagToken                       = "token"
 	flagTraefikMetricsURL           = "traefik.metrics-url"
+	flagMetricsOTLPEndpoint         = "metrics.otlp-endpoint"
+	flagMetricsOTLPHeaders          = "metrics.otlp-headers"
+	flagMetricsRemoteWriteURL       = "metrics.remote-write-url"
+	flagMetricsRemoteWriteHeaders   = "metrics.remote-write-headers"
+	flagMetricsAPIUsagePortal       = "metrics.api-usage-portal"
+	flagLeaderElection              = "leader-election"
+	flagLeaderElectionLeaseName     = "leader-election.lease-name"
+	flagLeaderElectionLeaseDuration = "leader-election.lease-duration"
+	flagLeaderElectionRenewDeadline = "leader-election.renew-deadline"
+	flagLeaderElectionRetryPeriod   = "leader-election.retry-period"
+	flagTopologyNamespaces          = "topology.namespaces"
+	flagTopologyExcludeNamespaces   = "topology.exclude-namespaces"
+	flagTopologyMinPatchInterval    = "topology.min-patch-interval"
+	flagTopologyResyncInterval      = "topology.resync-interval"
+	flagTopologyMaxPatchSize        = "topology.max-patch-size"
+	flagTopologyRedactKeys          = "topology.redact-keys"
+	flagTopologyRedactPatterns      = "topology.redact-patterns"
+	flagStandalone                  = "standalone"
+	flagStandaloneDomain            = "standalone.domain"
+	flagCommandsScaleMinReplicas    = "commands.scale-min-replicas"
+	flagCommandsScaleMaxReplicas    = "commands.scale-max-replicas"
+	flagSkipCRDManagement           = "skip-crd-management"
+	flagUpdateChannel               = "update.channel"
+	flagUpdatePolicy                = "update.policy"
 )
 
 type controllerCmd struct {
@@ -71,21 +110,165 @@ func newControllerCmd() controllerCmd {
 			Hidden:  true,
 		},
 		&cli.StringFlag{
-			Name:     flagToken,
-			Usage:    "The token to use for Hub platform API calls",
-			EnvVars:  []string{strcase.ToSNAKE(flagToken)},
-			Required: true,
+			Name:    flagPlatformFaultInjection,
+			Usage:   "Path to a JSON file describing faults to randomly inject in the Hub platform API responses, for testing purposes",
+			EnvVars: []string{strcase.ToSNAKE(flagPlatformFaultInjection)},
 		},
+		&cli.StringFlag{
+			Name:    flagToken,
+			Usage:   "The token to use for Hub platform API calls, required unless running in standalone mode or reading it from a file",
+			EnvVars: []string{strcase.ToSNAKE(flagToken)},
+		},
+		tokenFileFlag(),
 		&cli.StringFlag{
 			Name:    flagTraefikMetricsURL,
 			Usage:   "The url used by Traefik to expose metrics",
 			EnvVars: []string{strcase.ToSNAKE(flagTraefikMetricsURL)},
 		},
+		&cli.StringFlag{
+			Name:    flagMetricsOTLPEndpoint,
+			Usage:   "OTLP HTTP endpoint of an OpenTelemetry collector the metrics are exported to, in addition to the Hub platform",
+			EnvVars: []string{strcase.ToSNAKE(flagMetricsOTLPEndpoint)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagMetricsOTLPHeaders,
+			Usage:   "Headers sent with the metrics exported to the OTLP endpoint, in the name=value format",
+			EnvVars: []string{strcase.ToSNAKE(flagMetricsOTLPHeaders)},
+		},
+		&cli.StringFlag{
+			Name:    flagMetricsRemoteWriteURL,
+			Usage:   "Prometheus remote-write URL the metrics are exported to, in addition to the Hub platform",
+			EnvVars: []string{strcase.ToSNAKE(flagMetricsRemoteWriteURL)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagMetricsRemoteWriteHeaders,
+			Usage:   "Headers sent with the metrics exported to the remote-write URL, in the name=value format",
+			EnvVars: []string{strcase.ToSNAKE(flagMetricsRemoteWriteHeaders)},
+		},
+		&cli.BoolFlag{
+			Name:    flagLeaderElection,
+			Usage:   "Enable leader election to run multiple controller replicas, only the leader synchronizes with the platform",
+			EnvVars: []string{strcase.ToSNAKE(flagLeaderElection)},
+		},
+		&cli.StringFlag{
+			Name:    flagLeaderElectionLeaseName,
+			Usage:   "Name of the Lease used for leader election",
+			EnvVars: []string{strcase.ToSNAKE(flagLeaderElectionLeaseName)},
+			Value:   "hub-agent-controller",
+		},
+		&cli.DurationFlag{
+			Name:    flagLeaderElectionLeaseDuration,
+			Usage:   "Duration followers wait before trying to acquire a non-renewed leadership",
+			EnvVars: []string{strcase.ToSNAKE(flagLeaderElectionLeaseDuration)},
+			Value:   15 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagLeaderElectionRenewDeadline,
+			Usage:   "Duration the leader retries refreshing its leadership before giving it up",
+			EnvVars: []string{strcase.ToSNAKE(flagLeaderElectionRenewDeadline)},
+			Value:   10 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagLeaderElectionRetryPeriod,
+			Usage:   "Duration between leader election attempts",
+			EnvVars: []string{strcase.ToSNAKE(flagLeaderElectionRetryPeriod)},
+			Value:   2 * time.Second,
+		},
+		&cli.StringSliceFlag{
+			Name:    flagTopologyNamespaces,
+			Usage:   "Namespaces to collect the topology from, all namespaces are collected if empty",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyNamespaces)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagTopologyExcludeNamespaces,
+			Usage:   "Namespaces to exclude from the topology sent to the platform",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyExcludeNamespaces)},
+		},
+		&cli.DurationFlag{
+			Name:    flagTopologyMinPatchInterval,
+			Usage:   "Minimum duration between two topology patches, changes occurring in the meantime are batched",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyMinPatchInterval)},
+			Value:   5 * time.Second,
+		},
+		&cli.StringSliceFlag{
+			Name:    flagTopologyRedactKeys,
+			Usage:   "Annotation and label keys whose values are redacted from the topology sent to the platform",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyRedactKeys)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagTopologyRedactPatterns,
+			Usage:   "Regular expressions matching the annotation and label keys whose values are redacted from the topology sent to the platform",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyRedactPatterns)},
+		},
+		&cli.IntFlag{
+			Name:    flagTopologyMaxPatchSize,
+			Usage:   "Maximum size in bytes of a topology patch, larger patches are split and noisy fields of oversized resources are truncated, no limit if 0",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyMaxPatchSize)},
+			Value:   1 << 20,
+		},
+		&cli.DurationFlag{
+			Name:    flagTopologyResyncInterval,
+			Usage:   "Interval at which the whole topology is sent to the platform even if no change was detected",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyResyncInterval)},
+			Value:   time.Minute,
+		},
+		&cli.BoolFlag{
+			Name:    flagMetricsAPIUsagePortal,
+			Usage:   "Publish the usage of the APIs by their consumers, so the dev portals can show it",
+			EnvVars: []string{strcase.ToSNAKE(flagMetricsAPIUsagePortal)},
+		},
+		&cli.BoolFlag{
+			Name:    flagStandalone,
+			Usage:   "Run without the Hub platform, driving ACPs, EdgeIngresses and API management entirely from CRDs",
+			EnvVars: []string{strcase.ToSNAKE(flagStandalone)},
+		},
+		&cli.StringFlag{
+			Name:    flagStandaloneDomain,
+			Usage:   "Base domain under which EdgeIngresses and APIGateways are exposed in standalone mode",
+			EnvVars: []string{strcase.ToSNAKE(flagStandaloneDomain)},
+			Value:   "hub.local",
+		},
+		&cli.BoolFlag{
+			Name:    flagSkipCRDManagement,
+			Usage:   "Don't install nor upgrade the hub.traefik.io CRDs at startup, for clusters where they are managed separately",
+			EnvVars: []string{strcase.ToSNAKE(flagSkipCRDManagement)},
+		},
+		&cli.StringFlag{
+			Name:    flagUpdateChannel,
+			Usage:   "Release channel the newer agent versions are looked for in: stable, or beta to include the pre-releases",
+			EnvVars: []string{strcase.ToSNAKE(flagUpdateChannel)},
+			Value:   version.ChannelStable,
+		},
+		&cli.StringFlag{
+			Name:    flagUpdatePolicy,
+			Usage:   "Newer agent versions to notify of: major for all of them, minor for the ones of the current major version, patch for the ones of the current minor version, or none to only report the version to the platform",
+			EnvVars: []string{strcase.ToSNAKE(flagUpdatePolicy)},
+			Value:   version.UpdatePolicyMajor,
+		},
+		&cli.IntFlag{
+			Name:    flagCommandsScaleMinReplicas,
+			Usage:   "Minimum number of replicas workloads can be scaled to from the platform",
+			EnvVars: []string{strcase.ToSNAKE(flagCommandsScaleMinReplicas)},
+			Value:   1,
+		},
+		&cli.IntFlag{
+			Name:    flagCommandsScaleMaxReplicas,
+			Usage:   "Maximum number of replicas workloads can be scaled to from the platform",
+			EnvVars: []string{strcase.ToSNAKE(flagCommandsScaleMaxReplicas)},
+			Value:   10,
+		},
 	}
 
 	flgs = append(flgs, globalFlags()...)
 	flgs = append(flgs, admissionFlags()...)
+	flgs = append(flgs, snapshotFlags()...)
+	flgs = append(flgs, alertingFlags()...)
 	flgs = append(flgs, devPortalFlags()...)
+	flgs = append(flgs, egressFlags()...)
+	flgs = append(flgs, tracingFlags("")...)
+	flgs = append(flgs, secretBackendFlags("")...)
+	flgs = append(flgs, shutdownFlags("", shutdown.DefaultGracePeriod)...)
+	flgs = append(flgs, adminFlags("")...)
 
 	return controllerCmd{
 		flags: flgs,
@@ -102,11 +285,26 @@ func (c controllerCmd) build() *cli.Command {
 }
 
 func (c controllerCmd) run(cliCtx *cli.Context) error {
+	cfgFile, err := loadConfigFile(cliCtx)
+	if err != nil {
+		return err
+	}
+	go cfgFile.Run(cliCtx.Context)
+
 	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
 
 	version.Log()
 
-	platformURL, token := cliCtx.String(flagPlatformURL), cliCtx.String(flagToken)
+	platformURL := cliCtx.String(flagPlatformURL)
+
+	coordinator, err := newShutdownCoordinator(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	process := diagnostics.NewProcess("controller", flagValues(cliCtx))
+
+	startAdminServer(cliCtx, coordinator, newAdminMux(process))
 
 	kubeCfg, err := kube.InClusterConfigWithRetrier(2)
 	if err != nil {
@@ -118,6 +316,29 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 		return fmt.Errorf("create Kubernetes client set: %w", err)
 	}
 
+	if !cliCtx.Bool(flagSkipCRDManagement) {
+		if err = installCRDs(cliCtx.Context, kubeCfg); err != nil {
+			return err
+		}
+	}
+
+	tracer, err := newTracer(cliCtx, "traefik-hub-agent-controller")
+	if err != nil {
+		return err
+	}
+
+	if cliCtx.Bool(flagStandalone) {
+		return runStandalone(cliCtx, kubeClient, tracer, coordinator, process)
+	}
+
+	token, tokens, err := loadToken(cliCtx)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("flag %q or %q is required unless running in standalone mode", flagToken, flagTokenFile)
+	}
+
 	if err = setupOIDCSecret(cliCtx, kubeClient, token); err != nil {
 		return fmt.Errorf("setup OIDC secret: %w", err)
 	}
@@ -132,10 +353,12 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 		return fmt.Errorf("create Traefik Hub client set: %w", err)
 	}
 
-	platformClient, err := platform.NewClient(platformURL, token)
+	platformClient, err := newPlatformClient(cliCtx, platformURL, token)
 	if err != nil {
 		return fmt.Errorf("build platform client: %w", err)
 	}
+	tokens.AddListener(platformClient.SetToken)
+	platformClient.SetTracer(tracer)
 
 	configWatcher := platform.NewConfigWatcher(time.Minute, platformClient)
 
@@ -146,35 +369,165 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 		return fmt.Errorf("setup agent: %w", err)
 	}
 
-	topoFetcher, err := state.NewFetcher(cliCtx.Context, kubeClient, traefikClientSet, hubClientSet)
+	topoNamespaces := state.NamespaceFilter{
+		Namespaces:        cliCtx.StringSlice(flagTopologyNamespaces),
+		ExcludeNamespaces: cliCtx.StringSlice(flagTopologyExcludeNamespaces),
+	}
+
+	topoRedaction := state.RedactionFilter{
+		Keys:     cliCtx.StringSlice(flagTopologyRedactKeys),
+		Patterns: cliCtx.StringSlice(flagTopologyRedactPatterns),
+	}
+
+	topoFetcher, err := state.NewFetcher(cliCtx.Context, kubeClient, traefikClientSet, hubClientSet, topoNamespaces, topoRedaction)
+	if err != nil {
+		return err
+	}
+	// Controller metrics are served by the webhook server.
+	registry := newControllerRegistry()
+
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "hub_agent",
+		Subsystem: "platform",
+		Name:      "clock_skew_seconds",
+		Help:      "Skew between the platform and agent clocks, positive when the platform clock is ahead.",
+	}, func() float64 {
+		return platformClient.ClockSkew().Measured().Seconds()
+	}))
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "hub_agent",
+		Subsystem: "platform",
+		Name:      "circuit_breaker_state",
+		Help:      "State of the circuit breaker of the requests to the platform: 0 when closed, 1 when half-open and 2 when open.",
+	}, func() float64 {
+		return float64(platformClient.CircuitBreaker().State())
+	}))
+	registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: "hub_agent",
+		Subsystem: "platform",
+		Name:      "circuit_breaker_opens_total",
+		Help:      "Number of times the circuit breaker of the requests to the platform opened.",
+	}, func() float64 {
+		return float64(platformClient.CircuitBreaker().Opens())
+	}))
+
+	platformMetrics, err := platform.NewMetrics(registry)
+	if err != nil {
+		return fmt.Errorf("create platform metrics: %w", err)
+	}
+	platformClient.SetMetrics(platformMetrics)
+
+	topoMetrics, err := store.NewMetrics(registry)
+	if err != nil {
+		return fmt.Errorf("create topology metrics: %w", err)
+	}
+
+	topoStore := store.New(platformClient, store.Config{
+		MaxPatchSize: cliCtx.Int(flagTopologyMaxPatchSize),
+		Metrics:      topoMetrics,
+	})
+
+	topoWatch := topology.NewWatcher(topoFetcher, topoStore, topology.WatcherConfig{
+		MinPatchInterval: cliCtx.Duration(flagTopologyMinPatchInterval),
+		ResyncInterval:   cliCtx.Duration(flagTopologyResyncInterval),
+	})
+
+	cfgFile.OnChange(func(cfg *cli.Context) error {
+		return topoFetcher.SetNamespaces(state.NamespaceFilter{
+			Namespaces:        cfg.StringSlice(flagTopologyNamespaces),
+			ExcludeNamespaces: cfg.StringSlice(flagTopologyExcludeNamespaces),
+		})
+	}, flagTopologyNamespaces, flagTopologyExcludeNamespaces)
+	cfgFile.OnChange(func(cfg *cli.Context) error {
+		topoWatch.SetMinPatchInterval(cfg.Duration(flagTopologyMinPatchInterval))
+		return nil
+	}, flagTopologyMinPatchInterval)
+
+	snapshotUploader, err := newSnapshotUploader(cliCtx)
+	if err != nil {
+		return fmt.Errorf("create topology snapshot uploader: %w", err)
+	}
+
+	alertDispatcher, err := newAlertDispatcher(cliCtx)
+	if err != nil {
+		return fmt.Errorf("create alert dispatcher: %w", err)
+	}
+
+	checker, err := newVersionChecker(cliCtx, platformClient, kubeClient)
 	if err != nil {
 		return err
 	}
-	topoWatch := topology.NewWatcher(topoFetcher, store.New(platformClient))
 
-	checker := version.NewChecker(platformClient)
+	scaleLimits, err := newScaleLimits(cliCtx)
+	if err != nil {
+		return err
+	}
 
-	commandWatcher := commands.NewWatcher(10*time.Second, platformClient, kubeClient, traefikClientSet)
+	commandWatcher := commands.NewWatcher(10*time.Second, platformClient, kubeClient, traefikClientSet, hubClientSet, scaleLimits)
+	commandWatcher.SetSubscriber(platformClient)
+	commandWatcher.SetMarkers(commands.NewMarkers(kubeClient, currentNamespace(), 24*time.Hour))
+	commandWatcher.SetDiagnostics(platformClient, process.Source(), diagnostics.ClusterSource(kubeClient, diagnostics.ClusterConfig{
+		Namespace: currentNamespace(),
+		LogLines:  diagnosticsLogLines,
+	}))
+
+	leaderRunner := leader.NewRunner(kubeClient, leader.Config{
+		Enabled:       cliCtx.Bool(flagLeaderElection),
+		LeaseName:     cliCtx.String(flagLeaderElectionLeaseName),
+		Namespace:     currentNamespace(),
+		Identity:      podName(),
+		LeaseDuration: cliCtx.Duration(flagLeaderElectionLeaseDuration),
+		RenewDeadline: cliCtx.Duration(flagLeaderElectionRenewDeadline),
+		RetryPeriod:   cliCtx.Duration(flagLeaderElectionRetryPeriod),
+	})
 
 	group, ctx := errgroup.WithContext(cliCtx.Context)
 
+	if tokens != nil {
+		group.Go(func() error {
+			tokens.Run(ctx)
+			return nil
+		})
+	}
+
+	if tracer != nil {
+		group.Go(func() error {
+			tracer.Run(ctx)
+			return nil
+		})
+	}
+
 	group.Go(func() error {
 		configWatcher.Run(ctx)
 		return nil
 	})
 
-	group.Go(func() error {
+	leaderRunner.Add(func(ctx context.Context) error {
 		heartbeater.Run(ctx)
 		return nil
 	})
 
 	if cliCtx.String(flagTraefikMetricsURL) != "" {
-		mtrcsMgr, mtrcsStore, errMetrics := newMetrics(topoWatch, token, platformURL, cliCtx.String(flagTraefikMetricsURL), agentCfg.Metrics, configWatcher)
+		export := exportConfig{
+			OTLPEndpoint:       cliCtx.String(flagMetricsOTLPEndpoint),
+			OTLPHeaders:        cliCtx.StringSlice(flagMetricsOTLPHeaders),
+			RemoteWriteURL:     cliCtx.String(flagMetricsRemoteWriteURL),
+			RemoteWriteHeaders: cliCtx.StringSlice(flagMetricsRemoteWriteHeaders),
+		}
+		mtrcsMgr, mtrcsStore, errMetrics := newMetrics(topoWatch, token, tokens, platformURL, cliCtx.String(flagTraefikMetricsURL), agentCfg.Metrics, export, configWatcher, platformClient.ClockSkew())
 		if errMetrics != nil {
 			return errMetrics
 		}
 
-		group.Go(func() error {
+		rejections, errMetrics := metrics.NewRejectionMetrics(registry)
+		if errMetrics != nil {
+			return fmt.Errorf("create rejection metrics: %w", errMetrics)
+		}
+		mtrcsMgr.SetRejectionMetrics(rejections)
+
+		coordinator.Register(shutdown.PhaseFlush, "metrics", mtrcsMgr.Flush)
+
+		leaderRunner.Add(func(ctx context.Context) error {
 			errMM := mtrcsMgr.Run(ctx)
 			if errMM != nil {
 				log.Error().Err(errMM).Msg("metrics manager stopped")
@@ -183,8 +536,17 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 			return errMM
 		})
 
-		group.Go(func() error {
-			errAlerting := runAlerting(ctx, token, platformURL, mtrcsStore, topoFetcher)
+		if cliCtx.Bool(flagMetricsAPIUsagePortal) {
+			publisher := metrics.NewAPIUsagePublisher(kubeClient, currentNamespace(), mtrcsMgr.APIUsages)
+
+			leaderRunner.Add(func(ctx context.Context) error {
+				publisher.Run(ctx)
+				return nil
+			})
+		}
+
+		leaderRunner.Add(func(ctx context.Context) error {
+			errAlerting := runAlerting(ctx, token, tokens, platformURL, mtrcsStore, topoFetcher, platformClient.ClockSkew(), alertDispatcher, hubClientSet)
 			if errAlerting != nil {
 				log.Error().Err(errAlerting).Msg("alerts stopped")
 			}
@@ -193,13 +555,26 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 		})
 	}
 
-	group.Go(func() error {
+	if snapshotUploader != nil {
+		topoWatch.AddListener(snapshotUploader.TopologyStateChanged)
+
+		leaderRunner.Add(func(ctx context.Context) error {
+			snapshotUploader.Run(ctx)
+			return nil
+		})
+	}
+
+	leaderRunner.Add(func(ctx context.Context) error {
 		topoWatch.Start(ctx)
 		return nil
 	})
+	coordinator.Register(shutdown.PhaseFlush, "topology", func(ctx context.Context) error {
+		topoWatch.Flush(ctx)
+		return nil
+	})
 
 	group.Go(func() error {
-		errWh := webhookAdmission(ctx, cliCtx, platformClient, configWatcher)
+		errWh := webhookAdmission(ctx, cliCtx, registry, tracer, platformClient, configWatcher, leaderRunner, coordinator, process)
 		if errWh != nil {
 			log.Error().Err(errWh).Msg("webhook stopped")
 		}
@@ -216,19 +591,146 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 		return errCheck
 	})
 
-	group.Go(func() error {
+	leaderRunner.Add(func(ctx context.Context) error {
 		commandWatcher.Start(ctx)
 		return nil
 	})
 
+	group.Go(func() error {
+		errLeader := leaderRunner.Run(ctx)
+		if errLeader != nil {
+			log.Error().Err(errLeader).Msg("leader runner stopped")
+		}
+
+		return errLeader
+	})
+
 	err = group.Wait()
 	if err != nil {
 		log.Error().Err(err).Msg("group wait stopped")
 	}
 
+	runShutdown(coordinator)
+
 	return err
 }
 
+// newVersionChecker creates the checker of the agent version, which records an event on the controller pod when a
+// newer version is available.
+func newVersionChecker(cliCtx *cli.Context, platformClient *platform.Client, kubeClient kclientset.Interface) (*version.Checker, error) {
+	cfg := version.CheckerConfig{
+		Channel:      cliCtx.String(flagUpdateChannel),
+		UpdatePolicy: cliCtx.String(flagUpdatePolicy),
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	checker := version.NewChecker(platformClient, cfg)
+
+	pod, err := kubeClient.CoreV1().Pods(currentNamespace()).Get(cliCtx.Context, podName(), metav1.GetOptions{})
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to get the controller pod, available updates won't be recorded as events")
+		return checker, nil
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	checker.SetEventRecorder(broadcaster.NewRecorder(kscheme.Scheme, corev1.EventSource{Component: "hub-agent-controller"}), pod)
+
+	return checker, nil
+}
+
+// installCRDs installs the hub.traefik.io CRDs, or upgrades them to the version the agent was built with, before the
+// informers watching them are started.
+func installCRDs(ctx context.Context, kubeCfg *rest.Config) error {
+	dynamicClient, err := dynamic.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create dynamic client: %w", err)
+	}
+
+	if err = install.NewManager(dynamicClient, version.Version()).Run(ctx); err != nil {
+		return fmt.Errorf("install CRDs: %w", err)
+	}
+
+	return nil
+}
+
+// runStandalone runs the controller without the Hub platform. Only the admission webhooks and the reconciliation of
+// ACPs, EdgeIngresses and API management resources from their CRDs are run: heartbeat, topology, metrics, alerting,
+// version checks and platform commands all require the platform.
+func runStandalone(cliCtx *cli.Context, kubeClient kclientset.Interface, tracer *tracing.Tracer, coordinator *shutdown.Coordinator, process *diagnostics.Process) error {
+	log.Info().
+		Str("domain", cliCtx.String(flagStandaloneDomain)).
+		Msg("Running in standalone mode, the Hub platform is not used")
+
+	leaderRunner := leader.NewRunner(kubeClient, leader.Config{
+		Enabled:       cliCtx.Bool(flagLeaderElection),
+		LeaseName:     cliCtx.String(flagLeaderElectionLeaseName),
+		Namespace:     currentNamespace(),
+		Identity:      podName(),
+		LeaseDuration: cliCtx.Duration(flagLeaderElectionLeaseDuration),
+		RenewDeadline: cliCtx.Duration(flagLeaderElectionRenewDeadline),
+		RetryPeriod:   cliCtx.Duration(flagLeaderElectionRetryPeriod),
+	})
+
+	group, ctx := errgroup.WithContext(cliCtx.Context)
+
+	if tracer != nil {
+		group.Go(func() error {
+			tracer.Run(ctx)
+			return nil
+		})
+	}
+
+	group.Go(func() error {
+		errWh := webhookAdmission(ctx, cliCtx, newControllerRegistry(), tracer, nil, nil, leaderRunner, coordinator, process)
+		if errWh != nil {
+			log.Error().Err(errWh).Msg("webhook stopped")
+		}
+
+		return errWh
+	})
+
+	group.Go(func() error {
+		errLeader := leaderRunner.Run(ctx)
+		if errLeader != nil {
+			log.Error().Err(errLeader).Msg("leader runner stopped")
+		}
+
+		return errLeader
+	})
+
+	err := group.Wait()
+	if err != nil {
+		log.Error().Err(err).Msg("group wait stopped")
+	}
+
+	runShutdown(coordinator)
+
+	return err
+}
+
+// newPlatformClient creates a platform client reaching the platform through the egress configured by the egress
+// flags, and injecting the faults configured by the platform fault injection flag if any.
+func newPlatformClient(cliCtx *cli.Context, platformURL, token string) (*platform.Client, error) {
+	egress, err := newEgress(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := platform.ClientConfig{Egress: egress}
+
+	if faultInjectionPath := cliCtx.String(flagPlatformFaultInjection); faultInjectionPath != "" {
+		cfg.FaultInjection, err = platform.LoadFaultInjectionConfig(faultInjectionPath)
+		if err != nil {
+			return nil, fmt.Errorf("load platform fault injection config: %w", err)
+		}
+	}
+
+	return platform.NewClientWithConfig(platformURL, token, cfg)
+}
+
 func setupOIDCSecret(cliCtx *cli.Context, client kclientset.Interface, token string) error {
 	ctx, cancel := context.WithTimeout(cliCtx.Context, time.Second*5)
 	defer cancel()
@@ -259,6 +761,19 @@ func setupOIDCSecret(cliCtx *cli.Context, client kclientset.Interface, token str
 	return nil
 }
 
+func podName() string {
+	if name := os.Getenv("POD_NAME"); name != "" {
+		return name
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to get hostname")
+	}
+
+	return hostname
+}
+
 func setup(ctx context.Context, c *platform.Client, kubeClient kclientset.Interface) (platform.Config, error) {
 	ns, err := kubeClient.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
 	if err != nil {
@@ -277,3 +792,24 @@ func setup(ctx context.Context, c *platform.Client, kubeClient kclientset.Interf
 
 	return cfg, nil
 }
+
+// newControllerRegistry creates the registry of the controller metrics.
+func newControllerRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	return registry
+}
+
+// newScaleLimits returns the guardrails of the replicas workloads can be scaled to from the platform.
+func newScaleLimits(cliCtx *cli.Context) (commands.ScaleLimits, error) {
+	limits := commands.ScaleLimits{
+		MinReplicas: int32(cliCtx.Int(flagCommandsScaleMinReplicas)),
+		MaxReplicas: int32(cliCtx.Int(flagCommandsScaleMaxReplicas)),
+	}
+	if limits.MinReplicas < 0 || limits.MinReplicas > limits.MaxReplicas {
+		return commands.ScaleLimits{}, fmt.Errorf("invalid scale replicas range [%d, %d]", limits.MinReplicas, limits.MaxReplicas)
+	}
+
+	return limits, nil
+}
@@ -18,7 +18,6 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package main
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	stdlog "log"
@@ -29,16 +28,32 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/api/devportal"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/health"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
 	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
-	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/shutdown"
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 	"github.com/urfave/cli/v2"
+	kinformers "k8s.io/client-go/informers"
+	kclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
 
+const (
+	flagOpenAPIHistoryRetention     = "openapi-history.retention"
+	flagOpenAPICacheDir             = "openapi-cache.dir"
+	flagOpenAPICacheRefreshInterval = "openapi-cache.refresh-interval"
+	flagQuotasURL                   = "quotas-url"
+	flagTryItMaxRequestBodyBytes    = "try-it.max-request-body-bytes"
+	flagTryItMaxResponseBodyBytes   = "try-it.max-response-body-bytes"
+	flagTryItTimeout                = "try-it.timeout"
+	flagAPIKeysMaxPerUser           = "api-keys.max-per-user"
+	flagAPIUsage                    = "api-usage"
+)
+
 type devPortalCmd struct {
 	flags []cli.Flag
 }
@@ -59,14 +74,73 @@ func newDevPortalCmd() devPortalCmd {
 			Hidden:  true,
 		},
 		&cli.StringFlag{
-			Name:     flagToken,
-			Usage:    "The token to use for Hub platform API calls",
-			EnvVars:  []string{strcase.ToSNAKE(flagToken)},
-			Required: true,
+			Name:    flagPlatformFaultInjection,
+			Usage:   "Path to a JSON file describing faults to randomly inject in the Hub platform API responses, for testing purposes",
+			EnvVars: []string{strcase.ToSNAKE(flagPlatformFaultInjection)},
+		},
+		&cli.StringFlag{
+			Name:    flagToken,
+			Usage:   "The token to use for Hub platform API calls, required unless reading it from a file",
+			EnvVars: []string{strcase.ToSNAKE(flagToken)},
+		},
+		tokenFileFlag(),
+		&cli.IntFlag{
+			Name:    flagOpenAPIHistoryRetention,
+			Usage:   "Number of versions of the OpenAPI spec of each API kept to show the changes between API releases, 0 disables the history",
+			EnvVars: []string{strcase.ToSNAKE(flagOpenAPIHistoryRetention)},
+			Value:   10,
+		},
+		&cli.StringFlag{
+			Name:    flagOpenAPICacheDir,
+			Usage:   "Directory in which the OpenAPI specs are cached so they survive restarts, empty only caches them in memory",
+			EnvVars: []string{strcase.ToSNAKE(flagOpenAPICacheDir)},
+		},
+		&cli.DurationFlag{
+			Name:    flagOpenAPICacheRefreshInterval,
+			Usage:   "Interval at which the cached OpenAPI specs are fetched again from the API services",
+			EnvVars: []string{strcase.ToSNAKE(flagOpenAPICacheRefreshInterval)},
+			Value:   5 * time.Minute,
+		},
+		&cli.StringFlag{
+			Name:    flagQuotasURL,
+			Usage:   "URL of the auth server endpoint serving the usage of the API key quotas, empty disables the portal quotas endpoint",
+			EnvVars: []string{strcase.ToSNAKE(flagQuotasURL)},
+		},
+		&cli.Int64Flag{
+			Name:    flagTryItMaxRequestBodyBytes,
+			Usage:   "Maximum size of the bodies of the requests portal users send to the APIs from the portal",
+			EnvVars: []string{strcase.ToSNAKE(flagTryItMaxRequestBodyBytes)},
+			Value:   1 << 20,
+		},
+		&cli.Int64Flag{
+			Name:    flagTryItMaxResponseBodyBytes,
+			Usage:   "Maximum size of the bodies of the API responses returned to portal users trying the APIs from the portal",
+			EnvVars: []string{strcase.ToSNAKE(flagTryItMaxResponseBodyBytes)},
+			Value:   5 << 20,
+		},
+		&cli.DurationFlag{
+			Name:    flagTryItTimeout,
+			Usage:   "Timeout of the requests portal users send to the APIs from the portal",
+			EnvVars: []string{strcase.ToSNAKE(flagTryItTimeout)},
+			Value:   30 * time.Second,
+		},
+		&cli.IntFlag{
+			Name:    flagAPIKeysMaxPerUser,
+			Usage:   "Maximum number of API keys each portal user can issue, 0 disables the portal API keys endpoints",
+			EnvVars: []string{strcase.ToSNAKE(flagAPIKeysMaxPerUser)},
+			Value:   10,
+		},
+		&cli.BoolFlag{
+			Name:    flagAPIUsage,
+			Usage:   "Serve the usage of the APIs published by the controller, which must run with --metrics.api-usage-portal",
+			EnvVars: []string{strcase.ToSNAKE(flagAPIUsage)},
 		},
 	}
 
 	flgs = append(flgs, globalFlags()...)
+	flgs = append(flgs, egressFlags()...)
+	flgs = append(flgs, secretBackendFlags("")...)
+	flgs = append(flgs, shutdownFlags("", shutdown.DefaultGracePeriod)...)
 
 	return devPortalCmd{
 		flags: flgs,
@@ -83,15 +157,39 @@ func (c devPortalCmd) build() *cli.Command {
 }
 
 func (c devPortalCmd) run(cliCtx *cli.Context) error {
+	cfgFile, err := loadConfigFile(cliCtx)
+	if err != nil {
+		return err
+	}
+	go cfgFile.Run(cliCtx.Context)
+
 	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
 
 	version.Log()
 
-	platformClient, err := platform.NewClient(cliCtx.String(flagPlatformURL), cliCtx.String(flagToken))
+	coordinator, err := newShutdownCoordinator(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	token, tokens, err := loadToken(cliCtx)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("flag %q or %q is required", flagToken, flagTokenFile)
+	}
+
+	platformClient, err := newPlatformClient(cliCtx, cliCtx.String(flagPlatformURL), token)
 	if err != nil {
 		return fmt.Errorf("build platform client: %w", err)
 	}
 
+	if tokens != nil {
+		tokens.AddListener(platformClient.SetToken)
+		go tokens.Run(cliCtx.Context)
+	}
+
 	config, err := kube.InClusterConfigWithRetrier(2)
 	if err != nil {
 		return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
@@ -102,7 +200,34 @@ func (c devPortalCmd) run(cliCtx *cli.Context) error {
 		return fmt.Errorf("create Hub client set: %w", err)
 	}
 
+	kubeClientSet, err := kclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes client set: %w", err)
+	}
+
 	hubInformer := hubinformers.NewSharedInformerFactory(hubClientSet, 5*time.Minute)
+	kubeInformer := kinformers.NewSharedInformerFactory(kubeClientSet, 5*time.Minute)
+
+	// Secrets hold the credentials and certificate authorities used to fetch the API specs.
+	secretProvider, err := newSecretProvider(cliCtx)
+	if err != nil {
+		return err
+	}
+	fetcher := openapi.NewFetcher(nil, newSecretResolver(secretProvider, kubeInformer.Core().V1().Secrets().Lister()))
+
+	// Specs are served from a cache, portal page loads must not hit the API services.
+	specs, err := openapi.NewCache(fetcher, openapi.CacheConfig{
+		Dir:             cliCtx.String(flagOpenAPICacheDir),
+		RefreshInterval: cliCtx.Duration(flagOpenAPICacheRefreshInterval),
+	})
+	if err != nil {
+		return fmt.Errorf("create OpenAPI spec cache: %w", err)
+	}
+
+	var history *devportal.SpecHistory
+	if retention := cliCtx.Int(flagOpenAPIHistoryRetention); retention > 0 {
+		history = devportal.NewSpecHistory(kubeClientSet, currentNamespace(), retention)
+	}
 
 	portalInformer := hubInformer.Hub().V1alpha1().APIPortals()
 	gatewayInformer := hubInformer.Hub().V1alpha1().APIGateways()
@@ -110,7 +235,35 @@ func (c devPortalCmd) run(cliCtx *cli.Context) error {
 	collectionInformer := hubInformer.Hub().V1alpha1().APICollections()
 	accessInformer := hubInformer.Hub().V1alpha1().APIAccesses()
 
-	handler := devportal.NewHandler(platformClient)
+	var quotas devportal.QuotaGetter
+	if quotasURL := cliCtx.String(flagQuotasURL); quotasURL != "" {
+		quotas = devportal.NewQuotaClient(quotasURL)
+	}
+
+	// Tried out APIs are reached on the public domains of their gateways, hence through the egress.
+	egress, err := newEgress(cliCtx)
+	if err != nil {
+		return err
+	}
+	tryIt := devportal.NewTryItProxy(egress.Transport(), devportal.TryItConfig{
+		MaxRequestBodyBytes:  cliCtx.Int64(flagTryItMaxRequestBodyBytes),
+		MaxResponseBodyBytes: cliCtx.Int64(flagTryItMaxResponseBodyBytes),
+		Timeout:              cliCtx.Duration(flagTryItTimeout),
+	})
+
+	// Issued keys are stored in the namespace of the agent, where the auth server looks them up.
+	var keys *devportal.APIKeyStore
+	if maxPerUser := cliCtx.Int(flagAPIKeysMaxPerUser); maxPerUser > 0 {
+		keys = devportal.NewAPIKeyStore(kubeClientSet, currentNamespace(), maxPerUser)
+	}
+
+	// API usages are published by the controller in the namespace of the agent.
+	var usage devportal.UsageGetter
+	if cliCtx.Bool(flagAPIUsage) {
+		usage = devportal.NewUsageReader(kubeClientSet, currentNamespace())
+	}
+
+	handler := devportal.NewHandler(platformClient, specs, history, quotas, tryIt, keys, usage)
 	portalWatcher := devportal.NewWatcher(handler,
 		portalInformer.Lister(),
 		gatewayInformer.Lister(),
@@ -139,18 +292,30 @@ func (c devPortalCmd) run(cliCtx *cli.Context) error {
 		}
 	}
 
+	kubeInformer.Start(cliCtx.Context.Done())
+
+	for t, ok := range kubeInformer.WaitForCacheSync(cliCtx.Context.Done()) {
+		if !ok {
+			return fmt.Errorf("wait for cache sync: %s: %w", t, cliCtx.Context.Err())
+		}
+	}
+
 	go portalWatcher.Run(cliCtx.Context)
+	go specs.Run(cliCtx.Context)
 
 	listenAddr := cliCtx.String(flagListenAddr)
 
 	mux := http.NewServeMux()
 
-	mux.Handle("/_live", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
-		rw.WriteHeader(http.StatusOK)
-	}))
-	mux.Handle("/_ready", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
-		rw.WriteHeader(http.StatusOK)
-	}))
+	// The dev portal is ready once it reaches the platform, serving the portal users, and its caches are synced.
+	checker := newHealthChecker(coordinator)
+	checker.AddCheck("platform", platformReachable(platformClient))
+	checker.AddCheck("informers", health.InformersSynced(hubInformer, kubeInformer))
+
+	mux.Handle("/livez", checker.LiveHandler())
+	mux.Handle("/readyz", checker.ReadyHandler())
+	mux.Handle("/_live", checker.LiveHandler())
+	mux.Handle("/_ready", checker.ReadyHandler())
 
 	mux.Handle("/", handler)
 
@@ -171,17 +336,11 @@ func (c devPortalCmd) run(cliCtx *cli.Context) error {
 		close(srvDone)
 	}()
 
+	coordinator.Register(shutdown.PhaseDrain, "dev portal server", shutdown.HTTPServer(server))
+
 	select {
 	case <-cliCtx.Context.Done():
-		gracefulCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
-
-		if err = server.Shutdown(gracefulCtx); err != nil {
-			log.Error().Err(err).Msg("Failed to shutdown dev portal gracefully")
-			if err = server.Close(); err != nil {
-				return fmt.Errorf("close dev portal: %w", err)
-			}
-		}
+		runShutdown(coordinator)
 	case <-srvDone:
 		return errors.New("dev portal stopped")
 	}
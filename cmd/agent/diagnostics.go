@@ -0,0 +1,261 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/ettle/strcase"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
+	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/urfave/cli/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kinformers "k8s.io/client-go/informers"
+	kclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	flagDiagnosticsKubeconfig = "kubeconfig"
+	flagDiagnosticsNamespace  = "namespace"
+	flagDiagnosticsSelector   = "selector"
+	flagDiagnosticsLogLines   = "log-lines"
+	flagDiagnosticsOutput     = "output"
+)
+
+// diagnosticsLogLines is the number of lines of logs collected from each agent container by default.
+const diagnosticsLogLines = 1000
+
+// diagnosticsPath is the path of the endpoint serving the diagnostics of a command, on its admin listener.
+const diagnosticsPath = "/diagnostics"
+
+type diagnosticsCmd struct {
+	flags []cli.Flag
+}
+
+func newDiagnosticsCmd() diagnosticsCmd {
+	flgs := []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagDiagnosticsKubeconfig,
+			Usage:   "Path to the kubeconfig of the cluster running the agent, defaults to the standard kubeconfig loading rules",
+			EnvVars: []string{"KUBECONFIG"},
+		},
+		&cli.StringFlag{
+			Name:    flagDiagnosticsNamespace,
+			Usage:   "Namespace of the agent",
+			EnvVars: []string{"DIAGNOSTICS_" + strcase.ToSNAKE(flagDiagnosticsNamespace)},
+			Value:   "hub-agent",
+		},
+		&cli.StringFlag{
+			Name:    flagDiagnosticsSelector,
+			Usage:   "Label selector of the agent pods, all the pods of the namespace are selected if empty",
+			EnvVars: []string{"DIAGNOSTICS_" + strcase.ToSNAKE(flagDiagnosticsSelector)},
+		},
+		&cli.Int64Flag{
+			Name:    flagDiagnosticsLogLines,
+			Usage:   "Number of lines of logs collected from each agent container",
+			EnvVars: []string{"DIAGNOSTICS_" + strcase.ToSNAKE(flagDiagnosticsLogLines)},
+			Value:   diagnosticsLogLines,
+		},
+		&cli.StringFlag{
+			Name:    flagDiagnosticsOutput,
+			Usage:   "Path of the gzipped tarball the diagnostics bundle is written to, \"-\" for the standard output",
+			EnvVars: []string{"DIAGNOSTICS_" + strcase.ToSNAKE(flagDiagnosticsOutput)},
+			Value:   "hub-agent-diagnostics.tar.gz",
+		},
+	}
+
+	flgs = append(flgs, globalFlags()...)
+
+	return diagnosticsCmd{
+		flags: flgs,
+	}
+}
+
+func (c diagnosticsCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "diagnostics",
+		Usage:  "Collects the diagnostics of an agent installation in a tarball for support, without any secret data",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c diagnosticsCmd) run(cliCtx *cli.Context) error {
+	// The configuration is only read once, as the command exits before it could be reloaded.
+	if _, err := loadConfigFile(cliCtx); err != nil {
+		return err
+	}
+
+	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = cliCtx.String(flagDiagnosticsKubeconfig)
+
+	kubeCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("load kubeconfig: %w", err)
+	}
+
+	kubeClientSet, err := kclientset.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes client set: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if output := cliCtx.String(flagDiagnosticsOutput); output != "-" {
+		var f *os.File
+		f, err = os.Create(output)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+
+		w = f
+	}
+
+	err = diagnostics.Write(cliCtx.Context, w, diagnostics.ClusterSource(kubeClientSet, diagnostics.ClusterConfig{
+		Namespace:       cliCtx.String(flagDiagnosticsNamespace),
+		Selector:        cliCtx.String(flagDiagnosticsSelector),
+		LogLines:        cliCtx.Int64(flagDiagnosticsLogLines),
+		ProcessEndpoint: processEndpoint,
+	}))
+	if err != nil {
+		return fmt.Errorf("write diagnostics bundle: %w", err)
+	}
+
+	return nil
+}
+
+// processEndpoint returns the endpoint on which the given pod serves the diagnostics of its command, read from the
+// admin listener flag of its containers. There's none when the admin listener is bound to the loopback interface, as
+// the API server proxy can't reach it.
+func processEndpoint(pod *corev1.Pod) (diagnostics.ProcessEndpoint, bool) {
+	commands := map[string]*cli.Command{
+		"controller":  newControllerCmd().build(),
+		"auth-server": newAuthServerCmd().build(),
+		"tunnel":      newTunnelCmd().build(),
+	}
+
+	for _, container := range pod.Spec.Containers {
+		args := append(append([]string(nil), container.Command...), container.Args...)
+
+		for _, arg := range args {
+			cmd, ok := commands[arg]
+			if !ok {
+				continue
+			}
+
+			addr := containerFlag(container, args, cmd, flagAdminListenAddr)
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil || isLoopback(host) {
+				return diagnostics.ProcessEndpoint{}, false
+			}
+
+			return diagnostics.ProcessEndpoint{
+				Scheme: "http",
+				Port:   port,
+				Path:   diagnosticsPath,
+			}, true
+		}
+	}
+
+	return diagnostics.ProcessEndpoint{}, false
+}
+
+// isLoopback reports whether the given host of a listen address is on the loopback interface.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// containerFlag returns the value of the given flag of a command run by a container, set on its command line, in its
+// environment, or its default value otherwise.
+func containerFlag(container corev1.Container, args []string, cmd *cli.Command, name string) string {
+	for i, arg := range args {
+		flagName, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || flagName != name {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+
+	for _, flg := range cmd.Flags {
+		strFlag, ok := flg.(*cli.StringFlag)
+		if !ok || strFlag.Name != name {
+			continue
+		}
+
+		for _, envVar := range strFlag.EnvVars {
+			for _, env := range container.Env {
+				if env.Name == envVar && env.ValueFrom == nil {
+					return env.Value
+				}
+			}
+		}
+
+		return strFlag.Value
+	}
+
+	return ""
+}
+
+// flagValues returns the values of the flags of the command.
+func flagValues(cliCtx *cli.Context) map[string]string {
+	values := make(map[string]string)
+	for _, flg := range cliCtx.Command.Flags {
+		name := flg.Names()[0]
+
+		if _, ok := flg.(*cli.StringSliceFlag); ok {
+			values[name] = strings.Join(cliCtx.StringSlice(name), ",")
+			continue
+		}
+		values[name] = fmt.Sprint(cliCtx.Value(name))
+	}
+
+	return values
+}
+
+func kubeInformerFor(factory kinformers.SharedInformerFactory) diagnostics.InformerFor {
+	return func(obj runtime.Object) cache.SharedIndexInformer {
+		return factory.InformerFor(obj, nil)
+	}
+}
+
+func hubInformerFor(factory hubinformers.SharedInformerFactory) diagnostics.InformerFor {
+	return func(obj runtime.Object) cache.SharedIndexInformer {
+		return factory.InformerFor(obj, nil)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestProcessEndpoint(t *testing.T) {
+	tests := []struct {
+		desc      string
+		container corev1.Container
+		want      diagnostics.ProcessEndpoint
+		wantOK    bool
+	}{
+		{
+			desc: "controller with the default admin listener, bound to the loopback interface",
+			container: corev1.Container{
+				Args: []string{"controller", "--log-level=debug"},
+			},
+		},
+		{
+			desc: "controller listening on another address",
+			container: corev1.Container{
+				Command: []string{"/agent"},
+				Args:    []string{"controller", "--admin-listen-addr", "0.0.0.0:8091"},
+			},
+			want:   diagnostics.ProcessEndpoint{Scheme: "http", Port: "8091", Path: "/diagnostics"},
+			wantOK: true,
+		},
+		{
+			desc: "auth server listening on the address of its environment",
+			container: corev1.Container{
+				Args: []string{"auth-server"},
+				Env: []corev1.EnvVar{
+					{Name: "AUTH_SERVER_ADMIN_LISTEN_ADDR", Value: ":9191"},
+				},
+			},
+			want:   diagnostics.ProcessEndpoint{Scheme: "http", Port: "9191", Path: "/diagnostics"},
+			wantOK: true,
+		},
+		{
+			desc: "tunnel",
+			container: corev1.Container{
+				Args: []string{"tunnel", "--admin-listen-addr=:9292"},
+			},
+			want:   diagnostics.ProcessEndpoint{Scheme: "http", Port: "9292", Path: "/diagnostics"},
+			wantOK: true,
+		},
+		{
+			desc: "tunnel listening on localhost",
+			container: corev1.Container{
+				Args: []string{"tunnel", "--admin-listen-addr=localhost:9292"},
+			},
+		},
+		{
+			desc: "dev portal",
+			container: corev1.Container{
+				Args: []string{"dev-portal"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{test.container}},
+			}
+
+			got, ok := processEndpoint(pod)
+			assert.Equal(t, test.wantOK, ok)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
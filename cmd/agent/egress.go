@@ -0,0 +1,55 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"fmt"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagProxyURL         = "proxy-url"
+	flagPlatformCABundle = "platform-ca-bundle"
+)
+
+func egressFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagProxyURL,
+			Usage:   "URL of the proxy to reach the Hub platform through, the HTTPS_PROXY environment variable is used if empty",
+			EnvVars: []string{strcase.ToSNAKE(flagProxyURL)},
+		},
+		&cli.StringFlag{
+			Name:    flagPlatformCABundle,
+			Usage:   "Path to a PEM bundle of CAs trusted in addition to the system ones when reaching the Hub platform",
+			EnvVars: []string{strcase.ToSNAKE(flagPlatformCABundle)},
+		},
+	}
+}
+
+// newEgress creates the egress to reach the platform through, from the egress flags.
+func newEgress(cliCtx *cli.Context) (httpclient.Egress, error) {
+	egress, err := httpclient.NewEgress(cliCtx.String(flagProxyURL), cliCtx.String(flagPlatformCABundle))
+	if err != nil {
+		return httpclient.Egress{}, fmt.Errorf("create egress: %w", err)
+	}
+
+	return egress, nil
+}
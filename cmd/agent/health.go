@@ -0,0 +1,53 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/health"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/shutdown"
+)
+
+// newHealthChecker creates the checker of the readiness of a command, which isn't ready anymore once shutting down so
+// no new work is sent to it.
+func newHealthChecker(coordinator *shutdown.Coordinator) *health.Checker {
+	checker := health.NewChecker()
+	checker.AddCheck("shutdown", func(_ context.Context) error {
+		if coordinator.ShuttingDown() {
+			return errors.New("shutting down")
+		}
+
+		return nil
+	})
+
+	return checker
+}
+
+// platformReachable checks the platform is reachable, which it isn't while the circuit breaker of the client is open.
+func platformReachable(client *platform.Client) health.Check {
+	return func(_ context.Context) error {
+		if client.CircuitBreaker().State() == platform.BreakerOpen {
+			return platform.ErrCircuitOpen
+		}
+
+		return nil
+	}
+}
@@ -53,6 +53,11 @@ func run() error {
 			newTunnelCmd().build(),
 			newVersionCmd().build(),
 			newDevPortalCmd().build(),
+			newSoakCmd().build(),
+			newACPFixturesCmd().build(),
+			newVerifyCmd().build(),
+			newDiagnosticsCmd().build(),
+			newRefreshConfigCmd().build(),
 		},
 	}
 
@@ -77,5 +82,6 @@ func globalFlags() []cli.Flag {
 			Value:   "json",
 			Hidden:  true,
 		},
+		configFlag(),
 	}
 }
@@ -20,17 +20,30 @@ package main
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/clock"
 	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
 	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tokenfile"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology"
 )
 
-func newMetrics(watch *topology.Watcher, token, platformURL, traefikURL string, cfg platform.MetricsConfig, cfgWatcher *platform.ConfigWatcher) (*metrics.Manager, *metrics.Store, error) {
+// exportConfig configures the export of the metrics to an OpenTelemetry collector and to a Prometheus remote-write
+// endpoint. Headers are in the name=value format.
+type exportConfig struct {
+	OTLPEndpoint string
+	OTLPHeaders  []string
+
+	RemoteWriteURL     string
+	RemoteWriteHeaders []string
+}
+
+func newMetrics(watch *topology.Watcher, token string, tokens *tokenfile.Watcher, platformURL, traefikURL string, cfg platform.MetricsConfig, export exportConfig, cfgWatcher *platform.ConfigWatcher, skew *clock.Skew) (*metrics.Manager, *metrics.Store, error) {
 	rc := retryablehttp.NewClient()
 	rc.RetryWaitMin = time.Second
 	rc.RetryWaitMax = 10 * time.Second
@@ -43,6 +56,7 @@ func newMetrics(watch *topology.Watcher, token, platformURL, traefikURL string,
 	if err != nil {
 		return nil, nil, err
 	}
+	tokens.AddListener(client.SetToken)
 
 	u, err := url.ParseRequestURI(traefikURL)
 	if err != nil {
@@ -57,9 +71,37 @@ func newMetrics(watch *topology.Watcher, token, platformURL, traefikURL string,
 	scraper := metrics.NewScraper(httpClient)
 
 	mgr := metrics.NewManager(client, traefikURL, store, scraper)
+	// Data points are timestamped with the platform clock, which they are sent to.
+	mgr.SetClock(skew.Now)
 
 	mgr.SetConfig(cfg.Interval, cfg.Tables)
 
+	if export.OTLPEndpoint != "" {
+		headers, err := parseHeaders(export.OTLPHeaders)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse OTLP headers: %w", err)
+		}
+
+		exporter, err := metrics.NewOTLPExporter(httpClient, export.OTLPEndpoint, headers)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create OTLP exporter: %w", err)
+		}
+		mgr.AddExporter(exporter)
+	}
+
+	if export.RemoteWriteURL != "" {
+		headers, err := parseHeaders(export.RemoteWriteHeaders)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse remote-write headers: %w", err)
+		}
+
+		exporter, err := metrics.NewRemoteWriteExporter(httpClient, export.RemoteWriteURL, headers)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create remote-write exporter: %w", err)
+		}
+		mgr.AddExporter(exporter)
+	}
+
 	watch.AddListener(mgr.TopologyStateChanged)
 
 	cfgWatcher.AddListener(func(cfg platform.Config) {
@@ -68,3 +110,16 @@ func newMetrics(watch *topology.Watcher, token, platformURL, traefikURL string,
 
 	return mgr, store, nil
 }
+
+func parseHeaders(rawHeaders []string) (map[string]string, error) {
+	headers := make(map[string]string, len(rawHeaders))
+	for _, header := range rawHeaders {
+		name, value, ok := strings.Cut(header, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q, expected name=value", header)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return headers, nil
+}
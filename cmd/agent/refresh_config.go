@@ -0,0 +1,252 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ettle/strcase"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/install"
+	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhook"
+	"github.com/urfave/cli/v2"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	kclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	flagRefreshConfigKubeconfig     = "kubeconfig"
+	flagRefreshConfigNamespace      = "namespace"
+	flagRefreshConfigServiceAccount = "service-account"
+	flagRefreshConfigDryRun         = "dry-run"
+)
+
+type refreshConfigCmd struct {
+	flags []cli.Flag
+}
+
+func newRefreshConfigCmd() refreshConfigCmd {
+	flgs := []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagRefreshConfigKubeconfig,
+			Usage:   "Path to the kubeconfig of the cluster running the agent, defaults to the standard kubeconfig loading rules",
+			EnvVars: []string{"KUBECONFIG"},
+		},
+		&cli.StringFlag{
+			Name:    flagRefreshConfigNamespace,
+			Usage:   "Namespace of the agent",
+			EnvVars: []string{"REFRESH_CONFIG_" + strcase.ToSNAKE(flagRefreshConfigNamespace)},
+			Value:   "hub-agent",
+		},
+		&cli.StringFlag{
+			Name:    flagRefreshConfigServiceAccount,
+			Usage:   "Name of the service account of the controller, bound to the ClusterRole managing the hub.traefik.io resources",
+			EnvVars: []string{"REFRESH_CONFIG_" + strcase.ToSNAKE(flagRefreshConfigServiceAccount)},
+			Value:   "hub-agent-controller",
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerServiceName,
+			Usage:   "Name of the Service the API server reaches the ACP server through, whose webhooks get their CA bundle refreshed",
+			EnvVars: []string{"REFRESH_CONFIG_" + strcase.ToSNAKE(flagACPServerServiceName)},
+			Value:   "hub-agent-controller",
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerCertSecret,
+			Usage:   "Name of the Secret storing the certificate of the ACP server when it is managed by the agent",
+			EnvVars: []string{"REFRESH_CONFIG_" + strcase.ToSNAKE(flagACPServerCertSecret)},
+			Value:   "hub-agent-webhook-certificate",
+		},
+		&cli.BoolFlag{
+			Name:    flagRefreshConfigDryRun,
+			Usage:   "Print the diff of the changes which would be made, without making them",
+			EnvVars: []string{"REFRESH_CONFIG_" + strcase.ToSNAKE(flagRefreshConfigDryRun)},
+		},
+	}
+
+	flgs = append(flgs, globalFlags()...)
+
+	return refreshConfigCmd{
+		flags: flgs,
+	}
+}
+
+func (c refreshConfigCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "refresh-config",
+		Usage:  "Refreshes the webhook CA bundles, the hub.traefik.io CRDs and their RBAC objects to match this agent version",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c refreshConfigCmd) run(cliCtx *cli.Context) error {
+	// The configuration is only read once, as the command exits before it could be reloaded.
+	if _, err := loadConfigFile(cliCtx); err != nil {
+		return err
+	}
+
+	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = cliCtx.String(flagRefreshConfigKubeconfig)
+
+	kubeCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("load kubeconfig: %w", err)
+	}
+
+	kubeClientSet, err := kclientset.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes client set: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create dynamic client: %w", err)
+	}
+
+	namespace := cliCtx.String(flagRefreshConfigNamespace)
+	dryRun := cliCtx.Bool(flagRefreshConfigDryRun)
+
+	manager := install.NewManager(dynamicClient, version.Version())
+	manager.SetRBAC(install.RBACConfig{
+		ServiceAccountNamespace: namespace,
+		ServiceAccountName:      cliCtx.String(flagRefreshConfigServiceAccount),
+	})
+
+	var changes []install.Change
+	if dryRun {
+		changes, err = manager.Plan(cliCtx.Context)
+	} else {
+		err = manager.Run(cliCtx.Context)
+	}
+	if err != nil {
+		return fmt.Errorf("refresh CRDs and RBAC: %w", err)
+	}
+
+	// The CA bundles are refreshed after the CRDs, whose conversion webhooks may call the agent.
+	certManager := webhook.NewCertificateManager(kubeClientSet, dynamicClient, webhook.CertificateManagerConfig{
+		Namespace:   namespace,
+		ServiceName: cliCtx.String(flagACPServerServiceName),
+		SecretName:  cliCtx.String(flagACPServerCertSecret),
+	})
+
+	patches, err := certManager.RefreshCABundles(cliCtx.Context, dryRun)
+	switch {
+	case kerror.IsNotFound(err):
+		log.Info().
+			Str("secret", cliCtx.String(flagACPServerCertSecret)).
+			Msg("Webhook certificate not managed by the agent, CA bundles not refreshed")
+	case err != nil:
+		return fmt.Errorf("refresh webhook CA bundles: %w", err)
+	}
+
+	if !dryRun {
+		return nil
+	}
+
+	return printRefreshDiff(os.Stdout, changes, patches)
+}
+
+// printRefreshDiff prints the unified diff of each of the given changes.
+func printRefreshDiff(w io.Writer, changes []install.Change, patches []webhook.CABundlePatch) error {
+	var b strings.Builder
+
+	for _, change := range changes {
+		diff, err := changeDiff(change)
+		if err != nil {
+			return fmt.Errorf("diff %s %q: %w", change.Desired.GetKind(), change.Desired.GetName(), err)
+		}
+
+		writeDiff(&b, change.Desired.GetKind(), change.Desired.GetName(), diff)
+	}
+
+	for _, patch := range patches {
+		diff, err := admission.PatchDiff(patch.Object, patch.Patch)
+		if err != nil {
+			return fmt.Errorf("diff %s %q: %w", patch.Kind, patch.Name, err)
+		}
+
+		writeDiff(&b, patch.Kind, patch.Name, diff)
+	}
+
+	if b.Len() == 0 {
+		b.WriteString("No changes\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeDiff(b *strings.Builder, kind, name, diff string) {
+	fmt.Fprintf(b, "%s %s\n", kind, name)
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(diff, "\n"), "\n") {
+		b.WriteString("  " + line)
+	}
+	b.WriteString("\n\n")
+}
+
+// changeDiff returns the unified diff of the given change, the fields set by the API server being left out.
+func changeDiff(change install.Change) (string, error) {
+	// Resources to install are diffed against nothing.
+	var current []string
+	if change.Current != nil {
+		raw, err := indentObject(change.Current)
+		if err != nil {
+			return "", err
+		}
+		current = difflib.SplitLines(raw)
+	}
+
+	desired, err := indentObject(change.Desired)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        current,
+		B:        difflib.SplitLines(desired),
+		FromFile: "current",
+		ToFile:   "patched",
+		Context:  3,
+	})
+}
+
+func indentObject(obj *unstructured.Unstructured) (string, error) {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	b, err := json.MarshalIndent(obj.Object, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
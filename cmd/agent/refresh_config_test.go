@@ -0,0 +1,85 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/install"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhook"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPrintRefreshDiff(t *testing.T) {
+	current := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRole",
+		"metadata": map[string]interface{}{
+			"name":          "hub-agent-crds-view",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{"apiGroups": []interface{}{"hub.traefik.io"}, "resources": []interface{}{"apis"}},
+		},
+	}}
+	desired := current.DeepCopy()
+	require.NoError(t, unstructured.SetNestedSlice(desired.Object, []interface{}{
+		map[string]interface{}{"apiGroups": []interface{}{"hub.traefik.io"}, "resources": []interface{}{"apis", "apiversions"}},
+	}, "rules"))
+
+	binding := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRoleBinding",
+		"metadata":   map[string]interface{}{"name": "hub-agent-crds"},
+	}}
+
+	changes := []install.Change{
+		{Current: current, Desired: desired, Fields: []string{"rules"}},
+		{Desired: binding},
+	}
+	patches := []webhook.CABundlePatch{
+		{
+			Kind:   "MutatingWebhookConfiguration",
+			Name:   "hub-acp",
+			Object: []byte(`{"metadata":{"name":"hub-acp"},"webhooks":[{"clientConfig":{"caBundle":"b2xk"}}]}`),
+			Patch:  []byte(`[{"op":"add","path":"/webhooks/0/clientConfig/caBundle","value":"bmV3"}]`),
+		},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, printRefreshDiff(&b, changes, patches))
+
+	out := b.String()
+	assert.Contains(t, out, "ClusterRole hub-agent-crds-view\n  --- current\n  +++ patched\n")
+	assert.Contains(t, out, `  +        "apiversions"`)
+	assert.NotContains(t, out, "managedFields")
+	assert.Contains(t, out, "ClusterRoleBinding hub-agent-crds\n  --- current\n  +++ patched\n  @@ -0,0 +1,7 @@\n")
+	assert.Contains(t, out, `  +  "kind": "ClusterRoleBinding",`)
+	assert.Contains(t, out, "MutatingWebhookConfiguration hub-acp\n")
+	assert.Contains(t, out, `"caBundle": "bmV3"`)
+}
+
+func TestPrintRefreshDiff_noChanges(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, printRefreshDiff(&b, nil, nil))
+
+	assert.Equal(t, "No changes\n", b.String())
+}
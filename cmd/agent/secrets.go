@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/secretref"
+	"github.com/urfave/cli/v2"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	flagSecretBackend           = "secret-backend"
+	flagSecretBackendVaultAddr  = "secret-backend.vault.address"
+	flagSecretBackendVaultToken = "secret-backend.vault.token"
+	flagSecretBackendVaultMount = "secret-backend.vault.mount"
+	flagSecretBackendVaultPath  = "secret-backend.vault.path-prefix"
+	flagSecretBackendAWSRegion  = "secret-backend.aws.region"
+	flagSecretBackendAWSPrefix  = "secret-backend.aws.prefix"
+)
+
+// Secret backends.
+const (
+	secretBackendKubernetes        = "kubernetes"
+	secretBackendVault             = "vault"
+	secretBackendAWSSecretsManager = "aws-secrets-manager"
+)
+
+// secretBackendFlags returns the flags configuring where the secrets referenced by hub resources are read from, whose
+// environment variables are prefixed with the given prefix.
+func secretBackendFlags(envPrefix string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagSecretBackend,
+			Usage:   "Backend the secrets referenced by hub resources are read from: kubernetes, vault or aws-secrets-manager",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagSecretBackend)},
+			Value:   secretBackendKubernetes,
+		},
+		&cli.StringFlag{
+			Name:    flagSecretBackendVaultAddr,
+			Usage:   "Address of the Vault server",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagSecretBackendVaultAddr), "VAULT_ADDR"},
+		},
+		&cli.StringFlag{
+			Name:    flagSecretBackendVaultToken,
+			Usage:   "Token used to authenticate to the Vault server",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagSecretBackendVaultToken), "VAULT_TOKEN"},
+		},
+		&cli.StringFlag{
+			Name:    flagSecretBackendVaultMount,
+			Usage:   "Mount path of the Vault KV version 2 secrets engine",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagSecretBackendVaultMount)},
+			Value:   "secret",
+		},
+		&cli.StringFlag{
+			Name:    flagSecretBackendVaultPath,
+			Usage:   "Path prepended to the <namespace>/<name> path of the secrets in Vault",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagSecretBackendVaultPath)},
+		},
+		&cli.StringFlag{
+			Name:    flagSecretBackendAWSRegion,
+			Usage:   "Region of AWS Secrets Manager, the credentials are read from the standard AWS environment variables",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagSecretBackendAWSRegion), "AWS_REGION"},
+		},
+		&cli.StringFlag{
+			Name:    flagSecretBackendAWSPrefix,
+			Usage:   "Prefix prepended to the <namespace>/<name> identifier of the secrets in AWS Secrets Manager",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagSecretBackendAWSPrefix)},
+		},
+	}
+}
+
+// newSecretProvider creates the provider of the secrets referenced by hub resources configured by the secret backend
+// flags, nil when they are read from Kubernetes.
+func newSecretProvider(cliCtx *cli.Context) (secretref.Provider, error) {
+	switch backend := cliCtx.String(flagSecretBackend); backend {
+	case "", secretBackendKubernetes:
+		return nil, nil
+	case secretBackendVault:
+		provider, err := secretref.NewVaultProvider(secretref.VaultConfig{
+			Address:    cliCtx.String(flagSecretBackendVaultAddr),
+			Token:      cliCtx.String(flagSecretBackendVaultToken),
+			Mount:      cliCtx.String(flagSecretBackendVaultMount),
+			PathPrefix: cliCtx.String(flagSecretBackendVaultPath),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create vault secret provider: %w", err)
+		}
+
+		return provider, nil
+	case secretBackendAWSSecretsManager:
+		provider, err := secretref.NewAWSSecretsManagerProvider(secretref.AWSSecretsManagerConfig{
+			Region:          cliCtx.String(flagSecretBackendAWSRegion),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Prefix:          cliCtx.String(flagSecretBackendAWSPrefix),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create aws secrets manager secret provider: %w", err)
+		}
+
+		return provider, nil
+	default:
+		return nil, fmt.Errorf("unsupported secret backend %q", backend)
+	}
+}
+
+// newSecretResolver creates a resolver reading secrets from the given provider, or from the given Kubernetes secrets
+// if it is nil. The secrets managed by the agent itself are always read from Kubernetes.
+func newSecretResolver(provider secretref.Provider, secrets corev1lister.SecretLister) *secretref.Resolver {
+	if provider == nil {
+		return secretref.NewResolver(secrets)
+	}
+
+	hubSecret := secretref.Ref{Namespace: currentNamespace(), Name: "hub-secret"}
+
+	return secretref.NewResolverWithProvider(secretref.NewAgentSecretsProvider(provider, secrets, hubSecret))
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/shutdown"
+	"github.com/urfave/cli/v2"
+)
+
+const flagShutdownGracePeriod = "shutdown-grace-period"
+
+func shutdownFlags(envPrefix string, gracePeriod time.Duration) []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:    flagShutdownGracePeriod,
+			Usage:   "How long the command is given, once asked to stop, to complete its in-flight work and flush its pending work",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagShutdownGracePeriod)},
+			Value:   gracePeriod,
+		},
+	}
+}
+
+// newShutdownCoordinator creates the coordinator of the graceful shutdown of a command, from the shutdown flags.
+func newShutdownCoordinator(cliCtx *cli.Context) (*shutdown.Coordinator, error) {
+	gracePeriod := cliCtx.Duration(flagShutdownGracePeriod)
+	if gracePeriod <= 0 {
+		return nil, fmt.Errorf("flag %q must be positive", flagShutdownGracePeriod)
+	}
+
+	return shutdown.NewCoordinator(gracePeriod), nil
+}
+
+// runShutdown runs the graceful shutdown of a command. A shutdown which didn't complete cleanly is reported but doesn't
+// fail the command, which was asked to stop anyway.
+func runShutdown(coordinator *shutdown.Coordinator) {
+	if err := coordinator.Shutdown(); err != nil {
+		log.Error().Err(err).Msg("Unable to shut down gracefully")
+	}
+}
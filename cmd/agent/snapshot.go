@@ -0,0 +1,129 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+	"github.com/traefik/hub-agent-kubernetes/pkg/optional"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/snapshot"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagSnapshotEndpoint          = "topology.snapshot.endpoint"
+	flagSnapshotBucket            = "topology.snapshot.bucket"
+	flagSnapshotRegion            = "topology.snapshot.region"
+	flagSnapshotPrefix            = "topology.snapshot.prefix"
+	flagSnapshotAccessKeyID       = "topology.snapshot.access-key-id"
+	flagSnapshotSecretAccessKey   = "topology.snapshot.secret-access-key"
+	flagSnapshotEncryptionKeyFile = "topology.snapshot.encryption-key-file"
+	flagSnapshotInterval          = "topology.snapshot.interval"
+)
+
+func snapshotFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagSnapshotEndpoint,
+			Usage:   "URL of the S3-compatible object storage topology snapshots are uploaded to, snapshots are disabled if empty",
+			EnvVars: []string{strcase.ToSNAKE(flagSnapshotEndpoint)},
+		},
+		&cli.StringFlag{
+			Name:    flagSnapshotBucket,
+			Usage:   "Bucket topology snapshots are uploaded to",
+			EnvVars: []string{strcase.ToSNAKE(flagSnapshotBucket)},
+		},
+		&cli.StringFlag{
+			Name:    flagSnapshotRegion,
+			Usage:   "Region of the bucket topology snapshots are uploaded to",
+			EnvVars: []string{strcase.ToSNAKE(flagSnapshotRegion)},
+			Value:   "us-east-1",
+		},
+		&cli.StringFlag{
+			Name:    flagSnapshotPrefix,
+			Usage:   "Prefix of the keys of the uploaded topology snapshots",
+			EnvVars: []string{strcase.ToSNAKE(flagSnapshotPrefix)},
+		},
+		&cli.StringFlag{
+			Name:    flagSnapshotAccessKeyID,
+			Usage:   "Access key ID used to upload topology snapshots",
+			EnvVars: []string{strcase.ToSNAKE(flagSnapshotAccessKeyID)},
+		},
+		&cli.StringFlag{
+			Name:    flagSnapshotSecretAccessKey,
+			Usage:   "Secret access key used to upload topology snapshots",
+			EnvVars: []string{strcase.ToSNAKE(flagSnapshotSecretAccessKey)},
+		},
+		&cli.StringFlag{
+			Name:    flagSnapshotEncryptionKeyFile,
+			Usage:   "File containing the base64 encoded AES-256 key topology snapshots are encrypted with",
+			EnvVars: []string{strcase.ToSNAKE(flagSnapshotEncryptionKeyFile)},
+		},
+		&cli.DurationFlag{
+			Name:    flagSnapshotInterval,
+			Usage:   "Interval at which topology snapshots are uploaded",
+			EnvVars: []string{strcase.ToSNAKE(flagSnapshotInterval)},
+			Value:   time.Hour,
+		},
+	}
+}
+
+// newSnapshotUploader creates the uploader of topology snapshots, or returns nil if no object storage is configured.
+func newSnapshotUploader(cliCtx *cli.Context) (*snapshot.Uploader, error) {
+	endpoint := cliCtx.String(flagSnapshotEndpoint)
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	keyFile := cliCtx.String(flagSnapshotEncryptionKeyFile)
+	if keyFile == "" {
+		return nil, fmt.Errorf("flag %q is required to upload topology snapshots", flagSnapshotEncryptionKeyFile)
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read encryption key: %w", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+
+	client, err := httpclient.New(httpclient.Config{TimeoutSeconds: optional.NewInt(30)})
+	if err != nil {
+		return nil, fmt.Errorf("create HTTP client: %w", err)
+	}
+
+	return snapshot.NewUploader(client, snapshot.Config{
+		Endpoint:        endpoint,
+		Bucket:          cliCtx.String(flagSnapshotBucket),
+		Region:          cliCtx.String(flagSnapshotRegion),
+		Prefix:          cliCtx.String(flagSnapshotPrefix),
+		AccessKeyID:     cliCtx.String(flagSnapshotAccessKeyID),
+		SecretAccessKey: cliCtx.String(flagSnapshotSecretAccessKey),
+		EncryptionKey:   key,
+		Interval:        cliCtx.Duration(flagSnapshotInterval),
+	})
+}
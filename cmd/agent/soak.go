@@ -0,0 +1,239 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/soak"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"github.com/urfave/cli/v2"
+	kclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	flagSoakKubeconfig       = "kubeconfig"
+	flagSoakNamespace        = "namespace"
+	flagSoakIngressClassName = "ingress-class-name"
+	flagSoakAPIs             = "apis"
+	flagSoakResources        = "resources"
+	flagSoakStep             = "step"
+	flagSoakStepInterval     = "step-interval"
+	flagSoakSampleInterval   = "sample-interval"
+	flagSoakDuration         = "duration"
+	flagSoakAgentNamespace   = "agent-namespace"
+	flagSoakAgentSelector    = "agent-selector"
+	flagSoakReport           = "report"
+	flagSoakKeep             = "keep"
+)
+
+type soakCmd struct {
+	flags []cli.Flag
+}
+
+func newSoakCmd() soakCmd {
+	flgs := []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagSoakKubeconfig,
+			Usage:   "Path to the kubeconfig of the cluster running the agent, defaults to the standard kubeconfig loading rules",
+			EnvVars: []string{"KUBECONFIG"},
+		},
+		&cli.StringFlag{
+			Name:    flagSoakNamespace,
+			Usage:   "Namespace in which synthetic resources are generated, deleted at the end of the test",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakNamespace)},
+			Value:   "hub-soak",
+		},
+		&cli.StringFlag{
+			Name:    flagSoakIngressClassName,
+			Usage:   "Ingress class of the synthetic ingresses",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakIngressClassName)},
+		},
+		&cli.BoolFlag{
+			Name:    flagSoakAPIs,
+			Usage:   "Generate an API for each synthetic service, requires the API management CRDs",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakAPIs)},
+		},
+		&cli.IntFlag{
+			Name:    flagSoakResources,
+			Usage:   "Total number of synthetic services, ingresses and APIs generated",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakResources)},
+			Value:   100,
+		},
+		&cli.IntFlag{
+			Name:    flagSoakStep,
+			Usage:   "Number of synthetic services, ingresses and APIs generated at each step",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakStep)},
+			Value:   10,
+		},
+		&cli.DurationFlag{
+			Name:    flagSoakStepInterval,
+			Usage:   "Interval between two generation steps",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakStepInterval)},
+			Value:   time.Minute,
+		},
+		&cli.DurationFlag{
+			Name:    flagSoakSampleInterval,
+			Usage:   "Interval between two measurements",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakSampleInterval)},
+			Value:   30 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagSoakDuration,
+			Usage:   "Duration of the soak test",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakDuration)},
+			Value:   time.Hour,
+		},
+		&cli.StringFlag{
+			Name:    flagSoakAgentNamespace,
+			Usage:   "Namespace of the agent pods",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakAgentNamespace)},
+			Value:   "hub-agent",
+		},
+		&cli.StringFlag{
+			Name:    flagSoakAgentSelector,
+			Usage:   "Label selector of the agent pods whose resource usage is measured",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakAgentSelector)},
+			Value:   "app=hub-agent-controller",
+		},
+		&cli.StringFlag{
+			Name:    flagSoakReport,
+			Usage:   "Path of the JSON report written at the end of the test, defaults to the standard output",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakReport)},
+		},
+		&cli.BoolFlag{
+			Name:    flagSoakKeep,
+			Usage:   "Keep the synthetic resources at the end of the test",
+			EnvVars: []string{"SOAK_" + strcase.ToSNAKE(flagSoakKeep)},
+		},
+	}
+
+	flgs = append(flgs, globalFlags()...)
+
+	return soakCmd{
+		flags: flgs,
+	}
+}
+
+func (c soakCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "soak",
+		Usage:  "Generates synthetic load on a cluster running the agent and measures the agent behavior over time",
+		Flags:  c.flags,
+		Action: c.run,
+		Hidden: true,
+	}
+}
+
+func (c soakCmd) run(cliCtx *cli.Context) error {
+	cfgFile, err := loadConfigFile(cliCtx)
+	if err != nil {
+		return err
+	}
+	go cfgFile.Run(cliCtx.Context)
+
+	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = cliCtx.String(flagSoakKubeconfig)
+
+	kubeCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("load kubeconfig: %w", err)
+	}
+
+	kubeClientSet, err := kclientset.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes client set: %w", err)
+	}
+
+	traefikClientSet, err := traefikclientset.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create Traefik client set: %w", err)
+	}
+
+	hubClientSet, err := hubclientset.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create Traefik Hub client set: %w", err)
+	}
+
+	topoFetcher, err := state.NewFetcher(cliCtx.Context, kubeClientSet, traefikClientSet, hubClientSet, state.NamespaceFilter{}, state.RedactionFilter{})
+	if err != nil {
+		return fmt.Errorf("create topology fetcher: %w", err)
+	}
+
+	generator := soak.NewGenerator(kubeClientSet, hubClientSet,
+		cliCtx.String(flagSoakNamespace), cliCtx.String(flagSoakIngressClassName), cliCtx.Bool(flagSoakAPIs))
+	usage := soak.NewUsageSampler(kubeClientSet, cliCtx.String(flagSoakAgentNamespace), cliCtx.String(flagSoakAgentSelector))
+	runner := soak.NewRunner(generator, usage, soak.NewTopologySampler(topoFetcher))
+
+	report, runErr := runner.Run(cliCtx.Context, soak.Config{
+		Resources:      cliCtx.Int(flagSoakResources),
+		Step:           cliCtx.Int(flagSoakStep),
+		StepInterval:   cliCtx.Duration(flagSoakStepInterval),
+		SampleInterval: cliCtx.Duration(flagSoakSampleInterval),
+		Duration:       cliCtx.Duration(flagSoakDuration),
+	})
+	if runErr != nil {
+		log.Error().Err(runErr).Msg("Soak test failed")
+	}
+
+	if !cliCtx.Bool(flagSoakKeep) {
+		// The command context may be canceled already, cleanup must still happen.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if err = runner.Cleanup(ctx); err != nil {
+			log.Error().Err(err).Msg("Unable to clean up synthetic resources")
+		}
+	}
+
+	if err = writeSoakReport(cliCtx.String(flagSoakReport), report); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	return runErr
+}
+
+func writeSoakReport(path string, report soak.Report) error {
+	out := os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create report file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(report)
+}
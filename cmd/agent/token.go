@@ -0,0 +1,56 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tokenfile"
+	"github.com/urfave/cli/v2"
+)
+
+const flagTokenFile = "token-file"
+
+// tokenFileCheckInterval is the interval at which the token file is checked for a rotated token. Mounted Secrets are
+// themselves refreshed by the kubelet about every minute.
+const tokenFileCheckInterval = 10 * time.Second
+
+func tokenFileFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    flagTokenFile,
+		Usage:   "Path to a file holding the token to use for Hub platform API calls, watched for rotations, takes precedence over the token flag",
+		EnvVars: []string{strcase.ToSNAKE(flagTokenFile)},
+	}
+}
+
+// loadToken returns the token to use for Hub platform API calls. When it's read from the token file, the returned
+// watcher must be run to pick up the rotated tokens, it's nil otherwise.
+func loadToken(cliCtx *cli.Context) (string, *tokenfile.Watcher, error) {
+	path := cliCtx.String(flagTokenFile)
+	if path == "" {
+		return cliCtx.String(flagToken), nil, nil
+	}
+
+	tokens, err := tokenfile.NewWatcher(path, tokenFileCheckInterval)
+	if err != nil {
+		return "", nil, fmt.Errorf("create token file watcher: %w", err)
+	}
+
+	return tokens.Token(), tokens, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"fmt"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tracing"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagTracingOTLPEndpoint = "tracing.otlp-endpoint"
+	flagTracingOTLPHeaders  = "tracing.otlp-headers"
+	flagTracingSampleRatio  = "tracing.sample-ratio"
+)
+
+// tracingFlags returns the flags configuring tracing, whose environment variables are prefixed with the given prefix.
+func tracingFlags(envPrefix string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagTracingOTLPEndpoint,
+			Usage:   "OTLP HTTP endpoint of an OpenTelemetry collector the traces are exported to, tracing is disabled if empty",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagTracingOTLPEndpoint)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagTracingOTLPHeaders,
+			Usage:   "Headers sent with the traces exported to the OTLP endpoint, in the name=value format",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagTracingOTLPHeaders)},
+		},
+		&cli.Float64Flag{
+			Name:    flagTracingSampleRatio,
+			Usage:   "Ratio of the traces started by the agent which are sampled, traces started by a caller follow its sampling decision",
+			EnvVars: []string{envPrefix + strcase.ToSNAKE(flagTracingSampleRatio)},
+			Value:   1,
+		},
+	}
+}
+
+// newTracer creates the tracer configured by the tracing flags, nil if tracing is disabled.
+func newTracer(cliCtx *cli.Context, serviceName string) (*tracing.Tracer, error) {
+	endpoint := cliCtx.String(flagTracingOTLPEndpoint)
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	headers, err := parseHeaders(cliCtx.StringSlice(flagTracingOTLPHeaders))
+	if err != nil {
+		return nil, fmt.Errorf("parse OTLP headers: %w", err)
+	}
+
+	tracer, err := tracing.NewTracer(tracing.Config{
+		Endpoint:    endpoint,
+		Headers:     headers,
+		ServiceName: serviceName,
+		SampleRatio: cliCtx.Float64(flagTracingSampleRatio),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create tracer: %w", err)
+	}
+
+	return tracer, nil
+}
@@ -18,11 +18,24 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	stdlog "log"
 	"net"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/ettle/strcase"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/shutdown"
 	"github.com/traefik/hub-agent-kubernetes/pkg/tunnel"
 	"github.com/urfave/cli/v2"
 )
@@ -32,10 +45,23 @@ type tunnelCmd struct {
 }
 
 const (
-	flagTraefikTunnelHost = "traefik.tunnel-host"
-	flagTraefikTunnelPort = "traefik.tunnel-port"
+	flagTraefikTunnelHost           = "traefik.tunnel-host"
+	flagTraefikTunnelPort           = "traefik.tunnel-port"
+	flagTraefikTunnelUDPEntryPoints = "traefik.tunnel-udp-entry-points"
+	flagTunnelConnections           = "tunnel.connections"
+	flagTunnelDrainTimeout          = "tunnel.drain-timeout"
+	flagTunnelMaxStreams            = "tunnel.max-streams"
+	flagTunnelBandwidth             = "tunnel.bandwidth"
+	flagTunnelEdgeIngressMaxStreams = "tunnel.edge-ingress-max-streams"
+	flagTunnelEdgeIngressBandwidth  = "tunnel.edge-ingress-bandwidth"
+	flagTunnelStreamIdleTimeout     = "tunnel.stream-idle-timeout"
+	flagTunnelQUIC                  = "tunnel.quic"
 )
 
+// tunnelShutdownGracePeriod is the default shutdown grace period of the tunnel, leaving the default drain timeout enough
+// time to elapse.
+const tunnelShutdownGracePeriod = 40 * time.Second
+
 func newTunnelCmd() tunnelCmd {
 	flags := []cli.Flag{
 		&cli.StringFlag{
@@ -46,11 +72,11 @@ func newTunnelCmd() tunnelCmd {
 			Hidden:  true,
 		},
 		&cli.StringFlag{
-			Name:     flagToken,
-			Usage:    "The token to use for Hub platform API calls",
-			EnvVars:  []string{strcase.ToSNAKE(flagToken)},
-			Required: true,
+			Name:    flagToken,
+			Usage:   "The token to use for Hub platform API calls, required unless reading it from a file",
+			EnvVars: []string{strcase.ToSNAKE(flagToken)},
 		},
+		tokenFileFlag(),
 		&cli.StringFlag{
 			Name:     flagTraefikTunnelHost,
 			Usage:    "The Traefik tunnel host",
@@ -64,9 +90,65 @@ func newTunnelCmd() tunnelCmd {
 			Value:    "9901",
 			Required: false,
 		},
+		&cli.StringSliceFlag{
+			Name:    flagTraefikTunnelUDPEntryPoints,
+			Usage:   "The Traefik UDP entry points receiving the traffic of UDP EdgeIngresses, as name=host:port",
+			EnvVars: []string{strcase.ToSNAKE(flagTraefikTunnelUDPEntryPoints)},
+		},
+		&cli.IntFlag{
+			Name:    flagTunnelConnections,
+			Usage:   "Number of connections opened for each tunnel, spread over its brokers",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelConnections)},
+			Value:   1,
+		},
+		&cli.DurationFlag{
+			Name:    flagTunnelDrainTimeout,
+			Usage:   "How long the connections of a closed tunnel are kept open for the ongoing requests to complete",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelDrainTimeout)},
+			Value:   30 * time.Second,
+		},
+		&cli.BoolFlag{
+			Name:    flagTunnelQUIC,
+			Usage:   "Open the tunnel connections over QUIC to the brokers supporting it, falling back to WebSocket otherwise",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelQUIC)},
+		},
+		&cli.DurationFlag{
+			Name:    flagTunnelStreamIdleTimeout,
+			Usage:   "How long a stream can go without traffic before being closed, 0 to keep idle streams such as WebSockets open",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelStreamIdleTimeout)},
+		},
+		&cli.IntFlag{
+			Name:    flagTunnelMaxStreams,
+			Usage:   "Maximum number of streams proxied at once through a tunnel, 0 for no limit",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelMaxStreams)},
+		},
+		&cli.Int64Flag{
+			Name:    flagTunnelBandwidth,
+			Usage:   "Maximum number of bytes per second going through a tunnel in each direction, 0 for no limit",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelBandwidth)},
+		},
+		&cli.IntFlag{
+			Name:    flagTunnelEdgeIngressMaxStreams,
+			Usage:   "Maximum number of streams proxied at once through a tunnel for an edge ingress, 0 for no limit",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelEdgeIngressMaxStreams)},
+		},
+		&cli.Int64Flag{
+			Name:    flagTunnelEdgeIngressBandwidth,
+			Usage:   "Maximum number of bytes per second going through a tunnel for an edge ingress in each direction, 0 for no limit",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelEdgeIngressBandwidth)},
+		},
+		&cli.StringFlag{
+			Name:    flagMetricsListenAddr,
+			Usage:   "Address on which the tunnel exposes its Prometheus metrics and health checks",
+			EnvVars: []string{"TUNNEL_METRICS_LISTEN_ADDR"},
+			Value:   "0.0.0.0:9090",
+		},
 	}
 
 	flags = append(flags, globalFlags()...)
+	flags = append(flags, egressFlags()...)
+	flags = append(flags, shutdownFlags("", tunnelShutdownGracePeriod)...)
+	flags = append(flags, adminFlags("TUNNEL_")...)
 
 	return tunnelCmd{
 		flags: flags,
@@ -83,21 +165,176 @@ func (c tunnelCmd) build() *cli.Command {
 }
 
 func (c tunnelCmd) run(cliCtx *cli.Context) error {
+	cfgFile, err := loadConfigFile(cliCtx)
+	if err != nil {
+		return err
+	}
+	go cfgFile.Run(cliCtx.Context)
+
 	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
 
 	ctx := cliCtx.Context
 
 	platformURL := cliCtx.String(flagPlatformURL)
-	token := cliCtx.String(flagToken)
+	token, tokens, err := loadToken(cliCtx)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("flag %q or %q is required", flagToken, flagTokenFile)
+	}
 
-	tunnelClient, err := tunnel.NewClient(platformURL, token)
+	egress, err := newEgress(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	tunnelClient, err := tunnel.NewClient(platformURL, token, egress)
 	if err != nil {
 		return fmt.Errorf("create tunnel client: %w", err)
 	}
 
+	udpEntryPoints, err := parseUDPEntryPoints(cliCtx.StringSlice(flagTraefikTunnelUDPEntryPoints))
+	if err != nil {
+		return fmt.Errorf("parse %q: %w", flagTraefikTunnelUDPEntryPoints, err)
+	}
+
 	traefikAddr := net.JoinHostPort(cliCtx.String(flagTraefikTunnelHost), cliCtx.String(flagTraefikTunnelPort))
-	tunnelManager := tunnel.NewManager(tunnelClient, traefikAddr, token)
-	tunnelManager.Run(ctx)
+	tunnelManager := tunnel.NewManager(tunnelClient, traefikAddr, token, egress)
+	tunnelManager.SetUDPEntryPoints(udpEntryPoints)
+
+	connections := cliCtx.Int(flagTunnelConnections)
+	if connections < 1 {
+		return fmt.Errorf("flag %q must be at least 1", flagTunnelConnections)
+	}
+	tunnelManager.SetConnections(connections)
+	coordinator, err := newShutdownCoordinator(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	drainTimeout := cliCtx.Duration(flagTunnelDrainTimeout)
+	if drainTimeout > cliCtx.Duration(flagShutdownGracePeriod) {
+		log.Warn().
+			Dur("drain_timeout", drainTimeout).
+			Dur("grace_period", cliCtx.Duration(flagShutdownGracePeriod)).
+			Msg("The tunnels drain timeout exceeds the shutdown grace period, the drain is cut short on shutdown")
+	}
+	tunnelManager.SetDrainTimeout(drainTimeout)
+
+	streamIdleTimeout := cliCtx.Duration(flagTunnelStreamIdleTimeout)
+	if streamIdleTimeout < 0 {
+		return fmt.Errorf("flag %q must not be negative", flagTunnelStreamIdleTimeout)
+	}
+	tunnelManager.SetStreamIdleTimeout(streamIdleTimeout)
+	tunnelManager.SetQUIC(cliCtx.Bool(flagTunnelQUIC))
+
+	qos := tunnel.QoSConfig{
+		MaxStreams:            cliCtx.Int(flagTunnelMaxStreams),
+		Bandwidth:             cliCtx.Int64(flagTunnelBandwidth),
+		EdgeIngressMaxStreams: cliCtx.Int(flagTunnelEdgeIngressMaxStreams),
+		EdgeIngressBandwidth:  cliCtx.Int64(flagTunnelEdgeIngressBandwidth),
+	}
+	for flag, value := range map[string]int64{
+		flagTunnelMaxStreams:            int64(qos.MaxStreams),
+		flagTunnelBandwidth:             qos.Bandwidth,
+		flagTunnelEdgeIngressMaxStreams: int64(qos.EdgeIngressMaxStreams),
+		flagTunnelEdgeIngressBandwidth:  qos.EdgeIngressBandwidth,
+	} {
+		if value < 0 {
+			return fmt.Errorf("flag %q must not be negative", flag)
+		}
+	}
+	tunnelManager.SetQoS(qos)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	tunnelMetrics, err := tunnel.NewMetrics(registry)
+	if err != nil {
+		return fmt.Errorf("create tunnel metrics: %w", err)
+	}
+	tunnelManager.SetMetrics(tunnelMetrics)
+
+	if tokens != nil {
+		tokens.AddListener(tunnelClient.SetToken)
+		tokens.AddListener(tunnelManager.SetToken)
+		go tokens.Run(ctx)
+	}
+
+	metricsListenAddr := cliCtx.String(flagMetricsListenAddr)
+
+	// The tunnel is ready once the tunnels of the cluster have been listed from the platform, and are all connected to
+	// their broker.
+	checker := newHealthChecker(coordinator)
+	checker.AddCheck("tunnels", func(_ context.Context) error {
+		if !tunnelManager.Ready() {
+			return errors.New("tunnels not listed or not connected")
+		}
+
+		return nil
+	})
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	metricsMux.Handle("/livez", checker.LiveHandler())
+	metricsMux.Handle("/readyz", checker.ReadyHandler())
+
+	startAdminServer(cliCtx, coordinator, newAdminMux(diagnostics.NewProcess("tunnel", flagValues(cliCtx))))
+
+	metricsServer := &http.Server{
+		Addr:              metricsListenAddr,
+		Handler:           metricsMux,
+		ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
+		ReadHeaderTimeout: 2 * time.Second,
+	}
+
+	go func() {
+		log.Info().Str("addr", metricsListenAddr).Msg("Starting tunnel metrics")
+		if errMetrics := metricsServer.ListenAndServe(); !errors.Is(errMetrics, http.ErrServerClosed) {
+			log.Err(errMetrics).Msg("Unable to listen and serve metrics requests")
+		}
+	}()
+
+	managerDone := make(chan struct{})
+	go func() {
+		tunnelManager.Run(ctx)
+		close(managerDone)
+	}()
+
+	// Once stopped, the manager drains the tunnels, until their streams complete or the drain timeout elapsed. The
+	// metrics server is stopped afterwards, so the drain can be observed.
+	coordinator.Register(shutdown.PhaseDrain, "tunnels", func(ctx context.Context) error {
+		select {
+		case <-managerDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	coordinator.Register(shutdown.PhaseFlush, "metrics server", shutdown.HTTPServer(metricsServer))
+
+	<-ctx.Done()
+	runShutdown(coordinator)
 
 	return nil
 }
+
+// parseUDPEntryPoints parses UDP entry points given as name=host:port.
+func parseUDPEntryPoints(entryPoints []string) (map[string]string, error) {
+	addrs := make(map[string]string, len(entryPoints))
+	for _, entryPoint := range entryPoints {
+		name, addr, ok := strings.Cut(entryPoint, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid entry point %q, expected name=host:port", entryPoint)
+		}
+
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("invalid address of entry point %q: %w", name, err)
+		}
+
+		addrs[name] = addr
+	}
+
+	return addrs, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/verify"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	flagVerifyDir    = "dir"
+	flagVerifyOutput = "output"
+)
+
+type verifyCmd struct {
+	flags []cli.Flag
+}
+
+func newVerifyCmd() verifyCmd {
+	return verifyCmd{
+		flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     flagVerifyDir,
+				Usage:    "Directory of the Ingress, IngressClass and AccessControlPolicy manifests to verify, in YAML or JSON",
+				EnvVars:  []string{"VERIFY_" + strcase.ToSNAKE(flagVerifyDir)},
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    flagVerifyOutput,
+				Usage:   "Output format of the report (text or json)",
+				EnvVars: []string{"VERIFY_" + strcase.ToSNAKE(flagVerifyOutput)},
+				Value:   "text",
+			},
+			&cli.StringFlag{
+				Name:    flagACPServerAuthServerAddr,
+				Usage:   "Address the ACP server can reach the auth server on",
+				EnvVars: []string{strcase.ToSNAKE(flagACPServerAuthServerAddr)},
+				Value:   "http://hub-agent-auth-server.hub.svc.cluster.local",
+			},
+			&cli.IntFlag{
+				Name:    flagACPServerAuthServerExtAuthzPort,
+				Usage:   "Port the ACP server can reach the auth server Envoy external authorization service on",
+				EnvVars: []string{strcase.ToSNAKE(flagACPServerAuthServerExtAuthzPort)},
+				Value:   9000,
+			},
+			&cli.StringFlag{
+				Name:    flagACPServerIstioRootNamespace,
+				Usage:   "Istio root namespace, in which the EnvoyFilters enforcing ACPs on VirtualServices are created",
+				EnvVars: []string{strcase.ToSNAKE(flagACPServerIstioRootNamespace)},
+				Value:   "istio-system",
+			},
+		},
+	}
+}
+
+func (c verifyCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "verify",
+		Usage:  "Reviews the resources of a directory of manifests offline, the way the ACP admission webhook would",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c verifyCmd) run(cliCtx *cli.Context) error {
+	output := cliCtx.String(flagVerifyOutput)
+	if output != "text" && output != "json" {
+		return fmt.Errorf("unsupported output format %q", output)
+	}
+
+	manifests, err := verify.LoadDir(cliCtx.String(flagVerifyDir))
+	if err != nil {
+		return fmt.Errorf("load manifests: %w", err)
+	}
+
+	report, err := verify.Run(cliCtx.Context, manifests, verify.Config{
+		AuthServerAddr:     cliCtx.String(flagACPServerAuthServerAddr),
+		ExtAuthzPort:       cliCtx.Int(flagACPServerAuthServerExtAuthzPort),
+		IstioRootNamespace: cliCtx.String(flagACPServerIstioRootNamespace),
+	})
+	if err != nil {
+		return fmt.Errorf("verify manifests: %w", err)
+	}
+
+	if output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		err = enc.Encode(report)
+	} else {
+		err = printReport(os.Stdout, report)
+	}
+	if err != nil {
+		return fmt.Errorf("print report: %w", err)
+	}
+
+	if report.Failed() {
+		return errors.New("the review of some resources failed")
+	}
+
+	return nil
+}
+
+func printReport(w io.Writer, report *verify.Report) error {
+	var b strings.Builder
+
+	for _, result := range report.Results {
+		fmt.Fprintf(&b, "%s %s/%s (%s)\n", result.Kind, result.Namespace, result.Name, result.Source)
+
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(&b, "  Warning: %s\n", warning)
+		}
+
+		switch {
+		case result.Error != "":
+			fmt.Fprintf(&b, "  Error: %s\n", result.Error)
+		case result.Diff == "":
+			b.WriteString("  Unchanged\n")
+		default:
+			for _, line := range strings.SplitAfter(strings.TrimSuffix(result.Diff, "\n"), "\n") {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+
+		b.WriteString("\n")
+	}
+
+	if len(report.Resources) > 0 {
+		b.WriteString("Resources created or updated by the admission webhook:\n")
+
+		for _, resource := range report.Resources {
+			raw, err := yaml.Marshal(resource.Object.Object)
+			if err != nil {
+				return fmt.Errorf("marshal %s %q: %w", resource.Object.GetKind(), resource.Object.GetName(), err)
+			}
+
+			fmt.Fprintf(&b, "---\n# %s\n%s", resource.Verb, raw)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
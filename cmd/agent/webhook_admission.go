@@ -19,6 +19,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	stdlog "log"
@@ -30,6 +31,8 @@ import (
 
 	"github.com/ettle/strcase"
 	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
@@ -39,22 +42,36 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/api"
 	apiadmission "github.com/traefik/hub-agent-kubernetes/pkg/api/admission"
 	apireviewer "github.com/traefik/hub-agent-kubernetes/pkg/api/admission/reviewer"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
+	"github.com/traefik/hub-agent-kubernetes/pkg/conversion"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
 	edgeadmission "github.com/traefik/hub-agent-kubernetes/pkg/edgeingress/admission"
+	"github.com/traefik/hub-agent-kubernetes/pkg/health"
+	"github.com/traefik/hub-agent-kubernetes/pkg/journal"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kubevers"
+	"github.com/traefik/hub-agent-kubernetes/pkg/leader"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/secretref"
+	"github.com/traefik/hub-agent-kubernetes/pkg/shutdown"
+	"github.com/traefik/hub-agent-kubernetes/pkg/standalone"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tracing"
+	"github.com/traefik/hub-agent-kubernetes/pkg/traefik"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhook"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/net/http2"
 	netv1 "k8s.io/api/networking/v1"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	kinformers "k8s.io/client-go/informers"
 	kclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -66,17 +83,59 @@ const (
 	flagACPServerListenAddr               = "acp-server.listen-addr"
 	flagACPServerCertificate              = "acp-server.cert"
 	flagACPServerKey                      = "acp-server.key"
+	flagACPServerManageCert               = "acp-server.manage-cert"
+	flagACPServerCertSecret               = "acp-server.cert-secret"
+	flagACPServerServiceName              = "acp-server.service-name"
 	flagACPServerAuthServerAddr           = "acp-server.auth-server-addr"
+	flagACPServerAuthServerExtAuthzPort   = "acp-server.auth-server-ext-authz-port"
+	flagACPServerAuthServerCapturePort    = "acp-server.auth-server-capture-port"
+	flagACPServerIstioRootNamespace       = "acp-server.istio-root-namespace"
+	flagACPServerMaxConcurrentReviews     = "acp-server.max-concurrent-reviews"
+	flagAdmissionDryRun                   = "admission-dry-run"
 	flagIngressClassName                  = "ingress-class-name"
 	flagTraefikAPIEntryPoint              = "traefik.api.entryPoint"
 	flagTraefikTunnelEntryPoint           = "traefik.tunnel.entryPoint"
 	flagTraefikTunnelEntryPointDeprecated = "traefik.entryPoint"
+	flagTraefikInstances                  = "traefik.instances"
 	flagDevPortalServiceName              = "dev-portal.service-name"
 	flagDevPortalPort                     = "dev-portal.port"
 )
 
 const apiManagementFeature = "api-management"
 
+// hubBackend is the backend Hub resources are synchronized with: the Hub platform, or the cluster itself when
+// running in standalone mode.
+type hubBackend interface {
+	admission.Backend
+	edgeadmission.Backend
+	edgeingress.PlatformClient
+	api.PlatformClient
+	api.RateLimitPlatformClient
+	api.APIVersionPlatformClient
+
+	CreateAPI(ctx context.Context, req *platform.CreateAPIReq) (*api.API, error)
+	UpdateAPI(ctx context.Context, namespace, name, lastKnownVersion string, req *platform.UpdateAPIReq) (*api.API, error)
+	DeleteAPI(ctx context.Context, namespace, name, lastKnownVersion string) error
+	CreateCollection(ctx context.Context, req *platform.CreateCollectionReq) (*api.Collection, error)
+	UpdateCollection(ctx context.Context, name, lastKnownVersion string, req *platform.UpdateCollectionReq) (*api.Collection, error)
+	DeleteCollection(ctx context.Context, name, lastKnownVersion string) error
+	CreateAccess(ctx context.Context, req *platform.CreateAccessReq) (*api.Access, error)
+	UpdateAccess(ctx context.Context, name, lastKnownVersion string, req *platform.UpdateAccessReq) (*api.Access, error)
+	DeleteAccess(ctx context.Context, name, lastKnownVersion string) error
+	CreateRateLimit(ctx context.Context, req *platform.CreateRateLimitReq) (*api.RateLimit, error)
+	UpdateRateLimit(ctx context.Context, name, lastKnownVersion string, req *platform.UpdateRateLimitReq) (*api.RateLimit, error)
+	DeleteRateLimit(ctx context.Context, name, lastKnownVersion string) error
+	CreateAPIVersion(ctx context.Context, req *platform.CreateAPIVersionReq) (*api.APIVersion, error)
+	UpdateAPIVersion(ctx context.Context, namespace, name, lastKnownVersion string, req *platform.UpdateAPIVersionReq) (*api.APIVersion, error)
+	DeleteAPIVersion(ctx context.Context, namespace, name, lastKnownVersion string) error
+	CreatePortal(ctx context.Context, req *platform.CreatePortalReq) (*api.Portal, error)
+	UpdatePortal(ctx context.Context, name, lastKnownVersion string, req *platform.UpdatePortalReq) (*api.Portal, error)
+	DeletePortal(ctx context.Context, name, lastKnownVersion string) error
+	CreateGateway(ctx context.Context, createReq *platform.CreateGatewayReq) (*api.Gateway, error)
+	UpdateGateway(ctx context.Context, name, lastKnownVersion string, updateReq *platform.UpdateGatewayReq) (*api.Gateway, error)
+	DeleteGateway(ctx context.Context, name, lastKnownVersion string) error
+}
+
 func devPortalFlags() []cli.Flag {
 	return []cli.Flag{
 		&cli.StringFlag{
@@ -114,12 +173,58 @@ func admissionFlags() []cli.Flag {
 			EnvVars: []string{strcase.ToSNAKE(flagACPServerKey)},
 			Value:   "/var/run/hub-agent-kubernetes/key.pem",
 		},
+		&cli.BoolFlag{
+			Name:    flagACPServerManageCert,
+			Usage:   fmt.Sprintf("Generate the certificate of the ACP server and rotate it before it expires, instead of reading --%s and --%s", flagACPServerCertificate, flagACPServerKey),
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerManageCert)},
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerCertSecret,
+			Usage:   "Name of the Secret storing the certificate of the ACP server when it is managed by the agent",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerCertSecret)},
+			Value:   "hub-agent-webhook-certificate",
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerServiceName,
+			Usage:   "Name of the Service the API server reaches the ACP server through, used to issue its managed certificate",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerServiceName)},
+			Value:   "hub-agent-controller",
+		},
 		&cli.StringFlag{
 			Name:    flagACPServerAuthServerAddr,
 			Usage:   "Address the ACP server can reach the auth server on",
 			EnvVars: []string{strcase.ToSNAKE(flagACPServerAuthServerAddr)},
 			Value:   "http://hub-agent-auth-server.hub.svc.cluster.local",
 		},
+		&cli.IntFlag{
+			Name:    flagACPServerAuthServerExtAuthzPort,
+			Usage:   "Port the ACP server can reach the auth server Envoy external authorization service on",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerAuthServerExtAuthzPort)},
+			Value:   9000,
+		},
+		&cli.IntFlag{
+			Name:    flagACPServerAuthServerCapturePort,
+			Usage:   "Port the APIs having capture enabled can reach the auth server capture proxy on",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerAuthServerCapturePort)},
+			Value:   8080,
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerIstioRootNamespace,
+			Usage:   "Istio root namespace, in which the EnvoyFilters enforcing ACPs on VirtualServices are created",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerIstioRootNamespace)},
+			Value:   "istio-system",
+		},
+		&cli.IntFlag{
+			Name:    flagACPServerMaxConcurrentReviews,
+			Usage:   "Maximum number of admission and conversion reviews handled concurrently, others wait for a free slot",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerMaxConcurrentReviews)},
+			Value:   32,
+		},
+		&cli.BoolFlag{
+			Name:    flagAdmissionDryRun,
+			Usage:   "Log the patches the ACP admission webhook would apply, with their diff, without mutating resources",
+			EnvVars: []string{strcase.ToSNAKE(flagAdmissionDryRun)},
+		},
 		&cli.StringFlag{
 			Name:    flagIngressClassName,
 			Usage:   "The ingress class name used for ingresses managed by Hub",
@@ -144,30 +249,56 @@ func admissionFlags() []cli.Flag {
 			EnvVars: []string{strcase.ToSNAKE(flagTraefikTunnelEntryPointDeprecated)},
 			Value:   "traefikhub-tunl",
 		},
+		&cli.StringFlag{
+			Name:    flagTraefikInstances,
+			Usage:   "Path to a JSON file describing additional Traefik instances, with the ingress class, entry points and namespaces they serve",
+			EnvVars: []string{strcase.ToSNAKE(flagTraefikInstances)},
+		},
 	}
 }
 
-func webhookAdmission(ctx context.Context, cliCtx *cli.Context, platformClient *platform.Client, cfgWatcher *platform.ConfigWatcher) error {
+// webhookAdmission runs the admission webhooks, which also serve the metrics of the given registry.
+// The platform client and config watcher are nil in standalone mode.
+func webhookAdmission(ctx context.Context, cliCtx *cli.Context, registry *prometheus.Registry, tracer *tracing.Tracer, platformClient *platform.Client, cfgWatcher *platform.ConfigWatcher, leaderRunner *leader.Runner, coordinator *shutdown.Coordinator, process *diagnostics.Process) error {
 	var (
 		listenAddr     = cliCtx.String(flagACPServerListenAddr)
 		certFile       = cliCtx.String(flagACPServerCertificate)
 		keyFile        = cliCtx.String(flagACPServerKey)
 		authServerAddr = cliCtx.String(flagACPServerAuthServerAddr)
+		extAuthzPort   = cliCtx.Int(flagACPServerAuthServerExtAuthzPort)
+		istioRootNs    = cliCtx.String(flagACPServerIstioRootNamespace)
+		dryRun         = cliCtx.Bool(flagAdmissionDryRun)
 	)
 
+	var standaloneDomain string
+	if cliCtx.Bool(flagStandalone) {
+		standaloneDomain = cliCtx.String(flagStandaloneDomain)
+	}
+
 	// Handle --traefik.entryPoint deprecation.
 	traefikTunnelEntrypoint := cliCtx.String(flagTraefikTunnelEntryPoint)
 	if traefikTunnelEntrypoint == "" {
 		traefikTunnelEntrypoint = cliCtx.String(flagTraefikTunnelEntryPointDeprecated)
 	}
 
-	if _, err := url.Parse(authServerAddr); err != nil {
+	traefikInstances, err := traefik.LoadInstances(cliCtx.String(flagTraefikInstances))
+	if err != nil {
+		return fmt.Errorf("load Traefik instances: %w", err)
+	}
+
+	authServerURL, err := url.Parse(authServerAddr)
+	if err != nil {
 		return fmt.Errorf("invalid auth server address: %w", err)
 	}
 
+	// The auth server address is expected to be the DNS name of its Service: <name>.<namespace>[.svc[.<cluster-domain>]].
+	authServerSvcName, authServerSvcNamespace, _ := strings.Cut(authServerURL.Hostname(), ".")
+	authServerSvcNamespace, _, _ = strings.Cut(authServerSvcNamespace, ".")
+
 	edgeIngressWatcherCfg := edgeingress.WatcherConfig{
 		IngressClassName:        cliCtx.String(flagIngressClassName),
 		TraefikTunnelEntryPoint: traefikTunnelEntrypoint,
+		TraefikInstances:        traefikInstances,
 		AgentNamespace:          currentNamespace(),
 		EdgeIngressSyncInterval: time.Minute,
 		CertRetryInterval:       time.Minute,
@@ -192,36 +323,100 @@ func webhookAdmission(ctx context.Context, cliCtx *cli.Context, platformClient *
 		AgentNamespace:          currentNamespace(),
 		TraefikAPIEntryPoint:    cliCtx.String(flagTraefikAPIEntryPoint),
 		TraefikTunnelEntryPoint: cliCtx.String(flagTraefikTunnelEntryPoint),
-		GatewaySyncInterval:     time.Minute,
-		CertSyncInterval:        time.Hour,
-		CertRetryInterval:       time.Minute,
+		TraefikInstances:        traefikInstances,
+		AuthServerAddress:       authServerAddr,
+		CaptureService: api.CaptureServiceConfig{
+			Name:      authServerSvcName,
+			Namespace: authServerSvcNamespace,
+			Port:      cliCtx.Int(flagACPServerAuthServerCapturePort),
+		},
+		GatewaySyncInterval: time.Minute,
+		CertSyncInterval:    time.Hour,
+		CertRetryInterval:   time.Minute,
+	}
+
+	secretProvider, err := newSecretProvider(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	// The controller is ready once it reaches the platform, its caches are synced, and it serves a valid certificate.
+	checker := newHealthChecker(coordinator)
+	if platformClient != nil {
+		checker.AddCheck("platform", platformReachable(platformClient))
+	}
+
+	var certManager *webhook.CertificateManager
+	if cliCtx.Bool(flagACPServerManageCert) {
+		certManager, err = newCertificateManager(ctx, cliCtx)
+		if err != nil {
+			return err
+		}
+
+		go certManager.Run(ctx)
+		leaderRunner.Add(func(ctx context.Context) error {
+			certManager.Rotate(ctx)
+			return nil
+		})
+
+		checker.AddCheck("webhook-certificate", certManager.Check)
+	} else {
+		checker.AddCheck("webhook-certificate", health.CertificateFile(certFile))
 	}
 
-	acpAdmission, edgeIngressAdmission, apiAdmission, err := setupAdmissionHandlers(ctx, platformClient, authServerAddr, edgeIngressWatcherCfg, portalWatcherCfg, gatewayWatcherCfg, cfgWatcher)
+	acpAdmission, webAdmissionACP, edgeIngressAdmission, apiAdmission, err := setupAdmissionHandlers(ctx, platformClient, standaloneDomain, authServerAddr, extAuthzPort, istioRootNs, dryRun, secretProvider, edgeIngressWatcherCfg, portalWatcherCfg, gatewayWatcherCfg, cfgWatcher, leaderRunner, checker, process)
 	if err != nil {
 		return fmt.Errorf("create admission handler: %w", err)
 	}
 
-	webAdmissionACP := admission.NewACPHandler(platformClient)
+	conversionRegistry, err := conversion.NewRegistry()
+	if err != nil {
+		return fmt.Errorf("create conversion registry: %w", err)
+	}
+
+	pipeline, err := webhook.NewPipeline(cliCtx.Int(flagACPServerMaxConcurrentReviews), registry)
+	if err != nil {
+		return fmt.Errorf("create review pipeline: %w", err)
+	}
+	pipeline.SetTracer(tracer)
 
 	router := chi.NewRouter()
-	router.Handle("/edge-ingress", edgeIngressAdmission)
+	router.Handle("/edge-ingress", pipeline.Wrap("edge-ingress", edgeIngressAdmission))
 	if apiAdmission != nil {
-		router.Handle("/api", apiAdmission)
-		router.Handle("/api-collection", apiAdmission)
-		router.Handle("/api-access", apiAdmission)
-		router.Handle("/api-gateway", apiAdmission)
-		router.Handle("/api-portal", apiAdmission)
-	}
-	router.Handle("/ingress", acpAdmission)
-	router.Handle("/acp", webAdmissionACP)
+		router.Handle("/api", pipeline.Wrap("api", apiAdmission))
+		router.Handle("/api-collection", pipeline.Wrap("api-collection", apiAdmission))
+		router.Handle("/api-access", pipeline.Wrap("api-access", apiAdmission))
+		router.Handle("/api-gateway", pipeline.Wrap("api-gateway", apiAdmission))
+		router.Handle("/api-portal", pipeline.Wrap("api-portal", apiAdmission))
+	}
+	router.Handle("/ingress", pipeline.Wrap("ingress", acpAdmission))
+	router.Handle("/acp", pipeline.Wrap("acp", webAdmissionACP))
+	router.Handle("/acp-validation", pipeline.Wrap("acp-validation", admission.NewACPValidationHandler()))
+	router.Handle("/conversion", pipeline.Wrap("conversion", conversion.NewHandler(conversionRegistry)))
+	router.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	router.Handle("/livez", checker.LiveHandler())
+	router.Handle("/readyz", checker.ReadyHandler())
 
 	server := &http.Server{
 		Addr:              listenAddr,
 		Handler:           router,
 		ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
 		ReadHeaderTimeout: 2 * time.Second,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	// The managed certificate is served from memory, so that rotations apply without restarting the server.
+	if certManager != nil {
+		server.TLSConfig.GetCertificate = certManager.GetCertificate
+		certFile, keyFile = "", ""
+	}
+
+	// The API server multiplexes reviews over HTTP/2 connections when available, which avoids opening a
+	// connection per review during large bursts.
+	if err = http2.ConfigureServer(server, &http2.Server{}); err != nil {
+		return fmt.Errorf("configure HTTP/2 admission server: %w", err)
 	}
+
 	srvDone := make(chan struct{})
 
 	go func() {
@@ -232,18 +427,11 @@ func webhookAdmission(ctx context.Context, cliCtx *cli.Context, platformClient *
 		close(srvDone)
 	}()
 
+	// The in-flight admission reviews complete during the shutdown, once the controller is stopped.
+	coordinator.Register(shutdown.PhaseDrain, "admission server", shutdown.HTTPServer(server))
+
 	select {
 	case <-ctx.Done():
-		gracefulCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
-
-		if err = server.Shutdown(gracefulCtx); err != nil {
-			log.Error().Err(err).Msg("Failed to shutdown admission server gracefully")
-			if err = server.Close(); err != nil {
-				return fmt.Errorf("close admission server: %w", err)
-			}
-		}
-		log.Info().Msg("Successfully shutdown admission server")
 	case <-srvDone:
 		return errors.New("admission server stopped")
 	}
@@ -251,33 +439,76 @@ func webhookAdmission(ctx context.Context, cliCtx *cli.Context, platformClient *
 	return nil
 }
 
-func setupAdmissionHandlers(ctx context.Context, platformClient *platform.Client, authServerAddr string, edgeIngressWatcherCfg edgeingress.WatcherConfig, portalWatcherCfg *api.WatcherPortalConfig, gatewayWatcherCfg *api.WatcherGatewayConfig, cfgWatcher *platform.ConfigWatcher) (acpHandler, edgeIngressHandler, apiHandler http.Handler, err error) {
+// newCertificateManager creates the manager of the admission server certificate, and loads the current certificate,
+// generating it if this is the first replica starting.
+func newCertificateManager(ctx context.Context, cliCtx *cli.Context) (*webhook.CertificateManager, error) {
 	config, err := kube.InClusterConfigWithRetrier(2)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
+		return nil, fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
+	}
+
+	kubeClient, err := kclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("create Kubernetes client set: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("create dynamic client: %w", err)
+	}
+
+	certManager := webhook.NewCertificateManager(kubeClient, dynamicClient, webhook.CertificateManagerConfig{
+		Namespace:     currentNamespace(),
+		ServiceName:   cliCtx.String(flagACPServerServiceName),
+		SecretName:    cliCtx.String(flagACPServerCertSecret),
+		CheckInterval: 10 * time.Minute,
+	})
+
+	if err = certManager.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load webhook certificate: %w", err)
+	}
+
+	return certManager, nil
+}
+
+// setupAdmissionHandlers sets up the admission handlers and the reconciliation loops of Hub resources.
+// The standalone domain is empty unless running in standalone mode, in which case the platform client is nil.
+func setupAdmissionHandlers(ctx context.Context, platformClient *platform.Client, standaloneDomain, authServerAddr string, extAuthzPort int, istioRootNs string, dryRun bool, secretProvider secretref.Provider, edgeIngressWatcherCfg edgeingress.WatcherConfig, portalWatcherCfg *api.WatcherPortalConfig, gatewayWatcherCfg *api.WatcherGatewayConfig, cfgWatcher *platform.ConfigWatcher, leaderRunner *leader.Runner, checker *health.Checker, process *diagnostics.Process) (acpHandler, acpPolicyHandler, edgeIngressHandler, apiHandler http.Handler, err error) {
+	config, err := kube.InClusterConfigWithRetrier(2)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
 	}
 
 	kubeClientSet, err := kclientset.NewForConfig(config)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create Kubernetes client set: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("create Kubernetes client set: %w", err)
 	}
 
+	// Child resources syncs are journaled so the ones interrupted by a restart are rolled forward by the next leader.
+	workJournal := journal.New(kubeClientSet, edgeIngressWatcherCfg.AgentNamespace)
+	edgeIngressWatcherCfg.Journal = workJournal
+	gatewayWatcherCfg.Journal = workJournal
+
 	if err = initIngressClass(ctx, kubeClientSet, edgeIngressWatcherCfg.IngressClassName); err != nil {
-		return nil, nil, nil, fmt.Errorf("initialize ingressClass: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("initialize ingressClass: %w", err)
 	}
 
 	hubClientSet, err := hubclientset.NewForConfig(config)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create Hub client set: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("create Hub client set: %w", err)
 	}
 	traefikClientSet, err := createTraefikClientSet(kubeClientSet, config)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create Traefik client set: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("create Traefik client set: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("create dynamic client: %w", err)
 	}
 
 	kubeVers, err := kubeClientSet.Discovery().ServerVersion()
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("detect Kubernetes version: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("detect Kubernetes version: %w", err)
 	}
 
 	kubeInformer := kinformers.NewSharedInformerFactory(kubeClientSet, 5*time.Minute)
@@ -288,64 +519,154 @@ func setupAdmissionHandlers(ctx context.Context, platformClient *platform.Client
 	acpEventHandler := admission.NewEventHandler(ingressUpdater)
 	ingClassWatcher := ingclass.NewWatcher()
 
+	secretResolver := newSecretResolver(secretProvider, kubeInformer.Core().V1().Secrets().Lister())
+	edgeIngressWatcherCfg.Secrets = secretResolver
+	gatewayWatcherCfg.Secrets = secretResolver
+
+	if _, err = kubeInformer.Core().V1().Secrets().Informer().AddEventHandler(secretResolver); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("add secret resolver: %w", err)
+	}
+
 	err = startKubeInformer(ctx, kubeVers.GitVersion, kubeInformer, ingClassWatcher)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("start kube informer: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("start kube informer: %w", err)
 	}
 
 	isAPIManagementCRDsAvailable, err := hasAPIManagementCRDs(kubeClientSet)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("API available: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("API available: %w", err)
 	}
 
-	err = startHubInformer(ctx, hubInformer, ingClassWatcher, acpEventHandler, isAPIManagementCRDsAvailable)
+	// APIRateLimits and APIVersions were introduced after the other API management CRDs, their CRDs may not be
+	// installed yet.
+	if isAPIManagementCRDsAvailable {
+		gatewayWatcherCfg.APIRateLimits, err = hasHubCRD(kubeClientSet, "APIRateLimit")
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("API rate limit available: %w", err)
+		}
+
+		gatewayWatcherCfg.APIVersions, err = hasHubCRD(kubeClientSet, "APIVersion")
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("API version available: %w", err)
+		}
+	}
+
+	err = startHubInformer(ctx, hubInformer, ingClassWatcher, acpEventHandler, isAPIManagementCRDsAvailable, gatewayWatcherCfg.APIRateLimits, gatewayWatcherCfg.APIVersions)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("start kube informer: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("start kube informer: %w", err)
 	}
+	checker.AddCheck("informers", health.InformersSynced(kubeInformer, hubInformer))
+	process.AddInformers(kubeInformer, kubeInformerFor(kubeInformer))
+	process.AddInformers(hubInformer, hubInformerFor(hubInformer))
 
-	acpWatcher := acp.NewWatcher(time.Minute, platformClient, hubClientSet, hubInformer)
+	var backend hubBackend
+	if standaloneDomain != "" {
+		// In standalone mode, CRDs are the source of truth: there are no platform ACPs to synchronize.
+		backend = standalone.NewBackend(standaloneDomain, hubInformer)
+	} else {
+		backend = platformClient
+
+		acpWatcher := acp.NewWatcher(time.Minute, platformClient, kubeClientSet, hubClientSet, hubInformer)
 
-	edgeIngressWatcher, err := edgeingress.NewWatcher(platformClient, hubClientSet, kubeClientSet, traefikClientSet, hubInformer, edgeIngressWatcherCfg)
+		// Reconciliation is only performed by the leader, other replicas only review admission requests.
+		leaderRunner.Add(func(ctx context.Context) error {
+			acpWatcher.Run(ctx)
+			return nil
+		})
+	}
+
+	edgeIngressWatcher, err := edgeingress.NewWatcher(backend, hubClientSet, kubeClientSet, traefikClientSet, hubInformer, edgeIngressWatcherCfg)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create edge ingress watcher: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("create edge ingress watcher: %w", err)
 	}
 
-	go acpWatcher.Run(ctx)
-	go ingressUpdater.Run(ctx)
-	go edgeIngressWatcher.Run(ctx)
+	leaderRunner.Add(func(ctx context.Context) error {
+		ingressUpdater.Run(ctx)
+		return nil
+	})
+	leaderRunner.Add(func(ctx context.Context) error {
+		edgeIngressWatcher.Run(ctx)
+		return nil
+	})
 
 	if isAPIManagementCRDsAvailable {
-		if err = setupAPIManagementWatcher(ctx,
-			platformClient, kubeClientSet, hubClientSet,
-			traefikClientSet, kubeInformer, hubInformer,
-			portalWatcherCfg, gatewayWatcherCfg, cfgWatcher); err != nil {
-			return nil, nil, nil, fmt.Errorf("setup API management watcher: %w", err)
-		}
+		leaderRunner.Add(func(ctx context.Context) error {
+			if standaloneDomain != "" {
+				runStandaloneAPIManagementWatchers(ctx, backend, kubeClientSet, hubClientSet, traefikClientSet, kubeInformer, hubInformer, portalWatcherCfg, gatewayWatcherCfg)
+				return nil
+			}
+
+			if err := setupAPIManagementWatcher(ctx,
+				platformClient, kubeClientSet, hubClientSet,
+				traefikClientSet, kubeInformer, hubInformer,
+				portalWatcherCfg, gatewayWatcherCfg, cfgWatcher); err != nil {
+				return fmt.Errorf("setup API management watcher: %w", err)
+			}
+
+			<-ctx.Done()
+			return nil
+		})
 	}
 
 	polGetter := reviewer.NewPolGetter(hubInformer)
 
 	fwdAuthMdlwrs := reviewer.NewFwdAuthMiddlewares(authServerAddr, polGetter, traefikClientSet)
 
+	kongPlugins := reviewer.NewKongPlugins(authServerAddr, polGetter, dynamicClient)
+
+	contourExtSvc, err := reviewer.NewContourExtensionService(authServerAddr, extAuthzPort, dynamicClient)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("create Contour ExtensionService: %w", err)
+	}
+
+	istioEnvoyFilters, err := reviewer.NewIstioEnvoyFilters(authServerAddr, extAuthzPort, istioRootNs, dynamicClient)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("create Istio EnvoyFilters: %w", err)
+	}
+
+	isIstioAvailable, err := hasIstioCRDs(kubeClientSet)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("detect Istio: %w", err)
+	}
+
+	// EnvoyFilters are not patches but standalone resources enforcing ACPs, they must not be reconciled in dry-run mode.
+	if isIstioAvailable && !dryRun {
+		vsController := admission.NewVirtualServiceController(dynamicClient, istioEnvoyFilters, time.Minute)
+		leaderRunner.Add(func(ctx context.Context) error {
+			vsController.Run(ctx)
+			return nil
+		})
+	}
+
 	traefikReviewer := reviewer.NewTraefikIngress(ingClassWatcher, fwdAuthMdlwrs)
 	reviewers := []admission.Reviewer{
 		reviewer.NewNginxIngress(authServerAddr, ingClassWatcher, polGetter),
+		reviewer.NewKongIngress(ingClassWatcher, kongPlugins),
 		reviewer.NewTraefikIngressRoute(fwdAuthMdlwrs),
+		reviewer.NewGatewayHTTPRoute(fwdAuthMdlwrs),
+		reviewer.NewContourHTTPProxy(contourExtSvc),
+		reviewer.NewIstioVirtualService(istioEnvoyFilters, dryRun),
 		traefikReviewer,
 	}
 
 	if isAPIManagementCRDsAvailable {
 		rev := []apiadmission.Reviewer{
-			apireviewer.NewAPI(platformClient),
-			apireviewer.NewCollection(platformClient),
-			apireviewer.NewAccess(platformClient),
-			apireviewer.NewPortal(platformClient),
-			apireviewer.NewGateway(platformClient),
+			apireviewer.NewAPI(backend),
+			apireviewer.NewCollection(backend),
+			apireviewer.NewAccess(backend),
+			apireviewer.NewPortal(backend),
+			apireviewer.NewGateway(backend),
+		}
+		if gatewayWatcherCfg.APIRateLimits {
+			rev = append(rev, apireviewer.NewRateLimit(backend))
+		}
+		if gatewayWatcherCfg.APIVersions {
+			rev = append(rev, apireviewer.NewAPIVersion(backend))
 		}
 		apiHandler = apiadmission.NewHandler(rev)
 	}
 
-	return admission.NewHandler(reviewers, traefikReviewer), edgeadmission.NewHandler(platformClient), apiHandler, nil
+	return admission.NewHandler(reviewers, traefikReviewer, dryRun), admission.NewACPHandler(backend), edgeadmission.NewHandler(backend), apiHandler, nil
 }
 
 func setupAPIManagementWatcher(
@@ -360,11 +681,14 @@ func setupAPIManagementWatcher(
 	gatewayWatcherCfg *api.WatcherGatewayConfig,
 	cfgWatcher *platform.ConfigWatcher,
 ) error {
-	portalWatcher := api.NewWatcherPortal(platformClient, kubeClientSet, kubeInformer, hubClientSet, hubInformer, portalWatcherCfg)
+	portalWatcher := api.NewWatcherPortal(platformClient, kubeClientSet, kubeInformer, hubClientSet, hubInformer, traefikClientSet, portalWatcherCfg)
 	gatewayWatcher := api.NewWatcherGateway(platformClient, kubeClientSet, kubeInformer, hubClientSet, hubInformer, traefikClientSet, gatewayWatcherCfg)
-	apiWatcher := api.NewWatcherAPI(platformClient, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+	specs := openapi.NewFetcher(nil, gatewayWatcherCfg.Secrets)
+	apiWatcher := api.NewWatcherAPI(platformClient, specs, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
 	collectionWatcher := api.NewWatcherCollection(platformClient, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
 	accessWatcher := api.NewWatcherAccess(platformClient, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+	rateLimitWatcher := api.NewWatcherRateLimit(platformClient, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+	apiVersionWatcher := api.NewWatcherAPIVersion(platformClient, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
 
 	var cancel func()
 	var watcherStarted bool
@@ -377,6 +701,12 @@ func setupAPIManagementWatcher(
 		go apiWatcher.Run(apiCtx)
 		go collectionWatcher.Run(apiCtx)
 		go accessWatcher.Run(apiCtx)
+		if gatewayWatcherCfg.APIRateLimits {
+			go rateLimitWatcher.Run(apiCtx)
+		}
+		if gatewayWatcherCfg.APIVersions {
+			go apiVersionWatcher.Run(apiCtx)
+		}
 
 		watcherStarted = true
 	}
@@ -408,6 +738,45 @@ func setupAPIManagementWatcher(
 	return nil
 }
 
+// runStandaloneAPIManagementWatchers runs the API management watchers until the context is done, without waiting for
+// the platform to enable the feature. APIPortals are not reconciled as they require the platform to authenticate users.
+func runStandaloneAPIManagementWatchers(
+	ctx context.Context,
+	backend interface {
+		api.PlatformClient
+		api.RateLimitPlatformClient
+		api.APIVersionPlatformClient
+	},
+	kubeClientSet *kclientset.Clientset,
+	hubClientSet *hubclientset.Clientset,
+	traefikClientSet v1alpha1.TraefikV1alpha1Interface,
+	kubeInformer kinformers.SharedInformerFactory,
+	hubInformer hubinformers.SharedInformerFactory,
+	portalWatcherCfg *api.WatcherPortalConfig,
+	gatewayWatcherCfg *api.WatcherGatewayConfig,
+) {
+	gatewayWatcher := api.NewWatcherGateway(backend, kubeClientSet, kubeInformer, hubClientSet, hubInformer, traefikClientSet, gatewayWatcherCfg)
+	specs := openapi.NewFetcher(nil, gatewayWatcherCfg.Secrets)
+	apiWatcher := api.NewWatcherAPI(backend, specs, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+	collectionWatcher := api.NewWatcherCollection(backend, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+	accessWatcher := api.NewWatcherAccess(backend, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+
+	go gatewayWatcher.Run(ctx)
+	go apiWatcher.Run(ctx)
+	go collectionWatcher.Run(ctx)
+	go accessWatcher.Run(ctx)
+	if gatewayWatcherCfg.APIRateLimits {
+		rateLimitWatcher := api.NewWatcherRateLimit(backend, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+		go rateLimitWatcher.Run(ctx)
+	}
+	if gatewayWatcherCfg.APIVersions {
+		apiVersionWatcher := api.NewWatcherAPIVersion(backend, kubeClientSet, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+		go apiVersionWatcher.Run(ctx)
+	}
+
+	<-ctx.Done()
+}
+
 func createTraefikClientSet(clientSet *kclientset.Clientset, config *rest.Config) (v1alpha1.TraefikV1alpha1Interface, error) {
 	crd, err := hasMiddlewareCRD(clientSet.Discovery())
 	if err != nil {
@@ -426,7 +795,7 @@ func createTraefikClientSet(clientSet *kclientset.Clientset, config *rest.Config
 	return traefikClientSet.TraefikV1alpha1(), nil
 }
 
-func startHubInformer(ctx context.Context, hubInformer hubinformers.SharedInformerFactory, ingClassWatcher, acpEventHandler cache.ResourceEventHandler, apiAvailable bool) error {
+func startHubInformer(ctx context.Context, hubInformer hubinformers.SharedInformerFactory, ingClassWatcher, acpEventHandler cache.ResourceEventHandler, apiAvailable, rateLimitAvailable, apiVersionAvailable bool) error {
 	if _, err := hubInformer.Hub().V1alpha1().IngressClasses().Informer().AddEventHandler(ingClassWatcher); err != nil {
 		return fmt.Errorf("add ingressClass event handler: %w", err)
 	}
@@ -444,6 +813,12 @@ func startHubInformer(ctx context.Context, hubInformer hubinformers.SharedInform
 		hubInformer.Hub().V1alpha1().APICollections().Informer()
 		hubInformer.Hub().V1alpha1().APIs().Informer()
 	}
+	if rateLimitAvailable {
+		hubInformer.Hub().V1alpha1().APIRateLimits().Informer()
+	}
+	if apiVersionAvailable {
+		hubInformer.Hub().V1alpha1().APIVersions().Informer()
+	}
 
 	hubInformer.Start(ctx.Done())
 
@@ -557,3 +932,42 @@ func hasAPIManagementCRDs(clientSet discovery.DiscoveryInterface) (bool, error)
 
 	return false, nil
 }
+
+// hasHubCRD returns whether the Hub CRD of the given kind is installed on the cluster.
+func hasHubCRD(clientSet discovery.DiscoveryInterface, kind string) (bool, error) {
+	crdList, err := clientSet.ServerResourcesForGroupVersion(hubv1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		if kerror.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	for _, resource := range crdList.APIResources {
+		if resource.Kind == kind {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func hasIstioCRDs(clientSet discovery.DiscoveryInterface) (bool, error) {
+	crdList, err := clientSet.ServerResourcesForGroupVersion("networking.istio.io/v1alpha3")
+	if err != nil {
+		if kerror.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	for _, resource := range crdList.APIResources {
+		if resource.Kind == "EnvoyFilter" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
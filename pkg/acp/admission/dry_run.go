@@ -0,0 +1,86 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rs/zerolog/log"
+)
+
+// logDryRunPatch logs the given JSON patch along with the diff it would produce on the given object.
+func logDryRunPatch(ctx context.Context, obj, patch []byte) {
+	logger := log.Ctx(ctx).With().RawJSON("patch", patch).Logger()
+
+	diff, err := PatchDiff(obj, patch)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Dry-run: unable to compute the patch diff, patch would have been applied")
+		return
+	}
+
+	logger.Info().Str("diff", diff).Msg("Dry-run: patch would have been applied")
+}
+
+// PatchDiff applies the given JSON patch on the given object and returns a unified diff of the changes.
+func PatchDiff(obj, patch []byte) (string, error) {
+	p, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return "", fmt.Errorf("decode patch: %w", err)
+	}
+
+	patched, err := p.Apply(obj)
+	if err != nil {
+		return "", fmt.Errorf("apply patch: %w", err)
+	}
+
+	before, err := indentJSON(obj)
+	if err != nil {
+		return "", fmt.Errorf("indent object: %w", err)
+	}
+	after, err := indentJSON(patched)
+	if err != nil {
+		return "", fmt.Errorf("indent patched object: %w", err)
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: "current",
+		ToFile:   "patched",
+		Context:  3,
+	})
+}
+
+// indentJSON re-encodes the given JSON document with sorted keys, so documents can be compared line by line.
+func indentJSON(raw []byte) (string, error) {
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", err
+	}
+
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b) + "\n", nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchDiff(t *testing.T) {
+	obj := []byte(`{"metadata":{"name":"my-ingress","annotations":{"hub.traefik.io/access-control-policy":"my-acp"}}}`)
+	patch := []byte(`[{"op":"replace","path":"/metadata/annotations","value":{"hub.traefik.io/access-control-policy":"my-acp","traefik.ingress.kubernetes.io/router.middlewares":"default-zz-my-acp@kubernetescrd"}}]`)
+
+	diff, err := PatchDiff(obj, patch)
+	require.NoError(t, err)
+
+	want := `--- current
++++ patched
+@@ -1,7 +1,8 @@
+ {
+   "metadata": {
+     "annotations": {
+-      "hub.traefik.io/access-control-policy": "my-acp"
++      "hub.traefik.io/access-control-policy": "my-acp",
++      "traefik.ingress.kubernetes.io/router.middlewares": "default-zz-my-acp@kubernetescrd"
+     },
+     "name": "my-ingress"
+   }
+`
+	assert.Equal(t, want, diff)
+}
+
+func TestPatchDiff_invalidPatch(t *testing.T) {
+	_, err := PatchDiff([]byte(`{}`), []byte(`[{"op":"remove","path":"/metadata"}]`))
+	assert.Error(t, err)
+}
@@ -43,6 +43,7 @@ const annotationDefaultIngressClass = "ingressclass.kubernetes.io/is-default-cla
 const (
 	ControllerTypeNginxCommunity = "k8s.io/ingress-nginx"
 	ControllerTypeTraefik        = "traefik.io/ingress-controller"
+	ControllerTypeKong           = "ingress-controllers.konghq.com/kong"
 )
 
 // Watcher watches for IngressClass resources, maintaining a local cache of these resources,
@@ -0,0 +1,130 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const contourExtensionServiceName = "hub-ext-authz"
+
+var contourExtensionServiceResource = schema.GroupVersionResource{
+	Group:    "projectcontour.io",
+	Version:  "v1alpha1",
+	Resource: "extensionservices",
+}
+
+// ContourExtensionService manages the Contour ExtensionService exposing the auth server Envoy external authorization
+// service to Contour.
+type ContourExtensionService struct {
+	serviceName      string
+	serviceNamespace string
+	port             int
+	client           dynamic.Interface
+}
+
+// NewContourExtensionService returns a new ContourExtensionService targeting the ext_authz service listening on the
+// given port of the Kubernetes Service the auth server address resolves to.
+func NewContourExtensionService(authServerAddr string, extAuthzPort int, client dynamic.Interface) (ContourExtensionService, error) {
+	u, err := url.Parse(authServerAddr)
+	if err != nil {
+		return ContourExtensionService{}, fmt.Errorf("parse auth server address: %w", err)
+	}
+
+	// The auth server address is expected to be the DNS name of a Service: <name>.<namespace>[.svc[.<cluster-domain>]].
+	parts := strings.Split(u.Hostname(), ".")
+	if len(parts) < 2 {
+		return ContourExtensionService{}, fmt.Errorf("auth server address %q is not a Service DNS name", authServerAddr)
+	}
+
+	return ContourExtensionService{
+		serviceName:      parts[0],
+		serviceNamespace: parts[1],
+		port:             extAuthzPort,
+		client:           client,
+	}, nil
+}
+
+// Setup creates or updates the ExtensionService and returns its name and namespace.
+func (s ContourExtensionService) Setup(ctx context.Context) (name, namespace string, err error) {
+	logger := log.Ctx(ctx).With().
+		Str("extension_service_name", contourExtensionServiceName).
+		Str("extension_service_namespace", s.serviceNamespace).
+		Logger()
+
+	spec := map[string]interface{}{
+		"protocol": "h2c",
+		"services": []interface{}{
+			map[string]interface{}{
+				"name": s.serviceName,
+				"port": int64(s.port),
+			},
+		},
+	}
+
+	resource := s.client.Resource(contourExtensionServiceResource).Namespace(s.serviceNamespace)
+
+	current, err := resource.Get(ctx, contourExtensionServiceName, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return "", "", fmt.Errorf("get ExtensionService: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		logger.Debug().Msg("No ExtensionService found, creating a new one")
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "projectcontour.io/v1alpha1",
+			"kind":       "ExtensionService",
+			"metadata": map[string]interface{}{
+				"name":      contourExtensionServiceName,
+				"namespace": s.serviceNamespace,
+			},
+			"spec": spec,
+		}}
+
+		if _, err = resource.Create(ctx, obj, metav1.CreateOptions{FieldManager: "hub-auth"}); err != nil {
+			return "", "", fmt.Errorf("create ExtensionService: %w", err)
+		}
+
+		return contourExtensionServiceName, s.serviceNamespace, nil
+	}
+
+	if reflect.DeepEqual(current.Object["spec"], spec) {
+		return contourExtensionServiceName, s.serviceNamespace, nil
+	}
+
+	logger.Debug().Msg("Existing ExtensionService is outdated, updating it")
+
+	current.Object["spec"] = spec
+	if _, err = resource.Update(ctx, current, metav1.UpdateOptions{FieldManager: "hub-auth"}); err != nil {
+		return "", "", fmt.Errorf("update ExtensionService: %w", err)
+	}
+
+	return contourExtensionServiceName, s.serviceNamespace, nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ContourHTTPProxy is a reviewer that handles Contour HTTPProxy resources.
+// ACPs are enforced by Envoy through the auth server external authorization service, referenced by the HTTPProxy
+// virtual host authorization.
+type ContourHTTPProxy struct {
+	extensionService ContourExtensionService
+}
+
+// NewContourHTTPProxy returns a Contour HTTPProxy reviewer.
+func NewContourHTTPProxy(extensionService ContourExtensionService) *ContourHTTPProxy {
+	return &ContourHTTPProxy{
+		extensionService: extensionService,
+	}
+}
+
+type httpProxy struct {
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec struct {
+		VirtualHost *struct {
+			Authorization *contourAuthorization `json:"authorization,omitempty"`
+		} `json:"virtualhost,omitempty"`
+	} `json:"spec"`
+}
+
+type contourAuthorization struct {
+	ExtensionRef contourExtensionRef `json:"extensionRef"`
+	AuthPolicy   *contourAuthPolicy  `json:"authPolicy,omitempty"`
+}
+
+type contourExtensionRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type contourAuthPolicy struct {
+	Context map[string]string `json:"context,omitempty"`
+}
+
+// CanReview returns whether this reviewer can handle the given admission review request.
+func (r ContourHTTPProxy) CanReview(ar admv1.AdmissionReview) (bool, error) {
+	resource := ar.Request.Kind
+
+	// Check resource type. Only continue if it's an HTTPProxy resource.
+	return isContourHTTPProxy(resource), nil
+}
+
+// Review reviews the given admission review request and optionally returns the required patch.
+func (r ContourHTTPProxy) Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error) {
+	logger := log.Ctx(ctx).With().Str("reviewer", "ContourHTTPProxy").Logger()
+	ctx = logger.WithContext(ctx)
+
+	logger.Info().Msg("Reviewing HTTPProxy resource")
+
+	if ar.Request.Operation == admv1.Delete {
+		logger.Info().Msg("Deleting HTTPProxy resource")
+		return nil, nil
+	}
+
+	proxy, oldProxy, err := parseRawHTTPProxies(ar.Request.Object.Raw, ar.Request.OldObject.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse raw objects: %w", err)
+	}
+
+	prevPolName := oldProxy.Annotations[AnnotationHubAuth]
+	// Contour supports a single external authorization, chains of ACPs are evaluated by the auth server.
+	polName := policyChain(proxy.Annotations[AnnotationHubAuth])
+	if prevPolName == "" && polName == "" {
+		logger.Debug().Msg("No ACP defined")
+		return nil, nil
+	}
+
+	var current *contourAuthorization
+	if proxy.Spec.VirtualHost != nil {
+		current = proxy.Spec.VirtualHost.Authorization
+	}
+
+	if polName == "" {
+		if current == nil || current.AuthPolicy == nil || current.AuthPolicy.Context[auth.ExtAuthzContextACP] == "" {
+			logger.Debug().Msg("No patch required")
+			return nil, nil
+		}
+
+		logger.Info().Str("prev_acp_name", prevPolName).Msg("Clearing previous ACP settings")
+
+		return map[string]interface{}{
+			"op":   "remove",
+			"path": "/spec/virtualhost/authorization",
+		}, nil
+	}
+
+	// Contour only supports external authorization on root HTTPProxies, which define the virtual host.
+	if proxy.Spec.VirtualHost == nil {
+		return nil, fmt.Errorf("ACP %q can only be set on a root HTTPProxy", polName)
+	}
+
+	name, namespace, err := r.extensionService.Setup(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("setup ExtensionService: %w", err)
+	}
+
+	authCtx := map[string]string{auth.ExtAuthzContextACP: polName}
+	if grps := proxy.Annotations[AnnotationHubAuthGroup]; grps != "" {
+		authCtx[auth.ExtAuthzContextGroups] = grps
+	}
+
+	authorization := &contourAuthorization{
+		ExtensionRef: contourExtensionRef{Name: name, Namespace: namespace},
+		AuthPolicy:   &contourAuthPolicy{Context: authCtx},
+	}
+
+	if reflect.DeepEqual(current, authorization) {
+		logger.Debug().Str("acp_name", polName).Msg("No patch required")
+		return nil, nil
+	}
+
+	logger.Info().Str("acp_name", polName).Msg("Patching resource")
+
+	return map[string]interface{}{
+		"op":    "add",
+		"path":  "/spec/virtualhost/authorization",
+		"value": authorization,
+	}, nil
+}
+
+// parseRawHTTPProxies parses raw HTTPProxies from admission requests.
+func parseRawHTTPProxies(newRaw, oldRaw []byte) (newProxy, oldProxy httpProxy, err error) {
+	if err = json.Unmarshal(newRaw, &newProxy); err != nil {
+		return httpProxy{}, httpProxy{}, fmt.Errorf("unmarshal reviewed HTTPProxy: %w", err)
+	}
+
+	if oldRaw != nil {
+		if err = json.Unmarshal(oldRaw, &oldProxy); err != nil {
+			return httpProxy{}, httpProxy{}, fmt.Errorf("unmarshal reviewed old HTTPProxy: %w", err)
+		}
+	}
+
+	return newProxy, oldProxy, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestContourHTTPProxy_CanReview(t *testing.T) {
+	tests := []struct {
+		desc      string
+		kind      metav1.GroupVersionKind
+		canReview bool
+	}{
+		{
+			desc:      "can review projectcontour.io v1 HTTPProxies",
+			kind:      metav1.GroupVersionKind{Group: "projectcontour.io", Version: "v1", Kind: "HTTPProxy"},
+			canReview: true,
+		},
+		{
+			desc:      "can't review other projectcontour.io resources",
+			kind:      metav1.GroupVersionKind{Group: "projectcontour.io", Version: "v1", Kind: "TLSCertificateDelegation"},
+			canReview: false,
+		},
+		{
+			desc:      "can't review Ingresses",
+			kind:      metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+			canReview: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			review := NewContourHTTPProxy(ContourExtensionService{})
+
+			ok, err := review.CanReview(admv1.AdmissionReview{Request: &admv1.AdmissionRequest{Kind: test.kind}})
+			require.NoError(t, err)
+			assert.Equal(t, test.canReview, ok)
+		})
+	}
+}
+
+func TestContourHTTPProxy_Review(t *testing.T) {
+	tests := []struct {
+		desc      string
+		oldProxy  string
+		proxy     string
+		wantPatch map[string]interface{}
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		{
+			desc:  "add authorization",
+			proxy: `{"metadata":{"name":"proxy","namespace":"default","annotations":{"hub.traefik.io/access-control-policy":"my-acp","hub.traefik.io/access-control-policy-groups":"admin"}},"spec":{"virtualhost":{"fqdn":"example.com"}}}`,
+			wantPatch: map[string]interface{}{
+				"op":   "add",
+				"path": "/spec/virtualhost/authorization",
+				"value": &contourAuthorization{
+					ExtensionRef: contourExtensionRef{Name: "hub-ext-authz", Namespace: "hub"},
+					AuthPolicy: &contourAuthPolicy{Context: map[string]string{
+						"hub-acp":    "my-acp",
+						"hub-groups": "admin",
+					}},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			desc:     "no patch required",
+			oldProxy: `{"metadata":{"annotations":{"hub.traefik.io/access-control-policy":"my-acp"}},"spec":{"virtualhost":{"fqdn":"example.com","authorization":{"extensionRef":{"name":"hub-ext-authz","namespace":"hub"},"authPolicy":{"context":{"hub-acp":"my-acp"}}}}}}`,
+			proxy:    `{"metadata":{"annotations":{"hub.traefik.io/access-control-policy":"my-acp"}},"spec":{"virtualhost":{"fqdn":"example.com","authorization":{"extensionRef":{"name":"hub-ext-authz","namespace":"hub"},"authPolicy":{"context":{"hub-acp":"my-acp"}}}}}}`,
+			wantErr:  assert.NoError,
+		},
+		{
+			desc:     "remove authorization",
+			oldProxy: `{"metadata":{"annotations":{"hub.traefik.io/access-control-policy":"my-acp"}},"spec":{"virtualhost":{"fqdn":"example.com","authorization":{"extensionRef":{"name":"hub-ext-authz","namespace":"hub"},"authPolicy":{"context":{"hub-acp":"my-acp"}}}}}}`,
+			proxy:    `{"metadata":{"annotations":{}},"spec":{"virtualhost":{"fqdn":"example.com","authorization":{"extensionRef":{"name":"hub-ext-authz","namespace":"hub"},"authPolicy":{"context":{"hub-acp":"my-acp"}}}}}}`,
+			wantPatch: map[string]interface{}{
+				"op":   "remove",
+				"path": "/spec/virtualhost/authorization",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			desc:    "reject ACP on an included HTTPProxy",
+			proxy:   `{"metadata":{"annotations":{"hub.traefik.io/access-control-policy":"my-acp"}},"spec":{"routes":[]}}`,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				contourExtensionServiceResource: "ExtensionServiceList",
+			})
+			extSvc, err := NewContourExtensionService("http://hub-agent-auth-server.hub.svc.cluster.local", 9000, client)
+			require.NoError(t, err)
+
+			review := NewContourHTTPProxy(extSvc)
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Operation: admv1.Update,
+					Object:    runtime.RawExtension{Raw: []byte(test.proxy)},
+				},
+			}
+			if test.oldProxy != "" {
+				ar.Request.OldObject = runtime.RawExtension{Raw: []byte(test.oldProxy)}
+			}
+
+			patch, err := review.Review(context.Background(), ar)
+			test.wantErr(t, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.wantPatch, patch)
+
+			if test.wantPatch == nil || test.wantPatch["op"] != "add" {
+				return
+			}
+
+			extSvcObj, err := client.Resource(contourExtensionServiceResource).Namespace("hub").Get(context.Background(), "hub-ext-authz", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			gotSpec, err := json.Marshal(extSvcObj.Object["spec"])
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"protocol":"h2c","services":[{"name":"hub-agent-auth-server","port":9000}]}`, string(gotSpec))
+		})
+	}
+}
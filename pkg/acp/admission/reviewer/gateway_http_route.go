@@ -0,0 +1,208 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	admv1 "k8s.io/api/admission/v1"
+	gatev1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// Traefik Middleware reference used as an ExtensionRef filter by the Traefik Gateway API provider.
+const (
+	traefikMiddlewareGroup = "traefik.containo.us"
+	traefikMiddlewareKind  = "Middleware"
+)
+
+// GatewayHTTPRoute is a reviewer that can handle Gateway API HTTPRoute resources.
+// Routes are protected by a Traefik ForwardAuth middleware referenced as an ExtensionRef filter on each rule.
+type GatewayHTTPRoute struct {
+	fwdAuthMiddlewares FwdAuthMiddlewares
+}
+
+// NewGatewayHTTPRoute returns a Gateway API HTTPRoute reviewer.
+func NewGatewayHTTPRoute(fwdAuthMiddlewares FwdAuthMiddlewares) *GatewayHTTPRoute {
+	return &GatewayHTTPRoute{
+		fwdAuthMiddlewares: fwdAuthMiddlewares,
+	}
+}
+
+// CanReview returns whether this reviewer can handle the given admission review request.
+func (r GatewayHTTPRoute) CanReview(ar admv1.AdmissionReview) (bool, error) {
+	resource := ar.Request.Kind
+
+	// Check resource type. Only continue if it's an HTTPRoute resource.
+	return isGatewayHTTPRoute(resource), nil
+}
+
+// Review reviews the given admission review request and optionally returns the required patch.
+func (r GatewayHTTPRoute) Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error) {
+	logger := log.Ctx(ctx).With().Str("reviewer", "GatewayHTTPRoute").Logger()
+	ctx = logger.WithContext(ctx)
+
+	logger.Info().Msg("Reviewing HTTPRoute resource")
+
+	if ar.Request.Operation == admv1.Delete {
+		log.Ctx(ctx).Info().Msg("Deleting HTTPRoute resource")
+		return nil, nil
+	}
+
+	route, oldRoute, err := parseRawHTTPRoutes(ar.Request.Object.Raw, ar.Request.OldObject.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse raw objects: %w", err)
+	}
+
+	prevPolName := oldRoute.Annotations[AnnotationHubAuth]
+	polName := route.Annotations[AnnotationHubAuth]
+	if prevPolName == "" && polName == "" {
+		logger.Debug().Msg("No ACP defined")
+		return nil, nil
+	}
+
+	var updated bool
+	if prevPolName != "" {
+		updated = clearPreviousHTTPRouteFilter(ctx, &route.Spec, prevPolName)
+	}
+
+	// Each ACP of the chain has its own middlewares, applied in order.
+	var mdlwrNames []string
+	grps := route.Annotations[AnnotationHubAuthGroup]
+	for _, name := range PolicyNames(polName) {
+		var names []string
+		names, err = r.fwdAuthMiddlewares.Setup(ctx, name, route.Namespace, grps)
+		if err != nil {
+			return nil, err
+		}
+
+		mdlwrNames = append(mdlwrNames, names...)
+	}
+
+	if !updateHTTPRoute(&route.Spec, mdlwrNames) && !updated {
+		logger.Debug().Str("acp_name", polName).Msg("No patch required")
+		return nil, nil
+	}
+
+	logger.Info().Str("acp_name", polName).Msg("Patching resource")
+
+	return map[string]interface{}{
+		"op":    "replace",
+		"path":  "/spec/rules",
+		"value": route.Spec.Rules,
+	}, nil
+}
+
+func updateHTTPRoute(spec *gatev1beta1.HTTPRouteSpec, names []string) (updated bool) {
+	for i, rule := range spec.Rules {
+		// The ForwardAuth middlewares must be the first filters applied so requests are authenticated before being
+		// modified by other filters.
+		var filters []gatev1beta1.HTTPRouteFilter
+		for _, name := range names {
+			if hasMiddlewareFilter(rule.Filters, name) {
+				continue
+			}
+
+			filters = append(filters, gatev1beta1.HTTPRouteFilter{
+				Type: gatev1beta1.HTTPRouteFilterExtensionRef,
+				ExtensionRef: &gatev1beta1.LocalObjectReference{
+					Group: traefikMiddlewareGroup,
+					Kind:  traefikMiddlewareKind,
+					Name:  gatev1beta1.ObjectName(name),
+				},
+			})
+		}
+
+		if len(filters) > 0 {
+			spec.Rules[i].Filters = append(filters, rule.Filters...)
+			updated = true
+		}
+	}
+
+	return updated
+}
+
+func hasMiddlewareFilter(filters []gatev1beta1.HTTPRouteFilter, name string) bool {
+	for _, filter := range filters {
+		if isMiddlewareFilter(filter, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func clearPreviousHTTPRouteFilter(ctx context.Context, spec *gatev1beta1.HTTPRouteSpec, oldPolName string) (updated bool) {
+	log.Ctx(ctx).Debug().Str("prev_acp_name", oldPolName).Msg("Clearing previous ACP settings")
+
+	var mdlwrNames []string
+	for _, name := range PolicyNames(oldPolName) {
+		mdlwrNames = append(mdlwrNames, middlewareName(name), headersMiddlewareName(name))
+	}
+
+	for i, rule := range spec.Rules {
+		var filters []gatev1beta1.HTTPRouteFilter
+		for _, filter := range rule.Filters {
+			if isAnyMiddlewareFilter(filter, mdlwrNames) {
+				updated = true
+				continue
+			}
+			filters = append(filters, filter)
+		}
+
+		spec.Rules[i].Filters = filters
+	}
+
+	return updated
+}
+
+func isAnyMiddlewareFilter(filter gatev1beta1.HTTPRouteFilter, names []string) bool {
+	for _, name := range names {
+		if isMiddlewareFilter(filter, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isMiddlewareFilter(filter gatev1beta1.HTTPRouteFilter, name string) bool {
+	return filter.Type == gatev1beta1.HTTPRouteFilterExtensionRef &&
+		filter.ExtensionRef != nil &&
+		filter.ExtensionRef.Group == traefikMiddlewareGroup &&
+		filter.ExtensionRef.Kind == traefikMiddlewareKind &&
+		string(filter.ExtensionRef.Name) == name
+}
+
+// parseRawHTTPRoutes parses raw HTTPRoutes from admission requests.
+// The v1alpha2 and v1beta1 HTTPRoute specs are identical, so both are parsed as v1beta1.
+func parseRawHTTPRoutes(newRaw, oldRaw []byte) (newRoute, oldRoute gatev1beta1.HTTPRoute, err error) {
+	if err = json.Unmarshal(newRaw, &newRoute); err != nil {
+		return gatev1beta1.HTTPRoute{}, gatev1beta1.HTTPRoute{}, fmt.Errorf("unmarshal reviewed HTTPRoute: %w", err)
+	}
+
+	if oldRaw != nil {
+		if err = json.Unmarshal(oldRaw, &oldRoute); err != nil {
+			return gatev1beta1.HTTPRoute{}, gatev1beta1.HTTPRoute{}, fmt.Errorf("unmarshal reviewed old HTTPRoute: %w", err)
+		}
+	}
+
+	return newRoute, oldRoute, nil
+}
@@ -0,0 +1,257 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatev1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestGatewayHTTPRoute_CanReviewChecksKind(t *testing.T) {
+	tests := []struct {
+		desc      string
+		kind      metav1.GroupVersionKind
+		canReview bool
+	}{
+		{
+			desc: "can review gateway.networking.k8s.io v1beta1 HTTPRoute",
+			kind: metav1.GroupVersionKind{
+				Group:   "gateway.networking.k8s.io",
+				Version: "v1beta1",
+				Kind:    "HTTPRoute",
+			},
+			canReview: true,
+		},
+		{
+			desc: "can review gateway.networking.k8s.io v1alpha2 HTTPRoute",
+			kind: metav1.GroupVersionKind{
+				Group:   "gateway.networking.k8s.io",
+				Version: "v1alpha2",
+				Kind:    "HTTPRoute",
+			},
+			canReview: true,
+		},
+		{
+			desc: "can't review invalid gateway.networking.k8s.io HTTPRoute version",
+			kind: metav1.GroupVersionKind{
+				Group:   "gateway.networking.k8s.io",
+				Version: "invalid",
+				Kind:    "HTTPRoute",
+			},
+			canReview: false,
+		},
+		{
+			desc: "can't review gateway.networking.k8s.io TCPRoute",
+			kind: metav1.GroupVersionKind{
+				Group:   "gateway.networking.k8s.io",
+				Version: "v1alpha2",
+				Kind:    "TCPRoute",
+			},
+			canReview: false,
+		},
+		{
+			desc: "can't review networking.k8s.io v1 Ingress",
+			kind: metav1.GroupVersionKind{
+				Group:   "networking.k8s.io",
+				Version: "v1",
+				Kind:    "Ingress",
+			},
+			canReview: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			review := NewGatewayHTTPRoute(NewFwdAuthMiddlewares("", nil, nil))
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Kind: test.kind,
+				},
+			}
+
+			ok, err := review.CanReview(ar)
+			require.NoError(t, err)
+			assert.Equal(t, test.canReview, ok)
+		})
+	}
+}
+
+func TestGatewayHTTPRoute_Review(t *testing.T) {
+	customFilter := gatev1beta1.HTTPRouteFilter{
+		Type: gatev1beta1.HTTPRouteFilterRequestHeaderModifier,
+		RequestHeaderModifier: &gatev1beta1.HTTPHeaderFilter{
+			Add: []gatev1beta1.HTTPHeader{{Name: "X-Foo", Value: "bar"}},
+		},
+	}
+	middlewareFilter := func(name string) gatev1beta1.HTTPRouteFilter {
+		return gatev1beta1.HTTPRouteFilter{
+			Type: gatev1beta1.HTTPRouteFilterExtensionRef,
+			ExtensionRef: &gatev1beta1.LocalObjectReference{
+				Group: "traefik.containo.us",
+				Kind:  "Middleware",
+				Name:  gatev1beta1.ObjectName(name),
+			},
+		}
+	}
+
+	tests := []struct {
+		desc      string
+		oldAnno   string
+		anno      string
+		rules     []gatev1beta1.HTTPRouteRule
+		wantPatch []gatev1beta1.HTTPRouteRule
+	}{
+		{
+			desc: "no ACP",
+			rules: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{customFilter}},
+			},
+		},
+		{
+			desc: "add ACP",
+			anno: "my-policy",
+			rules: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{customFilter}},
+				{},
+			},
+			wantPatch: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{middlewareFilter("zz-my-policy"), customFilter}},
+				{Filters: []gatev1beta1.HTTPRouteFilter{middlewareFilter("zz-my-policy")}},
+			},
+		},
+		{
+			desc:    "replace ACP",
+			oldAnno: "my-old-policy",
+			anno:    "my-policy",
+			rules: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{middlewareFilter("zz-my-old-policy"), customFilter}},
+			},
+			wantPatch: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{middlewareFilter("zz-my-policy"), customFilter}},
+			},
+		},
+		{
+			desc:    "remove ACP",
+			oldAnno: "my-old-policy",
+			rules: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{middlewareFilter("zz-my-old-policy"), customFilter}},
+			},
+			wantPatch: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{customFilter}},
+			},
+		},
+		{
+			desc:    "ACP already set",
+			oldAnno: "my-policy",
+			anno:    "my-policy",
+			rules: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{middlewareFilter("zz-my-policy")}},
+			},
+			wantPatch: []gatev1beta1.HTTPRouteRule{
+				{Filters: []gatev1beta1.HTTPRouteFilter{middlewareFilter("zz-my-policy")}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			traefikClientSet := traefikcrdfake.NewSimpleClientset()
+
+			policies := newPolicyGetterMock(t)
+			if test.anno != "" {
+				policies.OnGetConfig(test.anno).TypedReturns(&acp.Config{JWT: &jwt.Config{}}, nil).Once()
+			}
+
+			rev := NewGatewayHTTPRoute(NewFwdAuthMiddlewares("http://auth-server", policies, traefikClientSet.TraefikV1alpha1()))
+
+			oldRoute := gatev1beta1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "name",
+					Namespace:   "test",
+					Annotations: map[string]string{"hub.traefik.io/access-control-policy": test.oldAnno},
+				},
+				Spec: gatev1beta1.HTTPRouteSpec{Rules: test.rules},
+			}
+			route := gatev1beta1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "name",
+					Namespace:   "test",
+					Annotations: map[string]string{"hub.traefik.io/access-control-policy": test.anno},
+				},
+				Spec: gatev1beta1.HTTPRouteSpec{Rules: test.rules},
+			}
+
+			oldB, err := json.Marshal(oldRoute)
+			require.NoError(t, err)
+
+			b, err := json.Marshal(route)
+			require.NoError(t, err)
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Operation: admv1.Update,
+					Object:    runtime.RawExtension{Raw: b},
+					OldObject: runtime.RawExtension{Raw: oldB},
+				},
+			}
+
+			patch, err := rev.Review(context.Background(), ar)
+			require.NoError(t, err)
+
+			if test.wantPatch == nil {
+				assert.Nil(t, patch)
+				return
+			}
+
+			require.NotNil(t, patch)
+			assert.Equal(t, "replace", patch["op"])
+			assert.Equal(t, "/spec/rules", patch["path"])
+
+			b, err = json.Marshal(patch["value"])
+			require.NoError(t, err)
+
+			var rules []gatev1beta1.HTTPRouteRule
+			require.NoError(t, json.Unmarshal(b, &rules))
+			assert.Equal(t, test.wantPatch, rules)
+
+			if test.anno != "" {
+				m, err := traefikClientSet.TraefikV1alpha1().Middlewares("test").Get(context.Background(), "zz-my-policy", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, "http://auth-server/my-policy", m.Spec.ForwardAuth.Address)
+			}
+		})
+	}
+}
@@ -21,12 +21,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // AnnotationHubAuth is the annotation to add to an Ingress resource in order to enable Hub authentication.
+// It references an ACP, or an ordered list of ACPs separated by commas which are evaluated as a chain: requests must be
+// authorized by each of them, in order.
 const (
 	AnnotationHubAuth      = "hub.traefik.io/access-control-policy"
 	AnnotationHubAuthGroup = "hub.traefik.io/access-control-policy-groups"
@@ -36,6 +39,7 @@ const (
 const (
 	defaultAnnotationNginx   = "nginx"
 	defaultAnnotationTraefik = "traefik"
+	defaultAnnotationKong    = "kong"
 )
 
 // ingress is a generic form of netv1, netv1beta1 and extv1 ingress resources.
@@ -57,6 +61,24 @@ type ingressRuleHTTP struct {
 	Paths []interface{} `json:"paths"`
 }
 
+// PolicyNames returns the ordered list of ACPs referenced by the given value of the AnnotationHubAuth annotation.
+func PolicyNames(anno string) []string {
+	var names []string
+	for _, name := range strings.Split(anno, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// policyChain returns the canonical form of the given value of the AnnotationHubAuth annotation, which is the path
+// the auth server evaluates the chain of ACPs on.
+func policyChain(anno string) string {
+	return strings.Join(PolicyNames(anno), ",")
+}
+
 // parseRawIngresses parses raw objects from admission requests into generic ingress resources.
 func parseRawIngresses(newRaw, oldRaw []byte) (newIng, oldIng ingress, err error) {
 	if err = json.Unmarshal(newRaw, &newIng); err != nil {
@@ -89,7 +111,31 @@ func parseIngressClass(obj []byte) (ingClassName, ingClassAnno string, err error
 	return ing.Spec.IngressClassName, ing.ObjectMeta.Annotations["kubernetes.io/ingress.class"], nil
 }
 
-func headerToForward(cfg *acp.Config) ([]string, error) {
+// headerToForward returns the headers set by the given chain of ACPs which must be forwarded to the upstream service.
+func headerToForward(cfgs ...*acp.Config) ([]string, error) {
+	var headerToFwd []string
+	seen := make(map[string]struct{})
+
+	for _, cfg := range cfgs {
+		headers, err := configHeaderToForward(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, header := range headers {
+			if _, ok := seen[header]; ok {
+				continue
+			}
+			seen[header] = struct{}{}
+
+			headerToFwd = append(headerToFwd, header)
+		}
+	}
+
+	return headerToFwd, nil
+}
+
+func configHeaderToForward(cfg *acp.Config) ([]string, error) {
 	var headerToFwd []string
 
 	switch {
@@ -135,12 +181,12 @@ func headerToForward(cfg *acp.Config) ([]string, error) {
 		return nil, errors.New("unsupported ACP type")
 	}
 
-	return headerToFwd, nil
+	return append(headerToFwd, cfg.Headers.RequestHeaders()...), nil
 }
 
 func isDefaultIngressClassValue(value string) bool {
 	switch value {
-	case defaultAnnotationTraefik, defaultAnnotationNginx:
+	case defaultAnnotationTraefik, defaultAnnotationNginx, defaultAnnotationKong:
 		return true
 	default:
 		return false
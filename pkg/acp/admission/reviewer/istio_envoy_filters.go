@@ -0,0 +1,275 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	istioExtAuthzEnvoyFilterName = "hub-ext-authz"
+
+	// annotationIstioVirtualService is set on the EnvoyFilters enforcing the ACP of a VirtualService.
+	// Its value is the namespace and the name of the VirtualService: <namespace>/<name>.
+	annotationIstioVirtualService = "hub.traefik.io/virtual-service"
+)
+
+var istioEnvoyFilterResource = schema.GroupVersionResource{
+	Group:    "networking.istio.io",
+	Version:  "v1alpha3",
+	Resource: "envoyfilters",
+}
+
+// IstioEnvoyFilters manages the Istio EnvoyFilters enforcing ACPs on VirtualServices through the auth server Envoy
+// external authorization service.
+// Istio AuthorizationPolicies can delegate authorization to an external provider, but can't give it per-policy
+// context. Instead, a shared EnvoyFilter inserts the ext_authz filter, disabled by default, in the HTTP filter chains.
+// Then, each VirtualService having an ACP gets its own EnvoyFilter, enabling the ext_authz filter on the virtual hosts
+// of its hosts with the ACP as context. Matching virtual hosts by domain name requires Istio 1.20 or later.
+// EnvoyFilters are created in the Istio root namespace, so they apply to gateways and sidecars alike.
+type IstioEnvoyFilters struct {
+	cluster       string
+	rootNamespace string
+	client        dynamic.Interface
+}
+
+// NewIstioEnvoyFilters returns a new IstioEnvoyFilters targeting the ext_authz service listening on the given port of
+// the Kubernetes Service the auth server address resolves to.
+func NewIstioEnvoyFilters(authServerAddr string, extAuthzPort int, rootNamespace string, client dynamic.Interface) (IstioEnvoyFilters, error) {
+	u, err := url.Parse(authServerAddr)
+	if err != nil {
+		return IstioEnvoyFilters{}, fmt.Errorf("parse auth server address: %w", err)
+	}
+
+	// The auth server address is expected to be the DNS name of a Service: <name>.<namespace>[.svc[.<cluster-domain>]].
+	parts := strings.Split(u.Hostname(), ".")
+	if len(parts) < 2 {
+		return IstioEnvoyFilters{}, fmt.Errorf("auth server address %q is not a Service DNS name", authServerAddr)
+	}
+
+	fqdn := u.Hostname()
+	if len(parts) < 4 {
+		fqdn = parts[0] + "." + parts[1] + ".svc.cluster.local"
+	}
+
+	return IstioEnvoyFilters{
+		// Istio names the clusters of the mesh services: outbound|<port>|<subset>|<fqdn>.
+		cluster:       fmt.Sprintf("outbound|%d||%s", extAuthzPort, fqdn),
+		rootNamespace: rootNamespace,
+		client:        client,
+	}, nil
+}
+
+// Setup creates or updates the shared EnvoyFilter inserting the ext_authz filter.
+func (f IstioEnvoyFilters) Setup(ctx context.Context) error {
+	spec := map[string]interface{}{
+		"configPatches": []interface{}{
+			map[string]interface{}{
+				"applyTo": "HTTP_FILTER",
+				"match": map[string]interface{}{
+					"listener": map[string]interface{}{
+						"filterChain": map[string]interface{}{
+							"filter": map[string]interface{}{
+								"name": "envoy.filters.network.http_connection_manager",
+								"subFilter": map[string]interface{}{
+									"name": "envoy.filters.http.router",
+								},
+							},
+						},
+					},
+				},
+				"patch": map[string]interface{}{
+					"operation": "INSERT_BEFORE",
+					"value": map[string]interface{}{
+						"name": "envoy.filters.http.ext_authz",
+						// The filter is only enabled on the virtual hosts of the VirtualServices having an ACP.
+						"disabled": true,
+						"typed_config": map[string]interface{}{
+							"@type":                 "type.googleapis.com/envoy.extensions.filters.http.ext_authz.v3.ExtAuthz",
+							"transport_api_version": "V3",
+							"grpc_service": map[string]interface{}{
+								"envoy_grpc": map[string]interface{}{
+									"cluster_name": f.cluster,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return f.upsert(ctx, istioExtAuthzEnvoyFilterName, nil, spec)
+}
+
+// SetupVirtualService creates or updates the EnvoyFilter enforcing the given ACP on the hosts of a VirtualService.
+// Chains of ACPs are evaluated by the auth server.
+func (f IstioEnvoyFilters) SetupVirtualService(ctx context.Context, namespace, name string, hosts []string, polName, groups string) error {
+	filterName, err := istioEnvoyFilterName(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	authCtx := map[string]interface{}{auth.ExtAuthzContextACP: policyChain(polName)}
+	if groups != "" {
+		authCtx[auth.ExtAuthzContextGroups] = groups
+	}
+
+	patches := make([]interface{}, 0, len(hosts))
+	for _, host := range hosts {
+		patches = append(patches, map[string]interface{}{
+			"applyTo": "VIRTUAL_HOST",
+			"match": map[string]interface{}{
+				"routeConfiguration": map[string]interface{}{
+					"vhost": map[string]interface{}{
+						"domainName": host,
+					},
+				},
+			},
+			"patch": map[string]interface{}{
+				"operation": "MERGE",
+				"value": map[string]interface{}{
+					"typed_per_filter_config": map[string]interface{}{
+						"envoy.filters.http.ext_authz": map[string]interface{}{
+							"@type": "type.googleapis.com/envoy.extensions.filters.http.ext_authz.v3.ExtAuthzPerRoute",
+							"check_settings": map[string]interface{}{
+								"context_extensions": authCtx,
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	annotations := map[string]interface{}{annotationIstioVirtualService: namespace + "/" + name}
+
+	return f.upsert(ctx, filterName, annotations, map[string]interface{}{"configPatches": patches})
+}
+
+// DeleteVirtualService deletes the EnvoyFilter enforcing an ACP on the hosts of a VirtualService.
+func (f IstioEnvoyFilters) DeleteVirtualService(ctx context.Context, namespace, name string) error {
+	filterName, err := istioEnvoyFilterName(namespace, name)
+	if err != nil {
+		return err
+	}
+
+	err = f.client.Resource(istioEnvoyFilterResource).Namespace(f.rootNamespace).Delete(ctx, filterName, metav1.DeleteOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("delete EnvoyFilter: %w", err)
+	}
+
+	return nil
+}
+
+// ListVirtualServices returns the namespace and name (<namespace>/<name>) of the VirtualServices having an EnvoyFilter.
+func (f IstioEnvoyFilters) ListVirtualServices(ctx context.Context) ([]string, error) {
+	list, err := f.client.Resource(istioEnvoyFilterResource).Namespace(f.rootNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/managed-by=traefik-hub",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list EnvoyFilters: %w", err)
+	}
+
+	var virtualServices []string
+	for _, filter := range list.Items {
+		if vs := filter.GetAnnotations()[annotationIstioVirtualService]; vs != "" {
+			virtualServices = append(virtualServices, vs)
+		}
+	}
+
+	return virtualServices, nil
+}
+
+func (f IstioEnvoyFilters) upsert(ctx context.Context, name string, annotations, spec map[string]interface{}) error {
+	logger := log.Ctx(ctx).With().
+		Str("envoy_filter_name", name).
+		Str("envoy_filter_namespace", f.rootNamespace).
+		Logger()
+
+	resource := f.client.Resource(istioEnvoyFilterResource).Namespace(f.rootNamespace)
+
+	current, err := resource.Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get EnvoyFilter: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		logger.Debug().Msg("No EnvoyFilter found, creating a new one")
+
+		metadata := map[string]interface{}{
+			"name":      name,
+			"namespace": f.rootNamespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+		}
+		if annotations != nil {
+			metadata["annotations"] = annotations
+		}
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "EnvoyFilter",
+			"metadata":   metadata,
+			"spec":       spec,
+		}}
+
+		if _, err = resource.Create(ctx, obj, metav1.CreateOptions{FieldManager: "hub-auth"}); err != nil {
+			return fmt.Errorf("create EnvoyFilter: %w", err)
+		}
+
+		return nil
+	}
+
+	if reflect.DeepEqual(current.Object["spec"], spec) {
+		return nil
+	}
+
+	logger.Debug().Msg("Existing EnvoyFilter is outdated, updating it")
+
+	current.Object["spec"] = spec
+	if _, err = resource.Update(ctx, current, metav1.UpdateOptions{FieldManager: "hub-auth"}); err != nil {
+		return fmt.Errorf("update EnvoyFilter: %w", err)
+	}
+
+	return nil
+}
+
+// istioEnvoyFilterName computes the name of the EnvoyFilter enforcing the ACP of a VirtualService.
+// The name follows this format: hub-acp-{name}-{hash(namespace/name)}.
+func istioEnvoyFilterName(namespace, name string) (string, error) {
+	h, err := hash(namespace + "/" + name)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("hub-acp-%s-%d", name, h), nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IstioVirtualService is a reviewer that handles Istio VirtualService resources.
+// ACPs are enforced by Envoy through the auth server external authorization service, enabled on the hosts of the
+// VirtualService by EnvoyFilters. VirtualServices are left untouched.
+type IstioVirtualService struct {
+	envoyFilters IstioEnvoyFilters
+	dryRun       bool
+}
+
+// NewIstioVirtualService returns an Istio VirtualService reviewer.
+// In dry-run mode, EnvoyFilter changes are logged but never applied.
+func NewIstioVirtualService(envoyFilters IstioEnvoyFilters, dryRun bool) *IstioVirtualService {
+	return &IstioVirtualService{
+		envoyFilters: envoyFilters,
+		dryRun:       dryRun,
+	}
+}
+
+type virtualService struct {
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec struct {
+		Hosts []string `json:"hosts,omitempty"`
+	} `json:"spec"`
+}
+
+// CanReview returns whether this reviewer can handle the given admission review request.
+func (r IstioVirtualService) CanReview(ar admv1.AdmissionReview) (bool, error) {
+	resource := ar.Request.Kind
+
+	// Check resource type. Only continue if it's a VirtualService resource.
+	return isIstioVirtualService(resource), nil
+}
+
+// Review reviews the given admission review request and sets up the EnvoyFilters enforcing the ACP of the
+// VirtualService. It never returns a patch.
+func (r IstioVirtualService) Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error) {
+	logger := log.Ctx(ctx).With().Str("reviewer", "IstioVirtualService").Logger()
+	ctx = logger.WithContext(ctx)
+
+	logger.Info().Msg("Reviewing VirtualService resource")
+
+	if ar.Request.Operation == admv1.Delete {
+		logger.Info().Msg("Deleting VirtualService resource")
+
+		var oldVS virtualService
+		if err := json.Unmarshal(ar.Request.OldObject.Raw, &oldVS); err != nil {
+			return nil, fmt.Errorf("unmarshal reviewed old VirtualService: %w", err)
+		}
+		if oldVS.Annotations[AnnotationHubAuth] == "" {
+			return nil, nil
+		}
+
+		if r.dryRun {
+			logger.Info().Msg("Dry-run: EnvoyFilter would have been deleted")
+			return nil, nil
+		}
+
+		if err := r.envoyFilters.DeleteVirtualService(ctx, ar.Request.Namespace, ar.Request.Name); err != nil {
+			return nil, fmt.Errorf("delete EnvoyFilter: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	vs, oldVS, err := parseRawVirtualServices(ar.Request.Object.Raw, ar.Request.OldObject.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse raw objects: %w", err)
+	}
+
+	prevPolName := oldVS.Annotations[AnnotationHubAuth]
+	polName := vs.Annotations[AnnotationHubAuth]
+	if prevPolName == "" && polName == "" {
+		logger.Debug().Msg("No ACP defined")
+		return nil, nil
+	}
+
+	// The namespace of the object is not set on creation when it's defaulted from the request.
+	namespace := vs.Namespace
+	if namespace == "" {
+		namespace = ar.Request.Namespace
+	}
+
+	if polName == "" {
+		logger.Info().Str("prev_acp_name", prevPolName).Msg("Clearing previous ACP settings")
+
+		if r.dryRun {
+			logger.Info().Msg("Dry-run: EnvoyFilter would have been deleted")
+			return nil, nil
+		}
+
+		if err = r.envoyFilters.DeleteVirtualService(ctx, namespace, vs.Name); err != nil {
+			return nil, fmt.Errorf("delete EnvoyFilter: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	if r.dryRun {
+		logger.Info().
+			Str("acp_name", polName).
+			Strs("hosts", vs.Spec.Hosts).
+			Msg("Dry-run: EnvoyFilter would have been set up")
+		return nil, nil
+	}
+
+	if err = r.envoyFilters.Setup(ctx); err != nil {
+		return nil, fmt.Errorf("setup ext_authz EnvoyFilter: %w", err)
+	}
+
+	logger.Info().Str("acp_name", polName).Msg("Setting up EnvoyFilter")
+
+	err = r.envoyFilters.SetupVirtualService(ctx, namespace, vs.Name, vs.Spec.Hosts, polName, vs.Annotations[AnnotationHubAuthGroup])
+	if err != nil {
+		return nil, fmt.Errorf("setup VirtualService EnvoyFilter: %w", err)
+	}
+
+	return nil, nil
+}
+
+// parseRawVirtualServices parses raw VirtualServices from admission requests.
+func parseRawVirtualServices(newRaw, oldRaw []byte) (newVS, oldVS virtualService, err error) {
+	if err = json.Unmarshal(newRaw, &newVS); err != nil {
+		return virtualService{}, virtualService{}, fmt.Errorf("unmarshal reviewed VirtualService: %w", err)
+	}
+
+	if oldRaw != nil {
+		if err = json.Unmarshal(oldRaw, &oldVS); err != nil {
+			return virtualService{}, virtualService{}, fmt.Errorf("unmarshal reviewed old VirtualService: %w", err)
+		}
+	}
+
+	return newVS, oldVS, nil
+}
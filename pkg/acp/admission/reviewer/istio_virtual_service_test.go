@@ -0,0 +1,180 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admission/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestIstioVirtualService_CanReview(t *testing.T) {
+	tests := []struct {
+		desc      string
+		kind      metav1.GroupVersionKind
+		canReview bool
+	}{
+		{
+			desc:      "can review networking.istio.io v1beta1 VirtualServices",
+			kind:      metav1.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"},
+			canReview: true,
+		},
+		{
+			desc:      "can review networking.istio.io v1alpha3 VirtualServices",
+			kind:      metav1.GroupVersionKind{Group: "networking.istio.io", Version: "v1alpha3", Kind: "VirtualService"},
+			canReview: true,
+		},
+		{
+			desc:      "can't review other networking.istio.io resources",
+			kind:      metav1.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "Gateway"},
+			canReview: false,
+		},
+		{
+			desc:      "can't review Ingresses",
+			kind:      metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+			canReview: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			review := NewIstioVirtualService(IstioEnvoyFilters{}, false)
+
+			ok, err := review.CanReview(admv1.AdmissionReview{Request: &admv1.AdmissionRequest{Kind: test.kind}})
+			require.NoError(t, err)
+			assert.Equal(t, test.canReview, ok)
+		})
+	}
+}
+
+func TestIstioVirtualService_Review(t *testing.T) {
+	const filterName = "hub-acp-vs-1984567514"
+
+	tests := []struct {
+		desc          string
+		operation     admv1.Operation
+		dryRun        bool
+		oldVS         string
+		vs            string
+		wantFilter    string
+		wantNoFilters bool
+	}{
+		{
+			desc:      "set up EnvoyFilters",
+			operation: admv1.Create,
+			vs:        `{"metadata":{"name":"vs","annotations":{"hub.traefik.io/access-control-policy":"my-acp","hub.traefik.io/access-control-policy-groups":"admin"}},"spec":{"hosts":["example.com","example.org"]}}`,
+			wantFilter: `{"configPatches":[
+				{"applyTo":"VIRTUAL_HOST","match":{"routeConfiguration":{"vhost":{"domainName":"example.com"}}},"patch":{"operation":"MERGE","value":{"typed_per_filter_config":{"envoy.filters.http.ext_authz":{"@type":"type.googleapis.com/envoy.extensions.filters.http.ext_authz.v3.ExtAuthzPerRoute","check_settings":{"context_extensions":{"hub-acp":"my-acp","hub-groups":"admin"}}}}}}},
+				{"applyTo":"VIRTUAL_HOST","match":{"routeConfiguration":{"vhost":{"domainName":"example.org"}}},"patch":{"operation":"MERGE","value":{"typed_per_filter_config":{"envoy.filters.http.ext_authz":{"@type":"type.googleapis.com/envoy.extensions.filters.http.ext_authz.v3.ExtAuthzPerRoute","check_settings":{"context_extensions":{"hub-acp":"my-acp","hub-groups":"admin"}}}}}}}
+			]}`,
+		},
+		{
+			desc:          "remove EnvoyFilter when the ACP is removed",
+			operation:     admv1.Update,
+			oldVS:         `{"metadata":{"name":"vs","namespace":"default","annotations":{"hub.traefik.io/access-control-policy":"my-acp"}},"spec":{"hosts":["example.com"]}}`,
+			vs:            `{"metadata":{"name":"vs","namespace":"default"},"spec":{"hosts":["example.com"]}}`,
+			wantNoFilters: true,
+		},
+		{
+			desc:          "remove EnvoyFilter when the VirtualService is deleted",
+			operation:     admv1.Delete,
+			oldVS:         `{"metadata":{"name":"vs","namespace":"default","annotations":{"hub.traefik.io/access-control-policy":"my-acp"}},"spec":{"hosts":["example.com"]}}`,
+			wantNoFilters: true,
+		},
+		{
+			desc:          "don't set up EnvoyFilters in dry-run mode",
+			operation:     admv1.Create,
+			dryRun:        true,
+			vs:            `{"metadata":{"name":"vs","annotations":{"hub.traefik.io/access-control-policy":"my-acp"}},"spec":{"hosts":["example.com"]}}`,
+			wantNoFilters: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				istioEnvoyFilterResource: "EnvoyFilterList",
+			})
+			envoyFilters, err := NewIstioEnvoyFilters("http://hub-agent-auth-server.hub.svc.cluster.local", 9000, "istio-system", client)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			if test.oldVS != "" {
+				require.NoError(t, envoyFilters.SetupVirtualService(ctx, "default", "vs", []string{"example.com"}, "my-acp", ""))
+			}
+
+			review := NewIstioVirtualService(envoyFilters, test.dryRun)
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Name:      "vs",
+					Namespace: "default",
+					Operation: test.operation,
+				},
+			}
+			if test.vs != "" {
+				ar.Request.Object = runtime.RawExtension{Raw: []byte(test.vs)}
+			}
+			if test.oldVS != "" {
+				ar.Request.OldObject = runtime.RawExtension{Raw: []byte(test.oldVS)}
+			}
+
+			patch, err := review.Review(ctx, ar)
+			require.NoError(t, err)
+			assert.Nil(t, patch)
+
+			resource := client.Resource(istioEnvoyFilterResource).Namespace("istio-system")
+
+			if test.wantNoFilters {
+				_, err = resource.Get(ctx, filterName, metav1.GetOptions{})
+				assert.True(t, kerror.IsNotFound(err))
+				return
+			}
+
+			extAuthzFilter, err := resource.Get(ctx, "hub-ext-authz", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			extAuthzSpec, err := json.Marshal(extAuthzFilter.Object["spec"])
+			require.NoError(t, err)
+			assert.Contains(t, string(extAuthzSpec), `"cluster_name":"outbound|9000||hub-agent-auth-server.hub.svc.cluster.local"`)
+
+			vsFilter, err := resource.Get(ctx, filterName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "default/vs", vsFilter.GetAnnotations()[annotationIstioVirtualService])
+
+			gotSpec, err := json.Marshal(vsFilter.Object["spec"])
+			require.NoError(t, err)
+			assert.JSONEq(t, test.wantFilter, string(gotSpec))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/ingclass"
+	admv1 "k8s.io/api/admission/v1"
+)
+
+const annotationKongPlugins = "konghq.com/plugins"
+
+// KongIngress is a reviewer that handles Kong Ingress resources.
+type KongIngress struct {
+	ingressClasses IngressClasses
+	kongPlugins    KongPlugins
+}
+
+// NewKongIngress returns a Kong ingress reviewer.
+func NewKongIngress(ingClasses IngressClasses, kongPlugins KongPlugins) *KongIngress {
+	return &KongIngress{
+		ingressClasses: ingClasses,
+		kongPlugins:    kongPlugins,
+	}
+}
+
+// CanReview returns whether this reviewer can handle the given admission review request.
+func (r KongIngress) CanReview(ar admv1.AdmissionReview) (bool, error) {
+	resource := ar.Request.Kind
+
+	// Check resource type. Only continue if it's a legacy Ingress (<1.18) or an Ingress resource.
+	if !isNetV1Ingress(resource) && !isNetV1Beta1Ingress(resource) && !isExtV1Beta1Ingress(resource) {
+		return false, nil
+	}
+
+	obj := ar.Request.Object.Raw
+	if ar.Request.Operation == admv1.Delete {
+		obj = ar.Request.OldObject.Raw
+	}
+
+	ingClassName, ingClassAnno, err := parseIngressClass(obj)
+	if err != nil {
+		return false, fmt.Errorf("parse raw ingress class: %w", err)
+	}
+
+	if ingClassName != "" {
+		var ctrlr string
+		ctrlr, err = r.ingressClasses.GetController(ingClassName)
+		if err != nil {
+			return false, fmt.Errorf("get ingress class controller from ingress class name: %w", err)
+		}
+
+		return isKong(ctrlr), nil
+	}
+
+	if ingClassAnno != "" {
+		if ingClassAnno == defaultAnnotationKong {
+			return true, nil
+		}
+
+		// Don't return an error if it's the default value of another reviewer,
+		// just say we can't review it.
+		if isDefaultIngressClassValue(ingClassAnno) {
+			return false, nil
+		}
+
+		var ctrlr string
+		ctrlr, err = r.ingressClasses.GetController(ingClassAnno)
+		if err != nil {
+			return false, fmt.Errorf("get ingress class controller from annotation: %w", err)
+		}
+
+		return isKong(ctrlr), nil
+	}
+
+	defaultCtrlr, err := r.ingressClasses.GetDefaultController()
+	if err != nil {
+		return false, fmt.Errorf("get default ingress class controller: %w", err)
+	}
+
+	return isKong(defaultCtrlr), nil
+}
+
+// Review reviews the given admission review request and optionally returns the required patch.
+func (r KongIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error) {
+	l := log.Ctx(ctx).With().Str("reviewer", "KongIngress").Logger()
+	ctx = l.WithContext(ctx)
+
+	log.Ctx(ctx).Info().Msg("Reviewing Ingress resource")
+
+	if ar.Request.Operation == admv1.Delete {
+		log.Ctx(ctx).Info().Msg("Deleting Ingress resource")
+		return nil, nil
+	}
+
+	ing, oldIng, err := parseRawIngresses(ar.Request.Object.Raw, ar.Request.OldObject.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse raw objects: %w", err)
+	}
+
+	// Kong doesn't allow a plugin to be applied twice on a route, chains of ACPs are evaluated by the auth server.
+	prevPolName := policyChain(oldIng.Metadata.Annotations[AnnotationHubAuth])
+	polName := policyChain(ing.Metadata.Annotations[AnnotationHubAuth])
+
+	if prevPolName == "" && polName == "" {
+		log.Ctx(ctx).Debug().Msg("No ACP defined")
+		return nil, nil
+	}
+
+	plugins := ing.Metadata.Annotations[annotationKongPlugins]
+
+	if prevPolName != "" {
+		prevGrps := oldIng.Metadata.Annotations[AnnotationHubAuthGroup]
+
+		plugins, err = clearPreviousKongPlugin(ctx, prevPolName, prevGrps, plugins)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if polName != "" {
+		grps := ing.Metadata.Annotations[AnnotationHubAuthGroup]
+
+		var pluginName string
+		pluginName, err = r.kongPlugins.Setup(ctx, polName, ing.Metadata.Namespace, grps)
+		if err != nil {
+			return nil, err
+		}
+
+		plugins = appendMiddleware(plugins, pluginName)
+	}
+
+	if ing.Metadata.Annotations[annotationKongPlugins] == plugins {
+		log.Ctx(ctx).Debug().Str("acp_name", polName).Msg("No patch required")
+		return nil, nil
+	}
+
+	if plugins != "" {
+		ing.Metadata.Annotations[annotationKongPlugins] = plugins
+	} else {
+		delete(ing.Metadata.Annotations, annotationKongPlugins)
+	}
+
+	log.Ctx(ctx).Info().Str("acp_name", polName).Msg("Patching resource")
+
+	return map[string]interface{}{
+		"op":    "replace",
+		"path":  "/metadata/annotations",
+		"value": ing.Metadata.Annotations,
+	}, nil
+}
+
+func clearPreviousKongPlugin(ctx context.Context, polName, groups, plugins string) (string, error) {
+	log.Ctx(ctx).Debug().Str("prev_acp_name", polName).Msg("Clearing previous ACP settings")
+
+	name, err := kongPluginName(polName, groups)
+	if err != nil {
+		return "", err
+	}
+
+	return removeMiddleware(plugins, name), nil
+}
+
+// kongPluginName returns the name of the KongPlugin for the given ACP and groups.
+func kongPluginName(polName, groups string) (string, error) {
+	name := middlewareName(polName)
+	if groups == "" {
+		return name, nil
+	}
+
+	h, err := hash(groups)
+	if err != nil {
+		return "", fmt.Errorf("unable to hash groups: %w", err)
+	}
+
+	return fmt.Sprintf("%s-%d", name, h), nil
+}
+
+func isKong(ctrlr string) bool {
+	return ctrlr == ingclass.ControllerTypeKong
+}
@@ -0,0 +1,248 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/ingclass"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
+	admv1 "k8s.io/api/admission/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestKongIngress_CanReviewChecksIngressClass(t *testing.T) {
+	tests := []struct {
+		desc               string
+		annotation         string
+		spec               string
+		ingressClassesMock func(t *testing.T) IngressClasses
+		canReview          assert.BoolAssertionFunc
+	}{
+		{
+			desc: "can review if the default controller is Kong",
+			ingressClassesMock: func(t *testing.T) IngressClasses {
+				t.Helper()
+
+				return newIngressClassesMock(t).
+					OnGetDefaultController().TypedReturns(ingclass.ControllerTypeKong, nil).Once().
+					Parent
+			},
+			canReview: assert.True,
+		},
+		{
+			desc: "can't review if the default controller is not Kong",
+			ingressClassesMock: func(t *testing.T) IngressClasses {
+				t.Helper()
+
+				return newIngressClassesMock(t).
+					OnGetDefaultController().TypedReturns(ingclass.ControllerTypeTraefik, nil).Once().
+					Parent
+			},
+			canReview: assert.False,
+		},
+		{
+			desc:       "can review if annotation is correct",
+			annotation: "kong",
+			canReview:  assert.True,
+		},
+		{
+			desc:       "can't review if using another annotation",
+			annotation: "traefik",
+			canReview:  assert.False,
+		},
+		{
+			desc: "can review if using a custom ingress class (spec)",
+			spec: "custom-kong-ingress-class",
+			ingressClassesMock: func(t *testing.T) IngressClasses {
+				t.Helper()
+
+				return newIngressClassesMock(t).
+					OnGetController("custom-kong-ingress-class").TypedReturns(ingclass.ControllerTypeKong, nil).Once().
+					Parent
+			},
+			canReview: assert.True,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var ic IngressClasses
+			if test.ingressClassesMock != nil {
+				ic = test.ingressClassesMock(t)
+			}
+			review := NewKongIngress(ic, NewKongPlugins("", nil, nil))
+
+			ing := netv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"kubernetes.io/ingress.class": test.annotation,
+					},
+				},
+				Spec: netv1.IngressSpec{
+					IngressClassName: &test.spec,
+				},
+			}
+
+			b, err := json.Marshal(ing)
+			require.NoError(t, err)
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Kind: metav1.GroupVersionKind{
+						Group:   "networking.k8s.io",
+						Version: "v1",
+						Kind:    "Ingress",
+					},
+					Object: runtime.RawExtension{Raw: b},
+				},
+			}
+
+			ok, err := review.CanReview(ar)
+			require.NoError(t, err)
+			test.canReview(t, ok)
+		})
+	}
+}
+
+func TestKongIngress_Review(t *testing.T) {
+	tests := []struct {
+		desc       string
+		config     *acp.Config
+		configErr  error
+		oldIngAnno map[string]string
+		ingAnno    map[string]string
+		wantPatch  map[string]string
+		wantPlugin string
+		wantConfig map[string]interface{}
+	}{
+		{
+			desc:       "add forward-auth plugin",
+			config:     &acp.Config{JWT: &jwt.Config{}},
+			oldIngAnno: map[string]string{},
+			ingAnno: map[string]string{
+				AnnotationHubAuth:     "my-policy",
+				annotationKongPlugins: "rate-limiting",
+			},
+			wantPatch: map[string]string{
+				AnnotationHubAuth:     "my-policy",
+				annotationKongPlugins: "rate-limiting,zz-my-policy",
+			},
+			wantPlugin: "pre-function",
+		},
+		{
+			desc:       "terminate requests when the ACP is missing",
+			configErr:  ErrPolicyNotFound,
+			oldIngAnno: map[string]string{},
+			ingAnno: map[string]string{
+				AnnotationHubAuth: "my-policy",
+			},
+			wantPatch: map[string]string{
+				AnnotationHubAuth:     "my-policy",
+				annotationKongPlugins: "zz-my-policy",
+			},
+			wantPlugin: "request-termination",
+			wantConfig: map[string]interface{}{"status_code": int64(404)},
+		},
+		{
+			desc: "remove previous plugin",
+			oldIngAnno: map[string]string{
+				AnnotationHubAuth:     "my-old-policy",
+				annotationKongPlugins: "zz-my-old-policy,rate-limiting",
+			},
+			ingAnno: map[string]string{
+				annotationKongPlugins: "zz-my-old-policy,rate-limiting",
+			},
+			wantPatch: map[string]string{
+				annotationKongPlugins: "rate-limiting",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			policies := newPolicyGetterMock(t)
+			if test.config != nil || test.configErr != nil {
+				policies.OnGetConfig("my-policy").TypedReturns(test.config, test.configErr).Once()
+			}
+
+			client := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				kongPluginResource: "KongPluginList",
+			})
+			review := NewKongIngress(nil, NewKongPlugins("http://hub-agent.default.svc:80", policies, client))
+
+			oldB, err := json.Marshal(netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "test", Annotations: test.oldIngAnno}})
+			require.NoError(t, err)
+			b, err := json.Marshal(netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "test", Annotations: test.ingAnno}})
+			require.NoError(t, err)
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Operation: admv1.Update,
+					Object:    runtime.RawExtension{Raw: b},
+					OldObject: runtime.RawExtension{Raw: oldB},
+				},
+			}
+
+			patch, err := review.Review(context.Background(), ar)
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]interface{}{
+				"op":    "replace",
+				"path":  "/metadata/annotations",
+				"value": test.wantPatch,
+			}, patch)
+
+			if test.wantPlugin == "" {
+				return
+			}
+
+			var plugin *unstructured.Unstructured
+			plugin, err = client.Resource(kongPluginResource).Namespace("test").Get(context.Background(), "zz-my-policy", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantPlugin, plugin.Object["plugin"])
+			if test.wantConfig != nil {
+				assert.Equal(t, test.wantConfig, plugin.Object["config"])
+			}
+		})
+	}
+}
+
+func TestGenKongForwardAuthScript(t *testing.T) {
+	script := genKongForwardAuthScript("http://hub-agent.default.svc:80/my-policy", []string{"X-User", "Authorization"})
+
+	assert.Contains(t, script, `request_uri("http://hub-agent.default.svc:80/my-policy"`)
+	assert.Contains(t, script, `ipairs({"X-User", "Authorization"})`)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var kongPluginResource = schema.GroupVersionResource{
+	Group:    "configuration.konghq.com",
+	Version:  "v1",
+	Resource: "kongplugins",
+}
+
+// KongPlugins manages the KongPlugins calling the auth server.
+// The forward-auth call is implemented with the bundled pre-function plugin, which requires Kong to allow the
+// `resty.http` module in its Lua sandbox (`untrusted_lua_sandbox_requires=resty.http`).
+type KongPlugins struct {
+	agentAddress string
+	policies     PolicyGetter
+	client       dynamic.Interface
+}
+
+// NewKongPlugins returns a new KongPlugins.
+func NewKongPlugins(agentAddr string, policies PolicyGetter, client dynamic.Interface) KongPlugins {
+	return KongPlugins{
+		agentAddress: agentAddr,
+		policies:     policies,
+		client:       client,
+	}
+}
+
+// Setup creates or updates the KongPlugin of the given ACP, or chain of ACPs, and returns its name.
+// If there's no ACP matching one of the given policy names, the plugin terminates requests with a 404. It allows to untie ACP
+// creation from ACP reference and remove ordering constraints while still not exposing publicly a protected resource.
+// NOTE: KongPlugins deletion is to be done elsewhere, when ACPs are deleted.
+func (p KongPlugins) Setup(ctx context.Context, polName, namespace, groups string) (string, error) {
+	name, err := kongPluginName(polName, groups)
+	if err != nil {
+		return "", err
+	}
+
+	logger := log.Ctx(ctx).With().
+		Str("acp_name", polName).
+		Str("kong_plugin_name", name).
+		Logger()
+
+	logger.Debug().Msg("Setting up KongPlugin")
+
+	var plugin string
+	var config map[string]interface{}
+
+	acpCfgs, err := getChainConfigs(p.policies, polName)
+	switch {
+	case errors.Is(err, ErrPolicyNotFound):
+		plugin = "request-termination"
+		config = map[string]interface{}{"status_code": int64(404)}
+	case err != nil:
+		return "", err
+	default:
+		plugin = "pre-function"
+		config, err = p.newPreFunctionConfig(polName, groups, acpCfgs)
+		if err != nil {
+			return "", fmt.Errorf("new pre-function config: %w", err)
+		}
+	}
+
+	current, err := p.client.Resource(kongPluginResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return "", fmt.Errorf("get KongPlugin: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		logger.Debug().Msg("No KongPlugin found, creating a new one")
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "configuration.konghq.com/v1",
+			"kind":       "KongPlugin",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"plugin": plugin,
+			"config": config,
+		}}
+
+		_, err = p.client.Resource(kongPluginResource).Namespace(namespace).Create(ctx, obj, metav1.CreateOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			return "", fmt.Errorf("create KongPlugin: %w", err)
+		}
+
+		return name, nil
+	}
+
+	if current.Object["plugin"] == plugin && reflect.DeepEqual(current.Object["config"], config) {
+		logger.Debug().Msg("Existing KongPlugin is up to date")
+
+		return name, nil
+	}
+
+	logger.Debug().Msg("Existing KongPlugin is outdated, updating it")
+
+	current.Object["plugin"] = plugin
+	current.Object["config"] = config
+
+	_, err = p.client.Resource(kongPluginResource).Namespace(namespace).Update(ctx, current, metav1.UpdateOptions{FieldManager: "hub-auth"})
+	if err != nil {
+		return "", fmt.Errorf("update KongPlugin: %w", err)
+	}
+
+	return name, nil
+}
+
+func (p KongPlugins) newPreFunctionConfig(canonicalPolName, groups string, cfgs []*acp.Config) (map[string]interface{}, error) {
+	headersToFwd, err := headerToForward(cfgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	address := p.agentAddress + "/" + canonicalPolName
+	if hasAPIKey(cfgs) && groups != "" {
+		address += "?groups=" + url.QueryEscape(groups)
+	}
+
+	return map[string]interface{}{
+		"access": []interface{}{genKongForwardAuthScript(address, headersToFwd)},
+	}, nil
+}
+
+func hasAPIKey(cfgs []*acp.Config) bool {
+	for _, cfg := range cfgs {
+		if cfg.APIKey != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// genKongForwardAuthScript generates the Lua script sending the request to the auth server before it reaches the
+// upstream service. Responses other than 2xx are sent back to the client, which covers OIDC redirections.
+func genKongForwardAuthScript(address string, headersToFwd []string) string {
+	quotedHeaders := make([]string, 0, len(headersToFwd))
+	for _, header := range headersToFwd {
+		quotedHeaders = append(quotedHeaders, fmt.Sprintf("%q", header))
+	}
+
+	return fmt.Sprintf(`local http = require "resty.http"
+local headers = kong.request.get_headers()
+headers["host"] = nil
+headers["X-Forwarded-Method"] = kong.request.get_method()
+headers["X-Forwarded-Proto"] = kong.request.get_forwarded_scheme()
+headers["X-Forwarded-Host"] = kong.request.get_forwarded_host()
+headers["X-Forwarded-Uri"] = kong.request.get_path_with_query()
+headers["X-Forwarded-For"] = kong.client.get_forwarded_ip()
+local res, err = http.new():request_uri(%q, { method = "GET", headers = headers })
+if not res then
+  kong.log.err("unable to reach the auth server: ", err)
+  return kong.response.exit(500)
+end
+if res.status < 200 or res.status >= 300 then
+  return kong.response.exit(res.status, res.body, res.headers)
+end
+for _, name in ipairs({%s}) do
+  local value = res.headers[name]
+  if value then
+    kong.service.request.set_header(name, value)
+  end
+end
+`, address, strings.Join(quotedHeaders, ", "))
+}
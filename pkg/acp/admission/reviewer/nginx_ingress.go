@@ -26,6 +26,7 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/ingclass"
 	admv1 "k8s.io/api/admission/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 )
 
 // NginxIngress is a reviewer that handles Nginx Ingress resources.
@@ -102,6 +103,8 @@ func (r NginxIngress) CanReview(ar admv1.AdmissionReview) (bool, error) {
 }
 
 // Review reviews the given admission review request and optionally returns the required patch.
+// The resource is rejected when the generated annotations exceed the Kubernetes annotations size limit, rather than
+// admitted without the Hub snippets: the headers forwarded to the backend would then be the ones sent by the client.
 func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error) {
 	l := log.Ctx(ctx).With().Str("reviewer", "NginxIngress").Logger()
 	ctx = l.WithContext(ctx)
@@ -119,7 +122,7 @@ func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map
 	}
 
 	prevPolName := oldIng.Metadata.Annotations[AnnotationHubAuth]
-	polName := ing.Metadata.Annotations[AnnotationHubAuth]
+	polName := policyChain(ing.Metadata.Annotations[AnnotationHubAuth])
 
 	if prevPolName == "" && polName == "" {
 		log.Ctx(ctx).Debug().Msg("No ACP defined")
@@ -132,15 +135,16 @@ func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map
 	} else {
 		log.Ctx(ctx).Debug().Str("acp_name", polName).Msg("ACP annotation is present")
 
-		var polCfg *acp.Config
-		polCfg, err = r.policies.GetConfig(polName)
+		// Nginx supports a single external authentication, chains of ACPs are evaluated by the auth server.
+		var polCfgs []*acp.Config
+		polCfgs, err = getChainConfigs(r.policies, polName)
 		switch {
 		case errors.Is(err, ErrPolicyNotFound):
 			nginxAnno, err = genNginxAnnotations(polName, nil, r.agentAddress, "")
 		case err == nil:
 			grps := ing.Metadata.Annotations[AnnotationHubAuthGroup]
 
-			nginxAnno, err = genNginxAnnotations(polName, polCfg, r.agentAddress, grps)
+			nginxAnno, err = genNginxAnnotations(polName, polCfgs, r.agentAddress, grps)
 		}
 
 		if err != nil {
@@ -154,14 +158,20 @@ func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map
 		return nil, nil
 	}
 
-	setNginxAnnotations(ing.Metadata.Annotations, nginxAnno)
+	anno := withNginxAnnotations(ing.Metadata.Annotations, nginxAnno)
+
+	if err = apivalidation.ValidateAnnotationsSize(anno); err != nil {
+		return nil, fmt.Errorf("annotations generated for ACP %q exceed the Kubernetes size limit of %d bytes, "+
+			"reduce the size of the Nginx snippets of the resource: %w",
+			polName, apivalidation.TotalAnnotationSizeLimitB, err)
+	}
 
 	log.Ctx(ctx).Info().Str("acp_name", polName).Msg("Patching resource")
 
 	return map[string]interface{}{
 		"op":    "replace",
 		"path":  "/metadata/annotations",
-		"value": ing.Metadata.Annotations,
+		"value": anno,
 	}, nil
 }
 
@@ -175,15 +185,23 @@ func noNginxPatchRequired(anno, nginxAnno map[string]string) bool {
 	return true
 }
 
-func setNginxAnnotations(anno, nginxAnno map[string]string) {
+// withNginxAnnotations returns a copy of the given annotations on which the Nginx annotations are set.
+func withNginxAnnotations(anno, nginxAnno map[string]string) map[string]string {
+	result := make(map[string]string, len(anno)+len(nginxAnno))
+	for k, v := range anno {
+		result[k] = v
+	}
+
 	for k, v := range nginxAnno {
 		if v == "" {
-			delete(anno, k)
+			delete(result, k)
 			continue
 		}
 
-		anno[k] = v
+		result[k] = v
 	}
+
+	return result
 }
 
 func isNginx(ctrlr string) bool {
@@ -37,23 +37,31 @@ const (
 	serverSnippet        = "nginx.ingress.kubernetes.io/server-snippet"
 )
 
-func genNginxAnnotations(polName string, polCfg *acp.Config, agentAddr, groups string) (map[string]string, error) {
+func genNginxAnnotations(polName string, polCfgs []*acp.Config, agentAddr, groups string) (map[string]string, error) {
 	// If there's no policy given, force a 404 response. It allows to untie ACP creation from ACP reference and
 	// remove ordering constraints while still not exposing publicly a protected resource.
-	if polCfg == nil {
+	if len(polCfgs) == 0 {
 		return map[string]string{
 			configurationSnippet: wrapHubSnippet("return 404;"),
 		}, nil
 	}
 
-	headerToFwd, err := headerToForward(polCfg)
+	headerToFwd, err := headerToForward(polCfgs...)
 	if err != nil {
 		return nil, fmt.Errorf("get header to forward: %w", err)
 	}
 
 	locSnip := generateLocationSnippet(headerToFwd)
 
-	if polCfg.OIDC == nil {
+	var polCfg *acp.Config
+	for _, cfg := range polCfgs {
+		if cfg.OIDC != nil {
+			polCfg = cfg
+			break
+		}
+	}
+
+	if polCfg == nil {
 		address := fmt.Sprintf("%s/%s", agentAddr, polName)
 		if groups != "" {
 			address += "?groups=" + url.QueryEscape(groups)
@@ -20,6 +20,7 @@ package reviewer
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,6 +35,7 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/token"
 	admv1 "k8s.io/api/admission/v1"
 	netv1 "k8s.io/api/networking/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -571,3 +573,106 @@ func TestNginxIngress_Review(t *testing.T) {
 		})
 	}
 }
+
+func TestNginxIngress_Review_oversizedAnnotations(t *testing.T) {
+	const hubSnippet = "##hub-snippet-start\nauth_request_set $value_0 $upstream_http_X_Header; proxy_set_header X-Header $value_0;\n##hub-snippet-end"
+
+	// Leave just enough room for the ACP and auth-url annotations, but not for the Hub configuration snippet.
+	userSnippet := strings.Repeat("#", apivalidation.TotalAnnotationSizeLimitB-300)
+
+	protected := map[string]string{
+		"hub.traefik.io/access-control-policy":              "my-policy",
+		"nginx.ingress.kubernetes.io/auth-url":              "http://hub-agent.default.svc.cluster.local/my-policy",
+		"nginx.ingress.kubernetes.io/configuration-snippet": "# user snippet\n" + hubSnippet,
+	}
+
+	tests := []struct {
+		desc              string
+		ingAnnotations    map[string]string
+		oldIngAnnotations map[string]string
+		wantPatch         map[string]string
+		wantErr           assert.ErrorAssertionFunc
+	}{
+		{
+			desc: "keeps Hub snippets when annotations fit",
+			ingAnnotations: map[string]string{
+				"hub.traefik.io/access-control-policy":              "my-policy",
+				"nginx.ingress.kubernetes.io/configuration-snippet": "# user snippet",
+			},
+			wantPatch: protected,
+			wantErr:   assert.NoError,
+		},
+		{
+			desc: "rejects the resource when the Hub snippets don't fit",
+			ingAnnotations: map[string]string{
+				"hub.traefik.io/access-control-policy":              "my-policy",
+				"nginx.ingress.kubernetes.io/configuration-snippet": userSnippet,
+			},
+			wantErr: assert.Error,
+		},
+		{
+			desc: "rejects the update of a protected resource when the Hub snippets don't fit anymore",
+			ingAnnotations: map[string]string{
+				"hub.traefik.io/access-control-policy":              "my-policy",
+				"nginx.ingress.kubernetes.io/configuration-snippet": userSnippet,
+			},
+			oldIngAnnotations: protected,
+			wantErr:           assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			policyGetter := newPolicyGetterMock(t)
+			policyGetter.OnGetConfig(mock.Anything).TypedReturns(&acp.Config{
+				JWT: &jwt.Config{
+					ForwardHeaders: map[string]string{
+						"X-Header": "claimsToForward",
+					},
+				},
+			}, nil).Maybe()
+
+			rev := NewNginxIngress("http://hub-agent.default.svc.cluster.local", nil, policyGetter)
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Object:    runtime.RawExtension{Raw: marshalIngress(t, test.ingAnnotations)},
+					OldObject: runtime.RawExtension{Raw: marshalIngress(t, test.oldIngAnnotations)},
+				},
+			}
+
+			patch, err := rev.Review(context.Background(), ar)
+			test.wantErr(t, err)
+			if err != nil {
+				// The resource isn't admitted, so it can't be exposed without its Hub snippets.
+				assert.ErrorContains(t, err, `annotations generated for ACP "my-policy" exceed the Kubernetes size limit`)
+				assert.Nil(t, patch)
+				return
+			}
+
+			assert.Equal(t, test.wantPatch, patch["value"].(map[string]string))
+		})
+	}
+}
+
+func marshalIngress(t *testing.T, annotations map[string]string) []byte {
+	t.Helper()
+
+	ing := struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}{
+		Metadata: metav1.ObjectMeta{
+			Name:        "name",
+			Namespace:   "test",
+			Annotations: annotations,
+		},
+	}
+
+	b, err := json.Marshal(ing)
+	require.NoError(t, err)
+
+	return b
+}
@@ -57,3 +57,19 @@ func (p PolGetter) GetConfig(canonicalName string) (*acp.Config, error) {
 
 	return acp.ConfigFromPolicy(policy), nil
 }
+
+// getChainConfigs returns the configurations of the given chain of ACPs, in order.
+// ErrPolicyNotFound is returned if any of the ACPs doesn't exist.
+func getChainConfigs(policies PolicyGetter, polChain string) ([]*acp.Config, error) {
+	var cfgs []*acp.Config
+	for _, polName := range PolicyNames(polChain) {
+		cfg, err := policies.GetConfig(polName)
+		if err != nil {
+			return nil, err
+		}
+
+		cfgs = append(cfgs, cfg)
+	}
+
+	return cfgs, nil
+}
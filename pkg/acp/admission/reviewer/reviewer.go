@@ -43,3 +43,19 @@ func isExtV1Beta1Ingress(resource metav1.GroupVersionKind) bool {
 func isTraefikV1Alpha1IngressRoute(resource metav1.GroupVersionKind) bool {
 	return resource.Group == "traefik.containo.us" && resource.Version == "v1alpha1" && resource.Kind == "IngressRoute"
 }
+
+func isGatewayHTTPRoute(resource metav1.GroupVersionKind) bool {
+	return resource.Group == "gateway.networking.k8s.io" &&
+		(resource.Version == "v1beta1" || resource.Version == "v1alpha2") &&
+		resource.Kind == "HTTPRoute"
+}
+
+func isContourHTTPProxy(resource metav1.GroupVersionKind) bool {
+	return resource.Group == "projectcontour.io" && resource.Version == "v1" && resource.Kind == "HTTPProxy"
+}
+
+func isIstioVirtualService(resource metav1.GroupVersionKind) bool {
+	return resource.Group == "networking.istio.io" &&
+		(resource.Version == "v1beta1" || resource.Version == "v1alpha3") &&
+		resource.Kind == "VirtualService"
+}
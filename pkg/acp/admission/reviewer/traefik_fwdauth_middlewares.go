@@ -49,18 +49,19 @@ func NewFwdAuthMiddlewares(agentAddr string, policies PolicyGetter, traefikClien
 	}
 }
 
-// Setup creates or updates the ACP middleware.
+// Setup creates or updates the ACP middlewares and returns their names, in the order they must be applied.
+// The ForwardAuth middleware always comes first and is followed by a Headers middleware when the ACP transforms
+// response headers.
 // If there's no ACP matching the given policy name, the middleware won't be created but its name will be returned.
 // This will have the effect of disabling routers referencing this middleware and requesters will receive a 404. It
 // allows to untie ACP creation from ACP reference and remove ordering constraints while still not exposing publicly
 // a protected resource.
 // NOTE: forward auth middlewares deletion is to be done elsewhere, when ACPs are deleted.
-func (m FwdAuthMiddlewares) Setup(ctx context.Context, polName, namespace, groups string) (string, error) {
+func (m FwdAuthMiddlewares) Setup(ctx context.Context, polName, namespace, groups string) ([]string, error) {
 	name := middlewareName(polName)
 
 	logger := log.Ctx(ctx).With().
 		Str("acp_name", polName).
-		Str("middleware_name", name).
 		Logger()
 	ctx = logger.WithContext(ctx)
 
@@ -69,23 +70,36 @@ func (m FwdAuthMiddlewares) Setup(ctx context.Context, polName, namespace, group
 	acpCfg, err := m.policies.GetConfig(polName)
 	if err != nil {
 		if errors.Is(err, ErrPolicyNotFound) {
-			return name, nil
+			return []string{name}, nil
 		}
 
-		return "", err
+		return nil, err
 	}
 
 	name, err = m.setupMiddleware(ctx, name, namespace, polName, groups, acpCfg)
 	if err != nil {
-		return "", fmt.Errorf("setup ForwardAuth middleware: %w", err)
+		return nil, fmt.Errorf("setup ForwardAuth middleware: %w", err)
 	}
 
-	return name, nil
+	names := []string{name}
+
+	respHeaders := acpCfg.Headers.ResponseHeaders()
+	if len(respHeaders) == 0 {
+		return names, nil
+	}
+
+	headersName := headersMiddlewareName(polName)
+	spec := traefikv1alpha1.MiddlewareSpec{
+		Headers: &traefikv1alpha1.Headers{CustomResponseHeaders: respHeaders},
+	}
+	if err = m.upsertMiddleware(ctx, headersName, namespace, spec); err != nil {
+		return nil, fmt.Errorf("setup Headers middleware: %w", err)
+	}
+
+	return append(names, headersName), nil
 }
 
 func (m *FwdAuthMiddlewares) setupMiddleware(ctx context.Context, name, namespace, canonicalPolName, groups string, cfg *acp.Config) (string, error) {
-	logger := log.Ctx(ctx)
-
 	if groups != "" {
 		h, err := hash(groups)
 		if err != nil {
@@ -95,41 +109,62 @@ func (m *FwdAuthMiddlewares) setupMiddleware(ctx context.Context, name, namespac
 		name = name + "-" + fmt.Sprintf("%d", h)
 	}
 
-	currentMiddleware, err := m.findMiddleware(ctx, name, namespace)
+	spec, err := m.newMiddlewareSpec(canonicalPolName, groups, cfg)
 	if err != nil {
+		return "", fmt.Errorf("new middleware spec: %w", err)
+	}
+
+	if err = m.upsertMiddleware(ctx, name, namespace, spec); err != nil {
 		return "", err
 	}
 
+	return name, nil
+}
+
+// upsertMiddleware creates the middleware with the given name, or updates it if its spec is outdated.
+func (m *FwdAuthMiddlewares) upsertMiddleware(ctx context.Context, name, namespace string, spec traefikv1alpha1.MiddlewareSpec) error {
+	logger := log.Ctx(ctx).With().Str("middleware_name", name).Logger()
+
+	currentMiddleware, err := m.findMiddleware(ctx, name, namespace)
+	if err != nil {
+		return err
+	}
+
 	if currentMiddleware == nil {
-		logger.Debug().Msg("No ForwardAuth middleware found, creating a new one")
-		if err = m.createMiddleware(ctx, name, namespace, canonicalPolName, groups, cfg); err != nil {
-			return "", err
+		logger.Debug().Msg("No middleware found, creating a new one")
+
+		mdlwr := &traefikv1alpha1.Middleware{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: spec,
 		}
 
-		return name, nil
-	}
+		_, err = m.traefikClientSet.Middlewares(namespace).Create(ctx, mdlwr, metav1.CreateOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			return fmt.Errorf("create middleware: %w", err)
+		}
 
-	newSpec, err := m.newMiddlewareSpec(canonicalPolName, groups, cfg)
-	if err != nil {
-		return "", err
+		return nil
 	}
 
-	if reflect.DeepEqual(currentMiddleware.Spec, newSpec) {
-		logger.Debug().Msg("Existing ForwardAuth middleware is up do date")
+	if reflect.DeepEqual(currentMiddleware.Spec, spec) {
+		logger.Debug().Msg("Existing middleware is up do date")
 
-		return name, nil
+		return nil
 	}
 
-	logger.Debug().Msg("Existing ForwardAuth middleware is outdated, updating it")
+	logger.Debug().Msg("Existing middleware is outdated, updating it")
 
-	currentMiddleware.Spec = newSpec
+	currentMiddleware.Spec = spec
 
 	_, err = m.traefikClientSet.Middlewares(namespace).Update(ctx, currentMiddleware, metav1.UpdateOptions{FieldManager: "hub-auth"})
 	if err != nil {
-		return "", err
+		return fmt.Errorf("update middleware: %w", err)
 	}
 
-	return name, nil
+	return nil
 }
 
 func (m *FwdAuthMiddlewares) findMiddleware(ctx context.Context, name, namespace string) (*traefikv1alpha1.Middleware, error) {
@@ -164,28 +199,6 @@ func (m *FwdAuthMiddlewares) newMiddlewareSpec(canonicalPolName, groups string,
 	}, nil
 }
 
-func (m *FwdAuthMiddlewares) createMiddleware(ctx context.Context, name, namespace, canonicalPolName, groups string, cfg *acp.Config) error {
-	spec, err := m.newMiddlewareSpec(canonicalPolName, groups, cfg)
-	if err != nil {
-		return fmt.Errorf("new middleware spec: %w", err)
-	}
-
-	mdlwr := &traefikv1alpha1.Middleware{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		Spec: spec,
-	}
-
-	_, err = m.traefikClientSet.Middlewares(namespace).Create(ctx, mdlwr, metav1.CreateOptions{FieldManager: "hub-auth"})
-	if err != nil {
-		return fmt.Errorf("create middleware: %w", err)
-	}
-
-	return nil
-}
-
 func hash(name string) (uint32, error) {
 	h := fnv.New32()
 
@@ -132,19 +132,21 @@ func (r TraefikIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (m
 		routerMiddlewares = r.clearPreviousFwdAuthMiddleware(ctx, prevPolName, ing.Metadata.Namespace, routerMiddlewares)
 	}
 
-	if polName != "" {
-		grps := ing.Metadata.Annotations[AnnotationHubAuthGroup]
-
-		var middlewareName string
-		middlewareName, err = r.fwdAuthMiddlewares.Setup(ctx, polName, ing.Metadata.Namespace, grps)
+	// Each ACP of the chain has its own middlewares, Traefik applies them in order.
+	grps := ing.Metadata.Annotations[AnnotationHubAuthGroup]
+	for _, name := range PolicyNames(polName) {
+		var middlewareNames []string
+		middlewareNames, err = r.fwdAuthMiddlewares.Setup(ctx, name, ing.Metadata.Namespace, grps)
 		if err != nil {
 			return nil, err
 		}
 
-		routerMiddlewares = appendMiddleware(
-			routerMiddlewares,
-			fmt.Sprintf("%s-%s@kubernetescrd", ing.Metadata.Namespace, middlewareName),
-		)
+		for _, middlewareName := range middlewareNames {
+			routerMiddlewares = appendMiddleware(
+				routerMiddlewares,
+				fmt.Sprintf("%s-%s@kubernetescrd", ing.Metadata.Namespace, middlewareName),
+			)
+		}
 	}
 
 	if ing.Metadata.Annotations[annotationTraefikMiddlewares] == routerMiddlewares {
@@ -170,10 +172,15 @@ func (r TraefikIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (m
 func (r TraefikIngress) clearPreviousFwdAuthMiddleware(ctx context.Context, polName, namespace, routerMiddlewares string) string {
 	log.Ctx(ctx).Debug().Str("prev_acp_name", polName).Msg("Clearing previous ACP settings")
 
-	middlewareName := middlewareName(polName)
-	oldCanonicalMiddlewareName := fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName)
+	for _, name := range PolicyNames(polName) {
+		for _, mdlwrName := range []string{middlewareName(name), headersMiddlewareName(name)} {
+			oldCanonicalMiddlewareName := fmt.Sprintf("%s-%s@kubernetescrd", namespace, mdlwrName)
 
-	return removeMiddleware(routerMiddlewares, oldCanonicalMiddlewareName)
+			routerMiddlewares = removeMiddleware(routerMiddlewares, oldCanonicalMiddlewareName)
+		}
+	}
+
+	return routerMiddlewares
 }
 
 // appendMiddleware appends newMiddleware to the comma-separated list of middlewareList.
@@ -198,9 +205,14 @@ func removeMiddleware(middlewareList, toRemove string) string {
 	return strings.Join(res, ",")
 }
 
-// middlewareName returns the ForwardAuth middleware desc for the given ACP.
+// middlewareName returns the ForwardAuth middleware desc for the given ACP, or chain of ACPs.
 func middlewareName(polName string) string {
-	return fmt.Sprintf("zz-%s", strings.ReplaceAll(polName, "@", "-"))
+	return fmt.Sprintf("zz-%s", strings.NewReplacer("@", "-", ",", ".").Replace(polName))
+}
+
+// headersMiddlewareName returns the name of the Headers middleware transforming the response headers for the given ACP.
+func headersMiddlewareName(polName string) string {
+	return middlewareName(polName) + "-headers"
 }
 
 func isTraefik(ctrlr string) bool {
@@ -76,17 +76,23 @@ func (r TraefikIngressRoute) Review(ctx context.Context, ar admv1.AdmissionRevie
 		updated = r.clearPreviousFwdAuthMiddleware(ctx, &ingRoute.Spec, prevPolName, ingRoute.Namespace)
 	}
 
-	var mdlwrName string
-	if polName != "" {
-		grps := ingRoute.Annotations[AnnotationHubAuthGroup]
-
-		mdlwrName, err = r.fwdAuthMiddlewares.Setup(ctx, polName, ingRoute.Namespace, grps)
+	// Each ACP of the chain has its own middlewares, Traefik applies them in order.
+	grps := ingRoute.Annotations[AnnotationHubAuthGroup]
+	for _, name := range PolicyNames(polName) {
+		var mdlwrNames []string
+		mdlwrNames, err = r.fwdAuthMiddlewares.Setup(ctx, name, ingRoute.Namespace, grps)
 		if err != nil {
 			return nil, err
 		}
+
+		for _, mdlwrName := range mdlwrNames {
+			if updateIngressRoute(&ingRoute.Spec, mdlwrName, ingRoute.Namespace) {
+				updated = true
+			}
+		}
 	}
 
-	if !updateIngressRoute(&ingRoute.Spec, mdlwrName, ingRoute.Namespace) && !updated {
+	if !updated {
 		logger.Debug().Str("acp_name", polName).Msg("No patch required")
 		return nil, nil
 	}
@@ -125,12 +131,16 @@ func updateIngressRoute(spec *traefikv1alpha1.IngressRouteSpec, name, namespace
 func (r TraefikIngressRoute) clearPreviousFwdAuthMiddleware(ctx context.Context, spec *traefikv1alpha1.IngressRouteSpec, oldPolName, namespace string) (updated bool) {
 	log.Ctx(ctx).Debug().Str("prev_acp_name", oldPolName).Msg("Clearing previous ACP settings")
 
-	mdlwrName := middlewareName(oldPolName)
+	mdlwrNames := make(map[string]struct{})
+	for _, name := range PolicyNames(oldPolName) {
+		mdlwrNames[middlewareName(name)] = struct{}{}
+		mdlwrNames[headersMiddlewareName(name)] = struct{}{}
+	}
 
 	for i, route := range spec.Routes {
 		var refs []traefikv1alpha1.MiddlewareRef
 		for _, middleware := range route.Middlewares {
-			if middleware.Name == mdlwrName && middleware.Namespace == namespace {
+			if _, ok := mdlwrNames[middleware.Name]; ok && middleware.Namespace == namespace {
 				updated = true
 				continue
 			}
@@ -27,6 +27,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/headers"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
@@ -350,6 +351,67 @@ func TestTraefikIngressRoute_ReviewAddsAuthentication(t *testing.T) {
 	}
 }
 
+func TestTraefikIngressRoute_ReviewAddsHeadersMiddleware(t *testing.T) {
+	traefikClientSet := traefikcrdfake.NewSimpleClientset()
+
+	policies := newPolicyGetterMock(t)
+	policies.OnGetConfig("my-policy").TypedReturns(&acp.Config{
+		JWT: &jwt.Config{},
+		Headers: &headers.Config{
+			Request: &headers.RequestConfig{
+				Set: map[string]string{"X-User": `{{ claim "sub" }}`},
+			},
+			Response: &headers.ResponseConfig{
+				Set:    map[string]string{"X-Frame-Options": "DENY"},
+				Remove: []string{"Server"},
+			},
+		},
+	}, nil).Once()
+
+	rev := NewTraefikIngressRoute(NewFwdAuthMiddlewares("", policies, traefikClientSet.TraefikV1alpha1()))
+
+	ing := traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "name",
+			Namespace:   "test",
+			Annotations: map[string]string{"hub.traefik.io/access-control-policy": "my-policy"},
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			Routes: []traefikv1alpha1.Route{{Match: "Host(`example.com`)"}},
+		},
+	}
+	b, err := json.Marshal(ing)
+	require.NoError(t, err)
+
+	ar := admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: b},
+		},
+	}
+
+	patch, err := rev.Review(context.Background(), ar)
+	require.NoError(t, err)
+
+	wantRoutes := []traefikv1alpha1.Route{
+		{
+			Match: "Host(`example.com`)",
+			Middlewares: []traefikv1alpha1.MiddlewareRef{
+				{Name: "zz-my-policy", Namespace: "test"},
+				{Name: "zz-my-policy-headers", Namespace: "test"},
+			},
+		},
+	}
+	assert.Equal(t, wantRoutes, patch["value"])
+
+	m, err := traefikClientSet.TraefikV1alpha1().Middlewares("test").Get(context.Background(), "zz-my-policy", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"X-User"}, m.Spec.ForwardAuth.AuthResponseHeaders)
+
+	m, err = traefikClientSet.TraefikV1alpha1().Middlewares("test").Get(context.Background(), "zz-my-policy-headers", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Frame-Options": "DENY", "Server": ""}, m.Spec.Headers.CustomResponseHeaders)
+}
+
 func TestTraefikIngressRoute_ReviewUpdatesExistingMiddleware(t *testing.T) {
 	tests := []struct {
 		desc                    string
@@ -545,6 +545,69 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 	}
 }
 
+func TestTraefikIngress_ReviewChainsACPs(t *testing.T) {
+	traefikClientSet := traefikcrdfake.NewSimpleClientset()
+
+	policies := newPolicyGetterMock(t)
+	policies.OnGetConfig("ip-allowlist").TypedReturns(&acp.Config{BasicAuth: &basicauth.Config{}}, nil).Once()
+	policies.OnGetConfig("jwt").TypedReturns(&acp.Config{JWT: &jwt.Config{
+		ForwardHeaders: map[string]string{"fwdHeader": "claim"},
+	}}, nil).Once()
+
+	fwdAuthMdlwrs := NewFwdAuthMiddlewares("auth.server.svc", policies, traefikClientSet.TraefikV1alpha1())
+
+	rev := NewTraefikIngress(newIngressClassesMock(t), fwdAuthMdlwrs)
+
+	oldB, err := json.Marshal(netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "test",
+			Annotations: map[string]string{
+				AnnotationHubAuth: "ip-allowlist",
+				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-ip-allowlist@kubernetescrd",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	b, err := json.Marshal(netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "test",
+			Annotations: map[string]string{
+				AnnotationHubAuth: "ip-allowlist, jwt",
+				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-ip-allowlist@kubernetescrd",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ar := admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			Object:    runtime.RawExtension{Raw: b},
+			OldObject: runtime.RawExtension{Raw: oldB},
+		},
+	}
+
+	patch, err := rev.Review(context.Background(), ar)
+	require.NoError(t, err)
+
+	wantAnno := map[string]string{
+		AnnotationHubAuth: "ip-allowlist, jwt",
+		"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-ip-allowlist@kubernetescrd,test-zz-jwt@kubernetescrd",
+	}
+	assert.Equal(t, wantAnno, patch["value"])
+
+	m, err := traefikClientSet.TraefikV1alpha1().Middlewares("test").Get(context.Background(), "zz-ip-allowlist", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "auth.server.svc/ip-allowlist", m.Spec.ForwardAuth.Address)
+
+	m, err = traefikClientSet.TraefikV1alpha1().Middlewares("test").Get(context.Background(), "zz-jwt", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "auth.server.svc/jwt", m.Spec.ForwardAuth.Address)
+	assert.Equal(t, []string{"fwdHeader"}, m.Spec.ForwardAuth.AuthResponseHeaders)
+}
+
 func TestTraefikIngress_ReviewUpdatesExistingMiddleware(t *testing.T) {
 	tests := []struct {
 		desc                    string
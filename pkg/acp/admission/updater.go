@@ -165,13 +165,11 @@ func (u *IngressUpdater) updateV1beta1Ingresses(ctx context.Context, polName str
 }
 
 func shouldUpdate(hubAuthAnno, polName string) bool {
-	if hubAuthAnno == "" {
-		return false
-	}
-
-	if hubAuthAnno != polName {
-		return false
+	for _, name := range reviewer.PolicyNames(hubAuthAnno) {
+		if name == polName {
+			return true
+		}
 	}
 
-	return true
+	return false
 }
@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var virtualServiceResource = schema.GroupVersionResource{
+	Group:    "networking.istio.io",
+	Version:  "v1alpha3",
+	Resource: "virtualservices",
+}
+
+// VirtualServiceController keeps the EnvoyFilters enforcing ACPs in sync with the Istio VirtualServices.
+// The VirtualService reviewer sets them up on admission, the controller takes care of the VirtualServices created
+// before the agent and of the EnvoyFilters left behind by deleted VirtualServices.
+type VirtualServiceController struct {
+	client       dynamic.Interface
+	envoyFilters reviewer.IstioEnvoyFilters
+	interval     time.Duration
+}
+
+// NewVirtualServiceController returns a new VirtualServiceController.
+func NewVirtualServiceController(client dynamic.Interface, envoyFilters reviewer.IstioEnvoyFilters, interval time.Duration) *VirtualServiceController {
+	return &VirtualServiceController{
+		client:       client,
+		envoyFilters: envoyFilters,
+		interval:     interval,
+	}
+}
+
+// Run runs the VirtualServiceController control loop.
+func (c *VirtualServiceController) Run(ctx context.Context) {
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+
+	for {
+		if err := c.reconcile(ctx); err != nil {
+			log.Error().Err(err).Msg("Unable to reconcile VirtualService EnvoyFilters")
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *VirtualServiceController) reconcile(ctx context.Context) error {
+	list, err := c.client.Resource(virtualServiceResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list VirtualServices: %w", err)
+	}
+
+	var setup bool
+	protected := make(map[string]struct{})
+	for _, vs := range list.Items {
+		polName := vs.GetAnnotations()[reviewer.AnnotationHubAuth]
+		if polName == "" {
+			continue
+		}
+
+		if !setup {
+			if err = c.envoyFilters.Setup(ctx); err != nil {
+				return fmt.Errorf("setup ext_authz EnvoyFilter: %w", err)
+			}
+			setup = true
+		}
+
+		hosts, _, err := unstructured.NestedStringSlice(vs.Object, "spec", "hosts")
+		if err != nil {
+			log.Error().Err(err).
+				Str("virtual_service_name", vs.GetName()).
+				Str("virtual_service_namespace", vs.GetNamespace()).
+				Msg("Unable to read VirtualService hosts")
+			continue
+		}
+
+		groups := vs.GetAnnotations()[reviewer.AnnotationHubAuthGroup]
+		if err = c.envoyFilters.SetupVirtualService(ctx, vs.GetNamespace(), vs.GetName(), hosts, polName, groups); err != nil {
+			log.Error().Err(err).
+				Str("virtual_service_name", vs.GetName()).
+				Str("virtual_service_namespace", vs.GetNamespace()).
+				Msg("Unable to set up VirtualService EnvoyFilter")
+		}
+
+		protected[vs.GetNamespace()+"/"+vs.GetName()] = struct{}{}
+	}
+
+	filtered, err := c.envoyFilters.ListVirtualServices(ctx)
+	if err != nil {
+		return fmt.Errorf("list VirtualServices having an EnvoyFilter: %w", err)
+	}
+
+	for _, key := range filtered {
+		if _, ok := protected[key]; ok {
+			continue
+		}
+
+		namespace, name, _ := strings.Cut(key, "/")
+		if err = c.envoyFilters.DeleteVirtualService(ctx, namespace, name); err != nil {
+			log.Error().Err(err).
+				Str("virtual_service_name", name).
+				Str("virtual_service_namespace", namespace).
+				Msg("Unable to delete stale VirtualService EnvoyFilter")
+		}
+	}
+
+	return nil
+}
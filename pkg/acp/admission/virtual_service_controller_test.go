@@ -0,0 +1,85 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestVirtualServiceController_reconcile(t *testing.T) {
+	envoyFilterResource := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "envoyfilters"}
+
+	client := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			virtualServiceResource: "VirtualServiceList",
+			envoyFilterResource:    "EnvoyFilterList",
+		},
+		newVirtualService("default", "protected", map[string]interface{}{
+			reviewer.AnnotationHubAuth: "my-acp",
+		}),
+		newVirtualService("default", "unprotected", nil),
+	)
+
+	envoyFilters, err := reviewer.NewIstioEnvoyFilters("http://hub-agent-auth-server.hub", 9000, "istio-system", client)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// EnvoyFilter left behind by a deleted VirtualService.
+	err = envoyFilters.SetupVirtualService(ctx, "default", "deleted", []string{"deleted.example.com"}, "my-acp", "")
+	require.NoError(t, err)
+
+	controller := NewVirtualServiceController(client, envoyFilters, 0)
+	require.NoError(t, controller.reconcile(ctx))
+
+	got, err := envoyFilters.ListVirtualServices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default/protected"}, got)
+
+	_, err = client.Resource(envoyFilterResource).Namespace("istio-system").Get(ctx, "hub-ext-authz", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func newVirtualService(namespace, name string, annotations map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+	}
+	if annotations != nil {
+		metadata["annotations"] = annotations
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "networking.istio.io/v1alpha3",
+		"kind":       "VirtualService",
+		"metadata":   metadata,
+		"spec": map[string]interface{}{
+			"hosts": []interface{}{name + ".example.com"},
+		},
+	}}
+}
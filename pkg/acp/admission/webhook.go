@@ -41,13 +41,17 @@ type Reviewer interface {
 type Handler struct {
 	reviewers       []Reviewer
 	defaultReviewer Reviewer
+	dryRun          bool
 }
 
 // NewHandler returns a new Handler that reviews incoming requests using the given reviewers.
-func NewHandler(reviewers []Reviewer, defaultReviewer Reviewer) *Handler {
+// In dry-run mode, patches computed by reviewers are logged along with the changes they would make but are never
+// applied to the reviewed objects.
+func NewHandler(reviewers []Reviewer, defaultReviewer Reviewer, dryRun bool) *Handler {
 	return &Handler{
 		reviewers:       reviewers,
 		defaultReviewer: defaultReviewer,
+		dryRun:          dryRun,
 	}
 }
 
@@ -115,6 +119,17 @@ func (h Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// Review reviews the given admission review request and returns the JSON patch to apply to the reviewed resource, if
+// any, along with warnings. It allows reviewing resources outside of the admission webhook.
+func (h Handler) Review(ctx context.Context, ar admv1.AdmissionReview) ([]byte, []string, error) {
+	resp, err := h.review(ctx, ar)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp.Patch, resp.Warnings, nil
+}
+
 type reviewResponse struct {
 	Patch    []byte
 	Warnings []string
@@ -156,11 +171,23 @@ func (h Handler) review(ctx context.Context, ar admv1.AdmissionReview) (*reviewR
 		return &resp, nil
 	}
 
-	resp.Patch, err = json.Marshal([]map[string]interface{}{resourcePatch})
+	patch, err := json.Marshal([]map[string]interface{}{resourcePatch})
 	if err != nil {
 		return nil, fmt.Errorf("serialize patches: %w", err)
 	}
 
+	if h.dryRun {
+		logDryRunPatch(ctx, ar.Request.Object.Raw, patch)
+
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf(
+			"dry-run mode: ACP patch not applied to resource %q of kind %q in namespace %q",
+			ar.Request.Name, ar.Request.Kind, ar.Request.Namespace))
+
+		return &resp, nil
+	}
+
+	resp.Patch = patch
+
 	return &resp, nil
 }
 
@@ -167,6 +167,7 @@ func (h ACPHandler) review(ctx context.Context, req *admv1.AdmissionRequest) ([]
 			return nil, fmt.Errorf("update ACP: %w", err)
 		}
 		newACP.Status.Version = a.Version
+		newACP.Status.Conditions = oldACP.Status.Conditions
 
 		return h.buildPatches(newACP)
 
@@ -192,6 +193,12 @@ func (h ACPHandler) buildPatches(policy *hubv1alpha1.AccessControlPolicy) ([]byt
 	if err != nil {
 		return nil, fmt.Errorf("create Spec Hash: %w", err)
 	}
+	status.Conditions = hubv1alpha1.MergeConditions(policy.Status.Conditions,
+		hubv1alpha1.NewCondition(hubv1alpha1.ConditionSynced, metav1.ConditionTrue, hubv1alpha1.ReasonSynced,
+			"Resource is synchronized with the platform", status.SyncedAt),
+		hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionTrue, hubv1alpha1.ReasonReady,
+			"Resource is ready", status.SyncedAt),
+	)
 
 	patches := []patch{
 		{Op: "replace", Path: "/status", Value: status},
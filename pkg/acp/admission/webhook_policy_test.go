@@ -105,6 +105,12 @@ func TestWebhookPolicy_ServeHTTP_Create(t *testing.T) {
 				Version:  "version-1",
 				SyncedAt: metav1.NewTime(now),
 				SpecHash: hash,
+				Conditions: []metav1.Condition{
+					hubv1alpha1.NewCondition(hubv1alpha1.ConditionSynced, metav1.ConditionTrue, hubv1alpha1.ReasonSynced,
+						"Resource is synchronized with the platform", metav1.NewTime(now)),
+					hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionTrue, hubv1alpha1.ReasonReady,
+						"Resource is ready", metav1.NewTime(now)),
+				},
 			}},
 		}),
 	}
@@ -221,6 +227,12 @@ func TestWebhookPolicy_ServeHTTP_Update(t *testing.T) {
 				Version:  "newVersion",
 				SyncedAt: metav1.NewTime(now),
 				SpecHash: hash,
+				Conditions: []metav1.Condition{
+					hubv1alpha1.NewCondition(hubv1alpha1.ConditionSynced, metav1.ConditionTrue, hubv1alpha1.ReasonSynced,
+						"Resource is synchronized with the platform", metav1.NewTime(now)),
+					hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionTrue, hubv1alpha1.ReasonReady,
+						"Resource is ready", metav1.NewTime(now)),
+				},
 			}},
 		}),
 	}
@@ -0,0 +1,362 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/headers"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"golang.org/x/net/http/httpguts"
+	admv1 "k8s.io/api/admission/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ACPValidationHandler is an HTTP handler that can be used as a Kubernetes Validating Admission Controller.
+// It rejects invalid ACPs when they are applied rather than when they are synchronized with the platform.
+type ACPValidationHandler struct{}
+
+// NewACPValidationHandler returns a new ACPValidationHandler.
+func NewACPValidationHandler() *ACPValidationHandler {
+	return &ACPValidationHandler{}
+}
+
+// ServeHTTP implements http.Handler.
+func (h ACPValidationHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// We always decode the admission request in an admv1 object regardless
+	// of the request version as it is strictly identical to the admv1beta1 object.
+	var ar admv1.AdmissionReview
+	if err := json.NewDecoder(req.Body).Decode(&ar); err != nil {
+		log.Error().Err(err).Msg("Unable to decode admission request")
+		http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	l := log.Logger.With().Str("uid", string(ar.Request.UID)).Logger()
+	if ar.Request != nil {
+		l = l.With().
+			Str("resource_kind", ar.Request.Kind.String()).
+			Str("resource_name", ar.Request.Name).
+			Logger()
+	}
+	ctx := l.WithContext(req.Context())
+
+	ar.Response = &admv1.AdmissionResponse{
+		Allowed: true,
+		UID:     ar.Request.UID,
+	}
+
+	if err := h.validate(ar.Request); err != nil {
+		log.Ctx(ctx).Info().Err(err).Msg("Rejecting invalid AccessControlPolicy")
+
+		status := metav1.Status{
+			Status:  "Failure",
+			Message: err.Error(),
+		}
+
+		var statusErr *kerror.StatusError
+		if errors.As(err, &statusErr) {
+			status = statusErr.Status()
+		}
+
+		ar.Response.Allowed = false
+		ar.Response.Result = &status
+	}
+
+	if err := json.NewEncoder(rw).Encode(ar); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to encode admission response")
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h ACPValidationHandler) validate(req *admv1.AdmissionRequest) error {
+	if !isACPRequest(req.Kind) {
+		return fmt.Errorf("unsupported resource %s", req.Kind.String())
+	}
+
+	if req.Operation == admv1.Delete {
+		return nil
+	}
+
+	var policy hubv1alpha1.AccessControlPolicy
+	if err := json.Unmarshal(req.Object.Raw, &policy); err != nil {
+		return fmt.Errorf("unmarshal reviewed ACP: %w", err)
+	}
+
+	// ACPs synchronized from the platform have already been validated by the platform.
+	hash, err := policy.Spec.Hash()
+	if err != nil {
+		return fmt.Errorf("build hash ACP spec: %w", err)
+	}
+	if hash == policy.Status.SpecHash {
+		return nil
+	}
+
+	errs := validateACPSpec(policy.Spec, field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+
+	gk := schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind}
+	return kerror.NewInvalid(gk, policy.Name, errs)
+}
+
+// validateACPSpec validates the given ACP spec.
+func validateACPSpec(spec hubv1alpha1.AccessControlPolicySpec, path *field.Path) field.ErrorList {
+	var (
+		errs      field.ErrorList
+		authTypes []string
+	)
+
+	if spec.JWT != nil {
+		authTypes = append(authTypes, "jwt")
+		errs = append(errs, validateJWT(spec.JWT, path.Child("jwt"))...)
+	}
+	if spec.BasicAuth != nil {
+		authTypes = append(authTypes, "basicAuth")
+		errs = append(errs, validateBasicAuth(spec.BasicAuth, path.Child("basicAuth"))...)
+	}
+	if spec.APIKey != nil {
+		authTypes = append(authTypes, "apiKey")
+		errs = append(errs, validateForwardHeaders(spec.APIKey.ForwardHeaders, "metadata key", path.Child("apiKey", "forwardHeaders"))...)
+	}
+	if spec.OIDC != nil {
+		authTypes = append(authTypes, "oidc")
+		errs = append(errs, validateClaims(spec.OIDC.Claims, path.Child("oidc", "claims"))...)
+		errs = append(errs, validateForwardHeaders(spec.OIDC.ForwardHeaders, "claim", path.Child("oidc", "forwardHeaders"))...)
+	}
+	if spec.OIDCGoogle != nil {
+		authTypes = append(authTypes, "oidcGoogle")
+		errs = append(errs, validateForwardHeaders(spec.OIDCGoogle.ForwardHeaders, "claim", path.Child("oidcGoogle", "forwardHeaders"))...)
+	}
+	if spec.OAuthIntro != nil {
+		authTypes = append(authTypes, "oAuthIntro")
+		errs = append(errs, validateClaims(spec.OAuthIntro.Claims, path.Child("oAuthIntro", "claims"))...)
+		errs = append(errs, validateForwardHeaders(spec.OAuthIntro.ForwardHeaders, "claim", path.Child("oAuthIntro", "forwardHeaders"))...)
+	}
+
+	if spec.Headers != nil {
+		errs = append(errs, validateHeaders(spec.Headers, path.Child("headers"))...)
+	}
+
+	switch len(authTypes) {
+	case 0:
+		errs = append(errs, field.Required(path,
+			"an authentication method must be set: one of jwt, basicAuth, apiKey, oidc, oidcGoogle or oAuthIntro"))
+	case 1:
+	default:
+		errs = append(errs, field.Forbidden(path, fmt.Sprintf(
+			"conflicting authentication methods %s: an ACP supports a single authentication method, "+
+				"create one ACP per authentication method instead", strings.Join(authTypes, ", "))))
+	}
+
+	return errs
+}
+
+func validateJWT(cfg *hubv1alpha1.AccessControlPolicyJWT, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if cfg.SigningSecret == "" && cfg.PublicKey == "" && cfg.JWKsFile == "" && cfg.JWKsURL == "" {
+		errs = append(errs, field.Required(path, "one of signingSecret, publicKey, jwksFile or jwksUrl must be set"))
+	}
+
+	// Encrypted values are only decrypted by the agent, their format can't be checked.
+	if cfg.SigningSecret != "" && cfg.SigningSecretBase64Encoded && !acp.IsEncrypted(cfg.SigningSecret) {
+		if _, err := base64.StdEncoding.DecodeString(cfg.SigningSecret); err != nil {
+			errs = append(errs, field.Invalid(path.Child("signingSecret"), field.OmitValueType{},
+				"signingSecretBase64Encoded is set but the signing secret is not valid base64"))
+		}
+	}
+
+	if cfg.PublicKey != "" {
+		if err := validatePublicKey(cfg.PublicKey); err != nil {
+			errs = append(errs, field.Invalid(path.Child("publicKey"), field.OmitValueType{}, err.Error()))
+		}
+	}
+
+	errs = append(errs, validateClaims(cfg.Claims, path.Child("claims"))...)
+	errs = append(errs, validateForwardHeaders(cfg.ForwardHeaders, "claim", path.Child("forwardHeaders"))...)
+
+	return errs
+}
+
+func validatePublicKey(key string) error {
+	block, rest := pem.Decode([]byte(key))
+	if block == nil {
+		return fmt.Errorf("malformed public key: expected a PEM encoded key starting with %q", "-----BEGIN PUBLIC KEY-----")
+	}
+	if len(strings.TrimSpace(string(rest))) > 0 {
+		return fmt.Errorf("malformed public key: unexpected content after the %q PEM block", block.Type)
+	}
+
+	// The parsing error is left out as it only gives low level ASN.1 details.
+	if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		return fmt.Errorf("malformed public key: the %q PEM block does not hold a PKIX RSA, ECDSA or Ed25519 public key", block.Type)
+	}
+
+	return nil
+}
+
+func validateBasicAuth(cfg *hubv1alpha1.AccessControlPolicyBasicAuth, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if len(cfg.Users) == 0 {
+		errs = append(errs, field.Required(path.Child("users"), "at least one user must be set"))
+	}
+
+	for i, user := range cfg.Users {
+		// Encrypted values are only decrypted by the agent, their format can't be checked.
+		if acp.IsEncrypted(user) {
+			continue
+		}
+
+		if name, hash, ok := strings.Cut(user, ":"); !ok || name == "" || hash == "" || strings.Contains(hash, ":") {
+			errs = append(errs, field.Invalid(path.Child("users").Index(i), field.OmitValueType{},
+				`expected "username:hashed-password", as generated by htpasswd`))
+		}
+	}
+
+	if cfg.ForwardUsernameHeader != "" && !httpguts.ValidHeaderFieldName(cfg.ForwardUsernameHeader) {
+		errs = append(errs, field.Invalid(path.Child("forwardUsernameHeader"), cfg.ForwardUsernameHeader, "invalid header name"))
+	}
+
+	return errs
+}
+
+func validateClaims(claims string, path *field.Path) field.ErrorList {
+	if claims == "" {
+		return nil
+	}
+
+	if _, err := expr.Parse(claims); err != nil {
+		return field.ErrorList{field.Invalid(path, claims, err.Error())}
+	}
+
+	return nil
+}
+
+func validateHeaders(cfg *hubv1alpha1.AccessControlPolicyHeaders, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if req := cfg.Request; req != nil {
+		reqPath := path.Child("request")
+
+		for _, name := range sortedKeys(req.Set) {
+			if !httpguts.ValidHeaderFieldName(name) {
+				errs = append(errs, field.Invalid(reqPath.Child("set").Key(name), name, "invalid header name"))
+				continue
+			}
+			if _, err := headers.ParseTemplate(req.Set[name]); err != nil {
+				errs = append(errs, field.Invalid(reqPath.Child("set").Key(name), req.Set[name], err.Error()))
+			}
+		}
+
+		errs = append(errs, validateHeaderNames(req.Remove, reqPath.Child("remove"))...)
+
+		for _, from := range sortedKeys(req.Rename) {
+			if !httpguts.ValidHeaderFieldName(from) {
+				errs = append(errs, field.Invalid(reqPath.Child("rename").Key(from), from, "invalid header name"))
+				continue
+			}
+			if to := req.Rename[from]; !httpguts.ValidHeaderFieldName(to) {
+				errs = append(errs, field.Invalid(reqPath.Child("rename").Key(from), to, "invalid header name"))
+			}
+		}
+	}
+
+	if resp := cfg.Response; resp != nil {
+		respPath := path.Child("response")
+
+		for _, name := range sortedKeys(resp.Set) {
+			if !httpguts.ValidHeaderFieldName(name) {
+				errs = append(errs, field.Invalid(respPath.Child("set").Key(name), name, "invalid header name"))
+				continue
+			}
+			if resp.Set[name] == "" {
+				errs = append(errs, field.Required(respPath.Child("set").Key(name), "use remove to remove a response header"))
+			}
+		}
+
+		errs = append(errs, validateHeaderNames(resp.Remove, respPath.Child("remove"))...)
+	}
+
+	return errs
+}
+
+func validateHeaderNames(names []string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, name := range names {
+		if !httpguts.ValidHeaderFieldName(name) {
+			errs = append(errs, field.Invalid(path.Index(i), name, "invalid header name"))
+		}
+	}
+
+	return errs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// validateForwardHeaders validates forwarded headers, mapping header names to a source of the given kind.
+func validateForwardHeaders(headers map[string]string, sourceKind string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	seen := make(map[string]string, len(headers))
+	for _, name := range sortedKeys(headers) {
+		if !httpguts.ValidHeaderFieldName(name) {
+			errs = append(errs, field.Invalid(path.Key(name), name,
+				"invalid header name: it must be a non-empty HTTP token, without spaces or separators such as ':'"))
+			continue
+		}
+
+		canonical := http.CanonicalHeaderKey(name)
+		if other, ok := seen[canonical]; ok {
+			errs = append(errs, field.Duplicate(path.Key(name),
+				fmt.Sprintf("%s (header names are case-insensitive, %q is already forwarded)", name, other)))
+			continue
+		}
+		seen[canonical] = name
+
+		if strings.TrimSpace(headers[name]) == "" {
+			errs = append(errs, field.Required(path.Key(name), fmt.Sprintf("the %s to forward in the header must be set", sourceKind)))
+		}
+	}
+
+	return errs
+}
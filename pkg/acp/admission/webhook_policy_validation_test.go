@@ -0,0 +1,332 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const testPublicKey = `-----BEGIN PUBLIC KEY-----
+MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAnzyis1ZjfNB0bBgKFMSv
+vkTtwlvBsaJq7S5wA+kzeVOVpVWwkWdVha4s38XM/pa/yr47av7+z3VTmvDRyAHc
+aT92whREFpLv9cj5lTeJSibyr/Mrm/YtjCZVWgaOYIhwrXwKLqPr/11inWsAkfIy
+tvHWTxZYEcXLgAXFuUuaS3uF9gEiNQwzGTU1v0FqkqTBr4B8nW3HCN47XUu0t8Y0
+e+lf4s4OxQawWD79J9/5d3Ry0vbV3Am1FtGJiJvOwRsIfVChDpYStTcHTCMqtvWb
+V6L11BWkpzGXSW4Hv43qa+GSYOD2QU68Mb59oSk2OB+BtOLpJofmbGEGgvmwyCI9
+MwIDAQAB
+-----END PUBLIC KEY-----
+`
+
+func TestACPValidationHandler_ServeHTTP(t *testing.T) {
+	encryptionKey := []byte("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		desc       string
+		operation  admv1.Operation
+		spec       hubv1alpha1.AccessControlPolicySpec
+		synced     bool
+		wantCauses []metav1.StatusCause
+	}{
+		{
+			desc:      "valid JWT ACP",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{
+					PublicKey:      testPublicKey,
+					Claims:         "Equals(`group`, `dev`)",
+					ForwardHeaders: map[string]string{"X-Group": "group"},
+				},
+			},
+		},
+		{
+			desc:      "valid basic auth ACP",
+			operation: admv1.Update,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+					Users:                 []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+					ForwardUsernameHeader: "X-User",
+				},
+			},
+		},
+		{
+			desc:      "encrypted JWT signing secret",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{
+					SigningSecret:              mustEncrypt(t, encryptionKey, "c2VjcmV0"),
+					SigningSecretBase64Encoded: true,
+				},
+			},
+		},
+		{
+			desc:      "encrypted basic auth users",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+					Users: []string{
+						mustEncrypt(t, encryptionKey, "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"),
+						"test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0",
+					},
+				},
+			},
+		},
+		{
+			desc:      "encrypted API keys",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				APIKey: &hubv1alpha1.AccessControlPolicyAPIKey{
+					KeySource: hubv1alpha1.TokenSource{Header: "Api-Key"},
+					Keys: []hubv1alpha1.AccessControlPolicyAPIKeyKey{
+						{ID: "key", Value: mustEncrypt(t, encryptionKey, "2f0f2a6f8c3c3e9c")},
+					},
+				},
+			},
+		},
+		{
+			desc:      "plaintext users are still checked along with encrypted ones",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+					Users: []string{
+						mustEncrypt(t, encryptionKey, "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"),
+						"test2",
+					},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: expected "username:hashed-password", as generated by htpasswd`,
+					Field:   "spec.basicAuth.users[1]",
+				},
+			},
+		},
+		{
+			desc:      "no authentication method",
+			operation: admv1.Create,
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueRequired,
+					Message: "Required value: an authentication method must be set: one of jwt, basicAuth, apiKey, oidc, oidcGoogle or oAuthIntro",
+					Field:   "spec",
+				},
+			},
+		},
+		{
+			desc:      "conflicting authentication methods",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{SigningSecret: "secret"},
+				BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+					Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseType(field.ErrorTypeForbidden),
+					Message: "Forbidden: conflicting authentication methods jwt, basicAuth: an ACP supports a single authentication method, create one ACP per authentication method instead",
+					Field:   "spec",
+				},
+			},
+		},
+		{
+			desc:      "malformed public key",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{PublicKey: "MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAnzyis1ZjfNB0bBgKFMSv"},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: malformed public key: expected a PEM encoded key starting with "-----BEGIN PUBLIC KEY-----"`,
+					Field:   "spec.jwt.publicKey",
+				},
+			},
+		},
+		{
+			desc:      "public key which is not a PKIX public key",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{PublicKey: "-----BEGIN PUBLIC KEY-----\nZm9v\n-----END PUBLIC KEY-----\n"},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: malformed public key: the "PUBLIC KEY" PEM block does not hold a PKIX RSA, ECDSA or Ed25519 public key`,
+					Field:   "spec.jwt.publicKey",
+				},
+			},
+		},
+		{
+			desc:      "bad forward headers",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				OIDC: &hubv1alpha1.AccessControlPolicyOIDC{
+					ForwardHeaders: map[string]string{
+						"X-User":   "sub",
+						"x-user":   "email",
+						"X Group":  "group",
+						"X-Tenant": "",
+					},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: "X Group": invalid header name: it must be a non-empty HTTP token, without spaces or separators such as ':'`,
+					Field:   "spec.oidc.forwardHeaders[X Group]",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueRequired,
+					Message: "Required value: the claim to forward in the header must be set",
+					Field:   "spec.oidc.forwardHeaders[X-Tenant]",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueDuplicate,
+					Message: `Duplicate value: "x-user (header names are case-insensitive, \"X-User\" is already forwarded)"`,
+					Field:   "spec.oidc.forwardHeaders[x-user]",
+				},
+			},
+		},
+		{
+			desc:      "bad header transformations",
+			operation: admv1.Create,
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{SigningSecret: "secret"},
+				Headers: &hubv1alpha1.AccessControlPolicyHeaders{
+					Request: &hubv1alpha1.AccessControlPolicyRequestHeaders{
+						Set:    map[string]string{"X-User": `{{ claim "sub"`},
+						Rename: map[string]string{"X-Old": "X New"},
+					},
+					Response: &hubv1alpha1.AccessControlPolicyResponseHeaders{
+						Remove: []string{"Server:"},
+					},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: "{{ claim \"sub\"": template: header:1: unclosed action`,
+					Field:   "spec.headers.request.set[X-User]",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: "X New": invalid header name`,
+					Field:   "spec.headers.request.rename[X-Old]",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: "Server:": invalid header name`,
+					Field:   "spec.headers.response.remove[0]",
+				},
+			},
+		},
+		{
+			desc:      "ACP synchronized from the platform",
+			operation: admv1.Update,
+			synced:    true,
+		},
+		{
+			desc:      "deleted ACP",
+			operation: admv1.Delete,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			policy := hubv1alpha1.AccessControlPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "acp"},
+				Spec:       test.spec,
+			}
+			if test.synced {
+				hash, err := policy.Spec.Hash()
+				require.NoError(t, err)
+				policy.Status.SpecHash = hash
+			}
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					UID: "id",
+					Kind: metav1.GroupVersionKind{
+						Group:   "hub.traefik.io",
+						Version: "v1alpha1",
+						Kind:    "AccessControlPolicy",
+					},
+					Name:      "acp",
+					Operation: test.operation,
+				},
+			}
+			if test.operation == admv1.Delete {
+				ar.Request.OldObject = runtime.RawExtension{Raw: mustMarshal(t, policy)}
+			} else {
+				ar.Request.Object = runtime.RawExtension{Raw: mustMarshal(t, policy)}
+			}
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/", bytes.NewBuffer(mustMarshal(t, ar)))
+			require.NoError(t, err)
+
+			NewACPValidationHandler().ServeHTTP(rec, req)
+
+			var gotAR admv1.AdmissionReview
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&gotAR))
+			require.NotNil(t, gotAR.Response)
+
+			assert.Equal(t, "id", string(gotAR.Response.UID))
+
+			if test.wantCauses == nil {
+				assert.True(t, gotAR.Response.Allowed)
+				assert.Nil(t, gotAR.Response.Result)
+				return
+			}
+
+			assert.False(t, gotAR.Response.Allowed)
+			require.NotNil(t, gotAR.Response.Result)
+			assert.Equal(t, metav1.StatusReasonInvalid, gotAR.Response.Result.Reason)
+			assert.Equal(t, int32(http.StatusUnprocessableEntity), gotAR.Response.Result.Code)
+			require.NotNil(t, gotAR.Response.Result.Details)
+			assert.Equal(t, test.wantCauses, gotAR.Response.Result.Details.Causes)
+		})
+	}
+}
+
+func mustEncrypt(t *testing.T, key []byte, value string) string {
+	t.Helper()
+
+	encrypted, err := acp.Encrypt(key, value)
+	require.NoError(t, err)
+
+	return encrypted
+}
@@ -96,6 +96,7 @@ func TestWebhook_ServeHTTP(t *testing.T) {
 	tests := []struct {
 		desc      string
 		req       admv1.AdmissionRequest
+		dryRun    bool
 		reviewers func(*testing.T) ([]Reviewer, Reviewer)
 		wantResp  admv1.AdmissionResponse
 	}{
@@ -136,6 +137,32 @@ func TestWebhook_ServeHTTP(t *testing.T) {
 				}(),
 			},
 		},
+		{
+			desc:   "returns no patch in dry-run mode",
+			req:    ingressWithACP,
+			dryRun: true,
+			reviewers: func(t *testing.T) ([]Reviewer, Reviewer) {
+				t.Helper()
+
+				reviewer := newReviewerMock(t)
+				reviewer.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil).Once()
+				reviewer.OnReviewRaw(mock.Anything).TypedReturns(
+					map[string]interface{}{
+						"op":    "replace",
+						"path":  "/metadata/annotations",
+						"value": map[string]string{"foo": "bar"},
+					}, nil).Once()
+
+				return []Reviewer{reviewer}, nil
+			},
+			wantResp: admv1.AdmissionResponse{
+				UID:     "uid",
+				Allowed: true,
+				Warnings: []string{
+					`dry-run mode: ACP patch not applied to resource "my-ingress" of kind "networking.k8s.io/v1, Kind=Ingress" in namespace ""`,
+				},
+			},
+		},
 		{
 			desc: "returns patch when removing ACP",
 			req:  ingressWithACPRemoved,
@@ -266,7 +293,7 @@ func TestWebhook_ServeHTTP(t *testing.T) {
 			require.NoError(t, err)
 
 			reviewers, defaultReviewer := test.reviewers(t)
-			h := NewHandler(reviewers, defaultReviewer)
+			h := NewHandler(reviewers, defaultReviewer, test.dryRun)
 
 			rec := httptest.NewRecorder()
 			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", bytes.NewBuffer(b))
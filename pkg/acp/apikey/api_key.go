@@ -20,13 +20,16 @@ package apikey
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/token"
-	"golang.org/x/crypto/sha3"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 )
 
 // Config configures an API key ACP handler.
@@ -34,6 +37,9 @@ type Config struct {
 	KeySource      token.Source      `json:"keySource"`
 	Keys           []Key             `json:"keys"`
 	ForwardHeaders map[string]string `json:"forwardHeaders"`
+	Quota          *Quota            `json:"quota,omitempty"`
+	// IssuedKeys accepts the API keys issued by the dev portals to their users, besides Keys.
+	IssuedKeys bool `json:"issuedKeys,omitempty"`
 }
 
 // Key defines an API key.
@@ -41,6 +47,8 @@ type Key struct {
 	ID       string            `json:"id"`
 	Metadata map[string]string `json:"metadata"`
 	Value    string            `json:"value"`
+	// ValueFrom references the secret entry the value has been read from, if any.
+	ValueFrom *hubv1alpha1.SecretReference `json:"valueFrom,omitempty"`
 }
 
 // Handler is an API Key ACP Handler.
@@ -49,18 +57,26 @@ type Handler struct {
 	keySrc     token.Source
 	keys       map[string]Key
 	fwdHeaders map[string]string
+	quota      *Quota
+	quotas     *Quotas
+	issued     *IssuedKeys
 }
 
-// NewHandler creates a new API key ACP Handler.
-func NewHandler(cfg *Config, name string) (*Handler, error) {
+// NewHandler creates a new API key ACP Handler. The quota of the keys, if any, is only enforced when Quotas are given.
+// Keys issued to portal users are only accepted when the configuration allows them and IssuedKeys are given.
+func NewHandler(cfg *Config, name string, quotas *Quotas, issued *IssuedKeys) (*Handler, error) {
 	if cfg.KeySource.Header == "" && cfg.KeySource.Query == "" && cfg.KeySource.Cookie == "" {
 		return nil, errors.New(`at least one of "header", "query" or "cookie" must be set`)
 	}
 
-	if len(cfg.Keys) == 0 {
+	if len(cfg.Keys) == 0 && !cfg.IssuedKeys {
 		return nil, errors.New("at least one key must be defined")
 	}
 
+	if cfg.Quota != nil && (cfg.Quota.Limit <= 0 || cfg.Quota.Period <= 0) {
+		return nil, errors.New("quota limit and period must be positive")
+	}
+
 	keys := make(map[string]Key, len(cfg.Keys))
 	uniqIDs := make(map[string]struct{}, len(cfg.Keys))
 	uniqValues := make(map[string]struct{}, len(cfg.Keys))
@@ -93,12 +109,19 @@ func NewHandler(cfg *Config, name string) (*Handler, error) {
 		}
 	}
 
-	return &Handler{
+	h := &Handler{
 		name:       name,
 		keySrc:     cfg.KeySource,
 		keys:       keys,
 		fwdHeaders: cfg.ForwardHeaders,
-	}, nil
+		quota:      cfg.Quota,
+		quotas:     quotas,
+	}
+	if cfg.IssuedKeys {
+		h.issued = issued
+	}
+
+	return h, nil
 }
 
 func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
@@ -111,9 +134,11 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	hash := make([]byte, 64)
-	sha3.ShakeSum256(hash, []byte(apiKey))
-	k, ok := h.keys[fmt.Sprintf("%x", hash)]
+	hash := Hash(apiKey)
+	k, ok := h.keys[hash]
+	if !ok && h.issued != nil {
+		k, ok = h.issued.get(hash)
+	}
 	if !ok {
 		rw.WriteHeader(http.StatusUnauthorized)
 		return
@@ -156,6 +181,18 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	if h.quota != nil && h.quotas != nil {
+		usage, allowed := h.quotas.take(h.name, k, *h.quota)
+		setQuotaHeaders(rw.Header(), usage, h.quotas.nowFunc())
+
+		if !allowed {
+			l.Debug().Str("key_id", k.ID).Msg("Quota exceeded")
+			rw.Header().Set("Retry-After", rw.Header().Get("X-RateLimit-Reset"))
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+	}
+
 	for name, meta := range h.fwdHeaders {
 		if v, exists := k.Metadata[meta]; exists {
 			rw.Header().Add(name, v)
@@ -165,6 +202,18 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	rw.WriteHeader(http.StatusOK)
 }
 
+// setQuotaHeaders reports the usage of a quota with the X-RateLimit-* headers. The reset is given in seconds.
+func setQuotaHeaders(header http.Header, usage QuotaUsage, now time.Time) {
+	reset := int(math.Ceil(usage.ResetAt.Sub(now).Seconds()))
+	if reset < 0 {
+		reset = 0
+	}
+
+	header.Set("X-RateLimit-Limit", strconv.Itoa(usage.Limit))
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(usage.Remaining))
+	header.Set("X-RateLimit-Reset", strconv.Itoa(reset))
+}
+
 func search(needle string, stack []string) bool {
 	for _, s := range stack {
 		if s == needle {
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -105,6 +106,20 @@ func TestNewHandler(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "invalid quota",
+			cfg: Config{
+				KeySource: token.Source{Header: "Api-Key"},
+				Keys: []Key{
+					{
+						ID:    "id-1",
+						Value: "17fa993d5eecbd361f30baf0b9b2329ad053bb6d5fec2228eca55e9b4914fface3af69bcc9a6b5f7ff093aa9a0d00811d0b2a3ee67eac60c57e79d2fd99bbde0",
+					},
+				},
+				Quota: &Quota{Limit: 0, Period: time.Minute},
+			},
+			wantErr: true,
+		},
 		{
 			desc: "ok",
 			cfg: Config{
@@ -118,6 +133,14 @@ func TestNewHandler(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			desc: "ok, issued keys only",
+			cfg: Config{
+				KeySource:  token.Source{Header: "Api-Key"},
+				IssuedKeys: true,
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -125,7 +148,7 @@ func TestNewHandler(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := NewHandler(&test.cfg, "api-key")
+			_, err := NewHandler(&test.cfg, "api-key", nil, nil)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -259,7 +282,7 @@ func TestServeHTTP(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			apiKey, err := NewHandler(&test.cfg, "api-key")
+			apiKey, err := NewHandler(&test.cfg, "api-key", nil, nil)
 			require.NoError(t, err)
 
 			rr := httptest.NewRecorder()
@@ -368,7 +391,7 @@ func TestServeHTTP_handleGroups(t *testing.T) {
 				}},
 			}
 
-			apiKey, err := NewHandler(&cfg, "api-key")
+			apiKey, err := NewHandler(&cfg, "api-key", nil, nil)
 			require.NoError(t, err)
 
 			rr := httptest.NewRecorder()
@@ -421,7 +444,7 @@ func TestServeHTTPForwardsHeader(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			apiKey, err := NewHandler(&test.cfg, "api-key")
+			apiKey, err := NewHandler(&test.cfg, "api-key", nil, nil)
 			require.NoError(t, err)
 
 			rr := httptest.NewRecorder()
@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Issued API keys are API keys issued by the dev portals to their users. Each of them is stored in a Secret labeled
+// with LabelIssuedKey, holding the hash of the key and the identity of the user it has been issued to.
+const (
+	LabelIssuedKey       = "hub.traefik.io/issued-api-key"
+	LabelIssuedKeyOwner  = "hub.traefik.io/issued-api-key-owner"
+	LabelIssuedKeyPortal = "hub.traefik.io/issued-api-key-portal"
+
+	IssuedKeyHash      = "hash"
+	IssuedKeyName      = "name"
+	IssuedKeyEmail     = "email"
+	IssuedKeyGroups    = "groups"
+	IssuedKeyCreatedAt = "createdAt"
+	IssuedKeyExpiresAt = "expiresAt"
+)
+
+const issuedKeyHashIndex = "issuedKeyHash"
+
+// Hash returns the hash of an API key, as held by API key ACPs and issued key Secrets.
+func Hash(key string) string {
+	hash := make([]byte, 64)
+	sha3.ShakeSum256(hash, []byte(key))
+
+	return hex.EncodeToString(hash)
+}
+
+// OwnerLabel returns the value of the LabelIssuedKeyOwner label of the keys issued to the user with the given email.
+// Emails are not valid label values, the label holds a digest of the email instead.
+func OwnerLabel(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+
+	return hex.EncodeToString(sum[:16])
+}
+
+// IssuedKeys looks up the API keys issued to portal users, from the Secrets of a namespace.
+type IssuedKeys struct {
+	indexer   cache.Indexer
+	namespace string
+	now       func() time.Time
+}
+
+// NewIssuedKeys creates new IssuedKeys reading the Secrets of the given namespace from the given informer. It indexes
+// the Secrets by key hash, hence must be called before the informer is started.
+func NewIssuedKeys(secrets cache.SharedIndexInformer, namespace string) (*IssuedKeys, error) {
+	err := secrets.AddIndexers(cache.Indexers{
+		issuedKeyHashIndex: func(obj interface{}) ([]string, error) {
+			s, ok := obj.(*corev1.Secret)
+			if !ok || s.Namespace != namespace || s.Labels[LabelIssuedKey] != "true" {
+				return nil, nil
+			}
+
+			hash, ok := s.Data[IssuedKeyHash]
+			if !ok {
+				return nil, nil
+			}
+
+			return []string{string(hash)}, nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("add issued API key indexer: %w", err)
+	}
+
+	return &IssuedKeys{
+		indexer:   secrets.GetIndexer(),
+		namespace: namespace,
+		now:       time.Now,
+	}, nil
+}
+
+// get returns the unexpired issued key having the given hash. Its metadata holds the email and groups of its owner
+// and its name.
+func (k *IssuedKeys) get(hash string) (Key, bool) {
+	objs, err := k.indexer.ByIndex(issuedKeyHashIndex, hash)
+	if err != nil || len(objs) != 1 {
+		return Key{}, false
+	}
+
+	s, ok := objs[0].(*corev1.Secret)
+	if !ok {
+		return Key{}, false
+	}
+
+	if expiresAt, ok := s.Data[IssuedKeyExpiresAt]; ok {
+		t, err := time.Parse(time.RFC3339, string(expiresAt))
+		if err != nil || !k.now().Before(t) {
+			return Key{}, false
+		}
+	}
+
+	return Key{
+		ID: s.Name,
+		Metadata: map[string]string{
+			"_id":    s.Name,
+			"name":   string(s.Data[IssuedKeyName]),
+			"email":  string(s.Data[IssuedKeyEmail]),
+			"groups": string(s.Data[IssuedKeyGroups]),
+		},
+		Value: hash,
+	}, true
+}
@@ -0,0 +1,172 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/token"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kinformers "k8s.io/client-go/informers"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandler_ServeHTTP_issuedKeys(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	clientSet := kubemock.NewSimpleClientset(
+		issuedKeySecret("hub-api-key-valid", "hub", "valid-key", "", "supplier,manager"),
+		issuedKeySecret("hub-api-key-expired", "hub", "expired-key", now.Add(-time.Minute).Format(time.RFC3339), "supplier"),
+		issuedKeySecret("hub-api-key-expiring", "hub", "expiring-key", now.Add(time.Minute).Format(time.RFC3339), "supplier"),
+		issuedKeySecret("hub-api-key-other-ns", "default", "other-ns-key", "", "supplier"),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeInformer := kinformers.NewSharedInformerFactory(clientSet, 0)
+	issued, err := NewIssuedKeys(kubeInformer.Core().V1().Secrets().Informer(), "hub")
+	require.NoError(t, err)
+	issued.now = func() time.Time { return now }
+
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+
+	cfg := &Config{
+		KeySource:      token.Source{Header: "Api-Key"},
+		IssuedKeys:     true,
+		ForwardHeaders: map[string]string{"User-Email": "email"},
+	}
+
+	tests := []struct {
+		desc        string
+		key         string
+		groups      string
+		issuedKeys  bool
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			desc:        "valid issued key",
+			key:         "valid-key",
+			issuedKeys:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"User-Email": "user@example.com"},
+		},
+		{
+			desc:        "issued key in the required groups",
+			key:         "valid-key",
+			groups:      "manager",
+			issuedKeys:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"User-Email": "user@example.com"},
+		},
+		{
+			desc:       "issued key not in the required groups",
+			key:        "valid-key",
+			groups:     "admin",
+			issuedKeys: true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "expired issued key",
+			key:        "expired-key",
+			issuedKeys: true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "issued key not expired yet",
+			key:        "expiring-key",
+			issuedKeys: true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "key issued in another namespace",
+			key:        "other-ns-key",
+			issuedKeys: true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "issued keys not accepted",
+			key:        "valid-key",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := *cfg
+			c.IssuedKeys = test.issuedKeys
+			if !test.issuedKeys {
+				c.Keys = []Key{{ID: "static", Value: Hash("static-key")}}
+			}
+
+			handler, err := NewHandler(&c, "api-key", nil, issued)
+			require.NoError(t, err)
+
+			target := "/"
+			if test.groups != "" {
+				target += "?groups=" + test.groups
+			}
+			req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+			req.Header.Set("Api-Key", test.key)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+			for name, value := range test.wantHeaders {
+				assert.Equal(t, value, rec.Header().Get(name))
+			}
+		})
+	}
+}
+
+func issuedKeySecret(name, namespace, key, expiresAt, groups string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				LabelIssuedKey:      "true",
+				LabelIssuedKeyOwner: OwnerLabel("user@example.com"),
+			},
+		},
+		Data: map[string][]byte{
+			IssuedKeyHash:   []byte(Hash(key)),
+			IssuedKeyName:   []byte(name),
+			IssuedKeyEmail:  []byte("user@example.com"),
+			IssuedKeyGroups: []byte(groups),
+		},
+	}
+	if expiresAt != "" {
+		secret.Data[IssuedKeyExpiresAt] = []byte(expiresAt)
+	}
+
+	return secret
+}
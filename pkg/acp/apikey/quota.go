@@ -0,0 +1,167 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apikey
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Quota defines the number of requests each API key can make per period.
+type Quota struct {
+	Limit  int           `json:"limit"`
+	Period time.Duration `json:"period"`
+}
+
+// QuotaUsage is the usage of the quota of an API key.
+type QuotaUsage struct {
+	ACP       string    `json:"acp"`
+	KeyID     string    `json:"keyId"`
+	Email     string    `json:"email,omitempty"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// Quotas counts the requests made by API keys against their quota. Counters are kept per ACP and key, using fixed
+// windows starting on the first request of each period, and survive the ACP handlers being rebuilt.
+type Quotas struct {
+	nowFunc func() time.Time
+
+	windowsMu sync.Mutex
+	windows   map[quotaKey]*quotaWindow
+}
+
+type quotaKey struct {
+	acp   string
+	keyID string
+}
+
+type quotaWindow struct {
+	email   string
+	limit   int
+	count   int
+	resetAt time.Time
+}
+
+// NewQuotas creates a new Quotas.
+func NewQuotas() *Quotas {
+	return &Quotas{
+		nowFunc: time.Now,
+		windows: make(map[quotaKey]*quotaWindow),
+	}
+}
+
+// take counts a request made by the given key against the given quota. It returns the usage of the quota once the
+// request is counted and whether the request is allowed.
+func (q *Quotas) take(acp string, k Key, quota Quota) (QuotaUsage, bool) {
+	now := q.nowFunc()
+
+	q.windowsMu.Lock()
+	defer q.windowsMu.Unlock()
+
+	key := quotaKey{acp: acp, keyID: k.ID}
+
+	w, ok := q.windows[key]
+	if !ok || !w.resetAt.After(now) {
+		w = &quotaWindow{resetAt: now.Add(quota.Period)}
+		q.windows[key] = w
+	}
+
+	// The quota may have changed since the window started.
+	w.limit = quota.Limit
+	w.email = k.Metadata["email"]
+
+	allowed := w.count < w.limit
+	if allowed {
+		w.count++
+	}
+
+	return w.usage(key), allowed
+}
+
+// Usages returns the usage of the quotas having a window in progress, sorted by ACP and key.
+func (q *Quotas) Usages() []QuotaUsage {
+	now := q.nowFunc()
+
+	q.windowsMu.Lock()
+	defer q.windowsMu.Unlock()
+
+	usages := make([]QuotaUsage, 0, len(q.windows))
+	for key, w := range q.windows {
+		if !w.resetAt.After(now) {
+			delete(q.windows, key)
+			continue
+		}
+
+		usages = append(usages, w.usage(key))
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].ACP != usages[j].ACP {
+			return usages[i].ACP < usages[j].ACP
+		}
+		return usages[i].KeyID < usages[j].KeyID
+	})
+
+	return usages
+}
+
+// ServeHTTP serves the usage of the quotas. They can be filtered on the email metadata of the keys with the `email`
+// query parameter.
+func (q *Quotas) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	usages := q.Usages()
+
+	if email := req.URL.Query().Get("email"); email != "" {
+		filtered := make([]QuotaUsage, 0, len(usages))
+		for _, usage := range usages {
+			if usage.Email == email {
+				filtered = append(filtered, usage)
+			}
+		}
+		usages = filtered
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(rw).Encode(usages); err != nil {
+		log.Error().Err(err).Msg("Write quota usages response")
+	}
+}
+
+func (w *quotaWindow) usage(key quotaKey) QuotaUsage {
+	remaining := w.limit - w.count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return QuotaUsage{
+		ACP:       key.acp,
+		KeyID:     key.keyID,
+		Email:     w.email,
+		Limit:     w.limit,
+		Remaining: remaining,
+		ResetAt:   w.resetAt,
+	}
+}
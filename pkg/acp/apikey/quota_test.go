@@ -0,0 +1,166 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apikey
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/token"
+)
+
+func TestHandler_ServeHTTP_quota(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	quotas := NewQuotas()
+	quotas.nowFunc = func() time.Time { return now }
+
+	handler, err := NewHandler(&Config{
+		KeySource: token.Source{Header: "Api-Key"},
+		Keys: []Key{
+			{
+				ID:    "id-1",
+				Value: "17fa993d5eecbd361f30baf0b9b2329ad053bb6d5fec2228eca55e9b4914fface3af69bcc9a6b5f7ff093aa9a0d00811d0b2a3ee67eac60c57e79d2fd99bbde0",
+			},
+		},
+		Quota: &Quota{Limit: 2, Period: time.Minute},
+	}, "api-key", quotas, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc          string
+		elapsed       time.Duration
+		wantStatus    int
+		wantRemaining string
+		wantReset     string
+	}{
+		{
+			desc:          "first request",
+			wantStatus:    http.StatusOK,
+			wantRemaining: "1",
+			wantReset:     "60",
+		},
+		{
+			desc:          "last allowed request",
+			elapsed:       10 * time.Second,
+			wantStatus:    http.StatusOK,
+			wantRemaining: "0",
+			wantReset:     "50",
+		},
+		{
+			desc:          "quota exceeded",
+			elapsed:       10 * time.Second,
+			wantStatus:    http.StatusTooManyRequests,
+			wantRemaining: "0",
+			wantReset:     "40",
+		},
+		{
+			desc:          "new period",
+			elapsed:       time.Minute,
+			wantStatus:    http.StatusOK,
+			wantRemaining: "1",
+			wantReset:     "60",
+		},
+	}
+
+	// Requests are made sequentially, each test depends on the requests made by the previous ones.
+	for _, test := range tests {
+		now = now.Add(test.elapsed)
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Api-Key", validAPIKey)
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+
+		assert.Equal(t, test.wantStatus, rw.Code, test.desc)
+		assert.Equal(t, "2", rw.Header().Get("X-RateLimit-Limit"), test.desc)
+		assert.Equal(t, test.wantRemaining, rw.Header().Get("X-RateLimit-Remaining"), test.desc)
+		assert.Equal(t, test.wantReset, rw.Header().Get("X-RateLimit-Reset"), test.desc)
+
+		if test.wantStatus == http.StatusTooManyRequests {
+			assert.Equal(t, test.wantReset, rw.Header().Get("Retry-After"), test.desc)
+		}
+	}
+}
+
+func TestQuotas_ServeHTTP(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	quotas := NewQuotas()
+	quotas.nowFunc = func() time.Time { return now }
+
+	quota := Quota{Limit: 10, Period: time.Hour}
+	quotas.take("acp-1", Key{ID: "key-2", Metadata: map[string]string{"email": "bob@example.com"}}, quota)
+	quotas.take("acp-1", Key{ID: "key-1", Metadata: map[string]string{"email": "alice@example.com"}}, quota)
+	quotas.take("acp-1", Key{ID: "key-1", Metadata: map[string]string{"email": "alice@example.com"}}, quota)
+	quotas.take("acp-2", Key{ID: "key-3", Metadata: map[string]string{"email": "alice@example.com"}}, Quota{Limit: 1, Period: time.Minute})
+	quotas.take("acp-2", Key{ID: "key-4"}, Quota{Limit: 1, Period: time.Second})
+
+	now = now.Add(time.Second)
+
+	tests := []struct {
+		desc  string
+		query string
+		want  []QuotaUsage
+	}{
+		{
+			desc: "all usages",
+			want: []QuotaUsage{
+				{ACP: "acp-1", KeyID: "key-1", Email: "alice@example.com", Limit: 10, Remaining: 8, ResetAt: now.Add(time.Hour - time.Second)},
+				{ACP: "acp-1", KeyID: "key-2", Email: "bob@example.com", Limit: 10, Remaining: 9, ResetAt: now.Add(time.Hour - time.Second)},
+				{ACP: "acp-2", KeyID: "key-3", Email: "alice@example.com", Limit: 1, Remaining: 0, ResetAt: now.Add(time.Minute - time.Second)},
+			},
+		},
+		{
+			desc:  "usages of a user",
+			query: "?email=alice%40example.com",
+			want: []QuotaUsage{
+				{ACP: "acp-1", KeyID: "key-1", Email: "alice@example.com", Limit: 10, Remaining: 8, ResetAt: now.Add(time.Hour - time.Second)},
+				{ACP: "acp-2", KeyID: "key-3", Email: "alice@example.com", Limit: 1, Remaining: 0, ResetAt: now.Add(time.Minute - time.Second)},
+			},
+		},
+		{
+			desc:  "unknown user",
+			query: "?email=eve%40example.com",
+			want:  []QuotaUsage{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rw := httptest.NewRecorder()
+			quotas.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/quotas"+test.query, http.NoBody))
+
+			require.Equal(t, http.StatusOK, rw.Code)
+			assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+
+			var got []QuotaUsage
+			require.NoError(t, json.NewDecoder(rw.Body).Decode(&got))
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
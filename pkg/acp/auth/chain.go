@@ -0,0 +1,75 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// chainHandler evaluates requests against a chain of ACPs, served on the paths listing their names separated by
+// commas, e.g. /ip-allowlist,jwt. ACPs are evaluated in order and the first one denying the request sends its
+// response back to the client. Once all of them authorized the request, the headers they set are all sent back, the
+// last ACP setting a header taking precedence.
+type chainHandler struct {
+	handlers map[string]http.Handler
+}
+
+func (c chainHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	names := strings.Split(strings.TrimPrefix(req.URL.Path, "/"), ",")
+	if len(names) < 2 {
+		http.NotFound(rw, req)
+		return
+	}
+
+	handlers := make([]http.Handler, 0, len(names))
+	for _, name := range names {
+		handler, ok := c.handlers[name]
+		if !ok {
+			http.NotFound(rw, req)
+			return
+		}
+
+		handlers = append(handlers, handler)
+	}
+
+	headers := make(http.Header)
+	for _, handler := range handlers {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.Clone(req.Context()))
+
+		if rec.Code < http.StatusOK || rec.Code >= http.StatusMultipleChoices {
+			for name, values := range rec.Header() {
+				rw.Header()[name] = values
+			}
+			rw.WriteHeader(rec.Code)
+			_, _ = rw.Write(rec.Body.Bytes())
+
+			return
+		}
+
+		for name, values := range rec.Header() {
+			headers[name] = values
+		}
+	}
+
+	for name, values := range headers {
+		rw.Header()[name] = values
+	}
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainHandler_ServeHTTP(t *testing.T) {
+	allow := func(header, value string) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set(header, value)
+			rw.WriteHeader(http.StatusOK)
+		})
+	}
+	deny := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+		rw.WriteHeader(http.StatusUnauthorized)
+		_, _ = rw.Write([]byte("unauthorized"))
+	})
+	unreachable := http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("ACP evaluated after a denial")
+	})
+
+	handler := chainHandler{handlers: map[string]http.Handler{
+		"ip-allowlist": allow("X-Client", "10.0.0.1"),
+		"jwt":          allow("X-User", "alice"),
+		"override":     allow("X-User", "bob"),
+		"deny":         deny,
+		"unreachable":  unreachable,
+	}}
+
+	tests := []struct {
+		desc        string
+		path        string
+		wantCode    int
+		wantHeaders http.Header
+		wantBody    string
+	}{
+		{
+			desc:     "single ACP",
+			path:     "/jwt",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			desc:     "unknown ACP",
+			path:     "/jwt,unknown",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			desc:     "all ACPs authorize the request",
+			path:     "/ip-allowlist,jwt",
+			wantCode: http.StatusOK,
+			wantHeaders: http.Header{
+				"X-Client": []string{"10.0.0.1"},
+				"X-User":   []string{"alice"},
+			},
+		},
+		{
+			desc:     "last ACP setting a header takes precedence",
+			path:     "/jwt,override",
+			wantCode: http.StatusOK,
+			wantHeaders: http.Header{
+				"X-User": []string{"bob"},
+			},
+		},
+		{
+			desc:     "first denial is sent back",
+			path:     "/ip-allowlist,deny,unreachable",
+			wantCode: http.StatusUnauthorized,
+			wantHeaders: http.Header{
+				"Www-Authenticate": []string{"Bearer"},
+			},
+			wantBody: "unauthorized",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, http.NoBody))
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			for name, values := range test.wantHeaders {
+				assert.Equal(t, values, rec.Header()[name])
+			}
+			if test.wantBody != "" {
+				assert.Equal(t, test.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+)
+
+// Context extensions set on Envoy ext_authz requests to select the ACP to apply.
+const (
+	ExtAuthzContextACP    = "hub-acp"
+	ExtAuthzContextGroups = "hub-groups"
+)
+
+// ExtAuthzServer is an Envoy external authorization gRPC service which evaluates requests against the ACP handlers
+// served by the given http.Handler, in the same way as forward-auth requests.
+type ExtAuthzServer struct {
+	handler http.Handler
+}
+
+// NewExtAuthzServer returns a new ExtAuthzServer.
+func NewExtAuthzServer(handler http.Handler) *ExtAuthzServer {
+	return &ExtAuthzServer{handler: handler}
+}
+
+// Check implements the authv3.AuthorizationServer interface.
+func (s *ExtAuthzServer) Check(ctx context.Context, checkReq *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	attrs := checkReq.GetAttributes()
+	polName := attrs.GetContextExtensions()[ExtAuthzContextACP]
+	if polName == "" {
+		log.Error().Msg("No ACP found in ext_authz context extensions")
+		return deniedResponse(http.StatusForbidden, nil, ""), nil
+	}
+
+	req := newForwardAuthRequest(ctx, attrs, polName)
+
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	if rec.Code < http.StatusOK || rec.Code >= http.StatusMultipleChoices {
+		return deniedResponse(rec.Code, rec.Header(), rec.Body.String()), nil
+	}
+
+	okResp := &authv3.OkHttpResponse{}
+	for name, values := range rec.Header() {
+		for _, value := range values {
+			// Handlers reset headers which must not reach the upstream service with an empty value.
+			if value == "" {
+				okResp.HeadersToRemove = append(okResp.HeadersToRemove, name)
+				continue
+			}
+
+			header := &corev3.HeaderValueOption{Header: &corev3.HeaderValue{Key: name, Value: value}}
+
+			// Quota headers are meant for the client rather than the upstream service.
+			if strings.HasPrefix(name, "X-Ratelimit-") {
+				okResp.ResponseHeadersToAdd = append(okResp.ResponseHeadersToAdd, header)
+				continue
+			}
+
+			okResp.Headers = append(okResp.Headers, header)
+		}
+	}
+
+	return &authv3.CheckResponse{
+		Status:       &status.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: okResp},
+	}, nil
+}
+
+// newForwardAuthRequest builds the request a reverse proxy would have sent to the auth server for the given
+// ext_authz request attributes.
+func newForwardAuthRequest(ctx context.Context, attrs *authv3.AttributeContext, polName string) *http.Request {
+	httpReq := attrs.GetRequest().GetHttp()
+
+	target := "/" + polName
+	if groups := attrs.GetContextExtensions()[ExtAuthzContextGroups]; groups != "" {
+		target += "?groups=" + url.QueryEscape(groups)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, target, http.NoBody).WithContext(ctx)
+	for name, value := range httpReq.GetHeaders() {
+		// Skip HTTP/2 pseudo-headers, they are converted to X-Forwarded headers below.
+		if strings.HasPrefix(name, ":") {
+			continue
+		}
+
+		req.Header.Set(name, value)
+	}
+
+	req.Header.Set("X-Forwarded-Method", httpReq.GetMethod())
+	req.Header.Set("X-Forwarded-Proto", httpReq.GetScheme())
+	req.Header.Set("X-Forwarded-Host", httpReq.GetHost())
+	req.Header.Set("X-Forwarded-Uri", httpReq.GetPath())
+
+	if addr := attrs.GetSource().GetAddress().GetSocketAddress().GetAddress(); addr != "" {
+		req.Header.Set("X-Forwarded-For", addr)
+	}
+
+	return req
+}
+
+func deniedResponse(code int, headers http.Header, body string) *authv3.CheckResponse {
+	deniedResp := &authv3.DeniedHttpResponse{
+		Status: &typev3.HttpStatus{Code: typev3.StatusCode(code)},
+		Body:   body,
+	}
+	for name, values := range headers {
+		for _, value := range values {
+			deniedResp.Headers = append(deniedResp.Headers, &corev3.HeaderValueOption{
+				Header: &corev3.HeaderValue{Key: name, Value: value},
+			})
+		}
+	}
+
+	return &authv3.CheckResponse{
+		Status:       &status.Status{Code: int32(codes.PermissionDenied)},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: deniedResp},
+	}
+}
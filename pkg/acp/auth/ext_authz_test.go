@@ -0,0 +1,119 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestExtAuthzServer_Check(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/my-acp", func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "admin", req.URL.Query().Get("groups"))
+		assert.Equal(t, "POST", req.Header.Get("X-Forwarded-Method"))
+		assert.Equal(t, "https", req.Header.Get("X-Forwarded-Proto"))
+		assert.Equal(t, "example.com", req.Header.Get("X-Forwarded-Host"))
+		assert.Equal(t, "/foo?bar=baz", req.Header.Get("X-Forwarded-Uri"))
+		assert.Equal(t, "10.0.0.1", req.Header.Get("X-Forwarded-For"))
+		assert.Empty(t, req.Header.Get(":path"))
+
+		if req.Header.Get("Authorization") != "secret" {
+			rw.Header().Set("WWW-Authenticate", "Basic")
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		rw.Header().Set("X-User", "bob")
+		rw.Header().Set("Authorization", "")
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	srv := NewExtAuthzServer(mux)
+
+	newCheckRequest := func(acpName, authorization string) *authv3.CheckRequest {
+		return &authv3.CheckRequest{
+			Attributes: &authv3.AttributeContext{
+				Source: &authv3.AttributeContext_Peer{
+					Address: &corev3.Address{Address: &corev3.Address_SocketAddress{
+						SocketAddress: &corev3.SocketAddress{Address: "10.0.0.1"},
+					}},
+				},
+				Request: &authv3.AttributeContext_Request{
+					Http: &authv3.AttributeContext_HttpRequest{
+						Method: "POST",
+						Scheme: "https",
+						Host:   "example.com",
+						Path:   "/foo?bar=baz",
+						Headers: map[string]string{
+							":path":         "/foo?bar=baz",
+							"authorization": authorization,
+						},
+					},
+				},
+				ContextExtensions: map[string]string{
+					ExtAuthzContextACP:    acpName,
+					ExtAuthzContextGroups: "admin",
+				},
+			},
+		}
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		resp, err := srv.Check(context.Background(), newCheckRequest("my-acp", "secret"))
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+
+		okResp := resp.GetOkResponse()
+		require.NotNil(t, okResp)
+		require.Len(t, okResp.GetHeaders(), 1)
+		assert.Equal(t, "X-User", okResp.GetHeaders()[0].GetHeader().GetKey())
+		assert.Equal(t, "bob", okResp.GetHeaders()[0].GetHeader().GetValue())
+		assert.Equal(t, []string{"Authorization"}, okResp.GetHeadersToRemove())
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		resp, err := srv.Check(context.Background(), newCheckRequest("my-acp", "invalid"))
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(codes.PermissionDenied), resp.GetStatus().GetCode())
+
+		deniedResp := resp.GetDeniedResponse()
+		require.NotNil(t, deniedResp)
+		assert.EqualValues(t, http.StatusUnauthorized, deniedResp.GetStatus().GetCode())
+		require.Len(t, deniedResp.GetHeaders(), 1)
+		assert.Equal(t, "Www-Authenticate", deniedResp.GetHeaders()[0].GetHeader().GetKey())
+	})
+
+	t.Run("unknown ACP", func(t *testing.T) {
+		resp, err := srv.Check(context.Background(), newCheckRequest("unknown", "secret"))
+		require.NoError(t, err)
+
+		deniedResp := resp.GetDeniedResponse()
+		require.NotNil(t, deniedResp)
+		assert.EqualValues(t, http.StatusNotFound, deniedResp.GetStatus().GetCode())
+	})
+}
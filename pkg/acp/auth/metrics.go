@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results reported by the auth server metrics.
+const (
+	ResultAuthorized  = "authorized"
+	ResultRedirected  = "redirected"
+	ResultDenied      = "denied"
+	ResultRateLimited = "rate_limited"
+	ResultErrored     = "errored"
+)
+
+// Metrics holds the Prometheus collectors exposed by the auth server.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	bans     *prometheus.CounterVec
+}
+
+// NewMetrics creates the auth server collectors and registers them in the given registerer.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "auth_server",
+			Name:      "requests_total",
+			Help:      "Number of auth requests handled, partitioned by ACP and result.",
+		}, []string{"acp_name", "acp_type", "result", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "hub_agent",
+			Subsystem: "auth_server",
+			Name:      "request_duration_seconds",
+			Help:      "Time spent handling auth requests, partitioned by ACP and result.",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}, []string{"acp_name", "acp_type", "result"}),
+		bans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "auth_server",
+			Name:      "client_bans_total",
+			Help:      "Number of clients banned after too many failed authentication attempts, partitioned by ACP.",
+		}, []string{"acp_name"}),
+	}
+
+	if err := reg.Register(m.requests); err != nil {
+		return nil, fmt.Errorf("register requests counter: %w", err)
+	}
+	if err := reg.Register(m.duration); err != nil {
+		return nil, fmt.Errorf("register duration histogram: %w", err)
+	}
+	if err := reg.Register(m.bans); err != nil {
+		return nil, fmt.Errorf("register bans counter: %w", err)
+	}
+
+	return m, nil
+}
+
+// Instrument wraps the given ACP handler so every request it handles is counted and timed.
+func (m *Metrics) Instrument(name, typ string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+
+		recorder := &statusRecorder{ResponseWriter: rw, code: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		result := resultFromCode(recorder.code)
+
+		m.requests.WithLabelValues(name, typ, result, strconv.Itoa(recorder.code)).Inc()
+		m.duration.WithLabelValues(name, typ, result).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Forget removes the series of an ACP which is no longer served.
+func (m *Metrics) Forget(name string) {
+	m.requests.DeletePartialMatch(prometheus.Labels{"acp_name": name})
+	m.duration.DeletePartialMatch(prometheus.Labels{"acp_name": name})
+	m.bans.DeletePartialMatch(prometheus.Labels{"acp_name": name})
+}
+
+func (m *Metrics) banned(name string) {
+	m.bans.WithLabelValues(name).Inc()
+}
+
+func resultFromCode(code int) string {
+	switch {
+	case code >= 200 && code < 300:
+		return ResultAuthorized
+	case code >= 300 && code < 400:
+		// OIDC handlers redirect unauthenticated users to the identity provider.
+		return ResultRedirected
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ResultDenied
+	case code == http.StatusTooManyRequests:
+		return ResultRateLimited
+	default:
+		return ResultErrored
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+
+	code        int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Instrument(t *testing.T) {
+	tests := []struct {
+		desc           string
+		code           int
+		expectedResult string
+	}{
+		{
+			desc:           "authorized",
+			code:           http.StatusOK,
+			expectedResult: ResultAuthorized,
+		},
+		{
+			desc:           "redirected to login",
+			code:           http.StatusFound,
+			expectedResult: ResultRedirected,
+		},
+		{
+			desc:           "unauthorized",
+			code:           http.StatusUnauthorized,
+			expectedResult: ResultDenied,
+		},
+		{
+			desc:           "forbidden",
+			code:           http.StatusForbidden,
+			expectedResult: ResultDenied,
+		},
+		{
+			desc:           "internal error",
+			code:           http.StatusInternalServerError,
+			expectedResult: ResultErrored,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			metrics, err := NewMetrics(prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			handler := metrics.Instrument("my-acp", "JWT", http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(test.code)
+			}))
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/my-acp", nil))
+
+			assert.Equal(t, test.code, rw.Code)
+			assert.Equal(t, 1, testutil.CollectAndCount(metrics.requests))
+			assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("my-acp", "JWT", test.expectedResult, strconv.Itoa(test.code))))
+			assert.Equal(t, 1, testutil.CollectAndCount(metrics.duration))
+		})
+	}
+}
+
+func TestMetrics_Forget(t *testing.T) {
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	ok := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {})
+	for _, name := range []string{"acp-1", "acp-2"} {
+		metrics.Instrument(name, "JWT", ok).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/"+name, nil))
+	}
+	require.Equal(t, 2, testutil.CollectAndCount(metrics.requests))
+
+	metrics.Forget("acp-1")
+
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.requests))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.duration))
+}
@@ -0,0 +1,215 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimitConfig configures the brute-force protection of credential based ACPs.
+type RateLimitConfig struct {
+	// MaxFailures is the number of failed authentication attempts a client can make within Window before being banned.
+	// A value of zero disables the protection.
+	MaxFailures int
+	// Window is the duration of the sliding window in which failed attempts are counted.
+	Window time.Duration
+	// BanDuration is the duration during which a banned client is rejected without its credentials being checked.
+	BanDuration time.Duration
+}
+
+// RateLimiter bans clients repeatedly failing to authenticate against an ACP.
+// Clients are identified by their IP address, independently for each ACP.
+type RateLimiter struct {
+	cfg     RateLimitConfig
+	metrics *Metrics
+
+	nowFunc func() time.Time
+
+	attemptsMu sync.Mutex
+	attempts   map[attemptKey]*attempts
+}
+
+type attemptKey struct {
+	acp string
+	ip  string
+}
+
+type attempts struct {
+	failures    []time.Time
+	bannedUntil time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter.
+func NewRateLimiter(cfg RateLimitConfig, metrics *Metrics) *RateLimiter {
+	return &RateLimiter{
+		cfg:      cfg,
+		metrics:  metrics,
+		nowFunc:  time.Now,
+		attempts: make(map[attemptKey]*attempts),
+	}
+}
+
+// Run periodically drops the attempts which are no longer relevant until the given context is canceled.
+func (l *RateLimiter) Run(ctx context.Context) {
+	if l.cfg.MaxFailures <= 0 {
+		return
+	}
+
+	t := time.NewTicker(l.cfg.Window)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			l.cleanup()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Protect wraps the handler of the given ACP so clients failing to authenticate too often get banned.
+func (l *RateLimiter) Protect(name string, next http.Handler) http.Handler {
+	if l.cfg.MaxFailures <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		key := attemptKey{acp: name, ip: clientIP(req)}
+
+		if retryAfter := l.banned(key); retryAfter > 0 {
+			log.Debug().
+				Str("acp_name", name).
+				Str("client_ip", key.ip).
+				Msg("Rejecting request from banned client")
+
+			rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: rw, code: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		switch recorder.code {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			l.fail(key)
+		case http.StatusOK:
+			l.reset(key)
+		}
+	})
+}
+
+func (l *RateLimiter) banned(key attemptKey) time.Duration {
+	l.attemptsMu.Lock()
+	defer l.attemptsMu.Unlock()
+
+	a, ok := l.attempts[key]
+	if !ok {
+		return 0
+	}
+
+	return a.bannedUntil.Sub(l.nowFunc())
+}
+
+func (l *RateLimiter) fail(key attemptKey) {
+	now := l.nowFunc()
+
+	l.attemptsMu.Lock()
+	defer l.attemptsMu.Unlock()
+
+	a, ok := l.attempts[key]
+	if !ok {
+		a = &attempts{}
+		l.attempts[key] = a
+	}
+
+	a.failures = append(pruneFailures(a.failures, now.Add(-l.cfg.Window)), now)
+	if len(a.failures) < l.cfg.MaxFailures {
+		return
+	}
+
+	log.Warn().
+		Str("acp_name", key.acp).
+		Str("client_ip", key.ip).
+		Int("failures", len(a.failures)).
+		Dur("ban_duration", l.cfg.BanDuration).
+		Msg("Too many failed authentication attempts, banning client")
+
+	a.failures = nil
+	a.bannedUntil = now.Add(l.cfg.BanDuration)
+
+	l.metrics.banned(key.acp)
+}
+
+func (l *RateLimiter) reset(key attemptKey) {
+	l.attemptsMu.Lock()
+	defer l.attemptsMu.Unlock()
+
+	delete(l.attempts, key)
+}
+
+func (l *RateLimiter) cleanup() {
+	now := l.nowFunc()
+
+	l.attemptsMu.Lock()
+	defer l.attemptsMu.Unlock()
+
+	for key, a := range l.attempts {
+		a.failures = pruneFailures(a.failures, now.Add(-l.cfg.Window))
+		if len(a.failures) == 0 && !a.bannedUntil.After(now) {
+			delete(l.attempts, key)
+		}
+	}
+}
+
+// pruneFailures drops failures which happened before the given time. Failures are sorted chronologically.
+func pruneFailures(failures []time.Time, since time.Time) []time.Time {
+	for i, failure := range failures {
+		if failure.After(since) {
+			return failures[i:]
+		}
+	}
+
+	return nil
+}
+
+// clientIP returns the IP of the client at the origin of the request. Traefik forwards it as the last
+// X-Forwarded-For entry when calling the auth server.
+func clientIP(req *http.Request) string {
+	if values := req.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		ips := strings.Split(values[len(values)-1], ",")
+		return strings.TrimSpace(ips[len(ips)-1])
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Protect(t *testing.T) {
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	limiter := NewRateLimiter(RateLimitConfig{
+		MaxFailures: 3,
+		Window:      time.Minute,
+		BanDuration: 5 * time.Minute,
+	}, metrics)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.nowFunc = func() time.Time { return now }
+
+	handler := limiter.Protect("my-acp", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "valid" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	call := func(ip, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/my-acp", nil)
+		req.Header.Set("X-Forwarded-For", "10.0.0.1, "+ip)
+		req.Header.Set("Authorization", authorization)
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+
+		return rw
+	}
+
+	// Failures outside the sliding window are forgotten.
+	assert.Equal(t, http.StatusUnauthorized, call("1.1.1.1", "invalid").Code)
+	now = now.Add(2 * time.Minute)
+
+	assert.Equal(t, http.StatusUnauthorized, call("1.1.1.1", "invalid").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("1.1.1.1", "invalid").Code)
+	assert.Equal(t, http.StatusUnauthorized, call("1.1.1.1", "invalid").Code)
+
+	// The client is banned, even with valid credentials.
+	rw := call("1.1.1.1", "valid")
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+	assert.Equal(t, "300", rw.Header().Get("Retry-After"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.bans.WithLabelValues("my-acp")))
+
+	// Other clients are not impacted.
+	assert.Equal(t, http.StatusOK, call("2.2.2.2", "valid").Code)
+
+	// The ban is lifted after the ban duration.
+	now = now.Add(5 * time.Minute)
+	assert.Equal(t, http.StatusOK, call("1.1.1.1", "valid").Code)
+
+	limiter.cleanup()
+	assert.Empty(t, limiter.attempts)
+}
+
+func TestRateLimiter_Protect_successResetsFailures(t *testing.T) {
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	limiter := NewRateLimiter(RateLimitConfig{
+		MaxFailures: 2,
+		Window:      time.Minute,
+		BanDuration: time.Minute,
+	}, metrics)
+
+	code := http.StatusUnauthorized
+	handler := limiter.Protect("my-acp", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(code)
+	}))
+
+	for _, c := range []int{http.StatusUnauthorized, http.StatusOK, http.StatusUnauthorized, http.StatusOK} {
+		code = c
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/my-acp", nil))
+
+		assert.Equal(t, c, rw.Code)
+	}
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.bans.WithLabelValues("my-acp")))
+}
+
+func TestRateLimiter_Protect_disabled(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{}, nil)
+
+	handler := limiter.Protect("my-acp", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	for i := 0; i < 100; i++ {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/my-acp", nil))
+
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+	}
+
+	assert.Empty(t, limiter.attempts)
+}
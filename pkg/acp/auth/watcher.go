@@ -28,13 +28,15 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/headers"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oauthintro"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oidc"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hublistersv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/secretref"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
 )
 
 // NOTE: if we use the same watcher for all resources, then we need to restart it when new CRDs are
@@ -43,33 +45,65 @@ import (
 // Also, if multiple clients of this watcher are not interested in the same resources
 // add a parameter to NewWatcher to subscribe only to a subset of events.
 
+// ownerKind identifies ACPs among the resources referencing secrets.
+const ownerKind = "AccessControlPolicy"
+
 // Watcher watches access control policy resources and builds configurations out of them.
 type Watcher struct {
 	configsMu sync.RWMutex
 	configs   map[string]*acp.Config
 	previous  uint64
 
-	acps               hublistersv1alpha1.AccessControlPolicyLister
-	secrets            acp.SecretGetter
-	secretRefCounterMu sync.RWMutex
-	secretRefCounter   map[string]int
+	acps      hublistersv1alpha1.AccessControlPolicyLister
+	secrets   *secretref.Resolver
+	decrypter *acp.Decrypter
 
 	refresh chan struct{}
 
 	switcher *HTTPHandlerSwitcher
+	metrics  *Metrics
+	limiter  *RateLimiter
+	quotas   *apikey.Quotas
+	issued   *apikey.IssuedKeys
+	served   map[string]struct{}
+
+	// transport is shared by the handlers to keep connections alive across handler rebuilds.
+	transport *http.Transport
+	// pages are the pages rendered by OIDC handlers, customized by each ACP.
+	pages *oidc.Pages
 }
 
 // NewWatcher returns a new watcher to track ACP resources. It calls the given Updater when an ACP is modified at most
-// once every throttle.
-func NewWatcher(switcher *HTTPHandlerSwitcher, acps hublistersv1alpha1.AccessControlPolicyLister, secrets acp.SecretGetter) *Watcher {
-	return &Watcher{
-		configs:          make(map[string]*acp.Config),
-		acps:             acps,
-		secrets:          secrets,
-		secretRefCounter: make(map[string]int),
-		refresh:          make(chan struct{}, 1),
-		switcher:         switcher,
+// once every throttle. Handlers built by the watcher report their outcome to the given metrics and the ones checking
+// static credentials are protected against brute-force attacks by the given limiter. The quotas of API keys are counted
+// by the given quotas, which survive handler rebuilds, and the keys issued to portal users are looked up in the given
+// issued keys. Secrets referenced by ACPs are resolved by the given resolver,
+// and handlers are rebuilt when they change. Encrypted ACP values are decrypted using the given decrypter, which may be
+// nil if no encryption key is configured. Outbound calls made by handlers, to identity providers for instance, go
+// through the given transport. OIDC handlers render the given pages, or the embedded ones if nil.
+func NewWatcher(switcher *HTTPHandlerSwitcher, acps hublistersv1alpha1.AccessControlPolicyLister, secrets *secretref.Resolver, decrypter *acp.Decrypter, metrics *Metrics, limiter *RateLimiter, quotas *apikey.Quotas, issued *apikey.IssuedKeys, transport *http.Transport, pages *oidc.Pages) *Watcher {
+	w := &Watcher{
+		configs:   make(map[string]*acp.Config),
+		acps:      acps,
+		secrets:   secrets,
+		decrypter: decrypter,
+		refresh:   make(chan struct{}, 1),
+		switcher:  switcher,
+		metrics:   metrics,
+		limiter:   limiter,
+		quotas:    quotas,
+		issued:    issued,
+		served:    make(map[string]struct{}),
+		transport: transport,
+		pages:     pages,
 	}
+
+	// Handlers are rebuilt with the new values of the secrets referenced by the ACPs.
+	secrets.OnChange(ownerKind, func(string) {
+		w.triggerRefresh()
+	})
+
+	return w
 }
 
 // Run launches listener if the watcher is dirty.
@@ -109,106 +143,51 @@ func (w *Watcher) Run(ctx context.Context) {
 
 // OnAdd implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
 func (w *Watcher) OnAdd(obj interface{}) {
-	switch v := obj.(type) {
-	case *hubv1alpha1.AccessControlPolicy:
-		refs := secretReferences(v)
-		w.secretRefCounterMu.Lock()
-		for _, ref := range refs {
-			w.secretRefCounter[ref]++
-		}
-		w.secretRefCounterMu.Unlock()
-
-	case *corev1.Secret:
-		w.secretRefCounterMu.RLock()
-		c := w.secretRefCounter[secretKey(v.Name, v.Namespace)]
-		w.secretRefCounterMu.RUnlock()
-		if c == 0 {
-			return
-		}
-
-	default:
+	policy, ok := obj.(*hubv1alpha1.AccessControlPolicy)
+	if !ok {
 		log.Error().
 			Str("type", fmt.Sprintf("%T", obj)).
 			Msg("Received add event of unknown type")
 		return
 	}
 
-	select {
-	case w.refresh <- struct{}{}:
-	default:
-	}
+	w.secrets.Track(secretref.Owner{Kind: ownerKind, Name: policy.Name}, secretReferences(policy)...)
+	w.triggerRefresh()
 }
 
 // OnUpdate implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
-func (w *Watcher) OnUpdate(oldObj, newObj interface{}) {
-	switch v := newObj.(type) {
-	case *hubv1alpha1.AccessControlPolicy:
-		oldRefs := secretReferences(oldObj.(*hubv1alpha1.AccessControlPolicy))
-		newRefs := secretReferences(v)
-		w.secretRefCounterMu.Lock()
-		for _, ref := range oldRefs {
-			if w.secretRefCounter[ref] > 1 {
-				w.secretRefCounter[ref]--
-			} else {
-				delete(w.secretRefCounter, ref)
-			}
-		}
-		for _, ref := range newRefs {
-			w.secretRefCounter[ref]++
-		}
-		w.secretRefCounterMu.Unlock()
-
-	case *corev1.Secret:
-		w.secretRefCounterMu.RLock()
-		c := w.secretRefCounter[secretKey(v.Name, v.Namespace)]
-		w.secretRefCounterMu.RUnlock()
-		if c == 0 {
-			return
-		}
-
-	default:
+func (w *Watcher) OnUpdate(_, newObj interface{}) {
+	policy, ok := newObj.(*hubv1alpha1.AccessControlPolicy)
+	if !ok {
 		log.Error().
 			Str("type", fmt.Sprintf("%T", newObj)).
 			Msg("Received update event of unknown type")
 		return
 	}
 
-	select {
-	case w.refresh <- struct{}{}:
-	default:
-	}
+	w.secrets.Track(secretref.Owner{Kind: ownerKind, Name: policy.Name}, secretReferences(policy)...)
+	w.triggerRefresh()
 }
 
 // OnDelete implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
 func (w *Watcher) OnDelete(obj interface{}) {
-	switch v := obj.(type) {
-	case *hubv1alpha1.AccessControlPolicy:
-		refs := secretReferences(v)
-		w.secretRefCounterMu.Lock()
-		for _, ref := range refs {
-			if w.secretRefCounter[ref] > 1 {
-				w.secretRefCounter[ref]--
-			} else {
-				delete(w.secretRefCounter, ref)
-			}
-		}
-		w.secretRefCounterMu.Unlock()
-
-	case *corev1.Secret:
-		w.secretRefCounterMu.RLock()
-		c := w.secretRefCounter[secretKey(v.Name, v.Namespace)]
-		w.secretRefCounterMu.RUnlock()
-		if c == 0 {
-			return
-		}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
 
-	default:
+	policy, ok := obj.(*hubv1alpha1.AccessControlPolicy)
+	if !ok {
 		log.Error().
 			Str("type", fmt.Sprintf("%T", obj)).
 			Msg("Received delete event of unknown type")
 		return
 	}
 
+	w.secrets.Untrack(secretref.Owner{Kind: ownerKind, Name: policy.Name})
+	w.triggerRefresh()
+}
+
+func (w *Watcher) triggerRefresh() {
 	select {
 	case w.refresh <- struct{}{}:
 	default:
@@ -232,6 +211,14 @@ func (w *Watcher) makeConfigs() (map[string]*acp.Config, error) {
 			continue
 		}
 
+		if err = acp.DecryptConfig(config, w.decrypter); err != nil {
+			log.Error().
+				Err(err).
+				Str("acp_name", policy.Name).
+				Msg("Could not decrypt ACP configuration")
+			continue
+		}
+
 		configs[policy.Name] = config
 	}
 
@@ -243,45 +230,74 @@ func (w *Watcher) buildRoutes(ctx context.Context) http.Handler {
 	defer w.configsMu.RUnlock()
 
 	mux := http.NewServeMux()
+	served := make(map[string]struct{})
+	handlers := make(map[string]http.Handler)
 
 	for name, cfg := range w.configs {
 		path := "/" + name
+		acpType := getACPType(cfg)
 
-		logger := log.With().Str("acp_name", name).Str("acp_type", getACPType(cfg)).Logger()
+		logger := log.With().Str("acp_name", name).Str("acp_type", acpType).Logger()
 
-		route, err := buildRoute(ctx, name, cfg)
+		route, err := buildRoute(ctx, name, cfg, w.quotas, w.issued, w.transport, w.pages)
 		if err != nil {
 			logger.Error().Err(err).Msg("Could not Create ACP handler")
 			continue
 		}
 
+		if cfg.Headers != nil {
+			route, err = headers.NewHandler(cfg.Headers, name, route)
+			if err != nil {
+				logger.Error().Err(err).Msg("Could not Create ACP headers handler")
+				continue
+			}
+		}
+
+		if cfg.BasicAuth != nil || cfg.APIKey != nil {
+			route = w.limiter.Protect(name, route)
+		}
+
 		logger.Debug().Msg("Registering ACP handler")
 
-		mux.Handle(path, route)
+		handler := w.metrics.Instrument(name, acpType, route)
+
+		mux.Handle(path, handler)
+		handlers[name] = handler
+		served[name] = struct{}{}
+	}
+
+	// Paths not matching a single ACP are evaluated as chains of ACPs.
+	mux.Handle("/", chainHandler{handlers: handlers})
+
+	for name := range w.served {
+		if _, ok := served[name]; !ok {
+			w.metrics.Forget(name)
+		}
 	}
+	w.served = served
 
 	return mux
 }
 
-func buildRoute(ctx context.Context, name string, cfg *acp.Config) (http.Handler, error) {
+func buildRoute(ctx context.Context, name string, cfg *acp.Config, quotas *apikey.Quotas, issued *apikey.IssuedKeys, transport *http.Transport, pages *oidc.Pages) (http.Handler, error) {
 	switch {
 	case cfg.JWT != nil:
-		return jwt.NewHandler(cfg.JWT, name)
+		return jwt.NewHandler(cfg.JWT, name, transport)
 
 	case cfg.BasicAuth != nil:
 		return basicauth.NewHandler(cfg.BasicAuth, name)
 
 	case cfg.APIKey != nil:
-		return apikey.NewHandler(cfg.APIKey, name)
+		return apikey.NewHandler(cfg.APIKey, name, quotas, issued)
 
 	case cfg.OIDC != nil:
-		return oidc.NewHandler(ctx, cfg.OIDC, name)
+		return oidc.NewHandler(ctx, cfg.OIDC, name, transport, pages)
 
 	case cfg.OIDCGoogle != nil:
-		return oidc.NewHandler(ctx, &cfg.OIDCGoogle.Config, name)
+		return oidc.NewHandler(ctx, &cfg.OIDCGoogle.Config, name, transport, pages)
 
 	case cfg.OAuthIntro != nil:
-		return oauthintro.NewHandler(cfg.OAuthIntro, name)
+		return oauthintro.NewHandler(cfg.OAuthIntro, name, transport)
 
 	default:
 		return nil, fmt.Errorf("unknown handler type for ACP %s", name)
@@ -313,26 +329,30 @@ func getACPType(cfg *acp.Config) string {
 	}
 }
 
-func secretKey(name, namespace string) string {
-	return name + "@" + namespace
-}
-
-func secretReferences(policy *hubv1alpha1.AccessControlPolicy) []string {
-	var refs []string
+func secretReferences(policy *hubv1alpha1.AccessControlPolicy) []secretref.Ref {
+	var refs []secretref.Ref
 
 	switch {
+	case policy.Spec.APIKey != nil:
+		for _, key := range policy.Spec.APIKey.Keys {
+			if key.ValueFrom != nil {
+				refs = append(refs, secretref.RefOf(*key.ValueFrom, ""))
+			}
+		}
+
 	case policy.Spec.OIDC != nil:
 		if policy.Spec.OIDC.Secret != nil {
-			refs = append(refs, secretKey(policy.Spec.OIDC.Secret.Name, policy.Spec.OIDC.Secret.Namespace))
+			refs = append(refs, secretref.Ref{Namespace: policy.Spec.OIDC.Secret.Namespace, Name: policy.Spec.OIDC.Secret.Name})
 		}
 
 	case policy.Spec.OIDCGoogle != nil:
 		if policy.Spec.OIDCGoogle.Secret != nil {
-			refs = append(refs, secretKey(policy.Spec.OIDCGoogle.Secret.Name, policy.Spec.OIDCGoogle.Secret.Namespace))
+			refs = append(refs, secretref.Ref{Namespace: policy.Spec.OIDCGoogle.Secret.Namespace, Name: policy.Spec.OIDCGoogle.Secret.Name})
 		}
 
 	case policy.Spec.OAuthIntro != nil:
-		refs = append(refs, secretKey(policy.Spec.OAuthIntro.ClientConfig.Auth.Secret.Name, policy.Spec.OAuthIntro.ClientConfig.Auth.Secret.Namespace))
+		secret := policy.Spec.OAuthIntro.ClientConfig.Auth.Secret
+		refs = append(refs, secretref.Ref{Namespace: secret.Namespace, Name: secret.Name})
 	}
 
 	return refs
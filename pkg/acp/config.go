@@ -28,6 +28,7 @@ import (
 
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/headers"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oauthintro"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oidc"
@@ -35,6 +36,7 @@ import (
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 	"github.com/traefik/hub-agent-kubernetes/pkg/optional"
+	"github.com/traefik/hub-agent-kubernetes/pkg/secretref"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -46,6 +48,8 @@ type Config struct {
 	OIDC       *oidc.Config       `json:"oidc,omitempty"`
 	OIDCGoogle *OIDCGoogle        `json:"oidcGoogle,omitempty"`
 	OAuthIntro *oauthintro.Config `json:"oAuthIntro,omitempty"`
+
+	Headers *headers.Config `json:"headers,omitempty"`
 }
 
 // OIDCGoogle is the Google OIDC configuration.
@@ -69,6 +73,17 @@ func ConfigFromPolicy(policy *hubv1alpha1.AccessControlPolicy) *Config {
 
 // ConfigFromPolicyWithSecret returns an ACP configuration for the given policy and resolves its secret references.
 func ConfigFromPolicyWithSecret(policy *hubv1alpha1.AccessControlPolicy, secrets SecretGetter) (*Config, error) {
+	cfg, err := authConfigFromPolicy(policy, secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Headers = makeHeadersConfig(policy.Spec.Headers)
+
+	return cfg, nil
+}
+
+func authConfigFromPolicy(policy *hubv1alpha1.AccessControlPolicy, secrets SecretGetter) (*Config, error) {
 	switch {
 	case policy.Spec.JWT != nil:
 		return makeJWTConfig(policy.Spec.JWT), nil
@@ -77,7 +92,7 @@ func ConfigFromPolicyWithSecret(policy *hubv1alpha1.AccessControlPolicy, secrets
 		return makeBasicAuthConfig(policy.Spec.BasicAuth), nil
 
 	case policy.Spec.APIKey != nil:
-		return makeAPIKeyConfig(policy.Spec.APIKey), nil
+		return makeAPIKeyConfig(policy.Spec.APIKey, secrets)
 
 	case policy.Spec.OIDC != nil:
 		return makeOIDCConfig(policy.Spec.OIDC, secrets)
@@ -102,6 +117,17 @@ func buildClaims(emails []string) string {
 	return strings.Join(matchers, " || ")
 }
 
+func makeHeadersConfig(policy *hubv1alpha1.AccessControlPolicyHeaders) *headers.Config {
+	if policy == nil {
+		return nil
+	}
+
+	return &headers.Config{
+		Request:  (*headers.RequestConfig)(policy.Request),
+		Response: (*headers.ResponseConfig)(policy.Response),
+	}
+}
+
 func makeJWTConfig(policy *hubv1alpha1.AccessControlPolicyJWT) *Config {
 	return &Config{
 		JWT: &jwt.Config{
@@ -129,13 +155,25 @@ func makeBasicAuthConfig(policy *hubv1alpha1.AccessControlPolicyBasicAuth) *Conf
 	}
 }
 
-func makeAPIKeyConfig(policy *hubv1alpha1.AccessControlPolicyAPIKey) *Config {
+func makeAPIKeyConfig(policy *hubv1alpha1.AccessControlPolicyAPIKey, secrets SecretGetter) (*Config, error) {
 	keys := make([]apikey.Key, 0, len(policy.Keys))
 	for _, k := range policy.Keys {
+		value := k.Value
+		if k.ValueFrom != nil {
+			ref := &corev1.SecretReference{Name: k.ValueFrom.Name, Namespace: k.ValueFrom.Namespace}
+
+			v, err := secrets.GetValue(ref, secretref.KeyOf(*k.ValueFrom, "value"))
+			if err != nil {
+				return nil, fmt.Errorf("get value of key %q: %w", k.ID, err)
+			}
+			value = string(v)
+		}
+
 		keys = append(keys, apikey.Key{
-			ID:       k.ID,
-			Metadata: k.Metadata,
-			Value:    k.Value,
+			ID:        k.ID,
+			Metadata:  k.Metadata,
+			Value:     value,
+			ValueFrom: k.ValueFrom,
 		})
 	}
 
@@ -149,7 +187,20 @@ func makeAPIKeyConfig(policy *hubv1alpha1.AccessControlPolicyAPIKey) *Config {
 			},
 			Keys:           keys,
 			ForwardHeaders: policy.ForwardHeaders,
+			Quota:          makeAPIKeyQuota(policy.Quota),
+			IssuedKeys:     policy.IssuedKeys,
 		},
+	}, nil
+}
+
+func makeAPIKeyQuota(quota *hubv1alpha1.AccessControlPolicyAPIKeyQuota) *apikey.Quota {
+	if quota == nil {
+		return nil
+	}
+
+	return &apikey.Quota{
+		Limit:  quota.Limit,
+		Period: quota.Period.Duration,
 	}
 }
 
@@ -163,6 +214,7 @@ func makeOIDCConfig(policy *hubv1alpha1.AccessControlPolicyOIDC, secrets SecretG
 		AuthParams:     policy.AuthParams,
 		ForwardHeaders: policy.ForwardHeaders,
 		Claims:         policy.Claims,
+		Pages:          makeOIDCPages(policy.Pages),
 	}
 
 	if policy.Secret != nil {
@@ -208,6 +260,17 @@ func makeOIDCConfig(policy *hubv1alpha1.AccessControlPolicyOIDC, secrets SecretG
 	return &Config{OIDC: oidcConfig}, nil
 }
 
+func makeOIDCPages(pages *hubv1alpha1.OIDCPages) *oidc.AuthPages {
+	if pages == nil {
+		return nil
+	}
+
+	return &oidc.AuthPages{
+		Locale:    pages.Locale,
+		Templates: pages.Templates,
+	}
+}
+
 func makeOIDCGoogleConfig(policy *hubv1alpha1.AccessControlPolicyOIDCGoogle, secrets SecretGetter) (*Config, error) {
 	oidcGoogleConfig := &OIDCGoogle{
 		Config: oidc.Config{
@@ -219,6 +282,7 @@ func makeOIDCGoogleConfig(policy *hubv1alpha1.AccessControlPolicyOIDCGoogle, sec
 			AuthParams:     policy.AuthParams,
 			ForwardHeaders: policy.ForwardHeaders,
 			Claims:         buildClaims(policy.Emails),
+			Pages:          makeOIDCPages(policy.Pages),
 		},
 		Emails: policy.Emails,
 	}
@@ -0,0 +1,162 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package acp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EncryptedValuePrefix prefixes ACP values encrypted at rest. Such values are decrypted by the agent when loading the
+// ACP so the plaintext never gets stored in the cluster.
+const EncryptedValuePrefix = "enc:v1:"
+
+// Decrypter decrypts ACP values encrypted with AES-256-GCM. The encryption key is typically a data key provided by a
+// KMS (envelope encryption) and mounted in the agent.
+type Decrypter struct {
+	aead cipher.AEAD
+}
+
+// NewDecrypter creates a new Decrypter using the given 32 bytes key.
+func NewDecrypter(key []byte) (*Decrypter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Decrypter{aead: aead}, nil
+}
+
+// NewDecrypterFromFile creates a new Decrypter using the base64 encoded key stored in the given file.
+func NewDecrypterFromFile(path string) (*Decrypter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read encryption key: %w", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+
+	return NewDecrypter(key)
+}
+
+// Decrypt decrypts the given value if it is encrypted, otherwise it is returned as is.
+func (d *Decrypter) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if d == nil {
+		return "", errors.New("encrypted value found but no encryption key is configured")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted value: %w", err)
+	}
+
+	nonceSize := d.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	plaintext, err := d.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt value: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncrypted returns whether the given value is encrypted.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedValuePrefix)
+}
+
+// Encrypt encrypts the given value with the given 32 bytes key so it can be stored in an ACP.
+func Encrypt(key []byte, value string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+
+	data := aead.Seal(nonce, nonce, []byte(value), nil)
+
+	return EncryptedValuePrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptConfig decrypts in place the encrypted values of the given configuration.
+func DecryptConfig(cfg *Config, d *Decrypter) error {
+	var err error
+
+	switch {
+	case cfg.JWT != nil:
+		if cfg.JWT.SigningSecret, err = d.Decrypt(cfg.JWT.SigningSecret); err != nil {
+			return fmt.Errorf("decrypt signing secret: %w", err)
+		}
+
+	case cfg.BasicAuth != nil:
+		users := make([]string, len(cfg.BasicAuth.Users))
+		for i, user := range cfg.BasicAuth.Users {
+			if users[i], err = d.Decrypt(user); err != nil {
+				return fmt.Errorf("decrypt user %d: %w", i, err)
+			}
+		}
+		cfg.BasicAuth.Users = users
+
+	case cfg.APIKey != nil:
+		for i, key := range cfg.APIKey.Keys {
+			if cfg.APIKey.Keys[i].Value, err = d.Decrypt(key.Value); err != nil {
+				return fmt.Errorf("decrypt key %q: %w", key.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes long, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create GCM: %w", err)
+	}
+
+	return aead, nil
+}
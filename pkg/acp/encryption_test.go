@@ -0,0 +1,163 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package acp
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
+)
+
+var testEncryptionKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestDecrypter_Decrypt(t *testing.T) {
+	encrypted, err := Encrypt(testEncryptionKey, "my-secret")
+	require.NoError(t, err)
+
+	encryptedWithOtherKey, err := Encrypt([]byte("fedcba9876543210fedcba9876543210"), "my-secret")
+	require.NoError(t, err)
+
+	decrypter, err := NewDecrypter(testEncryptionKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc      string
+		decrypter *Decrypter
+		value     string
+		expected  string
+		wantErr   bool
+	}{
+		{
+			desc:      "plaintext value",
+			decrypter: decrypter,
+			value:     "my-secret",
+			expected:  "my-secret",
+		},
+		{
+			desc:     "plaintext value without decrypter",
+			value:    "my-secret",
+			expected: "my-secret",
+		},
+		{
+			desc:      "encrypted value",
+			decrypter: decrypter,
+			value:     encrypted,
+			expected:  "my-secret",
+		},
+		{
+			desc:    "encrypted value without decrypter",
+			value:   encrypted,
+			wantErr: true,
+		},
+		{
+			desc:      "encrypted with another key",
+			decrypter: decrypter,
+			value:     encryptedWithOtherKey,
+			wantErr:   true,
+		},
+		{
+			desc:      "malformed value",
+			decrypter: decrypter,
+			value:     EncryptedValuePrefix + "not base64",
+			wantErr:   true,
+		},
+		{
+			desc:      "truncated value",
+			decrypter: decrypter,
+			value:     EncryptedValuePrefix + "AAAA",
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := test.decrypter.Decrypt(test.value)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func TestNewDecrypter_invalidKey(t *testing.T) {
+	_, err := NewDecrypter([]byte("too short"))
+	assert.Error(t, err)
+}
+
+func TestNewDecrypterFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(testEncryptionKey)+"\n"), 0o600)
+	require.NoError(t, err)
+
+	decrypter, err := NewDecrypterFromFile(path)
+	require.NoError(t, err)
+
+	encrypted, err := Encrypt(testEncryptionKey, "my-secret")
+	require.NoError(t, err)
+
+	got, err := decrypter.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "my-secret", got)
+}
+
+func TestDecryptConfig(t *testing.T) {
+	decrypter, err := NewDecrypter(testEncryptionKey)
+	require.NoError(t, err)
+
+	encrypt := func(value string) string {
+		encrypted, errEncrypt := Encrypt(testEncryptionKey, value)
+		require.NoError(t, errEncrypt)
+
+		return encrypted
+	}
+
+	users := basicauth.Users{encrypt("user:$apr1$hash"), "other:$apr1$hash"}
+	cfg := &Config{BasicAuth: &basicauth.Config{Users: users}}
+
+	require.NoError(t, DecryptConfig(cfg, decrypter))
+	assert.Equal(t, basicauth.Users{"user:$apr1$hash", "other:$apr1$hash"}, cfg.BasicAuth.Users)
+	// The users of the policy must not be altered.
+	assert.True(t, IsEncrypted(users[0]))
+
+	cfg = &Config{JWT: &jwt.Config{SigningSecret: encrypt("secret")}}
+
+	require.NoError(t, DecryptConfig(cfg, decrypter))
+	assert.Equal(t, "secret", cfg.JWT.SigningSecret)
+
+	cfg = &Config{APIKey: &apikey.Config{Keys: []apikey.Key{{ID: "id", Value: encrypt("hash")}}}}
+
+	require.NoError(t, DecryptConfig(cfg, decrypter))
+	assert.Equal(t, "hash", cfg.APIKey.Keys[0].Value)
+
+	cfg = &Config{JWT: &jwt.Config{SigningSecret: encrypt("secret")}}
+	assert.Error(t, DecryptConfig(cfg, nil))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package expr
+
+import (
+	"fmt"
+
+	"github.com/vulcand/predicate"
+)
+
+// claimsSetter sets claims in a claims map.
+type claimsSetter func(claims map[string]interface{})
+
+// SampleClaims returns claims satisfying the given expression, such as the claims of test tokens. Only the left
+// operand of OR expressions is satisfied and negations are ignored, so it returns an error when the sample claims
+// don't satisfy the expression.
+func SampleClaims(expr string) (map[string]interface{}, error) {
+	parser, err := predicate.NewParser(predicate.Def{
+		Operators: predicate.Operators{
+			AND: func(a, b claimsSetter) claimsSetter {
+				return func(claims map[string]interface{}) {
+					a(claims)
+					b(claims)
+				}
+			},
+			OR: func(a, _ claimsSetter) claimsSetter {
+				return a
+			},
+			NOT: func(_ claimsSetter) claimsSetter {
+				return func(map[string]interface{}) {}
+			},
+		},
+		Functions: map[string]interface{}{
+			"Equals":        sampleClaim,
+			"Prefix":        sampleClaim,
+			"Contains":      sampleListClaim,
+			"SplitContains": sampleSplitClaim,
+			"Ohubf":         sampleOneOfClaim,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create parser: %w", err)
+	}
+
+	setter, err := parser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse expression: %w", err)
+	}
+
+	claims := make(map[string]interface{})
+	setter.(claimsSetter)(claims)
+
+	pred, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	if !pred(claims) {
+		return nil, fmt.Errorf("unable to find claims satisfying %q", expr)
+	}
+
+	return claims, nil
+}
+
+func sampleClaim(claimName, value string) claimsSetter {
+	return func(claims map[string]interface{}) {
+		setClaim(claims, claimName, value)
+	}
+}
+
+func sampleListClaim(claimName, value string) claimsSetter {
+	return func(claims map[string]interface{}) {
+		setClaim(claims, claimName, []interface{}{value})
+	}
+}
+
+func sampleSplitClaim(claimName, _, value string) claimsSetter {
+	return sampleClaim(claimName, value)
+}
+
+func sampleOneOfClaim(claimName string, values ...string) claimsSetter {
+	return func(claims map[string]interface{}) {
+		if len(values) > 0 {
+			setClaim(claims, claimName, values[0])
+		}
+	}
+}
+
+// setClaim sets the value addressed by claimName in the given claims map, creating the nested maps it goes through.
+func setClaim(claims map[string]interface{}, claimName string, value interface{}) {
+	parts := split(claimName, '.')
+
+	v := claims
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := v[part].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			v[part] = nested
+		}
+		v = nested
+	}
+
+	v[parts[len(parts)-1]] = value
+}
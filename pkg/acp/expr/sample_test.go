@@ -0,0 +1,92 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleClaims(t *testing.T) {
+	tests := []struct {
+		desc    string
+		expr    string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			desc: "equals",
+			expr: "Equals(`grp`, `admin`)",
+			want: map[string]interface{}{"grp": "admin"},
+		},
+		{
+			desc: "AND expression",
+			expr: "Prefix(`sub`, `svc-`) && Contains(`roles`, `deploy`) && SplitContains(`scope`, ` `, `write`)",
+			want: map[string]interface{}{
+				"sub":   "svc-",
+				"roles": []interface{}{"deploy"},
+				"scope": "write",
+			},
+		},
+		{
+			desc: "OR expression",
+			expr: "(Equals(`grp`, `admin`) || Equals(`grp`, `dev`)) && Ohubf(`env`, `prod`, `staging`)",
+			want: map[string]interface{}{"grp": "admin", "env": "prod"},
+		},
+		{
+			desc: "nested claims",
+			expr: "Equals(`user.name`, `john`) && Equals(`user.role`, `developer`) && Equals(`a\\.b`, `c`)",
+			want: map[string]interface{}{
+				"user": map[string]interface{}{"name": "john", "role": "developer"},
+				"a.b":  "c",
+			},
+		},
+		{
+			desc: "negation",
+			expr: "!Equals(`grp`, `guest`)",
+			want: map[string]interface{}{},
+		},
+		{
+			desc:    "unsatisfied negation",
+			expr:    "Equals(`grp`, `admin`) && !Prefix(`grp`, `adm`)",
+			wantErr: true,
+		},
+		{
+			desc:    "invalid expression",
+			expr:    "Equals(`grp`",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := SampleClaims(test.expr)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
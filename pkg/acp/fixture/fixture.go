@@ -0,0 +1,403 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package fixture generates sample requests, carrying valid or invalid credentials, to verify how an Access Control
+// Policy is enforced end-to-end.
+package fixture
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	goauth "github.com/abbot/go-http-auth"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
+	acpjwt "github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"golang.org/x/crypto/sha3"
+)
+
+const defaultTTL = time.Hour
+
+// Fixture is a sample request to send to a route protected by an Access Control Policy.
+type Fixture struct {
+	Description string `json:"description"`
+	// Status is the status code the policy is expected to answer the request with.
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Query   map[string]string `json:"query,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
+}
+
+// Apply sets the headers, query parameters and cookies of the fixture on the given request.
+func (f Fixture) Apply(req *http.Request) {
+	for name, value := range f.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if len(f.Query) > 0 {
+		query := req.URL.Query()
+		for name, value := range f.Query {
+			query.Set(name, value)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	for name, value := range f.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+}
+
+// Options configures the generation of fixtures.
+type Options struct {
+	// Credentials holds the API key values, or the user:password pairs for basic auth, valid fixtures are generated
+	// for. Policies only hold the hashes of these credentials.
+	Credentials []string
+	// PrivateKey is the PEM-encoded RSA or ECDSA private key JWTs are signed with, when the policy verifies them with
+	// a public key or a JWK set.
+	PrivateKey []byte
+	// KeyID is the ID of the private key in the JWK set of the policy.
+	KeyID string
+	// Claims are added to the claims of the JWTs, e.g. to set the issuer a JWK set is fetched from.
+	Claims map[string]interface{}
+	// TTL is the lifetime of the valid JWTs. It defaults to an hour.
+	TTL time.Duration
+}
+
+// Generate generates fixtures for the given policy. JWT, basic auth and API key policies are supported.
+func Generate(policy *hubv1alpha1.AccessControlPolicy, opts Options) ([]Fixture, error) {
+	cfg := acp.ConfigFromPolicy(policy)
+
+	switch {
+	case cfg == nil:
+		return nil, errors.New("invalid access control policy")
+	case cfg.JWT != nil:
+		return generateJWT(cfg.JWT, opts)
+	case cfg.BasicAuth != nil:
+		return generateBasicAuth(cfg.BasicAuth, opts)
+	case cfg.APIKey != nil:
+		return generateAPIKey(cfg.APIKey, opts)
+	default:
+		return nil, errors.New("fixtures can only be generated for JWT, basic auth and API key policies")
+	}
+}
+
+func generateJWT(cfg *acpjwt.Config, opts Options) ([]Fixture, error) {
+	method, key, otherKey, err := jwtSigningKeys(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+
+	now := time.Now()
+	baseClaims := func(exp time.Time) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"iat": now.Unix(),
+			"exp": exp.Unix(),
+		}
+		for name, value := range opts.Claims {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	claims := baseClaims(now.Add(ttl))
+	if cfg.Claims != "" {
+		var sample map[string]interface{}
+		sample, err = expr.SampleClaims(cfg.Claims)
+		if err != nil {
+			return nil, fmt.Errorf("sample claims: %w", err)
+		}
+		for name, value := range sample {
+			claims[name] = value
+		}
+	}
+
+	sign := func(desc string, status int, claims jwt.MapClaims, key interface{}) (Fixture, error) {
+		tok := jwt.NewWithClaims(method, claims)
+		if opts.KeyID != "" {
+			tok.Header["kid"] = opts.KeyID
+		}
+
+		raw, signErr := tok.SignedString(key)
+		if signErr != nil {
+			return Fixture{}, fmt.Errorf("sign token: %w", signErr)
+		}
+
+		return Fixture{
+			Description: desc,
+			Status:      status,
+			Headers:     map[string]string{"Authorization": "Bearer " + raw},
+		}, nil
+	}
+
+	valid, err := sign("Valid token", http.StatusOK, claims, key)
+	if err != nil {
+		return nil, err
+	}
+	expired, err := sign("Expired token", http.StatusUnauthorized, baseClaims(now.Add(-time.Minute)), key)
+	if err != nil {
+		return nil, err
+	}
+	forged, err := sign("Token signed with another key", http.StatusUnauthorized, claims, otherKey)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := []Fixture{
+		valid,
+		{Description: "Missing token", Status: http.StatusUnauthorized},
+		expired,
+		forged,
+	}
+
+	if cfg.Claims != "" {
+		// Claims are parsed by the policy before being validated.
+		pred, parseErr := expr.Parse(cfg.Claims)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		claims = baseClaims(now.Add(ttl))
+		if !pred(claims) {
+			unauthorized, signErr := sign("Token without the required claims", http.StatusForbidden, claims, key)
+			if signErr != nil {
+				return nil, signErr
+			}
+			fixtures = append(fixtures, unauthorized)
+		}
+	}
+
+	return fixtures, nil
+}
+
+// jwtSigningKeys returns the signing method and key of valid JWTs, and another key of the same kind to forge invalid
+// ones.
+func jwtSigningKeys(cfg *acpjwt.Config, opts Options) (method jwt.SigningMethod, key, otherKey interface{}, err error) {
+	if len(opts.PrivateKey) == 0 {
+		if cfg.SigningSecret == "" {
+			return nil, nil, nil, errors.New("signing tokens verified with a public key or a JWK set requires a private key")
+		}
+
+		secret := []byte(cfg.SigningSecret)
+		if cfg.SigningSecretBase64Encoded {
+			secret, err = base64.StdEncoding.DecodeString(cfg.SigningSecret)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("decode base64-encoded signing secret: %w", err)
+			}
+		}
+
+		otherSecret := make([]byte, 32)
+		if _, err = rand.Read(otherSecret); err != nil {
+			return nil, nil, nil, fmt.Errorf("generate signing secret: %w", err)
+		}
+
+		return jwt.SigningMethodHS256, secret, otherSecret, nil
+	}
+
+	if opts.KeyID == "" && cfg.PublicKey == "" {
+		return nil, nil, nil, errors.New("a key ID is required to sign tokens verified with a JWK set")
+	}
+
+	privKey, err := parsePrivateKey(opts.PrivateKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	switch k := privKey.(type) {
+	case *rsa.PrivateKey:
+		otherKey, err = rsa.GenerateKey(rand.Reader, k.N.BitLen())
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("generate RSA key: %w", err)
+		}
+
+		return jwt.SigningMethodRS256, k, otherKey, nil
+
+	case *ecdsa.PrivateKey:
+		otherKey, err = ecdsa.GenerateKey(k.Curve, rand.Reader)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("generate ECDSA key: %w", err)
+		}
+
+		switch k.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, k, otherKey, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, k, otherKey, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, k, otherKey, nil
+		default:
+			return nil, nil, nil, fmt.Errorf("unsupported ECDSA curve %q", k.Curve.Params().Name)
+		}
+
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported private key type %T, RSA or ECDSA expected", privKey)
+	}
+}
+
+func parsePrivateKey(raw []byte) (interface{}, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("empty or ill-formatted private key")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.New("unable to parse private key, PKCS #8, PKCS #1 or SEC 1 expected")
+}
+
+func generateBasicAuth(cfg *basicauth.Config, opts Options) ([]Fixture, error) {
+	if len(cfg.Users) == 0 {
+		return nil, errors.New("policy has no users")
+	}
+
+	hashes := make(map[string]string, len(cfg.Users))
+	var firstUser string
+	for _, user := range cfg.Users {
+		name, hash, ok := strings.Cut(user, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid user %q", user)
+		}
+		if firstUser == "" {
+			firstUser = name
+		}
+		hashes[name] = hash
+	}
+
+	var fixtures []Fixture
+	for _, cred := range opts.Credentials {
+		name, password, ok := strings.Cut(cred, ":")
+		if !ok {
+			return nil, errors.New("invalid basic auth credential, user:password expected")
+		}
+
+		hash, ok := hashes[name]
+		if !ok || !goauth.CheckSecret(password, hash) {
+			return nil, fmt.Errorf("credential of user %q doesn't match the policy", name)
+		}
+
+		fixtures = append(fixtures, basicAuthFixture(fmt.Sprintf("Valid credential of user %q", name), http.StatusOK, name, password))
+	}
+
+	wrongPassword, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	unknownUser, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(fixtures,
+		Fixture{Description: "Missing credential", Status: http.StatusUnauthorized},
+		basicAuthFixture(fmt.Sprintf("Wrong password of user %q", firstUser), http.StatusUnauthorized, firstUser, wrongPassword),
+		basicAuthFixture("Unknown user", http.StatusUnauthorized, "unknown-"+unknownUser, wrongPassword),
+	), nil
+}
+
+func basicAuthFixture(desc string, status int, user, password string) Fixture {
+	cred := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+
+	return Fixture{
+		Description: desc,
+		Status:      status,
+		Headers:     map[string]string{"Authorization": "Basic " + cred},
+	}
+}
+
+func generateAPIKey(cfg *apikey.Config, opts Options) ([]Fixture, error) {
+	keyIDs := make(map[string]string, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		keyIDs[k.Value] = k.ID
+	}
+
+	keyFixture := func(desc string, status int, value string) Fixture {
+		fixture := Fixture{Description: desc, Status: status}
+
+		switch {
+		case cfg.KeySource.Header != "":
+			if cfg.KeySource.Header == "Authorization" && cfg.KeySource.HeaderAuthScheme != "" {
+				value = cfg.KeySource.HeaderAuthScheme + " " + value
+			}
+			fixture.Headers = map[string]string{cfg.KeySource.Header: value}
+		case cfg.KeySource.Query != "":
+			fixture.Query = map[string]string{cfg.KeySource.Query: value}
+		case cfg.KeySource.Cookie != "":
+			fixture.Cookies = map[string]string{cfg.KeySource.Cookie: value}
+		}
+
+		return fixture
+	}
+
+	var fixtures []Fixture
+	for _, cred := range opts.Credentials {
+		hash := make([]byte, 64)
+		sha3.ShakeSum256(hash, []byte(cred))
+
+		id, ok := keyIDs[hex.EncodeToString(hash)]
+		if !ok {
+			return nil, errors.New("API key doesn't match the policy")
+		}
+
+		fixtures = append(fixtures, keyFixture(fmt.Sprintf("Valid key %q", id), http.StatusOK, cred))
+	}
+
+	unknownKey, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(fixtures,
+		Fixture{Description: "Missing key", Status: http.StatusUnauthorized},
+		keyFixture("Unknown key", http.StatusUnauthorized, unknownKey),
+	), nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random string: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package fixture_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/fixture"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestGenerate(t *testing.T) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rawPrivKey, err := x509.MarshalPKCS8PrivateKey(privKey)
+	require.NoError(t, err)
+	rawPubKey, err := x509.MarshalPKIXPublicKey(&privKey.PublicKey)
+	require.NoError(t, err)
+
+	hash := make([]byte, 64)
+	sha3.ShakeSum256(hash, []byte("my-api-key"))
+
+	tests := []struct {
+		desc      string
+		spec      hubv1alpha1.AccessControlPolicySpec
+		opts      fixture.Options
+		wantDescs []string
+		wantErr   bool
+	}{
+		{
+			desc: "JWT with a signing secret and claims",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{
+					SigningSecret: "secret",
+					Claims:        "Equals(`grp`, `admin`) && Contains(`scope`, `deploy`)",
+				},
+			},
+			wantDescs: []string{
+				"Valid token",
+				"Missing token",
+				"Expired token",
+				"Token signed with another key",
+				"Token without the required claims",
+			},
+		},
+		{
+			desc: "JWT with a public key",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{
+					PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rawPubKey})),
+				},
+			},
+			opts: fixture.Options{
+				PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rawPrivKey}),
+			},
+			wantDescs: []string{
+				"Valid token",
+				"Missing token",
+				"Expired token",
+				"Token signed with another key",
+			},
+		},
+		{
+			desc: "JWT with a public key and no private key",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{
+					PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rawPubKey})),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "basic auth",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+					Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+				},
+			},
+			opts: fixture.Options{Credentials: []string{"test:test"}},
+			wantDescs: []string{
+				`Valid credential of user "test"`,
+				"Missing credential",
+				`Wrong password of user "test"`,
+				"Unknown user",
+			},
+		},
+		{
+			desc: "basic auth with a wrong password",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+					Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+				},
+			},
+			opts:    fixture.Options{Credentials: []string{"test:wrong"}},
+			wantErr: true,
+		},
+		{
+			desc: "API key in a header with an auth scheme",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				APIKey: &hubv1alpha1.AccessControlPolicyAPIKey{
+					KeySource: hubv1alpha1.TokenSource{Header: "Authorization", HeaderAuthScheme: "Bearer"},
+					Keys:      []hubv1alpha1.AccessControlPolicyAPIKeyKey{{ID: "my-key", Value: hex.EncodeToString(hash)}},
+				},
+			},
+			opts: fixture.Options{Credentials: []string{"my-api-key"}},
+			wantDescs: []string{
+				`Valid key "my-key"`,
+				"Missing key",
+				"Unknown key",
+			},
+		},
+		{
+			desc: "API key in a query parameter",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				APIKey: &hubv1alpha1.AccessControlPolicyAPIKey{
+					KeySource: hubv1alpha1.TokenSource{Query: "api-key"},
+					Keys:      []hubv1alpha1.AccessControlPolicyAPIKeyKey{{ID: "my-key", Value: hex.EncodeToString(hash)}},
+				},
+			},
+			opts: fixture.Options{Credentials: []string{"my-api-key"}},
+			wantDescs: []string{
+				`Valid key "my-key"`,
+				"Missing key",
+				"Unknown key",
+			},
+		},
+		{
+			desc: "unknown API key",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				APIKey: &hubv1alpha1.AccessControlPolicyAPIKey{
+					KeySource: hubv1alpha1.TokenSource{Header: "Api-Key"},
+					Keys:      []hubv1alpha1.AccessControlPolicyAPIKeyKey{{ID: "my-key", Value: hex.EncodeToString(hash)}},
+				},
+			},
+			opts:    fixture.Options{Credentials: []string{"other-api-key"}},
+			wantErr: true,
+		},
+		{
+			desc: "OIDC",
+			spec: hubv1alpha1.AccessControlPolicySpec{
+				OIDC: &hubv1alpha1.AccessControlPolicyOIDC{Issuer: "https://idp.example.com"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			policy := &hubv1alpha1.AccessControlPolicy{Spec: test.spec}
+
+			fixtures, err := fixture.Generate(policy, test.opts)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			handler := newHandler(t, policy)
+
+			var descs []string
+			for _, f := range fixtures {
+				descs = append(descs, f.Description)
+
+				req := httptest.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+				f.Apply(req)
+				// Traefik forwards the original URI to the ACP.
+				req.Header.Set("X-Forwarded-Uri", req.URL.RequestURI())
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				assert.Equal(t, f.Status, rec.Code, f.Description)
+			}
+
+			assert.Equal(t, test.wantDescs, descs)
+		})
+	}
+}
+
+func newHandler(t *testing.T, policy *hubv1alpha1.AccessControlPolicy) http.Handler {
+	t.Helper()
+
+	cfg := acp.ConfigFromPolicy(policy)
+
+	var (
+		handler http.Handler
+		err     error
+	)
+	switch {
+	case cfg.JWT != nil:
+		handler, err = jwt.NewHandler(cfg.JWT, "acp", nil)
+	case cfg.BasicAuth != nil:
+		handler, err = basicauth.NewHandler(cfg.BasicAuth, "acp")
+	case cfg.APIKey != nil:
+		handler, err = apikey.NewHandler(cfg.APIKey, "acp", nil, nil)
+	}
+	require.NoError(t, err)
+
+	return handler
+}
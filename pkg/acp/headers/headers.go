@@ -0,0 +1,221 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package headers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"text/template"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
+)
+
+// Config configures the header transformations of an ACP.
+type Config struct {
+	Request  *RequestConfig  `json:"request,omitempty"`
+	Response *ResponseConfig `json:"response,omitempty"`
+}
+
+// RequestConfig configures the transformations of the headers of the authorized requests.
+type RequestConfig struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+	Rename map[string]string `json:"rename,omitempty"`
+}
+
+// ResponseConfig configures the transformations of the headers of the responses.
+type ResponseConfig struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+// RequestHeaders returns the names of the request headers set, removed or renamed by the given configuration, which
+// must be forwarded to the upstream service.
+func (c *Config) RequestHeaders() []string {
+	if c == nil || c.Request == nil {
+		return nil
+	}
+
+	var names []string
+	for name := range c.Request.Set {
+		names = append(names, name)
+	}
+	names = append(names, c.Request.Remove...)
+	for from, to := range c.Request.Rename {
+		names = append(names, from, to)
+	}
+
+	return names
+}
+
+// ResponseHeaders returns the response headers to set, removed headers having an empty value, as expected by the
+// Traefik Headers middleware.
+func (c *Config) ResponseHeaders() map[string]string {
+	if c == nil || c.Response == nil {
+		return nil
+	}
+
+	headers := make(map[string]string, len(c.Response.Set)+len(c.Response.Remove))
+	for name, value := range c.Response.Set {
+		headers[name] = value
+	}
+	for _, name := range c.Response.Remove {
+		headers[name] = ""
+	}
+
+	if len(headers) == 0 {
+		return nil
+	}
+
+	return headers
+}
+
+// ParseTemplate parses the given value of a request header to set.
+func ParseTemplate(value string) (*template.Template, error) {
+	return template.New("header").
+		Funcs(template.FuncMap{
+			// Functions are redefined when executing the template, they are only declared here.
+			"claim":  func(string) string { return "" },
+			"header": func(string) string { return "" },
+		}).
+		Parse(value)
+}
+
+type claimsKey struct{}
+
+// RecordClaims records the claims of a request authorized by an ACP handler, so they can be referenced by the
+// request headers set by the ACP.
+func RecordClaims(req *http.Request, claims map[string]interface{}) {
+	if recorded, ok := req.Context().Value(claimsKey{}).(*map[string]interface{}); ok {
+		*recorded = claims
+	}
+}
+
+// Handler applies the header transformations of an ACP to the requests authorized by its handler.
+type Handler struct {
+	next   http.Handler
+	name   string
+	set    map[string]*template.Template
+	remove []string
+	rename map[string]string
+}
+
+// NewHandler returns a new Handler applying the request header transformations of the given configuration to the
+// requests authorized by the given ACP handler. Response headers are set by the ingress controllers.
+func NewHandler(cfg *Config, name string, next http.Handler) (*Handler, error) {
+	h := &Handler{
+		next: next,
+		name: name,
+		set:  make(map[string]*template.Template),
+	}
+
+	if cfg.Request == nil {
+		return h, nil
+	}
+
+	for header, value := range cfg.Request.Set {
+		tmpl, err := ParseTemplate(value)
+		if err != nil {
+			return nil, fmt.Errorf("parse template of header %q: %w", header, err)
+		}
+
+		h.set[header] = tmpl
+	}
+	h.remove = cfg.Request.Remove
+	h.rename = cfg.Request.Rename
+
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var claims map[string]interface{}
+	ctx := context.WithValue(req.Context(), claimsKey{}, &claims)
+
+	rec := httptest.NewRecorder()
+	h.next.ServeHTTP(rec, req.WithContext(ctx))
+
+	for name, values := range rec.Header() {
+		rw.Header()[name] = values
+	}
+
+	if rec.Code < http.StatusOK || rec.Code >= http.StatusMultipleChoices {
+		rw.WriteHeader(rec.Code)
+		_, _ = rw.Write(rec.Body.Bytes())
+
+		return
+	}
+
+	// Headers with an empty value are removed from the request by the ingress controllers.
+	for from, to := range h.rename {
+		if value := req.Header.Get(from); value != "" {
+			rw.Header().Set(to, value)
+		}
+		rw.Header().Set(from, "")
+	}
+
+	for _, name := range h.remove {
+		rw.Header().Set(name, "")
+	}
+
+	for name, tmpl := range h.set {
+		value, err := execute(tmpl, req, claims)
+		if err != nil {
+			log.Error().Err(err).
+				Str("handler_name", h.name).
+				Str("header", name).
+				Msg("Unable to render header value")
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		rw.Header().Set(name, value)
+	}
+
+	rw.WriteHeader(rec.Code)
+}
+
+func execute(tmpl *template.Template, req *http.Request, claims map[string]interface{}) (string, error) {
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	tmpl.Funcs(template.FuncMap{
+		"claim": func(name string) (string, error) {
+			values, err := expr.PluckClaim(name, claims)
+			if err != nil {
+				return "", err
+			}
+
+			return strings.Join(values, ","), nil
+		},
+		"header": req.Header.Get,
+	})
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, nil); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
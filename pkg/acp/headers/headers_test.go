@@ -0,0 +1,113 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package headers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	cfg := &Config{
+		Request: &RequestConfig{
+			Set: map[string]string{
+				"X-User":    `{{ claim "sub" }}`,
+				"X-Request": `{{ header "X-Request-Id" }}-{{ claim "tenant.id" }}`,
+			},
+			Remove: []string{"Cookie"},
+			Rename: map[string]string{"X-Api-Version": "X-Version"},
+		},
+	}
+
+	tests := []struct {
+		desc       string
+		authCode   int
+		wantCode   int
+		wantHeader http.Header
+	}{
+		{
+			desc:     "authorized request",
+			authCode: http.StatusOK,
+			wantCode: http.StatusOK,
+			wantHeader: http.Header{
+				"Authorization": {""},
+				"X-User":        {"john"},
+				"X-Request":     {"abc-acme"},
+				"Cookie":        {""},
+				"X-Api-Version": {""},
+				"X-Version":     {"v2"},
+			},
+		},
+		{
+			desc:     "denied request",
+			authCode: http.StatusUnauthorized,
+			wantCode: http.StatusUnauthorized,
+			wantHeader: http.Header{
+				"Authorization": {""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				RecordClaims(req, map[string]interface{}{
+					"sub":    "john",
+					"tenant": map[string]interface{}{"id": "acme"},
+				})
+
+				rw.Header().Set("Authorization", "")
+				rw.WriteHeader(test.authCode)
+			})
+
+			handler, err := NewHandler(cfg, "acp@my-ns", next)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Header.Set("X-Request-Id", "abc")
+			req.Header.Set("X-Api-Version", "v2")
+			req.Header.Set("Cookie", "session=secret")
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantHeader, rec.Header())
+		})
+	}
+}
+
+func TestConfig_ResponseHeaders(t *testing.T) {
+	cfg := &Config{
+		Response: &ResponseConfig{
+			Set:    map[string]string{"X-Frame-Options": "DENY"},
+			Remove: []string{"Server"},
+		},
+	}
+
+	assert.Equal(t, map[string]string{"X-Frame-Options": "DENY", "Server": ""}, cfg.ResponseHeaders())
+	assert.Nil(t, (&Config{}).ResponseHeaders())
+	assert.Nil(t, (*Config)(nil).ResponseHeaders())
+}
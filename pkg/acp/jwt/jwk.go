@@ -168,19 +168,24 @@ type RemoteKeySet struct {
 	client   *http.Client
 }
 
-// NewRemoteKeySet returns a RemoteKeySet.
-func NewRemoteKeySet(url string) *RemoteKeySet {
+// NewRemoteKeySet returns a RemoteKeySet fetching keys through the given transport. If transport is nil, a dedicated
+// one is used.
+func NewRemoteKeySet(url string, transport *http.Transport) *RemoteKeySet {
+	if transport == nil {
+		transport = &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		}
+	}
+
 	return &RemoteKeySet{
 		url: url,
 		client: &http.Client{
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout:   30 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-				TLSHandshakeTimeout: 10 * time.Second,
-			},
-			Timeout: 5 * time.Second,
+			Transport: transport,
+			Timeout:   5 * time.Second,
 		},
 	}
 }
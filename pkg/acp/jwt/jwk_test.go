@@ -108,7 +108,7 @@ func TestRemoteKeySet_KeysFetchesKeySet(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(hdlr))
 	defer srv.Close()
 
-	ks := jwt.NewRemoteKeySet(srv.URL)
+	ks := jwt.NewRemoteKeySet(srv.URL, nil)
 
 	gotFooKey, err := ks.Key(context.Background(), "foo-key")
 	require.NoError(t, err)
@@ -136,7 +136,7 @@ func TestRemoteKeySet_KeysFetchesKeySetWithoutCache(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(hdlr))
 	defer srv.Close()
 
-	ks := jwt.NewRemoteKeySet(srv.URL)
+	ks := jwt.NewRemoteKeySet(srv.URL, nil)
 
 	gotFooKey, err := ks.Key(context.Background(), "foo-key")
 	require.NoError(t, err)
@@ -158,7 +158,7 @@ func TestRemoteKeySet_KeysReturnsNilWhenKeyIsUnknown(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(hdlr))
 	defer srv.Close()
 
-	ks := jwt.NewRemoteKeySet(srv.URL)
+	ks := jwt.NewRemoteKeySet(srv.URL, nil)
 
 	gotKey, err := ks.Key(context.Background(), "meh-key")
 	require.NoError(t, err)
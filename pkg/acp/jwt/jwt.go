@@ -30,9 +30,10 @@ import (
 	"sync"
 
 	"github.com/golang-jwt/jwt/v4"
-	jwtreq "github.com/golang-jwt/jwt/v4/request"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/headers"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/token"
 )
 
 // Config configures a JWT ACP handler.
@@ -48,7 +49,7 @@ type Config struct {
 	Claims                     string            `json:"claims,omitempty"`
 }
 
-func (cfg *Config) keySet() (KeySet, error) {
+func (cfg *Config) keySet(transport *http.Transport) (KeySet, error) {
 	if cfg == nil {
 		return nil, nil
 	}
@@ -66,7 +67,7 @@ func (cfg *Config) keySet() (KeySet, error) {
 	}
 
 	if cfg.JWKsURL != "" && !strings.HasPrefix(cfg.JWKsURL, "/") {
-		return NewRemoteKeySet(cfg.JWKsURL), nil
+		return NewRemoteKeySet(cfg.JWKsURL, transport), nil
 	}
 
 	return nil, nil
@@ -87,15 +88,18 @@ type Handler struct {
 	keySet       KeySet
 	dynKeySetsMu sync.RWMutex
 	dynKeySets   map[string]*RemoteKeySet
+	transport    *http.Transport
 
 	stripAuthorization bool
 	fwdHeaders         map[string]string
 
 	validateCustomClaims expr.Predicate
+
+	validations token.Coalescer
 }
 
-// NewHandler returns a new JWT ACP Handler.
-func NewHandler(cfg *Config, polName string) (*Handler, error) {
+// NewHandler returns a new JWT ACP Handler. Remote key sets are fetched through the given transport, which may be nil.
+func NewHandler(cfg *Config, polName string, transport *http.Transport) (*Handler, error) {
 	if cfg.PublicKey == "" && cfg.SigningSecret == "" && cfg.JWKsFile == "" && cfg.JWKsURL == "" {
 		return nil, errors.New("at least a signing secret, public key or a JWKs file or URL is required")
 	}
@@ -139,7 +143,7 @@ func NewHandler(cfg *Config, polName string) (*Handler, error) {
 		tokenQueryKey = cfg.TokenQueryKey
 	}
 
-	ks, err := cfg.keySet()
+	ks, err := cfg.keySet(transport)
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +155,7 @@ func NewHandler(cfg *Config, polName string) (*Handler, error) {
 		jwksURL:              cfg.JWKsURL,
 		keySet:               ks,
 		dynKeySets:           make(map[string]*RemoteKeySet),
+		transport:            transport,
 		stripAuthorization:   cfg.StripAuthorizationHeader,
 		fwdHeaders:           cfg.ForwardHeaders,
 		tokQryKey:            tokenQueryKey,
@@ -162,8 +167,14 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	l := log.With().Str("handler_type", "JWT").Str("handler_name", h.name).Logger()
 
 	extractor := jwtExtractor{tokQryKey: h.tokQryKey}
-	p := &jwt.Parser{UseJSONNumber: true}
-	tok, err := jwtreq.ParseFromRequest(req, extractor, h.keyFunc(req.Context()), jwtreq.WithParser(p))
+	rawTok, err := extractor.ExtractToken(req)
+	if err != nil {
+		l.Error().Err(err).Msg("Unable to parse JWT")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := h.validate(req.Context(), rawTok)
 	if err != nil {
 		var jwtErr *jwt.ValidationError
 		if errors.As(err, &jwtErr) && jwtErr.Errors&jwt.ValidationErrorUnverifiable != 0 {
@@ -177,13 +188,15 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 
 	if h.validateCustomClaims != nil {
-		if !h.validateCustomClaims(tok.Claims.(jwt.MapClaims)) {
+		if !h.validateCustomClaims(claims) {
 			rw.WriteHeader(http.StatusForbidden)
 			return
 		}
 	}
 
-	hdrs, err := expr.PluckClaims(h.fwdHeaders, tok.Claims.(jwt.MapClaims))
+	headers.RecordClaims(req, claims)
+
+	hdrs, err := expr.PluckClaims(h.fwdHeaders, claims)
 	if err != nil {
 		l.Error().Err(err).Msg("Unable to set forwarded header")
 		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -203,6 +216,25 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	rw.WriteHeader(http.StatusOK)
 }
 
+// validate parses the given JWT and verifies its signature. Concurrent validations of the same JWT are coalesced, so
+// signing keys are resolved once for all of them.
+func (h *Handler) validate(ctx context.Context, rawTok string) (jwt.MapClaims, error) {
+	claims, err := h.validations.Do(ctx, rawTok, func(ctx context.Context) (interface{}, error) {
+		p := &jwt.Parser{UseJSONNumber: true}
+		tok, err := p.Parse(rawTok, h.keyFunc(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		return tok.Claims, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claims.(jwt.MapClaims), nil
+}
+
 // keyFunc returns a function to find the correct key to validate its given JWT's signature.
 func (h *Handler) keyFunc(ctx context.Context) jwt.Keyfunc {
 	return func(tok *jwt.Token) (key interface{}, err error) {
@@ -294,7 +326,7 @@ func (h *Handler) remoteKeySet(iss string) (*RemoteKeySet, error) {
 	h.dynKeySetsMu.Lock()
 	rks = h.dynKeySets[ksURL]
 	if rks == nil {
-		rks = NewRemoteKeySet(ksURL)
+		rks = NewRemoteKeySet(ksURL, h.transport)
 		h.dynKeySets[ksURL] = rks
 	}
 	h.dynKeySetsMu.Unlock()
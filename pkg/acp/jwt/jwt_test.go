@@ -91,7 +91,7 @@ func TestNew(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := NewHandler(&test.jwtCfg, "acp@my-ns")
+			_, err := NewHandler(&test.jwtCfg, "acp@my-ns", nil)
 			test.wantErr(t, err)
 		})
 	}
@@ -184,7 +184,7 @@ func TestServeHTTP(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			middleware, err := NewHandler(&test.jwtCfg, "acp@my-ns")
+			middleware, err := NewHandler(&test.jwtCfg, "acp@my-ns", nil)
 			require.NoError(t, err)
 
 			rec := httptest.NewRecorder()
@@ -445,7 +445,7 @@ func Test_JWTAuthNew(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := NewHandler(&test.static, "acp@my-ns")
+			_, err := NewHandler(&test.static, "acp@my-ns", nil)
 			test.wantErr(t, err)
 		})
 	}
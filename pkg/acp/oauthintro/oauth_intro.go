@@ -19,16 +19,19 @@ package oauthintro
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"text/template"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/headers"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/token"
 	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 )
@@ -81,10 +84,13 @@ type Handler struct {
 	tokenSrc             token.Source
 	fwdHeaders           map[string]string
 	validateCustomClaims expr.Predicate
+
+	introspections token.Coalescer
 }
 
-// NewHandler creates a new OAuth 2.0 Token Introspection ACP Handler.
-func NewHandler(cfg *Config, polName string) (*Handler, error) {
+// NewHandler creates a new OAuth 2.0 Token Introspection ACP Handler. Introspection requests are sent through the given
+// transport, which may be nil.
+func NewHandler(cfg *Config, polName string, transport *http.Transport) (*Handler, error) {
 	if cfg.ClientConfig.URL == "" {
 		return nil, errors.New("empty URL")
 	}
@@ -105,7 +111,7 @@ func NewHandler(cfg *Config, polName string) (*Handler, error) {
 		return nil, errors.New(`at least one of "header", "query" or "cookie" must be set`)
 	}
 
-	httpClient, err := httpclient.New(cfg.ClientConfig.Config)
+	httpClient, err := httpclient.NewWithTransport(cfg.ClientConfig.Config, transport)
 	if err != nil {
 		return nil, fmt.Errorf("creating HTTP client: %w", err)
 	}
@@ -169,6 +175,8 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	headers.RecordClaims(req, claims)
+
 	hdrs, err := expr.PluckClaims(h.fwdHeaders, claims)
 	if err != nil {
 		l.Error().Err(err).Msg("Unable to set forwarded header")
@@ -185,33 +193,62 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	rw.WriteHeader(http.StatusOK)
 }
 
+// introspectToken introspects the given token. Concurrent introspections of the same token with the same headers are
+// coalesced into a single request to the Authorization Server.
 func (h *Handler) introspectToken(originalReq *http.Request, tok string) (map[string]interface{}, error) {
-	form := url.Values{"token": []string{tok}}
-	form.Set("token", tok)
-	if h.tokenTypeHint != "" {
-		form.Set("token_type_hint", h.tokenTypeHint)
+	headers, err := h.renderHeaders(originalReq)
+	if err != nil {
+		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, h.url, strings.NewReader(form.Encode()))
+	key := introspectionKey(tok, headers)
+	claims, err := h.introspections.Do(originalReq.Context(), key, func(ctx context.Context) (interface{}, error) {
+		return h.doIntrospect(ctx, tok, headers)
+	})
 	if err != nil {
 		return nil, err
 	}
-	req = req.WithContext(originalReq.Context())
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Accept", "application/json")
+
+	return claims.(map[string]interface{}), nil
+}
+
+func (h *Handler) renderHeaders(originalReq *http.Request) (map[string]string, error) {
+	if len(h.headers.Templates()) == 0 {
+		return nil, nil
+	}
+
 	data := struct {
 		Request *http.Request
 	}{
 		Request: originalReq,
 	}
-	if len(h.headers.Templates()) > 0 {
-		for _, tmpl := range h.headers.Templates() {
-			var buf bytes.Buffer
-			if err = tmpl.Execute(&buf, data); err != nil {
-				return nil, fmt.Errorf("executing template for header %q: %w", tmpl.Name(), err)
-			}
-			req.Header.Set(tmpl.Name(), buf.String())
+
+	headers := make(map[string]string)
+	for _, tmpl := range h.headers.Templates() {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("executing template for header %q: %w", tmpl.Name(), err)
 		}
+		headers[tmpl.Name()] = buf.String()
+	}
+
+	return headers, nil
+}
+
+func (h *Handler) doIntrospect(ctx context.Context, tok string, headers map[string]string) (map[string]interface{}, error) {
+	form := url.Values{"token": []string{tok}}
+	if h.tokenTypeHint != "" {
+		form.Set("token_type_hint", h.tokenTypeHint)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
 	}
 
 	switch h.auth.Kind {
@@ -244,3 +281,23 @@ func (h *Handler) introspectToken(originalReq *http.Request, tok string) (map[st
 
 	return claims, nil
 }
+
+// introspectionKey returns a key identifying an introspection of the given token sent with the given headers.
+func introspectionKey(tok string, headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(tok)
+	for _, name := range names {
+		b.WriteByte(0)
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.WriteString(headers[name])
+	}
+
+	return b.String()
+}
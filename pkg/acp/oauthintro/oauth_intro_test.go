@@ -20,7 +20,10 @@ package oauthintro
 import (
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -126,7 +129,7 @@ func TestNewHandler(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := NewHandler(&test.cfg, "oauth-intro")
+			_, err := NewHandler(&test.cfg, "oauth-intro", nil)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -167,7 +170,7 @@ func TestOAuthIntro_GetsTokenFromHeader(t *testing.T) {
 			HeaderAuthScheme: "Bearer",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -210,7 +213,7 @@ func TestOAuthIntro_GetsTokenFromQuery(t *testing.T) {
 			Query: "tok",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -254,7 +257,7 @@ func TestOAuthIntro_GetsTokenFromCookie(t *testing.T) {
 			Cookie: "tok",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -288,7 +291,7 @@ func TestOAuthIntro_FailsIfMissingToken(t *testing.T) {
 			HeaderAuthScheme: "Bearer",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -331,7 +334,7 @@ func TestOAuthIntro_AuthenticatesWithHeader(t *testing.T) {
 			Header: "Token",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -377,7 +380,7 @@ func TestOAuthIntro_AuthenticatesWithQuery(t *testing.T) {
 			Header: "Token",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -437,7 +440,7 @@ func TestOAuthIntro_SendsCustomHeaders(t *testing.T) {
 			HeaderAuthScheme: "Bearer",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -482,7 +485,7 @@ func TestOAuthIntro_HandlesTokenInactive(t *testing.T) {
 			HeaderAuthScheme: "Bearer",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -526,7 +529,7 @@ func TestOAuthIntro_HandlesIntrospectionServerError(t *testing.T) {
 			HeaderAuthScheme: "Bearer",
 		},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -573,7 +576,7 @@ func TestOAuthIntro_HandlesWrongClaims(t *testing.T) {
 		},
 		Claims: "Equals(`grp`, `admin`)",
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -618,7 +621,7 @@ func TestOAuthIntro_ForwardsClaimsHeaders(t *testing.T) {
 		},
 		ForwardHeaders: map[string]string{"Group": "grp"},
 	}
-	handler, err := NewHandler(&cfg, "oauth-intro")
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
 	require.NoError(t, err)
 
 	rec := httptest.NewRecorder()
@@ -632,3 +635,64 @@ func TestOAuthIntro_ForwardsClaimsHeaders(t *testing.T) {
 	assert.Equal(t, "test", rec.Header().Get("Group"))
 	assert.Equal(t, 1, callCount)
 }
+
+func TestOAuthIntro_CoalescesConcurrentIntrospections(t *testing.T) {
+	var callCount atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount.Add(1)
+		<-release
+
+		require.NoError(t, r.ParseForm())
+		_, _ = w.Write([]byte(`{"active": true, "tok": "` + r.Form.Get("token") + `"}`))
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		ClientConfig: ClientConfig{
+			URL: srv.URL,
+			Auth: ClientConfigAuth{
+				Kind: "Bearer",
+				Secret: SecretReference{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Key:   "Authorization",
+				Value: "Bearer token",
+			},
+		},
+		TokenSource: token.Source{
+			Header:           "Authorization",
+			HeaderAuthScheme: "Bearer",
+		},
+		ForwardHeaders: map[string]string{"X-Token": "tok"},
+	}
+	handler, err := NewHandler(&cfg, "oauth-intro", nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for _, tok := range []string{"abc", "abc", "abc", "abc", "def", "def"} {
+		tok := tok
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Authorization", "Bearer "+tok)
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tok, rec.Header().Get("X-Token"))
+		}()
+	}
+
+	// Give some time to the requests to join the in-flight introspections.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), callCount.Load())
+}
@@ -41,6 +41,8 @@ type Config struct {
 	// Claims defines an expression to perform validation on the ID token. For example:
 	//     Equals(`grp`, `admin`) && Equals(`scope`, `deploy`)
 	Claims string `json:"claims,omitempty"`
+	// Pages customizes the login, error and logout pages served to the users.
+	Pages *AuthPages `json:"pages,omitempty"`
 }
 
 // ApplyDefaultValues applies default values on the given dynamic configuration.
@@ -148,6 +150,15 @@ type AuthSession struct {
 	Refresh  *bool  `json:"refresh,omitempty"`
 }
 
+// AuthPages carries the customization of the pages served during the login flow.
+type AuthPages struct {
+	// Locale forces the locale of the pages instead of negotiating it with the Accept-Language header.
+	Locale string `json:"locale,omitempty"`
+	// Templates overrides page templates. Keys are a page name (login, error or logout),
+	// optionally suffixed with a locale to only override this locale, e.g. `error.fr`.
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
 // ptrBool returns a pointer to boolean.
 func ptrBool(v bool) *bool {
 	return &v
@@ -23,16 +23,20 @@ import (
 	"time"
 )
 
-func newHTTPClient() *http.Client {
-	return &http.Client{
-		Transport: &http.Transport{
+func newHTTPClient(transport *http.Transport) *http.Client {
+	if transport == nil {
+		transport = &http.Transport{
 			DialContext: (&net.Dialer{
 				Timeout:   30 * time.Second,
 				KeepAlive: 30 * time.Second,
 			}).DialContext,
 			TLSHandshakeTimeout: 10 * time.Second,
 			Proxy:               http.ProxyFromEnvironment,
-		},
-		Timeout: 5 * time.Second,
+		}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   5 * time.Second,
 	}
 }
@@ -39,7 +39,7 @@ func Test_newHTTPClient_WithProxyEnvVars(t *testing.T) {
 
 	t.Setenv("HTTP_PROXY", testProxyServer.URL)
 
-	client := newHTTPClient()
+	client := newHTTPClient(nil)
 
 	resp, err := client.Get("http://foo.bar")
 	require.NoError(t, err)
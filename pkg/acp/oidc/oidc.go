@@ -35,6 +35,7 @@ import (
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/headers"
 	"golang.org/x/oauth2"
 )
 
@@ -114,17 +115,32 @@ type Handler struct {
 	validateClaims expr.Predicate
 
 	client *http.Client
+	pages  *Pages
 
 	cfg *Config
 }
 
-// NewHandler creates a new instance of a Handler from an auth source.
-func NewHandler(ctx context.Context, cfg *Config, name string) (*Handler, error) {
+// NewHandler creates a new instance of a Handler from an auth source. Calls to the OIDC provider are made through the
+// given transport, which may be nil. Pages are rendered from the given pages, or from the embedded ones if nil.
+func NewHandler(ctx context.Context, cfg *Config, name string, transport *http.Transport, pages *Pages) (*Handler, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("validate configuration: %w", err)
 	}
 
-	client := newHTTPClient()
+	var err error
+	if pages == nil {
+		pages, err = NewPages("")
+		if err != nil {
+			return nil, fmt.Errorf("load pages: %w", err)
+		}
+	}
+
+	pages, err = pages.Override(cfg.Pages)
+	if err != nil {
+		return nil, fmt.Errorf("override pages: %w", err)
+	}
+
+	client := newHTTPClient(transport)
 
 	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, client), cfg.Issuer)
 	if err != nil {
@@ -159,6 +175,7 @@ func NewHandler(ctx context.Context, cfg *Config, name string) (*Handler, error)
 		block:          block,
 		validateClaims: pred,
 		client:         client,
+		pages:          pages,
 	}, nil
 }
 
@@ -193,10 +210,23 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// Browsers navigating to the logout URL are shown the logout page. It is returned with an unauthorized status
+	// so the proxy serves it instead of forwarding the request.
+	if equalURL(forwardedURL, logoutURL) && forwardedMethod == http.MethodGet && acceptsHTML(req) {
+		if err := h.session.Delete(rw, req); err != nil {
+			logger.Debug().Err(err).Msg("Unable to delete the session")
+		}
+
+		loginURL := fmt.Sprintf("%s://%s/", req.Header.Get("X-Forwarded-Proto"), req.Header.Get("X-Forwarded-Host"))
+		h.pages.Render(rw, req, PageLogout, PageData{Status: http.StatusUnauthorized, LoginURL: loginURL})
+
+		return
+	}
+
 	sess, err := h.session.Get(req)
 	if err != nil {
 		logger.Debug().Err(err).Msg("Unable to get the session")
-		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		h.pages.Render(rw, req, PageLogin, PageData{Status: http.StatusUnauthorized, LoginURL: forwardedURL})
 
 		return
 	}
@@ -220,7 +250,7 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 		if !h.shouldRedirect(req) {
 			logger.Debug().Msg("Received a request that should not be redirected")
-			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			h.pages.Render(rw, req, PageLogin, PageData{Status: http.StatusUnauthorized, LoginURL: forwardedURL})
 
 			return
 		}
@@ -242,7 +272,7 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 		if !h.shouldRedirect(req) {
 			logger.Debug().Err(err).Msg("Received a request that should not be redirected")
-			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			h.pages.Render(rw, req, PageLogin, PageData{Status: http.StatusUnauthorized, LoginURL: forwardedURL})
 
 			return
 		}
@@ -259,7 +289,7 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if refreshSession && h.shouldRedirect(req) {
 		if err = h.session.Update(rw, req, *sess); err != nil {
 			logger.Debug().Err(err).Msg("Unable to refresh the session")
-			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			h.pages.Error(rw, req, http.StatusInternalServerError)
 
 			return
 		}
@@ -281,7 +311,7 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	idToken, err = h.verifier.Verify(req.Context(), sess.IDToken)
 	if err != nil {
 		logger.Debug().Err(err).Msg("Invalid ID token")
-		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		h.pages.Error(rw, req, http.StatusBadRequest)
 
 		return
 	}
@@ -289,21 +319,23 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	claims := make(map[string]interface{})
 	if err = idToken.Claims(&claims); err != nil {
 		logger.Debug().Err(err).Msg("Unable to unmarshal claims")
-		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.pages.Error(rw, req, http.StatusInternalServerError)
 
 		return
 	}
 
 	if h.validateClaims != nil && !h.validateClaims(claims) {
 		logger.Debug().Err(err).Msg("Unauthorized claim")
-		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		h.pages.Error(rw, req, http.StatusForbidden)
 
 		return
 	}
 
+	headers.RecordClaims(req, claims)
+
 	if err = h.forwardHeader(rw, claims); err != nil {
 		logger.Error().Err(err).Msg("Unable to set forwarded header")
-		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.pages.Error(rw, req, http.StatusInternalServerError)
 
 		return
 	}
@@ -375,7 +407,7 @@ func (h *Handler) redirectToProvider(rw http.ResponseWriter, req *http.Request,
 	stateCookie, err := h.newStateCookie(state)
 	if err != nil {
 		logger.Debug().Err(err).Msg("Unable to create state cookie")
-		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.pages.Error(rw, req, http.StatusInternalServerError)
 
 		return
 	}
@@ -414,15 +446,10 @@ func (h *Handler) redirectToProvider(rw http.ResponseWriter, req *http.Request,
 		return
 	}
 
-	http.Redirect(
-		rw,
-		req,
-		h.oauth.AuthCodeURL(
-			state.RedirectID,
-			opts...,
-		),
-		http.StatusFound,
-	)
+	authURL := h.oauth.AuthCodeURL(state.RedirectID, opts...)
+
+	rw.Header().Set("Location", authURL)
+	h.pages.Render(rw, req, PageLogin, PageData{Status: http.StatusFound, LoginURL: authURL})
 }
 
 func (h *Handler) handleProviderCallback(rw http.ResponseWriter, req *http.Request, redirectURL string) {
@@ -431,7 +458,7 @@ func (h *Handler) handleProviderCallback(rw http.ResponseWriter, req *http.Reque
 	state, err := h.getStateCookie(req)
 	if err != nil {
 		logger.Debug().Err(err).Msg("Malformed state payload")
-		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		h.pages.Error(rw, req, http.StatusBadRequest)
 		return
 	}
 
@@ -442,7 +469,7 @@ func (h *Handler) handleProviderCallback(rw http.ResponseWriter, req *http.Reque
 
 	if state == nil || u.Query().Get("state") != state.RedirectID {
 		logger.Debug().Err(err).Msg("Mismatched request ID or empty state")
-		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		h.pages.Error(rw, req, http.StatusBadRequest)
 		return
 	}
 
@@ -459,7 +486,7 @@ func (h *Handler) handleProviderCallback(rw http.ResponseWriter, req *http.Reque
 	)
 	if err != nil {
 		logger.Debug().Err(err).Msg("Unable to exchange code")
-		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.pages.Error(rw, req, http.StatusInternalServerError)
 		return
 	}
 
@@ -468,7 +495,7 @@ func (h *Handler) handleProviderCallback(rw http.ResponseWriter, req *http.Reque
 	rawIDToken, ok := oauth2Token.Extra("id_token").(string)
 	if !ok {
 		logger.Debug().Err(err).Msg("ID token invalid or not found")
-		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.pages.Error(rw, req, http.StatusInternalServerError)
 		return
 	}
 
@@ -476,14 +503,14 @@ func (h *Handler) handleProviderCallback(rw http.ResponseWriter, req *http.Reque
 	idToken, err := h.verifier.Verify(req.Context(), rawIDToken)
 	if err != nil {
 		logger.Debug().Err(err).Msg("Invalid ID token")
-		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		h.pages.Error(rw, req, http.StatusBadRequest)
 		return
 	}
 
 	// Nonce validation.
 	if idToken.Nonce != state.Nonce {
 		logger.Debug().Err(err).Msg("Invalid Nonce")
-		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		h.pages.Error(rw, req, http.StatusBadRequest)
 		return
 	}
 
@@ -497,7 +524,7 @@ func (h *Handler) handleProviderCallback(rw http.ResponseWriter, req *http.Reque
 	}
 	if err = h.session.Create(rw, *sess); err != nil {
 		logger.Debug().Err(err).Msg("Unable to create session")
-		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		h.pages.Error(rw, req, http.StatusInternalServerError)
 		return
 	}
 	h.clearStateCookie(rw)
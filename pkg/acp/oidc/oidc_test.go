@@ -68,7 +68,7 @@ func TestNewMiddlewareFromSource_ValidatesConfiguration(t *testing.T) {
 		test := test
 		t.Run(test.desc, func(t *testing.T) {
 			test.cfg.ApplyDefaultValues()
-			_, err := NewHandler(context.Background(), test.cfg, test.desc)
+			_, err := NewHandler(context.Background(), test.cfg, test.desc, nil, nil)
 
 			if test.wantErr != "" {
 				assert.Error(t, err)
@@ -510,14 +510,31 @@ func TestMiddleware_LogsOutCorrectly(t *testing.T) {
 	tests := []struct {
 		desc      string
 		logoutURL string
+		method    string
+		accept    string
+
+		wantStatus int
+		wantBody   string
 	}{
 		{
-			desc:      "logout URL is a path",
-			logoutURL: "/logout",
+			desc:       "logout URL is a path",
+			logoutURL:  "/logout",
+			method:     http.MethodDelete,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			desc:       "logout URL is a host and path",
+			logoutURL:  "example.com/logout",
+			method:     http.MethodDelete,
+			wantStatus: http.StatusNoContent,
 		},
 		{
-			desc:      "logout URL is a host and path",
-			logoutURL: "example.com/logout",
+			desc:       "browser navigating to the logout URL",
+			logoutURL:  "/logout",
+			method:     http.MethodGet,
+			accept:     "text/html",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `<a href="http://example.com/">Sign in again</a>`,
 		},
 	}
 
@@ -536,11 +553,16 @@ func TestMiddleware_LogsOutCorrectly(t *testing.T) {
 				LogoutURL:    test.logoutURL,
 			}
 
+			pages, err := NewPages("")
+			require.NoError(t, err)
+
 			handler := buildHandler(t)
 			handler.cfg = &cfg
 			handler.session = session
+			handler.pages = pages
 
-			r := httptest.NewRequest(http.MethodDelete, "https://example.com/logout", nil)
+			r := httptest.NewRequest(test.method, "https://example.com/logout", nil)
+			r.Header.Add("Accept", test.accept)
 			r.Header.Add("X-Forwarded-Method", r.Method)
 			r.Header.Add("X-Forwarded-Proto", "http")
 			r.Header.Add("X-Forwarded-Host", r.Host)
@@ -549,7 +571,8 @@ func TestMiddleware_LogsOutCorrectly(t *testing.T) {
 			w := httptest.NewRecorder()
 			handler.ServeHTTP(w, r)
 
-			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, test.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), test.wantBody)
 		})
 	}
 }
@@ -572,7 +595,7 @@ func buildHandler(t *testing.T) *Handler {
 	stateBlock, err := aes.NewCipher([]byte("secret1234567890"))
 	require.NoError(t, err)
 
-	client := newHTTPClient()
+	client := newHTTPClient(nil)
 	require.NoError(t, err)
 
 	return &Handler{
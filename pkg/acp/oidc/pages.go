@@ -0,0 +1,287 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package oidc
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/text/language"
+)
+
+// Names of the pages served during the login flow.
+const (
+	PageLogin  = "login"
+	PageError  = "error"
+	PageLogout = "logout"
+)
+
+// defaultLocale is the locale used when none of the locales accepted by the client is available.
+const defaultLocale = "en"
+
+//go:embed pages
+var embeddedPages embed.FS
+
+// PageData is the data given to the page templates.
+type PageData struct {
+	// Lang is the locale the page is rendered in.
+	Lang string
+	// Status is the HTTP status code of the response.
+	Status int
+	// LoginURL is the URL to follow to sign in. It is only set on the login and logout pages.
+	LoginURL string
+}
+
+// Pages renders the localized login, error and logout pages served by OIDC handlers.
+// Pages are selected based on the Accept-Language header of the request, unless a locale is forced.
+type Pages struct {
+	// templates holds the page templates indexed by locale and page name.
+	templates map[string]map[string]*template.Template
+	locales   []string
+	matcher   language.Matcher
+
+	locale string
+}
+
+// NewPages creates Pages from the embedded templates, overridden by the templates found in the given directory.
+// The directory, which may be empty, must follow the `<locale>/<page>.html` layout.
+func NewPages(dir string) (*Pages, error) {
+	p := &Pages{templates: make(map[string]map[string]*template.Template)}
+
+	sub, err := fs.Sub(embeddedPages, "pages")
+	if err != nil {
+		return nil, fmt.Errorf("open embedded pages: %w", err)
+	}
+
+	if err = p.load(sub); err != nil {
+		return nil, fmt.Errorf("load embedded pages: %w", err)
+	}
+
+	if dir != "" {
+		if err = p.load(os.DirFS(dir)); err != nil {
+			return nil, fmt.Errorf("load pages from %q: %w", dir, err)
+		}
+	}
+
+	p.index()
+
+	return p, nil
+}
+
+// Override returns a copy of the pages with the given per-ACP configuration applied.
+func (p *Pages) Override(cfg *AuthPages) (*Pages, error) {
+	if cfg == nil {
+		return p, nil
+	}
+
+	o := &Pages{
+		templates: make(map[string]map[string]*template.Template, len(p.templates)),
+		locale:    p.locale,
+	}
+	for locale, pages := range p.templates {
+		o.templates[locale] = copyTemplates(pages)
+	}
+
+	keys := make([]string, 0, len(cfg.Templates))
+	for key := range cfg.Templates {
+		keys = append(keys, key)
+	}
+	// Templates targeting every locale are applied first, so locale specific ones take precedence.
+	sort.Slice(keys, func(i, j int) bool {
+		if hasLocale(keys[i]) != hasLocale(keys[j]) {
+			return !hasLocale(keys[i])
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		page, locale, _ := strings.Cut(key, ".")
+
+		locales := []string{locale}
+		if locale == "" {
+			locales = make([]string, 0, len(o.templates))
+			for l := range o.templates {
+				locales = append(locales, l)
+			}
+		}
+
+		for _, l := range locales {
+			if err := o.add(l, page, cfg.Templates[key]); err != nil {
+				return nil, fmt.Errorf("template %q: %w", key, err)
+			}
+		}
+	}
+
+	if cfg.Locale != "" {
+		tag, err := language.Parse(cfg.Locale)
+		if err != nil {
+			return nil, fmt.Errorf("parse locale %q: %w", cfg.Locale, err)
+		}
+
+		o.locale = tag.String()
+		if _, ok := o.templates[o.locale]; !ok {
+			return nil, fmt.Errorf("locale %q has no pages", cfg.Locale)
+		}
+	}
+
+	o.index()
+
+	return o, nil
+}
+
+// Render writes the given page in the locale negotiated with the request. Clients not accepting HTML
+// get a plain text response.
+func (p *Pages) Render(rw http.ResponseWriter, req *http.Request, page string, data PageData) {
+	if p == nil || !acceptsHTML(req) {
+		http.Error(rw, http.StatusText(data.Status), data.Status)
+		return
+	}
+
+	data.Lang = p.negotiate(req)
+
+	var buf bytes.Buffer
+	if err := p.templates[data.Lang][page].Execute(&buf, data); err != nil {
+		log.Error().Err(err).Str("page", page).Str("locale", data.Lang).Msg("Unable to render page")
+		http.Error(rw, http.StatusText(data.Status), data.Status)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Content-Language", data.Lang)
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(data.Status)
+
+	_, _ = rw.Write(buf.Bytes())
+}
+
+// Error writes the error page for the given status.
+func (p *Pages) Error(rw http.ResponseWriter, req *http.Request, status int) {
+	p.Render(rw, req, PageError, PageData{Status: status})
+}
+
+func (p *Pages) negotiate(req *http.Request) string {
+	if p.locale != "" {
+		return p.locale
+	}
+
+	prefs, _, err := language.ParseAcceptLanguage(req.Header.Get("Accept-Language"))
+	if err != nil || len(prefs) == 0 {
+		return defaultLocale
+	}
+
+	_, idx, confidence := p.matcher.Match(prefs...)
+	if confidence == language.No {
+		return defaultLocale
+	}
+
+	return p.locales[idx]
+}
+
+func (p *Pages) load(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*/*.html")
+	if err != nil {
+		return fmt.Errorf("list templates: %w", err)
+	}
+
+	for _, file := range files {
+		content, readErr := fs.ReadFile(fsys, file)
+		if readErr != nil {
+			return fmt.Errorf("read %q: %w", file, readErr)
+		}
+
+		locale, name := path.Split(file)
+		if err = p.add(strings.TrimSuffix(locale, "/"), strings.TrimSuffix(name, ".html"), string(content)); err != nil {
+			return fmt.Errorf("template %q: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// add parses and registers the template of a page for the given locale. A locale having no template yet starts
+// from the templates of the default locale, so every locale can render every page.
+func (p *Pages) add(locale, page, content string) error {
+	switch page {
+	case PageLogin, PageError, PageLogout:
+	default:
+		return fmt.Errorf("unknown page %q", page)
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return fmt.Errorf("parse locale %q: %w", locale, err)
+	}
+	locale = tag.String()
+
+	tmpl, err := template.New(page).Parse(content)
+	if err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+
+	if _, ok := p.templates[locale]; !ok {
+		p.templates[locale] = copyTemplates(p.templates[defaultLocale])
+	}
+	p.templates[locale][page] = tmpl
+
+	return nil
+}
+
+// index builds the language matcher from the available locales, the default locale being the first one.
+func (p *Pages) index() {
+	p.locales = []string{defaultLocale}
+	for locale := range p.templates {
+		if locale != defaultLocale {
+			p.locales = append(p.locales, locale)
+		}
+	}
+	sort.Strings(p.locales[1:])
+
+	tags := make([]language.Tag, 0, len(p.locales))
+	for _, locale := range p.locales {
+		tags = append(tags, language.Make(locale))
+	}
+
+	p.matcher = language.NewMatcher(tags)
+}
+
+func copyTemplates(src map[string]*template.Template) map[string]*template.Template {
+	dst := make(map[string]*template.Template, len(src))
+	for page, tmpl := range src {
+		dst[page] = tmpl
+	}
+
+	return dst
+}
+
+func hasLocale(key string) bool {
+	return strings.Contains(key, ".")
+}
+
+func acceptsHTML(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), "text/html")
+}
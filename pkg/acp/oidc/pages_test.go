@@ -0,0 +1,236 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package oidc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPages_Render(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "it"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "it", "error.html"), []byte(`errore {{ .Status }}`), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fr"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr", "login.html"), []byte(`connexion {{ .LoginURL }}`), 0o600))
+
+	pages, err := NewPages(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc           string
+		cfg            *AuthPages
+		accept         string
+		acceptLanguage string
+		page           string
+		data           PageData
+
+		wantContentType string
+		wantLang        string
+		wantBody        string
+		wantContains    string
+	}{
+		{
+			desc:            "plain text for non HTML clients",
+			accept:          "application/json",
+			acceptLanguage:  "fr",
+			page:            PageError,
+			data:            PageData{Status: http.StatusForbidden},
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Forbidden\n",
+		},
+		{
+			desc:            "default locale without Accept-Language",
+			accept:          "text/html",
+			page:            PageError,
+			data:            PageData{Status: http.StatusForbidden},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "en",
+			wantContains:    "You do not have permission to access this page.",
+		},
+		{
+			desc:            "default locale for unknown language",
+			accept:          "text/html",
+			acceptLanguage:  "ja",
+			page:            PageError,
+			data:            PageData{Status: http.StatusForbidden},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "en",
+			wantContains:    "You do not have permission to access this page.",
+		},
+		{
+			desc:            "negotiated embedded locale",
+			accept:          "text/html,application/xhtml+xml",
+			acceptLanguage:  "de-CH, de;q=0.9, en;q=0.8",
+			page:            PageLogout,
+			data:            PageData{Status: http.StatusUnauthorized},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "de",
+			wantContains:    "Sie wurden erfolgreich abgemeldet.",
+		},
+		{
+			desc:            "mounted template overrides an embedded one",
+			accept:          "text/html",
+			acceptLanguage:  "fr-FR",
+			page:            PageLogin,
+			data:            PageData{Status: http.StatusUnauthorized, LoginURL: "https://example.com/?a=b&c=d"},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "fr",
+			wantBody:        "connexion https://example.com/?a=b&amp;c=d",
+		},
+		{
+			desc:            "mounted locale falls back to the default locale for missing pages",
+			accept:          "text/html",
+			acceptLanguage:  "it",
+			page:            PageLogout,
+			data:            PageData{Status: http.StatusUnauthorized},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "it",
+			wantContains:    "You have been signed out successfully.",
+		},
+		{
+			desc:            "mounted locale",
+			accept:          "text/html",
+			acceptLanguage:  "it",
+			page:            PageError,
+			data:            PageData{Status: http.StatusBadRequest},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "it",
+			wantBody:        "errore 400",
+		},
+		{
+			desc:            "ACP forced locale",
+			cfg:             &AuthPages{Locale: "es"},
+			accept:          "text/html",
+			acceptLanguage:  "de",
+			page:            PageLogout,
+			data:            PageData{Status: http.StatusUnauthorized},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "es",
+			wantContains:    "Su sesión se ha cerrado correctamente.",
+		},
+		{
+			desc: "ACP template overrides every locale",
+			cfg: &AuthPages{Templates: map[string]string{
+				"error":    "custom {{ .Lang }}",
+				"error.de": "angepasst",
+			}},
+			accept:          "text/html",
+			acceptLanguage:  "fr",
+			page:            PageError,
+			data:            PageData{Status: http.StatusBadRequest},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "fr",
+			wantBody:        "custom fr",
+		},
+		{
+			desc: "ACP locale template takes precedence",
+			cfg: &AuthPages{Templates: map[string]string{
+				"error":    "custom {{ .Lang }}",
+				"error.de": "angepasst",
+			}},
+			accept:          "text/html",
+			acceptLanguage:  "de",
+			page:            PageError,
+			data:            PageData{Status: http.StatusBadRequest},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "de",
+			wantBody:        "angepasst",
+		},
+		{
+			desc:            "ACP template adds a locale",
+			cfg:             &AuthPages{Templates: map[string]string{"login.nl": "inloggen"}},
+			accept:          "text/html",
+			acceptLanguage:  "nl-BE",
+			page:            PageLogin,
+			data:            PageData{Status: http.StatusUnauthorized},
+			wantContentType: "text/html; charset=utf-8",
+			wantLang:        "nl",
+			wantBody:        "inloggen",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := pages.Override(test.cfg)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", test.accept)
+			req.Header.Set("Accept-Language", test.acceptLanguage)
+
+			rw := httptest.NewRecorder()
+			p.Render(rw, req, test.page, test.data)
+
+			assert.Equal(t, test.data.Status, rw.Code)
+			assert.Equal(t, test.wantContentType, rw.Header().Get("Content-Type"))
+			assert.Equal(t, test.wantLang, rw.Header().Get("Content-Language"))
+
+			if test.wantBody != "" {
+				assert.Equal(t, test.wantBody, rw.Body.String())
+			}
+			assert.Contains(t, rw.Body.String(), test.wantContains)
+		})
+	}
+}
+
+func TestPages_Override_invalid(t *testing.T) {
+	pages, err := NewPages("")
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc string
+		cfg  *AuthPages
+	}{
+		{
+			desc: "unknown page",
+			cfg:  &AuthPages{Templates: map[string]string{"consent": "hello"}},
+		},
+		{
+			desc: "invalid locale",
+			cfg:  &AuthPages{Templates: map[string]string{"error.english-language": "hello"}},
+		},
+		{
+			desc: "invalid template",
+			cfg:  &AuthPages{Templates: map[string]string{"error": "{{ .Status "}},
+		},
+		{
+			desc: "locale without pages",
+			cfg:  &AuthPages{Locale: "ja"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := pages.Override(test.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
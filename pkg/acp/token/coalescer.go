@@ -0,0 +1,48 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package token
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Coalescer coalesces concurrent validations of identical tokens: the validation is performed once and its result is
+// shared with every caller. During traffic spikes, many forward-auth requests carry the same token and this avoids
+// sending as many requests to identity providers.
+// The zero value is ready to use.
+type Coalescer struct {
+	group singleflight.Group
+}
+
+// Do calls validate and returns its result, unless a validation with the same key is already in flight in which case it
+// waits for it and returns its result. The key must identify every input of the validation, the token included.
+// As its result may be shared, the validation doesn't run with the caller context and must bound its own duration,
+// however Do returns as soon as ctx is done.
+func (c *Coalescer) Do(ctx context.Context, key string, validate func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		return validate(context.Background())
+	})
+
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
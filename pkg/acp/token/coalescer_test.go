@@ -0,0 +1,130 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package token
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoalescer_Do(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c       Coalescer
+		calls   atomic.Int32
+		release = make(chan struct{})
+	)
+
+	validate := func(_ context.Context) (interface{}, error) {
+		calls.Add(1)
+		<-release
+
+		return "claims", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 10)
+	for i := range results {
+		i := i
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			res, err := c.Do(context.Background(), "token", validate)
+			assert.NoError(t, err)
+
+			results[i] = res
+		}()
+	}
+
+	// Give some time to the goroutines to join the in-flight validation.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, res := range results {
+		assert.Equal(t, "claims", res)
+	}
+
+	// Once done, a new validation is performed.
+	res, err := c.Do(context.Background(), "token", func(_ context.Context) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	assert.Nil(t, res)
+	assert.EqualError(t, err, "boom")
+}
+
+func TestCoalescer_Do_distinctKeys(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c     Coalescer
+		calls atomic.Int32
+	)
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"token-1", "token-2", "token-3"} {
+		key := key
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			res, err := c.Do(context.Background(), key, func(_ context.Context) (interface{}, error) {
+				calls.Add(1)
+				return key, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, key, res)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCoalescer_Do_callerCanceled(t *testing.T) {
+	t.Parallel()
+
+	var c Coalescer
+
+	release := make(chan struct{})
+	defer close(release)
+
+	validate := func(ctx context.Context) (interface{}, error) {
+		<-release
+
+		// The validation must outlive the caller that triggered it.
+		return "claims", ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := c.Do(ctx, "token", validate)
+	require.ErrorIs(t, err, context.Canceled)
+}
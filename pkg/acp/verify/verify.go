@@ -0,0 +1,398 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package verify reviews Ingress resources referencing Access Control Policies offline, the way the ACP admission
+// webhook would, so changes can be validated before being applied to a cluster.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/ingclass"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	traefikscheme "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/scheme"
+	admv1 "k8s.io/api/admission/v1"
+	netv1 "k8s.io/api/networking/v1"
+	netv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// Config configures the offline review.
+type Config struct {
+	// AuthServerAddr is the address the auth server can be reached on.
+	AuthServerAddr string
+	// ExtAuthzPort is the port of the Envoy external authorization service of the auth server.
+	ExtAuthzPort int
+	// IstioRootNamespace is the namespace in which EnvoyFilters are created.
+	IstioRootNamespace string
+}
+
+// Manifest is a resource read from a manifest file.
+type Manifest struct {
+	Source string
+	Object *unstructured.Unstructured
+}
+
+// Result is the outcome of the review of a resource referencing an Access Control Policy.
+type Result struct {
+	Source    string `json:"source"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Diff is the unified diff of the changes the admission webhook would make to the resource.
+	Diff     string   `json:"diff,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Resource is a resource the admission webhook would create or update to enforce Access Control Policies, such as
+// Traefik middlewares or Kong plugins.
+type Resource struct {
+	Verb   string                     `json:"verb"`
+	Object *unstructured.Unstructured `json:"object"`
+}
+
+// Report is the report of an offline review.
+type Report struct {
+	Results   []Result   `json:"results"`
+	Resources []Resource `json:"resources"`
+}
+
+// Failed returns whether the review of a resource failed.
+func (r Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Error != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	envoyFilterResource      = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "envoyfilters"}
+	kongPluginResource       = schema.GroupVersionResource{Group: "configuration.konghq.com", Version: "v1", Resource: "kongplugins"}
+	extensionServiceResource = schema.GroupVersionResource{Group: "projectcontour.io", Version: "v1alpha1", Resource: "extensionservices"}
+)
+
+// LoadDir reads the manifests of the YAML and JSON files of the given directory and its sub-directories.
+func LoadDir(dir string) ([]Manifest, error) {
+	var manifests []Manifest
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		objects, err := loadFile(path)
+		if err != nil {
+			return fmt.Errorf("load %q: %w", path, err)
+		}
+
+		for _, object := range objects {
+			manifests = append(manifests, Manifest{Source: path, Object: object})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifests, nil
+}
+
+func loadFile(path string) ([]*unstructured.Unstructured, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*unstructured.Unstructured
+
+	decoder := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(raw), 4096)
+	for {
+		var doc map[string]interface{}
+		if err = decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+
+		// Skip empty documents, such as the ones made of comments only.
+		if len(doc) == 0 {
+			continue
+		}
+
+		object := &unstructured.Unstructured{Object: doc}
+		if object.GetKind() == "" || object.GetAPIVersion() == "" {
+			return nil, errors.New("manifest without apiVersion or kind")
+		}
+
+		objects = append(objects, object)
+	}
+}
+
+// Run reviews the resources of the given manifests referencing an Access Control Policy. AccessControlPolicies and
+// IngressClasses of the manifests are the only resources considered to exist.
+func Run(ctx context.Context, manifests []Manifest, cfg Config) (*Report, error) {
+	ingClasses := ingclass.NewWatcher()
+
+	var policies []runtime.Object
+	var reviewed []Manifest
+	for _, manifest := range manifests {
+		typed, err := toTyped(manifest.Object)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s %q of %q: %w", manifest.Object.GetKind(), manifest.Object.GetName(), manifest.Source, err)
+		}
+
+		switch obj := typed.(type) {
+		case *hubv1alpha1.AccessControlPolicy:
+			policies = append(policies, obj)
+		case *netv1.IngressClass, *netv1beta1.IngressClass, *hubv1alpha1.IngressClass:
+			ingClasses.OnAdd(obj)
+		default:
+			if manifest.Object.GetAnnotations()[reviewer.AnnotationHubAuth] != "" {
+				reviewed = append(reviewed, manifest)
+			}
+		}
+	}
+
+	hubClientSet := hubcrdfake.NewSimpleClientset(policies...)
+	traefikClientSet := traefikcrdfake.NewSimpleClientset()
+	dynamicClient := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		envoyFilterResource:      "EnvoyFilterList",
+		kongPluginResource:       "KongPluginList",
+		extensionServiceResource: "ExtensionServiceList",
+	})
+
+	hubInformer := hubinformers.NewSharedInformerFactory(hubClientSet, 0)
+	hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer()
+	hubInformer.Start(ctx.Done())
+	for typ, ok := range hubInformer.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return nil, fmt.Errorf("wait for %s cache sync", typ)
+		}
+	}
+
+	handler, err := newHandler(cfg, hubInformer, ingClasses, traefikClientSet, dynamicClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var report Report
+	for _, manifest := range reviewed {
+		report.Results = append(report.Results, review(ctx, handler, hubClientSet, manifest))
+	}
+
+	traefikResources, err := createdResources(traefikClientSet.Actions())
+	if err != nil {
+		return nil, err
+	}
+	dynamicResources, err := createdResources(dynamicClient.Actions())
+	if err != nil {
+		return nil, err
+	}
+	report.Resources = append(traefikResources, dynamicResources...)
+
+	return &report, nil
+}
+
+func newHandler(cfg Config, hubInformer hubinformers.SharedInformerFactory, ingClasses reviewer.IngressClasses, traefikClientSet *traefikcrdfake.Clientset, dynamicClient *dynfake.FakeDynamicClient) (*admission.Handler, error) {
+	polGetter := reviewer.NewPolGetter(hubInformer)
+
+	fwdAuthMdlwrs := reviewer.NewFwdAuthMiddlewares(cfg.AuthServerAddr, polGetter, traefikClientSet.TraefikV1alpha1())
+	kongPlugins := reviewer.NewKongPlugins(cfg.AuthServerAddr, polGetter, dynamicClient)
+
+	contourExtSvc, err := reviewer.NewContourExtensionService(cfg.AuthServerAddr, cfg.ExtAuthzPort, dynamicClient)
+	if err != nil {
+		return nil, fmt.Errorf("create Contour ExtensionService: %w", err)
+	}
+
+	istioEnvoyFilters, err := reviewer.NewIstioEnvoyFilters(cfg.AuthServerAddr, cfg.ExtAuthzPort, cfg.IstioRootNamespace, dynamicClient)
+	if err != nil {
+		return nil, fmt.Errorf("create Istio EnvoyFilters: %w", err)
+	}
+
+	traefikReviewer := reviewer.NewTraefikIngress(ingClasses, fwdAuthMdlwrs)
+	reviewers := []admission.Reviewer{
+		reviewer.NewNginxIngress(cfg.AuthServerAddr, ingClasses, polGetter),
+		reviewer.NewKongIngress(ingClasses, kongPlugins),
+		reviewer.NewTraefikIngressRoute(fwdAuthMdlwrs),
+		reviewer.NewGatewayHTTPRoute(fwdAuthMdlwrs),
+		reviewer.NewContourHTTPProxy(contourExtSvc),
+		reviewer.NewIstioVirtualService(istioEnvoyFilters, false),
+		traefikReviewer,
+	}
+
+	return admission.NewHandler(reviewers, traefikReviewer, false), nil
+}
+
+func review(ctx context.Context, handler *admission.Handler, hubClientSet *hubcrdfake.Clientset, manifest Manifest) Result {
+	obj := manifest.Object
+
+	// Resources without namespace are applied in the default namespace.
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(metav1.NamespaceDefault)
+	}
+
+	result := Result{
+		Source:    manifest.Source,
+		Kind:      obj.GetKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+
+	for _, polName := range reviewer.PolicyNames(obj.GetAnnotations()[reviewer.AnnotationHubAuth]) {
+		_, err := hubClientSet.HubV1alpha1().AccessControlPolicies().Get(ctx, polName, metav1.GetOptions{})
+		if err != nil {
+			// The webhook accepts references to missing policies, but the resource is unreachable until it exists.
+			result.Warnings = append(result.Warnings, fmt.Sprintf("AccessControlPolicy %q is not defined in the manifests", polName))
+		}
+	}
+
+	raw, err := obj.MarshalJSON()
+	if err != nil {
+		result.Error = fmt.Sprintf("marshal resource: %v", err)
+		return result
+	}
+
+	gvk := obj.GroupVersionKind()
+	ar := admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			UID:       ktypes.UID(manifest.Source + "/" + obj.GetNamespace() + "/" + obj.GetName()),
+			Kind:      metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Operation: admv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+
+	patch, warnings, err := handler.Review(ctx, ar)
+	result.Warnings = append(result.Warnings, warnings...)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if patch == nil {
+		return result
+	}
+
+	result.Diff, err = admission.PatchDiff(raw, patch)
+	if err != nil {
+		result.Error = fmt.Sprintf("compute patch diff: %v", err)
+	}
+
+	return result
+}
+
+// toTyped converts the AccessControlPolicies and IngressClasses to their typed counterparts. Other resources are
+// returned as is.
+func toTyped(obj *unstructured.Unstructured) (runtime.Object, error) {
+	var typed runtime.Object
+	switch obj.GroupVersionKind() {
+	case hubv1alpha1.SchemeGroupVersion.WithKind("AccessControlPolicy"):
+		typed = &hubv1alpha1.AccessControlPolicy{}
+	case hubv1alpha1.SchemeGroupVersion.WithKind("IngressClass"):
+		typed = &hubv1alpha1.IngressClass{}
+	case netv1.SchemeGroupVersion.WithKind("IngressClass"):
+		typed = &netv1.IngressClass{}
+	case netv1beta1.SchemeGroupVersion.WithKind("IngressClass"):
+		typed = &netv1beta1.IngressClass{}
+	default:
+		return obj, nil
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return nil, err
+	}
+
+	// IngressClasses are indexed by UID, which manifests don't have.
+	if accessor, ok := typed.(metav1.Object); ok && accessor.GetUID() == "" {
+		accessor.SetUID(ktypes.UID(obj.GetAPIVersion() + "/" + obj.GetKind() + "/" + obj.GetName()))
+	}
+
+	return typed, nil
+}
+
+// createdResources returns the resources created or updated by the given client actions.
+func createdResources(actions []ktesting.Action) ([]Resource, error) {
+	var resources []Resource
+	for _, action := range actions {
+		var obj runtime.Object
+		switch a := action.(type) {
+		case ktesting.CreateAction:
+			obj = a.GetObject()
+		case ktesting.UpdateAction:
+			obj = a.GetObject()
+		default:
+			continue
+		}
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("convert %s: %w", action.GetResource().Resource, err)
+		}
+
+		object := &unstructured.Unstructured{Object: content}
+		unstructured.RemoveNestedField(object.Object, "metadata", "creationTimestamp")
+
+		// Typed objects created by reviewers don't have their type set.
+		if object.GetKind() == "" {
+			gvks, _, err := traefikscheme.Scheme.ObjectKinds(obj)
+			if err != nil {
+				return nil, fmt.Errorf("get kind of %s: %w", action.GetResource().Resource, err)
+			}
+			object.SetGroupVersionKind(gvks[0])
+		}
+
+		resources = append(resources, Resource{Verb: action.GetVerb(), Object: object})
+	}
+
+	return resources, nil
+}
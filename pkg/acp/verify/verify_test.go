@@ -0,0 +1,126 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRun(t *testing.T) {
+	manifests, err := LoadDir("testdata/manifests")
+	require.NoError(t, err)
+	require.Len(t, manifests, 7)
+
+	report, err := Run(context.Background(), manifests, Config{
+		AuthServerAddr:     "http://auth-server.hub.svc.cluster.local",
+		ExtAuthzPort:       9000,
+		IstioRootNamespace: "istio-system",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, report.Failed())
+
+	wantResults := []Result{
+		{
+			Source:    "testdata/manifests/apps/missing.json",
+			Kind:      "Ingress",
+			Namespace: "default",
+			Name:      "missing",
+			Warnings:  []string{`AccessControlPolicy "unknown-acp" is not defined in the manifests`},
+			Error:     `find reviewer: get ingress class controller from ingress class name: IngressClass "unknown" not found`,
+		},
+		{
+			Source:    "testdata/manifests/apps/whoami.yaml",
+			Kind:      "Ingress",
+			Namespace: "apps",
+			Name:      "whoami",
+			Diff: `--- current
++++ patched
+@@ -3,7 +3,8 @@
+   "kind": "Ingress",
+   "metadata": {
+     "annotations": {
+-      "hub.traefik.io/access-control-policy": "my-acp"
++      "hub.traefik.io/access-control-policy": "my-acp",
++      "traefik.ingress.kubernetes.io/router.middlewares": "apps-zz-my-acp@kubernetescrd"
+     },
+     "name": "whoami",
+     "namespace": "apps"
+`,
+		},
+		{
+			Source:    "testdata/manifests/apps/whoami.yaml",
+			Kind:      "Ingress",
+			Namespace: "apps",
+			Name:      "whoami-nginx",
+			Diff: `--- current
++++ patched
+@@ -3,7 +3,9 @@
+   "kind": "Ingress",
+   "metadata": {
+     "annotations": {
+-      "hub.traefik.io/access-control-policy": "my-acp"
++      "hub.traefik.io/access-control-policy": "my-acp",
++      "nginx.ingress.kubernetes.io/auth-url": "http://auth-server.hub.svc.cluster.local/my-acp",
++      "nginx.ingress.kubernetes.io/configuration-snippet": "##hub-snippet-start\nauth_request_set $value_0 $upstream_http_X_User; proxy_set_header X-User $value_0;\n##hub-snippet-end"
+     },
+     "name": "whoami-nginx",
+     "namespace": "apps"
+`,
+		},
+	}
+	assert.Equal(t, wantResults, report.Results)
+
+	wantResources := []Resource{
+		{
+			Verb: "create",
+			Object: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "traefik.containo.us/v1alpha1",
+				"kind":       "Middleware",
+				"metadata": map[string]interface{}{
+					"name":      "zz-my-acp",
+					"namespace": "apps",
+				},
+				"spec": map[string]interface{}{
+					"forwardAuth": map[string]interface{}{
+						"address":             "http://auth-server.hub.svc.cluster.local/my-acp",
+						"authResponseHeaders": []interface{}{"X-User"},
+					},
+				},
+			}},
+		},
+	}
+	assert.Equal(t, wantResources, report.Resources)
+}
+
+func TestLoadDir_invalidManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte("metadata:\n  name: no-kind\n"), 0o600)
+	require.NoError(t, err)
+
+	_, err = LoadDir(dir)
+	assert.Error(t, err)
+}
@@ -21,15 +21,23 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/scheme"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/reconcile"
 	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	kclientset "k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 // Client for the ACP service.
@@ -48,20 +56,34 @@ type ACP struct {
 
 // Watcher watches hub ACPs.
 type Watcher struct {
-	interval     time.Duration
-	client       Client
-	hubClientSet hubclientset.Interface
-	hubInformer  hubinformers.SharedInformerFactory
+	interval      time.Duration
+	client        Client
+	hubClientSet  hubclientset.Interface
+	hubInformer   hubinformers.SharedInformerFactory
+	eventRecorder record.EventRecorder
+
+	// platformACPs are the ACPs last fetched from the platform, by name. They are nil until fetched.
+	platformACPsMu sync.RWMutex
+	platformACPs   map[string]*ACP
+
+	queue *reconcile.Queue
 }
 
 // NewWatcher returns a new Watcher.
-func NewWatcher(interval time.Duration, client Client, hubClientSet hubclientset.Interface, hubInformer hubinformers.SharedInformerFactory) *Watcher {
-	return &Watcher{
-		interval:     interval,
-		client:       client,
-		hubClientSet: hubClientSet,
-		hubInformer:  hubInformer,
+func NewWatcher(interval time.Duration, client Client, kubeClientSet kclientset.Interface, hubClientSet hubclientset.Interface, hubInformer hubinformers.SharedInformerFactory) *Watcher {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
+
+	w := &Watcher{
+		interval:      interval,
+		client:        client,
+		hubClientSet:  hubClientSet,
+		hubInformer:   hubInformer,
+		eventRecorder: eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{}),
 	}
+	w.queue = reconcile.NewQueue("AccessControlPolicy", w.reconcilePolicy)
+
+	return w
 }
 
 // Run runs Watcher.
@@ -69,56 +91,91 @@ func (w *Watcher) Run(ctx context.Context) {
 	t := time.NewTicker(w.interval)
 	defer t.Stop()
 
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.queue.Run(ctx)
+	}()
+	defer wg.Wait()
+
 	for {
 		select {
 		case <-ctx.Done():
 			log.Info().Msg("Stopping ACP watcher")
 			return
 		case <-t.C:
-			ctxFetch, cancel := context.WithTimeout(ctx, 5*time.Second)
-			acps, err := w.client.GetACPs(ctxFetch)
-			if err != nil {
-				log.Error().Err(err).Msg("Fetching ACPs")
-				cancel()
-				continue
-			}
+			ctxSync, cancel := context.WithTimeout(ctx, 5*time.Second)
+			w.syncPolicies(ctxSync)
 			cancel()
+		}
+	}
+}
 
-			policies, err := w.hubInformer.Hub().V1alpha1().AccessControlPolicies().Lister().List(labels.Everything())
-			if err != nil {
-				log.Error().Err(err).Msg("Listing ACPs")
-				continue
-			}
+func (w *Watcher) syncPolicies(ctx context.Context) {
+	platformACPs, err := w.client.GetACPs(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Fetching ACPs")
+		return
+	}
 
-			policiesByID := map[string]*hubv1alpha1.AccessControlPolicy{}
-			for _, p := range policies {
-				policiesByID[p.Name] = p
-			}
+	policies, err := w.hubInformer.Hub().V1alpha1().AccessControlPolicies().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Listing ACPs")
+		return
+	}
 
-			for _, a := range acps {
-				policy, found := policiesByID[a.Name]
-				// We delete the policy from the map, since we use this map to delete unused policies.
-				delete(policiesByID, a.Name)
-
-				if found && !needUpdate(a, policy) {
-					continue
-				}
-
-				if !found {
-					if err := w.createPolicy(ctx, a); err != nil {
-						log.Error().Err(err).Str("name", a.Name).Msg("Creating ACP")
-					}
-					continue
-				}
-
-				if err := w.updatePolicy(ctx, a, policy); err != nil {
-					log.Error().Err(err).Str("name", policy.Name).Msg("Upsert ACP")
-				}
-			}
+	acps := make(map[string]*ACP, len(platformACPs))
+	for _, a := range platformACPs {
+		platformACP := a
+		acps[platformACP.Name] = &platformACP
+	}
+
+	w.platformACPsMu.Lock()
+	w.platformACPs = acps
+	w.platformACPsMu.Unlock()
+
+	// Each policy is reconciled on its own, policies missing on the platform are deleted.
+	for name := range acps {
+		w.queue.Add(name)
+	}
+	for _, p := range policies {
+		if acps[p.Name] == nil {
+			w.queue.Add(p.Name)
+		}
+	}
+}
+
+// reconcilePolicy creates, updates or deletes the policy with the given name to match the platform ACP.
+func (w *Watcher) reconcilePolicy(ctx context.Context, name string) error {
+	w.platformACPsMu.RLock()
+	platformACP, found := w.platformACPs[name]
+	w.platformACPsMu.RUnlock()
+
+	obj, clusterFound, err := w.hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer().GetIndexer().GetByKey(name)
+	if err != nil {
+		return fmt.Errorf("get ACP: %w", err)
+	}
 
-			w.cleanPolicies(ctx, policiesByID)
+	if !found {
+		if !clusterFound {
+			return nil
 		}
+
+		return w.deletePolicy(ctx, name)
+	}
+
+	if !clusterFound {
+		return w.createPolicy(ctx, *platformACP)
 	}
+
+	policy := obj.(*hubv1alpha1.AccessControlPolicy)
+	if !needUpdate(*platformACP, policy) {
+		return nil
+	}
+
+	// Objects from the informer cache must not be modified.
+	return w.updatePolicy(ctx, *platformACP, policy.DeepCopy())
 }
 
 func (w *Watcher) createPolicy(ctx context.Context, acp ACP) error {
@@ -127,7 +184,8 @@ func (w *Watcher) createPolicy(ctx context.Context, acp ACP) error {
 			Name: acp.Name,
 		},
 		Status: hubv1alpha1.AccessControlPolicyStatus{
-			Version: acp.Version,
+			Version:    acp.Version,
+			Conditions: syncedConditions(nil),
 		},
 	}
 	policy.Spec = buildAccessControlPolicySpec(acp)
@@ -138,19 +196,20 @@ func (w *Watcher) createPolicy(ctx context.Context, acp ACP) error {
 		return fmt.Errorf("build spec hash: %w ", err)
 	}
 
-	ctxCreate, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	if _, err := w.hubClientSet.HubV1alpha1().AccessControlPolicies().Create(ctxCreate, policy, metav1.CreateOptions{}); err != nil {
+	createdPolicy, err := w.hubClientSet.HubV1alpha1().AccessControlPolicies().Create(ctx, policy, metav1.CreateOptions{})
+	if err != nil {
 		return fmt.Errorf("creating ACP: %w", err)
 	}
 	log.Debug().Str("name", policy.Name).Msg("ACP created")
+
+	w.eventRecorder.Event(createdPolicy, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
 	return nil
 }
 
 func (w *Watcher) updatePolicy(ctx context.Context, acp ACP, policy *hubv1alpha1.AccessControlPolicy) error {
 	policy.Spec = buildAccessControlPolicySpec(acp)
 	policy.Status.Version = acp.Version
+	policy.Status.Conditions = syncedConditions(policy.Status.Conditions)
 
 	var err error
 	policy.Status.SpecHash, err = policy.Spec.Hash()
@@ -158,33 +217,42 @@ func (w *Watcher) updatePolicy(ctx context.Context, acp ACP, policy *hubv1alpha1
 		return fmt.Errorf("build spec hash: %w", err)
 	}
 
-	ctxUpdate, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	if _, err := w.hubClientSet.HubV1alpha1().AccessControlPolicies().Update(ctxUpdate, policy, metav1.UpdateOptions{}); err != nil {
+	updatedPolicy, err := w.hubClientSet.HubV1alpha1().AccessControlPolicies().Update(ctx, policy, metav1.UpdateOptions{})
+	if err != nil {
+		w.eventRecorder.Eventf(policy, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
 		return fmt.Errorf("updating ACP: %w", err)
 	}
 	log.Debug().Str("name", policy.Name).Msg("ACP updated")
 
+	w.eventRecorder.Event(updatedPolicy, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
+
 	return nil
 }
 
-func (w *Watcher) cleanPolicies(ctx context.Context, policies map[string]*hubv1alpha1.AccessControlPolicy) {
-	for _, p := range policies {
-		ctxDelete, cancel := context.WithTimeout(ctx, 5*time.Second)
-		err := w.hubClientSet.HubV1alpha1().AccessControlPolicies().Delete(ctxDelete, p.Name, metav1.DeleteOptions{})
-		if err != nil {
-			log.Error().Err(err).Msg("Deleting ACP")
-			cancel()
-			continue
-		}
-		log.Debug().Str("name", p.Name).Msg("ACP deleted")
-		cancel()
+func (w *Watcher) deletePolicy(ctx context.Context, name string) error {
+	err := w.hubClientSet.HubV1alpha1().AccessControlPolicies().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("deleting ACP: %w", err)
 	}
+	log.Debug().Str("name", name).Msg("ACP deleted")
+
+	return nil
 }
 
 func needUpdate(a ACP, policy *hubv1alpha1.AccessControlPolicy) bool {
-	return !reflect.DeepEqual(buildAccessControlPolicySpec(a), policy.Spec)
+	// Policies created before conditions were introduced are updated once to report them.
+	return !reflect.DeepEqual(buildAccessControlPolicySpec(a), policy.Spec) ||
+		!apimeta.IsStatusConditionTrue(policy.Status.Conditions, hubv1alpha1.ConditionSynced)
+}
+
+// syncedConditions returns the given conditions updated to report a policy synchronized with the platform.
+func syncedConditions(conditions []metav1.Condition) []metav1.Condition {
+	return hubv1alpha1.MergeConditions(conditions,
+		hubv1alpha1.NewCondition(hubv1alpha1.ConditionSynced, metav1.ConditionTrue, hubv1alpha1.ReasonSynced,
+			"Resource is synchronized with the platform", metav1.Time{}),
+		hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionTrue, hubv1alpha1.ReasonReady,
+			"Resource is ready", metav1.Time{}),
+	)
 }
 
 func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
@@ -215,9 +283,10 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 		keys := make([]hubv1alpha1.AccessControlPolicyAPIKeyKey, 0, len(a.APIKey.Keys))
 		for _, k := range a.APIKey.Keys {
 			keys = append(keys, hubv1alpha1.AccessControlPolicyAPIKeyKey{
-				ID:       k.ID,
-				Metadata: k.Metadata,
-				Value:    k.Value,
+				ID:        k.ID,
+				Metadata:  k.Metadata,
+				Value:     k.Value,
+				ValueFrom: k.ValueFrom,
 			})
 		}
 
@@ -230,6 +299,14 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 			},
 			Keys:           keys,
 			ForwardHeaders: a.APIKey.ForwardHeaders,
+			IssuedKeys:     a.APIKey.IssuedKeys,
+		}
+
+		if a.APIKey.Quota != nil {
+			spec.APIKey.Quota = &hubv1alpha1.AccessControlPolicyAPIKeyQuota{
+				Limit:  a.APIKey.Quota.Limit,
+				Period: metav1.Duration{Duration: a.APIKey.Quota.Period},
+			}
 		}
 
 	case a.OIDC != nil:
@@ -346,5 +423,12 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 		}
 	}
 
+	if a.Headers != nil {
+		spec.Headers = &hubv1alpha1.AccessControlPolicyHeaders{
+			Request:  (*hubv1alpha1.AccessControlPolicyRequestHeaders)(a.Headers.Request),
+			Response: (*hubv1alpha1.AccessControlPolicyResponseHeaders)(a.Headers.Response),
+		}
+	}
+
 	return spec
 }
@@ -19,6 +19,8 @@ package acp
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -31,7 +33,10 @@ import (
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 var toUpdate = &hubv1alpha1.AccessControlPolicy{
@@ -95,10 +100,18 @@ func Test_WatcherRun(t *testing.T) {
 			}
 		})
 
-	w := NewWatcher(time.Millisecond, client, clientSetHub, hubInformer)
-	go w.Run(ctx)
+	recorder := record.NewFakeRecorder(10)
 
-	<-ctx.Done()
+	w := NewWatcher(time.Millisecond, client, kubefake.NewSimpleClientset(), clientSetHub, hubInformer)
+	w.eventRecorder = recorder
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
 
 	policy, err := clientSetHub.HubV1alpha1().AccessControlPolicies().Get(ctx, "toCreate", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -110,4 +123,103 @@ func Test_WatcherRun(t *testing.T) {
 
 	_, err = clientSetHub.HubV1alpha1().AccessControlPolicies().Get(ctx, "toDelete", metav1.GetOptions{})
 	require.Error(t, err)
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Contains(t, events, "Normal Created Created from the Hub platform")
+	assert.Contains(t, events, "Normal Updated Updated from the Hub platform")
+}
+
+func Test_WatcherRun_retriesFailingPolicy(t *testing.T) {
+	clientSetHub := hubfake.NewSimpleClientset()
+
+	// The first creation of the "failing" policy fails, it must be retried without blocking the other policy.
+	var (
+		createsMu sync.Mutex
+		creates   int
+	)
+	clientSetHub.PrependReactor("create", "accesscontrolpolicies", func(action ktesting.Action) (bool, runtime.Object, error) {
+		policy := action.(ktesting.CreateAction).GetObject().(*hubv1alpha1.AccessControlPolicy)
+		if policy.Name != "failing" {
+			return false, nil, nil
+		}
+
+		createsMu.Lock()
+		defer createsMu.Unlock()
+
+		creates++
+		if creates == 1 {
+			return true, nil, errors.New("boom")
+		}
+		return false, nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+	acpInformer := hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), acpInformer.HasSynced)
+
+	client := newClientMock(t)
+	client.OnGetACPs().
+		TypedReturns([]ACP{
+			{Name: "failing", Config: Config{JWT: &jwt.Config{PublicKey: "secret"}}},
+			{Name: "working", Config: Config{JWT: &jwt.Config{PublicKey: "secret"}}},
+		}, nil).
+		Maybe()
+
+	w := NewWatcher(time.Millisecond, client, kubefake.NewSimpleClientset(), clientSetHub, hubInformer)
+	w.eventRecorder = record.NewFakeRecorder(10)
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	policyExists := func(name string) func() bool {
+		return func() bool {
+			_, err := clientSetHub.HubV1alpha1().AccessControlPolicies().Get(ctx, name, metav1.GetOptions{})
+			return err == nil
+		}
+	}
+	assert.Eventually(t, policyExists("working"), time.Second, 10*time.Millisecond)
+	assert.Eventually(t, policyExists("failing"), 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stop
+
+	createsMu.Lock()
+	defer createsMu.Unlock()
+	assert.GreaterOrEqual(t, creates, 2)
+}
+
+func TestBuildAccessControlPolicySpec_keepsSecretReferences(t *testing.T) {
+	spec := hubv1alpha1.AccessControlPolicySpec{
+		APIKey: &hubv1alpha1.AccessControlPolicyAPIKey{
+			KeySource: hubv1alpha1.TokenSource{Header: "Api-Key"},
+			Keys: []hubv1alpha1.AccessControlPolicyAPIKeyKey{
+				{
+					ID:    "inline",
+					Value: "hash",
+				},
+				{
+					ID:        "shared",
+					ValueFrom: &hubv1alpha1.SecretReference{Name: "keys", Namespace: "default", Key: "shared"},
+				},
+			},
+		},
+	}
+
+	// ACPs are sent to the platform without resolving their secret references, which must be kept when synchronizing
+	// them back from the platform.
+	policy := &hubv1alpha1.AccessControlPolicy{Spec: spec}
+	a := ACP{Config: *ConfigFromPolicy(policy)}
+
+	assert.Equal(t, spec, buildAccessControlPolicySpec(a))
 }
@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sync"
 
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 )
@@ -35,7 +36,8 @@ type Client struct {
 	baseURL    *url.URL
 	httpClient *http.Client
 
-	token string
+	tokenMu sync.RWMutex
+	token   string
 }
 
 // NewClient creates an alerting service client.
@@ -52,6 +54,21 @@ func NewClient(client *http.Client, baseURL, token string) (*Client, error) {
 	}, nil
 }
 
+// SetToken sets the token used to authenticate the calls to the alerting service, e.g. once it's been rotated.
+func (c *Client) SetToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.token = token
+}
+
+func (c *Client) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+
+	return c.token
+}
+
 // GetRules gets the agent configuration.
 func (c *Client) GetRules(ctx context.Context) ([]Rule, error) {
 	endpoint, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "rules"))
@@ -197,5 +214,5 @@ func (c *Client) SendAlerts(ctx context.Context, data []Alert) error {
 }
 
 func (c *Client) setAuthHeader(req *http.Request) {
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 }
@@ -45,7 +45,9 @@ type Manager struct {
 	rulesMu sync.Mutex
 	rules   []Rule
 
-	procs map[string]Processor
+	procs      map[string]Processor
+	dispatcher *Dispatcher
+	silencer   *Silencer
 
 	refreshInterval   time.Duration
 	schedulerInterval time.Duration
@@ -66,6 +68,18 @@ func NewManager(backend Backend, procs map[string]Processor, refreshInterval, sc
 	}
 }
 
+// SetDispatcher sets the dispatcher the raised alerts are notified to, in addition to being sent to the platform. It
+// must be called before running the manager.
+func (m *Manager) SetDispatcher(dispatcher *Dispatcher) {
+	m.dispatcher = dispatcher
+}
+
+// SetSilencer sets the silencer muting the alerts matching an active silence. It must be called before running the
+// manager.
+func (m *Manager) SetSilencer(silencer *Silencer) {
+	m.silencer = silencer
+}
+
 // Run runs the alert manager.
 func (m *Manager) Run(ctx context.Context) error {
 	rules, err := m.backend.GetRules(ctx)
@@ -145,11 +159,23 @@ func (m *Manager) checkAlerts(ctx context.Context) error {
 			continue
 		}
 
+		if m.silencer != nil {
+			if silence, silenced := m.silencer.Silenced(*alert); silenced {
+				log.Debug().Str("rule_id", rule.ID).Str("silence", silence).Msg("Alert silenced")
+				continue
+			}
+		}
+
 		alerts = append(alerts, *alert)
 	}
 
 	m.rulesMu.Unlock()
 
+	// Notifications are dispatched independently of the platform, so they are delivered even if it is unavailable.
+	if m.dispatcher != nil {
+		m.dispatcher.Dispatch(ctx, alerts)
+	}
+
 	log.Debug().Int("count", len(alerts)).Msg("Checking alerts to send")
 
 	// Make a preflight request even if there is no alerts as it's also used for resolving existing alerts.
@@ -911,3 +911,113 @@ func (_c *logProviderGetServiceLogsCall) OnGetServiceLogs(namespace string, name
 func (_c *logProviderGetServiceLogsCall) OnGetServiceLogsRaw(namespace interface{}, name interface{}, lines interface{}, maxLen interface{}) *logProviderGetServiceLogsCall {
 	return _c.Parent.OnGetServiceLogsRaw(namespace, name, lines, maxLen)
 }
+
+// eventProviderMock mock of EventProvider.
+type eventProviderMock struct{ mock.Mock }
+
+// newEventProviderMock creates a new eventProviderMock.
+func newEventProviderMock(tb testing.TB) *eventProviderMock {
+	tb.Helper()
+
+	m := &eventProviderMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *eventProviderMock) GetServiceEvents(_ context.Context, namespace string, name string, since time.Time, maxEvents int) ([]byte, error) {
+	_ret := _m.Called(namespace, name, since, maxEvents)
+
+	if _rf, ok := _ret.Get(0).(func(string, string, time.Time, int) ([]byte, error)); ok {
+		return _rf(namespace, name, since, maxEvents)
+	}
+
+	_ra0, _ := _ret.Get(0).([]byte)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *eventProviderMock) OnGetServiceEvents(namespace string, name string, since time.Time, maxEvents int) *eventProviderGetServiceEventsCall {
+	return &eventProviderGetServiceEventsCall{Call: _m.Mock.On("GetServiceEvents", namespace, name, since, maxEvents), Parent: _m}
+}
+
+func (_m *eventProviderMock) OnGetServiceEventsRaw(namespace interface{}, name interface{}, since interface{}, maxEvents interface{}) *eventProviderGetServiceEventsCall {
+	return &eventProviderGetServiceEventsCall{Call: _m.Mock.On("GetServiceEvents", namespace, name, since, maxEvents), Parent: _m}
+}
+
+type eventProviderGetServiceEventsCall struct {
+	*mock.Call
+	Parent *eventProviderMock
+}
+
+func (_c *eventProviderGetServiceEventsCall) Panic(msg string) *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) Once() *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) Twice() *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) Times(i int) *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) WaitUntil(w <-chan time.Time) *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) After(d time.Duration) *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) Run(fn func(args mock.Arguments)) *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) Maybe() *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) TypedReturns(a []byte, b error) *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) ReturnsFn(fn func(string, string, time.Time, int) ([]byte, error)) *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) TypedRun(fn func(string, string, time.Time, int)) *eventProviderGetServiceEventsCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_namespace := args.String(0)
+		_name := args.String(1)
+		_since, _ := args.Get(2).(time.Time)
+		_maxEvents := args.Int(3)
+		fn(_namespace, _name, _since, _maxEvents)
+	})
+	return _c
+}
+
+func (_c *eventProviderGetServiceEventsCall) OnGetServiceEvents(namespace string, name string, since time.Time, maxEvents int) *eventProviderGetServiceEventsCall {
+	return _c.Parent.OnGetServiceEvents(namespace, name, since, maxEvents)
+}
+
+func (_c *eventProviderGetServiceEventsCall) OnGetServiceEventsRaw(namespace interface{}, name interface{}, since interface{}, maxEvents interface{}) *eventProviderGetServiceEventsCall {
+	return _c.Parent.OnGetServiceEventsRaw(namespace, name, since, maxEvents)
+}
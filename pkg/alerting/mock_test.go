@@ -21,3 +21,4 @@ package alerting
 // mocktail:Processor
 // mocktail:DataPointsFinder
 // mocktail:LogProvider
+// mocktail:EventProvider
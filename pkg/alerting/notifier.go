@@ -0,0 +1,328 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Notification statuses.
+const (
+	NotificationFiring   = "firing"
+	NotificationResolved = "resolved"
+)
+
+// Notification notifies that an alert is firing or has been resolved.
+type Notification struct {
+	Status string `json:"status"`
+	Alert  Alert  `json:"alert"`
+}
+
+// Notifier delivers notifications outside the platform.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Dispatcher delivers the notifications of the alerts raised by the manager to notifiers. Each notifier is notified
+// once when an alert starts firing, again every repeat interval while it keeps firing, and once when it is resolved.
+// Failed notifications are retried on the next dispatch.
+type Dispatcher struct {
+	repeatInterval time.Duration
+	notifiers      []*notifierState
+
+	nowFunc func() time.Time
+}
+
+type notifierState struct {
+	Notifier
+
+	mu sync.Mutex
+	// firing holds the firing alerts and when they were successfully notified, indexed by alert key.
+	firing map[string]firingAlert
+}
+
+type firingAlert struct {
+	alert      Alert
+	notifiedAt time.Time
+}
+
+// NewDispatcher returns a dispatcher delivering notifications to the given notifiers.
+func NewDispatcher(repeatInterval time.Duration, notifiers ...Notifier) *Dispatcher {
+	states := make([]*notifierState, 0, len(notifiers))
+	for _, notifier := range notifiers {
+		states = append(states, &notifierState{
+			Notifier: notifier,
+			firing:   make(map[string]firingAlert),
+		})
+	}
+
+	return &Dispatcher{
+		repeatInterval: repeatInterval,
+		notifiers:      states,
+		nowFunc:        time.Now,
+	}
+}
+
+// SetClock sets the clock used to determine when alerts must be notified again.
+func (d *Dispatcher) SetClock(nowFunc func() time.Time) {
+	d.nowFunc = nowFunc
+}
+
+// Dispatch notifies the given firing alerts, and the previously firing ones which are not anymore as resolved.
+func (d *Dispatcher) Dispatch(ctx context.Context, alerts []Alert) {
+	now := d.nowFunc()
+
+	firing := make(map[string]Alert, len(alerts))
+	for _, alert := range alerts {
+		firing[alertKey(alert)] = alert
+	}
+
+	for _, notifier := range d.notifiers {
+		notifier.dispatch(ctx, firing, now, d.repeatInterval)
+	}
+}
+
+func (n *notifierState) dispatch(ctx context.Context, firing map[string]Alert, now time.Time, repeatInterval time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for key, alert := range firing {
+		prev, ok := n.firing[key]
+		if ok && !prev.notifiedAt.IsZero() && now.Sub(prev.notifiedAt) < repeatInterval {
+			continue
+		}
+
+		if err := n.Notify(ctx, Notification{Status: NotificationFiring, Alert: alert}); err != nil {
+			log.Error().Err(err).Str("rule_id", alert.RuleID).Msg("Unable to notify firing alert")
+
+			// Keep track of the alert so it gets resolved even though its firing notification failed.
+			if !ok {
+				n.firing[key] = firingAlert{alert: alert}
+			}
+			continue
+		}
+
+		n.firing[key] = firingAlert{alert: alert, notifiedAt: now}
+	}
+
+	for key, prev := range n.firing {
+		if _, ok := firing[key]; ok {
+			continue
+		}
+
+		// An alert that has never been notified as firing doesn't need to be notified as resolved.
+		if !prev.notifiedAt.IsZero() {
+			if err := n.Notify(ctx, Notification{Status: NotificationResolved, Alert: prev.alert}); err != nil {
+				log.Error().Err(err).Str("rule_id", prev.alert.RuleID).Msg("Unable to notify resolved alert")
+				continue
+			}
+		}
+
+		delete(n.firing, key)
+	}
+}
+
+// alertKey identifies the alerts of a rule raised for an ingress and a service.
+func alertKey(alert Alert) string {
+	return alert.RuleID + "|" + alert.Ingress + "|" + alert.Service
+}
+
+// WebhookNotifier posts notifications as JSON documents to a webhook.
+type WebhookNotifier struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+// NewWebhookNotifier returns a notifier posting notifications to the given URL with the given headers.
+func NewWebhookNotifier(client *http.Client, url string, headers map[string]string) *WebhookNotifier {
+	return &WebhookNotifier{
+		client:  client,
+		url:     url,
+		headers: headers,
+	}
+}
+
+// Notify posts the notification to the webhook.
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, n.client, n.url, n.headers, notification)
+}
+
+// SlackNotifier posts notifications as messages to a Slack incoming webhook.
+type SlackNotifier struct {
+	client *http.Client
+	url    string
+}
+
+// NewSlackNotifier returns a notifier posting notifications to the given Slack incoming webhook URL.
+func NewSlackNotifier(client *http.Client, url string) *SlackNotifier {
+	return &SlackNotifier{
+		client: client,
+		url:    url,
+	}
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Notify posts the notification as a Slack message.
+func (n *SlackNotifier) Notify(ctx context.Context, notification Notification) error {
+	icon := ":rotating_light:"
+	if notification.Status == NotificationResolved {
+		icon = ":white_check_mark:"
+	}
+
+	text := fmt.Sprintf("%s *Alert %s %s*\n%s", icon, notification.Alert.RuleID, notification.Status, describe(notification.Alert))
+
+	return postJSON(ctx, n.client, n.url, nil, slackMessage{Text: text})
+}
+
+// PagerDutyEventsURL is the URL of the PagerDuty Events API v2.
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyNotifier triggers and resolves PagerDuty incidents through the Events API v2.
+type PagerDutyNotifier struct {
+	client     *http.Client
+	url        string
+	routingKey string
+}
+
+// NewPagerDutyNotifier returns a notifier sending events to the given events URL with the given integration routing key.
+func NewPagerDutyNotifier(client *http.Client, url, routingKey string) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		client:     client,
+		url:        url,
+		routingKey: routingKey,
+	}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string `json:"summary"`
+	Source        string `json:"source"`
+	Severity      string `json:"severity"`
+	CustomDetails Alert  `json:"custom_details"`
+}
+
+// Notify triggers a PagerDuty incident for a firing alert, and resolves it once the alert is resolved.
+func (n *PagerDutyNotifier) Notify(ctx context.Context, notification Notification) error {
+	alert := notification.Alert
+
+	event := pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: "resolve",
+		DedupKey:    alertKey(alert),
+	}
+
+	if notification.Status == NotificationFiring {
+		event.EventAction = "trigger"
+
+		source := alert.Service
+		if source == "" {
+			source = alert.Ingress
+		}
+
+		// Logs are compressed, they are useless in the incident details.
+		alert.Logs = nil
+
+		event.Payload = &pagerDutyPayload{
+			Summary:       fmt.Sprintf("Alert %s firing: %s", alert.RuleID, describe(alert)),
+			Source:        source,
+			Severity:      "error",
+			CustomDetails: alert,
+		}
+	}
+
+	return postJSON(ctx, n.client, n.url, nil, event)
+}
+
+// describe returns a human-readable description of the given alert.
+func describe(alert Alert) string {
+	var target []string
+	if alert.Ingress != "" {
+		target = append(target, "ingress "+alert.Ingress)
+	}
+	if alert.Service != "" {
+		target = append(target, "service "+alert.Service)
+	}
+
+	var condition string
+	switch {
+	case alert.Threshold != nil:
+		direction := "below"
+		if alert.Threshold.Condition.Above {
+			direction = "above"
+		}
+		condition = fmt.Sprintf("%s %s %g on %d points over %s",
+			alert.Threshold.Metric, direction, alert.Threshold.Condition.Value, alert.Threshold.Occurrence, alert.Threshold.TimeRange)
+	case alert.Anomaly != nil:
+		condition = fmt.Sprintf("%s deviating by more than %g standard deviations over %s",
+			alert.Anomaly.Metric, alert.Anomaly.Deviations, alert.Anomaly.TimeRange)
+	case alert.Absence != nil:
+		condition = fmt.Sprintf("no data point over %s", alert.Absence.TimeRange)
+	}
+
+	return strings.Join(target, ", ") + ": " + condition
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		all, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("notify got %d: %s", resp.StatusCode, string(all))
+	}
+
+	return nil
+}
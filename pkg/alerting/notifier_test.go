@@ -0,0 +1,262 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notifierMock struct {
+	err           error
+	notifications []Notification
+}
+
+func (n *notifierMock) Notify(_ context.Context, notification Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	alert1 := Alert{RuleID: "rule-1", Ingress: "ing@default", Service: "svc@default"}
+	alert2 := Alert{RuleID: "rule-2", Service: "svc@default"}
+
+	now := time.Now()
+	notifier := &notifierMock{}
+
+	d := NewDispatcher(time.Hour, notifier)
+	d.SetClock(func() time.Time { return now })
+
+	// Newly firing alerts are notified.
+	d.Dispatch(context.Background(), []Alert{alert1})
+	assert.Equal(t, []Notification{{Status: NotificationFiring, Alert: alert1}}, notifier.notifications)
+
+	// Already notified alerts are not notified again until the repeat interval is elapsed.
+	notifier.notifications = nil
+	now = now.Add(30 * time.Minute)
+	d.Dispatch(context.Background(), []Alert{alert1, alert2})
+	assert.Equal(t, []Notification{{Status: NotificationFiring, Alert: alert2}}, notifier.notifications)
+
+	notifier.notifications = nil
+	now = now.Add(30 * time.Minute)
+	d.Dispatch(context.Background(), []Alert{alert1, alert2})
+	assert.Equal(t, []Notification{{Status: NotificationFiring, Alert: alert1}}, notifier.notifications)
+
+	// Alerts not firing anymore are resolved.
+	notifier.notifications = nil
+	d.Dispatch(context.Background(), []Alert{alert1})
+	assert.Equal(t, []Notification{{Status: NotificationResolved, Alert: alert2}}, notifier.notifications)
+
+	notifier.notifications = nil
+	d.Dispatch(context.Background(), nil)
+	assert.Equal(t, []Notification{{Status: NotificationResolved, Alert: alert1}}, notifier.notifications)
+
+	notifier.notifications = nil
+	d.Dispatch(context.Background(), nil)
+	assert.Empty(t, notifier.notifications)
+}
+
+func TestDispatcher_Dispatch_retriesFailedNotifications(t *testing.T) {
+	alert := Alert{RuleID: "rule-1", Service: "svc@default"}
+
+	notifier := &notifierMock{err: errors.New("boom")}
+	d := NewDispatcher(time.Hour, notifier)
+
+	d.Dispatch(context.Background(), []Alert{alert})
+	assert.Empty(t, notifier.notifications)
+
+	notifier.err = nil
+	d.Dispatch(context.Background(), []Alert{alert})
+	assert.Equal(t, []Notification{{Status: NotificationFiring, Alert: alert}}, notifier.notifications)
+
+	notifier.notifications = nil
+	notifier.err = errors.New("boom")
+	d.Dispatch(context.Background(), nil)
+
+	notifier.err = nil
+	d.Dispatch(context.Background(), nil)
+	assert.Equal(t, []Notification{{Status: NotificationResolved, Alert: alert}}, notifier.notifications)
+}
+
+func TestDispatcher_Dispatch_neverNotifiedAlertIsNotResolved(t *testing.T) {
+	alert := Alert{RuleID: "rule-1", Service: "svc@default"}
+
+	notifier := &notifierMock{err: errors.New("boom")}
+	d := NewDispatcher(time.Hour, notifier)
+
+	d.Dispatch(context.Background(), []Alert{alert})
+
+	notifier.err = nil
+	d.Dispatch(context.Background(), nil)
+	assert.Empty(t, notifier.notifications)
+}
+
+func TestNotifiers_Notify(t *testing.T) {
+	alert := Alert{
+		RuleID:  "rule-1",
+		Service: "svc@default",
+		Points:  []Point{{Timestamp: 1, Value: 42}},
+		Logs:    []byte("logs"),
+		Threshold: &Threshold{
+			Metric:     "latency",
+			Condition:  ThresholdCondition{Above: true, Value: 10},
+			Occurrence: 1,
+			TimeRange:  time.Hour,
+		},
+	}
+
+	tests := []struct {
+		desc         string
+		notification Notification
+		newNotifier  func(url string) Notifier
+		wantHeaders  map[string]string
+		wantBody     map[string]interface{}
+	}{
+		{
+			desc:         "webhook",
+			notification: Notification{Status: NotificationFiring, Alert: alert},
+			newNotifier: func(url string) Notifier {
+				return NewWebhookNotifier(http.DefaultClient, url, map[string]string{"Authorization": "Bearer secret"})
+			},
+			wantHeaders: map[string]string{"Authorization": "Bearer secret"},
+			wantBody: map[string]interface{}{
+				"status": "firing",
+				"alert": map[string]interface{}{
+					"ruleId":  "rule-1",
+					"ingress": "",
+					"service": "svc@default",
+					"points":  []interface{}{map[string]interface{}{"ts": float64(1), "value": float64(42)}},
+					"logs":    "bG9ncw==",
+					"threshold": map[string]interface{}{
+						"metric":     "latency",
+						"condition":  map[string]interface{}{"above": true, "value": float64(10)},
+						"occurrence": float64(1),
+						"timeRange":  float64(time.Hour),
+					},
+				},
+			},
+		},
+		{
+			desc:         "slack",
+			notification: Notification{Status: NotificationResolved, Alert: alert},
+			newNotifier: func(url string) Notifier {
+				return NewSlackNotifier(http.DefaultClient, url)
+			},
+			wantBody: map[string]interface{}{
+				"text": ":white_check_mark: *Alert rule-1 resolved*\nservice svc@default: latency above 10 on 1 points over 1h0m0s",
+			},
+		},
+		{
+			desc:         "PagerDuty trigger",
+			notification: Notification{Status: NotificationFiring, Alert: alert},
+			newNotifier: func(url string) Notifier {
+				return NewPagerDutyNotifier(http.DefaultClient, url, "routing-key")
+			},
+			wantBody: map[string]interface{}{
+				"routing_key":  "routing-key",
+				"event_action": "trigger",
+				"dedup_key":    "rule-1||svc@default",
+				"payload": map[string]interface{}{
+					"summary":  "Alert rule-1 firing: service svc@default: latency above 10 on 1 points over 1h0m0s",
+					"source":   "svc@default",
+					"severity": "error",
+					"custom_details": map[string]interface{}{
+						"ruleId":  "rule-1",
+						"ingress": "",
+						"service": "svc@default",
+						"points":  []interface{}{map[string]interface{}{"ts": float64(1), "value": float64(42)}},
+						"logs":    nil,
+						"threshold": map[string]interface{}{
+							"metric":     "latency",
+							"condition":  map[string]interface{}{"above": true, "value": float64(10)},
+							"occurrence": float64(1),
+							"timeRange":  float64(time.Hour),
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:         "PagerDuty resolve",
+			notification: Notification{Status: NotificationResolved, Alert: alert},
+			newNotifier: func(url string) Notifier {
+				return NewPagerDutyNotifier(http.DefaultClient, url, "routing-key")
+			},
+			wantBody: map[string]interface{}{
+				"routing_key":  "routing-key",
+				"event_action": "resolve",
+				"dedup_key":    "rule-1||svc@default",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotBody map[string]interface{}
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPost {
+					http.Error(rw, "unsupported method", http.StatusMethodNotAllowed)
+					return
+				}
+
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				for name, value := range test.wantHeaders {
+					assert.Equal(t, value, req.Header.Get(name))
+				}
+
+				err := json.NewDecoder(req.Body).Decode(&gotBody)
+				require.NoError(t, err)
+
+				rw.WriteHeader(http.StatusAccepted)
+			}))
+			t.Cleanup(srv.Close)
+
+			err := test.newNotifier(srv.URL).Notify(context.Background(), test.notification)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantBody, gotBody)
+		})
+	}
+}
+
+func TestWebhookNotifier_Notify_handlesErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	n := NewWebhookNotifier(http.DefaultClient, srv.URL, nil)
+
+	err := n.Notify(context.Background(), Notification{Status: NotificationFiring, Alert: Alert{RuleID: "rule-1"}})
+	assert.EqualError(t, err, "notify got 503: unavailable\n")
+}
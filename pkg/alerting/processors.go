@@ -23,6 +23,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 const (
 	logLines         = 50
 	logMaxLineLength = 200
+	maxEvents        = 50
 )
 
 // DataPointsFinder is capable of finding data points for given criteria.
@@ -47,23 +49,36 @@ type LogProvider interface {
 	GetServiceLogs(ctx context.Context, namespace, name string, lines, maxLen int) ([]byte, error)
 }
 
+// EventProvider implements an object that can provide the Kubernetes events of the pods of a service.
+type EventProvider interface {
+	GetServiceEvents(ctx context.Context, namespace, name string, since time.Time, maxEvents int) ([]byte, error)
+}
+
 // ThresholdProcessor processes threshold rules.
 type ThresholdProcessor struct {
 	dataPoints DataPointsFinder
 	logs       LogProvider
+	events     EventProvider
 
 	nowFunc func() time.Time
 }
 
 // NewThresholdProcessor returns a threshold processor.
-func NewThresholdProcessor(dataPoints DataPointsFinder, logs LogProvider) *ThresholdProcessor {
+func NewThresholdProcessor(dataPoints DataPointsFinder, logs LogProvider, events EventProvider) *ThresholdProcessor {
 	return &ThresholdProcessor{
 		dataPoints: dataPoints,
 		logs:       logs,
+		events:     events,
 		nowFunc:    time.Now,
 	}
 }
 
+// SetClock sets the clock used to compute the time range of the rules, e.g. to compensate a skew with the
+// platform clock. It must be called before processing any rule.
+func (p *ThresholdProcessor) SetClock(now func() time.Time) {
+	p.nowFunc = now
+}
+
 // Process processes a threshold rule returning an alert or nil.
 func (p *ThresholdProcessor) Process(ctx context.Context, rule *Rule) (*Alert, error) {
 	table := rule.Threshold.Table()
@@ -74,29 +89,16 @@ func (p *ThresholdProcessor) Process(ctx context.Context, rule *Rule) (*Alert, e
 	to := p.nowFunc().UTC().Truncate(granularity).Add(-granularity)
 	from := to.Add(-rule.Threshold.TimeRange)
 
-	var dataPoints metrics.DataPoints
-
-	switch {
-	case rule.Ingress != "" && rule.Service != "":
-		var err error
-
-		dataPoints, err = p.dataPoints.FindByIngressAndService(table, rule.Ingress, rule.Service, from, to)
-		if err != nil {
-			return nil, err
-		}
-	case rule.Service != "":
-		dataPoints = p.dataPoints.FindByService(table, rule.Service, from, to)
-	case rule.Ingress != "":
-		dataPoints = p.dataPoints.FindByIngress(table, rule.Ingress, from, to)
-	default:
-		return nil, errors.New("invalid rule")
+	dataPoints, err := findDataPoints(p.dataPoints, rule, table, from, to)
+	if err != nil {
+		return nil, err
 	}
 
 	var points []Point
 	for _, datapoint := range dataPoints {
-		value, err := getValue(rule.Threshold.Metric, datapoint)
-		if err != nil {
-			return nil, err
+		value, valueErr := getValue(rule.Threshold.Metric, datapoint)
+		if valueErr != nil {
+			return nil, valueErr
 		}
 
 		points = append(points, Point{
@@ -112,17 +114,24 @@ func (p *ThresholdProcessor) Process(ctx context.Context, rule *Rule) (*Alert, e
 	}
 
 	// Grab pod logs selected by the service if there are some.
-	logs, err := p.getLogs(ctx, rule.Service)
+	logs, err := getLogs(ctx, p.logs, rule.Service)
 	if err != nil {
 		log.Error().Err(err).Str("service", rule.Service).Msg("Unable to get logs")
 	}
 
+	// Grab the events of these pods, such as failed probes or OOM kills, which occurred during the time range.
+	events, err := getEvents(ctx, p.events, rule.Service, from)
+	if err != nil {
+		log.Error().Err(err).Str("service", rule.Service).Msg("Unable to get events")
+	}
+
 	return &Alert{
 		RuleID:    rule.ID,
 		Ingress:   rule.Ingress,
 		Service:   rule.Service,
 		Points:    points,
 		Logs:      logs,
+		Events:    events,
 		Threshold: rule.Threshold,
 	}, nil
 }
@@ -140,7 +149,245 @@ func (p *ThresholdProcessor) countOccurrences(rule *Rule, pnts []Point) int {
 	return count
 }
 
-func (p *ThresholdProcessor) getLogs(ctx context.Context, service string) ([]byte, error) {
+// AnomalyProcessor processes anomaly rules.
+type AnomalyProcessor struct {
+	dataPoints DataPointsFinder
+	logs       LogProvider
+
+	nowFunc func() time.Time
+}
+
+// NewAnomalyProcessor returns an anomaly processor.
+func NewAnomalyProcessor(dataPoints DataPointsFinder, logs LogProvider) *AnomalyProcessor {
+	return &AnomalyProcessor{
+		dataPoints: dataPoints,
+		logs:       logs,
+		nowFunc:    time.Now,
+	}
+}
+
+// SetClock sets the clock used to compute the time range of the rules, e.g. to compensate a skew with the
+// platform clock. It must be called before processing any rule.
+func (p *AnomalyProcessor) SetClock(now func() time.Time) {
+	p.nowFunc = now
+}
+
+// minBaselinePoints is the minimum number of data points a baseline must have to detect anomalies.
+const minBaselinePoints = 3
+
+// Process processes an anomaly rule returning an alert or nil.
+func (p *AnomalyProcessor) Process(ctx context.Context, rule *Rule) (*Alert, error) {
+	anomaly := rule.Anomaly
+	if anomaly.Baseline <= 0 || anomaly.Deviations < 0 {
+		return nil, errors.New("invalid anomaly rule")
+	}
+
+	table := anomaly.Table()
+	granularity := anomaly.Granularity()
+
+	// The granularity is subtracted to avoid capturing the last data point which is not yet complete.
+	to := p.nowFunc().UTC().Truncate(granularity).Add(-granularity)
+	from := to.Add(-anomaly.TimeRange)
+
+	dataPoints, err := findDataPoints(p.dataPoints, rule, table, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline, err := p.computeBaseline(rule, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if baseline == nil {
+		return nil, nil
+	}
+
+	var (
+		points []Point
+		count  int
+	)
+	for _, datapoint := range dataPoints {
+		value, valueErr := getValue(anomaly.Metric, datapoint)
+		if valueErr != nil {
+			return nil, valueErr
+		}
+
+		points = append(points, Point{
+			Timestamp: datapoint.Timestamp,
+			Value:     value,
+		})
+
+		if isAnomalous(anomaly, *baseline, value) {
+			count++
+		}
+	}
+
+	if count == 0 || count < anomaly.Occurrence {
+		return nil, nil
+	}
+
+	// Grab pod logs selected by the service if there are some.
+	logs, err := getLogs(ctx, p.logs, rule.Service)
+	if err != nil {
+		log.Error().Err(err).Str("service", rule.Service).Msg("Unable to get logs")
+	}
+
+	return &Alert{
+		RuleID:   rule.ID,
+		Ingress:  rule.Ingress,
+		Service:  rule.Service,
+		Points:   points,
+		Logs:     logs,
+		Anomaly:  anomaly,
+		Baseline: baseline,
+	}, nil
+}
+
+// computeBaseline computes the baseline of the given anomaly rule evaluated on the from-to time range. It returns nil
+// when there are not enough data points to compute it.
+func (p *AnomalyProcessor) computeBaseline(rule *Rule, from, to time.Time) (*Baseline, error) {
+	anomaly := rule.Anomaly
+	granularity := anomaly.Granularity()
+
+	// The baseline time range ends right before the evaluated one, unless it is shifted by an offset.
+	baselineTo := from.Add(-granularity)
+	if anomaly.Offset > 0 {
+		baselineTo = to.Add(-anomaly.Offset)
+	}
+	baselineFrom := baselineTo.Add(-anomaly.Baseline)
+
+	dataPoints, err := findDataPoints(p.dataPoints, rule, anomaly.Table(), baselineFrom, baselineTo)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dataPoints) < minBaselinePoints {
+		log.Debug().
+			Str("rule_id", rule.ID).
+			Int("count", len(dataPoints)).
+			Msg("Not enough data points to compute the baseline")
+		return nil, nil
+	}
+
+	values := make([]float64, 0, len(dataPoints))
+	for _, datapoint := range dataPoints {
+		value, valueErr := getValue(anomaly.Metric, datapoint)
+		if valueErr != nil {
+			return nil, valueErr
+		}
+		values = append(values, value)
+	}
+
+	return newBaseline(values), nil
+}
+
+// newBaseline computes the mean and the standard deviation of the given values.
+func newBaseline(values []float64) *Baseline {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	variance /= float64(len(values))
+
+	return &Baseline{Mean: mean, StdDev: math.Sqrt(variance)}
+}
+
+// isAnomalous returns whether the given value deviates from the baseline in the direction of the anomaly rule. A
+// baseline without any variation makes any different value anomalous.
+func isAnomalous(anomaly *Anomaly, baseline Baseline, value float64) bool {
+	limit := anomaly.Deviations * baseline.StdDev
+
+	switch anomaly.Direction {
+	case AnomalyAbove:
+		return value-baseline.Mean > limit
+	case AnomalyBelow:
+		return baseline.Mean-value > limit
+	default:
+		return math.Abs(value-baseline.Mean) > limit
+	}
+}
+
+// AbsenceProcessor processes absence rules.
+type AbsenceProcessor struct {
+	dataPoints DataPointsFinder
+	logs       LogProvider
+
+	nowFunc func() time.Time
+}
+
+// NewAbsenceProcessor returns an absence processor.
+func NewAbsenceProcessor(dataPoints DataPointsFinder, logs LogProvider) *AbsenceProcessor {
+	return &AbsenceProcessor{
+		dataPoints: dataPoints,
+		logs:       logs,
+		nowFunc:    time.Now,
+	}
+}
+
+// SetClock sets the clock used to compute the time range of the rules, e.g. to compensate a skew with the
+// platform clock. It must be called before processing any rule.
+func (p *AbsenceProcessor) SetClock(now func() time.Time) {
+	p.nowFunc = now
+}
+
+// Process processes an absence rule returning an alert or nil.
+func (p *AbsenceProcessor) Process(ctx context.Context, rule *Rule) (*Alert, error) {
+	absence := rule.Absence
+	if absence.TimeRange <= 0 {
+		return nil, errors.New("invalid absence rule")
+	}
+
+	granularity := absence.Granularity()
+
+	// The granularity is subtracted to avoid capturing the last data point which is not yet complete.
+	to := p.nowFunc().UTC().Truncate(granularity).Add(-granularity)
+	from := to.Add(-absence.TimeRange)
+
+	dataPoints, err := findDataPoints(p.dataPoints, rule, absence.Table(), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dataPoints) > 0 {
+		return nil, nil
+	}
+
+	// Grab pod logs selected by the service if there are some, they may tell why the service is gone.
+	logs, err := getLogs(ctx, p.logs, rule.Service)
+	if err != nil {
+		log.Error().Err(err).Str("service", rule.Service).Msg("Unable to get logs")
+	}
+
+	return &Alert{
+		RuleID:  rule.ID,
+		Ingress: rule.Ingress,
+		Service: rule.Service,
+		Logs:    logs,
+		Absence: absence,
+	}, nil
+}
+
+// findDataPoints finds the data points of the ingress and/or service of the given rule.
+func findDataPoints(finder DataPointsFinder, rule *Rule, table string, from, to time.Time) (metrics.DataPoints, error) {
+	switch {
+	case rule.Ingress != "" && rule.Service != "":
+		return finder.FindByIngressAndService(table, rule.Ingress, rule.Service, from, to)
+	case rule.Service != "":
+		return finder.FindByService(table, rule.Service, from, to), nil
+	case rule.Ingress != "":
+		return finder.FindByIngress(table, rule.Ingress, from, to), nil
+	default:
+		return nil, errors.New("invalid rule")
+	}
+}
+
+func getLogs(ctx context.Context, logProvider LogProvider, service string) ([]byte, error) {
 	if service == "" {
 		return nil, nil
 	}
@@ -150,7 +397,7 @@ func (p *ThresholdProcessor) getLogs(ctx context.Context, service string) ([]byt
 		return nil, fmt.Errorf("invalid service name %q", service)
 	}
 
-	logs, err := p.logs.GetServiceLogs(ctx, parts[1], parts[0], logLines, logMaxLineLength)
+	logs, err := logProvider.GetServiceLogs(ctx, parts[1], parts[0], logLines, logMaxLineLength)
 	if err != nil {
 		return nil, fmt.Errorf("fetch service logs: %w", err)
 	}
@@ -163,6 +410,33 @@ func (p *ThresholdProcessor) getLogs(ctx context.Context, service string) ([]byt
 	return logs, nil
 }
 
+func getEvents(ctx context.Context, eventProvider EventProvider, service string, since time.Time) ([]byte, error) {
+	if service == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(service, "@")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid service name %q", service)
+	}
+
+	events, err := eventProvider.GetServiceEvents(ctx, parts[1], parts[0], since, maxEvents)
+	if err != nil {
+		return nil, fmt.Errorf("fetch service events: %w", err)
+	}
+
+	if events == nil {
+		return nil, nil
+	}
+
+	events, err = compress(events)
+	if err != nil {
+		return nil, fmt.Errorf("compress events: %w", err)
+	}
+
+	return events, nil
+}
+
 func getValue(metric string, pnt metrics.DataPoint) (float64, error) {
 	switch metric {
 	case "requestsPerSecond":
@@ -173,6 +447,12 @@ func getValue(metric string, pnt metrics.DataPoint) (float64, error) {
 		return pnt.RequestClientErrPerS, nil
 	case "averageResponseTime":
 		return pnt.AvgResponseTime, nil
+	case "responseTimeP50":
+		return pnt.ResponseTimeP50, nil
+	case "responseTimeP95":
+		return pnt.ResponseTimeP95, nil
+	case "responseTimeP99":
+		return pnt.ResponseTimeP99, nil
 	default:
 		return 0, fmt.Errorf("invalid metric type: %s", metric)
 	}
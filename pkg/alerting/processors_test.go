@@ -20,10 +20,12 @@ package alerting
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
 )
@@ -36,6 +38,11 @@ func TestThresholdProcessor_Process(t *testing.T) {
 		0x2c, 0x4a, 0x55, 0xc8, 0xad, 0x54, 0xc8, 0xc9, 0x4f, 0x2f, 0x06, 0x04, 0x00, 0x00, 0xff, 0xff,
 		0x20, 0x9a, 0x9e, 0x8d, 0x10, 0x00, 0x00, 0x00,
 	}
+	serviceEvents := []byte("2021-01-01T08:18:00Z Unhealthy pod/pod-1: Readiness probe failed\n")
+	serviceCompressedEvents, err := compress(serviceEvents)
+	require.NoError(t, err)
+	compressedLogs, err := compress(serviceLogs)
+	require.NoError(t, err)
 
 	type expected struct {
 		alert      *Alert
@@ -47,6 +54,7 @@ func TestThresholdProcessor_Process(t *testing.T) {
 		rule           *Rule
 		dataPointsMock func(testing.TB) *dataPointsFinderMock
 		logsMock       func(testing.TB) *logProviderMock
+		eventsMock     func(testing.TB) *eventProviderMock
 		expected       expected
 	}{
 		{
@@ -126,6 +134,76 @@ func TestThresholdProcessor_Process(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "Alert: Rule with service attaches pod events",
+			rule: &Rule{
+				ID:      "rule-1",
+				Service: "service-1@myns",
+				Threshold: &Threshold{
+					Metric:     "requestsPerSecond",
+					Condition:  ThresholdCondition{Above: true, Value: 100},
+					Occurrence: 1,
+					TimeRange:  5 * time.Minute,
+				},
+			},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				view := newDataPointsFinderMock(tb)
+				view.
+					OnFindByService("1m", "service-1@myns",
+						time.Date(2021, 1, 1, 8, 15, 0, 0, time.UTC),
+						time.Date(2021, 1, 1, 8, 20, 0, 0, time.UTC),
+					).
+					TypedReturns(metrics.DataPoints{
+						{Timestamp: now.Add(-3 * time.Minute).Unix(), ReqPerS: 120},
+					}).
+					Once()
+
+				return view
+			},
+			logsMock: func(tb testing.TB) *logProviderMock {
+				tb.Helper()
+
+				logs := newLogProviderMock(tb)
+				logs.
+					OnGetServiceLogs("myns", "service-1", logLines, logMaxLineLength).
+					TypedReturns(serviceLogs, nil).
+					Once()
+
+				return logs
+			},
+			eventsMock: func(tb testing.TB) *eventProviderMock {
+				tb.Helper()
+
+				events := newEventProviderMock(tb)
+				events.
+					OnGetServiceEvents("myns", "service-1", time.Date(2021, 1, 1, 8, 15, 0, 0, time.UTC), maxEvents).
+					TypedReturns(serviceEvents, nil).
+					Once()
+
+				return events
+			},
+			expected: expected{
+				requireErr: require.NoError,
+				alert: &Alert{
+					RuleID:  "rule-1",
+					Ingress: "",
+					Service: "service-1@myns",
+					Points: []Point{
+						{Timestamp: now.Add(-3 * time.Minute).Unix(), Value: 120},
+					},
+					Logs:   compressedLogs,
+					Events: serviceCompressedEvents,
+					Threshold: &Threshold{
+						Metric:     "requestsPerSecond",
+						Condition:  ThresholdCondition{Above: true, Value: 100},
+						Occurrence: 1,
+						TimeRange:  5 * time.Minute,
+					},
+				},
+			},
+		},
 		{
 			desc: "Alert: Rule with service needs 1 occurrence: rule matches 2 data point",
 			rule: &Rule{
@@ -809,7 +887,18 @@ func TestThresholdProcessor_Process(t *testing.T) {
 			logs := test.logsMock(t)
 			view := test.dataPointsMock(t)
 
-			threshProc := NewThresholdProcessor(view, logs)
+			var events *eventProviderMock
+			if test.eventsMock != nil {
+				events = test.eventsMock(t)
+			} else {
+				events = newEventProviderMock(t)
+				events.
+					OnGetServiceEventsRaw(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					TypedReturns(nil, nil).
+					Maybe()
+			}
+
+			threshProc := NewThresholdProcessor(view, logs, events)
 			threshProc.nowFunc = func() time.Time { return now }
 
 			alert, err := threshProc.Process(context.Background(), test.rule)
@@ -856,6 +945,24 @@ func TestGetValue(t *testing.T) {
 			point:    metrics.DataPoint{AvgResponseTime: 100},
 			expected: expected{value: 100},
 		},
+		{
+			desc:     "with 50th percentile response time metric",
+			metric:   "responseTimeP50",
+			point:    metrics.DataPoint{ResponseTimeP50: 100},
+			expected: expected{value: 100},
+		},
+		{
+			desc:     "with 95th percentile response time metric",
+			metric:   "responseTimeP95",
+			point:    metrics.DataPoint{ResponseTimeP95: 100},
+			expected: expected{value: 100},
+		},
+		{
+			desc:     "with 99th percentile response time metric",
+			metric:   "responseTimeP99",
+			point:    metrics.DataPoint{ResponseTimeP99: 100},
+			expected: expected{value: 100},
+		},
 		{
 			desc:   "with unknown metric",
 			metric: "requestsPerPotatoes",
@@ -895,3 +1002,377 @@ func TestGetValue(t *testing.T) {
 		})
 	}
 }
+
+func TestAnomalyProcessor_Process(t *testing.T) {
+	now := time.Date(2021, 1, 1, 8, 21, 43, 0, time.UTC)
+
+	baselinePoints := metrics.DataPoints{
+		{Timestamp: now.Add(-70 * time.Minute).Unix(), ReqPerS: 10},
+		{Timestamp: now.Add(-60 * time.Minute).Unix(), ReqPerS: 12},
+		{Timestamp: now.Add(-50 * time.Minute).Unix(), ReqPerS: 11},
+		{Timestamp: now.Add(-40 * time.Minute).Unix(), ReqPerS: 9},
+		{Timestamp: now.Add(-30 * time.Minute).Unix(), ReqPerS: 13},
+	}
+	points := metrics.DataPoints{
+		{Timestamp: now.Add(-20 * time.Minute).Unix(), ReqPerS: 12},
+		{Timestamp: now.Add(-10 * time.Minute).Unix(), ReqPerS: 30},
+	}
+
+	tests := []struct {
+		desc           string
+		anomaly        Anomaly
+		dataPointsMock func(testing.TB) *dataPointsFinderMock
+		wantAlert      *Alert
+		wantErr        bool
+	}{
+		{
+			desc: "Alert: a point deviates from the preceding baseline",
+			anomaly: Anomaly{
+				Metric:     "requestsPerSecond",
+				Deviations: 3,
+				Occurrence: 1,
+				TimeRange:  20 * time.Minute,
+				Baseline:   30 * time.Minute,
+			},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				view := newDataPointsFinderMock(tb)
+				view.
+					OnFindByIngress("10m", "ing@ns",
+						time.Date(2021, 1, 1, 7, 50, 0, 0, time.UTC),
+						time.Date(2021, 1, 1, 8, 10, 0, 0, time.UTC),
+					).
+					TypedReturns(points).
+					Once()
+				view.
+					OnFindByIngress("10m", "ing@ns",
+						time.Date(2021, 1, 1, 7, 10, 0, 0, time.UTC),
+						time.Date(2021, 1, 1, 7, 40, 0, 0, time.UTC),
+					).
+					TypedReturns(baselinePoints).
+					Once()
+
+				return view
+			},
+			wantAlert: &Alert{
+				RuleID:  "rule-1",
+				Ingress: "ing@ns",
+				Points: []Point{
+					{Timestamp: now.Add(-20 * time.Minute).Unix(), Value: 12},
+					{Timestamp: now.Add(-10 * time.Minute).Unix(), Value: 30},
+				},
+				Baseline: &Baseline{Mean: 11, StdDev: math.Sqrt(2)},
+			},
+		},
+		{
+			desc: "No alert: no point deviates below the baseline",
+			anomaly: Anomaly{
+				Metric:     "requestsPerSecond",
+				Deviations: 3,
+				Direction:  AnomalyBelow,
+				Occurrence: 1,
+				TimeRange:  20 * time.Minute,
+				Baseline:   30 * time.Minute,
+			},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				view := newDataPointsFinderMock(tb)
+				view.
+					OnFindByIngressRaw("10m", "ing@ns", mock.Anything, mock.Anything).
+					TypedReturns(points).
+					Once()
+				view.
+					OnFindByIngressRaw("10m", "ing@ns", mock.Anything, mock.Anything).
+					TypedReturns(baselinePoints).
+					Once()
+
+				return view
+			},
+		},
+		{
+			desc: "No alert: not enough occurrences",
+			anomaly: Anomaly{
+				Metric:     "requestsPerSecond",
+				Deviations: 3,
+				Occurrence: 2,
+				TimeRange:  20 * time.Minute,
+				Baseline:   30 * time.Minute,
+			},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				view := newDataPointsFinderMock(tb)
+				view.
+					OnFindByIngressRaw("10m", "ing@ns", mock.Anything, mock.Anything).
+					TypedReturns(points).
+					Once()
+				view.
+					OnFindByIngressRaw("10m", "ing@ns", mock.Anything, mock.Anything).
+					TypedReturns(baselinePoints).
+					Once()
+
+				return view
+			},
+		},
+		{
+			desc: "No alert: not enough baseline points",
+			anomaly: Anomaly{
+				Metric:     "requestsPerSecond",
+				Deviations: 3,
+				Occurrence: 1,
+				TimeRange:  20 * time.Minute,
+				Baseline:   30 * time.Minute,
+			},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				view := newDataPointsFinderMock(tb)
+				view.
+					OnFindByIngressRaw("10m", "ing@ns", mock.Anything, mock.Anything).
+					TypedReturns(points).
+					Once()
+				view.
+					OnFindByIngressRaw("10m", "ing@ns", mock.Anything, mock.Anything).
+					TypedReturns(baselinePoints[:2]).
+					Once()
+
+				return view
+			},
+		},
+		{
+			desc: "Alert: a point deviates from the baseline of the same time last week",
+			anomaly: Anomaly{
+				Metric:     "requestsPerSecond",
+				Deviations: 3,
+				Direction:  AnomalyAbove,
+				Occurrence: 1,
+				TimeRange:  2 * time.Hour,
+				Baseline:   4 * time.Hour,
+				Offset:     7 * 24 * time.Hour,
+			},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				view := newDataPointsFinderMock(tb)
+				view.
+					OnFindByIngress("1h", "ing@ns",
+						time.Date(2021, 1, 1, 5, 0, 0, 0, time.UTC),
+						time.Date(2021, 1, 1, 7, 0, 0, 0, time.UTC),
+					).
+					TypedReturns(points).
+					Once()
+				view.
+					OnFindByIngress("1h", "ing@ns",
+						time.Date(2020, 12, 25, 3, 0, 0, 0, time.UTC),
+						time.Date(2020, 12, 25, 7, 0, 0, 0, time.UTC),
+					).
+					TypedReturns(baselinePoints).
+					Once()
+
+				return view
+			},
+			wantAlert: &Alert{
+				RuleID:  "rule-1",
+				Ingress: "ing@ns",
+				Points: []Point{
+					{Timestamp: now.Add(-20 * time.Minute).Unix(), Value: 12},
+					{Timestamp: now.Add(-10 * time.Minute).Unix(), Value: 30},
+				},
+				Baseline: &Baseline{Mean: 11, StdDev: math.Sqrt(2)},
+			},
+		},
+		{
+			desc: "Invalid rule without baseline",
+			anomaly: Anomaly{
+				Metric:     "requestsPerSecond",
+				Deviations: 3,
+				Occurrence: 1,
+				TimeRange:  20 * time.Minute,
+			},
+			dataPointsMock: newDataPointsFinderMock,
+			wantErr:        true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rule := &Rule{ID: "rule-1", Ingress: "ing@ns", Anomaly: &test.anomaly}
+
+			p := NewAnomalyProcessor(test.dataPointsMock(t), newLogProviderMock(t))
+			p.SetClock(func() time.Time { return now })
+
+			got, err := p.Process(context.Background(), rule)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if test.wantAlert != nil {
+				test.wantAlert.Anomaly = &test.anomaly
+			}
+			assert.Equal(t, test.wantAlert, got)
+		})
+	}
+}
+
+func TestAnomaly_Table(t *testing.T) {
+	tests := []struct {
+		desc    string
+		anomaly Anomaly
+		want    string
+	}{
+		{
+			desc:    "short baseline",
+			anomaly: Anomaly{TimeRange: 3 * time.Minute, Baseline: 5 * time.Minute},
+			want:    "1m",
+		},
+		{
+			desc:    "baseline older than the minute table",
+			anomaly: Anomaly{TimeRange: 3 * time.Minute, Baseline: 30 * time.Minute},
+			want:    "10m",
+		},
+		{
+			desc:    "same time last week",
+			anomaly: Anomaly{TimeRange: 30 * time.Minute, Baseline: time.Hour, Offset: 7 * 24 * time.Hour},
+			want:    "1h",
+		},
+		{
+			desc:    "same time last month",
+			anomaly: Anomaly{TimeRange: 30 * time.Minute, Baseline: time.Hour, Offset: 30 * 24 * time.Hour},
+			want:    "1d",
+		},
+		{
+			desc:    "long time range",
+			anomaly: Anomaly{TimeRange: 2 * time.Hour, Baseline: 10 * time.Minute},
+			want:    "1h",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, test.anomaly.Table())
+		})
+	}
+}
+
+func TestAbsenceProcessor_Process(t *testing.T) {
+	now := time.Date(2021, 1, 1, 8, 21, 43, 0, time.UTC)
+
+	serviceLogs, err := compress([]byte("here are my logs"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc           string
+		rule           *Rule
+		dataPointsMock func(testing.TB) *dataPointsFinderMock
+		logsMock       func(testing.TB) *logProviderMock
+		wantAlert      *Alert
+		wantErr        bool
+	}{
+		{
+			desc: "No alert: the service reports data points",
+			rule: &Rule{ID: "rule-1", Service: "service-1@myns", Absence: &Absence{TimeRange: 5 * time.Minute}},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				return newDataPointsFinderMock(tb).
+					OnFindByService("1m", "service-1@myns",
+						time.Date(2021, 1, 1, 8, 15, 0, 0, time.UTC),
+						time.Date(2021, 1, 1, 8, 20, 0, 0, time.UTC),
+					).
+					TypedReturns(metrics.DataPoints{{Timestamp: now.Add(-2 * time.Minute).Unix()}}).
+					Once().
+					Parent
+			},
+			logsMock: newLogProviderMock,
+		},
+		{
+			desc: "Alert: the service reports no data point",
+			rule: &Rule{ID: "rule-1", Service: "service-1@myns", Absence: &Absence{TimeRange: 5 * time.Minute}},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				return newDataPointsFinderMock(tb).
+					OnFindByService("1m", "service-1@myns",
+						time.Date(2021, 1, 1, 8, 15, 0, 0, time.UTC),
+						time.Date(2021, 1, 1, 8, 20, 0, 0, time.UTC),
+					).
+					TypedReturns(nil).
+					Once().
+					Parent
+			},
+			logsMock: func(tb testing.TB) *logProviderMock {
+				tb.Helper()
+
+				return newLogProviderMock(tb).
+					OnGetServiceLogs("myns", "service-1", logLines, logMaxLineLength).
+					TypedReturns([]byte("here are my logs"), nil).
+					Once().
+					Parent
+			},
+			wantAlert: &Alert{
+				RuleID:  "rule-1",
+				Service: "service-1@myns",
+				Logs:    serviceLogs,
+				Absence: &Absence{TimeRange: 5 * time.Minute},
+			},
+		},
+		{
+			desc: "Alert: the ingress reports no data point for hours",
+			rule: &Rule{ID: "rule-1", Ingress: "ing@ns", Absence: &Absence{TimeRange: 3 * time.Hour}},
+			dataPointsMock: func(tb testing.TB) *dataPointsFinderMock {
+				tb.Helper()
+
+				return newDataPointsFinderMock(tb).
+					OnFindByIngress("1h", "ing@ns",
+						time.Date(2021, 1, 1, 4, 0, 0, 0, time.UTC),
+						time.Date(2021, 1, 1, 7, 0, 0, 0, time.UTC),
+					).
+					TypedReturns(nil).
+					Once().
+					Parent
+			},
+			logsMock: newLogProviderMock,
+			wantAlert: &Alert{
+				RuleID:  "rule-1",
+				Ingress: "ing@ns",
+				Absence: &Absence{TimeRange: 3 * time.Hour},
+			},
+		},
+		{
+			desc:           "Invalid rule without time range",
+			rule:           &Rule{ID: "rule-1", Ingress: "ing@ns", Absence: &Absence{}},
+			dataPointsMock: newDataPointsFinderMock,
+			logsMock:       newLogProviderMock,
+			wantErr:        true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewAbsenceProcessor(test.dataPointsMock(t), test.logsMock(t))
+			p.SetClock(func() time.Time { return now })
+
+			got, err := p.Process(context.Background(), test.rule)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantAlert, got)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hublistersv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Alert labels silences can match.
+const (
+	LabelRule      = "rule"
+	LabelIngress   = "ingress"
+	LabelService   = "service"
+	LabelNamespace = "namespace"
+)
+
+// Silencer silences alerts according to the AlertSilence resources of the cluster.
+type Silencer struct {
+	silences hublistersv1alpha1.AlertSilenceLister
+
+	nowFunc func() time.Time
+}
+
+// NewSilencer returns a silencer reading silences from the given lister.
+func NewSilencer(silences hublistersv1alpha1.AlertSilenceLister) *Silencer {
+	return &Silencer{
+		silences: silences,
+		nowFunc:  time.Now,
+	}
+}
+
+// SetClock sets the clock used to determine whether silences are active.
+func (s *Silencer) SetClock(nowFunc func() time.Time) {
+	s.nowFunc = nowFunc
+}
+
+// Silenced returns the name of the active silence matching the given alert, if any.
+func (s *Silencer) Silenced(alert Alert) (string, bool) {
+	silences, err := s.silences.List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to list alert silences")
+		return "", false
+	}
+
+	now := s.nowFunc()
+
+	for _, silence := range silences {
+		if !isActive(silence.Spec, now) {
+			continue
+		}
+
+		ok, err := matches(silence.Spec.Matchers, alert)
+		if err != nil {
+			log.Error().Err(err).Str("silence", silence.Name).Msg("Invalid alert silence")
+			continue
+		}
+
+		if ok {
+			return silence.Name, true
+		}
+	}
+
+	return "", false
+}
+
+func isActive(spec hubv1alpha1.AlertSilenceSpec, now time.Time) bool {
+	if spec.StartsAt != nil && now.Before(spec.StartsAt.Time) {
+		return false
+	}
+
+	return now.Before(spec.EndsAt.Time)
+}
+
+// matches returns whether the given alert matches all the given matchers. A silence without matchers matches no alert,
+// so that a misconfigured silence can't mute every alert.
+func matches(matchers []hubv1alpha1.AlertMatcher, alert Alert) (bool, error) {
+	if len(matchers) == 0 {
+		return false, nil
+	}
+
+	for _, matcher := range matchers {
+		value, err := labelValue(alert, matcher.Label)
+		if err != nil {
+			return false, err
+		}
+
+		if !matcher.Regex {
+			if value != matcher.Value {
+				return false, nil
+			}
+			continue
+		}
+
+		re, err := regexp.Compile("^(?:" + matcher.Value + ")$")
+		if err != nil {
+			return false, fmt.Errorf("compile regular expression of label %q: %w", matcher.Label, err)
+		}
+
+		if !re.MatchString(value) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func labelValue(alert Alert, label string) (string, error) {
+	switch label {
+	case LabelRule:
+		return alert.RuleID, nil
+	case LabelIngress:
+		return alert.Ingress, nil
+	case LabelService:
+		return alert.Service, nil
+	case LabelNamespace:
+		// Services are identified by "name@namespace" and ingresses by "name@namespace.kind.group".
+		id := alert.Service
+		if id == "" {
+			id = alert.Ingress
+		}
+
+		_, namespace, _ := strings.Cut(id, "@")
+		namespace, _, _ = strings.Cut(namespace, ".")
+
+		return namespace, nil
+	default:
+		return "", fmt.Errorf("unsupported label %q", label)
+	}
+}
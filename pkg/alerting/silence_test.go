@@ -0,0 +1,152 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hublistersv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestSilencer_Silenced(t *testing.T) {
+	now := time.Date(2023, 5, 12, 10, 0, 0, 0, time.UTC)
+
+	alert := Alert{
+		RuleID:  "rule-1",
+		Ingress: "web@my-ns.ingress.networking.k8s.io",
+		Service: "whoami@my-ns",
+	}
+
+	tests := []struct {
+		desc        string
+		spec        hubv1alpha1.AlertSilenceSpec
+		wantSilence bool
+	}{
+		{
+			desc: "matching service",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				EndsAt:   metav1.NewTime(now.Add(time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{{Label: LabelService, Value: "whoami@my-ns"}},
+			},
+			wantSilence: true,
+		},
+		{
+			desc: "matching all matchers",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				EndsAt: metav1.NewTime(now.Add(time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{
+					{Label: LabelRule, Value: "rule-1"},
+					{Label: LabelIngress, Value: "web@.*", Regex: true},
+					{Label: LabelNamespace, Value: "my-ns"},
+				},
+			},
+			wantSilence: true,
+		},
+		{
+			desc: "not matching all matchers",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				EndsAt: metav1.NewTime(now.Add(time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{
+					{Label: LabelRule, Value: "rule-1"},
+					{Label: LabelNamespace, Value: "other-ns"},
+				},
+			},
+		},
+		{
+			desc: "regular expression matching part of the value",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				EndsAt:   metav1.NewTime(now.Add(time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{{Label: LabelService, Value: "who", Regex: true}},
+			},
+		},
+		{
+			desc: "invalid regular expression",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				EndsAt:   metav1.NewTime(now.Add(time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{{Label: LabelService, Value: "(", Regex: true}},
+			},
+		},
+		{
+			desc: "unsupported label",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				EndsAt:   metav1.NewTime(now.Add(time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{{Label: "pod", Value: "whoami"}},
+			},
+		},
+		{
+			desc: "no matchers",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				EndsAt: metav1.NewTime(now.Add(time.Hour)),
+			},
+		},
+		{
+			desc: "not started yet",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				StartsAt: &metav1.Time{Time: now.Add(time.Minute)},
+				EndsAt:   metav1.NewTime(now.Add(time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{{Label: LabelService, Value: "whoami@my-ns"}},
+			},
+		},
+		{
+			desc: "started",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				StartsAt: &metav1.Time{Time: now.Add(-time.Minute)},
+				EndsAt:   metav1.NewTime(now.Add(time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{{Label: LabelService, Value: "whoami@my-ns"}},
+			},
+			wantSilence: true,
+		},
+		{
+			desc: "ended",
+			spec: hubv1alpha1.AlertSilenceSpec{
+				EndsAt:   metav1.NewTime(now),
+				Matchers: []hubv1alpha1.AlertMatcher{{Label: LabelService, Value: "whoami@my-ns"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			err := indexer.Add(&hubv1alpha1.AlertSilence{
+				ObjectMeta: metav1.ObjectMeta{Name: "maintenance"},
+				Spec:       test.spec,
+			})
+			require.NoError(t, err)
+
+			silencer := NewSilencer(hublistersv1alpha1.NewAlertSilenceLister(indexer))
+			silencer.SetClock(func() time.Time { return now })
+
+			silence, silenced := silencer.Silenced(alert)
+
+			assert.Equal(t, test.wantSilence, silenced)
+			if test.wantSilence {
+				assert.Equal(t, "maintenance", silence)
+			}
+		})
+	}
+}
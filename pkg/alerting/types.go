@@ -23,6 +23,8 @@ import "time"
 const (
 	UnknownType   = "unknown"
 	ThresholdType = "threshold"
+	AnomalyType   = "anomaly"
+	AbsenceType   = "absence"
 )
 
 // Rule defines evaluation configuration for alerting
@@ -33,14 +35,22 @@ type Rule struct {
 	Service string `json:"service"`
 
 	Threshold *Threshold `json:"threshold"`
+	Anomaly   *Anomaly   `json:"anomaly,omitempty"`
+	Absence   *Absence   `json:"absence,omitempty"`
 }
 
-// Type returns the rule type (for now, only threshold).
+// Type returns the rule type.
 func (r *Rule) Type() string {
-	if r.Threshold != nil {
+	switch {
+	case r.Threshold != nil:
 		return ThresholdType
+	case r.Anomaly != nil:
+		return AnomalyType
+	case r.Absence != nil:
+		return AbsenceType
+	default:
+		return UnknownType
 	}
-	return UnknownType
 }
 
 // Threshold contains a threshold and its direction.
@@ -53,26 +63,108 @@ type Threshold struct {
 
 // Table returns the metrics table containing the data points.
 func (t Threshold) Table() string {
-	switch {
-	case t.TimeRange > 24*time.Hour:
+	return table(t.TimeRange)
+}
+
+// Granularity returns the metrics point granularity.
+func (t Threshold) Granularity() time.Duration {
+	return granularity(t.TimeRange)
+}
+
+// Anomaly directions.
+const (
+	AnomalyAbove = "above"
+	AnomalyBelow = "below"
+)
+
+// Anomaly compares the data points of a time range against a baseline made of the data points of a preceding time
+// range: points deviating from the baseline mean by more than the given number of standard deviations are anomalous.
+type Anomaly struct {
+	Metric string `json:"metric"`
+	// Deviations is the number of standard deviations from the baseline mean a point must exceed to be anomalous.
+	Deviations float64 `json:"deviations"`
+	// Direction restricts the anomalies to the points above or below the baseline. Both are anomalous when empty.
+	Direction  string        `json:"direction,omitempty"`
+	Occurrence int           `json:"occurrence"`
+	TimeRange  time.Duration `json:"timeRange"`
+	// Baseline is the duration of the time range the baseline is computed on.
+	Baseline time.Duration `json:"baseline"`
+	// Offset shifts the baseline time range back from the evaluated one, e.g. a week to compare with the same time
+	// last week. The baseline precedes the evaluated time range when not set.
+	Offset time.Duration `json:"offset,omitempty"`
+}
+
+// Table returns the metrics table containing the data points.
+func (a Anomaly) Table() string {
+	switch a.Granularity() {
+	case 24 * time.Hour:
 		return "1d"
-	case t.TimeRange > time.Hour:
+	case time.Hour:
 		return "1h"
-	case t.TimeRange > 10*time.Minute:
+	case 10 * time.Minute:
 		return "10m"
 	default:
 		return "1m"
 	}
 }
 
+// Granularity returns the metrics point granularity. It is coarser than the one of a threshold rule on the same
+// time range when the finer tables don't hold the data points of the baseline anymore.
+func (a Anomaly) Granularity() time.Duration {
+	var retained time.Duration
+	switch span := a.TimeRange + a.Baseline + a.Offset; {
+	case span > 8*24*time.Hour:
+		retained = 24 * time.Hour
+	case span > time.Hour:
+		retained = time.Hour
+	case span > 10*time.Minute:
+		retained = 10 * time.Minute
+	default:
+		retained = time.Minute
+	}
+
+	if gran := granularity(a.TimeRange); gran > retained {
+		return gran
+	}
+	return retained
+}
+
+// Absence fires when the ingress and/or service of a rule report no data point for a time range, as it happens when
+// metrics can't be scraped anymore or when a backend is down and doesn't get any traffic.
+type Absence struct {
+	TimeRange time.Duration `json:"timeRange"`
+}
+
+// Table returns the metrics table containing the data points.
+func (a Absence) Table() string {
+	return table(a.TimeRange)
+}
+
 // Granularity returns the metrics point granularity.
-func (t Threshold) Granularity() time.Duration {
+func (a Absence) Granularity() time.Duration {
+	return granularity(a.TimeRange)
+}
+
+func table(timeRange time.Duration) string {
+	switch {
+	case timeRange > 24*time.Hour:
+		return "1d"
+	case timeRange > time.Hour:
+		return "1h"
+	case timeRange > 10*time.Minute:
+		return "10m"
+	default:
+		return "1m"
+	}
+}
+
+func granularity(timeRange time.Duration) time.Duration {
 	switch {
-	case t.TimeRange > 24*time.Hour:
+	case timeRange > 24*time.Hour:
 		return 24 * time.Hour
-	case t.TimeRange > time.Hour:
+	case timeRange > time.Hour:
 		return time.Hour
-	case t.TimeRange > 10*time.Minute:
+	case timeRange > 10*time.Minute:
 		return 10 * time.Minute
 	default:
 		return time.Minute
@@ -87,12 +179,24 @@ type ThresholdCondition struct {
 
 // Alert contains alert information.
 type Alert struct {
-	RuleID    string     `json:"ruleId"`
-	Ingress   string     `json:"ingress"`
-	Service   string     `json:"service"`
-	Points    []Point    `json:"points"`
-	Logs      []byte     `json:"logs"`
+	RuleID  string  `json:"ruleId"`
+	Ingress string  `json:"ingress"`
+	Service string  `json:"service"`
+	Points  []Point `json:"points"`
+	Logs    []byte  `json:"logs"`
+	// Events holds the compressed Kubernetes events of the pods of the service, one per line.
+	Events    []byte     `json:"events,omitempty"`
 	Threshold *Threshold `json:"threshold"`
+	Anomaly   *Anomaly   `json:"anomaly,omitempty"`
+	Absence   *Absence   `json:"absence,omitempty"`
+	// Baseline holds the baseline the points of an anomaly alert have been compared to.
+	Baseline *Baseline `json:"baseline,omitempty"`
+}
+
+// Baseline contains the statistics of the baseline of an anomaly rule.
+type Baseline struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
 }
 
 // Point contains a point and its timestamp.
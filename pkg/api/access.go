@@ -43,6 +43,8 @@ type Access struct {
 
 // Resource builds the v1alpha1 APIAccess resource.
 func (a *Access) Resource() (*hubv1alpha1.APIAccess, error) {
+	syncedAt := metav1.Now()
+
 	access := &hubv1alpha1.APIAccess{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "hub.traefik.io/v1alpha1",
@@ -59,7 +61,11 @@ func (a *Access) Resource() (*hubv1alpha1.APIAccess, error) {
 		},
 		Status: hubv1alpha1.APIAccessStatus{
 			Version:  a.Version,
-			SyncedAt: metav1.Now(),
+			SyncedAt: syncedAt,
+			Conditions: []metav1.Condition{
+				syncedCondition(syncedAt),
+				readyCondition(syncedAt),
+			},
 		},
 	}
 
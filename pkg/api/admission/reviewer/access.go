@@ -27,6 +27,7 @@ import (
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type accessService interface {
@@ -107,7 +108,7 @@ func (a *Access) reviewCreateOperation(ctx context.Context, accessCRD *hubv1alph
 		return nil, fmt.Errorf("create APIAccess: %w", err)
 	}
 
-	return a.buildPatches(createdAccess)
+	return a.buildPatches(createdAccess, nil)
 }
 
 func (a *Access) reviewUpdateOperation(ctx context.Context, oldAccess, newAccess *hubv1alpha1.APIAccess) ([]byte, error) {
@@ -125,7 +126,7 @@ func (a *Access) reviewUpdateOperation(ctx context.Context, oldAccess, newAccess
 		return nil, fmt.Errorf("update APIAccess: %w", err)
 	}
 
-	return a.buildPatches(updateAccess)
+	return a.buildPatches(updateAccess, oldAccess.Status.Conditions)
 }
 
 func (a *Access) reviewDeleteOperation(ctx context.Context, oldAccess *hubv1alpha1.APIAccess) ([]byte, error) {
@@ -137,12 +138,15 @@ func (a *Access) reviewDeleteOperation(ctx context.Context, oldAccess *hubv1alph
 	return nil, nil
 }
 
-func (a *Access) buildPatches(obj *api.Access) ([]byte, error) {
+func (a *Access) buildPatches(obj *api.Access, conditions []metav1.Condition) ([]byte, error) {
 	res, err := obj.Resource()
 	if err != nil {
 		return nil, fmt.Errorf("build resource: %w", err)
 	}
 
+	// Keep the conditions set by the agent, as they only transition when their status changes.
+	res.Status.Conditions = hubv1alpha1.MergeConditions(conditions, res.Status.Conditions...)
+
 	return json.Marshal([]patch{
 		{Op: "replace", Path: "/status", Value: res.Status},
 	})
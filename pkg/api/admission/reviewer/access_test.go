@@ -85,9 +85,10 @@ func TestAccess_Review_createOperation(t *testing.T) {
 			},
 			wantPatch: mustMarshal(t, []patch{
 				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIAccessStatus{
-					Version:  "version-1",
-					SyncedAt: now,
-					Hash:     "sWyFgExjawaHl612Q9vhkA==",
+					Version:    "version-1",
+					SyncedAt:   now,
+					Hash:       "sWyFgExjawaHl612Q9vhkA==",
+					Conditions: []metav1.Condition{syncedCondition(now), readyCondition(now)},
 				}},
 			}),
 		},
@@ -222,9 +223,10 @@ func TestAccess_Review_updateOperation(t *testing.T) {
 			},
 			wantPatch: mustMarshal(t, []patch{
 				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIAccessStatus{
-					Version:  "version-2",
-					SyncedAt: now,
-					Hash:     "9MVYBofD2Nshp3qEtHf+eg==",
+					Version:    "version-2",
+					SyncedAt:   now,
+					Hash:       "9MVYBofD2Nshp3qEtHf+eg==",
+					Conditions: []metav1.Condition{syncedCondition(now), readyCondition(now)},
 				}},
 			}),
 		},
@@ -27,6 +27,7 @@ import (
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type apiService interface {
@@ -107,9 +108,13 @@ func (a *API) reviewCreateOperation(ctx context.Context, apiCRD *hubv1alpha1.API
 			Name: apiCRD.Spec.Service.Name,
 			Port: int(apiCRD.Spec.Service.Port.Number),
 			OpenAPISpec: platform.OpenAPISpec{
-				URL:  apiCRD.Spec.Service.OpenAPISpec.URL,
-				Path: apiCRD.Spec.Service.OpenAPISpec.Path,
+				URL:     apiCRD.Spec.Service.OpenAPISpec.URL,
+				Path:    apiCRD.Spec.Service.OpenAPISpec.Path,
+				Headers: apiCRD.Spec.Service.OpenAPISpec.Headers,
+				Auth:    api.OpenAPISpecAuthFromCRD(apiCRD.Spec.Service.OpenAPISpec.Auth),
+				CA:      (*api.SecretReference)(apiCRD.Spec.Service.OpenAPISpec.CA),
 			},
+			ExternalURL: apiCRD.Spec.Service.ExternalURL,
 		},
 	}
 
@@ -117,12 +122,42 @@ func (a *API) reviewCreateOperation(ctx context.Context, apiCRD *hubv1alpha1.API
 		createReq.Service.OpenAPISpec.Port = int(apiCRD.Spec.Service.OpenAPISpec.Port.Number)
 	}
 
+	if apiCRD.Spec.VersionHeader != nil {
+		createReq.VersionHeader = &api.VersionHeader{
+			Name:  apiCRD.Spec.VersionHeader.Name,
+			Value: apiCRD.Spec.VersionHeader.Value,
+		}
+	}
+
+	createReq.Matchers = api.MatchersFromCRD(apiCRD.Spec.Matchers)
+
+	if apiCRD.Spec.Deprecation != nil {
+		createReq.Deprecation = &api.Deprecation{}
+		if apiCRD.Spec.Deprecation.Sunset != nil {
+			createReq.Deprecation.Sunset = &apiCRD.Spec.Deprecation.Sunset.Time
+		}
+	}
+
+	if apiCRD.Spec.Sandbox != nil {
+		createReq.Sandbox = &api.Sandbox{
+			Service: api.SandboxService{
+				Name: apiCRD.Spec.Sandbox.Service.Name,
+				Port: int(apiCRD.Spec.Sandbox.Service.Port.Number),
+			},
+		}
+	}
+
+	createReq.MaxRequestBodyBytes = apiCRD.Spec.MaxRequestBodyBytes
+	createReq.AllowedMethods = apiCRD.Spec.AllowedMethods
+	createReq.ValidateRequests = apiCRD.Spec.ValidateRequests
+	createReq.CORS = apiCRD.Spec.CORS
+
 	createdAPI, err := a.platform.CreateAPI(ctx, createReq)
 	if err != nil {
 		return nil, fmt.Errorf("create API: %w", err)
 	}
 
-	return a.buildPatches(createdAPI)
+	return a.buildPatches(createdAPI, nil)
 }
 
 func (a *API) reviewUpdateOperation(ctx context.Context, oldAPI, newAPI *hubv1alpha1.API) ([]byte, error) {
@@ -139,9 +174,13 @@ func (a *API) reviewUpdateOperation(ctx context.Context, oldAPI, newAPI *hubv1al
 			Name: newAPI.Spec.Service.Name,
 			Port: int(newAPI.Spec.Service.Port.Number),
 			OpenAPISpec: platform.OpenAPISpec{
-				URL:  newAPI.Spec.Service.OpenAPISpec.URL,
-				Path: newAPI.Spec.Service.OpenAPISpec.Path,
+				URL:     newAPI.Spec.Service.OpenAPISpec.URL,
+				Path:    newAPI.Spec.Service.OpenAPISpec.Path,
+				Headers: newAPI.Spec.Service.OpenAPISpec.Headers,
+				Auth:    api.OpenAPISpecAuthFromCRD(newAPI.Spec.Service.OpenAPISpec.Auth),
+				CA:      (*api.SecretReference)(newAPI.Spec.Service.OpenAPISpec.CA),
 			},
+			ExternalURL: newAPI.Spec.Service.ExternalURL,
 		},
 	}
 
@@ -149,12 +188,42 @@ func (a *API) reviewUpdateOperation(ctx context.Context, oldAPI, newAPI *hubv1al
 		updateReq.Service.OpenAPISpec.Port = int(newAPI.Spec.Service.OpenAPISpec.Port.Number)
 	}
 
+	if newAPI.Spec.VersionHeader != nil {
+		updateReq.VersionHeader = &api.VersionHeader{
+			Name:  newAPI.Spec.VersionHeader.Name,
+			Value: newAPI.Spec.VersionHeader.Value,
+		}
+	}
+
+	updateReq.Matchers = api.MatchersFromCRD(newAPI.Spec.Matchers)
+
+	if newAPI.Spec.Deprecation != nil {
+		updateReq.Deprecation = &api.Deprecation{}
+		if newAPI.Spec.Deprecation.Sunset != nil {
+			updateReq.Deprecation.Sunset = &newAPI.Spec.Deprecation.Sunset.Time
+		}
+	}
+
+	if newAPI.Spec.Sandbox != nil {
+		updateReq.Sandbox = &api.Sandbox{
+			Service: api.SandboxService{
+				Name: newAPI.Spec.Sandbox.Service.Name,
+				Port: int(newAPI.Spec.Sandbox.Service.Port.Number),
+			},
+		}
+	}
+
+	updateReq.MaxRequestBodyBytes = newAPI.Spec.MaxRequestBodyBytes
+	updateReq.AllowedMethods = newAPI.Spec.AllowedMethods
+	updateReq.ValidateRequests = newAPI.Spec.ValidateRequests
+	updateReq.CORS = newAPI.Spec.CORS
+
 	updateAPI, err := a.platform.UpdateAPI(ctx, oldAPI.Namespace, oldAPI.Name, oldAPI.Status.Version, updateReq)
 	if err != nil {
 		return nil, fmt.Errorf("update API: %w", err)
 	}
 
-	return a.buildPatches(updateAPI)
+	return a.buildPatches(updateAPI, oldAPI.Status.Conditions)
 }
 
 func (a *API) reviewDeleteOperation(ctx context.Context, oldAPI *hubv1alpha1.API) ([]byte, error) {
@@ -166,12 +235,15 @@ func (a *API) reviewDeleteOperation(ctx context.Context, oldAPI *hubv1alpha1.API
 	return nil, nil
 }
 
-func (a *API) buildPatches(obj *api.API) ([]byte, error) {
+func (a *API) buildPatches(obj *api.API, conditions []metav1.Condition) ([]byte, error) {
 	res, err := obj.Resource()
 	if err != nil {
 		return nil, fmt.Errorf("build resource: %w", err)
 	}
 
+	// Keep the conditions set by the agent, as they only transition when their status changes.
+	res.Status.Conditions = hubv1alpha1.MergeConditions(conditions, res.Status.Conditions...)
+
 	return json.Marshal([]patch{
 		{Op: "replace", Path: "/status", Value: res.Status},
 	})
@@ -84,9 +84,10 @@ func TestAPI_Review_createOperation(t *testing.T) {
 			},
 			wantPatch: mustMarshal(t, []patch{
 				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIStatus{
-					Version:  "version-1",
-					SyncedAt: now,
-					Hash:     "+xgrfxe5a0V1CHEEFurzwA==",
+					Version:    "version-1",
+					SyncedAt:   now,
+					Hash:       "+xgrfxe5a0V1CHEEFurzwA==",
+					Conditions: []metav1.Condition{syncedCondition(now), readyCondition(now)},
 				}},
 			}),
 		},
@@ -221,9 +222,10 @@ func TestAPI_Review_updateOperation(t *testing.T) {
 			},
 			wantPatch: mustMarshal(t, []patch{
 				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIStatus{
-					Version:  "version-2",
-					SyncedAt: now,
-					Hash:     "cXxdTodtlUhIj1+8EbO3cw==",
+					Version:    "version-2",
+					SyncedAt:   now,
+					Hash:       "cXxdTodtlUhIj1+8EbO3cw==",
+					Conditions: []metav1.Condition{syncedCondition(now), readyCondition(now)},
 				}},
 			}),
 		},
@@ -395,3 +397,23 @@ func mustMarshal(t *testing.T, obj interface{}) []byte {
 
 	return b
 }
+
+func syncedCondition(now metav1.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:               hubv1alpha1.ConditionSynced,
+		Status:             metav1.ConditionTrue,
+		Reason:             hubv1alpha1.ReasonSynced,
+		Message:            "Resource is synchronized with the platform",
+		LastTransitionTime: now,
+	}
+}
+
+func readyCondition(now metav1.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:               hubv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             hubv1alpha1.ReasonReady,
+		Message:            "Resource is ready",
+		LastTransitionTime: now,
+	}
+}
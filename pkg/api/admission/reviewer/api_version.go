@@ -0,0 +1,207 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type apiVersionService interface {
+	CreateAPIVersion(ctx context.Context, req *platform.CreateAPIVersionReq) (*api.APIVersion, error)
+	UpdateAPIVersion(ctx context.Context, namespace, name, lastKnownVersion string, req *platform.UpdateAPIVersionReq) (*api.APIVersion, error)
+	DeleteAPIVersion(ctx context.Context, namespace, name, lastKnownVersion string) error
+}
+
+// APIVersion is a reviewer that handle APIVersion.
+type APIVersion struct {
+	platform apiVersionService
+}
+
+// NewAPIVersion returns a new APIVersion reviewer.
+func NewAPIVersion(client apiVersionService) *APIVersion {
+	return &APIVersion{
+		platform: client,
+	}
+}
+
+// Review reviews the admission request.
+func (a *APIVersion) Review(ctx context.Context, req *admv1.AdmissionRequest) ([]byte, error) {
+	logger := log.Ctx(ctx).With().Str("reviewer", "APIVersion").Logger()
+
+	logger.Info().Msg("Reviewing APIVersion resource")
+	ctx = logger.WithContext(ctx)
+
+	// TODO: Handle DryRun flag.
+	if req.DryRun != nil && *req.DryRun {
+		return nil, nil
+	}
+
+	var newVersion, oldVersion *hubv1alpha1.APIVersion
+	if err := parseRaw(req.Object.Raw, &newVersion); err != nil {
+		return nil, fmt.Errorf("parse raw APIVersion: %w", err)
+	}
+	if err := parseRaw(req.OldObject.Raw, &oldVersion); err != nil {
+		return nil, fmt.Errorf("parse raw APIVersion: %w", err)
+	}
+
+	// Skip the review if the APIVersion hasn't changed since the last platform sync.
+	if newVersion != nil {
+		versionHash, err := api.HashAPIVersion(newVersion)
+		if err != nil {
+			return nil, fmt.Errorf("compute APIVersion hash: %w", err)
+		}
+
+		if newVersion.Status.Hash == versionHash {
+			return nil, nil
+		}
+	}
+
+	switch req.Operation {
+	case admv1.Create:
+		return a.reviewCreateOperation(ctx, newVersion)
+	case admv1.Update:
+		return a.reviewUpdateOperation(ctx, oldVersion, newVersion)
+	case admv1.Delete:
+		return a.reviewDeleteOperation(ctx, oldVersion)
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", req.Operation)
+	}
+}
+
+func (a *APIVersion) reviewCreateOperation(ctx context.Context, versionCRD *hubv1alpha1.APIVersion) ([]byte, error) {
+	log.Ctx(ctx).Info().Msg("Creating APIVersion resource")
+
+	if versionCRD.Namespace == "" {
+		versionCRD.Namespace = "default"
+	}
+
+	createReq := &platform.CreateAPIVersionReq{
+		Name:          versionCRD.Name,
+		Namespace:     versionCRD.Namespace,
+		Labels:        versionCRD.Labels,
+		APIName:       versionCRD.Spec.APIName,
+		Release:       versionCRD.Spec.Release,
+		PathPrefix:    versionCRD.Spec.PathPrefix,
+		VersionHeader: versionHeaderFromCRD(versionCRD.Spec.VersionHeader),
+		Service:       versionServiceFromCRD(versionCRD.Spec.Service),
+		Deprecation:   deprecationFromCRD(versionCRD.Spec.Deprecation),
+	}
+
+	createdVersion, err := a.platform.CreateAPIVersion(ctx, createReq)
+	if err != nil {
+		return nil, fmt.Errorf("create APIVersion: %w", err)
+	}
+
+	return a.buildPatches(createdVersion, nil)
+}
+
+func (a *APIVersion) reviewUpdateOperation(ctx context.Context, oldVersion, newVersion *hubv1alpha1.APIVersion) ([]byte, error) {
+	log.Ctx(ctx).Info().Msg("Updating APIVersion resource")
+
+	if oldVersion.Namespace == "" {
+		oldVersion.Namespace = "default"
+	}
+
+	updateReq := &platform.UpdateAPIVersionReq{
+		Labels:        newVersion.Labels,
+		APIName:       newVersion.Spec.APIName,
+		Release:       newVersion.Spec.Release,
+		PathPrefix:    newVersion.Spec.PathPrefix,
+		VersionHeader: versionHeaderFromCRD(newVersion.Spec.VersionHeader),
+		Service:       versionServiceFromCRD(newVersion.Spec.Service),
+		Deprecation:   deprecationFromCRD(newVersion.Spec.Deprecation),
+	}
+
+	updatedVersion, err := a.platform.UpdateAPIVersion(ctx, oldVersion.Namespace, oldVersion.Name, oldVersion.Status.Version, updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("update APIVersion: %w", err)
+	}
+
+	return a.buildPatches(updatedVersion, oldVersion.Status.Conditions)
+}
+
+func (a *APIVersion) reviewDeleteOperation(ctx context.Context, oldVersion *hubv1alpha1.APIVersion) ([]byte, error) {
+	log.Ctx(ctx).Info().Msg("Deleting APIVersion resource")
+
+	if err := a.platform.DeleteAPIVersion(ctx, oldVersion.Namespace, oldVersion.Name, oldVersion.Status.Version); err != nil {
+		return nil, fmt.Errorf("delete APIVersion: %w", err)
+	}
+	return nil, nil
+}
+
+func (a *APIVersion) buildPatches(obj *api.APIVersion, conditions []metav1.Condition) ([]byte, error) {
+	res, err := obj.Resource()
+	if err != nil {
+		return nil, fmt.Errorf("build resource: %w", err)
+	}
+
+	// Keep the conditions set by the agent, as they only transition when their status changes.
+	res.Status.Conditions = hubv1alpha1.MergeConditions(conditions, res.Status.Conditions...)
+
+	return json.Marshal([]patch{
+		{Op: "replace", Path: "/status", Value: res.Status},
+	})
+}
+
+// CanReview returns true if the reviewer can review the admission request.
+func (a *APIVersion) CanReview(req *admv1.AdmissionRequest) bool {
+	return req.Kind.Kind == "APIVersion" && req.Kind.Group == hubv1alpha1.SchemeGroupVersion.Group && req.Kind.Version == hubv1alpha1.SchemeGroupVersion.Version
+}
+
+func versionHeaderFromCRD(header *hubv1alpha1.APIVersionHeader) *api.VersionHeader {
+	if header == nil {
+		return nil
+	}
+
+	return &api.VersionHeader{
+		Name:  header.Name,
+		Value: header.Value,
+	}
+}
+
+func versionServiceFromCRD(service *hubv1alpha1.APIVersionService) *api.VersionService {
+	if service == nil {
+		return nil
+	}
+
+	return &api.VersionService{
+		Name: service.Name,
+		Port: int(service.Port.Number),
+	}
+}
+
+func deprecationFromCRD(deprecation *hubv1alpha1.APIDeprecation) *api.Deprecation {
+	if deprecation == nil {
+		return nil
+	}
+
+	d := &api.Deprecation{}
+	if deprecation.Sunset != nil {
+		d.Sunset = &deprecation.Sunset.Time
+	}
+
+	return d
+}
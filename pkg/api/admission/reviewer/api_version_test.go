@@ -0,0 +1,343 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestAPIVersion_Review_createOperation(t *testing.T) {
+	now := metav1.Now()
+
+	createReq := &admv1.AdmissionRequest{
+		UID: "id",
+		Kind: metav1.GroupVersionKind{
+			Group:   "hub.traefik.io",
+			Version: "v1alpha1",
+			Kind:    "APIVersion",
+		},
+		Name:      "orders-v2",
+		Namespace: "ns",
+		Operation: admv1.Create,
+		Object: runtime.RawExtension{
+			Raw: mustMarshal(t, hubv1alpha1.APIVersion{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "APIVersion",
+					APIVersion: "hub.traefik.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "orders-v2", Namespace: "ns"},
+				Spec: hubv1alpha1.APIVersionSpec{
+					APIName:    "orders",
+					Release:    "v2",
+					PathPrefix: "/orders/v2",
+					Service: &hubv1alpha1.APIVersionService{
+						Name: "orders-v2",
+						Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+					},
+				},
+			}),
+		},
+	}
+
+	wantCreateReq := &platform.CreateAPIVersionReq{
+		Name:       "orders-v2",
+		Namespace:  "ns",
+		APIName:    "orders",
+		Release:    "v2",
+		PathPrefix: "/orders/v2",
+		Service: &api.VersionService{
+			Name: "orders-v2",
+			Port: 80,
+		},
+	}
+
+	tests := []struct {
+		desc string
+
+		errCreate error
+		wantPatch []byte
+	}{
+		{
+			desc: "call API version service on create admission request",
+			wantPatch: mustMarshal(t, []patch{
+				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIVersionStatus{
+					Version:    "version-1",
+					SyncedAt:   now,
+					Hash:       "AWeBARMXp9qJOOxXnhk8TQ==",
+					Conditions: []metav1.Condition{syncedCondition(now), readyCondition(now)},
+				}},
+			}),
+		},
+		{
+			desc:      "APIVersion service is broken",
+			errCreate: errors.New("boom"),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			createdVersion := &api.APIVersion{
+				Name:       "orders-v2",
+				Namespace:  "ns",
+				APIName:    "orders",
+				Release:    "v2",
+				PathPrefix: "/orders/v2",
+				Service: &api.VersionService{
+					Name: "orders-v2",
+					Port: 80,
+				},
+				Version:   "version-1",
+				CreatedAt: time.Now().Add(-time.Hour).UTC().Truncate(time.Millisecond),
+				UpdatedAt: time.Now().UTC().Truncate(time.Millisecond),
+			}
+
+			client := newAPIVersionServiceMock(t)
+			client.OnCreateAPIVersion(wantCreateReq).TypedReturns(createdVersion, test.errCreate).Once()
+
+			h := NewAPIVersion(client)
+			patch, err := h.Review(context.Background(), createReq)
+
+			assertErr := assert.NoError
+			if test.errCreate != nil {
+				assertErr = assert.Error
+			}
+			assertErr(t, err)
+			assert.Equal(t, test.wantPatch, patch)
+		})
+	}
+}
+
+func TestAPIVersion_Review_updateOperation(t *testing.T) {
+	now := metav1.Now()
+
+	updateReq := &admv1.AdmissionRequest{
+		UID: "id",
+		Kind: metav1.GroupVersionKind{
+			Group:   "hub.traefik.io",
+			Version: "v1alpha1",
+			Kind:    "APIVersion",
+		},
+		Name:      "orders-v2",
+		Namespace: "ns",
+		Operation: admv1.Update,
+		Object: runtime.RawExtension{
+			Raw: mustMarshal(t, hubv1alpha1.APIVersion{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "APIVersion",
+					APIVersion: "hub.traefik.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "orders-v2", Namespace: "ns"},
+				Spec: hubv1alpha1.APIVersionSpec{
+					APIName: "orders",
+					Release: "v2",
+					VersionHeader: &hubv1alpha1.APIVersionHeader{
+						Name:  "X-Version",
+						Value: "2",
+					},
+				},
+			}),
+		},
+		OldObject: runtime.RawExtension{
+			Raw: mustMarshal(t, hubv1alpha1.APIVersion{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "APIVersion",
+					APIVersion: "hub.traefik.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "orders-v2", Namespace: "ns"},
+				Spec: hubv1alpha1.APIVersionSpec{
+					APIName:    "orders",
+					Release:    "v2",
+					PathPrefix: "/orders/v2",
+				},
+				Status: hubv1alpha1.APIVersionStatus{
+					Version: "version-1",
+				},
+			}),
+		},
+	}
+
+	wantUpdateReq := &platform.UpdateAPIVersionReq{
+		APIName: "orders",
+		Release: "v2",
+		VersionHeader: &api.VersionHeader{
+			Name:  "X-Version",
+			Value: "2",
+		},
+	}
+
+	tests := []struct {
+		desc string
+
+		errUpdate error
+		wantPatch []byte
+	}{
+		{
+			desc: "call API version service on update admission request",
+			wantPatch: mustMarshal(t, []patch{
+				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIVersionStatus{
+					Version:    "version-2",
+					SyncedAt:   now,
+					Hash:       "saxfNpoCWt5S4pezfPMvQA==",
+					Conditions: []metav1.Condition{syncedCondition(now), readyCondition(now)},
+				}},
+			}),
+		},
+		{
+			desc:      "APIVersion service is broken",
+			errUpdate: errors.New("boom"),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			updatedVersion := &api.APIVersion{
+				Name:      "orders-v2",
+				Namespace: "ns",
+				APIName:   "orders",
+				Release:   "v2",
+				VersionHeader: &api.VersionHeader{
+					Name:  "X-Version",
+					Value: "2",
+				},
+				Version:   "version-2",
+				CreatedAt: time.Now().Add(-time.Hour).UTC().Truncate(time.Millisecond),
+				UpdatedAt: time.Now().UTC().Truncate(time.Millisecond),
+			}
+
+			client := newAPIVersionServiceMock(t)
+			client.OnUpdateAPIVersion("ns", "orders-v2", "version-1", wantUpdateReq).TypedReturns(updatedVersion, test.errUpdate).Once()
+
+			h := NewAPIVersion(client)
+			patch, err := h.Review(context.Background(), updateReq)
+
+			assertErr := assert.NoError
+			if test.errUpdate != nil {
+				assertErr = assert.Error
+			}
+			assertErr(t, err)
+			assert.Equal(t, test.wantPatch, patch)
+		})
+	}
+}
+
+func TestAPIVersion_Review_deleteOperation(t *testing.T) {
+	deleteReq := &admv1.AdmissionRequest{
+		UID: "id",
+		Kind: metav1.GroupVersionKind{
+			Group:   "hub.traefik.io",
+			Version: "v1alpha1",
+			Kind:    "APIVersion",
+		},
+		Name:      "orders-v2",
+		Namespace: "ns",
+		Operation: admv1.Delete,
+		OldObject: runtime.RawExtension{
+			Raw: mustMarshal(t, hubv1alpha1.APIVersion{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "APIVersion",
+					APIVersion: "hub.traefik.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "orders-v2", Namespace: "ns"},
+				Spec: hubv1alpha1.APIVersionSpec{
+					APIName:    "orders",
+					Release:    "v2",
+					PathPrefix: "/orders/v2",
+				},
+				Status: hubv1alpha1.APIVersionStatus{
+					Version: "version-1",
+				},
+			}),
+		},
+	}
+
+	tests := []struct {
+		desc string
+
+		errDelete error
+	}{
+		{
+			desc: "call API version service on delete admission request",
+		},
+		{
+			desc:      "APIVersion service is broken",
+			errDelete: errors.New("boom"),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := newAPIVersionServiceMock(t)
+			client.OnDeleteAPIVersion("ns", "orders-v2", "version-1").TypedReturns(test.errDelete).Once()
+
+			h := NewAPIVersion(client)
+			patch, err := h.Review(context.Background(), deleteReq)
+			assert.Empty(t, patch)
+
+			assertErr := assert.NoError
+			if test.errDelete != nil {
+				assertErr = assert.Error
+			}
+			assertErr(t, err)
+		})
+	}
+}
+
+func TestAPIVersion_CanReview(t *testing.T) {
+	tests := []struct {
+		desc string
+
+		kind metav1.GroupVersionKind
+		want assert.BoolAssertionFunc
+	}{
+		{
+			desc: "return true when it's an APIVersion",
+			kind: metav1.GroupVersionKind{Group: "hub.traefik.io", Version: "v1alpha1", Kind: "APIVersion"},
+			want: assert.True,
+		},
+		{
+			desc: "return false when it's not an APIVersion",
+			kind: metav1.GroupVersionKind{Group: "hub.traefik.io", Version: "v1alpha1", Kind: "API"},
+			want: assert.False,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h := NewAPIVersion(nil)
+			test.want(t, h.CanReview(&admv1.AdmissionRequest{UID: "id", Kind: test.kind, Name: "my-api-version"}))
+		})
+	}
+}
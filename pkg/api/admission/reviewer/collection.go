@@ -27,6 +27,7 @@ import (
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type collectionService interface {
@@ -106,7 +107,7 @@ func (c *Collection) reviewCreateOperation(ctx context.Context, collectionCRD *h
 		return nil, fmt.Errorf("create APICollection: %w", err)
 	}
 
-	return c.buildPatches(createdCollection)
+	return c.buildPatches(createdCollection, nil)
 }
 
 func (c *Collection) reviewUpdateOperation(ctx context.Context, oldCollection, newCollection *hubv1alpha1.APICollection) ([]byte, error) {
@@ -123,7 +124,7 @@ func (c *Collection) reviewUpdateOperation(ctx context.Context, oldCollection, n
 		return nil, fmt.Errorf("update APICollection: %w", err)
 	}
 
-	return c.buildPatches(updateCollection)
+	return c.buildPatches(updateCollection, oldCollection.Status.Conditions)
 }
 
 func (c *Collection) reviewDeleteOperation(ctx context.Context, oldCollection *hubv1alpha1.APICollection) ([]byte, error) {
@@ -135,12 +136,15 @@ func (c *Collection) reviewDeleteOperation(ctx context.Context, oldCollection *h
 	return nil, nil
 }
 
-func (c *Collection) buildPatches(obj *api.Collection) ([]byte, error) {
+func (c *Collection) buildPatches(obj *api.Collection, conditions []metav1.Condition) ([]byte, error) {
 	res, err := obj.Resource()
 	if err != nil {
 		return nil, fmt.Errorf("build resource: %w", err)
 	}
 
+	// Keep the conditions set by the agent, as they only transition when their status changes.
+	res.Status.Conditions = hubv1alpha1.MergeConditions(conditions, res.Status.Conditions...)
+
 	return json.Marshal([]patch{
 		{Op: "replace", Path: "/status", Value: res.Status},
 	})
@@ -101,6 +101,7 @@ func TestCollection_Review_createOperation(t *testing.T) {
 					Version:     "version-1",
 					SyncedAt:    now,
 					Hash:        "XrYSYUqKbEn+omNot2rSM9GTxMs=",
+					Conditions:  []metav1.Condition{syncedCondition(now), readyCondition(now)},
 				}},
 			}),
 		},
@@ -273,6 +274,7 @@ func TestCollection_Review_updateOperation(t *testing.T) {
 					Version:     "version-2",
 					SyncedAt:    now,
 					Hash:        "K93Yd3LNurYamfSBMYDock3kfJw=",
+					Conditions:  []metav1.Condition{syncedCondition(now), readyCondition(now)},
 				}},
 			}),
 		},
@@ -27,6 +27,7 @@ import (
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type gatewayService interface {
@@ -99,6 +100,9 @@ func (g *Gateway) reviewCreateOperation(ctx context.Context, gateway *hubv1alpha
 		Labels:        gateway.Labels,
 		Accesses:      gateway.Spec.APIAccesses,
 		CustomDomains: gateway.Spec.CustomDomains,
+		Certificate:   (*api.SecretReference)(gateway.Spec.Certificate),
+
+		MaxRequestBodyBytes: gateway.Spec.MaxRequestBodyBytes,
 	}
 
 	createdGateway, err := g.platform.CreateGateway(ctx, createReq)
@@ -106,7 +110,7 @@ func (g *Gateway) reviewCreateOperation(ctx context.Context, gateway *hubv1alpha
 		return nil, fmt.Errorf("create APIGateway: %w", err)
 	}
 
-	return g.buildPatches(createdGateway)
+	return g.buildPatches(createdGateway, nil)
 }
 
 func (g *Gateway) reviewUpdateOperation(ctx context.Context, oldGateway, newGateway *hubv1alpha1.APIGateway) ([]byte, error) {
@@ -116,6 +120,9 @@ func (g *Gateway) reviewUpdateOperation(ctx context.Context, oldGateway, newGate
 		Labels:        newGateway.Labels,
 		Accesses:      newGateway.Spec.APIAccesses,
 		CustomDomains: newGateway.Spec.CustomDomains,
+		Certificate:   (*api.SecretReference)(newGateway.Spec.Certificate),
+
+		MaxRequestBodyBytes: newGateway.Spec.MaxRequestBodyBytes,
 	}
 
 	updatedGateway, err := g.platform.UpdateGateway(ctx, oldGateway.Name, oldGateway.Status.Version, updateReq)
@@ -123,7 +130,7 @@ func (g *Gateway) reviewUpdateOperation(ctx context.Context, oldGateway, newGate
 		return nil, fmt.Errorf("update APIGateway: %w", err)
 	}
 
-	return g.buildPatches(updatedGateway)
+	return g.buildPatches(updatedGateway, oldGateway.Status.Conditions)
 }
 
 func (g *Gateway) reviewDeleteOperation(ctx context.Context, oldGateway *hubv1alpha1.APIGateway) ([]byte, error) {
@@ -135,12 +142,15 @@ func (g *Gateway) reviewDeleteOperation(ctx context.Context, oldGateway *hubv1al
 	return nil, nil
 }
 
-func (g *Gateway) buildPatches(gateway *api.Gateway) ([]byte, error) {
+func (g *Gateway) buildPatches(gateway *api.Gateway, conditions []metav1.Condition) ([]byte, error) {
 	res, err := gateway.Resource()
 	if err != nil {
 		return nil, fmt.Errorf("build resource: %w", err)
 	}
 
+	// Keep the conditions set by the agent, as they only transition when their status changes.
+	res.Status.Conditions = hubv1alpha1.MergeConditions(conditions, res.Status.Conditions...)
+
 	return json.Marshal([]patch{
 		{Op: "replace", Path: "/status", Value: res.Status},
 	})
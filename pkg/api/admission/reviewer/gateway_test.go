@@ -79,10 +79,11 @@ func TestGateway_Review_createOperation(t *testing.T) {
 			},
 			wantPatch: mustMarshal(t, []patch{
 				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIGatewayStatus{
-					Version:  "version-1",
-					URLs:     "https://",
-					SyncedAt: now,
-					Hash:     "po2Qx/eLWCKDbbx5iwuCBQ==",
+					Version:    "version-1",
+					URLs:       "https://",
+					SyncedAt:   now,
+					Hash:       "po2Qx/eLWCKDbbx5iwuCBQ==",
+					Conditions: []metav1.Condition{syncedCondition(now)},
 				}},
 			}),
 		},
@@ -127,6 +128,13 @@ func TestGateway_Review_createOperation(t *testing.T) {
 
 func TestGateway_Review_updateOperation(t *testing.T) {
 	now := metav1.Now()
+	certificateProvisioned := metav1.Condition{
+		Type:               hubv1alpha1.ConditionCertificateProvisioned,
+		Status:             metav1.ConditionTrue,
+		Reason:             hubv1alpha1.ReasonCertificateProvisioned,
+		Message:            "Certificates are provisioned",
+		LastTransitionTime: metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
 
 	updateReq := &admv1.AdmissionRequest{
 		UID: "id",
@@ -159,7 +167,8 @@ func TestGateway_Review_updateOperation(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Name: "gateway-name"},
 				Spec:       testGatewaySpec,
 				Status: hubv1alpha1.APIGatewayStatus{
-					Version: "version-1",
+					Version:    "version-1",
+					Conditions: []metav1.Condition{certificateProvisioned},
 				},
 			}),
 		},
@@ -182,10 +191,11 @@ func TestGateway_Review_updateOperation(t *testing.T) {
 			},
 			wantPatch: mustMarshal(t, []patch{
 				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIGatewayStatus{
-					Version:  "version-2",
-					SyncedAt: now,
-					URLs:     "https://",
-					Hash:     "po2Qx/eLWCKDbbx5iwuCBQ==",
+					Version:    "version-2",
+					SyncedAt:   now,
+					URLs:       "https://",
+					Hash:       "po2Qx/eLWCKDbbx5iwuCBQ==",
+					Conditions: []metav1.Condition{certificateProvisioned, syncedCondition(now)},
 				}},
 			}),
 		},
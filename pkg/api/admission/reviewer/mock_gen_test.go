@@ -1718,3 +1718,687 @@ func (_c *gatewayServiceUpdateGatewayCall) OnDeleteGatewayRaw(name interface{},
 func (_c *gatewayServiceUpdateGatewayCall) OnUpdateGatewayRaw(name interface{}, lastKnownVersion interface{}, updateReq interface{}) *gatewayServiceUpdateGatewayCall {
 	return _c.Parent.OnUpdateGatewayRaw(name, lastKnownVersion, updateReq)
 }
+
+// rateLimitServiceMock mock of rateLimitService.
+type rateLimitServiceMock struct{ mock.Mock }
+
+// newRateLimitServiceMock creates a new rateLimitServiceMock.
+func newRateLimitServiceMock(tb testing.TB) *rateLimitServiceMock {
+	tb.Helper()
+
+	m := &rateLimitServiceMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *rateLimitServiceMock) CreateRateLimit(_ context.Context, req *platform.CreateRateLimitReq) (*api.RateLimit, error) {
+	_ret := _m.Called(req)
+
+	if _rf, ok := _ret.Get(0).(func(*platform.CreateRateLimitReq) (*api.RateLimit, error)); ok {
+		return _rf(req)
+	}
+
+	_ra0, _ := _ret.Get(0).(*api.RateLimit)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *rateLimitServiceMock) OnCreateRateLimit(req *platform.CreateRateLimitReq) *rateLimitServiceCreateRateLimitCall {
+	return &rateLimitServiceCreateRateLimitCall{Call: _m.Mock.On("CreateRateLimit", req), Parent: _m}
+}
+
+func (_m *rateLimitServiceMock) OnCreateRateLimitRaw(req interface{}) *rateLimitServiceCreateRateLimitCall {
+	return &rateLimitServiceCreateRateLimitCall{Call: _m.Mock.On("CreateRateLimit", req), Parent: _m}
+}
+
+type rateLimitServiceCreateRateLimitCall struct {
+	*mock.Call
+	Parent *rateLimitServiceMock
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) Panic(msg string) *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) Once() *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) Twice() *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) Times(i int) *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) WaitUntil(w <-chan time.Time) *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) After(d time.Duration) *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) Run(fn func(args mock.Arguments)) *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) Maybe() *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) TypedReturns(a *api.RateLimit, b error) *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) ReturnsFn(fn func(*platform.CreateRateLimitReq) (*api.RateLimit, error)) *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) TypedRun(fn func(*platform.CreateRateLimitReq)) *rateLimitServiceCreateRateLimitCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_req, _ := args.Get(0).(*platform.CreateRateLimitReq)
+		fn(_req)
+	})
+	return _c
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) OnCreateRateLimit(req *platform.CreateRateLimitReq) *rateLimitServiceCreateRateLimitCall {
+	return _c.Parent.OnCreateRateLimit(req)
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) OnDeleteRateLimit(name string, lastKnownVersion string) *rateLimitServiceDeleteRateLimitCall {
+	return _c.Parent.OnDeleteRateLimit(name, lastKnownVersion)
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) OnUpdateRateLimit(name string, lastKnownVersion string, req *platform.UpdateRateLimitReq) *rateLimitServiceUpdateRateLimitCall {
+	return _c.Parent.OnUpdateRateLimit(name, lastKnownVersion, req)
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) OnCreateRateLimitRaw(req interface{}) *rateLimitServiceCreateRateLimitCall {
+	return _c.Parent.OnCreateRateLimitRaw(req)
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) OnDeleteRateLimitRaw(name interface{}, lastKnownVersion interface{}) *rateLimitServiceDeleteRateLimitCall {
+	return _c.Parent.OnDeleteRateLimitRaw(name, lastKnownVersion)
+}
+
+func (_c *rateLimitServiceCreateRateLimitCall) OnUpdateRateLimitRaw(name interface{}, lastKnownVersion interface{}, req interface{}) *rateLimitServiceUpdateRateLimitCall {
+	return _c.Parent.OnUpdateRateLimitRaw(name, lastKnownVersion, req)
+}
+
+func (_m *rateLimitServiceMock) DeleteRateLimit(_ context.Context, name string, lastKnownVersion string) error {
+	_ret := _m.Called(name, lastKnownVersion)
+
+	if _rf, ok := _ret.Get(0).(func(string, string) error); ok {
+		return _rf(name, lastKnownVersion)
+	}
+
+	_ra0 := _ret.Error(0)
+
+	return _ra0
+}
+
+func (_m *rateLimitServiceMock) OnDeleteRateLimit(name string, lastKnownVersion string) *rateLimitServiceDeleteRateLimitCall {
+	return &rateLimitServiceDeleteRateLimitCall{Call: _m.Mock.On("DeleteRateLimit", name, lastKnownVersion), Parent: _m}
+}
+
+func (_m *rateLimitServiceMock) OnDeleteRateLimitRaw(name interface{}, lastKnownVersion interface{}) *rateLimitServiceDeleteRateLimitCall {
+	return &rateLimitServiceDeleteRateLimitCall{Call: _m.Mock.On("DeleteRateLimit", name, lastKnownVersion), Parent: _m}
+}
+
+type rateLimitServiceDeleteRateLimitCall struct {
+	*mock.Call
+	Parent *rateLimitServiceMock
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) Panic(msg string) *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) Once() *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) Twice() *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) Times(i int) *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) WaitUntil(w <-chan time.Time) *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) After(d time.Duration) *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) Run(fn func(args mock.Arguments)) *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) Maybe() *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) TypedReturns(a error) *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Return(a)
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) ReturnsFn(fn func(string, string) error) *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) TypedRun(fn func(string, string)) *rateLimitServiceDeleteRateLimitCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_name := args.String(0)
+		_lastKnownVersion := args.String(1)
+		fn(_name, _lastKnownVersion)
+	})
+	return _c
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) OnCreateRateLimit(req *platform.CreateRateLimitReq) *rateLimitServiceCreateRateLimitCall {
+	return _c.Parent.OnCreateRateLimit(req)
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) OnDeleteRateLimit(name string, lastKnownVersion string) *rateLimitServiceDeleteRateLimitCall {
+	return _c.Parent.OnDeleteRateLimit(name, lastKnownVersion)
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) OnUpdateRateLimit(name string, lastKnownVersion string, req *platform.UpdateRateLimitReq) *rateLimitServiceUpdateRateLimitCall {
+	return _c.Parent.OnUpdateRateLimit(name, lastKnownVersion, req)
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) OnCreateRateLimitRaw(req interface{}) *rateLimitServiceCreateRateLimitCall {
+	return _c.Parent.OnCreateRateLimitRaw(req)
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) OnDeleteRateLimitRaw(name interface{}, lastKnownVersion interface{}) *rateLimitServiceDeleteRateLimitCall {
+	return _c.Parent.OnDeleteRateLimitRaw(name, lastKnownVersion)
+}
+
+func (_c *rateLimitServiceDeleteRateLimitCall) OnUpdateRateLimitRaw(name interface{}, lastKnownVersion interface{}, req interface{}) *rateLimitServiceUpdateRateLimitCall {
+	return _c.Parent.OnUpdateRateLimitRaw(name, lastKnownVersion, req)
+}
+
+func (_m *rateLimitServiceMock) UpdateRateLimit(_ context.Context, name string, lastKnownVersion string, req *platform.UpdateRateLimitReq) (*api.RateLimit, error) {
+	_ret := _m.Called(name, lastKnownVersion, req)
+
+	if _rf, ok := _ret.Get(0).(func(string, string, *platform.UpdateRateLimitReq) (*api.RateLimit, error)); ok {
+		return _rf(name, lastKnownVersion, req)
+	}
+
+	_ra0, _ := _ret.Get(0).(*api.RateLimit)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *rateLimitServiceMock) OnUpdateRateLimit(name string, lastKnownVersion string, req *platform.UpdateRateLimitReq) *rateLimitServiceUpdateRateLimitCall {
+	return &rateLimitServiceUpdateRateLimitCall{Call: _m.Mock.On("UpdateRateLimit", name, lastKnownVersion, req), Parent: _m}
+}
+
+func (_m *rateLimitServiceMock) OnUpdateRateLimitRaw(name interface{}, lastKnownVersion interface{}, req interface{}) *rateLimitServiceUpdateRateLimitCall {
+	return &rateLimitServiceUpdateRateLimitCall{Call: _m.Mock.On("UpdateRateLimit", name, lastKnownVersion, req), Parent: _m}
+}
+
+type rateLimitServiceUpdateRateLimitCall struct {
+	*mock.Call
+	Parent *rateLimitServiceMock
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) Panic(msg string) *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) Once() *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) Twice() *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) Times(i int) *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) WaitUntil(w <-chan time.Time) *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) After(d time.Duration) *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) Run(fn func(args mock.Arguments)) *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) Maybe() *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) TypedReturns(a *api.RateLimit, b error) *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) ReturnsFn(fn func(string, string, *platform.UpdateRateLimitReq) (*api.RateLimit, error)) *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) TypedRun(fn func(string, string, *platform.UpdateRateLimitReq)) *rateLimitServiceUpdateRateLimitCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_name := args.String(0)
+		_lastKnownVersion := args.String(1)
+		_req, _ := args.Get(2).(*platform.UpdateRateLimitReq)
+		fn(_name, _lastKnownVersion, _req)
+	})
+	return _c
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) OnCreateRateLimit(req *platform.CreateRateLimitReq) *rateLimitServiceCreateRateLimitCall {
+	return _c.Parent.OnCreateRateLimit(req)
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) OnDeleteRateLimit(name string, lastKnownVersion string) *rateLimitServiceDeleteRateLimitCall {
+	return _c.Parent.OnDeleteRateLimit(name, lastKnownVersion)
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) OnUpdateRateLimit(name string, lastKnownVersion string, req *platform.UpdateRateLimitReq) *rateLimitServiceUpdateRateLimitCall {
+	return _c.Parent.OnUpdateRateLimit(name, lastKnownVersion, req)
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) OnCreateRateLimitRaw(req interface{}) *rateLimitServiceCreateRateLimitCall {
+	return _c.Parent.OnCreateRateLimitRaw(req)
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) OnDeleteRateLimitRaw(name interface{}, lastKnownVersion interface{}) *rateLimitServiceDeleteRateLimitCall {
+	return _c.Parent.OnDeleteRateLimitRaw(name, lastKnownVersion)
+}
+
+func (_c *rateLimitServiceUpdateRateLimitCall) OnUpdateRateLimitRaw(name interface{}, lastKnownVersion interface{}, req interface{}) *rateLimitServiceUpdateRateLimitCall {
+	return _c.Parent.OnUpdateRateLimitRaw(name, lastKnownVersion, req)
+}
+
+// apiVersionServiceMock mock of apiVersionService.
+type apiVersionServiceMock struct{ mock.Mock }
+
+// newAPIVersionServiceMock creates a new apiVersionServiceMock.
+func newAPIVersionServiceMock(tb testing.TB) *apiVersionServiceMock {
+	tb.Helper()
+
+	m := &apiVersionServiceMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *apiVersionServiceMock) CreateAPIVersion(_ context.Context, req *platform.CreateAPIVersionReq) (*api.APIVersion, error) {
+	_ret := _m.Called(req)
+
+	if _rf, ok := _ret.Get(0).(func(*platform.CreateAPIVersionReq) (*api.APIVersion, error)); ok {
+		return _rf(req)
+	}
+
+	_ra0, _ := _ret.Get(0).(*api.APIVersion)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *apiVersionServiceMock) OnCreateAPIVersion(req *platform.CreateAPIVersionReq) *apiVersionServiceCreateAPIVersionCall {
+	return &apiVersionServiceCreateAPIVersionCall{Call: _m.Mock.On("CreateAPIVersion", req), Parent: _m}
+}
+
+func (_m *apiVersionServiceMock) OnCreateAPIVersionRaw(req interface{}) *apiVersionServiceCreateAPIVersionCall {
+	return &apiVersionServiceCreateAPIVersionCall{Call: _m.Mock.On("CreateAPIVersion", req), Parent: _m}
+}
+
+type apiVersionServiceCreateAPIVersionCall struct {
+	*mock.Call
+	Parent *apiVersionServiceMock
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) Panic(msg string) *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) Once() *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) Twice() *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) Times(i int) *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) WaitUntil(w <-chan time.Time) *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) After(d time.Duration) *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) Run(fn func(args mock.Arguments)) *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) Maybe() *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) TypedReturns(a *api.APIVersion, b error) *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) ReturnsFn(fn func(*platform.CreateAPIVersionReq) (*api.APIVersion, error)) *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) TypedRun(fn func(*platform.CreateAPIVersionReq)) *apiVersionServiceCreateAPIVersionCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_req, _ := args.Get(0).(*platform.CreateAPIVersionReq)
+		fn(_req)
+	})
+	return _c
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) OnCreateAPIVersion(req *platform.CreateAPIVersionReq) *apiVersionServiceCreateAPIVersionCall {
+	return _c.Parent.OnCreateAPIVersion(req)
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) OnDeleteAPIVersion(namespace string, name string, lastKnownVersion string) *apiVersionServiceDeleteAPIVersionCall {
+	return _c.Parent.OnDeleteAPIVersion(namespace, name, lastKnownVersion)
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) OnUpdateAPIVersion(namespace string, name string, lastKnownVersion string, req *platform.UpdateAPIVersionReq) *apiVersionServiceUpdateAPIVersionCall {
+	return _c.Parent.OnUpdateAPIVersion(namespace, name, lastKnownVersion, req)
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) OnCreateAPIVersionRaw(req interface{}) *apiVersionServiceCreateAPIVersionCall {
+	return _c.Parent.OnCreateAPIVersionRaw(req)
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) OnDeleteAPIVersionRaw(namespace interface{}, name interface{}, lastKnownVersion interface{}) *apiVersionServiceDeleteAPIVersionCall {
+	return _c.Parent.OnDeleteAPIVersionRaw(namespace, name, lastKnownVersion)
+}
+
+func (_c *apiVersionServiceCreateAPIVersionCall) OnUpdateAPIVersionRaw(namespace interface{}, name interface{}, lastKnownVersion interface{}, req interface{}) *apiVersionServiceUpdateAPIVersionCall {
+	return _c.Parent.OnUpdateAPIVersionRaw(namespace, name, lastKnownVersion, req)
+}
+
+func (_m *apiVersionServiceMock) DeleteAPIVersion(_ context.Context, namespace string, name string, lastKnownVersion string) error {
+	_ret := _m.Called(namespace, name, lastKnownVersion)
+
+	if _rf, ok := _ret.Get(0).(func(string, string, string) error); ok {
+		return _rf(namespace, name, lastKnownVersion)
+	}
+
+	_ra0 := _ret.Error(0)
+
+	return _ra0
+}
+
+func (_m *apiVersionServiceMock) OnDeleteAPIVersion(namespace string, name string, lastKnownVersion string) *apiVersionServiceDeleteAPIVersionCall {
+	return &apiVersionServiceDeleteAPIVersionCall{Call: _m.Mock.On("DeleteAPIVersion", namespace, name, lastKnownVersion), Parent: _m}
+}
+
+func (_m *apiVersionServiceMock) OnDeleteAPIVersionRaw(namespace interface{}, name interface{}, lastKnownVersion interface{}) *apiVersionServiceDeleteAPIVersionCall {
+	return &apiVersionServiceDeleteAPIVersionCall{Call: _m.Mock.On("DeleteAPIVersion", namespace, name, lastKnownVersion), Parent: _m}
+}
+
+type apiVersionServiceDeleteAPIVersionCall struct {
+	*mock.Call
+	Parent *apiVersionServiceMock
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) Panic(msg string) *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) Once() *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) Twice() *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) Times(i int) *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) WaitUntil(w <-chan time.Time) *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) After(d time.Duration) *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) Run(fn func(args mock.Arguments)) *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) Maybe() *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) TypedReturns(a error) *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Return(a)
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) ReturnsFn(fn func(string, string, string) error) *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) TypedRun(fn func(string, string, string)) *apiVersionServiceDeleteAPIVersionCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_namespace := args.String(0)
+		_name := args.String(1)
+		_lastKnownVersion := args.String(2)
+		fn(_namespace, _name, _lastKnownVersion)
+	})
+	return _c
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) OnCreateAPIVersion(req *platform.CreateAPIVersionReq) *apiVersionServiceCreateAPIVersionCall {
+	return _c.Parent.OnCreateAPIVersion(req)
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) OnDeleteAPIVersion(namespace string, name string, lastKnownVersion string) *apiVersionServiceDeleteAPIVersionCall {
+	return _c.Parent.OnDeleteAPIVersion(namespace, name, lastKnownVersion)
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) OnUpdateAPIVersion(namespace string, name string, lastKnownVersion string, req *platform.UpdateAPIVersionReq) *apiVersionServiceUpdateAPIVersionCall {
+	return _c.Parent.OnUpdateAPIVersion(namespace, name, lastKnownVersion, req)
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) OnCreateAPIVersionRaw(req interface{}) *apiVersionServiceCreateAPIVersionCall {
+	return _c.Parent.OnCreateAPIVersionRaw(req)
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) OnDeleteAPIVersionRaw(namespace interface{}, name interface{}, lastKnownVersion interface{}) *apiVersionServiceDeleteAPIVersionCall {
+	return _c.Parent.OnDeleteAPIVersionRaw(namespace, name, lastKnownVersion)
+}
+
+func (_c *apiVersionServiceDeleteAPIVersionCall) OnUpdateAPIVersionRaw(namespace interface{}, name interface{}, lastKnownVersion interface{}, req interface{}) *apiVersionServiceUpdateAPIVersionCall {
+	return _c.Parent.OnUpdateAPIVersionRaw(namespace, name, lastKnownVersion, req)
+}
+
+func (_m *apiVersionServiceMock) UpdateAPIVersion(_ context.Context, namespace string, name string, lastKnownVersion string, req *platform.UpdateAPIVersionReq) (*api.APIVersion, error) {
+	_ret := _m.Called(namespace, name, lastKnownVersion, req)
+
+	if _rf, ok := _ret.Get(0).(func(string, string, string, *platform.UpdateAPIVersionReq) (*api.APIVersion, error)); ok {
+		return _rf(namespace, name, lastKnownVersion, req)
+	}
+
+	_ra0, _ := _ret.Get(0).(*api.APIVersion)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *apiVersionServiceMock) OnUpdateAPIVersion(namespace string, name string, lastKnownVersion string, req *platform.UpdateAPIVersionReq) *apiVersionServiceUpdateAPIVersionCall {
+	return &apiVersionServiceUpdateAPIVersionCall{Call: _m.Mock.On("UpdateAPIVersion", namespace, name, lastKnownVersion, req), Parent: _m}
+}
+
+func (_m *apiVersionServiceMock) OnUpdateAPIVersionRaw(namespace interface{}, name interface{}, lastKnownVersion interface{}, req interface{}) *apiVersionServiceUpdateAPIVersionCall {
+	return &apiVersionServiceUpdateAPIVersionCall{Call: _m.Mock.On("UpdateAPIVersion", namespace, name, lastKnownVersion, req), Parent: _m}
+}
+
+type apiVersionServiceUpdateAPIVersionCall struct {
+	*mock.Call
+	Parent *apiVersionServiceMock
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) Panic(msg string) *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) Once() *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) Twice() *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) Times(i int) *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) WaitUntil(w <-chan time.Time) *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) After(d time.Duration) *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) Run(fn func(args mock.Arguments)) *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) Maybe() *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) TypedReturns(a *api.APIVersion, b error) *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) ReturnsFn(fn func(string, string, string, *platform.UpdateAPIVersionReq) (*api.APIVersion, error)) *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) TypedRun(fn func(string, string, string, *platform.UpdateAPIVersionReq)) *apiVersionServiceUpdateAPIVersionCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_namespace := args.String(0)
+		_name := args.String(1)
+		_lastKnownVersion := args.String(2)
+		_req, _ := args.Get(3).(*platform.UpdateAPIVersionReq)
+		fn(_namespace, _name, _lastKnownVersion, _req)
+	})
+	return _c
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) OnCreateAPIVersion(req *platform.CreateAPIVersionReq) *apiVersionServiceCreateAPIVersionCall {
+	return _c.Parent.OnCreateAPIVersion(req)
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) OnDeleteAPIVersion(namespace string, name string, lastKnownVersion string) *apiVersionServiceDeleteAPIVersionCall {
+	return _c.Parent.OnDeleteAPIVersion(namespace, name, lastKnownVersion)
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) OnUpdateAPIVersion(namespace string, name string, lastKnownVersion string, req *platform.UpdateAPIVersionReq) *apiVersionServiceUpdateAPIVersionCall {
+	return _c.Parent.OnUpdateAPIVersion(namespace, name, lastKnownVersion, req)
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) OnCreateAPIVersionRaw(req interface{}) *apiVersionServiceCreateAPIVersionCall {
+	return _c.Parent.OnCreateAPIVersionRaw(req)
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) OnDeleteAPIVersionRaw(namespace interface{}, name interface{}, lastKnownVersion interface{}) *apiVersionServiceDeleteAPIVersionCall {
+	return _c.Parent.OnDeleteAPIVersionRaw(namespace, name, lastKnownVersion)
+}
+
+func (_c *apiVersionServiceUpdateAPIVersionCall) OnUpdateAPIVersionRaw(namespace interface{}, name interface{}, lastKnownVersion interface{}, req interface{}) *apiVersionServiceUpdateAPIVersionCall {
+	return _c.Parent.OnUpdateAPIVersionRaw(namespace, name, lastKnownVersion, req)
+}
@@ -22,3 +22,5 @@ package admission
 // mocktail:accessService
 // mocktail:portalService
 // mocktail:gatewayService
+// mocktail:rateLimitService
+// mocktail:apiVersionService
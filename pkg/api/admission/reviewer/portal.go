@@ -27,6 +27,7 @@ import (
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type portalService interface {
@@ -100,6 +101,7 @@ func (p *Portal) reviewCreateOperation(ctx context.Context, portal *hubv1alpha1.
 		Description:   portal.Spec.Description,
 		Gateway:       portal.Spec.APIGateway,
 		CustomDomains: portal.Spec.CustomDomains,
+		CORS:          portal.Spec.CORS,
 	}
 
 	createdPortal, err := p.platform.CreatePortal(ctx, createReq)
@@ -107,7 +109,7 @@ func (p *Portal) reviewCreateOperation(ctx context.Context, portal *hubv1alpha1.
 		return nil, fmt.Errorf("create APIPortal: %w", err)
 	}
 
-	return p.buildPatches(createdPortal)
+	return p.buildPatches(createdPortal, nil)
 }
 
 func (p *Portal) reviewUpdateOperation(ctx context.Context, oldPortal, newPortal *hubv1alpha1.APIPortal) ([]byte, error) {
@@ -119,6 +121,7 @@ func (p *Portal) reviewUpdateOperation(ctx context.Context, oldPortal, newPortal
 		Gateway:       newPortal.Spec.APIGateway,
 		HubDomain:     newPortal.Status.HubDomain,
 		CustomDomains: newPortal.Spec.CustomDomains,
+		CORS:          newPortal.Spec.CORS,
 	}
 
 	updatedPortal, err := p.platform.UpdatePortal(ctx, oldPortal.Name, oldPortal.Status.Version, updateReq)
@@ -126,7 +129,7 @@ func (p *Portal) reviewUpdateOperation(ctx context.Context, oldPortal, newPortal
 		return nil, fmt.Errorf("update APIPortal: %w", err)
 	}
 
-	return p.buildPatches(updatedPortal)
+	return p.buildPatches(updatedPortal, oldPortal.Status.Conditions)
 }
 
 func (p *Portal) reviewDeleteOperation(ctx context.Context, oldPortal *hubv1alpha1.APIPortal) ([]byte, error) {
@@ -138,12 +141,15 @@ func (p *Portal) reviewDeleteOperation(ctx context.Context, oldPortal *hubv1alph
 	return nil, nil
 }
 
-func (p *Portal) buildPatches(obj *api.Portal) ([]byte, error) {
+func (p *Portal) buildPatches(obj *api.Portal, conditions []metav1.Condition) ([]byte, error) {
 	res, err := obj.Resource()
 	if err != nil {
 		return nil, fmt.Errorf("build resource: %w", err)
 	}
 
+	// Keep the conditions set by the agent, as they only transition when their status changes.
+	res.Status.Conditions = hubv1alpha1.MergeConditions(conditions, res.Status.Conditions...)
+
 	return json.Marshal([]patch{
 		{Op: "replace", Path: "/status", Value: res.Status},
 	})
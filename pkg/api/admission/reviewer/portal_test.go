@@ -88,6 +88,7 @@ func TestPortal_Review_createOperation(t *testing.T) {
 					URLs:          "https://example.com",
 					CustomDomains: []string{"example.com"},
 					Hash:          "j4SP57OtltRAVw+lrQTh0A==",
+					Conditions:    []metav1.Condition{syncedCondition(now)},
 				}},
 			}),
 		},
@@ -196,9 +197,10 @@ func TestPortal_Review_updateOperation(t *testing.T) {
 			},
 			wantPatch: mustMarshal(t, []patch{
 				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIPortalStatus{
-					Version:  "version-2",
-					SyncedAt: now,
-					Hash:     "7z8bBr7Yav5c/oZ/W/Qy8Q==",
+					Version:    "version-2",
+					SyncedAt:   now,
+					Hash:       "7z8bBr7Yav5c/oZ/W/Qy8Q==",
+					Conditions: []metav1.Condition{syncedCondition(now)},
 				}},
 			}),
 		},
@@ -0,0 +1,167 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	admv1 "k8s.io/api/admission/v1"
+)
+
+type rateLimitService interface {
+	CreateRateLimit(ctx context.Context, req *platform.CreateRateLimitReq) (*api.RateLimit, error)
+	UpdateRateLimit(ctx context.Context, name, lastKnownVersion string, req *platform.UpdateRateLimitReq) (*api.RateLimit, error)
+	DeleteRateLimit(ctx context.Context, name, lastKnownVersion string) error
+}
+
+// RateLimit is a reviewer that handle APIRateLimit.
+type RateLimit struct {
+	platform rateLimitService
+}
+
+// NewRateLimit returns a new APIRateLimit reviewer.
+func NewRateLimit(client rateLimitService) *RateLimit {
+	return &RateLimit{
+		platform: client,
+	}
+}
+
+// Review reviews the admission request.
+func (r *RateLimit) Review(ctx context.Context, req *admv1.AdmissionRequest) ([]byte, error) {
+	logger := log.Ctx(ctx).With().Str("reviewer", "APIRateLimit").Logger()
+
+	logger.Info().Msg("Reviewing APIRateLimit resource")
+	ctx = logger.WithContext(ctx)
+
+	// TODO: Handle DryRun flag.
+	if req.DryRun != nil && *req.DryRun {
+		return nil, nil
+	}
+
+	var newRateLimit, oldRateLimit *hubv1alpha1.APIRateLimit
+	if err := parseRaw(req.Object.Raw, &newRateLimit); err != nil {
+		return nil, fmt.Errorf("parse raw APIRateLimit: %w", err)
+	}
+	if err := parseRaw(req.OldObject.Raw, &oldRateLimit); err != nil {
+		return nil, fmt.Errorf("parse raw APIRateLimit: %w", err)
+	}
+
+	// Skip the review if the APIRateLimit hasn't changed since the last platform sync.
+	if newRateLimit != nil {
+		rateLimitHash, err := api.HashRateLimit(newRateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("compute APIRateLimit hash: %w", err)
+		}
+
+		if newRateLimit.Status.Hash == rateLimitHash {
+			return nil, nil
+		}
+	}
+
+	switch req.Operation {
+	case admv1.Create:
+		return r.reviewCreateOperation(ctx, newRateLimit)
+	case admv1.Update:
+		return r.reviewUpdateOperation(ctx, oldRateLimit, newRateLimit)
+	case admv1.Delete:
+		return r.reviewDeleteOperation(ctx, oldRateLimit)
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", req.Operation)
+	}
+}
+
+func (r *RateLimit) reviewCreateOperation(ctx context.Context, rateLimitCRD *hubv1alpha1.APIRateLimit) ([]byte, error) {
+	log.Ctx(ctx).Info().Msg("Creating APIRateLimit resource")
+
+	createReq := &platform.CreateRateLimitReq{
+		Name:        rateLimitCRD.Name,
+		Labels:      rateLimitCRD.Labels,
+		Groups:      rateLimitCRD.Spec.Groups,
+		Everyone:    rateLimitCRD.Spec.Everyone,
+		APISelector: rateLimitCRD.Spec.APISelector,
+		Limit:       rateLimitCRD.Spec.Limit,
+		Period:      rateLimitCRD.Spec.Period,
+		Strategy:    rateLimitCRD.Spec.Strategy,
+	}
+
+	createdRateLimit, err := r.platform.CreateRateLimit(ctx, createReq)
+	if err != nil {
+		return nil, fmt.Errorf("create APIRateLimit: %w", err)
+	}
+
+	return r.buildPatches(createdRateLimit, nil)
+}
+
+func (r *RateLimit) reviewUpdateOperation(ctx context.Context, oldRateLimit, newRateLimit *hubv1alpha1.APIRateLimit) ([]byte, error) {
+	log.Ctx(ctx).Info().Msg("Updating APIRateLimit resource")
+
+	updateReq := &platform.UpdateRateLimitReq{
+		Labels:      newRateLimit.Labels,
+		Groups:      newRateLimit.Spec.Groups,
+		Everyone:    newRateLimit.Spec.Everyone,
+		APISelector: newRateLimit.Spec.APISelector,
+		Limit:       newRateLimit.Spec.Limit,
+		Period:      newRateLimit.Spec.Period,
+		Strategy:    newRateLimit.Spec.Strategy,
+	}
+
+	updatedRateLimit, err := r.platform.UpdateRateLimit(ctx, oldRateLimit.Name, oldRateLimit.Status.Version, updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("update APIRateLimit: %w", err)
+	}
+
+	return r.buildPatches(updatedRateLimit, &oldRateLimit.Status)
+}
+
+func (r *RateLimit) reviewDeleteOperation(ctx context.Context, oldRateLimit *hubv1alpha1.APIRateLimit) ([]byte, error) {
+	log.Ctx(ctx).Info().Msg("Deleting APIRateLimit resource")
+
+	if err := r.platform.DeleteRateLimit(ctx, oldRateLimit.Name, oldRateLimit.Status.Version); err != nil {
+		return nil, fmt.Errorf("delete APIRateLimit: %w", err)
+	}
+	return nil, nil
+}
+
+func (r *RateLimit) buildPatches(obj *api.RateLimit, oldStatus *hubv1alpha1.APIRateLimitStatus) ([]byte, error) {
+	res, err := obj.Resource()
+	if err != nil {
+		return nil, fmt.Errorf("build resource: %w", err)
+	}
+
+	if oldStatus != nil {
+		// Keep the conditions set by the agent, as they only transition when their status changes, and the selected
+		// APIs, which are only reported when they change.
+		res.Status.Conditions = hubv1alpha1.MergeConditions(oldStatus.Conditions, res.Status.Conditions...)
+		res.Status.APIs = oldStatus.APIs
+	}
+
+	return json.Marshal([]patch{
+		{Op: "replace", Path: "/status", Value: res.Status},
+	})
+}
+
+// CanReview returns true if the reviewer can review the admission request.
+func (r *RateLimit) CanReview(req *admv1.AdmissionRequest) bool {
+	return req.Kind.Kind == "APIRateLimit" && req.Kind.Group == hubv1alpha1.SchemeGroupVersion.Group && req.Kind.Version == hubv1alpha1.SchemeGroupVersion.Version
+}
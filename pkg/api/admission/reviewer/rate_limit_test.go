@@ -0,0 +1,324 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package admission
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var testRateLimitSpec = hubv1alpha1.APIRateLimitSpec{
+	Groups: []string{"group"},
+	APISelector: &metav1.LabelSelector{
+		MatchLabels: map[string]string{"key": "value"},
+	},
+	Limit:    10,
+	Period:   &metav1.Duration{Duration: time.Minute},
+	Strategy: hubv1alpha1.RateLimitStrategyPerConsumer,
+}
+
+func TestRateLimit_Review_createOperation(t *testing.T) {
+	now := metav1.Now()
+
+	createReq := &admv1.AdmissionRequest{
+		UID: "id",
+		Kind: metav1.GroupVersionKind{
+			Group:   "hub.traefik.io",
+			Version: "v1alpha1",
+			Kind:    "APIRateLimit",
+		},
+		Name:      "name",
+		Operation: admv1.Create,
+		Object: runtime.RawExtension{
+			Raw: mustMarshal(t, hubv1alpha1.APIRateLimit{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "APIRateLimit",
+					APIVersion: "hub.traefik.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "name"},
+				Spec:       testRateLimitSpec,
+			}),
+		},
+	}
+
+	wantCreateReq := &platform.CreateRateLimitReq{
+		Name:   "name",
+		Groups: []string{"group"},
+		APISelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"key": "value"},
+		},
+		Limit:    10,
+		Period:   &metav1.Duration{Duration: time.Minute},
+		Strategy: hubv1alpha1.RateLimitStrategyPerConsumer,
+	}
+
+	tests := []struct {
+		desc string
+
+		errCreate error
+		wantPatch []byte
+	}{
+		{
+			desc: "call rate limit service on create admission request",
+			wantPatch: mustMarshal(t, []patch{
+				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIRateLimitStatus{
+					Version:    "version-1",
+					SyncedAt:   now,
+					Hash:       "RNc7Qq8CMVNy5N4xlzivKQ==",
+					Conditions: []metav1.Condition{syncedCondition(now), readyCondition(now)},
+				}},
+			}),
+		},
+		{
+			desc:      "RateLimit service is broken",
+			errCreate: errors.New("boom"),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			createdRateLimit := &api.RateLimit{
+				Name:   "name",
+				Groups: []string{"group"},
+				APISelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
+				},
+				Limit:     10,
+				Period:    &metav1.Duration{Duration: time.Minute},
+				Strategy:  hubv1alpha1.RateLimitStrategyPerConsumer,
+				Version:   "version-1",
+				CreatedAt: time.Now().Add(-time.Hour).UTC().Truncate(time.Millisecond),
+				UpdatedAt: time.Now().UTC().Truncate(time.Millisecond),
+			}
+
+			client := newRateLimitServiceMock(t)
+			client.OnCreateRateLimit(wantCreateReq).TypedReturns(createdRateLimit, test.errCreate).Once()
+
+			h := NewRateLimit(client)
+			patch, err := h.Review(context.Background(), createReq)
+
+			assertErr := assert.NoError
+			if test.errCreate != nil {
+				assertErr = assert.Error
+			}
+			assertErr(t, err)
+			assert.Equal(t, test.wantPatch, patch)
+		})
+	}
+}
+
+func TestRateLimit_Review_updateOperation(t *testing.T) {
+	now := metav1.Now()
+
+	updateReq := &admv1.AdmissionRequest{
+		UID: "id",
+		Kind: metav1.GroupVersionKind{
+			Group:   "hub.traefik.io",
+			Version: "v1alpha1",
+			Kind:    "APIRateLimit",
+		},
+		Name:      "name",
+		Operation: admv1.Update,
+		Object: runtime.RawExtension{
+			Raw: mustMarshal(t, hubv1alpha1.APIRateLimit{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "APIRateLimit",
+					APIVersion: "hub.traefik.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "name"},
+				Spec: hubv1alpha1.APIRateLimitSpec{
+					Everyone: true,
+					Limit:    20,
+					Strategy: hubv1alpha1.RateLimitStrategyShared,
+				},
+			}),
+		},
+		OldObject: runtime.RawExtension{
+			Raw: mustMarshal(t, hubv1alpha1.APIRateLimit{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "APIRateLimit",
+					APIVersion: "hub.traefik.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "name"},
+				Spec:       testRateLimitSpec,
+				Status: hubv1alpha1.APIRateLimitStatus{
+					Version: "version-1",
+					APIs:    []string{"api@default"},
+				},
+			}),
+		},
+	}
+
+	wantUpdateReq := &platform.UpdateRateLimitReq{
+		Everyone: true,
+		Limit:    20,
+		Strategy: hubv1alpha1.RateLimitStrategyShared,
+	}
+
+	tests := []struct {
+		desc string
+
+		errUpdate error
+		wantPatch []byte
+	}{
+		{
+			desc: "call rate limit service on update admission request",
+			wantPatch: mustMarshal(t, []patch{
+				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIRateLimitStatus{
+					Version:    "version-2",
+					SyncedAt:   now,
+					Hash:       "cMlFPdimUUO6qEa08jjCXA==",
+					APIs:       []string{"api@default"},
+					Conditions: []metav1.Condition{syncedCondition(now), readyCondition(now)},
+				}},
+			}),
+		},
+		{
+			desc:      "RateLimit service is broken",
+			errUpdate: errors.New("boom"),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			updatedRateLimit := &api.RateLimit{
+				Name:      "name",
+				Everyone:  true,
+				Limit:     20,
+				Strategy:  hubv1alpha1.RateLimitStrategyShared,
+				Version:   "version-2",
+				CreatedAt: time.Now().Add(-time.Hour).UTC().Truncate(time.Millisecond),
+				UpdatedAt: time.Now().UTC().Truncate(time.Millisecond),
+			}
+
+			client := newRateLimitServiceMock(t)
+			client.OnUpdateRateLimit("name", "version-1", wantUpdateReq).TypedReturns(updatedRateLimit, test.errUpdate).Once()
+
+			h := NewRateLimit(client)
+			patch, err := h.Review(context.Background(), updateReq)
+
+			assertErr := assert.NoError
+			if test.errUpdate != nil {
+				assertErr = assert.Error
+			}
+			assertErr(t, err)
+			assert.Equal(t, test.wantPatch, patch)
+		})
+	}
+}
+
+func TestRateLimit_Review_deleteOperation(t *testing.T) {
+	deleteReq := &admv1.AdmissionRequest{
+		UID: "id",
+		Kind: metav1.GroupVersionKind{
+			Group:   "hub.traefik.io",
+			Version: "v1alpha1",
+			Kind:    "APIRateLimit",
+		},
+		Name:      "name",
+		Operation: admv1.Delete,
+		OldObject: runtime.RawExtension{
+			Raw: mustMarshal(t, hubv1alpha1.APIRateLimit{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "APIRateLimit",
+					APIVersion: "hub.traefik.io/v1alpha1",
+				},
+				ObjectMeta: metav1.ObjectMeta{Name: "name"},
+				Spec:       testRateLimitSpec,
+				Status: hubv1alpha1.APIRateLimitStatus{
+					Version: "version-1",
+				},
+			}),
+		},
+	}
+
+	tests := []struct {
+		desc string
+
+		errDelete error
+	}{
+		{
+			desc: "call rate limit service on delete admission request",
+		},
+		{
+			desc:      "RateLimit service is broken",
+			errDelete: errors.New("boom"),
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := newRateLimitServiceMock(t)
+			client.OnDeleteRateLimit("name", "version-1").TypedReturns(test.errDelete).Once()
+
+			h := NewRateLimit(client)
+			patch, err := h.Review(context.Background(), deleteReq)
+			assert.Empty(t, patch)
+
+			assertErr := assert.NoError
+			if test.errDelete != nil {
+				assertErr = assert.Error
+			}
+			assertErr(t, err)
+		})
+	}
+}
+
+func TestRateLimit_CanReview(t *testing.T) {
+	tests := []struct {
+		desc string
+
+		kind metav1.GroupVersionKind
+		want assert.BoolAssertionFunc
+	}{
+		{
+			desc: "return true when it's an APIRateLimit",
+			kind: metav1.GroupVersionKind{Group: "hub.traefik.io", Version: "v1alpha1", Kind: "APIRateLimit"},
+			want: assert.True,
+		},
+		{
+			desc: "return false when it's not an APIRateLimit",
+			kind: metav1.GroupVersionKind{Group: "hub.traefik.io", Version: "v1alpha1", Kind: "APIAccess"},
+			want: assert.False,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h := NewRateLimit(nil)
+			test.want(t, h.CanReview(&admv1.AdmissionRequest{UID: "id", Kind: test.kind, Name: "my-rate-limit"}))
+		})
+	}
+}
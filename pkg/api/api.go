@@ -34,6 +34,16 @@ type API struct {
 	PathPrefix string            `json:"pathPrefix"`
 	Service    Service           `json:"service"`
 
+	VersionHeader *VersionHeader `json:"versionHeader,omitempty"`
+	Matchers      *Matchers      `json:"matchers,omitempty"`
+	Deprecation   *Deprecation   `json:"deprecation,omitempty"`
+	Sandbox       *Sandbox       `json:"sandbox,omitempty"`
+
+	MaxRequestBodyBytes *int64            `json:"maxRequestBodyBytes,omitempty"`
+	AllowedMethods      []string          `json:"allowedMethods,omitempty"`
+	ValidateRequests    bool              `json:"validateRequests,omitempty"`
+	CORS                *hubv1alpha1.CORS `json:"cors,omitempty"`
+
 	Version string `json:"version"`
 
 	CreatedAt time.Time `json:"createdAt"`
@@ -46,6 +56,74 @@ type Service struct {
 	Port int    `json:"port" bson:"port"`
 
 	OpenAPISpec OpenAPISpec `json:"openApiSpec,omitempty" bson:"openApiSpec,omitempty"`
+	ExternalURL string      `json:"externalUrl,omitempty" bson:"externalUrl,omitempty"`
+}
+
+// VersionHeader is the header used to route requests to a version of an API.
+type VersionHeader struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value"`
+}
+
+// Matchers are the header values and query parameters requests must carry to be routed to an API.
+type Matchers struct {
+	Headers []Matcher `json:"headers,omitempty"`
+	Query   []Matcher `json:"query,omitempty"`
+}
+
+// Matcher matches the value of a request header or query parameter.
+type Matcher struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// MatchersFromCRD returns the Matchers described by the given resource.
+func MatchersFromCRD(m *hubv1alpha1.APIMatchers) *Matchers {
+	if m == nil {
+		return nil
+	}
+
+	res := &Matchers{}
+	for _, header := range m.Headers {
+		res.Headers = append(res.Headers, Matcher(header))
+	}
+	for _, query := range m.Query {
+		res.Query = append(res.Query, Matcher(query))
+	}
+
+	return res
+}
+
+func (m *Matchers) resource() *hubv1alpha1.APIMatchers {
+	if m == nil {
+		return nil
+	}
+
+	res := &hubv1alpha1.APIMatchers{}
+	for _, header := range m.Headers {
+		res.Headers = append(res.Headers, hubv1alpha1.APIMatcher(header))
+	}
+	for _, query := range m.Query {
+		res.Query = append(res.Query, hubv1alpha1.APIMatcher(query))
+	}
+
+	return res
+}
+
+// Deprecation marks an API as deprecated.
+type Deprecation struct {
+	Sunset *time.Time `json:"sunset,omitempty"`
+}
+
+// Sandbox is the sandbox environment of an API.
+type Sandbox struct {
+	Service SandboxService `json:"service"`
+}
+
+// SandboxService is the Kubernetes Service serving the sandbox environment of an API.
+type SandboxService struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
 }
 
 // OpenAPISpec is an OpenAPISpec. It can either be fetched from a URL, or Path/Port from the service
@@ -55,10 +133,60 @@ type OpenAPISpec struct {
 
 	Path string `json:"path,omitempty" bson:"path,omitempty"`
 	Port int    `json:"port,omitempty" bson:"port,omitempty"`
+
+	// Headers, Auth and CA are used when fetching the spec.
+	Headers map[string]string `json:"headers,omitempty" bson:"headers,omitempty"`
+	Auth    *OpenAPISpecAuth  `json:"auth,omitempty" bson:"auth,omitempty"`
+	CA      *SecretReference  `json:"ca,omitempty" bson:"ca,omitempty"`
+}
+
+// OpenAPISpecAuth holds the credentials sent when fetching an OpenAPI spec.
+type OpenAPISpecAuth struct {
+	BearerToken *SecretReference           `json:"bearerToken,omitempty" bson:"bearerToken,omitempty"`
+	Headers     map[string]SecretReference `json:"headers,omitempty" bson:"headers,omitempty"`
+}
+
+// OpenAPISpecAuthFromCRD returns the OpenAPISpecAuth described by the given resource.
+func OpenAPISpecAuthFromCRD(auth *hubv1alpha1.OpenAPISpecAuth) *OpenAPISpecAuth {
+	if auth == nil {
+		return nil
+	}
+
+	res := &OpenAPISpecAuth{
+		BearerToken: (*SecretReference)(auth.BearerToken),
+	}
+	if auth.Headers != nil {
+		res.Headers = make(map[string]SecretReference, len(auth.Headers))
+		for name, ref := range auth.Headers {
+			res.Headers[name] = SecretReference(ref)
+		}
+	}
+
+	return res
+}
+
+func (a *OpenAPISpecAuth) resource() *hubv1alpha1.OpenAPISpecAuth {
+	if a == nil {
+		return nil
+	}
+
+	res := &hubv1alpha1.OpenAPISpecAuth{
+		BearerToken: (*hubv1alpha1.SecretReference)(a.BearerToken),
+	}
+	if a.Headers != nil {
+		res.Headers = make(map[string]hubv1alpha1.SecretReference, len(a.Headers))
+		for name, ref := range a.Headers {
+			res.Headers[name] = hubv1alpha1.SecretReference(ref)
+		}
+	}
+
+	return res
 }
 
 // Resource builds the v1alpha1 API resource.
 func (a *API) Resource() (*hubv1alpha1.API, error) {
+	syncedAt := metav1.Now()
+
 	api := &hubv1alpha1.API{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "hub.traefik.io/v1alpha1",
@@ -77,14 +205,22 @@ func (a *API) Resource() (*hubv1alpha1.API, error) {
 					Number: int32(a.Service.Port),
 				},
 				OpenAPISpec: hubv1alpha1.OpenAPISpec{
-					URL:  a.Service.OpenAPISpec.URL,
-					Path: a.Service.OpenAPISpec.Path,
+					URL:     a.Service.OpenAPISpec.URL,
+					Path:    a.Service.OpenAPISpec.Path,
+					Headers: a.Service.OpenAPISpec.Headers,
+					Auth:    a.Service.OpenAPISpec.Auth.resource(),
+					CA:      (*hubv1alpha1.SecretReference)(a.Service.OpenAPISpec.CA),
 				},
+				ExternalURL: a.Service.ExternalURL,
 			},
 		},
 		Status: hubv1alpha1.APIStatus{
 			Version:  a.Version,
-			SyncedAt: metav1.Now(),
+			SyncedAt: syncedAt,
+			Conditions: []metav1.Condition{
+				syncedCondition(syncedAt),
+				readyCondition(syncedAt),
+			},
 		},
 	}
 
@@ -94,6 +230,39 @@ func (a *API) Resource() (*hubv1alpha1.API, error) {
 		}
 	}
 
+	if a.VersionHeader != nil {
+		api.Spec.VersionHeader = &hubv1alpha1.APIVersionHeader{
+			Name:  a.VersionHeader.Name,
+			Value: a.VersionHeader.Value,
+		}
+	}
+
+	api.Spec.Matchers = a.Matchers.resource()
+
+	if a.Deprecation != nil {
+		api.Spec.Deprecation = &hubv1alpha1.APIDeprecation{}
+		if a.Deprecation.Sunset != nil {
+			sunset := metav1.NewTime(*a.Deprecation.Sunset)
+			api.Spec.Deprecation.Sunset = &sunset
+		}
+	}
+
+	if a.Sandbox != nil {
+		api.Spec.Sandbox = &hubv1alpha1.APISandbox{
+			Service: hubv1alpha1.APISandboxService{
+				Name: a.Sandbox.Service.Name,
+				Port: hubv1alpha1.APIServiceBackendPort{
+					Number: int32(a.Sandbox.Service.Port),
+				},
+			},
+		}
+	}
+
+	api.Spec.MaxRequestBodyBytes = a.MaxRequestBodyBytes
+	api.Spec.AllowedMethods = a.AllowedMethods
+	api.Spec.ValidateRequests = a.ValidateRequests
+	api.Spec.CORS = a.CORS
+
 	apiHash, err := HashAPI(api)
 	if err != nil {
 		return nil, fmt.Errorf("compute API hash: %w", err)
@@ -105,17 +274,35 @@ func (a *API) Resource() (*hubv1alpha1.API, error) {
 }
 
 type apiHash struct {
-	PathPrefix string                 `json:"pathPrefix,omitempty"`
-	Service    hubv1alpha1.APIService `json:"service"`
-	Labels     sortedMap[string]      `json:"labels,omitempty"`
+	PathPrefix    string                        `json:"pathPrefix,omitempty"`
+	Service       hubv1alpha1.APIService        `json:"service"`
+	VersionHeader *hubv1alpha1.APIVersionHeader `json:"versionHeader,omitempty"`
+	Matchers      *hubv1alpha1.APIMatchers      `json:"matchers,omitempty"`
+	Deprecation   *hubv1alpha1.APIDeprecation   `json:"deprecation,omitempty"`
+	Sandbox       *hubv1alpha1.APISandbox       `json:"sandbox,omitempty"`
+	Labels        sortedMap[string]             `json:"labels,omitempty"`
+
+	MaxRequestBodyBytes *int64            `json:"maxRequestBodyBytes,omitempty"`
+	AllowedMethods      []string          `json:"allowedMethods,omitempty"`
+	ValidateRequests    bool              `json:"validateRequests,omitempty"`
+	CORS                *hubv1alpha1.CORS `json:"cors,omitempty"`
 }
 
 // HashAPI generates the hash of the API.
 func HashAPI(a *hubv1alpha1.API) (string, error) {
 	ah := apiHash{
-		PathPrefix: a.Spec.PathPrefix,
-		Service:    a.Spec.Service,
-		Labels:     newSortedMap(a.Labels),
+		PathPrefix:    a.Spec.PathPrefix,
+		Service:       a.Spec.Service,
+		VersionHeader: a.Spec.VersionHeader,
+		Matchers:      a.Spec.Matchers,
+		Deprecation:   a.Spec.Deprecation,
+		Sandbox:       a.Spec.Sandbox,
+		Labels:        newSortedMap(a.Labels),
+
+		MaxRequestBodyBytes: a.Spec.MaxRequestBodyBytes,
+		AllowedMethods:      a.Spec.AllowedMethods,
+		ValidateRequests:    a.Spec.ValidateRequests,
+		CORS:                a.Spec.CORS,
 	}
 
 	hash, err := sum(ah)
@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// APIVersion is a version of an API, exposed next to it with its own path prefix or version header.
+type APIVersion struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+
+	APIName       string          `json:"apiName"`
+	Release       string          `json:"release"`
+	PathPrefix    string          `json:"pathPrefix,omitempty"`
+	VersionHeader *VersionHeader  `json:"versionHeader,omitempty"`
+	Service       *VersionService `json:"service,omitempty"`
+	Deprecation   *Deprecation    `json:"deprecation,omitempty"`
+
+	Version string `json:"version"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// VersionService is the Kubernetes Service serving a version of an API.
+type VersionService struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+// Resource builds the v1alpha1 APIVersion resource.
+func (v *APIVersion) Resource() (*hubv1alpha1.APIVersion, error) {
+	syncedAt := metav1.Now()
+
+	version := &hubv1alpha1.APIVersion{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "APIVersion",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      v.Name,
+			Namespace: v.Namespace,
+			Labels:    v.Labels,
+		},
+		Spec: hubv1alpha1.APIVersionSpec{
+			APIName:    v.APIName,
+			Release:    v.Release,
+			PathPrefix: v.PathPrefix,
+		},
+		Status: hubv1alpha1.APIVersionStatus{
+			Version:  v.Version,
+			SyncedAt: syncedAt,
+			Conditions: []metav1.Condition{
+				syncedCondition(syncedAt),
+				readyCondition(syncedAt),
+			},
+		},
+	}
+
+	if v.VersionHeader != nil {
+		version.Spec.VersionHeader = &hubv1alpha1.APIVersionHeader{
+			Name:  v.VersionHeader.Name,
+			Value: v.VersionHeader.Value,
+		}
+	}
+
+	if v.Service != nil {
+		version.Spec.Service = &hubv1alpha1.APIVersionService{
+			Name: v.Service.Name,
+			Port: hubv1alpha1.APIServiceBackendPort{
+				Number: int32(v.Service.Port),
+			},
+		}
+	}
+
+	if v.Deprecation != nil {
+		version.Spec.Deprecation = &hubv1alpha1.APIDeprecation{}
+		if v.Deprecation.Sunset != nil {
+			sunset := metav1.NewTime(*v.Deprecation.Sunset)
+			version.Spec.Deprecation.Sunset = &sunset
+		}
+	}
+
+	h, err := HashAPIVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("compute APIVersion hash: %w", err)
+	}
+
+	version.Status.Hash = h
+
+	return version, nil
+}
+
+type apiVersionHash struct {
+	APIName       string                         `json:"apiName"`
+	Release       string                         `json:"release"`
+	PathPrefix    string                         `json:"pathPrefix,omitempty"`
+	VersionHeader *hubv1alpha1.APIVersionHeader  `json:"versionHeader,omitempty"`
+	Service       *hubv1alpha1.APIVersionService `json:"service,omitempty"`
+	Deprecation   *hubv1alpha1.APIDeprecation    `json:"deprecation,omitempty"`
+	Labels        sortedMap[string]              `json:"labels,omitempty"`
+}
+
+// HashAPIVersion generates the hash of the APIVersion.
+func HashAPIVersion(v *hubv1alpha1.APIVersion) (string, error) {
+	vh := apiVersionHash{
+		APIName:       v.Spec.APIName,
+		Release:       v.Spec.Release,
+		PathPrefix:    v.Spec.PathPrefix,
+		VersionHeader: v.Spec.VersionHeader,
+		Service:       v.Spec.Service,
+		Deprecation:   v.Spec.Deprecation,
+		Labels:        newSortedMap(v.Labels),
+	}
+
+	hash, err := sum(vh)
+	if err != nil {
+		return "", fmt.Errorf("sum object: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(hash), nil
+}
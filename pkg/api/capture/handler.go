@@ -0,0 +1,71 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package capture
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Handler exposes the exchanges captured by a Recorder.
+//
+//	GET    /?api=name@namespace  lists the exchanges captured for an API, from the oldest to the newest.
+//	GET    /                     lists the APIs having captured exchanges.
+//	DELETE /?api=name@namespace  drops the exchanges captured for an API.
+type Handler struct {
+	recorder *Recorder
+}
+
+// NewHandler returns a new Handler.
+func NewHandler(recorder *Recorder) *Handler {
+	return &Handler{recorder: recorder}
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	api := req.URL.Query().Get("api")
+
+	switch req.Method {
+	case http.MethodGet:
+		if api == "" {
+			writeJSON(rw, h.recorder.APIs())
+			return
+		}
+
+		writeJSON(rw, h.recorder.Exchanges(api))
+	case http.MethodDelete:
+		if api == "" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		h.recorder.Clear(api)
+		rw.WriteHeader(http.StatusNoContent)
+	default:
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Unable to encode captured exchanges")
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package capture
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hublistersv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+)
+
+// AnnotationSampleRate enables the capture of the traffic of the API it is set on. Its value is the percentage of
+// requests to capture, between 0 and 100.
+const AnnotationSampleRate = "hub.traefik.io/capture-sample-rate"
+
+// HeaderAPI is set by the API gateway on the requests sent to the capture proxy. Its value is the API the request
+// targets, in the "name@namespace" format.
+const HeaderAPI = "X-Hub-Capture-API"
+
+// SampleRate returns the percentage of requests to capture for the given API, and whether the capture is enabled.
+func SampleRate(api *hubv1alpha1.API) (float64, bool) {
+	value, ok := api.Annotations[AnnotationSampleRate]
+	if !ok {
+		return 0, false
+	}
+
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate <= 0 {
+		return 0, false
+	}
+
+	if rate > 100 {
+		rate = 100
+	}
+
+	return rate, true
+}
+
+// Proxy is a reverse proxy forwarding requests to the service of the API they target and recording a sample of
+// them. The API service is resolved from the API resource so the proxy can only reach services exposed as APIs.
+type Proxy struct {
+	apis      hublistersv1alpha1.APILister
+	services  corelistersv1.ServiceLister
+	recorder  *Recorder
+	transport http.RoundTripper
+
+	// sample returns a number in [0,100) compared against the API sample rate.
+	sample func() float64
+}
+
+// NewProxy returns a new Proxy recording the exchanges it samples in the given recorder.
+func NewProxy(apis hublistersv1alpha1.APILister, services corelistersv1.ServiceLister, recorder *Recorder) *Proxy {
+	return &Proxy{
+		apis:      apis,
+		services:  services,
+		recorder:  recorder,
+		transport: http.DefaultTransport,
+		sample: func() float64 {
+			return rand.Float64() * 100 //nolint:gosec // No need for crypto randomness to sample requests.
+		},
+	}
+}
+
+func (p *Proxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	apiKey := req.Header.Get(HeaderAPI)
+	req.Header.Del(HeaderAPI)
+
+	logger := log.With().Str("api", apiKey).Logger()
+
+	name, namespace, ok := strings.Cut(apiKey, "@")
+	if !ok {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	api, err := p.apis.APIs(namespace).Get(name)
+	if err != nil {
+		if kerror.IsNotFound(err) {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		logger.Error().Err(err).Msg("Unable to get API")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	upstream, err := p.upstream(api)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to resolve API upstream")
+		rw.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	var proxyErr error
+	proxy := &httputil.ReverseProxy{
+		Director: func(outReq *http.Request) {
+			outReq.URL.Scheme = "http"
+			outReq.URL.Host = upstream
+		},
+		Transport: p.transport,
+		ErrorHandler: func(rw http.ResponseWriter, _ *http.Request, err error) {
+			proxyErr = err
+			logger.Error().Err(err).Msg("Unable to reach API upstream")
+			rw.WriteHeader(http.StatusBadGateway)
+		},
+	}
+
+	rate, ok := SampleRate(api)
+	if !ok || p.sample() >= rate {
+		proxy.ServeHTTP(rw, req)
+		return
+	}
+
+	exchange := Exchange{
+		Time:           time.Now(),
+		Method:         req.Method,
+		Host:           req.Host,
+		Path:           req.URL.Path,
+		Query:          scrubQuery(req.URL.RawQuery),
+		ClientIP:       scrubIP(clientIP(req)),
+		RequestHeaders: scrubHeaders(req.Header),
+	}
+
+	body := &countingReadCloser{ReadCloser: req.Body}
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = body
+	}
+
+	crw := &countingResponseWriter{ResponseWriter: rw}
+	proxy.ServeHTTP(crw, req)
+
+	exchange.Duration = time.Since(exchange.Time)
+	exchange.RequestSize = body.n
+	exchange.StatusCode = crw.statusCode()
+	exchange.ResponseHeaders = scrubHeaders(crw.Header())
+	exchange.ResponseSize = crw.n
+	if proxyErr != nil {
+		exchange.Error = proxyErr.Error()
+	}
+
+	p.recorder.Record(apiKey, exchange)
+}
+
+// upstream returns the address of the service of the given API.
+func (p *Proxy) upstream(api *hubv1alpha1.API) (string, error) {
+	port := api.Spec.Service.Port.Number
+	if port == 0 {
+		svc, err := p.services.Services(api.Namespace).Get(api.Spec.Service.Name)
+		if err != nil {
+			return "", fmt.Errorf("get service: %w", err)
+		}
+
+		for _, svcPort := range svc.Spec.Ports {
+			if svcPort.Name == api.Spec.Service.Port.Name {
+				port = svcPort.Port
+				break
+			}
+		}
+
+		if port == 0 {
+			return "", fmt.Errorf("service port %q not found", api.Spec.Service.Port.Name)
+		}
+	}
+
+	host := fmt.Sprintf("%s.%s.svc", api.Spec.Service.Name, api.Namespace)
+
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+// clientIP returns the IP of the client which sent the request to the API gateway.
+func clientIP(req *http.Request) string {
+	if xff := req.Header.Get("X-Forwarded-For"); xff != "" {
+		ip, _, _ := strings.Cut(xff, ",")
+		return strings.TrimSpace(ip)
+	}
+
+	return req.RemoteAddr
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+type countingResponseWriter struct {
+	http.ResponseWriter
+
+	code int
+	n    int64
+}
+
+func (c *countingResponseWriter) WriteHeader(code int) {
+	if c.code == 0 {
+		c.code = code
+	}
+
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *countingResponseWriter) Write(p []byte) (int, error) {
+	if c.code == 0 {
+		c.code = http.StatusOK
+	}
+
+	n, err := c.ResponseWriter.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// Flush implements http.Flusher, allowing streamed responses to be proxied.
+func (c *countingResponseWriter) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (c *countingResponseWriter) statusCode() int {
+	if c.code == 0 {
+		return http.StatusOK
+	}
+
+	return c.code
+}
@@ -0,0 +1,173 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package capture
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hublistersv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestProxy_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc          string
+		apiHeader     string
+		annotations   map[string]string
+		port          hubv1alpha1.APIServiceBackendPort
+		wantStatus    int
+		wantUpstream  string
+		wantExchanges int
+	}{
+		{
+			desc:          "captured request",
+			apiHeader:     "books@library",
+			annotations:   map[string]string{AnnotationSampleRate: "100"},
+			port:          hubv1alpha1.APIServiceBackendPort{Number: 8080},
+			wantStatus:    http.StatusCreated,
+			wantUpstream:  "books-svc.library.svc:8080",
+			wantExchanges: 1,
+		},
+		{
+			desc:          "captured request on a named port",
+			apiHeader:     "books@library",
+			annotations:   map[string]string{AnnotationSampleRate: "100"},
+			port:          hubv1alpha1.APIServiceBackendPort{Name: "http"},
+			wantStatus:    http.StatusCreated,
+			wantUpstream:  "books-svc.library.svc:9090",
+			wantExchanges: 1,
+		},
+		{
+			desc:         "request not sampled",
+			apiHeader:    "books@library",
+			annotations:  map[string]string{AnnotationSampleRate: "10"},
+			port:         hubv1alpha1.APIServiceBackendPort{Number: 8080},
+			wantStatus:   http.StatusCreated,
+			wantUpstream: "books-svc.library.svc:8080",
+		},
+		{
+			desc:         "capture disabled",
+			apiHeader:    "books@library",
+			port:         hubv1alpha1.APIServiceBackendPort{Number: 8080},
+			wantStatus:   http.StatusCreated,
+			wantUpstream: "books-svc.library.svc:8080",
+		},
+		{
+			desc:       "unknown API",
+			apiHeader:  "unknown@library",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "missing API header",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Empty(t, req.Header.Get(HeaderAPI))
+				assert.Equal(t, "/books/1", req.URL.Path)
+
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "hello", string(body))
+
+				rw.Header().Set("Set-Cookie", "session=secret")
+				rw.WriteHeader(http.StatusCreated)
+				_, _ = rw.Write([]byte("created"))
+			}))
+			t.Cleanup(upstream.Close)
+
+			apiIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, apiIndexer.Add(&hubv1alpha1.API{
+				ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "library", Annotations: test.annotations},
+				Spec: hubv1alpha1.APISpec{
+					PathPrefix: "/books",
+					Service:    hubv1alpha1.APIService{Name: "books-svc", Port: test.port},
+				},
+			}))
+
+			svcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, svcIndexer.Add(&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "books-svc", Namespace: "library"},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 9090}},
+				},
+			}))
+
+			var gotUpstream string
+			recorder := NewRecorder(10)
+			proxy := NewProxy(hublistersv1alpha1.NewAPILister(apiIndexer), corelistersv1.NewServiceLister(svcIndexer), recorder)
+			proxy.sample = func() float64 { return 50 }
+			proxy.transport = &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					gotUpstream = addr
+					return (&net.Dialer{}).DialContext(ctx, network, upstream.Listener.Addr().String())
+				},
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "http://api.example.com/books/1?token=secret", strings.NewReader("hello"))
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("X-Forwarded-For", "10.0.0.42, 10.0.0.1")
+			if test.apiHeader != "" {
+				req.Header.Set(HeaderAPI, test.apiHeader)
+			}
+
+			rw := httptest.NewRecorder()
+			proxy.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.wantStatus, rw.Code)
+			assert.Equal(t, test.wantUpstream, gotUpstream)
+
+			exchanges := recorder.Exchanges(test.apiHeader)
+			require.Len(t, exchanges, test.wantExchanges)
+			if test.wantExchanges == 0 {
+				return
+			}
+
+			exchange := exchanges[0]
+			assert.Equal(t, http.MethodPost, exchange.Method)
+			assert.Equal(t, "api.example.com", exchange.Host)
+			assert.Equal(t, "/books/1", exchange.Path)
+			assert.Equal(t, "token=REDACTED", exchange.Query)
+			assert.Equal(t, "10.0.0.0", exchange.ClientIP)
+			assert.Equal(t, []string{"REDACTED"}, exchange.RequestHeaders["Authorization"])
+			assert.Equal(t, int64(5), exchange.RequestSize)
+			assert.Equal(t, http.StatusCreated, exchange.StatusCode)
+			assert.Equal(t, []string{"REDACTED"}, exchange.ResponseHeaders["Set-Cookie"])
+			assert.Equal(t, int64(7), exchange.ResponseSize)
+			assert.Empty(t, exchange.Error)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package capture
+
+import (
+	"sync"
+	"time"
+)
+
+// Exchange holds the metadata of a captured request and its response.
+// Bodies are never captured, only their size.
+type Exchange struct {
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+
+	Method         string              `json:"method"`
+	Host           string              `json:"host"`
+	Path           string              `json:"path"`
+	Query          string              `json:"query,omitempty"`
+	ClientIP       string              `json:"clientIp,omitempty"`
+	RequestHeaders map[string][]string `json:"requestHeaders,omitempty"`
+	RequestSize    int64               `json:"requestSize"`
+
+	StatusCode      int                 `json:"statusCode"`
+	ResponseHeaders map[string][]string `json:"responseHeaders,omitempty"`
+	ResponseSize    int64               `json:"responseSize"`
+	Error           string              `json:"error,omitempty"`
+}
+
+// Recorder keeps the last captured exchanges of each API in ring buffers.
+type Recorder struct {
+	size int
+
+	mu      sync.RWMutex
+	buffers map[string]*ring
+}
+
+// NewRecorder returns a new Recorder keeping at most size exchanges per API.
+func NewRecorder(size int) *Recorder {
+	return &Recorder{
+		size:    size,
+		buffers: make(map[string]*ring),
+	}
+}
+
+// Record records the given exchange for the given API, evicting the oldest one if the API buffer is full.
+func (r *Recorder) Record(api string, exchange Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buf, ok := r.buffers[api]
+	if !ok {
+		buf = &ring{exchanges: make([]Exchange, r.size)}
+		r.buffers[api] = buf
+	}
+
+	buf.push(exchange)
+}
+
+// Exchanges returns the exchanges captured for the given API, from the oldest to the newest.
+func (r *Recorder) Exchanges(api string) []Exchange {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	buf, ok := r.buffers[api]
+	if !ok {
+		return []Exchange{}
+	}
+
+	return buf.list()
+}
+
+// APIs returns the APIs having captured exchanges, in lexicographic order.
+func (r *Recorder) APIs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return sortedKeys(r.buffers)
+}
+
+// Clear drops the exchanges captured for the given API.
+func (r *Recorder) Clear(api string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.buffers, api)
+}
+
+type ring struct {
+	exchanges []Exchange
+	next      int
+	full      bool
+}
+
+func (r *ring) push(exchange Exchange) {
+	if len(r.exchanges) == 0 {
+		return
+	}
+
+	r.exchanges[r.next] = exchange
+	r.next = (r.next + 1) % len(r.exchanges)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *ring) list() []Exchange {
+	if !r.full {
+		return append([]Exchange{}, r.exchanges[:r.next]...)
+	}
+
+	exchanges := make([]Exchange, 0, len(r.exchanges))
+	exchanges = append(exchanges, r.exchanges[r.next:]...)
+
+	return append(exchanges, r.exchanges[:r.next]...)
+}
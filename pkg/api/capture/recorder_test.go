@@ -0,0 +1,83 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package capture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder_Record(t *testing.T) {
+	tests := []struct {
+		desc      string
+		size      int
+		recorded  []string
+		wantPaths []string
+	}{
+		{
+			desc:      "buffer not full",
+			size:      3,
+			recorded:  []string{"/1", "/2"},
+			wantPaths: []string{"/1", "/2"},
+		},
+		{
+			desc:      "buffer full",
+			size:      3,
+			recorded:  []string{"/1", "/2", "/3"},
+			wantPaths: []string{"/1", "/2", "/3"},
+		},
+		{
+			desc:      "oldest exchanges are evicted",
+			size:      3,
+			recorded:  []string{"/1", "/2", "/3", "/4", "/5"},
+			wantPaths: []string{"/3", "/4", "/5"},
+		},
+		{
+			desc:      "empty buffer",
+			size:      0,
+			recorded:  []string{"/1"},
+			wantPaths: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := NewRecorder(test.size)
+			for _, path := range test.recorded {
+				recorder.Record("api@ns", Exchange{Path: path})
+				recorder.Record("other@ns", Exchange{Path: "/other"})
+			}
+
+			gotPaths := []string{}
+			for _, exchange := range recorder.Exchanges("api@ns") {
+				gotPaths = append(gotPaths, exchange.Path)
+			}
+
+			assert.Equal(t, test.wantPaths, gotPaths)
+			assert.Equal(t, []string{"api@ns", "other@ns"}, recorder.APIs())
+
+			recorder.Clear("api@ns")
+			assert.Empty(t, recorder.Exchanges("api@ns"))
+			assert.Equal(t, []string{"other@ns"}, recorder.APIs())
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package capture
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders are headers known to carry credentials or personal data.
+var sensitiveHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"Cookie":              {},
+	"Set-Cookie":          {},
+	"X-Forwarded-For":     {},
+	"X-Real-Ip":           {},
+	"Forwarded":           {},
+}
+
+// sensitiveKeywords are looked for in header names and query parameter names to detect custom credentials.
+var sensitiveKeywords = []string{"auth", "token", "secret", "key", "password", "session", "email"}
+
+// scrubHeaders returns a copy of the given headers with the values of sensitive headers redacted.
+func scrubHeaders(headers http.Header) map[string][]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	scrubbed := make(map[string][]string, len(headers))
+	for name, values := range headers {
+		if isSensitive(name) {
+			scrubbed[name] = []string{redacted}
+			continue
+		}
+
+		scrubbed[name] = append([]string{}, values...)
+	}
+
+	return scrubbed
+}
+
+// scrubQuery returns the given raw query with the values of sensitive parameters redacted.
+func scrubQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redacted
+	}
+
+	for name := range query {
+		if isSensitive(name) {
+			query[name] = []string{redacted}
+		}
+	}
+
+	return query.Encode()
+}
+
+// scrubIP anonymizes the given address by zeroing the last octet of IPv4 addresses and the last 80 bits of IPv6
+// addresses.
+func scrubIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+func isSensitive(name string) bool {
+	if _, ok := sensitiveHeaders[http.CanonicalHeaderKey(name)]; ok {
+		return true
+	}
+
+	lower := strings.ToLower(name)
+	for _, keyword := range sensitiveKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedKeys returns the keys of the given map in lexicographic order.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,121 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package capture
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubHeaders(t *testing.T) {
+	headers := http.Header{
+		"Authorization":  {"Bearer secret"},
+		"Cookie":         {"session=secret"},
+		"X-Api-Key":      {"secret"},
+		"X-Auth-User":    {"bob@example.com"},
+		"Content-Type":   {"application/json"},
+		"Accept-Version": {"v1", "v2"},
+	}
+
+	got := scrubHeaders(headers)
+
+	assert.Equal(t, map[string][]string{
+		"Authorization":  {"REDACTED"},
+		"Cookie":         {"REDACTED"},
+		"X-Api-Key":      {"REDACTED"},
+		"X-Auth-User":    {"REDACTED"},
+		"Content-Type":   {"application/json"},
+		"Accept-Version": {"v1", "v2"},
+	}, got)
+	assert.Equal(t, []string{"Bearer secret"}, headers["Authorization"])
+}
+
+func TestScrubQuery(t *testing.T) {
+	tests := []struct {
+		desc  string
+		query string
+		want  string
+	}{
+		{
+			desc: "empty query",
+		},
+		{
+			desc:  "query without sensitive parameters",
+			query: "page=2&sort=name",
+			want:  "page=2&sort=name",
+		},
+		{
+			desc:  "query with sensitive parameters",
+			query: "page=2&access_token=secret&apiKey=secret&email=bob%40example.com",
+			want:  "access_token=REDACTED&apiKey=REDACTED&email=REDACTED&page=2",
+		},
+		{
+			desc:  "invalid query",
+			query: "token=%zz",
+			want:  "REDACTED",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, scrubQuery(test.query))
+		})
+	}
+}
+
+func TestScrubIP(t *testing.T) {
+	tests := []struct {
+		desc string
+		addr string
+		want string
+	}{
+		{
+			desc: "IPv4",
+			addr: "192.168.1.42",
+			want: "192.168.1.0",
+		},
+		{
+			desc: "IPv4 with port",
+			addr: "192.168.1.42:4242",
+			want: "192.168.1.0",
+		},
+		{
+			desc: "IPv6",
+			addr: "2001:db8:85a3:8d3:1319:8a2e:370:7348",
+			want: "2001:db8:85a3::",
+		},
+		{
+			desc: "invalid address",
+			addr: "invalid",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, scrubIP(test.addr))
+		})
+	}
+}
@@ -43,6 +43,8 @@ type Collection struct {
 
 // Resource builds the v1alpha1 Collection resource.
 func (c *Collection) Resource() (*hubv1alpha1.APICollection, error) {
+	syncedAt := metav1.Now()
+
 	collection := &hubv1alpha1.APICollection{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "hub.traefik.io/v1alpha1",
@@ -58,7 +60,11 @@ func (c *Collection) Resource() (*hubv1alpha1.APICollection, error) {
 		},
 		Status: hubv1alpha1.APICollectionStatus{
 			Version:  c.Version,
-			SyncedAt: metav1.Now(),
+			SyncedAt: syncedAt,
+			Conditions: []metav1.Condition{
+				syncedCondition(syncedAt),
+				readyCondition(syncedAt),
+			},
 		},
 	}
 
@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// syncedCondition returns the Synced condition of a resource synchronized with the platform at the given time.
+func syncedCondition(syncedAt metav1.Time) metav1.Condition {
+	return hubv1alpha1.NewCondition(hubv1alpha1.ConditionSynced, metav1.ConditionTrue, hubv1alpha1.ReasonSynced,
+		"Resource is synchronized with the platform", syncedAt)
+}
+
+// readyCondition returns the Ready condition of a resource which needs nothing more than a platform sync to be ready.
+func readyCondition(syncedAt metav1.Time) metav1.Condition {
+	return hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionTrue, hubv1alpha1.ReasonReady,
+		"Resource is ready", syncedAt)
+}
+
+// certificateProvisionedCondition returns the CertificateProvisioned condition matching the given provisioning error.
+func certificateProvisionedCondition(err error) metav1.Condition {
+	if err != nil {
+		return hubv1alpha1.NewCondition(hubv1alpha1.ConditionCertificateProvisioned, metav1.ConditionFalse,
+			hubv1alpha1.ReasonCertificateProvisioningFailed, err.Error(), metav1.Time{})
+	}
+
+	return hubv1alpha1.NewCondition(hubv1alpha1.ConditionCertificateProvisioned, metav1.ConditionTrue,
+		hubv1alpha1.ReasonCertificateProvisioned, "Certificates are provisioned", metav1.Time{})
+}
+
+// openAPISpecValidCondition returns the OpenAPISpecValid condition matching the given spec fetching or validation
+// error.
+func openAPISpecValidCondition(err error) metav1.Condition {
+	switch {
+	case err == nil:
+		return hubv1alpha1.NewCondition(hubv1alpha1.ConditionOpenAPISpecValid, metav1.ConditionTrue,
+			hubv1alpha1.ReasonOpenAPISpecValid, "OpenAPI spec is valid", metav1.Time{})
+	case errors.Is(err, openapi.ErrInvalidSpec):
+		return hubv1alpha1.NewCondition(hubv1alpha1.ConditionOpenAPISpecValid, metav1.ConditionFalse,
+			hubv1alpha1.ReasonOpenAPISpecInvalid, err.Error(), metav1.Time{})
+	default:
+		return hubv1alpha1.NewCondition(hubv1alpha1.ConditionOpenAPISpecValid, metav1.ConditionFalse,
+			hubv1alpha1.ReasonOpenAPISpecUnavailable, err.Error(), metav1.Time{})
+	}
+}
+
+// notReadyCondition returns the Ready condition of a resource which failed to be set up for the given reason.
+func notReadyCondition(reason, message string) metav1.Condition {
+	return hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionFalse, reason, message, metav1.Time{})
+}
+
+// isSynced returns whether the given conditions report the resource as synced. Resources created before conditions
+// were introduced have none, and must be updated to report them even if their version didn't change.
+func isSynced(conditions []metav1.Condition) bool {
+	return apimeta.IsStatusConditionTrue(conditions, hubv1alpha1.ConditionSynced)
+}
+
+// conditionsPatch returns a JSON patch replacing the status conditions with the given ones.
+func conditionsPatch(conditions []metav1.Condition) ([]byte, error) {
+	patch, err := json.Marshal([]struct {
+		Op    string             `json:"op"`
+		Path  string             `json:"path"`
+		Value []metav1.Condition `json:"value"`
+	}{
+		{Op: "add", Path: "/status/conditions", Value: conditions},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal conditions patch: %w", err)
+	}
+
+	return patch, nil
+}
@@ -22,57 +22,85 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
-	"github.com/hashicorp/go-retryablehttp"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
-	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 )
 
 const (
 	headerHubGroups = "Hub-Groups"
 	headerHubEmail  = "Hub-Email"
+
+	maxAPIKeyNameLength = 100
 )
 
 // PortalAPI is a handler that exposes APIPortal information.
 type PortalAPI struct {
-	router     chi.Router
-	httpClient *http.Client
-	platform   PlatformClient
+	router   chi.Router
+	platform PlatformClient
+	specs    SpecFetcher
+	history  *SpecHistory
+	quotas   QuotaGetter
+	tryIt    *TryItProxy
+	keys     *APIKeyStore
+	usage    UsageGetter
+	catalog  *catalog
 
 	portal *portal
 }
 
 // NewPortalAPI creates a new PortalAPI handler.
-func NewPortalAPI(portal *portal, platformClient PlatformClient) (*PortalAPI, error) {
-	client := retryablehttp.NewClient()
-	client.RetryMax = 4
-	client.Logger = logwrapper.NewRetryableHTTPWrapper(log.Logger.With().
-		Str("component", "portal_api").
-		Logger())
-
+// The history of the API specs is only kept when a SpecHistory is given, the quota usages are only served when a
+// QuotaGetter is given, the APIs can only be tried out when a TryItProxy is given, API keys are only issued to the
+// users when an APIKeyStore is given, and the API usages are only served when a UsageGetter is given.
+func NewPortalAPI(portal *portal, platformClient PlatformClient, specs SpecFetcher, history *SpecHistory, quotas QuotaGetter, tryIt *TryItProxy, keys *APIKeyStore, usage UsageGetter) (*PortalAPI, error) {
 	p := &PortalAPI{
-		router:     chi.NewRouter(),
-		httpClient: client.StandardClient(),
-		platform:   platformClient,
-		portal:     portal,
+		router:   chi.NewRouter(),
+		platform: platformClient,
+		specs:    specs,
+		history:  history,
+		quotas:   quotas,
+		tryIt:    tryIt,
+		keys:     keys,
+		usage:    usage,
+		catalog:  newCatalog(specs),
+		portal:   portal,
 	}
 
 	p.router.Get("/apis", p.handleListAPIs)
+	p.router.Get("/search", p.handleSearchAPIs)
+	p.router.Get("/tags", p.handleListTags)
 	p.router.Get("/apis/{api}", p.handleGetAPISpec)
 	p.router.Get("/collections/{collection}/apis/{api}", p.handleGetCollectionAPISpec)
+	p.router.Get("/apis/{api}/versions", p.handleListAPISpecVersions)
+	p.router.Get("/apis/{api}/diff", p.handleDiffAPISpecVersions)
+	p.router.Get("/collections/{collection}/apis/{api}/versions", p.handleListAPISpecVersions)
+	p.router.Get("/collections/{collection}/apis/{api}/diff", p.handleDiffAPISpecVersions)
+	p.router.Get("/apis/{api}/usage", p.handleGetAPIUsage)
+	p.router.Get("/collections/{collection}/apis/{api}/usage", p.handleGetAPIUsage)
+	p.router.HandleFunc("/apis/{api}/try/*", p.handleTryAPI)
+	p.router.HandleFunc("/collections/{collection}/apis/{api}/try/*", p.handleTryAPI)
 	p.router.Get("/tokens", p.handleListTokens)
 	p.router.Post("/tokens", p.handleCreateToken)
 	p.router.Post("/tokens/suspend", p.handleSuspendToken)
 	p.router.Delete("/tokens", p.handleDeleteToken)
+	p.router.Get("/quotas", p.handleListQuotas)
+	p.router.Get("/keys", p.handleListAPIKeys)
+	p.router.Post("/keys", p.handleCreateAPIKey)
+	p.router.Delete("/keys/{key}", p.handleRevokeAPIKey)
 
 	return p, nil
 }
@@ -115,6 +143,39 @@ func (p *PortalAPI) handleListTokens(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (p *PortalAPI) handleListQuotas(rw http.ResponseWriter, r *http.Request) {
+	if p.quotas == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logger := log.With().Str("portal_name", p.portal.Name).Logger()
+
+	userEmail := r.Header.Get(headerHubEmail)
+	if userEmail == "" {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	usages, err := p.quotas.GetQuotas(r.Context(), userEmail)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get quota usages")
+		rw.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	if usages == nil {
+		usages = make([]apikey.QuotaUsage, 0)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(usages); err != nil {
+		logger.Error().Err(err).Msg("Write list quotas response")
+	}
+}
+
 type createTokenReq struct {
 	Name string `json:"name"`
 }
@@ -229,19 +290,251 @@ func (p *PortalAPI) handleDeleteToken(rw http.ResponseWriter, r *http.Request) {
 	rw.WriteHeader(http.StatusNoContent)
 }
 
+func (p *PortalAPI) handleListAPIKeys(rw http.ResponseWriter, r *http.Request) {
+	if p.keys == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logger := log.With().Str("portal_name", p.portal.Name).Logger()
+
+	userEmail := r.Header.Get(headerHubEmail)
+	if userEmail == "" {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	keys, err := p.keys.List(r.Context(), p.portal.Name, userEmail)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to list API keys")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(keys); err != nil {
+		logger.Error().Err(err).Msg("Write list API keys response")
+	}
+}
+
+type createAPIKeyReq struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type createAPIKeyResp struct {
+	APIKey
+
+	Key string `json:"key"`
+}
+
+func (p *PortalAPI) handleCreateAPIKey(rw http.ResponseWriter, r *http.Request) {
+	if p.keys == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logger := log.With().Str("portal_name", p.portal.Name).Logger()
+
+	userEmail := r.Header.Get(headerHubEmail)
+	if userEmail == "" {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var payload createAPIKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		logger.Error().Err(err).Msg("Unable to decode payload")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if payload.Name == "" || len(payload.Name) > maxAPIKeyNameLength {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	key, value, err := p.keys.Create(r.Context(), p.portal.Name, userEmail, r.Header.Values(headerHubGroups), payload.Name, payload.ExpiresAt)
+	if errors.Is(err, ErrAPIKeyLimitReached) {
+		rw.WriteHeader(http.StatusConflict)
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to create API key")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(rw).Encode(createAPIKeyResp{APIKey: key, Key: value}); err != nil {
+		logger.Error().Err(err).Msg("Write create API key response")
+	}
+}
+
+func (p *PortalAPI) handleRevokeAPIKey(rw http.ResponseWriter, r *http.Request) {
+	if p.keys == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logger := log.With().Str("portal_name", p.portal.Name).Logger()
+
+	userEmail := r.Header.Get(headerHubEmail)
+	if userEmail == "" {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err := p.keys.Revoke(r.Context(), p.portal.Name, userEmail, chi.URLParam(r, "key"))
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to revoke API key")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
 func (p *PortalAPI) handleListAPIs(rw http.ResponseWriter, r *http.Request) {
 	userGroups := r.Header.Values(headerHubGroups)
 
+	// APIs can be filtered on the tags of their OpenAPI spec.
+	var keep func(apiNameNamespace string) bool
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		entries := p.catalog.index(r.Context(), accessibleAPIs(p.portal, userGroups))
+		keep = func(apiNameNamespace string) bool {
+			return entries[apiNameNamespace].hasTag(tag)
+		}
+	}
+
 	rw.Header().Set("Content-Type", "application/json")
 	rw.WriteHeader(http.StatusOK)
 
-	if err := json.NewEncoder(rw).Encode(buildListResp(p.portal, userGroups)); err != nil {
+	if err := json.NewEncoder(rw).Encode(buildListResp(p.portal, userGroups, keep)); err != nil {
 		log.Error().Err(err).
 			Str("portal_name", p.portal.Name).
 			Msg("Write list APIs response")
 	}
 }
 
+func (p *PortalAPI) handleSearchAPIs(rw http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	terms := strings.Fields(strings.ToLower(query.Get("q")))
+	tag := query.Get("tag")
+	if len(terms) == 0 && tag == "" {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	userGroups := r.Header.Values(headerHubGroups)
+	entries := p.catalog.index(r.Context(), accessibleAPIs(p.portal, userGroups))
+
+	results := make([]searchResult, 0)
+	addResult := func(collectionName, collectionPathPrefix, specLink string, a api, entry *catalogEntry) {
+		if tag != "" && !entry.hasTag(tag) {
+			return
+		}
+
+		result := searchResult{
+			apiResp: apiResp{
+				Name:       a.Name,
+				PathPrefix: path.Join(collectionPathPrefix, a.Spec.PathPrefix),
+				SpecLink:   specLink,
+			},
+			Collection:  collectionName,
+			Description: entry.description,
+			Tags:        entry.tags,
+		}
+
+		if len(terms) > 0 {
+			result.score, result.Operations = match(terms, a.Name, entry)
+			if result.score == 0 {
+				return
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	for collectionName, c := range p.portal.Gateway.Collections {
+		if !c.authorizes(userGroups) {
+			continue
+		}
+
+		for apiNameNamespace, a := range c.APIs {
+			specLink := fmt.Sprintf("/collections/%s/apis/%s", collectionName, apiNameNamespace)
+			addResult(collectionName, c.Spec.PathPrefix, specLink, a, entries[apiNameNamespace])
+		}
+	}
+
+	for apiNameNamespace, a := range p.portal.Gateway.APIs {
+		if !a.authorizes(userGroups) {
+			continue
+		}
+
+		addResult("", "", fmt.Sprintf("/apis/%s", apiNameNamespace), a, entries[apiNameNamespace])
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].SpecLink < results[j].SpecLink
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(rw).Encode(searchResp{Results: results}); err != nil {
+		log.Error().Err(err).
+			Str("portal_name", p.portal.Name).
+			Msg("Write search APIs response")
+	}
+}
+
+func (p *PortalAPI) handleListTags(rw http.ResponseWriter, r *http.Request) {
+	entries := p.catalog.index(r.Context(), accessibleAPIs(p.portal, r.Header.Values(headerHubGroups)))
+
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		for _, tag := range entry.tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]tagResp, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, tagResp{Name: tag, APIs: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(rw).Encode(tags); err != nil {
+		log.Error().Err(err).
+			Str("portal_name", p.portal.Name).
+			Msg("Write list tags response")
+	}
+}
+
 func (p *PortalAPI) handleGetAPISpec(rw http.ResponseWriter, r *http.Request) {
 	apiNameNamespace := chi.URLParam(r, "api")
 
@@ -288,7 +581,7 @@ func (p *PortalAPI) handleGetCollectionAPISpec(rw http.ResponseWriter, r *http.R
 func (p *PortalAPI) serveAPISpec(ctx context.Context, rw http.ResponseWriter, g *gateway, c *collection, a *api) {
 	logger := log.Ctx(ctx)
 
-	spec, err := p.getOpenAPISpec(ctx, &a.API)
+	spec, err := p.specs.Fetch(ctx, &a.API)
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to fetch OpenAPI spec")
 		rw.WriteHeader(http.StatusBadGateway)
@@ -296,19 +589,25 @@ func (p *PortalAPI) serveAPISpec(ctx context.Context, rw http.ResponseWriter, g
 		return
 	}
 
+	// Broken specs are not published, they would only mislead the portal users.
+	if err = openapi.Validate(ctx, spec); err != nil {
+		logger.Error().Err(err).Msg("Refusing to serve invalid OpenAPI spec")
+		rw.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	if p.history != nil {
+		p.recordSpec(ctx, a, spec)
+	}
+
 	var pathPrefix string
 	if c != nil {
 		pathPrefix = c.Spec.PathPrefix
 	}
 	pathPrefix = path.Join(pathPrefix, a.Spec.PathPrefix)
 
-	// As soon as a CustomDomain is provided on the Gateway, the API is no longer accessible through the HubDomain.
-	domains := g.Status.CustomDomains
-	if len(domains) == 0 {
-		domains = []string{g.Status.HubDomain}
-	}
-
-	if err = overrideServersAndSecurity(spec, domains, pathPrefix); err != nil {
+	if err = overrideServersAndSecurity(spec, g.domains(), pathPrefix); err != nil {
 		logger.Error().Err(err).Msg("Unable to adapt OpenAPI spec server and security configurations")
 		rw.WriteHeader(http.StatusInternalServerError)
 
@@ -323,69 +622,270 @@ func (p *PortalAPI) serveAPISpec(ctx context.Context, rw http.ResponseWriter, g
 	}
 }
 
-func (p *PortalAPI) getOpenAPISpec(ctx context.Context, a *hubv1alpha1.API) (*openapi3.T, error) {
-	svc := a.Spec.Service
+// recordSpec records the spec as served by the API, before it gets adapted to the gateway.
+func (p *PortalAPI) recordSpec(ctx context.Context, a *api, spec *openapi3.T) {
+	rawSpec, err := json.Marshal(spec)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to marshal OpenAPI spec")
+		return
+	}
 
-	var openapiURL *url.URL
-	switch {
-	case svc.OpenAPISpec.URL != "":
-		u, err := url.Parse(svc.OpenAPISpec.URL)
-		if err != nil {
-			return nil, fmt.Errorf("parse OpenAPI URL %q: %w", svc.OpenAPISpec.URL, err)
-		}
-		openapiURL = u
+	if err = p.history.Record(ctx, a.Name+"@"+a.Namespace, rawSpec); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to record OpenAPI spec version")
+	}
+}
 
-	case svc.Port.Number != 0 || svc.OpenAPISpec.Port != nil && svc.OpenAPISpec.Port.Number != 0:
-		protocol := svc.OpenAPISpec.Protocol
-		if svc.OpenAPISpec.Protocol == "" {
-			protocol = "http"
-		}
+func (p *PortalAPI) handleListAPISpecVersions(rw http.ResponseWriter, r *http.Request) {
+	a, ok := p.lookupAPI(r)
+	if !ok || p.history == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
 
-		port := svc.Port.Number
-		if svc.OpenAPISpec.Port != nil {
-			port = svc.OpenAPISpec.Port.Number
-		}
+	logger := log.With().
+		Str("portal_name", p.portal.Name).
+		Str("api_name", chi.URLParam(r, "api")).
+		Logger()
 
-		namespace := a.Namespace
-		if namespace == "" {
-			namespace = "default"
-		}
+	versions, err := p.history.Versions(r.Context(), a.Name+"@"+a.Namespace)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to list OpenAPI spec versions")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-		openapiURL = &url.URL{
-			Scheme: protocol,
-			Host:   fmt.Sprint(svc.Name, ".", namespace, ":", port),
-			Path:   svc.OpenAPISpec.Path,
-		}
-	default:
-		return nil, errors.New("no spec endpoint specified")
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(versions); err != nil {
+		logger.Error().Err(err).Msg("Write list OpenAPI spec versions response")
+	}
+}
+
+// handleDiffAPISpecVersions serves the changes between two versions of an API spec. The versions are given by the
+// `from` and `to` query parameters, which default to the two latest versions.
+func (p *PortalAPI) handleDiffAPISpecVersions(rw http.ResponseWriter, r *http.Request) {
+	a, ok := p.lookupAPI(r)
+	if !ok || p.history == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, openapiURL.String(), http.NoBody)
+	apiNameNamespace := a.Name + "@" + a.Namespace
+
+	logger := log.With().
+		Str("portal_name", p.portal.Name).
+		Str("api_name", apiNameNamespace).
+		Logger()
+
+	versions, err := p.history.Versions(r.Context(), apiNameNamespace)
 	if err != nil {
-		return nil, fmt.Errorf("create request %q: %w", openapiURL.String(), err)
+		logger.Error().Err(err).Msg("Unable to list OpenAPI spec versions")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	fromID, toID, err := diffVersionIDs(r.URL.Query(), versions)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fromVersion, fromSpec, err := p.loadSpecVersion(r.Context(), apiNameNamespace, fromID)
+	if err != nil {
+		p.writeSpecVersionError(rw, logger, err)
+		return
+	}
+
+	toVersion, toSpec, err := p.loadSpecVersion(r.Context(), apiNameNamespace, toID)
+	if err != nil {
+		p.writeSpecVersionError(rw, logger, err)
+		return
+	}
+
+	diff := SpecDiff{From: fromVersion, To: toVersion}
+	if diff.Operations, err = diffSpecs(fromSpec, toSpec); err != nil {
+		logger.Error().Err(err).Msg("Unable to diff OpenAPI spec versions")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if diff.Schemas, err = diffFields("", schemas(fromSpec), schemas(toSpec)); err != nil {
+		logger.Error().Err(err).Msg("Unable to diff OpenAPI spec versions")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(diff); err != nil {
+		logger.Error().Err(err).Msg("Write OpenAPI spec diff response")
+	}
+}
+
+// handleTryAPI sends the request to the API targeted by the request, on the path following "/try", if the user is
+// authorized to access it.
+func (p *PortalAPI) handleTryAPI(rw http.ResponseWriter, r *http.Request) {
+	if p.tryIt == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get(headerHubEmail) == "" {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	a, ok := p.lookupAPI(r)
+	if !ok {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logger := log.With().
+		Str("portal_name", p.portal.Name).
+		Str("api_name", chi.URLParam(r, "api")).
+		Str("user_email", r.Header.Get(headerHubEmail)).
+		Logger()
+
+	var pathPrefix string
+	if collectionName := chi.URLParam(r, "collection"); collectionName != "" {
+		pathPrefix = p.portal.Gateway.Collections[collectionName].Spec.PathPrefix
+	}
+
+	apiPath := chi.URLParam(r, "*")
+	target := &url.URL{
+		Scheme:   "https",
+		Host:     p.portal.Gateway.domains()[0],
+		Path:     path.Join("/", pathPrefix, a.Spec.PathPrefix, apiPath),
+		RawQuery: r.URL.RawQuery,
+	}
+	if strings.HasSuffix(apiPath, "/") && !strings.HasSuffix(target.Path, "/") {
+		target.Path += "/"
+	}
+
+	p.tryIt.serve(rw, r.WithContext(logger.WithContext(r.Context())), target, a.Spec.Sandbox != nil)
+}
+
+// handleGetAPIUsage serves the usage of an API during the last hour by the groups of consumers the user belongs to.
+func (p *PortalAPI) handleGetAPIUsage(rw http.ResponseWriter, r *http.Request) {
+	a, ok := p.lookupAPI(r)
+	if !ok || p.usage == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
 	}
 
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Accept", "application/yaml")
+	logger := log.With().
+		Str("portal_name", p.portal.Name).
+		Str("api_name", chi.URLParam(r, "api")).
+		Logger()
 
-	resp, err := p.httpClient.Do(req)
+	usages, err := p.usage.GetUsages(r.Context())
+	if errors.Is(err, ErrUsageUnavailable) {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		return nil, fmt.Errorf("do request %q: %w", openapiURL.String(), err)
+		logger.Error().Err(err).Msg("Unable to get API usage")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	apiNameNamespace := a.Name + "@" + a.Namespace
+	userGroups := r.Header.Values(headerHubGroups)
+
+	// Users only see the usage of the consumer groups they belong to.
+	apiUsages := make([]metrics.APIUsage, 0)
+	for _, usage := range usages {
+		if usage.API != apiNameNamespace || !containsAny(usage.Groups, userGroups) {
+			continue
+		}
+
+		apiUsages = append(apiUsages, usage)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(apiUsages); err != nil {
+		logger.Error().Err(err).Msg("Write API usage response")
+	}
+}
+
+// lookupAPI returns the API targeted by the request, either directly or through a collection, if the user is
+// authorized to access it.
+func (p *PortalAPI) lookupAPI(r *http.Request) (*api, bool) {
+	apiNameNamespace := chi.URLParam(r, "api")
+	userGroups := r.Header.Values(headerHubGroups)
+
+	collectionName := chi.URLParam(r, "collection")
+	if collectionName == "" {
+		a, ok := p.portal.Gateway.APIs[apiNameNamespace]
+		if !ok || !a.authorizes(userGroups) {
+			return nil, false
+		}
+
+		return &a, true
+	}
+
+	c, ok := p.portal.Gateway.Collections[collectionName]
+	if !ok || !c.authorizes(userGroups) {
+		return nil, false
 	}
 
-	rawSpec, err := io.ReadAll(resp.Body)
+	a, ok := c.APIs[apiNameNamespace]
+	if !ok {
+		return nil, false
+	}
+
+	return &a, true
+}
+
+func (p *PortalAPI) loadSpecVersion(ctx context.Context, apiNameNamespace string, id int) (SpecVersion, *openapi3.T, error) {
+	version, rawSpec, err := p.history.Get(ctx, apiNameNamespace, id)
 	if err != nil {
-		return nil, fmt.Errorf("read spec %q: %w", openapiURL.String(), err)
+		return SpecVersion{}, nil, err
 	}
 
-	// A new loader must be created each time. LoadFromData mutates the internal state of Loader.
-	// LoadFromURI doesn't take a context, therefore, we must do the call ourselves.
 	spec, err := openapi3.NewLoader().LoadFromData(rawSpec)
 	if err != nil {
-		return nil, fmt.Errorf("load OpenAPI spec: %w", err)
+		return SpecVersion{}, nil, fmt.Errorf("load OpenAPI spec version %d: %w", id, err)
 	}
 
-	return spec, nil
+	return version, spec, nil
+}
+
+func (p *PortalAPI) writeSpecVersionError(rw http.ResponseWriter, logger zerolog.Logger, err error) {
+	if errors.Is(err, ErrSpecVersionNotFound) {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logger.Error().Err(err).Msg("Unable to get OpenAPI spec version")
+	rw.WriteHeader(http.StatusInternalServerError)
+}
+
+func diffVersionIDs(query url.Values, versions []SpecVersion) (fromID, toID int, err error) {
+	if len(versions) > 0 {
+		toID = versions[len(versions)-1].ID
+	}
+	if len(versions) > 1 {
+		fromID = versions[len(versions)-2].ID
+	}
+
+	if to := query.Get("to"); to != "" {
+		if toID, err = strconv.Atoi(to); err != nil {
+			return 0, 0, fmt.Errorf("invalid to version %q", to)
+		}
+	}
+
+	if from := query.Get("from"); from != "" {
+		if fromID, err = strconv.Atoi(from); err != nil {
+			return 0, 0, fmt.Errorf("invalid from version %q", from)
+		}
+	}
+
+	return fromID, toID, nil
 }
 
 func overrideServersAndSecurity(spec *openapi3.T, domains []string, pathPrefix string) error {
@@ -463,7 +963,56 @@ type apiResp struct {
 	SpecLink   string `json:"specLink"`
 }
 
-func buildListResp(p *portal, userGroups []string) listResp {
+type searchResp struct {
+	Results []searchResult `json:"results"`
+}
+
+type searchResult struct {
+	apiResp
+
+	Collection  string             `json:"collection,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Tags        []string           `json:"tags,omitempty"`
+	Operations  []catalogOperation `json:"operations,omitempty"`
+
+	score int
+}
+
+type tagResp struct {
+	Name string `json:"name"`
+	APIs int    `json:"apis"`
+}
+
+// accessibleAPIs returns the APIs of the given portal the given user groups can access, directly or through a
+// collection, by "name@namespace".
+func accessibleAPIs(p *portal, userGroups []string) map[string]*api {
+	apis := make(map[string]*api)
+	for _, c := range p.Gateway.Collections {
+		if !c.authorizes(userGroups) {
+			continue
+		}
+
+		for apiNameNamespace, a := range c.APIs {
+			a := a
+			apis[apiNameNamespace] = &a
+		}
+	}
+
+	for apiNameNamespace, a := range p.Gateway.APIs {
+		if !a.authorizes(userGroups) {
+			continue
+		}
+
+		a := a
+		apis[apiNameNamespace] = &a
+	}
+
+	return apis
+}
+
+// buildListResp lists the APIs the given user groups can access. Only the APIs kept by the given function are listed
+// when it is not nil, and the collections having none of them are omitted.
+func buildListResp(p *portal, userGroups []string, keep func(apiNameNamespace string) bool) listResp {
 	var resp listResp
 	for collectionName, c := range p.Gateway.Collections {
 		if !c.authorizes(userGroups) {
@@ -477,6 +1026,10 @@ func buildListResp(p *portal, userGroups []string) listResp {
 		}
 
 		for apiNameNamespace, a := range c.APIs {
+			if keep != nil && !keep(apiNameNamespace) {
+				continue
+			}
+
 			cr.APIs = append(cr.APIs, apiResp{
 				Name:       a.Name,
 				PathPrefix: path.Join(cr.PathPrefix, a.Spec.PathPrefix),
@@ -485,12 +1038,16 @@ func buildListResp(p *portal, userGroups []string) listResp {
 		}
 		sortAPIsResp(cr.APIs)
 
+		if keep != nil && len(cr.APIs) == 0 {
+			continue
+		}
+
 		resp.Collections = append(resp.Collections, cr)
 	}
 	sortCollectionsResp(resp.Collections)
 
 	for apiNameNamespace, a := range p.Gateway.APIs {
-		if !a.authorizes(userGroups) {
+		if !a.authorizes(userGroups) || keep != nil && !keep(apiNameNamespace) {
 			continue
 		}
 
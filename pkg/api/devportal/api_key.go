@@ -0,0 +1,246 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+// Errors returned by the APIKeyStore.
+var (
+	ErrAPIKeyNotFound     = errors.New("API key not found")
+	ErrAPIKeyLimitReached = errors.New("API key limit reached")
+)
+
+// APIKey is an API key issued to a portal user. Its value is only known when it is issued.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Groups    []string   `json:"groups"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// APIKeyStore issues API keys to portal users. Each key is stored in a Secret holding its hash and the groups of its
+// owner, which the auth server looks up to authenticate the requests made with it on the API key ACPs accepting issued
+// keys. Revoking a key deletes its Secret.
+type APIKeyStore struct {
+	client     kclientset.Interface
+	namespace  string
+	maxPerUser int
+	now        func() time.Time
+
+	// mu serializes the key creations, so users can't exceed their number of keys.
+	mu sync.Mutex
+}
+
+// NewAPIKeyStore returns a new APIKeyStore storing its Secrets in the given namespace, issuing at most maxPerUser keys
+// to each user of a portal.
+func NewAPIKeyStore(client kclientset.Interface, namespace string, maxPerUser int) *APIKeyStore {
+	return &APIKeyStore{
+		client:     client,
+		namespace:  namespace,
+		maxPerUser: maxPerUser,
+		now:        time.Now,
+	}
+}
+
+// List returns the keys issued to the given user by the given portal, sorted by creation date.
+func (s *APIKeyStore) List(ctx context.Context, portalName, userEmail string) ([]APIKey, error) {
+	secrets, err := s.list(ctx, portalName, userEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, 0, len(secrets))
+	for i := range secrets {
+		keys = append(keys, apiKeyFromSecret(&secrets[i]))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys, nil
+}
+
+// Create issues a new key named name to the given user of the given portal, granting the given groups until expiresAt,
+// if any. It returns the key along with its value.
+func (s *APIKeyStore) Create(ctx context.Context, portalName, userEmail string, groups []string, name string, expiresAt *time.Time) (APIKey, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.list(ctx, portalName, userEmail)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	if len(secrets) >= s.maxPerUser {
+		return APIKey{}, "", ErrAPIKeyLimitReached
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	value, err := randomKey()
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	data := map[string][]byte{
+		apikey.IssuedKeyHash:      []byte(apikey.Hash(value)),
+		apikey.IssuedKeyName:      []byte(name),
+		apikey.IssuedKeyEmail:     []byte(userEmail),
+		apikey.IssuedKeyGroups:    []byte(strings.Join(groups, ",")),
+		apikey.IssuedKeyCreatedAt: []byte(s.now().UTC().Format(time.RFC3339)),
+	}
+	if expiresAt != nil {
+		data[apikey.IssuedKeyExpiresAt] = []byte(expiresAt.UTC().Format(time.RFC3339))
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hub-api-key-" + id,
+			Namespace: s.namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+				apikey.LabelIssuedKey:          "true",
+				apikey.LabelIssuedKeyOwner:     apikey.OwnerLabel(userEmail),
+				apikey.LabelIssuedKeyPortal:    portalName,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+
+	secret, err = s.client.CoreV1().Secrets(s.namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if err != nil {
+		return APIKey{}, "", fmt.Errorf("create API key Secret: %w", err)
+	}
+
+	return apiKeyFromSecret(secret), value, nil
+}
+
+// Revoke revokes the key with the given ID issued to the given user of the given portal.
+func (s *APIKeyStore) Revoke(ctx context.Context, portalName, userEmail, id string) error {
+	secrets, err := s.list(ctx, portalName, userEmail)
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secrets {
+		if secret.Name != id {
+			continue
+		}
+
+		err = s.client.CoreV1().Secrets(s.namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{})
+		if kerror.IsNotFound(err) {
+			return ErrAPIKeyNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("delete API key Secret: %w", err)
+		}
+
+		return nil
+	}
+
+	return ErrAPIKeyNotFound
+}
+
+// list returns the Secrets of the keys issued to the given user by the given portal.
+func (s *APIKeyStore) list(ctx context.Context, portalName, userEmail string) ([]corev1.Secret, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		apikey.LabelIssuedKey:       "true",
+		apikey.LabelIssuedKeyOwner:  apikey.OwnerLabel(userEmail),
+		apikey.LabelIssuedKeyPortal: portalName,
+	})
+
+	list, err := s.client.CoreV1().Secrets(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("list API key Secrets: %w", err)
+	}
+
+	// The owner label is a digest of the email, the email itself is checked to rule out collisions.
+	secrets := make([]corev1.Secret, 0, len(list.Items))
+	for _, secret := range list.Items {
+		if strings.EqualFold(string(secret.Data[apikey.IssuedKeyEmail]), userEmail) {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	return secrets, nil
+}
+
+func apiKeyFromSecret(secret *corev1.Secret) APIKey {
+	key := APIKey{
+		ID:     secret.Name,
+		Name:   string(secret.Data[apikey.IssuedKeyName]),
+		Groups: make([]string, 0),
+	}
+
+	if groups := string(secret.Data[apikey.IssuedKeyGroups]); groups != "" {
+		key.Groups = strings.Split(groups, ",")
+	}
+
+	// Unparsable dates are left zero: the auth server rejects keys whose expiration date can't be parsed.
+	key.CreatedAt, _ = time.Parse(time.RFC3339, string(secret.Data[apikey.IssuedKeyCreatedAt]))
+	if expiresAt, ok := secret.Data[apikey.IssuedKeyExpiresAt]; ok {
+		t, _ := time.Parse(time.RFC3339, string(expiresAt))
+		key.ExpiresAt = &t
+	}
+
+	return key
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random ID: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func randomKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate API key: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
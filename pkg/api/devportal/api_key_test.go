@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAPIKeyStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(24 * time.Hour)
+
+	client := kubemock.NewSimpleClientset()
+	store := NewAPIKeyStore(client, "hub", 2)
+	store.now = func() time.Time { return now }
+
+	key, value, err := store.Create(ctx, "my-portal", "john@example.com", []string{"supplier", "manager"}, "ci", &expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", key.Name)
+	assert.Equal(t, []string{"supplier", "manager"}, key.Groups)
+	assert.Equal(t, now, key.CreatedAt)
+	assert.Equal(t, &expiresAt, key.ExpiresAt)
+
+	// Only the hash of the key is stored.
+	secret, err := client.CoreV1().Secrets("hub").Get(ctx, key.ID, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, apikey.Hash(value), string(secret.Data[apikey.IssuedKeyHash]))
+	assert.Equal(t, "true", secret.Labels[apikey.LabelIssuedKey])
+	assert.Equal(t, "john@example.com", string(secret.Data[apikey.IssuedKeyEmail]))
+	assert.Equal(t, "supplier,manager", string(secret.Data[apikey.IssuedKeyGroups]))
+	for _, data := range secret.Data {
+		assert.NotContains(t, string(data), value)
+	}
+
+	_, _, err = store.Create(ctx, "my-portal", "john@example.com", []string{"supplier"}, "laptop", nil)
+	require.NoError(t, err)
+
+	_, _, err = store.Create(ctx, "my-portal", "john@example.com", []string{"supplier"}, "one-too-many", nil)
+	assert.ErrorIs(t, err, ErrAPIKeyLimitReached)
+
+	// Keys are issued per user and per portal.
+	_, _, err = store.Create(ctx, "my-portal", "jane@example.com", []string{"supplier"}, "ci", nil)
+	require.NoError(t, err)
+	_, _, err = store.Create(ctx, "other-portal", "john@example.com", []string{"supplier"}, "ci", nil)
+	require.NoError(t, err)
+
+	keys, err := store.List(ctx, "my-portal", "john@example.com")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	err = store.Revoke(ctx, "my-portal", "jane@example.com", key.ID)
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	require.NoError(t, store.Revoke(ctx, "my-portal", "john@example.com", key.ID))
+
+	keys, err = store.List(ctx, "my-portal", "john@example.com")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "laptop", keys[0].Name)
+	assert.Nil(t, keys[0].ExpiresAt)
+
+	err = store.Revoke(ctx, "my-portal", "john@example.com", key.ID)
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+}
+
+func TestPortalAPI_Router_apiKeys(t *testing.T) {
+	store := NewAPIKeyStore(kubemock.NewSimpleClientset(), "hub", 1)
+
+	a, err := NewPortalAPI(&testPortal, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, store, nil)
+	require.NoError(t, err)
+
+	call := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "http://portal"+target, strings.NewReader(body))
+		req.Header.Set("Hub-Email", testEmail)
+		req.Header.Add("Hub-Groups", "supplier")
+		rec := httptest.NewRecorder()
+
+		a.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := call(http.MethodPost, "/keys", `{"name": ""}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = call(http.MethodPost, "/keys", `{"name": "ci", "expiresAt": "2020-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = call(http.MethodPost, "/keys", `{"name": "ci"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var created createAPIKeyResp
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	assert.Equal(t, "ci", created.Name)
+	assert.Equal(t, []string{"supplier"}, created.Groups)
+	assert.NotEmpty(t, created.Key)
+
+	rec = call(http.MethodPost, "/keys", `{"name": "laptop"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = call(http.MethodGet, "/keys", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var keys []map[string]interface{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&keys))
+	require.Len(t, keys, 1)
+	assert.Equal(t, created.ID, keys[0]["id"])
+	assert.NotContains(t, keys[0], "key")
+
+	rec = call(http.MethodDelete, "/keys/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = call(http.MethodDelete, "/keys/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPortalAPI_Router_apiKeysDisabled(t *testing.T) {
+	a, err := NewPortalAPI(&testPortal, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://portal/keys", http.NoBody)
+	req.Header.Set("Hub-Email", testEmail)
+	rec := httptest.NewRecorder()
+
+	a.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -28,10 +28,13 @@ import (
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,6 +45,13 @@ const (
 	testTokenName = "my-token"
 )
 
+// validSpec is the smallest valid OpenAPI spec.
+var validSpec = openapi3.T{
+	OpenAPI: "3.0.3",
+	Info:    &openapi3.Info{Title: "API", Version: "1.0.0"},
+	Paths:   openapi3.Paths{},
+}
+
 var testPortal = portal{
 	APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
 	Gateway: gateway{
@@ -250,7 +260,7 @@ func TestPortalAPI_Router_listTokens(t *testing.T) {
 			platformClient := newPlatformClientMock(t)
 			platformClient.OnListUserTokens(testEmail).TypedReturns(test.tokens, test.platformErr)
 
-			a, err := NewPortalAPI(&testPortal, platformClient)
+			a, err := NewPortalAPI(&testPortal, platformClient, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
 			require.NoError(t, err)
 
 			srv := httptest.NewServer(a)
@@ -276,6 +286,96 @@ func TestPortalAPI_Router_listTokens(t *testing.T) {
 	}
 }
 
+func TestPortalAPI_Router_listQuotas(t *testing.T) {
+	resetAt := time.Date(2023, 1, 1, 13, 0, 0, 0, time.UTC)
+	usages := []apikey.QuotaUsage{
+		{ACP: "acp", KeyID: "key-1", Email: testEmail, Limit: 10, Remaining: 4, ResetAt: resetAt},
+	}
+
+	tests := []struct {
+		desc           string
+		noQuotas       bool
+		authServerCode int
+		email          string
+		wantStatusCode int
+		wantUsages     []apikey.QuotaUsage
+	}{
+		{
+			desc:           "list quotas",
+			authServerCode: http.StatusOK,
+			email:          testEmail,
+			wantStatusCode: http.StatusOK,
+			wantUsages:     usages,
+		},
+		{
+			desc:           "missing email",
+			authServerCode: http.StatusOK,
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:           "quotas disabled",
+			noQuotas:       true,
+			email:          testEmail,
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:           "auth server error",
+			authServerCode: http.StatusInternalServerError,
+			email:          testEmail,
+			wantStatusCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			authServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/quotas", req.URL.Path)
+				assert.Equal(t, testEmail, req.URL.Query().Get("email"))
+
+				rw.WriteHeader(test.authServerCode)
+				if test.authServerCode == http.StatusOK {
+					_ = json.NewEncoder(rw).Encode(usages)
+				}
+			}))
+			t.Cleanup(authServer.Close)
+
+			var quotas QuotaGetter
+			if !test.noQuotas {
+				quotas = NewQuotaClient(authServer.URL + "/quotas")
+			}
+
+			a, err := NewPortalAPI(&testPortal, nil, openapi.NewFetcher(nil, nil), nil, quotas, nil, nil, nil)
+			require.NoError(t, err)
+
+			srv := httptest.NewServer(a)
+			t.Cleanup(srv.Close)
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/quotas", http.NoBody)
+			require.NoError(t, err)
+
+			if test.email != "" {
+				req.Header.Add("Hub-Email", test.email)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			require.Equal(t, test.wantStatusCode, resp.StatusCode)
+			if test.wantStatusCode == http.StatusOK {
+				var got []apikey.QuotaUsage
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+
+				assert.Equal(t, test.wantUsages, got)
+			}
+		})
+	}
+}
+
 func TestPortalAPI_Router_createToken(t *testing.T) {
 	tests := []struct {
 		desc           string
@@ -320,7 +420,7 @@ func TestPortalAPI_Router_createToken(t *testing.T) {
 			platformClient := newPlatformClientMock(t)
 			platformClient.OnCreateUserToken(testEmail, testTokenName).TypedReturns(test.token, test.platformErr)
 
-			a, err := NewPortalAPI(&testPortal, platformClient)
+			a, err := NewPortalAPI(&testPortal, platformClient, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
 			require.NoError(t, err)
 
 			srv := httptest.NewServer(a)
@@ -391,7 +491,7 @@ func TestPortalAPI_Router_suspendToken(t *testing.T) {
 			platformClient := newPlatformClientMock(t)
 			platformClient.OnSuspendUserToken(testEmail, testTokenName, test.suspend).TypedReturns(test.platformErr)
 
-			a, err := NewPortalAPI(&testPortal, platformClient)
+			a, err := NewPortalAPI(&testPortal, platformClient, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
 			require.NoError(t, err)
 
 			srv := httptest.NewServer(a)
@@ -447,7 +547,7 @@ func TestPortalAPI_Router_deleteToken(t *testing.T) {
 			platformClient := newPlatformClientMock(t)
 			platformClient.OnDeleteUserToken(testEmail, testTokenName).TypedReturns(test.platformErr)
 
-			a, err := NewPortalAPI(&testPortal, platformClient)
+			a, err := NewPortalAPI(&testPortal, platformClient, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
 			require.NoError(t, err)
 
 			srv := httptest.NewServer(a)
@@ -470,7 +570,7 @@ func TestPortalAPI_Router_deleteToken(t *testing.T) {
 }
 
 func TestPortalAPI_Router_listAPIs(t *testing.T) {
-	a, err := NewPortalAPI(&testPortal, nil)
+	a, err := NewPortalAPI(&testPortal, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	srv := httptest.NewServer(a)
@@ -512,7 +612,7 @@ func TestPortalAPI_Router_listAPIs(t *testing.T) {
 
 func TestPortalAPI_Router_listAPIs_noAPIsAndCollections(t *testing.T) {
 	var p portal
-	a, err := NewPortalAPI(&p, nil)
+	a, err := NewPortalAPI(&p, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	srv := httptest.NewServer(a)
@@ -585,14 +685,13 @@ func TestPortalAPI_Router_getCollectionAPISpec(t *testing.T) {
 					return
 				}
 
-				if err := json.NewEncoder(rw).Encode(openapi3.T{OpenAPI: "v3.0"}); err != nil {
+				if err := json.NewEncoder(rw).Encode(validSpec); err != nil {
 					rw.WriteHeader(http.StatusInternalServerError)
 				}
 			}))
 
-			a, err := NewPortalAPI(&testPortal, nil)
+			a, err := NewPortalAPI(&testPortal, nil, openapi.NewFetcher(buildProxyTransport(t, svcSrv.URL), nil), nil, nil, nil, nil, nil)
 			require.NoError(t, err)
-			a.httpClient = buildProxyClient(t, svcSrv.URL)
 
 			apiSrv := httptest.NewServer(a)
 
@@ -614,7 +713,7 @@ func TestPortalAPI_Router_getCollectionAPISpec(t *testing.T) {
 			got, err := io.ReadAll(resp.Body)
 			require.NoError(t, err)
 
-			assert.JSONEq(t, `{"openapi": "v3.0","info": null,"paths": null}`, string(got))
+			assert.JSONEq(t, `{"openapi": "3.0.3","info": {"title": "API","version": "1.0.0"},"paths": {}}`, string(got))
 		})
 	}
 }
@@ -760,9 +859,8 @@ func TestPortalAPI_Router_getCollectionAPISpec_overrideServerAndAuth(t *testing.
 		test := test
 
 		t.Run(test.desc, func(t *testing.T) {
-			a, err := NewPortalAPI(&test.portal, nil)
+			a, err := NewPortalAPI(&test.portal, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
 			require.NoError(t, err)
-			a.httpClient = http.DefaultClient
 
 			apiSrv := httptest.NewServer(a)
 
@@ -840,13 +938,12 @@ func TestPortalAPI_Router_getAPISpec(t *testing.T) {
 					return
 				}
 
-				if err := json.NewEncoder(rw).Encode(openapi3.T{OpenAPI: "v3.0"}); err != nil {
+				if err := json.NewEncoder(rw).Encode(validSpec); err != nil {
 					rw.WriteHeader(http.StatusInternalServerError)
 				}
 			}))
-			a, err := NewPortalAPI(&testPortal, nil)
+			a, err := NewPortalAPI(&testPortal, nil, openapi.NewFetcher(buildProxyTransport(t, svcSrv.URL), nil), nil, nil, nil, nil, nil)
 			require.NoError(t, err)
-			a.httpClient = buildProxyClient(t, svcSrv.URL)
 
 			apiSrv := httptest.NewServer(a)
 
@@ -867,7 +964,7 @@ func TestPortalAPI_Router_getAPISpec(t *testing.T) {
 			got, err := io.ReadAll(resp.Body)
 			require.NoError(t, err)
 
-			assert.JSONEq(t, `{"openapi": "v3.0","info": null,"paths": null}`, string(got))
+			assert.JSONEq(t, `{"openapi": "3.0.3","info": {"title": "API","version": "1.0.0"},"paths": {}}`, string(got))
 		})
 	}
 }
@@ -909,9 +1006,8 @@ func TestPortalAPI_Router_getAPISpec_overrideServerAndAuth(t *testing.T) {
 		},
 	}
 
-	a, err := NewPortalAPI(&p, nil)
+	a, err := NewPortalAPI(&p, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
 	require.NoError(t, err)
-	a.httpClient = http.DefaultClient
 
 	apiSrv := httptest.NewServer(a)
 
@@ -933,19 +1029,69 @@ func TestPortalAPI_Router_getAPISpec_overrideServerAndAuth(t *testing.T) {
 	assert.JSONEq(t, string(wantSpec), string(got))
 }
 
-func buildProxyClient(t *testing.T, proxyURL string) *http.Client {
+func TestPortalAPI_Router_getAPISpec_invalidSpec(t *testing.T) {
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// The spec info is missing its required version.
+		_, _ = rw.Write([]byte(`{"openapi": "3.0.3", "info": {"title": "API"}, "paths": {}}`))
+	}))
+	t.Cleanup(svcSrv.Close)
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]api{
+				"my-api@my-ns": {
+					API: hubv1alpha1.API{
+						ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+						Spec: hubv1alpha1.APISpec{
+							PathPrefix: "/api-prefix",
+							Service: hubv1alpha1.APIService{
+								Name:        "svc",
+								Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+								OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+							},
+						},
+					},
+					authorizedGroups: []string{"supplier"},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	apiSrv := httptest.NewServer(a)
+	t.Cleanup(apiSrv.Close)
+
+	req, err := http.NewRequest(http.MethodGet, apiSrv.URL+"/apis/my-api@my-ns", http.NoBody)
+	require.NoError(t, err)
+
+	req.Header.Add("Hub-Email", testEmail)
+	req.Header.Add("Hub-Groups", "supplier")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func buildProxyTransport(t *testing.T, proxyURL string) *http.Transport {
 	t.Helper()
 
 	u, err := url.Parse(proxyURL)
 	require.NoError(t, err)
 
-	return &http.Client{
-		Transport: &http.Transport{
-			Proxy: func(r *http.Request) (*url.URL, error) {
-				r.URL.Host = u.Host
+	return &http.Transport{
+		Proxy: func(r *http.Request) (*url.URL, error) {
+			r.URL.Host = u.Host
 
-				return r.URL, nil
-			},
+			return r.URL, nil
 		},
 	}
 }
@@ -0,0 +1,247 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/slices"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// catalogIndexTTL is the duration after which an API is indexed again, to catch up with the changes of its spec.
+	catalogIndexTTL = time.Minute
+	// catalogIndexConcurrency is the maximum number of API specs fetched concurrently while indexing.
+	catalogIndexConcurrency = 8
+	// catalogIndexTimeout is the maximum duration of the indexing of an API.
+	catalogIndexTimeout = 10 * time.Second
+)
+
+// catalogOperation is an operation of an API, as described by its OpenAPI spec.
+type catalogOperation struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operationId,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// catalogEntry is the searchable description of an API, extracted from its OpenAPI spec.
+type catalogEntry struct {
+	// hash is the hash of the API when it was indexed.
+	hash      string
+	indexedAt time.Time
+
+	description string
+	tags        []string
+	operations  []catalogOperation
+}
+
+// hasTag returns whether the API has the given tag, on the spec or on any of its operations.
+func (e *catalogEntry) hasTag(tag string) bool {
+	return e != nil && slices.Contains(e.tags, tag)
+}
+
+// catalog indexes the APIs of a portal, so they can be searched and filtered by tags.
+type catalog struct {
+	specs SpecFetcher
+	now   func() time.Time
+
+	entriesMu sync.Mutex
+	// entries are the indexed APIs, by "name@namespace".
+	entries map[string]*catalogEntry
+}
+
+func newCatalog(specs SpecFetcher) *catalog {
+	return &catalog{
+		specs:   specs,
+		now:     time.Now,
+		entries: make(map[string]*catalogEntry),
+	}
+}
+
+// index returns the entries of the given APIs, by "name@namespace", indexing the ones which are not indexed yet or
+// whose entry expired. APIs whose spec can't be fetched are indexed without description, tags nor operations.
+func (c *catalog) index(ctx context.Context, apis map[string]*api) map[string]*catalogEntry {
+	now := c.now()
+	indexed := make(map[string]*catalogEntry, len(apis))
+
+	var stale []string
+
+	c.entriesMu.Lock()
+	for key, a := range apis {
+		entry, ok := c.entries[key]
+		if ok && entry.hash == a.Status.Hash && now.Sub(entry.indexedAt) < catalogIndexTTL {
+			indexed[key] = entry
+			continue
+		}
+		stale = append(stale, key)
+	}
+	c.entriesMu.Unlock()
+
+	var (
+		group    errgroup.Group
+		resultMu sync.Mutex
+	)
+	group.SetLimit(catalogIndexConcurrency)
+
+	for _, key := range stale {
+		key, a := key, apis[key]
+		group.Go(func() error {
+			entry := c.indexAPI(ctx, key, a)
+
+			resultMu.Lock()
+			indexed[key] = entry
+			resultMu.Unlock()
+
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	c.entriesMu.Lock()
+	for key, entry := range indexed {
+		c.entries[key] = entry
+	}
+	c.entriesMu.Unlock()
+
+	return indexed
+}
+
+func (c *catalog) indexAPI(ctx context.Context, key string, a *api) *catalogEntry {
+	entry := &catalogEntry{hash: a.Status.Hash, indexedAt: c.now()}
+
+	ctxFetch, cancel := context.WithTimeout(ctx, catalogIndexTimeout)
+	defer cancel()
+
+	spec, err := c.specs.Fetch(ctxFetch, &a.API)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("api_name", key).Msg("Unable to fetch OpenAPI spec to index")
+		return entry
+	}
+
+	if spec.Info != nil {
+		entry.description = spec.Info.Description
+	}
+
+	tags := make(map[string]struct{})
+	for _, tag := range spec.Tags {
+		tags[tag.Name] = struct{}{}
+	}
+
+	for p, pathItem := range spec.Paths {
+		for method, op := range pathItem.Operations() {
+			entry.operations = append(entry.operations, catalogOperation{
+				Method:      method,
+				Path:        p,
+				OperationID: op.OperationID,
+				Summary:     op.Summary,
+				Tags:        op.Tags,
+			})
+
+			for _, tag := range op.Tags {
+				tags[tag] = struct{}{}
+			}
+		}
+	}
+
+	sort.Slice(entry.operations, func(i, j int) bool {
+		if entry.operations[i].Path != entry.operations[j].Path {
+			return entry.operations[i].Path < entry.operations[j].Path
+		}
+		return entry.operations[i].Method < entry.operations[j].Method
+	})
+
+	for tag := range tags {
+		entry.tags = append(entry.tags, tag)
+	}
+	sort.Strings(entry.tags)
+
+	return entry
+}
+
+// Search result weights, the more specific the matched field, the higher the weight.
+const (
+	weightName        = 4
+	weightTag         = 3
+	weightOperation   = 2
+	weightDescription = 1
+)
+
+// match matches the given lowercase search terms against the given API and its entry. It returns the score of the API,
+// 0 if any of the terms matches none of its fields, and the operations matching any of the terms.
+func match(terms []string, name string, entry *catalogEntry) (int, []catalogOperation) {
+	var (
+		score      int
+		operations []catalogOperation
+	)
+
+	opMatches := make([]bool, len(entry.operations))
+	for _, term := range terms {
+		var termScore int
+
+		if strings.Contains(strings.ToLower(name), term) {
+			termScore += weightName
+		}
+		if strings.Contains(strings.ToLower(entry.description), term) {
+			termScore += weightDescription
+		}
+		for _, tag := range entry.tags {
+			if strings.Contains(strings.ToLower(tag), term) {
+				termScore += weightTag
+				break
+			}
+		}
+		var opMatched bool
+		for i, op := range entry.operations {
+			if operationContains(op, term) {
+				opMatches[i] = true
+				opMatched = true
+			}
+		}
+		if opMatched {
+			termScore += weightOperation
+		}
+
+		// All the terms must match.
+		if termScore == 0 {
+			return 0, nil
+		}
+		score += termScore
+	}
+
+	for i, op := range entry.operations {
+		if opMatches[i] {
+			operations = append(operations, op)
+		}
+	}
+
+	return score, operations
+}
+
+func operationContains(op catalogOperation, term string) bool {
+	return strings.Contains(strings.ToLower(op.Path), term) ||
+		strings.Contains(strings.ToLower(op.OperationID), term) ||
+		strings.Contains(strings.ToLower(op.Summary), term)
+}
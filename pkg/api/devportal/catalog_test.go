@@ -0,0 +1,253 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var catalogPortal = portal{
+	APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+	Gateway: gateway{
+		Collections: map[string]collection{
+			"library": {
+				APICollection: hubv1alpha1.APICollection{
+					ObjectMeta: metav1.ObjectMeta{Name: "library"},
+					Spec:       hubv1alpha1.APICollectionSpec{PathPrefix: "/library"},
+				},
+				APIs: map[string]api{
+					"books@library-ns": {
+						API: hubv1alpha1.API{
+							ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "library-ns"},
+							Spec:       hubv1alpha1.APISpec{PathPrefix: "/books"},
+						},
+					},
+				},
+				authorizedGroups: []string{"supplier"},
+			},
+		},
+		APIs: map[string]api{
+			"orders@default": {
+				API: hubv1alpha1.API{
+					ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"},
+					Spec:       hubv1alpha1.APISpec{PathPrefix: "/orders"},
+				},
+				authorizedGroups: []string{"supplier"},
+			},
+			"broken@default": {
+				API: hubv1alpha1.API{
+					ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "default"},
+					Spec:       hubv1alpha1.APISpec{PathPrefix: "/broken"},
+				},
+				authorizedGroups: []string{"supplier"},
+			},
+			"bookkeepers@default": {
+				API: hubv1alpha1.API{
+					ObjectMeta: metav1.ObjectMeta{Name: "bookkeepers", Namespace: "default"},
+					Spec:       hubv1alpha1.APISpec{PathPrefix: "/bookkeepers"},
+				},
+				authorizedGroups: []string{"manager"},
+			},
+		},
+	},
+}
+
+var catalogSpecs = map[string]*openapi3.T{
+	"books": {
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "Books", Version: "1.0.0", Description: "Books of the library."},
+		Tags:    openapi3.Tags{{Name: "catalog"}},
+		Paths: openapi3.Paths{
+			"/books": &openapi3.PathItem{
+				Get: &openapi3.Operation{OperationID: "listBooks", Summary: "List books", Tags: []string{"catalog"}},
+			},
+			"/books/{id}/loans": &openapi3.PathItem{
+				Post: &openapi3.Operation{OperationID: "borrowBook", Summary: "Borrow a book", Tags: []string{"loans"}},
+			},
+		},
+	},
+	"orders": {
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "Orders", Version: "1.0.0", Description: "Customer orders, including books."},
+		Paths: openapi3.Paths{
+			"/orders": &openapi3.PathItem{
+				Get: &openapi3.Operation{OperationID: "listOrders", Summary: "List orders", Tags: []string{"sales"}},
+			},
+		},
+	},
+	"bookkeepers": {
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "Bookkeepers", Version: "1.0.0"},
+		Tags:    openapi3.Tags{{Name: "accounting"}},
+	},
+}
+
+func TestPortalAPI_Router_searchAPIs(t *testing.T) {
+	tests := []struct {
+		desc       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "search terms match names, descriptions, tags and operations",
+			path:       "/search?q=book",
+			wantStatus: http.StatusOK,
+			wantBody: `{"results": [
+				{
+					"name": "books",
+					"pathPrefix": "/library/books",
+					"specLink": "/collections/library/apis/books@library-ns",
+					"collection": "library",
+					"description": "Books of the library.",
+					"tags": ["catalog", "loans"],
+					"operations": [
+						{"method": "GET", "path": "/books", "operationId": "listBooks", "summary": "List books", "tags": ["catalog"]},
+						{"method": "POST", "path": "/books/{id}/loans", "operationId": "borrowBook", "summary": "Borrow a book", "tags": ["loans"]}
+					]
+				},
+				{
+					"name": "orders",
+					"pathPrefix": "/orders",
+					"specLink": "/apis/orders@default",
+					"description": "Customer orders, including books.",
+					"tags": ["sales"]
+				}
+			]}`,
+		},
+		{
+			desc:       "all the search terms must match",
+			path:       "/search?q=book+borrow",
+			wantStatus: http.StatusOK,
+			wantBody: `{"results": [
+				{
+					"name": "books",
+					"pathPrefix": "/library/books",
+					"specLink": "/collections/library/apis/books@library-ns",
+					"collection": "library",
+					"description": "Books of the library.",
+					"tags": ["catalog", "loans"],
+					"operations": [
+						{"method": "GET", "path": "/books", "operationId": "listBooks", "summary": "List books", "tags": ["catalog"]},
+						{"method": "POST", "path": "/books/{id}/loans", "operationId": "borrowBook", "summary": "Borrow a book", "tags": ["loans"]}
+					]
+				}
+			]}`,
+		},
+		{
+			desc:       "APIs whose spec is unavailable match on their name",
+			path:       "/search?q=BROKEN",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"results": [{"name": "broken", "pathPrefix": "/broken", "specLink": "/apis/broken@default"}]}`,
+		},
+		{
+			desc:       "search by tag",
+			path:       "/search?tag=sales",
+			wantStatus: http.StatusOK,
+			wantBody: `{"results": [
+				{
+					"name": "orders",
+					"pathPrefix": "/orders",
+					"specLink": "/apis/orders@default",
+					"description": "Customer orders, including books.",
+					"tags": ["sales"]
+				}
+			]}`,
+		},
+		{
+			desc:       "APIs not accessible to the user groups are not searched",
+			path:       "/search?tag=accounting",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"results": []}`,
+		},
+		{
+			desc:       "missing search terms and tag",
+			path:       "/search",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "list APIs by tag",
+			path:       "/apis?tag=loans",
+			wantStatus: http.StatusOK,
+			wantBody: `{
+				"collections": [
+					{
+						"name": "library",
+						"pathPrefix": "/library",
+						"apis": [{"name": "books", "pathPrefix": "/library/books", "specLink": "/collections/library/apis/books@library-ns"}]
+					}
+				],
+				"apis": []
+			}`,
+		},
+		{
+			desc:       "list tags",
+			path:       "/tags",
+			wantStatus: http.StatusOK,
+			wantBody: `[
+				{"name": "catalog", "apis": 1},
+				{"name": "loans", "apis": 1},
+				{"name": "sales", "apis": 1}
+			]`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			specs := newSpecFetcherMock(t)
+			specs.OnFetchRaw(mock.Anything).
+				ReturnsFn(func(a *hubv1alpha1.API) (*openapi3.T, error) {
+					spec, ok := catalogSpecs[a.Name]
+					if !ok {
+						return nil, errors.New("unavailable")
+					}
+					return spec, nil
+				}).
+				Maybe()
+
+			p := catalogPortal
+			a, err := NewPortalAPI(&p, nil, specs, nil, nil, nil, nil, nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://portal"+test.path, http.NoBody)
+			req.Header.Set("Hub-Email", testEmail)
+			req.Header.Set("Hub-Groups", "supplier")
+			rec := httptest.NewRecorder()
+
+			a.ServeHTTP(rec, req)
+
+			require.Equal(t, test.wantStatus, rec.Code)
+			if test.wantBody != "" {
+				assert.JSONEq(t, test.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
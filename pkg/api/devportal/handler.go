@@ -23,7 +23,9 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 )
 
@@ -35,19 +37,39 @@ type PlatformClient interface {
 	DeleteUserToken(ctx context.Context, userEmail, tokenName string) error
 }
 
+// SpecFetcher fetches the OpenAPI specs of APIs.
+type SpecFetcher interface {
+	Fetch(ctx context.Context, a *hubv1alpha1.API) (*openapi3.T, error)
+}
+
 // Handler exposes both an API and a UI for a set of APIPortals.
 // The handler can be safely updated to support more APIPortals as they come and go.
 type Handler struct {
 	handlerMu      sync.RWMutex
 	handler        http.Handler
 	platformClient PlatformClient
+	specs          SpecFetcher
+	history        *SpecHistory
+	quotas         QuotaGetter
+	tryIt          *TryItProxy
+	keys           *APIKeyStore
+	usage          UsageGetter
 }
 
-// NewHandler builds a new instance of Handler.
-func NewHandler(platformClient PlatformClient) *Handler {
+// NewHandler builds a new instance of Handler, fetching the API specs with the given SpecFetcher. The history of the API
+// specs is only kept when a SpecHistory is given, the quota usages are only served when a QuotaGetter is given, the
+// APIs can only be tried out when a TryItProxy is given, API keys are only issued when an APIKeyStore is given, and the
+// API usages are only served when a UsageGetter is given.
+func NewHandler(platformClient PlatformClient, specs SpecFetcher, history *SpecHistory, quotas QuotaGetter, tryIt *TryItProxy, keys *APIKeyStore, usage UsageGetter) *Handler {
 	return &Handler{
 		handler:        http.NotFoundHandler(),
 		platformClient: platformClient,
+		specs:          specs,
+		history:        history,
+		quotas:         quotas,
+		tryIt:          tryIt,
+		keys:           keys,
+		usage:          usage,
 	}
 }
 
@@ -66,7 +88,7 @@ func (h *Handler) Update(portals []portal) error {
 	for _, p := range portals {
 		p := p
 
-		apiHandler, err := NewPortalAPI(&p, h.platformClient)
+		apiHandler, err := NewPortalAPI(&p, h.platformClient, h.specs, h.history, h.quotas, h.tryIt, h.keys, h.usage)
 		if err != nil {
 			return fmt.Errorf("create portal %q API handler: %w", p.Name, err)
 		}
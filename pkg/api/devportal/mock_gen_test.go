@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/mock"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 )
 
@@ -597,3 +599,110 @@ func (_c *platformClientSuspendUserTokenCall) OnListUserTokensRaw(userEmail inte
 func (_c *platformClientSuspendUserTokenCall) OnSuspendUserTokenRaw(userEmail interface{}, tokenName interface{}, suspend interface{}) *platformClientSuspendUserTokenCall {
 	return _c.Parent.OnSuspendUserTokenRaw(userEmail, tokenName, suspend)
 }
+
+// specFetcherMock mock of SpecFetcher.
+type specFetcherMock struct{ mock.Mock }
+
+// newSpecFetcherMock creates a new specFetcherMock.
+func newSpecFetcherMock(tb testing.TB) *specFetcherMock {
+	tb.Helper()
+
+	m := &specFetcherMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *specFetcherMock) Fetch(_ context.Context, a *hubv1alpha1.API) (*openapi3.T, error) {
+	_ret := _m.Called(a)
+
+	if _rf, ok := _ret.Get(0).(func(*hubv1alpha1.API) (*openapi3.T, error)); ok {
+		return _rf(a)
+	}
+
+	_ra0, _ := _ret.Get(0).(*openapi3.T)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *specFetcherMock) OnFetch(a *hubv1alpha1.API) *specFetcherFetchCall {
+	return &specFetcherFetchCall{Call: _m.Mock.On("Fetch", a), Parent: _m}
+}
+
+func (_m *specFetcherMock) OnFetchRaw(a interface{}) *specFetcherFetchCall {
+	return &specFetcherFetchCall{Call: _m.Mock.On("Fetch", a), Parent: _m}
+}
+
+type specFetcherFetchCall struct {
+	*mock.Call
+	Parent *specFetcherMock
+}
+
+func (_c *specFetcherFetchCall) Panic(msg string) *specFetcherFetchCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *specFetcherFetchCall) Once() *specFetcherFetchCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *specFetcherFetchCall) Twice() *specFetcherFetchCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *specFetcherFetchCall) Times(i int) *specFetcherFetchCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *specFetcherFetchCall) WaitUntil(w <-chan time.Time) *specFetcherFetchCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *specFetcherFetchCall) After(d time.Duration) *specFetcherFetchCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *specFetcherFetchCall) Run(fn func(args mock.Arguments)) *specFetcherFetchCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *specFetcherFetchCall) Maybe() *specFetcherFetchCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *specFetcherFetchCall) TypedReturns(a *openapi3.T, b error) *specFetcherFetchCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *specFetcherFetchCall) ReturnsFn(fn func(*hubv1alpha1.API) (*openapi3.T, error)) *specFetcherFetchCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *specFetcherFetchCall) TypedRun(fn func(*hubv1alpha1.API)) *specFetcherFetchCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_a, _ := args.Get(0).(*hubv1alpha1.API)
+		fn(_a)
+	})
+	return _c
+}
+
+func (_c *specFetcherFetchCall) OnFetch(a *hubv1alpha1.API) *specFetcherFetchCall {
+	return _c.Parent.OnFetch(a)
+}
+
+func (_c *specFetcherFetchCall) OnFetchRaw(a interface{}) *specFetcherFetchCall {
+	return _c.Parent.OnFetchRaw(a)
+}
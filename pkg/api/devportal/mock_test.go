@@ -19,3 +19,4 @@ package devportal
 
 // mocktail:UpdatableHandler
 // mocktail:PlatformClient
+// mocktail:SpecFetcher
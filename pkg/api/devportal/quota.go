@@ -0,0 +1,82 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+)
+
+// QuotaGetter gets the usage of the API key quotas of a user.
+type QuotaGetter interface {
+	GetQuotas(ctx context.Context, userEmail string) ([]apikey.QuotaUsage, error)
+}
+
+// QuotaClient gets the usage of the API key quotas from the auth server, which counts the requests made with each key.
+type QuotaClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewQuotaClient creates a new QuotaClient getting the quota usages from the given auth server URL.
+func NewQuotaClient(quotasURL string) *QuotaClient {
+	return &QuotaClient{
+		url:        quotasURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetQuotas gets the usage of the quotas of the keys having the given email in their metadata.
+func (c *QuotaClient) GetQuotas(ctx context.Context, userEmail string) ([]apikey.QuotaUsage, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, fmt.Errorf("parse quotas URL: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("email", userEmail)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get quotas: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var usages []apikey.QuotaUsage
+	if err = json.NewDecoder(resp.Body).Decode(&usages); err != nil {
+		return nil, fmt.Errorf("decode quotas: %w", err)
+	}
+
+	return usages, nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Kinds of changes between two versions of an OpenAPI spec.
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// SpecDiff describes what changed between two versions of an OpenAPI spec.
+type SpecDiff struct {
+	From       SpecVersion     `json:"from"`
+	To         SpecVersion     `json:"to"`
+	Operations []OperationDiff `json:"operations"`
+	Schemas    []FieldChange   `json:"schemas"`
+}
+
+// OperationDiff describes how an operation changed between two versions of an OpenAPI spec.
+type OperationDiff struct {
+	Path    string        `json:"path"`
+	Method  string        `json:"method"`
+	Change  string        `json:"change"`
+	Details []FieldChange `json:"details,omitempty"`
+}
+
+// FieldChange describes how a field of an operation changed.
+type FieldChange struct {
+	// Field is the changed field, e.g. "parameter query.limit" or "response 200".
+	Field  string `json:"field"`
+	Change string `json:"change"`
+}
+
+// diffSpecs returns the operations which changed between the given specs, sorted by path and method.
+func diffSpecs(from, to *openapi3.T) ([]OperationDiff, error) {
+	fromOps := operations(from)
+	toOps := operations(to)
+
+	keys := make(map[operationKey]struct{}, len(fromOps)+len(toOps))
+	for key := range fromOps {
+		keys[key] = struct{}{}
+	}
+	for key := range toOps {
+		keys[key] = struct{}{}
+	}
+
+	diffs := make([]OperationDiff, 0)
+	for key := range keys {
+		fromOp, inFrom := fromOps[key]
+		toOp, inTo := toOps[key]
+
+		switch {
+		case !inFrom:
+			diffs = append(diffs, OperationDiff{Path: key.path, Method: key.method, Change: ChangeAdded})
+		case !inTo:
+			diffs = append(diffs, OperationDiff{Path: key.path, Method: key.method, Change: ChangeRemoved})
+		default:
+			details, err := diffOperations(fromOp, toOp)
+			if err != nil {
+				return nil, fmt.Errorf("diff operation %s %s: %w", key.method, key.path, err)
+			}
+			if len(details) == 0 {
+				continue
+			}
+
+			diffs = append(diffs, OperationDiff{Path: key.path, Method: key.method, Change: ChangeModified, Details: details})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Path == diffs[j].Path {
+			return diffs[i].Method < diffs[j].Method
+		}
+		return diffs[i].Path < diffs[j].Path
+	})
+
+	return diffs, nil
+}
+
+type operationKey struct {
+	path   string
+	method string
+}
+
+func operations(spec *openapi3.T) map[operationKey]*openapi3.Operation {
+	ops := make(map[operationKey]*openapi3.Operation)
+	for path, item := range spec.Paths {
+		for method, op := range item.Operations() {
+			ops[operationKey{path: path, method: method}] = op
+		}
+	}
+
+	return ops
+}
+
+func diffOperations(from, to *openapi3.Operation) ([]FieldChange, error) {
+	var changes []FieldChange
+
+	if from.Summary != to.Summary {
+		changes = append(changes, FieldChange{Field: "summary", Change: ChangeModified})
+	}
+	if from.Description != to.Description {
+		changes = append(changes, FieldChange{Field: "description", Change: ChangeModified})
+	}
+	if from.Deprecated != to.Deprecated {
+		changes = append(changes, FieldChange{Field: "deprecated", Change: ChangeModified})
+	}
+
+	paramChanges, err := diffFields("parameter ", parameters(from), parameters(to))
+	if err != nil {
+		return nil, fmt.Errorf("diff parameters: %w", err)
+	}
+	changes = append(changes, paramChanges...)
+
+	bodyChanges, err := diffFields("", requestBody(from), requestBody(to))
+	if err != nil {
+		return nil, fmt.Errorf("diff request body: %w", err)
+	}
+	changes = append(changes, bodyChanges...)
+
+	responseChanges, err := diffFields("response ", responses(from), responses(to))
+	if err != nil {
+		return nil, fmt.Errorf("diff responses: %w", err)
+	}
+	changes = append(changes, responseChanges...)
+
+	return changes, nil
+}
+
+// diffFields compares the fields of two operations, which are identified by name.
+func diffFields(prefix string, from, to map[string]interface{}) ([]FieldChange, error) {
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []FieldChange
+	for _, name := range names {
+		fromField, inFrom := from[name]
+		toField, inTo := to[name]
+
+		switch {
+		case !inFrom:
+			changes = append(changes, FieldChange{Field: prefix + name, Change: ChangeAdded})
+		case !inTo:
+			changes = append(changes, FieldChange{Field: prefix + name, Change: ChangeRemoved})
+		default:
+			equal, err := jsonEqual(fromField, toField)
+			if err != nil {
+				return nil, err
+			}
+			if !equal {
+				changes = append(changes, FieldChange{Field: prefix + name, Change: ChangeModified})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+func schemas(spec *openapi3.T) map[string]interface{} {
+	s := make(map[string]interface{}, len(spec.Components.Schemas))
+	for name, ref := range spec.Components.Schemas {
+		if ref.Value == nil {
+			continue
+		}
+		s[name] = ref.Value
+	}
+
+	return s
+}
+
+func parameters(op *openapi3.Operation) map[string]interface{} {
+	params := make(map[string]interface{}, len(op.Parameters))
+	for _, ref := range op.Parameters {
+		if ref.Value == nil {
+			continue
+		}
+		params[strings.ToLower(ref.Value.In)+"."+ref.Value.Name] = ref.Value
+	}
+
+	return params
+}
+
+func requestBody(op *openapi3.Operation) map[string]interface{} {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return nil
+	}
+
+	return map[string]interface{}{"requestBody": op.RequestBody.Value}
+}
+
+func responses(op *openapi3.Operation) map[string]interface{} {
+	resps := make(map[string]interface{}, len(op.Responses))
+	for status, ref := range op.Responses {
+		if ref.Value == nil {
+			continue
+		}
+		resps[status] = ref.Value
+	}
+
+	return resps
+}
+
+func jsonEqual(a, b interface{}) (bool, error) {
+	rawA, err := json.Marshal(a)
+	if err != nil {
+		return false, fmt.Errorf("marshal: %w", err)
+	}
+
+	rawB, err := json.Marshal(b)
+	if err != nil {
+		return false, fmt.Errorf("marshal: %w", err)
+	}
+
+	return bytes.Equal(rawA, rawB), nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffSpecs(t *testing.T) {
+	from := loadSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "Books", "version": "1"},
+		"paths": {
+			"/books": {
+				"get": {
+					"summary": "List books",
+					"parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer"}}],
+					"responses": {"200": {"description": "OK"}}
+				},
+				"delete": {"responses": {"204": {"description": "Deleted"}}}
+			},
+			"/books/{id}": {
+				"get": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`)
+	to := loadSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "Books", "version": "2"},
+		"paths": {
+			"/books": {
+				"get": {
+					"summary": "List all the books",
+					"parameters": [
+						{"name": "limit", "in": "query", "schema": {"type": "string"}},
+						{"name": "offset", "in": "query", "schema": {"type": "integer"}}
+					],
+					"responses": {"200": {"description": "OK"}, "400": {"description": "Bad request"}}
+				},
+				"post": {"responses": {"201": {"description": "Created"}}}
+			},
+			"/books/{id}": {
+				"get": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		}
+	}`)
+
+	got, err := diffSpecs(from, to)
+	require.NoError(t, err)
+
+	want := []OperationDiff{
+		{Path: "/books", Method: "DELETE", Change: ChangeRemoved},
+		{
+			Path:   "/books",
+			Method: "GET",
+			Change: ChangeModified,
+			Details: []FieldChange{
+				{Field: "summary", Change: ChangeModified},
+				{Field: "parameter query.limit", Change: ChangeModified},
+				{Field: "parameter query.offset", Change: ChangeAdded},
+				{Field: "response 400", Change: ChangeAdded},
+			},
+		},
+		{Path: "/books", Method: "POST", Change: ChangeAdded},
+	}
+	assert.Equal(t, want, got)
+}
+
+func loadSpec(t *testing.T, rawSpec string) *openapi3.T {
+	t.Helper()
+
+	spec, err := openapi3.NewLoader().LoadFromData([]byte(rawSpec))
+	require.NoError(t, err)
+
+	return spec
+}
@@ -0,0 +1,267 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	annotationHistoryAPI = "hub.traefik.io/api"
+
+	historyIndexKey = "versions.json"
+
+	// maxHistorySize is the maximum size of the data stored in a history ConfigMap. ConfigMaps are limited to 1MiB,
+	// some room is left for the object metadata.
+	maxHistorySize = 900 * 1024
+)
+
+// ErrSpecVersionNotFound is returned when a version of an OpenAPI spec is not in the history.
+var ErrSpecVersionNotFound = errors.New("spec version not found")
+
+// SpecVersion is a version of the OpenAPI spec of an API.
+type SpecVersion struct {
+	ID        int       `json:"id"`
+	Digest    string    `json:"digest"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SpecHistory keeps track of the successive versions of the OpenAPI specs of APIs. The history of each API is stored in
+// a ConfigMap, holding at most the configured number of versions.
+type SpecHistory struct {
+	client    kclientset.Interface
+	namespace string
+	retention int
+
+	// mu serializes the history updates, which are read-modify-write operations on ConfigMaps.
+	mu sync.Mutex
+}
+
+// NewSpecHistory returns a new SpecHistory storing its ConfigMaps in the given namespace.
+func NewSpecHistory(client kclientset.Interface, namespace string, retention int) *SpecHistory {
+	return &SpecHistory{
+		client:    client,
+		namespace: namespace,
+		retention: retention,
+	}
+}
+
+// Record adds the given spec to the history of the API, unless it is the same as the latest recorded version.
+// The oldest versions are dropped when the retention is reached.
+func (h *SpecHistory) Record(ctx context.Context, apiNameNamespace string, spec []byte) error {
+	sum := sha256.Sum256(spec)
+	digest := hex.EncodeToString(sum[:])
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cm, err := h.getConfigMap(ctx, apiNameNamespace)
+	if err != nil && !errors.Is(err, ErrSpecVersionNotFound) {
+		return err
+	}
+
+	create := cm == nil
+	if create {
+		cm, err = newHistoryConfigMap(apiNameNamespace, h.namespace)
+		if err != nil {
+			return err
+		}
+	}
+
+	versions, err := historyVersions(cm)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) > 0 && versions[len(versions)-1].Digest == digest {
+		return nil
+	}
+
+	id := 1
+	if len(versions) > 0 {
+		id = versions[len(versions)-1].ID + 1
+	}
+
+	versions = append(versions, SpecVersion{ID: id, Digest: digest, CreatedAt: time.Now().UTC()})
+	cm.Data[specKey(id)] = string(spec)
+
+	// Drop the oldest versions, always keeping the latest one.
+	for len(versions) > 1 && (len(versions) > h.retention || historySize(cm) > maxHistorySize) {
+		delete(cm.Data, specKey(versions[0].ID))
+		versions = versions[1:]
+	}
+
+	index, err := json.Marshal(versions)
+	if err != nil {
+		return fmt.Errorf("marshal spec versions: %w", err)
+	}
+	cm.Data[historyIndexKey] = string(index)
+
+	if create {
+		if _, err = h.client.CoreV1().ConfigMaps(h.namespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create history ConfigMap: %w", err)
+		}
+	} else if _, err = h.client.CoreV1().ConfigMaps(h.namespace).Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update history ConfigMap: %w", err)
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("api_name", apiNameNamespace).
+		Int("spec_version", id).
+		Msg("New OpenAPI spec version recorded")
+
+	return nil
+}
+
+// Versions returns the recorded versions of the spec of the given API, from the oldest to the latest.
+func (h *SpecHistory) Versions(ctx context.Context, apiNameNamespace string) ([]SpecVersion, error) {
+	cm, err := h.getConfigMap(ctx, apiNameNamespace)
+	if errors.Is(err, ErrSpecVersionNotFound) {
+		return []SpecVersion{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return historyVersions(cm)
+}
+
+// Get returns the given version of the spec of an API.
+func (h *SpecHistory) Get(ctx context.Context, apiNameNamespace string, id int) (SpecVersion, []byte, error) {
+	cm, err := h.getConfigMap(ctx, apiNameNamespace)
+	if err != nil {
+		return SpecVersion{}, nil, err
+	}
+
+	versions, err := historyVersions(cm)
+	if err != nil {
+		return SpecVersion{}, nil, err
+	}
+
+	for _, version := range versions {
+		if version.ID != id {
+			continue
+		}
+
+		spec, ok := cm.Data[specKey(id)]
+		if !ok {
+			return SpecVersion{}, nil, ErrSpecVersionNotFound
+		}
+
+		return version, []byte(spec), nil
+	}
+
+	return SpecVersion{}, nil, ErrSpecVersionNotFound
+}
+
+func (h *SpecHistory) getConfigMap(ctx context.Context, apiNameNamespace string) (*corev1.ConfigMap, error) {
+	name, err := historyConfigMapName(apiNameNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	cm, err := h.client.CoreV1().ConfigMaps(h.namespace).Get(ctx, name, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return nil, ErrSpecVersionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get history ConfigMap: %w", err)
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+
+	return cm, nil
+}
+
+func newHistoryConfigMap(apiNameNamespace, namespace string) (*corev1.ConfigMap, error) {
+	name, err := historyConfigMapName(apiNameNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+			Annotations: map[string]string{
+				annotationHistoryAPI: apiNameNamespace,
+			},
+		},
+		Data: make(map[string]string),
+	}, nil
+}
+
+func historyVersions(cm *corev1.ConfigMap) ([]SpecVersion, error) {
+	versions := make([]SpecVersion, 0)
+
+	index, ok := cm.Data[historyIndexKey]
+	if !ok {
+		return versions, nil
+	}
+
+	if err := json.Unmarshal([]byte(index), &versions); err != nil {
+		return nil, fmt.Errorf("unmarshal spec versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+func historySize(cm *corev1.ConfigMap) int {
+	var size int
+	for key, value := range cm.Data {
+		size += len(key) + len(value)
+	}
+
+	return size
+}
+
+func specKey(id int) string {
+	return strconv.Itoa(id) + ".json"
+}
+
+// historyConfigMapName computes the name of the ConfigMap holding the history of an API.
+// The name follows this format: hub-openapi-history-{hash(api-name@api-namespace)}
+func historyConfigMapName(apiNameNamespace string) (string, error) {
+	h := fnv.New32()
+
+	if _, err := h.Write([]byte(apiNameNamespace)); err != nil {
+		return "", fmt.Errorf("generate hash: %w", err)
+	}
+
+	return fmt.Sprintf("hub-openapi-history-%d", h.Sum32()), nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSpecHistory_Record(t *testing.T) {
+	ctx := context.Background()
+	history := NewSpecHistory(kubemock.NewSimpleClientset(), "hub", 2)
+
+	versions, err := history.Versions(ctx, "books@products-ns")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	require.NoError(t, history.Record(ctx, "books@products-ns", []byte(`{"openapi":"3.0.0","info":{"version":"1"}}`)))
+	// Recording the latest version again is a no-op.
+	require.NoError(t, history.Record(ctx, "books@products-ns", []byte(`{"openapi":"3.0.0","info":{"version":"1"}}`)))
+	require.NoError(t, history.Record(ctx, "books@products-ns", []byte(`{"openapi":"3.0.0","info":{"version":"2"}}`)))
+	require.NoError(t, history.Record(ctx, "books@products-ns", []byte(`{"openapi":"3.0.0","info":{"version":"3"}}`)))
+
+	versions, err = history.Versions(ctx, "books@products-ns")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].ID)
+	assert.Equal(t, 3, versions[1].ID)
+
+	_, _, err = history.Get(ctx, "books@products-ns", 1)
+	assert.ErrorIs(t, err, ErrSpecVersionNotFound)
+
+	version, spec, err := history.Get(ctx, "books@products-ns", 3)
+	require.NoError(t, err)
+	assert.Equal(t, versions[1], version)
+	assert.JSONEq(t, `{"openapi":"3.0.0","info":{"version":"3"}}`, string(spec))
+
+	// Histories are kept per API.
+	versions, err = history.Versions(ctx, "movies@products-ns")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
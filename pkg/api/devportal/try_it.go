@@ -0,0 +1,171 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	hubapi "github.com/traefik/hub-agent-kubernetes/pkg/api"
+)
+
+// TryItConfig configures a TryItProxy.
+type TryItConfig struct {
+	// MaxRequestBodyBytes is the maximum size of the request bodies sent to the APIs.
+	MaxRequestBodyBytes int64
+	// MaxResponseBodyBytes is the maximum size of the response bodies returned to the portal users.
+	MaxResponseBodyBytes int64
+	// Timeout is the maximum duration of a request, including the read of the response body.
+	Timeout time.Duration
+}
+
+// TryItProxy sends the requests portal users make from the portal UI to the APIs. Requests go through the API
+// gateway, so they are authenticated, rate limited and validated like any other request made to the APIs.
+type TryItProxy struct {
+	client *http.Client
+	config TryItConfig
+}
+
+// NewTryItProxy creates a new TryItProxy, reaching the API gateways with the given transport.
+func NewTryItProxy(transport http.RoundTripper, config TryItConfig) *TryItProxy {
+	return &TryItProxy{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+			// Redirects are returned to the portal UI, which shows them to the user.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config: config,
+	}
+}
+
+// Headers which are not forwarded in either direction. Portal users must not leak their portal session to the APIs,
+// and APIs must not set cookies on the portal domain.
+var tryItHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Cookie",
+	"Set-Cookie",
+	headerHubEmail,
+	headerHubGroups,
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Port",
+	"X-Forwarded-Proto",
+	"X-Forwarded-Server",
+	"X-Real-Ip",
+}
+
+// serve sends the given request to the given URL and writes back the response. Requests are flagged as sandbox
+// requests when the API has a sandbox.
+func (t *TryItProxy) serve(rw http.ResponseWriter, req *http.Request, target *url.URL, sandbox bool) {
+	logger := log.Ctx(req.Context())
+
+	// The request body is read before being sent, so a body too large to be sent to the API is rejected upfront.
+	body, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, t.config.MaxRequestBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(rw, fmt.Sprintf("request body exceeds %d bytes", t.config.MaxRequestBodyBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		logger.Debug().Err(err).Msg("Unable to read try it request")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	outReq, err := http.NewRequestWithContext(req.Context(), req.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to build try it request")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	outReq.Header = req.Header.Clone()
+	removeHeaders(outReq.Header)
+
+	if sandbox {
+		outReq.Header.Set(hubapi.HeaderSandbox, "true")
+	}
+
+	resp, err := t.client.Do(outReq)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Unable to send try it request")
+		rw.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// The response body is read before being written back, so a response too large to be returned to the user can
+	// still be turned into an error.
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, t.config.MaxResponseBodyBytes+1))
+	if err != nil {
+		logger.Debug().Err(err).Msg("Unable to read try it response")
+		rw.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	if int64(len(respBody)) > t.config.MaxResponseBodyBytes {
+		http.Error(rw, fmt.Sprintf("response body exceeds %d bytes", t.config.MaxResponseBodyBytes), http.StatusBadGateway)
+		return
+	}
+
+	logger.Debug().
+		Str("method", req.Method).
+		Str("url", target.String()).
+		Int("status", resp.StatusCode).
+		Msg("Try it request sent")
+
+	header := rw.Header()
+	for name, values := range resp.Header {
+		header[name] = values
+	}
+	removeHeaders(header)
+	header.Del("Content-Length")
+
+	rw.WriteHeader(resp.StatusCode)
+	_, _ = rw.Write(respBody)
+}
+
+func removeHeaders(header http.Header) {
+	// Headers listed in the Connection header are hop-by-hop headers too.
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			header.Del(strings.TrimSpace(name))
+		}
+	}
+
+	for _, name := range tryItHopHeaders {
+		header.Del(name)
+	}
+}
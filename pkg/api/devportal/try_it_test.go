@@ -0,0 +1,252 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// handlerTransport serves the requests with a handler instead of sending them.
+type handlerTransport struct {
+	handler http.HandlerFunc
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler(rec, req)
+
+	return rec.Result(), nil
+}
+
+func TestPortalAPI_Router_tryAPI(t *testing.T) {
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			Collections: map[string]collection{
+				"products": {
+					APICollection: hubv1alpha1.APICollection{
+						ObjectMeta: metav1.ObjectMeta{Name: "products"},
+						Spec:       hubv1alpha1.APICollectionSpec{PathPrefix: "/products"},
+					},
+					APIs: map[string]api{
+						"books@products-ns": {
+							API: hubv1alpha1.API{
+								ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "products-ns"},
+								Spec:       hubv1alpha1.APISpec{PathPrefix: "/books"},
+							},
+						},
+					},
+					authorizedGroups: []string{"supplier"},
+				},
+			},
+			APIs: map[string]api{
+				"orders@default": {
+					API: hubv1alpha1.API{
+						ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default"},
+						Spec:       hubv1alpha1.APISpec{PathPrefix: "/orders"},
+					},
+					authorizedGroups: []string{"supplier"},
+				},
+				"payments@default": {
+					API: hubv1alpha1.API{
+						ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "default"},
+						Spec: hubv1alpha1.APISpec{
+							PathPrefix: "/payments",
+							Sandbox: &hubv1alpha1.APISandbox{
+								Service: hubv1alpha1.APISandboxService{Name: "payments-sandbox"},
+							},
+						},
+					},
+					authorizedGroups: []string{"supplier"},
+				},
+				"managers@default": {
+					API: hubv1alpha1.API{
+						ObjectMeta: metav1.ObjectMeta{Name: "managers", Namespace: "default"},
+						Spec:       hubv1alpha1.APISpec{PathPrefix: "/managers"},
+					},
+					authorizedGroups: []string{"manager"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		desc        string
+		path        string
+		body        string
+		noEmail     bool
+		respBody    string
+		wantStatus  int
+		wantURL     string
+		wantSandbox bool
+	}{
+		{
+			desc:       "API",
+			path:       "/apis/orders@default/try/v1/orders/?limit=10",
+			body:       `{"item":"book"}`,
+			respBody:   `{"id":1}`,
+			wantStatus: http.StatusCreated,
+			wantURL:    "https://majestic-beaver-123.hub-traefik.io/orders/v1/orders/?limit=10",
+		},
+		{
+			desc:       "collection API",
+			path:       "/collections/products/apis/books@products-ns/try/books/42",
+			respBody:   `{"id":1}`,
+			wantStatus: http.StatusCreated,
+			wantURL:    "https://majestic-beaver-123.hub-traefik.io/products/books/books/42",
+		},
+		{
+			desc:        "API having a sandbox",
+			path:        "/apis/payments@default/try/charges",
+			respBody:    `{"id":1}`,
+			wantStatus:  http.StatusCreated,
+			wantURL:     "https://majestic-beaver-123.hub-traefik.io/payments/charges",
+			wantSandbox: true,
+		},
+		{
+			desc:       "unauthenticated user",
+			path:       "/apis/orders@default/try/v1/orders",
+			noEmail:    true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "API not accessible to the user groups",
+			path:       "/apis/managers@default/try/managers",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "unknown API",
+			path:       "/apis/unknown@default/try/unknown",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "request body too large",
+			path:       "/apis/orders@default/try/v1/orders",
+			body:       strings.Repeat("a", 33),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:       "response body too large",
+			path:       "/apis/orders@default/try/v1/orders",
+			respBody:   strings.Repeat("a", 65),
+			wantStatus: http.StatusBadGateway,
+			wantURL:    "https://majestic-beaver-123.hub-traefik.io/orders/v1/orders",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotReq *http.Request
+			var gotBody string
+			transport := handlerTransport{handler: func(rw http.ResponseWriter, req *http.Request) {
+				gotReq = req
+
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				gotBody = string(body)
+
+				http.SetCookie(rw, &http.Cookie{Name: "session", Value: "api"})
+				rw.Header().Set("X-Request-Id", "123")
+				rw.WriteHeader(http.StatusCreated)
+				_, _ = rw.Write([]byte(test.respBody))
+			}}
+
+			tryIt := NewTryItProxy(transport, TryItConfig{
+				MaxRequestBodyBytes:  32,
+				MaxResponseBodyBytes: 64,
+				Timeout:              time.Second,
+			})
+
+			a, err := NewPortalAPI(&p, nil, openapi.NewFetcher(nil, nil), nil, nil, tryIt, nil, nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://portal"+test.path, strings.NewReader(test.body))
+			req.Header.Set("Hub-Groups", "supplier")
+			req.Header.Set("Authorization", "Bearer api-key")
+			req.Header.Set("Cookie", "portal-session=secret")
+			if !test.noEmail {
+				req.Header.Set("Hub-Email", testEmail)
+			}
+			rec := httptest.NewRecorder()
+
+			a.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+
+			if test.wantURL == "" {
+				assert.Nil(t, gotReq)
+				return
+			}
+
+			require.NotNil(t, gotReq)
+			assert.Equal(t, http.MethodPost, gotReq.Method)
+			assert.Equal(t, test.wantURL, gotReq.URL.String())
+			assert.Equal(t, test.body, gotBody)
+			assert.Equal(t, "Bearer api-key", gotReq.Header.Get("Authorization"))
+			assert.Empty(t, gotReq.Header.Get("Cookie"))
+			assert.Empty(t, gotReq.Header.Get("Hub-Email"))
+			assert.Empty(t, gotReq.Header.Get("Hub-Groups"))
+
+			wantSandbox := ""
+			if test.wantSandbox {
+				wantSandbox = "true"
+			}
+			assert.Equal(t, wantSandbox, gotReq.Header.Get("X-Hub-Sandbox"))
+
+			if test.wantStatus != http.StatusCreated {
+				return
+			}
+
+			assert.Equal(t, test.respBody, rec.Body.String())
+			assert.Equal(t, "123", rec.Header().Get("X-Request-Id"))
+			assert.Empty(t, rec.Header().Get("Set-Cookie"))
+		})
+	}
+}
+
+func TestPortalAPI_Router_tryAPI_disabled(t *testing.T) {
+	a, err := NewPortalAPI(&testPortal, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://portal/apis/managers@people-ns/try/managers", http.NoBody)
+	req.Header.Set("Hub-Email", testEmail)
+	req.Header.Set("Hub-Groups", "manager")
+	rec := httptest.NewRecorder()
+
+	a.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
+	"golang.org/x/exp/slices"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+// ErrUsageUnavailable is returned when the API usages haven't been published.
+var ErrUsageUnavailable = errors.New("API usage unavailable")
+
+// UsageGetter gets the usage of the APIs by their consumers.
+type UsageGetter interface {
+	GetUsages(ctx context.Context) ([]metrics.APIUsage, error)
+}
+
+// UsageReader reads the API usages from the ConfigMap the controller publishes them to.
+type UsageReader struct {
+	client    kclientset.Interface
+	namespace string
+}
+
+// NewUsageReader returns a new UsageReader reading the API usages from the given namespace.
+func NewUsageReader(client kclientset.Interface, namespace string) *UsageReader {
+	return &UsageReader{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+// GetUsages gets the usages of the APIs during the last hour.
+func (r *UsageReader) GetUsages(ctx context.Context) ([]metrics.APIUsage, error) {
+	cm, err := r.client.CoreV1().ConfigMaps(r.namespace).Get(ctx, metrics.APIUsageConfigMap, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return nil, ErrUsageUnavailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get API usage ConfigMap: %w", err)
+	}
+
+	raw, ok := cm.Data[metrics.APIUsageKey]
+	if !ok {
+		return nil, ErrUsageUnavailable
+	}
+
+	var usages []metrics.APIUsage
+	if err = json.Unmarshal([]byte(raw), &usages); err != nil {
+		return nil, fmt.Errorf("unmarshal API usage: %w", err)
+	}
+
+	return usages, nil
+}
+
+// containsAny returns whether any of the given values is in the list.
+func containsAny(list, values []string) bool {
+	for _, value := range values {
+		if slices.Contains(list, value) {
+			return true
+		}
+	}
+
+	return false
+}
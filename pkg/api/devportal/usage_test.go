@@ -0,0 +1,138 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPortalAPI_Router_getAPIUsage(t *testing.T) {
+	usages := []metrics.APIUsage{
+		{API: "managers@people-ns", Groups: []string{"supplier", "partner"}, Requests: 10, RequestErrors: 1, ErrorRate: 0.1},
+		{API: "managers@people-ns", Groups: []string{"partner"}, Requests: 5},
+		{API: "books@products-ns", Groups: []string{"supplier"}, Requests: 3, AvgResponseTime: 0.2},
+	}
+	raw, err := json.Marshal(usages)
+	require.NoError(t, err)
+
+	usageConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: metrics.APIUsageConfigMap, Namespace: "hub"},
+		Data:       map[string]string{metrics.APIUsageKey: string(raw)},
+	}
+
+	tests := []struct {
+		desc       string
+		objects    []runtime.Object
+		disabled   bool
+		path       string
+		groups     []string
+		wantStatus int
+		wantUsages []metrics.APIUsage
+	}{
+		{
+			desc:       "usage of the groups of the user",
+			objects:    []runtime.Object{usageConfigMap},
+			path:       "/apis/managers@people-ns/usage",
+			groups:     []string{"supplier"},
+			wantStatus: http.StatusOK,
+			wantUsages: []metrics.APIUsage{usages[0]},
+		},
+		{
+			desc:       "usage of an API of a collection",
+			objects:    []runtime.Object{usageConfigMap},
+			path:       "/collections/products/apis/books@products-ns/usage",
+			groups:     []string{"supplier"},
+			wantStatus: http.StatusOK,
+			wantUsages: []metrics.APIUsage{usages[2]},
+		},
+		{
+			desc:       "no usage for the API",
+			objects:    []runtime.Object{&corev1.ConfigMap{ObjectMeta: usageConfigMap.ObjectMeta, Data: map[string]string{metrics.APIUsageKey: "[]"}}},
+			path:       "/apis/managers@people-ns/usage",
+			groups:     []string{"supplier"},
+			wantStatus: http.StatusOK,
+			wantUsages: []metrics.APIUsage{},
+		},
+		{
+			desc:       "API not accessible to the user",
+			objects:    []runtime.Object{usageConfigMap},
+			path:       "/apis/managers@people-ns/usage",
+			groups:     []string{"partner"},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "usage not published",
+			path:       "/apis/managers@people-ns/usage",
+			groups:     []string{"supplier"},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "usage disabled",
+			objects:    []runtime.Object{usageConfigMap},
+			disabled:   true,
+			path:       "/apis/managers@people-ns/usage",
+			groups:     []string{"supplier"},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var usage UsageGetter
+			if !test.disabled {
+				usage = NewUsageReader(kubemock.NewSimpleClientset(test.objects...), "hub")
+			}
+
+			a, err := NewPortalAPI(&testPortal, nil, openapi.NewFetcher(nil, nil), nil, nil, nil, nil, usage)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://portal"+test.path, http.NoBody)
+			for _, group := range test.groups {
+				req.Header.Add("Hub-Groups", group)
+			}
+			rec := httptest.NewRecorder()
+
+			a.ServeHTTP(rec, req)
+
+			require.Equal(t, test.wantStatus, rec.Code)
+			if test.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got []metrics.APIUsage
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+			assert.Equal(t, test.wantUsages, got)
+		})
+	}
+}
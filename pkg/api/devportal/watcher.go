@@ -44,6 +44,16 @@ type gateway struct {
 	APIs        map[string]api
 }
 
+// domains returns the domains the APIs of the gateway are reachable on. As soon as a CustomDomain is provided on the
+// Gateway, the APIs are no longer accessible through the HubDomain.
+func (g *gateway) domains() []string {
+	if len(g.Status.CustomDomains) > 0 {
+		return g.Status.CustomDomains
+	}
+
+	return []string{g.Status.HubDomain}
+}
+
 type collection struct {
 	hubv1alpha1.APICollection
 
@@ -40,10 +40,21 @@ type Gateway struct {
 	HubDomain     string         `json:"hubDomain,omitempty"`
 	CustomDomains []CustomDomain `json:"customDomains,omitempty"`
 
+	Certificate *SecretReference `json:"certificate,omitempty"`
+
+	MaxRequestBodyBytes *int64 `json:"maxRequestBodyBytes,omitempty"`
+
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// SecretReference references an entry of a Kubernetes secret.
+type SecretReference struct {
+	Name      string `json:"name" bson:"name"`
+	Namespace string `json:"namespace,omitempty" bson:"namespace,omitempty"`
+	Key       string `json:"key,omitempty" bson:"key,omitempty"`
+}
+
 // Resource builds the v1alpha1 APIGateway resource.
 func (g *Gateway) Resource() (*hubv1alpha1.APIGateway, error) {
 	var customDomains []string
@@ -54,6 +65,9 @@ func (g *Gateway) Resource() (*hubv1alpha1.APIGateway, error) {
 	spec := hubv1alpha1.APIGatewaySpec{
 		APIAccesses:   g.Accesses,
 		CustomDomains: customDomains,
+		Certificate:   (*hubv1alpha1.SecretReference)(g.Certificate),
+
+		MaxRequestBodyBytes: g.MaxRequestBodyBytes,
 	}
 
 	var urls []string
@@ -68,6 +82,8 @@ func (g *Gateway) Resource() (*hubv1alpha1.APIGateway, error) {
 	}
 	urls = append(urls, "https://"+g.HubDomain)
 
+	syncedAt := metav1.Now()
+
 	gateway := &hubv1alpha1.APIGateway{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "hub.traefik.io/v1alpha1",
@@ -80,10 +96,11 @@ func (g *Gateway) Resource() (*hubv1alpha1.APIGateway, error) {
 		Spec: spec,
 		Status: hubv1alpha1.APIGatewayStatus{
 			Version:       g.Version,
-			SyncedAt:      metav1.Now(),
+			SyncedAt:      syncedAt,
 			HubDomain:     g.HubDomain,
 			CustomDomains: verifiedCustomDomains,
 			URLs:          strings.Join(urls, ","),
+			Conditions:    []metav1.Condition{syncedCondition(syncedAt)},
 		},
 	}
 
@@ -114,6 +131,10 @@ type gatewayHash struct {
 	Accesses      []string          `json:"accesses,omitempty"`
 	HubDomain     string            `json:"hubDomain,omitempty"`
 	CustomDomains []string          `json:"customDomains,omitempty"`
+
+	Certificate *hubv1alpha1.SecretReference `json:"certificate,omitempty"`
+
+	MaxRequestBodyBytes *int64 `json:"maxRequestBodyBytes,omitempty"`
 }
 
 // HashGateway generates the hash of the APIGateway.
@@ -123,6 +144,9 @@ func HashGateway(g *hubv1alpha1.APIGateway) (string, error) {
 		Accesses:      g.Spec.APIAccesses,
 		HubDomain:     g.Status.HubDomain,
 		CustomDomains: g.Spec.CustomDomains,
+		Certificate:   g.Spec.Certificate,
+
+		MaxRequestBodyBytes: g.Spec.MaxRequestBodyBytes,
 	}
 
 	h, err := sum(gh)
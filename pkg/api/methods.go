@@ -0,0 +1,47 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// PathAllowedMethods is the auth server path checking the methods of the requests sent to the APIs restricting them.
+// It is called by the ForwardAuth middlewares set on the routes of these APIs, with the allowed methods given as a
+// comma-separated list in the "methods" query parameter.
+const PathAllowedMethods = "/_allowed-methods"
+
+// AllowedMethodsHandler authorizes the requests forwarded by Traefik whose method is allowed, and rejects the other
+// ones with a 405 status code.
+type AllowedMethodsHandler struct{}
+
+func (AllowedMethodsHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	method := req.Header.Get("X-Forwarded-Method")
+
+	allowed := strings.Split(req.URL.Query().Get("methods"), ",")
+	for _, m := range allowed {
+		if m == method {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	rw.Header().Set("Allow", strings.Join(allowed, ", "))
+	http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
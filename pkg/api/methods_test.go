@@ -0,0 +1,70 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedMethodsHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc      string
+		method    string
+		wantCode  int
+		wantAllow string
+	}{
+		{
+			desc:     "allowed method",
+			method:   http.MethodPost,
+			wantCode: http.StatusOK,
+		},
+		{
+			desc:      "forbidden method",
+			method:    http.MethodDelete,
+			wantCode:  http.StatusMethodNotAllowed,
+			wantAllow: "GET, POST",
+		},
+		{
+			desc:      "missing method",
+			wantCode:  http.StatusMethodNotAllowed,
+			wantAllow: "GET, POST",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://auth-server"+PathAllowedMethods+"?methods=GET,POST", nil)
+			if test.method != "" {
+				req.Header.Set("X-Forwarded-Method", test.method)
+			}
+			rec := httptest.NewRecorder()
+
+			AllowedMethodsHandler{}.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			assert.Equal(t, test.wantAllow, rec.Header().Get("Allow"))
+		})
+	}
+}
@@ -999,3 +999,323 @@ func (_c *platformClientGetWildcardCertificateCall) OnGetPortalsRaw() *platformC
 func (_c *platformClientGetWildcardCertificateCall) OnGetWildcardCertificateRaw() *platformClientGetWildcardCertificateCall {
 	return _c.Parent.OnGetWildcardCertificateRaw()
 }
+
+// rateLimitPlatformClientMock mock of RateLimitPlatformClient.
+type rateLimitPlatformClientMock struct{ mock.Mock }
+
+// newRateLimitPlatformClientMock creates a new rateLimitPlatformClientMock.
+func newRateLimitPlatformClientMock(tb testing.TB) *rateLimitPlatformClientMock {
+	tb.Helper()
+
+	m := &rateLimitPlatformClientMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *rateLimitPlatformClientMock) GetRateLimits(_ context.Context) ([]RateLimit, error) {
+	_ret := _m.Called()
+
+	if _rf, ok := _ret.Get(0).(func() ([]RateLimit, error)); ok {
+		return _rf()
+	}
+
+	_ra0, _ := _ret.Get(0).([]RateLimit)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *rateLimitPlatformClientMock) OnGetRateLimits() *rateLimitPlatformClientGetRateLimitsCall {
+	return &rateLimitPlatformClientGetRateLimitsCall{Call: _m.Mock.On("GetRateLimits"), Parent: _m}
+}
+
+func (_m *rateLimitPlatformClientMock) OnGetRateLimitsRaw() *rateLimitPlatformClientGetRateLimitsCall {
+	return &rateLimitPlatformClientGetRateLimitsCall{Call: _m.Mock.On("GetRateLimits"), Parent: _m}
+}
+
+type rateLimitPlatformClientGetRateLimitsCall struct {
+	*mock.Call
+	Parent *rateLimitPlatformClientMock
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) Panic(msg string) *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) Once() *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) Twice() *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) Times(i int) *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) WaitUntil(w <-chan time.Time) *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) After(d time.Duration) *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) Run(fn func(args mock.Arguments)) *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) Maybe() *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) TypedReturns(a []RateLimit, b error) *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) ReturnsFn(fn func() ([]RateLimit, error)) *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) TypedRun(fn func()) *rateLimitPlatformClientGetRateLimitsCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		fn()
+	})
+	return _c
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) OnGetRateLimits() *rateLimitPlatformClientGetRateLimitsCall {
+	return _c.Parent.OnGetRateLimits()
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) OnSetRateLimitStatus(name string, status RateLimitStatus) *rateLimitPlatformClientSetRateLimitStatusCall {
+	return _c.Parent.OnSetRateLimitStatus(name, status)
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) OnGetRateLimitsRaw() *rateLimitPlatformClientGetRateLimitsCall {
+	return _c.Parent.OnGetRateLimitsRaw()
+}
+
+func (_c *rateLimitPlatformClientGetRateLimitsCall) OnSetRateLimitStatusRaw(name interface{}, status interface{}) *rateLimitPlatformClientSetRateLimitStatusCall {
+	return _c.Parent.OnSetRateLimitStatusRaw(name, status)
+}
+
+func (_m *rateLimitPlatformClientMock) SetRateLimitStatus(_ context.Context, name string, status RateLimitStatus) error {
+	_ret := _m.Called(name, status)
+
+	if _rf, ok := _ret.Get(0).(func(string, RateLimitStatus) error); ok {
+		return _rf(name, status)
+	}
+
+	_ra0 := _ret.Error(0)
+
+	return _ra0
+}
+
+func (_m *rateLimitPlatformClientMock) OnSetRateLimitStatus(name string, status RateLimitStatus) *rateLimitPlatformClientSetRateLimitStatusCall {
+	return &rateLimitPlatformClientSetRateLimitStatusCall{Call: _m.Mock.On("SetRateLimitStatus", name, status), Parent: _m}
+}
+
+func (_m *rateLimitPlatformClientMock) OnSetRateLimitStatusRaw(name interface{}, status interface{}) *rateLimitPlatformClientSetRateLimitStatusCall {
+	return &rateLimitPlatformClientSetRateLimitStatusCall{Call: _m.Mock.On("SetRateLimitStatus", name, status), Parent: _m}
+}
+
+type rateLimitPlatformClientSetRateLimitStatusCall struct {
+	*mock.Call
+	Parent *rateLimitPlatformClientMock
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) Panic(msg string) *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) Once() *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) Twice() *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) Times(i int) *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) WaitUntil(w <-chan time.Time) *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) After(d time.Duration) *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) Run(fn func(args mock.Arguments)) *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) Maybe() *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) TypedReturns(a error) *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Return(a)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) ReturnsFn(fn func(string, RateLimitStatus) error) *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) TypedRun(fn func(string, RateLimitStatus)) *rateLimitPlatformClientSetRateLimitStatusCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_name := args.String(0)
+		_status, _ := args.Get(1).(RateLimitStatus)
+		fn(_name, _status)
+	})
+	return _c
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) OnGetRateLimits() *rateLimitPlatformClientGetRateLimitsCall {
+	return _c.Parent.OnGetRateLimits()
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) OnSetRateLimitStatus(name string, status RateLimitStatus) *rateLimitPlatformClientSetRateLimitStatusCall {
+	return _c.Parent.OnSetRateLimitStatus(name, status)
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) OnGetRateLimitsRaw() *rateLimitPlatformClientGetRateLimitsCall {
+	return _c.Parent.OnGetRateLimitsRaw()
+}
+
+func (_c *rateLimitPlatformClientSetRateLimitStatusCall) OnSetRateLimitStatusRaw(name interface{}, status interface{}) *rateLimitPlatformClientSetRateLimitStatusCall {
+	return _c.Parent.OnSetRateLimitStatusRaw(name, status)
+}
+
+// apiVersionPlatformClientMock mock of APIVersionPlatformClient.
+type apiVersionPlatformClientMock struct{ mock.Mock }
+
+// newAPIVersionPlatformClientMock creates a new apiVersionPlatformClientMock.
+func newAPIVersionPlatformClientMock(tb testing.TB) *apiVersionPlatformClientMock {
+	tb.Helper()
+
+	m := &apiVersionPlatformClientMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *apiVersionPlatformClientMock) GetAPIVersions(_ context.Context) ([]APIVersion, error) {
+	_ret := _m.Called()
+
+	if _rf, ok := _ret.Get(0).(func() ([]APIVersion, error)); ok {
+		return _rf()
+	}
+
+	_ra0, _ := _ret.Get(0).([]APIVersion)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *apiVersionPlatformClientMock) OnGetAPIVersions() *apiVersionPlatformClientGetAPIVersionsCall {
+	return &apiVersionPlatformClientGetAPIVersionsCall{Call: _m.Mock.On("GetAPIVersions"), Parent: _m}
+}
+
+func (_m *apiVersionPlatformClientMock) OnGetAPIVersionsRaw() *apiVersionPlatformClientGetAPIVersionsCall {
+	return &apiVersionPlatformClientGetAPIVersionsCall{Call: _m.Mock.On("GetAPIVersions"), Parent: _m}
+}
+
+type apiVersionPlatformClientGetAPIVersionsCall struct {
+	*mock.Call
+	Parent *apiVersionPlatformClientMock
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) Panic(msg string) *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) Once() *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) Twice() *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) Times(i int) *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) WaitUntil(w <-chan time.Time) *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) After(d time.Duration) *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) Run(fn func(args mock.Arguments)) *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) Maybe() *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) TypedReturns(a []APIVersion, b error) *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) ReturnsFn(fn func() ([]APIVersion, error)) *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) TypedRun(fn func()) *apiVersionPlatformClientGetAPIVersionsCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		fn()
+	})
+	return _c
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) OnGetAPIVersions() *apiVersionPlatformClientGetAPIVersionsCall {
+	return _c.Parent.OnGetAPIVersions()
+}
+
+func (_c *apiVersionPlatformClientGetAPIVersionsCall) OnGetAPIVersionsRaw() *apiVersionPlatformClientGetAPIVersionsCall {
+	return _c.Parent.OnGetAPIVersionsRaw()
+}
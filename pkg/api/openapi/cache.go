@@ -0,0 +1,355 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// refreshTimeout is the maximum duration of the refresh of a spec.
+	refreshTimeout = 30 * time.Second
+	// minRefreshBackoff is the delay before refreshing again a spec whose refresh failed for the first time. It
+	// doubles on each consecutive failure, up to the refresh interval.
+	minRefreshBackoff = 5 * time.Second
+	// idleRefreshes is the number of refresh intervals after which the specs which were not requested are evicted.
+	idleRefreshes = 10
+)
+
+// CacheConfig configures a Cache.
+type CacheConfig struct {
+	// Dir is the directory the specs are persisted in, so they survive restarts. Specs are only kept in memory when
+	// empty.
+	Dir string
+	// RefreshInterval is the interval at which the cached specs are fetched again.
+	RefreshInterval time.Duration
+}
+
+// Cache serves the OpenAPI specs of APIs from memory, and refreshes them in the background. Specs are refreshed with
+// conditional requests, so unchanged specs are not transferred again. Stale specs keep being served while their
+// refresh fails.
+type Cache struct {
+	fetcher *Fetcher
+	config  CacheConfig
+
+	// checkInterval is the interval at which the specs due for a refresh are looked up.
+	checkInterval time.Duration
+	now           func() time.Time
+
+	fetches singleflight.Group
+
+	entriesMu sync.Mutex
+	// entries are the cached specs, by "name@namespace" of their API.
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a cached spec. Entries are persisted as is when the cache has a directory.
+type cacheEntry struct {
+	// Source identifies how the spec was fetched. The entry is no longer valid when the API changes it.
+	Source    string    `json:"source"`
+	Document  *document `json:"document"`
+	FetchedAt time.Time `json:"fetchedAt"`
+
+	api         *hubv1alpha1.API
+	usedAt      time.Time
+	failures    int
+	nextRefresh time.Time
+}
+
+// NewCache creates a new Cache, fetching the specs with the given Fetcher.
+func NewCache(fetcher *Fetcher, config CacheConfig) (*Cache, error) {
+	if config.RefreshInterval <= 0 {
+		return nil, errors.New("refresh interval must be positive")
+	}
+
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create cache directory: %w", err)
+		}
+	}
+
+	return &Cache{
+		fetcher:       fetcher,
+		config:        config,
+		checkInterval: 5 * time.Second,
+		now:           time.Now,
+		entries:       make(map[string]*cacheEntry),
+	}, nil
+}
+
+// Run refreshes the cached specs until the given context is done.
+func (c *Cache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.refresh(ctx)
+		}
+	}
+}
+
+// Fetch returns the OpenAPI spec of the given API, fetching it only if it isn't cached yet.
+func (c *Cache) Fetch(ctx context.Context, a *hubv1alpha1.API) (*openapi3.T, error) {
+	key := a.Name + "@" + a.Namespace
+
+	source, err := specSource(a)
+	if err != nil {
+		return nil, err
+	}
+
+	c.entriesMu.Lock()
+	entry, ok := c.entries[key]
+	if ok && entry.Source == source {
+		entry.api = a
+		entry.usedAt = c.now()
+		doc := entry.Document
+		c.entriesMu.Unlock()
+
+		return load(doc)
+	}
+	c.entriesMu.Unlock()
+
+	v, err, _ := c.fetches.Do(key+"/"+source, func() (interface{}, error) {
+		// Specs persisted by a previous run are served right away, and refreshed in the background if stale.
+		fetched := c.readEntry(key, source)
+		if fetched == nil {
+			doc, errFetch := c.fetcher.fetchDocument(ctx, a, nil)
+			if errFetch != nil {
+				return nil, errFetch
+			}
+
+			fetched = &cacheEntry{Source: source, Document: doc, FetchedAt: c.now()}
+			c.writeEntry(key, fetched)
+		}
+
+		fetched.api = a
+		fetched.usedAt = c.now()
+		fetched.nextRefresh = fetched.FetchedAt.Add(c.config.RefreshInterval)
+
+		c.entriesMu.Lock()
+		c.entries[key] = fetched
+		c.entriesMu.Unlock()
+
+		return fetched.Document, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return load(v.(*document))
+}
+
+// refresh fetches again the specs due for a refresh, and evicts the ones which are no longer requested.
+func (c *Cache) refresh(ctx context.Context) {
+	now := c.now()
+
+	var due []string
+	c.entriesMu.Lock()
+	for key, entry := range c.entries {
+		if now.Sub(entry.usedAt) > idleRefreshes*c.config.RefreshInterval {
+			delete(c.entries, key)
+			c.removeEntry(key)
+			continue
+		}
+
+		if !now.Before(entry.nextRefresh) {
+			due = append(due, key)
+		}
+	}
+	c.entriesMu.Unlock()
+
+	for _, key := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.refreshEntry(ctx, key)
+	}
+}
+
+func (c *Cache) refreshEntry(ctx context.Context, key string) {
+	c.entriesMu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		c.entriesMu.Unlock()
+		return
+	}
+	a, prev := entry.api, entry.Document
+	c.entriesMu.Unlock()
+
+	ctxRefresh, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	doc, err := c.fetcher.fetchDocument(ctxRefresh, a, prev)
+
+	c.entriesMu.Lock()
+	defer c.entriesMu.Unlock()
+
+	// The entry may have been replaced by a fetch of the spec from another source in the meantime.
+	if c.entries[key] != entry {
+		return
+	}
+
+	now := c.now()
+	if err != nil {
+		entry.failures++
+		entry.nextRefresh = now.Add(refreshBackoff(entry.failures, c.config.RefreshInterval))
+
+		log.Warn().Err(err).
+			Str("api", key).
+			Int("failures", entry.failures).
+			Time("next_refresh", entry.nextRefresh).
+			Msg("Unable to refresh OpenAPI spec, serving the cached one")
+		return
+	}
+
+	entry.failures = 0
+	entry.FetchedAt = now
+	entry.nextRefresh = now.Add(c.config.RefreshInterval)
+
+	if doc != prev {
+		entry.Document = doc
+		log.Debug().Str("api", key).Msg("OpenAPI spec changed")
+	}
+
+	c.writeEntry(key, entry)
+}
+
+// readEntry reads the entry of the given key from the cache directory. It returns nil if there is no such entry, or if
+// it was fetched from another source.
+func (c *Cache) readEntry(key, source string) *cacheEntry {
+	if c.config.Dir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Warn().Err(err).Str("api", key).Msg("Unable to read cached OpenAPI spec")
+		}
+		return nil
+	}
+
+	var entry cacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		log.Warn().Err(err).Str("api", key).Msg("Unable to decode cached OpenAPI spec")
+		return nil
+	}
+
+	if entry.Source != source || entry.Document == nil {
+		return nil
+	}
+
+	return &entry
+}
+
+// writeEntry persists the given entry in the cache directory, if any. The entry is written to a temporary file first
+// so a concurrent read never sees a partially written entry.
+func (c *Cache) writeEntry(key string, entry *cacheEntry) {
+	if c.config.Dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Warn().Err(err).Str("api", key).Msg("Unable to encode OpenAPI spec")
+		return
+	}
+
+	path := c.entryPath(key)
+
+	tmp, err := os.CreateTemp(c.config.Dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		log.Warn().Err(err).Str("api", key).Msg("Unable to persist OpenAPI spec")
+		return
+	}
+
+	_, err = tmp.Write(data)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		log.Warn().Err(err).Str("api", key).Msg("Unable to persist OpenAPI spec")
+	}
+}
+
+func (c *Cache) removeEntry(key string) {
+	if c.config.Dir == "" {
+		return
+	}
+
+	if err := os.Remove(c.entryPath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Warn().Err(err).Str("api", key).Msg("Unable to remove cached OpenAPI spec")
+	}
+}
+
+func (c *Cache) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.config.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// specSource returns a hash of the configuration used to fetch the spec of the given API.
+func specSource(a *hubv1alpha1.API) (string, error) {
+	data, err := json.Marshal(a.Spec.Service)
+	if err != nil {
+		return "", fmt.Errorf("encode API service: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// refreshBackoff returns the delay before refreshing again a spec whose refresh failed the given number of
+// consecutive times.
+func refreshBackoff(failures int, refreshInterval time.Duration) time.Duration {
+	backoff := minRefreshBackoff
+	for i := 1; i < failures && backoff < refreshInterval; i++ {
+		backoff *= 2
+	}
+
+	if backoff > refreshInterval {
+		return refreshInterval
+	}
+
+	return backoff
+}
@@ -0,0 +1,223 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// specServer serves a spec whose version can be changed, or an error status code.
+type specServer struct {
+	mu       sync.Mutex
+	version  string
+	status   int
+	requests int
+	notMod   int
+}
+
+func (s *specServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	if s.status != 0 {
+		rw.WriteHeader(s.status)
+		return
+	}
+
+	etag := `"` + s.version + `"`
+	if req.Header.Get("If-None-Match") == etag {
+		s.notMod++
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rw.Header().Set("ETag", etag)
+	_, _ = fmt.Fprintf(rw, `{"openapi": "3.0.3", "info": {"title": "Books", "version": %q}, "paths": {}}`, s.version)
+}
+
+func (s *specServer) set(version string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.version = version
+	s.status = status
+}
+
+func (s *specServer) counts() (requests, notModified int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests, s.notMod
+}
+
+func TestCache_Fetch(t *testing.T) {
+	srv := &specServer{version: "1.0.0"}
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+
+	c, err := NewCache(NewFetcher(nil, nil), CacheConfig{RefreshInterval: time.Minute})
+	require.NoError(t, err)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	a := newSpecAPI(httpSrv.URL)
+
+	// The spec is only fetched once.
+	for i := 0; i < 3; i++ {
+		spec, errFetch := c.Fetch(context.Background(), a)
+		require.NoError(t, errFetch)
+		assert.Equal(t, "1.0.0", spec.Info.Version)
+	}
+
+	requests, _ := srv.counts()
+	assert.Equal(t, 1, requests)
+
+	// Specs are not refreshed before the refresh interval.
+	c.refresh(context.Background())
+
+	requests, _ = srv.counts()
+	assert.Equal(t, 1, requests)
+
+	// Unchanged specs are not transferred again.
+	now = now.Add(time.Minute)
+	c.refresh(context.Background())
+
+	requests, notModified := srv.counts()
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, notModified)
+
+	// Changed specs are served once refreshed.
+	srv.set("2.0.0", 0)
+
+	spec, err := c.Fetch(context.Background(), a)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", spec.Info.Version)
+
+	now = now.Add(time.Minute)
+	c.refresh(context.Background())
+
+	spec, err = c.Fetch(context.Background(), a)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", spec.Info.Version)
+
+	// Stale specs are served while their refresh fails, and the refresh is retried with a backoff.
+	srv.set("3.0.0", http.StatusNotFound)
+
+	now = now.Add(time.Minute)
+	c.refresh(context.Background())
+
+	spec, err = c.Fetch(context.Background(), a)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", spec.Info.Version)
+	assert.Equal(t, now.Add(minRefreshBackoff), c.entries["books@books-ns"].nextRefresh)
+
+	// Specs fetched from another source are fetched right away.
+	srv.set("3.0.0", 0)
+	a.Spec.Service.OpenAPISpec.URL = httpSrv.URL + "/v3"
+
+	spec, err = c.Fetch(context.Background(), a)
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.0", spec.Info.Version)
+
+	// Specs which are no longer requested are evicted.
+	now = now.Add(idleRefreshes*time.Minute + time.Second)
+	c.refresh(context.Background())
+
+	assert.Empty(t, c.entries)
+}
+
+func TestCache_Fetch_persisted(t *testing.T) {
+	srv := &specServer{version: "1.0.0"}
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(httpSrv.Close)
+
+	dir := t.TempDir()
+	a := newSpecAPI(httpSrv.URL)
+
+	c, err := NewCache(NewFetcher(nil, nil), CacheConfig{Dir: dir, RefreshInterval: time.Minute})
+	require.NoError(t, err)
+
+	_, err = c.Fetch(context.Background(), a)
+	require.NoError(t, err)
+
+	// Specs are served from the cache directory after a restart, even if the API service fails.
+	srv.set("2.0.0", http.StatusNotFound)
+
+	c, err = NewCache(NewFetcher(nil, nil), CacheConfig{Dir: dir, RefreshInterval: time.Minute})
+	require.NoError(t, err)
+
+	spec, err := c.Fetch(context.Background(), a)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", spec.Info.Version)
+
+	// Specs persisted for another source are ignored.
+	a.Spec.Service.OpenAPISpec.URL = httpSrv.URL + "/v2"
+
+	_, err = c.Fetch(context.Background(), a)
+	assert.Error(t, err)
+}
+
+func Test_refreshBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 5 * time.Second},
+		{failures: 2, want: 10 * time.Second},
+		{failures: 4, want: 40 * time.Second},
+		{failures: 5, want: time.Minute},
+		{failures: 100, want: time.Minute},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprint(test.failures), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, refreshBackoff(test.failures, time.Minute))
+		})
+	}
+}
+
+func newSpecAPI(specURL string) *hubv1alpha1.API {
+	return &hubv1alpha1.API{
+		ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "books-ns"},
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: "/books",
+			Service: hubv1alpha1.APIService{
+				Name:        "books-svc",
+				Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+				OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: specURL},
+			},
+		},
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+// Package openapi fetches the OpenAPI specs of APIs.
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/secretref"
+)
+
+// Default entries of the secrets referenced by OpenAPI specs.
+const (
+	defaultBearerTokenKey = "token"
+	defaultCAKey          = "ca.crt"
+)
+
+// Fetcher fetches the OpenAPI specs of APIs, sending the headers and credentials they configure.
+type Fetcher struct {
+	secrets *secretref.Resolver
+
+	clientsMu sync.Mutex
+	// clients are the HTTP clients trusting custom certificate authorities, by certificate authorities hash.
+	clients       map[string]*http.Client
+	defaultClient *http.Client
+	transport     *http.Transport
+}
+
+// NewFetcher creates a new Fetcher, whose transports are built from the given one, or from http.DefaultTransport when
+// nil. Specs referencing secrets can't be fetched without a secret resolver.
+func NewFetcher(transport *http.Transport, secrets *secretref.Resolver) *Fetcher {
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+
+	return &Fetcher{
+		secrets:       secrets,
+		clients:       make(map[string]*http.Client),
+		defaultClient: newClient(transport.Clone()),
+		transport:     transport,
+	}
+}
+
+// document is a raw OpenAPI spec, along with the validators of the response it was received in.
+type document struct {
+	Data         []byte `json:"data"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// Fetch fetches the OpenAPI spec of the given API.
+func (f *Fetcher) Fetch(ctx context.Context, a *hubv1alpha1.API) (*openapi3.T, error) {
+	doc, err := f.fetchDocument(ctx, a, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return load(doc)
+}
+
+// fetchDocument fetches the raw OpenAPI spec of the given API. When a previously fetched document is given, the request
+// is conditional and the given document is returned if the spec did not change.
+func (f *Fetcher) fetchDocument(ctx context.Context, a *hubv1alpha1.API, prev *document) (*document, error) {
+	namespace := a.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	specURL, err := SpecURL(a)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, specURL.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request %q: %w", specURL.String(), err)
+	}
+
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Accept", "application/yaml")
+
+	if prev != nil {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
+	spec := a.Spec.Service.OpenAPISpec
+	for name, value := range spec.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if err = f.setAuth(req, spec.Auth, namespace); err != nil {
+		return nil, fmt.Errorf("set credentials: %w", err)
+	}
+
+	client, err := f.client(spec.CA, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("build HTTP client: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request %q: %w", specURL.String(), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if prev != nil && resp.StatusCode == http.StatusNotModified {
+		return prev, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch spec %q: unexpected status code %d", specURL.String(), resp.StatusCode)
+	}
+
+	rawSpec, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read spec %q: %w", specURL.String(), err)
+	}
+
+	return &document{
+		Data:         rawSpec,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// load parses the given document.
+func load(doc *document) (*openapi3.T, error) {
+	// A new loader must be created each time. LoadFromData mutates the internal state of Loader.
+	// LoadFromURI doesn't take a context, therefore, we must do the call ourselves.
+	loaded, err := openapi3.NewLoader().LoadFromData(doc.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+
+	return loaded, nil
+}
+
+// SpecURL returns the URL of the OpenAPI spec of the given API: either its spec URL, or an endpoint of its service.
+func SpecURL(a *hubv1alpha1.API) (*url.URL, error) {
+	svc := a.Spec.Service
+
+	switch {
+	case svc.OpenAPISpec.URL != "":
+		u, err := url.Parse(svc.OpenAPISpec.URL)
+		if err != nil {
+			return nil, fmt.Errorf("parse OpenAPI URL %q: %w", svc.OpenAPISpec.URL, err)
+		}
+
+		return u, nil
+
+	case svc.Port.Number != 0 || svc.OpenAPISpec.Port != nil && svc.OpenAPISpec.Port.Number != 0:
+		protocol := svc.OpenAPISpec.Protocol
+		if svc.OpenAPISpec.Protocol == "" {
+			protocol = "http"
+		}
+
+		port := svc.Port.Number
+		if svc.OpenAPISpec.Port != nil {
+			port = svc.OpenAPISpec.Port.Number
+		}
+
+		namespace := a.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+
+		return &url.URL{
+			Scheme: protocol,
+			Host:   fmt.Sprint(svc.Name, ".", namespace, ":", port),
+			Path:   svc.OpenAPISpec.Path,
+		}, nil
+
+	default:
+		return nil, errors.New("no spec endpoint specified")
+	}
+}
+
+func (f *Fetcher) setAuth(req *http.Request, auth *hubv1alpha1.OpenAPISpecAuth, namespace string) error {
+	if auth == nil {
+		return nil
+	}
+
+	if auth.BearerToken != nil {
+		token, err := f.secretValue(*auth.BearerToken, namespace, defaultBearerTokenKey)
+		if err != nil {
+			return fmt.Errorf("get bearer token: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+string(token))
+	}
+
+	for name, ref := range auth.Headers {
+		value, err := f.secretValue(ref, namespace, name)
+		if err != nil {
+			return fmt.Errorf("get header %q: %w", name, err)
+		}
+
+		req.Header.Set(name, string(value))
+	}
+
+	return nil
+}
+
+func (f *Fetcher) client(ca *hubv1alpha1.SecretReference, namespace string) (*http.Client, error) {
+	if ca == nil {
+		return f.defaultClient, nil
+	}
+
+	bundle, err := f.secretValue(*ca, namespace, defaultCAKey)
+	if err != nil {
+		return nil, fmt.Errorf("get certificate authorities: %w", err)
+	}
+
+	sum := sha256.Sum256(bundle)
+	key := hex.EncodeToString(sum[:])
+
+	f.clientsMu.Lock()
+	defer f.clientsMu.Unlock()
+
+	if client, ok := f.clients[key]; ok {
+		return client, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, errors.New("no valid PEM encoded certificate authority")
+	}
+
+	transport := f.transport.Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    pool,
+	}
+
+	client := newClient(transport)
+	f.clients[key] = client
+
+	return client, nil
+}
+
+func (f *Fetcher) secretValue(ref hubv1alpha1.SecretReference, namespace, defaultKey string) ([]byte, error) {
+	if f.secrets == nil {
+		return nil, errors.New("secret references are not supported")
+	}
+
+	return f.secrets.Value(secretref.RefOf(ref, namespace), secretref.KeyOf(ref, defaultKey))
+}
+
+func newClient(transport http.RoundTripper) *http.Client {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 4
+	client.HTTPClient.Transport = transport
+	client.CheckRetry = checkRetry
+	client.Logger = logwrapper.NewRetryableHTTPWrapper(log.Logger.With().
+		Str("component", "openapi_fetcher").
+		Logger())
+
+	return client.StandardClient()
+}
+
+// checkRetry extends the default retry policy, not retrying on certificate verification errors which are permanent.
+func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return false, err
+	}
+
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/secretref"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestFetcher_Fetch(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret-token" ||
+			req.Header.Get("X-Api-Key") != "secret-key" ||
+			req.Header.Get("X-Tenant") != "acme" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(openapi3.T{OpenAPI: "3.0.0", Info: &openapi3.Info{Title: "Books"}})
+	}))
+	t.Cleanup(srv.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	secrets := secretref.NewResolver(newSecretLister(t,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "spec-credentials", Namespace: "books"},
+			Data: map[string][]byte{
+				"token":   []byte("secret-token"),
+				"api-key": []byte("secret-key"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal-ca", Namespace: "pki"},
+			Data:       map[string][]byte{"ca.crt": ca},
+		},
+	))
+
+	validSpec := hubv1alpha1.OpenAPISpec{
+		URL:     srv.URL + "/spec.json",
+		Headers: map[string]string{"X-Tenant": "acme"},
+		Auth: &hubv1alpha1.OpenAPISpecAuth{
+			BearerToken: &hubv1alpha1.SecretReference{Name: "spec-credentials"},
+			Headers: map[string]hubv1alpha1.SecretReference{
+				"X-Api-Key": {Name: "spec-credentials", Key: "api-key"},
+			},
+		},
+		CA: &hubv1alpha1.SecretReference{Name: "internal-ca", Namespace: "pki"},
+	}
+
+	tests := []struct {
+		desc    string
+		secrets *secretref.Resolver
+		spec    func(spec *hubv1alpha1.OpenAPISpec)
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			desc:    "authenticated spec",
+			secrets: secrets,
+			spec:    func(*hubv1alpha1.OpenAPISpec) {},
+			wantErr: assert.NoError,
+		},
+		{
+			desc:    "missing credentials",
+			secrets: secrets,
+			spec: func(spec *hubv1alpha1.OpenAPISpec) {
+				spec.Auth = nil
+			},
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "untrusted certificate",
+			secrets: secrets,
+			spec: func(spec *hubv1alpha1.OpenAPISpec) {
+				spec.CA = nil
+			},
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "unknown secret entry",
+			secrets: secrets,
+			spec: func(spec *hubv1alpha1.OpenAPISpec) {
+				spec.Auth.BearerToken = &hubv1alpha1.SecretReference{Name: "spec-credentials", Key: "unknown"}
+			},
+			wantErr: assert.Error,
+		},
+		{
+			desc: "secret references not supported",
+			spec: func(*hubv1alpha1.OpenAPISpec) {},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorContains(t, err, "secret references are not supported")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			api := &hubv1alpha1.API{
+				ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "books"},
+				Spec: hubv1alpha1.APISpec{
+					Service: hubv1alpha1.APIService{
+						Name:        "books-svc",
+						Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+						OpenAPISpec: *validSpec.DeepCopy(),
+					},
+				},
+			}
+			test.spec(&api.Spec.Service.OpenAPISpec)
+
+			spec, err := NewFetcher(nil, test.secrets).Fetch(context.Background(), api)
+			if !test.wantErr(t, err) || err != nil {
+				return
+			}
+
+			assert.Equal(t, "Books", spec.Info.Title)
+		})
+	}
+}
+
+func TestSpecURL(t *testing.T) {
+	tests := []struct {
+		desc    string
+		api     hubv1alpha1.API
+		want    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			desc: "spec URL",
+			api: hubv1alpha1.API{Spec: hubv1alpha1.APISpec{Service: hubv1alpha1.APIService{
+				OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: "https://specs.example.com/books.json"},
+			}}},
+			want:    "https://specs.example.com/books.json",
+			wantErr: assert.NoError,
+		},
+		{
+			desc: "service path and port",
+			api: hubv1alpha1.API{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "books"},
+				Spec: hubv1alpha1.APISpec{Service: hubv1alpha1.APIService{
+					Name: "books-svc",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+					OpenAPISpec: hubv1alpha1.OpenAPISpec{
+						Path:     "/spec.json",
+						Port:     &hubv1alpha1.APIServiceBackendPort{Number: 9000},
+						Protocol: "https",
+					},
+				}},
+			},
+			want:    "https://books-svc.books:9000/spec.json",
+			wantErr: assert.NoError,
+		},
+		{
+			desc:    "no spec endpoint",
+			api:     hubv1alpha1.API{Spec: hubv1alpha1.APISpec{Service: hubv1alpha1.APIService{Name: "books-svc"}}},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := SpecURL(&test.api)
+			test.wantErr(t, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.want, got.String())
+		})
+	}
+}
+
+func newSecretLister(t *testing.T, secrets ...*corev1.Secret) corev1lister.SecretLister {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, secret := range secrets {
+		require.NoError(t, indexer.Add(secret))
+	}
+
+	return corev1lister.NewSecretLister(indexer)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ErrInvalidSpec is returned when an OpenAPI spec can't be loaded or doesn't comply with the OpenAPI specification.
+var ErrInvalidSpec = errors.New("invalid OpenAPI spec")
+
+// Validate validates the given spec against the version of the OpenAPI specification it declares, which must be 3.0
+// or 3.1.
+func Validate(ctx context.Context, spec *openapi3.T) error {
+	var opts []openapi3.ValidationOption
+	switch {
+	case strings.HasPrefix(spec.OpenAPI, "3.0."):
+	case strings.HasPrefix(spec.OpenAPI, "3.1."):
+		// 3.1 schemas are JSON Schema 2020-12 ones, whose examples and defaults can't be checked by the 3.0 schema
+		// validator. Paths are optional as well, specs may only describe webhooks or components.
+		opts = append(opts, openapi3.DisableExamplesValidation(), openapi3.DisableSchemaDefaultsValidation())
+		if spec.Paths == nil {
+			spec.Paths = openapi3.Paths{}
+		}
+	default:
+		return fmt.Errorf("%w: unsupported OpenAPI version %q", ErrInvalidSpec, spec.OpenAPI)
+	}
+
+	if err := spec.Validate(ctx, opts...); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+
+	return nil
+}
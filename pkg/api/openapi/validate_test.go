@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package openapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		desc    string
+		spec    string
+		wantErr string
+	}{
+		{
+			desc: "valid 3.0 spec",
+			spec: `
+openapi: 3.0.3
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    get:
+      operationId: listBooks
+      responses:
+        "200":
+          description: The books.
+`,
+		},
+		{
+			desc: "valid 3.1 spec without paths",
+			spec: `
+openapi: 3.1.0
+info:
+  title: Books
+  version: 1.0.0
+components:
+  schemas:
+    Book:
+      type: object
+`,
+		},
+		{
+			desc: "unsupported version",
+			spec: `
+openapi: 2.0.0
+info:
+  title: Books
+  version: 1.0.0
+paths: {}
+`,
+			wantErr: `invalid OpenAPI spec: unsupported OpenAPI version "2.0.0"`,
+		},
+		{
+			desc: "missing info version",
+			spec: `
+openapi: 3.0.3
+info:
+  title: Books
+paths: {}
+`,
+			wantErr: "invalid OpenAPI spec: invalid info: value of version must be a non-empty string",
+		},
+		{
+			desc: "missing paths in a 3.0 spec",
+			spec: `
+openapi: 3.0.3
+info:
+  title: Books
+  version: 1.0.0
+`,
+			wantErr: "invalid OpenAPI spec: invalid paths: must be an object",
+		},
+		{
+			desc: "duplicated operation IDs",
+			spec: `
+openapi: 3.0.3
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    get:
+      operationId: getBooks
+      responses:
+        "200":
+          description: The books.
+  /authors:
+    get:
+      operationId: getBooks
+      responses:
+        "200":
+          description: The authors.
+`,
+			wantErr: "invalid OpenAPI spec: invalid paths: operations \"GET /authors\" and \"GET /books\" have the same operation id \"getBooks\"",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			spec, err := openapi3.NewLoader().LoadFromData([]byte(test.spec))
+			require.NoError(t, err)
+
+			err = Validate(context.Background(), spec)
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrInvalidSpec)
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
@@ -40,6 +40,8 @@ type Portal struct {
 	HubDomain     string         `json:"hubDomain,omitempty"`
 	CustomDomains []CustomDomain `json:"customDomains,omitempty"`
 
+	CORS *hubv1alpha1.CORS `json:"cors,omitempty"`
+
 	HubACPConfig OIDCConfig `json:"hubAcpConfig"`
 
 	CreatedAt time.Time `json:"createdAt"`
@@ -71,6 +73,7 @@ func (p *Portal) Resource() (*hubv1alpha1.APIPortal, error) {
 		Description:   p.Description,
 		APIGateway:    p.Gateway,
 		CustomDomains: customDomains,
+		CORS:          p.CORS,
 	}
 
 	var urls []string
@@ -87,6 +90,8 @@ func (p *Portal) Resource() (*hubv1alpha1.APIPortal, error) {
 		urls = append(urls, "https://"+p.HubDomain)
 	}
 
+	syncedAt := metav1.Now()
+
 	portal := &hubv1alpha1.APIPortal{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "hub.traefik.io/v1alpha1",
@@ -96,10 +101,11 @@ func (p *Portal) Resource() (*hubv1alpha1.APIPortal, error) {
 		Spec:       spec,
 		Status: hubv1alpha1.APIPortalStatus{
 			Version:       p.Version,
-			SyncedAt:      metav1.Now(),
+			SyncedAt:      syncedAt,
 			HubDomain:     p.HubDomain,
 			CustomDomains: verifiedCustomDomains,
 			URLs:          strings.Join(urls, ","),
+			Conditions:    []metav1.Condition{syncedCondition(syncedAt)},
 		},
 	}
 
@@ -126,11 +132,12 @@ func (p *Portal) UnverifiedCustomDomains() []string {
 }
 
 type portalHash struct {
-	Title         string   `json:"title,omitempty"`
-	Description   string   `json:"description,omitempty"`
-	Gateway       string   `json:"gateway"`
-	HubDomain     string   `json:"hubDomain,omitempty"`
-	CustomDomains []string `json:"customDomains,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	Description   string            `json:"description,omitempty"`
+	Gateway       string            `json:"gateway"`
+	HubDomain     string            `json:"hubDomain,omitempty"`
+	CustomDomains []string          `json:"customDomains,omitempty"`
+	CORS          *hubv1alpha1.CORS `json:"cors,omitempty"`
 }
 
 // HashPortal generates the hash of the APIPortal.
@@ -141,6 +148,7 @@ func HashPortal(p *hubv1alpha1.APIPortal) (string, error) {
 		Gateway:       p.Spec.APIGateway,
 		HubDomain:     p.Status.HubDomain,
 		CustomDomains: p.Spec.CustomDomains,
+		CORS:          p.Spec.CORS,
 	}
 
 	h, err := sum(ph)
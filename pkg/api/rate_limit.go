@@ -0,0 +1,126 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RateLimit defines how many requests consumers can make on APIs.
+type RateLimit struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+
+	Groups      []string              `json:"groups,omitempty"`
+	Everyone    bool                  `json:"everyone,omitempty"`
+	APISelector *metav1.LabelSelector `json:"apiSelector,omitempty"`
+	Limit       int64                 `json:"limit"`
+	Period      *metav1.Duration      `json:"period,omitempty"`
+	Strategy    string                `json:"strategy,omitempty"`
+
+	Version string `json:"version"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RateLimitStatus is the status of an APIRateLimit reported to the platform.
+type RateLimitStatus struct {
+	// APIs are the APIs selected by the APIRateLimit, formatted as name@namespace.
+	APIs []string `json:"apis"`
+}
+
+// Resource builds the v1alpha1 APIRateLimit resource.
+func (r *RateLimit) Resource() (*hubv1alpha1.APIRateLimit, error) {
+	syncedAt := metav1.Now()
+
+	rateLimit := &hubv1alpha1.APIRateLimit{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "APIRateLimit",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   r.Name,
+			Labels: r.Labels,
+		},
+		Spec: hubv1alpha1.APIRateLimitSpec{
+			Groups:      r.Groups,
+			Everyone:    r.Everyone,
+			APISelector: r.APISelector,
+			Limit:       r.Limit,
+			Period:      r.Period,
+			Strategy:    r.Strategy,
+		},
+		Status: hubv1alpha1.APIRateLimitStatus{
+			Version:  r.Version,
+			SyncedAt: syncedAt,
+			Conditions: []metav1.Condition{
+				syncedCondition(syncedAt),
+				readyCondition(syncedAt),
+			},
+		},
+	}
+
+	h, err := HashRateLimit(rateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("compute APIRateLimit hash: %w", err)
+	}
+
+	rateLimit.Status.Hash = h
+
+	return rateLimit, nil
+}
+
+type rateLimitHash struct {
+	Groups      []string          `json:"groups"`
+	Everyone    bool              `json:"everyone"`
+	APISelector string            `json:"apiSelector"`
+	Limit       int64             `json:"limit"`
+	Period      string            `json:"period"`
+	Strategy    string            `json:"strategy"`
+	Labels      sortedMap[string] `json:"labels"`
+}
+
+// HashRateLimit generates the hash of the APIRateLimit.
+func HashRateLimit(r *hubv1alpha1.APIRateLimit) (string, error) {
+	rh := rateLimitHash{
+		Groups:   r.Spec.Groups,
+		Everyone: r.Spec.Everyone,
+		Limit:    r.Spec.Limit,
+		Strategy: r.Spec.Strategy,
+		Labels:   newSortedMap(r.Labels),
+	}
+	if r.Spec.APISelector != nil {
+		rh.APISelector = r.Spec.APISelector.String()
+	}
+	if r.Spec.Period != nil {
+		rh.Period = r.Spec.Period.Duration.String()
+	}
+
+	hash, err := sum(rh)
+	if err != nil {
+		return "", fmt.Errorf("sum object: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(hash), nil
+}
@@ -0,0 +1,264 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hublistersv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	"golang.org/x/sync/singleflight"
+)
+
+// PathRequestValidation is the auth server path checking the requests sent to the APIs validating them against their
+// OpenAPI spec. It is called by the ForwardAuth middlewares set on the routes of these APIs, with the API given as
+// "name@namespace" in the "api" query parameter.
+const PathRequestValidation = "/_request-validation"
+
+// specRetryDelay is the delay before fetching again a spec which couldn't be fetched.
+const specRetryDelay = 30 * time.Second
+
+// RequestValidationHandler authorizes the requests forwarded by Traefik matching the OpenAPI spec of their API, and
+// rejects the other ones: unknown paths with a 404, unknown methods with a 405, unsupported content types with a 415
+// and invalid parameters with a 400 status code. Request bodies are not forwarded by Traefik, so they are not
+// validated.
+type RequestValidationHandler struct {
+	apis  hublistersv1alpha1.APILister
+	specs *openapi.Fetcher
+
+	fetches singleflight.Group
+
+	validatorsMu sync.RWMutex
+	// validators are the request validators of the APIs, by "name@namespace".
+	validators map[string]*requestValidator
+}
+
+// requestValidator validates the requests of an API against the OpenAPI spec of a given version of the API.
+type requestValidator struct {
+	// hash is the hash of the API the spec was fetched for.
+	hash   string
+	router routers.Router
+
+	// err is the error which prevented the spec to be fetched, and failedAt the time it happened.
+	err      error
+	failedAt time.Time
+}
+
+// NewRequestValidationHandler creates a new RequestValidationHandler, validating the requests against the OpenAPI
+// specs of the APIs from the given lister.
+func NewRequestValidationHandler(apis hublistersv1alpha1.APILister, specs *openapi.Fetcher) *RequestValidationHandler {
+	return &RequestValidationHandler{
+		apis:       apis,
+		specs:      specs,
+		validators: make(map[string]*requestValidator),
+	}
+}
+
+func (h *RequestValidationHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	apiKey := req.URL.Query().Get("api")
+	logger := log.With().Str("api", apiKey).Logger()
+
+	name, namespace, ok := strings.Cut(apiKey, "@")
+	if !ok {
+		logger.Error().Msg("Missing API namespace")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	a, err := h.apis.APIs(namespace).Get(name)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get API")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	router, err := h.router(apiKey, a)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to load OpenAPI spec")
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	forwardedReq, err := forwardedRequest(req)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Invalid forwarded request")
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if status, msg := validateRequest(forwardedReq, router); status != http.StatusOK {
+		logger.Debug().
+			Str("method", forwardedReq.Method).
+			Str("uri", forwardedReq.URL.RequestURI()).
+			Str("reason", msg).
+			Msg("Request rejected by OpenAPI spec")
+		http.Error(rw, msg, status)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// router returns the router of the OpenAPI spec of the given API, fetching the spec when the API changed since it was
+// last fetched.
+func (h *RequestValidationHandler) router(apiKey string, a *hubv1alpha1.API) (routers.Router, error) {
+	h.validatorsMu.RLock()
+	validator, ok := h.validators[apiKey]
+	h.validatorsMu.RUnlock()
+
+	if ok && validator.hash == a.Status.Hash {
+		if validator.err == nil {
+			return validator.router, nil
+		}
+		if time.Since(validator.failedAt) < specRetryDelay {
+			return nil, validator.err
+		}
+	}
+
+	// Concurrent requests wait for a single fetch of the spec. It isn't bound to the context of the first request,
+	// whose cancellation would fail all the others.
+	v, _, _ := h.fetches.Do(apiKey, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		validator = &requestValidator{hash: a.Status.Hash}
+		validator.router, validator.err = h.loadRouter(fetchCtx, a)
+		if validator.err != nil {
+			validator.failedAt = time.Now()
+		}
+
+		h.validatorsMu.Lock()
+		h.validators[apiKey] = validator
+		h.validatorsMu.Unlock()
+
+		return validator, nil
+	})
+
+	validator = v.(*requestValidator)
+
+	return validator.router, validator.err
+}
+
+func (h *RequestValidationHandler) loadRouter(ctx context.Context, a *hubv1alpha1.API) (routers.Router, error) {
+	spec, err := h.specs.Fetch(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("fetch spec: %w", err)
+	}
+
+	if err = openapi.Validate(ctx, spec); err != nil {
+		return nil, err
+	}
+
+	// Spec paths are relative to the path prefix of the API, which is stripped before the requests are validated. The
+	// servers of the spec are the ones of the API services, they are not the ones the requests are sent to.
+	spec.Servers = nil
+
+	// The spec was already validated according to its version.
+	router, err := legacy.NewRouter(spec, openapi3.DisableExamplesValidation(), openapi3.DisableSchemaDefaultsValidation())
+	if err != nil {
+		return nil, fmt.Errorf("build router: %w", err)
+	}
+
+	return router, nil
+}
+
+// forwardedRequest rebuilds the request forwarded by Traefik from the X-Forwarded headers and the headers of the
+// original request.
+func forwardedRequest(req *http.Request) (*http.Request, error) {
+	uri, err := url.ParseRequestURI(req.Header.Get("X-Forwarded-Uri"))
+	if err != nil {
+		return nil, fmt.Errorf("parse forwarded URI: %w", err)
+	}
+
+	forwardedReq := req.Clone(req.Context())
+	forwardedReq.Method = req.Header.Get("X-Forwarded-Method")
+	forwardedReq.URL = uri
+	forwardedReq.RequestURI = uri.RequestURI()
+	forwardedReq.Body = http.NoBody
+
+	return forwardedReq, nil
+}
+
+// validateRequest validates the given request against the spec of the given router. It returns the status code
+// rejecting the request and the reason, or http.StatusOK when the request is valid.
+func validateRequest(req *http.Request, router routers.Router) (int, string) {
+	route, pathParams, err := router.FindRoute(req)
+	if err != nil {
+		var routeErr *routers.RouteError
+		if errors.As(err, &routeErr) && routeErr.Reason == routers.ErrMethodNotAllowed.Error() {
+			return http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed)
+		}
+
+		return http.StatusNotFound, http.StatusText(http.StatusNotFound)
+	}
+
+	if !isContentTypeAllowed(req, route.Operation.RequestBody) {
+		return http.StatusUnsupportedMediaType, http.StatusText(http.StatusUnsupportedMediaType)
+	}
+
+	err = openapi3filter.ValidateRequest(req.Context(), &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			ExcludeRequestBody: true,
+			// Requests are authenticated by the access control policies of the API.
+			AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+			SkipSettingDefaults: true,
+		},
+	})
+	if err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+
+	return http.StatusOK, ""
+}
+
+// isContentTypeAllowed returns whether the content type of the given request is one of the ones the given request
+// body accepts. Requests without content type are allowed unless their body is required.
+func isContentTypeAllowed(req *http.Request, body *openapi3.RequestBodyRef) bool {
+	contentType := req.Header.Get("Content-Type")
+	if body == nil || body.Value == nil || len(body.Value.Content) == 0 {
+		return true
+	}
+
+	if contentType == "" {
+		return !body.Value.Required
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return body.Value.Content.Get(mediaType) != nil
+}
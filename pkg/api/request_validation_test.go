@@ -0,0 +1,233 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ordersSpec = `
+openapi: 3.0.3
+info:
+  title: Orders
+  version: 1.0.0
+servers:
+  - url: https://orders.example.com/v1
+paths:
+  /orders:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: Orders.
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+      responses:
+        "201":
+          description: Created order.
+  /orders/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        "200":
+          description: Order.
+`
+
+func TestRequestValidationHandler_ServeHTTP(t *testing.T) {
+	specSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/spec.yaml" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = rw.Write([]byte(ordersSpec))
+	}))
+	t.Cleanup(specSrv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubClientSet := hubfake.NewSimpleClientset(
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "ns"},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/orders-api",
+				Service: hubv1alpha1.APIService{
+					Name:        "orders-svc",
+					Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+					OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: specSrv.URL + "/spec.yaml"},
+				},
+			},
+		},
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-spec", Namespace: "ns"},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/no-spec",
+				Service: hubv1alpha1.APIService{
+					Name:        "no-spec-svc",
+					Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+					OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: specSrv.URL + "/missing.yaml"},
+				},
+			},
+		},
+	)
+	hubInformer := hubinformers.NewSharedInformerFactory(hubClientSet, 0)
+	apiLister := hubInformer.Hub().V1alpha1().APIs().Lister()
+
+	hubInformer.Start(ctx.Done())
+	for typ, synced := range hubInformer.WaitForCacheSync(ctx.Done()) {
+		require.True(t, synced, typ)
+	}
+
+	handler := NewRequestValidationHandler(apiLister, openapi.NewFetcher(nil, nil))
+
+	tests := []struct {
+		desc        string
+		api         string
+		method      string
+		uri         string
+		contentType string
+		wantCode    int
+	}{
+		{
+			desc:     "valid request",
+			api:      "orders@ns",
+			method:   http.MethodGet,
+			uri:      "/orders?limit=10",
+			wantCode: http.StatusOK,
+		},
+		{
+			desc:     "valid request with path parameter",
+			api:      "orders@ns",
+			method:   http.MethodGet,
+			uri:      "/orders/42",
+			wantCode: http.StatusOK,
+		},
+		{
+			desc:        "valid request with body",
+			api:         "orders@ns",
+			method:      http.MethodPost,
+			uri:         "/orders",
+			contentType: "application/json; charset=utf-8",
+			wantCode:    http.StatusOK,
+		},
+		{
+			desc:     "unknown path",
+			api:      "orders@ns",
+			method:   http.MethodGet,
+			uri:      "/customers",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			desc:     "unknown method",
+			api:      "orders@ns",
+			method:   http.MethodDelete,
+			uri:      "/orders",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+		{
+			desc:        "unsupported content type",
+			api:         "orders@ns",
+			method:      http.MethodPost,
+			uri:         "/orders",
+			contentType: "text/plain",
+			wantCode:    http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:     "missing content type of a required body",
+			api:      "orders@ns",
+			method:   http.MethodPost,
+			uri:      "/orders",
+			wantCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:     "missing required query parameter",
+			api:      "orders@ns",
+			method:   http.MethodGet,
+			uri:      "/orders",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc:     "invalid path parameter",
+			api:      "orders@ns",
+			method:   http.MethodGet,
+			uri:      "/orders/abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			desc:     "unavailable spec",
+			api:      "no-spec@ns",
+			method:   http.MethodGet,
+			uri:      "/orders",
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			desc:     "unknown API",
+			api:      "unknown@ns",
+			method:   http.MethodGet,
+			uri:      "/orders",
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://auth-server"+PathRequestValidation+"?api="+test.api, nil)
+			req.Header.Set("X-Forwarded-Method", test.method)
+			req.Header.Set("X-Forwarded-Uri", test.uri)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
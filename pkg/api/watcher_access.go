@@ -139,7 +139,6 @@ func (w *WatcherAccess) syncAccesses(ctx context.Context) {
 func (w *WatcherAccess) createAccess(ctx context.Context, access *hubv1alpha1.APIAccess) error {
 	createdAccess, err := w.hubClientSet.HubV1alpha1().APIAccesses().Create(ctx, access, metav1.CreateOptions{})
 	if err != nil {
-		w.eventRecorder.Eventf(access, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
 		return fmt.Errorf("creating APIAccess: %w", err)
 	}
 
@@ -147,7 +146,7 @@ func (w *WatcherAccess) createAccess(ctx context.Context, access *hubv1alpha1.AP
 		Str("name", createdAccess.Name).
 		Msg("APIAccess created")
 
-	w.eventRecorder.Event(createdAccess, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+	w.eventRecorder.Event(createdAccess, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
 
 	return nil
 }
@@ -156,11 +155,12 @@ func (w *WatcherAccess) updateAccess(ctx context.Context, oldAccess, newAccess *
 	meta := oldAccess.ObjectMeta
 	meta.Labels = newAccess.Labels
 	newAccess.ObjectMeta = meta
+	newAccess.Status.Conditions = hubv1alpha1.MergeConditions(oldAccess.Status.Conditions, newAccess.Status.Conditions...)
 
-	if newAccess.Status.Version != oldAccess.Status.Version {
+	if newAccess.Status.Version != oldAccess.Status.Version || !isSynced(oldAccess.Status.Conditions) {
 		updatedAccess, err := w.hubClientSet.HubV1alpha1().APIAccesses().Update(ctx, newAccess, metav1.UpdateOptions{})
 		if err != nil {
-			w.eventRecorder.Eventf(newAccess, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
+			w.eventRecorder.Eventf(newAccess, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
 			return fmt.Errorf("updating APIAccess: %w", err)
 		}
 
@@ -168,7 +168,7 @@ func (w *WatcherAccess) updateAccess(ctx context.Context, oldAccess, newAccess *
 			Str("name", updatedAccess.Name).
 			Msg("APIAccess updated")
 
-		w.eventRecorder.Event(updatedAccess, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+		w.eventRecorder.Event(updatedAccess, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
 	}
 
 	return nil
@@ -20,16 +20,21 @@ package api
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/scheme"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/reconcile"
 	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	kclientset "k8s.io/client-go/kubernetes"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -40,6 +45,7 @@ type WatcherAPI struct {
 	apiSyncInterval time.Duration
 
 	platform PlatformClient
+	specs    *openapi.Fetcher
 
 	kubeClientSet kclientset.Interface
 
@@ -47,17 +53,25 @@ type WatcherAPI struct {
 	hubInformer  hubinformers.SharedInformerFactory
 
 	eventRecorder record.EventRecorder
+
+	// platformAPIs are the APIs last fetched from the platform, by key. They are nil until fetched.
+	platformAPIsMu sync.RWMutex
+	platformAPIs   map[string]*API
+
+	queue *reconcile.Queue
 }
 
-// NewWatcherAPI returns a new WatcherAPI.
-func NewWatcherAPI(client PlatformClient, kubeClientSet kclientset.Interface, hubClientSet hubclientset.Interface, hubInformer hubinformers.SharedInformerFactory, apiSyncInterval time.Duration) *WatcherAPI {
+// NewWatcherAPI returns a new WatcherAPI. The OpenAPI specs referenced by the APIs are fetched with the given fetcher
+// to be validated, unless it is nil.
+func NewWatcherAPI(client PlatformClient, specs *openapi.Fetcher, kubeClientSet kclientset.Interface, hubClientSet hubclientset.Interface, hubInformer hubinformers.SharedInformerFactory, apiSyncInterval time.Duration) *WatcherAPI {
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartRecordingToSink(&v1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
 	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{})
 
-	return &WatcherAPI{
+	w := &WatcherAPI{
 		apiSyncInterval: apiSyncInterval,
 		platform:        client,
+		specs:           specs,
 
 		kubeClientSet: kubeClientSet,
 
@@ -66,6 +80,9 @@ func NewWatcherAPI(client PlatformClient, kubeClientSet kclientset.Interface, hu
 
 		eventRecorder: eventRecorder,
 	}
+	w.queue = reconcile.NewQueue("API", w.reconcileAPI)
+
+	return w
 }
 
 // Run runs WatcherAPI.
@@ -73,6 +90,14 @@ func (w *WatcherAPI) Run(ctx context.Context) {
 	t := time.NewTicker(w.apiSyncInterval)
 	defer t.Stop()
 
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.queue.Run(ctx)
+	}()
+	defer wg.Wait()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -83,6 +108,8 @@ func (w *WatcherAPI) Run(ctx context.Context) {
 			ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
 			w.syncAPIs(ctxSync)
 			cancel()
+
+			w.validateSpecs(ctx)
 		}
 	}
 }
@@ -100,49 +127,61 @@ func (w *WatcherAPI) syncAPIs(ctx context.Context) {
 		return
 	}
 
-	clusterAPIsByNameNamespace := map[string]*hubv1alpha1.API{}
-	for _, api := range clusterAPIs {
-		clusterAPIsByNameNamespace[api.Name+"@"+api.Namespace] = api
-	}
-
+	apis := make(map[string]*API, len(platformAPIs))
 	for _, api := range platformAPIs {
 		platformAPI := api
+		apis[reconcile.Key(platformAPI.Namespace, platformAPI.Name)] = &platformAPI
+	}
 
-		logger := log.With().
-			Str("name", platformAPI.Name).
-			Str("namespace", platformAPI.Namespace).
-			Logger()
+	w.platformAPIsMu.Lock()
+	w.platformAPIs = apis
+	w.platformAPIsMu.Unlock()
 
-		oldClusterAPI, found := clusterAPIsByNameNamespace[platformAPI.Name+"@"+platformAPI.Namespace]
+	// Each API is reconciled on its own, cluster APIs missing on the platform are deleted.
+	for key := range apis {
+		w.queue.Add(key)
+	}
+	for _, api := range clusterAPIs {
+		if key := reconcile.Key(api.Namespace, api.Name); apis[key] == nil {
+			w.queue.Add(key)
+		}
+	}
+}
 
-		// APIs that will remain in the map will be deleted.
-		delete(clusterAPIsByNameNamespace, platformAPI.Name+"@"+platformAPI.Namespace)
+// reconcileAPI creates, updates or deletes the cluster API identified by the given key to match the platform.
+func (w *WatcherAPI) reconcileAPI(ctx context.Context, key string) error {
+	w.platformAPIsMu.RLock()
+	platformAPI, found := w.platformAPIs[key]
+	w.platformAPIsMu.RUnlock()
 
-		newClusterAPI, resourceErr := platformAPI.Resource()
-		if resourceErr != nil {
-			logger.Error().Err(resourceErr).Msg("Unable to build API resource")
-			continue
-		}
+	obj, clusterFound, err := w.hubInformer.Hub().V1alpha1().APIs().Informer().GetIndexer().GetByKey(key)
+	if err != nil {
+		return fmt.Errorf("get API: %w", err)
+	}
 
-		if !found {
-			if err = w.createAPI(ctx, newClusterAPI); err != nil {
-				logger.Error().Err(err).Msg("Unable to create API")
-			}
-			continue
+	if !found {
+		if !clusterFound {
+			return nil
 		}
 
-		if err = w.updateAPI(ctx, oldClusterAPI, newClusterAPI); err != nil {
-			logger.Error().Err(err).Msg("Unable to update API")
-		}
+		return w.deleteAPI(ctx, obj.(*hubv1alpha1.API))
+	}
+
+	newClusterAPI, err := platformAPI.Resource()
+	if err != nil {
+		return fmt.Errorf("build API resource: %w", err)
 	}
 
-	w.cleanAPIs(ctx, clusterAPIsByNameNamespace)
+	if !clusterFound {
+		return w.createAPI(ctx, newClusterAPI)
+	}
+
+	return w.updateAPI(ctx, obj.(*hubv1alpha1.API), newClusterAPI)
 }
 
 func (w *WatcherAPI) createAPI(ctx context.Context, api *hubv1alpha1.API) error {
 	createdAPI, err := w.hubClientSet.HubV1alpha1().APIs(api.Namespace).Create(ctx, api, metav1.CreateOptions{})
 	if err != nil {
-		w.eventRecorder.Eventf(api, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
 		return fmt.Errorf("creating API: %w", err)
 	}
 
@@ -151,7 +190,7 @@ func (w *WatcherAPI) createAPI(ctx context.Context, api *hubv1alpha1.API) error
 		Str("namespace", createdAPI.Namespace).
 		Msg("API created")
 
-	w.eventRecorder.Event(createdAPI, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+	w.eventRecorder.Event(createdAPI, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
 
 	return nil
 }
@@ -160,11 +199,12 @@ func (w *WatcherAPI) updateAPI(ctx context.Context, oldAPI, newAPI *hubv1alpha1.
 	meta := oldAPI.ObjectMeta
 	meta.Labels = newAPI.Labels
 	newAPI.ObjectMeta = meta
+	newAPI.Status.Conditions = hubv1alpha1.MergeConditions(oldAPI.Status.Conditions, newAPI.Status.Conditions...)
 
-	if newAPI.Status.Version != oldAPI.Status.Version {
+	if newAPI.Status.Version != oldAPI.Status.Version || !isSynced(oldAPI.Status.Conditions) {
 		updatedAPI, err := w.hubClientSet.HubV1alpha1().APIs(newAPI.Namespace).Update(ctx, newAPI, metav1.UpdateOptions{})
 		if err != nil {
-			w.eventRecorder.Eventf(newAPI, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
+			w.eventRecorder.Eventf(newAPI, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
 			return fmt.Errorf("updating API: %w", err)
 		}
 
@@ -173,30 +213,91 @@ func (w *WatcherAPI) updateAPI(ctx context.Context, oldAPI, newAPI *hubv1alpha1.
 			Str("namespace", updatedAPI.Namespace).
 			Msg("API updated")
 
-		w.eventRecorder.Event(updatedAPI, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+		w.eventRecorder.Event(updatedAPI, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
 	}
 
 	return nil
 }
 
-func (w *WatcherAPI) cleanAPIs(ctx context.Context, apis map[string]*hubv1alpha1.API) {
+// validateSpecs fetches and validates the OpenAPI specs referenced by the APIs, reporting the result in their
+// OpenAPISpecValid condition.
+func (w *WatcherAPI) validateSpecs(ctx context.Context) {
+	if w.specs == nil {
+		return
+	}
+
+	apis, err := w.hubInformer.Hub().V1alpha1().APIs().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to obtain APIs")
+		return
+	}
+
 	for _, api := range apis {
-		// Foreground propagation allow us to delete all resources owned by the API.
-		policy := metav1.DeletePropagationForeground
+		openAPISpec := api.Spec.Service.OpenAPISpec
+		if openAPISpec.URL == "" && openAPISpec.Path == "" {
+			continue
+		}
 
-		opts := metav1.DeleteOptions{
-			PropagationPolicy: &policy,
+		ctxFetch, cancel := context.WithTimeout(ctx, 10*time.Second)
+		spec, err := w.specs.Fetch(ctxFetch, api)
+		if err == nil {
+			err = openapi.Validate(ctxFetch, spec)
 		}
-		err := w.hubClientSet.HubV1alpha1().APIs(api.Namespace).Delete(ctx, api.Name, opts)
-		if err != nil {
-			log.Error().Err(err).Msg("Unable to delete API")
+		cancel()
 
-			continue
+		if err != nil {
+			log.Debug().Err(err).
+				Str("name", api.Name).
+				Str("namespace", api.Namespace).
+				Msg("Invalid OpenAPI spec")
 		}
 
-		log.Debug().
+		w.setAPIConditions(ctx, api, openAPISpecValidCondition(err))
+	}
+}
+
+// setAPIConditions sets the given conditions on the API status.
+// Only the conditions are patched, leaving the rest of the status untouched. Failing to do so is only logged, as
+// conditions are informative.
+func (w *WatcherAPI) setAPIConditions(ctx context.Context, api *hubv1alpha1.API, conditions ...metav1.Condition) {
+	api = api.DeepCopy()
+	if !hubv1alpha1.SetConditions(&api.Status.Conditions, conditions...) {
+		return
+	}
+
+	patch, err := conditionsPatch(api.Status.Conditions)
+	if err != nil {
+		log.Error().Err(err).
+			Str("name", api.Name).
+			Str("namespace", api.Namespace).
+			Msg("Unable to build API conditions patch")
+		return
+	}
+
+	if _, err = w.hubClientSet.HubV1alpha1().APIs(api.Namespace).Patch(ctx, api.Name, ktypes.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		log.Error().Err(err).
 			Str("name", api.Name).
 			Str("namespace", api.Namespace).
-			Msg("API deleted")
+			Msg("Unable to patch API conditions")
 	}
 }
+
+func (w *WatcherAPI) deleteAPI(ctx context.Context, api *hubv1alpha1.API) error {
+	// Foreground propagation allow us to delete all resources owned by the API.
+	policy := metav1.DeletePropagationForeground
+
+	opts := metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+	}
+	err := w.hubClientSet.HubV1alpha1().APIs(api.Namespace).Delete(ctx, api.Name, opts)
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("deleting API: %w", err)
+	}
+
+	log.Debug().
+		Str("name", api.Name).
+		Str("namespace", api.Namespace).
+		Msg("API deleted")
+
+	return nil
+}
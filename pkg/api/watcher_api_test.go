@@ -19,15 +19,19 @@ package api
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/openapi"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubefake "k8s.io/client-go/kubernetes/fake"
@@ -96,10 +100,15 @@ func Test_WatcherAPIRun(t *testing.T) {
 			}
 		})
 
-	w := NewWatcherAPI(client, kubeClientSet, clientSetHub, hubInformer, time.Millisecond)
-	go w.Run(ctx)
+	w := NewWatcherAPI(client, nil, kubeClientSet, clientSetHub, hubInformer, time.Millisecond)
 
-	<-ctx.Done()
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
 
 	api, err := clientSetHub.HubV1alpha1().APIs("").Get(ctx, "apiToCreate", metav1.GetOptions{})
 	require.NoError(t, err)
@@ -126,3 +135,80 @@ func Test_WatcherAPIRun(t *testing.T) {
 	_, err = clientSetHub.HubV1alpha1().APIs("").Get(ctx, "apiToDelete", metav1.GetOptions{})
 	require.Error(t, err)
 }
+
+func Test_WatcherAPIValidateSpecs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/valid.yaml":
+			_, _ = rw.Write([]byte("openapi: 3.0.3\ninfo:\n  title: Books\n  version: 1.0.0\npaths: {}\n"))
+		case "/invalid.yaml":
+			_, _ = rw.Write([]byte("openapi: 3.0.3\ninfo:\n  title: Books\npaths: {}\n"))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	newAPI := func(name, specPath string) *hubv1alpha1.API {
+		a := &hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/" + name,
+				Service: hubv1alpha1.APIService{
+					Name: "books-svc",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+				},
+			},
+		}
+		if specPath != "" {
+			a.Spec.Service.OpenAPISpec.URL = srv.URL + specPath
+		}
+
+		return a
+	}
+
+	clientSetHub := hubfake.NewSimpleClientset(
+		newAPI("valid", "/valid.yaml"),
+		newAPI("invalid", "/invalid.yaml"),
+		newAPI("unavailable", "/unavailable.yaml"),
+		newAPI("no-spec", ""),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+	apiInformer := hubInformer.Hub().V1alpha1().APIs().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), apiInformer.HasSynced)
+
+	w := NewWatcherAPI(newPlatformClientMock(t), openapi.NewFetcher(nil, nil), kubefake.NewSimpleClientset(), clientSetHub, hubInformer, time.Minute)
+	w.validateSpecs(ctx)
+
+	tests := []struct {
+		name       string
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{name: "valid", wantStatus: metav1.ConditionTrue, wantReason: hubv1alpha1.ReasonOpenAPISpecValid},
+		{name: "invalid", wantStatus: metav1.ConditionFalse, wantReason: hubv1alpha1.ReasonOpenAPISpecInvalid},
+		{name: "unavailable", wantStatus: metav1.ConditionFalse, wantReason: hubv1alpha1.ReasonOpenAPISpecUnavailable},
+		{name: "no-spec"},
+	}
+
+	for _, test := range tests {
+		a, err := clientSetHub.HubV1alpha1().APIs("default").Get(ctx, test.name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		condition := apimeta.FindStatusCondition(a.Status.Conditions, hubv1alpha1.ConditionOpenAPISpecValid)
+		if test.wantReason == "" {
+			assert.Nil(t, condition, test.name)
+			continue
+		}
+
+		require.NotNil(t, condition, test.name)
+		assert.Equal(t, test.wantStatus, condition.Status, test.name)
+		assert.Equal(t, test.wantReason, condition.Reason, test.name)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/scheme"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kclientset "k8s.io/client-go/kubernetes"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// APIVersionPlatformClient allows fetching API versions from the platform.
+type APIVersionPlatformClient interface {
+	GetAPIVersions(ctx context.Context) ([]APIVersion, error)
+}
+
+// WatcherAPIVersion watches hub API versions and sync them with the cluster.
+type WatcherAPIVersion struct {
+	versionSyncInterval time.Duration
+
+	platform APIVersionPlatformClient
+
+	kubeClientSet kclientset.Interface
+
+	hubClientSet hubclientset.Interface
+	hubInformer  hubinformers.SharedInformerFactory
+
+	eventRecorder record.EventRecorder
+}
+
+// NewWatcherAPIVersion returns a new WatcherAPIVersion.
+func NewWatcherAPIVersion(client APIVersionPlatformClient, kubeClientSet kclientset.Interface, hubClientSet hubclientset.Interface, hubInformer hubinformers.SharedInformerFactory, versionSyncInterval time.Duration) *WatcherAPIVersion {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
+	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{})
+
+	return &WatcherAPIVersion{
+		versionSyncInterval: versionSyncInterval,
+		platform:            client,
+
+		kubeClientSet: kubeClientSet,
+
+		hubClientSet: hubClientSet,
+		hubInformer:  hubInformer,
+
+		eventRecorder: eventRecorder,
+	}
+}
+
+// Run runs WatcherAPIVersion.
+func (w *WatcherAPIVersion) Run(ctx context.Context) {
+	t := time.NewTicker(w.versionSyncInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping API version watcher")
+			return
+
+		case <-t.C:
+			ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
+			w.syncAPIVersions(ctxSync)
+			cancel()
+		}
+	}
+}
+
+func (w *WatcherAPIVersion) syncAPIVersions(ctx context.Context) {
+	platformVersions, err := w.platform.GetAPIVersions(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to fetch APIVersions")
+		return
+	}
+
+	clusterVersions, err := w.hubInformer.Hub().V1alpha1().APIVersions().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to obtain APIVersions")
+		return
+	}
+
+	clusterVersionsByNameNamespace := map[string]*hubv1alpha1.APIVersion{}
+	for _, version := range clusterVersions {
+		clusterVersionsByNameNamespace[version.Name+"@"+version.Namespace] = version
+	}
+
+	for _, version := range platformVersions {
+		platformVersion := version
+
+		logger := log.With().
+			Str("name", platformVersion.Name).
+			Str("namespace", platformVersion.Namespace).
+			Logger()
+
+		oldClusterVersion, found := clusterVersionsByNameNamespace[platformVersion.Name+"@"+platformVersion.Namespace]
+
+		// APIVersions that will remain in the map will be deleted.
+		delete(clusterVersionsByNameNamespace, platformVersion.Name+"@"+platformVersion.Namespace)
+
+		newClusterVersion, resourceErr := platformVersion.Resource()
+		if resourceErr != nil {
+			logger.Error().Err(resourceErr).Msg("Unable to build APIVersion resource")
+			continue
+		}
+
+		if !found {
+			if err = w.createAPIVersion(ctx, newClusterVersion); err != nil {
+				logger.Error().Err(err).Msg("Unable to create APIVersion")
+			}
+			continue
+		}
+
+		if err = w.updateAPIVersion(ctx, oldClusterVersion, newClusterVersion); err != nil {
+			logger.Error().Err(err).Msg("Unable to update APIVersion")
+		}
+	}
+
+	w.cleanAPIVersions(ctx, clusterVersionsByNameNamespace)
+}
+
+func (w *WatcherAPIVersion) createAPIVersion(ctx context.Context, version *hubv1alpha1.APIVersion) error {
+	createdVersion, err := w.hubClientSet.HubV1alpha1().APIVersions(version.Namespace).Create(ctx, version, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating APIVersion: %w", err)
+	}
+
+	log.Debug().
+		Str("name", createdVersion.Name).
+		Str("namespace", createdVersion.Namespace).
+		Msg("APIVersion created")
+
+	w.eventRecorder.Event(createdVersion, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
+
+	return nil
+}
+
+func (w *WatcherAPIVersion) updateAPIVersion(ctx context.Context, oldVersion, newVersion *hubv1alpha1.APIVersion) error {
+	meta := oldVersion.ObjectMeta
+	meta.Labels = newVersion.Labels
+	newVersion.ObjectMeta = meta
+	newVersion.Status.Conditions = hubv1alpha1.MergeConditions(oldVersion.Status.Conditions, newVersion.Status.Conditions...)
+
+	if newVersion.Status.Version != oldVersion.Status.Version || !isSynced(oldVersion.Status.Conditions) {
+		updatedVersion, err := w.hubClientSet.HubV1alpha1().APIVersions(newVersion.Namespace).Update(ctx, newVersion, metav1.UpdateOptions{})
+		if err != nil {
+			w.eventRecorder.Eventf(newVersion, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
+			return fmt.Errorf("updating APIVersion: %w", err)
+		}
+
+		log.Debug().
+			Str("name", updatedVersion.Name).
+			Str("namespace", updatedVersion.Namespace).
+			Msg("APIVersion updated")
+
+		w.eventRecorder.Event(updatedVersion, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
+	}
+
+	return nil
+}
+
+func (w *WatcherAPIVersion) cleanAPIVersions(ctx context.Context, versions map[string]*hubv1alpha1.APIVersion) {
+	for _, version := range versions {
+		// Foreground propagation allow us to delete all resources owned by the APIVersion.
+		policy := metav1.DeletePropagationForeground
+
+		opts := metav1.DeleteOptions{
+			PropagationPolicy: &policy,
+		}
+		err := w.hubClientSet.HubV1alpha1().APIVersions(version.Namespace).Delete(ctx, version.Name, opts)
+		if err != nil {
+			log.Error().Err(err).Msg("Unable to delete APIVersion")
+
+			continue
+		}
+
+		log.Debug().
+			Str("name", version.Name).
+			Str("namespace", version.Namespace).
+			Msg("APIVersion deleted")
+	}
+}
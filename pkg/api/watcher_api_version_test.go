@@ -0,0 +1,133 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func Test_WatcherAPIVersionRun(t *testing.T) {
+	versionToUpdate := &hubv1alpha1.APIVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "versionToUpdate",
+			Namespace: "default",
+		},
+		Spec: hubv1alpha1.APIVersionSpec{
+			APIName:    "api",
+			Release:    "v1",
+			PathPrefix: "/v1",
+		},
+	}
+	versionToDelete := &hubv1alpha1.APIVersion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "versionToDelete",
+			Namespace: "default",
+		},
+		Spec: hubv1alpha1.APIVersionSpec{
+			APIName:    "api",
+			Release:    "v0",
+			PathPrefix: "/v0",
+		},
+	}
+
+	kubeClientSet := kubefake.NewSimpleClientset()
+	clientSetHub := hubfake.NewSimpleClientset([]runtime.Object{versionToUpdate, versionToDelete}...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+	versionInformer := hubInformer.Hub().V1alpha1().APIVersions().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), versionInformer.HasSynced)
+
+	var callCount int
+
+	client := newAPIVersionPlatformClientMock(t)
+	client.OnGetAPIVersions().
+		TypedReturns([]APIVersion{
+			{
+				Name:       "versionToCreate",
+				Namespace:  "default",
+				Labels:     map[string]string{"foo": "bar"},
+				APIName:    "api",
+				Release:    "v3",
+				PathPrefix: "/v3",
+				Service: &VersionService{
+					Name: "service-v3",
+					Port: 80,
+				},
+				Version: "1",
+			},
+			{
+				Name:      "versionToUpdate",
+				Namespace: "default",
+				Labels:    map[string]string{"foo": "bar"},
+				APIName:   "api",
+				Release:   "v1",
+				VersionHeader: &VersionHeader{
+					Name:  "X-Version",
+					Value: "1",
+				},
+				Version: "2",
+			},
+		}, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+
+	w := NewWatcherAPIVersion(client, kubeClientSet, clientSetHub, hubInformer, time.Millisecond)
+	go w.Run(ctx)
+
+	<-ctx.Done()
+
+	version, err := clientSetHub.HubV1alpha1().APIVersions("default").Get(ctx, "versionToCreate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "api", version.Spec.APIName)
+	assert.Equal(t, "/v3", version.Spec.PathPrefix)
+	assert.Equal(t, &hubv1alpha1.APIVersionService{
+		Name: "service-v3",
+		Port: hubv1alpha1.APIServiceBackendPort{
+			Number: 80,
+		},
+	}, version.Spec.Service)
+	assert.Equal(t, map[string]string{"foo": "bar"}, version.Labels)
+
+	version, err = clientSetHub.HubV1alpha1().APIVersions("default").Get(ctx, "versionToUpdate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, version.Spec.PathPrefix)
+	assert.Equal(t, &hubv1alpha1.APIVersionHeader{Name: "X-Version", Value: "1"}, version.Spec.VersionHeader)
+	assert.Equal(t, map[string]string{"foo": "bar"}, version.Labels)
+
+	_, err = clientSetHub.HubV1alpha1().APIVersions("default").Get(ctx, "versionToDelete", metav1.GetOptions{})
+	require.Error(t, err)
+}
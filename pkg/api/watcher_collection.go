@@ -139,7 +139,6 @@ func (w *WatcherCollection) syncCollections(ctx context.Context) {
 func (w *WatcherCollection) createCollection(ctx context.Context, collection *hubv1alpha1.APICollection) error {
 	createdCollection, err := w.hubClientSet.HubV1alpha1().APICollections().Create(ctx, collection, metav1.CreateOptions{})
 	if err != nil {
-		w.eventRecorder.Eventf(collection, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
 		return fmt.Errorf("creating APICollection: %w", err)
 	}
 
@@ -147,7 +146,7 @@ func (w *WatcherCollection) createCollection(ctx context.Context, collection *hu
 		Str("name", createdCollection.Name).
 		Msg("APICollection created")
 
-	w.eventRecorder.Event(createdCollection, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+	w.eventRecorder.Event(createdCollection, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
 
 	return nil
 }
@@ -156,11 +155,12 @@ func (w *WatcherCollection) updateCollection(ctx context.Context, oldCollection,
 	meta := oldCollection.ObjectMeta
 	meta.Labels = newCollection.Labels
 	newCollection.ObjectMeta = meta
+	newCollection.Status.Conditions = hubv1alpha1.MergeConditions(oldCollection.Status.Conditions, newCollection.Status.Conditions...)
 
-	if newCollection.Status.Version != oldCollection.Status.Version {
+	if newCollection.Status.Version != oldCollection.Status.Version || !isSynced(oldCollection.Status.Conditions) {
 		updatedCollection, err := w.hubClientSet.HubV1alpha1().APICollections().Update(ctx, newCollection, metav1.UpdateOptions{})
 		if err != nil {
-			w.eventRecorder.Eventf(newCollection, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
+			w.eventRecorder.Eventf(newCollection, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
 			return fmt.Errorf("updating APICollection: %w", err)
 		}
 
@@ -168,7 +168,7 @@ func (w *WatcherCollection) updateCollection(ctx context.Context, oldCollection,
 			Str("name", updatedCollection.Name).
 			Msg("APICollection updated")
 
-		w.eventRecorder.Event(updatedCollection, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+		w.eventRecorder.Event(updatedCollection, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
 	}
 
 	return nil
@@ -20,6 +20,7 @@ package api
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"path"
 	"sort"
@@ -36,12 +37,18 @@ import (
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/journal"
+	"github.com/traefik/hub-agent-kubernetes/pkg/reconcile"
+	"github.com/traefik/hub-agent-kubernetes/pkg/secretref"
+	"github.com/traefik/hub-agent-kubernetes/pkg/traefik"
+	"golang.org/x/exp/slices"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	ktypes "k8s.io/apimachinery/pkg/types"
 	kinformers "k8s.io/client-go/informers"
 	kclientset "k8s.io/client-go/kubernetes"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -52,6 +59,9 @@ import (
 const (
 	hubDomainSecretName          = "hub-certificate"
 	customDomainSecretNamePrefix = "hub-certificate-custom-domains"
+
+	// resourceKindGateway identifies APIGateways in the journal and among the resources referencing secrets.
+	resourceKindGateway = "APIGateway"
 )
 
 // WatcherGatewayConfig holds the watcher gateway configuration.
@@ -60,12 +70,43 @@ type WatcherGatewayConfig struct {
 	AgentNamespace          string
 	TraefikAPIEntryPoint    string
 	TraefikTunnelEntryPoint string
+	// TraefikInstances are the additional Traefik instances serving APIs of specific namespaces.
+	TraefikInstances traefik.Instances
+
+	// AuthServerAddress is the address of the auth server, checking the methods of the requests sent to the APIs
+	// restricting them and validating the requests of the APIs enabling it.
+	AuthServerAddress string
+
+	// APIRateLimits enables the APIRateLimits, whose CRD may not be installed on the cluster.
+	APIRateLimits bool
+
+	// APIVersions enables the APIVersions, whose CRD may not be installed on the cluster.
+	APIVersions bool
+
+	// CaptureService is the service of the auth server capture proxy, receiving the traffic of the APIs having
+	// capture enabled.
+	CaptureService CaptureServiceConfig
+
+	// Journal records the child resources syncs in progress, so the ones interrupted by a restart are rolled
+	// forward on startup.
+	Journal *journal.Journal
+
+	// Secrets resolves the certificates referenced by APIGateways. Referenced certificates are not supported
+	// without it.
+	Secrets *secretref.Resolver
 
 	GatewaySyncInterval time.Duration
 	CertSyncInterval    time.Duration
 	CertRetryInterval   time.Duration
 }
 
+// CaptureServiceConfig locates the auth server capture proxy.
+type CaptureServiceConfig struct {
+	Name      string
+	Namespace string
+	Port      int
+}
+
 // WatcherGateway watches hub gateways and sync them with the cluster.
 type WatcherGateway struct {
 	config *WatcherGatewayConfig
@@ -73,6 +114,9 @@ type WatcherGateway struct {
 	wildCardCertMu sync.RWMutex
 	wildCardCert   edgeingress.Certificate
 
+	// secretChanges are the APIGateways whose referenced certificate changed.
+	secretChanges *secretref.Changes
+
 	platform PlatformClient
 
 	kubeClientSet kclientset.Interface
@@ -84,6 +128,12 @@ type WatcherGateway struct {
 	traefikClientSet v1alpha1.TraefikV1alpha1Interface
 
 	eventRecorder record.EventRecorder
+
+	// platformGateways are the APIGateways last fetched from the platform, by name. They are nil until fetched.
+	platformGatewaysMu sync.RWMutex
+	platformGateways   map[string]*Gateway
+
+	queue *reconcile.Queue
 }
 
 // NewWatcherGateway returns a new WatcherGateway.
@@ -92,9 +142,14 @@ func NewWatcherGateway(client PlatformClient, kubeClientSet kclientset.Interface
 	eventBroadcaster.StartRecordingToSink(&v1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
 	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{})
 
-	return &WatcherGateway{
+	secretChanges := secretref.NewChanges()
+	config.Secrets.OnChange(resourceKindGateway, secretChanges.Add)
+
+	w := &WatcherGateway{
 		config: config,
 
+		secretChanges: secretChanges,
+
 		platform: client,
 
 		kubeClientSet: kubeClientSet,
@@ -107,6 +162,9 @@ func NewWatcherGateway(client PlatformClient, kubeClientSet kclientset.Interface
 
 		eventRecorder: eventRecorder,
 	}
+	w.queue = reconcile.NewQueue("APIGateway", w.reconcileGateway)
+
+	return w
 }
 
 // Run runs WatcherGateway.
@@ -114,12 +172,21 @@ func (w *WatcherGateway) Run(ctx context.Context) {
 	t := time.NewTicker(w.config.GatewaySyncInterval)
 	defer t.Stop()
 
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.queue.Run(ctx)
+	}()
+	defer wg.Wait()
+
 	certSyncInterval := time.After(w.config.CertSyncInterval)
 	ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
 	if err := w.syncCertificates(ctxSync); err != nil {
 		log.Error().Err(err).Msg("Unable to synchronize certificates with platform")
 		certSyncInterval = time.After(w.config.CertRetryInterval)
 	}
+	w.replayJournal(ctxSync)
 	w.syncGateways(ctxSync)
 	cancel()
 
@@ -134,6 +201,11 @@ func (w *WatcherGateway) Run(ctx context.Context) {
 			w.syncGateways(ctxSync)
 			cancel()
 
+		case <-w.secretChanges.C():
+			ctxSync, cancel = context.WithTimeout(ctx, 20*time.Second)
+			w.syncSecretChanges(ctxSync)
+			cancel()
+
 		case <-certSyncInterval:
 			ctxSync, cancel = context.WithTimeout(ctx, 20*time.Second)
 			if err := w.syncCertificates(ctxSync); err != nil {
@@ -199,7 +271,7 @@ func (w *WatcherGateway) setupCertificates(ctx context.Context, gateway *hubv1al
 	for namespace := range apisByNamespace {
 		upserted, err := w.upsertSecret(ctx, certificate, hubDomainSecretName, namespace, gateway)
 		if err != nil {
-			w.eventRecorder.Eventf(gateway, "Failed", "CertificateSyncing", "Unable to sync certificate for [%s] with the Hub platform: %s", gateway.Status.HubDomain, err)
+			w.eventRecorder.Eventf(gateway, corev1.EventTypeWarning, "CertificateSyncFailed", "Unable to sync certificate for [%s] with the Hub platform: %s", gateway.Status.HubDomain, err)
 			return fmt.Errorf("upsert secret: %w", err)
 		}
 		if upserted {
@@ -214,9 +286,9 @@ func (w *WatcherGateway) setupCertificates(ctx context.Context, gateway *hubv1al
 		return nil
 	}
 
-	cert, err := w.platform.GetCertificateByDomains(ctx, gateway.Status.CustomDomains)
+	cert, err := w.customDomainsCertificate(ctx, gateway)
 	if err != nil {
-		return fmt.Errorf("get certificate by domains %q: %w", strings.Join(gateway.Status.CustomDomains, ","), err)
+		return err
 	}
 
 	secretName, err := getCustomDomainSecretName(gateway.Name)
@@ -228,7 +300,7 @@ func (w *WatcherGateway) setupCertificates(ctx context.Context, gateway *hubv1al
 	for namespace := range apisByNamespace {
 		upserted, err := w.upsertSecret(ctx, cert, secretName, namespace, gateway)
 		if err != nil {
-			w.eventRecorder.Eventf(gateway, "Failed", "CertificateSyncing", "Unable to sync certificate for [%s] with the Hub platform: %s", strings.Join(gateway.Status.CustomDomains, ", "), err)
+			w.eventRecorder.Eventf(gateway, corev1.EventTypeWarning, "CertificateSyncFailed", "Unable to sync certificate for [%s] with the Hub platform: %s", strings.Join(gateway.Status.CustomDomains, ", "), err)
 			return fmt.Errorf("upsert secret: %w", err)
 		}
 		if upserted {
@@ -242,6 +314,41 @@ func (w *WatcherGateway) setupCertificates(ctx context.Context, gateway *hubv1al
 	return nil
 }
 
+// customDomainsCertificate returns the certificate referenced by the APIGateway, or the one issued by the platform
+// for its custom domains.
+func (w *WatcherGateway) customDomainsCertificate(ctx context.Context, gateway *hubv1alpha1.APIGateway) (edgeingress.Certificate, error) {
+	owner := secretref.Owner{Kind: resourceKindGateway, Name: gateway.Name}
+
+	if gateway.Spec.Certificate == nil {
+		w.config.Secrets.Untrack(owner)
+
+		cert, err := w.platform.GetCertificateByDomains(ctx, gateway.Status.CustomDomains)
+		if err != nil {
+			return edgeingress.Certificate{}, fmt.Errorf("get certificate by domains %q: %w", strings.Join(gateway.Status.CustomDomains, ","), err)
+		}
+
+		return cert, nil
+	}
+
+	if w.config.Secrets == nil {
+		return edgeingress.Certificate{}, errors.New("referenced certificates are not supported")
+	}
+	// APIGateways are cluster scoped, there is no namespace to default to.
+	if gateway.Spec.Certificate.Namespace == "" {
+		return edgeingress.Certificate{}, errors.New("referenced certificate namespace is required")
+	}
+
+	ref := secretref.RefOf(*gateway.Spec.Certificate, "")
+	w.config.Secrets.Track(owner, ref)
+
+	cert, key, err := w.config.Secrets.TLS(ref)
+	if err != nil {
+		return edgeingress.Certificate{}, fmt.Errorf("get referenced certificate: %w", err)
+	}
+
+	return edgeingress.Certificate{Certificate: cert, PrivateKey: key}, nil
+}
+
 func (w *WatcherGateway) upsertSecret(ctx context.Context, cert edgeingress.Certificate, name, namespace string, gateway *hubv1alpha1.APIGateway) (bool, error) {
 	secret, err := w.kubeClientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil && !kerror.IsNotFound(err) {
@@ -322,6 +429,11 @@ func (w *WatcherGateway) upsertSecret(ctx context.Context, cert edgeingress.Cert
 }
 
 func (w *WatcherGateway) syncGateways(ctx context.Context) {
+	// External services are shared by all the gateways exposing their APIs, they are synchronized once for all of them.
+	if err := w.syncExternalServices(ctx); err != nil {
+		log.Error().Err(err).Msg("Unable to synchronize external services")
+	}
+
 	platformGateways, err := w.platform.GetGateways(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Unable to fetch APIGateways")
@@ -334,50 +446,66 @@ func (w *WatcherGateway) syncGateways(ctx context.Context) {
 		return
 	}
 
-	gatewaysByName := map[string]*hubv1alpha1.APIGateway{}
-	for _, gateway := range clusterGateways {
-		gatewaysByName[gateway.Name] = gateway
-	}
-
+	gateways := make(map[string]*Gateway, len(platformGateways))
 	for _, gateway := range platformGateways {
 		platformGateway := gateway
+		gateways[platformGateway.Name] = &platformGateway
+	}
 
-		logger := log.With().Str("name", platformGateway.Name).Logger()
+	w.platformGatewaysMu.Lock()
+	w.platformGateways = gateways
+	w.platformGatewaysMu.Unlock()
 
-		oldClusterGateway, found := gatewaysByName[platformGateway.Name]
+	// Each gateway is reconciled on its own, cluster gateways missing on the platform are deleted.
+	for name := range gateways {
+		w.queue.Add(name)
+	}
+	for _, gateway := range clusterGateways {
+		if gateways[gateway.Name] == nil {
+			w.queue.Add(gateway.Name)
+		}
+	}
+}
 
-		// Gateways that will remain in the map will be deleted.
-		delete(gatewaysByName, platformGateway.Name)
+// reconcileGateway creates, updates or deletes the cluster APIGateway with the given name to match the platform.
+func (w *WatcherGateway) reconcileGateway(ctx context.Context, name string) error {
+	w.platformGatewaysMu.RLock()
+	platformGateway, found := w.platformGateways[name]
+	w.platformGatewaysMu.RUnlock()
 
-		newClusterGateway, resourceErr := platformGateway.Resource()
-		if resourceErr != nil {
-			logger.Error().Err(resourceErr).Msg("Unable to build APIGateway resource")
-			continue
-		}
+	clusterGateway, err := w.hubInformer.Hub().V1alpha1().APIGateways().Lister().Get(name)
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get APIGateway: %w", err)
+	}
+	clusterFound := err == nil
 
-		if !found {
-			if err = w.createGateway(ctx, newClusterGateway); err != nil {
-				logger.Error().Err(err).Msg("Unable to create APIGateway")
-			}
-			continue
+	if !found {
+		if !clusterFound {
+			return nil
 		}
 
-		if err = w.updateGateway(ctx, oldClusterGateway, newClusterGateway); err != nil {
-			logger.Error().Err(err).Msg("Unable to update APIGateway")
-		}
+		return w.deleteGateway(ctx, clusterGateway)
+	}
 
-		if unverified := platformGateway.UnverifiedCustomDomains(); len(unverified) > 0 {
-			w.eventRecorder.Eventf(oldClusterGateway, corev1.EventTypeWarning, "DomainOwnership", "Domains [%s] ownership have not been verified yet. The APIGateway won't be served for these domains.", strings.Join(unverified, ", "))
-		}
+	newClusterGateway, err := platformGateway.Resource()
+	if err != nil {
+		return fmt.Errorf("build APIGateway resource: %w", err)
+	}
+
+	if !clusterFound {
+		return w.createGateway(ctx, newClusterGateway)
 	}
 
-	w.cleanGateways(ctx, gatewaysByName)
+	if unverified := platformGateway.UnverifiedCustomDomains(); len(unverified) > 0 {
+		w.eventRecorder.Eventf(clusterGateway, corev1.EventTypeWarning, "DomainOwnership", "Domains [%s] ownership have not been verified yet. The APIGateway won't be served for these domains.", strings.Join(unverified, ", "))
+	}
+
+	return w.updateGateway(ctx, clusterGateway, newClusterGateway)
 }
 
 func (w *WatcherGateway) createGateway(ctx context.Context, gateway *hubv1alpha1.APIGateway) error {
 	createdGateway, err := w.hubClientSet.HubV1alpha1().APIGateways().Create(ctx, gateway, metav1.CreateOptions{})
 	if err != nil {
-		w.eventRecorder.Eventf(gateway, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
 		return fmt.Errorf("creating APIGateway: %w", err)
 	}
 
@@ -385,7 +513,7 @@ func (w *WatcherGateway) createGateway(ctx context.Context, gateway *hubv1alpha1
 		Str("name", createdGateway.Name).
 		Msg("APIGateway created")
 
-	w.eventRecorder.Event(createdGateway, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+	w.eventRecorder.Event(createdGateway, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
 
 	return w.syncChildResources(ctx, createdGateway)
 }
@@ -394,11 +522,13 @@ func (w *WatcherGateway) updateGateway(ctx context.Context, oldGateway, newGatew
 	meta := oldGateway.ObjectMeta
 	meta.Labels = newGateway.Labels
 	newGateway.ObjectMeta = meta
+	newGateway.Status.Conditions = hubv1alpha1.MergeConditions(oldGateway.Status.Conditions, newGateway.Status.Conditions...)
 
-	if newGateway.Status.Version != oldGateway.Status.Version {
+	clusterGateway := oldGateway
+	if newGateway.Status.Version != oldGateway.Status.Version || !isSynced(oldGateway.Status.Conditions) {
 		updatedGateway, err := w.hubClientSet.HubV1alpha1().APIGateways().Update(ctx, newGateway, metav1.UpdateOptions{})
 		if err != nil {
-			w.eventRecorder.Eventf(newGateway, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
+			w.eventRecorder.Eventf(newGateway, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
 			return fmt.Errorf("updating APIGateway: %w", err)
 		}
 
@@ -406,34 +536,88 @@ func (w *WatcherGateway) updateGateway(ctx context.Context, oldGateway, newGatew
 			Str("name", updatedGateway.Name).
 			Msg("APIGateway updated")
 
-		w.eventRecorder.Event(updatedGateway, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+		w.eventRecorder.Event(updatedGateway, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
+
+		clusterGateway = updatedGateway
+	}
+
+	return w.syncChildResources(ctx, clusterGateway)
+}
+
+func (w *WatcherGateway) deleteGateway(ctx context.Context, gateway *hubv1alpha1.APIGateway) error {
+	// Foreground propagation allow us to delete all resources owned by the APIGateway.
+	policy := metav1.DeletePropagationForeground
+
+	opts := metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+	}
+	err := w.hubClientSet.HubV1alpha1().APIGateways().Delete(ctx, gateway.Name, opts)
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("deleting APIGateway: %w", err)
 	}
 
-	return w.syncChildResources(ctx, newGateway)
+	log.Debug().
+		Str("name", gateway.Name).
+		Msg("APIGateway deleted")
+
+	return nil
 }
 
-func (w *WatcherGateway) cleanGateways(ctx context.Context, gateways map[string]*hubv1alpha1.APIGateway) {
-	for _, gateway := range gateways {
-		// Foreground propagation allow us to delete all resources owned by the APIGateway.
-		policy := metav1.DeletePropagationForeground
+// replayJournal rolls forward the child resources syncs interrupted by a restart.
+func (w *WatcherGateway) replayJournal(ctx context.Context) {
+	lister := w.hubInformer.Hub().V1alpha1().APIGateways().Lister()
 
-		opts := metav1.DeleteOptions{
-			PropagationPolicy: &policy,
+	err := w.config.Journal.Replay(ctx, resourceKindGateway, func(ctx context.Context, entry journal.Entry) error {
+		gateway, err := lister.Get(entry.Name)
+		if kerror.IsNotFound(err) {
+			// Child resources of deleted APIGateways are garbage collected through their owner references.
+			return nil
 		}
-		err := w.hubClientSet.HubV1alpha1().APIGateways().Delete(ctx, gateway.Name, opts)
 		if err != nil {
-			log.Error().Err(err).Msg("Unable to delete APIGateway")
+			return fmt.Errorf("get APIGateway: %w", err)
+		}
+
+		return w.syncChildResources(ctx, gateway.DeepCopy())
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to replay APIGateway journal")
+	}
+}
 
+// syncSecretChanges syncs the child resources of the APIGateways whose referenced certificate changed.
+func (w *WatcherGateway) syncSecretChanges(ctx context.Context) {
+	lister := w.hubInformer.Hub().V1alpha1().APIGateways().Lister()
+
+	for _, name := range w.secretChanges.Take() {
+		gateway, err := lister.Get(name)
+		if kerror.IsNotFound(err) {
+			w.config.Secrets.Untrack(secretref.Owner{Kind: resourceKindGateway, Name: name})
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Str("name", name).Msg("Unable to get APIGateway")
 			continue
 		}
 
-		log.Debug().
-			Str("name", gateway.Name).
-			Msg("APIGateway deleted")
+		if err = w.syncChildResources(ctx, gateway.DeepCopy()); err != nil {
+			log.Error().Err(err).Str("name", name).Msg("Unable to sync child resources")
+		}
 	}
 }
 
-func (w *WatcherGateway) syncChildResources(ctx context.Context, gateway *hubv1alpha1.APIGateway) error {
+// syncChildResources syncs the certificates and ingresses of the given APIGateway. Failures are recorded as events on
+// the APIGateway.
+func (w *WatcherGateway) syncChildResources(ctx context.Context, gateway *hubv1alpha1.APIGateway) (err error) {
+	defer func() {
+		if err != nil {
+			w.eventRecorder.Eventf(gateway, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize child resources: %s", err)
+		}
+	}()
+
+	if err := w.config.Journal.Begin(ctx, resourceKindGateway, "", gateway.Name); err != nil {
+		log.Warn().Err(err).Str("name", gateway.Name).Msg("Unable to journal APIGateway sync")
+	}
+
 	apisByNamespace, err := w.apisByNamespace(ctx, gateway)
 	if err != nil {
 		return fmt.Errorf("unable to load gateway APIs by namespace: %w", err)
@@ -444,20 +628,65 @@ func (w *WatcherGateway) syncChildResources(ctx context.Context, gateway *hubv1a
 	w.wildCardCertMu.RUnlock()
 
 	if err := w.setupCertificates(ctx, gateway, apisByNamespace, certificate); err != nil {
+		w.setGatewayConditions(ctx, gateway,
+			certificateProvisionedCondition(err),
+			notReadyCondition(hubv1alpha1.ReasonCertificateProvisioningFailed, "Certificates are not provisioned"),
+		)
+
 		return fmt.Errorf("unable to setup APIGateway certificates: %w", err)
 	}
 
 	if err := w.cleanupNamespaces(ctx, gateway, apisByNamespace); err != nil {
+		w.setGatewayConditions(ctx, gateway,
+			certificateProvisionedCondition(nil),
+			notReadyCondition(hubv1alpha1.ReasonRoutingFailed, err.Error()),
+		)
+
 		return fmt.Errorf("clean up ingresses: %w", err)
 	}
 
 	if err := w.upsertIngresses(ctx, gateway, apisByNamespace); err != nil {
+		w.setGatewayConditions(ctx, gateway,
+			certificateProvisionedCondition(nil),
+			notReadyCondition(hubv1alpha1.ReasonRoutingFailed, err.Error()),
+		)
+
 		return fmt.Errorf("upsert ingresses: %w", err)
 	}
 
+	w.setGatewayConditions(ctx, gateway, certificateProvisionedCondition(nil), readyCondition(metav1.Time{}))
+
+	if err := w.config.Journal.End(ctx, resourceKindGateway, "", gateway.Name); err != nil {
+		log.Warn().Err(err).Str("name", gateway.Name).Msg("Unable to journal APIGateway sync completion")
+	}
+
 	return nil
 }
 
+// setGatewayConditions sets the given conditions on the APIGateway status.
+// Only the conditions are patched, leaving the rest of the status untouched. Failing to do so is only logged, as
+// conditions are informative.
+func (w *WatcherGateway) setGatewayConditions(ctx context.Context, gateway *hubv1alpha1.APIGateway, conditions ...metav1.Condition) {
+	gateway = gateway.DeepCopy()
+	if !hubv1alpha1.SetConditions(&gateway.Status.Conditions, conditions...) {
+		return
+	}
+
+	patch, err := conditionsPatch(gateway.Status.Conditions)
+	if err != nil {
+		log.Error().Err(err).
+			Str("name", gateway.Name).
+			Msg("Unable to build APIGateway conditions patch")
+		return
+	}
+
+	if _, err = w.hubClientSet.HubV1alpha1().APIGateways().Patch(ctx, gateway.Name, ktypes.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		log.Error().Err(err).
+			Str("name", gateway.Name).
+			Msg("Unable to patch APIGateway conditions")
+	}
+}
+
 type resolvedAPI struct {
 	groups string
 	api    *hubv1alpha1.API
@@ -480,6 +709,10 @@ func (w *WatcherGateway) apisByNamespace(ctx context.Context, gateway *hubv1alph
 			return nil, fmt.Errorf("find APIs: %w", err)
 		}
 
+		if apis, err = w.withAPIVersions(apis); err != nil {
+			return nil, fmt.Errorf("find API versions: %w", err)
+		}
+
 		sort.Strings(access.Spec.Groups)
 		groups := strings.Join(access.Spec.Groups, ",")
 		for _, api := range apis {
@@ -497,6 +730,10 @@ func (w *WatcherGateway) apisByNamespace(ctx context.Context, gateway *hubv1alph
 				return nil, fmt.Errorf("find APIs: %w", err)
 			}
 
+			if collectionAPIs, err = w.withAPIVersions(collectionAPIs); err != nil {
+				return nil, fmt.Errorf("find API versions: %w", err)
+			}
+
 			for _, collectionAPI := range collectionAPIs {
 				if collection.Spec.PathPrefix == "" {
 					resolvedAPIs = append(resolvedAPIs, resolvedAPI{groups: groups, api: collectionAPI})
@@ -536,6 +773,68 @@ func (w *WatcherGateway) findAPIs(selector *metav1.LabelSelector) ([]*hubv1alpha
 	return apis, nil
 }
 
+// withAPIVersions returns the given APIs along with their versions. Each version is exposed as a copy of its API,
+// routed on the version path prefix and/or header, and served by the version service when it has one.
+func (w *WatcherGateway) withAPIVersions(apis []*hubv1alpha1.API) ([]*hubv1alpha1.API, error) {
+	if !w.config.APIVersions {
+		return apis, nil
+	}
+
+	var apisWithVersions []*hubv1alpha1.API
+	for _, api := range apis {
+		apisWithVersions = append(apisWithVersions, api)
+
+		versions, err := w.hubInformer.Hub().V1alpha1().APIVersions().Lister().APIVersions(api.Namespace).List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("list API versions: %w", err)
+		}
+
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i].Name < versions[j].Name
+		})
+
+		for _, version := range versions {
+			if version.Spec.APIName != api.Name {
+				continue
+			}
+
+			if version.Spec.PathPrefix == "" && version.Spec.VersionHeader == nil {
+				log.Error().
+					Str("name", version.Name).
+					Str("namespace", version.Namespace).
+					Msg("APIVersion has neither a path prefix nor a version header, ignoring it")
+				continue
+			}
+
+			apisWithVersions = append(apisWithVersions, versionedAPI(api, version))
+		}
+	}
+
+	return apisWithVersions, nil
+}
+
+// versionedAPI builds the API serving the given version.
+func versionedAPI(api *hubv1alpha1.API, version *hubv1alpha1.APIVersion) *hubv1alpha1.API {
+	versioned := api.DeepCopy()
+	// The "/" separator can't be part of a resource name, preventing conflicts with other APIs.
+	versioned.Name = api.Name + "/" + version.Name
+	versioned.Spec.VersionHeader = version.Spec.VersionHeader
+	versioned.Spec.Deprecation = version.Spec.Deprecation
+	versioned.Spec.Sandbox = nil
+
+	if version.Spec.PathPrefix != "" {
+		versioned.Spec.PathPrefix = version.Spec.PathPrefix
+	}
+
+	if version.Spec.Service != nil {
+		versioned.Spec.Service.Name = version.Spec.Service.Name
+		versioned.Spec.Service.Port = version.Spec.Service.Port
+		versioned.Spec.Service.ExternalURL = ""
+	}
+
+	return versioned
+}
+
 func (w *WatcherGateway) findCollections(selector *metav1.LabelSelector) ([]*hubv1alpha1.APICollection, error) {
 	if selector == nil {
 		return nil, nil
@@ -621,6 +920,15 @@ func (w *WatcherGateway) setupStripPrefixMiddleware(ctx context.Context, gateway
 	return traefikMiddlewareName, nil
 }
 
+// traefikInstance returns the Traefik instance serving APIs of the given namespace.
+func (w *WatcherGateway) traefikInstance(namespace string) traefik.Instance {
+	return w.config.TraefikInstances.ForNamespace(namespace, traefik.Instance{
+		IngressClassName: w.config.IngressClassName,
+		APIEntryPoint:    w.config.TraefikAPIEntryPoint,
+		TunnelEntryPoint: w.config.TraefikTunnelEntryPoint,
+	})
+}
+
 func (w *WatcherGateway) upsertIngress(ctx context.Context, ingress *netv1.Ingress) error {
 	existingIngress, err := w.kubeClientSet.NetworkingV1().Ingresses(ingress.Namespace).Get(ctx, ingress.Name, metav1.GetOptions{})
 	if err != nil && !kerror.IsNotFound(err) {
@@ -676,6 +984,7 @@ func (w *WatcherGateway) cleanupNamespaces(ctx context.Context, gateway *hubv1al
 		return fmt.Errorf("get ingress name for hub domain: %w", err)
 	}
 
+	staleNamespaces := make(map[string]struct{})
 	for _, ingress := range hubIngresses {
 		if !strings.HasPrefix(ingress.Name, ingressName) {
 			continue
@@ -720,6 +1029,68 @@ func (w *WatcherGateway) cleanupNamespaces(ctx context.Context, gateway *hubv1al
 					Str("ingress_name", ingress.Name).
 					Msg("Unable to clean APIGateway's child Ingress")
 			}
+
+			// The body limit middleware is shared by the Ingresses and IngressRoutes of the namespace.
+			staleNamespaces[ingress.Namespace] = struct{}{}
+		}
+	}
+
+	hubIngressRoutes, err := w.traefikClientSet.IngressRoutes("").List(ctx, metav1.ListOptions{
+		LabelSelector: hubIngressesSelector.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("list ingress routes: %w", err)
+	}
+
+	for _, route := range hubIngressRoutes.Items {
+		if !strings.HasPrefix(route.Name, ingressName) {
+			continue
+		}
+		if _, ok := apisByNamespace[route.Namespace]; ok {
+			continue
+		}
+
+		logger := log.Ctx(ctx).With().Str("gateway_name", gateway.Name).Str("namespace", route.Namespace).Logger()
+		if route.Spec.TLS != nil {
+			err = w.kubeClientSet.CoreV1().
+				Secrets(route.Namespace).
+				Delete(ctx, route.Spec.TLS.SecretName, metav1.DeleteOptions{})
+			if err != nil && !kerror.IsNotFound(err) {
+				logger.Error().Err(err).
+					Str("secret_name", route.Spec.TLS.SecretName).
+					Msg("Unable to clean APIGateway's child Secret")
+
+				continue
+			}
+		}
+
+		middlewareName, err := getStripPrefixMiddlewareName(gateway.Name)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to get APIGateway's child Middleware name")
+
+			continue
+		}
+
+		err = w.traefikClientSet.
+			Middlewares(route.Namespace).
+			Delete(ctx, middlewareName, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			logger.Error().Err(err).
+				Str("middleware_name", middlewareName).
+				Msg("Unable to clean APIGateway's child Middleware")
+
+			continue
+		}
+
+		staleNamespaces[route.Namespace] = struct{}{}
+	}
+
+	for namespace := range staleNamespaces {
+		if err = w.cleanupIngressRoutes(ctx, namespace, gateway, newUpsertedRoutes()); err != nil {
+			log.Ctx(ctx).Error().Err(err).
+				Str("gateway_name", gateway.Name).
+				Str("namespace", namespace).
+				Msg("Unable to clean APIGateway's child IngressRoutes")
 		}
 	}
 
@@ -744,15 +1115,79 @@ func (w *WatcherGateway) upsertIngressesOnNamespace(ctx context.Context, namespa
 	}
 
 	ingressUpserted := make(map[string]struct{})
+	routesUpserted := newUpsertedRoutes()
+
+	middlewares := gatewayMiddlewares{stripPrefix: traefikMiddlewareName}
+	if err = w.setupBodyLimitMiddlewares(ctx, namespace, gateway, resolvedAPIs, &middlewares, routesUpserted); err != nil {
+		return fmt.Errorf("setup body limit middlewares for namespace %q: %w", namespace, err)
+	}
+	if err = w.setupAllowedMethodsMiddlewares(ctx, namespace, gateway, resolvedAPIs, &middlewares, routesUpserted); err != nil {
+		return fmt.Errorf("setup allowed methods middlewares for namespace %q: %w", namespace, err)
+	}
+	if err = w.setupRequestValidationMiddlewares(ctx, namespace, gateway, resolvedAPIs, &middlewares, routesUpserted); err != nil {
+		return fmt.Errorf("setup request validation middlewares for namespace %q: %w", namespace, err)
+	}
+	if err = w.setupCORSMiddlewares(ctx, namespace, gateway, resolvedAPIs, &middlewares, routesUpserted); err != nil {
+		return fmt.Errorf("setup CORS middlewares for namespace %q: %w", namespace, err)
+	}
+	if err = w.setupRateLimitMiddlewares(ctx, namespace, gateway, resolvedAPIs, &middlewares, routesUpserted); err != nil {
+		return fmt.Errorf("setup rate limit middlewares for namespace %q: %w", namespace, err)
+	}
+
 	for groups, apis := range apisByGroups {
+		var pathAPIs, versionedAPIs, sandboxAPIs []*hubv1alpha1.API
+		for _, api := range apis {
+			if api.Spec.Sandbox != nil {
+				sandboxAPIs = append(sandboxAPIs, api)
+			}
+
+			switch {
+			case api.Spec.Deprecation != nil || isCaptured(api):
+				if err = w.upsertDedicatedAPIIngressRoutes(ctx, namespace, gateway, groups, api, middlewares, routesUpserted); err != nil {
+					return fmt.Errorf("upsert dedicated API ingress routes for namespace %q: %w", namespace, err)
+				}
+			// APIs having their own middlewares can't share the ones of the Ingresses.
+			case api.Spec.VersionHeader != nil || hasMatchers(api) || hasOwnMiddlewares(api) || middlewares.isRateLimited(groups, api.Name):
+				versionedAPIs = append(versionedAPIs, api)
+			default:
+				pathAPIs = append(pathAPIs, api)
+			}
+		}
+
+		if len(versionedAPIs) > 0 {
+			if err = w.upsertVersionedIngressRoutes(ctx, namespace, gateway, groups, versionedAPIs, middlewares, routesUpserted); err != nil {
+				return fmt.Errorf("upsert versioned ingress routes for namespace %q: %w", namespace, err)
+			}
+		}
+
+		if len(sandboxAPIs) > 0 {
+			if err = w.upsertSandboxIngressRoutes(ctx, namespace, gateway, groups, sandboxAPIs, middlewares, routesUpserted); err != nil {
+				return fmt.Errorf("upsert sandbox ingress routes for namespace %q: %w", namespace, err)
+			}
+		}
+
+		if len(pathAPIs) == 0 {
+			continue
+		}
+
+		instance := w.traefikInstance(namespace)
+
 		name, err := getHubDomainIngressName(gateway.Name, groups)
 		if err != nil {
 			return fmt.Errorf("get hub domain ingress name: %w", err)
 		}
 
+		// APIs are listed in any order, sort them so that the Ingress doesn't change from one sync to another.
+		sort.Slice(pathAPIs, func(i, j int) bool {
+			if pathAPIs[i].Spec.PathPrefix != pathAPIs[j].Spec.PathPrefix {
+				return pathAPIs[i].Spec.PathPrefix < pathAPIs[j].Spec.PathPrefix
+			}
+			return pathAPIs[i].Name < pathAPIs[j].Name
+		})
+
 		var paths []netv1.HTTPIngressPath
 		pathType := netv1.PathTypePrefix
-		for _, api := range apis {
+		for _, api := range pathAPIs {
 			paths = append(paths, netv1.HTTPIngressPath{
 				PathType: &pathType,
 				Path:     api.Spec.PathPrefix,
@@ -784,8 +1219,8 @@ func (w *WatcherGateway) upsertIngressesOnNamespace(ctx context.Context, namespa
 				Namespace: namespace,
 				Annotations: map[string]string{
 					"traefik.ingress.kubernetes.io/router.tls":         "true",
-					"traefik.ingress.kubernetes.io/router.entrypoints": w.config.TraefikTunnelEntryPoint,
-					"traefik.ingress.kubernetes.io/router.middlewares": traefikMiddlewareName,
+					"traefik.ingress.kubernetes.io/router.entrypoints": instance.TunnelEntryPoint,
+					"traefik.ingress.kubernetes.io/router.middlewares": strings.Join(middlewares.names(), ","),
 					reviewer.AnnotationHubAuth:                         "hub-api-management",
 					reviewer.AnnotationHubAuthGroup:                    groups,
 				},
@@ -801,7 +1236,7 @@ func (w *WatcherGateway) upsertIngressesOnNamespace(ctx context.Context, namespa
 				}},
 			},
 			Spec: netv1.IngressSpec{
-				IngressClassName: pointer.String(w.config.IngressClassName),
+				IngressClassName: pointer.String(instance.IngressClassName),
 				Rules:            rules,
 				TLS: []netv1.IngressTLS{{
 					Hosts:      []string{gateway.Status.HubDomain},
@@ -825,7 +1260,7 @@ func (w *WatcherGateway) upsertIngressesOnNamespace(ctx context.Context, namespa
 		}
 
 		ing.Name = name
-		ing.ObjectMeta.Annotations["traefik.ingress.kubernetes.io/router.entrypoints"] = w.config.TraefikAPIEntryPoint
+		ing.ObjectMeta.Annotations["traefik.ingress.kubernetes.io/router.entrypoints"] = instance.APIEntryPoint
 
 		var rulesCustom []netv1.IngressRule
 		for _, domain := range gateway.Status.CustomDomains {
@@ -874,7 +1309,7 @@ func (w *WatcherGateway) upsertIngressesOnNamespace(ctx context.Context, namespa
 		}
 	}
 
-	return nil
+	return w.cleanupIngressRoutes(ctx, namespace, gateway, routesUpserted)
 }
 
 func newStripPrefixMiddleware(name, namespace string, apis []*hubv1alpha1.API) traefikv1alpha1.Middleware {
@@ -883,8 +1318,13 @@ func newStripPrefixMiddleware(name, namespace string, apis []*hubv1alpha1.API) t
 		prefixes = append(prefixes, api.Spec.PathPrefix)
 	}
 	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) == len(prefixes[j]) {
+			return prefixes[i] < prefixes[j]
+		}
 		return len(prefixes[i]) > len(prefixes[j])
 	})
+	// APIs versioned by header or restricted by matchers can share the same path prefix.
+	prefixes = slices.Compact(prefixes)
 
 	return traefikv1alpha1.Middleware{
 		TypeMeta: metav1.TypeMeta{
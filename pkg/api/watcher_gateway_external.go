@@ -0,0 +1,225 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// LabelExternalAPI is set on the ExternalName Services managed for APIs having an external URL.
+// Its value is the name of the API.
+const LabelExternalAPI = "hub.traefik.io/external-api"
+
+const (
+	annotationServersScheme  = "traefik.ingress.kubernetes.io/service.serversscheme"
+	annotationPassHostHeader = "traefik.ingress.kubernetes.io/service.passhostheader"
+)
+
+// externalBackend is a backend running outside of the cluster, described by the external URL of an API.
+type externalBackend struct {
+	scheme string
+	host   string
+	port   int32
+}
+
+// parseExternalURL parses the external URL of an API. Its port defaults to the port of the scheme, and must match
+// the service port of the API when it has one.
+func parseExternalURL(api *hubv1alpha1.API) (externalBackend, error) {
+	u, err := url.Parse(api.Spec.Service.ExternalURL)
+	if err != nil {
+		return externalBackend{}, fmt.Errorf("parse external URL: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return externalBackend{}, fmt.Errorf("unsupported external URL scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return externalBackend{}, errors.New("missing external URL host")
+	}
+	if net.ParseIP(u.Hostname()) != nil {
+		return externalBackend{}, errors.New("external URL host must be a DNS name")
+	}
+
+	port := int32(80)
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if u.Port() != "" {
+		p, err := strconv.ParseInt(u.Port(), 10, 32)
+		if err != nil {
+			return externalBackend{}, fmt.Errorf("parse external URL port: %w", err)
+		}
+		port = int32(p)
+	}
+
+	if number := api.Spec.Service.Port.Number; number != 0 && number != port {
+		return externalBackend{}, fmt.Errorf("service port %d doesn't match the external URL port %d", number, port)
+	}
+
+	return externalBackend{scheme: u.Scheme, host: u.Hostname(), port: port}, nil
+}
+
+// syncExternalServices manages the ExternalName Services resolving to the backends of the APIs having an external
+// URL, and removes the ones which are no longer needed.
+func (w *WatcherGateway) syncExternalServices(ctx context.Context) error {
+	apis, err := w.hubInformer.Hub().V1alpha1().APIs().Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("list APIs: %w", err)
+	}
+
+	upserted := make(map[string]struct{})
+	for _, api := range apis {
+		if api.Spec.Service.ExternalURL == "" {
+			continue
+		}
+
+		if err = w.upsertExternalService(ctx, api); err != nil {
+			log.Error().Err(err).
+				Str("name", api.Name).
+				Str("namespace", api.Namespace).
+				Msg("Unable to upsert external service")
+			continue
+		}
+		upserted[api.Spec.Service.Name+"@"+api.Namespace] = struct{}{}
+	}
+
+	services, err := w.kubeClientSet.CoreV1().Services("").List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/managed-by=traefik-hub," + LabelExternalAPI,
+	})
+	if err != nil {
+		return fmt.Errorf("list external services: %w", err)
+	}
+
+	for _, service := range services.Items {
+		if _, found := upserted[service.Name+"@"+service.Namespace]; found {
+			continue
+		}
+
+		if err = w.kubeClientSet.CoreV1().Services(service.Namespace).Delete(ctx, service.Name, metav1.DeleteOptions{}); err != nil && !kerror.IsNotFound(err) {
+			log.Error().Err(err).
+				Str("name", service.Name).
+				Str("namespace", service.Namespace).
+				Msg("Unable to delete external service")
+		}
+	}
+
+	return nil
+}
+
+func (w *WatcherGateway) upsertExternalService(ctx context.Context, api *hubv1alpha1.API) error {
+	backend, err := parseExternalURL(api)
+	if err != nil {
+		return err
+	}
+
+	portName := api.Spec.Service.Port.Name
+	if portName == "" {
+		portName = backend.scheme
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      api.Spec.Service.Name,
+			Namespace: api.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+				LabelExternalAPI:               api.Name,
+			},
+			Annotations: map[string]string{
+				annotationServersScheme:  backend.scheme,
+				annotationPassHostHeader: "false",
+			},
+			// Set OwnerReference allow us to delete services owned by an API.
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "hub.traefik.io/v1alpha1",
+				Kind:       "API",
+				Name:       api.Name,
+				UID:        api.UID,
+			}},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: backend.host,
+			Ports: []corev1.ServicePort{{
+				Name:     portName,
+				Protocol: corev1.ProtocolTCP,
+				Port:     backend.port,
+			}},
+		},
+	}
+
+	existing, err := w.kubeClientSet.CoreV1().Services(service.Namespace).Get(ctx, service.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get service: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		if _, err = w.kubeClientSet.CoreV1().Services(service.Namespace).Create(ctx, service, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create service: %w", err)
+		}
+
+		log.Debug().
+			Str("name", service.Name).
+			Str("namespace", service.Namespace).
+			Msg("External service created")
+
+		return nil
+	}
+
+	// Never take over a Service which isn't managed by the agent.
+	if _, ok := existing.Labels[LabelExternalAPI]; !ok {
+		return fmt.Errorf("service %q already exists and is not managed by Hub", service.Name)
+	}
+
+	if reflect.DeepEqual(existing.Spec.Ports, service.Spec.Ports) &&
+		existing.Spec.ExternalName == service.Spec.ExternalName &&
+		reflect.DeepEqual(existing.Labels, service.Labels) &&
+		reflect.DeepEqual(existing.Annotations, service.Annotations) {
+		return nil
+	}
+
+	existing.Labels = service.Labels
+	existing.Annotations = service.Annotations
+	existing.OwnerReferences = service.OwnerReferences
+	existing.Spec.ExternalName = service.Spec.ExternalName
+	existing.Spec.Ports = service.Spec.Ports
+
+	if _, err = w.kubeClientSet.CoreV1().Services(existing.Namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update service: %w", err)
+	}
+
+	log.Debug().
+		Str("name", service.Name).
+		Str("namespace", service.Namespace).
+		Msg("External service updated")
+
+	return nil
+}
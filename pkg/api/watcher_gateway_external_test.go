@@ -0,0 +1,197 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_parseExternalURL(t *testing.T) {
+	tests := []struct {
+		desc        string
+		externalURL string
+		port        hubv1alpha1.APIServiceBackendPort
+		want        externalBackend
+		wantErr     bool
+	}{
+		{
+			desc:        "https URL without port",
+			externalURL: "https://payments.example.com/v1",
+			want:        externalBackend{scheme: "https", host: "payments.example.com", port: 443},
+		},
+		{
+			desc:        "http URL with port matching the service port",
+			externalURL: "http://payments.example.com:8080",
+			port:        hubv1alpha1.APIServiceBackendPort{Number: 8080},
+			want:        externalBackend{scheme: "http", host: "payments.example.com", port: 8080},
+		},
+		{
+			desc:        "port not matching the service port",
+			externalURL: "https://payments.example.com:8443",
+			port:        hubv1alpha1.APIServiceBackendPort{Number: 443},
+			wantErr:     true,
+		},
+		{
+			desc:        "unsupported scheme",
+			externalURL: "ftp://payments.example.com",
+			wantErr:     true,
+		},
+		{
+			desc:        "IP address",
+			externalURL: "https://10.0.0.1",
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			api := &hubv1alpha1.API{
+				Spec: hubv1alpha1.APISpec{
+					Service: hubv1alpha1.APIService{Name: "payments", Port: test.port, ExternalURL: test.externalURL},
+				},
+			}
+
+			got, err := parseExternalURL(api)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestWatcherGateway_syncExternalServices(t *testing.T) {
+	apis := []runtime.Object{
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "apps", UID: "api-uid"},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/payments",
+				Service: hubv1alpha1.APIService{
+					Name:        "payments-external",
+					Port:        hubv1alpha1.APIServiceBackendPort{Number: 443},
+					ExternalURL: "https://payments.example.com",
+				},
+			},
+		},
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "conflict", Namespace: "apps"},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/conflict",
+				Service: hubv1alpha1.APIService{
+					Name:        "whoami",
+					Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+					ExternalURL: "http://whoami.example.com",
+				},
+			},
+		},
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "in-cluster", Namespace: "apps"},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/in-cluster",
+				Service: hubv1alpha1.APIService{
+					Name: "in-cluster",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+				},
+			},
+		},
+	}
+
+	services := []runtime.Object{
+		// A Service not managed by Hub, which must not be taken over.
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "whoami", Namespace: "apps"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+		},
+		// A Service managed for an API which no longer has an external URL.
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "stale",
+				Namespace: "apps",
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "traefik-hub",
+					LabelExternalAPI:               "stale",
+				},
+			},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "stale.example.com"},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	kubeClientSet := kubefake.NewSimpleClientset(services...)
+	hubInformer := hubinformers.NewSharedInformerFactory(kube.NewFakeHubClientset(apis...), 0)
+	hubInformer.Hub().V1alpha1().APIs().Informer()
+	hubInformer.Start(ctx.Done())
+	hubInformer.WaitForCacheSync(ctx.Done())
+
+	w := &WatcherGateway{
+		kubeClientSet: kubeClientSet,
+		hubInformer:   hubInformer,
+	}
+
+	require.NoError(t, w.syncExternalServices(ctx))
+
+	got, err := kubeClientSet.CoreV1().Services("apps").Get(ctx, "payments-external", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"app.kubernetes.io/managed-by": "traefik-hub",
+		LabelExternalAPI:               "payments",
+	}, got.Labels)
+	assert.Equal(t, map[string]string{
+		annotationServersScheme:  "https",
+		annotationPassHostHeader: "false",
+	}, got.Annotations)
+	assert.Equal(t, corev1.ServiceSpec{
+		Type:         corev1.ServiceTypeExternalName,
+		ExternalName: "payments.example.com",
+		Ports:        []corev1.ServicePort{{Name: "https", Protocol: corev1.ProtocolTCP, Port: 443}},
+	}, got.Spec)
+	require.Len(t, got.OwnerReferences, 1)
+	assert.Equal(t, "payments", got.OwnerReferences[0].Name)
+
+	whoami, err := kubeClientSet.CoreV1().Services("apps").Get(ctx, "whoami", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, whoami.Spec.Type)
+
+	_, err = kubeClientSet.CoreV1().Services("apps").Get(ctx, "stale", metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+
+	_, err = kubeClientSet.CoreV1().Services("apps").Get(ctx, "in-cluster", metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+}
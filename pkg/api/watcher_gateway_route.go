@@ -0,0 +1,1131 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/capture"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	"golang.org/x/exp/slices"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+// AnnotationDeprecatedAPI is set on the IngressRoutes exposing a deprecated API. Its value is the name of the API.
+const AnnotationDeprecatedAPI = "hub.traefik.io/deprecated-api"
+
+// AnnotationBodyLimit is set on the IngressRoutes limiting the size of the request bodies, so the requests rejected for
+// exceeding the limit can be counted.
+const AnnotationBodyLimit = "hub.traefik.io/body-limit"
+
+// HeaderSandbox flags requests which must be routed to the sandbox environment of an API, when it has one.
+// It is set by the portal's try-it console and on requests authenticated with a test API key.
+const HeaderSandbox = "X-Hub-Sandbox"
+
+// upsertedRoutes keeps track of the IngressRoutes and Middlewares upserted for an APIGateway in a namespace.
+type upsertedRoutes struct {
+	ingressRoutes map[string]struct{}
+	middlewares   map[string]struct{}
+}
+
+func newUpsertedRoutes() upsertedRoutes {
+	return upsertedRoutes{
+		ingressRoutes: make(map[string]struct{}),
+		middlewares:   make(map[string]struct{}),
+	}
+}
+
+// gatewayMiddlewares holds the Traefik names of the middlewares an APIGateway applies to the APIs of a namespace.
+type gatewayMiddlewares struct {
+	stripPrefix string
+	// bodyLimit limits the size of the request bodies, when the APIGateway has a limit.
+	bodyLimit string
+	// apiBodyLimits limit the size of the request bodies of the APIs having their own limit, by API name.
+	apiBodyLimits map[string]string
+	// apiAllowedMethods reject the requests using methods not allowed by the APIs restricting them, by API name.
+	apiAllowedMethods map[string]string
+	// apiRequestValidations reject the requests not matching the OpenAPI spec of the APIs validating them, by API name.
+	apiRequestValidations map[string]string
+	// apiCORS apply the CORS policies of the APIs having one, by API name.
+	apiCORS map[string]string
+	// apiRateLimits apply the APIRateLimits selecting the APIs, by access groups and API name.
+	apiRateLimits map[rateLimitedAPI][]string
+}
+
+// rateLimitedAPI identifies an API exposed to the given access groups.
+type rateLimitedAPI struct {
+	groups  string
+	apiName string
+}
+
+// names returns the middlewares applied to the APIs not having their own body limit.
+func (m gatewayMiddlewares) names() []string {
+	names := []string{m.stripPrefix}
+	if m.bodyLimit != "" {
+		names = append(names, m.bodyLimit)
+	}
+
+	return names
+}
+
+// refs returns the middlewares applied to the given API. The body limit of the API overrides the one of the
+// APIGateway. The CORS policy comes first, so preflight requests are answered before being authenticated.
+func (m gatewayMiddlewares) refs(apiName string) []traefikv1alpha1.MiddlewareRef {
+	refs := []traefikv1alpha1.MiddlewareRef{{Name: m.stripPrefix}}
+
+	if cors, ok := m.apiCORS[apiName]; ok {
+		refs = append(refs, traefikv1alpha1.MiddlewareRef{Name: cors})
+	}
+
+	if allowedMethods, ok := m.apiAllowedMethods[apiName]; ok {
+		refs = append(refs, traefikv1alpha1.MiddlewareRef{Name: allowedMethods})
+	}
+
+	if requestValidation, ok := m.apiRequestValidations[apiName]; ok {
+		refs = append(refs, traefikv1alpha1.MiddlewareRef{Name: requestValidation})
+	}
+
+	if bodyLimit, ok := m.apiBodyLimits[apiName]; ok {
+		return append(refs, traefikv1alpha1.MiddlewareRef{Name: bodyLimit})
+	}
+	if m.bodyLimit != "" {
+		refs = append(refs, traefikv1alpha1.MiddlewareRef{Name: m.bodyLimit})
+	}
+
+	return refs
+}
+
+// rateLimitRefs returns the rate limit middlewares applied to the given API when exposed to the given access groups.
+func (m gatewayMiddlewares) rateLimitRefs(groups, apiName string) []traefikv1alpha1.MiddlewareRef {
+	var refs []traefikv1alpha1.MiddlewareRef
+	for _, rateLimit := range m.apiRateLimits[rateLimitedAPI{groups: groups, apiName: apiName}] {
+		refs = append(refs, traefikv1alpha1.MiddlewareRef{Name: rateLimit})
+	}
+
+	return refs
+}
+
+// isRateLimited returns whether APIRateLimits apply to the given API when exposed to the given access groups.
+func (m gatewayMiddlewares) isRateLimited(groups, apiName string) bool {
+	return len(m.apiRateLimits[rateLimitedAPI{groups: groups, apiName: apiName}]) > 0
+}
+
+// hasBodyLimit returns whether the size of the request bodies of the given API is limited.
+func (m gatewayMiddlewares) hasBodyLimit(apiName string) bool {
+	_, ok := m.apiBodyLimits[apiName]
+
+	return ok || m.bodyLimit != ""
+}
+
+// setupBodyLimitMiddlewares upserts the buffering middlewares limiting the size of the request bodies accepted by the
+// APIGateway and by the APIs having their own limit, and registers them in the given gatewayMiddlewares.
+func (w *WatcherGateway) setupBodyLimitMiddlewares(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, resolvedAPIs []resolvedAPI, middlewares *gatewayMiddlewares, upserted upsertedRoutes) error {
+	if gateway.Spec.MaxRequestBodyBytes != nil {
+		middlewareName, err := getBodyLimitMiddlewareName(gateway.Name)
+		if err != nil {
+			return fmt.Errorf("get body limit middleware name: %w", err)
+		}
+
+		middleware := newBodyLimitMiddleware(middlewareName, namespace, *gateway.Spec.MaxRequestBodyBytes)
+		if err = w.upsertMiddleware(ctx, &middleware); err != nil {
+			return fmt.Errorf("upsert body limit middleware: %w", err)
+		}
+		upserted.middlewares[middlewareName] = struct{}{}
+
+		middlewares.bodyLimit = fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName)
+	}
+
+	middlewares.apiBodyLimits = make(map[string]string)
+	for _, a := range resolvedAPIs {
+		if a.api.Spec.MaxRequestBodyBytes == nil {
+			continue
+		}
+
+		middlewareName, err := getAPIBodyLimitMiddlewareName(gateway.Name, a.api.Name)
+		if err != nil {
+			return fmt.Errorf("get API body limit middleware name: %w", err)
+		}
+
+		middleware := newBodyLimitMiddleware(middlewareName, namespace, *a.api.Spec.MaxRequestBodyBytes)
+		if err = w.upsertMiddleware(ctx, &middleware); err != nil {
+			return fmt.Errorf("upsert API body limit middleware: %w", err)
+		}
+		upserted.middlewares[middlewareName] = struct{}{}
+
+		middlewares.apiBodyLimits[a.api.Name] = fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName)
+	}
+
+	return nil
+}
+
+// setupAllowedMethodsMiddlewares upserts the ForwardAuth middlewares rejecting the requests using methods not allowed
+// by the APIs restricting them, and registers them in the given gatewayMiddlewares.
+func (w *WatcherGateway) setupAllowedMethodsMiddlewares(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, resolvedAPIs []resolvedAPI, middlewares *gatewayMiddlewares, upserted upsertedRoutes) error {
+	middlewares.apiAllowedMethods = make(map[string]string)
+	for _, a := range resolvedAPIs {
+		if len(a.api.Spec.AllowedMethods) == 0 {
+			continue
+		}
+
+		middlewareName, err := getAPIAllowedMethodsMiddlewareName(gateway.Name, a.api.Name)
+		if err != nil {
+			return fmt.Errorf("get API allowed methods middleware name: %w", err)
+		}
+
+		middleware := newAllowedMethodsMiddleware(middlewareName, namespace, w.config.AuthServerAddress, a.api.Spec.AllowedMethods)
+		if err = w.upsertMiddleware(ctx, &middleware); err != nil {
+			return fmt.Errorf("upsert API allowed methods middleware: %w", err)
+		}
+		upserted.middlewares[middlewareName] = struct{}{}
+
+		middlewares.apiAllowedMethods[a.api.Name] = fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName)
+	}
+
+	return nil
+}
+
+// setupRequestValidationMiddlewares upserts the ForwardAuth middlewares rejecting the requests not matching the
+// OpenAPI spec of the APIs validating them, and registers them in the given gatewayMiddlewares.
+func (w *WatcherGateway) setupRequestValidationMiddlewares(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, resolvedAPIs []resolvedAPI, middlewares *gatewayMiddlewares, upserted upsertedRoutes) error {
+	middlewares.apiRequestValidations = make(map[string]string)
+	for _, a := range resolvedAPIs {
+		if !a.api.Spec.ValidateRequests {
+			continue
+		}
+
+		middlewareName, err := getAPIRequestValidationMiddlewareName(gateway.Name, a.api.Name)
+		if err != nil {
+			return fmt.Errorf("get API request validation middleware name: %w", err)
+		}
+
+		// Versions of an API are validated against the spec of their API.
+		apiName, _, _ := strings.Cut(a.api.Name, "/")
+
+		middleware := newRequestValidationMiddleware(middlewareName, namespace, w.config.AuthServerAddress, apiName+"@"+a.api.Namespace)
+		if err = w.upsertMiddleware(ctx, &middleware); err != nil {
+			return fmt.Errorf("upsert API request validation middleware: %w", err)
+		}
+		upserted.middlewares[middlewareName] = struct{}{}
+
+		middlewares.apiRequestValidations[a.api.Name] = fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName)
+	}
+
+	return nil
+}
+
+// setupCORSMiddlewares upserts the Headers middlewares applying the CORS policies of the APIs having one, and
+// registers them in the given gatewayMiddlewares.
+func (w *WatcherGateway) setupCORSMiddlewares(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, resolvedAPIs []resolvedAPI, middlewares *gatewayMiddlewares, upserted upsertedRoutes) error {
+	middlewares.apiCORS = make(map[string]string)
+	for _, a := range resolvedAPIs {
+		if a.api.Spec.CORS == nil {
+			continue
+		}
+
+		middlewareName, err := getAPICORSMiddlewareName(gateway.Name, a.api.Name)
+		if err != nil {
+			return fmt.Errorf("get API CORS middleware name: %w", err)
+		}
+
+		middleware := newCORSMiddleware(middlewareName, namespace, a.api.Spec.CORS)
+		if err = w.upsertMiddleware(ctx, &middleware); err != nil {
+			return fmt.Errorf("upsert API CORS middleware: %w", err)
+		}
+		upserted.middlewares[middlewareName] = struct{}{}
+
+		middlewares.apiCORS[a.api.Name] = fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName)
+	}
+
+	return nil
+}
+
+// setupRateLimitMiddlewares upserts the RateLimit middlewares of the APIRateLimits selecting the given APIs, and
+// registers them in the given gatewayMiddlewares. An APIRateLimit applies to an API exposed to access groups when it
+// targets everyone or one of these groups.
+func (w *WatcherGateway) setupRateLimitMiddlewares(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, resolvedAPIs []resolvedAPI, middlewares *gatewayMiddlewares, upserted upsertedRoutes) error {
+	middlewares.apiRateLimits = make(map[rateLimitedAPI][]string)
+	if !w.config.APIRateLimits {
+		return nil
+	}
+
+	rateLimits, err := w.hubInformer.Hub().V1alpha1().APIRateLimits().Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("list API rate limits: %w", err)
+	}
+	sort.Slice(rateLimits, func(i, j int) bool {
+		return rateLimits[i].Name < rateLimits[j].Name
+	})
+
+	for _, rateLimit := range rateLimits {
+		selector, err := metav1.LabelSelectorAsSelector(rateLimit.Spec.APISelector)
+		if err != nil {
+			log.Error().Err(err).
+				Str("name", rateLimit.Name).
+				Msg("Invalid APIRateLimit API selector")
+			continue
+		}
+
+		var middlewareRef string
+		for _, a := range resolvedAPIs {
+			if !selector.Matches(labels.Set(a.api.Labels)) || !rateLimitAppliesTo(rateLimit, a.groups) {
+				continue
+			}
+
+			if middlewareRef == "" {
+				middlewareName, err := getAPIRateLimitMiddlewareName(gateway.Name, rateLimit.Name)
+				if err != nil {
+					return fmt.Errorf("get API rate limit middleware name: %w", err)
+				}
+
+				middleware := newRateLimitMiddleware(middlewareName, namespace, rateLimit)
+				if err = w.upsertMiddleware(ctx, &middleware); err != nil {
+					return fmt.Errorf("upsert API rate limit middleware: %w", err)
+				}
+				upserted.middlewares[middlewareName] = struct{}{}
+
+				middlewareRef = fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName)
+			}
+
+			key := rateLimitedAPI{groups: a.groups, apiName: a.api.Name}
+			middlewares.apiRateLimits[key] = append(middlewares.apiRateLimits[key], middlewareRef)
+		}
+	}
+
+	return nil
+}
+
+// rateLimitAppliesTo returns whether the given APIRateLimit applies to the consumers of the given access groups.
+func rateLimitAppliesTo(rateLimit *hubv1alpha1.APIRateLimit, groups string) bool {
+	if rateLimit.Spec.Everyone {
+		return true
+	}
+
+	for _, group := range strings.Split(groups, ",") {
+		if slices.Contains(rateLimit.Spec.Groups, group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// upsertVersionedIngressRoutes exposes the APIs routed on a version header or on matchers through IngressRoutes, as
+// header and query matching cannot be expressed with Ingresses. Requests not matching them keep being routed by the
+// Ingresses, as Traefik gives a higher priority to the longer rules of the IngressRoutes.
+func (w *WatcherGateway) upsertVersionedIngressRoutes(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, groups string, apis []*hubv1alpha1.API, middlewares gatewayMiddlewares, upserted upsertedRoutes) error {
+	hubName, err := getHubDomainIngressName(gateway.Name, groups)
+	if err != nil {
+		return fmt.Errorf("get hub domain ingress name: %w", err)
+	}
+
+	customName, err := getCustomDomainsIngressName(gateway.Name, groups)
+	if err != nil {
+		return fmt.Errorf("get custom domains ingress name: %w", err)
+	}
+
+	tmpl := apiIngressRoute{
+		namespace:          namespace,
+		groups:             groups,
+		apis:               apis,
+		gatewayMiddlewares: middlewares,
+	}
+
+	return w.upsertAPIIngressRoutes(ctx, gateway, hubName, customName, tmpl, upserted)
+}
+
+// upsertDedicatedAPIIngressRoutes exposes an API through dedicated IngressRoutes, which is needed when:
+//   - the API is deprecated: the Deprecation and Sunset headers are added to its responses, and having dedicated
+//     IngressRoutes allows to track the traffic of deprecated APIs.
+//   - the API has capture enabled: its traffic is sent to the capture proxy, which forwards it to the API service.
+func (w *WatcherGateway) upsertDedicatedAPIIngressRoutes(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, groups string, api *hubv1alpha1.API, middlewares gatewayMiddlewares, upserted upsertedRoutes) error {
+	tmpl := apiIngressRoute{
+		namespace:          namespace,
+		groups:             groups,
+		apis:               []*hubv1alpha1.API{api},
+		gatewayMiddlewares: middlewares,
+		annotations:        make(map[string]string),
+	}
+
+	getName := getCapturedAPIIngressName
+
+	if api.Spec.Deprecation != nil {
+		middlewareName, err := getDeprecationMiddlewareName(gateway.Name, api.Name)
+		if err != nil {
+			return fmt.Errorf("get deprecation middleware name: %w", err)
+		}
+
+		middleware := newDeprecationMiddleware(middlewareName, namespace, api.Spec.Deprecation)
+		if err = w.upsertMiddleware(ctx, &middleware); err != nil {
+			return fmt.Errorf("upsert deprecation middleware: %w", err)
+		}
+		upserted.middlewares[middlewareName] = struct{}{}
+
+		tmpl.middlewares = append(tmpl.middlewares, traefikv1alpha1.MiddlewareRef{
+			Name: fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName),
+		})
+		tmpl.annotations[AnnotationDeprecatedAPI] = api.Name
+		getName = getDeprecatedAPIIngressName
+	}
+
+	if isCaptured(api) {
+		middlewareName, err := getCaptureMiddlewareName(gateway.Name, api.Name)
+		if err != nil {
+			return fmt.Errorf("get capture middleware name: %w", err)
+		}
+
+		middleware := newCaptureMiddleware(middlewareName, namespace, api)
+		if err = w.upsertMiddleware(ctx, &middleware); err != nil {
+			return fmt.Errorf("upsert capture middleware: %w", err)
+		}
+		upserted.middlewares[middlewareName] = struct{}{}
+
+		tmpl.middlewares = append(tmpl.middlewares, traefikv1alpha1.MiddlewareRef{
+			Name: fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName),
+		})
+		tmpl.service = &traefikv1alpha1.LoadBalancerSpec{
+			Name:      w.config.CaptureService.Name,
+			Namespace: w.config.CaptureService.Namespace,
+			Port:      intstr.FromInt(w.config.CaptureService.Port),
+		}
+	}
+
+	hubName, err := getHubDomainIngressName(gateway.Name, groups)
+	if err != nil {
+		return fmt.Errorf("get hub domain ingress name: %w", err)
+	}
+	if hubName, err = getName(hubName, api.Name); err != nil {
+		return fmt.Errorf("get dedicated API ingress name: %w", err)
+	}
+
+	customName, err := getCustomDomainsIngressName(gateway.Name, groups)
+	if err != nil {
+		return fmt.Errorf("get custom domains ingress name: %w", err)
+	}
+	if customName, err = getName(customName, api.Name); err != nil {
+		return fmt.Errorf("get dedicated API ingress name: %w", err)
+	}
+
+	return w.upsertAPIIngressRoutes(ctx, gateway, hubName, customName, tmpl, upserted)
+}
+
+// upsertSandboxIngressRoutes routes the requests flagged as sandbox requests to the sandbox service of the given APIs.
+// Other requests keep being routed by the Ingresses and IngressRoutes exposing the APIs, as Traefik gives a higher
+// priority to the longer rules of the sandbox IngressRoutes.
+func (w *WatcherGateway) upsertSandboxIngressRoutes(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, groups string, apis []*hubv1alpha1.API, middlewares gatewayMiddlewares, upserted upsertedRoutes) error {
+	hubName, err := getHubDomainIngressName(gateway.Name, groups)
+	if err != nil {
+		return fmt.Errorf("get hub domain ingress name: %w", err)
+	}
+
+	customName, err := getCustomDomainsIngressName(gateway.Name, groups)
+	if err != nil {
+		return fmt.Errorf("get custom domains ingress name: %w", err)
+	}
+
+	tmpl := apiIngressRoute{
+		namespace:          namespace,
+		groups:             groups,
+		apis:               apis,
+		gatewayMiddlewares: middlewares,
+		sandbox:            true,
+	}
+
+	return w.upsertAPIIngressRoutes(ctx, gateway, getSandboxIngressName(hubName), getSandboxIngressName(customName), tmpl, upserted)
+}
+
+// apiIngressRoute holds what is needed to build an IngressRoute exposing APIs.
+type apiIngressRoute struct {
+	namespace          string
+	groups             string
+	apis               []*hubv1alpha1.API
+	gatewayMiddlewares gatewayMiddlewares
+	// middlewares are applied after the ones of the APIGateway.
+	middlewares []traefikv1alpha1.MiddlewareRef
+	annotations map[string]string
+	// service overrides the service of the APIs when set.
+	service *traefikv1alpha1.LoadBalancerSpec
+	// sandbox routes the sandbox requests to the sandbox service of the APIs.
+	sandbox bool
+}
+
+func (w *WatcherGateway) upsertAPIIngressRoutes(ctx context.Context, gateway *hubv1alpha1.APIGateway, hubName, customName string, tmpl apiIngressRoute, upserted upsertedRoutes) error {
+	instance := w.traefikInstance(tmpl.namespace)
+
+	route := w.newAPIIngressRoute(hubName, gateway, tmpl, []string{gateway.Status.HubDomain})
+	route.Spec.EntryPoints = []string{instance.TunnelEntryPoint}
+	route.Spec.TLS = &traefikv1alpha1.TLS{SecretName: hubDomainSecretName}
+
+	if err := w.upsertIngressRoute(ctx, route); err != nil {
+		return fmt.Errorf("upsert ingress route for hub domain: %w", err)
+	}
+	upserted.ingressRoutes[hubName] = struct{}{}
+
+	if len(gateway.Status.CustomDomains) == 0 {
+		return nil
+	}
+
+	secretName, err := getCustomDomainSecretName(gateway.Name)
+	if err != nil {
+		return fmt.Errorf("get custom domains secret name: %w", err)
+	}
+
+	route = w.newAPIIngressRoute(customName, gateway, tmpl, gateway.Status.CustomDomains)
+	route.Spec.EntryPoints = []string{instance.APIEntryPoint}
+	route.Spec.TLS = &traefikv1alpha1.TLS{SecretName: secretName}
+
+	if err = w.upsertIngressRoute(ctx, route); err != nil {
+		return fmt.Errorf("upsert ingress route for custom domains: %w", err)
+	}
+	upserted.ingressRoutes[customName] = struct{}{}
+
+	return nil
+}
+
+func (w *WatcherGateway) newAPIIngressRoute(name string, gateway *hubv1alpha1.APIGateway, tmpl apiIngressRoute, hosts []string) *traefikv1alpha1.IngressRoute {
+	var bodyLimited bool
+	routes := make([]traefikv1alpha1.Route, 0, len(tmpl.apis))
+	for _, api := range tmpl.apis {
+		service := traefikv1alpha1.LoadBalancerSpec{
+			Name:      api.Spec.Service.Name,
+			Namespace: tmpl.namespace,
+			Port:      servicePort(api.Spec.Service.Port),
+		}
+		if api.Spec.Service.ExternalURL != "" {
+			// The IngressRoute provider ignores the annotations set on the external service.
+			// An invalid external URL is reported when syncing the external service, which won't exist.
+			if backend, err := parseExternalURL(api); err == nil {
+				service.Scheme = backend.scheme
+				service.PassHostHeader = pointer.Bool(false)
+			}
+		}
+		if tmpl.service != nil {
+			service = *tmpl.service
+		}
+
+		match := apiRouteMatch(hosts, api)
+		if tmpl.sandbox {
+			match = fmt.Sprintf("%s && Headers(`%s`, `true`)", match, HeaderSandbox)
+			service = traefikv1alpha1.LoadBalancerSpec{
+				Name:      api.Spec.Sandbox.Service.Name,
+				Namespace: tmpl.namespace,
+				Port:      servicePort(api.Spec.Sandbox.Service.Port),
+			}
+		}
+
+		if tmpl.gatewayMiddlewares.hasBodyLimit(api.Name) {
+			bodyLimited = true
+		}
+
+		middlewares := tmpl.gatewayMiddlewares.refs(api.Name)
+		middlewares = append(middlewares, tmpl.gatewayMiddlewares.rateLimitRefs(tmpl.groups, api.Name)...)
+		middlewares = append(middlewares, tmpl.middlewares...)
+
+		routes = append(routes, traefikv1alpha1.Route{
+			Match:       match,
+			Kind:        "Rule",
+			Services:    []traefikv1alpha1.Service{{LoadBalancerSpec: service}},
+			Middlewares: middlewares,
+		})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Match < routes[j].Match
+	})
+
+	annotations := map[string]string{
+		"kubernetes.io/ingress.class":   w.traefikInstance(tmpl.namespace).IngressClassName,
+		reviewer.AnnotationHubAuth:      "hub-api-management",
+		reviewer.AnnotationHubAuthGroup: tmpl.groups,
+	}
+	for key, value := range tmpl.annotations {
+		annotations[key] = value
+	}
+	if bodyLimited {
+		annotations[AnnotationBodyLimit] = "true"
+	}
+
+	return &traefikv1alpha1.IngressRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "IngressRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   tmpl.namespace,
+			Annotations: annotations,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+			// Set OwnerReference allow us to delete ingress routes owned by an APIGateway.
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: gateway.APIVersion,
+				Kind:       gateway.Kind,
+				Name:       gateway.Name,
+				UID:        gateway.UID,
+			}},
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			Routes: routes,
+		},
+	}
+}
+
+func (w *WatcherGateway) upsertIngressRoute(ctx context.Context, route *traefikv1alpha1.IngressRoute) error {
+	existingRoute, err := w.traefikClientSet.IngressRoutes(route.Namespace).Get(ctx, route.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get ingress route: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		_, err = w.traefikClientSet.IngressRoutes(route.Namespace).Create(ctx, route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("create ingress route: %w", err)
+		}
+
+		log.Debug().
+			Str("name", route.Name).
+			Str("namespace", route.Namespace).
+			Msg("IngressRoute created")
+
+		return nil
+	}
+
+	existingRoute.Spec = route.Spec
+	existingRoute.ObjectMeta.Annotations = route.ObjectMeta.Annotations
+	existingRoute.ObjectMeta.Labels = route.ObjectMeta.Labels
+
+	_, err = w.traefikClientSet.IngressRoutes(route.Namespace).Update(ctx, existingRoute, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update ingress route: %w", err)
+	}
+
+	log.Debug().
+		Str("name", route.Name).
+		Str("namespace", route.Namespace).
+		Msg("IngressRoute updated")
+
+	return nil
+}
+
+func (w *WatcherGateway) upsertMiddleware(ctx context.Context, middleware *traefikv1alpha1.Middleware) error {
+	existingMiddleware, err := w.traefikClientSet.Middlewares(middleware.Namespace).Get(ctx, middleware.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get middleware: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		_, err = w.traefikClientSet.Middlewares(middleware.Namespace).Create(ctx, middleware, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("create middleware: %w", err)
+		}
+
+		log.Debug().
+			Str("name", middleware.Name).
+			Str("namespace", middleware.Namespace).
+			Msg("Middleware created")
+
+		return nil
+	}
+
+	existingMiddleware.Spec = middleware.Spec
+	existingMiddleware.ObjectMeta.Labels = middleware.ObjectMeta.Labels
+
+	_, err = w.traefikClientSet.Middlewares(middleware.Namespace).Update(ctx, existingMiddleware, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update middleware: %w", err)
+	}
+
+	return nil
+}
+
+// cleanupIngressRoutes deletes the IngressRoutes and deprecation, capture and body limit Middlewares of the given
+// APIGateway which have not been upserted. When nothing has been upserted, all of them are deleted from the namespace.
+func (w *WatcherGateway) cleanupIngressRoutes(ctx context.Context, namespace string, gateway *hubv1alpha1.APIGateway, upserted upsertedRoutes) error {
+	ingressName, err := getIngressName(gateway.Name)
+	if err != nil {
+		return fmt.Errorf("get ingress name: %w", err)
+	}
+
+	listOpts := metav1.ListOptions{LabelSelector: "app.kubernetes.io/managed-by=traefik-hub"}
+
+	routes, err := w.traefikClientSet.IngressRoutes(namespace).List(ctx, listOpts)
+	if err != nil {
+		return fmt.Errorf("list ingress routes: %w", err)
+	}
+
+	for _, route := range routes.Items {
+		if !strings.HasPrefix(route.Name, ingressName) {
+			continue
+		}
+		if _, found := upserted.ingressRoutes[route.Name]; found {
+			continue
+		}
+
+		err = w.traefikClientSet.IngressRoutes(namespace).Delete(ctx, route.Name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			log.Error().Err(err).
+				Str("namespace", namespace).
+				Str("ingress_route_name", route.Name).
+				Msg("Unable to delete ingress route")
+		}
+	}
+
+	middlewares, err := w.traefikClientSet.Middlewares(namespace).List(ctx, listOpts)
+	if err != nil {
+		return fmt.Errorf("list middlewares: %w", err)
+	}
+
+	for _, middleware := range middlewares.Items {
+		if !strings.HasPrefix(middleware.Name, ingressName) || !isRouteMiddleware(middleware.Name) {
+			continue
+		}
+		if _, found := upserted.middlewares[middleware.Name]; found {
+			continue
+		}
+
+		err = w.traefikClientSet.Middlewares(namespace).Delete(ctx, middleware.Name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			log.Error().Err(err).
+				Str("namespace", namespace).
+				Str("middleware_name", middleware.Name).
+				Msg("Unable to delete middleware")
+		}
+	}
+
+	return nil
+}
+
+func newDeprecationMiddleware(name, namespace string, deprecation *hubv1alpha1.APIDeprecation) traefikv1alpha1.Middleware {
+	headers := map[string]string{
+		"Deprecation": "true",
+	}
+	if deprecation.Sunset != nil {
+		headers["Sunset"] = deprecation.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Middleware",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+		},
+		Spec: traefikv1alpha1.MiddlewareSpec{
+			Headers: &traefikv1alpha1.Headers{
+				CustomResponseHeaders: headers,
+			},
+		},
+	}
+}
+
+func newCaptureMiddleware(name, namespace string, api *hubv1alpha1.API) traefikv1alpha1.Middleware {
+	return traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Middleware",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+		},
+		Spec: traefikv1alpha1.MiddlewareSpec{
+			Headers: &traefikv1alpha1.Headers{
+				CustomRequestHeaders: map[string]string{
+					capture.HeaderAPI: api.Name + "@" + api.Namespace,
+				},
+			},
+		},
+	}
+}
+
+func isCaptured(api *hubv1alpha1.API) bool {
+	_, ok := capture.SampleRate(api)
+	return ok
+}
+
+func newBodyLimitMiddleware(name, namespace string, maxRequestBodyBytes int64) traefikv1alpha1.Middleware {
+	return traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Middleware",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+		},
+		Spec: traefikv1alpha1.MiddlewareSpec{
+			Buffering: &traefikv1alpha1.Buffering{
+				MaxRequestBodyBytes: maxRequestBodyBytes,
+			},
+		},
+	}
+}
+
+// newAllowedMethodsMiddleware returns a ForwardAuth middleware asking the auth server whether the method of the
+// requests is one of the given methods.
+func newAllowedMethodsMiddleware(name, namespace, authServerAddr string, methods []string) traefikv1alpha1.Middleware {
+	return traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Middleware",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+		},
+		Spec: traefikv1alpha1.MiddlewareSpec{
+			ForwardAuth: &traefikv1alpha1.ForwardAuth{
+				Address: authServerAddr + PathAllowedMethods + "?methods=" + url.QueryEscape(strings.Join(methods, ",")),
+			},
+		},
+	}
+}
+
+// newRequestValidationMiddleware returns a ForwardAuth middleware asking the auth server whether the requests match the
+// OpenAPI spec of the given API, identified by "name@namespace".
+func newRequestValidationMiddleware(name, namespace, authServerAddr, apiKey string) traefikv1alpha1.Middleware {
+	return traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Middleware",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+		},
+		Spec: traefikv1alpha1.MiddlewareSpec{
+			ForwardAuth: &traefikv1alpha1.ForwardAuth{
+				Address: authServerAddr + PathRequestValidation + "?api=" + url.QueryEscape(apiKey),
+			},
+		},
+	}
+}
+
+// newCORSMiddleware returns a Headers middleware applying the given CORS policy. The middleware has no effect when
+// the policy is nil.
+func newCORSMiddleware(name, namespace string, cors *hubv1alpha1.CORS) traefikv1alpha1.Middleware {
+	headers := &traefikv1alpha1.Headers{}
+	if cors != nil {
+		headers = &traefikv1alpha1.Headers{
+			AccessControlAllowOriginList:  cors.AllowOrigins,
+			AccessControlAllowMethods:     cors.AllowMethods,
+			AccessControlAllowHeaders:     cors.AllowHeaders,
+			AccessControlExposeHeaders:    cors.ExposeHeaders,
+			AccessControlAllowCredentials: cors.AllowCredentials,
+			AccessControlMaxAge:           cors.MaxAge,
+			// Responses depend on the Origin header when several origins are allowed.
+			AddVaryHeader: true,
+		}
+	}
+
+	return traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Middleware",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+		},
+		Spec: traefikv1alpha1.MiddlewareSpec{
+			Headers: headers,
+		},
+	}
+}
+
+// newRateLimitMiddleware returns a RateLimit middleware applying the given APIRateLimit. Consumers are told apart by
+// their Authorization header, as rate limits are applied before the consumers are authenticated.
+func newRateLimitMiddleware(name, namespace string, rateLimit *hubv1alpha1.APIRateLimit) traefikv1alpha1.Middleware {
+	period := time.Second
+	if rateLimit.Spec.Period != nil {
+		period = rateLimit.Spec.Period.Duration
+	}
+
+	sourceCriterion := &traefikv1alpha1.SourceCriterion{RequestHeaderName: "Authorization"}
+	if rateLimit.Spec.Strategy == hubv1alpha1.RateLimitStrategyShared {
+		sourceCriterion = &traefikv1alpha1.SourceCriterion{RequestHost: true}
+	}
+
+	return traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Middleware",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+		},
+		Spec: traefikv1alpha1.MiddlewareSpec{
+			RateLimit: &traefikv1alpha1.RateLimit{
+				Average:         rateLimit.Spec.Limit,
+				Period:          &intstr.IntOrString{Type: intstr.String, StrVal: period.String()},
+				Burst:           pointer.Int64(rateLimit.Spec.Limit),
+				SourceCriterion: sourceCriterion,
+			},
+		},
+	}
+}
+
+func isRouteMiddleware(name string) bool {
+	return strings.HasSuffix(name, "-deprecation") ||
+		strings.HasSuffix(name, "-capture") ||
+		strings.HasSuffix(name, "-body-limit") ||
+		strings.HasSuffix(name, "-allowed-methods") ||
+		strings.HasSuffix(name, "-request-validation") ||
+		strings.HasSuffix(name, "-cors") ||
+		strings.HasSuffix(name, "-rate-limit")
+}
+
+func apiRouteMatch(hosts []string, api *hubv1alpha1.API) string {
+	quotedHosts := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		quotedHosts = append(quotedHosts, "`"+host+"`")
+	}
+
+	match := fmt.Sprintf("Host(%s) && PathPrefix(`%s`)", strings.Join(quotedHosts, ", "), api.Spec.PathPrefix)
+
+	if api.Spec.VersionHeader != nil {
+		headerName := api.Spec.VersionHeader.Name
+		if headerName == "" {
+			headerName = "Accept-Version"
+		}
+
+		match = fmt.Sprintf("%s && Headers(`%s`, `%s`)", match, headerName, api.Spec.VersionHeader.Value)
+	}
+
+	if api.Spec.Matchers != nil {
+		for _, header := range api.Spec.Matchers.Headers {
+			match = fmt.Sprintf("%s && Headers(`%s`, `%s`)", match, header.Name, header.Value)
+		}
+		for _, query := range api.Spec.Matchers.Query {
+			match = fmt.Sprintf("%s && Query(`%s=%s`)", match, query.Name, query.Value)
+		}
+	}
+
+	return match
+}
+
+// hasMatchers returns whether the API is restricted to requests carrying given header values or query parameters.
+func hasMatchers(api *hubv1alpha1.API) bool {
+	return api.Spec.Matchers != nil && (len(api.Spec.Matchers.Headers) > 0 || len(api.Spec.Matchers.Query) > 0)
+}
+
+// hasOwnMiddlewares returns whether the given API needs middlewares of its own: a body limit, allowed methods, a
+// request validation or a CORS policy.
+func hasOwnMiddlewares(api *hubv1alpha1.API) bool {
+	return api.Spec.MaxRequestBodyBytes != nil || len(api.Spec.AllowedMethods) > 0 || api.Spec.ValidateRequests ||
+		api.Spec.CORS != nil
+}
+
+func servicePort(port hubv1alpha1.APIServiceBackendPort) intstr.IntOrString {
+	if port.Name != "" {
+		return intstr.FromString(port.Name)
+	}
+
+	return intstr.FromInt(int(port.Number))
+}
+
+// getDeprecatedAPIIngressName compute the name of the IngressRoute exposing a deprecated API.
+// The name follow this format: {ingress-name}-{hash(api-name)}-deprecated
+func getDeprecatedAPIIngressName(ingressName, apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-deprecated", ingressName, h), nil
+}
+
+// getDeprecationMiddlewareName compute the name of the middleware adding the deprecation headers of an API.
+// The name follow this format: {gateway-name}-{hash(gateway-name)}-{hash(api-name)}-deprecation
+func getDeprecationMiddlewareName(gatewayName, apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := getIngressName(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-deprecation", name, h), nil
+}
+
+// getCapturedAPIIngressName compute the name of the IngressRoute exposing an API having capture enabled.
+// The name follow this format: {ingress-name}-{hash(api-name)}-captured
+func getCapturedAPIIngressName(ingressName, apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-captured", ingressName, h), nil
+}
+
+// getCaptureMiddlewareName compute the name of the middleware flagging the requests sent to the capture proxy.
+// The name follow this format: {gateway-name}-{hash(gateway-name)}-{hash(api-name)}-capture
+func getCaptureMiddlewareName(gatewayName, apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := getIngressName(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-capture", name, h), nil
+}
+
+// getBodyLimitMiddlewareName compute the name of the middleware limiting the size of the request bodies accepted by
+// an APIGateway.
+// The name follow this format: {gateway-name}-{hash(gateway-name)}-body-limit
+func getBodyLimitMiddlewareName(gatewayName string) (string, error) {
+	name, err := getIngressName(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return name + "-body-limit", nil
+}
+
+// getAPIBodyLimitMiddlewareName compute the name of the middleware limiting the size of the request bodies accepted by
+// an API.
+// The name follow this format: {gateway-name}-{hash(gateway-name)}-{hash(api-name)}-body-limit
+func getAPIBodyLimitMiddlewareName(gatewayName, apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := getIngressName(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-body-limit", name, h), nil
+}
+
+// getAPIAllowedMethodsMiddlewareName compute the name of the middleware rejecting the requests using methods not
+// allowed by an API.
+// The name follow this format: {gateway-name}-{hash(gateway-name)}-{hash(api-name)}-allowed-methods
+func getAPIAllowedMethodsMiddlewareName(gatewayName, apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := getIngressName(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-allowed-methods", name, h), nil
+}
+
+// getAPIRequestValidationMiddlewareName compute the name of the middleware rejecting the requests not matching the
+// OpenAPI spec of an API.
+// The name follow this format: {gateway-name}-{hash(gateway-name)}-{hash(api-name)}-request-validation
+func getAPIRequestValidationMiddlewareName(gatewayName, apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := getIngressName(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-request-validation", name, h), nil
+}
+
+// getAPICORSMiddlewareName compute the name of the middleware applying the CORS policy of an API.
+// The name follow this format: {gateway-name}-{hash(gateway-name)}-{hash(api-name)}-cors
+func getAPICORSMiddlewareName(gatewayName, apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := getIngressName(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-cors", name, h), nil
+}
+
+// getAPIRateLimitMiddlewareName compute the name of the middleware applying an APIRateLimit.
+// The name follow this format: {gateway-name}-{hash(gateway-name)}-{hash(rate-limit-name)}-rate-limit
+func getAPIRateLimitMiddlewareName(gatewayName, rateLimitName string) (string, error) {
+	h, err := hash(rateLimitName)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := getIngressName(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-rate-limit", name, h), nil
+}
+
+// getSandboxIngressName compute the name of the IngressRoute routing sandbox requests to the sandbox services of APIs.
+// The name follow this format: {ingress-name}-sandbox
+func getSandboxIngressName(ingressName string) string {
+	return ingressName + "-sandbox"
+}
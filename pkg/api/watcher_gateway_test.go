@@ -40,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	kinformers "k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 )
 
 func Test_WatcherGatewayRun(t *testing.T) {
@@ -62,14 +63,17 @@ func Test_WatcherGatewayRun(t *testing.T) {
 		clusterAccesses    string
 		clusterCollections string
 		clusterAPIs        string
+		clusterRateLimits  string
+		clusterAPIVersions string
 		clusterIngresses   string
 		clusterSecrets     string
 		clusterMiddlewares string
 
-		wantGateways    string
-		wantIngresses   string
-		wantSecrets     string
-		wantMiddlewares string
+		wantGateways      string
+		wantIngresses     string
+		wantIngressRoutes string
+		wantSecrets       string
+		wantMiddlewares   string
 	}{
 		{
 			desc: "new gateway present on the platform needs to be created on the cluster",
@@ -172,6 +176,194 @@ func Test_WatcherGatewayRun(t *testing.T) {
 			wantSecrets:        "testdata/remove-api-from-gateway/want.secrets.yaml",
 			wantMiddlewares:    "testdata/remove-api-from-gateway/want.middlewares.yaml",
 		},
+		{
+			desc: "APIs versioned by header or restricted by matchers are exposed with ingress routes",
+			platformGateways: []Gateway{
+				{
+					Name:      "versioned-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+					CustomDomains: []CustomDomain{
+						{Name: "api.hello.example.com", Verified: true},
+					},
+				},
+			},
+			clusterAccesses:   "testdata/versioned-api/accesses.yaml",
+			clusterAPIs:       "testdata/versioned-api/apis.yaml",
+			wantGateways:      "testdata/versioned-api/want.gateways.yaml",
+			wantIngresses:     "testdata/versioned-api/want.ingresses.yaml",
+			wantIngressRoutes: "testdata/versioned-api/want.ingressroutes.yaml",
+			wantSecrets:       "testdata/versioned-api/want.secrets.yaml",
+			wantMiddlewares:   "testdata/versioned-api/want.middlewares.yaml",
+		},
+		{
+			desc: "deprecated APIs are exposed with dedicated ingress routes",
+			platformGateways: []Gateway{
+				{
+					Name:      "deprecated-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+				},
+			},
+			clusterAccesses:    "testdata/deprecated-api/accesses.yaml",
+			clusterAPIs:        "testdata/deprecated-api/apis.yaml",
+			clusterMiddlewares: "testdata/deprecated-api/middlewares.yaml",
+			wantGateways:       "testdata/deprecated-api/want.gateways.yaml",
+			wantIngresses:      "testdata/deprecated-api/want.ingresses.yaml",
+			wantIngressRoutes:  "testdata/deprecated-api/want.ingressroutes.yaml",
+			wantSecrets:        "testdata/deprecated-api/want.secrets.yaml",
+			wantMiddlewares:    "testdata/deprecated-api/want.middlewares.yaml",
+		},
+		{
+			desc: "sandbox requests are routed to the sandbox service of APIs",
+			platformGateways: []Gateway{
+				{
+					Name:      "sandbox-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+				},
+			},
+			clusterAccesses:   "testdata/sandbox-api/accesses.yaml",
+			clusterAPIs:       "testdata/sandbox-api/apis.yaml",
+			wantGateways:      "testdata/sandbox-api/want.gateways.yaml",
+			wantIngresses:     "testdata/sandbox-api/want.ingresses.yaml",
+			wantIngressRoutes: "testdata/sandbox-api/want.ingressroutes.yaml",
+			wantSecrets:       "testdata/sandbox-api/want.secrets.yaml",
+			wantMiddlewares:   "testdata/sandbox-api/want.middlewares.yaml",
+		},
+		{
+			desc: "APIs having capture enabled are routed to the capture proxy",
+			platformGateways: []Gateway{
+				{
+					Name:      "captured-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+				},
+			},
+			clusterAccesses:    "testdata/captured-api/accesses.yaml",
+			clusterAPIs:        "testdata/captured-api/apis.yaml",
+			clusterMiddlewares: "testdata/captured-api/middlewares.yaml",
+			wantGateways:       "testdata/captured-api/want.gateways.yaml",
+			wantIngresses:      "testdata/captured-api/want.ingresses.yaml",
+			wantIngressRoutes:  "testdata/captured-api/want.ingressroutes.yaml",
+			wantSecrets:        "testdata/captured-api/want.secrets.yaml",
+			wantMiddlewares:    "testdata/captured-api/want.middlewares.yaml",
+		},
+		{
+			desc: "request bodies are limited by the gateway unless APIs have their own limit",
+			platformGateways: []Gateway{
+				{
+					Name:                "limited-gateway",
+					Accesses:            []string{"supply-chain"},
+					Version:             "version-1",
+					HubDomain:           "brave-lion-123.hub-traefik.io",
+					MaxRequestBodyBytes: pointer.Int64(1048576),
+				},
+			},
+			clusterAccesses:   "testdata/body-limit-api/accesses.yaml",
+			clusterAPIs:       "testdata/body-limit-api/apis.yaml",
+			wantGateways:      "testdata/body-limit-api/want.gateways.yaml",
+			wantIngresses:     "testdata/body-limit-api/want.ingresses.yaml",
+			wantIngressRoutes: "testdata/body-limit-api/want.ingressroutes.yaml",
+			wantSecrets:       "testdata/body-limit-api/want.secrets.yaml",
+			wantMiddlewares:   "testdata/body-limit-api/want.middlewares.yaml",
+		},
+		{
+			desc: "requests using methods not allowed by APIs are rejected",
+			platformGateways: []Gateway{
+				{
+					Name:      "restricted-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+				},
+			},
+			clusterAccesses:   "testdata/allowed-methods-api/accesses.yaml",
+			clusterAPIs:       "testdata/allowed-methods-api/apis.yaml",
+			wantGateways:      "testdata/allowed-methods-api/want.gateways.yaml",
+			wantIngresses:     "testdata/allowed-methods-api/want.ingresses.yaml",
+			wantIngressRoutes: "testdata/allowed-methods-api/want.ingressroutes.yaml",
+			wantSecrets:       "testdata/allowed-methods-api/want.secrets.yaml",
+			wantMiddlewares:   "testdata/allowed-methods-api/want.middlewares.yaml",
+		},
+		{
+			desc: "requests of APIs validating them are checked against their OpenAPI spec",
+			platformGateways: []Gateway{
+				{
+					Name:      "restricted-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+				},
+			},
+			clusterAccesses:   "testdata/request-validation-api/accesses.yaml",
+			clusterAPIs:       "testdata/request-validation-api/apis.yaml",
+			wantGateways:      "testdata/request-validation-api/want.gateways.yaml",
+			wantIngresses:     "testdata/request-validation-api/want.ingresses.yaml",
+			wantIngressRoutes: "testdata/request-validation-api/want.ingressroutes.yaml",
+			wantSecrets:       "testdata/request-validation-api/want.secrets.yaml",
+			wantMiddlewares:   "testdata/request-validation-api/want.middlewares.yaml",
+		},
+		{
+			desc: "CORS requests are answered according to the policy of the APIs",
+			platformGateways: []Gateway{
+				{
+					Name:      "cors-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+				},
+			},
+			clusterAccesses:   "testdata/cors-api/accesses.yaml",
+			clusterAPIs:       "testdata/cors-api/apis.yaml",
+			wantGateways:      "testdata/cors-api/want.gateways.yaml",
+			wantIngresses:     "testdata/cors-api/want.ingresses.yaml",
+			wantIngressRoutes: "testdata/cors-api/want.ingressroutes.yaml",
+			wantSecrets:       "testdata/cors-api/want.secrets.yaml",
+			wantMiddlewares:   "testdata/cors-api/want.middlewares.yaml",
+		},
+		{
+			desc: "APIs are rate limited by the APIRateLimits applying to their access groups",
+			platformGateways: []Gateway{
+				{
+					Name:      "rate-limit-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+				},
+			},
+			clusterAccesses:   "testdata/rate-limit-api/accesses.yaml",
+			clusterAPIs:       "testdata/rate-limit-api/apis.yaml",
+			clusterRateLimits: "testdata/rate-limit-api/ratelimits.yaml",
+			wantGateways:      "testdata/rate-limit-api/want.gateways.yaml",
+			wantIngresses:     "testdata/rate-limit-api/want.ingresses.yaml",
+			wantIngressRoutes: "testdata/rate-limit-api/want.ingressroutes.yaml",
+			wantSecrets:       "testdata/rate-limit-api/want.secrets.yaml",
+			wantMiddlewares:   "testdata/rate-limit-api/want.middlewares.yaml",
+		},
+		{
+			desc: "versions of APIs are exposed on their own path prefix or header",
+			platformGateways: []Gateway{
+				{
+					Name:      "versions-gateway",
+					Accesses:  []string{"supply-chain"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+				},
+			},
+			clusterAccesses:    "testdata/api-versions/accesses.yaml",
+			clusterAPIs:        "testdata/api-versions/apis.yaml",
+			clusterAPIVersions: "testdata/api-versions/apiversions.yaml",
+			wantGateways:       "testdata/api-versions/want.gateways.yaml",
+			wantIngresses:      "testdata/api-versions/want.ingresses.yaml",
+			wantIngressRoutes:  "testdata/api-versions/want.ingressroutes.yaml",
+			wantSecrets:        "testdata/api-versions/want.secrets.yaml",
+			wantMiddlewares:    "testdata/api-versions/want.middlewares.yaml",
+		},
 		{
 			desc:             "deleted gateway on the platform needs to be deleted on the cluster",
 			platformGateways: []Gateway{},
@@ -191,11 +383,14 @@ func Test_WatcherGatewayRun(t *testing.T) {
 			wantIngresses := loadFixtures[netv1.Ingress](t, test.wantIngresses)
 			wantSecrets := loadFixtures[corev1.Secret](t, test.wantSecrets)
 			wantMiddlewares := loadFixtures[traefikv1alpha1.Middleware](t, test.wantMiddlewares)
+			wantIngressRoutes := loadFixtures[traefikv1alpha1.IngressRoute](t, test.wantIngressRoutes)
 
 			clusterGateways := loadFixtures[hubv1alpha1.APIGateway](t, test.clusterGateways)
 			clusterAccesses := loadFixtures[hubv1alpha1.APIAccess](t, test.clusterAccesses)
 			clusterCollections := loadFixtures[hubv1alpha1.APICollection](t, test.clusterCollections)
 			clusterAPIs := loadFixtures[hubv1alpha1.API](t, test.clusterAPIs)
+			clusterRateLimits := loadFixtures[hubv1alpha1.APIRateLimit](t, test.clusterRateLimits)
+			clusterAPIVersions := loadFixtures[hubv1alpha1.APIVersion](t, test.clusterAPIVersions)
 			clusterIngresses := loadFixtures[netv1.Ingress](t, test.clusterIngresses)
 			clusterSecrets := loadFixtures[corev1.Secret](t, test.clusterSecrets)
 			clusterMiddlewares := loadFixtures[traefikv1alpha1.Middleware](t, test.clusterMiddlewares)
@@ -222,6 +417,12 @@ func Test_WatcherGatewayRun(t *testing.T) {
 			for _, clusterAPI := range clusterAPIs {
 				hubObjects = append(hubObjects, clusterAPI.DeepCopy())
 			}
+			for _, clusterRateLimit := range clusterRateLimits {
+				hubObjects = append(hubObjects, clusterRateLimit.DeepCopy())
+			}
+			for _, clusterAPIVersion := range clusterAPIVersions {
+				hubObjects = append(hubObjects, clusterAPIVersion.DeepCopy())
+			}
 
 			var traefikObjects []runtime.Object
 			for _, clusterMiddleware := range clusterMiddlewares {
@@ -242,6 +443,8 @@ func Test_WatcherGatewayRun(t *testing.T) {
 			hubInformer.Hub().V1alpha1().APIAccesses().Informer()
 			hubInformer.Hub().V1alpha1().APICollections().Informer()
 			hubInformer.Hub().V1alpha1().APIs().Informer()
+			hubInformer.Hub().V1alpha1().APIRateLimits().Informer()
+			hubInformer.Hub().V1alpha1().APIVersions().Informer()
 
 			hubInformer.Start(ctx.Done())
 			kubeInformer.Start(ctx.Done())
@@ -301,7 +504,15 @@ func Test_WatcherGatewayRun(t *testing.T) {
 				AgentNamespace:          "agent-ns",
 				TraefikAPIEntryPoint:    "api-entrypoint",
 				TraefikTunnelEntryPoint: "tunnel-entrypoint",
-				GatewaySyncInterval:     time.Millisecond,
+				AuthServerAddress:       "http://hub-agent-auth-server.agent-ns:80",
+				APIRateLimits:           true,
+				APIVersions:             true,
+				CaptureService: CaptureServiceConfig{
+					Name:      "hub-agent-auth-server",
+					Namespace: "agent-ns",
+					Port:      8080,
+				},
+				GatewaySyncInterval: time.Millisecond,
 				// we don't want to test certSync here.
 				CertSyncInterval:  10 * time.Second,
 				CertRetryInterval: time.Millisecond,
@@ -313,16 +524,13 @@ func Test_WatcherGatewayRun(t *testing.T) {
 				close(stop)
 			}()
 
-			select {
-			case <-ctx.Done():
-				t.Log(ctx.Err())
-			case <-stop:
-			}
+			<-stop
 
 			assertGatewaysMatches(t, hubClientSet, wantGateways)
 			assertSecretsMatches(t, kubeClientSet, namespaces, wantSecrets)
 			assertIngressesMatches(t, kubeClientSet, namespaces, wantIngresses)
 			assertMiddlewaresMatches(t, traefikClientSet, namespaces, wantMiddlewares)
+			assertIngressRoutesMatches(t, traefikClientSet, namespaces, wantIngressRoutes)
 		})
 	}
 }
@@ -343,6 +551,9 @@ func assertGatewaysMatches(t *testing.T, hubClientSet *hubfake.Clientset, want [
 	var gateways []hubv1alpha1.APIGateway
 	for _, gateway := range gatewayList.Items {
 		gateway.Status.SyncedAt = metav1.Time{}
+		for i := range gateway.Status.Conditions {
+			gateway.Status.Conditions[i].LastTransitionTime = metav1.Time{}
+		}
 
 		gateways = append(gateways, gateway)
 	}
@@ -432,3 +643,28 @@ func assertMiddlewaresMatches(t *testing.T, traefikClientSet *traefikcrdfake.Cli
 
 	assert.Equal(t, want, middlewares)
 }
+
+func assertIngressRoutesMatches(t *testing.T, traefikClientSet *traefikcrdfake.Clientset, namespaces []string, want []traefikv1alpha1.IngressRoute) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	sort.Slice(want, func(i, j int) bool {
+		return want[i].Name < want[j].Name
+	})
+
+	var routes []traefikv1alpha1.IngressRoute
+	for _, namespace := range namespaces {
+		namespaceRouteList, err := traefikClientSet.TraefikV1alpha1().IngressRoutes(namespace).List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+
+		routes = append(routes, namespaceRouteList.Items...)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Name < routes[j].Name
+	})
+
+	assert.Equal(t, want, routes)
+}
@@ -30,6 +30,7 @@ import (
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/scheme"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
@@ -84,11 +85,13 @@ type WatcherPortal struct {
 	hubClientSet hubclientset.Interface
 	hubInformer  hubinformers.SharedInformerFactory
 
+	traefikClientSet v1alpha1.TraefikV1alpha1Interface
+
 	eventRecorder record.EventRecorder
 }
 
 // NewWatcherPortal returns a new WatcherPortal.
-func NewWatcherPortal(client PlatformClient, kubeClientSet kclientset.Interface, kubeInformer kinformers.SharedInformerFactory, hubClientSet hubclientset.Interface, hubInformer hubinformers.SharedInformerFactory, config *WatcherPortalConfig) *WatcherPortal {
+func NewWatcherPortal(client PlatformClient, kubeClientSet kclientset.Interface, kubeInformer kinformers.SharedInformerFactory, hubClientSet hubclientset.Interface, hubInformer hubinformers.SharedInformerFactory, traefikClientSet v1alpha1.TraefikV1alpha1Interface, config *WatcherPortalConfig) *WatcherPortal {
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartRecordingToSink(&v1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
 	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{})
@@ -104,6 +107,8 @@ func NewWatcherPortal(client PlatformClient, kubeClientSet kclientset.Interface,
 		hubClientSet: hubClientSet,
 		hubInformer:  hubInformer,
 
+		traefikClientSet: traefikClientSet,
+
 		eventRecorder: eventRecorder,
 	}
 }
@@ -178,7 +183,7 @@ func (w *WatcherPortal) setupCertificates(ctx context.Context, portal *hubv1alph
 
 	upserted, err := w.upsertCertificateSecret(ctx, cert, portal, secretName)
 	if err != nil {
-		w.eventRecorder.Eventf(portal, "Failed", "CertificateSyncing", "Unable to sync certificate for [%s] with the Hub platform: %s", strings.Join(portal.Status.CustomDomains, ", "), err)
+		w.eventRecorder.Eventf(portal, corev1.EventTypeWarning, "CertificateSyncFailed", "Unable to sync certificate for [%s] with the Hub platform: %s", strings.Join(portal.Status.CustomDomains, ", "), err)
 		return fmt.Errorf("upsert certificate secret: %w", err)
 	}
 	if upserted {
@@ -244,7 +249,6 @@ func (w *WatcherPortal) syncPortals(ctx context.Context) {
 func (w *WatcherPortal) createPortal(ctx context.Context, portal *hubv1alpha1.APIPortal, hubACPConfig OIDCConfig) error {
 	createdPortal, err := w.hubClientSet.HubV1alpha1().APIPortals().Create(ctx, portal, metav1.CreateOptions{})
 	if err != nil {
-		w.eventRecorder.Eventf(portal, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
 		return fmt.Errorf("creating APIPortal: %w", err)
 	}
 
@@ -252,18 +256,20 @@ func (w *WatcherPortal) createPortal(ctx context.Context, portal *hubv1alpha1.AP
 		Str("name", createdPortal.Name).
 		Msg("APIPortal created")
 
-	w.eventRecorder.Event(createdPortal, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+	w.eventRecorder.Event(createdPortal, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
 
 	return w.syncChildResources(ctx, createdPortal, hubACPConfig)
 }
 
 func (w *WatcherPortal) updatePortal(ctx context.Context, oldPortal, newPortal *hubv1alpha1.APIPortal, hubACPConfig OIDCConfig) error {
 	newPortal.ObjectMeta = oldPortal.ObjectMeta
+	newPortal.Status.Conditions = hubv1alpha1.MergeConditions(oldPortal.Status.Conditions, newPortal.Status.Conditions...)
 
-	if newPortal.Status.Version != oldPortal.Status.Version {
+	clusterPortal := oldPortal
+	if newPortal.Status.Version != oldPortal.Status.Version || !isSynced(oldPortal.Status.Conditions) {
 		updatedPortal, err := w.hubClientSet.HubV1alpha1().APIPortals().Update(ctx, newPortal, metav1.UpdateOptions{})
 		if err != nil {
-			w.eventRecorder.Eventf(newPortal, "Failed", "Syncing", "Unable to synchronize with the Hub platform: %s", err)
+			w.eventRecorder.Eventf(newPortal, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
 			return fmt.Errorf("updating APIPortal: %w", err)
 		}
 
@@ -271,10 +277,12 @@ func (w *WatcherPortal) updatePortal(ctx context.Context, oldPortal, newPortal *
 			Str("name", updatedPortal.Name).
 			Msg("APIPortal updated")
 
-		w.eventRecorder.Event(updatedPortal, corev1.EventTypeNormal, "Synced", "Synced successfully with the Hub platform")
+		w.eventRecorder.Event(updatedPortal, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
+
+		clusterPortal = updatedPortal
 	}
 
-	return w.syncChildResources(ctx, newPortal, hubACPConfig)
+	return w.syncChildResources(ctx, clusterPortal, hubACPConfig)
 }
 
 func (w *WatcherPortal) cleanPortals(ctx context.Context, portals map[string]*hubv1alpha1.APIPortal) {
@@ -301,28 +309,78 @@ func (w *WatcherPortal) cleanPortals(ctx context.Context, portals map[string]*hu
 func (w *WatcherPortal) syncChildResources(ctx context.Context, portal *hubv1alpha1.APIPortal, hubACPConfig OIDCConfig) error {
 	acp, err := w.upsertPortalACP(ctx, portal, hubACPConfig)
 	if err != nil {
+		w.setPortalConditions(ctx, portal, notReadyCondition(hubv1alpha1.ReasonRoutingFailed, err.Error()))
+
 		return fmt.Errorf("upsert portal ACP: %w", err)
 	}
 
-	if err = w.upsertPortalEdgeIngress(ctx, portal, acp.Name); err != nil {
+	corsMiddleware, err := w.upsertPortalCORSMiddleware(ctx, portal)
+	if err != nil {
+		w.setPortalConditions(ctx, portal, notReadyCondition(hubv1alpha1.ReasonRoutingFailed, err.Error()))
+
+		return fmt.Errorf("upsert portal CORS middleware: %w", err)
+	}
+
+	if err = w.upsertPortalEdgeIngress(ctx, portal, acp.Name, corsMiddleware); err != nil {
+		w.setPortalConditions(ctx, portal, notReadyCondition(hubv1alpha1.ReasonRoutingFailed, err.Error()))
+
 		return fmt.Errorf("upsert portal edge ingress: %w", err)
 	}
 
+	// The certificate of the hub domain is provisioned by the portal EdgeIngress.
 	if len(portal.Status.CustomDomains) == 0 {
+		w.setPortalConditions(ctx, portal, certificateProvisionedCondition(nil), readyCondition(metav1.Time{}))
+
 		return nil
 	}
 
 	if err = w.setupCertificates(ctx, portal); err != nil {
+		w.setPortalConditions(ctx, portal,
+			certificateProvisionedCondition(err),
+			notReadyCondition(hubv1alpha1.ReasonCertificateProvisioningFailed, "Certificates are not provisioned"),
+		)
+
 		return fmt.Errorf("setup certificate: %w", err)
 	}
 
-	if err = w.upsertPortalIngress(ctx, portal, acp.Name); err != nil {
+	if err = w.upsertPortalIngress(ctx, portal, acp.Name, corsMiddleware); err != nil {
+		w.setPortalConditions(ctx, portal,
+			certificateProvisionedCondition(nil),
+			notReadyCondition(hubv1alpha1.ReasonRoutingFailed, err.Error()),
+		)
+
 		return fmt.Errorf("upsert portal ingress: %w", err)
 	}
 
+	w.setPortalConditions(ctx, portal, certificateProvisionedCondition(nil), readyCondition(metav1.Time{}))
+
 	return nil
 }
 
+// setPortalConditions sets the given conditions on the APIPortal status.
+// Only the conditions are patched, leaving the rest of the status untouched. Failing to do so is only logged, as
+// conditions are informative.
+func (w *WatcherPortal) setPortalConditions(ctx context.Context, portal *hubv1alpha1.APIPortal, conditions ...metav1.Condition) {
+	portal = portal.DeepCopy()
+	if !hubv1alpha1.SetConditions(&portal.Status.Conditions, conditions...) {
+		return
+	}
+
+	patch, err := conditionsPatch(portal.Status.Conditions)
+	if err != nil {
+		log.Error().Err(err).
+			Str("name", portal.Name).
+			Msg("Unable to build APIPortal conditions patch")
+		return
+	}
+
+	if _, err = w.hubClientSet.HubV1alpha1().APIPortals().Patch(ctx, portal.Name, ktypes.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		log.Error().Err(err).
+			Str("name", portal.Name).
+			Msg("Unable to patch APIPortal conditions")
+	}
+}
+
 func (w *WatcherPortal) upsertPortalACP(ctx context.Context, portal *hubv1alpha1.APIPortal, hubACPConfig OIDCConfig) (*hubv1alpha1.AccessControlPolicy, error) {
 	acpName, err := getACPPortalName(portal.Name)
 	if err != nil {
@@ -467,7 +525,53 @@ func (w *WatcherPortal) upsertACPSecret(ctx context.Context, portal *hubv1alpha1
 	return nil
 }
 
-func (w *WatcherPortal) upsertPortalEdgeIngress(ctx context.Context, portal *hubv1alpha1.APIPortal, acpName string) error {
+// upsertPortalCORSMiddleware upserts the Headers middleware holding the CORS policy of the given portal and returns its
+// reference. The middleware always exists, without any header when the portal has no CORS policy, so that the routes
+// referencing it don't need to be updated when the policy changes.
+func (w *WatcherPortal) upsertPortalCORSMiddleware(ctx context.Context, portal *hubv1alpha1.APIPortal) (string, error) {
+	name, err := getPortalCORSMiddlewareName(portal.Name)
+	if err != nil {
+		return "", fmt.Errorf("get CORS middleware name: %w", err)
+	}
+
+	middleware := newCORSMiddleware(name, w.config.AgentNamespace, portal.Spec.CORS)
+	// Set OwnerReference allow us to delete Middlewares owned by an APIPortal.
+	middleware.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: portal.APIVersion,
+			Kind:       portal.Kind,
+			Name:       portal.Name,
+			UID:        portal.UID,
+		},
+	}
+
+	existingMiddleware, err := w.traefikClientSet.Middlewares(w.config.AgentNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return "", fmt.Errorf("get middleware: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		if _, err = w.traefikClientSet.Middlewares(w.config.AgentNamespace).Create(ctx, &middleware, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("create middleware: %w", err)
+		}
+
+		log.Debug().
+			Str("name", name).
+			Str("namespace", w.config.AgentNamespace).
+			Msg("Middleware created")
+	} else {
+		existingMiddleware.Spec = middleware.Spec
+		existingMiddleware.ObjectMeta.Labels = middleware.ObjectMeta.Labels
+
+		if _, err = w.traefikClientSet.Middlewares(w.config.AgentNamespace).Update(ctx, existingMiddleware, metav1.UpdateOptions{}); err != nil {
+			return "", fmt.Errorf("update middleware: %w", err)
+		}
+	}
+
+	return w.config.AgentNamespace + "-" + name + "@kubernetescrd", nil
+}
+
+func (w *WatcherPortal) upsertPortalEdgeIngress(ctx context.Context, portal *hubv1alpha1.APIPortal, acpName, middlewares string) error {
 	ingName, err := getEdgeIngressPortalName(portal.Name)
 	if err != nil {
 		return fmt.Errorf("get edge ingress name: %w", err)
@@ -481,6 +585,9 @@ func (w *WatcherPortal) upsertPortalEdgeIngress(ctx context.Context, portal *hub
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ingName,
 			Namespace: w.config.AgentNamespace,
+			Annotations: map[string]string{
+				edgeingress.AnnotationMiddlewares: middlewares,
+			},
 			Labels: map[string]string{
 				"app.kubernetes.io/managed-by": "traefik-hub",
 			},
@@ -548,7 +655,7 @@ func (w *WatcherPortal) upsertPortalEdgeIngress(ctx context.Context, portal *hub
 	return nil
 }
 
-func (w *WatcherPortal) upsertPortalIngress(ctx context.Context, portal *hubv1alpha1.APIPortal, acpName string) error {
+func (w *WatcherPortal) upsertPortalIngress(ctx context.Context, portal *hubv1alpha1.APIPortal, acpName, middlewares string) error {
 	ingressName, err := getIngressPortalName(portal.Name)
 	if err != nil {
 		return fmt.Errorf("get ingress name: %w", err)
@@ -564,7 +671,7 @@ func (w *WatcherPortal) upsertPortalIngress(ctx context.Context, portal *hubv1al
 		return fmt.Errorf("get ingress: %w", err)
 	}
 
-	ingress := w.buildIngress(portal, ingressName, secretName, acpName)
+	ingress := w.buildIngress(portal, ingressName, secretName, acpName, middlewares)
 	if kerror.IsNotFound(err) {
 		_, err = w.kubeClientSet.NetworkingV1().Ingresses(w.config.AgentNamespace).Create(ctx, ingress, metav1.CreateOptions{})
 		if err != nil {
@@ -596,7 +703,7 @@ func (w *WatcherPortal) upsertPortalIngress(ctx context.Context, portal *hubv1al
 	return nil
 }
 
-func (w *WatcherPortal) buildIngress(portal *hubv1alpha1.APIPortal, ingressName, secretName, acpName string) *netv1.Ingress {
+func (w *WatcherPortal) buildIngress(portal *hubv1alpha1.APIPortal, ingressName, secretName, acpName, middlewares string) *netv1.Ingress {
 	pathPrefix := netv1.PathTypePrefix
 	rule := netv1.IngressRuleValue{
 		HTTP: &netv1.HTTPIngressRuleValue{
@@ -636,6 +743,7 @@ func (w *WatcherPortal) buildIngress(portal *hubv1alpha1.APIPortal, ingressName,
 				"hub.traefik.io/access-control-policy":             acpName,
 				"traefik.ingress.kubernetes.io/router.tls":         "true",
 				"traefik.ingress.kubernetes.io/router.entrypoints": w.config.TraefikAPIEntryPoint,
+				"traefik.ingress.kubernetes.io/router.middlewares": middlewares,
 			},
 			Labels: map[string]string{
 				"app.kubernetes.io/managed-by": "traefik-hub",
@@ -771,6 +879,18 @@ func getACPPortalName(portalName string) (string, error) {
 	return fmt.Sprintf("%s-%d-portal-acp", portalName, h), nil
 }
 
+// getPortalCORSMiddlewareName compute the name of the CORS middleware of a portal.
+// The name follow this format: {portal-name}-{hash(portal-name)}-portal-cors
+// This hash is here to reduce the chance of getting a collision on an existing middleware.
+func getPortalCORSMiddlewareName(portalName string) (string, error) {
+	h, err := hash(portalName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-portal-cors", portalName, h), nil
+}
+
 // getPortalCustomDomainSecretName compute the name of the secret storing the certificate of the portal custom domains.
 // The name follow this format: {portalCustomDomainSecretNamePrefix}-{hash(portal-name)}
 // This hash is here to reduce the chance of getting a collision on an existing secret while staying under
@@ -29,8 +29,10 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
 	corev1 "k8s.io/api/core/v1"
@@ -69,6 +71,7 @@ func Test_WatcherRun(t *testing.T) {
 		wantIngresses     string
 		wantSecrets       string
 		wantACP           string
+		wantMiddlewares   string
 	}{
 		{
 			desc: "new portal present on the platform needs to be created on the cluster",
@@ -115,6 +118,12 @@ func Test_WatcherRun(t *testing.T) {
 						{Name: "new.example.com", Verified: true},
 						{Name: "not-yet-verified.example.com", Verified: false},
 					},
+					CORS: &hubv1alpha1.CORS{
+						AllowOrigins:     []string{"https://app.example.com"},
+						AllowMethods:     []string{"GET", "POST"},
+						AllowCredentials: true,
+						MaxAge:           600,
+					},
 				},
 			},
 			clusterPortals:       "testdata/update-portal/portals.yaml",
@@ -124,6 +133,7 @@ func Test_WatcherRun(t *testing.T) {
 			wantEdgeIngresses:    "testdata/update-portal/want.edge-ingresses.yaml",
 			wantIngresses:        "testdata/update-portal/want.ingresses.yaml",
 			wantSecrets:          "testdata/update-portal/want.secrets.yaml",
+			wantMiddlewares:      "testdata/update-portal/want.middlewares.yaml",
 		},
 		{
 			desc:                 "deleted portal on the platform needs to be deleted on the cluster",
@@ -159,6 +169,7 @@ func Test_WatcherRun(t *testing.T) {
 
 			kubeClientSet := kubefake.NewSimpleClientset(kubeObjects...)
 			hubClientSet := kube.NewFakeHubClientset(hubObjects...)
+			traefikClientSet := traefikcrdfake.NewSimpleClientset()
 
 			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 
@@ -202,7 +213,7 @@ func Test_WatcherRun(t *testing.T) {
 					}, nil)
 			}
 
-			w := NewWatcherPortal(client, kubeClientSet, kubeInformer, hubClientSet, hubInformer, &WatcherPortalConfig{
+			w := NewWatcherPortal(client, kubeClientSet, kubeInformer, hubClientSet, hubInformer, traefikClientSet.TraefikV1alpha1(), &WatcherPortalConfig{
 				IngressClassName:        "ingress-class",
 				AgentNamespace:          "agent-ns",
 				TraefikAPIEntryPoint:    "api-entrypoint",
@@ -243,6 +254,10 @@ func Test_WatcherRun(t *testing.T) {
 				wantSecrets := loadFixtures[corev1.Secret](t, test.wantSecrets)
 				assertSecretsMatches(t, kubeClientSet, []string{"agent-ns"}, wantSecrets)
 			}
+			if test.wantMiddlewares != "" {
+				wantMiddlewares := loadFixtures[traefikv1alpha1.Middleware](t, test.wantMiddlewares)
+				assertMiddlewaresMatches(t, traefikClientSet, []string{"agent-ns"}, wantMiddlewares)
+			}
 		})
 	}
 }
@@ -263,6 +278,9 @@ func assertPortalsMatches(t *testing.T, hubClientSet *hubfake.Clientset, want []
 	var portals []hubv1alpha1.APIPortal
 	for _, portal := range portalList.Items {
 		portal.Status.SyncedAt = metav1.Time{}
+		for i := range portal.Status.Conditions {
+			portal.Status.Conditions[i].LastTransitionTime = metav1.Time{}
+		}
 
 		portals = append(portals, portal)
 	}
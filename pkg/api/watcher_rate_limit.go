@@ -0,0 +1,272 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/scheme"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	kclientset "k8s.io/client-go/kubernetes"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// RateLimitPlatformClient allows fetching API rate limits from the platform and reporting their status.
+type RateLimitPlatformClient interface {
+	GetRateLimits(ctx context.Context) ([]RateLimit, error)
+	SetRateLimitStatus(ctx context.Context, name string, status RateLimitStatus) error
+}
+
+// WatcherRateLimit watches hub API rate limits and sync them with the cluster.
+type WatcherRateLimit struct {
+	rateLimitSyncInterval time.Duration
+
+	platform RateLimitPlatformClient
+
+	kubeClientSet kclientset.Interface
+
+	hubClientSet hubclientset.Interface
+	hubInformer  hubinformers.SharedInformerFactory
+
+	eventRecorder record.EventRecorder
+}
+
+// NewWatcherRateLimit returns a new WatcherRateLimit.
+func NewWatcherRateLimit(client RateLimitPlatformClient, kubeClientSet kclientset.Interface, hubClientSet hubclientset.Interface, hubInformer hubinformers.SharedInformerFactory, rateLimitSyncInterval time.Duration) *WatcherRateLimit {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1.EventSinkImpl{Interface: kubeClientSet.CoreV1().Events("")})
+	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{})
+
+	return &WatcherRateLimit{
+		rateLimitSyncInterval: rateLimitSyncInterval,
+		platform:              client,
+
+		kubeClientSet: kubeClientSet,
+
+		hubClientSet: hubClientSet,
+		hubInformer:  hubInformer,
+
+		eventRecorder: eventRecorder,
+	}
+}
+
+// Run runs WatcherRateLimit.
+func (w *WatcherRateLimit) Run(ctx context.Context) {
+	t := time.NewTicker(w.rateLimitSyncInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping API rate limit watcher")
+			return
+
+		case <-t.C:
+			ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
+			w.syncRateLimits(ctxSync)
+			cancel()
+		}
+	}
+}
+
+func (w *WatcherRateLimit) syncRateLimits(ctx context.Context) {
+	platformRateLimits, err := w.platform.GetRateLimits(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to fetch APIRateLimits")
+		return
+	}
+
+	clusterRateLimits, err := w.hubInformer.Hub().V1alpha1().APIRateLimits().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to obtain APIRateLimits")
+		return
+	}
+
+	clusterRateLimitsByName := map[string]*hubv1alpha1.APIRateLimit{}
+	for _, rateLimit := range clusterRateLimits {
+		clusterRateLimitsByName[rateLimit.Name] = rateLimit
+	}
+
+	for _, rateLimit := range platformRateLimits {
+		platformRateLimit := rateLimit
+
+		logger := log.With().Str("name", platformRateLimit.Name).Logger()
+
+		oldClusterRateLimit, found := clusterRateLimitsByName[platformRateLimit.Name]
+
+		// RateLimits that will remain in the map will be deleted.
+		delete(clusterRateLimitsByName, platformRateLimit.Name)
+
+		newClusterRateLimit, resourceErr := platformRateLimit.Resource()
+		if resourceErr != nil {
+			logger.Error().Err(resourceErr).Msg("Unable to build APIRateLimit resource")
+			continue
+		}
+
+		var syncedRateLimit *hubv1alpha1.APIRateLimit
+		if !found {
+			syncedRateLimit, err = w.createRateLimit(ctx, newClusterRateLimit)
+			if err != nil {
+				logger.Error().Err(err).Msg("Unable to create APIRateLimit")
+				continue
+			}
+		} else {
+			syncedRateLimit, err = w.updateRateLimit(ctx, oldClusterRateLimit, newClusterRateLimit)
+			if err != nil {
+				logger.Error().Err(err).Msg("Unable to update APIRateLimit")
+				continue
+			}
+		}
+
+		if err = w.reportSelectedAPIs(ctx, syncedRateLimit); err != nil {
+			logger.Error().Err(err).Msg("Unable to report APIs selected by APIRateLimit")
+		}
+	}
+
+	w.cleanRateLimits(ctx, clusterRateLimitsByName)
+}
+
+func (w *WatcherRateLimit) createRateLimit(ctx context.Context, rateLimit *hubv1alpha1.APIRateLimit) (*hubv1alpha1.APIRateLimit, error) {
+	createdRateLimit, err := w.hubClientSet.HubV1alpha1().APIRateLimits().Create(ctx, rateLimit, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("creating APIRateLimit: %w", err)
+	}
+
+	log.Debug().
+		Str("name", createdRateLimit.Name).
+		Msg("APIRateLimit created")
+
+	w.eventRecorder.Event(createdRateLimit, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
+
+	return createdRateLimit, nil
+}
+
+func (w *WatcherRateLimit) updateRateLimit(ctx context.Context, oldRateLimit, newRateLimit *hubv1alpha1.APIRateLimit) (*hubv1alpha1.APIRateLimit, error) {
+	meta := oldRateLimit.ObjectMeta
+	meta.Labels = newRateLimit.Labels
+	newRateLimit.ObjectMeta = meta
+	newRateLimit.Status.Conditions = hubv1alpha1.MergeConditions(oldRateLimit.Status.Conditions, newRateLimit.Status.Conditions...)
+	// The selected APIs are computed by the agent and only reported when they change.
+	newRateLimit.Status.APIs = oldRateLimit.Status.APIs
+
+	if newRateLimit.Status.Version == oldRateLimit.Status.Version && isSynced(oldRateLimit.Status.Conditions) {
+		return oldRateLimit, nil
+	}
+
+	updatedRateLimit, err := w.hubClientSet.HubV1alpha1().APIRateLimits().Update(ctx, newRateLimit, metav1.UpdateOptions{})
+	if err != nil {
+		w.eventRecorder.Eventf(newRateLimit, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
+		return nil, fmt.Errorf("updating APIRateLimit: %w", err)
+	}
+
+	log.Debug().
+		Str("name", updatedRateLimit.Name).
+		Msg("APIRateLimit updated")
+
+	w.eventRecorder.Event(updatedRateLimit, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
+
+	return updatedRateLimit, nil
+}
+
+// reportSelectedAPIs reports the APIs selected by the given APIRateLimit to the platform and stores them in its
+// status, if they changed since the last report.
+func (w *WatcherRateLimit) reportSelectedAPIs(ctx context.Context, rateLimit *hubv1alpha1.APIRateLimit) error {
+	apis, err := w.selectedAPIs(rateLimit)
+	if err != nil {
+		return fmt.Errorf("select APIs: %w", err)
+	}
+
+	if slices.Equal(apis, rateLimit.Status.APIs) {
+		return nil
+	}
+
+	if err = w.platform.SetRateLimitStatus(ctx, rateLimit.Name, RateLimitStatus{APIs: apis}); err != nil {
+		return fmt.Errorf("set APIRateLimit status: %w", err)
+	}
+
+	patch, err := json.Marshal([]struct {
+		Op    string   `json:"op"`
+		Path  string   `json:"path"`
+		Value []string `json:"value"`
+	}{
+		{Op: "add", Path: "/status/apis", Value: apis},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal APIs patch: %w", err)
+	}
+
+	if _, err = w.hubClientSet.HubV1alpha1().APIRateLimits().Patch(ctx, rateLimit.Name, ktypes.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("patch APIRateLimit: %w", err)
+	}
+
+	return nil
+}
+
+// selectedAPIs returns the sorted list of APIs selected by the given APIRateLimit, formatted as name@namespace.
+func (w *WatcherRateLimit) selectedAPIs(rateLimit *hubv1alpha1.APIRateLimit) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(rateLimit.Spec.APISelector)
+	if err != nil {
+		return nil, fmt.Errorf("convert API selector: %w", err)
+	}
+
+	apis, err := w.hubInformer.Hub().V1alpha1().APIs().Lister().List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("list APIs: %w", err)
+	}
+
+	names := make([]string, 0, len(apis))
+	for _, a := range apis {
+		names = append(names, a.Name+"@"+a.Namespace)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (w *WatcherRateLimit) cleanRateLimits(ctx context.Context, rateLimits map[string]*hubv1alpha1.APIRateLimit) {
+	for _, rateLimit := range rateLimits {
+		// Foreground propagation allow us to delete all resources owned by the APIRateLimit.
+		policy := metav1.DeletePropagationForeground
+
+		opts := metav1.DeleteOptions{
+			PropagationPolicy: &policy,
+		}
+		err := w.hubClientSet.HubV1alpha1().APIRateLimits().Delete(ctx, rateLimit.Name, opts)
+		if err != nil {
+			log.Error().Err(err).Msg("Unable to delete APIRateLimit")
+
+			continue
+		}
+
+		log.Debug().
+			Str("name", rateLimit.Name).
+			Msg("APIRateLimit deleted")
+	}
+}
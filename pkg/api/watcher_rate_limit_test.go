@@ -0,0 +1,153 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func Test_WatcherRateLimitRun(t *testing.T) {
+	rateLimitToUpdate := &hubv1alpha1.APIRateLimit{
+		ObjectMeta: metav1.ObjectMeta{Name: "rateLimitToUpdate"},
+		Spec: hubv1alpha1.APIRateLimitSpec{
+			Everyone: true,
+			APISelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "books"},
+			},
+			Limit: 10,
+		},
+		Status: hubv1alpha1.APIRateLimitStatus{
+			Version: "1",
+			APIs:    []string{"books@default"},
+		},
+	}
+	rateLimitToDelete := &hubv1alpha1.APIRateLimit{
+		ObjectMeta: metav1.ObjectMeta{Name: "rateLimitToDelete"},
+		Spec: hubv1alpha1.APIRateLimitSpec{
+			Everyone: true,
+			Limit:    10,
+		},
+	}
+	books := &hubv1alpha1.API{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "books",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "books"},
+		},
+	}
+	authors := &hubv1alpha1.API{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "authors",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "authors"},
+		},
+	}
+
+	kubeClientSet := kubefake.NewSimpleClientset()
+	clientSetHub := hubfake.NewSimpleClientset([]runtime.Object{rateLimitToUpdate, rateLimitToDelete, books, authors}...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+	rateLimitInformer := hubInformer.Hub().V1alpha1().APIRateLimits().Informer()
+	apiInformer := hubInformer.Hub().V1alpha1().APIs().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), rateLimitInformer.HasSynced, apiInformer.HasSynced)
+
+	var callCount int
+
+	client := newRateLimitPlatformClientMock(t)
+	client.OnGetRateLimits().
+		TypedReturns([]RateLimit{
+			{
+				Name:   "rateLimitToCreate",
+				Groups: []string{"group"},
+				APISelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "authors"},
+				},
+				Limit:    5,
+				Period:   &metav1.Duration{Duration: time.Minute},
+				Strategy: hubv1alpha1.RateLimitStrategyPerConsumer,
+				Version:  "1",
+			},
+			{
+				Name:     "rateLimitToUpdate",
+				Everyone: true,
+				APISelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "books"},
+				},
+				Limit:    20,
+				Strategy: hubv1alpha1.RateLimitStrategyShared,
+				Version:  "2",
+			},
+		}, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+	// Only the created APIRateLimit selects APIs it doesn't know about yet.
+	client.OnSetRateLimitStatus("rateLimitToCreate", RateLimitStatus{APIs: []string{"authors@default"}}).
+		TypedReturns(nil)
+
+	w := NewWatcherRateLimit(client, kubeClientSet, clientSetHub, hubInformer, time.Millisecond)
+	go w.Run(ctx)
+
+	<-ctx.Done()
+
+	rateLimit, err := clientSetHub.HubV1alpha1().APIRateLimits().Get(ctx, "rateLimitToCreate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, hubv1alpha1.APIRateLimitSpec{
+		Groups: []string{"group"},
+		APISelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "authors"},
+		},
+		Limit:    5,
+		Period:   &metav1.Duration{Duration: time.Minute},
+		Strategy: hubv1alpha1.RateLimitStrategyPerConsumer,
+	}, rateLimit.Spec)
+	assert.Equal(t, []string{"authors@default"}, rateLimit.Status.APIs)
+
+	rateLimit, err = clientSetHub.HubV1alpha1().APIRateLimits().Get(ctx, "rateLimitToUpdate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, hubv1alpha1.APIRateLimitSpec{
+		Everyone: true,
+		APISelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "books"},
+		},
+		Limit:    20,
+		Strategy: hubv1alpha1.RateLimitStrategyShared,
+	}, rateLimit.Spec)
+	assert.Equal(t, []string{"books@default"}, rateLimit.Status.APIs)
+
+	_, err = clientSetHub.HubV1alpha1().APIRateLimits().Get(ctx, "rateLimitToDelete", metav1.GetOptions{})
+	require.Error(t, err)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package clock measures the skew between the agent clock and the platform clock, so that time-based logic
+// involving the platform can be compensated.
+package clock
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// minCompensatedSkew is the smallest skew compensated. The Date header has a one second precision, and network
+// latency makes smaller skews indistinguishable from noise.
+const minCompensatedSkew = 2 * time.Second
+
+// Skew tracks the skew between the agent clock and the platform clock, measured from the Date header of the
+// platform responses. A nil Skew measures nothing and compensates nothing.
+type Skew struct {
+	warnThreshold time.Duration
+	now           func() time.Time
+
+	mu     sync.RWMutex
+	offset time.Duration
+	warned bool
+}
+
+// NewSkew creates a Skew which logs a warning whenever the measured skew exceeds the given threshold.
+func NewSkew(warnThreshold time.Duration) *Skew {
+	return &Skew{
+		warnThreshold: warnThreshold,
+		now:           time.Now,
+	}
+}
+
+// Measured returns the last measured skew, positive when the platform clock is ahead of the agent clock.
+func (s *Skew) Measured() time.Duration {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.offset
+}
+
+// Offset returns the duration to add to the agent clock to get the platform clock.
+// Skews too small to be measured reliably are not reported.
+func (s *Skew) Offset() time.Duration {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if abs(s.offset) < minCompensatedSkew {
+		return 0
+	}
+
+	return s.offset
+}
+
+// Now returns the current time according to the platform clock.
+func (s *Skew) Now() time.Time {
+	if s == nil {
+		return time.Now()
+	}
+
+	return s.now().Add(s.Offset())
+}
+
+// Observe records the Date header of a platform response, for a request sent at sentAt and whose response was
+// received at receivedAt.
+func (s *Skew) Observe(resp *http.Response, sentAt, receivedAt time.Time) {
+	if s == nil || resp == nil {
+		return
+	}
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		// Responses without a Date header, e.g. from proxies, are ignored.
+		return
+	}
+
+	// The platform dated the response somewhere during the round trip, most likely in its middle. The Date header
+	// being truncated to the second, it is half a second late on average.
+	local := sentAt.Add(receivedAt.Sub(sentAt) / 2)
+	offset := date.Add(500 * time.Millisecond).Sub(local)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset = offset
+
+	switch {
+	case abs(offset) > s.warnThreshold && !s.warned:
+		s.warned = true
+		log.Warn().
+			Str("skew", offset.Round(time.Second).String()).
+			Msg("The agent clock is skewed from the platform clock, time-based computations are compensated. Make sure the node clocks are synchronized with NTP")
+	case abs(offset) <= s.warnThreshold && s.warned:
+		s.warned = false
+		log.Info().
+			Str("skew", offset.Round(time.Second).String()).
+			Msg("The agent clock is synchronized with the platform clock again")
+	}
+}
+
+// Transport returns a round tripper measuring the skew from every response of the given round tripper.
+func (s *Skew) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sentAt := s.now()
+
+		resp, err := next.RoundTrip(req)
+		if err == nil {
+			s.Observe(resp, sentAt, s.now())
+		}
+
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
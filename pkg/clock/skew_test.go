@@ -0,0 +1,113 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package clock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkew_Observe(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc         string
+		date         string
+		roundTrip    time.Duration
+		wantMeasured time.Duration
+		wantOffset   time.Duration
+	}{
+		{
+			desc:         "synchronized clocks",
+			date:         now.Format(http.TimeFormat),
+			wantMeasured: 500 * time.Millisecond,
+		},
+		{
+			desc:         "platform ahead",
+			date:         now.Add(time.Minute).Format(http.TimeFormat),
+			wantMeasured: time.Minute + 500*time.Millisecond,
+			wantOffset:   time.Minute + 500*time.Millisecond,
+		},
+		{
+			desc:         "platform behind, with latency",
+			date:         now.Add(-time.Minute).Format(http.TimeFormat),
+			roundTrip:    time.Second,
+			wantMeasured: -time.Minute + 500*time.Millisecond,
+			wantOffset:   -time.Minute + 500*time.Millisecond,
+		},
+		{
+			desc: "no date",
+		},
+		{
+			desc: "malformed date",
+			date: "yesterday",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewSkew(30 * time.Second)
+			s.now = func() time.Time { return now }
+
+			resp := &http.Response{Header: http.Header{}}
+			if test.date != "" {
+				resp.Header.Set("Date", test.date)
+			}
+
+			s.Observe(resp, now.Add(-test.roundTrip/2), now.Add(test.roundTrip/2))
+
+			assert.Equal(t, test.wantMeasured, s.Measured())
+			assert.Equal(t, test.wantOffset, s.Offset())
+			assert.Equal(t, now.Add(test.wantOffset), s.Now())
+		})
+	}
+}
+
+func TestSkew_Transport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	}))
+	t.Cleanup(srv.Close)
+
+	s := NewSkew(30 * time.Second)
+	client := &http.Client{Transport: s.Transport(nil)}
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.InDelta(t, -time.Hour.Seconds(), s.Offset().Seconds(), 2)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), s.Now(), 2*time.Second)
+}
+
+func TestSkew_nil(t *testing.T) {
+	var s *Skew
+
+	s.Observe(&http.Response{Header: http.Header{"Date": []string{"Mon, 02 Jan 2006 15:04:05 GMT"}}}, time.Now(), time.Now())
+
+	assert.Zero(t, s.Offset())
+	assert.WithinDuration(t, time.Now(), s.Now(), time.Second)
+}
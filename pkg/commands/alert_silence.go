@@ -0,0 +1,164 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetAlertSilenceCommand creates or updates an AlertSilence.
+type SetAlertSilenceCommand struct {
+	hubClientSet hubclientset.Interface
+}
+
+// NewSetAlertSilenceCommand creates a new SetAlertSilenceCommand.
+func NewSetAlertSilenceCommand(hubClientSet hubclientset.Interface) *SetAlertSilenceCommand {
+	return &SetAlertSilenceCommand{
+		hubClientSet: hubClientSet,
+	}
+}
+
+type setAlertSilencePayload struct {
+	Name     string                     `json:"name"`
+	StartsAt *time.Time                 `json:"startsAt,omitempty"`
+	EndsAt   time.Time                  `json:"endsAt"`
+	Matchers []hubv1alpha1.AlertMatcher `json:"matchers"`
+	Comment  string                     `json:"comment,omitempty"`
+}
+
+// ResourceKey returns the key of the AlertSilence targeted by the command.
+func (c *SetAlertSilenceCommand) ResourceKey(data json.RawMessage) (string, bool) {
+	var payload setAlertSilencePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", false
+	}
+
+	return alertSilenceResourceKey(payload.Name), true
+}
+
+// Handle handles the creation or update of the given AlertSilence.
+func (c *SetAlertSilenceCommand) Handle(ctx context.Context, id string, requestedAt time.Time, data json.RawMessage) *platform.CommandExecutionReport {
+	var payload setAlertSilencePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to unmarshal command payload")
+		return newInternalErrorReport(id, err)
+	}
+
+	spec := hubv1alpha1.AlertSilenceSpec{
+		EndsAt:   metav1.NewTime(payload.EndsAt),
+		Matchers: payload.Matchers,
+		Comment:  payload.Comment,
+	}
+	if payload.StartsAt != nil {
+		startsAt := metav1.NewTime(*payload.StartsAt)
+		spec.StartsAt = &startsAt
+	}
+
+	silences := c.hubClientSet.HubV1alpha1().AlertSilences()
+
+	silence, err := silences.Get(ctx, payload.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return newErrorReport(id, err)
+	}
+
+	if kerror.IsNotFound(err) {
+		silence = &hubv1alpha1.AlertSilence{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: payload.Name,
+				Annotations: map[string]string{
+					AnnotationLastPatchRequestedAt: requestedAt.Format(time.RFC3339),
+				},
+			},
+			Spec: spec,
+		}
+
+		if _, err = silences.Create(ctx, silence, metav1.CreateOptions{}); err != nil {
+			return newErrorReport(id, err)
+		}
+
+		return platform.NewSuccessCommandExecutionReport(id)
+	}
+
+	if silence.Annotations == nil {
+		silence.Annotations = make(map[string]string)
+	}
+	silence.Annotations[AnnotationLastPatchRequestedAt] = requestedAt.Format(time.RFC3339)
+	silence.Spec = spec
+
+	if _, err = silences.Update(ctx, silence, metav1.UpdateOptions{}); err != nil {
+		return newErrorReport(id, err)
+	}
+
+	return platform.NewSuccessCommandExecutionReport(id)
+}
+
+// DeleteAlertSilenceCommand deletes an AlertSilence, ending it before its planned end.
+type DeleteAlertSilenceCommand struct {
+	hubClientSet hubclientset.Interface
+}
+
+// NewDeleteAlertSilenceCommand creates a new DeleteAlertSilenceCommand.
+func NewDeleteAlertSilenceCommand(hubClientSet hubclientset.Interface) *DeleteAlertSilenceCommand {
+	return &DeleteAlertSilenceCommand{
+		hubClientSet: hubClientSet,
+	}
+}
+
+type deleteAlertSilencePayload struct {
+	Name string `json:"name"`
+}
+
+// ResourceKey returns the key of the AlertSilence targeted by the command.
+func (c *DeleteAlertSilenceCommand) ResourceKey(data json.RawMessage) (string, bool) {
+	var payload deleteAlertSilencePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", false
+	}
+
+	return alertSilenceResourceKey(payload.Name), true
+}
+
+// Handle handles the deletion of the given AlertSilence.
+func (c *DeleteAlertSilenceCommand) Handle(ctx context.Context, id string, _ time.Time, data json.RawMessage) *platform.CommandExecutionReport {
+	var payload deleteAlertSilencePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to unmarshal command payload")
+		return newInternalErrorReport(id, err)
+	}
+
+	err := c.hubClientSet.HubV1alpha1().AlertSilences().Delete(ctx, payload.Name, metav1.DeleteOptions{})
+	if err != nil {
+		return newErrorReport(id, err)
+	}
+
+	return platform.NewSuccessCommandExecutionReport(id)
+}
+
+// alertSilenceResourceKey returns the resource key of the given AlertSilence, shared by all the commands targeting it.
+func alertSilenceResourceKey(name string) string {
+	return "alertsilence/" + name
+}
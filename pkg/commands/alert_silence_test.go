@@ -0,0 +1,124 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSetAlertSilenceCommand_Handle(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		desc     string
+		existing []*hubv1alpha1.AlertSilence
+	}{
+		{
+			desc: "create",
+		},
+		{
+			desc: "update",
+			existing: []*hubv1alpha1.AlertSilence{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "maintenance",
+						Annotations: map[string]string{
+							"hub.traefik.io/last-patch-requested-at": now.Add(-time.Hour).Format(time.RFC3339),
+						},
+					},
+					Spec: hubv1alpha1.AlertSilenceSpec{
+						EndsAt:   metav1.NewTime(now),
+						Matchers: []hubv1alpha1.AlertMatcher{{Label: "rule", Value: "rule-1"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			var objects []runtime.Object
+			for _, silence := range test.existing {
+				objects = append(objects, silence)
+			}
+			hubClient := hubcrdfake.NewSimpleClientset(objects...)
+
+			handler := NewSetAlertSilenceCommand(hubClient)
+
+			data := []byte(`{
+				"name": "maintenance",
+				"startsAt": "` + now.Format(time.RFC3339) + `",
+				"endsAt": "` + now.Add(2*time.Hour).Format(time.RFC3339) + `",
+				"matchers": [{"label": "namespace", "value": "my-ns"}, {"label": "service", "value": "whoami@.*", "regex": true}],
+				"comment": "Database upgrade"
+			}`)
+
+			report := handler.Handle(ctx, "command-id", now, data)
+			assert.Equal(t, platform.NewSuccessCommandExecutionReport("command-id"), report)
+
+			silence, err := hubClient.HubV1alpha1().AlertSilences().Get(ctx, "maintenance", metav1.GetOptions{})
+			require.NoError(t, err)
+
+			assert.Equal(t, now.Format(time.RFC3339), silence.Annotations["hub.traefik.io/last-patch-requested-at"])
+			assert.Equal(t, hubv1alpha1.AlertSilenceSpec{
+				StartsAt: &metav1.Time{Time: now},
+				EndsAt:   metav1.NewTime(now.Add(2 * time.Hour)),
+				Matchers: []hubv1alpha1.AlertMatcher{
+					{Label: "namespace", Value: "my-ns"},
+					{Label: "service", Value: "whoami@.*", Regex: true},
+				},
+				Comment: "Database upgrade",
+			}, silence.Spec)
+		})
+	}
+}
+
+func TestDeleteAlertSilenceCommand_Handle(t *testing.T) {
+	ctx := context.Background()
+
+	hubClient := hubcrdfake.NewSimpleClientset(&hubv1alpha1.AlertSilence{
+		ObjectMeta: metav1.ObjectMeta{Name: "maintenance"},
+	})
+
+	handler := NewDeleteAlertSilenceCommand(hubClient)
+
+	report := handler.Handle(ctx, "command-id", time.Now(), []byte(`{"name": "maintenance"}`))
+	assert.Equal(t, platform.NewSuccessCommandExecutionReport("command-id"), report)
+
+	silences, err := hubClient.HubV1alpha1().AlertSilences().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, silences.Items)
+
+	report = handler.Handle(ctx, "command-id", time.Now(), []byte(`{"name": "maintenance"}`))
+	assert.Equal(t, newErrorReportWithType("command-id", reportErrorTypeSilenceNotFound), report)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+// DiagnosticsUploader is capable of uploading diagnostics bundles.
+type DiagnosticsUploader interface {
+	UploadDiagnostics(ctx context.Context, commandID string, bundle []byte) error
+}
+
+// CollectDiagnosticsCommand collects a diagnostics bundle and uploads it to the platform.
+type CollectDiagnosticsCommand struct {
+	uploader DiagnosticsUploader
+	sources  []diagnostics.Source
+}
+
+// NewCollectDiagnosticsCommand creates a new CollectDiagnosticsCommand, collecting bundles from the given sources.
+func NewCollectDiagnosticsCommand(uploader DiagnosticsUploader, sources ...diagnostics.Source) *CollectDiagnosticsCommand {
+	return &CollectDiagnosticsCommand{
+		uploader: uploader,
+		sources:  sources,
+	}
+}
+
+// ResourceKey returns the same key for all the commands, so bundles are collected one at a time.
+func (c *CollectDiagnosticsCommand) ResourceKey(_ json.RawMessage) (string, bool) {
+	return "diagnostics", true
+}
+
+// Handle collects a diagnostics bundle and uploads it.
+func (c *CollectDiagnosticsCommand) Handle(ctx context.Context, id string, _ time.Time, _ json.RawMessage) *platform.CommandExecutionReport {
+	var bundle bytes.Buffer
+	if err := diagnostics.Write(ctx, &bundle, c.sources...); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to collect diagnostics")
+		return newInternalErrorReport(id, fmt.Errorf("collect diagnostics: %w", err))
+	}
+
+	if err := c.uploader.UploadDiagnostics(ctx, id, bundle.Bytes()); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to upload diagnostics")
+		return newInternalErrorReport(id, fmt.Errorf("upload diagnostics: %w", err))
+	}
+
+	return platform.NewSuccessCommandExecutionReport(id)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+func TestCollectDiagnosticsCommand_Handle(t *testing.T) {
+	source := func(_ context.Context, b *diagnostics.Bundle) error {
+		return b.Add("hello.txt", "hello")
+	}
+
+	tests := []struct {
+		desc       string
+		sources    []diagnostics.Source
+		uploadErr  error
+		wantReport *platform.CommandExecutionReport
+		wantFiles  []string
+	}{
+		{
+			desc:       "upload the bundle",
+			sources:    []diagnostics.Source{source},
+			wantReport: platform.NewSuccessCommandExecutionReport("command-id"),
+			wantFiles:  []string{"hub-agent-diagnostics/hello.txt"},
+		},
+		{
+			desc: "unable to collect the bundle",
+			sources: []diagnostics.Source{func(_ context.Context, _ *diagnostics.Bundle) error {
+				return errors.New("boom")
+			}},
+			wantReport: newInternalErrorReport("command-id", errors.New("collect diagnostics: boom")),
+		},
+		{
+			desc:       "unable to upload the bundle",
+			sources:    []diagnostics.Source{source},
+			uploadErr:  errors.New("boom"),
+			wantReport: newInternalErrorReport("command-id", errors.New("upload diagnostics: boom")),
+			wantFiles:  []string{"hub-agent-diagnostics/hello.txt"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotFiles []string
+			uploader := uploadDiagnosticsFunc(func(_ context.Context, commandID string, bundle []byte) error {
+				assert.Equal(t, "command-id", commandID)
+
+				gzr, err := gzip.NewReader(bytes.NewReader(bundle))
+				require.NoError(t, err)
+
+				tr := tar.NewReader(gzr)
+				for {
+					header, err := tr.Next()
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					require.NoError(t, err)
+
+					gotFiles = append(gotFiles, header.Name)
+				}
+
+				return test.uploadErr
+			})
+
+			handler := NewCollectDiagnosticsCommand(uploader, test.sources...)
+			report := handler.Handle(context.Background(), "command-id", time.Now(), nil)
+
+			assert.Equal(t, test.wantReport, report)
+			assert.Equal(t, test.wantFiles, gotFiles)
+		})
+	}
+}
+
+type uploadDiagnosticsFunc func(ctx context.Context, commandID string, bundle []byte) error
+
+func (f uploadDiagnosticsFunc) UploadDiagnostics(ctx context.Context, commandID string, bundle []byte) error {
+	return f(ctx, commandID, bundle)
+}
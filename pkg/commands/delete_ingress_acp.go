@@ -50,6 +50,16 @@ type deleteIngressACPPayload struct {
 	IngressID string `json:"ingressId"`
 }
 
+// ResourceKey returns the key of the Ingress targeted by the command.
+func (c *DeleteIngressACPCommand) ResourceKey(data json.RawMessage) (string, bool) {
+	var payload deleteIngressACPPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", false
+	}
+
+	return ingressResourceKey(payload.IngressID), true
+}
+
 // Handle handles the ACP deletion on the given Ingress.
 func (c *DeleteIngressACPCommand) Handle(ctx context.Context, id string, requestedAt time.Time, data json.RawMessage) *platform.CommandExecutionReport {
 	var payload deleteIngressACPPayload
@@ -32,6 +32,11 @@ const (
 	reportErrorTypeUnsupportedCommand reportErrorType = "unsupported-command"
 	reportErrorTypeIngressNotFound    reportErrorType = "ingress-not-found"
 	reportErrorTypeACPNotFound        reportErrorType = "acp-not-found"
+	reportErrorTypeSilenceNotFound    reportErrorType = "alert-silence-not-found"
+	reportErrorTypeWorkloadNotFound   reportErrorType = "workload-not-found"
+	reportErrorTypeForbidden          reportErrorType = "forbidden"
+	reportErrorTypeReplicasOutOfRange reportErrorType = "replicas-out-of-range"
+	reportErrorTypeInvalidLogLevel    reportErrorType = "invalid-log-level"
 )
 
 func newErrorReport(commandID string, err error) *platform.CommandExecutionReport {
@@ -54,9 +59,17 @@ func newErrorReport(commandID string, err error) *platform.CommandExecutionRepor
 			return newErrorReportWithType(commandID, reportErrorTypeIngressNotFound)
 		case "accesscontrolpolicy", "accesscontrolpolicies":
 			return newErrorReportWithType(commandID, reportErrorTypeACPNotFound)
+		case "alertsilence", "alertsilences":
+			return newErrorReportWithType(commandID, reportErrorTypeSilenceNotFound)
+		case "deployment", "deployments", "daemonset", "daemonsets", "statefulset", "statefulsets":
+			return newErrorReportWithType(commandID, reportErrorTypeWorkloadNotFound)
 		}
 	}
 
+	if statusErr.Status().Reason == metav1.StatusReasonForbidden {
+		return newErrorReportWithType(commandID, reportErrorTypeForbidden)
+	}
+
 	return newInternalErrorReport(commandID, err)
 }
 
@@ -0,0 +1,148 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+// maxConcurrentCommands is the maximum number of resources commands are applied on concurrently.
+const maxConcurrentCommands = 8
+
+// ResourceKeyer is implemented by the handlers of commands targeting a single resource. Commands targeting the same
+// resource are applied one after the other, in their creation order, while commands targeting different resources are
+// applied concurrently. Commands whose handler doesn't implement it are applied one after the other.
+type ResourceKeyer interface {
+	ResourceKey(data json.RawMessage) (string, bool)
+}
+
+// execute applies the given commands, which must be sorted from the oldest to the newest. Commands already executed
+// are skipped and their recorded report returned again. It returns the reports in the order of the commands.
+func (w *Watcher) execute(ctx context.Context, commands []platform.Command) []platform.CommandExecutionReport {
+	logger := log.Ctx(ctx)
+
+	reports := make([]*platform.CommandExecutionReport, len(commands))
+
+	var keys []string
+	queues := make(map[string][]int)
+	seen := make(map[string]struct{})
+
+	for i, command := range commands {
+		// The platform may list a command more than once.
+		if _, ok := seen[command.ID]; ok {
+			continue
+		}
+		seen[command.ID] = struct{}{}
+
+		report, err := w.markers.Get(ctx, command.ID)
+		if err != nil {
+			// Without markers, already executed commands can't be told apart: wait for the next attempt.
+			logger.Error().Err(err).Msg("Unable to get command markers")
+			return nil
+		}
+		if report != nil {
+			logger.Debug().Str("command_id", command.ID).Msg("Command already executed, sending its report again")
+
+			reports[i] = report
+			continue
+		}
+
+		handler, ok := w.commands[command.Type]
+		if !ok {
+			logger.Error().
+				Str("command", command.Type).
+				Msg("Command unsupported on this agent version")
+
+			reports[i] = newErrorReportWithType(command.ID, reportErrorTypeUnsupportedCommand)
+			continue
+		}
+
+		key := resourceKey(handler, command)
+		if _, ok = queues[key]; !ok {
+			keys = append(keys, key)
+		}
+		queues[key] = append(queues[key], i)
+	}
+
+	sem := make(chan struct{}, maxConcurrentCommands)
+
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		wg.Add(1)
+
+		go func(queue []int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			for _, i := range queue {
+				reports[i] = w.apply(ctx, w.commands[commands[i].Type], commands[i])
+			}
+		}(queues[key])
+	}
+	wg.Wait()
+
+	var result []platform.CommandExecutionReport
+	for _, report := range reports {
+		if report != nil {
+			result = append(result, *report)
+		}
+	}
+
+	return result
+}
+
+// apply applies a command and records its execution.
+func (w *Watcher) apply(ctx context.Context, handler Handler, command platform.Command) *platform.CommandExecutionReport {
+	logger := log.Ctx(ctx).With().
+		Str("command_type", command.Type).
+		Str("command_id", command.ID).
+		Logger()
+
+	report := handler.Handle(logger.WithContext(ctx), command.ID, command.CreatedAt, command.Data)
+	if report == nil {
+		return nil
+	}
+
+	if err := w.markers.Set(ctx, command.ID, *report); err != nil {
+		logger.Error().Err(err).Msg("Unable to record command execution")
+	}
+
+	return report
+}
+
+// resourceKey returns the key of the resource targeted by the given command.
+func resourceKey(handler Handler, command platform.Command) string {
+	keyer, ok := handler.(ResourceKeyer)
+	if !ok {
+		return ""
+	}
+
+	key, ok := keyer.ResourceKey(command.Data)
+	if !ok {
+		return ""
+	}
+
+	return key
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// keyedHandler handles commands whose data is the key of the resource they target.
+type keyedHandler struct {
+	handle func(id string) *platform.CommandExecutionReport
+}
+
+func (h keyedHandler) ResourceKey(data json.RawMessage) (string, bool) {
+	return string(data), true
+}
+
+func (h keyedHandler) Handle(_ context.Context, id string, _ time.Time, _ json.RawMessage) *platform.CommandExecutionReport {
+	return h.handle(id)
+}
+
+func TestWatcher_execute_serializesCommandsPerResource(t *testing.T) {
+	now := time.Now()
+
+	commands := []platform.Command{
+		{ID: "a-1", CreatedAt: now, Type: "keyed", Data: []byte("a")},
+		{ID: "b-1", CreatedAt: now.Add(time.Second), Type: "keyed", Data: []byte("b")},
+		{ID: "a-2", CreatedAt: now.Add(2 * time.Second), Type: "keyed", Data: []byte("a")},
+	}
+
+	// a-1 only completes once b-1 has started, which requires commands on different resources to run concurrently.
+	bStarted := make(chan struct{})
+
+	var mu sync.Mutex
+	var applied []string
+
+	handler := keyedHandler{handle: func(id string) *platform.CommandExecutionReport {
+		switch id {
+		case "a-1":
+			select {
+			case <-bStarted:
+			case <-time.After(5 * time.Second):
+				return platform.NewErrorCommandExecutionReport(id, platform.CommandExecutionReportError{Type: "timeout"})
+			}
+		case "b-1":
+			close(bStarted)
+		}
+
+		mu.Lock()
+		applied = append(applied, id)
+		mu.Unlock()
+
+		return platform.NewSuccessCommandExecutionReport(id)
+	}}
+
+	w := NewWatcher(time.Hour, nil, nil, nil, nil, ScaleLimits{})
+	w.commands = map[string]Handler{"keyed": handler}
+
+	reports := w.execute(context.Background(), commands)
+
+	assert.Equal(t, []platform.CommandExecutionReport{
+		*platform.NewSuccessCommandExecutionReport("a-1"),
+		*platform.NewSuccessCommandExecutionReport("b-1"),
+		*platform.NewSuccessCommandExecutionReport("a-2"),
+	}, reports)
+	assert.Equal(t, []string{"b-1", "a-1", "a-2"}, applied)
+}
+
+func TestWatcher_execute_appliesCommandsOnce(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset()
+
+	commands := []platform.Command{
+		{ID: "command-1", CreatedAt: time.Now(), Type: "keyed", Data: []byte("a")},
+		{ID: "command-1", CreatedAt: time.Now(), Type: "keyed", Data: []byte("a")},
+	}
+
+	var calls int
+	handler := keyedHandler{handle: func(id string) *platform.CommandExecutionReport {
+		calls++
+		return platform.NewErrorCommandExecutionReport(id, platform.CommandExecutionReportError{Type: "ingress-not-found"})
+	}}
+
+	w := NewWatcher(time.Hour, nil, nil, nil, nil, ScaleLimits{})
+	w.SetMarkers(NewMarkers(client, "hub-agent", time.Hour))
+	w.commands = map[string]Handler{"keyed": handler}
+
+	wantReports := []platform.CommandExecutionReport{
+		*platform.NewErrorCommandExecutionReport("command-1", platform.CommandExecutionReportError{Type: "ingress-not-found"}),
+	}
+
+	reports := w.execute(ctx, commands)
+	assert.Equal(t, wantReports, reports)
+	assert.Equal(t, 1, calls)
+
+	// After a restart, the command is listed again as its report hasn't been acknowledged yet.
+	restarted := NewWatcher(time.Hour, nil, nil, nil, nil, ScaleLimits{})
+	restarted.SetMarkers(NewMarkers(client, "hub-agent", time.Hour))
+	restarted.commands = map[string]Handler{"keyed": handler}
+
+	reports = restarted.execute(ctx, commands[:1])
+	require.Equal(t, wantReports, reports)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// MarkersConfigMapName is the name of the ConfigMap in which the executed commands are recorded.
+const MarkersConfigMapName = "hub-agent-command-markers"
+
+// marker records the execution of a command.
+type marker struct {
+	ID         string                          `json:"id"`
+	ExecutedAt time.Time                       `json:"executedAt"`
+	Report     platform.CommandExecutionReport `json:"report"`
+}
+
+// Markers records the commands executed on the cluster in a ConfigMap, along with their report, so commands listed
+// again by the platform, for instance after an agent restart or a failed report submission, are not applied twice.
+// Markers are kept for the given retention, which must exceed the time the platform takes to acknowledge a report.
+// A nil Markers is valid and records nothing.
+type Markers struct {
+	client    kclientset.Interface
+	namespace string
+	retention time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	markers map[string]marker
+}
+
+// NewMarkers creates markers persisted in the given namespace.
+func NewMarkers(client kclientset.Interface, namespace string, retention time.Duration) *Markers {
+	return &Markers{
+		client:    client,
+		namespace: namespace,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Get returns the report of the given command if it has already been executed, nil otherwise.
+func (m *Markers) Get(ctx context.Context, id string) (*platform.CommandExecutionReport, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.load(ctx); err != nil {
+		return nil, err
+	}
+
+	mk, ok := m.markers[markerKey(id)]
+	if !ok || mk.ID != id {
+		return nil, nil
+	}
+
+	report := mk.Report
+	return &report, nil
+}
+
+// Set records the execution of the given command, and drops the markers past their retention.
+func (m *Markers) Set(ctx context.Context, id string, report platform.CommandExecutionReport) error {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.load(ctx); err != nil {
+		return err
+	}
+
+	mk := marker{ID: id, ExecutedAt: m.now().UTC(), Report: report}
+
+	value, err := json.Marshal(mk)
+	if err != nil {
+		return fmt.Errorf("marshal marker: %w", err)
+	}
+
+	expiredBefore := mk.ExecutedAt.Add(-m.retention)
+	key := markerKey(id)
+
+	if err = m.update(ctx, func(data map[string]string) {
+		for k, v := range data {
+			var existing marker
+			if json.Unmarshal([]byte(v), &existing) != nil || existing.ExecutedAt.Before(expiredBefore) {
+				delete(data, k)
+			}
+		}
+
+		data[key] = string(value)
+	}); err != nil {
+		return err
+	}
+
+	for k, existing := range m.markers {
+		if existing.ExecutedAt.Before(expiredBefore) {
+			delete(m.markers, k)
+		}
+	}
+	m.markers[key] = mk
+
+	return nil
+}
+
+// load loads the markers from the ConfigMap, once.
+func (m *Markers) load(ctx context.Context) error {
+	if m.markers != nil {
+		return nil
+	}
+
+	cm, err := m.client.CoreV1().ConfigMaps(m.namespace).Get(ctx, MarkersConfigMapName, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get command markers: %w", err)
+	}
+
+	markers := make(map[string]marker)
+	if cm != nil {
+		for key, value := range cm.Data {
+			var mk marker
+			if err = json.Unmarshal([]byte(value), &mk); err != nil {
+				log.Warn().Err(err).Str("key", key).Msg("Ignoring malformed command marker")
+				continue
+			}
+
+			markers[key] = mk
+		}
+	}
+
+	m.markers = markers
+
+	return nil
+}
+
+// update applies the given mutation to the markers ConfigMap.
+func (m *Markers) update(ctx context.Context, mutate func(data map[string]string)) error {
+	retriable := func(err error) bool {
+		return kerror.IsConflict(err) || kerror.IsAlreadyExists(err)
+	}
+
+	err := retry.OnError(retry.DefaultRetry, retriable, func() error {
+		cm, err := m.client.CoreV1().ConfigMaps(m.namespace).Get(ctx, MarkersConfigMapName, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			data := map[string]string{}
+			mutate(data)
+
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      MarkersConfigMapName,
+					Namespace: m.namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "traefik-hub",
+					},
+				},
+				Data: data,
+			}
+
+			_, err = m.client.CoreV1().ConfigMaps(m.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		mutate(cm.Data)
+
+		_, err = m.client.CoreV1().ConfigMaps(m.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("update command markers: %w", err)
+	}
+
+	return nil
+}
+
+// markerKey returns the ConfigMap key of the marker of the given command. IDs which aren't valid keys are hashed.
+func markerKey(id string) string {
+	if len(validation.IsConfigMapKey(id)) == 0 {
+		return id
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
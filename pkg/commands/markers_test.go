@@ -0,0 +1,85 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMarkers_GetSet(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset()
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	markers := NewMarkers(client, "hub-agent", time.Hour)
+	markers.now = func() time.Time { return now }
+
+	report, err := markers.Get(ctx, "command-1")
+	require.NoError(t, err)
+	assert.Nil(t, report)
+
+	failed := platform.NewErrorCommandExecutionReport("command/2", platform.CommandExecutionReportError{Type: "ingress-not-found"})
+
+	require.NoError(t, markers.Set(ctx, "command-1", *platform.NewSuccessCommandExecutionReport("command-1")))
+	require.NoError(t, markers.Set(ctx, "command/2", *failed))
+
+	// Markers survive restarts.
+	restarted := NewMarkers(client, "hub-agent", time.Hour)
+	restarted.now = func() time.Time { return now }
+
+	report, err = restarted.Get(ctx, "command-1")
+	require.NoError(t, err)
+	assert.Equal(t, platform.NewSuccessCommandExecutionReport("command-1"), report)
+
+	report, err = restarted.Get(ctx, "command/2")
+	require.NoError(t, err)
+	assert.Equal(t, failed, report)
+
+	// Markers past their retention are dropped.
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, restarted.Set(ctx, "command-3", *platform.NewSuccessCommandExecutionReport("command-3")))
+
+	report, err = restarted.Get(ctx, "command-1")
+	require.NoError(t, err)
+	assert.Nil(t, report)
+
+	cm, err := client.CoreV1().ConfigMaps("hub-agent").Get(ctx, MarkersConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "traefik-hub", cm.Labels["app.kubernetes.io/managed-by"])
+	assert.Len(t, cm.Data, 1)
+	assert.Contains(t, cm.Data, "command-3")
+}
+
+func TestMarkers_nil(t *testing.T) {
+	var markers *Markers
+
+	require.NoError(t, markers.Set(context.Background(), "command-1", *platform.NewSuccessCommandExecutionReport("command-1")))
+
+	report, err := markers.Get(context.Background(), "command-1")
+	require.NoError(t, err)
+	assert.Nil(t, report)
+}
@@ -225,6 +225,112 @@ func (_c *storeSubmitCommandReportsCall) OnSubmitCommandReportsRaw(reports inter
 	return _c.Parent.OnSubmitCommandReportsRaw(reports)
 }
 
+// subscriberMock mock of Subscriber.
+type subscriberMock struct{ mock.Mock }
+
+// newSubscriberMock creates a new subscriberMock.
+func newSubscriberMock(tb testing.TB) *subscriberMock {
+	tb.Helper()
+
+	m := &subscriberMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *subscriberMock) SubscribeCommands(_ context.Context, notify func()) error {
+	_ret := _m.Called(notify)
+
+	if _rf, ok := _ret.Get(0).(func(func()) error); ok {
+		return _rf(notify)
+	}
+
+	_ra0 := _ret.Error(0)
+
+	return _ra0
+}
+
+func (_m *subscriberMock) OnSubscribeCommands(notify func()) *subscriberSubscribeCommandsCall {
+	return &subscriberSubscribeCommandsCall{Call: _m.Mock.On("SubscribeCommands", notify), Parent: _m}
+}
+
+func (_m *subscriberMock) OnSubscribeCommandsRaw(notify interface{}) *subscriberSubscribeCommandsCall {
+	return &subscriberSubscribeCommandsCall{Call: _m.Mock.On("SubscribeCommands", notify), Parent: _m}
+}
+
+type subscriberSubscribeCommandsCall struct {
+	*mock.Call
+	Parent *subscriberMock
+}
+
+func (_c *subscriberSubscribeCommandsCall) Panic(msg string) *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) Once() *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) Twice() *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) Times(i int) *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) WaitUntil(w <-chan time.Time) *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) After(d time.Duration) *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) Run(fn func(args mock.Arguments)) *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) Maybe() *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) TypedReturns(a error) *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Return(a)
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) ReturnsFn(fn func(func()) error) *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) TypedRun(fn func(func())) *subscriberSubscribeCommandsCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_notify, _ := args.Get(0).(func())
+		fn(_notify)
+	})
+	return _c
+}
+
+func (_c *subscriberSubscribeCommandsCall) OnSubscribeCommands(notify func()) *subscriberSubscribeCommandsCall {
+	return _c.Parent.OnSubscribeCommands(notify)
+}
+
+func (_c *subscriberSubscribeCommandsCall) OnSubscribeCommandsRaw(notify interface{}) *subscriberSubscribeCommandsCall {
+	return _c.Parent.OnSubscribeCommandsRaw(notify)
+}
+
 // handlerMock mock of Handler.
 type handlerMock struct{ mock.Mock }
 
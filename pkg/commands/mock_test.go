@@ -18,4 +18,5 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package commands
 
 // mocktail:Store
+// mocktail:Subscriber
 // mocktail:Handler
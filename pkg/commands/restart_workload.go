@@ -0,0 +1,175 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+// AnnotationRestartedAt is the pod template annotation set by `kubectl rollout restart` to trigger a rollout.
+const AnnotationRestartedAt = "kubectl.kubernetes.io/restartedAt"
+
+// workloadResources are the resources of the workloads which can be restarted, by kind.
+var workloadResources = map[string]string{
+	"Deployment":  "deployments",
+	"DaemonSet":   "daemonsets",
+	"StatefulSet": "statefulsets",
+}
+
+// RestartWorkloadCommand performs a rollout restart of a Deployment, DaemonSet or StatefulSet.
+type RestartWorkloadCommand struct {
+	k8sClientSet kclientset.Interface
+}
+
+// NewRestartWorkloadCommand creates a new RestartWorkloadCommand.
+func NewRestartWorkloadCommand(k8sClientSet kclientset.Interface) *RestartWorkloadCommand {
+	return &RestartWorkloadCommand{
+		k8sClientSet: k8sClientSet,
+	}
+}
+
+type restartWorkloadPayload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type workloadPatch struct {
+	ObjectMetadata objectMetadata `json:"metadata"`
+	Spec           workloadSpec   `json:"spec"`
+}
+
+type workloadSpec struct {
+	Template podTemplate `json:"template"`
+}
+
+type podTemplate struct {
+	ObjectMetadata objectMetadata `json:"metadata"`
+}
+
+// ResourceKey returns the key of the workload targeted by the command.
+func (c *RestartWorkloadCommand) ResourceKey(data json.RawMessage) (string, bool) {
+	var payload restartWorkloadPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", false
+	}
+
+	return workloadResourceKey(payload.Kind, payload.Namespace, payload.Name), true
+}
+
+// Handle restarts the given workload, the same way `kubectl rollout restart` does. The restart time is the time at
+// which the restart has been requested, so applying the same command twice doesn't restart the workload twice.
+func (c *RestartWorkloadCommand) Handle(ctx context.Context, id string, requestedAt time.Time, data json.RawMessage) *platform.CommandExecutionReport {
+	var payload restartWorkloadPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to unmarshal command payload")
+		return newInternalErrorReport(id, err)
+	}
+
+	logger := log.Ctx(ctx).With().
+		Str("kind", payload.Kind).
+		Str("namespace", payload.Namespace).
+		Str("name", payload.Name).
+		Logger()
+
+	resource, ok := workloadResources[payload.Kind]
+	if !ok || payload.Namespace == "" || payload.Name == "" {
+		logger.Error().Msg("Invalid workload")
+		return newErrorReportWithType(id, reportErrorTypeWorkloadNotFound)
+	}
+
+	allowed, err := canPatchWorkload(ctx, c.k8sClientSet, resource, payload.Namespace, payload.Name)
+	if err != nil {
+		return newInternalErrorReport(id, err)
+	}
+	if !allowed {
+		logger.Error().Msg("Not allowed to restart workload")
+		return newErrorReportWithType(id, reportErrorTypeForbidden)
+	}
+
+	restartedAt := stringPtr(requestedAt.Format(time.RFC3339))
+	patch, err := json.Marshal(workloadPatch{
+		ObjectMetadata: objectMetadata{
+			Annotations: map[string]*string{AnnotationLastPatchRequestedAt: restartedAt},
+		},
+		Spec: workloadSpec{
+			Template: podTemplate{
+				ObjectMetadata: objectMetadata{
+					Annotations: map[string]*string{AnnotationRestartedAt: restartedAt},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return newInternalErrorReport(id, err)
+	}
+
+	apps := c.k8sClientSet.AppsV1()
+	switch payload.Kind {
+	case "Deployment":
+		_, err = apps.Deployments(payload.Namespace).Patch(ctx, payload.Name, ktypes.MergePatchType, patch, metav1.PatchOptions{})
+	case "DaemonSet":
+		_, err = apps.DaemonSets(payload.Namespace).Patch(ctx, payload.Name, ktypes.MergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = apps.StatefulSets(payload.Namespace).Patch(ctx, payload.Name, ktypes.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		return newErrorReport(id, err)
+	}
+
+	logger.Info().Msg("Workload restarted")
+
+	return platform.NewSuccessCommandExecutionReport(id)
+}
+
+// workloadResourceKey returns the resource key of the given workload, shared by all the commands targeting it.
+func workloadResourceKey(kind, namespace, name string) string {
+	return "workload/" + kind + "/" + namespace + "/" + name
+}
+
+// canPatchWorkload reports whether the agent is allowed to patch the given workload. The agent may be granted wider
+// permissions than the ones needed to act on workloads from the platform: a workload is only patched when it has
+// explicitly been allowed.
+func canPatchWorkload(ctx context.Context, k8sClientSet kclientset.Interface, resource, namespace, name string) (bool, error) {
+	review, err := k8sClientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "patch",
+				Group:     "apps",
+				Resource:  resource,
+				Name:      name,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("review access: %w", err)
+	}
+
+	return review.Status.Allowed, nil
+}
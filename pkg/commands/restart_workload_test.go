@@ -0,0 +1,152 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestRestartWorkloadCommand_Handle(t *testing.T) {
+	requestedAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc          string
+		kind          string
+		name          string
+		allowed       bool
+		wantReport    *platform.CommandExecutionReport
+		wantRestarted bool
+	}{
+		{
+			desc:          "restart a Deployment",
+			kind:          "Deployment",
+			name:          "whoami",
+			allowed:       true,
+			wantReport:    platform.NewSuccessCommandExecutionReport("command-id"),
+			wantRestarted: true,
+		},
+		{
+			desc:          "restart a DaemonSet",
+			kind:          "DaemonSet",
+			name:          "whoami",
+			allowed:       true,
+			wantReport:    platform.NewSuccessCommandExecutionReport("command-id"),
+			wantRestarted: true,
+		},
+		{
+			desc:          "restart a StatefulSet",
+			kind:          "StatefulSet",
+			name:          "whoami",
+			allowed:       true,
+			wantReport:    platform.NewSuccessCommandExecutionReport("command-id"),
+			wantRestarted: true,
+		},
+		{
+			desc:       "unknown workload",
+			kind:       "Deployment",
+			name:       "unknown",
+			allowed:    true,
+			wantReport: newErrorReportWithType("command-id", reportErrorTypeWorkloadNotFound),
+		},
+		{
+			desc:       "unsupported kind",
+			kind:       "ReplicaSet",
+			name:       "whoami",
+			allowed:    true,
+			wantReport: newErrorReportWithType("command-id", reportErrorTypeWorkloadNotFound),
+		},
+		{
+			desc:       "not allowed to patch the workload",
+			kind:       "Deployment",
+			name:       "whoami",
+			wantReport: newErrorReportWithType("command-id", reportErrorTypeForbidden),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			meta := metav1.ObjectMeta{Name: "whoami", Namespace: "my-ns"}
+			k8sClient := kubefake.NewSimpleClientset(
+				&appsv1.Deployment{ObjectMeta: meta},
+				&appsv1.DaemonSet{ObjectMeta: meta},
+				&appsv1.StatefulSet{ObjectMeta: meta},
+			)
+			k8sClient.PrependReactor("create", "selfsubjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+				review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+
+				assert.Equal(t, "patch", review.Spec.ResourceAttributes.Verb)
+				assert.Equal(t, "apps", review.Spec.ResourceAttributes.Group)
+				assert.Equal(t, "my-ns", review.Spec.ResourceAttributes.Namespace)
+				assert.Equal(t, test.name, review.Spec.ResourceAttributes.Name)
+
+				review.Status.Allowed = test.allowed
+				return true, review, nil
+			})
+
+			handler := NewRestartWorkloadCommand(k8sClient)
+
+			data := fmt.Sprintf(`{"kind": %q, "namespace": "my-ns", "name": %q}`, test.kind, test.name)
+
+			report := handler.Handle(ctx, "command-id", requestedAt, []byte(data))
+			assert.Equal(t, test.wantReport, report)
+
+			var workload, template metav1.ObjectMeta
+			switch test.kind {
+			case "DaemonSet":
+				daemonSet, err := k8sClient.AppsV1().DaemonSets("my-ns").Get(ctx, "whoami", metav1.GetOptions{})
+				require.NoError(t, err)
+				workload, template = daemonSet.ObjectMeta, daemonSet.Spec.Template.ObjectMeta
+			case "StatefulSet":
+				statefulSet, err := k8sClient.AppsV1().StatefulSets("my-ns").Get(ctx, "whoami", metav1.GetOptions{})
+				require.NoError(t, err)
+				workload, template = statefulSet.ObjectMeta, statefulSet.Spec.Template.ObjectMeta
+			default:
+				deployment, err := k8sClient.AppsV1().Deployments("my-ns").Get(ctx, "whoami", metav1.GetOptions{})
+				require.NoError(t, err)
+				workload, template = deployment.ObjectMeta, deployment.Spec.Template.ObjectMeta
+			}
+
+			if !test.wantRestarted {
+				assert.Empty(t, template.Annotations)
+				return
+			}
+
+			assert.Equal(t, "2023-01-01T10:00:00Z", template.Annotations[AnnotationRestartedAt])
+			assert.Equal(t, "2023-01-01T10:00:00Z", workload.Annotations[AnnotationLastPatchRequestedAt])
+		})
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+// ScaleLimits are the guardrails of the number of replicas workloads can be scaled to from the platform.
+type ScaleLimits struct {
+	MinReplicas int32
+	MaxReplicas int32
+}
+
+// scalableResources are the resources of the workloads which can be scaled, by kind.
+var scalableResources = map[string]string{
+	"Deployment":  "deployments",
+	"StatefulSet": "statefulsets",
+}
+
+// ScaleWorkloadCommand sets the number of replicas of a Deployment or StatefulSet.
+type ScaleWorkloadCommand struct {
+	k8sClientSet kclientset.Interface
+	limits       ScaleLimits
+}
+
+// NewScaleWorkloadCommand creates a new ScaleWorkloadCommand.
+func NewScaleWorkloadCommand(k8sClientSet kclientset.Interface, limits ScaleLimits) *ScaleWorkloadCommand {
+	return &ScaleWorkloadCommand{
+		k8sClientSet: k8sClientSet,
+		limits:       limits,
+	}
+}
+
+type scaleWorkloadPayload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Replicas  int32  `json:"replicas"`
+}
+
+type scalePatch struct {
+	ObjectMetadata objectMetadata `json:"metadata"`
+	Spec           scaleSpec      `json:"spec"`
+}
+
+type scaleSpec struct {
+	Replicas int32 `json:"replicas"`
+}
+
+// replicasOutOfRangeData is the data of replicas out of range errors.
+type replicasOutOfRangeData struct {
+	MinReplicas int32 `json:"minReplicas"`
+	MaxReplicas int32 `json:"maxReplicas"`
+}
+
+// ResourceKey returns the key of the workload targeted by the command.
+func (c *ScaleWorkloadCommand) ResourceKey(data json.RawMessage) (string, bool) {
+	var payload scaleWorkloadPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", false
+	}
+
+	return workloadResourceKey(payload.Kind, payload.Namespace, payload.Name), true
+}
+
+// Handle scales the given workload to the requested number of replicas, within the configured limits.
+func (c *ScaleWorkloadCommand) Handle(ctx context.Context, id string, requestedAt time.Time, data json.RawMessage) *platform.CommandExecutionReport {
+	var payload scaleWorkloadPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to unmarshal command payload")
+		return newInternalErrorReport(id, err)
+	}
+
+	logger := log.Ctx(ctx).With().
+		Str("kind", payload.Kind).
+		Str("namespace", payload.Namespace).
+		Str("name", payload.Name).
+		Int32("replicas", payload.Replicas).
+		Logger()
+
+	resource, ok := scalableResources[payload.Kind]
+	if !ok || payload.Namespace == "" || payload.Name == "" {
+		logger.Error().Msg("Invalid workload")
+		return newErrorReportWithType(id, reportErrorTypeWorkloadNotFound)
+	}
+
+	if payload.Replicas < c.limits.MinReplicas || payload.Replicas > c.limits.MaxReplicas {
+		logger.Error().
+			Int32("min_replicas", c.limits.MinReplicas).
+			Int32("max_replicas", c.limits.MaxReplicas).
+			Msg("Replicas out of the allowed range")
+
+		return platform.NewErrorCommandExecutionReport(id, platform.CommandExecutionReportError{
+			Type: string(reportErrorTypeReplicasOutOfRange),
+			Data: replicasOutOfRangeData{MinReplicas: c.limits.MinReplicas, MaxReplicas: c.limits.MaxReplicas},
+		})
+	}
+
+	allowed, err := canPatchWorkload(ctx, c.k8sClientSet, resource, payload.Namespace, payload.Name)
+	if err != nil {
+		return newInternalErrorReport(id, err)
+	}
+	if !allowed {
+		logger.Error().Msg("Not allowed to scale workload")
+		return newErrorReportWithType(id, reportErrorTypeForbidden)
+	}
+
+	patch, err := json.Marshal(scalePatch{
+		ObjectMetadata: objectMetadata{
+			Annotations: map[string]*string{
+				AnnotationLastPatchRequestedAt: stringPtr(requestedAt.Format(time.RFC3339)),
+			},
+		},
+		Spec: scaleSpec{Replicas: payload.Replicas},
+	})
+	if err != nil {
+		return newInternalErrorReport(id, err)
+	}
+
+	apps := c.k8sClientSet.AppsV1()
+	switch payload.Kind {
+	case "Deployment":
+		_, err = apps.Deployments(payload.Namespace).Patch(ctx, payload.Name, ktypes.MergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = apps.StatefulSets(payload.Namespace).Patch(ctx, payload.Name, ktypes.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		return newErrorReport(id, err)
+	}
+
+	logger.Info().Msg("Workload scaled")
+
+	return platform.NewSuccessCommandExecutionReport(id)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+)
+
+func TestScaleWorkloadCommand_Handle(t *testing.T) {
+	requestedAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc         string
+		kind         string
+		name         string
+		replicas     int32
+		allowed      bool
+		wantReport   *platform.CommandExecutionReport
+		wantReplicas int32
+	}{
+		{
+			desc:         "scale a Deployment",
+			kind:         "Deployment",
+			name:         "whoami",
+			replicas:     5,
+			allowed:      true,
+			wantReport:   platform.NewSuccessCommandExecutionReport("command-id"),
+			wantReplicas: 5,
+		},
+		{
+			desc:         "scale a StatefulSet",
+			kind:         "StatefulSet",
+			name:         "whoami",
+			replicas:     1,
+			allowed:      true,
+			wantReport:   platform.NewSuccessCommandExecutionReport("command-id"),
+			wantReplicas: 1,
+		},
+		{
+			desc:         "unknown workload",
+			kind:         "Deployment",
+			name:         "unknown",
+			replicas:     5,
+			allowed:      true,
+			wantReport:   newErrorReportWithType("command-id", reportErrorTypeWorkloadNotFound),
+			wantReplicas: 2,
+		},
+		{
+			desc:         "unsupported kind",
+			kind:         "DaemonSet",
+			name:         "whoami",
+			replicas:     5,
+			allowed:      true,
+			wantReport:   newErrorReportWithType("command-id", reportErrorTypeWorkloadNotFound),
+			wantReplicas: 2,
+		},
+		{
+			desc:     "below the minimum replicas",
+			kind:     "Deployment",
+			name:     "whoami",
+			replicas: 0,
+			allowed:  true,
+			wantReport: platform.NewErrorCommandExecutionReport("command-id", platform.CommandExecutionReportError{
+				Type: "replicas-out-of-range",
+				Data: replicasOutOfRangeData{MinReplicas: 1, MaxReplicas: 10},
+			}),
+			wantReplicas: 2,
+		},
+		{
+			desc:     "above the maximum replicas",
+			kind:     "Deployment",
+			name:     "whoami",
+			replicas: 11,
+			allowed:  true,
+			wantReport: platform.NewErrorCommandExecutionReport("command-id", platform.CommandExecutionReportError{
+				Type: "replicas-out-of-range",
+				Data: replicasOutOfRangeData{MinReplicas: 1, MaxReplicas: 10},
+			}),
+			wantReplicas: 2,
+		},
+		{
+			desc:         "not allowed to patch the workload",
+			kind:         "Deployment",
+			name:         "whoami",
+			replicas:     5,
+			wantReport:   newErrorReportWithType("command-id", reportErrorTypeForbidden),
+			wantReplicas: 2,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			meta := metav1.ObjectMeta{Name: "whoami", Namespace: "my-ns"}
+			k8sClient := kubefake.NewSimpleClientset(
+				&appsv1.Deployment{ObjectMeta: meta, Spec: appsv1.DeploymentSpec{Replicas: pointer.Int32(2)}},
+				&appsv1.StatefulSet{ObjectMeta: meta, Spec: appsv1.StatefulSetSpec{Replicas: pointer.Int32(2)}},
+			)
+			k8sClient.PrependReactor("create", "selfsubjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+				review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				review.Status.Allowed = test.allowed
+				return true, review, nil
+			})
+
+			handler := NewScaleWorkloadCommand(k8sClient, ScaleLimits{MinReplicas: 1, MaxReplicas: 10})
+
+			data := fmt.Sprintf(`{"kind": %q, "namespace": "my-ns", "name": %q, "replicas": %d}`, test.kind, test.name, test.replicas)
+
+			report := handler.Handle(ctx, "command-id", requestedAt, []byte(data))
+			assert.Equal(t, test.wantReport, report)
+
+			var replicas *int32
+			if test.kind == "StatefulSet" {
+				statefulSet, err := k8sClient.AppsV1().StatefulSets("my-ns").Get(ctx, "whoami", metav1.GetOptions{})
+				require.NoError(t, err)
+				replicas = statefulSet.Spec.Replicas
+			} else {
+				deployment, err := k8sClient.AppsV1().Deployments("my-ns").Get(ctx, "whoami", metav1.GetOptions{})
+				require.NoError(t, err)
+				replicas = deployment.Spec.Replicas
+			}
+
+			require.NotNil(t, replicas)
+			assert.Equal(t, test.wantReplicas, *replicas)
+		})
+	}
+}
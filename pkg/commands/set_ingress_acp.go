@@ -68,6 +68,16 @@ type objectMetadata struct {
 	Annotations map[string]*string `json:"annotations"`
 }
 
+// ResourceKey returns the key of the Ingress targeted by the command.
+func (c *SetIngressACPCommand) ResourceKey(data json.RawMessage) (string, bool) {
+	var payload setIngressACPPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", false
+	}
+
+	return ingressResourceKey(payload.IngressID), true
+}
+
 // Handle sets an ACP on an Ingress.
 func (c *SetIngressACPCommand) Handle(ctx context.Context, id string, requestedAt time.Time, data json.RawMessage) *platform.CommandExecutionReport {
 	var payload setIngressACPPayload
@@ -118,6 +128,11 @@ func (c *SetIngressACPCommand) Handle(ctx context.Context, id string, requestedA
 	return platform.NewSuccessCommandExecutionReport(id)
 }
 
+// ingressResourceKey returns the resource key of the given Ingress, shared by all the commands targeting it.
+func ingressResourceKey(ingressID string) string {
+	return "ingress/" + ingressID
+}
+
 type ingressKey struct {
 	Name      string
 	Namespace string
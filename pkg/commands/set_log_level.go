@@ -0,0 +1,62 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+// SetLogLevelCommand overrides the log level of a component of the controller.
+type SetLogLevelCommand struct{}
+
+// NewSetLogLevelCommand creates a new SetLogLevelCommand.
+func NewSetLogLevelCommand() *SetLogLevelCommand {
+	return &SetLogLevelCommand{}
+}
+
+// ResourceKey returns the key of the component targeted by the command.
+func (c *SetLogLevelCommand) ResourceKey(data json.RawMessage) (string, bool) {
+	var payload logger.LevelRequest
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", false
+	}
+
+	return "log-level/" + payload.Component, true
+}
+
+// Handle overrides the log level of the given component, or removes its override when the level is empty.
+func (c *SetLogLevelCommand) Handle(ctx context.Context, id string, _ time.Time, data json.RawMessage) *platform.CommandExecutionReport {
+	var payload logger.LevelRequest
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to unmarshal command payload")
+		return newInternalErrorReport(id, err)
+	}
+
+	if err := payload.Apply(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("level", payload.Level).Msg("Invalid log level")
+		return newErrorReportWithType(id, reportErrorTypeInvalidLogLevel)
+	}
+
+	return platform.NewSuccessCommandExecutionReport(id)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+func TestSetLogLevelCommand_Handle(t *testing.T) {
+	tests := []struct {
+		desc          string
+		payload       logger.LevelRequest
+		wantReport    *platform.CommandExecutionReport
+		wantOverrides []logger.LevelOverride
+	}{
+		{
+			desc: "override the level of a component",
+			payload: logger.LevelRequest{
+				Component: "platform_client",
+				Level:     "debug",
+			},
+			wantReport: platform.NewSuccessCommandExecutionReport("command-id"),
+			wantOverrides: []logger.LevelOverride{
+				{Component: "platform_client", Level: "debug"},
+			},
+		},
+		{
+			desc: "remove the override of a component",
+			payload: logger.LevelRequest{
+				Component: "platform_client",
+			},
+			wantReport: platform.NewSuccessCommandExecutionReport("command-id"),
+		},
+		{
+			desc: "invalid level",
+			payload: logger.LevelRequest{
+				Component: "platform_client",
+				Level:     "verbose",
+			},
+			wantReport: newErrorReportWithType("command-id", reportErrorTypeInvalidLogLevel),
+		},
+		{
+			desc: "invalid duration",
+			payload: logger.LevelRequest{
+				Component: "platform_client",
+				Level:     "debug",
+				Duration:  "soon",
+			},
+			wantReport: newErrorReportWithType("command-id", reportErrorTypeInvalidLogLevel),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Cleanup(func() {
+				require.NoError(t, logger.OverrideLevel(test.payload.Component, "", 0))
+			})
+
+			data, err := json.Marshal(test.payload)
+			require.NoError(t, err)
+
+			handler := NewSetLogLevelCommand()
+			report := handler.Handle(context.Background(), "command-id", time.Now(), data)
+
+			assert.Equal(t, test.wantReport, report)
+
+			_, overrides := logger.Levels()
+			assert.ElementsMatch(t, test.wantOverrides, overrides)
+		})
+	}
+}
@@ -20,11 +20,15 @@ package commands
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
 	"sort"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/diagnostics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	kclientset "k8s.io/client-go/kubernetes"
 )
@@ -39,35 +43,75 @@ type Store interface {
 	SubmitCommandReports(ctx context.Context, reports []platform.CommandExecutionReport) error
 }
 
+// Subscriber is capable of subscribing to the commands pushed by the platform.
+type Subscriber interface {
+	SubscribeCommands(ctx context.Context, notify func()) error
+}
+
 // Handler can handle a command.
 type Handler interface {
 	Handle(ctx context.Context, id string, requestedAt time.Time, data json.RawMessage) *platform.CommandExecutionReport
 }
 
+// Delays between two command subscription attempts.
+const (
+	minSubscribeRetryDelay = time.Second
+	maxSubscribeRetryDelay = 5 * time.Minute
+)
+
 // Watcher watches and applies the patch commands from the platform.
 type Watcher struct {
-	interval time.Duration
-	store    Store
-	commands map[string]Handler
+	interval   time.Duration
+	store      Store
+	subscriber Subscriber
+	markers    *Markers
+	commands   map[string]Handler
 }
 
 // NewWatcher creates a Watcher.
-func NewWatcher(interval time.Duration, store Store, k8sClientSet kclientset.Interface, traefikClientSet traefikclientset.Interface) *Watcher {
+func NewWatcher(interval time.Duration, store Store, k8sClientSet kclientset.Interface, traefikClientSet traefikclientset.Interface, hubClientSet hubclientset.Interface, scaleLimits ScaleLimits) *Watcher {
 	return &Watcher{
 		interval: interval,
 		store:    store,
 		commands: map[string]Handler{
-			"set-ingress-acp":    NewSetIngressACPCommand(k8sClientSet, traefikClientSet),
-			"delete-ingress-acp": NewDeleteIngressACPCommand(k8sClientSet, traefikClientSet),
+			"set-ingress-acp":      NewSetIngressACPCommand(k8sClientSet, traefikClientSet),
+			"delete-ingress-acp":   NewDeleteIngressACPCommand(k8sClientSet, traefikClientSet),
+			"set-alert-silence":    NewSetAlertSilenceCommand(hubClientSet),
+			"delete-alert-silence": NewDeleteAlertSilenceCommand(hubClientSet),
+			"restart-workload":     NewRestartWorkloadCommand(k8sClientSet),
+			"scale-workload":       NewScaleWorkloadCommand(k8sClientSet, scaleLimits),
+			"set-log-level":        NewSetLogLevelCommand(),
 		},
 	}
 }
 
+// SetSubscriber sets the subscriber used to apply the commands as soon as the platform pushes them, instead of
+// waiting for the next poll. Commands are still polled, in case the subscription is lost.
+func (w *Watcher) SetSubscriber(subscriber Subscriber) {
+	w.subscriber = subscriber
+}
+
+// SetMarkers sets the markers recording the executed commands, preventing them from being applied twice.
+func (w *Watcher) SetMarkers(markers *Markers) {
+	w.markers = markers
+}
+
+// SetDiagnostics enables the collect-diagnostics command, uploading the bundles of the given sources with the
+// given uploader.
+func (w *Watcher) SetDiagnostics(uploader DiagnosticsUploader, sources ...diagnostics.Source) {
+	w.commands["collect-diagnostics"] = NewCollectDiagnosticsCommand(uploader, sources...)
+}
+
 // Start starts watching commands.
 func (w *Watcher) Start(ctx context.Context) {
 	tick := time.NewTicker(w.interval)
 	defer tick.Stop()
 
+	pending := make(chan struct{}, 1)
+	if w.subscriber != nil {
+		go w.subscribe(ctx, pending)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -75,6 +119,59 @@ func (w *Watcher) Start(ctx context.Context) {
 			return
 		case <-tick.C:
 			w.applyPendingCommands(ctx)
+		case <-pending:
+			w.applyPendingCommands(ctx)
+		}
+	}
+}
+
+// subscribe keeps a subscription to the platform commands open, signaling on the pending channel when commands are
+// pushed. The subscription is reopened with an exponential backoff when it's lost.
+func (w *Watcher) subscribe(ctx context.Context, pending chan<- struct{}) {
+	logger := log.Ctx(ctx)
+
+	notify := func() {
+		select {
+		case pending <- struct{}{}:
+		default:
+		}
+	}
+
+	delay := minSubscribeRetryDelay
+	for {
+		subscribedAt := time.Now()
+
+		err := w.subscriber.SubscribeCommands(ctx, notify)
+		if ctx.Err() != nil {
+			return
+		}
+
+		var apiErr platform.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			logger.Info().Msg("Command subscription unsupported by the platform, falling back to polling")
+			return
+		}
+
+		if time.Since(subscribedAt) > maxSubscribeRetryDelay {
+			delay = minSubscribeRetryDelay
+		}
+
+		if err != nil {
+			logger.Debug().Err(err).Dur("retry_in", delay).Msg("Command subscription lost")
+		}
+
+		// Commands may have been pushed while the subscription was down.
+		notify()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxSubscribeRetryDelay {
+			delay = maxSubscribeRetryDelay
 		}
 	}
 }
@@ -93,33 +190,11 @@ func (w *Watcher) applyPendingCommands(ctx context.Context) {
 	}
 
 	// Sort commands from the oldest to the newest.
-	sort.Slice(commands, func(i, j int) bool {
+	sort.SliceStable(commands, func(i, j int) bool {
 		return commands[i].CreatedAt.Before(commands[j].CreatedAt)
 	})
 
-	var reports []platform.CommandExecutionReport
-
-	for _, command := range commands {
-		handler, ok := w.commands[command.Type]
-		if !ok {
-			logger.Error().
-				Str("command", command.Type).
-				Msg("Command unsupported on this agent version")
-
-			reports = append(reports, *newErrorReportWithType(command.ID, reportErrorTypeUnsupportedCommand))
-			continue
-		}
-
-		execCtx := logger.With().
-			Str("command_type", command.Type).
-			Logger().WithContext(ctx)
-
-		report := handler.Handle(execCtx, command.ID, command.CreatedAt, command.Data)
-		if report != nil {
-			reports = append(reports, *report)
-		}
-	}
-
+	reports := w.execute(ctx, commands)
 	if len(reports) == 0 {
 		return
 	}
@@ -19,6 +19,8 @@ package commands
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"testing"
 	"time"
 
@@ -65,7 +67,7 @@ func TestWatcher_applyPendingCommands_skipsUnknownCommands(t *testing.T) {
 		}),
 	}).TypedReturns(nil).Once()
 
-	w := NewWatcher(10*time.Second, store, nil, nil)
+	w := NewWatcher(10*time.Second, store, nil, nil, nil, ScaleLimits{})
 	w.commands = map[string]Handler{
 		"do-something": doSomethingHandler,
 	}
@@ -123,10 +125,94 @@ func TestWatcher_applyPendingCommands_appliedByDate(t *testing.T) {
 		*platform.NewSuccessCommandExecutionReport("command-2"),
 	}).TypedReturns(nil).Once()
 
-	w := NewWatcher(10*time.Second, commands, nil, nil)
+	w := NewWatcher(10*time.Second, commands, nil, nil, nil, ScaleLimits{})
 	w.commands = map[string]Handler{
 		"do-something": doSomethingHandler,
 	}
 
 	w.applyPendingCommands(ctx)
 }
+
+func TestWatcher_Start_appliesPushedCommands(t *testing.T) {
+	tests := []struct {
+		desc      string
+		subscribe func(notify func()) error
+	}{
+		{
+			desc: "commands pushed by the platform",
+			subscribe: func(notify func()) error {
+				notify()
+				return platform.APIError{StatusCode: http.StatusNotFound}
+			},
+		},
+		{
+			desc: "subscription lost",
+			subscribe: func(_ func()) error {
+				return errors.New("connection reset")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			pendingCommands := []platform.Command{
+				{
+					ID:        "command-1",
+					CreatedAt: time.Now(),
+					Type:      "do-something",
+					Data:      []byte("command-1"),
+				},
+			}
+
+			handler := newHandlerMock(t)
+			handler.
+				OnHandleRaw("command-1", mock.Anything, mock.Anything).
+				TypedReturns(platform.NewSuccessCommandExecutionReport("command-1")).
+				Once()
+
+			done := make(chan struct{})
+
+			store := newStoreMock(t)
+			store.OnListPendingCommands().TypedReturns(pendingCommands, nil).Once()
+			store.
+				OnSubmitCommandReports([]platform.CommandExecutionReport{
+					*platform.NewSuccessCommandExecutionReport("command-1"),
+				}).
+				TypedReturns(nil).
+				Run(func(_ mock.Arguments) { close(done) }).
+				Once()
+
+			subscriber := newSubscriberMock(t)
+			subscriber.OnSubscribeCommandsRaw(mock.Anything).ReturnsFn(test.subscribe).Once()
+			subscriber.OnSubscribeCommandsRaw(mock.Anything).TypedReturns(platform.APIError{StatusCode: http.StatusNotFound}).Maybe()
+
+			w := NewWatcher(time.Hour, store, nil, nil, nil, ScaleLimits{})
+			w.SetSubscriber(subscriber)
+			w.commands = map[string]Handler{
+				"do-something": handler,
+			}
+
+			stopped := make(chan struct{})
+			go func() {
+				w.Start(ctx)
+				close(stopped)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("pushed commands not applied")
+			}
+
+			cancel()
+			<-stopped
+		})
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package configfile reads the flags of the agent commands from a YAML or TOML configuration file, and watches it for
+// changes so the reloadable settings are applied without restarting the agent.
+package configfile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Values are the values of the flags set in a configuration file, by flag name. Slice flags can have several values.
+type Values map[string][]string
+
+// Changed returns the names of the flags whose values differ between the two sets of values, sorted.
+func (v Values) Changed(other Values) []string {
+	var changed []string
+	for name, values := range v {
+		if !reflect.DeepEqual(values, other[name]) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range other {
+		if _, ok := v[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// Watcher watches a configuration file, typically a mounted ConfigMap entry, so that the changed settings are applied
+// without restarting the agent.
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	valuesMu sync.RWMutex
+	values   Values
+
+	listenersMu sync.RWMutex
+	listeners   []func(previous, current Values)
+}
+
+// NewWatcher returns a new Watcher of the given configuration file, checked at the given interval.
+func NewWatcher(path string, interval time.Duration) (*Watcher, error) {
+	values, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		values:   values,
+	}, nil
+}
+
+// Values returns the current values of the configuration file.
+func (w *Watcher) Values() Values {
+	w.valuesMu.RLock()
+	defer w.valuesMu.RUnlock()
+
+	return w.values
+}
+
+// AddListener adds a listener called with the previous and current values each time the configuration file changes.
+func (w *Watcher) AddListener(listener func(previous, current Values)) {
+	w.listenersMu.Lock()
+	defer w.listenersMu.Unlock()
+
+	w.listeners = append(w.listeners, listener)
+}
+
+// Run runs the Watcher.
+func (w *Watcher) Run(ctx context.Context) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := w.reload(); err != nil {
+				log.Error().Err(err).Str("path", w.path).Msg("Unable to reload configuration file")
+			}
+		}
+	}
+}
+
+func (w *Watcher) reload() error {
+	b, err := os.ReadFile(w.path)
+	if err != nil {
+		return fmt.Errorf("read configuration file: %w", err)
+	}
+
+	// An empty file is most likely being written: resetting every reloadable setting to its default would be worse
+	// than waiting for its content.
+	if len(bytes.TrimSpace(b)) == 0 {
+		log.Warn().Str("path", w.path).Msg("Configuration file is empty, keeping the current values")
+		return nil
+	}
+
+	values, err := parse(w.path, b)
+	if err != nil {
+		return err
+	}
+
+	w.valuesMu.Lock()
+	previous := w.values
+	if reflect.DeepEqual(previous, values) {
+		w.valuesMu.Unlock()
+		return nil
+	}
+	w.values = values
+	w.valuesMu.Unlock()
+
+	log.Info().Str("path", w.path).Strs("flags", previous.Changed(values)).Msg("Configuration file changed")
+
+	w.listenersMu.RLock()
+	defer w.listenersMu.RUnlock()
+
+	for _, listener := range w.listeners {
+		listener(previous, values)
+	}
+
+	return nil
+}
+
+// Read reads the values of the flags set in the given configuration file, YAML or TOML depending on its extension.
+// Nested tables are flattened, their keys joined with dots: `tunnel: {connections: 2}` sets the tunnel.connections
+// flag, just like `tunnel.connections: 2`.
+func Read(path string) (Values, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read configuration file: %w", err)
+	}
+
+	return parse(path, b)
+}
+
+// parse parses the given content of a configuration file, YAML or TOML depending on the extension of its path.
+func parse(path string, b []byte) (Values, error) {
+	var err error
+	raw := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &raw)
+	case ".toml":
+		err = toml.Unmarshal(b, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported configuration file extension %q, expected .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse configuration file: %w", err)
+	}
+
+	values := make(Values)
+	if err = flatten(values, "", raw); err != nil {
+		return nil, fmt.Errorf("parse configuration file: %w", err)
+	}
+
+	return values, nil
+}
+
+func flatten(values Values, prefix string, raw map[string]any) error {
+	for key, value := range raw {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		if table, ok := value.(map[string]any); ok {
+			if err := flatten(values, name, table); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, ok := values[name]; ok {
+			return fmt.Errorf("flag %q set twice", name)
+		}
+
+		list, ok := value.([]any)
+		if !ok {
+			list = []any{value}
+		}
+
+		for _, item := range list {
+			s, err := format(item)
+			if err != nil {
+				return fmt.Errorf("flag %q: %w", name, err)
+			}
+
+			values[name] = append(values[name], s)
+		}
+	}
+
+	return nil
+}
+
+func format(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package configfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		desc       string
+		file       string
+		content    string
+		wantValues Values
+		wantErr    string
+	}{
+		{
+			desc: "YAML",
+			file: "config.yaml",
+			content: `
+log-level: debug
+topology:
+  min-patch-interval: 10s
+  namespaces: [default, apps]
+tunnel.connections: 2
+tunnel.quic: true
+`,
+			wantValues: Values{
+				"log-level":                   {"debug"},
+				"topology.min-patch-interval": {"10s"},
+				"topology.namespaces":         {"default", "apps"},
+				"tunnel.connections":          {"2"},
+				"tunnel.quic":                 {"true"},
+			},
+		},
+		{
+			desc: "TOML",
+			file: "config.toml",
+			content: `
+log-level = "debug"
+"tunnel.connections" = 2
+
+[topology]
+min-patch-interval = "10s"
+namespaces = ["default", "apps"]
+
+[tracing]
+sample-ratio = 0.5
+`,
+			wantValues: Values{
+				"log-level":                   {"debug"},
+				"topology.min-patch-interval": {"10s"},
+				"topology.namespaces":         {"default", "apps"},
+				"tunnel.connections":          {"2"},
+				"tracing.sample-ratio":        {"0.5"},
+			},
+		},
+		{
+			desc:       "empty YAML",
+			file:       "config.yml",
+			wantValues: Values{},
+		},
+		{
+			desc: "flag set twice",
+			file: "config.yaml",
+			content: `
+topology.namespaces: [default]
+topology:
+  namespaces: [apps]
+`,
+			wantErr: `parse configuration file: flag "topology.namespaces" set twice`,
+		},
+		{
+			desc:    "unsupported value",
+			file:    "config.yaml",
+			content: "topology.namespaces: [[default]]",
+			wantErr: `parse configuration file: flag "topology.namespaces": unsupported value of type []interface {}`,
+		},
+		{
+			desc:    "invalid YAML",
+			file:    "config.yaml",
+			content: "log-level: [",
+			wantErr: "parse configuration file: ",
+		},
+		{
+			desc:    "unsupported extension",
+			file:    "config.json",
+			content: `{"log-level": "debug"}`,
+			wantErr: `unsupported configuration file extension ".json", expected .yaml, .yml or .toml`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), test.file)
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			values, err := Read(path)
+			if test.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantValues, values)
+		})
+	}
+}
+
+func TestValues_Changed(t *testing.T) {
+	previous := Values{
+		"log-level":           {"info"},
+		"topology.namespaces": {"default"},
+		"tunnel.connections":  {"2"},
+	}
+	current := Values{
+		"log-level":           {"debug"},
+		"topology.namespaces": {"default"},
+		"tunnel.quic":         {"true"},
+	}
+
+	assert.Equal(t, []string{"log-level", "tunnel.connections", "tunnel.quic"}, previous.Changed(current))
+	assert.Empty(t, current.Changed(current))
+}
+
+func TestWatcher_Run(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("log-level: info"), 0o600))
+
+	w, err := NewWatcher(path, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	type change struct {
+		previous, current Values
+	}
+	changes := make(chan change, 1)
+	w.AddListener(func(previous, current Values) {
+		changes <- change{previous: previous, current: current}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go w.Run(ctx)
+
+	// Invalid and empty files are ignored, the current values are kept.
+	writeFile(t, path, "log-level: [")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, Values{"log-level": {"info"}}, w.Values())
+
+	writeFile(t, path, "")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, Values{"log-level": {"info"}}, w.Values())
+
+	writeFile(t, path, "log-level: debug")
+
+	select {
+	case c := <-changes:
+		assert.Equal(t, Values{"log-level": {"info"}}, c.previous)
+		assert.Equal(t, Values{"log-level": {"debug"}}, c.current)
+	case <-time.After(time.Second):
+		require.Fail(t, "configuration change not notified")
+	}
+
+	assert.Equal(t, Values{"log-level": {"debug"}}, w.Values())
+}
+
+// writeFile replaces the file at the given path with the given content at once, the way ConfigMap volumes are updated,
+// so that it's never read half-written.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
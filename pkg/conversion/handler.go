@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubv1alpha2 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// NewRegistry returns a scheme knowing every version of the Hub API and how to convert objects between them.
+func NewRegistry() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+
+	builder := runtime.NewSchemeBuilder(hubv1alpha1.AddToScheme, hubv1alpha2.AddToScheme)
+	if err := builder.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("build conversion registry: %w", err)
+	}
+
+	return scheme, nil
+}
+
+// Handler is an HTTP handler that can be used as a Kubernetes CRD conversion webhook. It converts Hub resources from
+// one API version to another, so that several versions of the Hub API can be served at the same time.
+type Handler struct {
+	registry *runtime.Scheme
+}
+
+// NewHandler returns a new Handler converting objects using the conversions of the given registry.
+func NewHandler(registry *runtime.Scheme) *Handler {
+	return &Handler{registry: registry}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var review Review
+	if err := json.NewDecoder(req.Body).Decode(&review); err != nil {
+		log.Error().Err(err).Msg("Unable to decode conversion review")
+		http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if review.Request == nil {
+		log.Error().Msg("Received a conversion review without request")
+		http.Error(rw, "missing conversion request", http.StatusUnprocessableEntity)
+		return
+	}
+
+	logger := log.With().
+		Str("uid", string(review.Request.UID)).
+		Str("desired_api_version", review.Request.DesiredAPIVersion).
+		Logger()
+
+	review.Response = &Response{
+		UID:    review.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+
+	converted, err := h.convertAll(review.Request)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to convert objects")
+
+		review.Response.Result = metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+		}
+	} else {
+		review.Response.ConvertedObjects = converted
+	}
+	review.Request = nil
+
+	if err = json.NewEncoder(rw).Encode(review); err != nil {
+		logger.Error().Err(err).Msg("Unable to encode conversion review")
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) convertAll(req *Request) ([]runtime.RawExtension, error) {
+	desiredGV, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("parse desired API version: %w", err)
+	}
+
+	// The API server expects all objects to be converted, partial conversions are reported as failures.
+	converted := make([]runtime.RawExtension, 0, len(req.Objects))
+	for i, obj := range req.Objects {
+		raw, err := h.convert(obj.Raw, desiredGV)
+		if err != nil {
+			return nil, fmt.Errorf("convert object %d: %w", i, err)
+		}
+
+		converted = append(converted, runtime.RawExtension{Raw: raw})
+	}
+
+	return converted, nil
+}
+
+func (h *Handler) convert(raw []byte, desiredGV schema.GroupVersion) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("unmarshal type meta: %w", err)
+	}
+
+	gvk := typeMeta.GroupVersionKind()
+	if gvk.GroupVersion() == desiredGV {
+		return raw, nil
+	}
+
+	if gvk.Group != desiredGV.Group {
+		return nil, fmt.Errorf("cannot convert %s to another group %q", gvk, desiredGV.Group)
+	}
+
+	in, err := h.registry.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported object %s: %w", gvk, err)
+	}
+
+	if err = json.Unmarshal(raw, in); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", gvk, err)
+	}
+
+	desiredGVK := desiredGV.WithKind(gvk.Kind)
+	out, err := h.registry.New(desiredGVK)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported object %s: %w", desiredGVK, err)
+	}
+
+	if err = h.registry.Convert(in, out, nil); err != nil {
+		return nil, fmt.Errorf("convert %s to %s: %w", gvk, desiredGVK, err)
+	}
+
+	out.GetObjectKind().SetGroupVersionKind(desiredGVK)
+
+	return json.Marshal(out)
+}
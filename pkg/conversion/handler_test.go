@@ -0,0 +1,163 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package conversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc              string
+		desiredAPIVersion string
+		objects           []string
+		wantStatus        string
+		wantMessage       string
+		wantObjects       []string
+	}{
+		{
+			desc:              "convert EdgeIngress from v1alpha1 to v1alpha2",
+			desiredAPIVersion: "hub.traefik.io/v1alpha2",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"EdgeIngress","metadata":{"name":"edge","namespace":"ns"},"spec":{"service":{"name":"whoami","port":80},"acp":{"name":"acp"}},"status":{"domain":"majestic-beaver-123.hub-traefik.io","connection":"UP"}}`,
+			},
+			wantStatus: metav1.StatusSuccess,
+			wantObjects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"EdgeIngress","metadata":{"name":"edge","namespace":"ns","creationTimestamp":null},"spec":{"service":{"name":"whoami","port":80},"acp":{"name":"acp"}},"status":{"syncedAt":null,"domain":"majestic-beaver-123.hub-traefik.io","connection":"UP"}}`,
+			},
+		},
+		{
+			desc:              "convert APIGateways from v1alpha2 to v1alpha1",
+			desiredAPIVersion: "hub.traefik.io/v1alpha1",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"APIGateway","metadata":{"name":"gw-1"},"spec":{"apiAccesses":["access"]},"status":{"urls":"https://api.example.com"}}`,
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"APIGateway","metadata":{"name":"gw-2"},"spec":{"customDomains":["api.example.com"]}}`,
+			},
+			wantStatus: metav1.StatusSuccess,
+			wantObjects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"APIGateway","metadata":{"name":"gw-1","creationTimestamp":null},"spec":{"apiAccesses":["access"]},"status":{"syncedAt":null,"urls":"https://api.example.com","hubDomain":""}}`,
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"APIGateway","metadata":{"name":"gw-2","creationTimestamp":null},"spec":{"customDomains":["api.example.com"]},"status":{"syncedAt":null,"urls":"","hubDomain":""}}`,
+			},
+		},
+		{
+			desc:              "objects already in the desired version are left untouched",
+			desiredAPIVersion: "hub.traefik.io/v1alpha1",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"API","metadata":{"name":"api"},"spec":{"pathPrefix":"/api"}}`,
+			},
+			wantStatus: metav1.StatusSuccess,
+			wantObjects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"API","metadata":{"name":"api"},"spec":{"pathPrefix":"/api"}}`,
+			},
+		},
+		{
+			desc:              "unsupported kind",
+			desiredAPIVersion: "hub.traefik.io/v1alpha2",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"APIGateway","metadata":{"name":"gw"}}`,
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"API","metadata":{"name":"api"}}`,
+			},
+			wantStatus:  metav1.StatusFailure,
+			wantMessage: `convert object 1: unsupported object hub.traefik.io/v1alpha2, Kind=API: no kind "API" is registered for version "hub.traefik.io/v1alpha2" in scheme "{scheme}"`,
+		},
+		{
+			desc:              "conversion to another group",
+			desiredAPIVersion: "traefik.io/v1alpha1",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"EdgeIngress","metadata":{"name":"edge"}}`,
+			},
+			wantStatus:  metav1.StatusFailure,
+			wantMessage: `convert object 0: cannot convert hub.traefik.io/v1alpha1, Kind=EdgeIngress to another group "traefik.io"`,
+		},
+		{
+			desc:              "invalid desired API version",
+			desiredAPIVersion: "hub.traefik.io/v1alpha2/v3",
+			wantStatus:        metav1.StatusFailure,
+			wantMessage:       `parse desired API version: unexpected GroupVersion string: hub.traefik.io/v1alpha2/v3`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			registry, err := NewRegistry()
+			require.NoError(t, err)
+
+			review := Review{
+				TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+				Request: &Request{
+					UID:               "uid",
+					DesiredAPIVersion: test.desiredAPIVersion,
+				},
+			}
+			for _, obj := range test.objects {
+				review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{Raw: []byte(obj)})
+			}
+
+			b, err := json.Marshal(review)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/conversion", bytes.NewReader(b))
+
+			NewHandler(registry).ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var got Review
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+
+			assert.Equal(t, review.TypeMeta, got.TypeMeta)
+			assert.Nil(t, got.Request)
+			require.NotNil(t, got.Response)
+			assert.Equal(t, review.Request.UID, got.Response.UID)
+			assert.Equal(t, test.wantStatus, got.Response.Result.Status)
+			assert.Equal(t, strings.ReplaceAll(test.wantMessage, "{scheme}", registry.Name()), got.Response.Result.Message)
+
+			require.Len(t, got.Response.ConvertedObjects, len(test.wantObjects))
+			for i, wantObject := range test.wantObjects {
+				assert.JSONEq(t, wantObject, string(got.Response.ConvertedObjects[i].Raw))
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_invalidReview(t *testing.T) {
+	registry, err := NewRegistry()
+	require.NoError(t, err)
+
+	for _, body := range []string{"invalid", "{}"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/conversion", bytes.NewReader([]byte(body)))
+
+		NewHandler(registry).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, body)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package conversion
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The types below mirror the apiextensions.k8s.io/v1 ConversionReview API. They are defined here to avoid depending
+// on k8s.io/apiextensions-apiserver and its dependencies for three structs.
+
+// Review describes a conversion request/response.
+type Review struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Request  *Request  `json:"request,omitempty"`
+	Response *Response `json:"response,omitempty"`
+}
+
+// Request describes the conversion request parameters.
+type Request struct {
+	// UID is an identifier for the individual request/response.
+	UID types.UID `json:"uid"`
+	// DesiredAPIVersion is the version to convert given objects to, e.g. "hub.traefik.io/v1alpha2".
+	DesiredAPIVersion string `json:"desiredAPIVersion"`
+	// Objects is the list of custom resource objects to be converted.
+	Objects []runtime.RawExtension `json:"objects"`
+}
+
+// Response describes a conversion response.
+type Response struct {
+	// UID is an identifier for the individual request/response. It must be copied over from the corresponding Request.
+	UID types.UID `json:"uid"`
+	// ConvertedObjects is the list of converted objects, in the same order as the request ones.
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	// Result contains the result of conversion with extra details if the conversion failed.
+	Result metav1.Status `json:"result"`
+}
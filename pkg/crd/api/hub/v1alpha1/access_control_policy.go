@@ -32,6 +32,8 @@ import (
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // AccessControlPolicy defines an access control policy.
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:resource:scope=Cluster
 type AccessControlPolicy struct {
 	metav1.TypeMeta `json:",inline"`
@@ -53,6 +55,10 @@ type AccessControlPolicySpec struct {
 	OIDC       *AccessControlPolicyOIDC       `json:"oidc,omitempty"`
 	OIDCGoogle *AccessControlPolicyOIDCGoogle `json:"oidcGoogle,omitempty"`
 	OAuthIntro *AccessControlOAuthIntro       `json:"oAuthIntro,omitempty"`
+
+	// Headers transforms the headers of the requests authorized by the ACP, and of their responses.
+	// +optional
+	Headers *AccessControlPolicyHeaders `json:"headers,omitempty"`
 }
 
 // Hash return AccessControlPolicySpec hash.
@@ -68,6 +74,40 @@ func (a AccessControlPolicySpec) Hash() (string, error) {
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
 }
 
+// AccessControlPolicyHeaders configures the header transformations of an access control policy.
+type AccessControlPolicyHeaders struct {
+	// Request transforms the headers of the authorized requests before they reach the upstream service.
+	// +optional
+	Request *AccessControlPolicyRequestHeaders `json:"request,omitempty"`
+	// Response transforms the headers of the responses sent back to the clients. Only supported by Traefik.
+	// +optional
+	Response *AccessControlPolicyResponseHeaders `json:"response,omitempty"`
+}
+
+// AccessControlPolicyRequestHeaders transforms the headers of the authorized requests.
+type AccessControlPolicyRequestHeaders struct {
+	// Set sets headers. Values are Go templates, which can reference the claims of the authorized request with
+	// `{{ claim "sub" }}` and its headers with `{{ header "X-Request-Id" }}`.
+	// +optional
+	Set map[string]string `json:"set,omitempty"`
+	// Remove removes headers.
+	// +optional
+	Remove []string `json:"remove,omitempty"`
+	// Rename renames headers, from their current name to their new one.
+	// +optional
+	Rename map[string]string `json:"rename,omitempty"`
+}
+
+// AccessControlPolicyResponseHeaders transforms the headers of the responses.
+type AccessControlPolicyResponseHeaders struct {
+	// Set sets headers.
+	// +optional
+	Set map[string]string `json:"set,omitempty"`
+	// Remove removes headers.
+	// +optional
+	Remove []string `json:"remove,omitempty"`
+}
+
 // AccessControlPolicyJWT configures a JWT access control policy.
 type AccessControlPolicyJWT struct {
 	SigningSecret              string            `json:"signingSecret,omitempty"`
@@ -99,6 +139,20 @@ type AccessControlPolicyAPIKey struct {
 	Keys []AccessControlPolicyAPIKeyKey `json:"keys,omitempty"`
 	// ForwardHeaders instructs the middleware to forward key metadata as header values upon successful authentication.
 	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty"`
+	// Quota limits the number of requests each key can make.
+	Quota *AccessControlPolicyAPIKeyQuota `json:"quota,omitempty"`
+	// IssuedKeys accepts the API keys issued by the dev portals to their users, in addition to Keys.
+	IssuedKeys bool `json:"issuedKeys,omitempty"`
+}
+
+// AccessControlPolicyAPIKeyQuota defines the number of requests each API key can make per period.
+type AccessControlPolicyAPIKeyQuota struct {
+	// Limit is the number of requests a key can make within a period.
+	// +kubebuilder:validation:Minimum:=1
+	Limit int `json:"limit"`
+	// Period is the duration after which the requests made by a key are no longer counted.
+	// +kubebuilder:validation:Required
+	Period metav1.Duration `json:"period"`
 }
 
 // AccessControlPolicyAPIKeyKey defines an API key.
@@ -107,8 +161,13 @@ type AccessControlPolicyAPIKeyKey struct {
 	// +kubebuilder:validation:Required
 	ID string `json:"id"`
 	// Value is the SHAKE-256 hash (using 64 bytes) of the API key.
-	// +kubebuilder:validation:Required
-	Value string `json:"value"`
+	// Either Value or ValueFrom must be set.
+	// +optional
+	Value string `json:"value,omitempty"`
+	// ValueFrom references the secret entry holding the SHAKE-256 hash of the API key, which defaults to the "value"
+	// entry. The namespace of the secret is required.
+	// +optional
+	ValueFrom *SecretReference `json:"valueFrom,omitempty"`
 	// Metadata holds arbitrary metadata for this key, can be used by ForwardHeaders.
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
@@ -130,6 +189,8 @@ type AccessControlPolicyOIDC struct {
 	Scopes         []string          `json:"scopes,omitempty"`
 	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty"`
 	Claims         string            `json:"claims,omitempty"`
+
+	Pages *OIDCPages `json:"pages,omitempty"`
 }
 
 // AccessControlPolicyOIDCGoogle holds the Google OIDC authentication configuration.
@@ -149,6 +210,17 @@ type AccessControlPolicyOIDCGoogle struct {
 	// Emails are the allowed emails to connect.
 	// +kubebuilder:validation:MinItems:=1
 	Emails []string `json:"emails,omitempty"`
+
+	Pages *OIDCPages `json:"pages,omitempty"`
+}
+
+// OIDCPages customizes the login, error and logout pages served during the OIDC login flow.
+type OIDCPages struct {
+	// Locale forces the locale of the pages instead of negotiating it with the Accept-Language header.
+	Locale string `json:"locale,omitempty"`
+	// Templates overrides page templates. Keys are a page name (login, error or logout),
+	// optionally suffixed with a locale to only override this locale, e.g. `error.fr`.
+	Templates map[string]string `json:"templates,omitempty"`
 }
 
 // StateCookie holds state cookie configuration.
@@ -247,6 +319,12 @@ type AccessControlPolicyStatus struct {
 	Version  string      `json:"version,omitempty"`
 	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
 	SpecHash string      `json:"specHash,omitempty"`
+
+	// Conditions are the latest observations of the AccessControlPolicy state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
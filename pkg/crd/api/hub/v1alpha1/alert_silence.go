@@ -0,0 +1,78 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertSilence silences the alerts matching its matchers during a time window, for instance during a planned
+// maintenance.
+// +kubebuilder:printcolumn:name="Starts At",type=date,JSONPath=`.spec.startsAt`
+// +kubebuilder:printcolumn:name="Ends At",type=date,JSONPath=`.spec.endsAt`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:scope=Cluster
+type AlertSilence struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AlertSilenceSpec `json:"spec,omitempty"`
+}
+
+// AlertSilenceSpec configures an AlertSilence.
+type AlertSilenceSpec struct {
+	// StartsAt is the time from which alerts are silenced. Alerts are silenced right away if not set.
+	// +optional
+	StartsAt *metav1.Time `json:"startsAt,omitempty"`
+	// EndsAt is the time from which alerts are not silenced anymore.
+	EndsAt metav1.Time `json:"endsAt"`
+	// Matchers select the silenced alerts, an alert is silenced if it matches all of them.
+	// +kubebuilder:validation:MinItems=1
+	Matchers []AlertMatcher `json:"matchers"`
+	// Comment describes why alerts are silenced.
+	// +optional
+	Comment string `json:"comment,omitempty"`
+}
+
+// AlertMatcher matches a label of alerts.
+type AlertMatcher struct {
+	// Label is the matched alert label.
+	// +kubebuilder:validation:Enum=rule;ingress;service;namespace
+	Label string `json:"label"`
+	// Value is the value the label must be equal to, or the regular expression it must match if Regex is true.
+	Value string `json:"value"`
+	// Regex enables matching the label against a regular expression.
+	// +optional
+	Regex bool `json:"regex,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertSilenceList defines a list of AlertSilences.
+type AlertSilenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []AlertSilence `json:"items"`
+}
@@ -28,6 +28,8 @@ import (
 // +kubebuilder:printcolumn:name="PathPrefix",type=string,JSONPath=`.spec.pathPrefix`
 // +kubebuilder:printcolumn:name="ServiceName",type=string,JSONPath=`.spec.service.name`
 // +kubebuilder:printcolumn:name="ServicePort",type=string,JSONPath=`.spec.service.port.number`
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type API struct {
 	metav1.TypeMeta `json:",inline"`
 	// +optional
@@ -44,6 +46,82 @@ type API struct {
 type APISpec struct {
 	PathPrefix string     `json:"pathPrefix"`
 	Service    APIService `json:"service"`
+	// VersionHeader restricts the API to requests carrying the given version header.
+	// It allows multiple APIs to share the same PathPrefix.
+	// +optional
+	VersionHeader *APIVersionHeader `json:"versionHeader,omitempty"`
+	// Matchers restricts the API to requests carrying the given header values and query parameters.
+	// It allows multiple APIs to share the same PathPrefix.
+	// +optional
+	Matchers *APIMatchers `json:"matchers,omitempty"`
+	// Deprecation marks the API as deprecated.
+	// +optional
+	Deprecation *APIDeprecation `json:"deprecation,omitempty"`
+	// Sandbox configures the sandbox environment of the API. Requests flagged as sandbox requests, such as the ones
+	// sent from the portal's try-it console, are routed to the sandbox service instead of the API service.
+	// +optional
+	Sandbox *APISandbox `json:"sandbox,omitempty"`
+	// MaxRequestBodyBytes is the maximum size of the request bodies accepted by the API. Larger requests are rejected
+	// with a 413 status code. It overrides the limit set on the APIGateways exposing the API.
+	// +optional
+	// +kubebuilder:validation:Minimum:=1
+	MaxRequestBodyBytes *int64 `json:"maxRequestBodyBytes,omitempty"`
+	// AllowedMethods restricts the HTTP methods accepted by the API. Requests using other methods are rejected with a
+	// 405 status code. All methods are accepted when empty.
+	// +optional
+	// +kubebuilder:validation:items:Enum=GET;HEAD;POST;PUT;PATCH;DELETE;OPTIONS;TRACE;CONNECT
+	AllowedMethods []string `json:"allowedMethods,omitempty"`
+	// ValidateRequests enables the validation of the requests against the OpenAPI spec of the API. Requests sent to
+	// unknown paths or methods, with unsupported content types or invalid parameters are rejected.
+	// +optional
+	ValidateRequests bool `json:"validateRequests,omitempty"`
+	// CORS configures the Cross-Origin Resource Sharing policy of the API.
+	// +optional
+	CORS *CORS `json:"cors,omitempty"`
+}
+
+// APIVersionHeader configures the header used to route requests to a version of an API.
+type APIVersionHeader struct {
+	// Name is the name of the header holding the requested version.
+	// Defaults to Accept-Version.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Value is the version requests must ask for to be routed to this API.
+	Value string `json:"value"`
+}
+
+// APIMatchers configures the header values and query parameters requests must carry to be routed to an API.
+type APIMatchers struct {
+	// Headers are the header values requests must carry.
+	// +optional
+	Headers []APIMatcher `json:"headers,omitempty"`
+	// Query are the query parameter values requests must carry.
+	// +optional
+	Query []APIMatcher `json:"query,omitempty"`
+}
+
+// APIMatcher matches the value of a request header or query parameter.
+type APIMatcher struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// APIDeprecation configures the deprecation of an API.
+type APIDeprecation struct {
+	// Sunset is the date at which the API will be retired.
+	// +optional
+	Sunset *metav1.Time `json:"sunset,omitempty"`
+}
+
+// APISandbox configures the sandbox environment of an API.
+type APISandbox struct {
+	Service APISandboxService `json:"service"`
+}
+
+// APISandboxService configures the service serving the sandbox environment of an API.
+type APISandboxService struct {
+	Name string                `json:"name"`
+	Port APIServiceBackendPort `json:"port"`
 }
 
 // APIService configures the service to exposed on the edge.
@@ -53,6 +131,11 @@ type APIService struct {
 	// is required for an APIServiceBackendPort.
 	Port        APIServiceBackendPort `json:"port"`
 	OpenAPISpec OpenAPISpec           `json:"openApiSpec,omitempty"`
+	// ExternalURL is the URL of a backend running outside of the cluster, such as "https://payments.example.com".
+	// When set, the agent manages an ExternalName Service with the given name and port, resolving to the URL host.
+	// Any port in the URL must match the service port. Traefik must allow ExternalName services.
+	// +optional
+	ExternalURL string `json:"externalUrl,omitempty"`
 }
 
 // APIServiceBackendPort is the service port being referenced.
@@ -79,6 +162,29 @@ type OpenAPISpec struct {
 	Port *APIServiceBackendPort `json:"port,omitempty"`
 	// +optional
 	Protocol string `json:"protocol,omitempty"`
+	// Headers are added to the requests fetching the spec.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// Auth holds the credentials sent when fetching the spec.
+	// +optional
+	Auth *OpenAPISpecAuth `json:"auth,omitempty"`
+	// CA references the secret entry holding the PEM encoded certificate authorities trusted when fetching the spec
+	// over HTTPS, which defaults to the "ca.crt" entry.
+	// +optional
+	CA *SecretReference `json:"ca,omitempty"`
+}
+
+// OpenAPISpecAuth holds the credentials sent when fetching an OpenAPI spec. They are read from secrets, which default
+// to the namespace of the API.
+type OpenAPISpecAuth struct {
+	// BearerToken references the secret entry holding the token sent in the Authorization header, which defaults to
+	// the "token" entry.
+	// +optional
+	BearerToken *SecretReference `json:"bearerToken,omitempty"`
+	// Headers reference the secret entries holding the values of the headers to send, by header name. The entries
+	// default to the header names.
+	// +optional
+	Headers map[string]SecretReference `json:"headers,omitempty"`
 }
 
 // APIStatus is the status of an API.
@@ -87,6 +193,12 @@ type APIStatus struct {
 	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
 	// Hash is a hash representing the API.
 	Hash string `json:"hash,omitempty"`
+
+	// Conditions are the latest observations of the API state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -26,6 +26,8 @@ import (
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // APIAccess defines which group of consumers can access APIs and APICollections.
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:resource:scope=Cluster
 type APIAccess struct {
 	metav1.TypeMeta `json:",inline"`
@@ -53,6 +55,12 @@ type APIAccessStatus struct {
 	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
 	// Hash is a hash representing the APIAccess.
 	Hash string `json:"hash,omitempty"`
+
+	// Conditions are the latest observations of the APIAccess state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -28,6 +28,8 @@ import (
 // APICollection defines a collection of APIs exposed within an APIPortal.
 // +kubebuilder:printcolumn:name="PathPrefix",type=string,JSONPath=`.spec.pathPrefix`
 // +kubebuilder:printcolumn:name="APISelector",type=string,JSONPath=`.status.apiSelector`
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:resource:scope=Cluster
 type APICollection struct {
 	metav1.TypeMeta `json:",inline"`
@@ -59,6 +61,12 @@ type APICollectionStatus struct {
 	SyncedAt    metav1.Time `json:"syncedAt,omitempty"`
 	// Hash is a hash representing the APICollection.
 	Hash string `json:"hash,omitempty"`
+
+	// Conditions are the latest observations of the APICollection state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
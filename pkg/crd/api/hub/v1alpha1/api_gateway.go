@@ -26,8 +26,12 @@ import (
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // APIGateway defines a gateway that exposes APIs.
-// +kubebuilder:printcolumn:name="URLs",type=string,JSONPath=`.status.urls`
-// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.status.hubDomain`
+// +kubebuilder:printcolumn:name="URLs",type=string,JSONPath=`.status.urls`,priority=1
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:scope=Cluster,shortName=apigw
+// +kubebuilder:storageversion
 type APIGateway struct {
 	metav1.TypeMeta `json:",inline"`
 	// +optional
@@ -48,6 +52,15 @@ type APIGatewaySpec struct {
 	// CustomDomains are the custom domains under which the gateway will be exposed.
 	// +optional
 	CustomDomains []string `json:"customDomains,omitempty"`
+	// Certificate references the TLS secret holding the certificate served for the custom domains, in its tls.crt
+	// and tls.key entries, instead of the certificate issued by the platform. The namespace of the secret is required.
+	// +optional
+	Certificate *SecretReference `json:"certificate,omitempty"`
+	// MaxRequestBodyBytes is the maximum size of the request bodies accepted by the gateway. Larger requests are
+	// rejected with a 413 status code before reaching the API services.
+	// +optional
+	// +kubebuilder:validation:Minimum:=1
+	MaxRequestBodyBytes *int64 `json:"maxRequestBodyBytes,omitempty"`
 }
 
 // APIGatewayStatus is the status of an APIGateway.
@@ -68,6 +81,12 @@ type APIGatewayStatus struct {
 
 	// Hash is a hash representing the APIPortal.
 	Hash string `json:"hash,omitempty"`
+
+	// Conditions are the latest observations of the APIGateway state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -26,8 +26,12 @@ import (
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // APIPortal defines a portal that exposes APIs.
-// +kubebuilder:printcolumn:name="URLs",type=string,JSONPath=`.status.urls`
-// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Gateway",type=string,JSONPath=`.spec.apiGateway`
+// +kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.status.hubDomain`
+// +kubebuilder:printcolumn:name="URLs",type=string,JSONPath=`.status.urls`,priority=1
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:scope=Cluster,shortName=apiportal
 type APIPortal struct {
 	metav1.TypeMeta `json:",inline"`
 	// +optional
@@ -51,6 +55,9 @@ type APIPortalSpec struct {
 	// CustomDomains are the custom domains under which the portal will be exposed.
 	// +optional
 	CustomDomains []string `json:"customDomains,omitempty"`
+	// CORS configures the Cross-Origin Resource Sharing policy of the portal.
+	// +optional
+	CORS *CORS `json:"cors,omitempty"`
 }
 
 // APIPortalStatus is the status of an APIPortal.
@@ -71,6 +78,12 @@ type APIPortalStatus struct {
 
 	// Hash is a hash representing the APIPortal.
 	Hash string `json:"hash,omitempty"`
+
+	// Conditions are the latest observations of the APIPortal state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
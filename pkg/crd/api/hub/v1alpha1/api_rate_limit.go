@@ -0,0 +1,103 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Rate limit strategies.
+const (
+	// RateLimitStrategyPerConsumer gives each consumer its own limit.
+	RateLimitStrategyPerConsumer = "perConsumer"
+	// RateLimitStrategyShared shares the limit between all the consumers.
+	RateLimitStrategyShared = "shared"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIRateLimit limits the number of requests the consumers of a set of APIs can make.
+// +kubebuilder:printcolumn:name="Limit",type=integer,JSONPath=`.spec.limit`
+// +kubebuilder:printcolumn:name="Period",type=string,JSONPath=`.spec.period`
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:scope=Cluster
+type APIRateLimit struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec APIRateLimitSpec `json:"spec,omitempty"`
+
+	// The current status of this APIRateLimit.
+	// +optional
+	Status APIRateLimitStatus `json:"status,omitempty"`
+}
+
+// APIRateLimitSpec configures an APIRateLimit.
+type APIRateLimitSpec struct {
+	// Groups are the consumer groups the limit applies to.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+	// Everyone applies the limit to all the consumers, whatever their groups.
+	// +optional
+	Everyone bool `json:"everyone,omitempty"`
+	// APISelector selects the limited APIs.
+	APISelector *metav1.LabelSelector `json:"apiSelector,omitempty"`
+	// Limit is the number of requests allowed per period.
+	// +kubebuilder:validation:Minimum=1
+	Limit int64 `json:"limit"`
+	// Period is the period the limit applies to. It defaults to 1s.
+	// +optional
+	Period *metav1.Duration `json:"period,omitempty"`
+	// Strategy defines whether each consumer has its own limit, or whether the limit is shared between all of them.
+	// It defaults to perConsumer.
+	// +optional
+	// +kubebuilder:validation:Enum=perConsumer;shared
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// APIRateLimitStatus is the status of an APIRateLimit.
+type APIRateLimitStatus struct {
+	Version  string      `json:"version,omitempty"`
+	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
+	// Hash is a hash representing the APIRateLimit.
+	Hash string `json:"hash,omitempty"`
+	// APIs are the APIs selected by the APIRateLimit, formatted as name@namespace.
+	// +optional
+	APIs []string `json:"apis,omitempty"`
+
+	// Conditions are the latest observations of the APIRateLimit state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIRateLimitList defines a list of APIRateLimits.
+type APIRateLimitList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []APIRateLimit `json:"items"`
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIVersion defines a version of an API, exposed next to it with its own path prefix or version header.
+// +kubebuilder:printcolumn:name="API",type=string,JSONPath=`.spec.apiName`
+// +kubebuilder:printcolumn:name="Release",type=string,JSONPath=`.spec.release`
+// +kubebuilder:printcolumn:name="PathPrefix",type=string,JSONPath=`.spec.pathPrefix`
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type APIVersion struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec APIVersionSpec `json:"spec,omitempty"`
+
+	// The current status of this APIVersion.
+	// +optional
+	Status APIVersionStatus `json:"status,omitempty"`
+}
+
+// APIVersionSpec configures an APIVersion. The version inherits the configuration of its API, and is routed either
+// on its own path prefix or on a version header, or both.
+type APIVersionSpec struct {
+	// APIName is the name of the API, in the same namespace, this is a version of.
+	APIName string `json:"apiName"`
+	// Release identifies the version, such as "v2".
+	Release string `json:"release"`
+	// PathPrefix exposes the version on its own path prefix. It defaults to the one of the API.
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	// VersionHeader restricts the version to requests carrying the given version header.
+	// +optional
+	VersionHeader *APIVersionHeader `json:"versionHeader,omitempty"`
+	// Service serves the version. It defaults to the service of the API.
+	// +optional
+	Service *APIVersionService `json:"service,omitempty"`
+	// Deprecation marks the version as deprecated.
+	// +optional
+	Deprecation *APIDeprecation `json:"deprecation,omitempty"`
+}
+
+// APIVersionService configures the service serving a version of an API.
+type APIVersionService struct {
+	Name string                `json:"name"`
+	Port APIServiceBackendPort `json:"port"`
+}
+
+// APIVersionStatus is the status of an APIVersion.
+type APIVersionStatus struct {
+	Version  string      `json:"version,omitempty"`
+	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
+	// Hash is a hash representing the APIVersion.
+	Hash string `json:"hash,omitempty"`
+
+	// Conditions are the latest observations of the APIVersion state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIVersionList defines a list of APIVersions.
+type APIVersionList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []APIVersion `json:"items"`
+}
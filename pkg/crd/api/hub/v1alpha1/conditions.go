@@ -0,0 +1,103 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types reported on the status of Hub resources.
+const (
+	// ConditionReady indicates that the resource is fully set up and serving traffic.
+	ConditionReady = "Ready"
+	// ConditionSynced indicates that the resource is synchronized with the platform.
+	ConditionSynced = "Synced"
+	// ConditionCertificateProvisioned indicates that the certificates for the resource domains are provisioned.
+	ConditionCertificateProvisioned = "CertificateProvisioned"
+	// ConditionOpenAPISpecValid indicates that the OpenAPI spec referenced by the API is valid.
+	ConditionOpenAPISpecValid = "OpenAPISpecValid"
+)
+
+// Condition reasons reported on the status of Hub resources.
+const (
+	ReasonSynced                        = "Synced"
+	ReasonReady                         = "Ready"
+	ReasonCertificateProvisioned        = "CertificateProvisioned"
+	ReasonCertificateProvisioningFailed = "CertificateProvisioningFailed"
+	ReasonRoutingFailed                 = "RoutingFailed"
+	ReasonConnectionDown                = "ConnectionDown"
+	ReasonOpenAPISpecValid              = "OpenAPISpecValid"
+	ReasonOpenAPISpecInvalid            = "OpenAPISpecInvalid"
+	ReasonOpenAPISpecUnavailable        = "OpenAPISpecUnavailable"
+)
+
+// Event reasons recorded on Hub resources, so that their synchronization can be followed with `kubectl describe`.
+const (
+	EventReasonCreated    = "Created"
+	EventReasonUpdated    = "Updated"
+	EventReasonSyncFailed = "SyncFailed"
+)
+
+// NewCondition returns a condition of the given type which transitioned at the given time.
+func NewCondition(conditionType string, status metav1.ConditionStatus, reason, message string, transitionTime metav1.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: transitionTime,
+	}
+}
+
+// SetConditions sets the given conditions on the list, keeping the last transition time of conditions whose status
+// didn't change. It returns true if any condition was added or modified, transition times set by the
+// given conditions excepted.
+func SetConditions(conditions *[]metav1.Condition, newConditions ...metav1.Condition) bool {
+	var changed bool
+	for _, newCondition := range newConditions {
+		existing := meta.FindStatusCondition(*conditions, newCondition.Type)
+		if existing == nil ||
+			existing.Status != newCondition.Status ||
+			existing.Reason != newCondition.Reason ||
+			existing.Message != newCondition.Message ||
+			existing.ObservedGeneration != newCondition.ObservedGeneration {
+			changed = true
+		}
+
+		meta.SetStatusCondition(conditions, newCondition)
+	}
+
+	return changed
+}
+
+// MergeConditions returns a copy of the current conditions updated with the given conditions.
+// Conditions that are not given are left untouched.
+func MergeConditions(current []metav1.Condition, newConditions ...metav1.Condition) []metav1.Condition {
+	conditions := make([]metav1.Condition, 0, len(current)+len(newConditions))
+	for _, condition := range current {
+		conditions = append(conditions, *condition.DeepCopy())
+	}
+
+	SetConditions(&conditions, newConditions...)
+
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	return conditions
+}
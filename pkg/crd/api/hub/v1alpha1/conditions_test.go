@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetConditions(t *testing.T) {
+	past := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		desc           string
+		conditions     []metav1.Condition
+		newConditions  []metav1.Condition
+		wantConditions []metav1.Condition
+		wantChanged    bool
+	}{
+		{
+			desc:          "add a condition",
+			newConditions: []metav1.Condition{NewCondition(ConditionReady, metav1.ConditionTrue, ReasonReady, "ready", now)},
+			wantConditions: []metav1.Condition{
+				NewCondition(ConditionReady, metav1.ConditionTrue, ReasonReady, "ready", now),
+			},
+			wantChanged: true,
+		},
+		{
+			desc: "same status keeps the last transition time",
+			conditions: []metav1.Condition{
+				NewCondition(ConditionReady, metav1.ConditionTrue, ReasonReady, "ready", past),
+			},
+			newConditions: []metav1.Condition{NewCondition(ConditionReady, metav1.ConditionTrue, ReasonReady, "ready", now)},
+			wantConditions: []metav1.Condition{
+				NewCondition(ConditionReady, metav1.ConditionTrue, ReasonReady, "ready", past),
+			},
+		},
+		{
+			desc: "same status with a new message",
+			conditions: []metav1.Condition{
+				NewCondition(ConditionReady, metav1.ConditionFalse, ReasonRoutingFailed, "boom", past),
+			},
+			newConditions: []metav1.Condition{NewCondition(ConditionReady, metav1.ConditionFalse, ReasonRoutingFailed, "bang", now)},
+			wantConditions: []metav1.Condition{
+				NewCondition(ConditionReady, metav1.ConditionFalse, ReasonRoutingFailed, "bang", past),
+			},
+			wantChanged: true,
+		},
+		{
+			desc: "status transition",
+			conditions: []metav1.Condition{
+				NewCondition(ConditionSynced, metav1.ConditionTrue, ReasonSynced, "synced", past),
+				NewCondition(ConditionReady, metav1.ConditionFalse, ReasonConnectionDown, "down", past),
+			},
+			newConditions: []metav1.Condition{NewCondition(ConditionReady, metav1.ConditionTrue, ReasonReady, "up", now)},
+			wantConditions: []metav1.Condition{
+				NewCondition(ConditionSynced, metav1.ConditionTrue, ReasonSynced, "synced", past),
+				NewCondition(ConditionReady, metav1.ConditionTrue, ReasonReady, "up", now),
+			},
+			wantChanged: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conditions := MergeConditions(test.conditions)
+			changed := SetConditions(&conditions, test.newConditions...)
+
+			assert.Equal(t, test.wantChanged, changed)
+			assert.Equal(t, test.wantConditions, conditions)
+		})
+	}
+}
+
+func TestMergeConditions_leavesCurrentUntouched(t *testing.T) {
+	past := metav1.NewTime(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	current := []metav1.Condition{NewCondition(ConditionReady, metav1.ConditionTrue, ReasonReady, "ready", past)}
+
+	merged := MergeConditions(current, NewCondition(ConditionReady, metav1.ConditionFalse, ReasonConnectionDown, "down", past))
+
+	assert.Equal(t, metav1.ConditionTrue, current[0].Status)
+	assert.Equal(t, metav1.ConditionFalse, merged[0].Status)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha1
+
+// CORS configures the Cross-Origin Resource Sharing policy applied to the responses, so browsers allow web
+// applications served from other origins to send requests.
+type CORS struct {
+	// AllowOrigins are the origins allowed to send requests, such as "https://example.com". "*" allows any origin.
+	// +kubebuilder:validation:MinItems=1
+	AllowOrigins []string `json:"allowOrigins"`
+	// AllowMethods are the methods allowed in cross-origin requests.
+	// +optional
+	AllowMethods []string `json:"allowMethods,omitempty"`
+	// AllowHeaders are the headers allowed in cross-origin requests.
+	// +optional
+	AllowHeaders []string `json:"allowHeaders,omitempty"`
+	// ExposeHeaders are the response headers browsers expose to the web applications.
+	// +optional
+	ExposeHeaders []string `json:"exposeHeaders,omitempty"`
+	// AllowCredentials allows cross-origin requests to carry credentials, such as cookies.
+	// +optional
+	AllowCredentials bool `json:"allowCredentials,omitempty"`
+	// MaxAge is the number of seconds browsers cache the result of preflight requests.
+	// +optional
+	// +kubebuilder:validation:Minimum:=0
+	MaxAge int64 `json:"maxAge,omitempty"`
+}
@@ -48,6 +48,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&IngressClassList{},
 		&AccessControlPolicy{},
 		&AccessControlPolicyList{},
+		&AlertSilence{},
+		&AlertSilenceList{},
 		&EdgeIngress{},
 		&EdgeIngressList{},
 		&APIGateway{},
@@ -60,6 +62,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&APICollectionList{},
 		&APIAccess{},
 		&APIAccessList{},
+		&APIRateLimit{},
+		&APIRateLimitList{},
+		&APIVersion{},
+		&APIVersionList{},
 	)
 
 	metav1.AddToGroupVersion(
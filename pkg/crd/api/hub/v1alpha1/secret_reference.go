@@ -0,0 +1,33 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha1
+
+// SecretReference references a Kubernetes secret, or one of its entries.
+type SecretReference struct {
+	// Name is the name of the secret.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Namespace is the namespace of the secret. It defaults to the namespace of the referencing resource, and is
+	// required when the referencing resource is cluster scoped.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Key is the entry of the secret holding the value. It defaults to an entry specific to each use of the
+	// reference, and is ignored when the whole secret is used, as for certificates.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
@@ -151,6 +151,13 @@ func (in *APIAccessSpec) DeepCopy() *APIAccessSpec {
 func (in *APIAccessStatus) DeepCopyInto(out *APIAccessStatus) {
 	*out = *in
 	in.SyncedAt.DeepCopyInto(&out.SyncedAt)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -246,6 +253,13 @@ func (in *APICollectionSpec) DeepCopy() *APICollectionSpec {
 func (in *APICollectionStatus) DeepCopyInto(out *APICollectionStatus) {
 	*out = *in
 	in.SyncedAt.DeepCopyInto(&out.SyncedAt)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -259,6 +273,26 @@ func (in *APICollectionStatus) DeepCopy() *APICollectionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIDeprecation) DeepCopyInto(out *APIDeprecation) {
+	*out = *in
+	if in.Sunset != nil {
+		in, out := &in.Sunset, &out.Sunset
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIDeprecation.
+func (in *APIDeprecation) DeepCopy() *APIDeprecation {
+	if in == nil {
+		return nil
+	}
+	out := new(APIDeprecation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIGateway) DeepCopyInto(out *APIGateway) {
 	*out = *in
@@ -333,6 +367,16 @@ func (in *APIGatewaySpec) DeepCopyInto(out *APIGatewaySpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(SecretReference)
+		**out = **in
+	}
+	if in.MaxRequestBodyBytes != nil {
+		in, out := &in.MaxRequestBodyBytes, &out.MaxRequestBodyBytes
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -355,6 +399,13 @@ func (in *APIGatewayStatus) DeepCopyInto(out *APIGatewayStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -401,6 +452,48 @@ func (in *APIList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIMatcher) DeepCopyInto(out *APIMatcher) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIMatcher.
+func (in *APIMatcher) DeepCopy() *APIMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(APIMatcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIMatchers) DeepCopyInto(out *APIMatchers) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]APIMatcher, len(*in))
+		copy(*out, *in)
+	}
+	if in.Query != nil {
+		in, out := &in.Query, &out.Query
+		*out = make([]APIMatcher, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIMatchers.
+func (in *APIMatchers) DeepCopy() *APIMatchers {
+	if in == nil {
+		return nil
+	}
+	out := new(APIMatchers)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIPortal) DeepCopyInto(out *APIPortal) {
 	*out = *in
@@ -470,6 +563,11 @@ func (in *APIPortalSpec) DeepCopyInto(out *APIPortalSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.CORS != nil {
+		in, out := &in.CORS, &out.CORS
+		*out = new(CORS)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -492,6 +590,13 @@ func (in *APIPortalStatus) DeepCopyInto(out *APIPortalStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -506,247 +611,646 @@ func (in *APIPortalStatus) DeepCopy() *APIPortalStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *APIService) DeepCopyInto(out *APIService) {
+func (in *APIRateLimit) DeepCopyInto(out *APIRateLimit) {
 	*out = *in
-	out.Port = in.Port
-	in.OpenAPISpec.DeepCopyInto(&out.OpenAPISpec)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIService.
-func (in *APIService) DeepCopy() *APIService {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimit.
+func (in *APIRateLimit) DeepCopy() *APIRateLimit {
 	if in == nil {
 		return nil
 	}
-	out := new(APIService)
+	out := new(APIRateLimit)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *APIRateLimit) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *APIServiceBackendPort) DeepCopyInto(out *APIServiceBackendPort) {
+func (in *APIRateLimitList) DeepCopyInto(out *APIRateLimitList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]APIRateLimit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServiceBackendPort.
-func (in *APIServiceBackendPort) DeepCopy() *APIServiceBackendPort {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimitList.
+func (in *APIRateLimitList) DeepCopy() *APIRateLimitList {
 	if in == nil {
 		return nil
 	}
-	out := new(APIServiceBackendPort)
+	out := new(APIRateLimitList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *APIRateLimitList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *APISpec) DeepCopyInto(out *APISpec) {
+func (in *APIRateLimitSpec) DeepCopyInto(out *APIRateLimitSpec) {
 	*out = *in
-	in.Service.DeepCopyInto(&out.Service)
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.APISelector != nil {
+		in, out := &in.APISelector, &out.APISelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APISpec.
-func (in *APISpec) DeepCopy() *APISpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimitSpec.
+func (in *APIRateLimitSpec) DeepCopy() *APIRateLimitSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(APISpec)
+	out := new(APIRateLimitSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *APIStatus) DeepCopyInto(out *APIStatus) {
+func (in *APIRateLimitStatus) DeepCopyInto(out *APIRateLimitStatus) {
 	*out = *in
 	in.SyncedAt.DeepCopyInto(&out.SyncedAt)
+	if in.APIs != nil {
+		in, out := &in.APIs, &out.APIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIStatus.
-func (in *APIStatus) DeepCopy() *APIStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimitStatus.
+func (in *APIRateLimitStatus) DeepCopy() *APIRateLimitStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(APIStatus)
+	out := new(APIRateLimitStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlOAuthIntro) DeepCopyInto(out *AccessControlOAuthIntro) {
+func (in *APISandbox) DeepCopyInto(out *APISandbox) {
 	*out = *in
-	in.ClientConfig.DeepCopyInto(&out.ClientConfig)
-	out.TokenSource = in.TokenSource
-	if in.ForwardHeaders != nil {
-		in, out := &in.ForwardHeaders, &out.ForwardHeaders
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
+	out.Service = in.Service
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlOAuthIntro.
-func (in *AccessControlOAuthIntro) DeepCopy() *AccessControlOAuthIntro {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APISandbox.
+func (in *APISandbox) DeepCopy() *APISandbox {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlOAuthIntro)
+	out := new(APISandbox)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlOAuthIntroClientConfig) DeepCopyInto(out *AccessControlOAuthIntroClientConfig) {
+func (in *APISandboxService) DeepCopyInto(out *APISandboxService) {
 	*out = *in
-	in.HTTPClientConfig.DeepCopyInto(&out.HTTPClientConfig)
-	out.Auth = in.Auth
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
+	out.Port = in.Port
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlOAuthIntroClientConfig.
-func (in *AccessControlOAuthIntroClientConfig) DeepCopy() *AccessControlOAuthIntroClientConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APISandboxService.
+func (in *APISandboxService) DeepCopy() *APISandboxService {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlOAuthIntroClientConfig)
+	out := new(APISandboxService)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlOAuthIntroClientConfigAuth) DeepCopyInto(out *AccessControlOAuthIntroClientConfigAuth) {
+func (in *APIService) DeepCopyInto(out *APIService) {
 	*out = *in
-	out.Secret = in.Secret
+	out.Port = in.Port
+	in.OpenAPISpec.DeepCopyInto(&out.OpenAPISpec)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlOAuthIntroClientConfigAuth.
-func (in *AccessControlOAuthIntroClientConfigAuth) DeepCopy() *AccessControlOAuthIntroClientConfigAuth {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIService.
+func (in *APIService) DeepCopy() *APIService {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlOAuthIntroClientConfigAuth)
+	out := new(APIService)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicy) DeepCopyInto(out *AccessControlPolicy) {
+func (in *APIServiceBackendPort) DeepCopyInto(out *APIServiceBackendPort) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicy.
-func (in *AccessControlPolicy) DeepCopy() *AccessControlPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServiceBackendPort.
+func (in *APIServiceBackendPort) DeepCopy() *APIServiceBackendPort {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicy)
+	out := new(APIServiceBackendPort)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *AccessControlPolicy) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicyAPIKey) DeepCopyInto(out *AccessControlPolicyAPIKey) {
+func (in *APISpec) DeepCopyInto(out *APISpec) {
 	*out = *in
-	out.KeySource = in.KeySource
-	if in.Keys != nil {
-		in, out := &in.Keys, &out.Keys
-		*out = make([]AccessControlPolicyAPIKeyKey, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	in.Service.DeepCopyInto(&out.Service)
+	if in.VersionHeader != nil {
+		in, out := &in.VersionHeader, &out.VersionHeader
+		*out = new(APIVersionHeader)
+		**out = **in
 	}
-	if in.ForwardHeaders != nil {
-		in, out := &in.ForwardHeaders, &out.ForwardHeaders
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Matchers != nil {
+		in, out := &in.Matchers, &out.Matchers
+		*out = new(APIMatchers)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(APIDeprecation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sandbox != nil {
+		in, out := &in.Sandbox, &out.Sandbox
+		*out = new(APISandbox)
+		**out = **in
+	}
+	if in.MaxRequestBodyBytes != nil {
+		in, out := &in.MaxRequestBodyBytes, &out.MaxRequestBodyBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AllowedMethods != nil {
+		in, out := &in.AllowedMethods, &out.AllowedMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CORS != nil {
+		in, out := &in.CORS, &out.CORS
+		*out = new(CORS)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyAPIKey.
-func (in *AccessControlPolicyAPIKey) DeepCopy() *AccessControlPolicyAPIKey {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APISpec.
+func (in *APISpec) DeepCopy() *APISpec {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicyAPIKey)
+	out := new(APISpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicyAPIKeyKey) DeepCopyInto(out *AccessControlPolicyAPIKeyKey) {
+func (in *APIStatus) DeepCopyInto(out *APIStatus) {
 	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	in.SyncedAt.DeepCopyInto(&out.SyncedAt)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyAPIKeyKey.
-func (in *AccessControlPolicyAPIKeyKey) DeepCopy() *AccessControlPolicyAPIKeyKey {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIStatus.
+func (in *APIStatus) DeepCopy() *APIStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicyAPIKeyKey)
+	out := new(APIStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicyBasicAuth) DeepCopyInto(out *AccessControlPolicyBasicAuth) {
+func (in *APIVersion) DeepCopyInto(out *APIVersion) {
 	*out = *in
-	if in.Users != nil {
-		in, out := &in.Users, &out.Users
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyBasicAuth.
-func (in *AccessControlPolicyBasicAuth) DeepCopy() *AccessControlPolicyBasicAuth {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersion.
+func (in *APIVersion) DeepCopy() *APIVersion {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicyBasicAuth)
+	out := new(APIVersion)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicyJWT) DeepCopyInto(out *AccessControlPolicyJWT) {
-	*out = *in
-	if in.ForwardHeaders != nil {
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *APIVersion) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIVersionHeader) DeepCopyInto(out *APIVersionHeader) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersionHeader.
+func (in *APIVersionHeader) DeepCopy() *APIVersionHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(APIVersionHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIVersionList) DeepCopyInto(out *APIVersionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]APIVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersionList.
+func (in *APIVersionList) DeepCopy() *APIVersionList {
+	if in == nil {
+		return nil
+	}
+	out := new(APIVersionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *APIVersionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIVersionService) DeepCopyInto(out *APIVersionService) {
+	*out = *in
+	out.Port = in.Port
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersionService.
+func (in *APIVersionService) DeepCopy() *APIVersionService {
+	if in == nil {
+		return nil
+	}
+	out := new(APIVersionService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIVersionSpec) DeepCopyInto(out *APIVersionSpec) {
+	*out = *in
+	if in.VersionHeader != nil {
+		in, out := &in.VersionHeader, &out.VersionHeader
+		*out = new(APIVersionHeader)
+		**out = **in
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(APIVersionService)
+		**out = **in
+	}
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(APIDeprecation)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersionSpec.
+func (in *APIVersionSpec) DeepCopy() *APIVersionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(APIVersionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIVersionStatus) DeepCopyInto(out *APIVersionStatus) {
+	*out = *in
+	in.SyncedAt.DeepCopyInto(&out.SyncedAt)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersionStatus.
+func (in *APIVersionStatus) DeepCopy() *APIVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(APIVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlOAuthIntro) DeepCopyInto(out *AccessControlOAuthIntro) {
+	*out = *in
+	in.ClientConfig.DeepCopyInto(&out.ClientConfig)
+	out.TokenSource = in.TokenSource
+	if in.ForwardHeaders != nil {
+		in, out := &in.ForwardHeaders, &out.ForwardHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlOAuthIntro.
+func (in *AccessControlOAuthIntro) DeepCopy() *AccessControlOAuthIntro {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlOAuthIntro)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlOAuthIntroClientConfig) DeepCopyInto(out *AccessControlOAuthIntroClientConfig) {
+	*out = *in
+	in.HTTPClientConfig.DeepCopyInto(&out.HTTPClientConfig)
+	out.Auth = in.Auth
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlOAuthIntroClientConfig.
+func (in *AccessControlOAuthIntroClientConfig) DeepCopy() *AccessControlOAuthIntroClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlOAuthIntroClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlOAuthIntroClientConfigAuth) DeepCopyInto(out *AccessControlOAuthIntroClientConfigAuth) {
+	*out = *in
+	out.Secret = in.Secret
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlOAuthIntroClientConfigAuth.
+func (in *AccessControlOAuthIntroClientConfigAuth) DeepCopy() *AccessControlOAuthIntroClientConfigAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlOAuthIntroClientConfigAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicy) DeepCopyInto(out *AccessControlPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicy.
+func (in *AccessControlPolicy) DeepCopy() *AccessControlPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AccessControlPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyAPIKey) DeepCopyInto(out *AccessControlPolicyAPIKey) {
+	*out = *in
+	out.KeySource = in.KeySource
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]AccessControlPolicyAPIKeyKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ForwardHeaders != nil {
+		in, out := &in.ForwardHeaders, &out.ForwardHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(AccessControlPolicyAPIKeyQuota)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyAPIKey.
+func (in *AccessControlPolicyAPIKey) DeepCopy() *AccessControlPolicyAPIKey {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyAPIKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyAPIKeyKey) DeepCopyInto(out *AccessControlPolicyAPIKeyKey) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(SecretReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyAPIKeyKey.
+func (in *AccessControlPolicyAPIKeyKey) DeepCopy() *AccessControlPolicyAPIKeyKey {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyAPIKeyKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyAPIKeyQuota) DeepCopyInto(out *AccessControlPolicyAPIKeyQuota) {
+	*out = *in
+	out.Period = in.Period
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyAPIKeyQuota.
+func (in *AccessControlPolicyAPIKeyQuota) DeepCopy() *AccessControlPolicyAPIKeyQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyAPIKeyQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyBasicAuth) DeepCopyInto(out *AccessControlPolicyBasicAuth) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyBasicAuth.
+func (in *AccessControlPolicyBasicAuth) DeepCopy() *AccessControlPolicyBasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyBasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyHeaders) DeepCopyInto(out *AccessControlPolicyHeaders) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(AccessControlPolicyRequestHeaders)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(AccessControlPolicyResponseHeaders)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyHeaders.
+func (in *AccessControlPolicyHeaders) DeepCopy() *AccessControlPolicyHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyJWT) DeepCopyInto(out *AccessControlPolicyJWT) {
+	*out = *in
+	if in.ForwardHeaders != nil {
 		in, out := &in.ForwardHeaders, &out.ForwardHeaders
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
@@ -836,128 +1340,351 @@ func (in *AccessControlPolicyOIDC) DeepCopyInto(out *AccessControlPolicyOIDC) {
 			(*out)[key] = val
 		}
 	}
+	if in.Pages != nil {
+		in, out := &in.Pages, &out.Pages
+		*out = new(OIDCPages)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyOIDC.
+func (in *AccessControlPolicyOIDC) DeepCopy() *AccessControlPolicyOIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyOIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyOIDCGoogle) DeepCopyInto(out *AccessControlPolicyOIDCGoogle) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	if in.AuthParams != nil {
+		in, out := &in.AuthParams, &out.AuthParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StateCookie != nil {
+		in, out := &in.StateCookie, &out.StateCookie
+		*out = new(StateCookie)
+		**out = **in
+	}
+	if in.Session != nil {
+		in, out := &in.Session, &out.Session
+		*out = new(Session)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForwardHeaders != nil {
+		in, out := &in.ForwardHeaders, &out.ForwardHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Emails != nil {
+		in, out := &in.Emails, &out.Emails
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pages != nil {
+		in, out := &in.Pages, &out.Pages
+		*out = new(OIDCPages)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyOIDCGoogle.
+func (in *AccessControlPolicyOIDCGoogle) DeepCopy() *AccessControlPolicyOIDCGoogle {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyOIDCGoogle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyRequestHeaders) DeepCopyInto(out *AccessControlPolicyRequestHeaders) {
+	*out = *in
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Remove != nil {
+		in, out := &in.Remove, &out.Remove
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rename != nil {
+		in, out := &in.Rename, &out.Rename
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyRequestHeaders.
+func (in *AccessControlPolicyRequestHeaders) DeepCopy() *AccessControlPolicyRequestHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyRequestHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyResponseHeaders) DeepCopyInto(out *AccessControlPolicyResponseHeaders) {
+	*out = *in
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Remove != nil {
+		in, out := &in.Remove, &out.Remove
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyResponseHeaders.
+func (in *AccessControlPolicyResponseHeaders) DeepCopy() *AccessControlPolicyResponseHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyResponseHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicySpec) DeepCopyInto(out *AccessControlPolicySpec) {
+	*out = *in
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = new(AccessControlPolicyJWT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(AccessControlPolicyBasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APIKey != nil {
+		in, out := &in.APIKey, &out.APIKey
+		*out = new(AccessControlPolicyAPIKey)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(AccessControlPolicyOIDC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OIDCGoogle != nil {
+		in, out := &in.OIDCGoogle, &out.OIDCGoogle
+		*out = new(AccessControlPolicyOIDCGoogle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OAuthIntro != nil {
+		in, out := &in.OAuthIntro, &out.OAuthIntro
+		*out = new(AccessControlOAuthIntro)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = new(AccessControlPolicyHeaders)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicySpec.
+func (in *AccessControlPolicySpec) DeepCopy() *AccessControlPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyStatus) DeepCopyInto(out *AccessControlPolicyStatus) {
+	*out = *in
+	in.SyncedAt.DeepCopyInto(&out.SyncedAt)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyStatus.
+func (in *AccessControlPolicyStatus) DeepCopy() *AccessControlPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertMatcher) DeepCopyInto(out *AlertMatcher) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertMatcher.
+func (in *AlertMatcher) DeepCopy() *AlertMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertMatcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertSilence) DeepCopyInto(out *AlertSilence) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertSilence.
+func (in *AlertSilence) DeepCopy() *AlertSilence {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertSilence)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AlertSilence) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertSilenceList) DeepCopyInto(out *AlertSilenceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AlertSilence, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyOIDC.
-func (in *AccessControlPolicyOIDC) DeepCopy() *AccessControlPolicyOIDC {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertSilenceList.
+func (in *AlertSilenceList) DeepCopy() *AlertSilenceList {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicyOIDC)
+	out := new(AlertSilenceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AlertSilenceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicyOIDCGoogle) DeepCopyInto(out *AccessControlPolicyOIDCGoogle) {
+func (in *AlertSilenceSpec) DeepCopyInto(out *AlertSilenceSpec) {
 	*out = *in
-	if in.Secret != nil {
-		in, out := &in.Secret, &out.Secret
-		*out = new(corev1.SecretReference)
-		**out = **in
-	}
-	if in.AuthParams != nil {
-		in, out := &in.AuthParams, &out.AuthParams
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.StateCookie != nil {
-		in, out := &in.StateCookie, &out.StateCookie
-		*out = new(StateCookie)
-		**out = **in
-	}
-	if in.Session != nil {
-		in, out := &in.Session, &out.Session
-		*out = new(Session)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ForwardHeaders != nil {
-		in, out := &in.ForwardHeaders, &out.ForwardHeaders
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Emails != nil {
-		in, out := &in.Emails, &out.Emails
-		*out = make([]string, len(*in))
+	if in.StartsAt != nil {
+		in, out := &in.StartsAt, &out.StartsAt
+		*out = (*in).DeepCopy()
+	}
+	in.EndsAt.DeepCopyInto(&out.EndsAt)
+	if in.Matchers != nil {
+		in, out := &in.Matchers, &out.Matchers
+		*out = make([]AlertMatcher, len(*in))
 		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyOIDCGoogle.
-func (in *AccessControlPolicyOIDCGoogle) DeepCopy() *AccessControlPolicyOIDCGoogle {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertSilenceSpec.
+func (in *AlertSilenceSpec) DeepCopy() *AlertSilenceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicyOIDCGoogle)
+	out := new(AlertSilenceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicySpec) DeepCopyInto(out *AccessControlPolicySpec) {
+func (in *CORS) DeepCopyInto(out *CORS) {
 	*out = *in
-	if in.JWT != nil {
-		in, out := &in.JWT, &out.JWT
-		*out = new(AccessControlPolicyJWT)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.BasicAuth != nil {
-		in, out := &in.BasicAuth, &out.BasicAuth
-		*out = new(AccessControlPolicyBasicAuth)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.APIKey != nil {
-		in, out := &in.APIKey, &out.APIKey
-		*out = new(AccessControlPolicyAPIKey)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.OIDC != nil {
-		in, out := &in.OIDC, &out.OIDC
-		*out = new(AccessControlPolicyOIDC)
-		(*in).DeepCopyInto(*out)
+	if in.AllowOrigins != nil {
+		in, out := &in.AllowOrigins, &out.AllowOrigins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.OIDCGoogle != nil {
-		in, out := &in.OIDCGoogle, &out.OIDCGoogle
-		*out = new(AccessControlPolicyOIDCGoogle)
-		(*in).DeepCopyInto(*out)
+	if in.AllowMethods != nil {
+		in, out := &in.AllowMethods, &out.AllowMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.OAuthIntro != nil {
-		in, out := &in.OAuthIntro, &out.OAuthIntro
-		*out = new(AccessControlOAuthIntro)
-		(*in).DeepCopyInto(*out)
+	if in.AllowHeaders != nil {
+		in, out := &in.AllowHeaders, &out.AllowHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicySpec.
-func (in *AccessControlPolicySpec) DeepCopy() *AccessControlPolicySpec {
-	if in == nil {
-		return nil
+	if in.ExposeHeaders != nil {
+		in, out := &in.ExposeHeaders, &out.ExposeHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	out := new(AccessControlPolicySpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicyStatus) DeepCopyInto(out *AccessControlPolicyStatus) {
-	*out = *in
-	in.SyncedAt.DeepCopyInto(&out.SyncedAt)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyStatus.
-func (in *AccessControlPolicyStatus) DeepCopy() *AccessControlPolicyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CORS.
+func (in *CORS) DeepCopy() *CORS {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicyStatus)
+	out := new(CORS)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1006,6 +1733,50 @@ func (in *EdgeIngressACP) DeepCopy() *EdgeIngressACP {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressClientAuth) DeepCopyInto(out *EdgeIngressClientAuth) {
+	*out = *in
+	if in.SecretNames != nil {
+		in, out := &in.SecretNames, &out.SecretNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressClientAuth.
+func (in *EdgeIngressClientAuth) DeepCopy() *EdgeIngressClientAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressClientAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressHealthCheck) DeepCopyInto(out *EdgeIngressHealthCheck) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressHealthCheck.
+func (in *EdgeIngressHealthCheck) DeepCopy() *EdgeIngressHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgeIngressList) DeepCopyInto(out *EdgeIngressList) {
 	*out = *in
@@ -1059,16 +1830,46 @@ func (in *EdgeIngressService) DeepCopy() *EdgeIngressService {
 func (in *EdgeIngressSpec) DeepCopyInto(out *EdgeIngressSpec) {
 	*out = *in
 	out.Service = in.Service
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]EdgeIngressWeightedService, len(*in))
+		copy(*out, *in)
+	}
 	if in.ACP != nil {
 		in, out := &in.ACP, &out.ACP
 		*out = new(EdgeIngressACP)
 		**out = **in
 	}
+	if in.ACPs != nil {
+		in, out := &in.ACPs, &out.ACPs
+		*out = make([]EdgeIngressACP, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sticky != nil {
+		in, out := &in.Sticky, &out.Sticky
+		*out = new(EdgeIngressSticky)
+		**out = **in
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(EdgeIngressHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(EdgeIngressTLS)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CustomDomains != nil {
 		in, out := &in.CustomDomains, &out.CustomDomains
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(SecretReference)
+		**out = **in
+	}
 	return
 }
 
@@ -1091,6 +1892,13 @@ func (in *EdgeIngressStatus) DeepCopyInto(out *EdgeIngressStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1104,6 +1912,64 @@ func (in *EdgeIngressStatus) DeepCopy() *EdgeIngressStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressSticky) DeepCopyInto(out *EdgeIngressSticky) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressSticky.
+func (in *EdgeIngressSticky) DeepCopy() *EdgeIngressSticky {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressSticky)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressTLS) DeepCopyInto(out *EdgeIngressTLS) {
+	*out = *in
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientAuth != nil {
+		in, out := &in.ClientAuth, &out.ClientAuth
+		*out = new(EdgeIngressClientAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressTLS.
+func (in *EdgeIngressTLS) DeepCopy() *EdgeIngressTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressWeightedService) DeepCopyInto(out *EdgeIngressWeightedService) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressWeightedService.
+func (in *EdgeIngressWeightedService) DeepCopy() *EdgeIngressWeightedService {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressWeightedService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPClientConfig) DeepCopyInto(out *HTTPClientConfig) {
 	*out = *in
@@ -1217,6 +2083,29 @@ func (in *IngressClassSpec) DeepCopy() *IngressClassSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCPages) DeepCopyInto(out *OIDCPages) {
+	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCPages.
+func (in *OIDCPages) DeepCopy() *OIDCPages {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCPages)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenAPISpec) DeepCopyInto(out *OpenAPISpec) {
 	*out = *in
@@ -1225,6 +2114,23 @@ func (in *OpenAPISpec) DeepCopyInto(out *OpenAPISpec) {
 		*out = new(APIServiceBackendPort)
 		**out = **in
 	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(OpenAPISpecAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(SecretReference)
+		**out = **in
+	}
 	return
 }
 
@@ -1238,6 +2144,50 @@ func (in *OpenAPISpec) DeepCopy() *OpenAPISpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenAPISpecAuth) DeepCopyInto(out *OpenAPISpecAuth) {
+	*out = *in
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = new(SecretReference)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]SecretReference, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenAPISpecAuth.
+func (in *OpenAPISpecAuth) DeepCopy() *OpenAPISpecAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenAPISpecAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretReference) DeepCopyInto(out *SecretReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReference.
+func (in *SecretReference) DeepCopy() *SecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Session) DeepCopyInto(out *Session) {
 	*out = *in
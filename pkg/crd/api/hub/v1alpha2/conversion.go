@@ -0,0 +1,208 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package v1alpha2
+
+import (
+	"fmt"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// addConversionFuncs registers the conversions between v1alpha1 and v1alpha2 objects.
+// Fields are converted one by one, even where both versions are still identical, so that changing a v1alpha2 type
+// without updating its conversions breaks the round-trip tests.
+func addConversionFuncs(scheme *runtime.Scheme) error {
+	conversions := []struct {
+		a, b interface{}
+		fn   conversion.ConversionFunc
+	}{
+		{
+			a: (*hubv1alpha1.EdgeIngress)(nil),
+			b: (*EdgeIngress)(nil),
+			fn: func(a, b interface{}, _ conversion.Scope) error {
+				convertEdgeIngressFromV1alpha1(a.(*hubv1alpha1.EdgeIngress), b.(*EdgeIngress))
+				return nil
+			},
+		},
+		{
+			a: (*EdgeIngress)(nil),
+			b: (*hubv1alpha1.EdgeIngress)(nil),
+			fn: func(a, b interface{}, _ conversion.Scope) error {
+				convertEdgeIngressToV1alpha1(a.(*EdgeIngress), b.(*hubv1alpha1.EdgeIngress))
+				return nil
+			},
+		},
+		{
+			a: (*hubv1alpha1.APIGateway)(nil),
+			b: (*APIGateway)(nil),
+			fn: func(a, b interface{}, _ conversion.Scope) error {
+				convertAPIGatewayFromV1alpha1(a.(*hubv1alpha1.APIGateway), b.(*APIGateway))
+				return nil
+			},
+		},
+		{
+			a: (*APIGateway)(nil),
+			b: (*hubv1alpha1.APIGateway)(nil),
+			fn: func(a, b interface{}, _ conversion.Scope) error {
+				convertAPIGatewayToV1alpha1(a.(*APIGateway), b.(*hubv1alpha1.APIGateway))
+				return nil
+			},
+		},
+	}
+
+	for _, c := range conversions {
+		if err := scheme.AddConversionFunc(c.a, c.b, c.fn); err != nil {
+			return fmt.Errorf("add conversion func from %T to %T: %w", c.a, c.b, err)
+		}
+	}
+
+	return nil
+}
+
+func convertEdgeIngressFromV1alpha1(in *hubv1alpha1.EdgeIngress, out *EdgeIngress) {
+	in = in.DeepCopy()
+
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = EdgeIngressSpec{
+		Service: EdgeIngressService{
+			Name: in.Spec.Service.Name,
+			Port: in.Spec.Service.Port,
+		},
+		Protocol:      EdgeIngressProtocol(in.Spec.Protocol),
+		EntryPoint:    in.Spec.EntryPoint,
+		Sticky:        (*EdgeIngressSticky)(in.Spec.Sticky),
+		HealthCheck:   (*EdgeIngressHealthCheck)(in.Spec.HealthCheck),
+		CustomDomains: in.Spec.CustomDomains,
+		Certificate:   (*SecretReference)(in.Spec.Certificate),
+	}
+	for _, svc := range in.Spec.Services {
+		out.Spec.Services = append(out.Spec.Services, EdgeIngressWeightedService(svc))
+	}
+	if in.Spec.ACP != nil {
+		out.Spec.ACP = &EdgeIngressACP{Name: in.Spec.ACP.Name}
+	}
+	for _, a := range in.Spec.ACPs {
+		out.Spec.ACPs = append(out.Spec.ACPs, EdgeIngressACP(a))
+	}
+	if in.Spec.TLS != nil {
+		out.Spec.TLS = &EdgeIngressTLS{
+			MinVersion:   in.Spec.TLS.MinVersion,
+			CipherSuites: in.Spec.TLS.CipherSuites,
+			ClientAuth:   (*EdgeIngressClientAuth)(in.Spec.TLS.ClientAuth),
+		}
+	}
+	out.Status = EdgeIngressStatus{
+		Version:       in.Status.Version,
+		SyncedAt:      in.Status.SyncedAt,
+		Domain:        in.Status.Domain,
+		CustomDomains: in.Status.CustomDomains,
+		URLs:          in.Status.URLs,
+		Connection:    EdgeIngressConnectionStatus(in.Status.Connection),
+		SpecHash:      in.Status.SpecHash,
+		Conditions:    in.Status.Conditions,
+	}
+}
+
+func convertEdgeIngressToV1alpha1(in *EdgeIngress, out *hubv1alpha1.EdgeIngress) {
+	in = in.DeepCopy()
+
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = hubv1alpha1.EdgeIngressSpec{
+		Service: hubv1alpha1.EdgeIngressService{
+			Name: in.Spec.Service.Name,
+			Port: in.Spec.Service.Port,
+		},
+		Protocol:      hubv1alpha1.EdgeIngressProtocol(in.Spec.Protocol),
+		EntryPoint:    in.Spec.EntryPoint,
+		Sticky:        (*hubv1alpha1.EdgeIngressSticky)(in.Spec.Sticky),
+		HealthCheck:   (*hubv1alpha1.EdgeIngressHealthCheck)(in.Spec.HealthCheck),
+		CustomDomains: in.Spec.CustomDomains,
+		Certificate:   (*hubv1alpha1.SecretReference)(in.Spec.Certificate),
+	}
+	for _, svc := range in.Spec.Services {
+		out.Spec.Services = append(out.Spec.Services, hubv1alpha1.EdgeIngressWeightedService(svc))
+	}
+	if in.Spec.ACP != nil {
+		out.Spec.ACP = &hubv1alpha1.EdgeIngressACP{Name: in.Spec.ACP.Name}
+	}
+	for _, a := range in.Spec.ACPs {
+		out.Spec.ACPs = append(out.Spec.ACPs, hubv1alpha1.EdgeIngressACP(a))
+	}
+	if in.Spec.TLS != nil {
+		out.Spec.TLS = &hubv1alpha1.EdgeIngressTLS{
+			MinVersion:   in.Spec.TLS.MinVersion,
+			CipherSuites: in.Spec.TLS.CipherSuites,
+			ClientAuth:   (*hubv1alpha1.EdgeIngressClientAuth)(in.Spec.TLS.ClientAuth),
+		}
+	}
+	out.Status = hubv1alpha1.EdgeIngressStatus{
+		Version:       in.Status.Version,
+		SyncedAt:      in.Status.SyncedAt,
+		Domain:        in.Status.Domain,
+		CustomDomains: in.Status.CustomDomains,
+		URLs:          in.Status.URLs,
+		Connection:    hubv1alpha1.EdgeIngressConnectionStatus(in.Status.Connection),
+		SpecHash:      in.Status.SpecHash,
+		Conditions:    in.Status.Conditions,
+	}
+}
+
+func convertAPIGatewayFromV1alpha1(in *hubv1alpha1.APIGateway, out *APIGateway) {
+	in = in.DeepCopy()
+
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = APIGatewaySpec{
+		APIAccesses:   in.Spec.APIAccesses,
+		CustomDomains: in.Spec.CustomDomains,
+		Certificate:   (*SecretReference)(in.Spec.Certificate),
+
+		MaxRequestBodyBytes: in.Spec.MaxRequestBodyBytes,
+	}
+	out.Status = APIGatewayStatus{
+		Version:       in.Status.Version,
+		SyncedAt:      in.Status.SyncedAt,
+		URLs:          in.Status.URLs,
+		HubDomain:     in.Status.HubDomain,
+		CustomDomains: in.Status.CustomDomains,
+		Hash:          in.Status.Hash,
+		Conditions:    in.Status.Conditions,
+	}
+}
+
+func convertAPIGatewayToV1alpha1(in *APIGateway, out *hubv1alpha1.APIGateway) {
+	in = in.DeepCopy()
+
+	out.ObjectMeta = in.ObjectMeta
+	out.Spec = hubv1alpha1.APIGatewaySpec{
+		APIAccesses:   in.Spec.APIAccesses,
+		CustomDomains: in.Spec.CustomDomains,
+		Certificate:   (*hubv1alpha1.SecretReference)(in.Spec.Certificate),
+
+		MaxRequestBodyBytes: in.Spec.MaxRequestBodyBytes,
+	}
+	out.Status = hubv1alpha1.APIGatewayStatus{
+		Version:       in.Status.Version,
+		SyncedAt:      in.Status.SyncedAt,
+		URLs:          in.Status.URLs,
+		HubDomain:     in.Status.HubDomain,
+		CustomDomains: in.Status.CustomDomains,
+		Hash:          in.Status.Hash,
+		Conditions:    in.Status.Conditions,
+	}
+}
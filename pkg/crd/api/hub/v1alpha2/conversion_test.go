@@ -0,0 +1,198 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package v1alpha2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+var (
+	testSyncedAt   = metav1.NewTime(time.Date(2023, 5, 12, 10, 0, 0, 0, time.UTC))
+	testConditions = []metav1.Condition{
+		{
+			Type:               hubv1alpha1.ConditionSynced,
+			Status:             metav1.ConditionTrue,
+			Reason:             hubv1alpha1.ReasonSynced,
+			Message:            "Synced with the platform",
+			LastTransitionTime: testSyncedAt,
+		},
+	}
+	testObjectMeta = metav1.ObjectMeta{
+		Name:            "name",
+		Namespace:       "ns",
+		UID:             "uid",
+		ResourceVersion: "42",
+		Labels:          map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"},
+		Annotations:     map[string]string{"foo": "bar"},
+	}
+)
+
+func TestConversion_EdgeIngress_roundTrip(t *testing.T) {
+	tests := []struct {
+		desc string
+		obj  *hubv1alpha1.EdgeIngress
+	}{
+		{
+			desc: "fully populated",
+			obj: &hubv1alpha1.EdgeIngress{
+				ObjectMeta: testObjectMeta,
+				Spec: hubv1alpha1.EdgeIngressSpec{
+					Service: hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 8080},
+					Services: []hubv1alpha1.EdgeIngressWeightedService{
+						{Name: "whoami", Port: 8080, Weight: 90},
+						{Name: "whoami-canary", Port: 8080, Weight: 10},
+					},
+					Protocol:   hubv1alpha1.EdgeIngressProtocolUDP,
+					EntryPoint: "dns",
+					Sticky:     &hubv1alpha1.EdgeIngressSticky{CookieName: "session", Secure: true, SameSite: "lax"},
+					HealthCheck: &hubv1alpha1.EdgeIngressHealthCheck{
+						Path:     "/health",
+						Interval: "10s",
+						Headers:  map[string]string{"X-Probe": "hub"},
+					},
+					TLS: &hubv1alpha1.EdgeIngressTLS{
+						MinVersion:   "VersionTLS12",
+						CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+						ClientAuth: &hubv1alpha1.EdgeIngressClientAuth{
+							SecretNames:    []string{"client-ca"},
+							ClientAuthType: "RequireAndVerifyClientCert",
+						},
+					},
+					ACP:           &hubv1alpha1.EdgeIngressACP{Name: "acp"},
+					ACPs:          []hubv1alpha1.EdgeIngressACP{{Name: "ip-allowlist"}, {Name: "jwt"}},
+					CustomDomains: []string{"foo.example.com", "bar.example.com"},
+					Certificate:   &hubv1alpha1.SecretReference{Name: "cert"},
+				},
+				Status: hubv1alpha1.EdgeIngressStatus{
+					Version:       "version",
+					SyncedAt:      testSyncedAt,
+					Domain:        "majestic-beaver-123.hub-traefik.io",
+					CustomDomains: []string{"foo.example.com"},
+					URLs:          "https://foo.example.com,https://majestic-beaver-123.hub-traefik.io",
+					Connection:    hubv1alpha1.EdgeIngressConnectionUp,
+					SpecHash:      "hash",
+					Conditions:    testConditions,
+				},
+			},
+		},
+		{
+			desc: "empty",
+			obj:  &hubv1alpha1.EdgeIngress{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			scheme := newTestScheme(t)
+
+			var v2 EdgeIngress
+			require.NoError(t, scheme.Convert(test.obj, &v2, nil))
+
+			var got hubv1alpha1.EdgeIngress
+			require.NoError(t, scheme.Convert(&v2, &got, nil))
+
+			assert.Equal(t, test.obj, &got)
+		})
+	}
+}
+
+func TestConversion_APIGateway_roundTrip(t *testing.T) {
+	tests := []struct {
+		desc string
+		obj  *hubv1alpha1.APIGateway
+	}{
+		{
+			desc: "fully populated",
+			obj: &hubv1alpha1.APIGateway{
+				ObjectMeta: testObjectMeta,
+				Spec: hubv1alpha1.APIGatewaySpec{
+					APIAccesses:   []string{"access-1", "access-2"},
+					CustomDomains: []string{"api.example.com"},
+					Certificate:   &hubv1alpha1.SecretReference{Name: "cert", Namespace: "ns"},
+
+					MaxRequestBodyBytes: pointer.Int64(1048576),
+				},
+				Status: hubv1alpha1.APIGatewayStatus{
+					Version:       "version",
+					SyncedAt:      testSyncedAt,
+					URLs:          "https://api.example.com,https://brave-lion-123.hub-traefik.io",
+					HubDomain:     "brave-lion-123.hub-traefik.io",
+					CustomDomains: []string{"api.example.com"},
+					Hash:          "hash",
+					Conditions:    testConditions,
+				},
+			},
+		},
+		{
+			desc: "empty",
+			obj:  &hubv1alpha1.APIGateway{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			scheme := newTestScheme(t)
+
+			var v2 APIGateway
+			require.NoError(t, scheme.Convert(test.obj, &v2, nil))
+
+			var got hubv1alpha1.APIGateway
+			require.NoError(t, scheme.Convert(&v2, &got, nil))
+
+			assert.Equal(t, test.obj, &got)
+		})
+	}
+}
+
+func TestConversion_doesNotShareMemory(t *testing.T) {
+	scheme := newTestScheme(t)
+
+	obj := &hubv1alpha1.APIGateway{
+		Spec: hubv1alpha1.APIGatewaySpec{APIAccesses: []string{"access"}},
+	}
+
+	var v2 APIGateway
+	require.NoError(t, scheme.Convert(obj, &v2, nil))
+
+	v2.Spec.APIAccesses[0] = "modified"
+
+	assert.Equal(t, "access", obj.Spec.APIAccesses[0])
+}
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, hubv1alpha1.AddToScheme(scheme))
+	require.NoError(t, AddToScheme(scheme))
+
+	return scheme
+}
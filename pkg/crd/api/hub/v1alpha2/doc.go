@@ -0,0 +1,23 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+// Package v1alpha2 is the next version of the Hub API. It is not served yet: it lets conversions between v1alpha1 and
+// v1alpha2 be developed and tested before v1alpha2 gets rolled out.
+// +k8s:deepcopy-gen=package
+// +kubebuilder:skipversion
+// +groupName=hub.traefik.io
+
+package v1alpha2
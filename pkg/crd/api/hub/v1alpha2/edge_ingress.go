@@ -0,0 +1,242 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EdgeIngress defines an edge ingress.
+// +kubebuilder:resource:shortName=ei
+// +kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.status.domain`
+// +kubebuilder:printcolumn:name="Service",type=string,JSONPath=`.spec.service.name`
+// +kubebuilder:printcolumn:name="Port",type=string,JSONPath=`.spec.service.port`
+// +kubebuilder:printcolumn:name="ACP",type=string,JSONPath=`.spec.acp.name`
+// +kubebuilder:printcolumn:name="Protocol",type=string,JSONPath=`.spec.protocol`,priority=1
+// +kubebuilder:printcolumn:name="URLs",type=string,JSONPath=`.status.urls`,priority=1
+// +kubebuilder:printcolumn:name="Connection",type=string,JSONPath=`.status.connection`
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type EdgeIngress struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The desired behavior of this edge ingress.
+	Spec EdgeIngressSpec `json:"spec,omitempty"`
+
+	// The current status of this edge ingress.
+	// +optional
+	Status EdgeIngressStatus `json:"status,omitempty"`
+}
+
+// EdgeIngressSpec configures an edgeIngress policy.
+type EdgeIngressSpec struct {
+	Service EdgeIngressService `json:"service"`
+	// Services splits the traffic between several services, proportionally to their weight. When set, it takes
+	// precedence over Service.
+	// +optional
+	Services []EdgeIngressWeightedService `json:"services,omitempty"`
+	ACP      *EdgeIngressACP              `json:"acp,omitempty"`
+	// ACPs is an ordered list of ACPs evaluated as a chain: requests must be authorized by each of them, in order.
+	// It can't be set along with ACP.
+	// +optional
+	ACPs []EdgeIngressACP `json:"acps,omitempty"`
+	// Protocol is the protocol of the exposed services. TCP services are exposed over TLS and routed by SNI, UDP
+	// services are exposed on a port allocated by the platform.
+	// +optional
+	// +kubebuilder:validation:Enum=http;tcp;udp
+	// +kubebuilder:default=http
+	Protocol EdgeIngressProtocol `json:"protocol,omitempty"`
+	// EntryPoint is the Traefik UDP entry point dedicated to the edge ingress. As UDP traffic can't be routed by
+	// domain, it is required for the udp protocol, and each UDP edge ingress needs its own entry point.
+	// +optional
+	EntryPoint string `json:"entryPoint,omitempty"`
+	// Sticky enables cookie-based sticky sessions: the requests of a client are always routed to the same server.
+	// Only supported for the http protocol.
+	// +optional
+	Sticky *EdgeIngressSticky `json:"sticky,omitempty"`
+	// HealthCheck enables active health checks of the servers of the exposed services. Unhealthy servers are removed
+	// from the load-balancing until they recover. Only supported for the http protocol.
+	// +optional
+	HealthCheck *EdgeIngressHealthCheck `json:"healthCheck,omitempty"`
+	// TLS configures the TLS connections of the clients, for services with specific compliance requirements. Not
+	// supported for the udp protocol.
+	// +optional
+	TLS *EdgeIngressTLS `json:"tls,omitempty"`
+	// CustomDomains are the custom domains for accessing the exposed service.
+	CustomDomains []string `json:"customDomains,omitempty"`
+	// Certificate references the TLS secret holding the certificate served for the custom domains, in its tls.crt
+	// and tls.key entries, instead of the certificate issued by the platform.
+	// +optional
+	Certificate *SecretReference `json:"certificate,omitempty"`
+}
+
+// SecretReference references a Kubernetes secret, or one of its entries.
+type SecretReference struct {
+	// Name is the name of the secret.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// Namespace is the namespace of the secret. It defaults to the namespace of the referencing resource, and is
+	// required when the referencing resource is cluster scoped.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Key is the entry of the secret holding the value. It defaults to an entry specific to each use of the
+	// reference, and is ignored when the whole secret is used, as for certificates.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// EdgeIngressService configures the service to exposed on the edge.
+type EdgeIngressService struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+// EdgeIngressProtocol is the protocol of the services exposed by an edge ingress.
+type EdgeIngressProtocol string
+
+// Edge ingress protocols.
+const (
+	EdgeIngressProtocolHTTP EdgeIngressProtocol = "http"
+	EdgeIngressProtocolTCP  EdgeIngressProtocol = "tcp"
+	EdgeIngressProtocolUDP  EdgeIngressProtocol = "udp"
+)
+
+// EdgeIngressWeightedService configures a service receiving a share of the traffic of an edge ingress.
+type EdgeIngressWeightedService struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+	// Weight is the share of the traffic sent to this service, relative to the sum of the weights.
+	// +kubebuilder:validation:Minimum=0
+	Weight int `json:"weight"`
+}
+
+// EdgeIngressSticky configures the sticky sessions of an edge ingress.
+type EdgeIngressSticky struct {
+	// CookieName is the name of the cookie holding the sticky session. Defaults to a name generated by Traefik.
+	// +optional
+	CookieName string `json:"cookieName,omitempty"`
+	// +optional
+	Secure bool `json:"secure,omitempty"`
+	// +optional
+	HTTPOnly bool `json:"httpOnly,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Enum=none;lax;strict
+	SameSite string `json:"sameSite,omitempty"`
+}
+
+// EdgeIngressHealthCheck configures the active health checks of the servers of an edge ingress.
+type EdgeIngressHealthCheck struct {
+	// Path is the path requested to check the health of a server. Servers answering with a 2XX or 3XX status code
+	// are healthy.
+	Path string `json:"path"`
+	// Port is the port used to check the health of a server. Defaults to the port of the service.
+	// +optional
+	Port int `json:"port,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Enum=http;https
+	Scheme string `json:"scheme,omitempty"`
+	// Interval is the duration between two health checks, 30s by default.
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// Timeout is the duration after which a health check is considered failed, 5s by default.
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+	// Headers are the headers sent with the health check requests.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// EdgeIngressTLS configures the TLS connections of the clients of an edge ingress.
+type EdgeIngressTLS struct {
+	// MinVersion is the minimum TLS version accepted.
+	// +optional
+	// +kubebuilder:validation:Enum=VersionTLS10;VersionTLS11;VersionTLS12;VersionTLS13
+	MinVersion string `json:"minVersion,omitempty"`
+	// CipherSuites are the cipher suites accepted for TLS 1.2 and lower, like TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+	// ClientAuth enables the authentication of the clients with certificates.
+	// +optional
+	ClientAuth *EdgeIngressClientAuth `json:"clientAuth,omitempty"`
+}
+
+// EdgeIngressClientAuth configures the authentication of the clients of an edge ingress with certificates.
+type EdgeIngressClientAuth struct {
+	// SecretNames are the names of the secrets, in the namespace of the edge ingress, holding the certificate
+	// authorities used to verify the client certificates in their tls.ca entry.
+	// +optional
+	SecretNames []string `json:"secretNames,omitempty"`
+	// +kubebuilder:validation:Enum=NoClientCert;RequestClientCert;RequireAnyClientCert;VerifyClientCertIfGiven;RequireAndVerifyClientCert
+	ClientAuthType string `json:"clientAuthType"`
+}
+
+// EdgeIngressACP configures the ACP to use on the Ingress.
+type EdgeIngressACP struct {
+	Name string `json:"name"`
+}
+
+// EdgeIngressConnectionStatus is the status of the underlying connection to the edge.
+type EdgeIngressConnectionStatus string
+
+// Connection statuses.
+const (
+	EdgeIngressConnectionDown EdgeIngressConnectionStatus = "DOWN"
+	EdgeIngressConnectionUp   EdgeIngressConnectionStatus = "UP"
+)
+
+// EdgeIngressStatus is the status of the EdgeIngress.
+type EdgeIngressStatus struct {
+	Version  string      `json:"version,omitempty"`
+	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
+
+	// Domain is the Domain for accessing the exposed service.
+	Domain string `json:"domain,omitempty"`
+
+	// CustomDomains are the custom domains for accessing the exposed service.
+	CustomDomains []string `json:"customDomains,omitempty"`
+
+	// URLs is the list of coma separated URL for accessing the exposed service.
+	URLs string `json:"urls,omitempty"`
+
+	// Connection is the status of the underlying connection to the edge.
+	Connection EdgeIngressConnectionStatus `json:"connection,omitempty"`
+
+	// SpecHash is a hash representing the EdgeIngressSpec
+	SpecHash string `json:"specHash,omitempty"`
+
+	// Conditions are the latest observations of the EdgeIngress state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// EdgeIngressList defines a list of edge ingress.
+type EdgeIngressList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []EdgeIngress `json:"items"`
+}
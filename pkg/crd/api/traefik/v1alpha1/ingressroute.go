@@ -92,6 +92,7 @@ type LoadBalancerSpec struct {
 	PassHostHeader     *bool               `json:"passHostHeader,omitempty"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty"`
 	ServersTransport   string              `json:"serversTransport,omitempty"`
+	HealthCheck        *ServerHealthCheck  `json:"healthCheck,omitempty"`
 
 	// Weight should only be specified when Name references a TraefikService object
 	// (and to be precise, one that embeds a Weighted Round Robin).
@@ -150,6 +151,19 @@ type Domain struct {
 
 // +k8s:deepcopy-gen=true
 
+// ServerHealthCheck holds the health check configuration of the servers of a load-balancer.
+type ServerHealthCheck struct {
+	Scheme   string            `json:"scheme,omitempty"`
+	Path     string            `json:"path,omitempty"`
+	Port     int               `json:"port,omitempty"`
+	Interval string            `json:"interval,omitempty"`
+	Timeout  string            `json:"timeout,omitempty"`
+	Hostname string            `json:"hostname,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // ResponseForwarding holds configuration for the forward of the response.
 type ResponseForwarding struct {
 	FlushInterval string `json:"flushInterval,omitempty"`
@@ -0,0 +1,88 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// IngressRouteTCPSpec is a specification for a IngressRouteTCPSpec resource.
+type IngressRouteTCPSpec struct {
+	Routes      []RouteTCP `json:"routes"`
+	EntryPoints []string   `json:"entryPoints,omitempty"`
+	TLS         *TLSTCP    `json:"tls,omitempty"`
+}
+
+// RouteTCP contains the set of routes.
+type RouteTCP struct {
+	Match    string       `json:"match"`
+	Priority int          `json:"priority,omitempty"`
+	Services []ServiceTCP `json:"services,omitempty"`
+}
+
+// TLSTCP contains the TLS certificates configuration of the routes.
+// To enable Let's Encrypt, use an empty TLS struct,
+// e.g. in YAML:
+//
+//	tls: {} # inline format
+//
+//	tls:
+//	  secretName: # block format
+type TLSTCP struct {
+	// SecretName is the name of the referenced Kubernetes Secret to specify the
+	// certificate details.
+	SecretName  string `json:"secretName,omitempty"`
+	Passthrough bool   `json:"passthrough,omitempty"`
+	// Options is a reference to a TLSOption, that specifies the parameters of the TLS connection.
+	Options *TLSOptionRef `json:"options,omitempty"`
+	// Store is a reference to a TLSStore, that specifies the parameters of the TLS store.
+	Store        *TLSStoreRef `json:"store,omitempty"`
+	CertResolver string       `json:"certResolver,omitempty"`
+	Domains      []Domain     `json:"domains,omitempty"`
+}
+
+// ServiceTCP defines an upstream to proxy traffic.
+type ServiceTCP struct {
+	Name             string             `json:"name"`
+	Namespace        string             `json:"namespace,omitempty"`
+	Port             intstr.IntOrString `json:"port"`
+	Weight           *int               `json:"weight,omitempty"`
+	TerminationDelay *int               `json:"terminationDelay,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:storageversion
+
+// IngressRouteTCP is an Ingress CRD specification.
+type IngressRouteTCP struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec IngressRouteTCPSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IngressRouteTCPList is a list of IngressRouteTCPs.
+type IngressRouteTCPList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []IngressRouteTCP `json:"items"`
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// IngressRouteUDPSpec is a specification for a IngressRouteUDPSpec resource.
+type IngressRouteUDPSpec struct {
+	Routes      []RouteUDP `json:"routes"`
+	EntryPoints []string   `json:"entryPoints,omitempty"`
+}
+
+// RouteUDP contains the set of routes.
+type RouteUDP struct {
+	Services []ServiceUDP `json:"services,omitempty"`
+}
+
+// ServiceUDP defines an upstream to proxy traffic.
+type ServiceUDP struct {
+	Name      string             `json:"name"`
+	Namespace string             `json:"namespace,omitempty"`
+	Port      intstr.IntOrString `json:"port"`
+	Weight    *int               `json:"weight,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:storageversion
+
+// IngressRouteUDP is an Ingress CRD specification.
+type IngressRouteUDP struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec IngressRouteUDPSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IngressRouteUDPList is a list of IngressRouteUDPs.
+type IngressRouteUDPList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []IngressRouteUDP `json:"items"`
+}
@@ -19,6 +19,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +genclient
@@ -40,6 +41,9 @@ type MiddlewareSpec struct {
 	StripPrefix      *StripPrefix      `json:"stripPrefix,omitempty"`
 	StripPrefixRegex *StripPrefixRegex `json:"stripPrefixRegex,omitempty"`
 	AddPrefix        *AddPrefix        `json:"addPrefix,omitempty"`
+	Headers          *Headers          `json:"headers,omitempty"`
+	Buffering        *Buffering        `json:"buffering,omitempty"`
+	RateLimit        *RateLimit        `json:"rateLimit,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -51,6 +55,47 @@ type AddPrefix struct {
 
 // +k8s:deepcopy-gen=true
 
+// Headers holds the custom headers configuration.
+type Headers struct {
+	CustomRequestHeaders  map[string]string `json:"customRequestHeaders,omitempty" toml:"customRequestHeaders,omitempty" yaml:"customRequestHeaders,omitempty" export:"true"`
+	CustomResponseHeaders map[string]string `json:"customResponseHeaders,omitempty" toml:"customResponseHeaders,omitempty" yaml:"customResponseHeaders,omitempty" export:"true"`
+
+	AccessControlAllowCredentials bool     `json:"accessControlAllowCredentials,omitempty" toml:"accessControlAllowCredentials,omitempty" yaml:"accessControlAllowCredentials,omitempty" export:"true"`
+	AccessControlAllowHeaders     []string `json:"accessControlAllowHeaders,omitempty" toml:"accessControlAllowHeaders,omitempty" yaml:"accessControlAllowHeaders,omitempty" export:"true"`
+	AccessControlAllowMethods     []string `json:"accessControlAllowMethods,omitempty" toml:"accessControlAllowMethods,omitempty" yaml:"accessControlAllowMethods,omitempty" export:"true"`
+	AccessControlAllowOriginList  []string `json:"accessControlAllowOriginList,omitempty" toml:"accessControlAllowOriginList,omitempty" yaml:"accessControlAllowOriginList,omitempty"`
+	AccessControlExposeHeaders    []string `json:"accessControlExposeHeaders,omitempty" toml:"accessControlExposeHeaders,omitempty" yaml:"accessControlExposeHeaders,omitempty" export:"true"`
+	AccessControlMaxAge           int64    `json:"accessControlMaxAge,omitempty" toml:"accessControlMaxAge,omitempty" yaml:"accessControlMaxAge,omitempty" export:"true"`
+	AddVaryHeader                 bool     `json:"addVaryHeader,omitempty" toml:"addVaryHeader,omitempty" yaml:"addVaryHeader,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Buffering holds the request/response buffering configuration.
+type Buffering struct {
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes,omitempty" toml:"maxRequestBodyBytes,omitempty" yaml:"maxRequestBodyBytes,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RateLimit holds the rate limit configuration.
+type RateLimit struct {
+	Average         int64               `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	Period          *intstr.IntOrString `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	Burst           *int64              `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+	SourceCriterion *SourceCriterion    `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// SourceCriterion defines what criterion is used to group requests as originating from a common source.
+type SourceCriterion struct {
+	RequestHeaderName string `json:"requestHeaderName,omitempty" toml:"requestHeaderName,omitempty" yaml:"requestHeaderName,omitempty" export:"true"`
+	RequestHost       bool   `json:"requestHost,omitempty" toml:"requestHost,omitempty" yaml:"requestHost,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // StripPrefix holds the StripPrefix configuration.
 type StripPrefix struct {
 	Prefixes   []string `json:"prefixes,omitempty" toml:"prefixes,omitempty" yaml:"prefixes,omitempty" export:"true"`
@@ -52,6 +52,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&IngressRoute{},
 		&IngressRouteList{},
+		&IngressRouteTCP{},
+		&IngressRouteTCPList{},
+		&IngressRouteUDP{},
+		&IngressRouteUDPList{},
 		&TraefikService{},
 		&TraefikServiceList{},
 		&Middleware{},
@@ -48,10 +48,22 @@ func (c *FakeHubV1alpha1) APIPortals() v1alpha1.APIPortalInterface {
 	return &FakeAPIPortals{c}
 }
 
+func (c *FakeHubV1alpha1) APIRateLimits() v1alpha1.APIRateLimitInterface {
+	return &FakeAPIRateLimits{c}
+}
+
+func (c *FakeHubV1alpha1) APIVersions(namespace string) v1alpha1.APIVersionInterface {
+	return &FakeAPIVersions{c, namespace}
+}
+
 func (c *FakeHubV1alpha1) AccessControlPolicies() v1alpha1.AccessControlPolicyInterface {
 	return &FakeAccessControlPolicies{c}
 }
 
+func (c *FakeHubV1alpha1) AlertSilences() v1alpha1.AlertSilenceInterface {
+	return &FakeAlertSilences{c}
+}
+
 func (c *FakeHubV1alpha1) EdgeIngresses(namespace string) v1alpha1.EdgeIngressInterface {
 	return &FakeEdgeIngresses{c, namespace}
 }
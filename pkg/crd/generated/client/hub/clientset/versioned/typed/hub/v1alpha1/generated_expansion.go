@@ -28,8 +28,14 @@ type APIGatewayExpansion interface{}
 
 type APIPortalExpansion interface{}
 
+type APIRateLimitExpansion interface{}
+
+type APIVersionExpansion interface{}
+
 type AccessControlPolicyExpansion interface{}
 
+type AlertSilenceExpansion interface{}
+
 type EdgeIngressExpansion interface{}
 
 type IngressClassExpansion interface{}
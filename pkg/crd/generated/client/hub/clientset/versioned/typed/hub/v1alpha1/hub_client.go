@@ -31,7 +31,10 @@ type HubV1alpha1Interface interface {
 	APICollectionsGetter
 	APIGatewaysGetter
 	APIPortalsGetter
+	APIRateLimitsGetter
+	APIVersionsGetter
 	AccessControlPoliciesGetter
+	AlertSilencesGetter
 	EdgeIngressesGetter
 	IngressClassesGetter
 }
@@ -61,10 +64,22 @@ func (c *HubV1alpha1Client) APIPortals() APIPortalInterface {
 	return newAPIPortals(c)
 }
 
+func (c *HubV1alpha1Client) APIRateLimits() APIRateLimitInterface {
+	return newAPIRateLimits(c)
+}
+
+func (c *HubV1alpha1Client) APIVersions(namespace string) APIVersionInterface {
+	return newAPIVersions(c, namespace)
+}
+
 func (c *HubV1alpha1Client) AccessControlPolicies() AccessControlPolicyInterface {
 	return newAccessControlPolicies(c)
 }
 
+func (c *HubV1alpha1Client) AlertSilences() AlertSilenceInterface {
+	return newAlertSilences(c)
+}
+
 func (c *HubV1alpha1Client) EdgeIngresses(namespace string) EdgeIngressInterface {
 	return newEdgeIngresses(c, namespace)
 }
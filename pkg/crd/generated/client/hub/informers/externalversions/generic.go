@@ -63,8 +63,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().APIGateways().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("apiportals"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().APIPortals().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("apiratelimits"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().APIRateLimits().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("apiversions"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().APIVersions().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("accesscontrolpolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().AccessControlPolicies().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("alertsilences"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().AlertSilences().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("edgeingresses"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().EdgeIngresses().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("ingressclasses"):
@@ -34,8 +34,14 @@ type Interface interface {
 	APIGateways() APIGatewayInformer
 	// APIPortals returns a APIPortalInformer.
 	APIPortals() APIPortalInformer
+	// APIRateLimits returns a APIRateLimitInformer.
+	APIRateLimits() APIRateLimitInformer
+	// APIVersions returns a APIVersionInformer.
+	APIVersions() APIVersionInformer
 	// AccessControlPolicies returns a AccessControlPolicyInformer.
 	AccessControlPolicies() AccessControlPolicyInformer
+	// AlertSilences returns a AlertSilenceInformer.
+	AlertSilences() AlertSilenceInformer
 	// EdgeIngresses returns a EdgeIngressInformer.
 	EdgeIngresses() EdgeIngressInformer
 	// IngressClasses returns a IngressClassInformer.
@@ -78,11 +84,26 @@ func (v *version) APIPortals() APIPortalInformer {
 	return &aPIPortalInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// APIRateLimits returns a APIRateLimitInformer.
+func (v *version) APIRateLimits() APIRateLimitInformer {
+	return &aPIRateLimitInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// APIVersions returns a APIVersionInformer.
+func (v *version) APIVersions() APIVersionInformer {
+	return &aPIVersionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // AccessControlPolicies returns a AccessControlPolicyInformer.
 func (v *version) AccessControlPolicies() AccessControlPolicyInformer {
 	return &accessControlPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// AlertSilences returns a AlertSilenceInformer.
+func (v *version) AlertSilences() AlertSilenceInformer {
+	return &alertSilenceInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // EdgeIngresses returns a EdgeIngressInformer.
 func (v *version) EdgeIngresses() EdgeIngressInformer {
 	return &edgeIngressInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -42,10 +42,26 @@ type APIGatewayListerExpansion interface{}
 // APIPortalLister.
 type APIPortalListerExpansion interface{}
 
+// APIRateLimitListerExpansion allows custom methods to be added to
+// APIRateLimitLister.
+type APIRateLimitListerExpansion interface{}
+
+// APIVersionListerExpansion allows custom methods to be added to
+// APIVersionLister.
+type APIVersionListerExpansion interface{}
+
+// APIVersionNamespaceListerExpansion allows custom methods to be added to
+// APIVersionNamespaceLister.
+type APIVersionNamespaceListerExpansion interface{}
+
 // AccessControlPolicyListerExpansion allows custom methods to be added to
 // AccessControlPolicyLister.
 type AccessControlPolicyListerExpansion interface{}
 
+// AlertSilenceListerExpansion allows custom methods to be added to
+// AlertSilenceLister.
+type AlertSilenceListerExpansion interface{}
+
 // EdgeIngressListerExpansion allows custom methods to be added to
 // EdgeIngressLister.
 type EdgeIngressListerExpansion interface{}
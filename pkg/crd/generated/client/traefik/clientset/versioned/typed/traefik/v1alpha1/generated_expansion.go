@@ -20,6 +20,10 @@ package v1alpha1
 
 type IngressRouteExpansion interface{}
 
+type IngressRouteTCPExpansion interface{}
+
+type IngressRouteUDPExpansion interface{}
+
 type MiddlewareExpansion interface{}
 
 type TLSOptionExpansion interface{}
@@ -27,6 +27,8 @@ import (
 type TraefikV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	IngressRoutesGetter
+	IngressRouteTCPsGetter
+	IngressRouteUDPsGetter
 	MiddlewaresGetter
 	TLSOptionsGetter
 	TraefikServicesGetter
@@ -41,6 +43,14 @@ func (c *TraefikV1alpha1Client) IngressRoutes(namespace string) IngressRouteInte
 	return newIngressRoutes(c, namespace)
 }
 
+func (c *TraefikV1alpha1Client) IngressRouteTCPs(namespace string) IngressRouteTCPInterface {
+	return newIngressRouteTCPs(c, namespace)
+}
+
+func (c *TraefikV1alpha1Client) IngressRouteUDPs(namespace string) IngressRouteUDPInterface {
+	return newIngressRouteUDPs(c, namespace)
+}
+
 func (c *TraefikV1alpha1Client) Middlewares(namespace string) MiddlewareInterface {
 	return newMiddlewares(c, namespace)
 }
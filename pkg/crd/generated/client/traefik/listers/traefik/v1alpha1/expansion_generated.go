@@ -26,6 +26,22 @@ type IngressRouteListerExpansion interface{}
 // IngressRouteNamespaceLister.
 type IngressRouteNamespaceListerExpansion interface{}
 
+// IngressRouteTCPListerExpansion allows custom methods to be added to
+// IngressRouteTCPLister.
+type IngressRouteTCPListerExpansion interface{}
+
+// IngressRouteTCPNamespaceListerExpansion allows custom methods to be added to
+// IngressRouteTCPNamespaceLister.
+type IngressRouteTCPNamespaceListerExpansion interface{}
+
+// IngressRouteUDPListerExpansion allows custom methods to be added to
+// IngressRouteUDPLister.
+type IngressRouteUDPListerExpansion interface{}
+
+// IngressRouteUDPNamespaceListerExpansion allows custom methods to be added to
+// IngressRouteUDPNamespaceLister.
+type IngressRouteUDPNamespaceListerExpansion interface{}
+
 // MiddlewareListerExpansion allows custom methods to be added to
 // MiddlewareLister.
 type MiddlewareListerExpansion interface{}
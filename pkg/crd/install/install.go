@@ -0,0 +1,483 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package install installs and upgrades the CustomResourceDefinitions of the hub.traefik.io group.
+package install
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	goversion "github.com/hashicorp/go-version"
+	"github.com/rs/zerolog/log"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
+)
+
+// AnnotationAgentVersion is the annotation holding the version of the agent which last installed or upgraded a CRD.
+const AnnotationAgentVersion = "hub.traefik.io/agent-version"
+
+const kindCRD = "CustomResourceDefinition"
+
+// specFields are the fields of the CRD specs managed by the agent. The other ones, such as the conversion webhook
+// configuration, are left untouched.
+var specFields = []string{"group", "names", "scope", "versions"}
+
+var (
+	crdResource                = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	clusterRoleResource        = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+	clusterRoleBindingResource = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}
+)
+
+// kind describes a kind of resource installed by the Manager.
+type kind struct {
+	resource schema.GroupVersionResource
+	// fields are the paths of the fields managed by the agent, the other ones are left untouched.
+	fields [][]string
+	// diff returns the managed fields of the installed resource which differ from the desired one.
+	diff func(installed, desired *unstructured.Unstructured) []string
+}
+
+var kinds = map[string]kind{
+	kindCRD: {
+		resource: crdResource,
+		fields:   [][]string{{"spec", "group"}, {"spec", "names"}, {"spec", "scope"}, {"spec", "versions"}},
+		diff:     Diff,
+	},
+	"ClusterRole": {
+		resource: clusterRoleResource,
+		fields:   [][]string{{"aggregationRule"}, {"rules"}},
+		diff:     diffFields("aggregationRule", "rules"),
+	},
+	"ClusterRoleBinding": {
+		resource: clusterRoleBindingResource,
+		fields:   [][]string{{"roleRef"}, {"subjects"}},
+		diff:     diffFields("roleRef", "subjects"),
+	},
+}
+
+// manifests are generated by controller-gen from the v1alpha1 types, see scripts/code-gen.sh.
+//
+//go:embed crds/*.yaml
+var manifests embed.FS
+
+// CRDs returns the CustomResourceDefinitions of the hub.traefik.io group, sorted by name.
+func CRDs() ([]*unstructured.Unstructured, error) {
+	files, err := fs.Glob(manifests, "crds/*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("list CRD manifests: %w", err)
+	}
+
+	crds := make([]*unstructured.Unstructured, 0, len(files))
+	for _, file := range files {
+		data, err := manifests.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read CRD manifest %q: %w", path.Base(file), err)
+		}
+
+		js, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode CRD manifest %q: %w", path.Base(file), err)
+		}
+
+		crd := &unstructured.Unstructured{}
+		if err = crd.UnmarshalJSON(js); err != nil {
+			return nil, fmt.Errorf("decode CRD manifest %q: %w", path.Base(file), err)
+		}
+
+		// Generated manifests carry an empty status, which is set by the API server.
+		unstructured.RemoveNestedField(crd.Object, "status")
+		unstructured.RemoveNestedField(crd.Object, "metadata", "creationTimestamp")
+
+		crds = append(crds, crd)
+	}
+
+	sort.Slice(crds, func(i, j int) bool {
+		return crds[i].GetName() < crds[j].GetName()
+	})
+
+	return crds, nil
+}
+
+// Manager installs the CRDs of the hub.traefik.io group, and upgrades the ones whose schema differ from the CRDs
+// this agent version was built with. It can also install the RBAC objects granting access to them.
+type Manager struct {
+	client  dynamic.Interface
+	version string
+	rbac    *RBACConfig
+
+	establishTimeout time.Duration
+}
+
+// NewManager returns a manager installing the CRDs on behalf of the given agent version.
+func NewManager(client dynamic.Interface, agentVersion string) *Manager {
+	return &Manager{
+		client:           client,
+		version:          agentVersion,
+		establishTimeout: time.Minute,
+	}
+}
+
+// SetRBAC makes the manager install and update the RBAC objects granting access to the CRDs along with them.
+func (m *Manager) SetRBAC(cfg RBACConfig) {
+	m.rbac = &cfg
+}
+
+// Change is a change the Manager makes to an installed resource.
+type Change struct {
+	// Current is the resource as installed, nil if the resource is not installed yet.
+	Current *unstructured.Unstructured
+	// Desired is the resource once changed.
+	Desired *unstructured.Unstructured
+	// Fields are the changed fields, e.g. `versions[v1alpha1].schema` or `rules`. They are empty on installation.
+	Fields []string
+}
+
+// Run installs or upgrades the CRDs, and the RBAC objects if enabled, and waits for the API server to serve the CRDs.
+func (m *Manager) Run(ctx context.Context) error {
+	objects, err := m.objects()
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		if err = m.apply(ctx, obj); err != nil {
+			return fmt.Errorf("apply %s %q: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	for _, obj := range objects {
+		if obj.GetKind() != kindCRD {
+			continue
+		}
+
+		if err = m.waitEstablished(ctx, obj.GetName()); err != nil {
+			return fmt.Errorf("wait for CRD %q: %w", obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// Plan returns the changes Run would make, without making them.
+func (m *Manager) Plan(ctx context.Context) ([]Change, error) {
+	objects, err := m.objects()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, obj := range objects {
+		change, err := m.plan(ctx, obj)
+		if err != nil {
+			return nil, fmt.Errorf("plan %s %q: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+
+	return changes, nil
+}
+
+// objects returns the resources to install: the CRDs, followed by the RBAC objects if enabled.
+func (m *Manager) objects() ([]*unstructured.Unstructured, error) {
+	crds, err := CRDs()
+	if err != nil {
+		return nil, err
+	}
+	if m.rbac == nil {
+		return crds, nil
+	}
+
+	rbac, err := RBAC(*m.rbac)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(crds, rbac...), nil
+}
+
+func (m *Manager) apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	logger := log.With().Str("kind", obj.GetKind()).Str("name", obj.GetName()).Logger()
+	client := m.client.Resource(kinds[obj.GetKind()].resource)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		change, err := m.plan(ctx, obj)
+		if err != nil || change == nil {
+			return err
+		}
+
+		// The role of a binding can't be changed, the binding is recreated instead.
+		if change.Current != nil && slices.Contains(change.Fields, "roleRef") {
+			if err = client.Delete(ctx, obj.GetName(), metav1.DeleteOptions{}); err != nil && !kerror.IsNotFound(err) {
+				return fmt.Errorf("delete: %w", err)
+			}
+
+			change.Desired = obj.DeepCopy()
+			setAgentVersion(change.Desired, m.version)
+			change.Current = nil
+		}
+
+		if change.Current == nil {
+			_, err = client.Create(ctx, change.Desired, metav1.CreateOptions{})
+			if kerror.IsAlreadyExists(err) {
+				// Another replica installed it in the meantime, compare it with the expected one.
+				return kerror.NewConflict(kinds[obj.GetKind()].resource.GroupResource(), obj.GetName(), err)
+			}
+			if err != nil {
+				return fmt.Errorf("create: %w", err)
+			}
+
+			logger.Info().Msg("Resource installed")
+			return nil
+		}
+
+		if _, err = client.Update(ctx, change.Desired, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		logger.Info().Strs("changes", change.Fields).Msg("Resource upgraded")
+		return nil
+	})
+}
+
+// plan returns the change to make to the installed resource to match the given one, nil if there's none.
+func (m *Manager) plan(ctx context.Context, obj *unstructured.Unstructured) (*Change, error) {
+	k := kinds[obj.GetKind()]
+
+	current, err := m.client.Resource(k.resource).Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		desired := obj.DeepCopy()
+		setAgentVersion(desired, m.version)
+
+		return &Change{Desired: desired}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	if newer, installedVersion := m.installedByNewerAgent(current); newer {
+		log.Warn().
+			Str("kind", obj.GetKind()).
+			Str("name", obj.GetName()).
+			Str("installed_by", installedVersion).
+			Msg("Resource installed by a newer agent version, not downgrading it")
+		return nil, nil
+	}
+
+	fields := k.diff(current, obj)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	desired := current.DeepCopy()
+	for _, field := range k.fields {
+		value, found, _ := unstructured.NestedFieldCopy(obj.Object, field...)
+		if !found {
+			unstructured.RemoveNestedField(desired.Object, field...)
+			continue
+		}
+
+		if err = unstructured.SetNestedField(desired.Object, value, field...); err != nil {
+			return nil, fmt.Errorf("set %s: %w", strings.Join(field, "."), err)
+		}
+	}
+
+	labels := desired.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for key, value := range obj.GetLabels() {
+		labels[key] = value
+	}
+	desired.SetLabels(labels)
+	setAgentVersion(desired, m.version)
+
+	return &Change{Current: current, Desired: desired, Fields: fields}, nil
+}
+
+// installedByNewerAgent reports whether the given CRD was installed by a more recent agent version, along with this
+// version. CRDs installed by other means, and development builds, are not compared.
+func (m *Manager) installedByNewerAgent(crd *unstructured.Unstructured) (bool, string) {
+	installedVersion := crd.GetAnnotations()[AnnotationAgentVersion]
+	if installedVersion == "" {
+		return false, ""
+	}
+
+	installed, err := goversion.NewVersion(installedVersion)
+	if err != nil {
+		return false, installedVersion
+	}
+	current, err := goversion.NewVersion(m.version)
+	if err != nil {
+		return false, installedVersion
+	}
+
+	return installed.GreaterThan(current), installedVersion
+}
+
+func (m *Manager) waitEstablished(ctx context.Context, name string) error {
+	return wait.PollImmediateWithContext(ctx, time.Second, m.establishTimeout, func(ctx context.Context) (bool, error) {
+		crd, err := m.client.Resource(crdResource).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+		for _, condition := range conditions {
+			c, ok := condition.(map[string]interface{})
+			if ok && c["type"] == "Established" && c["status"] == "True" {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+}
+
+// diffFields returns a function comparing the given top level fields of resources, along with the labels of the
+// desired resource which are used to aggregate ClusterRoles.
+func diffFields(fields ...string) func(installed, desired *unstructured.Unstructured) []string {
+	return func(installed, desired *unstructured.Unstructured) []string {
+		var changes []string
+		for _, field := range fields {
+			if !reflect.DeepEqual(installed.Object[field], desired.Object[field]) {
+				changes = append(changes, field)
+			}
+		}
+
+		installedLabels := installed.GetLabels()
+		for key, value := range desired.GetLabels() {
+			if installedLabels[key] != value {
+				changes = append(changes, "metadata.labels")
+				break
+			}
+		}
+
+		return changes
+	}
+}
+
+// Diff returns the fields of the spec of the installed CRD which differ from the desired one. Versions are compared
+// one by one, so that the changes read like `versions[v1alpha1].schema`.
+func Diff(installed, desired *unstructured.Unstructured) []string {
+	var changes []string
+	for _, field := range specFields {
+		if field == "versions" {
+			continue
+		}
+
+		installedValue, _, _ := unstructured.NestedFieldNoCopy(installed.Object, "spec", field)
+		desiredValue, _, _ := unstructured.NestedFieldNoCopy(desired.Object, "spec", field)
+		if !reflect.DeepEqual(installedValue, desiredValue) {
+			changes = append(changes, field)
+		}
+	}
+
+	installedVersions := versionsByName(installed)
+	desiredVersions := versionsByName(desired)
+
+	var names []string
+	for name := range installedVersions {
+		names = append(names, name)
+	}
+	for name := range desiredVersions {
+		if _, ok := installedVersions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		installedVersion, isInstalled := installedVersions[name]
+		desiredVersion, isDesired := desiredVersions[name]
+
+		switch {
+		case !isInstalled:
+			changes = append(changes, fmt.Sprintf("versions[%s]: added", name))
+		case !isDesired:
+			changes = append(changes, fmt.Sprintf("versions[%s]: removed", name))
+		default:
+			changes = append(changes, diffVersion(name, installedVersion, desiredVersion)...)
+		}
+	}
+
+	return changes
+}
+
+func diffVersion(name string, installed, desired map[string]interface{}) []string {
+	keys := make(map[string]struct{})
+	for key := range installed {
+		keys[key] = struct{}{}
+	}
+	for key := range desired {
+		keys[key] = struct{}{}
+	}
+
+	var changes []string
+	for key := range keys {
+		if !reflect.DeepEqual(installed[key], desired[key]) {
+			changes = append(changes, fmt.Sprintf("versions[%s].%s", name, key))
+		}
+	}
+	sort.Strings(changes)
+
+	return changes
+}
+
+func versionsByName(crd *unstructured.Unstructured) map[string]map[string]interface{} {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+
+	byName := make(map[string]map[string]interface{}, len(versions))
+	for _, version := range versions {
+		v, ok := version.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := v["name"].(string)
+		byName[name] = v
+	}
+
+	return byName
+}
+
+func setAgentVersion(crd *unstructured.Unstructured, agentVersion string) {
+	annotations := crd.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationAgentVersion] = agentVersion
+
+	crd.SetAnnotations(annotations)
+}
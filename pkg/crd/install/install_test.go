@@ -0,0 +1,335 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package install
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestCRDs(t *testing.T) {
+	crds, err := CRDs()
+	require.NoError(t, err)
+
+	var names []string
+	for _, crd := range crds {
+		names = append(names, crd.GetName())
+
+		assert.Equal(t, "CustomResourceDefinition", crd.GetKind())
+		assert.NotContains(t, crd.Object, "status")
+
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		assert.Equal(t, "hub.traefik.io", group)
+	}
+
+	assert.Equal(t, []string{
+		"accesscontrolpolicies.hub.traefik.io",
+		"alertsilences.hub.traefik.io",
+		"apiaccesses.hub.traefik.io",
+		"apicollections.hub.traefik.io",
+		"apigateways.hub.traefik.io",
+		"apiportals.hub.traefik.io",
+		"apiratelimits.hub.traefik.io",
+		"apis.hub.traefik.io",
+		"apiversions.hub.traefik.io",
+		"edgeingresses.hub.traefik.io",
+		"ingressclasses.hub.traefik.io",
+	}, names)
+}
+
+func TestManager_Run_install(t *testing.T) {
+	client := newFakeClient()
+
+	err := NewManager(client, "v1.2.0").Run(context.Background())
+	require.NoError(t, err)
+
+	crds, err := CRDs()
+	require.NoError(t, err)
+
+	for _, crd := range crds {
+		installed := getCRD(t, client, crd.GetName())
+
+		assert.Equal(t, "v1.2.0", installed.GetAnnotations()[AnnotationAgentVersion])
+		assert.Empty(t, Diff(installed, crd))
+	}
+}
+
+func TestManager_Run_upgrade(t *testing.T) {
+	outdated := edgeIngressCRD(t)
+	outdated.SetAnnotations(map[string]string{AnnotationAgentVersion: "v1.1.0"})
+	require.NoError(t, unstructured.SetNestedSlice(outdated.Object, []interface{}{
+		map[string]interface{}{
+			"name":    "v1alpha1",
+			"served":  true,
+			"storage": true,
+			"schema": map[string]interface{}{
+				"openAPIV3Schema": map[string]interface{}{"type": "object"},
+			},
+		},
+	}, "spec", "versions"))
+	conversion := map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"clientConfig":             map[string]interface{}{"caBundle": "Y2E="},
+			"conversionReviewVersions": []interface{}{"v1"},
+		},
+	}
+	require.NoError(t, unstructured.SetNestedMap(outdated.Object, conversion, "spec", "conversion"))
+
+	client := newFakeClient(outdated)
+
+	err := NewManager(client, "v1.2.0").Run(context.Background())
+	require.NoError(t, err)
+
+	installed := getCRD(t, client, "edgeingresses.hub.traefik.io")
+	assert.Equal(t, "v1.2.0", installed.GetAnnotations()[AnnotationAgentVersion])
+	assert.Empty(t, Diff(installed, edgeIngressCRD(t)))
+
+	gotConversion, _, err := unstructured.NestedMap(installed.Object, "spec", "conversion")
+	require.NoError(t, err)
+	assert.Equal(t, conversion, gotConversion)
+}
+
+func TestManager_Run_installedByNewerAgent(t *testing.T) {
+	newer := edgeIngressCRD(t)
+	newer.SetAnnotations(map[string]string{AnnotationAgentVersion: "v1.3.0"})
+	unstructured.RemoveNestedField(newer.Object, "spec", "versions")
+
+	client := newFakeClient(newer)
+
+	err := NewManager(client, "v1.2.0").Run(context.Background())
+	require.NoError(t, err)
+
+	installed := getCRD(t, client, "edgeingresses.hub.traefik.io")
+	assert.Equal(t, "v1.3.0", installed.GetAnnotations()[AnnotationAgentVersion])
+	assert.Equal(t, []string{"versions[v1alpha1]: added"}, Diff(installed, edgeIngressCRD(t)))
+}
+
+func TestManager_Run_rbac(t *testing.T) {
+	// A binding to another role can't be updated, it must be recreated.
+	binding := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRoleBinding",
+		"metadata":   map[string]interface{}{"name": ClusterRoleController},
+		"roleRef": map[string]interface{}{
+			"apiGroup": "rbac.authorization.k8s.io",
+			"kind":     "ClusterRole",
+			"name":     "cluster-admin",
+		},
+	}}
+
+	client := newFakeClient(binding)
+
+	m := NewManager(client, "v1.2.0")
+	m.SetRBAC(RBACConfig{ServiceAccountNamespace: "hub", ServiceAccountName: "hub-agent-controller"})
+
+	require.NoError(t, m.Run(context.Background()))
+
+	rbac, err := RBAC(RBACConfig{ServiceAccountNamespace: "hub", ServiceAccountName: "hub-agent-controller"})
+	require.NoError(t, err)
+
+	for _, obj := range rbac {
+		installed, err := client.Resource(kinds[obj.GetKind()].resource).Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, "v1.2.0", installed.GetAnnotations()[AnnotationAgentVersion])
+		assert.Empty(t, kinds[obj.GetKind()].diff(installed, obj), "%s %s", obj.GetKind(), obj.GetName())
+	}
+
+	changes, err := m.Plan(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestManager_Plan(t *testing.T) {
+	outdated := edgeIngressCRD(t)
+	require.NoError(t, unstructured.SetNestedField(outdated.Object, "Cluster", "spec", "scope"))
+
+	role := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRole",
+		"metadata": map[string]interface{}{
+			"name":   ClusterRoleView,
+			"labels": map[string]interface{}{"app.kubernetes.io/managed-by": "Helm"},
+		},
+	}}
+
+	client := newFakeClient(outdated, role)
+
+	m := NewManager(client, "v1.2.0")
+	m.SetRBAC(RBACConfig{ServiceAccountNamespace: "hub", ServiceAccountName: "hub-agent-controller"})
+
+	changes, err := m.Plan(context.Background())
+	require.NoError(t, err)
+
+	changed := make(map[string]Change)
+	for _, change := range changes {
+		changed[change.Desired.GetKind()+"/"+change.Desired.GetName()] = change
+	}
+
+	// Every CRD and RBAC object is installed, apart from the up-to-date ones.
+	crds, err := CRDs()
+	require.NoError(t, err)
+	assert.Len(t, changes, len(crds)+4)
+
+	change := changed["CustomResourceDefinition/edgeingresses.hub.traefik.io"]
+	require.NotNil(t, change.Current)
+	assert.Equal(t, []string{"scope"}, change.Fields)
+	assert.Empty(t, Diff(change.Desired, edgeIngressCRD(t)))
+
+	change = changed["ClusterRole/"+ClusterRoleView]
+	require.NotNil(t, change.Current)
+	assert.Equal(t, []string{"rules", "metadata.labels"}, change.Fields)
+	assert.Equal(t, "Helm", change.Desired.GetLabels()["app.kubernetes.io/managed-by"])
+	assert.Equal(t, "true", change.Desired.GetLabels()["rbac.authorization.k8s.io/aggregate-to-view"])
+
+	change = changed["ClusterRoleBinding/"+ClusterRoleController]
+	assert.Nil(t, change.Current)
+	assert.Equal(t, "v1.2.0", change.Desired.GetAnnotations()[AnnotationAgentVersion])
+
+	// Nothing is applied.
+	installed := getCRD(t, client, "edgeingresses.hub.traefik.io")
+	assert.Equal(t, []string{"scope"}, Diff(installed, edgeIngressCRD(t)))
+
+	_, err = client.Resource(clusterRoleBindingResource).Get(context.Background(), ClusterRoleController, metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		desc   string
+		update func(crd *unstructured.Unstructured) error
+		want   []string
+	}{
+		{
+			desc:   "same spec",
+			update: func(crd *unstructured.Unstructured) error { return nil },
+		},
+		{
+			desc: "unmanaged fields",
+			update: func(crd *unstructured.Unstructured) error {
+				crd.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "Helm"})
+				return unstructured.SetNestedField(crd.Object, "None", "spec", "conversion", "strategy")
+			},
+		},
+		{
+			desc: "scope and short names",
+			update: func(crd *unstructured.Unstructured) error {
+				if err := unstructured.SetNestedField(crd.Object, "Cluster", "spec", "scope"); err != nil {
+					return err
+				}
+				return unstructured.SetNestedStringSlice(crd.Object, []string{"edge"}, "spec", "names", "shortNames")
+			},
+			want: []string{"names", "scope"},
+		},
+		{
+			desc: "version schema and printer columns",
+			update: func(crd *unstructured.Unstructured) error {
+				versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+				version := versions[0].(map[string]interface{})
+				delete(version, "additionalPrinterColumns")
+				version["schema"] = map[string]interface{}{"openAPIV3Schema": map[string]interface{}{"type": "object"}}
+
+				return unstructured.SetNestedSlice(crd.Object, versions, "spec", "versions")
+			},
+			want: []string{"versions[v1alpha1].additionalPrinterColumns", "versions[v1alpha1].schema"},
+		},
+		{
+			desc: "added and removed versions",
+			update: func(crd *unstructured.Unstructured) error {
+				versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+				versions[0].(map[string]interface{})["name"] = "v1alpha2"
+
+				return unstructured.SetNestedSlice(crd.Object, versions, "spec", "versions")
+			},
+			want: []string{"versions[v1alpha1]: added", "versions[v1alpha2]: removed"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			desired := edgeIngressCRD(t)
+			installed := desired.DeepCopy()
+			require.NoError(t, test.update(installed))
+
+			assert.Equal(t, test.want, Diff(installed, desired))
+		})
+	}
+}
+
+// newFakeClient returns a dynamic client holding the given CRDs, on which CRDs get established once created.
+func newFakeClient(objects ...runtime.Object) *dynfake.FakeDynamicClient {
+	for _, object := range objects {
+		establish(object.(*unstructured.Unstructured))
+	}
+
+	client := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdResource: "CustomResourceDefinitionList"},
+		objects...,
+	)
+	client.PrependReactor("create", "customresourcedefinitions", func(action ktesting.Action) (bool, runtime.Object, error) {
+		establish(action.(ktesting.CreateAction).GetObject().(*unstructured.Unstructured))
+		return false, nil, nil
+	})
+
+	return client
+}
+
+func establish(crd *unstructured.Unstructured) {
+	_ = unstructured.SetNestedSlice(crd.Object, []interface{}{
+		map[string]interface{}{"type": "Established", "status": "True"},
+	}, "status", "conditions")
+}
+
+func edgeIngressCRD(t *testing.T) *unstructured.Unstructured {
+	t.Helper()
+
+	crds, err := CRDs()
+	require.NoError(t, err)
+
+	for _, crd := range crds {
+		if crd.GetName() == "edgeingresses.hub.traefik.io" {
+			return crd
+		}
+	}
+
+	require.Fail(t, "EdgeIngress CRD not found")
+	return nil
+}
+
+func getCRD(t *testing.T, client *dynfake.FakeDynamicClient, name string) *unstructured.Unstructured {
+	t.Helper()
+
+	crd, err := client.Resource(crdResource).Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	return crd
+}
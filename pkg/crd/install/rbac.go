@@ -0,0 +1,158 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package install
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Names of the RBAC objects granting access to the hub.traefik.io resources.
+const (
+	ClusterRoleController = "hub-agent-crds"
+	ClusterRoleView       = "hub-agent-crds-view"
+	ClusterRoleEdit       = "hub-agent-crds-edit"
+)
+
+// resourceACPs is the resource of the AccessControlPolicies, which hold secrets and can't be read by the view role.
+const resourceACPs = "accesscontrolpolicies"
+
+// RBACConfig configures the RBAC objects granting access to the hub.traefik.io resources.
+type RBACConfig struct {
+	// ServiceAccountNamespace and ServiceAccountName identify the service account of the controller.
+	ServiceAccountNamespace string
+	ServiceAccountName      string
+}
+
+// RBAC returns the RBAC objects granting access to the hub.traefik.io resources:
+//   - a ClusterRole, bound to the service account of the controller, allowing it to manage the resources and their
+//     CRDs,
+//   - ClusterRoles aggregated to the view, edit and admin default roles, so that users can read and edit the
+//     resources according to their role. AccessControlPolicies hold secrets, so like Secrets, they can only be read
+//     by the edit and admin roles.
+func RBAC(cfg RBACConfig) ([]*unstructured.Unstructured, error) {
+	crds, err := CRDs()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]string, 0, len(crds))
+	viewResources := make([]string, 0, len(crds))
+	for _, crd := range crds {
+		plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+		resources = append(resources, plural)
+
+		if plural != resourceACPs {
+			viewResources = append(viewResources, plural)
+		}
+	}
+
+	objects := []runtime.Object{
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleController},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{"hub.traefik.io"},
+					Resources: resources,
+					Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+				},
+				{
+					APIGroups: []string{"apiextensions.k8s.io"},
+					Resources: []string{"customresourcedefinitions"},
+					Verbs:     []string{"get", "list", "create", "update", "patch"},
+				},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleController},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     ClusterRoleController,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					Kind:      rbacv1.ServiceAccountKind,
+					Namespace: cfg.ServiceAccountNamespace,
+					Name:      cfg.ServiceAccountName,
+				},
+			},
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ClusterRoleView,
+				Labels: map[string]string{
+					"rbac.authorization.k8s.io/aggregate-to-view":  "true",
+					"rbac.authorization.k8s.io/aggregate-to-edit":  "true",
+					"rbac.authorization.k8s.io/aggregate-to-admin": "true",
+				},
+			},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{"hub.traefik.io"},
+					Resources: viewResources,
+					Verbs:     []string{"get", "list", "watch"},
+				},
+			},
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta: metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ClusterRoleEdit,
+				Labels: map[string]string{
+					"rbac.authorization.k8s.io/aggregate-to-edit":  "true",
+					"rbac.authorization.k8s.io/aggregate-to-admin": "true",
+				},
+			},
+			Rules: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{"hub.traefik.io"},
+					Resources: []string{resourceACPs},
+					Verbs:     []string{"get", "list", "watch"},
+				},
+				{
+					APIGroups: []string{"hub.traefik.io"},
+					Resources: resources,
+					Verbs:     []string{"create", "update", "patch", "delete", "deletecollection"},
+				},
+			},
+		},
+	}
+
+	rbac := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, object := range objects {
+		raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			return nil, fmt.Errorf("convert %T: %w", object, err)
+		}
+
+		obj := &unstructured.Unstructured{Object: raw}
+		unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+
+		rbac = append(rbac, obj)
+	}
+
+	return rbac, nil
+}
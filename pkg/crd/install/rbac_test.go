@@ -0,0 +1,78 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package install
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRBAC(t *testing.T) {
+	rbac, err := RBAC(RBACConfig{ServiceAccountNamespace: "hub", ServiceAccountName: "hub-agent-controller"})
+	require.NoError(t, err)
+
+	var objects []string
+	for _, obj := range rbac {
+		objects = append(objects, obj.GetKind()+"/"+obj.GetName())
+		assert.NotContains(t, obj.Object["metadata"], "creationTimestamp")
+	}
+	assert.Equal(t, []string{
+		"ClusterRole/hub-agent-crds",
+		"ClusterRoleBinding/hub-agent-crds",
+		"ClusterRole/hub-agent-crds-view",
+		"ClusterRole/hub-agent-crds-edit",
+	}, objects)
+
+	// Every hub.traefik.io resource is granted.
+	rules, _, err := unstructured.NestedSlice(rbac[0].Object, "rules")
+	require.NoError(t, err)
+	resources, _, err := unstructured.NestedStringSlice(rules[0].(map[string]interface{}), "resources")
+	require.NoError(t, err)
+	assert.Contains(t, resources, "accesscontrolpolicies")
+	assert.Contains(t, resources, "edgeingresses")
+	assert.Len(t, resources, 11)
+
+	subjects, _, err := unstructured.NestedSlice(rbac[1].Object, "subjects")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"kind": "ServiceAccount", "namespace": "hub", "name": "hub-agent-controller"},
+	}, subjects)
+
+	assert.Equal(t, "true", rbac[2].GetLabels()["rbac.authorization.k8s.io/aggregate-to-view"])
+	assert.NotContains(t, rbac[3].GetLabels(), "rbac.authorization.k8s.io/aggregate-to-view")
+
+	// AccessControlPolicies hold secrets and are only readable through the edit role.
+	viewRules, _, err := unstructured.NestedSlice(rbac[2].Object, "rules")
+	require.NoError(t, err)
+	viewResources, _, err := unstructured.NestedStringSlice(viewRules[0].(map[string]interface{}), "resources")
+	require.NoError(t, err)
+	assert.NotContains(t, viewResources, "accesscontrolpolicies")
+	assert.Contains(t, viewResources, "edgeingresses")
+	assert.Len(t, viewResources, 10)
+
+	editRules, _, err := unstructured.NestedSlice(rbac[3].Object, "rules")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"apiGroups": []interface{}{"hub.traefik.io"},
+		"resources": []interface{}{"accesscontrolpolicies"},
+		"verbs":     []interface{}{"get", "list", "watch"},
+	}, editRules[0])
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package diagnostics builds the diagnostics bundles of the agent, gathering what's needed to troubleshoot an
+// installation in a tarball, without including any secret data.
+package diagnostics
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+// bundleDir is the directory the files of a bundle are in, once extracted.
+const bundleDir = "hub-agent-diagnostics"
+
+// Source adds files to a bundle. The files which can't be collected are recorded with Bundle.AddError rather than
+// failing the whole bundle: a source only returns an error when the bundle can't be written.
+type Source func(ctx context.Context, b *Bundle) error
+
+// Bundle is a diagnostics bundle being written.
+type Bundle struct {
+	tw     *tar.Writer
+	now    time.Time
+	errors map[string]string
+}
+
+// Write writes the bundle of the given sources to w, as a gzipped tarball. The files which couldn't be collected are
+// listed, with their error, in the errors.json file of the bundle.
+func Write(ctx context.Context, w io.Writer, sources ...Source) error {
+	gw := gzip.NewWriter(w)
+
+	b := &Bundle{
+		tw:     tar.NewWriter(gw),
+		now:    time.Now(),
+		errors: make(map[string]string),
+	}
+
+	for _, source := range sources {
+		if err := source(ctx, b); err != nil {
+			return err
+		}
+	}
+
+	if len(b.errors) > 0 {
+		if err := b.Add("errors.json", b.errors); err != nil {
+			return err
+		}
+	}
+
+	if err := b.tw.Close(); err != nil {
+		return fmt.Errorf("close tarball: %w", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("close gzip writer: %w", err)
+	}
+
+	return nil
+}
+
+// Add adds a file to the bundle. Byte slices and strings are written as is, other contents as indented JSON.
+func (b *Bundle) Add(name string, content any) error {
+	var data []byte
+	switch c := content.(type) {
+	case []byte:
+		data = c
+	case string:
+		data = []byte(c)
+	default:
+		var err error
+		data, err = json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			b.AddError(name, fmt.Errorf("marshal: %w", err))
+			return nil
+		}
+		data = append(data, '\n')
+	}
+
+	err := b.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Join(bundleDir, name),
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  b.now,
+	})
+	if err != nil {
+		return fmt.Errorf("write header of %q: %w", name, err)
+	}
+
+	if _, err = b.tw.Write(data); err != nil {
+		return fmt.Errorf("write %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// AddError records that the given file couldn't be collected.
+func (b *Bundle) AddError(name string, err error) {
+	b.errors[name] = err.Error()
+}
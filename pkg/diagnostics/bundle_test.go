@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(context.Background(), &buf,
+		func(_ context.Context, b *Bundle) error {
+			if err := b.Add("raw.log", []byte("raw\n")); err != nil {
+				return err
+			}
+			return b.Add("text.txt", "text\n")
+		},
+		func(_ context.Context, b *Bundle) error {
+			b.AddError("missing.json", errors.New("boom"))
+			return b.Add("object.json", map[string]int{"answer": 42})
+		},
+	)
+	require.NoError(t, err)
+
+	want := map[string]string{
+		"raw.log":     "raw\n",
+		"text.txt":    "text\n",
+		"object.json": "{\n  \"answer\": 42\n}\n",
+		"errors.json": "{\n  \"missing.json\": \"boom\"\n}\n",
+	}
+	assert.Equal(t, want, readBundle(t, buf.Bytes()))
+}
+
+func TestWrite_sourceError(t *testing.T) {
+	err := Write(context.Background(), io.Discard, func(_ context.Context, _ *Bundle) error {
+		return errors.New("boom")
+	})
+	assert.Error(t, err)
+}
+
+// readBundle returns the content of the files of the given bundle, by name.
+func readBundle(t *testing.T, bundle []byte) map[string]string {
+	t.Helper()
+
+	gzr, err := gzip.NewReader(bytes.NewReader(bundle))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+
+		name, ok := strings.CutPrefix(header.Name, bundleDir+"/")
+		require.True(t, ok, header.Name)
+
+		files[name] = string(content)
+	}
+}
@@ -0,0 +1,293 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+
+	admv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+// ClusterConfig configures the collection of the diagnostics of an agent installation from the Kubernetes API.
+type ClusterConfig struct {
+	// Namespace is the namespace of the agent.
+	Namespace string
+	// Selector is the label selector of the agent pods, all the pods of the namespace are selected if empty.
+	Selector string
+	// LogLines is the number of lines of logs collected from each container.
+	LogLines int64
+	// ProcessEndpoint returns the endpoint on which a pod serves the diagnostics of its command, fetched through the
+	// API server proxy. They aren't fetched if it's nil or returns false.
+	ProcessEndpoint func(pod *corev1.Pod) (ProcessEndpoint, bool)
+}
+
+// ProcessEndpoint is the endpoint on which a pod serves the diagnostics of its command.
+type ProcessEndpoint struct {
+	Scheme string
+	Port   string
+	Path   string
+}
+
+// PodReport is the state of an agent pod.
+type PodReport struct {
+	Name       string            `json:"name"`
+	Node       string            `json:"node"`
+	Phase      corev1.PodPhase   `json:"phase"`
+	StartTime  *metav1.Time      `json:"startTime,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Containers []ContainerReport `json:"containers"`
+}
+
+// ContainerReport is the state of a container of an agent pod. The values of the flags holding secret data are
+// redacted, and the ones read from Secrets or ConfigMaps are only referenced.
+type ContainerReport struct {
+	Name                  string      `json:"name"`
+	Image                 string      `json:"image"`
+	ImageID               string      `json:"imageID,omitempty"`
+	Ready                 bool        `json:"ready"`
+	RestartCount          int32       `json:"restartCount"`
+	LastTerminationReason string      `json:"lastTerminationReason,omitempty"`
+	Args                  []string    `json:"args,omitempty"`
+	Env                   []EnvReport `json:"env,omitempty"`
+	EnvFrom               []string    `json:"envFrom,omitempty"`
+}
+
+// EnvReport is an environment variable of a container.
+type EnvReport struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+	From  string `json:"from,omitempty"`
+}
+
+// WebhooksReport lists the admission webhook configurations calling the agent.
+type WebhooksReport struct {
+	Mutating   []admv1.MutatingWebhookConfiguration   `json:"mutating"`
+	Validating []admv1.ValidatingWebhookConfiguration `json:"validating"`
+}
+
+// ClusterSource returns the source adding the diagnostics of the agent installation to a bundle, read from the
+// Kubernetes API: the Kubernetes version, the agent pods with their recent logs and the diagnostics of their
+// command, and the admission webhook configurations calling the agent. Secrets are never read.
+func ClusterSource(client kclientset.Interface, cfg ClusterConfig) Source {
+	return func(ctx context.Context, b *Bundle) error {
+		if serverVersion, err := client.Discovery().ServerVersion(); err != nil {
+			b.AddError("kubernetes.json", err)
+		} else if err = b.Add("kubernetes.json", serverVersion); err != nil {
+			return err
+		}
+
+		pods, err := client.CoreV1().Pods(cfg.Namespace).List(ctx, metav1.ListOptions{LabelSelector: cfg.Selector})
+		if err != nil {
+			b.AddError("pods", fmt.Errorf("list pods: %w", err))
+		} else {
+			for i := range pods.Items {
+				if err = addPod(ctx, b, client, cfg, &pods.Items[i]); err != nil {
+					return err
+				}
+			}
+		}
+
+		webhooks, err := listWebhooks(ctx, client, cfg.Namespace)
+		if err != nil {
+			b.AddError("webhooks.json", err)
+			return nil
+		}
+
+		return b.Add("webhooks.json", webhooks)
+	}
+}
+
+func addPod(ctx context.Context, b *Bundle, client kclientset.Interface, cfg ClusterConfig, pod *corev1.Pod) error {
+	report := newPodReport(pod)
+	if err := b.Add(path.Join("pods", pod.Name+".json"), report); err != nil {
+		return err
+	}
+
+	for _, container := range report.Containers {
+		name := path.Join("logs", pod.Name, container.Name+".log")
+		logs, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: container.Name,
+			TailLines: &cfg.LogLines,
+		}).DoRaw(ctx)
+		if err != nil {
+			b.AddError(name, fmt.Errorf("get logs: %w", err))
+		} else if err = b.Add(name, logs); err != nil {
+			return err
+		}
+
+		if container.RestartCount == 0 {
+			continue
+		}
+
+		// The logs of the previous container explain why it restarted.
+		name = path.Join("logs", pod.Name, container.Name+".previous.log")
+		logs, err = client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: container.Name,
+			TailLines: &cfg.LogLines,
+			Previous:  true,
+		}).DoRaw(ctx)
+		if err != nil {
+			b.AddError(name, fmt.Errorf("get previous logs: %w", err))
+		} else if err = b.Add(name, logs); err != nil {
+			return err
+		}
+	}
+
+	if cfg.ProcessEndpoint == nil || pod.Status.Phase != corev1.PodRunning {
+		return nil
+	}
+
+	endpoint, ok := cfg.ProcessEndpoint(pod)
+	if !ok {
+		return nil
+	}
+
+	name := path.Join("processes", pod.Name+".json")
+	process, err := client.CoreV1().Pods(pod.Namespace).
+		ProxyGet(endpoint.Scheme, pod.Name, endpoint.Port, endpoint.Path, nil).
+		DoRaw(ctx)
+	if err != nil {
+		b.AddError(name, fmt.Errorf("get process diagnostics: %w", err))
+		return nil
+	}
+
+	return b.Add(name, process)
+}
+
+func newPodReport(pod *corev1.Pod) PodReport {
+	report := PodReport{
+		Name:      pod.Name,
+		Node:      pod.Spec.NodeName,
+		Phase:     pod.Status.Phase,
+		StartTime: pod.Status.StartTime,
+		Labels:    pod.Labels,
+	}
+
+	statuses := make(map[string]corev1.ContainerStatus)
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	for _, container := range pod.Spec.Containers {
+		containerReport := ContainerReport{
+			Name:  container.Name,
+			Image: container.Image,
+			Args:  RedactArgs(append(slices.Clone(container.Command), container.Args...)),
+		}
+
+		if status, ok := statuses[container.Name]; ok {
+			containerReport.ImageID = status.ImageID
+			containerReport.Ready = status.Ready
+			containerReport.RestartCount = status.RestartCount
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				containerReport.LastTerminationReason = terminated.Reason
+			}
+		}
+
+		for _, env := range container.Env {
+			containerReport.Env = append(containerReport.Env, newEnvReport(env))
+		}
+
+		for _, envFrom := range container.EnvFrom {
+			switch {
+			case envFrom.SecretRef != nil:
+				containerReport.EnvFrom = append(containerReport.EnvFrom, "secret "+envFrom.SecretRef.Name)
+			case envFrom.ConfigMapRef != nil:
+				containerReport.EnvFrom = append(containerReport.EnvFrom, "configmap "+envFrom.ConfigMapRef.Name)
+			}
+		}
+
+		report.Containers = append(report.Containers, containerReport)
+	}
+
+	return report
+}
+
+func newEnvReport(env corev1.EnvVar) EnvReport {
+	report := EnvReport{Name: env.Name}
+
+	from := env.ValueFrom
+	switch {
+	case from == nil:
+		report.Value = RedactEnv(env.Name, env.Value)
+	case from.SecretKeyRef != nil:
+		report.From = fmt.Sprintf("secret %s, key %s", from.SecretKeyRef.Name, from.SecretKeyRef.Key)
+	case from.ConfigMapKeyRef != nil:
+		report.From = fmt.Sprintf("configmap %s, key %s", from.ConfigMapKeyRef.Name, from.ConfigMapKeyRef.Key)
+	case from.FieldRef != nil:
+		report.From = "field " + from.FieldRef.FieldPath
+	case from.ResourceFieldRef != nil:
+		report.From = "resource " + from.ResourceFieldRef.Resource
+	}
+
+	return report
+}
+
+// listWebhooks lists the admission webhook configurations with a webhook calling a service of the given namespace.
+func listWebhooks(ctx context.Context, client kclientset.Interface, namespace string) (WebhooksReport, error) {
+	report := WebhooksReport{
+		Mutating:   []admv1.MutatingWebhookConfiguration{},
+		Validating: []admv1.ValidatingWebhookConfiguration{},
+	}
+
+	mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return WebhooksReport{}, fmt.Errorf("list mutating webhook configurations: %w", err)
+	}
+
+	for _, cfg := range mutating.Items {
+		var calling bool
+		for i, webhook := range cfg.Webhooks {
+			calling = calling || callsNamespace(webhook.ClientConfig, namespace)
+			cfg.Webhooks[i].ClientConfig.CABundle = nil
+		}
+		if calling {
+			cfg.ManagedFields = nil
+			report.Mutating = append(report.Mutating, cfg)
+		}
+	}
+
+	validating, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return WebhooksReport{}, fmt.Errorf("list validating webhook configurations: %w", err)
+	}
+
+	for _, cfg := range validating.Items {
+		var calling bool
+		for i, webhook := range cfg.Webhooks {
+			calling = calling || callsNamespace(webhook.ClientConfig, namespace)
+			cfg.Webhooks[i].ClientConfig.CABundle = nil
+		}
+		if calling {
+			cfg.ManagedFields = nil
+			report.Validating = append(report.Validating, cfg)
+		}
+	}
+
+	return report, nil
+}
+
+func callsNamespace(clientConfig admv1.WebhookClientConfig, namespace string) bool {
+	return clientConfig.Service != nil && clientConfig.Service.Namespace == namespace
+}
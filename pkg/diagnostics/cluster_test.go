@@ -0,0 +1,214 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestClusterSource(t *testing.T) {
+	controller := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hub-agent-controller",
+			Namespace: "hub-agent",
+			Labels:    map[string]string{"app": "hub-agent-controller"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node",
+			Containers: []corev1.Container{
+				{
+					Name:  "hub-agent-controller",
+					Image: "ghcr.io/traefik/hub-agent-kubernetes:v1.0.0",
+					Args:  []string{"controller", "--token=secret", "--log-level=debug"},
+					Env: []corev1.EnvVar{
+						{Name: "LOG_FORMAT", Value: "json"},
+						{Name: "SECRET_BACKEND_VAULT_TOKEN", Value: "secret"},
+						{
+							Name: "TOKEN",
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "hub-agent-token"},
+									Key:                  "token",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "hub-agent-controller",
+					Ready:        true,
+					RestartCount: 1,
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"},
+					},
+				},
+			},
+		},
+	}
+	other := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "whoami",
+			Namespace: "default",
+		},
+	}
+	webhookPath := "/ingress"
+	agentWebhook := &admv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-acp"},
+		Webhooks: []admv1.MutatingWebhook{
+			{
+				Name: "hub-agent.traefik.ingress",
+				ClientConfig: admv1.WebhookClientConfig{
+					Service:  &admv1.ServiceReference{Namespace: "hub-agent", Name: "hub-agent-controller", Path: &webhookPath},
+					CABundle: []byte("ca"),
+				},
+			},
+		},
+	}
+	otherWebhook := &admv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+		Webhooks: []admv1.ValidatingWebhook{
+			{
+				Name: "other.example.com",
+				ClientConfig: admv1.WebhookClientConfig{
+					Service: &admv1.ServiceReference{Namespace: "default", Name: "other"},
+				},
+			},
+		},
+	}
+
+	client := kubefake.NewSimpleClientset(controller, other, agentWebhook, otherWebhook)
+	client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.26.1"}
+
+	var proxied *ktesting.ProxyGetActionImpl
+	client.PrependProxyReactor("pods", func(action ktesting.Action) (bool, rest.ResponseWrapper, error) {
+		a := action.(ktesting.ProxyGetActionImpl)
+		proxied = &a
+
+		return true, errorResponse{err: errors.New("unreachable")}, nil
+	})
+
+	var buf bytes.Buffer
+	err := Write(context.Background(), &buf, ClusterSource(client, ClusterConfig{
+		Namespace: "hub-agent",
+		LogLines:  100,
+		ProcessEndpoint: func(pod *corev1.Pod) (ProcessEndpoint, bool) {
+			return ProcessEndpoint{Scheme: "https", Port: "443", Path: "/diagnostics"}, true
+		},
+	}))
+	require.NoError(t, err)
+
+	files := readBundle(t, buf.Bytes())
+
+	assert.ElementsMatch(t, []string{
+		"kubernetes.json",
+		"pods/hub-agent-controller.json",
+		"logs/hub-agent-controller/hub-agent-controller.log",
+		"logs/hub-agent-controller/hub-agent-controller.previous.log",
+		"webhooks.json",
+		"errors.json",
+	}, keys(files))
+
+	assert.Contains(t, files["kubernetes.json"], `"gitVersion": "v1.26.1"`)
+	assert.Equal(t, "fake logs", files["logs/hub-agent-controller/hub-agent-controller.log"])
+
+	var pod PodReport
+	require.NoError(t, json.Unmarshal([]byte(files["pods/hub-agent-controller.json"]), &pod))
+	assert.Equal(t, PodReport{
+		Name:   "hub-agent-controller",
+		Node:   "node",
+		Phase:  corev1.PodRunning,
+		Labels: map[string]string{"app": "hub-agent-controller"},
+		Containers: []ContainerReport{
+			{
+				Name:                  "hub-agent-controller",
+				Image:                 "ghcr.io/traefik/hub-agent-kubernetes:v1.0.0",
+				Ready:                 true,
+				RestartCount:          1,
+				LastTerminationReason: "OOMKilled",
+				Args:                  []string{"controller", "--token=" + redacted, "--log-level=debug"},
+				Env: []EnvReport{
+					{Name: "LOG_FORMAT", Value: "json"},
+					{Name: "SECRET_BACKEND_VAULT_TOKEN", Value: redacted},
+					{Name: "TOKEN", From: "secret hub-agent-token, key token"},
+				},
+			},
+		},
+	}, pod)
+
+	var webhooks WebhooksReport
+	require.NoError(t, json.Unmarshal([]byte(files["webhooks.json"]), &webhooks))
+	require.Len(t, webhooks.Mutating, 1)
+	assert.Equal(t, "hub-acp", webhooks.Mutating[0].Name)
+	assert.Nil(t, webhooks.Mutating[0].Webhooks[0].ClientConfig.CABundle)
+	assert.Empty(t, webhooks.Validating)
+
+	require.NotNil(t, proxied)
+	assert.Equal(t, "hub-agent-controller", proxied.GetName())
+	assert.Equal(t, "https", proxied.GetScheme())
+	assert.Equal(t, "443", proxied.GetPort())
+	assert.Equal(t, "/diagnostics", proxied.GetPath())
+	assert.Contains(t, files["errors.json"], `"processes/hub-agent-controller.json": "get process diagnostics: unreachable"`)
+
+	// Secrets are never part of a bundle.
+	for name, content := range files {
+		assert.NotContains(t, content, "secret\"", name)
+	}
+}
+
+func keys(files map[string]string) []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// errorResponse is the response of a proxied request which failed.
+type errorResponse struct {
+	err error
+}
+
+func (r errorResponse) DoRaw(context.Context) ([]byte, error) {
+	return nil, r.err
+}
+
+func (r errorResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return nil, r.err
+}
@@ -0,0 +1,197 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/health"
+	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// InformerFor returns the informer of the given type started by an informer factory.
+type InformerFor func(obj runtime.Object) cache.SharedIndexInformer
+
+type informerFactory struct {
+	factory     health.CacheSyncer
+	informerFor InformerFor
+}
+
+// Process gathers the diagnostics of the running agent command.
+type Process struct {
+	command   string
+	flags     map[string]string
+	startedAt time.Time
+
+	informersMu sync.Mutex
+	informers   []informerFactory
+}
+
+// NewProcess creates a Process for the given command, whose flags are redacted.
+func NewProcess(command string, flags map[string]string) *Process {
+	return &Process{
+		command:   command,
+		flags:     RedactFlags(flags),
+		startedAt: time.Now(),
+	}
+}
+
+// AddInformers adds the informers started by the given factory to the diagnostics. The informerFor function must
+// return the informers of the factory, as its InformerFor method does.
+func (p *Process) AddInformers(factory health.CacheSyncer, informerFor InformerFor) {
+	p.informersMu.Lock()
+	defer p.informersMu.Unlock()
+
+	p.informers = append(p.informers, informerFactory{factory: factory, informerFor: informerFor})
+}
+
+// ProcessReport is the diagnostics of an agent command.
+type ProcessReport struct {
+	Command    string                     `json:"command"`
+	Version    VersionReport              `json:"version"`
+	StartedAt  time.Time                  `json:"startedAt"`
+	Flags      map[string]string          `json:"flags"`
+	LogLevel   string                     `json:"logLevel"`
+	Overrides  []logger.LevelOverride     `json:"logLevelOverrides,omitempty"`
+	Informers  []InformerReport           `json:"informers,omitempty"`
+	LastErrors map[string]json.RawMessage `json:"lastErrors,omitempty"`
+}
+
+// VersionReport is the version of an agent command.
+type VersionReport struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
+
+// InformerReport is the state of the cache of an informer.
+type InformerReport struct {
+	Type            string `json:"type"`
+	Synced          bool   `json:"synced"`
+	Items           int    `json:"items"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// Report returns the diagnostics of the command.
+func (p *Process) Report() ProcessReport {
+	report := ProcessReport{
+		Command: p.command,
+		Version: VersionReport{
+			Version:   version.Version(),
+			Commit:    version.Commit(),
+			BuildDate: version.BuildDate(),
+		},
+		StartedAt:  p.startedAt,
+		Flags:      p.flags,
+		Informers:  p.informerReports(),
+		LastErrors: make(map[string]json.RawMessage),
+	}
+
+	report.LogLevel, report.Overrides = logger.Levels()
+
+	// The last errors are kept under the component that logged them, errors logged without component under "agent".
+	for component, event := range logger.LastErrors() {
+		if component == "" {
+			component = "agent"
+		}
+		report.LastErrors[component] = bytes.TrimSpace(event)
+	}
+
+	return report
+}
+
+func (p *Process) informerReports() []InformerReport {
+	p.informersMu.Lock()
+	defer p.informersMu.Unlock()
+
+	// A closed channel makes the factories report the current state of the caches instead of waiting for them.
+	done := make(chan struct{})
+	close(done)
+
+	var reports []InformerReport
+	for _, f := range p.informers {
+		for typ, synced := range f.factory.WaitForCacheSync(done) {
+			report := InformerReport{
+				Type:   typeName(typ),
+				Synced: synced,
+			}
+
+			// Factories key their informers by the pointer type of the objects they watch.
+			if typ.Kind() == reflect.Pointer {
+				if obj, ok := reflect.New(typ.Elem()).Interface().(runtime.Object); ok {
+					informer := f.informerFor(obj)
+					report.Items = len(informer.GetStore().ListKeys())
+					report.ResourceVersion = informer.LastSyncResourceVersion()
+				}
+			}
+
+			reports = append(reports, report)
+		}
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Type < reports[j].Type })
+
+	return reports
+}
+
+func typeName(typ reflect.Type) string {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return fmt.Sprintf("%s.%s", typ.PkgPath(), typ.Name())
+}
+
+// Source returns the source adding the diagnostics and the recent logs of the command to a bundle.
+func (p *Process) Source() Source {
+	return func(_ context.Context, b *Bundle) error {
+		if err := b.Add("process.json", p.Report()); err != nil {
+			return err
+		}
+
+		return b.Add("logs/recent.log", bytes.Join(logger.RecentLogs(), nil))
+	}
+}
+
+// Handler serves the diagnostics of the command, as JSON.
+func (p *Process) Handler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			rw.Header().Set("Allow", http.MethodGet)
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(rw).Encode(p.Report()); err != nil {
+			log.Error().Err(err).Msg("Unable to write diagnostics")
+		}
+	})
+}
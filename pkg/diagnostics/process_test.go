@@ -0,0 +1,74 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestProcess_Handler(t *testing.T) {
+	client := kubefake.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "whoami", Namespace: "default"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}},
+	)
+
+	factory := kinformers.NewSharedInformerFactory(client, 0)
+	factory.Core().V1().Services().Informer()
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	process := NewProcess("controller", map[string]string{
+		"log-level": "debug",
+		"token":     "secret",
+	})
+	process.AddInformers(factory, func(obj runtime.Object) cache.SharedIndexInformer {
+		return factory.InformerFor(obj, nil)
+	})
+
+	rec := httptest.NewRecorder()
+	process.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/diagnostics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var report ProcessReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+
+	assert.Equal(t, "controller", report.Command)
+	assert.Equal(t, map[string]string{"log-level": "debug", "token": redacted}, report.Flags)
+	assert.Equal(t, []InformerReport{{Type: "k8s.io/api/core/v1.Service", Synced: true, Items: 2}}, report.Informers)
+
+	rec = httptest.NewRecorder()
+	process.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/diagnostics", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
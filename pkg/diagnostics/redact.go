@@ -0,0 +1,116 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package diagnostics
+
+import (
+	"net/url"
+	"strings"
+)
+
+const redacted = "<redacted>"
+
+// sensitiveSuffixes are the suffixes of the names of the flags holding secret data, such as tokens, or data which may
+// contain some, such as HTTP headers or Slack webhook URLs.
+var sensitiveSuffixes = []string{
+	"token",
+	"secret",
+	"secret-access-key",
+	"password",
+	"credentials",
+	"private-key",
+	"routing-key",
+	"headers",
+	"webhook-url",
+	"webhook-urls",
+}
+
+// RedactFlags returns the given flag values, with the secret ones redacted.
+func RedactFlags(flags map[string]string) map[string]string {
+	redactedFlags := make(map[string]string, len(flags))
+	for name, value := range flags {
+		redactedFlags[name] = RedactFlag(name, value)
+	}
+
+	return redactedFlags
+}
+
+// RedactFlag returns the value of the given flag, redacted if the flag holds secret data. The passwords of the URLs
+// of any other flag are redacted, e.g. the credentials of a proxy.
+func RedactFlag(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	if sensitiveFlag(name) {
+		return redacted
+	}
+
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		u, err := url.Parse(v)
+		if err != nil || u.User == nil {
+			continue
+		}
+
+		values[i] = u.Redacted()
+	}
+
+	return strings.Join(values, ",")
+}
+
+// RedactEnv returns the value of the given environment variable, redacted if the flag it sets holds secret data.
+func RedactEnv(name, value string) string {
+	return RedactFlag(strings.ReplaceAll(strings.ToLower(name), "_", "-"), value)
+}
+
+// RedactArgs returns the given command line arguments, with the values of the flags holding secret data redacted.
+func RedactArgs(args []string) []string {
+	redactedArgs := make([]string, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		redactedArgs[i] = arg
+
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if hasValue {
+			redactedArgs[i] = arg[:len(arg)-len(value)] + RedactFlag(name, value)
+			continue
+		}
+
+		// The value of the flag may be the next argument.
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			redactedArgs[i] = RedactFlag(name, args[i])
+		}
+	}
+
+	return redactedArgs
+}
+
+func sensitiveFlag(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -19,9 +19,12 @@ package admission
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -140,6 +143,27 @@ func (h Handler) review(ctx context.Context, req *admv1.AdmissionRequest) ([]byt
 func (h Handler) reviewCreateOperation(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress) ([]byte, error) {
 	log.Ctx(ctx).Info().Msg("Creating EdgeIngress resource")
 
+	if err := validateProtocol(edgeIng.Spec); err != nil {
+		return nil, err
+	}
+
+	if err := validateHealthCheck(edgeIng.Spec.HealthCheck); err != nil {
+		return nil, err
+	}
+
+	if err := validateTLS(edgeIng.Spec.TLS); err != nil {
+		return nil, err
+	}
+
+	if err := validateACPs(edgeIng.Spec); err != nil {
+		return nil, err
+	}
+
+	services, err := weightedServices(edgeIng.Spec.Services)
+	if err != nil {
+		return nil, err
+	}
+
 	createReq := &platform.CreateEdgeIngressReq{
 		Name:      edgeIng.Name,
 		Namespace: edgeIng.Namespace,
@@ -147,42 +171,83 @@ func (h Handler) reviewCreateOperation(ctx context.Context, edgeIng *hubv1alpha1
 			Name: edgeIng.Spec.Service.Name,
 			Port: edgeIng.Spec.Service.Port,
 		},
+		Services:      services,
 		CustomDomains: edgeIng.Spec.CustomDomains,
+		Protocol:      string(edgeIng.Spec.Protocol),
+		EntryPoint:    edgeIng.Spec.EntryPoint,
+		Sticky:        edgeIng.Spec.Sticky,
+		HealthCheck:   edgeIng.Spec.HealthCheck,
+		TLS:           edgeIng.Spec.TLS,
+		Certificate:   edgeIng.Spec.Certificate,
 	}
 	if edgeIng.Spec.ACP != nil {
 		createReq.ACP = &platform.ACP{Name: edgeIng.Spec.ACP.Name}
 	}
+	for _, a := range edgeIng.Spec.ACPs {
+		createReq.ACPs = append(createReq.ACPs, platform.ACP(a))
+	}
 
 	createdEdgeIng, err := h.backend.CreateEdgeIngress(ctx, createReq)
 	if err != nil {
 		return nil, fmt.Errorf("create edge ingress: %w", err)
 	}
 
-	return h.buildPatches(createdEdgeIng)
+	return h.buildPatches(createdEdgeIng, nil)
 }
 
 func (h Handler) reviewUpdateOperation(ctx context.Context, oldEdgeIng, newEdgeIng *hubv1alpha1.EdgeIngress) ([]byte, error) {
 	log.Ctx(ctx).Info().Msg("Updating EdgeIngress resource")
 
+	if err := validateProtocol(newEdgeIng.Spec); err != nil {
+		return nil, err
+	}
+
+	if err := validateHealthCheck(newEdgeIng.Spec.HealthCheck); err != nil {
+		return nil, err
+	}
+
+	if err := validateTLS(newEdgeIng.Spec.TLS); err != nil {
+		return nil, err
+	}
+
+	if err := validateACPs(newEdgeIng.Spec); err != nil {
+		return nil, err
+	}
+
+	services, err := weightedServices(newEdgeIng.Spec.Services)
+	if err != nil {
+		return nil, err
+	}
+
 	updateReq := &platform.UpdateEdgeIngressReq{
 		Service: platform.Service{
 			Name: newEdgeIng.Spec.Service.Name,
 			Port: newEdgeIng.Spec.Service.Port,
 		},
+		Services:      services,
 		CustomDomains: newEdgeIng.Spec.CustomDomains,
+		Protocol:      string(newEdgeIng.Spec.Protocol),
+		EntryPoint:    newEdgeIng.Spec.EntryPoint,
+		Sticky:        newEdgeIng.Spec.Sticky,
+		HealthCheck:   newEdgeIng.Spec.HealthCheck,
+		TLS:           newEdgeIng.Spec.TLS,
+		Certificate:   newEdgeIng.Spec.Certificate,
 	}
 	if newEdgeIng.Spec.ACP != nil {
 		updateReq.ACP = &platform.ACP{
 			Name: newEdgeIng.Spec.ACP.Name,
 		}
 	}
+	for _, a := range newEdgeIng.Spec.ACPs {
+		updateReq.ACPs = append(updateReq.ACPs, platform.ACP(a))
+	}
 
 	updatedEdgeIng, err := h.backend.UpdateEdgeIngress(ctx, oldEdgeIng.Namespace, oldEdgeIng.Name, oldEdgeIng.Status.Version, updateReq)
 	if err != nil {
 		return nil, fmt.Errorf("update edge ingress: %w", err)
 	}
 
-	return h.buildPatches(updatedEdgeIng)
+	return h.buildPatches(updatedEdgeIng, oldEdgeIng.Status.Conditions)
 }
 
 func (h Handler) reviewDeleteOperation(ctx context.Context, oldEdgeIng *hubv1alpha1.EdgeIngress) ([]byte, error) {
@@ -194,18 +259,172 @@ func (h Handler) reviewDeleteOperation(ctx context.Context, oldEdgeIng *hubv1alp
 	return nil, nil
 }
 
+// validateProtocol makes sure the options of an edge ingress are supported by its protocol.
+func validateProtocol(spec hubv1alpha1.EdgeIngressSpec) error {
+	switch spec.Protocol {
+	case "", hubv1alpha1.EdgeIngressProtocolHTTP, hubv1alpha1.EdgeIngressProtocolTCP:
+		if spec.EntryPoint != "" {
+			return errors.New("entryPoint is only supported for the udp protocol")
+		}
+	case hubv1alpha1.EdgeIngressProtocolUDP:
+		if spec.EntryPoint == "" {
+			return errors.New("entryPoint is required for the udp protocol")
+		}
+		if spec.Certificate != nil {
+			return errors.New("certificate is not supported for the udp protocol")
+		}
+		if spec.TLS != nil {
+			return errors.New("tls is not supported for the udp protocol")
+		}
+	default:
+		return fmt.Errorf("unsupported protocol %q", spec.Protocol)
+	}
+
+	if spec.Protocol == "" || spec.Protocol == hubv1alpha1.EdgeIngressProtocolHTTP {
+		return nil
+	}
+
+	// ACPs are enforced by HTTP middlewares, sticky sessions rely on cookies and health checks on HTTP requests.
+	switch {
+	case spec.ACP != nil, len(spec.ACPs) > 0:
+		return fmt.Errorf("ACPs are not supported for the %s protocol", spec.Protocol)
+	case spec.Sticky != nil:
+		return fmt.Errorf("sticky sessions are not supported for the %s protocol", spec.Protocol)
+	case spec.HealthCheck != nil:
+		return fmt.Errorf("health checks are not supported for the %s protocol", spec.Protocol)
+	}
+
+	return nil
+}
+
+// validateHealthCheck validates the health check of an edge ingress.
+func validateHealthCheck(healthCheck *hubv1alpha1.EdgeIngressHealthCheck) error {
+	if healthCheck == nil {
+		return nil
+	}
+
+	if !strings.HasPrefix(healthCheck.Path, "/") {
+		return errors.New("healthCheck.path must start with a /")
+	}
+	if healthCheck.Port < 0 || healthCheck.Port > 65535 {
+		return fmt.Errorf("healthCheck.port %d is out of range", healthCheck.Port)
+	}
+
+	if healthCheck.Interval != "" {
+		if _, err := time.ParseDuration(healthCheck.Interval); err != nil {
+			return fmt.Errorf("invalid healthCheck.interval: %w", err)
+		}
+	}
+	if healthCheck.Timeout != "" {
+		if _, err := time.ParseDuration(healthCheck.Timeout); err != nil {
+			return fmt.Errorf("invalid healthCheck.timeout: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateACPs validates the chain of ACPs of an edge ingress.
+func validateACPs(spec hubv1alpha1.EdgeIngressSpec) error {
+	if len(spec.ACPs) == 0 {
+		return nil
+	}
+
+	if spec.ACP != nil {
+		return errors.New("acp and acps are mutually exclusive")
+	}
+
+	seen := make(map[string]struct{})
+	for i, a := range spec.ACPs {
+		if a.Name == "" {
+			return fmt.Errorf("acps[%d].name is required", i)
+		}
+
+		if _, ok := seen[a.Name]; ok {
+			return fmt.Errorf("acps[%d]: ACP %q is referenced more than once", i, a.Name)
+		}
+		seen[a.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// validateTLS validates the TLS options of an edge ingress.
+func validateTLS(tlsOpts *hubv1alpha1.EdgeIngressTLS) error {
+	if tlsOpts == nil {
+		return nil
+	}
+
+	supportedCipherSuites := make(map[string]struct{})
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		supportedCipherSuites[suite.Name] = struct{}{}
+	}
+
+	for i, cipherSuite := range tlsOpts.CipherSuites {
+		if _, ok := supportedCipherSuites[cipherSuite]; !ok {
+			return fmt.Errorf("tls.cipherSuites[%d]: unsupported cipher suite %q", i, cipherSuite)
+		}
+	}
+
+	if tlsOpts.ClientAuth == nil {
+		return nil
+	}
+
+	switch tlsOpts.ClientAuth.ClientAuthType {
+	case "VerifyClientCertIfGiven", "RequireAndVerifyClientCert":
+		if len(tlsOpts.ClientAuth.SecretNames) == 0 {
+			return fmt.Errorf("tls.clientAuth.secretNames is required to %s", tlsOpts.ClientAuth.ClientAuthType)
+		}
+	case "NoClientCert", "RequestClientCert", "RequireAnyClientCert":
+	default:
+		return fmt.Errorf("unsupported tls.clientAuth.clientAuthType %q", tlsOpts.ClientAuth.ClientAuthType)
+	}
+
+	return nil
+}
+
+// weightedServices validates the weighted services of an edge ingress and converts them for the platform.
+func weightedServices(services []hubv1alpha1.EdgeIngressWeightedService) ([]platform.WeightedService, error) {
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	var totalWeight int
+	result := make([]platform.WeightedService, 0, len(services))
+	for i, svc := range services {
+		if svc.Name == "" {
+			return nil, fmt.Errorf("services[%d]: name is required", i)
+		}
+		if svc.Weight < 0 {
+			return nil, fmt.Errorf("services[%d]: weight must be positive", i)
+		}
+
+		totalWeight += svc.Weight
+		result = append(result, platform.WeightedService(svc))
+	}
+
+	if totalWeight == 0 {
+		return nil, errors.New("at least one service must have a non-zero weight")
+	}
+
+	return result, nil
+}
+
 type patch struct {
 	Op    string      `json:"op"`
 	Path  string      `json:"path"`
 	Value interface{} `json:"value,omitempty"`
 }
 
-func (h Handler) buildPatches(edgeIng *edgeingress.EdgeIngress) ([]byte, error) {
+func (h Handler) buildPatches(edgeIng *edgeingress.EdgeIngress, conditions []metav1.Condition) ([]byte, error) {
 	res, err := edgeIng.Resource()
 	if err != nil {
 		return nil, fmt.Errorf("build resource: %w", err)
 	}
 
+	// Keep the conditions set by the agent, as they only transition when their status changes.
+	res.Status.Conditions = hubv1alpha1.MergeConditions(conditions, res.Status.Conditions...)
+
 	return json.Marshal([]patch{
 		{Op: "replace", Path: "/status", Value: res.Status},
 	})
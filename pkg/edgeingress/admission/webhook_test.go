@@ -135,6 +135,12 @@ func TestHandler_ServeHTTP_createOperation(t *testing.T) {
 				URLs:       "https://majestic-beaver-123.hub-traefik.io",
 				SpecHash:   "NexiGZBcal8NDre24JKd5LKyxF4=",
 				Connection: hubv1alpha1.EdgeIngressConnectionDown,
+				Conditions: []metav1.Condition{
+					hubv1alpha1.NewCondition(hubv1alpha1.ConditionSynced, metav1.ConditionTrue, hubv1alpha1.ReasonSynced,
+						"Resource is synchronized with the platform", now),
+					hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionFalse, hubv1alpha1.ReasonConnectionDown,
+						"Connection to the edge is down", now),
+				},
 			}},
 		}),
 	}
@@ -210,6 +216,8 @@ func TestHandler_ServeHTTP_createOperationConflict(t *testing.T) {
 
 func TestHandler_ServeHTTP_updateOperation(t *testing.T) {
 	now := metav1.Now()
+	certificateProvisioned := hubv1alpha1.NewCondition(hubv1alpha1.ConditionCertificateProvisioned, metav1.ConditionTrue,
+		hubv1alpha1.ReasonCertificateProvisioned, "Certificates are provisioned", metav1.NewTime(now.Time.Add(-time.Hour)))
 
 	const (
 		edgeIngName      = "edge-ingress"
@@ -261,6 +269,11 @@ func TestHandler_ServeHTTP_updateOperation(t *testing.T) {
 			SyncedAt:   metav1.NewTime(now.Time.Add(-time.Hour)),
 			Domain:     "majestic-beaver-567889.hub.traefik.io",
 			Connection: hubv1alpha1.EdgeIngressConnectionUp,
+			Conditions: []metav1.Condition{
+				certificateProvisioned,
+				hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionTrue, hubv1alpha1.ReasonReady,
+					"Connection to the edge is up", metav1.NewTime(now.Time.Add(-time.Hour))),
+			},
 		},
 	}
 	admissionRev := admv1.AdmissionReview{
@@ -339,6 +352,13 @@ func TestHandler_ServeHTTP_updateOperation(t *testing.T) {
 				SyncedAt:   now,
 				SpecHash:   "ckcEOKdkROXWIZnEXuMt/1PRQSc=",
 				Connection: hubv1alpha1.EdgeIngressConnectionDown,
+				Conditions: []metav1.Condition{
+					certificateProvisioned,
+					hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionFalse, hubv1alpha1.ReasonConnectionDown,
+						"Connection to the edge is down", now),
+					hubv1alpha1.NewCondition(hubv1alpha1.ConditionSynced, metav1.ConditionTrue, hubv1alpha1.ReasonSynced,
+						"Resource is synchronized with the platform", now),
+				},
 			}},
 		}),
 	}
@@ -684,6 +704,250 @@ func TestHandler_ServeHTTP_unsupportedOperation(t *testing.T) {
 	assert.Equal(t, &wantResp, gotAr.Response)
 }
 
+func TestHandler_ServeHTTP_invalidSpec(t *testing.T) {
+	tests := []struct {
+		desc    string
+		spec    hubv1alpha1.EdgeIngressSpec
+		wantMsg string
+	}{
+		{
+			desc: "weighted service without name",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Services: []hubv1alpha1.EdgeIngressWeightedService{
+					{Port: 80, Weight: 1},
+				},
+			},
+			wantMsg: "services[0]: name is required",
+		},
+		{
+			desc: "negative weight",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Services: []hubv1alpha1.EdgeIngressWeightedService{
+					{Name: "whoami", Port: 80, Weight: 1},
+					{Name: "whoami-canary", Port: 80, Weight: -1},
+				},
+			},
+			wantMsg: "services[1]: weight must be positive",
+		},
+		{
+			desc: "all weights are zero",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Services: []hubv1alpha1.EdgeIngressWeightedService{
+					{Name: "whoami", Port: 80},
+					{Name: "whoami-canary", Port: 80},
+				},
+			},
+			wantMsg: "at least one service must have a non-zero weight",
+		},
+		{
+			desc: "unsupported protocol",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:  hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				Protocol: "sctp",
+			},
+			wantMsg: `unsupported protocol "sctp"`,
+		},
+		{
+			desc: "entry point with the tcp protocol",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:    hubv1alpha1.EdgeIngressService{Name: "postgres", Port: 5432},
+				Protocol:   hubv1alpha1.EdgeIngressProtocolTCP,
+				EntryPoint: "postgres",
+			},
+			wantMsg: "entryPoint is only supported for the udp protocol",
+		},
+		{
+			desc: "ACP with the tcp protocol",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:  hubv1alpha1.EdgeIngressService{Name: "postgres", Port: 5432},
+				Protocol: hubv1alpha1.EdgeIngressProtocolTCP,
+				ACP:      &hubv1alpha1.EdgeIngressACP{Name: "acp"},
+			},
+			wantMsg: "ACPs are not supported for the tcp protocol",
+		},
+		{
+			desc: "udp protocol without entry point",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:  hubv1alpha1.EdgeIngressService{Name: "dns", Port: 53},
+				Protocol: hubv1alpha1.EdgeIngressProtocolUDP,
+			},
+			wantMsg: "entryPoint is required for the udp protocol",
+		},
+		{
+			desc: "certificate with the udp protocol",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:     hubv1alpha1.EdgeIngressService{Name: "dns", Port: 53},
+				Protocol:    hubv1alpha1.EdgeIngressProtocolUDP,
+				EntryPoint:  "dns",
+				Certificate: &hubv1alpha1.SecretReference{Name: "cert"},
+			},
+			wantMsg: "certificate is not supported for the udp protocol",
+		},
+		{
+			desc: "sticky sessions with the tcp protocol",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:  hubv1alpha1.EdgeIngressService{Name: "postgres", Port: 5432},
+				Protocol: hubv1alpha1.EdgeIngressProtocolTCP,
+				Sticky:   &hubv1alpha1.EdgeIngressSticky{},
+			},
+			wantMsg: "sticky sessions are not supported for the tcp protocol",
+		},
+		{
+			desc: "health check with the udp protocol",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:     hubv1alpha1.EdgeIngressService{Name: "dns", Port: 53},
+				Protocol:    hubv1alpha1.EdgeIngressProtocolUDP,
+				EntryPoint:  "dns",
+				HealthCheck: &hubv1alpha1.EdgeIngressHealthCheck{Path: "/health"},
+			},
+			wantMsg: "health checks are not supported for the udp protocol",
+		},
+		{
+			desc: "tls with the udp protocol",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:    hubv1alpha1.EdgeIngressService{Name: "dns", Port: 53},
+				Protocol:   hubv1alpha1.EdgeIngressProtocolUDP,
+				EntryPoint: "dns",
+				TLS:        &hubv1alpha1.EdgeIngressTLS{MinVersion: "VersionTLS12"},
+			},
+			wantMsg: "tls is not supported for the udp protocol",
+		},
+		{
+			desc: "tls with an unsupported cipher suite",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service: hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				TLS: &hubv1alpha1.EdgeIngressTLS{
+					CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_NULL"},
+				},
+			},
+			wantMsg: `tls.cipherSuites[1]: unsupported cipher suite "TLS_NULL"`,
+		},
+		{
+			desc: "tls client certificate verification without CA",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service: hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				TLS: &hubv1alpha1.EdgeIngressTLS{
+					ClientAuth: &hubv1alpha1.EdgeIngressClientAuth{ClientAuthType: "RequireAndVerifyClientCert"},
+				},
+			},
+			wantMsg: "tls.clientAuth.secretNames is required to RequireAndVerifyClientCert",
+		},
+		{
+			desc: "tls with an unsupported client auth type",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service: hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				TLS: &hubv1alpha1.EdgeIngressTLS{
+					ClientAuth: &hubv1alpha1.EdgeIngressClientAuth{ClientAuthType: "Always"},
+				},
+			},
+			wantMsg: `unsupported tls.clientAuth.clientAuthType "Always"`,
+		},
+		{
+			desc: "health check with a relative path",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:     hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				HealthCheck: &hubv1alpha1.EdgeIngressHealthCheck{Path: "health"},
+			},
+			wantMsg: "healthCheck.path must start with a /",
+		},
+		{
+			desc: "health check with an out of range port",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:     hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				HealthCheck: &hubv1alpha1.EdgeIngressHealthCheck{Path: "/health", Port: 70000},
+			},
+			wantMsg: "healthCheck.port 70000 is out of range",
+		},
+		{
+			desc: "health check with an invalid interval",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:     hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				HealthCheck: &hubv1alpha1.EdgeIngressHealthCheck{Path: "/health", Interval: "often"},
+			},
+			wantMsg: `invalid healthCheck.interval: time: invalid duration "often"`,
+		},
+		{
+			desc: "acp and acps",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service: hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				ACP:     &hubv1alpha1.EdgeIngressACP{Name: "jwt"},
+				ACPs:    []hubv1alpha1.EdgeIngressACP{{Name: "ip-allowlist"}},
+			},
+			wantMsg: "acp and acps are mutually exclusive",
+		},
+		{
+			desc: "acps referencing an ACP twice",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service: hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+				ACPs:    []hubv1alpha1.EdgeIngressACP{{Name: "ip-allowlist"}, {Name: "jwt"}, {Name: "ip-allowlist"}},
+			},
+			wantMsg: `acps[2]: ACP "ip-allowlist" is referenced more than once`,
+		},
+		{
+			desc: "acps with the tcp protocol",
+			spec: hubv1alpha1.EdgeIngressSpec{
+				Service:  hubv1alpha1.EdgeIngressService{Name: "postgres", Port: 5432},
+				Protocol: hubv1alpha1.EdgeIngressProtocolTCP,
+				ACPs:     []hubv1alpha1.EdgeIngressACP{{Name: "ip-allowlist"}},
+			},
+			wantMsg: "ACPs are not supported for the tcp protocol",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			edgeIng := hubv1alpha1.EdgeIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "whoami", Namespace: "default"},
+				Spec:       test.spec,
+			}
+
+			b := mustMarshal(t, admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					UID: "id",
+					Kind: metav1.GroupVersionKind{
+						Group:   "hub.traefik.io",
+						Version: "v1alpha1",
+						Kind:    "EdgeIngress",
+					},
+					Name:      "whoami",
+					Namespace: "default",
+					Operation: admv1.Create,
+					Object: runtime.RawExtension{
+						Raw: mustMarshal(t, edgeIng),
+					},
+				},
+				Response: &admv1.AdmissionResponse{},
+			})
+
+			h := NewHandler(nil)
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", bytes.NewBuffer(b))
+			require.NoError(t, err)
+
+			h.ServeHTTP(rec, req)
+
+			var gotAr admv1.AdmissionReview
+			err = json.NewDecoder(rec.Body).Decode(&gotAr)
+			require.NoError(t, err)
+
+			wantResp := admv1.AdmissionResponse{
+				UID:     "id",
+				Allowed: false,
+				Result: &metav1.Status{
+					Status:  "Failure",
+					Message: test.wantMsg,
+				},
+			}
+
+			assert.Equal(t, &wantResp, gotAr.Response)
+		})
+	}
+}
+
 func mustMarshal(t *testing.T, obj interface{}) []byte {
 	t.Helper()
 
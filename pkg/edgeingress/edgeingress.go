@@ -42,9 +42,22 @@ type EdgeIngress struct {
 	Domain        string         `json:"domain"`
 	CustomDomains []CustomDomain `json:"customDomains"`
 
-	Version string  `json:"version"`
-	Service Service `json:"service"`
-	ACP     *ACP    `json:"acp,omitempty"`
+	Version  string            `json:"version"`
+	Service  Service           `json:"service"`
+	Services []WeightedService `json:"services,omitempty"`
+	ACP      *ACP              `json:"acp,omitempty"`
+	ACPs     []ACP             `json:"acps,omitempty"`
+
+	Protocol   string `json:"protocol,omitempty"`
+	EntryPoint string `json:"entryPoint,omitempty"`
+	// Port is the port allocated by the platform to expose UDP edge ingresses.
+	Port int `json:"port,omitempty"`
+
+	Sticky      *hubv1alpha1.EdgeIngressSticky      `json:"sticky,omitempty"`
+	HealthCheck *hubv1alpha1.EdgeIngressHealthCheck `json:"healthCheck,omitempty"`
+	TLS         *hubv1alpha1.EdgeIngressTLS         `json:"tls,omitempty"`
+
+	Certificate *hubv1alpha1.SecretReference `json:"certificate,omitempty"`
 
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -62,11 +75,34 @@ type Service struct {
 	Port int    `json:"port"`
 }
 
+// WeightedService is a service receiving a share of the traffic of the edge ingress.
+type WeightedService struct {
+	Name   string `json:"name"`
+	Port   int    `json:"port"`
+	Weight int    `json:"weight"`
+}
+
 // ACP is an ACP used by the edge ingress.
 type ACP struct {
 	Name string `json:"name"`
 }
 
+// url returns the URL for accessing the exposed service on the given domain.
+func (e *EdgeIngress) url(domain string) string {
+	switch hubv1alpha1.EdgeIngressProtocol(e.Protocol) {
+	case hubv1alpha1.EdgeIngressProtocolTCP:
+		return "tls://" + domain + ":443"
+	case hubv1alpha1.EdgeIngressProtocolUDP:
+		// No port is allocated without the platform, in standalone mode.
+		if e.Port == 0 {
+			return "udp://" + domain
+		}
+		return fmt.Sprintf("udp://%s:%d", domain, e.Port)
+	default:
+		return "https://" + domain
+	}
+}
+
 // Resource builds the v1alpha1 EdgeIngress resource.
 func (e *EdgeIngress) Resource() (*hubv1alpha1.EdgeIngress, error) {
 	var customDomains []string
@@ -79,7 +115,17 @@ func (e *EdgeIngress) Resource() (*hubv1alpha1.EdgeIngress, error) {
 			Name: e.Service.Name,
 			Port: e.Service.Port,
 		},
+		Protocol:      hubv1alpha1.EdgeIngressProtocol(e.Protocol),
+		EntryPoint:    e.EntryPoint,
+		Sticky:        e.Sticky,
+		HealthCheck:   e.HealthCheck,
+		TLS:           e.TLS,
 		CustomDomains: customDomains,
+		Certificate:   e.Certificate,
+	}
+
+	for _, svc := range e.Services {
+		spec.Services = append(spec.Services, hubv1alpha1.EdgeIngressWeightedService(svc))
 	}
 
 	if e.ACP != nil {
@@ -87,6 +133,9 @@ func (e *EdgeIngress) Resource() (*hubv1alpha1.EdgeIngress, error) {
 			Name: e.ACP.Name,
 		}
 	}
+	for _, a := range e.ACPs {
+		spec.ACPs = append(spec.ACPs, hubv1alpha1.EdgeIngressACP(a))
+	}
 
 	specHash, err := spec.Hash()
 	if err != nil {
@@ -100,11 +149,13 @@ func (e *EdgeIngress) Resource() (*hubv1alpha1.EdgeIngress, error) {
 			continue
 		}
 
-		urls = append(urls, "https://"+customDomain.Name)
+		urls = append(urls, e.url(customDomain.Name))
 		verifiedCustomDomains = append(verifiedCustomDomains, customDomain.Name)
 	}
 
-	urls = append(urls, "https://"+e.Domain)
+	urls = append(urls, e.url(e.Domain))
+
+	syncedAt := metav1.Now()
 
 	return &hubv1alpha1.EdgeIngress{
 		ObjectMeta: metav1.ObjectMeta{
@@ -114,12 +165,18 @@ func (e *EdgeIngress) Resource() (*hubv1alpha1.EdgeIngress, error) {
 		Spec: spec,
 		Status: hubv1alpha1.EdgeIngressStatus{
 			Version:       e.Version,
-			SyncedAt:      metav1.Now(),
+			SyncedAt:      syncedAt,
 			Domain:        e.Domain,
 			CustomDomains: verifiedCustomDomains,
 			URLs:          strings.Join(urls, ","),
 			Connection:    hubv1alpha1.EdgeIngressConnectionDown,
 			SpecHash:      specHash,
+			Conditions: []metav1.Condition{
+				hubv1alpha1.NewCondition(hubv1alpha1.ConditionSynced, metav1.ConditionTrue, hubv1alpha1.ReasonSynced,
+					"Resource is synchronized with the platform", syncedAt),
+				hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionFalse, hubv1alpha1.ReasonConnectionDown,
+					"Connection to the edge is down", syncedAt),
+			},
 		},
 	}, nil
 }
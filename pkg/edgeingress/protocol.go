@@ -0,0 +1,225 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package edgeingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/traefik"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// upsertTCPRoute routes the traffic of a TCP EdgeIngress. The traffic is served over TLS on the tunnel entry point,
+// alongside HTTP EdgeIngresses, and routed by SNI.
+func (w *Watcher) upsertTCPRoute(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress, customDomains []string) error {
+	if w.traefikClientSet == nil {
+		return errors.New("the tcp protocol requires the Traefik CRDs")
+	}
+
+	if err := w.deleteStaleRoutes(ctx, edgeIng, routeTCP); err != nil {
+		return err
+	}
+
+	route := buildIngressRouteTCP(edgeIng, w.traefikInstance(edgeIng.Namespace), customDomains)
+
+	existingRoute, err := w.traefikClientSet.IngressRouteTCPs(route.Namespace).Get(ctx, route.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get ingress route TCP: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		_, err = w.traefikClientSet.IngressRouteTCPs(route.Namespace).Create(ctx, route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("create ingress route TCP: %w", err)
+		}
+
+		log.Debug().
+			Str("name", route.Name).
+			Str("namespace", route.Namespace).
+			Msg("IngressRouteTCP created")
+
+		return nil
+	}
+
+	existingRoute.Spec = route.Spec
+	existingRoute.ObjectMeta.Annotations = route.ObjectMeta.Annotations
+	existingRoute.ObjectMeta.Labels = route.ObjectMeta.Labels
+	existingRoute.ObjectMeta.OwnerReferences = route.ObjectMeta.OwnerReferences
+
+	_, err = w.traefikClientSet.IngressRouteTCPs(route.Namespace).Update(ctx, existingRoute, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update ingress route TCP: %w", err)
+	}
+
+	log.Debug().
+		Str("name", route.Name).
+		Str("namespace", route.Namespace).
+		Msg("IngressRouteTCP updated")
+
+	return nil
+}
+
+// upsertUDPRoute routes the traffic of a UDP EdgeIngress. UDP traffic can't be routed by domain, it is served on the
+// entry point dedicated to the EdgeIngress.
+func (w *Watcher) upsertUDPRoute(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress) error {
+	if w.traefikClientSet == nil {
+		return errors.New("the udp protocol requires the Traefik CRDs")
+	}
+
+	if err := w.deleteStaleRoutes(ctx, edgeIng, routeUDP); err != nil {
+		return err
+	}
+
+	route := buildIngressRouteUDP(edgeIng, w.traefikInstance(edgeIng.Namespace))
+
+	existingRoute, err := w.traefikClientSet.IngressRouteUDPs(route.Namespace).Get(ctx, route.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get ingress route UDP: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		_, err = w.traefikClientSet.IngressRouteUDPs(route.Namespace).Create(ctx, route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("create ingress route UDP: %w", err)
+		}
+
+		log.Debug().
+			Str("name", route.Name).
+			Str("namespace", route.Namespace).
+			Msg("IngressRouteUDP created")
+
+		return nil
+	}
+
+	existingRoute.Spec = route.Spec
+	existingRoute.ObjectMeta.Annotations = route.ObjectMeta.Annotations
+	existingRoute.ObjectMeta.Labels = route.ObjectMeta.Labels
+	existingRoute.ObjectMeta.OwnerReferences = route.ObjectMeta.OwnerReferences
+
+	_, err = w.traefikClientSet.IngressRouteUDPs(route.Namespace).Update(ctx, existingRoute, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update ingress route UDP: %w", err)
+	}
+
+	log.Debug().
+		Str("name", route.Name).
+		Str("namespace", route.Namespace).
+		Msg("IngressRouteUDP updated")
+
+	return nil
+}
+
+func buildIngressRouteTCP(edgeIng *hubv1alpha1.EdgeIngress, instance traefik.Instance, customDomains []string) *traefikv1alpha1.IngressRouteTCP {
+	var services []traefikv1alpha1.ServiceTCP
+	for _, svc := range backendServices(edgeIng) {
+		services = append(services, traefikv1alpha1.ServiceTCP{
+			Name:      svc.Name,
+			Namespace: edgeIng.Namespace,
+			Port:      intstr.FromInt(svc.Port),
+			Weight:    svc.Weight,
+		})
+	}
+
+	return &traefikv1alpha1.IngressRouteTCP{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "IngressRouteTCP",
+		},
+		ObjectMeta: routeObjectMeta(edgeIng, instance),
+		Spec: traefikv1alpha1.IngressRouteTCPSpec{
+			EntryPoints: []string{instance.TunnelEntryPoint},
+			Routes: []traefikv1alpha1.RouteTCP{
+				{
+					Match:    hostsMatch("HostSNI", edgeIng.Status.Domain, customDomains),
+					Services: services,
+				},
+			},
+			TLS: &traefikv1alpha1.TLSTCP{
+				SecretName: customDomainsSecretName(edgeIng, customDomains),
+				Options:    tlsOptionRef(edgeIng),
+			},
+		},
+	}
+}
+
+func buildIngressRouteUDP(edgeIng *hubv1alpha1.EdgeIngress, instance traefik.Instance) *traefikv1alpha1.IngressRouteUDP {
+	var services []traefikv1alpha1.ServiceUDP
+	for _, svc := range backendServices(edgeIng) {
+		services = append(services, traefikv1alpha1.ServiceUDP{
+			Name:      svc.Name,
+			Namespace: edgeIng.Namespace,
+			Port:      intstr.FromInt(svc.Port),
+			Weight:    svc.Weight,
+		})
+	}
+
+	return &traefikv1alpha1.IngressRouteUDP{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "IngressRouteUDP",
+		},
+		ObjectMeta: routeObjectMeta(edgeIng, instance),
+		Spec: traefikv1alpha1.IngressRouteUDPSpec{
+			EntryPoints: []string{edgeIng.Spec.EntryPoint},
+			Routes:      []traefikv1alpha1.RouteUDP{{Services: services}},
+		},
+	}
+}
+
+func routeObjectMeta(edgeIng *hubv1alpha1.EdgeIngress, instance traefik.Instance) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      edgeIng.Name,
+		Namespace: edgeIng.Namespace,
+		Annotations: map[string]string{
+			"kubernetes.io/ingress.class": instance.IngressClassName,
+		},
+		Labels:          map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"},
+		OwnerReferences: edgeIngressOwnerReferences(edgeIng),
+	}
+}
+
+// backendService is a service receiving the traffic of an EdgeIngress.
+type backendService struct {
+	Name string
+	Port int
+	// Weight is the share of the traffic of the service, nil when it receives all the traffic.
+	Weight *int
+}
+
+// backendServices returns the services receiving the traffic of an EdgeIngress: its weighted services if any, its
+// service otherwise.
+func backendServices(edgeIng *hubv1alpha1.EdgeIngress) []backendService {
+	if len(edgeIng.Spec.Services) == 0 {
+		return []backendService{{Name: edgeIng.Spec.Service.Name, Port: edgeIng.Spec.Service.Port}}
+	}
+
+	services := make([]backendService, 0, len(edgeIng.Spec.Services))
+	for _, svc := range edgeIng.Spec.Services {
+		weight := svc.Weight
+		services = append(services, backendService{Name: svc.Name, Port: svc.Port, Weight: &weight})
+	}
+
+	return services
+}
@@ -0,0 +1,124 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package edgeingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// syncTLSOption creates, updates or deletes the TLSOption holding the TLS options of an EdgeIngress. It must be
+// synced before the routes referencing it.
+func (w *Watcher) syncTLSOption(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress) error {
+	if edgeIng.Spec.TLS == nil {
+		if w.traefikClientSet == nil {
+			return nil
+		}
+
+		err := w.traefikClientSet.TLSOptions(edgeIng.Namespace).Delete(ctx, edgeIng.Name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete tls option: %w", err)
+		}
+
+		return nil
+	}
+
+	if w.traefikClientSet == nil {
+		return errors.New("tls options require the Traefik CRDs")
+	}
+
+	opt := buildTLSOption(edgeIng)
+
+	existingOpt, err := w.traefikClientSet.TLSOptions(opt.Namespace).Get(ctx, opt.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get tls option: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		_, err = w.traefikClientSet.TLSOptions(opt.Namespace).Create(ctx, opt, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("create tls option: %w", err)
+		}
+
+		log.Debug().
+			Str("name", opt.Name).
+			Str("namespace", opt.Namespace).
+			Msg("TLSOption created")
+
+		return nil
+	}
+
+	existingOpt.Spec = opt.Spec
+	existingOpt.ObjectMeta.Labels = opt.ObjectMeta.Labels
+	existingOpt.ObjectMeta.OwnerReferences = opt.ObjectMeta.OwnerReferences
+
+	_, err = w.traefikClientSet.TLSOptions(opt.Namespace).Update(ctx, existingOpt, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update tls option: %w", err)
+	}
+
+	log.Debug().
+		Str("name", opt.Name).
+		Str("namespace", opt.Namespace).
+		Msg("TLSOption updated")
+
+	return nil
+}
+
+func buildTLSOption(edgeIng *hubv1alpha1.EdgeIngress) *traefikv1alpha1.TLSOption {
+	spec := traefikv1alpha1.TLSOptionSpec{
+		MinVersion:   edgeIng.Spec.TLS.MinVersion,
+		CipherSuites: edgeIng.Spec.TLS.CipherSuites,
+	}
+	if clientAuth := edgeIng.Spec.TLS.ClientAuth; clientAuth != nil {
+		spec.ClientAuth = traefikv1alpha1.ClientAuth{
+			SecretNames:    clientAuth.SecretNames,
+			ClientAuthType: clientAuth.ClientAuthType,
+		}
+	}
+
+	return &traefikv1alpha1.TLSOption{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "TLSOption",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            edgeIng.Name,
+			Namespace:       edgeIng.Namespace,
+			Labels:          map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"},
+			OwnerReferences: edgeIngressOwnerReferences(edgeIng),
+		},
+		Spec: spec,
+	}
+}
+
+// tlsOptionRef returns the reference to the TLSOption of an EdgeIngress, nil if it has no TLS options.
+func tlsOptionRef(edgeIng *hubv1alpha1.EdgeIngress) *traefikv1alpha1.TLSOptionRef {
+	if edgeIng.Spec.TLS == nil {
+		return nil
+	}
+
+	return &traefikv1alpha1.TLSOptionRef{Name: edgeIng.Name, Namespace: edgeIng.Namespace}
+}
@@ -20,6 +20,7 @@ package edgeingress
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -30,14 +31,22 @@ import (
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/scheme"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/journal"
+	"github.com/traefik/hub-agent-kubernetes/pkg/reconcile"
+	"github.com/traefik/hub-agent-kubernetes/pkg/secretref"
+	"github.com/traefik/hub-agent-kubernetes/pkg/traefik"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	kclientset "k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 )
 
@@ -45,8 +54,16 @@ const (
 	catchAllName            = "hub-catch-all"
 	secretName              = "hub-certificate"
 	secretCustomDomainsName = "hub-certificate-custom-domains"
+
+	// resourceKind identifies EdgeIngresses in the journal and among the resources referencing secrets.
+	resourceKind = "EdgeIngress"
 )
 
+// AnnotationMiddlewares is the annotation listing, separated by commas, the Traefik middlewares to apply on the routes
+// of an EdgeIngress before its ACPs. Middlewares are referenced with their provider namespace, e.g.
+// `namespace-name@kubernetescrd`.
+const AnnotationMiddlewares = "hub.traefik.io/middlewares"
+
 // PlatformClient for the EdgeIngress service.
 type PlatformClient interface {
 	GetEdgeIngresses(ctx context.Context) ([]EdgeIngress, error)
@@ -59,6 +76,16 @@ type WatcherConfig struct {
 	IngressClassName        string
 	AgentNamespace          string
 	TraefikTunnelEntryPoint string
+	// TraefikInstances are the additional Traefik instances serving EdgeIngresses of specific namespaces.
+	TraefikInstances traefik.Instances
+
+	// Journal records the child resources syncs in progress, so the ones interrupted by a restart are rolled
+	// forward on startup.
+	Journal *journal.Journal
+
+	// Secrets resolves the certificates referenced by EdgeIngresses. Referenced certificates are not supported
+	// without it.
+	Secrets *secretref.Resolver
 
 	EdgeIngressSyncInterval time.Duration
 	CertRetryInterval       time.Duration
@@ -72,24 +99,49 @@ type Watcher struct {
 	wildCardCert   Certificate
 	wildCardCertMu sync.RWMutex
 
+	// secretChanges are the EdgeIngresses whose referenced certificate changed.
+	secretChanges *secretref.Changes
+
 	client           PlatformClient
 	hubClientSet     hubclientset.Interface
 	hubInformer      hubinformers.SharedInformerFactory
 	clientSet        kclientset.Interface
 	traefikClientSet v1alpha1.TraefikV1alpha1Interface
+
+	eventRecorder record.EventRecorder
+
+	// platformEdgeIngresses are the EdgeIngresses last fetched from the platform, by key. They are nil until fetched.
+	platformEdgeIngressesMu sync.RWMutex
+	platformEdgeIngresses   map[string]*EdgeIngress
+
+	queue *reconcile.Queue
 }
 
 // NewWatcher returns a new Watcher.
 func NewWatcher(client PlatformClient, hubClientSet hubclientset.Interface, clientSet kclientset.Interface, traefikClientSet v1alpha1.TraefikV1alpha1Interface, hubInformer hubinformers.SharedInformerFactory, config WatcherConfig) (*Watcher, error) {
-	return &Watcher{
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientSet.CoreV1().Events("")})
+	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{})
+
+	secretChanges := secretref.NewChanges()
+	config.Secrets.OnChange(resourceKind, secretChanges.Add)
+
+	w := &Watcher{
 		config: config,
 
+		secretChanges: secretChanges,
+
 		client:           client,
 		hubClientSet:     hubClientSet,
 		hubInformer:      hubInformer,
 		clientSet:        clientSet,
 		traefikClientSet: traefikClientSet,
-	}, nil
+
+		eventRecorder: eventRecorder,
+	}
+	w.queue = reconcile.NewQueue(resourceKind, w.reconcileEdgeIngress)
+
+	return w, nil
 }
 
 // Run runs Watcher.
@@ -97,12 +149,21 @@ func (w *Watcher) Run(ctx context.Context) {
 	t := time.NewTicker(w.config.EdgeIngressSyncInterval)
 	defer t.Stop()
 
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.queue.Run(ctx)
+	}()
+	defer wg.Wait()
+
 	certSyncInterval := time.After(w.config.CertSyncInterval)
 	ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
 	if err := w.syncCertificates(ctxSync); err != nil {
 		log.Error().Err(err).Msg("Unable to synchronize certificates with platform")
 		certSyncInterval = time.After(w.config.CertRetryInterval)
 	}
+	w.replayJournal(ctxSync)
 	w.syncEdgeIngresses(ctxSync)
 	cancel()
 
@@ -117,6 +178,11 @@ func (w *Watcher) Run(ctx context.Context) {
 			w.syncEdgeIngresses(ctxSync)
 			cancel()
 
+		case <-w.secretChanges.C():
+			ctxSync, cancel = context.WithTimeout(ctx, 20*time.Second)
+			w.syncSecretChanges(ctxSync)
+			cancel()
+
 		case <-certSyncInterval:
 			ctxSync, cancel = context.WithTimeout(ctx, 20*time.Second)
 			if err := w.syncCertificates(ctxSync); err != nil {
@@ -185,54 +251,138 @@ func (w *Watcher) syncEdgeIngresses(ctx context.Context) {
 		return
 	}
 
-	clusterEdgeIngressByID := map[string]*hubv1alpha1.EdgeIngress{}
+	edgeIngs := make(map[string]*EdgeIngress, len(platformEdgeIngresses))
+	for _, p := range platformEdgeIngresses {
+		platformEdgeIng := p
+		edgeIngs[reconcile.Key(platformEdgeIng.Namespace, platformEdgeIng.Name)] = &platformEdgeIng
+	}
+
+	w.platformEdgeIngressesMu.Lock()
+	w.platformEdgeIngresses = edgeIngs
+	w.platformEdgeIngressesMu.Unlock()
+
+	// Each edge ingress is reconciled on its own, cluster edge ingresses missing on the platform are deleted.
+	for key := range edgeIngs {
+		w.queue.Add(key)
+	}
 	for _, edgeIng := range clusterEdgeIngresses {
-		clusterEdgeIngressByID[edgeIng.Name+"@"+edgeIng.Namespace] = edgeIng
+		if key := reconcile.Key(edgeIng.Namespace, edgeIng.Name); edgeIngs[key] == nil {
+			w.queue.Add(key)
+		}
 	}
+}
 
-	for _, p := range platformEdgeIngresses {
-		platformEdgeIng := p
+// reconcileEdgeIngress creates, updates or deletes the cluster EdgeIngress identified by the given key to match the
+// platform, and syncs its child resources.
+func (w *Watcher) reconcileEdgeIngress(ctx context.Context, key string) error {
+	w.platformEdgeIngressesMu.RLock()
+	platformEdgeIng, found := w.platformEdgeIngresses[key]
+	w.platformEdgeIngressesMu.RUnlock()
 
-		clusterEdgeIng, found := clusterEdgeIngressByID[platformEdgeIng.Name+"@"+platformEdgeIng.Namespace]
-		// We delete the edge ingress from the map, since we use this map to delete unused edge ingresses.
-		delete(clusterEdgeIngressByID, platformEdgeIng.Name+"@"+platformEdgeIng.Namespace)
+	obj, clusterFound, err := w.hubInformer.Hub().V1alpha1().EdgeIngresses().Informer().GetIndexer().GetByKey(key)
+	if err != nil {
+		return fmt.Errorf("get EdgeIngress: %w", err)
+	}
 
-		if !found {
-			if err := w.createEdgeIngress(ctx, &platformEdgeIng); err != nil {
-				log.Error().Err(err).
-					Str("name", platformEdgeIng.Name).
-					Str("namespace", platformEdgeIng.Namespace).
-					Msg("Unable to create EdgeIngress")
-			}
-			continue
+	if !found {
+		if !clusterFound {
+			return nil
 		}
 
-		if platformEdgeIng.Version == clusterEdgeIng.Status.Version {
-			if clusterEdgeIng.Status.Connection == hubv1alpha1.EdgeIngressConnectionUp {
-				continue
-			}
-			if err := w.syncChildAndUpdateConnectionStatus(ctx, clusterEdgeIng, platformEdgeIng.CustomDomains); err != nil {
-				log.Error().Err(err).
-					Str("name", platformEdgeIng.Name).
-					Str("namespace", platformEdgeIng.Namespace).
-					Msg("Unable to sync child resources")
-			}
+		return w.deleteEdgeIngress(ctx, obj.(*hubv1alpha1.EdgeIngress))
+	}
 
-			continue
+	if !clusterFound {
+		return w.createEdgeIngress(ctx, platformEdgeIng)
+	}
+
+	clusterEdgeIng := obj.(*hubv1alpha1.EdgeIngress).DeepCopy()
+
+	// EdgeIngresses created by older agents have no conditions, they must be updated to surface their sync status.
+	synced := apimeta.IsStatusConditionTrue(clusterEdgeIng.Status.Conditions, hubv1alpha1.ConditionSynced)
+	if platformEdgeIng.Version == clusterEdgeIng.Status.Version && synced {
+		if clusterEdgeIng.Status.Connection == hubv1alpha1.EdgeIngressConnectionUp {
+			return nil
 		}
 
-		if err := w.updateEdgeIngress(ctx, clusterEdgeIng, &platformEdgeIng); err != nil {
-			log.Error().Err(err).
-				Str("name", clusterEdgeIng.Name).
-				Str("namespace", clusterEdgeIng.Namespace).
-				Msg("Unable to update EdgeIngress")
+		return w.syncChildAndUpdateConnectionStatus(ctx, clusterEdgeIng, platformEdgeIng.CustomDomains)
+	}
+
+	return w.updateEdgeIngress(ctx, clusterEdgeIng, platformEdgeIng)
+}
+
+// replayJournal rolls forward the child resources syncs interrupted by a restart.
+func (w *Watcher) replayJournal(ctx context.Context) {
+	lister := w.hubInformer.Hub().V1alpha1().EdgeIngresses().Lister()
+
+	err := w.config.Journal.Replay(ctx, resourceKind, func(ctx context.Context, entry journal.Entry) error {
+		edgeIng, err := lister.EdgeIngresses(entry.Namespace).Get(entry.Name)
+		if kerror.IsNotFound(err) {
+			// Child resources of deleted EdgeIngresses are garbage collected through their owner references.
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("get EdgeIngress: %w", err)
 		}
+
+		// Only verified custom domains are kept in the status.
+		var customDomains []CustomDomain
+		for _, domain := range edgeIng.Status.CustomDomains {
+			customDomains = append(customDomains, CustomDomain{Name: domain, Verified: true})
+		}
+
+		return w.syncChildAndUpdateConnectionStatus(ctx, edgeIng.DeepCopy(), customDomains)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to replay EdgeIngress journal")
 	}
+}
 
-	w.cleanEdgeIngresses(ctx, clusterEdgeIngressByID)
+// syncSecretChanges syncs the child resources of the EdgeIngresses whose referenced certificate changed.
+func (w *Watcher) syncSecretChanges(ctx context.Context) {
+	lister := w.hubInformer.Hub().V1alpha1().EdgeIngresses().Lister()
+
+	for _, key := range w.secretChanges.Take() {
+		name, namespace, _ := strings.Cut(key, "@")
+
+		edgeIng, err := lister.EdgeIngresses(namespace).Get(name)
+		if kerror.IsNotFound(err) {
+			w.config.Secrets.Untrack(secretref.Owner{Kind: resourceKind, Name: key})
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Str("name", name).Str("namespace", namespace).Msg("Unable to get EdgeIngress")
+			continue
+		}
+
+		// Only verified custom domains are kept in the status.
+		var customDomains []CustomDomain
+		for _, domain := range edgeIng.Status.CustomDomains {
+			customDomains = append(customDomains, CustomDomain{Name: domain, Verified: true})
+		}
+
+		if err = w.syncChildAndUpdateConnectionStatus(ctx, edgeIng.DeepCopy(), customDomains); err != nil {
+			log.Error().Err(err).Str("name", name).Str("namespace", namespace).Msg("Unable to sync child resources")
+		}
+	}
 }
 
-func (w *Watcher) syncChildAndUpdateConnectionStatus(ctx context.Context, edgeIngress *hubv1alpha1.EdgeIngress, customDomains []CustomDomain) error {
+// syncChildAndUpdateConnectionStatus syncs the certificates and ingress of the given EdgeIngress, and reports its
+// connection up. Failures are recorded as events on the EdgeIngress.
+func (w *Watcher) syncChildAndUpdateConnectionStatus(ctx context.Context, edgeIngress *hubv1alpha1.EdgeIngress, customDomains []CustomDomain) (err error) {
+	defer func() {
+		if err != nil {
+			w.eventRecorder.Eventf(edgeIngress, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize child resources: %s", err)
+		}
+	}()
+
+	if err := w.config.Journal.Begin(ctx, resourceKind, edgeIngress.Namespace, edgeIngress.Name); err != nil {
+		log.Warn().Err(err).
+			Str("name", edgeIngress.Name).
+			Str("namespace", edgeIngress.Namespace).
+			Msg("Unable to journal EdgeIngress sync")
+	}
+
 	var customDomainsName []string
 	for _, customDomain := range customDomains {
 		if customDomain.Verified {
@@ -245,10 +395,23 @@ func (w *Watcher) syncChildAndUpdateConnectionStatus(ctx context.Context, edgeIn
 	w.wildCardCertMu.RUnlock()
 
 	if err := w.setupCertificates(ctx, edgeIngress, certificate, customDomainsName); err != nil {
+		w.setEdgeIngressConditions(ctx, edgeIngress,
+			hubv1alpha1.NewCondition(hubv1alpha1.ConditionCertificateProvisioned, metav1.ConditionFalse,
+				hubv1alpha1.ReasonCertificateProvisioningFailed, err.Error(), metav1.Time{}),
+			hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionFalse,
+				hubv1alpha1.ReasonCertificateProvisioningFailed, "Certificates are not provisioned", metav1.Time{}),
+		)
+
 		return fmt.Errorf("unable to setup secrets: %w", err)
 	}
 
 	if err := w.upsertIngress(ctx, edgeIngress, customDomainsName); err != nil {
+		w.setEdgeIngressConditions(ctx, edgeIngress,
+			certificateProvisionedCondition(),
+			hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionFalse,
+				hubv1alpha1.ReasonRoutingFailed, err.Error(), metav1.Time{}),
+		)
+
 		return fmt.Errorf("upsert ingress: %w", err)
 	}
 
@@ -256,6 +419,13 @@ func (w *Watcher) syncChildAndUpdateConnectionStatus(ctx context.Context, edgeIn
 		return fmt.Errorf("update edge ingress status: %w", err)
 	}
 
+	if err := w.config.Journal.End(ctx, resourceKind, edgeIngress.Namespace, edgeIngress.Name); err != nil {
+		log.Warn().Err(err).
+			Str("name", edgeIngress.Name).
+			Str("namespace", edgeIngress.Namespace).
+			Msg("Unable to journal EdgeIngress sync completion")
+	}
+
 	return nil
 }
 
@@ -268,9 +438,9 @@ func (w *Watcher) setupCertificates(ctx context.Context, edgeIngress *hubv1alpha
 		return nil
 	}
 
-	cert, err := w.client.GetCertificateByDomains(ctx, customDomainsName)
+	cert, err := w.customDomainsCertificate(ctx, edgeIngress, customDomainsName)
 	if err != nil {
-		return fmt.Errorf("get certificate by domains %q: %w", strings.Join(customDomainsName, ","), err)
+		return err
 	}
 
 	if err := w.upsertSecret(ctx, cert, secretCustomDomainsName+"-"+edgeIngress.Name, edgeIngress.Namespace, edgeIngress); err != nil {
@@ -280,14 +450,64 @@ func (w *Watcher) setupCertificates(ctx context.Context, edgeIngress *hubv1alpha
 	return nil
 }
 
+// customDomainsCertificate returns the certificate referenced by the EdgeIngress, or the one issued by the platform
+// for the given custom domains.
+func (w *Watcher) customDomainsCertificate(ctx context.Context, edgeIngress *hubv1alpha1.EdgeIngress, customDomainsName []string) (Certificate, error) {
+	owner := secretref.Owner{Kind: resourceKind, Name: edgeIngress.Name + "@" + edgeIngress.Namespace}
+
+	if edgeIngress.Spec.Certificate == nil {
+		w.config.Secrets.Untrack(owner)
+
+		cert, err := w.client.GetCertificateByDomains(ctx, customDomainsName)
+		if err != nil {
+			return Certificate{}, fmt.Errorf("get certificate by domains %q: %w", strings.Join(customDomainsName, ","), err)
+		}
+
+		return cert, nil
+	}
+
+	if w.config.Secrets == nil {
+		return Certificate{}, errors.New("referenced certificates are not supported")
+	}
+
+	ref := secretref.RefOf(*edgeIngress.Spec.Certificate, edgeIngress.Namespace)
+	w.config.Secrets.Track(owner, ref)
+
+	cert, key, err := w.config.Secrets.TLS(ref)
+	if err != nil {
+		return Certificate{}, fmt.Errorf("get referenced certificate: %w", err)
+	}
+
+	return Certificate{Certificate: cert, PrivateKey: key}, nil
+}
+
 func (w *Watcher) upsertIngress(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress, customDomains []string) error {
+	if err := w.syncTLSOption(ctx, edgeIng); err != nil {
+		return err
+	}
+
+	switch {
+	case edgeIng.Spec.Protocol == hubv1alpha1.EdgeIngressProtocolTCP:
+		return w.upsertTCPRoute(ctx, edgeIng, customDomains)
+	case edgeIng.Spec.Protocol == hubv1alpha1.EdgeIngressProtocolUDP:
+		return w.upsertUDPRoute(ctx, edgeIng)
+	case len(edgeIng.Spec.Services) > 0, edgeIng.Spec.Sticky != nil, edgeIng.Spec.HealthCheck != nil:
+		return w.upsertWeightedRoute(ctx, edgeIng, customDomains)
+	}
+
+	if err := w.deleteStaleRoutes(ctx, edgeIng, routeIngress); err != nil {
+		return err
+	}
+
+	instance := w.traefikInstance(edgeIng.Namespace)
+
 	ing, err := w.clientSet.NetworkingV1().Ingresses(edgeIng.Namespace).Get(ctx, edgeIng.Name, metav1.GetOptions{})
 	if err != nil && !kerror.IsNotFound(err) {
 		return fmt.Errorf("get ingress: %w", err)
 	}
 
 	if kerror.IsNotFound(err) {
-		ing = buildIngress(edgeIng, &netv1.Ingress{}, w.config.IngressClassName, w.config.TraefikTunnelEntryPoint, customDomains)
+		ing = buildIngress(edgeIng, &netv1.Ingress{}, instance.IngressClassName, instance.TunnelEntryPoint, customDomains)
 		_, err = w.clientSet.NetworkingV1().Ingresses(edgeIng.Namespace).Create(ctx, ing, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("create ingress: %w", err)
@@ -301,7 +521,7 @@ func (w *Watcher) upsertIngress(ctx context.Context, edgeIng *hubv1alpha1.EdgeIn
 		return nil
 	}
 
-	ing = buildIngress(edgeIng, ing, w.config.IngressClassName, w.config.TraefikTunnelEntryPoint, customDomains)
+	ing = buildIngress(edgeIng, ing, instance.IngressClassName, instance.TunnelEntryPoint, customDomains)
 	_, err = w.clientSet.NetworkingV1().Ingresses(edgeIng.Namespace).Update(ctx, ing, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("update ingress: %w", err)
@@ -315,6 +535,73 @@ func (w *Watcher) upsertIngress(ctx context.Context, edgeIng *hubv1alpha1.EdgeIn
 	return nil
 }
 
+// routeKind is a kind of resources routing the traffic of an EdgeIngress.
+type routeKind int
+
+const (
+	routeIngress routeKind = iota
+	routeWeighted
+	routeTCP
+	routeUDP
+)
+
+// deleteStaleRoutes deletes the resources routing the traffic of an EdgeIngress with another kind of route than the
+// given one, left over when its protocol or services changed.
+func (w *Watcher) deleteStaleRoutes(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress, kind routeKind) error {
+	if kind != routeIngress {
+		err := w.clientSet.NetworkingV1().Ingresses(edgeIng.Namespace).Delete(ctx, edgeIng.Name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete ingress: %w", err)
+		}
+	}
+
+	if w.traefikClientSet == nil {
+		return nil
+	}
+
+	opts := metav1.DeleteOptions{}
+
+	if kind != routeWeighted {
+		err := w.traefikClientSet.IngressRoutes(edgeIng.Namespace).Delete(ctx, edgeIng.Name, opts)
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete ingress route: %w", err)
+		}
+
+		err = w.traefikClientSet.TraefikServices(edgeIng.Namespace).Delete(ctx, edgeIng.Name, opts)
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete traefik service: %w", err)
+		}
+	}
+
+	if kind != routeTCP {
+		err := w.traefikClientSet.IngressRouteTCPs(edgeIng.Namespace).Delete(ctx, edgeIng.Name, opts)
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete ingress route TCP: %w", err)
+		}
+	}
+
+	if kind != routeUDP {
+		err := w.traefikClientSet.IngressRouteUDPs(edgeIng.Namespace).Delete(ctx, edgeIng.Name, opts)
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete ingress route UDP: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// traefikInstance returns the Traefik instance serving EdgeIngresses of the given namespace.
+func (w *Watcher) traefikInstance(namespace string) traefik.Instance {
+	return w.config.TraefikInstances.ForNamespace(namespace, w.defaultTraefikInstance())
+}
+
+func (w *Watcher) defaultTraefikInstance() traefik.Instance {
+	return traefik.Instance{
+		IngressClassName: w.config.IngressClassName,
+		TunnelEntryPoint: w.config.TraefikTunnelEntryPoint,
+	}
+}
+
 func (w *Watcher) createIngressCatchAll(ctx context.Context) error {
 	if w.traefikClientSet == nil {
 		return nil
@@ -354,14 +641,30 @@ func (w *Watcher) createIngressCatchAll(ctx context.Context) error {
 		w.config.AgentNamespace,
 		w.config.AgentNamespace)
 
+	// Each Traefik instance needs its own catch-all ingress to serve EdgeIngresses which are not ready yet.
+	for i, instance := range w.config.TraefikInstances.All(w.defaultTraefikInstance()) {
+		name := catchAllName
+		if i > 0 {
+			name += "-" + instance.IngressClassName
+		}
+
+		if err = w.createInstanceIngressCatchAll(ctx, name, instance, middlewares); err != nil {
+			return fmt.Errorf("create catch-all ingress for ingress class %q: %w", instance.IngressClassName, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *Watcher) createInstanceIngressCatchAll(ctx context.Context, name string, instance traefik.Instance, middlewares string) error {
 	pathType := netv1.PathTypePrefix
 	ing := &netv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      catchAllName,
+			Name:      name,
 			Namespace: w.config.AgentNamespace,
 			Annotations: map[string]string{
 				"traefik.ingress.kubernetes.io/router.tls":         "true",
-				"traefik.ingress.kubernetes.io/router.entrypoints": w.config.TraefikTunnelEntryPoint,
+				"traefik.ingress.kubernetes.io/router.entrypoints": instance.TunnelEntryPoint,
 				"traefik.ingress.kubernetes.io/router.middlewares": middlewares,
 				"traefik.ingress.kubernetes.io/router.priority":    "1",
 			},
@@ -370,7 +673,7 @@ func (w *Watcher) createIngressCatchAll(ctx context.Context) error {
 			},
 		},
 		Spec: netv1.IngressSpec{
-			IngressClassName: pointer.String(w.config.IngressClassName),
+			IngressClassName: pointer.String(instance.IngressClassName),
 			TLS:              []netv1.IngressTLS{{SecretName: secretName}},
 			Rules: []netv1.IngressRule{
 				{
@@ -397,7 +700,7 @@ func (w *Watcher) createIngressCatchAll(ctx context.Context) error {
 		},
 	}
 
-	_, err = w.clientSet.NetworkingV1().Ingresses(w.config.AgentNamespace).Create(ctx, ing, metav1.CreateOptions{})
+	_, err := w.clientSet.NetworkingV1().Ingresses(w.config.AgentNamespace).Create(ctx, ing, metav1.CreateOptions{})
 	if err != nil && !kerror.IsAlreadyExists(err) {
 		return fmt.Errorf("create ingress: %w", err)
 	}
@@ -495,7 +798,13 @@ func appendOwnerReference(references []metav1.OwnerReference, ref metav1.OwnerRe
 }
 
 func (w *Watcher) setEdgeIngressConnectionStatusUP(ctx context.Context, edgeIngress *hubv1alpha1.EdgeIngress) error {
+	edgeIngress = edgeIngress.DeepCopy()
 	edgeIngress.Status.Connection = hubv1alpha1.EdgeIngressConnectionUp
+	hubv1alpha1.SetConditions(&edgeIngress.Status.Conditions,
+		certificateProvisionedCondition(),
+		hubv1alpha1.NewCondition(hubv1alpha1.ConditionReady, metav1.ConditionTrue,
+			hubv1alpha1.ReasonReady, "Connection to the edge is up", metav1.Time{}),
+	)
 
 	ctxUpdate, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -513,23 +822,50 @@ func (w *Watcher) setEdgeIngressConnectionStatusUP(ctx context.Context, edgeIngr
 	return nil
 }
 
+// setEdgeIngressConditions sets the given conditions on the EdgeIngress status.
+// Failing to do so is only logged, as conditions are informative.
+func (w *Watcher) setEdgeIngressConditions(ctx context.Context, edgeIngress *hubv1alpha1.EdgeIngress, conditions ...metav1.Condition) {
+	edgeIngress = edgeIngress.DeepCopy()
+	if !hubv1alpha1.SetConditions(&edgeIngress.Status.Conditions, conditions...) {
+		return
+	}
+
+	ctxUpdate, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := w.hubClientSet.HubV1alpha1().EdgeIngresses(edgeIngress.Namespace).Update(ctxUpdate, edgeIngress, metav1.UpdateOptions{})
+	if err != nil {
+		log.Error().Err(err).
+			Str("name", edgeIngress.Name).
+			Str("namespace", edgeIngress.Namespace).
+			Msg("Unable to update EdgeIngress conditions")
+	}
+}
+
+func certificateProvisionedCondition() metav1.Condition {
+	return hubv1alpha1.NewCondition(hubv1alpha1.ConditionCertificateProvisioned, metav1.ConditionTrue,
+		hubv1alpha1.ReasonCertificateProvisioned, "Certificates are provisioned", metav1.Time{})
+}
+
 func (w *Watcher) createEdgeIngress(ctx context.Context, edgeIng *EdgeIngress) error {
 	obj, err := edgeIng.Resource()
 	if err != nil {
 		return fmt.Errorf("build EdgeIngress resource: %w", err)
 	}
 
-	obj, err = w.hubClientSet.HubV1alpha1().EdgeIngresses(obj.Namespace).Create(ctx, obj, metav1.CreateOptions{})
+	createdEdgeIng, err := w.hubClientSet.HubV1alpha1().EdgeIngresses(obj.Namespace).Create(ctx, obj, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("creating EdgeIngress: %w", err)
 	}
 
 	log.Debug().
-		Str("name", obj.Name).
-		Str("namespace", obj.Namespace).
+		Str("name", createdEdgeIng.Name).
+		Str("namespace", createdEdgeIng.Namespace).
 		Msg("EdgeIngress created")
 
-	return w.syncChildAndUpdateConnectionStatus(ctx, obj, edgeIng.CustomDomains)
+	w.eventRecorder.Event(createdEdgeIng, corev1.EventTypeNormal, hubv1alpha1.EventReasonCreated, "Created from the Hub platform")
+
+	return w.syncChildAndUpdateConnectionStatus(ctx, createdEdgeIng, edgeIng.CustomDomains)
 }
 
 func (w *Watcher) updateEdgeIngress(ctx context.Context, oldEdgeIng *hubv1alpha1.EdgeIngress, newEdgeIng *EdgeIngress) error {
@@ -538,11 +874,15 @@ func (w *Watcher) updateEdgeIngress(ctx context.Context, oldEdgeIng *hubv1alpha1
 		return fmt.Errorf("build EdgeIngress resource: %w", err)
 	}
 
+	conditions := hubv1alpha1.MergeConditions(oldEdgeIng.Status.Conditions, obj.Status.Conditions...)
+
 	oldEdgeIng.Spec = obj.Spec
 	oldEdgeIng.Status = obj.Status
+	oldEdgeIng.Status.Conditions = conditions
 
 	obj, err = w.hubClientSet.HubV1alpha1().EdgeIngresses(obj.Namespace).Update(ctx, oldEdgeIng, metav1.UpdateOptions{})
 	if err != nil {
+		w.eventRecorder.Eventf(oldEdgeIng, corev1.EventTypeWarning, hubv1alpha1.EventReasonSyncFailed, "Unable to synchronize with the Hub platform: %s", err)
 		return fmt.Errorf("updating EdgeIngress: %w", err)
 	}
 
@@ -551,29 +891,44 @@ func (w *Watcher) updateEdgeIngress(ctx context.Context, oldEdgeIng *hubv1alpha1
 		Str("namespace", obj.Namespace).
 		Msg("EdgeIngress updated")
 
+	w.eventRecorder.Event(obj, corev1.EventTypeNormal, hubv1alpha1.EventReasonUpdated, "Updated from the Hub platform")
+
 	return w.syncChildAndUpdateConnectionStatus(ctx, obj, newEdgeIng.CustomDomains)
 }
 
-func (w *Watcher) cleanEdgeIngresses(ctx context.Context, edgeIngs map[string]*hubv1alpha1.EdgeIngress) {
-	for _, edgeIng := range edgeIngs {
-		// Foreground propagation allow us to delete all ingresses owned by the edgeIngress.
-		policy := metav1.DeletePropagationForeground
+func (w *Watcher) deleteEdgeIngress(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress) error {
+	// Foreground propagation allow us to delete all ingresses owned by the edgeIngress.
+	policy := metav1.DeletePropagationForeground
 
-		opts := metav1.DeleteOptions{
-			PropagationPolicy: &policy,
-		}
-		err := w.hubClientSet.HubV1alpha1().EdgeIngresses(edgeIng.Namespace).Delete(ctx, edgeIng.Name, opts)
-		if err != nil {
-			log.Error().Err(err).Msg("Unable to delete EdgeIngress")
+	opts := metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+	}
+	err := w.hubClientSet.HubV1alpha1().EdgeIngresses(edgeIng.Namespace).Delete(ctx, edgeIng.Name, opts)
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("deleting EdgeIngress: %w", err)
+	}
 
-			continue
-		}
+	log.Debug().
+		Str("name", edgeIng.Name).
+		Str("namespace", edgeIng.Namespace).
+		Msg("EdgeIngress deleted")
 
-		log.Debug().
-			Str("name", edgeIng.Name).
-			Str("namespace", edgeIng.Namespace).
-			Msg("EdgeIngress deleted")
+	return nil
+}
+
+// acpChain returns the value of the ACP annotation of the routes of the given edge ingress: the name of its ACP, or the
+// ordered list of its ACPs separated by commas.
+func acpChain(edgeIng *hubv1alpha1.EdgeIngress) string {
+	if edgeIng.Spec.ACP != nil {
+		return edgeIng.Spec.ACP.Name
+	}
+
+	names := make([]string, 0, len(edgeIng.Spec.ACPs))
+	for _, a := range edgeIng.Spec.ACPs {
+		names = append(names, a.Name)
 	}
+
+	return strings.Join(names, ",")
 }
 
 func buildIngress(edgeIng *hubv1alpha1.EdgeIngress, ing *netv1.Ingress, ingressClassName, entryPoint string, customDomains []string) *netv1.Ingress {
@@ -581,8 +936,14 @@ func buildIngress(edgeIng *hubv1alpha1.EdgeIngress, ing *netv1.Ingress, ingressC
 		"traefik.ingress.kubernetes.io/router.tls":         "true",
 		"traefik.ingress.kubernetes.io/router.entrypoints": entryPoint,
 	}
-	if edgeIng.Spec.ACP != nil && edgeIng.Spec.ACP.Name != "" {
-		annotations[reviewer.AnnotationHubAuth] = edgeIng.Spec.ACP.Name
+	if acps := acpChain(edgeIng); acps != "" {
+		annotations[reviewer.AnnotationHubAuth] = acps
+	}
+	if edgeIng.Spec.TLS != nil {
+		annotations["traefik.ingress.kubernetes.io/router.tls.options"] = edgeIng.Namespace + "-" + edgeIng.Name + "@kubernetescrd"
+	}
+	if middlewares := edgeIng.Annotations[AnnotationMiddlewares]; middlewares != "" {
+		annotations["traefik.ingress.kubernetes.io/router.middlewares"] = middlewares
 	}
 
 	ing.ObjectMeta = metav1.ObjectMeta{
@@ -592,15 +953,7 @@ func buildIngress(edgeIng *hubv1alpha1.EdgeIngress, ing *netv1.Ingress, ingressC
 		Labels: map[string]string{
 			"app.kubernetes.io/managed-by": "traefik-hub",
 		},
-		// Set OwnerReference allow us to delete ingresses owned by an edgeIngress.
-		OwnerReferences: []metav1.OwnerReference{
-			{
-				APIVersion: "hub.traefik.io/v1alpha1",
-				Kind:       "EdgeIngress",
-				Name:       edgeIng.Name,
-				UID:        edgeIng.UID,
-			},
-		},
+		OwnerReferences: edgeIngressOwnerReferences(edgeIng),
 	}
 
 	// No secret is needed for TLS because we will use the wildcard certificate configured in the catch-all ingress.
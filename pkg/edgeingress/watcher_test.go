@@ -26,14 +26,20 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/traefik"
 	netv1 "k8s.io/api/networking/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 )
 
@@ -61,6 +67,33 @@ var toUpdate = hubv1alpha1.EdgeIngress{
 	},
 }
 
+// toResync is up-to-date but was created by an agent which didn't report conditions nor the domain.
+var toResync = hubv1alpha1.EdgeIngress{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "toResync",
+		Namespace: "default",
+		UID:       "uid-resync",
+		Labels: map[string]string{
+			"app.kubernetes.io/managed-by": "traefik-hub",
+		},
+	},
+	Spec: hubv1alpha1.EdgeIngressSpec{
+		Service: hubv1alpha1.EdgeIngressService{
+			Name: "service-3",
+			Port: 8083,
+		},
+		ACP: &hubv1alpha1.EdgeIngressACP{
+			Name: "acp-name",
+		},
+	},
+	Status: hubv1alpha1.EdgeIngressStatus{
+		Version:    "version-3",
+		SyncedAt:   metav1.NewTime(time.Now().Add(-time.Hour)),
+		URLs:       "https://happy-fox-123.hub-traefik.io",
+		Connection: hubv1alpha1.EdgeIngressConnectionUp,
+	},
+}
+
 var toDelete = hubv1alpha1.EdgeIngress{
 	ObjectMeta: metav1.ObjectMeta{
 		Name:      "toDelete",
@@ -85,7 +118,7 @@ var toDelete = hubv1alpha1.EdgeIngress{
 }
 
 func Test_WatcherRun(t *testing.T) {
-	clientSetHub := hubfake.NewSimpleClientset([]runtime.Object{&toUpdate, &toDelete}...)
+	clientSetHub := hubfake.NewSimpleClientset([]runtime.Object{&toUpdate, &toResync, &toDelete}...)
 	clientSet := kubefake.NewSimpleClientset()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -113,11 +146,20 @@ func Test_WatcherRun(t *testing.T) {
 			Service:   Service{Name: "service-2", Port: 8082},
 			ACP:       &ACP{Name: "acp-name"},
 		},
+		{
+			Name:      "toResync",
+			Namespace: "default",
+			Domain:    "happy-fox-123.hub-traefik.io",
+			Version:   "version-3",
+			Service:   Service{Name: "service-3", Port: 8083},
+			ACP:       &ACP{Name: "acp-name"},
+		},
 	}
 
 	hashes := map[string]string{
 		"toCreate": "gFO9z9bw0btZf3+lNIiaXfA8z0g=",
 		"toUpdate": "4vJBrpeDJLuGzikpIg0ZJTca9FQ=",
+		"toResync": "sC35OdIUwlNU1hESxgx+3aoddmU=",
 	}
 
 	client := newPlatformClientMock(t)
@@ -149,6 +191,9 @@ func Test_WatcherRun(t *testing.T) {
 
 	require.NoError(t, err)
 
+	recorder := record.NewFakeRecorder(100)
+	w.eventRecorder = recorder
+
 	stop := make(chan struct{})
 	go func() {
 		w.Run(ctx)
@@ -162,6 +207,13 @@ func Test_WatcherRun(t *testing.T) {
 		Get(ctx, "toDelete", metav1.GetOptions{})
 	require.Error(t, err)
 
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	assert.Contains(t, events, "Normal Created Created from the Hub platform")
+	assert.Contains(t, events, "Normal Updated Updated from the Hub platform")
+
 	for _, edgeIngress := range edgeIngresses {
 		edgeIng, errL := clientSetHub.HubV1alpha1().
 			EdgeIngresses(edgeIngress.Namespace).
@@ -181,6 +233,11 @@ func Test_WatcherRun(t *testing.T) {
 		assert.WithinDuration(t, time.Now(), edgeIng.Status.SyncedAt.Time, 100*time.Millisecond)
 		edgeIng.Status.SyncedAt = metav1.Time{}
 
+		for _, conditionType := range []string{hubv1alpha1.ConditionSynced, hubv1alpha1.ConditionCertificateProvisioned, hubv1alpha1.ConditionReady} {
+			assert.True(t, meta.IsStatusConditionTrue(edgeIng.Status.Conditions, conditionType), conditionType)
+		}
+		edgeIng.Status.Conditions = nil
+
 		assert.Equal(t, hubv1alpha1.EdgeIngressStatus{
 			Version:    edgeIngress.Version,
 			SyncedAt:   metav1.Time{},
@@ -337,7 +394,584 @@ func Test_WatcherRun_appends_owner_reference(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Len(t, secret.OwnerReferences, 2)
-	assert.Equal(t, wantOwner, secret.OwnerReferences)
+	// Edge ingresses are synchronized concurrently, in any order.
+	assert.ElementsMatch(t, wantOwner, secret.OwnerReferences)
+}
+
+func Test_WatcherRun_multiple_traefik_instances(t *testing.T) {
+	clientSetHub := hubfake.NewSimpleClientset()
+	clientSet := kubefake.NewSimpleClientset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+
+	edgeIngressInformer := hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), edgeIngressInformer.HasSynced)
+
+	client := newPlatformClientMock(t)
+	client.OnGetWildcardCertificate().TypedReturns(Certificate{
+		Certificate: []byte("cert"),
+		PrivateKey:  []byte("private"),
+	}, nil)
+
+	var callCount int
+	client.OnGetEdgeIngresses().
+		TypedReturns([]EdgeIngress{
+			{
+				Name:      "public",
+				Namespace: "default",
+				Domain:    "majestic-beaver-123.hub-traefik.io",
+				Version:   "version-1",
+				Service:   Service{Name: "service-1", Port: 8080},
+			},
+			{
+				Name:      "private",
+				Namespace: "internal",
+				Domain:    "sad-bat-123.hub-traefik.io",
+				Version:   "version-1",
+				Service:   Service{Name: "service-2", Port: 8082},
+			},
+		}, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+
+	traefikClientSet := traefikcrdfake.NewSimpleClientset()
+
+	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
+		IngressClassName:        "traefik-hub",
+		TraefikTunnelEntryPoint: "traefikhub-tunl",
+		TraefikInstances: traefik.Instances{
+			{IngressClassName: "traefik-internal", Namespaces: []string{"internal"}, TunnelEntryPoint: "internal-tunl"},
+		},
+		AgentNamespace:          "hub-agent",
+		EdgeIngressSyncInterval: time.Millisecond,
+		CertRetryInterval:       time.Millisecond,
+		CertSyncInterval:        time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
+
+	tests := []struct {
+		name           string
+		namespace      string
+		wantClass      string
+		wantEntryPoint string
+	}{
+		{
+			name:           "public",
+			namespace:      "default",
+			wantClass:      "traefik-hub",
+			wantEntryPoint: "traefikhub-tunl",
+		},
+		{
+			name:           "private",
+			namespace:      "internal",
+			wantClass:      "traefik-internal",
+			wantEntryPoint: "internal-tunl",
+		},
+		{
+			name:           catchAllName,
+			namespace:      "hub-agent",
+			wantClass:      "traefik-hub",
+			wantEntryPoint: "traefikhub-tunl",
+		},
+		{
+			name:           catchAllName + "-traefik-internal",
+			namespace:      "hub-agent",
+			wantClass:      "traefik-internal",
+			wantEntryPoint: "internal-tunl",
+		},
+	}
+
+	for _, test := range tests {
+		ing, err := clientSet.NetworkingV1().Ingresses(test.namespace).Get(ctx, test.name, metav1.GetOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, test.wantClass, *ing.Spec.IngressClassName, test.name)
+		assert.Equal(t, test.wantEntryPoint, ing.Annotations["traefik.ingress.kubernetes.io/router.entrypoints"], test.name)
+	}
+}
+
+func Test_WatcherRun_weighted_services(t *testing.T) {
+	clientSetHub := hubfake.NewSimpleClientset()
+	// The Ingress created before the EdgeIngress had weighted services must be removed.
+	clientSet := kubefake.NewSimpleClientset(&netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "canary", Namespace: "default"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+
+	edgeIngressInformer := hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), edgeIngressInformer.HasSynced)
+
+	client := newPlatformClientMock(t)
+	client.OnGetWildcardCertificate().TypedReturns(Certificate{
+		Certificate: []byte("cert"),
+		PrivateKey:  []byte("private"),
+	}, nil)
+	client.OnGetCertificateByDomains([]string{"canary.example.com"}).TypedReturns(Certificate{
+		Certificate: []byte("cert"),
+		PrivateKey:  []byte("private"),
+	}, nil)
+
+	var callCount int
+	client.OnGetEdgeIngresses().
+		TypedReturns([]EdgeIngress{
+			{
+				Name:          "canary",
+				Namespace:     "default",
+				Domain:        "majestic-beaver-123.hub-traefik.io",
+				CustomDomains: []CustomDomain{{Name: "canary.example.com", Verified: true}},
+				Version:       "version-1",
+				Services: []WeightedService{
+					{Name: "whoami", Port: 80, Weight: 90},
+					{Name: "whoami-canary", Port: 8080, Weight: 10},
+				},
+				ACP: &ACP{Name: "acp-name"},
+			},
+		}, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+
+	traefikClientSet := traefikcrdfake.NewSimpleClientset()
+
+	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
+		IngressClassName:        "traefik-hub",
+		TraefikTunnelEntryPoint: "traefikhub-tunl",
+		AgentNamespace:          "hub-agent",
+		EdgeIngressSyncInterval: time.Millisecond,
+		CertRetryInterval:       time.Millisecond,
+		CertSyncInterval:        time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
+
+	ctx = context.Background()
+
+	edgeIng, err := clientSetHub.HubV1alpha1().EdgeIngresses("default").Get(ctx, "canary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	_, err = clientSet.NetworkingV1().Ingresses("default").Get(ctx, "canary", metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+
+	wantOwnerReferences := []metav1.OwnerReference{
+		{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "EdgeIngress",
+			Name:       edgeIng.Name,
+			UID:        edgeIng.UID,
+		},
+	}
+
+	svc, err := traefikClientSet.TraefikV1alpha1().TraefikServices("default").Get(ctx, "canary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, wantOwnerReferences, svc.OwnerReferences)
+	assert.Equal(t, traefikv1alpha1.ServiceSpec{
+		Weighted: &traefikv1alpha1.WeightedRoundRobin{
+			Services: []traefikv1alpha1.Service{
+				{
+					LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+						Name:      "whoami",
+						Namespace: "default",
+						Port:      intstr.FromInt(80),
+						Weight:    pointer.Int(90),
+					},
+				},
+				{
+					LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+						Name:      "whoami-canary",
+						Namespace: "default",
+						Port:      intstr.FromInt(8080),
+						Weight:    pointer.Int(10),
+					},
+				},
+			},
+		},
+	}, svc.Spec)
+
+	route, err := traefikClientSet.TraefikV1alpha1().IngressRoutes("default").Get(ctx, "canary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, wantOwnerReferences, route.OwnerReferences)
+	assert.Equal(t, map[string]string{
+		"kubernetes.io/ingress.class":          "traefik-hub",
+		"hub.traefik.io/access-control-policy": "acp-name",
+	}, route.Annotations)
+	assert.Equal(t, traefikv1alpha1.IngressRouteSpec{
+		EntryPoints: []string{"traefikhub-tunl"},
+		Routes: []traefikv1alpha1.Route{
+			{
+				Match: "Host(`majestic-beaver-123.hub-traefik.io`) || Host(`canary.example.com`)",
+				Kind:  "Rule",
+				Services: []traefikv1alpha1.Service{
+					{
+						LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+							Name: "canary",
+							Kind: "TraefikService",
+						},
+					},
+				},
+			},
+		},
+		TLS: &traefikv1alpha1.TLS{SecretName: secretCustomDomainsName + "-canary"},
+	}, route.Spec)
+}
+
+func Test_WatcherRun_sticky_sessions_and_health_check(t *testing.T) {
+	clientSetHub := hubfake.NewSimpleClientset()
+	clientSet := kubefake.NewSimpleClientset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+
+	edgeIngressInformer := hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), edgeIngressInformer.HasSynced)
+
+	client := newPlatformClientMock(t)
+	client.OnGetWildcardCertificate().TypedReturns(Certificate{
+		Certificate: []byte("cert"),
+		PrivateKey:  []byte("private"),
+	}, nil)
+
+	var callCount int
+	client.OnGetEdgeIngresses().
+		TypedReturns([]EdgeIngress{
+			{
+				Name:      "whoami",
+				Namespace: "default",
+				Domain:    "majestic-beaver-123.hub-traefik.io",
+				Version:   "version-1",
+				Service:   Service{Name: "whoami", Port: 80},
+				Sticky: &hubv1alpha1.EdgeIngressSticky{
+					CookieName: "session",
+					Secure:     true,
+					SameSite:   "strict",
+				},
+				HealthCheck: &hubv1alpha1.EdgeIngressHealthCheck{
+					Path:     "/health",
+					Interval: "10s",
+					Headers:  map[string]string{"X-Probe": "hub"},
+				},
+			},
+		}, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+
+	traefikClientSet := traefikcrdfake.NewSimpleClientset()
+
+	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
+		IngressClassName:        "traefik-hub",
+		TraefikTunnelEntryPoint: "traefikhub-tunl",
+		AgentNamespace:          "hub-agent",
+		EdgeIngressSyncInterval: time.Millisecond,
+		CertRetryInterval:       time.Millisecond,
+		CertSyncInterval:        time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
+
+	ctx = context.Background()
+
+	_, err = clientSet.NetworkingV1().Ingresses("default").Get(ctx, "whoami", metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+
+	svc, err := traefikClientSet.TraefikV1alpha1().TraefikServices("default").Get(ctx, "whoami", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, traefikv1alpha1.ServiceSpec{
+		Weighted: &traefikv1alpha1.WeightedRoundRobin{
+			Services: []traefikv1alpha1.Service{
+				{
+					LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+						Name:      "whoami",
+						Namespace: "default",
+						Port:      intstr.FromInt(80),
+						Sticky: &traefikv1alpha1.Sticky{
+							Cookie: &traefikv1alpha1.Cookie{
+								Name:     "session",
+								Secure:   true,
+								SameSite: "strict",
+							},
+						},
+						HealthCheck: &traefikv1alpha1.ServerHealthCheck{
+							Path:     "/health",
+							Interval: "10s",
+							Headers:  map[string]string{"X-Probe": "hub"},
+						},
+					},
+				},
+			},
+		},
+	}, svc.Spec)
+
+	route, err := traefikClientSet.TraefikV1alpha1().IngressRoutes("default").Get(ctx, "whoami", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, route.Spec.Routes, 1)
+	assert.Equal(t, "Host(`majestic-beaver-123.hub-traefik.io`)", route.Spec.Routes[0].Match)
+	assert.Equal(t, []traefikv1alpha1.Service{
+		{
+			LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+				Name: "whoami",
+				Kind: "TraefikService",
+			},
+		},
+	}, route.Spec.Routes[0].Services)
+}
+
+func Test_WatcherRun_tls_options(t *testing.T) {
+	clientSetHub := hubfake.NewSimpleClientset()
+	clientSet := kubefake.NewSimpleClientset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+
+	edgeIngressInformer := hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), edgeIngressInformer.HasSynced)
+
+	client := newPlatformClientMock(t)
+	client.OnGetWildcardCertificate().TypedReturns(Certificate{
+		Certificate: []byte("cert"),
+		PrivateKey:  []byte("private"),
+	}, nil)
+
+	var callCount int
+	client.OnGetEdgeIngresses().
+		TypedReturns([]EdgeIngress{
+			{
+				Name:      "payments",
+				Namespace: "default",
+				Domain:    "majestic-beaver-123.hub-traefik.io",
+				Version:   "version-1",
+				Service:   Service{Name: "payments", Port: 80},
+				TLS: &hubv1alpha1.EdgeIngressTLS{
+					MinVersion:   "VersionTLS12",
+					CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+					ClientAuth: &hubv1alpha1.EdgeIngressClientAuth{
+						SecretNames:    []string{"client-ca"},
+						ClientAuthType: "RequireAndVerifyClientCert",
+					},
+				},
+			},
+			{
+				Name:      "whoami",
+				Namespace: "default",
+				Domain:    "sad-bat-123.hub-traefik.io",
+				Version:   "version-1",
+				Service:   Service{Name: "whoami", Port: 80},
+			},
+		}, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+
+	// The TLSOption of an EdgeIngress which no longer has TLS options must be removed.
+	traefikClientSet := traefikcrdfake.NewSimpleClientset(&traefikv1alpha1.TLSOption{
+		ObjectMeta: metav1.ObjectMeta{Name: "whoami", Namespace: "default"},
+	})
+
+	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
+		IngressClassName:        "traefik-hub",
+		TraefikTunnelEntryPoint: "traefikhub-tunl",
+		AgentNamespace:          "hub-agent",
+		EdgeIngressSyncInterval: time.Millisecond,
+		CertRetryInterval:       time.Millisecond,
+		CertSyncInterval:        time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
+
+	ctx = context.Background()
+
+	opt, err := traefikClientSet.TraefikV1alpha1().TLSOptions("default").Get(ctx, "payments", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, traefikv1alpha1.TLSOptionSpec{
+		MinVersion:   "VersionTLS12",
+		CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		ClientAuth: traefikv1alpha1.ClientAuth{
+			SecretNames:    []string{"client-ca"},
+			ClientAuthType: "RequireAndVerifyClientCert",
+		},
+	}, opt.Spec)
+
+	ing, err := clientSet.NetworkingV1().Ingresses("default").Get(ctx, "payments", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "default-payments@kubernetescrd", ing.Annotations["traefik.ingress.kubernetes.io/router.tls.options"])
+
+	_, err = traefikClientSet.TraefikV1alpha1().TLSOptions("default").Get(ctx, "whoami", metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+
+	ing, err = clientSet.NetworkingV1().Ingresses("default").Get(ctx, "whoami", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, ing.Annotations, "traefik.ingress.kubernetes.io/router.tls.options")
+}
+
+func Test_WatcherRun_tcp_udp_protocols(t *testing.T) {
+	clientSetHub := hubfake.NewSimpleClientset()
+	clientSet := kubefake.NewSimpleClientset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformers.NewSharedInformerFactory(clientSetHub, 0)
+
+	edgeIngressInformer := hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), edgeIngressInformer.HasSynced)
+
+	client := newPlatformClientMock(t)
+	client.OnGetWildcardCertificate().TypedReturns(Certificate{
+		Certificate: []byte("cert"),
+		PrivateKey:  []byte("private"),
+	}, nil)
+
+	var callCount int
+	client.OnGetEdgeIngresses().
+		TypedReturns([]EdgeIngress{
+			{
+				Name:      "postgres",
+				Namespace: "default",
+				Domain:    "majestic-beaver-123.hub-traefik.io",
+				Version:   "version-1",
+				Service:   Service{Name: "postgres", Port: 5432},
+				Protocol:  "tcp",
+			},
+			{
+				Name:       "dns",
+				Namespace:  "default",
+				Domain:     "sad-bat-123.hub-traefik.io",
+				Version:    "version-1",
+				Service:    Service{Name: "coredns", Port: 53},
+				Protocol:   "udp",
+				EntryPoint: "dns",
+				Port:       40053,
+			},
+		}, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+
+	traefikClientSet := traefikcrdfake.NewSimpleClientset()
+
+	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
+		IngressClassName:        "traefik-hub",
+		TraefikTunnelEntryPoint: "traefikhub-tunl",
+		AgentNamespace:          "hub-agent",
+		EdgeIngressSyncInterval: time.Millisecond,
+		CertRetryInterval:       time.Millisecond,
+		CertSyncInterval:        time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
+
+	ctx = context.Background()
+
+	for _, name := range []string{"postgres", "dns"} {
+		_, err = clientSet.NetworkingV1().Ingresses("default").Get(ctx, name, metav1.GetOptions{})
+		assert.True(t, kerror.IsNotFound(err), name)
+	}
+
+	postgres, err := clientSetHub.HubV1alpha1().EdgeIngresses("default").Get(ctx, "postgres", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "tls://majestic-beaver-123.hub-traefik.io:443", postgres.Status.URLs)
+
+	routeTCP, err := traefikClientSet.TraefikV1alpha1().IngressRouteTCPs("default").Get(ctx, "postgres", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"kubernetes.io/ingress.class": "traefik-hub"}, routeTCP.Annotations)
+	assert.Equal(t, traefikv1alpha1.IngressRouteTCPSpec{
+		EntryPoints: []string{"traefikhub-tunl"},
+		Routes: []traefikv1alpha1.RouteTCP{
+			{
+				Match: "HostSNI(`majestic-beaver-123.hub-traefik.io`)",
+				Services: []traefikv1alpha1.ServiceTCP{
+					{Name: "postgres", Namespace: "default", Port: intstr.FromInt(5432)},
+				},
+			},
+		},
+		TLS: &traefikv1alpha1.TLSTCP{},
+	}, routeTCP.Spec)
+
+	dns, err := clientSetHub.HubV1alpha1().EdgeIngresses("default").Get(ctx, "dns", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "udp://sad-bat-123.hub-traefik.io:40053", dns.Status.URLs)
+
+	routeUDP, err := traefikClientSet.TraefikV1alpha1().IngressRouteUDPs("default").Get(ctx, "dns", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, traefikv1alpha1.IngressRouteUDPSpec{
+		EntryPoints: []string{"dns"},
+		Routes: []traefikv1alpha1.RouteUDP{
+			{
+				Services: []traefikv1alpha1.ServiceUDP{
+					{Name: "coredns", Namespace: "default", Port: intstr.FromInt(53)},
+				},
+			},
+		},
+	}, routeUDP.Spec)
 }
 
 func Test_WatcherRun_handle_custom_domains(t *testing.T) {
@@ -436,6 +1070,11 @@ func Test_WatcherRun_handle_custom_domains(t *testing.T) {
 	assert.WithinDuration(t, time.Now(), edgeIng.Status.SyncedAt.Time, 100*time.Millisecond)
 	edgeIng.Status.SyncedAt = metav1.Time{}
 
+	for _, conditionType := range []string{hubv1alpha1.ConditionSynced, hubv1alpha1.ConditionCertificateProvisioned, hubv1alpha1.ConditionReady} {
+		assert.True(t, meta.IsStatusConditionTrue(edgeIng.Status.Conditions, conditionType), conditionType)
+	}
+	edgeIng.Status.Conditions = nil
+
 	assert.Equal(t, hubv1alpha1.EdgeIngressStatus{
 		Version:       wantEdgeIngress.Version,
 		SyncedAt:      metav1.Time{},
@@ -673,10 +1312,73 @@ func Test_WatcherRun_sync_certificates(t *testing.T) {
 		})
 	}
 
-	assert.Equal(t, wantOwner, secret.OwnerReferences)
+	// Edge ingresses are synchronized concurrently, in any order.
+	assert.ElementsMatch(t, wantOwner, secret.OwnerReferences)
 
 	secret, err = clientSet.CoreV1().Secrets("service").Get(ctx, secretCustomDomainsName+"-toCreateAlso", metav1.GetOptions{})
 	require.NoError(t, err)
 	assert.Equal(t, []byte("customRefresh"), secret.Data["tls.crt"])
 	assert.Len(t, secret.OwnerReferences, 1)
 }
+
+func Test_acpChain(t *testing.T) {
+	tests := []struct {
+		desc string
+		spec hubv1alpha1.EdgeIngressSpec
+		want string
+	}{
+		{
+			desc: "no ACP",
+		},
+		{
+			desc: "single ACP",
+			spec: hubv1alpha1.EdgeIngressSpec{ACP: &hubv1alpha1.EdgeIngressACP{Name: "jwt"}},
+			want: "jwt",
+		},
+		{
+			desc: "chain of ACPs",
+			spec: hubv1alpha1.EdgeIngressSpec{ACPs: []hubv1alpha1.EdgeIngressACP{{Name: "ip-allowlist"}, {Name: "jwt"}}},
+			want: "ip-allowlist,jwt",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, acpChain(&hubv1alpha1.EdgeIngress{Spec: test.spec}))
+		})
+	}
+}
+
+func Test_middlewareRefs(t *testing.T) {
+	tests := []struct {
+		desc        string
+		annotations map[string]string
+		want        []traefikv1alpha1.MiddlewareRef
+	}{
+		{
+			desc: "no middlewares annotation",
+		},
+		{
+			desc:        "list of middlewares",
+			annotations: map[string]string{AnnotationMiddlewares: "agent-ns-cors@kubernetescrd, default-retry@kubernetescrd"},
+			want: []traefikv1alpha1.MiddlewareRef{
+				{Name: "agent-ns-cors@kubernetescrd"},
+				{Name: "default-retry@kubernetescrd"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			edgeIng := &hubv1alpha1.EdgeIngress{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+
+			assert.Equal(t, test.want, middlewareRefs(edgeIng))
+		})
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package edgeingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/traefik"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// upsertWeightedRoute routes the traffic of an EdgeIngress splitting it between weighted services, with optional
+// sticky sessions and health checks. The Kubernetes Ingress can't express those, the traffic is routed by an
+// IngressRoute towards a weighted TraefikService instead.
+func (w *Watcher) upsertWeightedRoute(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress, customDomains []string) error {
+	if w.traefikClientSet == nil {
+		return errors.New("weighted services, sticky sessions and health checks require the Traefik CRDs")
+	}
+
+	if err := w.deleteStaleRoutes(ctx, edgeIng, routeWeighted); err != nil {
+		return err
+	}
+
+	if err := w.upsertTraefikService(ctx, buildWeightedTraefikService(edgeIng)); err != nil {
+		return err
+	}
+
+	route := buildWeightedIngressRoute(edgeIng, w.traefikInstance(edgeIng.Namespace), customDomains)
+
+	return w.upsertIngressRoute(ctx, route)
+}
+
+func (w *Watcher) upsertTraefikService(ctx context.Context, svc *traefikv1alpha1.TraefikService) error {
+	existingSvc, err := w.traefikClientSet.TraefikServices(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get traefik service: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		_, err = w.traefikClientSet.TraefikServices(svc.Namespace).Create(ctx, svc, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("create traefik service: %w", err)
+		}
+
+		log.Debug().
+			Str("name", svc.Name).
+			Str("namespace", svc.Namespace).
+			Msg("TraefikService created")
+
+		return nil
+	}
+
+	existingSvc.Spec = svc.Spec
+	existingSvc.ObjectMeta.Labels = svc.ObjectMeta.Labels
+	existingSvc.ObjectMeta.OwnerReferences = svc.ObjectMeta.OwnerReferences
+
+	_, err = w.traefikClientSet.TraefikServices(svc.Namespace).Update(ctx, existingSvc, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update traefik service: %w", err)
+	}
+
+	log.Debug().
+		Str("name", svc.Name).
+		Str("namespace", svc.Namespace).
+		Msg("TraefikService updated")
+
+	return nil
+}
+
+func (w *Watcher) upsertIngressRoute(ctx context.Context, route *traefikv1alpha1.IngressRoute) error {
+	existingRoute, err := w.traefikClientSet.IngressRoutes(route.Namespace).Get(ctx, route.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get ingress route: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		_, err = w.traefikClientSet.IngressRoutes(route.Namespace).Create(ctx, route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("create ingress route: %w", err)
+		}
+
+		log.Debug().
+			Str("name", route.Name).
+			Str("namespace", route.Namespace).
+			Msg("IngressRoute created")
+
+		return nil
+	}
+
+	existingRoute.Spec = route.Spec
+	existingRoute.ObjectMeta.Annotations = route.ObjectMeta.Annotations
+	existingRoute.ObjectMeta.Labels = route.ObjectMeta.Labels
+	existingRoute.ObjectMeta.OwnerReferences = route.ObjectMeta.OwnerReferences
+
+	_, err = w.traefikClientSet.IngressRoutes(route.Namespace).Update(ctx, existingRoute, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update ingress route: %w", err)
+	}
+
+	log.Debug().
+		Str("name", route.Name).
+		Str("namespace", route.Namespace).
+		Msg("IngressRoute updated")
+
+	return nil
+}
+
+func buildWeightedTraefikService(edgeIng *hubv1alpha1.EdgeIngress) *traefikv1alpha1.TraefikService {
+	sticky := buildSticky(edgeIng.Spec.Sticky)
+	healthCheck := buildHealthCheck(edgeIng.Spec.HealthCheck)
+
+	backends := backendServices(edgeIng)
+	services := make([]traefikv1alpha1.Service, 0, len(backends))
+	for _, svc := range backends {
+		services = append(services, traefikv1alpha1.Service{
+			LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+				Name:        svc.Name,
+				Namespace:   edgeIng.Namespace,
+				Port:        intstr.FromInt(svc.Port),
+				Weight:      svc.Weight,
+				Sticky:      sticky,
+				HealthCheck: healthCheck,
+			},
+		})
+	}
+
+	// Sticky sessions must also pin the clients to a service when the traffic is split between several of them.
+	var weightedSticky *traefikv1alpha1.Sticky
+	if len(services) > 1 {
+		weightedSticky = sticky
+	}
+
+	return &traefikv1alpha1.TraefikService{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "TraefikService",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            edgeIng.Name,
+			Namespace:       edgeIng.Namespace,
+			Labels:          map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"},
+			OwnerReferences: edgeIngressOwnerReferences(edgeIng),
+		},
+		Spec: traefikv1alpha1.ServiceSpec{
+			Weighted: &traefikv1alpha1.WeightedRoundRobin{Services: services, Sticky: weightedSticky},
+		},
+	}
+}
+
+func buildWeightedIngressRoute(edgeIng *hubv1alpha1.EdgeIngress, instance traefik.Instance, customDomains []string) *traefikv1alpha1.IngressRoute {
+	annotations := map[string]string{
+		"kubernetes.io/ingress.class": instance.IngressClassName,
+	}
+	if acps := acpChain(edgeIng); acps != "" {
+		annotations[reviewer.AnnotationHubAuth] = acps
+	}
+
+	return &traefikv1alpha1.IngressRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "IngressRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            edgeIng.Name,
+			Namespace:       edgeIng.Namespace,
+			Annotations:     annotations,
+			Labels:          map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"},
+			OwnerReferences: edgeIngressOwnerReferences(edgeIng),
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			EntryPoints: []string{instance.TunnelEntryPoint},
+			Routes: []traefikv1alpha1.Route{
+				{
+					Match:       hostsMatch("Host", edgeIng.Status.Domain, customDomains),
+					Kind:        "Rule",
+					Middlewares: middlewareRefs(edgeIng),
+					Services: []traefikv1alpha1.Service{
+						{
+							LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+								Name: edgeIng.Name,
+								Kind: "TraefikService",
+							},
+						},
+					},
+				},
+			},
+			TLS: &traefikv1alpha1.TLS{
+				SecretName: customDomainsSecretName(edgeIng, customDomains),
+				Options:    tlsOptionRef(edgeIng),
+			},
+		},
+	}
+}
+
+// middlewareRefs returns the references to the middlewares listed in the middlewares annotation of the given edge
+// ingress.
+func middlewareRefs(edgeIng *hubv1alpha1.EdgeIngress) []traefikv1alpha1.MiddlewareRef {
+	middlewares := edgeIng.Annotations[AnnotationMiddlewares]
+	if middlewares == "" {
+		return nil
+	}
+
+	var refs []traefikv1alpha1.MiddlewareRef
+	for _, name := range strings.Split(middlewares, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			refs = append(refs, traefikv1alpha1.MiddlewareRef{Name: name})
+		}
+	}
+
+	return refs
+}
+
+// hostsMatch returns the rule matching the given domains with the given matcher, Host or HostSNI.
+func hostsMatch(matcher, domain string, customDomains []string) string {
+	hosts := make([]string, 0, len(customDomains)+1)
+	for _, d := range append([]string{domain}, customDomains...) {
+		hosts = append(hosts, fmt.Sprintf("%s(`%s`)", matcher, d))
+	}
+
+	return strings.Join(hosts, " || ")
+}
+
+// customDomainsSecretName returns the name of the secret holding the certificate of the custom domains, if any. The
+// wildcard certificate is served by the catch-all ingress, only the custom domains need their own.
+func customDomainsSecretName(edgeIng *hubv1alpha1.EdgeIngress, customDomains []string) string {
+	if len(customDomains) == 0 {
+		return ""
+	}
+
+	return secretCustomDomainsName + "-" + edgeIng.Name
+}
+
+// edgeIngressOwnerReferences returns the owner references allowing to delete the resources owned by an EdgeIngress.
+func edgeIngressOwnerReferences(edgeIng *hubv1alpha1.EdgeIngress) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "EdgeIngress",
+			Name:       edgeIng.Name,
+			UID:        edgeIng.UID,
+		},
+	}
+}
+
+// buildSticky builds the Traefik sticky sessions configuration of an EdgeIngress.
+func buildSticky(sticky *hubv1alpha1.EdgeIngressSticky) *traefikv1alpha1.Sticky {
+	if sticky == nil {
+		return nil
+	}
+
+	return &traefikv1alpha1.Sticky{
+		Cookie: &traefikv1alpha1.Cookie{
+			Name:     sticky.CookieName,
+			Secure:   sticky.Secure,
+			HTTPOnly: sticky.HTTPOnly,
+			SameSite: sticky.SameSite,
+		},
+	}
+}
+
+// buildHealthCheck builds the Traefik health check configuration of an EdgeIngress.
+func buildHealthCheck(healthCheck *hubv1alpha1.EdgeIngressHealthCheck) *traefikv1alpha1.ServerHealthCheck {
+	if healthCheck == nil {
+		return nil
+	}
+
+	return &traefikv1alpha1.ServerHealthCheck{
+		Scheme:   healthCheck.Scheme,
+		Path:     healthCheck.Path,
+		Port:     healthCheck.Port,
+		Interval: healthCheck.Interval,
+		Timeout:  healthCheck.Timeout,
+		Headers:  healthCheck.Headers,
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package health serves the liveness and readiness endpoints of the agent commands, used by the Kubernetes probes.
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Check reports whether a component is ready, returning an error describing why it isn't otherwise.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks of a command.
+type Checker struct {
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewChecker creates a Checker without any check: until one is added, the command is ready as soon as it serves.
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// AddCheck adds a readiness check under the given name, which can be excluded from a probe with the exclude query
+// parameter.
+func (c *Checker) AddCheck(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// LiveHandler answers the liveness probes: a command able to answer them is alive.
+func (c *Checker) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		writeStatus(rw, http.StatusOK, "ok\n")
+	})
+}
+
+// ReadyHandler answers the readiness probes, with 200 when all the checks pass and 503 otherwise, listing the failing
+// ones. The checks named by the exclude query parameter are skipped, and all checks are listed with the verbose one.
+func (c *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+
+		excluded := make(map[string]struct{})
+		for _, name := range query["exclude"] {
+			excluded[name] = struct{}{}
+		}
+		_, verbose := query["verbose"]
+
+		c.mu.RLock()
+		checks := c.checks
+		c.mu.RUnlock()
+
+		var (
+			report strings.Builder
+			failed []string
+		)
+		for _, check := range checks {
+			if _, ok := excluded[check.name]; ok {
+				fmt.Fprintf(&report, "[+]%s excluded\n", check.name)
+				continue
+			}
+
+			if err := check.check(req.Context()); err != nil {
+				failed = append(failed, check.name)
+				fmt.Fprintf(&report, "[-]%s failed: %v\n", check.name, err)
+				continue
+			}
+
+			fmt.Fprintf(&report, "[+]%s ok\n", check.name)
+		}
+
+		if len(failed) > 0 {
+			log.Debug().Strs("checks", failed).Msg("Readiness checks failed")
+
+			writeStatus(rw, http.StatusServiceUnavailable, report.String())
+			return
+		}
+
+		if verbose {
+			writeStatus(rw, http.StatusOK, report.String()+"ok\n")
+			return
+		}
+
+		writeStatus(rw, http.StatusOK, "ok\n")
+	})
+}
+
+func writeStatus(rw http.ResponseWriter, status int, body string) {
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(status)
+
+	_, _ = rw.Write([]byte(body))
+}
+
+// CacheSyncer is an informer factory, reporting whether the caches of its started informers are synced.
+type CacheSyncer interface {
+	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
+}
+
+// InformersSynced checks the caches of the informers started by the given factories are synced.
+func InformersSynced(factories ...CacheSyncer) Check {
+	// A closed channel makes the factories report the current state of the caches instead of waiting for them.
+	done := make(chan struct{})
+	close(done)
+
+	return func(_ context.Context) error {
+		var unsynced []string
+		for _, factory := range factories {
+			for typ, synced := range factory.WaitForCacheSync(done) {
+				if !synced {
+					unsynced = append(unsynced, typ.String())
+				}
+			}
+		}
+
+		if len(unsynced) > 0 {
+			sort.Strings(unsynced)
+			return fmt.Errorf("caches not synced: %s", strings.Join(unsynced, ", "))
+		}
+
+		return nil
+	}
+}
+
+// CertificateFile checks the first certificate of the given PEM file is currently valid. The file is read on each
+// check, so rotated certificates are taken into account.
+func CertificateFile(path string) Check {
+	return func(_ context.Context) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read certificate: %w", err)
+		}
+
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type != "CERTIFICATE" {
+			return errors.New("no PEM certificate found")
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("parse certificate: %w", err)
+		}
+
+		now := time.Now()
+		switch {
+		case now.Before(cert.NotBefore):
+			return fmt.Errorf("certificate not valid before %s", cert.NotBefore.Format(time.RFC3339))
+		case now.After(cert.NotAfter):
+			return fmt.Errorf("certificate expired on %s", cert.NotAfter.Format(time.RFC3339))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_ReadyHandler(t *testing.T) {
+	passing := func(_ context.Context) error { return nil }
+	failing := func(_ context.Context) error { return errors.New("boom") }
+
+	tests := []struct {
+		desc       string
+		checks     map[string]Check
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "no checks",
+			wantStatus: http.StatusOK,
+			wantBody:   "ok\n",
+		},
+		{
+			desc: "all checks pass",
+			checks: map[string]Check{
+				"platform": passing,
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "ok\n",
+		},
+		{
+			desc: "all checks pass, verbose",
+			checks: map[string]Check{
+				"platform": passing,
+			},
+			query:      "?verbose",
+			wantStatus: http.StatusOK,
+			wantBody:   "[+]platform ok\nok\n",
+		},
+		{
+			desc: "a check fails",
+			checks: map[string]Check{
+				"platform": failing,
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "[-]platform failed: boom\n",
+		},
+		{
+			desc: "failing check excluded",
+			checks: map[string]Check{
+				"platform": failing,
+			},
+			query:      "?exclude=platform&verbose",
+			wantStatus: http.StatusOK,
+			wantBody:   "[+]platform excluded\nok\n",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			checker := NewChecker()
+			for name, check := range test.checks {
+				checker.AddCheck(name, check)
+			}
+
+			rec := httptest.NewRecorder()
+			checker.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz"+test.query, nil))
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+			assert.Equal(t, test.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestChecker_LiveHandler(t *testing.T) {
+	checker := NewChecker()
+	checker.AddCheck("platform", func(_ context.Context) error { return errors.New("boom") })
+
+	rec := httptest.NewRecorder()
+	checker.LiveHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+type cacheSyncerMock map[reflect.Type]bool
+
+func (m cacheSyncerMock) WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool {
+	// The check must not wait for the caches.
+	select {
+	case <-stopCh:
+	default:
+		panic("stop channel not closed")
+	}
+
+	return m
+}
+
+func TestInformersSynced(t *testing.T) {
+	synced := cacheSyncerMock{reflect.TypeOf(""): true}
+	unsynced := cacheSyncerMock{reflect.TypeOf(0): false, reflect.TypeOf(true): true}
+
+	assert.NoError(t, InformersSynced(synced)(context.Background()))
+	assert.EqualError(t, InformersSynced(synced, unsynced)(context.Background()), "caches not synced: int")
+}
+
+func TestCertificateFile(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		desc    string
+		content []byte
+		wantErr string
+	}{
+		{
+			desc:    "valid",
+			content: certificatePEM(t, now.Add(-time.Hour), now.Add(time.Hour)),
+		},
+		{
+			desc:    "expired",
+			content: certificatePEM(t, now.Add(-2*time.Hour), now.Add(-time.Hour)),
+			wantErr: "certificate expired on ",
+		},
+		{
+			desc:    "not valid yet",
+			content: certificatePEM(t, now.Add(time.Hour), now.Add(2*time.Hour)),
+			wantErr: "certificate not valid before ",
+		},
+		{
+			desc:    "not PEM",
+			content: []byte("not a certificate"),
+			wantErr: "no PEM certificate found",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "cert.pem")
+			require.NoError(t, os.WriteFile(path, test.content, 0o600))
+
+			err := CertificateFile(path)(context.Background())
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.wantErr)
+		})
+	}
+
+	err := CertificateFile(filepath.Join(t.TempDir(), "missing.pem"))(context.Background())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func certificatePEM(t *testing.T, notBefore, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hub-agent"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
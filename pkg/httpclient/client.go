@@ -52,11 +52,26 @@ func New(cfg Config) (*http.Client, error) {
 // NewWithLogger returns a new HTTP client with optional TLS and retry capabilities.
 // Retry attempts are logged using the given logger.
 func NewWithLogger(cfg Config, l zerolog.Logger) (*http.Client, error) {
+	return newClient(cfg, nil, l)
+}
+
+// NewWithTransport returns a new HTTP client with optional TLS and retry capabilities, sending its requests through the
+// given transport so its connection pool is shared with other clients. If TLS is configured, the client uses a clone
+// of the transport instead: connections and TLS sessions must not be shared with clients trusting other CAs.
+func NewWithTransport(cfg Config, transport *http.Transport) (*http.Client, error) {
+	return newClient(cfg, transport, log.Logger)
+}
+
+func newClient(cfg Config, transport *http.Transport, l zerolog.Logger) (*http.Client, error) {
 	client := retryablehttp.NewClient()
 	client.RetryMax = cfg.MaxRetries.IntOrDefault(3)
 	client.HTTPClient.Timeout = time.Duration(cfg.TimeoutSeconds.IntOrDefault(5)) * time.Second
 	client.Logger = logger.NewRetryableHTTPWrapper(l.With().Str("component", "http_client").Logger())
 
+	if transport != nil {
+		client.HTTPClient.Transport = transport
+	}
+
 	if cfg.TLS == nil {
 		return client.StandardClient(), nil
 	}
@@ -72,13 +87,27 @@ func NewWithLogger(cfg Config, l zerolog.Logger) (*http.Client, error) {
 		}
 	}
 
-	client.HTTPClient.Transport = &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		TLSClientConfig: &tls.Config{
-			RootCAs:            pool,
-			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
-		},
+	tlsConfig := &tls.Config{
+		RootCAs:            pool,
+		InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+	}
+
+	if transport == nil {
+		client.HTTPClient.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+
+		return client.StandardClient(), nil
+	}
+
+	if transport.TLSClientConfig != nil && transport.TLSClientConfig.ClientSessionCache != nil {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
 	}
 
+	tlsTransport := transport.Clone()
+	tlsTransport.TLSClientConfig = tlsConfig
+	client.HTTPClient.Transport = tlsTransport
+
 	return client.StandardClient(), nil
 }
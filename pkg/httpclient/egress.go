@@ -0,0 +1,108 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Egress describes how the agent reaches the Hub platform from restricted networks: through which proxy, and trusting
+// which CAs, e.g. the private CA of a TLS-intercepting egress proxy.
+type Egress struct {
+	// Proxy returns the proxy to send a request through, nil for none.
+	Proxy func(req *http.Request) (*url.URL, error)
+	// TLSConfig is the TLS configuration of the connections, nil for the default one.
+	TLSConfig *tls.Config
+}
+
+// DefaultEgress returns an Egress sending requests through the proxies set with the HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY environment variables, and trusting the system CAs.
+func DefaultEgress() Egress {
+	return Egress{Proxy: http.ProxyFromEnvironment}
+}
+
+// NewEgress creates an Egress sending requests through the given proxy, except for the hosts listed in the NO_PROXY
+// environment variable, and trusting the CAs of the given PEM bundle file in addition to the system ones.
+// The proxies of the environment are used when no proxy is given, and only the system CAs when no bundle is given.
+func NewEgress(proxyURL, caBundleFile string) (Egress, error) {
+	egress := DefaultEgress()
+
+	if proxyURL != "" {
+		if _, err := url.Parse(proxyURL); err != nil {
+			return Egress{}, fmt.Errorf("parse proxy URL: %w", err)
+		}
+
+		proxyFunc := (&httpproxy.Config{
+			HTTPProxy:  proxyURL,
+			HTTPSProxy: proxyURL,
+			NoProxy:    getEnvAny("NO_PROXY", "no_proxy"),
+		}).ProxyFunc()
+
+		egress.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+
+	if caBundleFile != "" {
+		bundle, err := os.ReadFile(caBundleFile)
+		if err != nil {
+			return Egress{}, fmt.Errorf("read CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return Egress{}, errors.New("wrong CA bundle")
+		}
+
+		egress.TLSConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return egress, nil
+}
+
+// Transport returns a new HTTP transport, with the default settings, going through the egress.
+func (e Egress) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = e.Proxy
+	if e.TLSConfig != nil {
+		transport.TLSClientConfig = e.TLSConfig.Clone()
+	}
+
+	return transport
+}
+
+func getEnvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
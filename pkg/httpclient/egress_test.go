@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package httpclient
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEgress_proxyURL(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(req.Host))
+	}))
+	defer proxy.Close()
+
+	t.Setenv("NO_PROXY", "internal.example.com")
+
+	egress, err := NewEgress(proxy.URL, "")
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: egress.Transport()}
+
+	resp, err := client.Get("http://platform.example.com/agent")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "platform.example.com", string(body))
+
+	req, err := http.NewRequest(http.MethodGet, "http://internal.example.com", http.NoBody)
+	require.NoError(t, err)
+
+	proxyURL, err := egress.Proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, proxyURL)
+}
+
+func TestNewEgress_caBundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// Without the bundle, the self-signed certificate of the server isn't trusted.
+	egress, err := NewEgress("", "")
+	require.NoError(t, err)
+
+	_, err = (&http.Client{Transport: egress.Transport()}).Get(srv.URL)
+	require.Error(t, err)
+
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	bundleFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundleFile, bundle, 0o600))
+
+	egress, err = NewEgress("", bundleFile)
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: egress.Transport()}).Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestNewEgress_wrongCABundle(t *testing.T) {
+	bundleFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundleFile, []byte("not a certificate"), 0o600))
+
+	_, err := NewEgress("", bundleFile)
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package httpclient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig tunes the connection pool of an HTTP transport.
+type TransportConfig struct {
+	// MaxIdleConns is the maximum number of idle connections kept across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept for a single host.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is the duration after which an idle connection is closed.
+	IdleConnTimeout time.Duration
+	// TLSSessionCacheSize is the number of TLS sessions cached to resume connections without a full handshake.
+	// Zero disables TLS session resumption.
+	TLSSessionCacheSize int
+}
+
+// DefaultTransportConfig returns the default TransportConfig.
+// Calls are usually made to a handful of hosts (identity providers, introspection endpoints...) by many concurrent
+// requests, so the number of idle connections per host is way higher than the net/http default of 2, which makes most
+// connections being closed and re-established, TLS handshake included, during traffic spikes.
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+		TLSSessionCacheSize: 128,
+	}
+}
+
+// NewTransport returns a new HTTP transport using the given connection pool settings.
+func NewTransport(cfg TransportConfig) *http.Transport {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if cfg.TLSSessionCacheSize > 0 {
+		transport.TLSClientConfig = &tls.Config{
+			ClientSessionCache: tls.NewLRUClientSessionCache(cfg.TLSSessionCacheSize),
+		}
+	}
+
+	return transport
+}
@@ -0,0 +1,210 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	tests := []struct {
+		desc             string
+		cfg              TransportConfig
+		wantSessionCache bool
+	}{
+		{
+			desc:             "default configuration",
+			cfg:              DefaultTransportConfig(),
+			wantSessionCache: true,
+		},
+		{
+			desc: "TLS session cache disabled",
+			cfg: TransportConfig{
+				MaxIdleConns:        10,
+				MaxIdleConnsPerHost: 5,
+				IdleConnTimeout:     time.Second,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			transport := NewTransport(test.cfg)
+
+			assert.Equal(t, test.cfg.MaxIdleConns, transport.MaxIdleConns)
+			assert.Equal(t, test.cfg.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+			assert.Equal(t, test.cfg.IdleConnTimeout, transport.IdleConnTimeout)
+			assert.NotNil(t, transport.Proxy)
+
+			if test.wantSessionCache {
+				require.NotNil(t, transport.TLSClientConfig)
+				assert.NotNil(t, transport.TLSClientConfig.ClientSessionCache)
+			} else {
+				assert.Nil(t, transport.TLSClientConfig)
+			}
+		})
+	}
+}
+
+func TestNewWithTransport_sharesConnections(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	srv.Config.ConnState = countNewConns(&conns)
+	srv.Start()
+	defer srv.Close()
+
+	transport := NewTransport(DefaultTransportConfig())
+
+	// Clients are rebuilt each time ACPs change: sharing the transport keeps connections alive across rebuilds.
+	for i := 0; i < 3; i++ {
+		client, err := NewWithTransport(Config{}, transport)
+		require.NoError(t, err)
+
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	assert.Equal(t, int32(1), conns.Load())
+}
+
+func TestNewWithTransport_TLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	transport := NewTransport(DefaultTransportConfig())
+
+	client, err := NewWithTransport(Config{TLS: &ConfigTLS{InsecureSkipVerify: true}}, transport)
+	require.NoError(t, err)
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The shared transport must not be altered by the TLS configuration of a client.
+	assert.False(t, transport.TLSClientConfig.InsecureSkipVerify)
+	assert.Nil(t, transport.TLSClientConfig.RootCAs)
+}
+
+// BenchmarkTransport measures bursts of concurrent calls to a TLS server, as made by the auth server during traffic
+// spikes, and reports the number of connections established per burst.
+func BenchmarkTransport(b *testing.B) {
+	const burstSize = 32
+
+	transports := []struct {
+		desc         string
+		newTransport func() *http.Transport
+	}{
+		{
+			desc: "net/http defaults",
+			newTransport: func() *http.Transport {
+				return http.DefaultTransport.(*http.Transport).Clone()
+			},
+		},
+		{
+			desc: "defaults",
+			newTransport: func() *http.Transport {
+				return NewTransport(DefaultTransportConfig())
+			},
+		},
+	}
+
+	for _, transport := range transports {
+		transport := transport
+		b.Run(transport.desc, func(b *testing.B) {
+			var conns atomic.Int32
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				// Simulate the latency of an identity provider.
+				time.Sleep(time.Millisecond)
+				rw.WriteHeader(http.StatusOK)
+			}))
+			srv.Config.ConnState = countNewConns(&conns)
+			// Identity providers commonly don't speak HTTP/2, which would multiplex requests on a single connection.
+			srv.EnableHTTP2 = false
+			srv.StartTLS()
+			defer srv.Close()
+
+			pool := x509.NewCertPool()
+			pool.AddCert(srv.Certificate())
+
+			tr := transport.newTransport()
+			if tr.TLSClientConfig == nil {
+				tr.TLSClientConfig = &tls.Config{}
+			}
+			tr.TLSClientConfig.RootCAs = pool
+			defer tr.CloseIdleConnections()
+
+			client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				var wg sync.WaitGroup
+				for j := 0; j < burstSize; j++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+
+						resp, err := client.Get(srv.URL)
+						if err != nil {
+							b.Error(err)
+							return
+						}
+						_, _ = io.Copy(io.Discard, resp.Body)
+						_ = resp.Body.Close()
+					}()
+				}
+				wg.Wait()
+			}
+
+			b.ReportMetric(float64(conns.Load())/float64(b.N), "conns/op")
+		})
+	}
+}
+
+func countNewConns(counter *atomic.Int32) func(net.Conn, http.ConnState) {
+	return func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			counter.Add(1)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package journal records the multi-step operations in progress, so the ones interrupted by an agent restart
+// (e.g. during a node drain) can be rolled forward on startup.
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// ConfigMapName is the name of the ConfigMap in which the journal is persisted.
+const ConfigMapName = "hub-agent-journal"
+
+// Entry is an operation in progress on a resource.
+type Entry struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// key returns the ConfigMap key of the entry. Kubernetes names never contain underscores, which makes them safe
+// separators.
+func (e Entry) key() string {
+	return strings.Join([]string{e.Kind, e.Namespace, e.Name}, "_")
+}
+
+// Journal persists the operations in progress in a ConfigMap.
+// A nil Journal is valid and records nothing.
+type Journal struct {
+	client    kclientset.Interface
+	namespace string
+	now       func() time.Time
+
+	mu sync.Mutex
+}
+
+// New creates a journal persisted in the given namespace.
+func New(client kclientset.Interface, namespace string) *Journal {
+	return &Journal{
+		client:    client,
+		namespace: namespace,
+		now:       time.Now,
+	}
+}
+
+// Begin records the start of an operation on the given resource. Beginning an operation already in progress
+// keeps its original start time.
+func (j *Journal) Begin(ctx context.Context, kind, namespace, name string) error {
+	if j == nil {
+		return nil
+	}
+
+	entry := Entry{Kind: kind, Namespace: namespace, Name: name, StartedAt: j.now().UTC()}
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal entry: %w", err)
+	}
+
+	return j.update(ctx, func(data map[string]string) bool {
+		if _, ok := data[entry.key()]; ok {
+			return false
+		}
+
+		data[entry.key()] = string(value)
+		return true
+	})
+}
+
+// End records the completion of the operation on the given resource.
+func (j *Journal) End(ctx context.Context, kind, namespace, name string) error {
+	if j == nil {
+		return nil
+	}
+
+	key := Entry{Kind: kind, Namespace: namespace, Name: name}.key()
+
+	return j.update(ctx, func(data map[string]string) bool {
+		if _, ok := data[key]; !ok {
+			return false
+		}
+
+		delete(data, key)
+		return true
+	})
+}
+
+// Pending returns the operations of the given kind still in progress, oldest first.
+func (j *Journal) Pending(ctx context.Context, kind string) ([]Entry, error) {
+	if j == nil {
+		return nil, nil
+	}
+
+	cm, err := j.client.CoreV1().ConfigMaps(j.namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get journal: %w", err)
+	}
+
+	var entries []Entry
+	for key, value := range cm.Data {
+		var entry Entry
+		if err = json.Unmarshal([]byte(value), &entry); err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("Ignoring malformed journal entry")
+			continue
+		}
+
+		if entry.Kind == kind {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, k int) bool {
+		return entries[i].StartedAt.Before(entries[k].StartedAt)
+	})
+
+	return entries, nil
+}
+
+// Replay calls rollForward for every operation of the given kind still in progress, and ends the ones rolled
+// forward successfully. Failing operations are left in progress and are logged.
+func (j *Journal) Replay(ctx context.Context, kind string, rollForward func(ctx context.Context, entry Entry) error) error {
+	entries, err := j.Pending(ctx, kind)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		logger := log.With().
+			Str("kind", entry.Kind).
+			Str("namespace", entry.Namespace).
+			Str("name", entry.Name).
+			Time("started_at", entry.StartedAt).
+			Logger()
+
+		logger.Info().Msg("Rolling forward interrupted operation")
+
+		if err = rollForward(ctx, entry); err != nil {
+			logger.Error().Err(err).Msg("Unable to roll forward interrupted operation")
+			continue
+		}
+
+		if err = j.End(ctx, entry.Kind, entry.Namespace, entry.Name); err != nil {
+			logger.Error().Err(err).Msg("Unable to end rolled forward operation")
+		}
+	}
+
+	return nil
+}
+
+// update applies the given mutation to the journal data. The mutation reports whether it changed the data.
+func (j *Journal) update(ctx context.Context, mutate func(data map[string]string) bool) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	retriable := func(err error) bool {
+		// Another agent may have created the journal or updated it in the meantime.
+		return kerror.IsConflict(err) || kerror.IsAlreadyExists(err)
+	}
+
+	err := retry.OnError(retry.DefaultRetry, retriable, func() error {
+		cm, err := j.client.CoreV1().ConfigMaps(j.namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			data := map[string]string{}
+			if !mutate(data) {
+				return nil
+			}
+
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ConfigMapName,
+					Namespace: j.namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "traefik-hub",
+					},
+				},
+				Data: data,
+			}
+
+			_, err = j.client.CoreV1().ConfigMaps(j.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		if !mutate(cm.Data) {
+			return nil
+		}
+
+		_, err = j.client.CoreV1().ConfigMaps(j.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("update journal: %w", err)
+	}
+
+	return nil
+}
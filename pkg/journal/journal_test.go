@@ -0,0 +1,124 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package journal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestJournal_BeginEnd(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset()
+
+	j := New(client, "hub-agent")
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	j.now = func() time.Time { return now }
+
+	entries, err := j.Pending(ctx, "APIGateway")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, j.Begin(ctx, "APIGateway", "", "gw"))
+	require.NoError(t, j.Begin(ctx, "EdgeIngress", "default", "edge"))
+
+	// Beginning an operation in progress keeps its original start time.
+	now = now.Add(time.Hour)
+	require.NoError(t, j.Begin(ctx, "APIGateway", "", "gw"))
+	require.NoError(t, j.Begin(ctx, "APIGateway", "", "other-gw"))
+
+	entries, err = j.Pending(ctx, "APIGateway")
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Kind: "APIGateway", Name: "gw", StartedAt: now.Add(-time.Hour)},
+		{Kind: "APIGateway", Name: "other-gw", StartedAt: now},
+	}, entries)
+
+	require.NoError(t, j.End(ctx, "APIGateway", "", "gw"))
+	// Ending an unknown operation is a no-op.
+	require.NoError(t, j.End(ctx, "APIGateway", "", "unknown"))
+
+	entries, err = j.Pending(ctx, "APIGateway")
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Kind: "APIGateway", Name: "other-gw", StartedAt: now}}, entries)
+
+	entries, err = j.Pending(ctx, "EdgeIngress")
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Kind: "EdgeIngress", Namespace: "default", Name: "edge", StartedAt: now.Add(-time.Hour)}}, entries)
+
+	cm, err := client.CoreV1().ConfigMaps("hub-agent").Get(ctx, ConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "traefik-hub", cm.Labels["app.kubernetes.io/managed-by"])
+	assert.Len(t, cm.Data, 2)
+}
+
+func TestJournal_Replay(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "hub-agent"},
+		Data: map[string]string{
+			"APIGateway__ok":      `{"kind":"APIGateway","name":"ok","startedAt":"2023-01-01T00:00:00Z"}`,
+			"APIGateway__failing": `{"kind":"APIGateway","name":"failing","startedAt":"2023-01-01T00:00:01Z"}`,
+			"EdgeIngress_ns_edge": `{"kind":"EdgeIngress","namespace":"ns","name":"edge","startedAt":"2023-01-01T00:00:00Z"}`,
+			"APIGateway__mangled": `{`,
+		},
+	})
+
+	j := New(client, "hub-agent")
+
+	var replayed []string
+	err := j.Replay(ctx, "APIGateway", func(_ context.Context, entry Entry) error {
+		replayed = append(replayed, entry.Name)
+		if entry.Name == "failing" {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ok", "failing"}, replayed)
+
+	cm, err := client.CoreV1().ConfigMaps("hub-agent").Get(ctx, ConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, cm.Data, "APIGateway__ok")
+	assert.Contains(t, cm.Data, "APIGateway__failing")
+	assert.Contains(t, cm.Data, "EdgeIngress_ns_edge")
+}
+
+func TestJournal_nil(t *testing.T) {
+	ctx := context.Background()
+
+	var j *Journal
+
+	assert.NoError(t, j.Begin(ctx, "APIGateway", "", "gw"))
+	assert.NoError(t, j.End(ctx, "APIGateway", "", "gw"))
+
+	err := j.Replay(ctx, "APIGateway", func(context.Context, Entry) error {
+		assert.Fail(t, "nothing must be replayed")
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -41,6 +41,11 @@ func SupportsIngressClasses(ver string) bool {
 	return atLeast(ver, "1.18")
 }
 
+// SupportsAutoscalingV2HPAs reports whether the Kubernetes cluster supports autoscaling v2 HorizontalPodAutoscalers.
+func SupportsAutoscalingV2HPAs(ver string) bool {
+	return atLeast(ver, "1.23")
+}
+
 func atLeast(ver, minVer string) bool {
 	kubeVersion := version.Must(version.NewSemver(ver))
 	minVersion := version.Must(version.NewSemver(minVer))
@@ -0,0 +1,208 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// ErrLeadershipLost is returned by Runner.Run when the current replica loses its leadership.
+var ErrLeadershipLost = errors.New("leadership lost")
+
+// Task is a function that must only run on the leader replica.
+// It must return when the given context is canceled. Returning an error stops the Runner.
+type Task func(ctx context.Context) error
+
+// Config configures the leader election.
+type Config struct {
+	// Enabled tells whether leader election is enabled. When disabled, the current replica is always the leader.
+	Enabled bool
+	// LeaseName is the name of the Lease used to elect the leader.
+	LeaseName string
+	// Namespace is the namespace of the Lease.
+	Namespace string
+	// Identity uniquely identifies the current replica.
+	Identity string
+
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// Runner runs tasks only while the current replica holds the leadership.
+// Replicas which are not leaders keep their caches warm and wait to take over.
+type Runner struct {
+	cfg        Config
+	kubeClient kubernetes.Interface
+
+	tasksMu   sync.Mutex
+	tasks     []Task
+	leaderCtx context.Context
+	wg        sync.WaitGroup
+
+	errMu  sync.Mutex
+	err    error
+	cancel context.CancelFunc
+}
+
+// NewRunner creates a new Runner.
+func NewRunner(kubeClient kubernetes.Interface, cfg Config) *Runner {
+	return &Runner{
+		cfg:        cfg,
+		kubeClient: kubeClient,
+	}
+}
+
+// Add registers a task to run while the current replica is the leader. If the replica is already leading, the task
+// starts immediately.
+func (r *Runner) Add(task Task) {
+	r.tasksMu.Lock()
+	defer r.tasksMu.Unlock()
+
+	r.tasks = append(r.tasks, task)
+
+	if r.leaderCtx != nil {
+		r.start(r.leaderCtx, task)
+	}
+}
+
+// Run campaigns for the leadership and runs the registered tasks once elected. It blocks until the given context is
+// canceled or the leadership is lost, in which case ErrLeadershipLost is returned so the replica can restart as a
+// follower.
+func (r *Runner) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r.errMu.Lock()
+	r.cancel = cancel
+	r.errMu.Unlock()
+
+	if !r.cfg.Enabled {
+		r.lead(ctx)
+		<-ctx.Done()
+		r.wg.Wait()
+
+		return r.taskErr()
+	}
+
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock,
+		r.cfg.Namespace,
+		r.cfg.LeaseName,
+		r.kubeClient.CoreV1(),
+		r.kubeClient.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: r.cfg.Identity},
+	)
+	if err != nil {
+		return fmt.Errorf("create lease lock: %w", err)
+	}
+
+	var lost bool
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   r.cfg.LeaseDuration,
+		RenewDeadline:   r.cfg.RenewDeadline,
+		RetryPeriod:     r.cfg.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            r.cfg.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				log.Info().Str("identity", r.cfg.Identity).Msg("Elected as leader")
+				r.lead(leaderCtx)
+			},
+			OnStoppedLeading: func() {
+				lost = ctx.Err() == nil
+				if lost {
+					log.Error().Str("identity", r.cfg.Identity).Msg("Leadership lost")
+				}
+			},
+			OnNewLeader: func(identity string) {
+				if identity != r.cfg.Identity {
+					log.Info().Str("leader", identity).Msg("Following leader")
+				}
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("create leader elector: %w", err)
+	}
+
+	// Run only returns once the leadership is lost or the context canceled, in both cases the leader context
+	// given to the tasks has been canceled.
+	elector.Run(ctx)
+	r.wg.Wait()
+
+	if err = r.taskErr(); err != nil {
+		return err
+	}
+
+	if lost {
+		return ErrLeadershipLost
+	}
+
+	return nil
+}
+
+func (r *Runner) lead(ctx context.Context) {
+	r.tasksMu.Lock()
+	defer r.tasksMu.Unlock()
+
+	r.leaderCtx = ctx
+	for _, task := range r.tasks {
+		r.start(ctx, task)
+	}
+}
+
+func (r *Runner) start(ctx context.Context, task Task) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		if err := task(ctx); err != nil && ctx.Err() == nil {
+			r.fail(err)
+		}
+	}()
+}
+
+func (r *Runner) fail(err error) {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+
+	if r.err == nil {
+		r.err = err
+	}
+
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+func (r *Runner) taskErr() error {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+
+	return r.err
+}
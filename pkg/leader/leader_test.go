@@ -0,0 +1,150 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package leader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunner_Run_disabled(t *testing.T) {
+	runner := NewRunner(kubefake.NewSimpleClientset(), Config{})
+
+	started := make(chan struct{})
+	runner.Add(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() { done <- runner.Run(ctx) }()
+
+	waitFor(t, started)
+
+	// Tasks added once leading are started right away.
+	lateStarted := make(chan struct{})
+	runner.Add(func(ctx context.Context) error {
+		close(lateStarted)
+		<-ctx.Done()
+		return nil
+	})
+	waitFor(t, lateStarted)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestRunner_Run_taskError(t *testing.T) {
+	runner := NewRunner(kubefake.NewSimpleClientset(), Config{})
+
+	boom := errors.New("boom")
+	runner.Add(func(ctx context.Context) error {
+		return boom
+	})
+	runner.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	assert.ErrorIs(t, runner.Run(context.Background()), boom)
+}
+
+func TestRunner_Run_leaderElection(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+
+	cfg := Config{
+		Enabled:       true,
+		LeaseName:     "hub-agent-controller",
+		Namespace:     "hub-agent",
+		LeaseDuration: time.Second,
+		RenewDeadline: 500 * time.Millisecond,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+
+	cfg.Identity = "leader"
+	leaderRunner := NewRunner(kubeClient, cfg)
+
+	cfg.Identity = "follower"
+	followerRunner := NewRunner(kubeClient, cfg)
+
+	leaderStarted := make(chan struct{})
+	leaderRunner.Add(func(ctx context.Context) error {
+		close(leaderStarted)
+		<-ctx.Done()
+		return nil
+	})
+
+	followerStarted := make(chan struct{})
+	followerRunner.Add(func(ctx context.Context) error {
+		close(followerStarted)
+		<-ctx.Done()
+		return nil
+	})
+
+	leaderCtx, leaderCancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() { leaderDone <- leaderRunner.Run(leaderCtx) }()
+
+	waitFor(t, leaderStarted)
+
+	lease, err := kubeClient.CoordinationV1().Leases("hub-agent").Get(context.Background(), "hub-agent-controller", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, lease.Spec.HolderIdentity)
+	assert.Equal(t, "leader", *lease.Spec.HolderIdentity)
+
+	followerCtx, followerCancel := context.WithCancel(context.Background())
+	t.Cleanup(followerCancel)
+
+	followerDone := make(chan error)
+	go func() { followerDone <- followerRunner.Run(followerCtx) }()
+
+	select {
+	case <-followerStarted:
+		t.Fatal("follower must not run tasks while another replica is leading")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Once the leader steps down, the follower takes over.
+	leaderCancel()
+	assert.NoError(t, <-leaderDone)
+
+	waitFor(t, followerStarted)
+
+	followerCancel()
+	assert.NoError(t, <-followerDone)
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+}
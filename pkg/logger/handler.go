@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LevelRequest is a request to override the log level of a component.
+type LevelRequest struct {
+	// Component is the component whose level is overridden, all of them if empty.
+	Component string `json:"component"`
+	// Level is the overriding level, the override is removed if empty.
+	Level string `json:"level"`
+	// Duration is how long the override lasts, e.g. "15m". It lasts until it's removed or the agent restarts if empty.
+	Duration string `json:"duration,omitempty"`
+}
+
+// Apply applies the request.
+func (r LevelRequest) Apply() error {
+	var ttl time.Duration
+	if r.Duration != "" {
+		var err error
+		ttl, err = time.ParseDuration(r.Duration)
+		if err != nil {
+			return fmt.Errorf("parse duration: %w", err)
+		}
+	}
+
+	return OverrideLevel(r.Component, r.Level, ttl)
+}
+
+type levelsResponse struct {
+	Level     string          `json:"level"`
+	Overrides []LevelOverride `json:"overrides"`
+}
+
+// LevelHandler serves the log levels on GET requests, and overrides the level of a component on PUT requests, whose
+// body is a LevelRequest.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var levelReq LevelRequest
+			if err := json.NewDecoder(http.MaxBytesReader(rw, req.Body, 1<<10)).Decode(&levelReq); err != nil {
+				http.Error(rw, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+				return
+			}
+
+			if err := levelReq.Apply(); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			rw.Header().Set("Allow", "GET, PUT")
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var resp levelsResponse
+		resp.Level, resp.Overrides = Levels()
+
+		rw.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(rw).Encode(resp); err != nil {
+			log.Error().Err(err).Msg("Unable to write log levels")
+		}
+	})
+}
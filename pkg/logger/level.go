@@ -0,0 +1,240 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// AllComponents designates all the components when overriding the log level.
+const AllComponents = ""
+
+// levels holds the log levels of the agent.
+var levels = &levelSet{
+	base:      zerolog.InfoLevel,
+	overrides: make(map[string]override),
+}
+
+// override is a log level overriding the base one for a component, until it expires.
+type override struct {
+	level     zerolog.Level
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+// levelSet holds the base log level, set from the flags, and the levels overriding it at runtime for some components.
+type levelSet struct {
+	mu        sync.RWMutex
+	base      zerolog.Level
+	overrides map[string]override
+}
+
+func (s *levelSet) setBase(level zerolog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.base = level
+	s.apply()
+}
+
+// set sets the level of the given component, removing its override if the level is zerolog.NoLevel. The override is
+// removed after the given TTL, unless it's zero.
+func (s *levelSet) set(component string, level zerolog.Level, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.overrides[component]; ok && previous.timer != nil {
+		previous.timer.Stop()
+	}
+	delete(s.overrides, component)
+
+	if level != zerolog.NoLevel {
+		o := override{level: level}
+		if ttl > 0 {
+			o.expiresAt = time.Now().Add(ttl)
+			o.timer = time.AfterFunc(ttl, func() { s.expire(component, o.expiresAt) })
+		}
+		s.overrides[component] = o
+	}
+
+	s.apply()
+}
+
+func (s *levelSet) expire(component string, expiresAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The override may have been replaced in the meantime.
+	if o, ok := s.overrides[component]; !ok || !o.expiresAt.Equal(expiresAt) {
+		return
+	}
+	delete(s.overrides, component)
+
+	s.apply()
+
+	// The component isn't logged under the component field, which would subject the event to its own level.
+	log.Info().Str("overridden_component", component).Msg("Log level override expired")
+}
+
+// apply sets the global level to the lowest level in use, so the events of all the components are built, and left to
+// the levelWriter to filter. It must be called with the lock held.
+func (s *levelSet) apply() {
+	lowest := s.base
+	for _, o := range s.overrides {
+		if o.level < lowest {
+			lowest = o.level
+		}
+	}
+
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// threshold returns the level under which the events of the given component are dropped.
+func (s *levelSet) threshold(event []byte) (zerolog.Level, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.overrides) == 0 {
+		return s.base, false
+	}
+
+	if o, ok := s.overrides[eventComponent(event)]; ok {
+		return o.level, true
+	}
+	if o, ok := s.overrides[AllComponents]; ok {
+		return o.level, true
+	}
+
+	return s.base, true
+}
+
+var componentField = []byte(`"component":"`)
+
+// eventComponent returns the component of the given JSON event, empty if it doesn't have any.
+func eventComponent(event []byte) string {
+	i := bytes.Index(event, componentField)
+	if i < 0 {
+		return ""
+	}
+
+	value := event[i+len(componentField):]
+	end := bytes.IndexByte(value, '"')
+	if end < 0 {
+		return ""
+	}
+
+	return string(value[:end])
+}
+
+// levelWriter drops the events under the level of their component, and keeps the others as recent logs.
+type levelWriter struct {
+	next io.Writer
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	recent.add(zerolog.NoLevel, p)
+
+	return w.next.Write(p)
+}
+
+func (w levelWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	// Without overrides, the global level already filtered the events out.
+	if threshold, ok := levels.threshold(p); ok && level < threshold {
+		return len(p), nil
+	}
+
+	recent.add(level, p)
+
+	return w.next.Write(p)
+}
+
+// SetLevel changes the base log level of a logger already set up, e.g. once the configuration file changed.
+func SetLevel(level string) error {
+	logLevel, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return fmt.Errorf("parse log level: %w", err)
+	}
+
+	levels.setBase(logLevel)
+	log.Info().Str("level", logLevel.String()).Msg("Log level changed")
+
+	return nil
+}
+
+// OverrideLevel overrides the log level of the given component, or of all of them with AllComponents, e.g. to debug
+// a component without restarting the agent. An empty level removes the override. The override is removed after the
+// given TTL, unless it's zero.
+func OverrideLevel(component, level string, ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("negative duration %s", ttl)
+	}
+
+	logLevel := zerolog.NoLevel
+	if level != "" {
+		var err error
+		logLevel, err = zerolog.ParseLevel(strings.ToLower(level))
+		if err != nil || logLevel == zerolog.NoLevel {
+			return fmt.Errorf("invalid log level %q", level)
+		}
+	}
+
+	levels.set(component, logLevel, ttl)
+
+	log.Info().
+		Str("overridden_component", component).
+		Str("level", level).
+		Dur("ttl", ttl).
+		Msg("Log level overridden")
+
+	return nil
+}
+
+// LevelOverride is a log level overriding the base one for a component.
+type LevelOverride struct {
+	Component string     `json:"component"`
+	Level     string     `json:"level"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Levels returns the base log level, and the overrides sorted by component.
+func Levels() (string, []LevelOverride) {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	overrides := make([]LevelOverride, 0, len(levels.overrides))
+	for component, o := range levels.overrides {
+		lo := LevelOverride{Component: component, Level: o.level.String()}
+		if !o.expiresAt.IsZero() {
+			expiresAt := o.expiresAt
+			lo.ExpiresAt = &expiresAt
+		}
+		overrides = append(overrides, lo)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Component < overrides[j].Component })
+
+	return levels.base.String(), overrides
+}
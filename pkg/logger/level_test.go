@@ -0,0 +1,165 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverrideLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := setupTest(t, &buf)
+
+	logAll := func() {
+		l.Debug().Msg("debug")
+		l.Debug().Str("component", "platform_client").Msg("client debug")
+		l.Info().Str("component", "platform_client").Msg("client info")
+		l.Info().Str("component", "tunnel").Msg("tunnel info")
+	}
+
+	logAll()
+	assertMessages(t, &buf, "client info", "tunnel info")
+
+	require.NoError(t, OverrideLevel("platform_client", "debug", 0))
+	logAll()
+	assertMessages(t, &buf, "client debug", "client info", "tunnel info")
+
+	require.NoError(t, OverrideLevel("tunnel", "error", 0))
+	logAll()
+	assertMessages(t, &buf, "client debug", "client info")
+
+	// Overrides of a component take precedence over the override of all the components.
+	require.NoError(t, OverrideLevel(AllComponents, "debug", 0))
+	logAll()
+	assertMessages(t, &buf, "debug", "client debug", "client info")
+
+	require.NoError(t, OverrideLevel(AllComponents, "", 0))
+	require.NoError(t, OverrideLevel("tunnel", "", 0))
+	require.NoError(t, OverrideLevel("platform_client", "", 0))
+	logAll()
+	assertMessages(t, &buf, "client info", "tunnel info")
+}
+
+func TestOverrideLevel_expires(t *testing.T) {
+	var buf bytes.Buffer
+	l := setupTest(t, &buf)
+
+	require.NoError(t, OverrideLevel("platform_client", "debug", 50*time.Millisecond))
+
+	_, overrides := Levels()
+	require.Len(t, overrides, 1)
+	assert.Equal(t, "platform_client", overrides[0].Component)
+	assert.NotNil(t, overrides[0].ExpiresAt)
+
+	assert.Eventually(t, func() bool {
+		_, overrides = Levels()
+		return len(overrides) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	l.Debug().Str("component", "platform_client").Msg("client debug")
+	assertMessages(t, &buf)
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+}
+
+func TestOverrideLevel_errors(t *testing.T) {
+	setupTest(t, &bytes.Buffer{})
+
+	assert.Error(t, OverrideLevel("platform_client", "verbose", 0))
+	assert.Error(t, OverrideLevel("platform_client", "debug", -time.Second))
+}
+
+func TestLevelHandler(t *testing.T) {
+	setupTest(t, &bytes.Buffer{})
+
+	handler := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	body := strings.NewReader(`{"component":"platform_client","level":"debug"}`)
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log-level", body))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info","overrides":[{"component":"platform_client","level":"debug"}]}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	body = strings.NewReader(`{"component":"platform_client","level":"debug","duration":"soon"}`)
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log-level", body))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/log-level", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log-level", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info","overrides":[{"component":"platform_client","level":"debug"}]}`, rec.Body.String())
+}
+
+// setupTest sets the levels up with an info base level, returning a logger writing to the given buffer. The global
+// level is restored once the test is done.
+func setupTest(t *testing.T, buf *bytes.Buffer) zerolog.Logger {
+	t.Helper()
+
+	globalLevel := zerolog.GlobalLevel()
+	t.Cleanup(func() {
+		levels.mu.Lock()
+		for _, o := range levels.overrides {
+			if o.timer != nil {
+				o.timer.Stop()
+			}
+		}
+		levels.overrides = make(map[string]override)
+		levels.mu.Unlock()
+
+		zerolog.SetGlobalLevel(globalLevel)
+	})
+
+	levels.setBase(zerolog.InfoLevel)
+
+	return zerolog.New(levelWriter{next: buf})
+}
+
+func assertMessages(t *testing.T, buf *bytes.Buffer, want ...string) {
+	t.Helper()
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		i := strings.Index(line, `"message":"`)
+		require.GreaterOrEqual(t, i, 0, line)
+		got = append(got, strings.TrimSuffix(line[i+len(`"message":"`):], `"}`))
+	}
+	buf.Reset()
+
+	assert.Equal(t, want, got)
+}
@@ -55,7 +55,8 @@ func Setup(level, format string) {
 		w = os.Stderr
 	}
 
-	logCtx := zerolog.New(w).With().Timestamp()
+	// Events are filtered by component once they're written, so components can log at another level than the others.
+	logCtx := zerolog.New(levelWriter{next: w}).With().Timestamp()
 	if logLevel <= zerolog.DebugLevel {
 		logCtx = logCtx.Caller()
 	}
@@ -63,7 +64,7 @@ func Setup(level, format string) {
 	log.Logger = logCtx.Logger()
 	zerolog.DefaultContextLogger = &log.Logger
 
-	zerolog.SetGlobalLevel(logLevel)
+	levels.setBase(logLevel)
 
 	log.Trace().Str("level", logLevel.String()).Msg("Log level set")
 }
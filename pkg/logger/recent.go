@@ -0,0 +1,98 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logger
+
+import (
+	"bytes"
+	"maps"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// recentLogsSize is the number of events kept in memory, e.g. to be part of diagnostics bundles.
+const recentLogsSize = 1000
+
+// recent holds the last events written by the agent.
+var recent = newRecentLogs(recentLogsSize)
+
+// recentLogs is a ring buffer of the last events written, along with the last error of each component.
+type recentLogs struct {
+	mu         sync.Mutex
+	events     [][]byte
+	next       int
+	full       bool
+	lastErrors map[string][]byte
+}
+
+func newRecentLogs(size int) *recentLogs {
+	return &recentLogs{
+		events:     make([][]byte, size),
+		lastErrors: make(map[string][]byte),
+	}
+}
+
+func (r *recentLogs) add(level zerolog.Level, event []byte) {
+	// Events are written from a buffer reused by zerolog.
+	event = bytes.Clone(event)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[r.next] = event
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+
+	if level >= zerolog.ErrorLevel && level < zerolog.NoLevel {
+		r.lastErrors[eventComponent(event)] = event
+	}
+}
+
+func (r *recentLogs) list() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([][]byte(nil), r.events[:r.next]...)
+	}
+
+	events := make([][]byte, 0, len(r.events))
+	events = append(events, r.events[r.next:]...)
+
+	return append(events, r.events[:r.next]...)
+}
+
+func (r *recentLogs) errors() map[string][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return maps.Clone(r.lastErrors)
+}
+
+// RecentLogs returns the last events written, oldest first, as written by a JSON logger.
+func RecentLogs() [][]byte {
+	return recent.list()
+}
+
+// LastErrors returns the last error logged by each component, by component, as written by a JSON logger. Errors logged
+// without component are under an empty one.
+func LastErrors() map[string][]byte {
+	return recent.errors()
+}
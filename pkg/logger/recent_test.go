@@ -0,0 +1,55 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logger
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentLogs(t *testing.T) {
+	r := newRecentLogs(3)
+
+	event := []byte(`{"level":"info","message":"1"}`)
+	r.add(zerolog.InfoLevel, event)
+	// Events are copied, zerolog reuses its buffers.
+	copy(event, `{"level":"info","message":"x"}`)
+
+	r.add(zerolog.ErrorLevel, []byte(`{"level":"error","component":"tunnel","message":"2"}`))
+	assert.Equal(t, [][]byte{
+		[]byte(`{"level":"info","message":"1"}`),
+		[]byte(`{"level":"error","component":"tunnel","message":"2"}`),
+	}, r.list())
+
+	r.add(zerolog.ErrorLevel, []byte(`{"level":"error","message":"3"}`))
+	r.add(zerolog.InfoLevel, []byte(`{"level":"info","message":"4"}`))
+	r.add(zerolog.ErrorLevel, []byte(`{"level":"error","component":"tunnel","message":"5"}`))
+
+	assert.Equal(t, [][]byte{
+		[]byte(`{"level":"error","message":"3"}`),
+		[]byte(`{"level":"info","message":"4"}`),
+		[]byte(`{"level":"error","component":"tunnel","message":"5"}`),
+	}, r.list())
+
+	assert.Equal(t, map[string][]byte{
+		"":       []byte(`{"level":"error","message":"3"}`),
+		"tunnel": []byte(`{"level":"error","component":"tunnel","message":"5"}`),
+	}, r.errors())
+}
@@ -17,6 +17,11 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 
 package metrics
 
+import (
+	"math"
+	"sort"
+)
+
 // DataPoints contains a slice of data points.
 type DataPoints []DataPoint
 
@@ -41,6 +46,7 @@ func (p DataPoints) Aggregate() DataPoint {
 		newPnt.RequestClientErrs += pnt.RequestClientErrs
 		newPnt.ResponseTimeSum += pnt.ResponseTimeSum
 		newPnt.ResponseTimeCount += pnt.ResponseTimeCount
+		newPnt.ResponseTimeBuckets = newPnt.ResponseTimeBuckets.Add(pnt.ResponseTimeBuckets)
 	}
 
 	if newPnt.Seconds > 0 {
@@ -57,6 +63,8 @@ func (p DataPoints) Aggregate() DataPoint {
 		newPnt.RequestClientErrPercent = float64(newPnt.RequestClientErrs) / float64(newPnt.Requests)
 	}
 
+	newPnt.setResponseTimePercentiles()
+
 	return newPnt
 }
 
@@ -85,6 +93,19 @@ type DataPoint struct {
 	RequestClientErrs int64   `avro:"request_client_errors"`
 	ResponseTimeSum   float64 `avro:"response_time_sum"`
 	ResponseTimeCount int64   `avro:"response_time_count"`
+
+	// The response time histogram and percentiles are not part of the metrics sent to the platform.
+	ResponseTimeBuckets Buckets `avro:"-"`
+	ResponseTimeP50     float64 `avro:"-"`
+	ResponseTimeP95     float64 `avro:"-"`
+	ResponseTimeP99     float64 `avro:"-"`
+}
+
+// setResponseTimePercentiles computes the response time percentiles from the response time histogram.
+func (p *DataPoint) setResponseTimePercentiles() {
+	p.ResponseTimeP50 = p.ResponseTimeBuckets.Quantile(0.5)
+	p.ResponseTimeP95 = p.ResponseTimeBuckets.Quantile(0.95)
+	p.ResponseTimeP99 = p.ResponseTimeBuckets.Quantile(0.99)
 }
 
 // SetKey contains the primary key of a metric set.
@@ -114,6 +135,7 @@ func (s MetricSet) RelativeTo(o MetricSet) MetricSet {
 	if !o.RequestDuration.Relative {
 		s.RequestDuration.Sum -= o.RequestDuration.Sum
 		s.RequestDuration.Count -= o.RequestDuration.Count
+		s.RequestDuration.Buckets = s.RequestDuration.Buckets.Sub(o.RequestDuration.Buckets)
 	}
 	return s
 }
@@ -129,7 +151,7 @@ func (s MetricSet) ToDataPoint(secs int64) DataPoint {
 		clientErrPercent = float64(s.RequestClientErrors) / float64(s.Requests)
 	}
 
-	return DataPoint{
+	pnt := DataPoint{
 		ReqPerS:                 float64(s.Requests) / float64(secs),
 		RequestErrPerS:          float64(s.RequestErrors) / float64(secs),
 		RequestErrPercent:       errPercent,
@@ -141,7 +163,11 @@ func (s MetricSet) ToDataPoint(secs int64) DataPoint {
 		RequestClientErrs:       s.RequestClientErrors,
 		ResponseTimeSum:         s.RequestDuration.Sum,
 		ResponseTimeCount:       s.RequestDuration.Count,
+		ResponseTimeBuckets:     s.RequestDuration.Buckets,
 	}
+	pnt.setResponseTimePercentiles()
+
+	return pnt
 }
 
 // ServiceHistogram contains histogram metrics.
@@ -149,6 +175,101 @@ type ServiceHistogram struct {
 	Relative bool
 	Sum      float64
 	Count    int64
+	Buckets  Buckets
+}
+
+// Buckets holds the cumulative counts of a histogram by upper bound, in seconds.
+type Buckets map[float64]uint64
+
+// Add returns the sum of b and o.
+func (b Buckets) Add(o Buckets) Buckets {
+	if len(b) == 0 && len(o) == 0 {
+		return nil
+	}
+
+	sum := make(Buckets, len(b))
+	for bound, count := range b {
+		sum[bound] = count
+	}
+	for bound, count := range o {
+		sum[bound] += count
+	}
+
+	return sum
+}
+
+// Sub returns b relative to o. Buckets whose count has been reset are kept as is.
+func (b Buckets) Sub(o Buckets) Buckets {
+	if len(b) == 0 {
+		return nil
+	}
+
+	diff := make(Buckets, len(b))
+	for bound, count := range b {
+		if prev := o[bound]; prev <= count {
+			count -= prev
+		}
+		diff[bound] = count
+	}
+
+	return diff
+}
+
+// scale returns b with its upper bounds multiplied by factor, e.g. to convert them to seconds.
+func (b Buckets) scale(factor float64) Buckets {
+	scaled := make(Buckets, len(b))
+	for bound, count := range b {
+		scaled[bound*factor] = count
+	}
+
+	return scaled
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) of the histogram, interpolating linearly within the bucket it
+// falls in, as the Prometheus histogram_quantile function does. When it falls in the +Inf bucket, the highest finite
+// upper bound is returned. It returns 0 for an empty histogram.
+func (b Buckets) Quantile(q float64) float64 {
+	bounds := make([]float64, 0, len(b))
+	for bound := range b {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+
+	if len(bounds) == 0 {
+		return 0
+	}
+
+	total := b[bounds[len(bounds)-1]]
+	if total == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+
+	var lowerBound float64
+	var lowerCount uint64
+	for i, bound := range bounds {
+		count := b[bound]
+		if float64(count) < rank || count == lowerCount {
+			lowerBound, lowerCount = bound, count
+			continue
+		}
+
+		if math.IsInf(bound, 1) {
+			if i == 0 {
+				return 0
+			}
+			return bounds[i-1]
+		}
+
+		if i == 0 && bound <= 0 {
+			return bound
+		}
+
+		return lowerBound + (bound-lowerBound)*(rank-float64(lowerCount))/float64(count-lowerCount)
+	}
+
+	return lowerBound
 }
 
 // Aggregate aggregates metrics into a service metric set.
@@ -180,8 +301,12 @@ func Aggregate(m []Metric) map[SetKey]MetricSet {
 			dur := svc.RequestDuration
 			dur.Sum += val.Sum
 			dur.Count += int64(val.Count)
+			dur.Buckets = dur.Buckets.Add(val.Buckets)
 			dur.Relative = val.Relative
 			svc.RequestDuration = dur
+
+		default:
+			continue
 		}
 
 		svcs[key] = svc
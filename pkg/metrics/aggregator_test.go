@@ -18,6 +18,7 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package metrics_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,6 +67,7 @@ func TestDataPoints_Aggregate(t *testing.T) {
 			RequestClientErrs:       2,
 			ResponseTimeSum:         10,
 			ResponseTimeCount:       1,
+			ResponseTimeBuckets:     metrics.Buckets{0.1: 0, 1: 1, math.Inf(1): 1},
 		},
 		{
 			ReqPerS:                 20,
@@ -80,6 +82,7 @@ func TestDataPoints_Aggregate(t *testing.T) {
 			RequestClientErrs:       3,
 			ResponseTimeSum:         20,
 			ResponseTimeCount:       2,
+			ResponseTimeBuckets:     metrics.Buckets{0.1: 1, 1: 2, math.Inf(1): 2},
 		},
 		{
 			ReqPerS:                 30,
@@ -94,6 +97,7 @@ func TestDataPoints_Aggregate(t *testing.T) {
 			RequestClientErrs:       4,
 			ResponseTimeSum:         30,
 			ResponseTimeCount:       3,
+			ResponseTimeBuckets:     metrics.Buckets{0.1: 1, 1: 2, math.Inf(1): 3},
 		},
 	}
 
@@ -111,6 +115,68 @@ func TestDataPoints_Aggregate(t *testing.T) {
 	assert.Equal(t, int64(9), got.RequestClientErrs)
 	assert.Equal(t, float64(60), got.ResponseTimeSum)
 	assert.Equal(t, int64(6), got.ResponseTimeCount)
+	assert.Equal(t, metrics.Buckets{0.1: 2, 1: 5, math.Inf(1): 6}, got.ResponseTimeBuckets)
+	assert.InDelta(t, 0.4, got.ResponseTimeP50, 1e-9)
+	assert.Equal(t, 1.0, got.ResponseTimeP95)
+	assert.Equal(t, 1.0, got.ResponseTimeP99)
+}
+
+func TestBuckets_Quantile(t *testing.T) {
+	tests := []struct {
+		desc    string
+		buckets metrics.Buckets
+		q       float64
+		want    float64
+	}{
+		{
+			desc: "empty histogram",
+			q:    0.5,
+		},
+		{
+			desc:    "no observations",
+			buckets: metrics.Buckets{0.1: 0, math.Inf(1): 0},
+			q:       0.5,
+		},
+		{
+			desc:    "interpolated in the first bucket",
+			buckets: metrics.Buckets{0.1: 10, 0.5: 10, math.Inf(1): 10},
+			q:       0.5,
+			want:    0.05,
+		},
+		{
+			desc:    "interpolated in a bucket",
+			buckets: metrics.Buckets{0.1: 10, 0.5: 30, 1: 40, math.Inf(1): 40},
+			q:       0.5,
+			want:    0.3,
+		},
+		{
+			desc:    "upper bound of a bucket",
+			buckets: metrics.Buckets{0.1: 10, 0.5: 30, 1: 40, math.Inf(1): 40},
+			q:       0.75,
+			want:    0.5,
+		},
+		{
+			desc:    "skips empty buckets",
+			buckets: metrics.Buckets{0.1: 0, 0.5: 0, 1: 10, math.Inf(1): 10},
+			q:       0.5,
+			want:    0.75,
+		},
+		{
+			desc:    "+Inf bucket",
+			buckets: metrics.Buckets{0.1: 10, 0.5: 10, math.Inf(1): 100},
+			q:       0.99,
+			want:    0.5,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.InDelta(t, test.want, test.buckets.Quantile(test.q), 1e-9)
+		})
+	}
 }
 
 func TestAggregator_Aggregate(t *testing.T) {
@@ -166,3 +232,35 @@ func TestAggregator_Aggregate(t *testing.T) {
 		},
 	})
 }
+
+func TestMetricSet_RelativeTo(t *testing.T) {
+	prev := metrics.MetricSet{
+		Requests: 10,
+		RequestDuration: metrics.ServiceHistogram{
+			Sum:     1,
+			Count:   10,
+			Buckets: metrics.Buckets{0.1: 8, 1: 10, math.Inf(1): 10},
+		},
+	}
+	cur := metrics.MetricSet{
+		Requests: 15,
+		RequestDuration: metrics.ServiceHistogram{
+			Sum:     2,
+			Count:   15,
+			Buckets: metrics.Buckets{0.1: 9, 1: 14, math.Inf(1): 15},
+		},
+	}
+
+	got := cur.RelativeTo(prev)
+
+	assert.Equal(t, metrics.MetricSet{
+		Requests: 5,
+		RequestDuration: metrics.ServiceHistogram{
+			Sum:     1,
+			Count:   5,
+			Buckets: metrics.Buckets{0.1: 1, 1: 4, math.Inf(1): 5},
+		},
+	}, got)
+	// The scraped buckets are left untouched.
+	assert.Equal(t, metrics.Buckets{0.1: 9, 1: 14, math.Inf(1): 15}, cur.RequestDuration.Buckets)
+}
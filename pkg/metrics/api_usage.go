@@ -0,0 +1,385 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+)
+
+const (
+	// apiUsageWindow is the period over which the API usages are summarized.
+	apiUsageWindow = time.Hour
+	// maxPendingAPIUsagePoints bounds the number of API usage points kept while the platform can't be reached.
+	maxPendingAPIUsagePoints = 10000
+)
+
+var pathPrefixRe = regexp.MustCompile("PathPrefix\\(`([^`]*)`\\)")
+
+// APIConsumer identifies the consumers of an API: the groups allowed to reach it by the route serving them.
+type APIConsumer struct {
+	// API is the API, in the name@namespace format.
+	API string
+	// Groups are the comma-separated groups of the consumers.
+	Groups string
+}
+
+// APIMetric is a metric of the traffic of API consumers. It is only aggregated by AggregateAPIs.
+type APIMetric struct {
+	APIConsumer
+
+	// Metric is either a Counter or a Histogram.
+	Metric Metric
+}
+
+// EdgeIngressName returns the metric edge ingress name.
+func (m APIMetric) EdgeIngressName() string {
+	return ""
+}
+
+// IngressName returns the metric ingress name.
+func (m APIMetric) IngressName() string {
+	return ""
+}
+
+// ServiceName returns the metric service name.
+func (m APIMetric) ServiceName() string {
+	return ""
+}
+
+// AggregateAPIs aggregates the API metrics of m by API consumer.
+func AggregateAPIs(m []Metric) map[APIConsumer]MetricSet {
+	var apiMetrics []Metric
+	consumers := make(map[SetKey]APIConsumer)
+	for _, metric := range m {
+		apiMetric, ok := metric.(*APIMetric)
+		if !ok {
+			continue
+		}
+
+		// API consumers are aggregated under a key of their own, so the regular aggregation can be reused.
+		key := SetKey{Ingress: apiMetric.API, Service: apiMetric.Groups}
+		consumers[key] = apiMetric.APIConsumer
+
+		switch val := apiMetric.Metric.(type) {
+		case *Counter:
+			c := *val
+			c.EdgeIngress, c.Ingress, c.Service = key.EdgeIngress, key.Ingress, key.Service
+			apiMetrics = append(apiMetrics, &c)
+		case *Histogram:
+			h := *val
+			h.EdgeIngress, h.Ingress, h.Service = key.EdgeIngress, key.Ingress, key.Service
+			apiMetrics = append(apiMetrics, &h)
+		}
+	}
+
+	sets := make(map[APIConsumer]MetricSet, len(consumers))
+	for key, set := range Aggregate(apiMetrics) {
+		sets[consumers[key]] = set
+	}
+
+	return sets
+}
+
+// APIUsage is the usage of an API by a group of consumers over a period.
+type APIUsage struct {
+	// API is the API, in the name@namespace format.
+	API    string   `json:"api"`
+	Groups []string `json:"groups"`
+
+	Requests            int64 `json:"requests"`
+	RequestErrors       int64 `json:"requestErrors"`
+	RequestClientErrors int64 `json:"requestClientErrors"`
+	// ErrorRate is the ratio of requests having failed with a server error.
+	ErrorRate float64 `json:"errorRate"`
+	// ClientErrorRate is the ratio of requests having failed with a client error.
+	ClientErrorRate float64 `json:"clientErrorRate"`
+	// Response times are in seconds.
+	AvgResponseTime float64 `json:"avgResponseTime"`
+	ResponseTimeP95 float64 `json:"responseTimeP95"`
+}
+
+// APIUsagePoint is the usage of an API by a group of consumers during a minute.
+type APIUsagePoint struct {
+	Timestamp int64    `json:"timestamp"`
+	API       string   `json:"api"`
+	Groups    []string `json:"groups"`
+
+	Requests            int64   `json:"requests"`
+	RequestErrors       int64   `json:"requestErrors"`
+	RequestClientErrors int64   `json:"requestClientErrors"`
+	ResponseTimeSum     float64 `json:"responseTimeSum"`
+	ResponseTimeCount   int64   `json:"responseTimeCount"`
+}
+
+// APIUsageTracker tracks the usage of the APIs by their consumers. It keeps the points of the last hour to summarize
+// the usages, and the points not sent to the platform yet.
+type APIUsageTracker struct {
+	mu      sync.Mutex
+	window  map[APIConsumer]DataPoints
+	pending []APIUsagePoint
+
+	nowFunc func() time.Time
+}
+
+// NewAPIUsageTracker returns a new APIUsageTracker.
+func NewAPIUsageTracker() *APIUsageTracker {
+	return &APIUsageTracker{
+		window:  make(map[APIConsumer]DataPoints),
+		nowFunc: time.Now,
+	}
+}
+
+// Insert records the usage of the APIs during the minute of the given points.
+func (t *APIUsageTracker) Insert(pnts map[APIConsumer]DataPoint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for consumer, pnt := range pnts {
+		t.window[consumer] = append(t.window[consumer], pnt)
+
+		t.pending = append(t.pending, APIUsagePoint{
+			Timestamp:           pnt.Timestamp,
+			API:                 consumer.API,
+			Groups:              splitGroups(consumer.Groups),
+			Requests:            pnt.Requests,
+			RequestErrors:       pnt.RequestErrs,
+			RequestClientErrors: pnt.RequestClientErrs,
+			ResponseTimeSum:     pnt.ResponseTimeSum,
+			ResponseTimeCount:   pnt.ResponseTimeCount,
+		})
+	}
+
+	if len(t.pending) > maxPendingAPIUsagePoints {
+		t.pending = t.pending[len(t.pending)-maxPendingAPIUsagePoints:]
+	}
+
+	t.evict()
+}
+
+// TakePending returns the points not sent to the platform yet. They must be given back with RestorePending if they
+// can't be sent.
+func (t *APIUsageTracker) TakePending() []APIUsagePoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pending := t.pending
+	t.pending = nil
+
+	return pending
+}
+
+// RestorePending gives back points that couldn't be sent, so they are sent with the next ones.
+func (t *APIUsageTracker) RestorePending(pnts []APIUsagePoint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending = append(pnts, t.pending...)
+	if len(t.pending) > maxPendingAPIUsagePoints {
+		t.pending = t.pending[len(t.pending)-maxPendingAPIUsagePoints:]
+	}
+}
+
+// Usages returns the usages of the last hour, sorted by API and groups.
+func (t *APIUsageTracker) Usages() []APIUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.evict()
+
+	usages := make([]APIUsage, 0, len(t.window))
+	for consumer, pnts := range t.window {
+		pnt := pnts.Aggregate()
+
+		usages = append(usages, APIUsage{
+			API:                 consumer.API,
+			Groups:              splitGroups(consumer.Groups),
+			Requests:            pnt.Requests,
+			RequestErrors:       pnt.RequestErrs,
+			RequestClientErrors: pnt.RequestClientErrs,
+			ErrorRate:           pnt.RequestErrPercent,
+			ClientErrorRate:     pnt.RequestClientErrPercent,
+			AvgResponseTime:     pnt.AvgResponseTime,
+			ResponseTimeP95:     pnt.ResponseTimeP95,
+		})
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].API != usages[j].API {
+			return usages[i].API < usages[j].API
+		}
+		return strings.Join(usages[i].Groups, ",") < strings.Join(usages[j].Groups, ",")
+	})
+
+	return usages
+}
+
+// evict drops the points older than the usage window. It must be called with the lock held.
+func (t *APIUsageTracker) evict() {
+	oldest := t.nowFunc().Add(-apiUsageWindow).Unix()
+
+	for consumer, pnts := range t.window {
+		i := sort.Search(len(pnts), func(i int) bool { return pnts[i].Timestamp > oldest })
+		if i == len(pnts) {
+			delete(t.window, consumer)
+			continue
+		}
+		t.window[consumer] = pnts[i:]
+	}
+}
+
+func splitGroups(groups string) []string {
+	if groups == "" {
+		return []string{}
+	}
+
+	return strings.Split(groups, ",")
+}
+
+// apiRouters returns the consumers of the APIs served by each Traefik router of the Ingresses and IngressRoutes
+// created by the API gateways. Routers are keyed by their name, without the entry point prefix Traefik may add.
+func apiRouters(cluster *state.Cluster) map[string]APIConsumer {
+	routers := make(map[string]APIConsumer)
+
+	for _, ing := range cluster.Ingresses {
+		groups, ok := apiRouteGroups(ing.IngressMeta)
+		if !ok {
+			continue
+		}
+
+		for _, rule := range ing.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+
+			for _, path := range rule.HTTP.Paths {
+				api, ok := apiOfPath(cluster.APIs, ing.Namespace, path.Path)
+				if !ok {
+					continue
+				}
+
+				consumer := APIConsumer{API: api, Groups: groups}
+
+				// Traefik 2.8+ names the routers after the namespace then the name of the Ingress, older versions
+				// the other way around.
+				routers[normalizeRouter(ing.Namespace, ing.Name, rule.Host, path.Path)+"@kubernetes"] = consumer
+				routers[normalizeRouter(ing.Name, ing.Namespace, rule.Host, path.Path)+"@kubernetes"] = consumer
+			}
+		}
+	}
+
+	for _, ingressRoute := range cluster.IngressRoutes {
+		groups, ok := apiRouteGroups(ingressRoute.IngressMeta)
+		if !ok {
+			continue
+		}
+
+		for _, route := range ingressRoute.Routes {
+			matches := pathPrefixRe.FindStringSubmatch(route.Match)
+			if matches == nil {
+				continue
+			}
+
+			api, ok := apiOfPath(cluster.APIs, ingressRoute.Namespace, matches[1])
+			if !ok {
+				continue
+			}
+
+			// IngressRoute routers are named after the IngressRoute and the hash of the rule of the route.
+			hash := fmt.Sprintf("%.10x", sha256.Sum256([]byte(route.Match)))
+			routers[normalizeRouter(ingressRoute.Namespace, ingressRoute.Name, hash)+"@kubernetescrd"] = APIConsumer{
+				API:    api,
+				Groups: groups,
+			}
+		}
+	}
+
+	return routers
+}
+
+// apiRouteGroups returns the consumer groups of an Ingress or IngressRoute exposing APIs, if it has been created by an
+// API gateway.
+func apiRouteGroups(meta state.IngressMeta) (string, bool) {
+	if meta.Labels["app.kubernetes.io/managed-by"] != "traefik-hub" {
+		return "", false
+	}
+
+	groups, ok := meta.Annotations[reviewer.AnnotationHubAuthGroup]
+
+	return groups, ok
+}
+
+// apiOfPath returns the API of the given namespace served under the given path. APIs of collections are served under
+// the path prefix of their collection, the API whose path prefix is the longest suffix of the path is returned.
+func apiOfPath(apis map[string]*state.API, namespace, path string) (string, bool) {
+	var (
+		found  string
+		prefix string
+	)
+	for key, api := range apis {
+		if api.Namespace != namespace || api.PathPrefix == "" || !strings.HasSuffix(path, api.PathPrefix) {
+			continue
+		}
+
+		if len(api.PathPrefix) > len(prefix) || (len(api.PathPrefix) == len(prefix) && key < found) {
+			found, prefix = key, api.PathPrefix
+		}
+	}
+
+	return found, found != ""
+}
+
+// normalizeRouter builds a router name from the given parts the way Traefik does.
+func normalizeRouter(parts ...string) string {
+	isSeparator := func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+	}
+
+	return strings.Join(strings.FieldsFunc(strings.Join(parts, "-"), isSeparator), "-")
+}
+
+// guessAPIConsumer returns the consumers of the API served by the router of the metric. The router name may be
+// prefixed by the name of its entry point, which may itself contain "-".
+func guessAPIConsumer(lbls []*dto.LabelPair, routers map[string]APIConsumer) (APIConsumer, bool) {
+	if len(routers) == 0 {
+		return APIConsumer{}, false
+	}
+
+	name := getLabel(lbls, "router")
+	for {
+		if consumer, ok := routers[name]; ok {
+			return consumer, true
+		}
+
+		var found bool
+		if _, name, found = strings.Cut(name, "-"); !found {
+			return APIConsumer{}, false
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// APIUsageConfigMap is the name of the ConfigMap the API usages are published to.
+	APIUsageConfigMap = "hub-api-usage"
+	// APIUsageKey is the key of the API usages in the APIUsageConfigMap.
+	APIUsageKey = "usage.json"
+
+	apiUsagePublishInterval = time.Minute
+)
+
+// APIUsagePublisher publishes the usage of the APIs by their consumers to a ConfigMap, which the dev portals read to
+// show consumers how they use the APIs.
+type APIUsagePublisher struct {
+	client    kclientset.Interface
+	namespace string
+	usages    func() []APIUsage
+}
+
+// NewAPIUsagePublisher returns a new APIUsagePublisher publishing the given usages to a ConfigMap of the given
+// namespace.
+func NewAPIUsagePublisher(client kclientset.Interface, namespace string, usages func() []APIUsage) *APIUsagePublisher {
+	return &APIUsagePublisher{
+		client:    client,
+		namespace: namespace,
+		usages:    usages,
+	}
+}
+
+// Run publishes the API usages every minute until the context is canceled.
+func (p *APIUsagePublisher) Run(ctx context.Context) {
+	tick := time.NewTicker(apiUsagePublishInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-tick.C:
+			if err := p.publish(ctx); err != nil {
+				log.Error().Err(err).Msg("Unable to publish API usage")
+			}
+		}
+	}
+}
+
+func (p *APIUsagePublisher) publish(ctx context.Context) error {
+	raw, err := json.Marshal(p.usages())
+	if err != nil {
+		return fmt.Errorf("marshal API usage: %w", err)
+	}
+
+	cm, err := p.client.CoreV1().ConfigMaps(p.namespace).Get(ctx, APIUsageConfigMap, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      APIUsageConfigMap,
+				Namespace: p.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "traefik-hub",
+				},
+			},
+			Data: map[string]string{APIUsageKey: string(raw)},
+		}
+
+		if _, err = p.client.CoreV1().ConfigMaps(p.namespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create API usage ConfigMap: %w", err)
+		}
+
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get API usage ConfigMap: %w", err)
+	}
+
+	if cm.Data[APIUsageKey] == string(raw) {
+		return nil
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[APIUsageKey] = string(raw)
+
+	if _, err = p.client.CoreV1().ConfigMaps(p.namespace).Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update API usage ConfigMap: %w", err)
+	}
+
+	return nil
+}
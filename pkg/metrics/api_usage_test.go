@@ -0,0 +1,198 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+func TestAPIRouters(t *testing.T) {
+	gatewayMeta := func(groups string) state.IngressMeta {
+		return state.IngressMeta{
+			Labels:      map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"},
+			Annotations: map[string]string{reviewer.AnnotationHubAuthGroup: groups},
+		}
+	}
+
+	cluster := &state.Cluster{
+		APIs: map[string]*state.API{
+			"books@apis":      {Name: "books", Namespace: "apis", PathPrefix: "/books"},
+			"orders@apis":     {Name: "orders", Namespace: "apis", PathPrefix: "/orders"},
+			"no-prefix@apis":  {Name: "no-prefix", Namespace: "apis"},
+			"books@other-ns":  {Name: "books", Namespace: "other-ns", PathPrefix: "/store/books"},
+			"ordered@default": {Name: "ordered", Namespace: "default", PathPrefix: "/ordered"},
+		},
+		Ingresses: map[string]*state.Ingress{
+			"gateway-books@apis.ingress.networking.k8s.io": {
+				ResourceMeta: state.ResourceMeta{Name: "gateway-books", Namespace: "apis"},
+				IngressMeta:  gatewayMeta("supplier,manager"),
+				Rules: []netv1.IngressRule{
+					{
+						Host: "api.example.com",
+						IngressRuleValue: netv1.IngressRuleValue{
+							HTTP: &netv1.HTTPIngressRuleValue{
+								Paths: []netv1.HTTPIngressPath{{Path: "/store/books"}},
+							},
+						},
+					},
+				},
+			},
+			"whoami@default.ingress.networking.k8s.io": {
+				ResourceMeta: state.ResourceMeta{Name: "whoami", Namespace: "default"},
+				Rules: []netv1.IngressRule{
+					{
+						Host: "example.com",
+						IngressRuleValue: netv1.IngressRuleValue{
+							HTTP: &netv1.HTTPIngressRuleValue{
+								Paths: []netv1.HTTPIngressPath{{Path: "/ordered"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		IngressRoutes: map[string]*state.IngressRoute{
+			"gateway-orders@apis.ingressroute.traefik.containo.us": {
+				ResourceMeta: state.ResourceMeta{Name: "gateway-orders", Namespace: "apis"},
+				IngressMeta:  gatewayMeta("supplier"),
+				Routes: []state.Route{
+					{Match: "Host(`api.example.com`) && PathPrefix(`/orders`)"},
+					{Match: "Host(`api.example.com`)"},
+				},
+			},
+		},
+	}
+
+	got := apiRouters(cluster)
+
+	books := APIConsumer{API: "books@apis", Groups: "supplier,manager"}
+	assert.Equal(t, map[string]APIConsumer{
+		"apis-gateway-books-api-example-com-store-books@kubernetes": books,
+		"gateway-books-apis-api-example-com-store-books@kubernetes": books,
+		"apis-gateway-orders-a352f9cc22c58977fdf2@kubernetescrd":    {API: "orders@apis", Groups: "supplier"},
+	}, got)
+}
+
+func TestScraper_ScrapeTraefik_apiMetrics(t *testing.T) {
+	data, err := os.ReadFile("testdata/traefik-api-metrics.txt")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := NewScraper(http.DefaultClient)
+
+	books := APIConsumer{API: "books@apis", Groups: "supplier,manager"}
+	orders := APIConsumer{API: "orders@apis", Groups: "supplier"}
+
+	got, err := s.Scrape(context.Background(), ParserTraefik, srv.URL, ScrapeState{
+		APIRouters: map[string]APIConsumer{
+			"apis-gateway-books-api-example-com-store-books@kubernetes": books,
+			"apis-gateway-orders-a352f9cc22c58977fdf2@kubernetescrd":    orders,
+		},
+	})
+	require.NoError(t, err)
+
+	buckets := Buckets{0.1: 3, 0.3: 4, math.Inf(1): 4}
+	assert.ElementsMatch(t, []Metric{
+		&APIMetric{APIConsumer: books, Metric: &Histogram{Name: MetricRequestDuration, Sum: 0.5, Count: 4, Buckets: buckets}},
+		&APIMetric{APIConsumer: books, Metric: &Counter{Name: MetricRequests, Value: 4}},
+		&APIMetric{APIConsumer: books, Metric: &Counter{Name: MetricRequests, Value: 2}},
+		&APIMetric{APIConsumer: books, Metric: &Counter{Name: MetricRequestClientErrors, Value: 2}},
+		&APIMetric{APIConsumer: orders, Metric: &Counter{Name: MetricRequests, Value: 5}},
+		&APIMetric{APIConsumer: orders, Metric: &Counter{Name: MetricRequests, Value: 1}},
+		&APIMetric{APIConsumer: orders, Metric: &Counter{Name: MetricRequestErrors, Value: 1}},
+	}, got)
+
+	// API metrics are left out of the regular aggregation.
+	assert.Empty(t, Aggregate(got))
+
+	sets := AggregateAPIs(got)
+	require.Len(t, sets, 2)
+	assert.Equal(t, int64(6), sets[books].Requests)
+	assert.Equal(t, int64(2), sets[books].RequestClientErrors)
+	assert.Equal(t, int64(4), sets[books].RequestDuration.Count)
+	assert.Equal(t, int64(6), sets[orders].Requests)
+	assert.Equal(t, int64(1), sets[orders].RequestErrors)
+}
+
+func TestAPIUsageTracker(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker := NewAPIUsageTracker()
+	tracker.nowFunc = func() time.Time { return now }
+
+	books := APIConsumer{API: "books@apis", Groups: "supplier,manager"}
+	orders := APIConsumer{API: "orders@apis", Groups: ""}
+
+	tracker.Insert(map[APIConsumer]DataPoint{
+		books: {Timestamp: now.Add(-2 * time.Hour).Unix(), Seconds: 60, Requests: 100},
+	})
+	tracker.Insert(map[APIConsumer]DataPoint{
+		books:  {Timestamp: now.Add(-2 * time.Minute).Unix(), Seconds: 60, Requests: 10, RequestErrs: 1, ResponseTimeSum: 2, ResponseTimeCount: 10},
+		orders: {Timestamp: now.Add(-2 * time.Minute).Unix(), Seconds: 60, Requests: 4, RequestClientErrs: 2},
+	})
+	tracker.Insert(map[APIConsumer]DataPoint{
+		books: {Timestamp: now.Add(-time.Minute).Unix(), Seconds: 60, Requests: 10, RequestErrs: 1, ResponseTimeSum: 4, ResponseTimeCount: 10},
+	})
+
+	// The point older than an hour is left out.
+	usages := tracker.Usages()
+	require.Len(t, usages, 2)
+
+	assert.Equal(t, "books@apis", usages[0].API)
+	assert.Equal(t, []string{"supplier", "manager"}, usages[0].Groups)
+	assert.Equal(t, int64(20), usages[0].Requests)
+	assert.Equal(t, int64(2), usages[0].RequestErrors)
+	assert.InDelta(t, 0.1, usages[0].ErrorRate, 0.0001)
+	assert.InDelta(t, 0.3, usages[0].AvgResponseTime, 0.0001)
+
+	assert.Equal(t, "orders@apis", usages[1].API)
+	assert.Equal(t, []string{}, usages[1].Groups)
+	assert.Equal(t, int64(2), usages[1].RequestClientErrors)
+	assert.InDelta(t, 0.5, usages[1].ClientErrorRate, 0.0001)
+
+	// All points are pending until sent, and given back when they couldn't be.
+	pending := tracker.TakePending()
+	assert.Len(t, pending, 4)
+	assert.Empty(t, tracker.TakePending())
+
+	tracker.RestorePending(pending)
+	tracker.Insert(map[APIConsumer]DataPoint{
+		orders: {Timestamp: now.Unix(), Seconds: 60, Requests: 1},
+	})
+
+	pending = tracker.TakePending()
+	require.Len(t, pending, 5)
+	assert.Equal(t, now.Add(-2*time.Hour).Unix(), pending[0].Timestamp)
+	assert.Equal(t, now.Unix(), pending[4].Timestamp)
+}
@@ -20,11 +20,13 @@ package metrics
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"sync"
 
 	"github.com/hamba/avro"
 	"github.com/traefik/hub-agent-kubernetes/pkg/metrics/protocol"
@@ -38,7 +40,8 @@ type Client struct {
 
 	metricsSchema avro.Schema
 
-	token string
+	tokenMu sync.RWMutex
+	token   string
 }
 
 // NewClient creates a token service client.
@@ -61,6 +64,21 @@ func NewClient(client *http.Client, baseURL, token string) (*Client, error) {
 	}, nil
 }
 
+// SetToken sets the token used to authenticate the calls to the metrics service, e.g. once it's been rotated.
+func (c *Client) SetToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.token = token
+}
+
+func (c *Client) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+
+	return c.token
+}
+
 // GetPreviousData gets the agent configuration.
 func (c *Client) GetPreviousData(ctx context.Context) (map[string][]DataPointGroup, error) {
 	endpoint, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "data"))
@@ -135,6 +153,41 @@ func (c *Client) Send(ctx context.Context, data map[string][]DataPointGroup) err
 	return nil
 }
 
+// SendAPIUsage sends the usage of the APIs by their consumers to the metrics service.
+func (c *Client) SendAPIUsage(ctx context.Context, pnts []APIUsagePoint) error {
+	endpoint, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "api-usage"))
+	if err != nil {
+		return fmt.Errorf("creating API usage url: %w", err)
+	}
+
+	raw, err := json.Marshal(pnts)
+	if err != nil {
+		return fmt.Errorf("marshaling API usage: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	c.setAuthHeader(req)
+	req.Header.Set("Content-Type", "application/json")
+	version.SetUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending API usage: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("sending API usage got %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
 func (c *Client) setAuthHeader(req *http.Request) {
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 }
@@ -0,0 +1,126 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+)
+
+// findRenames returns the Ingresses and IngressRoutes of prev renamed in next, indexed by their previous name.
+// An Ingress or IngressRoute is considered renamed when it is replaced by a single new one having the same identity,
+// that is routing the same hosts and paths to the same services. Ambiguous renames are ignored.
+func findRenames(prev, next *state.Cluster) map[string]string {
+	prevIdentities := identities(prev)
+	nextIdentities := identities(next)
+
+	removed := make(map[string][]string)
+	for name, identity := range prevIdentities {
+		if _, ok := nextIdentities[name]; !ok {
+			removed[identity] = append(removed[identity], name)
+		}
+	}
+
+	added := make(map[string][]string)
+	for name, identity := range nextIdentities {
+		if _, ok := prevIdentities[name]; !ok {
+			added[identity] = append(added[identity], name)
+		}
+	}
+
+	renames := make(map[string]string)
+	for identity, oldNames := range removed {
+		newNames := added[identity]
+		if len(oldNames) != 1 || len(newNames) != 1 {
+			continue
+		}
+
+		renames[oldNames[0]] = newNames[0]
+	}
+
+	return renames
+}
+
+// identities returns the identity of the Ingresses and IngressRoutes of the given cluster, indexed by their name.
+// Ingresses and IngressRoutes without any route have no identity.
+func identities(cluster *state.Cluster) map[string]string {
+	ids := make(map[string]string)
+	if cluster == nil {
+		return ids
+	}
+
+	for name, ingress := range cluster.Ingresses {
+		if id := ingressIdentity(ingress); id != "" {
+			ids[name] = id
+		}
+	}
+
+	for name, ingressRoute := range cluster.IngressRoutes {
+		if id := ingressRouteIdentity(ingressRoute); id != "" {
+			ids[name] = id
+		}
+	}
+
+	return ids
+}
+
+func ingressIdentity(ingress *state.Ingress) string {
+	var routes []string
+	if backend := ingress.DefaultBackend; backend != nil && backend.Service != nil {
+		routes = append(routes, "*||"+backend.Service.Name+"@"+ingress.Namespace)
+	}
+
+	for _, rule := range ingress.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+
+			routes = append(routes, rule.Host+"|"+path.Path+"|"+path.Backend.Service.Name+"@"+ingress.Namespace)
+		}
+	}
+
+	return identity("ingress", ingress.Namespace, routes)
+}
+
+func ingressRouteIdentity(ingressRoute *state.IngressRoute) string {
+	var routes []string
+	for _, route := range ingressRoute.Routes {
+		for _, service := range route.Services {
+			routes = append(routes, route.Match+"|"+service.Name+"@"+service.Namespace)
+		}
+	}
+
+	return identity("ingressroute", ingressRoute.Namespace, routes)
+}
+
+func identity(kind, namespace string, routes []string) string {
+	if len(routes) == 0 {
+		return ""
+	}
+
+	sort.Strings(routes)
+
+	return kind + "/" + namespace + "/" + strings.Join(routes, ",")
+}
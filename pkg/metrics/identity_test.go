@@ -0,0 +1,130 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+func TestFindRenames(t *testing.T) {
+	ingress := func(name, host, path, service string) *state.Ingress {
+		return &state.Ingress{
+			ResourceMeta: state.ResourceMeta{Kind: "Ingress", Group: "networking.k8s.io", Name: name, Namespace: "ns"},
+			Rules: []netv1.IngressRule{{
+				Host: host,
+				IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+					Paths: []netv1.HTTPIngressPath{{
+						Path:    path,
+						Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: service}},
+					}},
+				}},
+			}},
+		}
+	}
+	ingressRoute := func(name, match, service string) *state.IngressRoute {
+		return &state.IngressRoute{
+			ResourceMeta: state.ResourceMeta{Kind: "IngressRoute", Group: "traefik.containo.us", Name: name, Namespace: "ns"},
+			Routes: []state.Route{{
+				Match:    match,
+				Services: []state.RouteService{{Namespace: "ns", Name: service}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		desc string
+		prev *state.Cluster
+		next *state.Cluster
+		want map[string]string
+	}{
+		{
+			desc: "renamed Ingress",
+			prev: &state.Cluster{Ingresses: map[string]*state.Ingress{
+				"old@ns.ingress.networking.k8s.io":  ingress("old", "example.com", "/api", "whoami"),
+				"kept@ns.ingress.networking.k8s.io": ingress("kept", "example.com", "/", "front"),
+			}},
+			next: &state.Cluster{Ingresses: map[string]*state.Ingress{
+				"new@ns.ingress.networking.k8s.io":  ingress("new", "example.com", "/api", "whoami"),
+				"kept@ns.ingress.networking.k8s.io": ingress("kept", "example.com", "/", "front"),
+			}},
+			want: map[string]string{"old@ns.ingress.networking.k8s.io": "new@ns.ingress.networking.k8s.io"},
+		},
+		{
+			desc: "renamed IngressRoute",
+			prev: &state.Cluster{IngressRoutes: map[string]*state.IngressRoute{
+				"old@ns.ingressroute.traefik.containo.us": ingressRoute("old", "Host(`example.com`)", "whoami"),
+			}},
+			next: &state.Cluster{IngressRoutes: map[string]*state.IngressRoute{
+				"new@ns.ingressroute.traefik.containo.us": ingressRoute("new", "Host(`example.com`)", "whoami"),
+			}},
+			want: map[string]string{"old@ns.ingressroute.traefik.containo.us": "new@ns.ingressroute.traefik.containo.us"},
+		},
+		{
+			desc: "replaced by an Ingress routing another path",
+			prev: &state.Cluster{Ingresses: map[string]*state.Ingress{
+				"old@ns.ingress.networking.k8s.io": ingress("old", "example.com", "/api", "whoami"),
+			}},
+			next: &state.Cluster{Ingresses: map[string]*state.Ingress{
+				"new@ns.ingress.networking.k8s.io": ingress("new", "example.com", "/v2", "whoami"),
+			}},
+			want: map[string]string{},
+		},
+		{
+			desc: "ambiguous rename",
+			prev: &state.Cluster{Ingresses: map[string]*state.Ingress{
+				"old@ns.ingress.networking.k8s.io": ingress("old", "example.com", "/api", "whoami"),
+			}},
+			next: &state.Cluster{Ingresses: map[string]*state.Ingress{
+				"new-1@ns.ingress.networking.k8s.io": ingress("new-1", "example.com", "/api", "whoami"),
+				"new-2@ns.ingress.networking.k8s.io": ingress("new-2", "example.com", "/api", "whoami"),
+			}},
+			want: map[string]string{},
+		},
+		{
+			desc: "Ingress replaced by an IngressRoute",
+			prev: &state.Cluster{Ingresses: map[string]*state.Ingress{
+				"old@ns.ingress.networking.k8s.io": ingress("old", "example.com", "/api", "whoami"),
+			}},
+			next: &state.Cluster{IngressRoutes: map[string]*state.IngressRoute{
+				"new@ns.ingressroute.traefik.containo.us": ingressRoute("new", "Host(`example.com`) && PathPrefix(`/api`)", "whoami"),
+			}},
+			want: map[string]string{},
+		},
+		{
+			desc: "initial state",
+			prev: &state.Cluster{},
+			next: &state.Cluster{Ingresses: map[string]*state.Ingress{
+				"new@ns.ingress.networking.k8s.io": ingress("new", "example.com", "/api", "whoami"),
+			}},
+			want: map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, findRenames(test.prev, test.next))
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
 )
 
@@ -36,12 +37,22 @@ type Manager struct {
 	client     *Client
 	traefikURL string
 	scraper    *Scraper
+	exporters  []Exporter
+	rejections *RejectionMetrics
+	apiUsage   *APIUsageTracker
 
 	sendMu     sync.Mutex
 	sendIntvl  time.Duration
 	sendTables []string
 
+	// sending serializes the sends of the sender and the final flush.
+	sending sync.Mutex
+	// started is whether the manager has been run, in which case it has metrics to flush.
+	started atomic.Bool
+
 	state atomic.Value
+
+	nowFunc func() time.Time
 }
 
 // NewManager returns a manager.
@@ -54,12 +65,38 @@ func NewManager(client *Client, traefikURL string, store *Store, scraper *Scrape
 		client:     client,
 		traefikURL: traefikURL,
 		scraper:    scraper,
+		apiUsage:   NewAPIUsageTracker(),
 		sendIntvl:  time.Minute,
 		sendTables: []string{"1m", "10m", "1h", "1d"},
 		state:      st,
+		nowFunc:    time.Now,
 	}
 }
 
+// SetClock sets the clock used to timestamp the data points of the manager and its store, e.g. to compensate a
+// skew with the platform clock. It must be called before running the manager.
+func (m *Manager) SetClock(now func() time.Time) {
+	m.nowFunc = now
+	m.store.nowFunc = now
+}
+
+// AddExporter adds an exporter the data points are exported to, in addition to being sent to the platform. It must
+// be called before running the manager.
+func (m *Manager) AddExporter(exporter Exporter) {
+	m.exporters = append(m.exporters, exporter)
+}
+
+// SetRejectionMetrics sets the collectors the requests rejected by the gateways are reported to. It must be called
+// before running the manager.
+func (m *Manager) SetRejectionMetrics(rejections *RejectionMetrics) {
+	m.rejections = rejections
+}
+
+// APIUsages returns the usage of the APIs by their consumers during the last hour.
+func (m *Manager) APIUsages() []APIUsage {
+	return m.apiUsage.Usages()
+}
+
 // SetConfig updates the configuration of the metrics manager.
 func (m *Manager) SetConfig(sendInterval time.Duration, sendTables []string) {
 	m.sendMu.Lock()
@@ -75,7 +112,13 @@ func (m *Manager) TopologyStateChanged(_ context.Context, cluster *state.Cluster
 		return
 	}
 
-	m.state.Store(cluster)
+	prev := m.state.Swap(cluster).(*state.Cluster)
+
+	// Keep the series of renamed Ingresses and IngressRoutes going, so their history isn't lost.
+	for from, to := range findRenames(prev, cluster) {
+		log.Info().Str("from", from).Str("to", to).Msg("Moving metrics of renamed ingress")
+		m.store.Rename(from, to)
+	}
 }
 
 // Run runs the metrics manager. This is a blocking method.
@@ -91,6 +134,8 @@ func (m *Manager) Run(ctx context.Context) error {
 		}
 	}
 
+	m.started.Store(true)
+
 	go m.startScraper(ctx)
 	go m.runSender(ctx)
 
@@ -127,9 +172,34 @@ func (m *Manager) getSendTables() []string {
 	return m.sendTables
 }
 
+// Flush sends the data points not sent yet. It's called once the manager is stopped, so the last metrics aren't lost on
+// shutdown.
+func (m *Manager) Flush(ctx context.Context) error {
+	if !m.started.Load() {
+		return nil
+	}
+
+	log.Info().Msg("Flushing pending metrics")
+	if err := m.send(ctx, m.getSendTables()); err != nil {
+		return fmt.Errorf("send metrics: %w", err)
+	}
+
+	return nil
+}
+
 func (m *Manager) send(ctx context.Context, tbls []string) error {
+	m.sending.Lock()
+	defer m.sending.Unlock()
+
 	m.store.RollUp()
 
+	if pnts := m.apiUsage.TakePending(); len(pnts) > 0 {
+		if err := m.client.SendAPIUsage(ctx, pnts); err != nil {
+			m.apiUsage.RestorePending(pnts)
+			log.Error().Err(err).Msg("Unable to send API usage")
+		}
+	}
+
 	toSend := make(map[string][]DataPointGroup)
 	tblMarks := make(map[string]WaterMarks)
 	for _, name := range tbls {
@@ -162,15 +232,15 @@ func (m *Manager) send(ctx context.Context, tbls []string) error {
 }
 
 func (m *Manager) startScraper(ctx context.Context) {
-	mtrcs, err := m.scraper.Scrape(ctx, ParserTraefik, m.traefikURL, ScrapeState{
-		Ingresses: m.getIngresses(),
-	})
+	mtrcs, err := m.scrape(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("Unable to scrape metrics")
 		return
 	}
 
 	ref := Aggregate(mtrcs)
+	apiRef := AggregateAPIs(mtrcs)
+	m.rejections.observe(mtrcs)
 
 	tick := time.NewTicker(scrapeInterval)
 	defer tick.Stop()
@@ -182,17 +252,16 @@ func (m *Manager) startScraper(ctx context.Context) {
 			return
 
 		case <-tick.C:
-			mtrcs, err = m.scraper.Scrape(ctx, ParserTraefik, m.traefikURL, ScrapeState{
-				Ingresses: m.getIngresses(),
-			})
+			mtrcs, err = m.scrape(ctx)
 			if err != nil {
 				log.Error().Err(err).Msg("Unable to scrape metrics")
 				return
 			}
 
 			mtrcSet := Aggregate(mtrcs)
+			m.rejections.observe(mtrcs)
 
-			ts := time.Now().UTC().Truncate(time.Minute).Unix()
+			ts := m.nowFunc().UTC().Truncate(time.Minute).Unix()
 
 			pnts := make(map[SetKey]DataPoint, len(mtrcSet))
 			for key, mtrc := range mtrcSet {
@@ -207,11 +276,72 @@ func (m *Manager) startScraper(ctx context.Context) {
 
 			m.store.Insert(pnts)
 
+			for _, exporter := range m.exporters {
+				if err = exporter.Export(ctx, pnts); err != nil {
+					log.Error().Err(err).Msg("Unable to export metrics")
+				}
+			}
+
 			ref = mtrcSet
+
+			apiMtrcSet := AggregateAPIs(mtrcs)
+			apiPnts := make(map[APIConsumer]DataPoint, len(apiMtrcSet))
+			for consumer, mtrc := range apiMtrcSet {
+				mtrc = mtrc.RelativeTo(apiRef[consumer])
+				if mtrc.Requests == 0 {
+					continue
+				}
+
+				pnt := mtrc.ToDataPoint(scrapeSec)
+				pnt.Timestamp = ts
+				pnt.Seconds = scrapeSec
+
+				apiPnts[consumer] = pnt
+			}
+
+			m.apiUsage.Insert(apiPnts)
+			apiRef = apiMtrcSet
 		}
 	}
 }
 
+// scrape scrapes the metrics of Traefik and of the ingress controllers detected in the cluster. Failing to scrape an
+// ingress controller doesn't prevent getting the metrics of the others.
+func (m *Manager) scrape(ctx context.Context) ([]Metric, error) {
+	scrapeState := ScrapeState{
+		Ingresses:                  m.getIngresses(),
+		Services:                   m.getServices(),
+		ServiceIngresses:           m.getServiceIngresses(),
+		DeprecatedAPIIngressRoutes: m.getAnnotatedIngressRoutes(api.AnnotationDeprecatedAPI),
+		BodyLimitedIngressRoutes:   m.getAnnotatedIngressRoutes(api.AnnotationBodyLimit),
+		APIRouters:                 apiRouters(m.state.Load().(*state.Cluster)),
+	}
+
+	mtrcs, err := m.scraper.Scrape(ctx, ParserTraefik, m.traefikURL, scrapeState)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := m.state.Load().(*state.Cluster)
+	for _, ctrl := range cluster.IngressControllers {
+		for _, metricsURL := range ctrl.MetricsURLs {
+			ctrlMtrcs, err := m.scraper.Scrape(ctx, ctrl.Type, metricsURL, scrapeState)
+			if err != nil {
+				log.Warn().Err(err).
+					Str("name", ctrl.Name).
+					Str("namespace", ctrl.Namespace).
+					Str("type", ctrl.Type).
+					Msg("Unable to scrape ingress controller metrics")
+				continue
+			}
+
+			mtrcs = append(mtrcs, ctrlMtrcs...)
+		}
+	}
+
+	return mtrcs, nil
+}
+
 func (m *Manager) getIngresses() map[string]struct{} {
 	cluster := m.state.Load().(*state.Cluster)
 
@@ -222,3 +352,46 @@ func (m *Manager) getIngresses() map[string]struct{} {
 
 	return ingresses
 }
+
+func (m *Manager) getServices() map[string]struct{} {
+	cluster := m.state.Load().(*state.Cluster)
+
+	services := make(map[string]struct{}, len(cluster.Services))
+	for name := range cluster.Services {
+		services[name] = struct{}{}
+	}
+
+	return services
+}
+
+func (m *Manager) getServiceIngresses() map[string][]IngressRef {
+	cluster := m.state.Load().(*state.Cluster)
+
+	serviceIngresses := make(map[string][]IngressRef)
+	for name, ingress := range cluster.Ingresses {
+		ref := IngressRef{Name: name}
+		if ingress.IngressClassName != nil {
+			ref.Class = *ingress.IngressClassName
+		}
+
+		for _, service := range ingress.Services {
+			serviceIngresses[service] = append(serviceIngresses[service], ref)
+		}
+	}
+
+	return serviceIngresses
+}
+
+// getAnnotatedIngressRoutes returns the IngressRoutes having the given annotation.
+func (m *Manager) getAnnotatedIngressRoutes(annotation string) map[string]struct{} {
+	cluster := m.state.Load().(*state.Cluster)
+
+	ingressRoutes := make(map[string]struct{})
+	for name, ingressRoute := range cluster.IngressRoutes {
+		if _, ok := ingressRoute.Annotations[annotation]; ok {
+			ingressRoutes[name] = struct{}{}
+		}
+	}
+
+	return ingressRoutes
+}
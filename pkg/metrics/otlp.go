@@ -0,0 +1,227 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+	collectormetricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcev1 "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Exporter exports the data points computed by the manager to another backend than the platform.
+type Exporter interface {
+	Export(ctx context.Context, pnts map[SetKey]DataPoint) error
+}
+
+// OTLPExporter exports data points to an OpenTelemetry collector, using OTLP over HTTP.
+type OTLPExporter struct {
+	endpoint   string
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// NewOTLPExporter creates an OTLPExporter sending data points to the given endpoint, such as
+// "http://otel-collector:4318". The "/v1/metrics" path is used when the endpoint has none.
+func NewOTLPExporter(client *http.Client, endpoint string, headers map[string]string) (*OTLPExporter, error) {
+	u, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("only http and https is supported, %s found", u.Scheme)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/metrics"
+	}
+
+	return &OTLPExporter{
+		endpoint:   u.String(),
+		headers:    headers,
+		httpClient: client,
+	}, nil
+}
+
+// Export exports the given data points.
+func (e *OTLPExporter) Export(ctx context.Context, pnts map[SetKey]DataPoint) error {
+	if len(pnts) == 0 {
+		return nil
+	}
+
+	raw, err := proto.Marshal(newOTLPRequest(pnts))
+	if err != nil {
+		return fmt.Errorf("marshal OTLP request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	for name, value := range e.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	version.SetUserAgent(req)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("exporting metrics: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("exporting metrics got %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// newOTLPRequest builds the OTLP request holding the given data points. Data points cover the Seconds preceding their
+// Timestamp, and are exported with a delta temporality.
+func newOTLPRequest(pnts map[SetKey]DataPoint) *collectormetricsv1.ExportMetricsServiceRequest {
+	keys := make([]SetKey, 0, len(pnts))
+	for key := range pnts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].EdgeIngress != keys[j].EdgeIngress {
+			return keys[i].EdgeIngress < keys[j].EdgeIngress
+		}
+		if keys[i].Ingress != keys[j].Ingress {
+			return keys[i].Ingress < keys[j].Ingress
+		}
+		return keys[i].Service < keys[j].Service
+	})
+
+	requests := &metricsv1.Sum{AggregationTemporality: metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, IsMonotonic: true}
+	requestErrs := &metricsv1.Sum{AggregationTemporality: metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, IsMonotonic: true}
+	requestClientErrs := &metricsv1.Sum{AggregationTemporality: metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, IsMonotonic: true}
+	duration := &metricsv1.Histogram{AggregationTemporality: metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA}
+
+	for _, key := range keys {
+		pnt := pnts[key]
+
+		attrs := otlpAttributes(key)
+		start := uint64(pnt.Timestamp-pnt.Seconds) * 1e9
+		end := uint64(pnt.Timestamp) * 1e9
+
+		requests.DataPoints = append(requests.DataPoints, otlpIntDataPoint(attrs, start, end, pnt.Requests))
+		requestErrs.DataPoints = append(requestErrs.DataPoints, otlpIntDataPoint(attrs, start, end, pnt.RequestErrs))
+		requestClientErrs.DataPoints = append(requestClientErrs.DataPoints, otlpIntDataPoint(attrs, start, end, pnt.RequestClientErrs))
+
+		if pnt.ResponseTimeCount == 0 {
+			continue
+		}
+
+		sum := pnt.ResponseTimeSum
+		duration.DataPoints = append(duration.DataPoints, &metricsv1.HistogramDataPoint{
+			Attributes:        attrs,
+			StartTimeUnixNano: start,
+			TimeUnixNano:      end,
+			Count:             uint64(pnt.ResponseTimeCount),
+			Sum:               &sum,
+			BucketCounts:      []uint64{uint64(pnt.ResponseTimeCount)},
+		})
+	}
+
+	return &collectormetricsv1.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricsv1.ResourceMetrics{{
+			Resource: &resourcev1.Resource{
+				Attributes: []*commonv1.KeyValue{
+					otlpStringAttribute("service.name", "traefik-hub-agent"),
+					otlpStringAttribute("service.version", version.Version()),
+				},
+			},
+			ScopeMetrics: []*metricsv1.ScopeMetrics{{
+				Scope: &commonv1.InstrumentationScope{
+					Name:    "github.com/traefik/hub-agent-kubernetes/pkg/metrics",
+					Version: version.Version(),
+				},
+				Metrics: []*metricsv1.Metric{
+					{
+						Name:        "hub.requests",
+						Description: "Number of requests.",
+						Unit:        "{request}",
+						Data:        &metricsv1.Metric_Sum{Sum: requests},
+					},
+					{
+						Name:        "hub.request.errors",
+						Description: "Number of requests which got a server error response.",
+						Unit:        "{request}",
+						Data:        &metricsv1.Metric_Sum{Sum: requestErrs},
+					},
+					{
+						Name:        "hub.request.client_errors",
+						Description: "Number of requests which got a client error response.",
+						Unit:        "{request}",
+						Data:        &metricsv1.Metric_Sum{Sum: requestClientErrs},
+					},
+					{
+						Name:        "hub.request.duration",
+						Description: "Duration of the requests.",
+						Unit:        "s",
+						Data:        &metricsv1.Metric_Histogram{Histogram: duration},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func otlpAttributes(key SetKey) []*commonv1.KeyValue {
+	var attrs []*commonv1.KeyValue
+	if key.EdgeIngress != "" {
+		attrs = append(attrs, otlpStringAttribute("edge_ingress", key.EdgeIngress))
+	}
+	if key.Ingress != "" {
+		attrs = append(attrs, otlpStringAttribute("ingress", key.Ingress))
+	}
+	if key.Service != "" {
+		attrs = append(attrs, otlpStringAttribute("service", key.Service))
+	}
+
+	return attrs
+}
+
+func otlpIntDataPoint(attrs []*commonv1.KeyValue, start, end uint64, value int64) *metricsv1.NumberDataPoint {
+	return &metricsv1.NumberDataPoint{
+		Attributes:        attrs,
+		StartTimeUnixNano: start,
+		TimeUnixNano:      end,
+		Value:             &metricsv1.NumberDataPoint_AsInt{AsInt: value},
+	}
+}
+
+func otlpStringAttribute(key, value string) *commonv1.KeyValue {
+	return &commonv1.KeyValue{
+		Key:   key,
+		Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: value}},
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
+	collectormetricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestOTLPExporter_Export(t *testing.T) {
+	var got collectormetricsv1.ExportMetricsServiceRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/metrics", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer collector-token", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(body, &got))
+	}))
+	t.Cleanup(srv.Close)
+
+	exporter, err := metrics.NewOTLPExporter(http.DefaultClient, srv.URL, map[string]string{
+		"Authorization": "Bearer collector-token",
+	})
+	require.NoError(t, err)
+
+	err = exporter.Export(context.Background(), map[metrics.SetKey]metrics.DataPoint{
+		{Ingress: "ing@ns", Service: "svc@ns"}: {
+			Timestamp:         120,
+			Seconds:           60,
+			Requests:          10,
+			RequestErrs:       2,
+			RequestClientErrs: 1,
+			ResponseTimeSum:   4,
+			ResponseTimeCount: 10,
+		},
+		{Service: "other@ns"}: {
+			Timestamp: 120,
+			Seconds:   60,
+			Requests:  3,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, got.ResourceMetrics, 1)
+	require.Len(t, got.ResourceMetrics[0].ScopeMetrics, 1)
+
+	mtrcs := make(map[string]*metricsv1.Metric)
+	for _, mtrc := range got.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		mtrcs[mtrc.Name] = mtrc
+	}
+
+	requests := mtrcs["hub.requests"].GetSum()
+	require.NotNil(t, requests)
+	assert.Equal(t, metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, requests.AggregationTemporality)
+	require.Len(t, requests.DataPoints, 2)
+
+	// Data points are sorted by edge ingress, ingress and service.
+	assert.Equal(t, int64(3), requests.DataPoints[0].GetAsInt())
+
+	pnt := requests.DataPoints[1]
+	assert.Equal(t, int64(10), pnt.GetAsInt())
+	assert.Equal(t, uint64(60e9), pnt.StartTimeUnixNano)
+	assert.Equal(t, uint64(120e9), pnt.TimeUnixNano)
+	require.Len(t, pnt.Attributes, 2)
+	assert.Equal(t, "ingress", pnt.Attributes[0].Key)
+	assert.Equal(t, "ing@ns", pnt.Attributes[0].Value.GetStringValue())
+	assert.Equal(t, "service", pnt.Attributes[1].Key)
+	assert.Equal(t, "svc@ns", pnt.Attributes[1].Value.GetStringValue())
+
+	assert.Equal(t, int64(2), mtrcs["hub.request.errors"].GetSum().DataPoints[1].GetAsInt())
+	assert.Equal(t, int64(1), mtrcs["hub.request.client_errors"].GetSum().DataPoints[1].GetAsInt())
+
+	duration := mtrcs["hub.request.duration"].GetHistogram()
+	require.NotNil(t, duration)
+	require.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, uint64(10), duration.DataPoints[0].Count)
+	assert.Equal(t, 4.0, duration.DataPoints[0].GetSum())
+}
+
+func TestOTLPExporter_Export_error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("invalid metrics"))
+	}))
+	t.Cleanup(srv.Close)
+
+	exporter, err := metrics.NewOTLPExporter(http.DefaultClient, srv.URL+"/custom/metrics", nil)
+	require.NoError(t, err)
+
+	err = exporter.Export(context.Background(), map[metrics.SetKey]metrics.DataPoint{
+		{Service: "svc@ns"}: {Timestamp: 120, Seconds: 60, Requests: 1},
+	})
+	assert.EqualError(t, err, "exporting metrics got 400: invalid metrics")
+}
+
+func TestNewOTLPExporter(t *testing.T) {
+	tests := []struct {
+		desc     string
+		endpoint string
+		wantErr  bool
+	}{
+		{
+			desc:     "base URL",
+			endpoint: "http://otel-collector:4318",
+		},
+		{
+			desc:     "URL with path",
+			endpoint: "https://otel-collector:4318/custom/metrics",
+		},
+		{
+			desc:     "unsupported scheme",
+			endpoint: "grpc://otel-collector:4317",
+			wantErr:  true,
+		},
+		{
+			desc:     "invalid URL",
+			endpoint: "otel-collector",
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := metrics.NewOTLPExporter(http.DefaultClient, test.endpoint, nil)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -18,6 +18,8 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package metrics
 
 import (
+	"net/http"
+	"strconv"
 	"strings"
 
 	dto "github.com/prometheus/client_model/go"
@@ -62,8 +64,15 @@ func (p TraefikParser) parseRouterRequestDuration(metrics []*dto.Metric, state S
 			continue
 		}
 
+		if consumer, ok := guessAPIConsumer(metric.Label, state.APIRouters); ok {
+			apiHist := *hist
+			apiHist.Name = MetricRequestDuration
+			enrichedMetrics = append(enrichedMetrics, &APIMetric{APIConsumer: consumer, Metric: &apiHist})
+		}
+
 		edgeIngress := p.guessEdgeIngress(metric.Label, state)
-		if edgeIngress == "" {
+		ingressRoute := p.guessIngressRoute(metric.Label, state.DeprecatedAPIIngressRoutes)
+		if edgeIngress == "" && ingressRoute == "" {
 			continue
 		}
 
@@ -71,6 +80,7 @@ func (p TraefikParser) parseRouterRequestDuration(metrics []*dto.Metric, state S
 		// router will deliver the traffic, not the leaf node of the service tree (e.g. load-balancer, wrr).
 		hist.Name = MetricRequestDuration
 		hist.EdgeIngress = edgeIngress
+		hist.Ingress = ingressRoute
 
 		enrichedMetrics = append(enrichedMetrics, hist)
 	}
@@ -87,8 +97,37 @@ func (p TraefikParser) parseRouterRequestTotal(metrics []*dto.Metric, state Scra
 			continue
 		}
 
+		if consumer, ok := guessAPIConsumer(metric.Label, state.APIRouters); ok {
+			enrichedMetrics = append(enrichedMetrics, &APIMetric{
+				APIConsumer: consumer,
+				Metric:      &Counter{Name: MetricRequests, Value: counter},
+			})
+
+			if metricErrorName := getMetricErrorName(metric.Label, "code"); metricErrorName != "" {
+				enrichedMetrics = append(enrichedMetrics, &APIMetric{
+					APIConsumer: consumer,
+					Metric:      &Counter{Name: metricErrorName, Value: counter},
+				})
+			}
+		}
+
 		edgeIngress := p.guessEdgeIngress(metric.Label, state)
-		if edgeIngress == "" {
+
+		// Requests rejected for exceeding a body limit are counted for any IngressRoute limiting the request bodies.
+		if getLabel(metric.Label, "code") == strconv.Itoa(http.StatusRequestEntityTooLarge) {
+			bodyLimitedRoute := p.guessIngressRoute(metric.Label, state.BodyLimitedIngressRoutes)
+			if edgeIngress != "" || bodyLimitedRoute != "" {
+				enrichedMetrics = append(enrichedMetrics, &Counter{
+					Name:        MetricRequestsTooLarge,
+					EdgeIngress: edgeIngress,
+					Ingress:     bodyLimitedRoute,
+					Value:       counter,
+				})
+			}
+		}
+
+		ingressRoute := p.guessIngressRoute(metric.Label, state.DeprecatedAPIIngressRoutes)
+		if edgeIngress == "" && ingressRoute == "" {
 			continue
 		}
 
@@ -97,6 +136,7 @@ func (p TraefikParser) parseRouterRequestTotal(metrics []*dto.Metric, state Scra
 		enrichedMetrics = append(enrichedMetrics, &Counter{
 			Name:        MetricRequests,
 			EdgeIngress: edgeIngress,
+			Ingress:     ingressRoute,
 			Value:       counter,
 		})
 
@@ -107,6 +147,7 @@ func (p TraefikParser) parseRouterRequestTotal(metrics []*dto.Metric, state Scra
 		enrichedMetrics = append(enrichedMetrics, &Counter{
 			Name:        metricErrorName,
 			EdgeIngress: edgeIngress,
+			Ingress:     ingressRoute,
 			Value:       counter,
 		})
 	}
@@ -162,6 +203,34 @@ func (p TraefikParser) guessEdgeIngress(lbls []*dto.LabelPair, state ScrapeState
 	return ""
 }
 
+// guessIngressRoute returns the IngressRoute, among the given ones, the router has been built from.
+func (p TraefikParser) guessIngressRoute(lbls []*dto.LabelPair, ingressRoutes map[string]struct{}) string {
+	name := getLabel(lbls, "router")
+
+	name, typ, ok := strings.Cut(name, "@")
+	if !ok || typ != "kubernetescrd" {
+		return ""
+	}
+
+	for ingressRouteName := range ingressRoutes {
+		// Remove the `.kind.group` from the namespace.
+		key, _, _ := strings.Cut(ingressRouteName, ".")
+
+		routeName, routeNamespace, ok := strings.Cut(key, "@")
+		if !ok {
+			continue
+		}
+
+		// The name of IngressRoute routers follows the following rule:
+		//     [entrypointName-]ingressRouteNamespace-ingressRouteName-hash@kubernetescrd
+		if strings.Contains(name, routeNamespace+"-"+routeName+"-") {
+			return ingressRouteName
+		}
+	}
+
+	return ""
+}
+
 func getMetricErrorName(lbls []*dto.LabelPair, statusName string) string {
 	status := getLabel(lbls, statusName)
 	if status == "" {
@@ -0,0 +1,183 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package metrics
+
+import (
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// HAProxyParser parses HAProxy metrics into a common form.
+// HAProxy backends are attributed to the services they are built from: HAProxy ingress controllers name them
+// `namespace_service_port`, or `namespace-service-port` for older versions.
+type HAProxyParser struct{}
+
+// NewHAProxyParser returns an HAProxy metrics parser.
+func NewHAProxyParser() HAProxyParser {
+	return HAProxyParser{}
+}
+
+// Parse parses metrics into a common form.
+func (p HAProxyParser) Parse(m *dto.MetricFamily, state ScrapeState) []Metric {
+	if m == nil || m.Name == nil {
+		return nil
+	}
+
+	var metrics []Metric
+	switch *m.Name {
+	case "haproxy_backend_http_requests_total":
+		metrics = append(metrics, p.parseBackendRequests(m.Metric, state)...)
+
+	case "haproxy_backend_http_responses_total":
+		metrics = append(metrics, p.parseBackendResponses(m.Metric, state)...)
+
+	case "haproxy_backend_total_time_average_seconds":
+		metrics = append(metrics, p.parseBackendTotalTime(m.Metric, state)...)
+	}
+
+	return metrics
+}
+
+func (p HAProxyParser) parseBackendRequests(metrics []*dto.Metric, state ScrapeState) []Metric {
+	var enrichedMetrics []Metric
+
+	for _, metric := range metrics {
+		counter := CounterFromMetric(metric)
+		if counter == 0 {
+			continue
+		}
+
+		service := p.guessService(metric.Label, state)
+		if service == "" {
+			continue
+		}
+
+		enrichedMetrics = append(enrichedMetrics, &Counter{
+			Name:    MetricRequests,
+			Service: service,
+			Value:   counter,
+		})
+	}
+
+	return enrichedMetrics
+}
+
+func (p HAProxyParser) parseBackendResponses(metrics []*dto.Metric, state ScrapeState) []Metric {
+	var enrichedMetrics []Metric
+
+	for _, metric := range metrics {
+		counter := CounterFromMetric(metric)
+		if counter == 0 {
+			continue
+		}
+
+		// Responses are counted by class of status code: 1xx, 2xx, 3xx, 4xx, 5xx and other.
+		metricErrorName := getMetricErrorName(metric.Label, "code")
+		if metricErrorName == "" {
+			continue
+		}
+
+		service := p.guessService(metric.Label, state)
+		if service == "" {
+			continue
+		}
+
+		enrichedMetrics = append(enrichedMetrics, &Counter{
+			Name:    metricErrorName,
+			Service: service,
+			Value:   counter,
+		})
+	}
+
+	return enrichedMetrics
+}
+
+func (p HAProxyParser) parseBackendTotalTime(metrics []*dto.Metric, state ScrapeState) []Metric {
+	var enrichedMetrics []Metric
+
+	for _, metric := range metrics {
+		avg := metric.GetGauge().GetValue()
+		if avg == 0 {
+			continue
+		}
+
+		service := p.guessService(metric.Label, state)
+		if service == "" {
+			continue
+		}
+
+		// HAProxy only exposes the average time of the last requests, which is already relative to the scrape interval.
+		enrichedMetrics = append(enrichedMetrics, &Histogram{
+			Name:     MetricRequestDuration,
+			Relative: true,
+			Service:  service,
+			Sum:      avg,
+			Count:    1,
+		})
+	}
+
+	return enrichedMetrics
+}
+
+// guessService returns the service the backend has been built from.
+func (p HAProxyParser) guessService(lbls []*dto.LabelPair, state ScrapeState) string {
+	backend := getLabel(lbls, "proxy")
+	if backend == "" {
+		// Metrics of the HAProxy exporter, used before HAProxy 2.0, identify backends with this label.
+		backend = getLabel(lbls, "backend")
+	}
+
+	if namespace, rest, ok := strings.Cut(backend, "_"); ok {
+		// Neither namespaces nor services can contain underscores.
+		name, _, _ := strings.Cut(rest, "_")
+
+		service := name + "@" + namespace
+		if _, ok = state.Services[service]; ok {
+			return service
+		}
+
+		return ""
+	}
+
+	// Namespaces and services can contain dashes: several services may match, keep the most specific one.
+	var candidates []string
+	for service := range state.Services {
+		name, namespace, ok := strings.Cut(service, "@")
+		if !ok {
+			continue
+		}
+
+		if strings.HasPrefix(backend, namespace+"-"+name+"-") {
+			candidates = append(candidates, service)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) > len(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	return candidates[0]
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package metrics
+
+import (
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// istioIngressClass is the ingress class of the Ingresses served by Istio ingress gateways.
+const istioIngressClass = "istio"
+
+// IstioParser parses the Envoy stats of Istio ingress gateways into a common form.
+// Upstream cluster stats are attributed to the services of the clusters. They are also attributed to the Ingress
+// routing to the service when a single Istio Ingress does, since Envoy doesn't report which route served a request.
+type IstioParser struct{}
+
+// NewIstioParser returns an Istio metrics parser.
+func NewIstioParser() IstioParser {
+	return IstioParser{}
+}
+
+// Parse parses metrics into a common form.
+func (p IstioParser) Parse(m *dto.MetricFamily, state ScrapeState) []Metric {
+	if m == nil || m.Name == nil {
+		return nil
+	}
+
+	var metrics []Metric
+	switch *m.Name {
+	case "envoy_cluster_upstream_rq_total":
+		metrics = append(metrics, p.parseUpstreamRequests(m.Metric, state)...)
+
+	// Istio tags upstream requests with their response code class, Envoy defaults to an _xx suffixed stat.
+	case "envoy_cluster_upstream_rq", "envoy_cluster_upstream_rq_xx":
+		metrics = append(metrics, p.parseUpstreamResponses(m.Metric, state)...)
+
+	case "envoy_cluster_upstream_rq_time":
+		metrics = append(metrics, p.parseUpstreamRequestTime(m.Metric, state)...)
+	}
+
+	return metrics
+}
+
+func (p IstioParser) parseUpstreamRequests(metrics []*dto.Metric, state ScrapeState) []Metric {
+	var enrichedMetrics []Metric
+
+	for _, metric := range metrics {
+		counter := CounterFromMetric(metric)
+		if counter == 0 {
+			continue
+		}
+
+		service := p.guessService(metric.Label, state)
+		if service == "" {
+			continue
+		}
+
+		enrichedMetrics = append(enrichedMetrics, &Counter{
+			Name:    MetricRequests,
+			Ingress: p.guessIngress(service, state),
+			Service: service,
+			Value:   counter,
+		})
+	}
+
+	return enrichedMetrics
+}
+
+func (p IstioParser) parseUpstreamResponses(metrics []*dto.Metric, state ScrapeState) []Metric {
+	var enrichedMetrics []Metric
+
+	for _, metric := range metrics {
+		counter := CounterFromMetric(metric)
+		if counter == 0 {
+			continue
+		}
+
+		// Istio names the code class 5xx, Envoy 5.
+		metricErrorName := getMetricErrorName(metric.Label, "response_code_class")
+		if metricErrorName == "" {
+			metricErrorName = getMetricErrorName(metric.Label, "envoy_response_code_class")
+		}
+		if metricErrorName == "" {
+			continue
+		}
+
+		service := p.guessService(metric.Label, state)
+		if service == "" {
+			continue
+		}
+
+		enrichedMetrics = append(enrichedMetrics, &Counter{
+			Name:    metricErrorName,
+			Ingress: p.guessIngress(service, state),
+			Service: service,
+			Value:   counter,
+		})
+	}
+
+	return enrichedMetrics
+}
+
+func (p IstioParser) parseUpstreamRequestTime(metrics []*dto.Metric, state ScrapeState) []Metric {
+	var enrichedMetrics []Metric
+
+	for _, metric := range metrics {
+		hist := HistogramFromMetric(metric)
+		if hist == nil {
+			continue
+		}
+
+		service := p.guessService(metric.Label, state)
+		if service == "" {
+			continue
+		}
+
+		// Envoy measures request times in milliseconds.
+		hist.Name = MetricRequestDuration
+		hist.Sum /= 1000
+		hist.Buckets = hist.Buckets.scale(1.0 / 1000)
+		hist.Ingress = p.guessIngress(service, state)
+		hist.Service = service
+
+		enrichedMetrics = append(enrichedMetrics, hist)
+	}
+
+	return enrichedMetrics
+}
+
+// guessService returns the service targeted by the upstream cluster.
+func (p IstioParser) guessService(lbls []*dto.LabelPair, state ScrapeState) string {
+	cluster := getLabel(lbls, "cluster_name")
+	if cluster == "" {
+		cluster = getLabel(lbls, "envoy_cluster_name")
+	}
+
+	// Istio names outbound clusters: outbound|port|subset|host, the host being service.namespace.svc.domain.
+	parts := strings.Split(cluster, "|")
+	if len(parts) != 4 || parts[0] != "outbound" {
+		return ""
+	}
+
+	name, rest, ok := strings.Cut(parts[3], ".")
+	if !ok {
+		return ""
+	}
+	namespace, _, _ := strings.Cut(rest, ".")
+
+	service := name + "@" + namespace
+	if _, ok = state.Services[service]; !ok {
+		return ""
+	}
+
+	return service
+}
+
+// guessIngress returns the Istio Ingress routing to the given service, if there's a single one.
+func (p IstioParser) guessIngress(service string, state ScrapeState) string {
+	var ingress string
+	for _, ing := range state.ServiceIngresses[service] {
+		if ing.Class != istioIngressClass {
+			continue
+		}
+		if ingress != "" {
+			return ""
+		}
+
+		ingress = ing.Name
+	}
+
+	return ingress
+}
@@ -0,0 +1,90 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RejectionMetrics holds the Prometheus collectors reporting the requests rejected by the gateways.
+type RejectionMetrics struct {
+	requestsTooLarge *prometheus.CounterVec
+
+	// totals holds the totals of the previous scrape, the scraped counters holding the requests rejected since the
+	// ingress controllers started.
+	totals map[SetKey]uint64
+}
+
+// NewRejectionMetrics creates the rejection collectors and registers them in the given registerer.
+func NewRejectionMetrics(reg prometheus.Registerer) (*RejectionMetrics, error) {
+	m := &RejectionMetrics{
+		requestsTooLarge: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "gateway",
+			Name:      "requests_too_large_total",
+			Help:      "Number of requests rejected for exceeding the maximum request body size, by Ingress or IngressRoute.",
+		}, []string{"ingress"}),
+	}
+
+	if err := reg.Register(m.requestsTooLarge); err != nil {
+		return nil, fmt.Errorf("register requests too large counter: %w", err)
+	}
+
+	return m, nil
+}
+
+// observe reports the requests rejected since the previous scrape. The first scrape only sets the totals the next
+// ones are compared to.
+func (m *RejectionMetrics) observe(mtrcs []Metric) {
+	if m == nil {
+		return
+	}
+
+	totals := make(map[SetKey]uint64)
+	for _, mtrc := range mtrcs {
+		counter, ok := mtrc.(*Counter)
+		if !ok || counter.Name != MetricRequestsTooLarge {
+			continue
+		}
+
+		totals[SetKey{EdgeIngress: counter.EdgeIngress, Ingress: counter.Ingress}] += counter.Value
+	}
+
+	if m.totals != nil {
+		for key, total := range totals {
+			increase := total
+			// A lower total means the ingress controller restarted.
+			if prev, ok := m.totals[key]; ok && prev <= total {
+				increase = total - prev
+			}
+			if increase == 0 {
+				continue
+			}
+
+			name := key.Ingress
+			if name == "" {
+				name = key.EdgeIngress
+			}
+			m.requestsTooLarge.WithLabelValues(name).Add(float64(increase))
+		}
+	}
+
+	m.totals = totals
+}
@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejectionMetrics_observe(t *testing.T) {
+	tests := []struct {
+		desc    string
+		scrapes [][]Metric
+		want    map[string]float64
+	}{
+		{
+			desc: "first scrape sets the totals",
+			scrapes: [][]Metric{
+				{&Counter{Name: MetricRequestsTooLarge, Ingress: "uploads@default.ingressroute.traefik.containo.us", Value: 3}},
+			},
+			want: map[string]float64{
+				"uploads@default.ingressroute.traefik.containo.us": 0,
+			},
+		},
+		{
+			desc: "increase since the previous scrape",
+			scrapes: [][]Metric{
+				{&Counter{Name: MetricRequestsTooLarge, Ingress: "uploads@default.ingressroute.traefik.containo.us", Value: 3}},
+				{
+					&Counter{Name: MetricRequestsTooLarge, Ingress: "uploads@default.ingressroute.traefik.containo.us", Value: 5},
+					&Counter{Name: MetricRequestsTooLarge, EdgeIngress: "myIngress@default", Value: 1},
+					&Counter{Name: MetricRequests, EdgeIngress: "myIngress@default", Value: 10},
+				},
+			},
+			want: map[string]float64{
+				"uploads@default.ingressroute.traefik.containo.us": 2,
+				"myIngress@default": 1,
+			},
+		},
+		{
+			desc: "ingress controller restarted",
+			scrapes: [][]Metric{
+				{&Counter{Name: MetricRequestsTooLarge, Ingress: "uploads@default.ingressroute.traefik.containo.us", Value: 3}},
+				{&Counter{Name: MetricRequestsTooLarge, Ingress: "uploads@default.ingressroute.traefik.containo.us", Value: 2}},
+			},
+			want: map[string]float64{
+				"uploads@default.ingressroute.traefik.containo.us": 2,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := NewRejectionMetrics(prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			for _, scrape := range test.scrapes {
+				m.observe(scrape)
+			}
+
+			for name, want := range test.want {
+				assert.Equal(t, want, testutil.ToFloat64(m.requestsTooLarge.WithLabelValues(name)))
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/golang/snappy"
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// RemoteWriteExporter exports data points to a Prometheus remote-write endpoint.
+// The request metrics of the data points are accumulated into counters, as expected by Prometheus.
+type RemoteWriteExporter struct {
+	endpoint   string
+	headers    map[string]string
+	httpClient *http.Client
+
+	totals map[SetKey]remoteWriteTotals
+}
+
+type remoteWriteTotals struct {
+	requests          int64
+	requestErrs       int64
+	requestClientErrs int64
+	responseTimeSum   float64
+	responseTimeCount int64
+}
+
+// NewRemoteWriteExporter creates a RemoteWriteExporter sending data points to the given endpoint, such as
+// "http://prometheus:9090/api/v1/write".
+func NewRemoteWriteExporter(client *http.Client, endpoint string, headers map[string]string) (*RemoteWriteExporter, error) {
+	u, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote-write endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("only http and https is supported, %s found", u.Scheme)
+	}
+
+	return &RemoteWriteExporter{
+		endpoint:   u.String(),
+		headers:    headers,
+		httpClient: client,
+		totals:     make(map[SetKey]remoteWriteTotals),
+	}, nil
+}
+
+// Export exports the given data points. It must not be called concurrently.
+func (e *RemoteWriteExporter) Export(ctx context.Context, pnts map[SetKey]DataPoint) error {
+	if len(pnts) == 0 {
+		return nil
+	}
+
+	for key, pnt := range pnts {
+		total := e.totals[key]
+		total.requests += pnt.Requests
+		total.requestErrs += pnt.RequestErrs
+		total.requestClientErrs += pnt.RequestClientErrs
+		total.responseTimeSum += pnt.ResponseTimeSum
+		total.responseTimeCount += pnt.ResponseTimeCount
+		e.totals[key] = total
+	}
+
+	raw := snappy.Encode(nil, e.newWriteRequest(pnts))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	for name, value := range e.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	version.SetUserAgent(req)
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("writing metrics: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("writing metrics got %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// newWriteRequest encodes the remote-write request holding the totals of the given data points, timestamped with
+// their Timestamp.
+func (e *RemoteWriteExporter) newWriteRequest(pnts map[SetKey]DataPoint) []byte {
+	keys := make([]SetKey, 0, len(pnts))
+	for key := range pnts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].EdgeIngress != keys[j].EdgeIngress {
+			return keys[i].EdgeIngress < keys[j].EdgeIngress
+		}
+		if keys[i].Ingress != keys[j].Ingress {
+			return keys[i].Ingress < keys[j].Ingress
+		}
+		return keys[i].Service < keys[j].Service
+	})
+
+	var req []byte
+	for _, key := range keys {
+		total := e.totals[key]
+		ts := pnts[key].Timestamp * 1000
+
+		req = appendTimeSeries(req, "hub_requests_total", key, float64(total.requests), ts)
+		req = appendTimeSeries(req, "hub_request_errors_total", key, float64(total.requestErrs), ts)
+		req = appendTimeSeries(req, "hub_request_client_errors_total", key, float64(total.requestClientErrs), ts)
+		req = appendTimeSeries(req, "hub_request_duration_seconds_sum", key, total.responseTimeSum, ts)
+		req = appendTimeSeries(req, "hub_request_duration_seconds_count", key, float64(total.responseTimeCount), ts)
+	}
+
+	return req
+}
+
+// appendTimeSeries appends a prometheus.TimeSeries holding a single sample, as the field 1 of a prometheus.WriteRequest.
+func appendTimeSeries(b []byte, name string, key SetKey, value float64, timestampMs int64) []byte {
+	// Labels must be sorted by name.
+	var series []byte
+	series = appendLabel(series, "__name__", name)
+	if key.EdgeIngress != "" {
+		series = appendLabel(series, "edge_ingress", key.EdgeIngress)
+	}
+	if key.Ingress != "" {
+		series = appendLabel(series, "ingress", key.Ingress)
+	}
+	if key.Service != "" {
+		series = appendLabel(series, "service", key.Service)
+	}
+
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(timestampMs))
+
+	series = protowire.AppendTag(series, 2, protowire.BytesType)
+	series = protowire.AppendBytes(series, sample)
+
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, series)
+}
+
+// appendLabel appends a prometheus.Label as the field 1 of a prometheus.TimeSeries.
+func appendLabel(b []byte, name, value string) []byte {
+	var label []byte
+	label = protowire.AppendTag(label, 1, protowire.BytesType)
+	label = protowire.AppendString(label, name)
+	label = protowire.AppendTag(label, 2, protowire.BytesType)
+	label = protowire.AppendString(label, value)
+
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, label)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type sample struct {
+	Labels    map[string]string
+	Value     float64
+	Timestamp int64
+}
+
+func TestRemoteWriteExporter_Export(t *testing.T) {
+	var got [][]sample
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/write", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "0.1.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		assert.Equal(t, "Bearer prometheus-token", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		raw, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+
+		got = append(got, decodeWriteRequest(t, raw))
+	}))
+	t.Cleanup(srv.Close)
+
+	exporter, err := metrics.NewRemoteWriteExporter(http.DefaultClient, srv.URL+"/api/v1/write", map[string]string{
+		"Authorization": "Bearer prometheus-token",
+	})
+	require.NoError(t, err)
+
+	err = exporter.Export(context.Background(), map[metrics.SetKey]metrics.DataPoint{
+		{Ingress: "ing@ns", Service: "svc@ns"}: {
+			Timestamp:         120,
+			Seconds:           60,
+			Requests:          10,
+			RequestErrs:       2,
+			RequestClientErrs: 1,
+			ResponseTimeSum:   4,
+			ResponseTimeCount: 10,
+		},
+	})
+	require.NoError(t, err)
+
+	err = exporter.Export(context.Background(), map[metrics.SetKey]metrics.DataPoint{
+		{Ingress: "ing@ns", Service: "svc@ns"}: {
+			Timestamp:         180,
+			Seconds:           60,
+			Requests:          5,
+			ResponseTimeSum:   1.5,
+			ResponseTimeCount: 5,
+		},
+	})
+	require.NoError(t, err)
+
+	lbls := func(name string) map[string]string {
+		return map[string]string{"__name__": name, "ingress": "ing@ns", "service": "svc@ns"}
+	}
+
+	require.Len(t, got, 2)
+	assert.Equal(t, []sample{
+		{Labels: lbls("hub_requests_total"), Value: 10, Timestamp: 120000},
+		{Labels: lbls("hub_request_errors_total"), Value: 2, Timestamp: 120000},
+		{Labels: lbls("hub_request_client_errors_total"), Value: 1, Timestamp: 120000},
+		{Labels: lbls("hub_request_duration_seconds_sum"), Value: 4, Timestamp: 120000},
+		{Labels: lbls("hub_request_duration_seconds_count"), Value: 10, Timestamp: 120000},
+	}, got[0])
+
+	// Data points are accumulated, as Prometheus expects counters.
+	assert.Equal(t, []sample{
+		{Labels: lbls("hub_requests_total"), Value: 15, Timestamp: 180000},
+		{Labels: lbls("hub_request_errors_total"), Value: 2, Timestamp: 180000},
+		{Labels: lbls("hub_request_client_errors_total"), Value: 1, Timestamp: 180000},
+		{Labels: lbls("hub_request_duration_seconds_sum"), Value: 5.5, Timestamp: 180000},
+		{Labels: lbls("hub_request_duration_seconds_count"), Value: 15, Timestamp: 180000},
+	}, got[1])
+}
+
+func TestRemoteWriteExporter_Export_error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("out of order sample"))
+	}))
+	t.Cleanup(srv.Close)
+
+	exporter, err := metrics.NewRemoteWriteExporter(http.DefaultClient, srv.URL, nil)
+	require.NoError(t, err)
+
+	err = exporter.Export(context.Background(), map[metrics.SetKey]metrics.DataPoint{
+		{Service: "svc@ns"}: {Timestamp: 120, Seconds: 60, Requests: 1},
+	})
+	assert.EqualError(t, err, "writing metrics got 400: out of order sample")
+}
+
+// decodeWriteRequest decodes a prometheus.WriteRequest holding time series with a single sample.
+func decodeWriteRequest(t *testing.T, b []byte) []sample {
+	t.Helper()
+
+	var samples []sample
+	for _, series := range decodeFields(t, b)[1] {
+		fields := decodeFields(t, series)
+
+		smpl := sample{Labels: make(map[string]string)}
+		for _, label := range fields[1] {
+			lblFields := decodeFields(t, label)
+			smpl.Labels[string(lblFields[1][0])] = string(lblFields[2][0])
+		}
+
+		require.Len(t, fields[2], 1)
+		smplFields := decodeFields(t, fields[2][0])
+		value, _ := protowire.ConsumeFixed64(smplFields[1][0])
+		ts, _ := protowire.ConsumeVarint(smplFields[2][0])
+		smpl.Value = math.Float64frombits(value)
+		smpl.Timestamp = int64(ts)
+
+		samples = append(samples, smpl)
+	}
+
+	return samples
+}
+
+// decodeFields returns the raw values of the given message by field number. Length-delimited values are returned
+// without their length prefix.
+func decodeFields(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	t.Helper()
+
+	fields := make(map[protowire.Number][][]byte)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		require.GreaterOrEqual(t, n, 0)
+
+		value := b[:n]
+		if typ == protowire.BytesType {
+			value, _ = protowire.ConsumeBytes(value)
+		}
+		fields[num] = append(fields[num], value)
+		b = b[n:]
+	}
+
+	return fields
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 
 	dto "github.com/prometheus/client_model/go"
@@ -33,6 +34,8 @@ import (
 // This should match the topology types.
 const (
 	ParserTraefik = "traefik"
+	ParserHAProxy = "haproxy"
+	ParserIstio   = "istio"
 )
 
 // Metric names.
@@ -41,6 +44,7 @@ const (
 	MetricRequests            = "requests"
 	MetricRequestErrors       = "request_errors"
 	MetricRequestClientErrors = "request_client_errors"
+	MetricRequestsTooLarge    = "requests_too_large"
 )
 
 // Metric represents a metric object.
@@ -94,6 +98,7 @@ type Histogram struct {
 	Service     string
 	Sum         float64
 	Count       uint64
+	Buckets     Buckets
 }
 
 // HistogramFromMetric returns a histogram metric from a prometheus
@@ -104,9 +109,16 @@ func HistogramFromMetric(m *dto.Metric) *Histogram {
 		return nil
 	}
 
+	buckets := make(Buckets, len(hist.Bucket)+1)
+	for _, bucket := range hist.Bucket {
+		buckets[bucket.GetUpperBound()] = bucket.GetCumulativeCount()
+	}
+	buckets[math.Inf(1)] = hist.GetSampleCount()
+
 	return &Histogram{
-		Sum:   hist.GetSampleSum(),
-		Count: hist.GetSampleCount(),
+		Sum:     hist.GetSampleSum(),
+		Count:   hist.GetSampleCount(),
+		Buckets: buckets,
 	}
 }
 
@@ -128,6 +140,23 @@ func (h Histogram) ServiceName() string {
 // ScrapeState contains the state used while scraping.
 type ScrapeState struct {
 	Ingresses map[string]struct{}
+	// Services holds the services of the cluster, which third-party ingress controller metrics are attributed to.
+	Services map[string]struct{}
+	// ServiceIngresses holds the Ingresses routing to each service.
+	ServiceIngresses map[string][]IngressRef
+	// DeprecatedAPIIngressRoutes holds the IngressRoutes exposing deprecated APIs.
+	DeprecatedAPIIngressRoutes map[string]struct{}
+	// BodyLimitedIngressRoutes holds the IngressRoutes limiting the size of the request bodies.
+	BodyLimitedIngressRoutes map[string]struct{}
+	// APIRouters holds the consumers of the APIs served by each Traefik router.
+	APIRouters map[string]APIConsumer
+}
+
+// IngressRef references an Ingress.
+type IngressRef struct {
+	Name string
+	// Class is the ingress class of the Ingress, if any.
+	Class string
 }
 
 // Parser represents a platform-specific metrics parser.
@@ -140,6 +169,8 @@ type Scraper struct {
 	client *http.Client
 
 	traefikParser TraefikParser
+	haproxyParser HAProxyParser
+	istioParser   IstioParser
 }
 
 // NewScraper returns a scraper instance with parser p.
@@ -147,6 +178,8 @@ func NewScraper(c *http.Client) *Scraper {
 	return &Scraper{
 		client:        c,
 		traefikParser: NewTraefikParser(),
+		haproxyParser: NewHAProxyParser(),
+		istioParser:   NewIstioParser(),
 	}
 }
 
@@ -161,6 +194,10 @@ func (s *Scraper) Scrape(ctx context.Context, parser, target string, state Scrap
 	switch parser {
 	case ParserTraefik:
 		p = s.traefikParser
+	case ParserHAProxy:
+		p = s.haproxyParser
+	case ParserIstio:
+		p = s.istioParser
 	default:
 		return nil, fmt.Errorf("invalid parser %q", parser)
 	}
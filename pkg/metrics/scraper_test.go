@@ -19,6 +19,7 @@ package metrics_test
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -32,6 +33,8 @@ import (
 )
 
 func TestScraper_ScrapeTraefik(t *testing.T) {
+	buckets := metrics.Buckets{0.1: 1, 0.3: 1, 1.2: 1, 5: 1, math.Inf(1): 1}
+
 	tests := []struct {
 		desc    string
 		metrics string
@@ -41,20 +44,30 @@ func TestScraper_ScrapeTraefik(t *testing.T) {
 			desc:    "Traefik v2.8+",
 			metrics: "testdata/traefik-v2-8-metrics.txt",
 			want: []metrics.Metric{
-				&metrics.Histogram{Name: metrics.MetricRequestDuration, EdgeIngress: "myIngress@default", Sum: 0.0137623, Count: 1},
+				&metrics.Histogram{Name: metrics.MetricRequestDuration, EdgeIngress: "myIngress@default", Sum: 0.0137623, Count: 1, Buckets: buckets},
 				&metrics.Counter{Name: metrics.MetricRequests, EdgeIngress: "myIngress@default", Value: 2},
 				// edge cases, TLS/middleware enable on entrypoint
 				&metrics.Counter{Name: metrics.MetricRequests, EdgeIngress: "app-obe@whoami", Value: 38},
+				// deprecated API
+				&metrics.Histogram{Name: metrics.MetricRequestDuration, Ingress: "myIngressRoute@default.ingressroute.traefik.containo.us", Sum: 0.0216373, Count: 1, Buckets: buckets},
+				&metrics.Counter{Name: metrics.MetricRequests, Ingress: "myIngressRoute@default.ingressroute.traefik.containo.us", Value: 1},
+				// body limited API
+				&metrics.Counter{Name: metrics.MetricRequestsTooLarge, Ingress: "uploads@default.ingressroute.traefik.containo.us", Value: 3},
 			},
 		},
 		{
 			desc:    "Traefik older versions",
 			metrics: "testdata/traefik-metrics.txt",
 			want: []metrics.Metric{
-				&metrics.Histogram{Name: metrics.MetricRequestDuration, EdgeIngress: "myIngress@default", Sum: 0.0137623, Count: 1},
+				&metrics.Histogram{Name: metrics.MetricRequestDuration, EdgeIngress: "myIngress@default", Sum: 0.0137623, Count: 1, Buckets: buckets},
 				&metrics.Counter{Name: metrics.MetricRequests, EdgeIngress: "myIngress@default", Value: 2},
 				// edge cases, TLS/middleware enable on entrypoint
 				&metrics.Counter{Name: metrics.MetricRequests, EdgeIngress: "app-obe@whoami", Value: 38},
+				// deprecated API
+				&metrics.Histogram{Name: metrics.MetricRequestDuration, Ingress: "myIngressRoute@default.ingressroute.traefik.containo.us", Sum: 0.0216373, Count: 1, Buckets: buckets},
+				&metrics.Counter{Name: metrics.MetricRequests, Ingress: "myIngressRoute@default.ingressroute.traefik.containo.us", Value: 1},
+				// body limited API
+				&metrics.Counter{Name: metrics.MetricRequestsTooLarge, Ingress: "uploads@default.ingressroute.traefik.containo.us", Value: 3},
 			},
 		},
 	}
@@ -73,6 +86,12 @@ func TestScraper_ScrapeTraefik(t *testing.T) {
 					"myIngress@default.ingress.networking.k8s.io": {},
 					"app-obe@whoami.ingress.networking.k8s.io":    {},
 				},
+				DeprecatedAPIIngressRoutes: map[string]struct{}{
+					"myIngressRoute@default.ingressroute.traefik.containo.us": {},
+				},
+				BodyLimitedIngressRoutes: map[string]struct{}{
+					"uploads@default.ingressroute.traefik.containo.us": {},
+				},
 			})
 			require.NoError(t, err)
 
@@ -81,6 +100,71 @@ func TestScraper_ScrapeTraefik(t *testing.T) {
 	}
 }
 
+func TestScraper_ScrapeHAProxy(t *testing.T) {
+	srvURL := startServer(t, "testdata/haproxy-metrics.txt")
+	s := metrics.NewScraper(http.DefaultClient)
+
+	got, err := s.Scrape(context.Background(), metrics.ParserHAProxy, srvURL, metrics.ScrapeState{
+		Services: map[string]struct{}{
+			"whoami@default": {},
+			"idle@default":   {},
+			"app-api@my":     {},
+			"app@my":         {},
+		},
+	})
+	require.NoError(t, err)
+
+	want := []metrics.Metric{
+		&metrics.Counter{Name: metrics.MetricRequests, Service: "whoami@default", Value: 42},
+		&metrics.Counter{Name: metrics.MetricRequestClientErrors, Service: "whoami@default", Value: 4},
+		&metrics.Counter{Name: metrics.MetricRequestErrors, Service: "whoami@default", Value: 2},
+		&metrics.Histogram{Name: metrics.MetricRequestDuration, Relative: true, Service: "whoami@default", Sum: 0.012, Count: 1},
+		// Older HAProxy ingress controllers separate the namespace and the service with dashes.
+		&metrics.Counter{Name: metrics.MetricRequests, Service: "app-api@my", Value: 10},
+		&metrics.Counter{Name: metrics.MetricRequestErrors, Service: "app-api@my", Value: 1},
+		&metrics.Histogram{Name: metrics.MetricRequestDuration, Relative: true, Service: "app-api@my", Sum: 0.3, Count: 1},
+	}
+	assert.ElementsMatch(t, want, got)
+}
+
+func TestScraper_ScrapeIstio(t *testing.T) {
+	srvURL := startServer(t, "testdata/istio-metrics.txt")
+	s := metrics.NewScraper(http.DefaultClient)
+
+	got, err := s.Scrape(context.Background(), metrics.ParserIstio, srvURL, metrics.ScrapeState{
+		Services: map[string]struct{}{
+			"whoami@default": {},
+			"api@shop":       {},
+		},
+		ServiceIngresses: map[string][]metrics.IngressRef{
+			"whoami@default": {
+				{Name: "whoami@default.ingress.networking.k8s.io", Class: "istio"},
+				{Name: "whoami-traefik@default.ingress.networking.k8s.io", Class: "traefik"},
+			},
+			// Traffic can't be attributed to an Ingress when several ones route to the service.
+			"api@shop": {
+				{Name: "api@shop.ingress.networking.k8s.io", Class: "istio"},
+				{Name: "api-v2@shop.ingress.networking.k8s.io", Class: "istio"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ingress := "whoami@default.ingress.networking.k8s.io"
+	want := []metrics.Metric{
+		&metrics.Counter{Name: metrics.MetricRequests, Ingress: ingress, Service: "whoami@default", Value: 20},
+		&metrics.Counter{Name: metrics.MetricRequestClientErrors, Ingress: ingress, Service: "whoami@default", Value: 3},
+		&metrics.Counter{Name: metrics.MetricRequestErrors, Ingress: ingress, Service: "whoami@default", Value: 1},
+		&metrics.Counter{Name: metrics.MetricRequestErrors, Ingress: ingress, Service: "whoami@default", Value: 1},
+		&metrics.Histogram{Name: metrics.MetricRequestDuration, Ingress: ingress, Service: "whoami@default", Sum: 0.15, Count: 20, Buckets: metrics.Buckets{
+			0.0005: 0, 0.001: 0, 0.005: 10, math.Inf(1): 20,
+		}},
+		&metrics.Counter{Name: metrics.MetricRequests, Service: "api@shop", Value: 8},
+		&metrics.Counter{Name: metrics.MetricRequestErrors, Service: "api@shop", Value: 2},
+	}
+	assert.ElementsMatch(t, want, got)
+}
+
 func startServer(t *testing.T, file string) string {
 	t.Helper()
 
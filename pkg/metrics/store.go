@@ -55,6 +55,8 @@ type Store struct {
 	mu    sync.RWMutex
 	data  map[string]map[tableKey]DataPoints
 	marks map[string]WaterMarks
+	// renames maps the previous names of renamed Ingresses and IngressRoutes to their current one.
+	renames map[string]string
 
 	// NowFunc is the function used to test time.
 	nowFunc func() time.Time
@@ -65,7 +67,8 @@ func NewStore() *Store {
 	tables := []tableInfo{
 		{Name: "1m", MinCount: 10, RollUp: 10 * time.Minute, Next: "10m"},
 		{Name: "10m", MinCount: 6, RollUp: time.Hour, Next: "1h"},
-		{Name: "1h", MinCount: 24, RollUp: 24 * time.Hour, Next: "1d"},
+		// Hourly points are kept for 8 days, so anomaly rules can compare with the same time last week.
+		{Name: "1h", MinCount: 8 * 24, RollUp: 24 * time.Hour, Next: "1d"},
 		{Name: "1d", MinCount: 30, RollUp: 30 * 24 * time.Hour},
 	}
 
@@ -80,6 +83,7 @@ func NewStore() *Store {
 		tables:  tables,
 		data:    tbls,
 		marks:   marks,
+		renames: make(map[string]string),
 		nowFunc: time.Now,
 	}
 }
@@ -119,13 +123,77 @@ func (s *Store) Insert(svcs map[SetKey]DataPoint) {
 	table := s.data["1m"]
 
 	for k, pnt := range svcs {
-		key := tableKey(k)
+		key := s.renamed(tableKey(k))
 		pnts := table[key]
 		pnts = append(pnts, pnt)
 		table[key] = pnts
 	}
 }
 
+// Rename moves the data points of an Ingress or IngressRoute to its new name, so its series continue under the new
+// name. Data points later inserted under the previous name are inserted under the new one.
+func (s *Store) Rename(from, to string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Renaming back to a previous name makes it current again.
+	delete(s.renames, to)
+	for prev, current := range s.renames {
+		if current == from {
+			s.renames[prev] = to
+		}
+	}
+	s.renames[from] = to
+
+	for tbl, table := range s.data {
+		for key, pnts := range table {
+			newKey := renameKey(key, from, to)
+			if newKey == key {
+				continue
+			}
+
+			// Data points of the previous name precede the ones of the new name. The low water mark can only cover the
+			// data points of the new name if all the ones of the previous name have been sent already.
+			mark := s.marks[tbl][key]
+			if mark == len(pnts) {
+				mark += s.marks[tbl][newKey]
+			}
+
+			merged := make(DataPoints, 0, len(pnts)+len(table[newKey]))
+			merged = append(merged, pnts...)
+			merged = append(merged, table[newKey]...)
+			sort.SliceStable(merged, func(i, j int) bool {
+				return merged[i].Timestamp < merged[j].Timestamp
+			})
+
+			table[newKey] = merged
+			s.marks[tbl][newKey] = mark
+
+			delete(table, key)
+			delete(s.marks[tbl], key)
+		}
+	}
+}
+
+func (s *Store) renamed(key tableKey) tableKey {
+	for from, to := range s.renames {
+		key = renameKey(key, from, to)
+	}
+
+	return key
+}
+
+func renameKey(key tableKey, from, to string) tableKey {
+	if key.EdgeIngress == from {
+		key.EdgeIngress = to
+	}
+	if key.Ingress == from {
+		key.Ingress = to
+	}
+
+	return key
+}
+
 // ForEachFunc represents a function that will be called while iterating over a table.
 // Each time this function is called, a unique ingress and service will
 // be given with their set of points.
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStore_PopulateAndForEach(t *testing.T) {
@@ -185,6 +186,70 @@ func TestStore_CleanupDoesntRemoveUnmarked(t *testing.T) {
 	})
 }
 
+func TestStore_Rename(t *testing.T) {
+	store := NewStore()
+
+	err := store.Populate("1m", []DataPointGroup{
+		{
+			Ingress:    "old@ns.ingressroute.traefik.containo.us",
+			Service:    "whoami@ns",
+			DataPoints: DataPoints{{Timestamp: 60, ReqPerS: 1}, {Timestamp: 120, ReqPerS: 2}},
+		},
+		{
+			Ingress:    "other@ns.ingressroute.traefik.containo.us",
+			Service:    "whoami@ns",
+			DataPoints: DataPoints{{Timestamp: 60, ReqPerS: 10}},
+		},
+	})
+	require.NoError(t, err)
+
+	store.Insert(map[SetKey]DataPoint{
+		{Ingress: "new@ns.ingressroute.traefik.containo.us", Service: "whoami@ns"}: {Timestamp: 180, ReqPerS: 3},
+	})
+
+	store.Rename("old@ns.ingressroute.traefik.containo.us", "new@ns.ingressroute.traefik.containo.us")
+
+	// Data points still reported under the previous name are inserted under the new one.
+	store.Insert(map[SetKey]DataPoint{
+		{Ingress: "old@ns.ingressroute.traefik.containo.us", Service: "whoami@ns"}: {Timestamp: 240, ReqPerS: 4},
+	})
+
+	got := make(map[string]DataPoints)
+	store.ForEach("1m", func(_, ingr, _ string, pnts DataPoints) {
+		got[ingr] = pnts
+	})
+
+	assert.Equal(t, map[string]DataPoints{
+		"new@ns.ingressroute.traefik.containo.us": {
+			{Timestamp: 60, ReqPerS: 1},
+			{Timestamp: 120, ReqPerS: 2},
+			{Timestamp: 180, ReqPerS: 3},
+			{Timestamp: 240, ReqPerS: 4},
+		},
+		"other@ns.ingressroute.traefik.containo.us": {{Timestamp: 60, ReqPerS: 10}},
+	}, got)
+
+	// Only the data points which have not been sent yet are unmarked.
+	var unmarked DataPoints
+	store.ForEachUnmarked("1m", func(_, ingr, _ string, pnts DataPoints) {
+		unmarked = append(unmarked, pnts...)
+	})
+	assert.Equal(t, DataPoints{{Timestamp: 180, ReqPerS: 3}, {Timestamp: 240, ReqPerS: 4}}, unmarked)
+
+	// Renaming back makes the previous name current again.
+	store.Rename("new@ns.ingressroute.traefik.containo.us", "old@ns.ingressroute.traefik.containo.us")
+	store.Insert(map[SetKey]DataPoint{
+		{Ingress: "old@ns.ingressroute.traefik.containo.us", Service: "whoami@ns"}: {Timestamp: 300, ReqPerS: 5},
+	})
+
+	got = make(map[string]DataPoints)
+	store.ForEach("1m", func(_, ingr, _ string, pnts DataPoints) {
+		got[ingr] = pnts
+	})
+	assert.Len(t, got["old@ns.ingressroute.traefik.containo.us"], 5)
+	assert.NotContains(t, got, "new@ns.ingressroute.traefik.containo.us")
+}
+
 func genDataPoints(t *testing.T, now time.Time, n int, gran time.Duration) []DataPoint {
 	t.Helper()
 
@@ -167,6 +167,7 @@ func mergeGroups(groups []DataPoints) DataPoints {
 			sum.RequestClientErrs += point.RequestClientErrs
 			sum.ResponseTimeSum += point.ResponseTimeSum
 			sum.ResponseTimeCount += point.ResponseTimeCount
+			sum.ResponseTimeBuckets = sum.ResponseTimeBuckets.Add(point.ResponseTimeBuckets)
 
 			pointSums[point.Timestamp] = sum
 			counts[point.Timestamp]++
@@ -198,6 +199,8 @@ func mergeGroups(groups []DataPoints) DataPoints {
 			point.AvgResponseTime = point.ResponseTimeSum / float64(point.ResponseTimeCount)
 		}
 
+		point.setResponseTimePercentiles()
+
 		points = append(points, point)
 	}
 
@@ -0,0 +1,228 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrCircuitOpen is returned by the requests to the platform while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("platform circuit breaker open")
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+// The different BreakerState available.
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops sending requests to the platform while it's failing, instead of hammering it during outages.
+// It opens after the given number of consecutive failures, which are server errors and requests that didn't get any
+// response, timeouts included. Once open, requests fail right away with ErrCircuitOpen until a backoff elapses, then
+// a single trial request is let through: the breaker closes if it succeeds, and opens again for twice as long
+// otherwise. Backoffs are jittered, so agents don't all hit the platform again at the same time when it recovers.
+type CircuitBreaker struct {
+	threshold  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+	jitter     func(d time.Duration) time.Duration
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	backoff   time.Duration
+	openUntil time.Time
+	trial     bool
+	opens     uint64
+}
+
+// NewCircuitBreaker creates a CircuitBreaker.
+func NewCircuitBreaker(threshold int, minBackoff, maxBackoff time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold:  threshold,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		now:        time.Now,
+		jitter:     jitter,
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && !b.now().Before(b.openUntil) {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+// Opens returns the number of times the breaker opened.
+func (b *CircuitBreaker) Opens() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.opens
+}
+
+// Transport returns a round tripper sending requests through the given round tripper while the breaker allows it.
+func (b *CircuitBreaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &breakerTransport{next: next, breaker: b}
+}
+
+// breakerTransport is an http.RoundTripper sending requests while its circuit breaker allows it.
+type breakerTransport struct {
+	next    http.RoundTripper
+	breaker *CircuitBreaker
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := t.next.RoundTrip(req)
+
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		// Requests canceled by the agent tell nothing about the platform health.
+		t.breaker.release()
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		t.breaker.failure()
+	default:
+		t.breaker.success()
+	}
+
+	return resp, err
+}
+
+// allow reports whether a request can be sent.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Before(b.openUntil) {
+			return false
+		}
+
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return true
+	case BreakerHalfOpen:
+		if b.trial {
+			return false
+		}
+
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerClosed {
+		log.Info().Msg("Platform is reachable again, circuit breaker closed")
+	}
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.backoff = 0
+	b.trial = false
+}
+
+func (b *CircuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+
+	switch {
+	case b.state == BreakerHalfOpen:
+		b.open(2 * b.backoff)
+	case b.state == BreakerClosed && b.failures >= b.threshold:
+		b.open(b.minBackoff)
+	}
+}
+
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// open opens the breaker for the given backoff, bounded to the maximum backoff and jittered.
+// It must be called with the lock held.
+func (b *CircuitBreaker) open(backoff time.Duration) {
+	if backoff > b.maxBackoff {
+		backoff = b.maxBackoff
+	}
+
+	b.state = BreakerOpen
+	b.backoff = backoff
+	b.openUntil = b.now().Add(b.jitter(backoff))
+	b.trial = false
+	b.opens++
+
+	log.Warn().
+		Int("failures", b.failures).
+		Time("retry_at", b.openUntil).
+		Msg("Platform is failing, circuit breaker open")
+}
+
+// jitter returns a duration randomly picked within 20% of the given one.
+func jitter(d time.Duration) time.Duration {
+	return time.Duration(float64(d) * (0.8 + 0.4*rand.Float64())) //nolint:gosec // No need to crypto randomness to jitter backoffs.
+}
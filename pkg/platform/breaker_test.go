@@ -0,0 +1,127 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusTransport answers every request with the status code it holds, or fails it when the status code is 0.
+type statusTransport struct {
+	statusCode int
+	requests   int
+}
+
+func (t *statusTransport) RoundTrip(_ *http.Request) (*http.Response, error) {
+	t.requests++
+
+	if t.statusCode == 0 {
+		return nil, errors.New("connection refused")
+	}
+
+	return &http.Response{StatusCode: t.statusCode, Body: http.NoBody}, nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	breaker := NewCircuitBreaker(3, 10*time.Second, 30*time.Second)
+	breaker.now = func() time.Time { return now }
+	breaker.jitter = func(d time.Duration) time.Duration { return d }
+
+	next := &statusTransport{statusCode: http.StatusBadGateway}
+	transport := breaker.Transport(next)
+
+	roundTrip := func() error {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://platform.hub.traefik.io/agent", http.NoBody)
+		require.NoError(t, err)
+
+		resp, err := transport.RoundTrip(req)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	// Client errors don't count as failures.
+	next.statusCode = http.StatusNotFound
+	require.NoError(t, roundTrip())
+
+	next.statusCode = http.StatusBadGateway
+	require.NoError(t, roundTrip())
+	require.NoError(t, roundTrip())
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	next.statusCode = 0
+	require.Error(t, roundTrip())
+	assert.Equal(t, BreakerOpen, breaker.State())
+	assert.Equal(t, uint64(1), breaker.Opens())
+
+	// Requests fail right away while the breaker is open.
+	require.ErrorIs(t, roundTrip(), ErrCircuitOpen)
+	assert.Equal(t, 4, next.requests)
+
+	// A failing trial request opens the breaker again for twice as long.
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+
+	require.Error(t, roundTrip())
+	assert.Equal(t, 5, next.requests)
+	assert.Equal(t, BreakerOpen, breaker.State())
+	assert.Equal(t, uint64(2), breaker.Opens())
+
+	now = now.Add(10 * time.Second)
+	require.ErrorIs(t, roundTrip(), ErrCircuitOpen)
+
+	// Backoffs are bounded.
+	now = now.Add(10 * time.Second)
+	require.Error(t, roundTrip())
+
+	now = now.Add(29 * time.Second)
+	require.ErrorIs(t, roundTrip(), ErrCircuitOpen)
+
+	// A successful trial request closes the breaker.
+	now = now.Add(time.Second)
+	next.statusCode = http.StatusOK
+	require.NoError(t, roundTrip())
+	assert.Equal(t, BreakerClosed, breaker.State())
+	assert.Equal(t, 7, next.requests)
+}
+
+func TestCircuitBreaker_ignoresCanceledRequests(t *testing.T) {
+	breaker := NewCircuitBreaker(1, 10*time.Second, 30*time.Second)
+	transport := breaker.Transport(&statusTransport{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://platform.hub.traefik.io/agent", http.NoBody)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req)
+	require.Error(t, err)
+
+	assert.Equal(t, BreakerClosed, breaker.State())
+}
@@ -29,16 +29,22 @@ import (
 	"net/url"
 	"path"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	"github.com/traefik/hub-agent-kubernetes/pkg/clock"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tracing"
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -55,11 +61,20 @@ func (a APIError) Error() string {
 
 // CreateEdgeIngressReq is the request for creating an edge ingress.
 type CreateEdgeIngressReq struct {
-	Name          string   `json:"name"`
-	Namespace     string   `json:"namespace"`
-	Service       Service  `json:"service"`
-	ACP           *ACP     `json:"acp,omitempty"`
-	CustomDomains []string `json:"customDomains,omitempty"`
+	Name          string            `json:"name"`
+	Namespace     string            `json:"namespace"`
+	Service       Service           `json:"service"`
+	Services      []WeightedService `json:"services,omitempty"`
+	ACP           *ACP              `json:"acp,omitempty"`
+	ACPs          []ACP             `json:"acps,omitempty"`
+	CustomDomains []string          `json:"customDomains,omitempty"`
+	Protocol      string            `json:"protocol,omitempty"`
+	EntryPoint    string            `json:"entryPoint,omitempty"`
+
+	Sticky      *hubv1alpha1.EdgeIngressSticky      `json:"sticky,omitempty"`
+	HealthCheck *hubv1alpha1.EdgeIngressHealthCheck `json:"healthCheck,omitempty"`
+	TLS         *hubv1alpha1.EdgeIngressTLS         `json:"tls,omitempty"`
+	Certificate *hubv1alpha1.SecretReference        `json:"certificate,omitempty"`
 }
 
 // Service defines the service being exposed by the edge ingress.
@@ -68,6 +83,13 @@ type Service struct {
 	Port int    `json:"port"`
 }
 
+// WeightedService defines a service receiving a share of the traffic of the edge ingress.
+type WeightedService struct {
+	Name   string `json:"name"`
+	Port   int    `json:"port"`
+	Weight int    `json:"weight"`
+}
+
 // ACP defines the ACP attached to the edge ingress.
 type ACP struct {
 	Name string `json:"name"`
@@ -87,27 +109,38 @@ type MetricsConfig struct {
 
 // UpdateEdgeIngressReq is a request for updating an edge ingress.
 type UpdateEdgeIngressReq struct {
-	Service       Service  `json:"service"`
-	ACP           *ACP     `json:"acp,omitempty"`
-	CustomDomains []string `json:"customDomains,omitempty"`
+	Service       Service           `json:"service"`
+	Services      []WeightedService `json:"services,omitempty"`
+	ACP           *ACP              `json:"acp,omitempty"`
+	ACPs          []ACP             `json:"acps,omitempty"`
+	CustomDomains []string          `json:"customDomains,omitempty"`
+	Protocol      string            `json:"protocol,omitempty"`
+	EntryPoint    string            `json:"entryPoint,omitempty"`
+
+	Sticky      *hubv1alpha1.EdgeIngressSticky      `json:"sticky,omitempty"`
+	HealthCheck *hubv1alpha1.EdgeIngressHealthCheck `json:"healthCheck,omitempty"`
+	TLS         *hubv1alpha1.EdgeIngressTLS         `json:"tls,omitempty"`
+	Certificate *hubv1alpha1.SecretReference        `json:"certificate,omitempty"`
 }
 
 // CreatePortalReq is the request for creating a portal.
 type CreatePortalReq struct {
-	Name          string   `json:"name"`
-	Title         string   `json:"title"`
-	Description   string   `json:"description"`
-	Gateway       string   `json:"gateway"`
-	CustomDomains []string `json:"customDomains"`
+	Name          string            `json:"name"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Gateway       string            `json:"gateway"`
+	CustomDomains []string          `json:"customDomains"`
+	CORS          *hubv1alpha1.CORS `json:"cors,omitempty"`
 }
 
 // UpdatePortalReq is a request for updating a portal.
 type UpdatePortalReq struct {
-	Title         string   `json:"title"`
-	Description   string   `json:"description"`
-	Gateway       string   `json:"gateway"`
-	HubDomain     string   `json:"hubDomain"`
-	CustomDomains []string `json:"customDomains"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Gateway       string            `json:"gateway"`
+	HubDomain     string            `json:"hubDomain"`
+	CustomDomains []string          `json:"customDomains"`
+	CORS          *hubv1alpha1.CORS `json:"cors,omitempty"`
 }
 
 // CreateGatewayReq is the request for creating a gateway.
@@ -116,6 +149,10 @@ type CreateGatewayReq struct {
 	Labels        map[string]string `json:"labels"`
 	Accesses      []string          `json:"accesses"`
 	CustomDomains []string          `json:"customDomains"`
+
+	Certificate *api.SecretReference `json:"certificate,omitempty"`
+
+	MaxRequestBodyBytes *int64 `json:"maxRequestBodyBytes,omitempty"`
 }
 
 // UpdateGatewayReq is a request for updating a gateway.
@@ -123,6 +160,10 @@ type UpdateGatewayReq struct {
 	Labels        map[string]string `json:"labels"`
 	Accesses      []string          `json:"accesses"`
 	CustomDomains []string          `json:"customDomains"`
+
+	Certificate *api.SecretReference `json:"certificate,omitempty"`
+
+	MaxRequestBodyBytes *int64 `json:"maxRequestBodyBytes,omitempty"`
 }
 
 // CreateAPIReq is the request for creating an API.
@@ -132,16 +173,34 @@ type CreateAPIReq struct {
 
 	Labels map[string]string `json:"labels,omitempty"`
 
-	PathPrefix string     `json:"pathPrefix"`
-	Service    APIService `json:"service"`
+	PathPrefix    string             `json:"pathPrefix"`
+	Service       APIService         `json:"service"`
+	VersionHeader *api.VersionHeader `json:"versionHeader,omitempty"`
+	Matchers      *api.Matchers      `json:"matchers,omitempty"`
+	Deprecation   *api.Deprecation   `json:"deprecation,omitempty"`
+	Sandbox       *api.Sandbox       `json:"sandbox,omitempty"`
+
+	MaxRequestBodyBytes *int64            `json:"maxRequestBodyBytes,omitempty"`
+	AllowedMethods      []string          `json:"allowedMethods,omitempty"`
+	ValidateRequests    bool              `json:"validateRequests,omitempty"`
+	CORS                *hubv1alpha1.CORS `json:"cors,omitempty"`
 }
 
 // UpdateAPIReq is a request for updating an API.
 type UpdateAPIReq struct {
 	Labels map[string]string `json:"labels,omitempty"`
 
-	PathPrefix string     `json:"pathPrefix"`
-	Service    APIService `json:"service"`
+	PathPrefix    string             `json:"pathPrefix"`
+	Service       APIService         `json:"service"`
+	VersionHeader *api.VersionHeader `json:"versionHeader,omitempty"`
+	Matchers      *api.Matchers      `json:"matchers,omitempty"`
+	Deprecation   *api.Deprecation   `json:"deprecation,omitempty"`
+	Sandbox       *api.Sandbox       `json:"sandbox,omitempty"`
+
+	MaxRequestBodyBytes *int64            `json:"maxRequestBodyBytes,omitempty"`
+	AllowedMethods      []string          `json:"allowedMethods,omitempty"`
+	ValidateRequests    bool              `json:"validateRequests,omitempty"`
+	CORS                *hubv1alpha1.CORS `json:"cors,omitempty"`
 }
 
 // APIService is a service used in API struct.
@@ -149,6 +208,7 @@ type APIService struct {
 	Name        string      `json:"name"`
 	Port        int         `json:"port"`
 	OpenAPISpec OpenAPISpec `json:"openApiSpec"`
+	ExternalURL string      `json:"externalUrl,omitempty"`
 }
 
 // OpenAPISpec is an OpenAPISpec. It can either be fetched from a URL, or Path/Port from the service.
@@ -157,6 +217,10 @@ type OpenAPISpec struct {
 
 	Path string `json:"path,omitempty"`
 	Port int    `json:"port,omitempty"`
+
+	Headers map[string]string    `json:"headers,omitempty"`
+	Auth    *api.OpenAPISpecAuth `json:"auth,omitempty"`
+	CA      *api.SecretReference `json:"ca,omitempty"`
 }
 
 // CreateCollectionReq is the request for creating a collection.
@@ -194,6 +258,59 @@ type UpdateAccessReq struct {
 	APICollectionSelector *metav1.LabelSelector `json:"apiCollectionSelector,omitempty"`
 }
 
+// CreateRateLimitReq is the request for creating an API rate limit.
+type CreateRateLimitReq struct {
+	Name string `json:"name"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+
+	Groups      []string              `json:"groups,omitempty"`
+	Everyone    bool                  `json:"everyone,omitempty"`
+	APISelector *metav1.LabelSelector `json:"apiSelector,omitempty"`
+	Limit       int64                 `json:"limit"`
+	Period      *metav1.Duration      `json:"period,omitempty"`
+	Strategy    string                `json:"strategy,omitempty"`
+}
+
+// UpdateRateLimitReq is a request for updating an API rate limit.
+type UpdateRateLimitReq struct {
+	Labels map[string]string `json:"labels,omitempty"`
+
+	Groups      []string              `json:"groups,omitempty"`
+	Everyone    bool                  `json:"everyone,omitempty"`
+	APISelector *metav1.LabelSelector `json:"apiSelector,omitempty"`
+	Limit       int64                 `json:"limit"`
+	Period      *metav1.Duration      `json:"period,omitempty"`
+	Strategy    string                `json:"strategy,omitempty"`
+}
+
+// CreateAPIVersionReq is the request for creating an API version.
+type CreateAPIVersionReq struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+
+	APIName       string              `json:"apiName"`
+	Release       string              `json:"release"`
+	PathPrefix    string              `json:"pathPrefix,omitempty"`
+	VersionHeader *api.VersionHeader  `json:"versionHeader,omitempty"`
+	Service       *api.VersionService `json:"service,omitempty"`
+	Deprecation   *api.Deprecation    `json:"deprecation,omitempty"`
+}
+
+// UpdateAPIVersionReq is a request for updating an API version.
+type UpdateAPIVersionReq struct {
+	Labels map[string]string `json:"labels,omitempty"`
+
+	APIName       string              `json:"apiName"`
+	Release       string              `json:"release"`
+	PathPrefix    string              `json:"pathPrefix,omitempty"`
+	VersionHeader *api.VersionHeader  `json:"versionHeader,omitempty"`
+	Service       *api.VersionService `json:"service,omitempty"`
+	Deprecation   *api.Deprecation    `json:"deprecation,omitempty"`
+}
+
 // Command defines patch operation to apply on the cluster.
 type Command struct {
 	ID        string          `json:"id"`
@@ -269,15 +386,53 @@ type patchResp struct {
 	Version int64 `json:"version"`
 }
 
+// clockSkewWarnThreshold is the skew between the agent and platform clocks above which a warning is logged.
+const clockSkewWarnThreshold = 30 * time.Second
+
+// Circuit breaker settings: the number of consecutive failed requests opening it, and the bounds of its backoff.
+const (
+	breakerThreshold  = 5
+	breakerMinBackoff = 5 * time.Second
+	breakerMaxBackoff = 5 * time.Minute
+)
+
 // Client allows interacting with the cluster service.
 type Client struct {
 	baseURL    *url.URL
+	tokenMu    sync.RWMutex
 	token      string
 	httpClient *http.Client
+	skew       *clock.Skew
+	breaker    *CircuitBreaker
+	listCache  *etagCache
+	egress     httpclient.Egress
+}
+
+// ClientConfig holds the configuration of a client for the cluster service.
+type ClientConfig struct {
+	// FaultInjection configures the faults randomly injected in the platform API responses.
+	FaultInjection FaultInjectionConfig
+	// Egress configures the proxy and the CAs used to reach the platform.
+	Egress httpclient.Egress
 }
 
 // NewClient creates a new client for the cluster service.
 func NewClient(baseURL, token string) (*Client, error) {
+	return NewClientWithConfig(baseURL, token, ClientConfig{Egress: httpclient.DefaultEgress()})
+}
+
+// NewClientWithFaultInjection creates a new client for the cluster service which randomly injects the given faults
+// in the platform API responses. Faults are injected in every attempt of a request, so transient faults are recovered
+// by retries like actual platform failures would be.
+func NewClientWithFaultInjection(baseURL, token string, faultCfg FaultInjectionConfig) (*Client, error) {
+	return NewClientWithConfig(baseURL, token, ClientConfig{
+		FaultInjection: faultCfg,
+		Egress:         httpclient.DefaultEgress(),
+	})
+}
+
+// NewClientWithConfig creates a new client for the cluster service with the given configuration.
+func NewClientWithConfig(baseURL, token string, cfg ClientConfig) (*Client, error) {
 	u, err := url.ParseRequestURI(baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse client url: %w", err)
@@ -286,14 +441,75 @@ func NewClient(baseURL, token string) (*Client, error) {
 	client := retryablehttp.NewClient()
 	client.RetryMax = 4
 	client.Logger = logger.NewRetryableHTTPWrapper(log.Logger.With().Str("component", "platform_client").Logger())
+	client.HTTPClient.Transport = cfg.Egress.Transport()
+
+	if len(cfg.FaultInjection.Faults) > 0 {
+		log.Warn().Int("faults", len(cfg.FaultInjection.Faults)).Msg("Platform API fault injection enabled")
+
+		client.HTTPClient.Transport = newFaultTransport(client.HTTPClient.Transport, u.Path, cfg.FaultInjection)
+	}
+
+	skew := clock.NewSkew(clockSkewWarnThreshold)
+	client.HTTPClient.Transport = skew.Transport(client.HTTPClient.Transport)
+
+	// The breaker wraps the retries, so that a request failing after all its retries counts as a single failure
+	// and requests fail right away while it's open.
+	breaker := NewCircuitBreaker(breakerThreshold, breakerMinBackoff, breakerMaxBackoff)
+	httpClient := client.StandardClient()
+	httpClient.Transport = breaker.Transport(httpClient.Transport)
 
 	return &Client{
 		baseURL:    u,
 		token:      token,
-		httpClient: client.StandardClient(),
+		httpClient: httpClient,
+		skew:       skew,
+		breaker:    breaker,
+		listCache:  newETagCache(),
+		egress:     cfg.Egress,
 	}, nil
 }
 
+// SetToken sets the token used to authenticate the calls to the platform, e.g. once it's been rotated.
+func (c *Client) SetToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.token = token
+}
+
+func (c *Client) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+
+	return c.token
+}
+
+// SetMetrics sets the metrics the calls to the platform are reported to. It must be called before the client is used
+// concurrently.
+func (c *Client) SetMetrics(metrics *Metrics) {
+	c.httpClient.Transport = &metricsTransport{
+		next:     c.httpClient.Transport,
+		basePath: strings.TrimSuffix(c.baseURL.Path, "/"),
+		metrics:  metrics,
+	}
+}
+
+// SetTracer sets the tracer the calls to the platform are traced with. It must be called before the client is used
+// concurrently.
+func (c *Client) SetTracer(tracer *tracing.Tracer) {
+	c.httpClient.Transport = tracer.Transport(c.httpClient.Transport)
+}
+
+// CircuitBreaker returns the circuit breaker of the requests to the platform.
+func (c *Client) CircuitBreaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// ClockSkew returns the skew between the agent clock and the platform clock, measured from the platform responses.
+func (c *Client) ClockSkew() *clock.Skew {
+	return c.skew
+}
+
 // Link links the agent to the given Kubernetes ID.
 func (c *Client) Link(ctx context.Context, kubeID string) (string, error) {
 	body, err := json.Marshal(linkClusterReq{KubeID: kubeID, Platform: "kubernetes", Version: version.Version()})
@@ -311,7 +527,7 @@ func (c *Client) Link(ctx context.Context, kubeID string) (string, error) {
 		return "", fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -353,7 +569,7 @@ func (c *Client) GetConfig(ctx context.Context) (Config, error) {
 		return Config{}, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -393,7 +609,7 @@ func (c *Client) Ping(ctx context.Context) error {
 		return fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -433,7 +649,7 @@ func (c *Client) SetVersionStatus(ctx context.Context, status version.Status) er
 		return fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -468,7 +684,7 @@ func (c *Client) ListVerifiedDomains(ctx context.Context) ([]string, error) {
 		return nil, fmt.Errorf("build request for %q: %w", baseURL.String(), err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -508,7 +724,7 @@ func (c *Client) ListUserTokens(ctx context.Context, userEmail string) ([]Token,
 		return nil, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -559,7 +775,7 @@ func (c *Client) CreateUserToken(ctx context.Context, userEmail, tokenName strin
 		return "", fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -615,7 +831,7 @@ func (c *Client) SuspendUserToken(ctx context.Context, userEmail, tokenName stri
 		return fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -661,7 +877,7 @@ func (c *Client) DeleteUserToken(ctx context.Context, userEmail, tokenName strin
 		return fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -1035,6 +1251,144 @@ func (c *Client) DeleteAccess(ctx context.Context, name, lastKnownVersion string
 	return nil
 }
 
+// CreateRateLimit creates an API rate limit.
+func (c *Client) CreateRateLimit(ctx context.Context, createReq *CreateRateLimitReq) (*api.RateLimit, error) {
+	body, err := json.Marshal(createReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal rate limit request: %w", err)
+	}
+
+	var r api.RateLimit
+	if err = c.createResource(ctx, "rate-limits", body, &r); err != nil {
+		return nil, fmt.Errorf("create rate limit: %w", err)
+	}
+
+	return &r, nil
+}
+
+// GetRateLimits fetches the API rate limits available for this agent.
+func (c *Client) GetRateLimits(ctx context.Context) ([]api.RateLimit, error) {
+	var rateLimits []api.RateLimit
+	if err := c.listResource(ctx, "rate-limits", &rateLimits); err != nil {
+		return nil, fmt.Errorf("list rate limits: %w", err)
+	}
+
+	return rateLimits, nil
+}
+
+// UpdateRateLimit updates an API rate limit.
+func (c *Client) UpdateRateLimit(ctx context.Context, name, lastKnownVersion string, updateReq *UpdateRateLimitReq) (*api.RateLimit, error) {
+	body, err := json.Marshal(updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal rate limit request: %w", err)
+	}
+
+	var r api.RateLimit
+	if err = c.updateResource(ctx, "rate-limits", name, lastKnownVersion, body, &r); err != nil {
+		return nil, fmt.Errorf("update rate limit: %w", err)
+	}
+
+	return &r, nil
+}
+
+// DeleteRateLimit deletes an API rate limit.
+func (c *Client) DeleteRateLimit(ctx context.Context, name, lastKnownVersion string) error {
+	if err := c.deleteResource(ctx, "rate-limits", name, lastKnownVersion); err != nil {
+		return fmt.Errorf("delete rate limit: %w", err)
+	}
+
+	return nil
+}
+
+// SetRateLimitStatus sends the status of an API rate limit to the platform.
+func (c *Client) SetRateLimitStatus(ctx context.Context, name string, status api.RateLimitStatus) error {
+	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "rate-limits", name, "status"))
+	if err != nil {
+		return fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	body, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("marshal status: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, baseURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
+	version.SetUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return apiErr
+	}
+
+	return nil
+}
+
+// CreateAPIVersion creates an API version.
+func (c *Client) CreateAPIVersion(ctx context.Context, createReq *CreateAPIVersionReq) (*api.APIVersion, error) {
+	body, err := json.Marshal(createReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal api version request: %w", err)
+	}
+
+	var v api.APIVersion
+	if err = c.createResource(ctx, "api-versions", body, &v); err != nil {
+		return nil, fmt.Errorf("create api version: %w", err)
+	}
+
+	return &v, nil
+}
+
+// GetAPIVersions fetches the API versions available for this agent.
+func (c *Client) GetAPIVersions(ctx context.Context) ([]api.APIVersion, error) {
+	var versions []api.APIVersion
+	if err := c.listResource(ctx, "api-versions", &versions); err != nil {
+		return nil, fmt.Errorf("list api versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// UpdateAPIVersion updates an API version.
+func (c *Client) UpdateAPIVersion(ctx context.Context, namespace, name, lastKnownVersion string, updateReq *UpdateAPIVersionReq) (*api.APIVersion, error) {
+	body, err := json.Marshal(updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal api version request: %w", err)
+	}
+
+	var v api.APIVersion
+	if err = c.updateResource(ctx, "api-versions", name+"@"+namespace, lastKnownVersion, body, &v); err != nil {
+		return nil, fmt.Errorf("update api version: %w", err)
+	}
+
+	return &v, nil
+}
+
+// DeleteAPIVersion deletes an API version.
+func (c *Client) DeleteAPIVersion(ctx context.Context, namespace, name, lastKnownVersion string) error {
+	if err := c.deleteResource(ctx, "api-versions", name+"@"+namespace, lastKnownVersion); err != nil {
+		return fmt.Errorf("delete api version: %w", err)
+	}
+
+	return nil
+}
+
 // GetWildcardCertificate gets a certificate for the workspace.
 func (c *Client) GetWildcardCertificate(ctx context.Context) (edgeingress.Certificate, error) {
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "wildcard-certificate"))
@@ -1047,7 +1401,7 @@ func (c *Client) GetWildcardCertificate(ctx context.Context) (edgeingress.Certif
 		return edgeingress.Certificate{}, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -1093,7 +1447,7 @@ func (c *Client) GetCertificateByDomains(ctx context.Context, domains []string)
 		return edgeingress.Certificate{}, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -1132,7 +1486,7 @@ func (c *Client) FetchTopology(ctx context.Context) (topology state.Cluster, top
 		return state.Cluster{}, 0, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	req.Header.Set("Accept-Encoding", "gzip")
 	version.SetUserAgent(req)
 
@@ -1178,7 +1532,7 @@ func (c *Client) PatchTopology(ctx context.Context, patch []byte, lastKnownVersi
 		return 0, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	req.Header.Set("Content-Type", "application/merge-patch+json")
 	req.Header.Set("Last-Known-Version", strconv.FormatInt(lastKnownVersion, 10))
 	version.SetUserAgent(req)
@@ -1221,7 +1575,7 @@ func (c *Client) ListPendingCommands(ctx context.Context) ([]Command, error) {
 		return nil, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -1248,6 +1602,94 @@ func (c *Client) ListPendingCommands(ctx context.Context) ([]Command, error) {
 	return commands, nil
 }
 
+// commandsPingInterval is the interval at which the commands subscription is checked to be alive.
+const commandsPingInterval = 30 * time.Second
+
+// SubscribeCommands subscribes to the commands pushed by the platform through a WebSocket, calling notify each time
+// the platform signals that new commands are pending. Pending commands are still fetched with ListPendingCommands,
+// messages only tell when to do so. It blocks until the subscription is closed by the platform or the context is done.
+func (c *Client) SubscribeCommands(ctx context.Context, notify func()) error {
+	u, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "commands", "subscribe"))
+	if err != nil {
+		return fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            c.egress.Proxy,
+		TLSClientConfig:  c.egress.TLSConfig,
+		HandshakeTimeout: 30 * time.Second,
+	}
+	conn, resp, err := dialer.DialContext(ctx, u.String(), http.Header{"Authorization": []string{"Bearer " + c.currentToken()}})
+	if err != nil {
+		if resp == nil {
+			return fmt.Errorf("dial: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return apiErr
+	}
+	defer func() { _ = conn.Close() }()
+
+	extendDeadline := func() error {
+		return conn.SetReadDeadline(time.Now().Add(2 * commandsPingInterval))
+	}
+	_ = extendDeadline()
+	conn.SetPongHandler(func(string) error { return extendDeadline() })
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		tick := time.NewTicker(commandsPingInterval)
+		defer tick.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+				_ = conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+				_ = conn.Close()
+				return
+			case <-tick.C:
+				// Closing the connection unblocks the pending read, ending the subscription.
+				if pingErr := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); pingErr != nil {
+					_ = conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		if _, _, err = conn.ReadMessage(); err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+
+			return fmt.Errorf("read: %w", err)
+		}
+
+		_ = extendDeadline()
+		notify()
+	}
+}
+
 // SubmitCommandReports submits the given command execution reports.
 func (c *Client) SubmitCommandReports(ctx context.Context, reports []CommandExecutionReport) error {
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "command-reports"))
@@ -1265,7 +1707,42 @@ func (c *Client) SubmitCommandReports(ctx context.Context, reports []CommandExec
 		return fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return apiErr
+	}
+
+	return nil
+}
+
+// UploadDiagnostics uploads the diagnostics bundle collected for the given command, a gzipped tarball.
+func (c *Client) UploadDiagnostics(ctx context.Context, commandID string, bundle []byte) error {
+	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "diagnostics", commandID))
+	if err != nil {
+		return fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL.String(), bytes.NewReader(bundle))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
+	req.Header.Set("Content-Type", "application/gzip")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -1329,6 +1806,9 @@ func readBody(resp *http.Response) ([]byte, error) {
 }
 
 func (c *Client) createResource(ctx context.Context, apiPath string, body []byte, obj any) error {
+	// The list of these resources changes: its cached version can't be used anymore.
+	defer c.listCache.invalidate(apiPath)
+
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, apiPath))
 	if err != nil {
 		return fmt.Errorf("parse endpoint: %w", err)
@@ -1339,7 +1819,7 @@ func (c *Client) createResource(ctx context.Context, apiPath string, body []byte
 		return fmt.Errorf("build request for %q: %w", baseURL.String(), err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -1366,6 +1846,8 @@ func (c *Client) createResource(ctx context.Context, apiPath string, body []byte
 	}
 }
 
+// listResource lists the resources of the given API path. Lists are requested conditionally with the ETag of the
+// last one received, and decoded from the cached list when the platform reports them as unchanged.
 func (c *Client) listResource(ctx context.Context, apiPath string, objs any) error {
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, apiPath))
 	if err != nil {
@@ -1377,16 +1859,35 @@ func (c *Client) listResource(ctx context.Context, apiPath string, objs any) err
 		return fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
+	cached, hasCached := c.listCache.get(apiPath)
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
+	var body []byte
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		body = cached.body
+	case resp.StatusCode == http.StatusOK:
+		if body, err = io.ReadAll(resp.Body); err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			c.listCache.set(apiPath, etag, body)
+		} else {
+			c.listCache.invalidate(apiPath)
+		}
+	default:
 		all, _ := io.ReadAll(resp.Body)
 
 		apiErr := APIError{StatusCode: resp.StatusCode}
@@ -1397,7 +1898,8 @@ func (c *Client) listResource(ctx context.Context, apiPath string, objs any) err
 		return apiErr
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&objs); err != nil {
+	if err = json.Unmarshal(body, &objs); err != nil {
+		c.listCache.invalidate(apiPath)
 		return fmt.Errorf("decode config: %w", err)
 	}
 
@@ -1405,6 +1907,9 @@ func (c *Client) listResource(ctx context.Context, apiPath string, objs any) err
 }
 
 func (c *Client) deleteResource(ctx context.Context, apiPath, name, lastKnownVersion string) error {
+	// The list of these resources changes: its cached version can't be used anymore.
+	defer c.listCache.invalidate(apiPath)
+
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, apiPath, name))
 	if err != nil {
 		return fmt.Errorf("parse endpoint: %w", err)
@@ -1415,7 +1920,7 @@ func (c *Client) deleteResource(ctx context.Context, apiPath, name, lastKnownVer
 		return fmt.Errorf("build request for %q: %w", baseURL.String(), err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	req.Header.Set("Last-Known-Version", lastKnownVersion)
 	version.SetUserAgent(req)
 
@@ -1441,6 +1946,9 @@ func (c *Client) deleteResource(ctx context.Context, apiPath, name, lastKnownVer
 }
 
 func (c *Client) updateResource(ctx context.Context, apiPath, name, lastKnownVersion string, body []byte, obj any) error {
+	// The list of these resources changes: its cached version can't be used anymore.
+	defer c.listCache.invalidate(apiPath)
+
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, apiPath, name))
 	if err != nil {
 		return fmt.Errorf("parse endpoint: %w", err)
@@ -1451,7 +1959,7 @@ func (c *Client) updateResource(ctx context.Context, apiPath, name, lastKnownVer
 		return fmt.Errorf("build request for %q: %w", baseURL.String(), err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	req.Header.Set("Last-Known-Version", lastKnownVersion)
 	version.SetUserAgent(req)
 
@@ -30,6 +30,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
@@ -251,6 +252,34 @@ func TestClient_Ping(t *testing.T) {
 	}
 }
 
+func TestClient_SetToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer rotated-token" {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	err = c.Ping(context.Background())
+	require.Error(t, err)
+
+	c.SetToken("rotated-token")
+
+	err = c.Ping(context.Background())
+	require.NoError(t, err)
+}
+
 func TestClient_ListVerifiedDomains(t *testing.T) {
 	tests := []struct {
 		desc             string
@@ -1260,6 +1289,69 @@ func TestClient_GetACPs(t *testing.T) {
 	assert.Equal(t, wantACPs, gotACPs)
 }
 
+func TestClient_GetACPs_conditionalRequests(t *testing.T) {
+	acps := []acp.ACP{{Name: "name", Version: "version-1"}}
+	etag := `"1"`
+
+	var gotIfNoneMatch []string
+	var sentBodies int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/acps", func(rw http.ResponseWriter, req *http.Request) {
+		gotIfNoneMatch = append(gotIfNoneMatch, req.Header.Get("If-None-Match"))
+
+		rw.Header().Set("ETag", etag)
+		if req.Header.Get("If-None-Match") == etag {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		sentBodies++
+		rw.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(rw).Encode(acps)
+		require.NoError(t, err)
+	})
+	mux.HandleFunc("/acps/", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	ctx := context.Background()
+
+	gotACPs, err := c.GetACPs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, acps, gotACPs)
+
+	// Unchanged ACPs are decoded from the cached response.
+	gotACPs, err = c.GetACPs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, acps, gotACPs)
+
+	// Changed ACPs are downloaded again.
+	acps = append(acps, acp.ACP{Name: "other", Version: "version-1"})
+	etag = `"2"`
+
+	gotACPs, err = c.GetACPs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, acps, gotACPs)
+
+	// Mutating ACPs drops the cached response.
+	require.NoError(t, c.DeleteACP(ctx, "version-1", "other"))
+
+	gotACPs, err = c.GetACPs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, acps, gotACPs)
+
+	assert.Equal(t, []string{"", `"1"`, `"1"`, ""}, gotIfNoneMatch)
+	assert.Equal(t, 3, sentBodies)
+}
+
 func TestClient_CreateACP(t *testing.T) {
 	tests := []struct {
 		desc             string
@@ -2148,6 +2240,96 @@ func TestClient_ListPendingCommands(t *testing.T) {
 	}
 }
 
+func TestClient_SubscribeCommands(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/commands/subscribe", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		for i := 0; i < 2; i++ {
+			if err = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"commands-pending"}`)); err != nil {
+				return
+			}
+		}
+
+		closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	var notified int
+	err = c.SubscribeCommands(context.Background(), func() { notified++ })
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, notified)
+
+	c, err = NewClient(srv.URL, "bad-token")
+	require.NoError(t, err)
+
+	err = c.SubscribeCommands(context.Background(), func() { notified++ })
+
+	var apiErr APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.Equal(t, 2, notified)
+}
+
+func TestClient_SubscribeCommands_stopsWithContext(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+
+	closed := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/commands/subscribe", func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		if err = conn.WriteMessage(websocket.TextMessage, []byte(`{}`)); err != nil {
+			return
+		}
+
+		_, _, err = conn.ReadMessage()
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			close(closed)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err = c.SubscribeCommands(ctx, cancel)
+	require.NoError(t, err)
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
+
 type reportErrorData struct {
 	Value int `json:"value"`
 }
@@ -2267,6 +2449,86 @@ func TestClient_SendCommandReports(t *testing.T) {
 	}
 }
 
+func TestClient_UploadDiagnostics(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statusCode int
+		wantErr    error
+	}{
+		{
+			desc:       "upload diagnostics succeed",
+			statusCode: http.StatusOK,
+		},
+		{
+			desc:       "upload diagnostics unexpected error",
+			statusCode: http.StatusTeapot,
+			wantErr: &APIError{
+				StatusCode: http.StatusTeapot,
+				Message:    "error",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var callCount int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/diagnostics/cmd-1", func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
+
+				if req.Method != http.MethodPost {
+					http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				if req.Header.Get("Content-Type") != "application/gzip" {
+					http.Error(rw, "Invalid content type", http.StatusUnsupportedMediaType)
+					return
+				}
+
+				gotBody, err := io.ReadAll(req.Body)
+				if err != nil {
+					http.Error(rw, "Read body", http.StatusBadRequest)
+					return
+				}
+
+				if !assert.Equal(t, "bundle", string(gotBody)) {
+					http.Error(rw, "Invalid body", http.StatusBadRequest)
+					return
+				}
+
+				rw.WriteHeader(test.statusCode)
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			err = c.UploadDiagnostics(context.Background(), "cmd-1", []byte("bundle"))
+			if test.wantErr != nil {
+				require.ErrorAs(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, 1, callCount)
+		})
+	}
+}
+
 func TestClient_GetAPIs(t *testing.T) {
 	wantAPIs := []api.API{
 		{
@@ -2889,6 +3151,111 @@ func TestClient_DeleteAccess(t *testing.T) {
 	}
 }
 
+func TestClient_GetRateLimits(t *testing.T) {
+	wantRateLimits := []api.RateLimit{
+		{
+			Name:   "name",
+			Groups: []string{"group"},
+			APISelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"key": "value"},
+			},
+			Limit:    100,
+			Period:   &metav1.Duration{Duration: time.Minute},
+			Strategy: "perConsumer",
+			Version:  "version-1",
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rate-limits", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(rw).Encode(wantRateLimits)
+		require.NoError(t, err)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	gotRateLimits, err := c.GetRateLimits(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, wantRateLimits, gotRateLimits)
+}
+
+func TestClient_SetRateLimitStatus(t *testing.T) {
+	tests := []struct {
+		desc             string
+		returnStatusCode int
+		wantErr          assert.ErrorAssertionFunc
+	}{
+		{
+			desc:             "rate limit status successfully sent",
+			returnStatusCode: http.StatusOK,
+			wantErr:          assert.NoError,
+		},
+		{
+			desc:             "status sent for an unknown rate limit",
+			returnStatusCode: http.StatusNotFound,
+			wantErr:          assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotStatus api.RateLimitStatus
+			mux := http.NewServeMux()
+			mux.HandleFunc("/rate-limits/name/status", func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPut {
+					http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				err := json.NewDecoder(req.Body).Decode(&gotStatus)
+				require.NoError(t, err)
+
+				rw.WriteHeader(test.returnStatusCode)
+			})
+
+			srv := httptest.NewServer(mux)
+
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			status := api.RateLimitStatus{APIs: []string{"orders@default"}}
+			err = c.SetRateLimitStatus(context.Background(), "name", status)
+			test.wantErr(t, err)
+
+			require.Equal(t, status, gotStatus)
+		})
+	}
+}
+
 func TestClient_ListUserToken(t *testing.T) {
 	tests := []struct {
 		desc             string
@@ -3210,3 +3577,50 @@ func TestClient_DeleteUserToken(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetAPIVersions(t *testing.T) {
+	wantVersions := []api.APIVersion{
+		{
+			Name:       "orders-v2",
+			Namespace:  "default",
+			APIName:    "orders",
+			Release:    "v2",
+			PathPrefix: "/orders/v2",
+			Service: &api.VersionService{
+				Name: "orders-v2",
+				Port: 80,
+			},
+			Version: "version-1",
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api-versions", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(rw).Encode(wantVersions)
+		require.NoError(t, err)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	gotVersions, err := c.GetAPIVersions(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, wantVersions, gotVersions)
+}
@@ -0,0 +1,261 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FaultInjectionConfig configures the faults injected in the platform API responses.
+type FaultInjectionConfig struct {
+	// Seed is the seed of the random source deciding whether faults are injected.
+	// A zero seed uses the current time.
+	Seed   int64   `json:"seed,omitempty"`
+	Faults []Fault `json:"faults"`
+}
+
+// Fault describes a failure injected in the platform API responses of an endpoint.
+type Fault struct {
+	// Endpoint is the path, relative to the platform URL, of the endpoints the fault applies to.
+	// It matches all the endpoints starting with this path. An empty endpoint matches all endpoints.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Method restricts the fault to the requests using this method. An empty method matches all methods.
+	Method string `json:"method,omitempty"`
+	// Probability is the probability, between 0 and 1, for the fault to be injected in a request.
+	Probability float64 `json:"probability"`
+
+	// Delay delays the request by the given duration.
+	Delay time.Duration `json:"-"`
+	// StatusCode makes the request fail with the given status code, without reaching the platform.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Error makes the request fail with a transport error, without reaching the platform.
+	Error bool `json:"error,omitempty"`
+	// Corrupt truncates the body of the platform response.
+	Corrupt bool `json:"corrupt,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing the delay as a duration string.
+func (f *Fault) UnmarshalJSON(data []byte) error {
+	type fault Fault
+	var raw struct {
+		fault
+
+		Delay string `json:"delay,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*f = Fault(raw.fault)
+
+	if raw.Delay != "" {
+		delay, err := time.ParseDuration(raw.Delay)
+		if err != nil {
+			return fmt.Errorf("parse delay: %w", err)
+		}
+		f.Delay = delay
+	}
+
+	return nil
+}
+
+// LoadFaultInjectionConfig loads a fault injection configuration from the given JSON file.
+func LoadFaultInjectionConfig(path string) (FaultInjectionConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return FaultInjectionConfig{}, fmt.Errorf("read file: %w", err)
+	}
+
+	var cfg FaultInjectionConfig
+	if err = json.Unmarshal(b, &cfg); err != nil {
+		return FaultInjectionConfig{}, fmt.Errorf("unmarshal config: %w", err)
+	}
+
+	for i, fault := range cfg.Faults {
+		if fault.Probability < 0 || fault.Probability > 1 {
+			return FaultInjectionConfig{}, fmt.Errorf("fault %d: probability must be between 0 and 1", i)
+		}
+		if fault.StatusCode != 0 && (fault.StatusCode < 100 || fault.StatusCode > 599) {
+			return FaultInjectionConfig{}, fmt.Errorf("fault %d: invalid status code %d", i, fault.StatusCode)
+		}
+		if fault.Delay < 0 {
+			return FaultInjectionConfig{}, fmt.Errorf("fault %d: delay must be positive", i)
+		}
+	}
+
+	return cfg, nil
+}
+
+// errInjectedFault is the error returned by requests failing because of an injected fault.
+var errInjectedFault = errors.New("injected fault")
+
+// faultTransport is an http.RoundTripper randomly injecting faults in the requests it sends.
+type faultTransport struct {
+	next     http.RoundTripper
+	basePath string
+	faults   []Fault
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+func newFaultTransport(next http.RoundTripper, basePath string, cfg FaultInjectionConfig) *faultTransport {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &faultTransport{
+		next:     next,
+		basePath: strings.TrimSuffix(basePath, "/"),
+		faults:   cfg.Faults,
+		rand:     rand.New(rand.NewSource(seed)), //nolint:gosec // No need to crypto randomness to inject faults.
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := strings.TrimPrefix(req.URL.Path, t.basePath)
+	inj := t.pick(req.Method, endpoint)
+
+	logger := log.Ctx(req.Context()).With().
+		Str("component", "platform_fault_injection").
+		Str("method", req.Method).
+		Str("endpoint", endpoint).
+		Logger()
+
+	if inj.delay > 0 {
+		logger.Warn().Dur("delay", inj.delay).Msg("Injecting delay")
+
+		timer := time.NewTimer(inj.delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if inj.fail {
+		logger.Warn().Msg("Injecting transport error")
+		return nil, errInjectedFault
+	}
+
+	if inj.statusCode != 0 {
+		logger.Warn().Int("status_code", inj.statusCode).Msg("Injecting error response")
+		return faultResponse(req, inj.statusCode), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !inj.corrupt {
+		return resp, err
+	}
+
+	logger.Warn().Msg("Injecting corrupted response")
+
+	if err = corruptBody(resp); err != nil {
+		return nil, fmt.Errorf("corrupt response: %w", err)
+	}
+
+	return resp, nil
+}
+
+// injection describes the faults to inject in a request.
+type injection struct {
+	delay      time.Duration
+	statusCode int
+	fail       bool
+	corrupt    bool
+}
+
+// pick rolls the dice for each fault matching the given request and returns the faults to inject.
+// Delays of the triggered faults add up and the first triggered status code wins.
+func (t *faultTransport) pick(method, endpoint string) injection {
+	var inj injection
+	for _, fault := range t.faults {
+		if !strings.HasPrefix(endpoint, fault.Endpoint) {
+			continue
+		}
+		if fault.Method != "" && !strings.EqualFold(fault.Method, method) {
+			continue
+		}
+		if !t.trigger(fault.Probability) {
+			continue
+		}
+
+		inj.delay += fault.Delay
+		if inj.statusCode == 0 {
+			inj.statusCode = fault.StatusCode
+		}
+		inj.fail = inj.fail || fault.Error
+		inj.corrupt = inj.corrupt || fault.Corrupt
+	}
+
+	return inj
+}
+
+func (t *faultTransport) trigger(probability float64) bool {
+	t.randMu.Lock()
+	defer t.randMu.Unlock()
+
+	return t.rand.Float64() < probability
+}
+
+func faultResponse(req *http.Request, statusCode int) *http.Response {
+	body, _ := json.Marshal(APIError{StatusCode: statusCode, Message: errInjectedFault.Error()})
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// corruptBody truncates the body of the given response, which is enough to break any JSON document.
+func corruptBody(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+
+	body = body[:len(body)/2]
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package platform
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFaultInjectionConfig(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content string
+		want    FaultInjectionConfig
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			desc: "valid config",
+			content: `{"seed":42,"faults":[
+				{"endpoint":"/topology","method":"PATCH","probability":0.5,"statusCode":503},
+				{"endpoint":"/config","probability":1,"delay":"3s","corrupt":true}
+			]}`,
+			want: FaultInjectionConfig{
+				Seed: 42,
+				Faults: []Fault{
+					{Endpoint: "/topology", Method: http.MethodPatch, Probability: 0.5, StatusCode: http.StatusServiceUnavailable},
+					{Endpoint: "/config", Probability: 1, Delay: 3 * time.Second, Corrupt: true},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			desc:    "invalid probability",
+			content: `{"faults":[{"probability":2,"error":true}]}`,
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "invalid status code",
+			content: `{"faults":[{"probability":1,"statusCode":42}]}`,
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "invalid delay",
+			content: `{"faults":[{"probability":1,"delay":"soon"}]}`,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "faults.json")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			got, err := LoadFaultInjectionConfig(path)
+			test.wantErr(t, err)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestClient_faultInjection(t *testing.T) {
+	tests := []struct {
+		desc       string
+		faults     []Fault
+		wantCalls  int
+		wantErr    assert.ErrorAssertionFunc
+		wantConfig Config
+	}{
+		{
+			desc: "fault on another endpoint",
+			faults: []Fault{
+				{Endpoint: "/topology", Probability: 1, Error: true},
+			},
+			wantCalls:  1,
+			wantErr:    assert.NoError,
+			wantConfig: Config{Features: []string{"feature"}},
+		},
+		{
+			desc: "fault on another method",
+			faults: []Fault{
+				{Endpoint: "/config", Method: http.MethodPost, Probability: 1, Error: true},
+			},
+			wantCalls:  1,
+			wantErr:    assert.NoError,
+			wantConfig: Config{Features: []string{"feature"}},
+		},
+		{
+			desc: "never triggered fault",
+			faults: []Fault{
+				{Endpoint: "/config", Probability: 0, Error: true},
+			},
+			wantCalls:  1,
+			wantErr:    assert.NoError,
+			wantConfig: Config{Features: []string{"feature"}},
+		},
+		{
+			desc: "delay",
+			faults: []Fault{
+				{Endpoint: "/config", Probability: 1, Delay: 10 * time.Millisecond},
+			},
+			wantCalls:  1,
+			wantErr:    assert.NoError,
+			wantConfig: Config{Features: []string{"feature"}},
+		},
+		{
+			desc: "error status code",
+			faults: []Fault{
+				{Endpoint: "/config", Probability: 1, StatusCode: http.StatusForbidden},
+			},
+			wantErr: assert.Error,
+		},
+		{
+			desc: "corrupted response",
+			faults: []Fault{
+				{Probability: 1, Corrupt: true},
+			},
+			wantCalls: 1,
+			wantErr:   assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var callCount int
+			mux := http.NewServeMux()
+			mux.HandleFunc("/agent/config", func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
+
+				rw.WriteHeader(http.StatusOK)
+				_, _ = rw.Write([]byte(`{"features":["feature"]}`))
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			c, err := NewClientWithFaultInjection(srv.URL+"/agent", testToken, FaultInjectionConfig{Seed: 1, Faults: test.faults})
+			require.NoError(t, err)
+
+			cfg, err := c.GetConfig(context.Background())
+			test.wantErr(t, err)
+
+			assert.Equal(t, test.wantCalls, callCount)
+			assert.Equal(t, test.wantConfig, cfg)
+		})
+	}
+}
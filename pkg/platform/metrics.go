@@ -0,0 +1,95 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package platform
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors reporting the calls made to the platform.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewMetrics creates the platform client collectors and registers them in the given registerer.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "platform",
+			Name:      "requests_total",
+			Help:      "Number of calls made to the platform, by endpoint, method and response code, \"error\" when no response was received.",
+		}, []string{"endpoint", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "hub_agent",
+			Subsystem: "platform",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the calls made to the platform, retries included, by endpoint and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "method"}),
+	}
+
+	if err := reg.Register(m.requests); err != nil {
+		return nil, fmt.Errorf("register requests counter: %w", err)
+	}
+	if err := reg.Register(m.duration); err != nil {
+		return nil, fmt.Errorf("register request duration histogram: %w", err)
+	}
+
+	return m, nil
+}
+
+// metricsTransport reports the calls going through it to the platform metrics.
+type metricsTransport struct {
+	next     http.RoundTripper
+	basePath string
+	metrics  *Metrics
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := endpointLabel(strings.TrimPrefix(req.URL.Path, t.basePath))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	t.metrics.duration.WithLabelValues(endpoint, req.Method).Observe(time.Since(start).Seconds())
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.requests.WithLabelValues(endpoint, req.Method, code).Inc()
+
+	return resp, err
+}
+
+// endpointLabel returns the first segment of the given path, relative to the platform URL, so that resource names and
+// user emails found in the next segments don't end up in metric labels.
+func endpointLabel(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		path = path[:i]
+	}
+
+	return "/" + path
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package platform
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SetMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/agent/ping", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/agent/acps/", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusConflict)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL+"/agent", testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+	c.SetMetrics(metrics)
+
+	require.NoError(t, c.Ping(context.Background()))
+	require.NoError(t, c.Ping(context.Background()))
+	require.Error(t, c.DeleteACP(context.Background(), "version", "my-acp"))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues("/ping", http.MethodPost, "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("/acps", http.MethodDelete, "409")))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.duration))
+}
+
+func TestEndpointLabel(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/ping", want: "/ping"},
+		{path: "/acps/my-acp", want: "/acps"},
+		{path: "/users/user@example.com/tokens", want: "/users"},
+		{path: "", want: "/"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.path, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, endpointLabel(test.path))
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package reconcile reconciles resources one by one, retrying the failing ones with an exponential backoff.
+package reconcile
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	workers       = 4
+	timeout       = 20 * time.Second
+	minRetryDelay = time.Second
+	maxRetryDelay = 5 * time.Minute
+)
+
+// Func reconciles the resource identified by the given key.
+type Func func(ctx context.Context, key string) error
+
+// Key returns the key identifying a resource, in the format of the informer caches keys: `namespace/name`, or `name`
+// for cluster-scoped resources.
+func Key(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	return namespace + "/" + name
+}
+
+// Queue reconciles the resources queued by their key with a pool of workers. Each resource is reconciled
+// independently: a failing resource is retried with an exponential backoff, without delaying the others.
+// A resource is never reconciled by two workers at the same time.
+type Queue struct {
+	kind      string
+	reconcile Func
+	workers   int
+	timeout   time.Duration
+
+	queue workqueue.RateLimitingInterface
+}
+
+// NewQueue returns a queue reconciling the resources of the given kind with the given function.
+func NewQueue(kind string, reconcile Func) *Queue {
+	return newQueue(kind, reconcile, minRetryDelay, maxRetryDelay)
+}
+
+func newQueue(kind string, reconcile Func, minDelay, maxDelay time.Duration) *Queue {
+	return &Queue{
+		kind:      kind,
+		reconcile: reconcile,
+		workers:   workers,
+		timeout:   timeout,
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(minDelay, maxDelay), kind),
+	}
+}
+
+// Add queues the resource identified by the given key for reconciliation. Failing resources waiting to be retried are
+// left to their backoff, the retry reconciles them against their latest state anyway.
+func (q *Queue) Add(key string) {
+	if q.queue.NumRequeues(key) > 0 {
+		return
+	}
+
+	q.queue.Add(key)
+}
+
+// Run reconciles the queued resources until the given context is canceled. The resources queued by then are still
+// handed to the reconcile function, with the canceled context, so that the queue drains before Run returns. Pending
+// retries are dropped.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for q.processNext(ctx) {
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	q.queue.ShutDown()
+	wg.Wait()
+}
+
+func (q *Queue) processNext(ctx context.Context) bool {
+	item, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(item)
+
+	key := item.(string)
+
+	ctxReconcile, cancel := context.WithTimeout(ctx, q.timeout)
+	err := q.reconcile(ctxReconcile, key)
+	cancel()
+
+	if err == nil {
+		q.queue.Forget(item)
+		return true
+	}
+
+	// Failures due to the shutdown are not worth reporting nor retrying.
+	if ctx.Err() != nil {
+		return true
+	}
+
+	log.Error().Err(err).
+		Str("kind", q.kind).
+		Str("key", key).
+		Int("retries", q.queue.NumRequeues(item)).
+		Msg("Unable to synchronize resource, retrying")
+
+	q.queue.AddRateLimited(item)
+
+	return true
+}
@@ -0,0 +1,104 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_Run_retriesFailingResources(t *testing.T) {
+	var (
+		callsMu sync.Mutex
+		calls   = make(map[string]int)
+	)
+	done := make(chan struct{})
+
+	q := newQueue("test", func(_ context.Context, key string) error {
+		callsMu.Lock()
+		defer callsMu.Unlock()
+
+		calls[key]++
+		if key == "failing" && calls[key] < 3 {
+			return errors.New("boom")
+		}
+		if key == "failing" {
+			close(done)
+		}
+
+		return nil
+	}, time.Millisecond, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(stop)
+	}()
+
+	q.Add("failing")
+	q.Add("ok")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "failing resource not retried")
+	}
+
+	cancel()
+	<-stop
+
+	assert.Equal(t, map[string]int{"failing": 3, "ok": 1}, calls)
+	assert.Zero(t, q.queue.NumRequeues("failing"))
+}
+
+func TestQueue_Add_backingOff(t *testing.T) {
+	q := newQueue("test", nil, time.Hour, time.Hour)
+
+	q.queue.AddRateLimited("failing")
+	q.Add("failing")
+	q.Add("ok")
+
+	assert.Equal(t, 1, q.queue.Len())
+}
+
+func TestQueue_Run_drainsOnShutdown(t *testing.T) {
+	var reconciled []string
+	q := newQueue("test", func(_ context.Context, key string) error {
+		reconciled = append(reconciled, key)
+		return errors.New("boom")
+	}, time.Millisecond, time.Millisecond)
+	q.workers = 1
+
+	q.Add("a")
+	q.Add("b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	q.Run(ctx)
+
+	assert.Equal(t, []string{"a", "b"}, reconciled)
+	assert.True(t, q.queue.ShuttingDown())
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package secretref
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSecretsManagerConfig configures an AWSSecretsManagerProvider.
+type AWSSecretsManagerConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Prefix is prepended to the <namespace>/<name> identifier of the secrets.
+	Prefix string
+	// Endpoint overrides the Secrets Manager endpoint of the region.
+	Endpoint string
+}
+
+// AWSSecretsManagerProvider fetches secrets from AWS Secrets Manager. The secret referenced as <name> in <namespace> is
+// the secret identified by <prefix><namespace>/<name>, whose string value must be a JSON object of its entries.
+type AWSSecretsManagerProvider struct {
+	cfg    AWSSecretsManagerConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewAWSSecretsManagerProvider creates a new AWSSecretsManagerProvider.
+func NewAWSSecretsManagerProvider(cfg AWSSecretsManagerConfig) (*AWSSecretsManagerProvider, error) {
+	if cfg.Region == "" {
+		return nil, errors.New("aws region is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("aws credentials are required")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://secretsmanager." + cfg.Region + ".amazonaws.com"
+	}
+
+	return &AWSSecretsManagerProvider{
+		cfg:    cfg,
+		client: newProviderClient(),
+		now:    time.Now,
+	}, nil
+}
+
+// Secret implements Provider.
+func (p *AWSSecretsManagerProvider) Secret(ctx context.Context, ref Ref) (map[string][]byte, error) {
+	secretID := p.cfg.Prefix + ref.Namespace + "/" + ref.Name
+
+	body, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(p.cfg.Endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	signV4(req, body, "secretsmanager", p.cfg, p.now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get aws secret %q: %w", secretID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+
+		if strings.HasSuffix(apiErr.Type, "ResourceNotFoundException") {
+			return nil, fmt.Errorf("aws secret %q not found", secretID)
+		}
+
+		return nil, fmt.Errorf("get aws secret %q: unexpected status code %d: %s %s", secretID, resp.StatusCode, apiErr.Type, apiErr.Message)
+	}
+
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("decode aws secret %q: %w", secretID, err)
+	}
+	if secret.SecretString == nil {
+		return nil, fmt.Errorf("aws secret %q has no string value", secretID)
+	}
+
+	var entries map[string]string
+	if err = json.Unmarshal([]byte(*secret.SecretString), &entries); err != nil {
+		return nil, fmt.Errorf("aws secret %q value is not a JSON object: %w", secretID, err)
+	}
+
+	data := make(map[string][]byte, len(entries))
+	for key, value := range entries {
+		data[key] = []byte(value)
+	}
+
+	return data, nil
+}
+
+// signV4 signs the given request for the given AWS service with the Signature Version 4 process, covering all of its
+// headers.
+func signV4(req *http.Request, body []byte, service string, cfg AWSSecretsManagerConfig, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	uri := req.URL.EscapedPath()
+	if uri == "" {
+		uri = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uri,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := date + "/" + cfg.Region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_signV4(t *testing.T) {
+	// "get-vanilla" case of the AWS Signature Version 4 test suite.
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", http.NoBody)
+	require.NoError(t, err)
+
+	cfg := AWSSecretsManagerConfig{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	signV4(req, nil, "service", cfg, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", req.Header.Get("Authorization"))
+}
+
+func TestAWSSecretsManagerProvider_Secret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key-id/") ||
+			req.Header.Get("X-Amz-Security-Token") != "session" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body struct {
+			SecretID string `json:"SecretId"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch body.SecretID {
+		case "hub/ns/secret":
+			_ = json.NewEncoder(rw).Encode(map[string]string{"SecretString": `{"value":"api-key"}`})
+		case "hub/ns/binary":
+			_ = json.NewEncoder(rw).Encode(map[string]string{"SecretBinary": "YXBpLWtleQ=="})
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(rw).Encode(map[string]string{
+				"__type":  "ResourceNotFoundException",
+				"message": "Secrets Manager can't find the specified secret.",
+			})
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	provider, err := NewAWSSecretsManagerProvider(AWSSecretsManagerConfig{
+		Region:          "eu-west-1",
+		AccessKeyID:     "key-id",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Prefix:          "hub/",
+		Endpoint:        srv.URL,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc    string
+		ref     Ref
+		want    map[string][]byte
+		wantErr string
+	}{
+		{
+			desc: "existing secret",
+			ref:  Ref{Namespace: "ns", Name: "secret"},
+			want: map[string][]byte{"value": []byte("api-key")},
+		},
+		{
+			desc:    "binary secret",
+			ref:     Ref{Namespace: "ns", Name: "binary"},
+			wantErr: `aws secret "hub/ns/binary" has no string value`,
+		},
+		{
+			desc:    "unknown secret",
+			ref:     Ref{Namespace: "other", Name: "secret"},
+			wantErr: `aws secret "hub/other/secret" not found`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := provider.Secret(context.Background(), test.ref)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package secretref
+
+import (
+	"sort"
+	"sync"
+)
+
+// Changes collects the names of the resources whose referenced secrets changed, until a watcher takes them. Its Add
+// method never blocks, so it can be registered as a Resolver listener.
+type Changes struct {
+	namesMu sync.Mutex
+	names   map[string]struct{}
+
+	signal chan struct{}
+}
+
+// NewChanges creates new Changes.
+func NewChanges() *Changes {
+	return &Changes{
+		names:  make(map[string]struct{}),
+		signal: make(chan struct{}, 1),
+	}
+}
+
+// Add records a change of the secrets referenced by the given resource.
+func (c *Changes) Add(name string) {
+	c.namesMu.Lock()
+	c.names[name] = struct{}{}
+	c.namesMu.Unlock()
+
+	select {
+	case c.signal <- struct{}{}:
+	default:
+	}
+}
+
+// C returns a channel receiving a value when changes are waiting to be taken.
+func (c *Changes) C() <-chan struct{} {
+	return c.signal
+}
+
+// Take returns the sorted names of the resources having changed since the last call.
+func (c *Changes) Take() []string {
+	c.namesMu.Lock()
+	defer c.namesMu.Unlock()
+
+	names := make([]string, 0, len(c.names))
+	for name := range c.names {
+		names = append(names, name)
+	}
+	c.names = make(map[string]struct{})
+
+	sort.Strings(names)
+
+	return names
+}
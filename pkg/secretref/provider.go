@@ -0,0 +1,83 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package secretref
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	corev1lister "k8s.io/client-go/listers/core/v1"
+)
+
+// Provider fetches the entries of secrets from a secret store.
+type Provider interface {
+	Secret(ctx context.Context, ref Ref) (map[string][]byte, error)
+}
+
+// providerTimeout is the timeout of the requests of the providers fetching secrets from an external secret manager.
+const providerTimeout = 10 * time.Second
+
+func newProviderClient() *http.Client {
+	return &http.Client{Timeout: providerTimeout}
+}
+
+// listerProvider fetches Kubernetes secrets from an informer cache.
+type listerProvider struct {
+	secrets corev1lister.SecretLister
+}
+
+func (p listerProvider) Secret(_ context.Context, ref Ref) (map[string][]byte, error) {
+	s, err := p.secrets.Secrets(ref.Namespace).Get(ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Data, nil
+}
+
+// agentSecretsProvider reads the secrets managed by the agent itself from Kubernetes, and the other ones from an
+// external secret manager.
+type agentSecretsProvider struct {
+	provider     Provider
+	kubernetes   listerProvider
+	agentSecrets map[Ref]struct{}
+}
+
+// NewAgentSecretsProvider returns a provider reading the given secrets, managed by the agent itself, from the
+// Kubernetes secrets of the given lister, and the other ones from the given provider.
+func NewAgentSecretsProvider(provider Provider, secrets corev1lister.SecretLister, agentSecrets ...Ref) Provider {
+	p := agentSecretsProvider{
+		provider:     provider,
+		kubernetes:   listerProvider{secrets: secrets},
+		agentSecrets: make(map[Ref]struct{}, len(agentSecrets)),
+	}
+	for _, ref := range agentSecrets {
+		p.agentSecrets[ref] = struct{}{}
+	}
+
+	return p
+}
+
+func (p agentSecretsProvider) Secret(ctx context.Context, ref Ref) (map[string][]byte, error) {
+	if _, ok := p.agentSecrets[ref]; ok {
+		return p.kubernetes.Secret(ctx, ref)
+	}
+
+	return p.provider.Secret(ctx, ref)
+}
@@ -0,0 +1,265 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package secretref resolves the secrets referenced by hub resources, from Kubernetes or an external secret manager,
+// and notifies the resources when the Kubernetes secrets they reference change.
+package secretref
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Ref locates a Kubernetes secret.
+type Ref struct {
+	Namespace string
+	Name      string
+}
+
+// String implements fmt.Stringer.
+func (r Ref) String() string {
+	return r.Name + "@" + r.Namespace
+}
+
+// RefOf returns the secret located by the given reference, defaulting to the given namespace.
+func RefOf(ref hubv1alpha1.SecretReference, namespace string) Ref {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	return Ref{Namespace: namespace, Name: ref.Name}
+}
+
+// KeyOf returns the entry referenced by the given reference, defaulting to the given key.
+func KeyOf(ref hubv1alpha1.SecretReference, defaultKey string) string {
+	if ref.Key != "" {
+		return ref.Key
+	}
+
+	return defaultKey
+}
+
+// Owner identifies a resource referencing secrets.
+type Owner struct {
+	Kind string
+	Name string
+}
+
+// Resolver resolves secrets from a Provider, by default an informer cache so resolving them doesn't hit the Kubernetes
+// API. Resources declare the secrets they reference with Track, and get notified when they change through the
+// listeners registered with OnChange. The Resolver must be registered as an event handler of the secret informer
+// backing its lister. Changes of secrets held by an external secret manager are not notified, they are picked up the
+// next time the resources resolve them.
+type Resolver struct {
+	provider Provider
+
+	refsMu sync.RWMutex
+	// owners are the resources referencing each secret.
+	owners map[Ref]map[Owner]struct{}
+	// refs are the secrets referenced by each resource.
+	refs map[Owner][]Ref
+
+	listenersMu sync.RWMutex
+	listeners   map[string][]func(name string)
+}
+
+// NewResolver creates a new Resolver resolving the Kubernetes secrets of the given lister.
+func NewResolver(secrets corev1lister.SecretLister) *Resolver {
+	return NewResolverWithProvider(listerProvider{secrets: secrets})
+}
+
+// NewResolverWithProvider creates a new Resolver resolving secrets from the given provider.
+func NewResolverWithProvider(provider Provider) *Resolver {
+	return &Resolver{
+		provider:  provider,
+		owners:    make(map[Ref]map[Owner]struct{}),
+		refs:      make(map[Owner][]Ref),
+		listeners: make(map[string][]func(name string)),
+	}
+}
+
+// Secret returns the entries of the given secret.
+func (r *Resolver) Secret(ref Ref) (map[string][]byte, error) {
+	data, err := r.provider.Secret(context.Background(), ref)
+	if err != nil {
+		return nil, fmt.Errorf("getting secret %q in namespace %q: %w", ref.Name, ref.Namespace, err)
+	}
+
+	return data, nil
+}
+
+// Value returns the value of the given key in the given secret.
+func (r *Resolver) Value(ref Ref, key string) ([]byte, error) {
+	data, err := r.Secret(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("no key %q in secret %q in namespace %q", key, ref.Name, ref.Namespace)
+	}
+
+	return value, nil
+}
+
+// TLS returns the certificate and private key held by the given TLS secret.
+func (r *Resolver) TLS(ref Ref) (cert, key []byte, err error) {
+	cert, err = r.Value(ref, corev1.TLSCertKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err = r.Value(ref, corev1.TLSPrivateKeyKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cert, key, nil
+}
+
+// GetValue returns the value of the given key in the given Kubernetes secret.
+func (r *Resolver) GetValue(secret *corev1.SecretReference, key string) ([]byte, error) {
+	return r.Value(Ref{Namespace: secret.Namespace, Name: secret.Name}, key)
+}
+
+// Track records the secrets referenced by the given resource, replacing the ones previously recorded. Tracking a
+// resource with no secret stops tracking it. A nil Resolver tracks nothing.
+func (r *Resolver) Track(owner Owner, refs ...Ref) {
+	if r == nil {
+		return
+	}
+
+	r.refsMu.Lock()
+	defer r.refsMu.Unlock()
+
+	for _, ref := range r.refs[owner] {
+		delete(r.owners[ref], owner)
+		if len(r.owners[ref]) == 0 {
+			delete(r.owners, ref)
+		}
+	}
+
+	if len(refs) == 0 {
+		delete(r.refs, owner)
+		return
+	}
+
+	r.refs[owner] = refs
+	for _, ref := range refs {
+		if r.owners[ref] == nil {
+			r.owners[ref] = make(map[Owner]struct{})
+		}
+		r.owners[ref][owner] = struct{}{}
+	}
+}
+
+// Untrack stops tracking the secrets referenced by the given resource.
+func (r *Resolver) Untrack(owner Owner) {
+	r.Track(owner)
+}
+
+// OnChange registers a listener called with the name of the resources of the given kind referencing a secret when it
+// is created, updated or deleted. Listeners must not block. A nil Resolver never calls them.
+func (r *Resolver) OnChange(kind string, listener func(name string)) {
+	if r == nil {
+		return
+	}
+
+	r.listenersMu.Lock()
+	defer r.listenersMu.Unlock()
+
+	r.listeners[kind] = append(r.listeners[kind], listener)
+}
+
+// OnAdd implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
+func (r *Resolver) OnAdd(obj interface{}) {
+	r.notify(obj)
+}
+
+// OnUpdate implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
+func (r *Resolver) OnUpdate(oldObj, newObj interface{}) {
+	oldSecret, ok := oldObj.(*corev1.Secret)
+	if ok && oldSecret.ResourceVersion == newObj.(*corev1.Secret).ResourceVersion {
+		// Informer resyncs deliver updates of unchanged secrets.
+		return
+	}
+
+	r.notify(newObj)
+}
+
+// OnDelete implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
+func (r *Resolver) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	r.notify(obj)
+}
+
+func (r *Resolver) notify(obj interface{}) {
+	s, ok := obj.(*corev1.Secret)
+	if !ok {
+		log.Error().
+			Str("type", fmt.Sprintf("%T", obj)).
+			Msg("Received event of unknown type")
+		return
+	}
+
+	ref := Ref{Namespace: s.Namespace, Name: s.Name}
+
+	r.refsMu.RLock()
+	owners := make([]Owner, 0, len(r.owners[ref]))
+	for owner := range r.owners[ref] {
+		owners = append(owners, owner)
+	}
+	r.refsMu.RUnlock()
+
+	if len(owners) == 0 {
+		return
+	}
+
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Kind != owners[j].Kind {
+			return owners[i].Kind < owners[j].Kind
+		}
+		return owners[i].Name < owners[j].Name
+	})
+
+	r.listenersMu.RLock()
+	defer r.listenersMu.RUnlock()
+
+	for _, owner := range owners {
+		log.Debug().
+			Str("secret", ref.String()).
+			Str("kind", owner.Kind).
+			Str("name", owner.Name).
+			Msg("Referenced secret changed")
+
+		for _, listener := range r.listeners[owner.Kind] {
+			listener(owner.Name)
+		}
+	}
+}
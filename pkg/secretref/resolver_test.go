@@ -0,0 +1,179 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package secretref
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestRefOf(t *testing.T) {
+	tests := []struct {
+		desc      string
+		ref       hubv1alpha1.SecretReference
+		namespace string
+		want      Ref
+	}{
+		{
+			desc:      "default namespace",
+			ref:       hubv1alpha1.SecretReference{Name: "secret"},
+			namespace: "ns",
+			want:      Ref{Namespace: "ns", Name: "secret"},
+		},
+		{
+			desc:      "referenced namespace",
+			ref:       hubv1alpha1.SecretReference{Name: "secret", Namespace: "other"},
+			namespace: "ns",
+			want:      Ref{Namespace: "other", Name: "secret"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, RefOf(test.ref, test.namespace))
+		})
+	}
+}
+
+func TestResolver_Value(t *testing.T) {
+	resolver := NewResolver(newSecretLister(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data: map[string][]byte{
+			"value":   []byte("api-key"),
+			"tls.crt": []byte("cert"),
+			"tls.key": []byte("key"),
+		},
+	}))
+
+	tests := []struct {
+		desc    string
+		ref     Ref
+		key     string
+		want    []byte
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			desc:    "existing key",
+			ref:     Ref{Namespace: "ns", Name: "secret"},
+			key:     "value",
+			want:    []byte("api-key"),
+			wantErr: assert.NoError,
+		},
+		{
+			desc:    "unknown key",
+			ref:     Ref{Namespace: "ns", Name: "secret"},
+			key:     "unknown",
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "unknown secret",
+			ref:     Ref{Namespace: "other", Name: "secret"},
+			key:     "value",
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := resolver.Value(test.ref, test.key)
+			test.wantErr(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	cert, key, err := resolver.TLS(Ref{Namespace: "ns", Name: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert"), cert)
+	assert.Equal(t, []byte("key"), key)
+}
+
+func TestResolver_notifiesOwners(t *testing.T) {
+	resolver := NewResolver(newSecretLister(t))
+
+	var edgeIngresses, gateways []string
+	resolver.OnChange("EdgeIngress", func(name string) { edgeIngresses = append(edgeIngresses, name) })
+	resolver.OnChange("APIGateway", func(name string) { gateways = append(gateways, name) })
+
+	cert := Ref{Namespace: "ns", Name: "cert"}
+	resolver.Track(Owner{Kind: "EdgeIngress", Name: "b@ns"}, cert)
+	resolver.Track(Owner{Kind: "EdgeIngress", Name: "a@ns"}, cert)
+	resolver.Track(Owner{Kind: "APIGateway", Name: "gateway"}, cert, Ref{Namespace: "ns", Name: "other"})
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cert", Namespace: "ns", ResourceVersion: "1"}}
+	resolver.OnAdd(secret)
+	assert.Equal(t, []string{"a@ns", "b@ns"}, edgeIngresses)
+	assert.Equal(t, []string{"gateway"}, gateways)
+
+	// Resyncs of unchanged secrets are ignored.
+	resolver.OnUpdate(secret, secret)
+	assert.Len(t, edgeIngresses, 2)
+
+	// Owners stop being notified once untracked, or once they no longer reference the secret.
+	resolver.Untrack(Owner{Kind: "EdgeIngress", Name: "b@ns"})
+	resolver.Track(Owner{Kind: "APIGateway", Name: "gateway"}, Ref{Namespace: "ns", Name: "other"})
+
+	resolver.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns/cert", Obj: secret})
+	assert.Equal(t, []string{"a@ns", "b@ns", "a@ns"}, edgeIngresses)
+	assert.Equal(t, []string{"gateway"}, gateways)
+
+	// Unreferenced secrets notify nobody.
+	resolver.OnAdd(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unknown", Namespace: "ns"}})
+	assert.Len(t, edgeIngresses, 3)
+	assert.Len(t, gateways, 1)
+}
+
+func TestChanges(t *testing.T) {
+	changes := NewChanges()
+	assert.Empty(t, changes.Take())
+
+	changes.Add("b")
+	changes.Add("a")
+	changes.Add("b")
+
+	select {
+	case <-changes.C():
+	default:
+		t.Fatal("Changes not signaled")
+	}
+
+	assert.Equal(t, []string{"a", "b"}, changes.Take())
+	assert.Empty(t, changes.Take())
+}
+
+func newSecretLister(t *testing.T, secrets ...*corev1.Secret) corev1lister.SecretLister {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, secret := range secrets {
+		require.NoError(t, indexer.Add(secret))
+	}
+
+	return corev1lister.NewSecretLister(indexer)
+}
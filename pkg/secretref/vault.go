@@ -0,0 +1,105 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package secretref
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// VaultConfig configures a VaultProvider.
+type VaultConfig struct {
+	// Address is the address of the Vault server, like https://vault.example.com:8200.
+	Address string
+	Token   string
+	// Mount is the mount path of the KV version 2 secrets engine, "secret" by default.
+	Mount string
+	// PathPrefix is prepended to the <namespace>/<name> path of the secrets.
+	PathPrefix string
+}
+
+// VaultProvider fetches secrets from the KV version 2 secrets engine of HashiCorp Vault. The secret referenced as
+// <name> in <namespace> is read at <mount>/data/<prefix>/<namespace>/<name>.
+type VaultProvider struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// NewVaultProvider creates a new VaultProvider.
+func NewVaultProvider(cfg VaultConfig) (*VaultProvider, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("vault token is required")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+
+	return &VaultProvider{
+		cfg:    cfg,
+		client: newProviderClient(),
+	}, nil
+}
+
+// Secret implements Provider.
+func (p *VaultProvider) Secret(ctx context.Context, ref Ref) (map[string][]byte, error) {
+	secretPath := path.Join(p.cfg.PathPrefix, ref.Namespace, ref.Name)
+	url := strings.TrimSuffix(p.cfg.Address, "/") + "/v1/" + strings.Trim(p.cfg.Mount, "/") + "/data/" + secretPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("read vault secret %q: %w", secretPath, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("vault secret %q not found", secretPath)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("read vault secret %q: unexpected status code %d", secretPath, resp.StatusCode)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("decode vault secret %q: %w", secretPath, err)
+	}
+
+	data := make(map[string][]byte, len(secret.Data.Data))
+	for key, value := range secret.Data.Data {
+		data[key] = []byte(value)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package secretref
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVaultProvider_Secret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "token" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch req.URL.Path {
+		case "/v1/kv/data/hub/ns/secret":
+			_, _ = rw.Write([]byte(`{"data":{"data":{"tls.crt":"cert","tls.key":"key"},"metadata":{"version":3}}}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	provider, err := NewVaultProvider(VaultConfig{
+		Address:    srv.URL,
+		Token:      "token",
+		Mount:      "kv",
+		PathPrefix: "hub",
+	})
+	require.NoError(t, err)
+
+	resolver := NewResolverWithProvider(provider)
+
+	cert, key, err := resolver.TLS(Ref{Namespace: "ns", Name: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert"), cert)
+	assert.Equal(t, []byte("key"), key)
+
+	_, err = resolver.Value(Ref{Namespace: "other", Name: "secret"}, "value")
+	assert.EqualError(t, err, `getting secret "secret" in namespace "other": vault secret "hub/other/secret" not found`)
+
+	// The secrets managed by the agent are still read from Kubernetes.
+	resolver = NewResolverWithProvider(NewAgentSecretsProvider(provider, newSecretLister(t, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-secret", Namespace: "hub"},
+		Data:       map[string][]byte{"key": []byte("session-key")},
+	}), Ref{Namespace: "hub", Name: "hub-secret"}))
+
+	value, err := resolver.Value(Ref{Namespace: "hub", Name: "hub-secret"}, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("session-key"), value)
+
+	value, err = resolver.Value(Ref{Namespace: "ns", Name: "secret"}, "tls.crt")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert"), value)
+
+	_, err = NewVaultProvider(VaultConfig{Address: srv.URL})
+	assert.EqualError(t, err, "vault token is required")
+}
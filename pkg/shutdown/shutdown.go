@@ -0,0 +1,222 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package shutdown coordinates the graceful shutdown of the agent commands: once asked to stop, a command stops
+// accepting new work, lets the in-flight work complete, flushes its pending work, then exits within a grace period.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+)
+
+// DefaultGracePeriod is the default grace period, below the default termination grace period of Kubernetes pods.
+const DefaultGracePeriod = 25 * time.Second
+
+// Phase is a step of the shutdown. Phases are run in order, and the hooks of a phase concurrently.
+type Phase int
+
+// Shutdown phases.
+const (
+	// PhaseDrain stops the servers from accepting new connections, and waits for the in-flight requests, such as
+	// admission reviews or auth requests, to complete.
+	PhaseDrain Phase = iota
+	// PhaseFlush sends the pending work, such as topology patches or metrics, to the platform.
+	PhaseFlush
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseDrain:
+		return "drain"
+	case PhaseFlush:
+		return "flush"
+	default:
+		return fmt.Sprintf("phase %d", int(p))
+	}
+}
+
+var phases = []Phase{PhaseDrain, PhaseFlush}
+
+// Hook is run during the shutdown. It must return once the given context is done.
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	run  Hook
+}
+
+// Coordinator runs the shutdown hooks registered by the components of a command.
+type Coordinator struct {
+	gracePeriod time.Duration
+
+	shuttingDown atomic.Bool
+
+	mu    sync.Mutex
+	hooks map[Phase][]namedHook
+}
+
+// NewCoordinator creates a coordinator completing the shutdown within the given grace period.
+func NewCoordinator(gracePeriod time.Duration) *Coordinator {
+	return &Coordinator{
+		gracePeriod: gracePeriod,
+		hooks:       make(map[Phase][]namedHook),
+	}
+}
+
+// Register registers a hook to run during the given phase of the shutdown.
+func (c *Coordinator) Register(phase Phase, name string, hook Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hooks[phase] = append(c.hooks[phase], namedHook{name: name, run: hook})
+}
+
+// ShuttingDown returns whether the shutdown started. Readiness probes report the command as not ready from then on, so
+// no new work is sent to it.
+func (c *Coordinator) ShuttingDown() bool {
+	return c.shuttingDown.Load()
+}
+
+// Shutdown runs the hooks, phase after phase. It returns once they are all completed, or once the grace period
+// elapsed, the remaining hooks being abandoned and the remaining phases skipped.
+func (c *Coordinator) Shutdown() error {
+	if !c.shuttingDown.CompareAndSwap(false, true) {
+		return errors.New("already shutting down")
+	}
+
+	log.Info().Dur("grace_period", c.gracePeriod).Msg("Shutting down")
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.gracePeriod)
+	defer cancel()
+
+	c.mu.Lock()
+	hooks := c.hooks
+	c.mu.Unlock()
+
+	var errs []error
+	for _, phase := range phases {
+		if ctx.Err() != nil {
+			if len(hooks[phase]) > 0 {
+				errs = append(errs, fmt.Errorf("%s: skipped, grace period exceeded", phase))
+			}
+			continue
+		}
+
+		if err := runPhase(ctx, phase, hooks[phase]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	log.Info().Dur("duration", time.Since(start)).Msg("Shutdown completed")
+
+	return nil
+}
+
+func runPhase(ctx context.Context, phase Phase, hooks []namedHook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	type result struct {
+		hook int
+		err  error
+	}
+
+	results := make(chan result, len(hooks))
+	pending := make(map[int]struct{}, len(hooks))
+	for i, hook := range hooks {
+		pending[i] = struct{}{}
+
+		go func(i int, hook namedHook) {
+			results <- result{hook: i, err: hook.run(ctx)}
+		}(i, hook)
+	}
+
+	var errs []error
+	for len(pending) > 0 {
+		select {
+		case res := <-results:
+			delete(pending, res.hook)
+
+			if res.err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", phase, hooks[res.hook].name, res.err))
+			}
+
+		case <-ctx.Done():
+			names := make([]string, 0, len(pending))
+			for i := range pending {
+				names = append(names, hooks[i].name)
+			}
+			sort.Strings(names)
+
+			errs = append(errs, fmt.Errorf("%s: grace period exceeded, abandoned %s", phase, strings.Join(names, ", ")))
+
+			return errors.Join(errs...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// HTTPServer returns a hook shutting down the given server gracefully, closing it if its requests don't complete in
+// time.
+func HTTPServer(server *http.Server) Hook {
+	return func(ctx context.Context) error {
+		if err := server.Shutdown(ctx); err != nil {
+			_ = server.Close()
+			return err
+		}
+
+		return nil
+	}
+}
+
+// GRPCServer returns a hook stopping the given server gracefully, stopping it right away if its requests don't
+// complete in time.
+func GRPCServer(server *grpc.Server) Hook {
+	return func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			server.Stop()
+			return ctx.Err()
+		}
+	}
+}
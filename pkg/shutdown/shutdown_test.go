@@ -0,0 +1,154 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinator_Shutdown(t *testing.T) {
+	c := NewCoordinator(5 * time.Second)
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, name)
+	}
+
+	// The hooks of a phase run concurrently: each drain hook waits for the other one.
+	serverA, serverB := make(chan struct{}), make(chan struct{})
+	c.Register(PhaseFlush, "topology", func(_ context.Context) error {
+		record("topology")
+		return nil
+	})
+	c.Register(PhaseDrain, "server A", func(_ context.Context) error {
+		close(serverA)
+		<-serverB
+		record("server A")
+		return nil
+	})
+	c.Register(PhaseDrain, "server B", func(_ context.Context) error {
+		close(serverB)
+		<-serverA
+		record("server B")
+		return nil
+	})
+
+	assert.False(t, c.ShuttingDown())
+
+	require.NoError(t, c.Shutdown())
+
+	assert.True(t, c.ShuttingDown())
+	assert.ElementsMatch(t, []string{"server A", "server B"}, calls[:2])
+	assert.Equal(t, "topology", calls[2])
+
+	assert.Error(t, c.Shutdown())
+}
+
+func TestCoordinator_Shutdown_errors(t *testing.T) {
+	c := NewCoordinator(200 * time.Millisecond)
+
+	c.Register(PhaseDrain, "server", func(_ context.Context) error {
+		return errors.New("boom")
+	})
+	c.Register(PhaseDrain, "stuck", func(_ context.Context) error {
+		select {}
+	})
+	c.Register(PhaseFlush, "metrics", func(_ context.Context) error {
+		t.Error("flush run after the grace period")
+		return nil
+	})
+
+	start := time.Now()
+	err := c.Shutdown()
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "drain server: boom")
+	assert.ErrorContains(t, err, "drain: grace period exceeded, abandoned stuck")
+
+	// No time is left to flush.
+	assert.ErrorContains(t, err, "flush: skipped, grace period exceeded")
+}
+
+func TestHTTPServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	received := make(chan struct{})
+	release := make(chan struct{})
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			close(received)
+			<-release
+			_, _ = rw.Write([]byte("done"))
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	go func() { _ = server.Serve(listener) }()
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, reqErr := http.Get("http://" + listener.Addr().String())
+		if reqErr != nil {
+			respCh <- reqErr.Error()
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+	<-received
+
+	c := NewCoordinator(5 * time.Second)
+	c.Register(PhaseDrain, "server", HTTPServer(server))
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- c.Shutdown() }()
+
+	// New connections are refused, while the in-flight request completes.
+	require.Eventually(t, func() bool {
+		conn, dialErr := net.Dial("tcp", listener.Addr().String())
+		if dialErr != nil {
+			return true
+		}
+		_ = conn.Close()
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+
+	assert.Equal(t, "done", <-respCh)
+	assert.NoError(t, <-shutdownErr)
+}
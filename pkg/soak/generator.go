@@ -0,0 +1,206 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package soak
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	labelManagedBy = "app.kubernetes.io/managed-by"
+	managedBy      = "hub-agent-soak"
+)
+
+// Generator generates synthetic services, ingresses and APIs in a namespace.
+type Generator struct {
+	kubeClientSet    kclientset.Interface
+	hubClientSet     hubclientset.Interface
+	namespace        string
+	ingressClassName string
+	apis             bool
+}
+
+// NewGenerator returns a new Generator creating resources in the given namespace.
+// Ingresses are created with the given ingress class, and APIs are created only if apis is true.
+func NewGenerator(kubeClientSet kclientset.Interface, hubClientSet hubclientset.Interface, namespace, ingressClassName string, apis bool) *Generator {
+	return &Generator{
+		kubeClientSet:    kubeClientSet,
+		hubClientSet:     hubClientSet,
+		namespace:        namespace,
+		ingressClassName: ingressClassName,
+		apis:             apis,
+	}
+}
+
+// Setup creates the namespace holding the synthetic resources.
+func (g *Generator) Setup(ctx context.Context) error {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   g.namespace,
+			Labels: map[string]string{labelManagedBy: managedBy},
+		},
+	}
+
+	_, err := g.kubeClientSet.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+	if err != nil && !kerror.IsAlreadyExists(err) {
+		return fmt.Errorf("create namespace %q: %w", g.namespace, err)
+	}
+
+	return nil
+}
+
+// Generated describes the resources generated by a call to Generate.
+type Generated struct {
+	// SyncLatencies holds, for each generated API synchronized with the platform, the duration of its creation.
+	// APIs are synchronized by the agent admission webhook, the creation duration is therefore the sync latency.
+	SyncLatencies []time.Duration
+	// Unsynced is the number of generated APIs which were not synchronized with the platform.
+	Unsynced int
+}
+
+// Generate creates the synthetic resources numbered from `from` (included) to `to` (excluded).
+func (g *Generator) Generate(ctx context.Context, from, to int) (Generated, error) {
+	var generated Generated
+
+	for i := from; i < to; i++ {
+		name := fmt.Sprintf("soak-%d", i)
+
+		if _, err := g.kubeClientSet.CoreV1().Services(g.namespace).Create(ctx, g.service(name), metav1.CreateOptions{}); err != nil && !kerror.IsAlreadyExists(err) {
+			return generated, fmt.Errorf("create service %q: %w", name, err)
+		}
+
+		if _, err := g.kubeClientSet.NetworkingV1().Ingresses(g.namespace).Create(ctx, g.ingress(name), metav1.CreateOptions{}); err != nil && !kerror.IsAlreadyExists(err) {
+			return generated, fmt.Errorf("create ingress %q: %w", name, err)
+		}
+
+		if !g.apis {
+			continue
+		}
+
+		start := time.Now()
+		api, err := g.hubClientSet.HubV1alpha1().APIs(g.namespace).Create(ctx, g.api(name), metav1.CreateOptions{})
+		if err != nil {
+			if kerror.IsAlreadyExists(err) {
+				continue
+			}
+			return generated, fmt.Errorf("create API %q: %w", name, err)
+		}
+
+		if api.Status.Version == "" {
+			generated.Unsynced++
+			continue
+		}
+		generated.SyncLatencies = append(generated.SyncLatencies, time.Since(start))
+	}
+
+	return generated, nil
+}
+
+// Cleanup deletes all the synthetic resources, including their namespace.
+func (g *Generator) Cleanup(ctx context.Context) error {
+	err := g.kubeClientSet.CoreV1().Namespaces().Delete(ctx, g.namespace, metav1.DeleteOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("delete namespace %q: %w", g.namespace, err)
+	}
+
+	return nil
+}
+
+func (g *Generator) objectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: g.namespace,
+		Labels:    map[string]string{labelManagedBy: managedBy},
+	}
+}
+
+func (g *Generator) service(name string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: g.objectMeta(name),
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": name},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+			},
+		},
+	}
+}
+
+func (g *Generator) ingress(name string) *netv1.Ingress {
+	pathType := netv1.PathTypePrefix
+
+	ing := &netv1.Ingress{
+		ObjectMeta: g.objectMeta(name),
+		Spec: netv1.IngressSpec{
+			Rules: []netv1.IngressRule{
+				{
+					Host: name + ".soak.localhost",
+					IngressRuleValue: netv1.IngressRuleValue{
+						HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: netv1.IngressBackend{
+										Service: &netv1.IngressServiceBackend{
+											Name: name,
+											Port: netv1.ServiceBackendPort{Number: 80},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if g.ingressClassName != "" {
+		ing.Spec.IngressClassName = &g.ingressClassName
+	}
+
+	return ing
+}
+
+func (g *Generator) api(name string) *hubv1alpha1.API {
+	return &hubv1alpha1.API{
+		ObjectMeta: g.objectMeta(name),
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: "/" + name,
+			Service: hubv1alpha1.APIService{
+				Name: name,
+				Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+			},
+		},
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package soak
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGenerator(t *testing.T) {
+	kubeClientSet := kubefake.NewSimpleClientset()
+	hubClientSet := hubfake.NewSimpleClientset()
+
+	g := NewGenerator(kubeClientSet, hubClientSet, "hub-soak", "traefik-hub", true)
+
+	ctx := context.Background()
+	require.NoError(t, g.Setup(ctx))
+	require.NoError(t, g.Setup(ctx))
+
+	generated, err := g.Generate(ctx, 0, 2)
+	require.NoError(t, err)
+
+	// APIs are not synchronized as there's no admission webhook in front of the fake client set.
+	assert.Empty(t, generated.SyncLatencies)
+	assert.Equal(t, 2, generated.Unsynced)
+
+	// Generating the same resources twice is a no-op.
+	generated, err = g.Generate(ctx, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, generated.Unsynced)
+
+	services, err := kubeClientSet.CoreV1().Services("hub-soak").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, services.Items, 3)
+
+	ingresses, err := kubeClientSet.NetworkingV1().Ingresses("hub-soak").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, ingresses.Items, 3)
+	assert.Equal(t, "traefik-hub", *ingresses.Items[0].Spec.IngressClassName)
+	assert.Equal(t, managedBy, ingresses.Items[0].Labels[labelManagedBy])
+
+	apis, err := hubClientSet.HubV1alpha1().APIs("hub-soak").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, apis.Items, 3)
+	assert.Equal(t, "/soak-0", apis.Items[0].Spec.PathPrefix)
+
+	require.NoError(t, g.Cleanup(ctx))
+	require.NoError(t, g.Cleanup(ctx))
+
+	namespaces, err := kubeClientSet.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, namespaces.Items)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package soak provides a harness generating synthetic load on a cluster running the agent, and measuring how the
+// agent behaves over time. It is meant for capacity planning.
+package soak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Config configures a soak test.
+type Config struct {
+	// Resources is the total number of synthetic resource sets (a service, an ingress and optionally an API) to generate.
+	Resources int
+	// Step is the number of resource sets generated at each step.
+	Step int
+	// StepInterval is the interval between two generation steps.
+	StepInterval time.Duration
+	// SampleInterval is the interval between two measurements.
+	SampleInterval time.Duration
+	// Duration is the total duration of the soak test.
+	Duration time.Duration
+}
+
+// Sample is a measurement of the agent behavior at a point in time.
+type Sample struct {
+	Time      time.Time `json:"time"`
+	Resources int       `json:"resources"`
+
+	Usage
+	TopologySample
+
+	// SyncLatency summarizes the latencies of the APIs synchronized since the previous sample.
+	SyncLatency LatencySummary `json:"syncLatency"`
+	// Unsynced is the number of APIs not synchronized with the platform since the previous sample.
+	Unsynced int `json:"unsynced"`
+}
+
+// LatencySummary summarizes a set of latencies.
+type LatencySummary struct {
+	Count int           `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	Max   time.Duration `json:"max"`
+}
+
+// Report is the result of a soak test.
+type Report struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Samples []Sample  `json:"samples"`
+
+	// PeakUsage is the highest resource usage observed.
+	PeakUsage Usage `json:"peakUsage"`
+	// MaxPatchBytes is the biggest topology patch observed, the initial topology excluded.
+	MaxPatchBytes int `json:"maxPatchBytes"`
+	// SyncLatency summarizes the latencies of all the synchronized APIs.
+	SyncLatency LatencySummary `json:"syncLatency"`
+}
+
+// Runner runs soak tests.
+type Runner struct {
+	generator *Generator
+	usage     *UsageSampler
+	topology  *TopologySampler
+}
+
+// NewRunner returns a new Runner.
+func NewRunner(generator *Generator, usage *UsageSampler, topology *TopologySampler) *Runner {
+	return &Runner{
+		generator: generator,
+		usage:     usage,
+		topology:  topology,
+	}
+}
+
+// Run runs a soak test with the given configuration and returns its report.
+// Measurements failing because of a transient error are logged and skipped.
+func (r *Runner) Run(ctx context.Context, cfg Config) (Report, error) {
+	if cfg.Resources <= 0 || cfg.Step <= 0 {
+		return Report{}, errors.New("resources and step must be positive")
+	}
+
+	if err := r.generator.Setup(ctx); err != nil {
+		return Report{}, fmt.Errorf("setup generator: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	report := Report{Start: time.Now()}
+
+	stepTicker := time.NewTicker(cfg.StepInterval)
+	defer stepTicker.Stop()
+	sampleTicker := time.NewTicker(cfg.SampleInterval)
+	defer sampleTicker.Stop()
+
+	var (
+		generated     int
+		latencies     []time.Duration
+		allLatencies  []time.Duration
+		unsynced      int
+		initialSample = true
+	)
+
+	generate := func() error {
+		if generated >= cfg.Resources {
+			return nil
+		}
+
+		to := generated + cfg.Step
+		if to > cfg.Resources {
+			to = cfg.Resources
+		}
+
+		res, err := r.generator.Generate(ctx, generated, to)
+		latencies = append(latencies, res.SyncLatencies...)
+		allLatencies = append(allLatencies, res.SyncLatencies...)
+		unsynced += res.Unsynced
+		if err != nil {
+			return err
+		}
+
+		generated = to
+		log.Info().Int("resources", generated).Msg("Generated synthetic resources")
+
+		return nil
+	}
+
+	if err := generate(); err != nil {
+		return report, fmt.Errorf("generate resources: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			report.End = time.Now()
+			report.SyncLatency = summarize(allLatencies)
+
+			return report, nil
+
+		case <-stepTicker.C:
+			if err := generate(); err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+				return report, fmt.Errorf("generate resources: %w", err)
+			}
+
+		case <-sampleTicker.C:
+			sample := Sample{
+				Time:        time.Now(),
+				Resources:   generated,
+				SyncLatency: summarize(latencies),
+				Unsynced:    unsynced,
+			}
+			latencies, unsynced = nil, 0
+
+			if err := r.sample(ctx, &sample); err != nil {
+				log.Warn().Err(err).Msg("Unable to sample the agent behavior")
+				continue
+			}
+
+			logSample(sample)
+			report.add(sample, initialSample)
+			initialSample = false
+		}
+	}
+}
+
+func (r *Runner) sample(ctx context.Context, sample *Sample) error {
+	var err error
+
+	sample.Usage, err = r.usage.Sample(ctx)
+	if err != nil {
+		return fmt.Errorf("sample resource usage: %w", err)
+	}
+
+	sample.TopologySample, err = r.topology.Sample(ctx)
+	if err != nil {
+		return fmt.Errorf("sample topology: %w", err)
+	}
+
+	return nil
+}
+
+// Cleanup deletes the synthetic resources.
+func (r *Runner) Cleanup(ctx context.Context) error {
+	return r.generator.Cleanup(ctx)
+}
+
+func (r *Report) add(sample Sample, initial bool) {
+	r.Samples = append(r.Samples, sample)
+
+	if sample.CPUMillicores > r.PeakUsage.CPUMillicores {
+		r.PeakUsage.CPUMillicores = sample.CPUMillicores
+	}
+	if sample.MemoryBytes > r.PeakUsage.MemoryBytes {
+		r.PeakUsage.MemoryBytes = sample.MemoryBytes
+	}
+
+	// The first patch holds the whole topology, it is not representative of the patches sent over time.
+	if !initial && sample.PatchBytes > r.MaxPatchBytes {
+		r.MaxPatchBytes = sample.PatchBytes
+	}
+}
+
+func logSample(sample Sample) {
+	log.Info().
+		Int("resources", sample.Resources).
+		Int64("cpu_millicores", sample.CPUMillicores).
+		Int64("memory_bytes", sample.MemoryBytes).
+		Int("topology_bytes", sample.TopologyBytes).
+		Int("patch_bytes", sample.PatchBytes).
+		Int("synced_apis", sample.SyncLatency.Count).
+		Int("unsynced_apis", sample.Unsynced).
+		Dur("sync_latency_p50", sample.SyncLatency.P50).
+		Dur("sync_latency_p95", sample.SyncLatency.P95).
+		Msg("Soak test sample")
+}
+
+func summarize(latencies []time.Duration) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return LatencySummary{
+		Count: len(sorted),
+		P50:   percentile(sorted, 50),
+		P95:   percentile(sorted, 95),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of the given sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
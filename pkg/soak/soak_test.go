@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package soak
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		desc      string
+		latencies []time.Duration
+		want      LatencySummary
+	}{
+		{
+			desc: "no latencies",
+			want: LatencySummary{},
+		},
+		{
+			desc:      "single latency",
+			latencies: []time.Duration{time.Second},
+			want:      LatencySummary{Count: 1, P50: time.Second, P95: time.Second, Max: time.Second},
+		},
+		{
+			desc: "unsorted latencies",
+			latencies: []time.Duration{
+				10 * time.Millisecond, 1 * time.Millisecond, 7 * time.Millisecond, 3 * time.Millisecond, 9 * time.Millisecond,
+				2 * time.Millisecond, 8 * time.Millisecond, 4 * time.Millisecond, 6 * time.Millisecond, 5 * time.Millisecond,
+			},
+			want: LatencySummary{Count: 10, P50: 5 * time.Millisecond, P95: 10 * time.Millisecond, Max: 10 * time.Millisecond},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, summarize(test.latencies))
+		})
+	}
+}
+
+func TestReport_add(t *testing.T) {
+	var report Report
+
+	report.add(Sample{Usage: Usage{CPUMillicores: 10, MemoryBytes: 300}, TopologySample: TopologySample{PatchBytes: 5000}}, true)
+	report.add(Sample{Usage: Usage{CPUMillicores: 50, MemoryBytes: 200}, TopologySample: TopologySample{PatchBytes: 100}}, false)
+	report.add(Sample{Usage: Usage{CPUMillicores: 20, MemoryBytes: 100}, TopologySample: TopologySample{PatchBytes: 300}}, false)
+
+	assert.Len(t, report.Samples, 3)
+	assert.Equal(t, Usage{CPUMillicores: 50, MemoryBytes: 300}, report.PeakUsage)
+	assert.Equal(t, 300, report.MaxPatchBytes)
+}
+
+func TestUsage_add(t *testing.T) {
+	raw := `{"pods":[
+		{"podRef":{"name":"agent","namespace":"hub-agent"},"cpu":{"usageNanoCores":250000000},"memory":{"workingSetBytes":67108864}},
+		{"podRef":{"name":"other","namespace":"hub-agent"},"cpu":{"usageNanoCores":250000000},"memory":{"workingSetBytes":67108864}},
+		{"podRef":{"name":"agent","namespace":"default"},"cpu":{"usageNanoCores":250000000},"memory":{"workingSetBytes":67108864}},
+		{"podRef":{"name":"agent","namespace":"hub-agent"}}
+	]}`
+
+	var summary statsSummary
+	require.NoError(t, json.Unmarshal([]byte(raw), &summary))
+
+	var usage Usage
+	usage.add(summary, "hub-agent", map[string]struct{}{"agent": {}})
+
+	assert.Equal(t, Usage{CPUMillicores: 250, MemoryBytes: 64 << 20}, usage)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package soak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/store"
+)
+
+// TopologySampler measures the size of the topology and of the topology patches the agent sends to the platform.
+// It relies on the same fetcher and store as the agent, the store being backed by a recorder instead of the platform.
+type TopologySampler struct {
+	fetcher  *state.Fetcher
+	store    *store.Store
+	recorder *patchRecorder
+}
+
+// NewTopologySampler returns a new TopologySampler fetching the cluster state with the given fetcher.
+func NewTopologySampler(fetcher *state.Fetcher) *TopologySampler {
+	recorder := &patchRecorder{}
+
+	return &TopologySampler{
+		fetcher:  fetcher,
+		store:    store.New(recorder, store.Config{}),
+		recorder: recorder,
+	}
+}
+
+// TopologySample is a sample of the topology sizes.
+type TopologySample struct {
+	// TopologyBytes is the size of the whole topology.
+	TopologyBytes int `json:"topologyBytes"`
+	// PatchBytes is the size of the patch sent since the last sample, 0 if the topology didn't change.
+	PatchBytes int `json:"patchBytes"`
+}
+
+// Sample fetches the cluster state and returns the size of the patch the agent would send for it.
+func (s *TopologySampler) Sample(ctx context.Context) (TopologySample, error) {
+	st, err := s.fetcher.FetchState()
+	if err != nil {
+		return TopologySample{}, fmt.Errorf("fetch state: %w", err)
+	}
+
+	topology, err := json.Marshal(st)
+	if err != nil {
+		return TopologySample{}, fmt.Errorf("marshal topology: %w", err)
+	}
+
+	s.recorder.lastPatchSize = 0
+	if err = s.store.Write(ctx, *st); err != nil {
+		return TopologySample{}, fmt.Errorf("write topology: %w", err)
+	}
+
+	return TopologySample{
+		TopologyBytes: len(topology),
+		PatchBytes:    s.recorder.lastPatchSize,
+	}, nil
+}
+
+// patchRecorder is a topology store platform client recording the size of the patches instead of sending them.
+type patchRecorder struct {
+	version       int64
+	lastPatchSize int
+}
+
+func (r *patchRecorder) FetchTopology(_ context.Context) (state.Cluster, int64, error) {
+	r.version = 1
+
+	return state.Cluster{}, r.version, nil
+}
+
+func (r *patchRecorder) PatchTopology(_ context.Context, patch []byte, _ int64) (int64, error) {
+	r.version++
+	r.lastPatchSize = len(patch)
+
+	return r.version, nil
+}
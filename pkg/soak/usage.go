@@ -0,0 +1,126 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package soak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+// Usage is the resource usage of a set of pods.
+type Usage struct {
+	// CPUMillicores is the CPU usage, in millicores.
+	CPUMillicores int64 `json:"cpuMillicores"`
+	// MemoryBytes is the working set memory, in bytes.
+	MemoryBytes int64 `json:"memoryBytes"`
+}
+
+// statsSummary is the subset of the kubelet stats summary needed to compute pods resource usage.
+type statsSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		CPU *struct {
+			UsageNanoCores *uint64 `json:"usageNanoCores"`
+		} `json:"cpu"`
+		Memory *struct {
+			WorkingSetBytes *uint64 `json:"workingSetBytes"`
+		} `json:"memory"`
+	} `json:"pods"`
+}
+
+// UsageSampler samples the resource usage of the agent pods from the kubelet stats summary. Unlike the metrics API,
+// the kubelet stats summary is available on any cluster, including kind clusters without metrics-server.
+type UsageSampler struct {
+	kubeClientSet kclientset.Interface
+	namespace     string
+	selector      string
+}
+
+// NewUsageSampler returns a new UsageSampler for the pods matching the given label selector in the given namespace.
+func NewUsageSampler(kubeClientSet kclientset.Interface, namespace, selector string) *UsageSampler {
+	return &UsageSampler{
+		kubeClientSet: kubeClientSet,
+		namespace:     namespace,
+		selector:      selector,
+	}
+}
+
+// Sample returns the total resource usage of the agent pods.
+func (s *UsageSampler) Sample(ctx context.Context) (Usage, error) {
+	pods, err := s.kubeClientSet.CoreV1().Pods(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: s.selector})
+	if err != nil {
+		return Usage{}, fmt.Errorf("list agent pods: %w", err)
+	}
+
+	podsByNode := make(map[string]map[string]struct{})
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if podsByNode[pod.Spec.NodeName] == nil {
+			podsByNode[pod.Spec.NodeName] = make(map[string]struct{})
+		}
+		podsByNode[pod.Spec.NodeName][pod.Name] = struct{}{}
+	}
+
+	var usage Usage
+	for node, podNames := range podsByNode {
+		raw, err := s.kubeClientSet.CoreV1().RESTClient().Get().
+			Resource("nodes").
+			Name(node).
+			SubResource("proxy", "stats", "summary").
+			DoRaw(ctx)
+		if err != nil {
+			return Usage{}, fmt.Errorf("get stats summary of node %q: %w", node, err)
+		}
+
+		var summary statsSummary
+		if err = json.Unmarshal(raw, &summary); err != nil {
+			return Usage{}, fmt.Errorf("unmarshal stats summary of node %q: %w", node, err)
+		}
+
+		usage.add(summary, s.namespace, podNames)
+	}
+
+	return usage, nil
+}
+
+func (u *Usage) add(summary statsSummary, namespace string, podNames map[string]struct{}) {
+	for _, pod := range summary.Pods {
+		if pod.PodRef.Namespace != namespace {
+			continue
+		}
+		if _, ok := podNames[pod.PodRef.Name]; !ok {
+			continue
+		}
+
+		if pod.CPU != nil && pod.CPU.UsageNanoCores != nil {
+			u.CPUMillicores += int64(*pod.CPU.UsageNanoCores / 1e6)
+		}
+		if pod.Memory != nil && pod.Memory.WorkingSetBytes != nil {
+			u.MemoryBytes += int64(*pod.Memory.WorkingSetBytes)
+		}
+	}
+}
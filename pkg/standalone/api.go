@@ -0,0 +1,655 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package standalone
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// GetAPIs returns the APIs defined in the cluster.
+func (b *Backend) GetAPIs(_ context.Context) ([]api.API, error) {
+	crds, err := b.hubInformer.Hub().V1alpha1().APIs().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list APIs: %w", err)
+	}
+
+	apis := make([]api.API, 0, len(crds))
+	for _, crd := range crds {
+		a := apiFromCRD(crd)
+		a.CreatedAt = crd.CreationTimestamp.Time
+		a.UpdatedAt = crd.CreationTimestamp.Time
+
+		if err = versionAPI(a); err != nil {
+			return nil, fmt.Errorf("version API %s/%s: %w", crd.Namespace, crd.Name, err)
+		}
+
+		apis = append(apis, *a)
+	}
+
+	return apis, nil
+}
+
+// CreateAPI creates an API.
+func (b *Backend) CreateAPI(_ context.Context, req *platform.CreateAPIReq) (*api.API, error) {
+	a := &api.API{
+		Name:          req.Name,
+		Namespace:     req.Namespace,
+		Labels:        req.Labels,
+		PathPrefix:    req.PathPrefix,
+		Service:       apiService(req.Service),
+		VersionHeader: req.VersionHeader,
+		Matchers:      req.Matchers,
+		Deprecation:   req.Deprecation,
+		Sandbox:       req.Sandbox,
+		CreatedAt:     b.now(),
+		UpdatedAt:     b.now(),
+
+		MaxRequestBodyBytes: req.MaxRequestBodyBytes,
+		AllowedMethods:      req.AllowedMethods,
+		ValidateRequests:    req.ValidateRequests,
+		CORS:                req.CORS,
+	}
+
+	if err := versionAPI(a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// UpdateAPI updates an API.
+func (b *Backend) UpdateAPI(_ context.Context, namespace, name, _ string, req *platform.UpdateAPIReq) (*api.API, error) {
+	a := &api.API{
+		Name:          name,
+		Namespace:     namespace,
+		Labels:        req.Labels,
+		PathPrefix:    req.PathPrefix,
+		Service:       apiService(req.Service),
+		VersionHeader: req.VersionHeader,
+		Matchers:      req.Matchers,
+		Deprecation:   req.Deprecation,
+		Sandbox:       req.Sandbox,
+		UpdatedAt:     b.now(),
+
+		MaxRequestBodyBytes: req.MaxRequestBodyBytes,
+		AllowedMethods:      req.AllowedMethods,
+		ValidateRequests:    req.ValidateRequests,
+		CORS:                req.CORS,
+	}
+
+	if err := versionAPI(a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// DeleteAPI deletes an API.
+func (b *Backend) DeleteAPI(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+// GetCollections returns the APICollections defined in the cluster.
+func (b *Backend) GetCollections(_ context.Context) ([]api.Collection, error) {
+	crds, err := b.hubInformer.Hub().V1alpha1().APICollections().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list APICollections: %w", err)
+	}
+
+	collections := make([]api.Collection, 0, len(crds))
+	for _, crd := range crds {
+		c := &api.Collection{
+			Name:        crd.Name,
+			Labels:      crd.Labels,
+			PathPrefix:  crd.Spec.PathPrefix,
+			APISelector: crd.Spec.APISelector,
+			CreatedAt:   crd.CreationTimestamp.Time,
+			UpdatedAt:   crd.CreationTimestamp.Time,
+		}
+
+		if err = versionCollection(c); err != nil {
+			return nil, fmt.Errorf("version APICollection %s: %w", crd.Name, err)
+		}
+
+		collections = append(collections, *c)
+	}
+
+	return collections, nil
+}
+
+// CreateCollection creates an APICollection.
+func (b *Backend) CreateCollection(_ context.Context, req *platform.CreateCollectionReq) (*api.Collection, error) {
+	c := &api.Collection{
+		Name:        req.Name,
+		Labels:      req.Labels,
+		PathPrefix:  req.PathPrefix,
+		APISelector: req.APISelector,
+		CreatedAt:   b.now(),
+		UpdatedAt:   b.now(),
+	}
+
+	if err := versionCollection(c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// UpdateCollection updates an APICollection.
+func (b *Backend) UpdateCollection(_ context.Context, name, _ string, req *platform.UpdateCollectionReq) (*api.Collection, error) {
+	c := &api.Collection{
+		Name:        name,
+		Labels:      req.Labels,
+		PathPrefix:  req.PathPrefix,
+		APISelector: req.APISelector,
+		UpdatedAt:   b.now(),
+	}
+
+	if err := versionCollection(c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// DeleteCollection deletes an APICollection.
+func (b *Backend) DeleteCollection(_ context.Context, _, _ string) error {
+	return nil
+}
+
+// GetAccesses returns the APIAccesses defined in the cluster.
+func (b *Backend) GetAccesses(_ context.Context) ([]api.Access, error) {
+	crds, err := b.hubInformer.Hub().V1alpha1().APIAccesses().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list APIAccesses: %w", err)
+	}
+
+	accesses := make([]api.Access, 0, len(crds))
+	for _, crd := range crds {
+		a := &api.Access{
+			Name:                  crd.Name,
+			Labels:                crd.Labels,
+			Groups:                crd.Spec.Groups,
+			APISelector:           crd.Spec.APISelector,
+			APICollectionSelector: crd.Spec.APICollectionSelector,
+			CreatedAt:             crd.CreationTimestamp.Time,
+			UpdatedAt:             crd.CreationTimestamp.Time,
+		}
+
+		if err = versionAccess(a); err != nil {
+			return nil, fmt.Errorf("version APIAccess %s: %w", crd.Name, err)
+		}
+
+		accesses = append(accesses, *a)
+	}
+
+	return accesses, nil
+}
+
+// CreateAccess creates an APIAccess.
+func (b *Backend) CreateAccess(_ context.Context, req *platform.CreateAccessReq) (*api.Access, error) {
+	a := &api.Access{
+		Name:                  req.Name,
+		Labels:                req.Labels,
+		Groups:                req.Groups,
+		APISelector:           req.APISelector,
+		APICollectionSelector: req.APICollectionSelector,
+		CreatedAt:             b.now(),
+		UpdatedAt:             b.now(),
+	}
+
+	if err := versionAccess(a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// UpdateAccess updates an APIAccess.
+func (b *Backend) UpdateAccess(_ context.Context, name, _ string, req *platform.UpdateAccessReq) (*api.Access, error) {
+	a := &api.Access{
+		Name:                  name,
+		Labels:                req.Labels,
+		Groups:                req.Groups,
+		APISelector:           req.APISelector,
+		APICollectionSelector: req.APICollectionSelector,
+		UpdatedAt:             b.now(),
+	}
+
+	if err := versionAccess(a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// DeleteAccess deletes an APIAccess.
+func (b *Backend) DeleteAccess(_ context.Context, _, _ string) error {
+	return nil
+}
+
+// GetRateLimits returns the APIRateLimits defined in the cluster.
+func (b *Backend) GetRateLimits(_ context.Context) ([]api.RateLimit, error) {
+	crds, err := b.hubInformer.Hub().V1alpha1().APIRateLimits().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list APIRateLimits: %w", err)
+	}
+
+	rateLimits := make([]api.RateLimit, 0, len(crds))
+	for _, crd := range crds {
+		r := &api.RateLimit{
+			Name:        crd.Name,
+			Labels:      crd.Labels,
+			Groups:      crd.Spec.Groups,
+			Everyone:    crd.Spec.Everyone,
+			APISelector: crd.Spec.APISelector,
+			Limit:       crd.Spec.Limit,
+			Period:      crd.Spec.Period,
+			Strategy:    crd.Spec.Strategy,
+			CreatedAt:   crd.CreationTimestamp.Time,
+			UpdatedAt:   crd.CreationTimestamp.Time,
+		}
+
+		if err = versionRateLimit(r); err != nil {
+			return nil, fmt.Errorf("version APIRateLimit %s: %w", crd.Name, err)
+		}
+
+		rateLimits = append(rateLimits, *r)
+	}
+
+	return rateLimits, nil
+}
+
+// CreateRateLimit creates an APIRateLimit.
+func (b *Backend) CreateRateLimit(_ context.Context, req *platform.CreateRateLimitReq) (*api.RateLimit, error) {
+	r := &api.RateLimit{
+		Name:        req.Name,
+		Labels:      req.Labels,
+		Groups:      req.Groups,
+		Everyone:    req.Everyone,
+		APISelector: req.APISelector,
+		Limit:       req.Limit,
+		Period:      req.Period,
+		Strategy:    req.Strategy,
+		CreatedAt:   b.now(),
+		UpdatedAt:   b.now(),
+	}
+
+	if err := versionRateLimit(r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// UpdateRateLimit updates an APIRateLimit.
+func (b *Backend) UpdateRateLimit(_ context.Context, name, _ string, req *platform.UpdateRateLimitReq) (*api.RateLimit, error) {
+	r := &api.RateLimit{
+		Name:        name,
+		Labels:      req.Labels,
+		Groups:      req.Groups,
+		Everyone:    req.Everyone,
+		APISelector: req.APISelector,
+		Limit:       req.Limit,
+		Period:      req.Period,
+		Strategy:    req.Strategy,
+		UpdatedAt:   b.now(),
+	}
+
+	if err := versionRateLimit(r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// DeleteRateLimit deletes an APIRateLimit.
+func (b *Backend) DeleteRateLimit(_ context.Context, _, _ string) error {
+	return nil
+}
+
+// SetRateLimitStatus does nothing, the APIs selected by the APIRateLimits are only reported in their status.
+func (b *Backend) SetRateLimitStatus(_ context.Context, _ string, _ api.RateLimitStatus) error {
+	return nil
+}
+
+// GetAPIVersions returns the APIVersions defined in the cluster.
+func (b *Backend) GetAPIVersions(_ context.Context) ([]api.APIVersion, error) {
+	crds, err := b.hubInformer.Hub().V1alpha1().APIVersions().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list APIVersions: %w", err)
+	}
+
+	versions := make([]api.APIVersion, 0, len(crds))
+	for _, crd := range crds {
+		v := apiVersionFromCRD(crd)
+		v.CreatedAt = crd.CreationTimestamp.Time
+		v.UpdatedAt = crd.CreationTimestamp.Time
+
+		if err = versionAPIVersion(v); err != nil {
+			return nil, fmt.Errorf("version APIVersion %s/%s: %w", crd.Namespace, crd.Name, err)
+		}
+
+		versions = append(versions, *v)
+	}
+
+	return versions, nil
+}
+
+// CreateAPIVersion creates an APIVersion.
+func (b *Backend) CreateAPIVersion(_ context.Context, req *platform.CreateAPIVersionReq) (*api.APIVersion, error) {
+	v := &api.APIVersion{
+		Name:          req.Name,
+		Namespace:     req.Namespace,
+		Labels:        req.Labels,
+		APIName:       req.APIName,
+		Release:       req.Release,
+		PathPrefix:    req.PathPrefix,
+		VersionHeader: req.VersionHeader,
+		Service:       req.Service,
+		Deprecation:   req.Deprecation,
+		CreatedAt:     b.now(),
+		UpdatedAt:     b.now(),
+	}
+
+	if err := versionAPIVersion(v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// UpdateAPIVersion updates an APIVersion.
+func (b *Backend) UpdateAPIVersion(_ context.Context, namespace, name, _ string, req *platform.UpdateAPIVersionReq) (*api.APIVersion, error) {
+	v := &api.APIVersion{
+		Name:          name,
+		Namespace:     namespace,
+		Labels:        req.Labels,
+		APIName:       req.APIName,
+		Release:       req.Release,
+		PathPrefix:    req.PathPrefix,
+		VersionHeader: req.VersionHeader,
+		Service:       req.Service,
+		Deprecation:   req.Deprecation,
+		UpdatedAt:     b.now(),
+	}
+
+	if err := versionAPIVersion(v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// DeleteAPIVersion deletes an APIVersion.
+func (b *Backend) DeleteAPIVersion(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+// GetGateways returns the APIGateways defined in the cluster.
+func (b *Backend) GetGateways(_ context.Context) ([]api.Gateway, error) {
+	crds, err := b.hubInformer.Hub().V1alpha1().APIGateways().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list APIGateways: %w", err)
+	}
+
+	gateways := make([]api.Gateway, 0, len(crds))
+	for _, crd := range crds {
+		g := b.gateway(crd.Name, crd.Labels, crd.Spec.APIAccesses, crd.Spec.CustomDomains)
+		g.Certificate = (*api.SecretReference)(crd.Spec.Certificate)
+		g.MaxRequestBodyBytes = crd.Spec.MaxRequestBodyBytes
+		g.CreatedAt = crd.CreationTimestamp.Time
+		g.UpdatedAt = crd.CreationTimestamp.Time
+
+		if err = versionGateway(g); err != nil {
+			return nil, fmt.Errorf("version APIGateway %s: %w", crd.Name, err)
+		}
+
+		gateways = append(gateways, *g)
+	}
+
+	return gateways, nil
+}
+
+// CreateGateway creates an APIGateway.
+func (b *Backend) CreateGateway(_ context.Context, req *platform.CreateGatewayReq) (*api.Gateway, error) {
+	g := b.gateway(req.Name, req.Labels, req.Accesses, req.CustomDomains)
+	g.Certificate = req.Certificate
+	g.MaxRequestBodyBytes = req.MaxRequestBodyBytes
+	g.CreatedAt = b.now()
+	g.UpdatedAt = g.CreatedAt
+
+	if err := versionGateway(g); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// UpdateGateway updates an APIGateway.
+func (b *Backend) UpdateGateway(_ context.Context, name, _ string, req *platform.UpdateGatewayReq) (*api.Gateway, error) {
+	g := b.gateway(name, req.Labels, req.Accesses, req.CustomDomains)
+	g.Certificate = req.Certificate
+	g.MaxRequestBodyBytes = req.MaxRequestBodyBytes
+	g.UpdatedAt = b.now()
+
+	if err := versionGateway(g); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// DeleteGateway deletes an APIGateway.
+func (b *Backend) DeleteGateway(_ context.Context, _, _ string) error {
+	return nil
+}
+
+// gateway builds an APIGateway exposed on <name>.<domain>.
+func (b *Backend) gateway(name string, lbls map[string]string, accesses, customDomains []string) *api.Gateway {
+	g := &api.Gateway{
+		Name:      name,
+		Labels:    lbls,
+		Accesses:  accesses,
+		HubDomain: fmt.Sprintf("%s.%s", name, b.domain),
+	}
+
+	// Domain ownership can't be verified without the platform, custom domains are trusted as is.
+	for _, domain := range customDomains {
+		g.CustomDomains = append(g.CustomDomains, api.CustomDomain{Name: domain, Verified: true})
+	}
+
+	return g
+}
+
+func apiFromCRD(crd *hubv1alpha1.API) *api.API {
+	a := &api.API{
+		Name:       crd.Name,
+		Namespace:  crd.Namespace,
+		Labels:     crd.Labels,
+		PathPrefix: crd.Spec.PathPrefix,
+		Service: api.Service{
+			Name: crd.Spec.Service.Name,
+			Port: int(crd.Spec.Service.Port.Number),
+			OpenAPISpec: api.OpenAPISpec{
+				URL:     crd.Spec.Service.OpenAPISpec.URL,
+				Path:    crd.Spec.Service.OpenAPISpec.Path,
+				Headers: crd.Spec.Service.OpenAPISpec.Headers,
+				Auth:    api.OpenAPISpecAuthFromCRD(crd.Spec.Service.OpenAPISpec.Auth),
+				CA:      (*api.SecretReference)(crd.Spec.Service.OpenAPISpec.CA),
+			},
+			ExternalURL: crd.Spec.Service.ExternalURL,
+		},
+	}
+
+	if crd.Spec.Service.OpenAPISpec.Port != nil {
+		a.Service.OpenAPISpec.Port = int(crd.Spec.Service.OpenAPISpec.Port.Number)
+	}
+
+	if crd.Spec.VersionHeader != nil {
+		a.VersionHeader = &api.VersionHeader{
+			Name:  crd.Spec.VersionHeader.Name,
+			Value: crd.Spec.VersionHeader.Value,
+		}
+	}
+
+	a.Matchers = api.MatchersFromCRD(crd.Spec.Matchers)
+
+	if crd.Spec.Deprecation != nil {
+		a.Deprecation = &api.Deprecation{}
+		if crd.Spec.Deprecation.Sunset != nil {
+			a.Deprecation.Sunset = &crd.Spec.Deprecation.Sunset.Time
+		}
+	}
+
+	if crd.Spec.Sandbox != nil {
+		a.Sandbox = &api.Sandbox{
+			Service: api.SandboxService{
+				Name: crd.Spec.Sandbox.Service.Name,
+				Port: int(crd.Spec.Sandbox.Service.Port.Number),
+			},
+		}
+	}
+
+	a.MaxRequestBodyBytes = crd.Spec.MaxRequestBodyBytes
+	a.AllowedMethods = crd.Spec.AllowedMethods
+	a.ValidateRequests = crd.Spec.ValidateRequests
+	a.CORS = crd.Spec.CORS
+
+	return a
+}
+
+func apiVersionFromCRD(crd *hubv1alpha1.APIVersion) *api.APIVersion {
+	v := &api.APIVersion{
+		Name:       crd.Name,
+		Namespace:  crd.Namespace,
+		Labels:     crd.Labels,
+		APIName:    crd.Spec.APIName,
+		Release:    crd.Spec.Release,
+		PathPrefix: crd.Spec.PathPrefix,
+	}
+
+	if crd.Spec.VersionHeader != nil {
+		v.VersionHeader = &api.VersionHeader{
+			Name:  crd.Spec.VersionHeader.Name,
+			Value: crd.Spec.VersionHeader.Value,
+		}
+	}
+
+	if crd.Spec.Service != nil {
+		v.Service = &api.VersionService{
+			Name: crd.Spec.Service.Name,
+			Port: int(crd.Spec.Service.Port.Number),
+		}
+	}
+
+	if crd.Spec.Deprecation != nil {
+		v.Deprecation = &api.Deprecation{}
+		if crd.Spec.Deprecation.Sunset != nil {
+			v.Deprecation.Sunset = &crd.Spec.Deprecation.Sunset.Time
+		}
+	}
+
+	return v
+}
+
+func apiService(svc platform.APIService) api.Service {
+	return api.Service{
+		Name: svc.Name,
+		Port: svc.Port,
+		OpenAPISpec: api.OpenAPISpec{
+			URL:     svc.OpenAPISpec.URL,
+			Path:    svc.OpenAPISpec.Path,
+			Port:    svc.OpenAPISpec.Port,
+			Headers: svc.OpenAPISpec.Headers,
+			Auth:    svc.OpenAPISpec.Auth,
+			CA:      svc.OpenAPISpec.CA,
+		},
+		ExternalURL: svc.ExternalURL,
+	}
+}
+
+// The version of API management resources is the hash of the resource they describe. This way, the version assigned
+// when reviewing a resource matches the one computed when listing it back from the cluster, and watchers leave it
+// untouched.
+
+func versionAPI(a *api.API) error {
+	res, err := a.Resource()
+	if err != nil {
+		return fmt.Errorf("build API resource: %w", err)
+	}
+	a.Version = res.Status.Hash
+
+	return nil
+}
+
+func versionCollection(c *api.Collection) error {
+	res, err := c.Resource()
+	if err != nil {
+		return fmt.Errorf("build APICollection resource: %w", err)
+	}
+	c.Version = res.Status.Hash
+
+	return nil
+}
+
+func versionAccess(a *api.Access) error {
+	res, err := a.Resource()
+	if err != nil {
+		return fmt.Errorf("build APIAccess resource: %w", err)
+	}
+	a.Version = res.Status.Hash
+
+	return nil
+}
+
+func versionRateLimit(r *api.RateLimit) error {
+	res, err := r.Resource()
+	if err != nil {
+		return fmt.Errorf("build APIRateLimit resource: %w", err)
+	}
+	r.Version = res.Status.Hash
+
+	return nil
+}
+
+func versionAPIVersion(v *api.APIVersion) error {
+	res, err := v.Resource()
+	if err != nil {
+		return fmt.Errorf("build APIVersion resource: %w", err)
+	}
+	v.Version = res.Status.Hash
+
+	return nil
+}
+
+func versionGateway(g *api.Gateway) error {
+	res, err := g.Resource()
+	if err != nil {
+		return fmt.Errorf("build APIGateway resource: %w", err)
+	}
+	g.Version = res.Status.Hash
+
+	return nil
+}
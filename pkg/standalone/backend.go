@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package standalone provides a backend driving Hub features entirely from their CRDs, without the Hub platform.
+// It is used when the agent runs in standalone mode, for evaluation and air-gapped environments.
+package standalone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+// ErrPortalsNotSupported is returned when managing APIPortals, which require the Hub platform to authenticate users.
+var ErrPortalsNotSupported = errors.New("APIPortals are not supported in standalone mode")
+
+type cachedCertificate struct {
+	certificate edgeingress.Certificate
+	notAfter    time.Time
+}
+
+// Backend serves the Hub resources defined in the cluster as if they were managed by the platform.
+// Resources are versioned with the hash of their spec, and exposed on local-only domains under a base domain,
+// using self-signed certificates.
+type Backend struct {
+	domain      string
+	hubInformer hubinformers.SharedInformerFactory
+	now         func() time.Time
+
+	certsMu sync.Mutex
+	certs   map[string]cachedCertificate
+}
+
+// NewBackend creates a new Backend exposing resources under the given base domain.
+// The hubInformer must have its EdgeIngress and API management informers started.
+func NewBackend(domain string, hubInformer hubinformers.SharedInformerFactory) *Backend {
+	return &Backend{
+		domain:      strings.TrimPrefix(domain, "."),
+		hubInformer: hubInformer,
+		now:         time.Now,
+		certs:       make(map[string]cachedCertificate),
+	}
+}
+
+// CreateACP creates an AccessControlPolicy.
+func (b *Backend) CreateACP(_ context.Context, policy *hubv1alpha1.AccessControlPolicy) (*acp.ACP, error) {
+	return b.acp(policy)
+}
+
+// UpdateACP updates an AccessControlPolicy.
+func (b *Backend) UpdateACP(_ context.Context, _ string, policy *hubv1alpha1.AccessControlPolicy) (*acp.ACP, error) {
+	return b.acp(policy)
+}
+
+// DeleteACP deletes an AccessControlPolicy.
+func (b *Backend) DeleteACP(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (b *Backend) acp(policy *hubv1alpha1.AccessControlPolicy) (*acp.ACP, error) {
+	version, err := policy.Spec.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("compute spec hash: %w", err)
+	}
+
+	return &acp.ACP{
+		Config:  *acp.ConfigFromPolicy(policy),
+		Name:    policy.Name,
+		Version: version,
+	}, nil
+}
+
+// GetPortals returns no APIPortals, as they are not supported in standalone mode.
+func (b *Backend) GetPortals(_ context.Context) ([]api.Portal, error) {
+	return nil, nil
+}
+
+// CreatePortal rejects the creation of APIPortals, as they are not supported in standalone mode.
+func (b *Backend) CreatePortal(_ context.Context, _ *platform.CreatePortalReq) (*api.Portal, error) {
+	return nil, ErrPortalsNotSupported
+}
+
+// UpdatePortal rejects the update of APIPortals, as they are not supported in standalone mode.
+func (b *Backend) UpdatePortal(_ context.Context, _, _ string, _ *platform.UpdatePortalReq) (*api.Portal, error) {
+	return nil, ErrPortalsNotSupported
+}
+
+// DeletePortal deletes an APIPortal.
+func (b *Backend) DeletePortal(_ context.Context, _, _ string) error {
+	return nil
+}
+
+// GetWildcardCertificate returns a self-signed certificate for the base domain and all its subdomains.
+func (b *Backend) GetWildcardCertificate(_ context.Context) (edgeingress.Certificate, error) {
+	return b.certificate([]string{"*." + b.domain, b.domain})
+}
+
+// GetCertificateByDomains returns a self-signed certificate for the given domains.
+func (b *Backend) GetCertificateByDomains(_ context.Context, domains []string) (edgeingress.Certificate, error) {
+	if len(domains) == 0 {
+		return edgeingress.Certificate{}, errors.New("no domains given")
+	}
+
+	sorted := make([]string, len(domains))
+	copy(sorted, domains)
+	sort.Strings(sorted)
+
+	return b.certificate(sorted)
+}
+
+// certificate returns the certificate for the given domains, generating it if it doesn't exist or is about to expire.
+// Certificates are cached so that watchers only update the TLS secrets they manage when a certificate is renewed.
+func (b *Backend) certificate(domains []string) (edgeingress.Certificate, error) {
+	key := strings.Join(domains, ",")
+	now := b.now()
+
+	b.certsMu.Lock()
+	defer b.certsMu.Unlock()
+
+	if cached, ok := b.certs[key]; ok && now.Add(certificateRenewBefore).Before(cached.notAfter) {
+		return cached.certificate, nil
+	}
+
+	cert, notAfter, err := selfSignedCertificate(domains, now)
+	if err != nil {
+		return edgeingress.Certificate{}, fmt.Errorf("generate self-signed certificate: %w", err)
+	}
+
+	b.certs[key] = cachedCertificate{certificate: cert, notAfter: notAfter}
+
+	return cert, nil
+}
@@ -0,0 +1,254 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package standalone
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestBackend_EdgeIngress(t *testing.T) {
+	edgeIng := &hubv1alpha1.EdgeIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "whoami", Namespace: "apps"},
+		Spec: hubv1alpha1.EdgeIngressSpec{
+			Service:       hubv1alpha1.EdgeIngressService{Name: "whoami", Port: 80},
+			ACP:           &hubv1alpha1.EdgeIngressACP{Name: "basic"},
+			CustomDomains: []string{"whoami.example.com"},
+		},
+	}
+
+	backend := newBackend(t, edgeIng)
+
+	created, err := backend.CreateEdgeIngress(context.Background(), &platform.CreateEdgeIngressReq{
+		Name:          "whoami",
+		Namespace:     "apps",
+		Service:       platform.Service{Name: "whoami", Port: 80},
+		ACP:           &platform.ACP{Name: "basic"},
+		CustomDomains: []string{"whoami.example.com"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "whoami-apps.hub.local", created.Domain)
+	assert.Equal(t, []edgeingress.CustomDomain{{Name: "whoami.example.com", Verified: true}}, created.CustomDomains)
+	assert.NotEmpty(t, created.Version)
+
+	edgeIngresses, err := backend.GetEdgeIngresses(context.Background())
+	require.NoError(t, err)
+	require.Len(t, edgeIngresses, 1)
+
+	// The version must match the one returned on creation, otherwise the watcher would keep updating the resource.
+	assert.Equal(t, created.Version, edgeIngresses[0].Version)
+	assert.Equal(t, created.Domain, edgeIngresses[0].Domain)
+	assert.Equal(t, created.ACP, edgeIngresses[0].ACP)
+
+	updated, err := backend.UpdateEdgeIngress(context.Background(), "apps", "whoami", created.Version, &platform.UpdateEdgeIngressReq{
+		Service: platform.Service{Name: "whoami", Port: 8080},
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Version, updated.Version)
+}
+
+func TestBackend_APIManagement(t *testing.T) {
+	labels := map[string]string{"area": "products"}
+	selector := metav1.LabelSelector{MatchLabels: labels}
+
+	objects := []runtime.Object{
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "products", Namespace: "apps", Labels: labels},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/products",
+				Service: hubv1alpha1.APIService{
+					Name:        "products",
+					Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+					OpenAPISpec: hubv1alpha1.OpenAPISpec{Path: "/openapi.json"},
+				},
+			},
+		},
+		&hubv1alpha1.APICollection{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop"},
+			Spec:       hubv1alpha1.APICollectionSpec{PathPrefix: "/shop", APISelector: selector},
+		},
+		&hubv1alpha1.APIAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "customers"},
+			Spec:       hubv1alpha1.APIAccessSpec{Groups: []string{"customers"}, APISelector: &selector},
+		},
+		&hubv1alpha1.APIGateway{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "hub.traefik.io/v1alpha1", Kind: "APIGateway"},
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway"},
+			Spec:       hubv1alpha1.APIGatewaySpec{APIAccesses: []string{"customers"}, CustomDomains: []string{"api.example.com"}},
+		},
+	}
+
+	backend := newBackend(t, objects...)
+	ctx := context.Background()
+
+	createdAPI, err := backend.CreateAPI(ctx, &platform.CreateAPIReq{
+		Name:       "products",
+		Namespace:  "apps",
+		Labels:     labels,
+		PathPrefix: "/products",
+		Service: platform.APIService{
+			Name:        "products",
+			Port:        80,
+			OpenAPISpec: platform.OpenAPISpec{Path: "/openapi.json"},
+		},
+	})
+	require.NoError(t, err)
+
+	apis, err := backend.GetAPIs(ctx)
+	require.NoError(t, err)
+	require.Len(t, apis, 1)
+	assert.Equal(t, createdAPI.Version, apis[0].Version)
+
+	createdCollection, err := backend.CreateCollection(ctx, &platform.CreateCollectionReq{
+		Name:        "shop",
+		PathPrefix:  "/shop",
+		APISelector: selector,
+	})
+	require.NoError(t, err)
+
+	collections, err := backend.GetCollections(ctx)
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	assert.Equal(t, createdCollection.Version, collections[0].Version)
+
+	createdAccess, err := backend.CreateAccess(ctx, &platform.CreateAccessReq{
+		Name:        "customers",
+		Groups:      []string{"customers"},
+		APISelector: &selector,
+	})
+	require.NoError(t, err)
+
+	accesses, err := backend.GetAccesses(ctx)
+	require.NoError(t, err)
+	require.Len(t, accesses, 1)
+	assert.Equal(t, createdAccess.Version, accesses[0].Version)
+
+	createdGateway, err := backend.CreateGateway(ctx, &platform.CreateGatewayReq{
+		Name:          "gateway",
+		Accesses:      []string{"customers"},
+		CustomDomains: []string{"api.example.com"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gateway.hub.local", createdGateway.HubDomain)
+	assert.Equal(t, []api.CustomDomain{{Name: "api.example.com", Verified: true}}, createdGateway.CustomDomains)
+
+	gateways, err := backend.GetGateways(ctx)
+	require.NoError(t, err)
+	require.Len(t, gateways, 1)
+	assert.Equal(t, createdGateway.Version, gateways[0].Version)
+
+	portals, err := backend.GetPortals(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, portals)
+
+	_, err = backend.CreatePortal(ctx, &platform.CreatePortalReq{Name: "portal"})
+	assert.ErrorIs(t, err, ErrPortalsNotSupported)
+}
+
+func TestBackend_ACP(t *testing.T) {
+	policy := &hubv1alpha1.AccessControlPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic"},
+		Spec: hubv1alpha1.AccessControlPolicySpec{
+			BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{Users: []string{"user:password"}},
+		},
+	}
+
+	backend := newBackend(t)
+
+	created, err := backend.CreateACP(context.Background(), policy)
+	require.NoError(t, err)
+
+	wantVersion, err := policy.Spec.Hash()
+	require.NoError(t, err)
+
+	assert.Equal(t, "basic", created.Name)
+	assert.Equal(t, wantVersion, created.Version)
+	require.NotNil(t, created.BasicAuth)
+}
+
+func TestBackend_certificates(t *testing.T) {
+	backend := newBackend(t)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	backend.now = func() time.Time { return now }
+
+	wildcard, err := backend.GetWildcardCertificate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*.hub.local", "hub.local"}, parseCertificate(t, wildcard).DNSNames)
+
+	// Certificates are cached, so TLS secrets are only updated when they are renewed.
+	again, err := backend.GetWildcardCertificate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, wildcard, again)
+
+	custom, err := backend.GetCertificateByDomains(context.Background(), []string{"b.example.com", "a.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, parseCertificate(t, custom).DNSNames)
+
+	now = now.Add(certificateValidity - certificateRenewBefore)
+
+	renewed, err := backend.GetWildcardCertificate(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, wildcard, renewed)
+}
+
+func newBackend(t *testing.T, objects ...runtime.Object) *Backend {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubInformer := hubinformers.NewSharedInformerFactory(kube.NewFakeHubClientset(objects...), 0)
+	hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+	hubInformer.Hub().V1alpha1().APIs().Informer()
+	hubInformer.Hub().V1alpha1().APICollections().Informer()
+	hubInformer.Hub().V1alpha1().APIAccesses().Informer()
+	hubInformer.Hub().V1alpha1().APIGateways().Informer()
+
+	hubInformer.Start(ctx.Done())
+	hubInformer.WaitForCacheSync(ctx.Done())
+
+	return NewBackend("hub.local", hubInformer)
+}
+
+func parseCertificate(t *testing.T, cert edgeingress.Certificate) *x509.Certificate {
+	t.Helper()
+
+	block, _ := pem.Decode(cert.Certificate)
+	require.NotNil(t, block)
+
+	c, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	return c
+}
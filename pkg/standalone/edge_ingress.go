@@ -0,0 +1,148 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package standalone
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// GetEdgeIngresses returns the EdgeIngresses defined in the cluster.
+func (b *Backend) GetEdgeIngresses(_ context.Context) ([]edgeingress.EdgeIngress, error) {
+	edgeIngs, err := b.hubInformer.Hub().V1alpha1().EdgeIngresses().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list EdgeIngresses: %w", err)
+	}
+
+	edgeIngresses := make([]edgeingress.EdgeIngress, 0, len(edgeIngs))
+	for _, edgeIng := range edgeIngs {
+		e, err := b.edgeIngress(edgeIng.Namespace, edgeIng.Name, edgeIng.Spec, edgeIng.CreationTimestamp.Time)
+		if err != nil {
+			return nil, fmt.Errorf("build EdgeIngress %s/%s: %w", edgeIng.Namespace, edgeIng.Name, err)
+		}
+
+		edgeIngresses = append(edgeIngresses, *e)
+	}
+
+	return edgeIngresses, nil
+}
+
+// CreateEdgeIngress creates an EdgeIngress.
+func (b *Backend) CreateEdgeIngress(_ context.Context, req *platform.CreateEdgeIngressReq) (*edgeingress.EdgeIngress, error) {
+	spec := edgeIngressSpec(&platform.UpdateEdgeIngressReq{
+		Service:       req.Service,
+		Services:      req.Services,
+		ACP:           req.ACP,
+		ACPs:          req.ACPs,
+		CustomDomains: req.CustomDomains,
+		Protocol:      req.Protocol,
+		EntryPoint:    req.EntryPoint,
+		Sticky:        req.Sticky,
+		HealthCheck:   req.HealthCheck,
+		TLS:           req.TLS,
+		Certificate:   req.Certificate,
+	})
+
+	return b.edgeIngress(req.Namespace, req.Name, spec, b.now())
+}
+
+// UpdateEdgeIngress updates an EdgeIngress.
+func (b *Backend) UpdateEdgeIngress(_ context.Context, namespace, name, _ string, req *platform.UpdateEdgeIngressReq) (*edgeingress.EdgeIngress, error) {
+	return b.edgeIngress(namespace, name, edgeIngressSpec(req), b.now())
+}
+
+// DeleteEdgeIngress deletes an EdgeIngress.
+func (b *Backend) DeleteEdgeIngress(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+// edgeIngress builds an EdgeIngress exposed on <name>-<namespace>.<domain>, versioned with the hash of its spec.
+func (b *Backend) edgeIngress(namespace, name string, spec hubv1alpha1.EdgeIngressSpec, updatedAt time.Time) (*edgeingress.EdgeIngress, error) {
+	e := &edgeingress.EdgeIngress{
+		Namespace: namespace,
+		Name:      name,
+		Domain:    fmt.Sprintf("%s-%s.%s", name, namespace, b.domain),
+		Service: edgeingress.Service{
+			Name: spec.Service.Name,
+			Port: spec.Service.Port,
+		},
+		Protocol:    string(spec.Protocol),
+		EntryPoint:  spec.EntryPoint,
+		Sticky:      spec.Sticky,
+		HealthCheck: spec.HealthCheck,
+		TLS:         spec.TLS,
+		Certificate: spec.Certificate,
+		CreatedAt:   updatedAt,
+		UpdatedAt:   updatedAt,
+	}
+
+	for _, svc := range spec.Services {
+		e.Services = append(e.Services, edgeingress.WeightedService(svc))
+	}
+
+	if spec.ACP != nil {
+		e.ACP = &edgeingress.ACP{Name: spec.ACP.Name}
+	}
+	for _, a := range spec.ACPs {
+		e.ACPs = append(e.ACPs, edgeingress.ACP(a))
+	}
+
+	// Domain ownership can't be verified without the platform, custom domains are trusted as is.
+	for _, domain := range spec.CustomDomains {
+		e.CustomDomains = append(e.CustomDomains, edgeingress.CustomDomain{Name: domain, Verified: true})
+	}
+
+	var err error
+	e.Version, err = spec.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("compute spec hash: %w", err)
+	}
+
+	return e, nil
+}
+
+// edgeIngressSpec builds the EdgeIngress spec described by a platform request.
+func edgeIngressSpec(req *platform.UpdateEdgeIngressReq) hubv1alpha1.EdgeIngressSpec {
+	spec := hubv1alpha1.EdgeIngressSpec{
+		Service:       hubv1alpha1.EdgeIngressService(req.Service),
+		Protocol:      hubv1alpha1.EdgeIngressProtocol(req.Protocol),
+		EntryPoint:    req.EntryPoint,
+		Sticky:        req.Sticky,
+		HealthCheck:   req.HealthCheck,
+		TLS:           req.TLS,
+		CustomDomains: req.CustomDomains,
+		Certificate:   req.Certificate,
+	}
+	for _, svc := range req.Services {
+		spec.Services = append(spec.Services, hubv1alpha1.EdgeIngressWeightedService(svc))
+	}
+	if req.ACP != nil {
+		spec.ACP = &hubv1alpha1.EdgeIngressACP{Name: req.ACP.Name}
+	}
+	for _, a := range req.ACPs {
+		spec.ACPs = append(spec.ACPs, hubv1alpha1.EdgeIngressACP(a))
+	}
+
+	return spec
+}
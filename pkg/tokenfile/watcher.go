@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package tokenfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Watcher watches a file holding the Hub token, typically a mounted Secret entry, so that a rotated token is used
+// without restarting the agent.
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	tokenMu sync.RWMutex
+	token   string
+
+	listenersMu sync.RWMutex
+	listeners   []func(token string)
+}
+
+// NewWatcher returns a new Watcher of the token held by the given file, checked at the given interval.
+func NewWatcher(path string, interval time.Duration) (*Watcher, error) {
+	token, err := readToken(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		path:     path,
+		interval: interval,
+		token:    token,
+	}, nil
+}
+
+// Token returns the current token.
+func (w *Watcher) Token() string {
+	w.tokenMu.RLock()
+	defer w.tokenMu.RUnlock()
+
+	return w.token
+}
+
+// AddListener adds a listener called with the new token each time it's rotated. It's a no-op on a nil Watcher, which
+// is the case when the token doesn't come from a file.
+func (w *Watcher) AddListener(listener func(token string)) {
+	if w == nil {
+		return
+	}
+
+	w.listenersMu.Lock()
+	defer w.listenersMu.Unlock()
+
+	w.listeners = append(w.listeners, listener)
+}
+
+// Run runs the Watcher.
+func (w *Watcher) Run(ctx context.Context) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := w.reload(); err != nil {
+				log.Error().Err(err).Str("path", w.path).Msg("Unable to reload token")
+			}
+		}
+	}
+}
+
+func (w *Watcher) reload() error {
+	token, err := readToken(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.tokenMu.Lock()
+	if token == w.token {
+		w.tokenMu.Unlock()
+		return nil
+	}
+	w.token = token
+	w.tokenMu.Unlock()
+
+	log.Info().Str("path", w.path).Msg("Token rotated")
+
+	w.listenersMu.RLock()
+	defer w.listenersMu.RUnlock()
+
+	for _, listener := range w.listeners {
+		listener(token)
+	}
+
+	return nil
+}
+
+func readToken(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read token file: %w", err)
+	}
+
+	// Files are often written with a trailing new line.
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", errors.New("empty token file")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package tokenfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWatcher(t *testing.T) {
+	tests := []struct {
+		desc      string
+		content   string
+		wantToken string
+		wantErr   bool
+	}{
+		{
+			desc:      "token",
+			content:   "token",
+			wantToken: "token",
+		},
+		{
+			desc:      "trailing new line",
+			content:   "token\n",
+			wantToken: "token",
+		},
+		{
+			desc:    "empty",
+			content: " \n",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "token")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			w, err := NewWatcher(path, time.Second)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantToken, w.Token())
+		})
+	}
+}
+
+func TestNewWatcher_missingFile(t *testing.T) {
+	_, err := NewWatcher(filepath.Join(t.TempDir(), "token"), time.Second)
+	assert.Error(t, err)
+}
+
+func TestWatcher_Run(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("token"), 0o600))
+
+	w, err := NewWatcher(path, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	rotated := make(chan string, 1)
+	w.AddListener(func(token string) {
+		rotated <- token
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go w.Run(ctx)
+
+	// Tokens read from an empty file are ignored, the current token is kept.
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "token", w.Token())
+
+	require.NoError(t, os.WriteFile(path, []byte("rotated-token\n"), 0o600))
+
+	select {
+	case token := <-rotated:
+		assert.Equal(t, "rotated-token", token)
+	case <-time.After(time.Second):
+		require.Fail(t, "token rotation not notified")
+	}
+
+	assert.Equal(t, "rotated-token", w.Token())
+}
+
+func TestWatcher_AddListener_nilWatcher(t *testing.T) {
+	var w *Watcher
+
+	assert.NotPanics(t, func() {
+		w.AddListener(func(string) {})
+	})
+}
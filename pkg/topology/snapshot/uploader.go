@@ -0,0 +1,221 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package snapshot exports full topology snapshots to an S3-compatible object storage, so they can be re-ingested by
+// the platform after a data loss or archived by the cluster owner.
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+)
+
+// Config configures the object storage snapshots are uploaded to.
+type Config struct {
+	// Endpoint is the URL of the S3-compatible API, e.g. https://s3.eu-west-1.amazonaws.com.
+	Endpoint string
+	Bucket   string
+	Region   string
+	// Prefix prefixes the keys of the uploaded objects.
+	Prefix string
+
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// EncryptionKey is the 32 bytes AES-256 key snapshots are encrypted with.
+	EncryptionKey []byte
+	// Interval is the interval at which snapshots are uploaded.
+	Interval time.Duration
+}
+
+// Uploader periodically uploads the latest topology state to an object storage. Snapshots are JSON documents,
+// compressed with gzip and encrypted with AES-256-GCM, the nonce prefixing the ciphertext.
+type Uploader struct {
+	client *http.Client
+	cfg    Config
+	bucket *url.URL
+	aead   cipher.AEAD
+
+	stateMu  sync.Mutex
+	state    *state.Cluster
+	uploaded *state.Cluster
+
+	nowFunc func() time.Time
+}
+
+// NewUploader creates an Uploader.
+func NewUploader(client *http.Client, cfg Config) (*Uploader, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("missing bucket")
+	}
+	if cfg.Interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse endpoint: %w", err)
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint %q must be an absolute URL", cfg.Endpoint)
+	}
+
+	if len(cfg.EncryptionKey) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes long, got %d", len(cfg.EncryptionKey))
+	}
+
+	block, err := aes.NewCipher(cfg.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create GCM: %w", err)
+	}
+
+	// Objects are addressed with path-style URLs, supported by S3 and the S3-compatible storages.
+	bucket := endpoint.JoinPath(cfg.Bucket)
+
+	return &Uploader{
+		client:  client,
+		cfg:     cfg,
+		bucket:  bucket,
+		aead:    aead,
+		nowFunc: time.Now,
+	}, nil
+}
+
+// TopologyStateChanged is called every time the topology state changes.
+func (u *Uploader) TopologyStateChanged(_ context.Context, cluster *state.Cluster) {
+	if cluster == nil {
+		return
+	}
+
+	u.stateMu.Lock()
+	u.state = cluster
+	u.stateMu.Unlock()
+}
+
+// Run uploads a snapshot of the latest topology state at every interval, if it changed since the last upload.
+func (u *Uploader) Run(ctx context.Context) {
+	tick := time.NewTicker(u.cfg.Interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if err := u.upload(ctx); err != nil {
+				log.Error().Err(err).Msg("Unable to upload topology snapshot")
+			}
+		}
+	}
+}
+
+func (u *Uploader) upload(ctx context.Context) error {
+	u.stateMu.Lock()
+	cluster, uploaded := u.state, u.uploaded
+	u.stateMu.Unlock()
+
+	// Listeners are given a new state every time the topology is fetched: the same pointer means no new state.
+	if cluster == nil || cluster == uploaded {
+		return nil
+	}
+
+	now := u.nowFunc().UTC()
+
+	body, err := u.encode(cluster)
+	if err != nil {
+		return err
+	}
+
+	key := strings.TrimPrefix(u.cfg.Prefix+now.Format("2006/01/02/20060102T150405Z")+".json.gz.enc", "/")
+
+	if err = u.put(ctx, key, body, now); err != nil {
+		return fmt.Errorf("put %q: %w", key, err)
+	}
+
+	u.stateMu.Lock()
+	u.uploaded = cluster
+	u.stateMu.Unlock()
+
+	log.Debug().Str("key", key).Int("size", len(body)).Msg("Topology snapshot uploaded")
+
+	return nil
+}
+
+// encode serializes, compresses and encrypts the given cluster state.
+func (u *Uploader) encode(cluster *state.Cluster) ([]byte, error) {
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(cluster); err != nil {
+		return nil, fmt.Errorf("encode snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compress snapshot: %w", err)
+	}
+
+	nonce := make([]byte, u.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return u.aead.Seal(nonce, nonce, buf.Bytes(), nil), nil
+}
+
+func (u *Uploader) put(ctx context.Context, key string, body []byte, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.bucket.JoinPath(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Amz-Content-Sha256", hashHex(body))
+	signV4(req, u.cfg.Region, u.cfg.AccessKeyID, u.cfg.SecretAccessKey, now)
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		all, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload got %d: %s", resp.StatusCode, string(all))
+	}
+
+	return nil
+}
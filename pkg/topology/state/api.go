@@ -29,10 +29,14 @@ func (f *Fetcher) getAPIs() (map[string]*API, error) {
 
 	result := make(map[string]*API)
 	for _, api := range apis {
+		if !f.namespaceFilter().Match(api.Namespace) {
+			continue
+		}
+
 		a := &API{
 			Name:       api.Name,
 			Namespace:  api.Namespace,
-			Labels:     api.Labels,
+			Labels:     f.redactor.labels(api.Labels),
 			PathPrefix: api.Spec.PathPrefix,
 			Service: APIService{
 				Name: api.Spec.Service.Name,
@@ -45,6 +49,7 @@ func (f *Fetcher) getAPIs() (map[string]*API, error) {
 					Path:     api.Spec.Service.OpenAPISpec.Path,
 					Protocol: api.Spec.Service.OpenAPISpec.Protocol,
 				},
+				ExternalURL: api.Spec.Service.ExternalURL,
 			},
 		}
 
@@ -71,7 +76,7 @@ func (f *Fetcher) getAPIAccesses() (map[string]*APIAccess, error) {
 	for _, apiAccess := range apiAccesses {
 		a := &APIAccess{
 			Name:                  apiAccess.Name,
-			Labels:                apiAccess.Labels,
+			Labels:                f.redactor.labels(apiAccess.Labels),
 			Groups:                apiAccess.Spec.Groups,
 			APISelector:           apiAccess.Spec.APISelector,
 			APICollectionSelector: apiAccess.Spec.APICollectionSelector,
@@ -93,7 +98,7 @@ func (f *Fetcher) getAPICollections() (map[string]*APICollection, error) {
 	for _, collection := range collections {
 		c := &APICollection{
 			Name:        collection.Name,
-			Labels:      collection.Labels,
+			Labels:      f.redactor.labels(collection.Labels),
 			PathPrefix:  collection.Spec.PathPrefix,
 			APISelector: collection.Spec.APISelector,
 		}
@@ -136,7 +141,7 @@ func (f *Fetcher) getAPIGateways() (map[string]*APIGateway, error) {
 	for _, gateway := range gateways {
 		c := &APIGateway{
 			Name:          gateway.Name,
-			Labels:        gateway.Labels,
+			Labels:        f.redactor.labels(gateway.Labels),
 			APIAccesses:   gateway.Spec.APIAccesses,
 			CustomDomains: gateway.Spec.CustomDomains,
 			HubDomain:     gateway.Status.HubDomain,
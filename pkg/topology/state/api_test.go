@@ -53,7 +53,7 @@ func TestFetcher_GetAPIs(t *testing.T) {
 	objects := kube.LoadK8sObjects(t, "fixtures/api/api.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.getAPIs()
@@ -84,7 +84,7 @@ func TestFetcher_getAPICollections(t *testing.T) {
 	objects := kube.LoadK8sObjects(t, "fixtures/api/api_collection.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.getAPICollections()
@@ -115,7 +115,7 @@ func TestFetcher_GetAPIAccesses(t *testing.T) {
 	objects := kube.LoadK8sObjects(t, "fixtures/api/access.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.getAPIAccesses()
@@ -138,7 +138,7 @@ func TestFetcher_GetAPIPortals(t *testing.T) {
 	objects := kube.LoadK8sObjects(t, "fixtures/api/portal.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.getAPIPortals()
@@ -164,7 +164,7 @@ func TestFetcher_GetAPIGateways(t *testing.T) {
 	objects := kube.LoadK8sObjects(t, "fixtures/api/gateway.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.getAPIGateways()
@@ -30,6 +30,8 @@ type Cluster struct {
 	Ingresses             map[string]*Ingress             `json:"ingresses"`
 	IngressRoutes         map[string]*IngressRoute        `json:"ingressRoutes"`
 	Services              map[string]*Service             `json:"services"`
+	HPAs                  map[string]*HPA                 `json:"hpas"`
+	NetworkPolicies       map[string]*NetworkPolicy       `json:"networkPolicies"`
 	AccessControlPolicies map[string]*AccessControlPolicy `json:"accessControlPolicies"`
 	EdgeIngresses         map[string]*EdgeIngress         `json:"edgeIngresses"`
 	APIs                  map[string]*API                 `json:"apis"`
@@ -37,6 +39,7 @@ type Cluster struct {
 	APICollections        map[string]*APICollection       `json:"apiCollections"`
 	APIPortals            map[string]*APIPortal           `json:"apiPortals"`
 	APIGateways           map[string]*APIGateway          `json:"apiGateways"`
+	IngressControllers    map[string]*IngressController   `json:"ingressControllers"`
 }
 
 // ResourceMeta represents the metadata which identify a Kubernetes resource.
@@ -57,6 +60,90 @@ type Service struct {
 	ExternalPorts []int              `json:"externalPorts,omitempty"`
 }
 
+// IngressController describes a third-party ingress controller running in the cluster.
+type IngressController struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Type is the kind of ingress controller. It matches the name of the parser of its metrics.
+	Type string `json:"type"`
+	// MetricsURLs are the Prometheus endpoints of the running pods of the controller.
+	MetricsURLs []string `json:"metricsURLs,omitempty"`
+}
+
+// HPA describes a Kubernetes HorizontalPodAutoscaler.
+type HPA struct {
+	Name            string         `json:"name"`
+	Namespace       string         `json:"namespace"`
+	ScaleTargetRef  HPAScaleTarget `json:"scaleTargetRef"`
+	MinReplicas     int32          `json:"minReplicas"`
+	MaxReplicas     int32          `json:"maxReplicas"`
+	CurrentReplicas int32          `json:"currentReplicas"`
+	DesiredReplicas int32          `json:"desiredReplicas"`
+	CurrentMetrics  []HPAMetric    `json:"currentMetrics,omitempty"`
+}
+
+// HPAScaleTarget identifies the workload scaled by an HPA.
+type HPAScaleTarget struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// HPAMetric describes the last observed value of a metric used by an HPA.
+type HPAMetric struct {
+	// Type is the type of the metric source: Resource, ContainerResource, Pods, Object or External.
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+
+	Value              string `json:"value,omitempty"`
+	AverageValue       string `json:"averageValue,omitempty"`
+	AverageUtilization *int32 `json:"averageUtilization,omitempty"`
+}
+
+// NetworkPolicy describes a Kubernetes NetworkPolicy. Label selectors are given in their string form, an empty
+// selector selecting everything.
+type NetworkPolicy struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	PodSelector string `json:"podSelector"`
+	// PolicyTypes are the directions of the traffic the policy applies to: Ingress, Egress or both.
+	PolicyTypes []string            `json:"policyTypes"`
+	Ingress     []NetworkPolicyRule `json:"ingress,omitempty"`
+	Egress      []NetworkPolicyRule `json:"egress,omitempty"`
+}
+
+// NetworkPolicyRule describes the traffic allowed by a NetworkPolicy. A rule without ports allows all ports,
+// and a rule without peers allows all peers.
+type NetworkPolicyRule struct {
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+	Peers []NetworkPolicyPeer `json:"peers,omitempty"`
+}
+
+// NetworkPolicyPort describes a port, or a range of ports, allowed by a NetworkPolicy rule.
+type NetworkPolicyPort struct {
+	Protocol string `json:"protocol"`
+	// Port is either a port number or a named port. It is empty when all the ports are allowed.
+	Port    string `json:"port,omitempty"`
+	EndPort *int32 `json:"endPort,omitempty"`
+}
+
+// NetworkPolicyPeer describes the peers allowed by a NetworkPolicy rule. It either selects pods, optionally restricted
+// to a given namespace, or an IP block.
+type NetworkPolicyPeer struct {
+	// Namespace is set when the peer selects pods of the namespace of the policy only.
+	Namespace         string                `json:"namespace,omitempty"`
+	NamespaceSelector string                `json:"namespaceSelector,omitempty"`
+	PodSelector       string                `json:"podSelector,omitempty"`
+	IPBlock           *NetworkPolicyIPBlock `json:"ipBlock,omitempty"`
+}
+
+// NetworkPolicyIPBlock describes a CIDR allowed by a NetworkPolicy rule.
+type NetworkPolicyIPBlock struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
 // OpenAPISpecLocation describes the location of an OpenAPI specification.
 type OpenAPISpecLocation struct {
 	Path string `json:"path"`
@@ -86,9 +173,11 @@ type IngressRoute struct {
 	ResourceMeta
 	IngressMeta
 
-	TLS      *IngressRouteTLS `json:"tls,omitempty"`
-	Routes   []Route          `json:"routes,omitempty"`
-	Services []string         `json:"services,omitempty"`
+	// IngressClassName is the ingress class of the Traefik instance serving this IngressRoute, if any.
+	IngressClassName *string          `json:"ingressClassName,omitempty"`
+	TLS              *IngressRouteTLS `json:"tls,omitempty"`
+	Routes           []Route          `json:"routes,omitempty"`
+	Services         []string         `json:"services,omitempty"`
 }
 
 // IngressRouteTLS represents a simplified Traefik IngressRoute TLS configuration.
@@ -260,6 +349,7 @@ type EdgeIngress struct {
 	Status    EdgeIngressStatus  `json:"status"`
 	Service   EdgeIngressService `json:"service"`
 	ACP       *EdgeIngressACP    `json:"acp,omitempty"`
+	ACPs      []EdgeIngressACP   `json:"acps,omitempty"`
 }
 
 // EdgeIngressStatus is the exposition status of an edge ingress.
@@ -297,6 +387,7 @@ type APIService struct {
 	Name        string                `json:"name"`
 	Port        APIServiceBackendPort `json:"port"`
 	OpenAPISpec OpenAPISpec           `json:"openApiSpec,omitempty"`
+	ExternalURL string                `json:"externalUrl,omitempty"`
 }
 
 // APIServiceBackendPort is the service port being referenced.
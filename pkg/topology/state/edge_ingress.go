@@ -27,6 +27,10 @@ func (f *Fetcher) getEdgeIngresses() (map[string]*EdgeIngress, error) {
 
 	result := make(map[string]*EdgeIngress)
 	for _, edgeIngress := range edgeIngresses {
+		if !f.namespaceFilter().Match(edgeIngress.Namespace) {
+			continue
+		}
+
 		status := EdgeIngressStatusDown
 		if edgeIngress.Status.Connection == "UP" {
 			status = EdgeIngressStatusUp
@@ -37,6 +41,11 @@ func (f *Fetcher) getEdgeIngresses() (map[string]*EdgeIngress, error) {
 			acp = &EdgeIngressACP{Name: edgeIngress.Spec.ACP.Name}
 		}
 
+		var acps []EdgeIngressACP
+		for _, a := range edgeIngress.Spec.ACPs {
+			acps = append(acps, EdgeIngressACP(a))
+		}
+
 		result[objectKey(edgeIngress.Name, edgeIngress.Namespace)] = &EdgeIngress{
 			Name:      edgeIngress.Name,
 			Namespace: edgeIngress.Namespace,
@@ -45,7 +54,8 @@ func (f *Fetcher) getEdgeIngresses() (map[string]*EdgeIngress, error) {
 				Name: edgeIngress.Spec.Service.Name,
 				Port: edgeIngress.Spec.Service.Port,
 			},
-			ACP: acp,
+			ACP:  acp,
+			ACPs: acps,
 		}
 	}
 
@@ -117,7 +117,7 @@ func TestFetcher_GetEdgeIngresses(t *testing.T) {
 			traefikClient := traefikcrdfake.NewSimpleClientset()
 			hubClient := hubfake.NewSimpleClientset(objects...)
 
-			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 			require.NoError(t, err)
 
 			got, err := f.getEdgeIngresses()
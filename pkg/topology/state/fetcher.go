@@ -19,8 +19,10 @@ package state
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-version"
@@ -32,9 +34,11 @@ import (
 	traefikinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/informers/externalversions"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kubevers"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/discovery"
 	kinformers "k8s.io/client-go/informers"
 	kclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Fetcher fetches Kubernetes resources and converts them into a filtered and simplified state.
@@ -45,10 +49,26 @@ type Fetcher struct {
 	hub       hubinformers.SharedInformerFactory
 	traefik   traefikinformers.SharedInformerFactory
 	clientSet kclientset.Interface
+
+	namespacesMu sync.RWMutex
+	namespaces   NamespaceFilter
+	redactor     redactor
+
+	changes chan struct{}
 }
 
-// NewFetcher creates a new Fetcher.
-func NewFetcher(ctx context.Context, clientSet kclientset.Interface, traefikClientSet traefikclientset.Interface, hubClientSet hubclientset.Interface) (*Fetcher, error) {
+// NewFetcher creates a new Fetcher, collecting resources from the namespaces selected by the given filter.
+// The values of the annotations and labels selected by the redaction filter are redacted from the state.
+func NewFetcher(ctx context.Context, clientSet kclientset.Interface, traefikClientSet traefikclientset.Interface, hubClientSet hubclientset.Interface, namespaces NamespaceFilter, redaction RedactionFilter) (*Fetcher, error) {
+	if err := namespaces.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid namespace filter: %w", err)
+	}
+
+	redactor, err := newRedactor(redaction)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction filter: %w", err)
+	}
+
 	serverVersion, err := clientSet.Discovery().ServerVersion()
 	if err != nil {
 		return nil, fmt.Errorf("get server version: %w", err)
@@ -63,29 +83,52 @@ func NewFetcher(ctx context.Context, clientSet kclientset.Interface, traefikClie
 		return nil, fmt.Errorf("unsupported version: %s", serverSemVer)
 	}
 
-	return watchAll(ctx, clientSet, traefikClientSet, hubClientSet, serverVersion.GitVersion)
+	f, err := watchAll(ctx, clientSet, traefikClientSet, hubClientSet, serverVersion.GitVersion, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	f.redactor = redactor
+
+	return f, nil
 }
 
-func watchAll(ctx context.Context, clientSet kclientset.Interface, traefikClientSet traefikclientset.Interface, hubClientSet hubclientset.Interface, serverVersion string) (*Fetcher, error) {
-	kubernetesFactory := kinformers.NewSharedInformerFactoryWithOptions(clientSet, 5*time.Minute)
+func watchAll(ctx context.Context, clientSet kclientset.Interface, traefikClientSet traefikclientset.Interface, hubClientSet hubclientset.Interface, serverVersion string, namespaces NamespaceFilter) (*Fetcher, error) {
+	// Restrict the watch to a single namespace when possible, otherwise resources are filtered out when fetching the state.
+	watchedNamespace := namespaces.watchedNamespace()
+
+	kubernetesFactory := kinformers.NewSharedInformerFactoryWithOptions(clientSet, 5*time.Minute,
+		kinformers.WithNamespace(watchedNamespace))
+
+	// Pods are used to fetch service logs and to detect ingress controllers. Only changes on ingress controller pods
+	// alter the topology, they are watched for changes below.
+	pods := kubernetesFactory.Core().V1().Pods().Informer()
+
+	var watched []cache.SharedIndexInformer
+	watched = append(watched, kubernetesFactory.Core().V1().Services().Informer())
+
+	if kubevers.SupportsAutoscalingV2HPAs(serverVersion) {
+		watched = append(watched, kubernetesFactory.Autoscaling().V2().HorizontalPodAutoscalers().Informer())
+	} else {
+		watched = append(watched, kubernetesFactory.Autoscaling().V1().HorizontalPodAutoscalers().Informer())
+	}
 
-	kubernetesFactory.Core().V1().Pods().Informer()
-	kubernetesFactory.Core().V1().Services().Informer()
+	watched = append(watched, kubernetesFactory.Networking().V1().NetworkPolicies().Informer())
 
 	if kubevers.SupportsNetV1IngressClasses(serverVersion) {
-		kubernetesFactory.Networking().V1().IngressClasses().Informer()
+		watched = append(watched, kubernetesFactory.Networking().V1().IngressClasses().Informer())
 	} else if kubevers.SupportsNetV1Beta1IngressClasses(serverVersion) {
-		kubernetesFactory.Networking().V1beta1().IngressClasses().Informer()
+		watched = append(watched, kubernetesFactory.Networking().V1beta1().IngressClasses().Informer())
 	}
 
 	if kubevers.SupportsNetV1Ingresses(serverVersion) {
-		kubernetesFactory.Networking().V1().Ingresses().Informer()
+		watched = append(watched, kubernetesFactory.Networking().V1().Ingresses().Informer())
 	} else {
 		// Since we only support Kubernetes v1.14 and up, we always have at least net v1beta1 Ingresses.
-		kubernetesFactory.Networking().V1beta1().Ingresses().Informer()
+		watched = append(watched, kubernetesFactory.Networking().V1beta1().Ingresses().Informer())
 	}
 
-	traefikFactory := traefikinformers.NewSharedInformerFactoryWithOptions(traefikClientSet, 5*time.Minute)
+	traefikFactory := traefikinformers.NewSharedInformerFactoryWithOptions(traefikClientSet, 5*time.Minute,
+		traefikinformers.WithNamespace(watchedNamespace))
 
 	hasTraefikCRDs, err := hasTraefikCRDs(clientSet.Discovery())
 	if err != nil {
@@ -93,8 +136,8 @@ func watchAll(ctx context.Context, clientSet kclientset.Interface, traefikClient
 	}
 
 	if hasTraefikCRDs {
-		traefikFactory.Traefik().V1alpha1().IngressRoutes().Informer()
-		traefikFactory.Traefik().V1alpha1().TraefikServices().Informer()
+		watched = append(watched, traefikFactory.Traefik().V1alpha1().IngressRoutes().Informer())
+		watched = append(watched, traefikFactory.Traefik().V1alpha1().TraefikServices().Informer())
 	} else {
 		msg := "The agent has been installed in a cluster where the Traefik Proxy CustomResourceDefinitions are not installed. " +
 			"If you want to install these CustomResourceDefinitions and take advantage of them in Traefik Hub, " +
@@ -103,14 +146,28 @@ func watchAll(ctx context.Context, clientSet kclientset.Interface, traefikClient
 		log.Info().Msg(msg)
 	}
 
-	hubFactory := hubinformers.NewSharedInformerFactoryWithOptions(hubClientSet, 5*time.Minute)
-	hubFactory.Hub().V1alpha1().AccessControlPolicies().Informer()
-	hubFactory.Hub().V1alpha1().EdgeIngresses().Informer()
-	hubFactory.Hub().V1alpha1().APIs().Informer()
-	hubFactory.Hub().V1alpha1().APIAccesses().Informer()
-	hubFactory.Hub().V1alpha1().APICollections().Informer()
-	hubFactory.Hub().V1alpha1().APIPortals().Informer()
-	hubFactory.Hub().V1alpha1().APIGateways().Informer()
+	hubFactory := hubinformers.NewSharedInformerFactoryWithOptions(hubClientSet, 5*time.Minute,
+		hubinformers.WithNamespace(watchedNamespace))
+	watched = append(watched, hubFactory.Hub().V1alpha1().AccessControlPolicies().Informer())
+	watched = append(watched, hubFactory.Hub().V1alpha1().EdgeIngresses().Informer())
+	watched = append(watched, hubFactory.Hub().V1alpha1().APIs().Informer())
+	watched = append(watched, hubFactory.Hub().V1alpha1().APIAccesses().Informer())
+	watched = append(watched, hubFactory.Hub().V1alpha1().APICollections().Informer())
+	watched = append(watched, hubFactory.Hub().V1alpha1().APIPortals().Informer())
+	watched = append(watched, hubFactory.Hub().V1alpha1().APIGateways().Informer())
+
+	changes := make(chan struct{}, 1)
+	notifier := changeNotifier(changes)
+	for _, informer := range watched {
+		if _, err = informer.AddEventHandler(notifier); err != nil {
+			return nil, fmt.Errorf("add topology change handler: %w", err)
+		}
+	}
+
+	podNotifier := cache.FilteringResourceEventHandler{FilterFunc: isIngressControllerPod, Handler: notifier}
+	if _, err = pods.AddEventHandler(podNotifier); err != nil {
+		return nil, fmt.Errorf("add ingress controller change handler: %w", err)
+	}
 
 	kubernetesFactory.Start(ctx.Done())
 	hubFactory.Start(ctx.Done())
@@ -140,9 +197,72 @@ func watchAll(ctx context.Context, clientSet kclientset.Interface, traefikClient
 		hub:           hubFactory,
 		traefik:       traefikFactory,
 		clientSet:     clientSet,
+		namespaces:    namespaces,
+		changes:       changes,
 	}, nil
 }
 
+// SetNamespaces changes the namespaces the resources of the topology are collected from. Only the filtering of the
+// watched resources can change: switching between watching a single namespace and all of them requires a restart.
+func (f *Fetcher) SetNamespaces(namespaces NamespaceFilter) error {
+	if err := namespaces.Validate(); err != nil {
+		return fmt.Errorf("invalid namespace filter: %w", err)
+	}
+
+	f.namespacesMu.Lock()
+	if namespaces.watchedNamespace() != f.namespaces.watchedNamespace() {
+		f.namespacesMu.Unlock()
+		return errors.New("the watched namespace changed, a restart is required")
+	}
+	f.namespaces = namespaces
+	f.namespacesMu.Unlock()
+
+	// The topology is fetched again, with the resources of the newly selected namespaces.
+	select {
+	case f.changes <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (f *Fetcher) namespaceFilter() NamespaceFilter {
+	f.namespacesMu.RLock()
+	defer f.namespacesMu.RUnlock()
+
+	return f.namespaces
+}
+
+// Changes returns a channel receiving a value whenever a resource part of the topology changes.
+// Notifications are coalesced: a single value is pending at most, no matter how many changes occurred.
+func (f *Fetcher) Changes() <-chan struct{} {
+	return f.changes
+}
+
+func changeNotifier(changes chan<- struct{}) cache.ResourceEventHandler {
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { notify() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Periodic resyncs send updates for unchanged objects.
+			oldMeta, oldOK := oldObj.(metav1.Object)
+			newMeta, newOK := newObj.(metav1.Object)
+			if oldOK && newOK && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+				return
+			}
+
+			notify()
+		},
+		DeleteFunc: func(interface{}) { notify() },
+	}
+}
+
 // FetchState assembles a cluster state from Kubernetes resources.
 func (f *Fetcher) FetchState() (*Cluster, error) {
 	var cluster Cluster
@@ -154,6 +274,16 @@ func (f *Fetcher) FetchState() (*Cluster, error) {
 		return nil, err
 	}
 
+	cluster.HPAs, err = f.getHPAs()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.NetworkPolicies, err = f.getNetworkPolicies()
+	if err != nil {
+		return nil, err
+	}
+
 	cluster.Ingresses, err = f.getIngresses()
 	if err != nil {
 		return nil, err
@@ -199,6 +329,11 @@ func (f *Fetcher) FetchState() (*Cluster, error) {
 		return nil, err
 	}
 
+	cluster.IngressControllers, err = f.getIngressControllers()
+	if err != nil {
+		return nil, err
+	}
+
 	return &cluster, nil
 }
 
@@ -235,22 +370,35 @@ func objectKey(name, ns string) string {
 }
 
 func ingressKey(meta ResourceMeta) string {
-	return fmt.Sprintf("%s.%s.%s", objectKey(meta.Name, meta.Namespace), strings.ToLower(meta.Kind), meta.Group)
-}
-
-func sanitizeAnnotations(annotations map[string]string) map[string]string {
-	if annotations == nil {
-		return nil
+	// This is called for every ingress of the cluster on each fetch: build the key in a single allocation.
+	var b strings.Builder
+	b.Grow(len(meta.Name) + len(meta.Namespace) + len(meta.Kind) + len(meta.Group) + 3)
+
+	b.WriteString(meta.Name)
+	b.WriteByte('@')
+	b.WriteString(meta.Namespace)
+	b.WriteByte('.')
+	for i := 0; i < len(meta.Kind); i++ {
+		c := meta.Kind[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b.WriteByte(c)
 	}
+	b.WriteByte('.')
+	b.WriteString(meta.Group)
 
-	result := make(map[string]string)
-	for name, value := range annotations {
-		if name == "kubectl.kubernetes.io/last-applied-configuration" {
-			continue
-		}
+	return b.String()
+}
 
-		result[name] = value
+// containsString reports whether s is in values. Services are deduplicated this way rather than with a set, as
+// resources only reference a handful of them and allocating a map for each resource is costly on large clusters.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
 	}
 
-	return result
+	return false
 }
@@ -0,0 +1,184 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kversion "k8s.io/apimachinery/pkg/version"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// benchmarkScales are the numbers of Kubernetes objects the topology benchmarks run with.
+var benchmarkScales = []int{1000, 10000, 50000}
+
+func BenchmarkFetcher_FetchState(b *testing.B) {
+	for _, scale := range benchmarkScales {
+		scale := scale
+		b.Run(fmt.Sprintf("objects=%d", scale), func(b *testing.B) {
+			benchmarkFetchState(b, scale)
+		})
+	}
+}
+
+// TestFetcher_FetchState_budgets makes sure fetching the state of large clusters stays within its performance
+// budgets. Timings depend on the host, so budgets are only enforced when TOPOLOGY_BUDGETS is set, which CI does.
+func TestFetcher_FetchState_budgets(t *testing.T) {
+	if os.Getenv("TOPOLOGY_BUDGETS") == "" {
+		t.Skip("TOPOLOGY_BUDGETS is not set")
+	}
+
+	tests := []struct {
+		objects        int
+		maxNsPerOp     int64
+		maxBytesPerOp  int64
+		maxAllocsPerOp int64
+	}{
+		{objects: 1000, maxNsPerOp: 5_000_000, maxBytesPerOp: 450_000, maxAllocsPerOp: 6_000},
+		{objects: 10000, maxNsPerOp: 75_000_000, maxBytesPerOp: 4_500_000, maxAllocsPerOp: 55_000},
+		{objects: 50000, maxNsPerOp: 400_000_000, maxBytesPerOp: 22_000_000, maxAllocsPerOp: 275_000},
+	}
+
+	// Sub-tests are not run in parallel: they would compete for the CPU and distort the measures.
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("objects=%d", test.objects), func(t *testing.T) {
+			res := testing.Benchmark(func(b *testing.B) {
+				benchmarkFetchState(b, test.objects)
+			})
+
+			assert.LessOrEqual(t, res.NsPerOp(), test.maxNsPerOp, "ns/op")
+			assert.LessOrEqual(t, res.AllocedBytesPerOp(), test.maxBytesPerOp, "B/op")
+			assert.LessOrEqual(t, res.AllocsPerOp(), test.maxAllocsPerOp, "allocs/op")
+		})
+	}
+}
+
+func benchmarkFetchState(b *testing.B, objects int) {
+	b.Helper()
+
+	f := newBenchmarkFetcher(b, objects)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := f.FetchState(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// newBenchmarkFetcher returns a Fetcher watching a cluster made of the given number of objects, half of them being
+// Services and the other half Ingresses routing to two of these Services.
+func newBenchmarkFetcher(tb testing.TB, objects int) *Fetcher {
+	tb.Helper()
+
+	k8sObjects := make([]runtime.Object, 0, objects)
+	for i := 0; i < objects/2; i++ {
+		namespace := fmt.Sprintf("ns-%d", i%100)
+
+		k8sObjects = append(k8sObjects, benchmarkService(namespace, i), benchmarkIngress(namespace, i))
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(k8sObjects...)
+	traefikClient := traefikcrdfake.NewSimpleClientset()
+	hubClient := hubfake.NewSimpleClientset()
+
+	fakeDiscovery, ok := kubeClient.Discovery().(*discoveryfake.FakeDiscovery)
+	if !ok {
+		tb.Fatal("couldn't convert Discovery() to *FakeDiscovery")
+	}
+	fakeDiscovery.FakedServerVersion = &kversion.Info{GitVersion: "v1.22.0"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tb.Cleanup(cancel)
+
+	f, err := NewFetcher(ctx, kubeClient, traefikClient, hubClient, NamespaceFilter{}, RedactionFilter{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return f
+}
+
+func benchmarkService(namespace string, i int) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("service-%d", i),
+			Namespace: namespace,
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{"app": fmt.Sprintf("app-%d", i)},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80},
+				{Name: "https", Port: 443},
+			},
+		},
+	}
+}
+
+func benchmarkIngress(namespace string, i int) *netv1.Ingress {
+	pathType := netv1.PathTypePrefix
+	backend := func(name string) netv1.IngressBackend {
+		return netv1.IngressBackend{
+			Service: &netv1.IngressServiceBackend{
+				Name: name,
+				Port: netv1.ServiceBackendPort{Number: 80},
+			},
+		}
+	}
+
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("ingress-%d", i),
+			Namespace:   namespace,
+			Annotations: map[string]string{"traefik.ingress.kubernetes.io/router.tls": "true"},
+			Labels:      map[string]string{"app": fmt.Sprintf("app-%d", i)},
+		},
+		Spec: netv1.IngressSpec{
+			Rules: []netv1.IngressRule{
+				{
+					Host: fmt.Sprintf("app-%d.example.com", i),
+					IngressRuleValue: netv1.IngressRuleValue{
+						HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{
+								{Path: "/", PathType: &pathType, Backend: backend(fmt.Sprintf("service-%d", i))},
+								{Path: "/api", PathType: &pathType, Backend: backend(fmt.Sprintf("service-%d", i+1))},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -20,11 +20,13 @@ package state
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	netv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -75,7 +77,7 @@ func TestNewFetcher_handlesUnsupportedVersions(t *testing.T) {
 
 			fakeDiscovery.FakedServerVersion = &kversion.Info{GitVersion: test.serverVersion}
 
-			_, err := NewFetcher(context.Background(), kubeClient, traefikClient, hubClient)
+			_, err := NewFetcher(context.Background(), kubeClient, traefikClient, hubClient, NamespaceFilter{}, RedactionFilter{})
 			test.wantErr(t, err)
 		})
 	}
@@ -193,7 +195,7 @@ func TestNewFetcher_handlesAllIngressAPIVersions(t *testing.T) {
 
 			fakeDiscovery.FakedServerVersion = &kversion.Info{GitVersion: test.serverVersion}
 
-			f, err := NewFetcher(context.Background(), kubeClient, traefikClient, hubClient)
+			f, err := NewFetcher(context.Background(), kubeClient, traefikClient, hubClient, NamespaceFilter{}, RedactionFilter{})
 			require.NoError(t, err)
 
 			got, err := f.getIngresses()
@@ -203,3 +205,108 @@ func TestNewFetcher_handlesAllIngressAPIVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestFetcher_Changes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeClient := kubefake.NewSimpleClientset()
+	traefikClient := traefikcrdfake.NewSimpleClientset()
+	hubClient := hubfake.NewSimpleClientset()
+
+	f, err := watchAll(ctx, kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
+	require.NoError(t, err)
+
+	// Drain the notification sent when filling the caches.
+	select {
+	case <-f.Changes():
+	default:
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "my-pod", Namespace: "ns"}}
+	_, err = kubeClient.CoreV1().Pods("ns").Create(ctx, pod, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	assertNoChange(t, f)
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "my-svc", Namespace: "ns"}}
+	_, err = kubeClient.CoreV1().Services("ns").Create(ctx, svc, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	select {
+	case <-f.Changes():
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no change notified after creating a service")
+	}
+}
+
+func TestFetcher_SetNamespaces(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "apps"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "default"}},
+	)
+	traefikClient := traefikcrdfake.NewSimpleClientset()
+	hubClient := hubfake.NewSimpleClientset()
+
+	f, err := watchAll(ctx, kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{Namespaces: []string{"apps", "default"}})
+	require.NoError(t, err)
+
+	// Drain the notification sent when filling the caches.
+	select {
+	case <-f.Changes():
+	default:
+	}
+
+	err = f.SetNamespaces(NamespaceFilter{ExcludeNamespaces: []string{"default"}})
+	require.NoError(t, err)
+
+	select {
+	case <-f.Changes():
+	default:
+		require.Fail(t, "no change notified after setting the namespaces")
+	}
+
+	services, err := f.getServices()
+	require.NoError(t, err)
+	assert.Len(t, services, 1)
+	assert.Contains(t, services, "svc@apps")
+
+	// Watching a single namespace instead of all of them requires a restart.
+	err = f.SetNamespaces(NamespaceFilter{Namespaces: []string{"apps"}})
+	assert.Error(t, err)
+
+	err = f.SetNamespaces(NamespaceFilter{Namespaces: []string{"apps"}, ExcludeNamespaces: []string{"apps"}})
+	assert.Error(t, err)
+}
+
+func TestChangeNotifier(t *testing.T) {
+	changes := make(chan struct{}, 1)
+	notifier := changeNotifier(changes)
+
+	svc := func(resourceVersion string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", ResourceVersion: resourceVersion}}
+	}
+
+	// Resyncs don't notify any change.
+	notifier.OnUpdate(svc("1"), svc("1"))
+	assert.Empty(t, changes)
+
+	// Changes are coalesced.
+	notifier.OnAdd(svc("1"))
+	notifier.OnUpdate(svc("1"), svc("2"))
+	notifier.OnDelete(svc("2"))
+	assert.Len(t, changes, 1)
+}
+
+func assertNoChange(t *testing.T, f *Fetcher) {
+	t.Helper()
+
+	select {
+	case <-f.Changes():
+		assert.Fail(t, "unexpected change notified")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"github.com/traefik/hub-agent-kubernetes/pkg/kubevers"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func (f *Fetcher) getHPAs() (map[string]*HPA, error) {
+	if !kubevers.SupportsAutoscalingV2HPAs(f.serverVersion) {
+		return f.getV1HPAs()
+	}
+
+	hpas, err := f.k8s.Autoscaling().V2().HorizontalPodAutoscalers().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*HPA, len(hpas))
+	for _, hpa := range hpas {
+		if !f.namespaceFilter().Match(hpa.Namespace) {
+			continue
+		}
+
+		var metrics []HPAMetric
+		for _, metric := range hpa.Status.CurrentMetrics {
+			metrics = append(metrics, toHPAMetric(metric))
+		}
+
+		result[objectKey(hpa.Name, hpa.Namespace)] = &HPA{
+			Name:      hpa.Name,
+			Namespace: hpa.Namespace,
+			ScaleTargetRef: HPAScaleTarget{
+				APIVersion: hpa.Spec.ScaleTargetRef.APIVersion,
+				Kind:       hpa.Spec.ScaleTargetRef.Kind,
+				Name:       hpa.Spec.ScaleTargetRef.Name,
+			},
+			MinReplicas:     minReplicas(hpa.Spec.MinReplicas),
+			MaxReplicas:     hpa.Spec.MaxReplicas,
+			CurrentReplicas: hpa.Status.CurrentReplicas,
+			DesiredReplicas: hpa.Status.DesiredReplicas,
+			CurrentMetrics:  metrics,
+		}
+	}
+
+	return result, nil
+}
+
+// getV1HPAs returns the HPAs of clusters which don't support autoscaling v2, for which only the CPU utilization
+// is available.
+func (f *Fetcher) getV1HPAs() (map[string]*HPA, error) {
+	hpas, err := f.k8s.Autoscaling().V1().HorizontalPodAutoscalers().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*HPA, len(hpas))
+	for _, hpa := range hpas {
+		if !f.namespaceFilter().Match(hpa.Namespace) {
+			continue
+		}
+
+		var metrics []HPAMetric
+		if hpa.Status.CurrentCPUUtilizationPercentage != nil {
+			metrics = append(metrics, HPAMetric{
+				Type:               string(autoscalingv2.ResourceMetricSourceType),
+				Name:               string(corev1.ResourceCPU),
+				AverageUtilization: hpa.Status.CurrentCPUUtilizationPercentage,
+			})
+		}
+
+		result[objectKey(hpa.Name, hpa.Namespace)] = &HPA{
+			Name:            hpa.Name,
+			Namespace:       hpa.Namespace,
+			ScaleTargetRef:  toV1HPAScaleTarget(hpa.Spec.ScaleTargetRef),
+			MinReplicas:     minReplicas(hpa.Spec.MinReplicas),
+			MaxReplicas:     hpa.Spec.MaxReplicas,
+			CurrentReplicas: hpa.Status.CurrentReplicas,
+			DesiredReplicas: hpa.Status.DesiredReplicas,
+			CurrentMetrics:  metrics,
+		}
+	}
+
+	return result, nil
+}
+
+func toV1HPAScaleTarget(ref autoscalingv1.CrossVersionObjectReference) HPAScaleTarget {
+	return HPAScaleTarget{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Name:       ref.Name,
+	}
+}
+
+// minReplicas returns the minimum number of replicas of an HPA, which defaults to 1.
+func minReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+
+	return *replicas
+}
+
+func toHPAMetric(metric autoscalingv2.MetricStatus) HPAMetric {
+	result := HPAMetric{Type: string(metric.Type)}
+
+	var current autoscalingv2.MetricValueStatus
+	switch metric.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		if metric.Resource != nil {
+			result.Name = string(metric.Resource.Name)
+			current = metric.Resource.Current
+		}
+	case autoscalingv2.ContainerResourceMetricSourceType:
+		if metric.ContainerResource != nil {
+			result.Name = string(metric.ContainerResource.Name)
+			result.Container = metric.ContainerResource.Container
+			current = metric.ContainerResource.Current
+		}
+	case autoscalingv2.PodsMetricSourceType:
+		if metric.Pods != nil {
+			result.Name = metric.Pods.Metric.Name
+			current = metric.Pods.Current
+		}
+	case autoscalingv2.ObjectMetricSourceType:
+		if metric.Object != nil {
+			result.Name = metric.Object.Metric.Name
+			current = metric.Object.Current
+		}
+	case autoscalingv2.ExternalMetricSourceType:
+		if metric.External != nil {
+			result.Name = metric.External.Metric.Name
+			current = metric.External.Current
+		}
+	}
+
+	if current.Value != nil {
+		result.Value = current.Value.String()
+	}
+	if current.AverageValue != nil {
+		result.AverageValue = current.AverageValue.String()
+	}
+	result.AverageUtilization = current.AverageUtilization
+
+	return result
+}
@@ -0,0 +1,133 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestFetcher_GetHPAs(t *testing.T) {
+	tests := []struct {
+		desc          string
+		fixture       string
+		serverVersion string
+		want          map[string]*HPA
+	}{
+		{
+			desc:          "autoscaling v2",
+			fixture:       "fixtures/hpa/hpa-v2.yml",
+			serverVersion: "v1.23.0",
+			want: map[string]*HPA{
+				"whoami@my-ns": {
+					Name:      "whoami",
+					Namespace: "my-ns",
+					ScaleTargetRef: HPAScaleTarget{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       "whoami",
+					},
+					MinReplicas:     2,
+					MaxReplicas:     10,
+					CurrentReplicas: 3,
+					DesiredReplicas: 4,
+					CurrentMetrics: []HPAMetric{
+						{
+							Type:               "Resource",
+							Name:               "cpu",
+							AverageValue:       "230m",
+							AverageUtilization: pointer.Int32(92),
+						},
+						{
+							Type:         "Pods",
+							Name:         "requests_per_second",
+							AverageValue: "120",
+						},
+					},
+				},
+				"without-status@my-ns": {
+					Name:      "without-status",
+					Namespace: "my-ns",
+					ScaleTargetRef: HPAScaleTarget{
+						APIVersion: "apps/v1",
+						Kind:       "StatefulSet",
+						Name:       "db",
+					},
+					MinReplicas: 1,
+					MaxReplicas: 3,
+				},
+			},
+		},
+		{
+			desc:          "autoscaling v1",
+			fixture:       "fixtures/hpa/hpa-v1.yml",
+			serverVersion: "v1.22.0",
+			want: map[string]*HPA{
+				"whoami@my-ns": {
+					Name:      "whoami",
+					Namespace: "my-ns",
+					ScaleTargetRef: HPAScaleTarget{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       "whoami",
+					},
+					MinReplicas:     2,
+					MaxReplicas:     10,
+					CurrentReplicas: 3,
+					DesiredReplicas: 4,
+					CurrentMetrics: []HPAMetric{
+						{
+							Type:               "Resource",
+							Name:               "cpu",
+							AverageUtilization: pointer.Int32(92),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			objects := kube.LoadK8sObjects(t, test.fixture)
+
+			kubeClient := kubefake.NewSimpleClientset(objects...)
+			traefikClient := traefikcrdfake.NewSimpleClientset()
+			hubClient := hubfake.NewSimpleClientset()
+
+			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, test.serverVersion, NamespaceFilter{})
+			require.NoError(t, err)
+
+			got, err := f.getHPAs()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -30,8 +30,12 @@ func (f *Fetcher) getIngresses() (map[string]*Ingress, error) {
 		return nil, err
 	}
 
-	result := make(map[string]*Ingress)
+	result := make(map[string]*Ingress, len(ingresses))
 	for _, ingress := range ingresses {
+		if !f.namespaceFilter().Match(ingress.Namespace) {
+			continue
+		}
+
 		ing := &Ingress{
 			ResourceMeta: ResourceMeta{
 				Kind:      "Ingress",
@@ -40,10 +44,10 @@ func (f *Fetcher) getIngresses() (map[string]*Ingress, error) {
 				Namespace: ingress.Namespace,
 			},
 			IngressMeta: IngressMeta{
-				Annotations: sanitizeAnnotations(ingress.Annotations),
-				Labels:      ingress.Labels,
+				Annotations: f.redactor.annotations(ingress.Annotations),
+				Labels:      f.redactor.labels(ingress.Labels),
 			},
-			IngressClassName: ingress.Spec.IngressClassName,
+			IngressClassName: ingressClassName(ingress),
 			TLS:              ingress.Spec.TLS,
 			DefaultBackend:   ingress.Spec.DefaultBackend,
 			Rules:            ingress.Spec.Rules,
@@ -78,15 +82,34 @@ func (f *Fetcher) fetchIngresses() ([]*netv1.Ingress, error) {
 	return ingresses, nil
 }
 
+// annotationIngressClass is the deprecated annotation used to select the ingress controller of an Ingress.
+// It is also used by Traefik to select the instance serving an IngressRoute.
+const annotationIngressClass = "kubernetes.io/ingress.class"
+
+// ingressClassName returns the ingress class of the given Ingress, which allows to attribute it to the right
+// ingress controller when several ones are installed in the cluster.
+func ingressClassName(ingress *netv1.Ingress) *string {
+	if ingress.Spec.IngressClassName != nil {
+		return ingress.Spec.IngressClassName
+	}
+
+	return ingressClassAnnotation(ingress.Annotations)
+}
+
+func ingressClassAnnotation(annotations map[string]string) *string {
+	class, ok := annotations[annotationIngressClass]
+	if !ok || class == "" {
+		return nil
+	}
+
+	return &class
+}
+
 func getIngressServices(ingress *netv1.Ingress) []string {
 	var result []string
 
-	knownServices := make(map[string]struct{})
-
 	if ingress.Spec.DefaultBackend != nil && ingress.Spec.DefaultBackend.Service != nil {
-		key := objectKey(ingress.Spec.DefaultBackend.Service.Name, ingress.Namespace)
-		knownServices[key] = struct{}{}
-		result = append(result, key)
+		result = append(result, objectKey(ingress.Spec.DefaultBackend.Service.Name, ingress.Namespace))
 	}
 
 	for _, r := range ingress.Spec.Rules {
@@ -100,11 +123,10 @@ func getIngressServices(ingress *netv1.Ingress) []string {
 			}
 
 			key := objectKey(p.Backend.Service.Name, ingress.Namespace)
-			if _, exists := knownServices[key]; exists {
+			if containsString(result, key) {
 				continue
 			}
 
-			knownServices[key] = struct{}{}
 			result = append(result, key)
 		}
 	}
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package state
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Ingress controller types.
+const (
+	IngressControllerTypeHAProxy = "haproxy"
+	IngressControllerTypeIstio   = "istio"
+)
+
+// Annotations locating the Prometheus endpoint of a pod.
+const (
+	annotationPrometheusScheme = "prometheus.io/scheme"
+	annotationPrometheusPort   = "prometheus.io/port"
+	annotationPrometheusPath   = "prometheus.io/path"
+)
+
+// ingressControllerImage describes the image of a known ingress controller.
+type ingressControllerImage struct {
+	typ string
+	// arg is an argument the container must be started with, for images not only used by ingress controllers.
+	arg string
+	// metricsPort is the port serving the metrics when the pod doesn't have a prometheus.io/port annotation.
+	metricsPort int
+	// metricsPath is the path serving the metrics when the pod doesn't have a prometheus.io/path annotation.
+	metricsPath string
+}
+
+// ingressControllerImages are the known ingress controller images, by repository.
+var ingressControllerImages = map[string]ingressControllerImage{
+	"haproxytech/kubernetes-ingress": {typ: IngressControllerTypeHAProxy, metricsPort: 1024, metricsPath: "/metrics"},
+	"jcmoraisjr/haproxy-ingress":     {typ: IngressControllerTypeHAProxy, metricsPort: 9101, metricsPath: "/metrics"},
+	// The Istio proxy image runs both sidecars and gateways, the latter being started as routers.
+	"istio/proxyv2":         {typ: IngressControllerTypeIstio, arg: "router", metricsPort: 15090, metricsPath: "/stats/prometheus"},
+	"istio-release/proxyv2": {typ: IngressControllerTypeIstio, arg: "router", metricsPort: 15090, metricsPath: "/stats/prometheus"},
+}
+
+func (f *Fetcher) getIngressControllers() (map[string]*IngressController, error) {
+	pods, err := f.k8s.Core().V1().Pods().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*IngressController)
+	for _, pod := range pods {
+		if !f.namespaceFilter().Match(pod.Namespace) {
+			continue
+		}
+
+		image, ok := findIngressControllerImage(pod)
+		if !ok {
+			continue
+		}
+
+		name := workloadName(pod)
+		key := objectKey(name, pod.Namespace)
+
+		ctrl, ok := result[key]
+		if !ok {
+			ctrl = &IngressController{
+				Name:      name,
+				Namespace: pod.Namespace,
+				Type:      image.typ,
+			}
+			result[key] = ctrl
+		}
+
+		if metricsURL := podMetricsURL(pod, image); metricsURL != "" {
+			ctrl.MetricsURLs = append(ctrl.MetricsURLs, metricsURL)
+		}
+	}
+
+	for _, ctrl := range result {
+		sort.Strings(ctrl.MetricsURLs)
+	}
+
+	return result, nil
+}
+
+// isIngressControllerPod reports whether the given object is a pod of a known ingress controller.
+func isIngressControllerPod(obj interface{}) bool {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+
+	_, ok = findIngressControllerImage(pod)
+	return ok
+}
+
+func findIngressControllerImage(pod *corev1.Pod) (ingressControllerImage, bool) {
+	for _, container := range pod.Spec.Containers {
+		image, ok := ingressControllerImages[imageRepository(container.Image)]
+		if ok && (image.arg == "" || containsString(container.Args, image.arg)) {
+			return image, true
+		}
+	}
+
+	return ingressControllerImage{}, false
+}
+
+// imageRepository returns the repository of the given image, without its registry, tag or digest.
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+
+	// A colon after the last slash separates the tag, otherwise it separates the registry port.
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+
+	// The first component is a registry when it holds a dot or a port, or is localhost.
+	if registry, repository, ok := strings.Cut(image, "/"); ok &&
+		(strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		image = repository
+	}
+
+	return strings.TrimPrefix(image, "library/")
+}
+
+// workloadName returns the name of the workload managing the given pod, or the name of the pod when it's not
+// managed.
+func workloadName(pod *corev1.Pod) string {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller == nil || !*owner.Controller {
+			continue
+		}
+
+		// Pods of Deployments are managed through ReplicaSets named after the Deployment and the pod template hash.
+		if owner.Kind == "ReplicaSet" {
+			if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; hash != "" {
+				return strings.TrimSuffix(owner.Name, "-"+hash)
+			}
+		}
+
+		return owner.Name
+	}
+
+	return pod.Name
+}
+
+// podMetricsURL returns the URL of the Prometheus endpoint of the given pod, or an empty string if it's not running.
+func podMetricsURL(pod *corev1.Pod, image ingressControllerImage) string {
+	if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+		return ""
+	}
+
+	scheme := "http"
+	if s := pod.Annotations[annotationPrometheusScheme]; s != "" {
+		scheme = s
+	}
+
+	port := strconv.Itoa(image.metricsPort)
+	if p := pod.Annotations[annotationPrometheusPort]; p != "" {
+		port = p
+	}
+
+	path := image.metricsPath
+	if p := pod.Annotations[annotationPrometheusPath]; p != "" {
+		path = "/" + strings.TrimPrefix(p, "/")
+	}
+
+	return scheme + "://" + net.JoinHostPort(pod.Status.PodIP, port) + path
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFetcher_GetIngressControllers(t *testing.T) {
+	tests := []struct {
+		desc       string
+		fixture    string
+		namespaces NamespaceFilter
+		want       map[string]*IngressController
+	}{
+		{
+			desc:    "HAProxy",
+			fixture: "fixtures/ingress-controller/haproxy.yml",
+			want: map[string]*IngressController{
+				"haproxy-kubernetes-ingress@haproxy-controller": {
+					Name:      "haproxy-kubernetes-ingress",
+					Namespace: "haproxy-controller",
+					Type:      IngressControllerTypeHAProxy,
+					MetricsURLs: []string{
+						"http://10.42.0.12:1024/metrics",
+						"http://10.42.0.13:8080/stats",
+					},
+				},
+				"haproxy-ingress@ingress": {
+					Name:        "haproxy-ingress",
+					Namespace:   "ingress",
+					Type:        IngressControllerTypeHAProxy,
+					MetricsURLs: []string{"http://[fd00::12]:9101/metrics"},
+				},
+			},
+		},
+		{
+			desc:       "filtered namespaces",
+			fixture:    "fixtures/ingress-controller/haproxy.yml",
+			namespaces: NamespaceFilter{ExcludeNamespaces: []string{"haproxy-controller"}},
+			want: map[string]*IngressController{
+				"haproxy-ingress@ingress": {
+					Name:        "haproxy-ingress",
+					Namespace:   "ingress",
+					Type:        IngressControllerTypeHAProxy,
+					MetricsURLs: []string{"http://[fd00::12]:9101/metrics"},
+				},
+			},
+		},
+		{
+			desc:    "Istio ingress gateway, ignoring sidecars",
+			fixture: "fixtures/ingress-controller/istio.yml",
+			want: map[string]*IngressController{
+				"istio-ingressgateway@istio-system": {
+					Name:        "istio-ingressgateway",
+					Namespace:   "istio-system",
+					Type:        IngressControllerTypeIstio,
+					MetricsURLs: []string{"http://10.42.0.30:15090/stats/prometheus"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			objects := kube.LoadK8sObjects(t, test.fixture)
+
+			kubeClient := kubefake.NewSimpleClientset(objects...)
+			traefikClient := traefikcrdfake.NewSimpleClientset()
+			hubClient := hubfake.NewSimpleClientset()
+
+			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.23.0", test.namespaces)
+			require.NoError(t, err)
+
+			got, err := f.getIngressControllers()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "haproxytech/kubernetes-ingress", want: "haproxytech/kubernetes-ingress"},
+		{image: "haproxytech/kubernetes-ingress:1.10.1", want: "haproxytech/kubernetes-ingress"},
+		{image: "docker.io/haproxytech/kubernetes-ingress@sha256:0f2c", want: "haproxytech/kubernetes-ingress"},
+		{image: "registry.local:5000/jcmoraisjr/haproxy-ingress:v0.14", want: "jcmoraisjr/haproxy-ingress"},
+		{image: "localhost/jcmoraisjr/haproxy-ingress", want: "jcmoraisjr/haproxy-ingress"},
+		{image: "gcr.io/istio-release/proxyv2:1.18.2-distroless", want: "istio-release/proxyv2"},
+		{image: "docker.io/library/traefik:v2.10", want: "traefik"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.image, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, imageRepository(test.image))
+		})
+	}
+}
@@ -38,8 +38,12 @@ func (f *Fetcher) getIngressRoutes() (map[string]*IngressRoute, error) {
 		return nil, err
 	}
 
-	result := make(map[string]*IngressRoute)
+	result := make(map[string]*IngressRoute, len(ingressRoutes))
 	for _, ingressRoute := range ingressRoutes {
+		if !f.namespaceFilter().Match(ingressRoute.Namespace) {
+			continue
+		}
+
 		var routes []Route
 		for _, route := range ingressRoute.Spec.Routes {
 			services, err := f.getRouteServices(ingressRoute.Namespace, route)
@@ -75,12 +79,13 @@ func (f *Fetcher) getIngressRoutes() (map[string]*IngressRoute, error) {
 				Namespace: ingressRoute.Namespace,
 			},
 			IngressMeta: IngressMeta{
-				Annotations: sanitizeAnnotations(ingressRoute.Annotations),
-				Labels:      ingressRoute.Labels,
+				Annotations: f.redactor.annotations(ingressRoute.Annotations),
+				Labels:      f.redactor.labels(ingressRoute.Labels),
 			},
-			TLS:      tls,
-			Routes:   routes,
-			Services: getIngressRouteServices(routes),
+			IngressClassName: ingressClassAnnotation(ingressRoute.Annotations),
+			TLS:              tls,
+			Routes:           routes,
+			Services:         getIngressRouteServices(routes),
 		}
 
 		result[ingressKey(ing.ResourceMeta)] = ing
@@ -182,16 +187,13 @@ func toRouteService(parentNamespace string, service *traefikv1alpha1.LoadBalance
 func getIngressRouteServices(routes []Route) []string {
 	var result []string
 
-	knownServices := make(map[string]struct{})
-
 	for _, r := range routes {
 		for _, s := range r.Services {
 			key := objectKey(s.Name, s.Namespace)
-			if _, exists := knownServices[key]; exists {
+			if containsString(result, key) {
 				continue
 			}
 
-			knownServices[key] = struct{}{}
 			result = append(result, key)
 		}
 	}
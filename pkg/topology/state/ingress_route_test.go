@@ -31,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 )
 
 // Mandatory to be able to parse traefik.containo.us/v1alpha1 resources.
@@ -87,6 +88,37 @@ func TestFetcher_GetIngressRoutes(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:    "One service served by a specific Traefik instance",
+			fixture: "ingress-route-ingress-class.yml",
+			want: map[string]*IngressRoute{
+				"name@ns.ingressroute.traefik.containo.us": {
+					ResourceMeta: ResourceMeta{
+						Kind:      ResourceKindIngressRoute,
+						Group:     traefikv1alpha1.GroupName,
+						Name:      "name",
+						Namespace: "ns",
+					},
+					IngressMeta: IngressMeta{
+						Annotations: map[string]string{"kubernetes.io/ingress.class": "traefik-internal"},
+					},
+					IngressClassName: pointer.String("traefik-internal"),
+					Routes: []Route{
+						{
+							Match: "Host(`foo.com`)",
+							Services: []RouteService{
+								{
+									Name:       "service",
+									Namespace:  "ns",
+									PortNumber: 80,
+								},
+							},
+						},
+					},
+					Services: []string{"service@ns"},
+				},
+			},
+		},
 		{
 			desc:    "One service with an internal Traefik service",
 			fixture: "ingress-route-one-internal-traefik-service.yml",
@@ -284,7 +316,7 @@ func TestFetcher_GetIngressRoutes(t *testing.T) {
 			traefikClient := traefikcrdfake.NewSimpleClientset(objects...)
 			hubClient := hubfake.NewSimpleClientset()
 
-			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 			require.NoError(t, err)
 
 			got, err := f.getIngressRoutes()
@@ -88,7 +88,7 @@ func TestFetcher_GetIngresses(t *testing.T) {
 	traefikClient := traefikcrdfake.NewSimpleClientset()
 	hubClient := hubfake.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.getIngresses()
@@ -169,7 +169,7 @@ func TestFetcher_FetchIngresses(t *testing.T) {
 	traefikClient := traefikcrdfake.NewSimpleClientset()
 	hubClient := hubfake.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.18")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.18", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.fetchIngresses()
@@ -0,0 +1,63 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import "fmt"
+
+// NamespaceFilter selects the namespaces whose resources are part of the topology.
+// Cluster-scoped resources are never filtered out.
+type NamespaceFilter struct {
+	// Namespaces is the list of namespaces to collect resources from. All namespaces are collected when empty.
+	Namespaces []string
+	// ExcludeNamespaces is the list of namespaces to never collect resources from.
+	ExcludeNamespaces []string
+}
+
+// Validate makes sure the filter selects at least one namespace.
+func (nf NamespaceFilter) Validate() error {
+	if len(nf.Namespaces) == 0 {
+		return nil
+	}
+
+	for _, ns := range nf.Namespaces {
+		if !containsString(nf.ExcludeNamespaces, ns) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("all namespaces %q are excluded", nf.Namespaces)
+}
+
+// Match returns whether resources of the given namespace are part of the topology.
+func (nf NamespaceFilter) Match(namespace string) bool {
+	if containsString(nf.ExcludeNamespaces, namespace) {
+		return false
+	}
+
+	return len(nf.Namespaces) == 0 || containsString(nf.Namespaces, namespace)
+}
+
+// watchedNamespace returns the only namespace that needs to be watched, or metav1.NamespaceAll when resources
+// from several namespaces are collected, in which case they are filtered out when fetching the state.
+func (nf NamespaceFilter) watchedNamespace() string {
+	if len(nf.Namespaces) == 1 && nf.Match(nf.Namespaces[0]) {
+		return nf.Namespaces[0]
+	}
+
+	return ""
+}
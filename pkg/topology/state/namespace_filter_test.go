@@ -0,0 +1,224 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNamespaceFilter_Match(t *testing.T) {
+	tests := []struct {
+		desc      string
+		filter    NamespaceFilter
+		namespace string
+		want      bool
+	}{
+		{
+			desc:      "no filter",
+			namespace: "default",
+			want:      true,
+		},
+		{
+			desc:      "included namespace",
+			filter:    NamespaceFilter{Namespaces: []string{"app", "default"}},
+			namespace: "default",
+			want:      true,
+		},
+		{
+			desc:      "not included namespace",
+			filter:    NamespaceFilter{Namespaces: []string{"app"}},
+			namespace: "default",
+			want:      false,
+		},
+		{
+			desc:      "excluded namespace",
+			filter:    NamespaceFilter{ExcludeNamespaces: []string{"kube-system"}},
+			namespace: "kube-system",
+			want:      false,
+		},
+		{
+			desc: "included and excluded namespace",
+			filter: NamespaceFilter{
+				Namespaces:        []string{"app", "default"},
+				ExcludeNamespaces: []string{"default"},
+			},
+			namespace: "default",
+			want:      false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, test.filter.Match(test.namespace))
+		})
+	}
+}
+
+func TestNamespaceFilter_Validate(t *testing.T) {
+	tests := []struct {
+		desc    string
+		filter  NamespaceFilter
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			desc:    "no filter",
+			wantErr: assert.NoError,
+		},
+		{
+			desc:    "only excluded namespaces",
+			filter:  NamespaceFilter{ExcludeNamespaces: []string{"kube-system"}},
+			wantErr: assert.NoError,
+		},
+		{
+			desc: "some included namespaces are not excluded",
+			filter: NamespaceFilter{
+				Namespaces:        []string{"app", "default"},
+				ExcludeNamespaces: []string{"default"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			desc: "all included namespaces are excluded",
+			filter: NamespaceFilter{
+				Namespaces:        []string{"default"},
+				ExcludeNamespaces: []string{"default"},
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.wantErr(t, test.filter.Validate())
+		})
+	}
+}
+
+func TestFetcher_FetchState_filtersNamespaces(t *testing.T) {
+	tests := []struct {
+		desc           string
+		filter         NamespaceFilter
+		wantNamespaces []string
+	}{
+		{
+			desc:           "no filter",
+			wantNamespaces: []string{"app", "other", "secret"},
+		},
+		{
+			desc:           "single included namespace",
+			filter:         NamespaceFilter{Namespaces: []string{"app"}},
+			wantNamespaces: []string{"app"},
+		},
+		{
+			desc:           "several included namespaces",
+			filter:         NamespaceFilter{Namespaces: []string{"app", "other"}},
+			wantNamespaces: []string{"app", "other"},
+		},
+		{
+			desc:           "excluded namespace",
+			filter:         NamespaceFilter{ExcludeNamespaces: []string{"secret"}},
+			wantNamespaces: []string{"app", "other"},
+		},
+		{
+			desc: "included and excluded namespaces",
+			filter: NamespaceFilter{
+				Namespaces:        []string{"app", "secret"},
+				ExcludeNamespaces: []string{"secret"},
+			},
+			wantNamespaces: []string{"app"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var k8sObjects, hubObjects []runtime.Object
+			for _, ns := range []string{"app", "other", "secret"} {
+				k8sObjects = append(k8sObjects,
+					&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: ns}},
+					&netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: ns}},
+				)
+				hubObjects = append(hubObjects,
+					&hubv1alpha1.EdgeIngress{ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: ns}},
+					&hubv1alpha1.API{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: ns}},
+				)
+			}
+			hubObjects = append(hubObjects, &hubv1alpha1.AccessControlPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "acp"},
+				Spec: hubv1alpha1.AccessControlPolicySpec{
+					BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{},
+				},
+			})
+
+			kubeClient := kubefake.NewSimpleClientset(k8sObjects...)
+			traefikClient := traefikcrdfake.NewSimpleClientset()
+			hubClient := hubfake.NewSimpleClientset(hubObjects...)
+
+			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", test.filter)
+			require.NoError(t, err)
+
+			got, err := f.FetchState()
+			require.NoError(t, err)
+
+			var wantServices, wantIngresses, wantEdgeIngresses, wantAPIs []string
+			for _, ns := range test.wantNamespaces {
+				wantServices = append(wantServices, objectKey("svc", ns))
+				wantIngresses = append(wantIngresses, "ing@"+ns+".ingress.networking.k8s.io")
+				wantEdgeIngresses = append(wantEdgeIngresses, objectKey("edge", ns))
+				wantAPIs = append(wantAPIs, objectKey("api", ns))
+			}
+
+			assert.ElementsMatch(t, wantServices, keys(got.Services))
+			assert.ElementsMatch(t, wantIngresses, keys(got.Ingresses))
+			assert.ElementsMatch(t, wantEdgeIngresses, keys(got.EdgeIngresses))
+			assert.ElementsMatch(t, wantAPIs, keys(got.APIs))
+
+			// Cluster-scoped resources are never filtered out.
+			assert.Contains(t, got.AccessControlPolicies, "acp")
+		})
+	}
+}
+
+func keys[T any](m map[string]T) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+
+	return result
+}
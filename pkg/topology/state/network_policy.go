@@ -0,0 +1,166 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func (f *Fetcher) getNetworkPolicies() (map[string]*NetworkPolicy, error) {
+	policies, err := f.k8s.Networking().V1().NetworkPolicies().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*NetworkPolicy, len(policies))
+	for _, policy := range policies {
+		if !f.namespaceFilter().Match(policy.Namespace) {
+			continue
+		}
+
+		netPol, err := toNetworkPolicy(policy)
+		if err != nil {
+			return nil, fmt.Errorf("normalize NetworkPolicy %s/%s: %w", policy.Namespace, policy.Name, err)
+		}
+
+		result[objectKey(policy.Name, policy.Namespace)] = netPol
+	}
+
+	return result, nil
+}
+
+func toNetworkPolicy(policy *netv1.NetworkPolicy) (*NetworkPolicy, error) {
+	podSelector, err := selectorString(&policy.Spec.PodSelector)
+	if err != nil {
+		return nil, fmt.Errorf("pod selector: %w", err)
+	}
+
+	result := &NetworkPolicy{
+		Name:        policy.Name,
+		Namespace:   policy.Namespace,
+		PodSelector: podSelector,
+		PolicyTypes: policyTypes(policy.Spec),
+	}
+
+	for _, rule := range policy.Spec.Ingress {
+		r, err := toNetworkPolicyRule(policy.Namespace, rule.Ports, rule.From)
+		if err != nil {
+			return nil, fmt.Errorf("ingress rule: %w", err)
+		}
+
+		result.Ingress = append(result.Ingress, r)
+	}
+
+	for _, rule := range policy.Spec.Egress {
+		r, err := toNetworkPolicyRule(policy.Namespace, rule.Ports, rule.To)
+		if err != nil {
+			return nil, fmt.Errorf("egress rule: %w", err)
+		}
+
+		result.Egress = append(result.Egress, r)
+	}
+
+	return result, nil
+}
+
+// policyTypes returns the policy types of a NetworkPolicy. When they are not specified, policies always apply to
+// the ingress traffic, and to the egress traffic only if they have egress rules.
+func policyTypes(spec netv1.NetworkPolicySpec) []string {
+	var types []string
+	if len(spec.PolicyTypes) == 0 {
+		types = append(types, string(netv1.PolicyTypeIngress))
+		if len(spec.Egress) > 0 {
+			types = append(types, string(netv1.PolicyTypeEgress))
+		}
+
+		return types
+	}
+
+	for _, typ := range spec.PolicyTypes {
+		types = append(types, string(typ))
+	}
+
+	return types
+}
+
+func toNetworkPolicyRule(namespace string, ports []netv1.NetworkPolicyPort, peers []netv1.NetworkPolicyPeer) (NetworkPolicyRule, error) {
+	var rule NetworkPolicyRule
+	for _, port := range ports {
+		p := NetworkPolicyPort{
+			Protocol: string(corev1.ProtocolTCP),
+			EndPort:  port.EndPort,
+		}
+		if port.Protocol != nil {
+			p.Protocol = string(*port.Protocol)
+		}
+		if port.Port != nil {
+			p.Port = port.Port.String()
+		}
+
+		rule.Ports = append(rule.Ports, p)
+	}
+
+	for _, peer := range peers {
+		if peer.IPBlock != nil {
+			rule.Peers = append(rule.Peers, NetworkPolicyPeer{
+				IPBlock: &NetworkPolicyIPBlock{
+					CIDR:   peer.IPBlock.CIDR,
+					Except: peer.IPBlock.Except,
+				},
+			})
+			continue
+		}
+
+		var p NetworkPolicyPeer
+		if peer.NamespaceSelector == nil {
+			p.Namespace = namespace
+		} else {
+			nsSelector, err := selectorString(peer.NamespaceSelector)
+			if err != nil {
+				return NetworkPolicyRule{}, fmt.Errorf("namespace selector: %w", err)
+			}
+			p.NamespaceSelector = nsSelector
+		}
+
+		if peer.PodSelector != nil {
+			podSelector, err := selectorString(peer.PodSelector)
+			if err != nil {
+				return NetworkPolicyRule{}, fmt.Errorf("pod selector: %w", err)
+			}
+			p.PodSelector = podSelector
+		}
+
+		rule.Peers = append(rule.Peers, p)
+	}
+
+	return rule, nil
+}
+
+func selectorString(selector *metav1.LabelSelector) (string, error) {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", err
+	}
+
+	return s.String(), nil
+}
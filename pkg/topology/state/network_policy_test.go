@@ -0,0 +1,126 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestFetcher_GetNetworkPolicies(t *testing.T) {
+	tests := []struct {
+		desc       string
+		namespaces NamespaceFilter
+		want       map[string]*NetworkPolicy
+	}{
+		{
+			desc: "all namespaces",
+			want: map[string]*NetworkPolicy{
+				"allow-traefik@my-ns": {
+					Name:        "allow-traefik",
+					Namespace:   "my-ns",
+					PodSelector: "app=whoami",
+					PolicyTypes: []string{"Ingress"},
+					Ingress: []NetworkPolicyRule{
+						{
+							Ports: []NetworkPolicyPort{
+								{Protocol: "TCP", Port: "80"},
+								{Protocol: "UDP", Port: "dns"},
+							},
+							Peers: []NetworkPolicyPeer{
+								{
+									NamespaceSelector: "kubernetes.io/metadata.name=traefik",
+									PodSelector:       "app.kubernetes.io/name=traefik",
+								},
+								{
+									Namespace:   "my-ns",
+									PodSelector: "tier in (frontend)",
+								},
+							},
+						},
+					},
+				},
+				"restrict-egress@my-ns": {
+					Name:        "restrict-egress",
+					Namespace:   "my-ns",
+					PolicyTypes: []string{"Egress"},
+					Egress: []NetworkPolicyRule{
+						{
+							Ports: []NetworkPolicyPort{
+								{Protocol: "TCP", Port: "32000", EndPort: pointer.Int32(32768)},
+							},
+							Peers: []NetworkPolicyPeer{
+								{
+									IPBlock: &NetworkPolicyIPBlock{
+										CIDR:   "10.0.0.0/8",
+										Except: []string{"10.1.0.0/16"},
+									},
+								},
+							},
+						},
+					},
+				},
+				"default-deny@other-ns": {
+					Name:        "default-deny",
+					Namespace:   "other-ns",
+					PolicyTypes: []string{"Ingress"},
+				},
+			},
+		},
+		{
+			desc:       "filtered namespaces",
+			namespaces: NamespaceFilter{ExcludeNamespaces: []string{"my-ns"}},
+			want: map[string]*NetworkPolicy{
+				"default-deny@other-ns": {
+					Name:        "default-deny",
+					Namespace:   "other-ns",
+					PolicyTypes: []string{"Ingress"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			objects := kube.LoadK8sObjects(t, "fixtures/network-policy/network-policies.yml")
+
+			kubeClient := kubefake.NewSimpleClientset(objects...)
+			traefikClient := traefikcrdfake.NewSimpleClientset()
+			hubClient := hubfake.NewSimpleClientset()
+
+			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.25.0", test.namespaces)
+			require.NoError(t, err)
+
+			got, err := f.getNetworkPolicies()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RedactedValue replaces the values of redacted annotations and labels.
+const RedactedValue = "REDACTED"
+
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// RedactionFilter selects the annotations and labels whose values must never leave the cluster.
+type RedactionFilter struct {
+	// Keys is the list of annotation and label keys to redact.
+	Keys []string
+	// Patterns is the list of regular expressions matched against annotation and label keys to redact.
+	Patterns []string
+}
+
+type redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+}
+
+func newRedactor(filter RedactionFilter) (redactor, error) {
+	r := redactor{keys: filter.Keys}
+
+	for _, pattern := range filter.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return redactor{}, fmt.Errorf("compile redaction pattern %q: %w", pattern, err)
+		}
+
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// annotations returns the given annotations with the redacted values replaced. The last applied configuration
+// annotation is always removed: it holds a copy of the whole resource, including its redacted annotations.
+func (r redactor) annotations(annotations map[string]string) map[string]string {
+	return r.redact(annotations, lastAppliedConfigAnnotation)
+}
+
+// labels returns the given labels with the redacted values replaced.
+func (r redactor) labels(labels map[string]string) map[string]string {
+	return r.redact(labels, "")
+}
+
+func (r redactor) redact(values map[string]string, remove string) map[string]string {
+	if !r.needsRedaction(values, remove) {
+		// Annotations and labels are never mutated, there's no need to copy them when there is nothing to redact.
+		return values
+	}
+
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		switch {
+		case key == remove:
+			continue
+		case r.match(key):
+			result[key] = RedactedValue
+		default:
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+func (r redactor) needsRedaction(values map[string]string, remove string) bool {
+	if _, ok := values[remove]; ok && remove != "" {
+		return true
+	}
+
+	if len(r.keys) == 0 && len(r.patterns) == 0 {
+		return false
+	}
+
+	for key := range values {
+		if r.match(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r redactor) match(key string) bool {
+	if containsString(r.keys, key) {
+		return true
+	}
+
+	for _, re := range r.patterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor_annotations(t *testing.T) {
+	tests := []struct {
+		desc        string
+		filter      RedactionFilter
+		annotations map[string]string
+		want        map[string]string
+	}{
+		{
+			desc:        "no filter",
+			annotations: map[string]string{"key": "value"},
+			want:        map[string]string{"key": "value"},
+		},
+		{
+			desc: "last applied configuration is always removed",
+			annotations: map[string]string{
+				"key":                       "value",
+				lastAppliedConfigAnnotation: `{"secret":"value"}`,
+			},
+			want: map[string]string{"key": "value"},
+		},
+		{
+			desc:   "redact by key",
+			filter: RedactionFilter{Keys: []string{"secret"}},
+			annotations: map[string]string{
+				"key":    "value",
+				"secret": "password",
+			},
+			want: map[string]string{
+				"key":    "value",
+				"secret": RedactedValue,
+			},
+		},
+		{
+			desc:   "redact by pattern",
+			filter: RedactionFilter{Patterns: []string{`^vault\.hashicorp\.com/`, `token$`}},
+			annotations: map[string]string{
+				"key":                                "value",
+				"vault.hashicorp.com/agent-inject":   "true",
+				"example.com/api-token":              "xxx",
+				"example.com/token-rotation-enabled": "true",
+			},
+			want: map[string]string{
+				"key":                                "value",
+				"vault.hashicorp.com/agent-inject":   RedactedValue,
+				"example.com/api-token":              RedactedValue,
+				"example.com/token-rotation-enabled": "true",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newRedactor(test.filter)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, r.annotations(test.annotations))
+		})
+	}
+}
+
+func TestRedactor_labels(t *testing.T) {
+	r, err := newRedactor(RedactionFilter{Keys: []string{"owner", lastAppliedConfigAnnotation}})
+	require.NoError(t, err)
+
+	got := r.labels(map[string]string{"app": "whoami", "owner": "john.doe@example.com"})
+	assert.Equal(t, map[string]string{"app": "whoami", "owner": RedactedValue}, got)
+
+	assert.Nil(t, r.labels(nil))
+}
+
+func TestNewRedactor_invalidPattern(t *testing.T) {
+	_, err := newRedactor(RedactionFilter{Patterns: []string{"("}})
+	assert.Error(t, err)
+}
@@ -25,8 +25,11 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -36,9 +39,16 @@ func (f *Fetcher) getServices() (map[string]*Service, error) {
 		return nil, err
 	}
 
-	svcs := make(map[string]*Service)
+	svcs := make(map[string]*Service, len(services))
 	for _, service := range services {
+		if !f.namespaceFilter().Match(service.Namespace) {
+			continue
+		}
+
 		var externalPorts []int
+		if len(service.Spec.Ports) > 0 {
+			externalPorts = make([]int, 0, len(service.Spec.Ports))
+		}
 
 		// for BC reason we keep externalPorts.
 		for _, port := range service.Spec.Ports {
@@ -64,7 +74,7 @@ func (f *Fetcher) getServices() (map[string]*Service, error) {
 		svcs[svcName] = &Service{
 			Name:          service.Name,
 			Namespace:     service.Namespace,
-			Annotations:   sanitizeAnnotations(service.Annotations),
+			Annotations:   f.redactor.annotations(service.Annotations),
 			Type:          service.Spec.Type,
 			ExternalIPs:   externalIPs,
 			ExternalPorts: externalPorts,
@@ -124,6 +134,120 @@ func (f *Fetcher) GetServiceLogs(ctx context.Context, namespace, name string, li
 	return buf.Bytes(), nil
 }
 
+// GetServiceEvents returns the warning events of the pods selected by a service that occurred since the given time,
+// such as failed probes or back-offs, along with their OOM-killed and crash-looping containers. They are returned one
+// per line, from the oldest to the newest, up to the given maximum.
+func (f *Fetcher) GetServiceEvents(ctx context.Context, namespace, name string, since time.Time, maxEvents int) ([]byte, error) {
+	service, err := f.k8s.Core().V1().Services().Lister().Services(namespace).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service %s/%s: %w", name, namespace, err)
+	}
+
+	pods, err := f.k8s.Core().V1().Pods().Lister().Pods(namespace).List(labels.SelectorFromSet(service.Spec.Selector))
+	if err != nil {
+		return nil, fmt.Errorf("list pods for %s/%s: %w", namespace, name, err)
+	}
+
+	if len(pods) == 0 {
+		return nil, nil
+	}
+
+	podNames := make(map[string]struct{}, len(pods))
+	for _, pod := range pods {
+		podNames[pod.Name] = struct{}{}
+	}
+
+	// Events are not watched by the fetcher, as they are numerous and only needed when an alert is raised.
+	events, err := f.clientSet.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			"involvedObject.kind": "Pod",
+			"type":                corev1.EventTypeWarning,
+		}.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list events for %s/%s: %w", namespace, name, err)
+	}
+
+	var entries []serviceEvent
+	for _, event := range events.Items {
+		if _, ok := podNames[event.InvolvedObject.Name]; !ok || event.Type != corev1.EventTypeWarning {
+			continue
+		}
+
+		at := event.LastTimestamp.Time
+		if at.IsZero() {
+			at = event.EventTime.Time
+		}
+		if at.Before(since) {
+			continue
+		}
+
+		msg := fmt.Sprintf("%s pod/%s: %s", event.Reason, event.InvolvedObject.Name, event.Message)
+		if event.Count > 1 {
+			msg += fmt.Sprintf(" (x%d)", event.Count)
+		}
+
+		entries = append(entries, serviceEvent{at: at, msg: msg})
+	}
+
+	for _, pod := range pods {
+		entries = append(entries, containerEvents(pod, since)...)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].at.Before(entries[j].at)
+	})
+
+	if len(entries) > maxEvents {
+		entries = entries[len(entries)-maxEvents:]
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		buf.WriteString(entry.at.UTC().Format(time.RFC3339) + " " + entry.msg + "\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+type serviceEvent struct {
+	at  time.Time
+	msg string
+}
+
+// containerEvents returns the OOM kills of the containers of the given pod which occurred since the given time, and
+// the containers currently in a crash loop, which Kubernetes doesn't report as events.
+func containerEvents(pod *corev1.Pod, since time.Time) []serviceEvent {
+	var entries []serviceEvent
+	for _, status := range pod.Status.ContainerStatuses {
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.Reason == "OOMKilled" &&
+			!terminated.FinishedAt.Time.Before(since) {
+			entries = append(entries, serviceEvent{
+				at:  terminated.FinishedAt.Time,
+				msg: fmt.Sprintf("OOMKilled pod/%s: container %q exited with code %d", pod.Name, status.Name, terminated.ExitCode),
+			})
+		}
+
+		if waiting := status.State.Waiting; waiting != nil && waiting.Reason == "CrashLoopBackOff" {
+			at := since
+			if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.FinishedAt.Time.After(since) {
+				at = terminated.FinishedAt.Time
+			}
+
+			entries = append(entries, serviceEvent{
+				at:  at,
+				msg: fmt.Sprintf("CrashLoopBackOff pod/%s: container %q restarted %d times: %s", pod.Name, status.Name, status.RestartCount, waiting.Message),
+			})
+		}
+	}
+
+	return entries
+}
+
 func writeBytes(buf *bytes.Buffer, b []byte, maxLen int) {
 	switch {
 	case len(b) == 0:
@@ -20,6 +20,7 @@ package state
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -90,7 +91,7 @@ func TestFetcher_GetServices(t *testing.T) {
 	traefikClient := traefikcrdfake.NewSimpleClientset()
 	hubClient := hubfake.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	gotSvcs, err := f.getServices()
@@ -160,7 +161,7 @@ func TestFetcher_GetServicesWithExternalIPs(t *testing.T) {
 	traefikClient := traefikcrdfake.NewSimpleClientset()
 	hubClient := hubfake.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	gotSvcs, err := f.getServices()
@@ -325,7 +326,7 @@ func TestFetcher_GetServicesWithOpenAPISpecs(t *testing.T) {
 			traefikClient := traefikcrdfake.NewSimpleClientset()
 			hubClient := hubfake.NewSimpleClientset()
 
-			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 			require.NoError(t, err)
 
 			gotSvcs, err := f.getServices()
@@ -403,7 +404,7 @@ func TestFetcher_GetServiceLogs(t *testing.T) {
 	traefikClient := traefikcrdfake.NewSimpleClientset()
 	hubClient := hubfake.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.GetServiceLogs(context.Background(), "myns", "myService", 20, 200)
@@ -488,7 +489,7 @@ func TestFetcher_GetServiceLogsHandlesTooManyPods(t *testing.T) {
 	traefikClient := traefikcrdfake.NewSimpleClientset()
 	hubClient := hubfake.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
 	require.NoError(t, err)
 
 	got, err := f.GetServiceLogs(context.Background(), "myns", "myService", 2, 200)
@@ -496,3 +497,123 @@ func TestFetcher_GetServiceLogsHandlesTooManyPods(t *testing.T) {
 
 	assert.Equal(t, []byte("fake logs\nfake logs\n"), got)
 }
+
+func TestFetcher_GetServiceEvents(t *testing.T) {
+	since := time.Date(2021, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	objects := []runtime.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myService",
+				Namespace: "myns",
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{
+					"my.label": "foo",
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "myns",
+				Labels: map[string]string{
+					"my.label": "foo",
+				},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:         "app",
+						RestartCount: 3,
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{
+								Reason:  "CrashLoopBackOff",
+								Message: "back-off 40s",
+							},
+						},
+						LastTerminationState: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{
+								Reason:     "OOMKilled",
+								ExitCode:   137,
+								FinishedAt: metav1.NewTime(since.Add(10 * time.Minute)),
+							},
+						},
+					},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "other",
+				Namespace: "myns",
+			},
+		},
+		&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1.1",
+				Namespace: "myns",
+			},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pod1", Namespace: "myns"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Unhealthy",
+			Message:        "Readiness probe failed",
+			Count:          4,
+			LastTimestamp:  metav1.NewTime(since.Add(5 * time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1.2",
+				Namespace: "myns",
+			},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pod1", Namespace: "myns"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Unhealthy",
+			Message:        "Liveness probe failed",
+			Count:          1,
+			LastTimestamp:  metav1.NewTime(since.Add(-5 * time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1.3",
+				Namespace: "myns",
+			},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pod1", Namespace: "myns"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Pulled",
+			Message:        "Container image pulled",
+			LastTimestamp:  metav1.NewTime(since.Add(time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "other.1",
+				Namespace: "myns",
+			},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "other", Namespace: "myns"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off pulling image",
+			LastTimestamp:  metav1.NewTime(since.Add(time.Minute)),
+		},
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(objects...)
+	traefikClient := traefikcrdfake.NewSimpleClientset()
+	hubClient := hubfake.NewSimpleClientset()
+
+	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1", NamespaceFilter{})
+	require.NoError(t, err)
+
+	got, err := f.GetServiceEvents(context.Background(), "myns", "myService", since, 10)
+	require.NoError(t, err)
+
+	want := "2021-01-01T08:05:00Z Unhealthy pod/pod1: Readiness probe failed (x4)\n" +
+		"2021-01-01T08:10:00Z OOMKilled pod/pod1: container \"app\" exited with code 137\n" +
+		"2021-01-01T08:10:00Z CrashLoopBackOff pod/pod1: container \"app\" restarted 3 times: back-off 40s\n"
+	assert.Equal(t, want, string(got))
+
+	got, err = f.GetServiceEvents(context.Background(), "myns", "myService", since, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2021-01-01T08:10:00Z CrashLoopBackOff pod/pod1: container \"app\" restarted 3 times: back-off 40s\n", string(got))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// noisyFields are the resource fields dropped from the topology when a single resource doesn't fit in a patch.
+var noisyFields = []string{"annotations"}
+
+// chunkOverhead is an upper bound of the bytes needed to nest a resource in its section within a patch:
+// `"section":{"id":value},`, without the section, ID and value themselves.
+const chunkOverhead = 9
+
+type patchUnit struct {
+	section string
+	// id is the resource ID within the section, empty when the whole section is patched at once.
+	id    string
+	value json.RawMessage
+}
+
+func (u patchUnit) size() int {
+	return len(u.section) + len(u.id) + len(u.value) + chunkOverhead
+}
+
+// splitPatch splits a topology merge patch into sequential patches of at most maxSize bytes, each one patching
+// a subset of the resources. It reports whether some resources don't fit in a patch on their own, in which case
+// they are sent in a dedicated, oversized, patch.
+func splitPatch(patch []byte, maxSize int) ([][]byte, bool, error) {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &sections); err != nil {
+		return nil, false, fmt.Errorf("unmarshal patch: %w", err)
+	}
+
+	var units []patchUnit
+	for section, value := range sections {
+		var resources map[string]json.RawMessage
+		if err := json.Unmarshal(value, &resources); err != nil || resources == nil {
+			// The section is not an object of resources, e.g. it is removed: it must be patched at once.
+			units = append(units, patchUnit{section: section, value: value})
+			continue
+		}
+
+		for id, resource := range resources {
+			units = append(units, patchUnit{section: section, id: id, value: resource})
+		}
+	}
+
+	sort.Slice(units, func(i, j int) bool {
+		if units[i].section != units[j].section {
+			return units[i].section < units[j].section
+		}
+		return units[i].id < units[j].id
+	})
+
+	var (
+		chunks    [][]byte
+		oversized bool
+		current   = map[string]interface{}{}
+		size      = len("{}")
+	)
+
+	flush := func() error {
+		if len(current) == 0 {
+			return nil
+		}
+
+		chunk, err := marshal(current)
+		if err != nil {
+			return err
+		}
+
+		chunks = append(chunks, chunk)
+		current = map[string]interface{}{}
+		size = len("{}")
+
+		return nil
+	}
+
+	for _, unit := range units {
+		if unit.size()+len("{}") > maxSize {
+			oversized = true
+		}
+
+		if size+unit.size() > maxSize {
+			if err := flush(); err != nil {
+				return nil, false, err
+			}
+		}
+
+		size += unit.size()
+
+		if unit.id == "" {
+			current[unit.section] = unit.value
+			continue
+		}
+
+		resources, ok := current[unit.section].(map[string]json.RawMessage)
+		if !ok {
+			resources = map[string]json.RawMessage{}
+			current[unit.section] = resources
+		}
+		resources[unit.id] = unit.value
+	}
+
+	if err := flush(); err != nil {
+		return nil, false, err
+	}
+
+	return chunks, oversized, nil
+}
+
+// truncateNoisyFields drops the noisy fields of the resources of the given topology which don't fit in a patch
+// of maxSize bytes on their own.
+func truncateNoisyFields(topology []byte, maxSize int) ([]byte, error) {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(topology, &sections); err != nil {
+		return nil, fmt.Errorf("unmarshal topology: %w", err)
+	}
+
+	for section, value := range sections {
+		var resources map[string]json.RawMessage
+		if err := json.Unmarshal(value, &resources); err != nil || resources == nil {
+			continue
+		}
+
+		var truncated bool
+		for id, resource := range resources {
+			if (patchUnit{section: section, id: id, value: resource}).size()+len("{}") <= maxSize {
+				continue
+			}
+
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(resource, &fields); err != nil {
+				continue
+			}
+
+			for _, field := range noisyFields {
+				delete(fields, field)
+			}
+
+			var err error
+			resources[id], err = marshal(fields)
+			if err != nil {
+				return nil, err
+			}
+			truncated = true
+		}
+
+		if !truncated {
+			continue
+		}
+
+		var err error
+		sections[section], err = marshal(resources)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return marshal(sections)
+}
+
+// marshal encodes the given value without escaping HTML characters, which would make chunks larger than expected.
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPatch(t *testing.T) {
+	tests := []struct {
+		desc          string
+		patch         string
+		maxSize       int
+		want          []string
+		wantOversized bool
+	}{
+		{
+			desc:    "fits in a single patch",
+			patch:   `{"ingresses":{"ing@ns":{"name":"ing"}},"services":{"svc@ns":{"name":"svc"}}}`,
+			maxSize: 1024,
+			want:    []string{`{"ingresses":{"ing@ns":{"name":"ing"}},"services":{"svc@ns":{"name":"svc"}}}`},
+		},
+		{
+			desc:    "split by resource",
+			patch:   `{"ingresses":{"ing@ns":{"name":"ing"}},"services":{"svc-1@ns":{"name":"svc-1"},"svc-2@ns":null}}`,
+			maxSize: 50,
+			want: []string{
+				`{"ingresses":{"ing@ns":{"name":"ing"}}}`,
+				`{"services":{"svc-1@ns":{"name":"svc-1"}}}`,
+				`{"services":{"svc-2@ns":null}}`,
+			},
+		},
+		{
+			desc:    "removed sections are patched at once",
+			patch:   `{"ingresses":null,"services":{"svc@ns":{"name":"svc"}}}`,
+			maxSize: 40,
+			want: []string{
+				`{"ingresses":null}`,
+				`{"services":{"svc@ns":{"name":"svc"}}}`,
+			},
+		},
+		{
+			desc:    "oversized resource",
+			patch:   `{"services":{"svc-1@ns":{"annotations":{"key":"a-very-long-value"}},"svc-2@ns":{"name":"svc-2"}}}`,
+			maxSize: 45,
+			want: []string{
+				`{"services":{"svc-1@ns":{"annotations":{"key":"a-very-long-value"}}}}`,
+				`{"services":{"svc-2@ns":{"name":"svc-2"}}}`,
+			},
+			wantOversized: true,
+		},
+		{
+			desc:    "HTML characters are not escaped",
+			patch:   `{"services":{"svc@ns":{"annotations":{"key":"<&>"}}}}`,
+			maxSize: 1024,
+			want:    []string{`{"services":{"svc@ns":{"annotations":{"key":"<&>"}}}}`},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, oversized, err := splitPatch([]byte(test.patch), test.maxSize)
+			require.NoError(t, err)
+
+			var gotPatches []string
+			for _, patch := range got {
+				gotPatches = append(gotPatches, string(patch))
+				if !test.wantOversized {
+					assert.LessOrEqual(t, len(patch), test.maxSize)
+				}
+			}
+
+			assert.Equal(t, test.want, gotPatches)
+			assert.Equal(t, test.wantOversized, oversized)
+		})
+	}
+}
+
+func TestTruncateNoisyFields(t *testing.T) {
+	topology := `{"services":{"big@ns":{"annotations":{"key":"a-very-long-value"},"name":"big"},"small@ns":{"annotations":{"k":"v"},"name":"small"}},"ingresses":null}`
+
+	got, err := truncateNoisyFields([]byte(topology), 70)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"services":{"big@ns":{"name":"big"},"small@ns":{"annotations":{"k":"v"},"name":"small"}},"ingresses":null}`, string(got))
+}
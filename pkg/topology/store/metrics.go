@@ -0,0 +1,85 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package store
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors reporting the topology patches sent to the platform.
+type Metrics struct {
+	patchSize   prometheus.Histogram
+	patches     prometheus.Counter
+	truncations prometheus.Counter
+}
+
+// NewMetrics creates the topology store collectors and registers them in the given registerer.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		patchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "hub_agent",
+			Subsystem: "topology",
+			Name:      "patch_size_bytes",
+			Help:      "Size of the topology patches sent to the platform, after chunking.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+		}),
+		patches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "topology",
+			Name:      "patches_total",
+			Help:      "Number of topology patches sent to the platform, after chunking.",
+		}),
+		truncations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "topology",
+			Name:      "truncations_total",
+			Help:      "Number of topology writes in which noisy fields were truncated to fit the maximum patch size.",
+		}),
+	}
+
+	if err := reg.Register(m.patchSize); err != nil {
+		return nil, fmt.Errorf("register patch size histogram: %w", err)
+	}
+	if err := reg.Register(m.patches); err != nil {
+		return nil, fmt.Errorf("register patches counter: %w", err)
+	}
+	if err := reg.Register(m.truncations); err != nil {
+		return nil, fmt.Errorf("register truncations counter: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) patchSent(size int) {
+	if m == nil {
+		return
+	}
+
+	m.patches.Inc()
+	m.patchSize.Observe(float64(size))
+}
+
+func (m *Metrics) truncated() {
+	if m == nil {
+		return
+	}
+
+	m.truncations.Inc()
+}
@@ -37,20 +37,34 @@ type PlatformClient interface {
 	PatchTopology(ctx context.Context, patch []byte, lastKnownVersion int64) (int64, error)
 }
 
+// Config configures the Store.
+type Config struct {
+	// MaxPatchSize is the maximum size in bytes of a patch sent to the platform. Larger patches are split into
+	// sequential patches, and noisy fields of the resources which don't fit in a patch on their own are truncated.
+	// No limit is enforced when zero.
+	MaxPatchSize int
+	// Metrics reports the patches sent to the platform. It is optional.
+	Metrics *Metrics
+}
+
 // Store stores the topology on the platform.
 type Store struct {
 	platform      PlatformClient
 	maxPatchRetry int
+	maxPatchSize  int
+	metrics       *Metrics
 
 	lastTopology     []byte
 	lastKnownVersion int64
 }
 
 // New instantiates a new Store.
-func New(platformClient PlatformClient) *Store {
+func New(platformClient PlatformClient, cfg Config) *Store {
 	return &Store{
 		platform:      platformClient,
 		maxPatchRetry: 5,
+		maxPatchSize:  cfg.MaxPatchSize,
+		metrics:       cfg.Metrics,
 	}
 }
 
@@ -73,15 +87,15 @@ func (s *Store) Write(ctx context.Context, st state.Cluster) error {
 			s.lastKnownVersion = version
 		}
 
-		patch, newTopology, err := s.buildPatch(s.lastTopology, st)
+		patches, newTopology, err := s.buildPatches(s.lastTopology, st)
 		if err != nil {
 			return fmt.Errorf("build topology patch: %w", err)
 		}
-		if patch == nil {
+		if len(patches) == 0 {
 			return nil
 		}
 
-		s.lastKnownVersion, err = s.platform.PatchTopology(ctx, patch, s.lastKnownVersion)
+		err = s.patch(ctx, patches)
 		if err == nil {
 			s.lastTopology = newTopology
 			return nil
@@ -101,6 +115,69 @@ func (s *Store) Write(ctx context.Context, st state.Cluster) error {
 	}
 }
 
+// patch sends the given patches sequentially. On failure, the last known version is reset, which makes the next
+// write start over from the topology stored on the platform.
+func (s *Store) patch(ctx context.Context, patches [][]byte) error {
+	for _, patch := range patches {
+		var err error
+		s.lastKnownVersion, err = s.platform.PatchTopology(ctx, patch, s.lastKnownVersion)
+		if err != nil {
+			return err
+		}
+
+		s.metrics.patchSent(len(patch))
+	}
+
+	return nil
+}
+
+// buildPatches builds the patches turning the last topology into the given state, in compliance with the
+// maximum patch size.
+func (s *Store) buildPatches(lastTopology []byte, st state.Cluster) ([][]byte, []byte, error) {
+	patch, newTopology, err := s.buildPatch(lastTopology, st)
+	if err != nil || patch == nil {
+		return nil, newTopology, err
+	}
+
+	if s.maxPatchSize <= 0 || len(patch) <= s.maxPatchSize {
+		return [][]byte{patch}, newTopology, nil
+	}
+
+	patches, oversized, err := splitPatch(patch, s.maxPatchSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("split patch: %w", err)
+	}
+	if !oversized {
+		return patches, newTopology, nil
+	}
+
+	newTopology, err = truncateNoisyFields(newTopology, s.maxPatchSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("truncate noisy fields: %w", err)
+	}
+	s.metrics.truncated()
+
+	patch, err = jsonpatch.CreateMergePatch(lastTopology, newTopology)
+	if err != nil {
+		return nil, nil, fmt.Errorf("build merge patch: %w", err)
+	}
+
+	// Truncated fields may have been the only changes.
+	if bytes.Equal(patch, []byte("{}")) {
+		return nil, newTopology, nil
+	}
+
+	patches, oversized, err = splitPatch(patch, s.maxPatchSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("split patch: %w", err)
+	}
+	if oversized {
+		log.Warn().Int("max_patch_size", s.maxPatchSize).Msg("Some topology resources exceed the maximum patch size")
+	}
+
+	return patches, newTopology, nil
+}
+
 func (s *Store) buildPatch(lastTopology []byte, st state.Cluster) ([]byte, []byte, error) {
 	newTopology, err := json.Marshal(st)
 	if err != nil {
@@ -116,5 +193,10 @@ func (s *Store) buildPatch(lastTopology []byte, st state.Cluster) ([]byte, []byt
 		return nil, nil, fmt.Errorf("build merge patch: %w", err)
 	}
 
+	// Topologies may be equivalent despite being serialized differently.
+	if bytes.Equal(patch, []byte("{}")) {
+		return nil, newTopology, nil
+	}
+
 	return patch, newTopology, nil
 }
@@ -0,0 +1,145 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// benchmarkScales are the numbers of topology objects the Store benchmarks run with.
+var benchmarkScales = []int{1000, 10000, 50000}
+
+func BenchmarkStore_buildPatch(b *testing.B) {
+	for _, scale := range benchmarkScales {
+		scale := scale
+		b.Run(fmt.Sprintf("objects=%d", scale), func(b *testing.B) {
+			benchmarkBuildPatch(b, scale)
+		})
+	}
+}
+
+// TestStore_buildPatch_budgets makes sure generating topology patches of large clusters stays within its performance
+// budgets. Timings depend on the host, so budgets are only enforced when TOPOLOGY_BUDGETS is set, which CI does.
+func TestStore_buildPatch_budgets(t *testing.T) {
+	if os.Getenv("TOPOLOGY_BUDGETS") == "" {
+		t.Skip("TOPOLOGY_BUDGETS is not set")
+	}
+
+	tests := []struct {
+		objects        int
+		maxNsPerOp     int64
+		maxBytesPerOp  int64
+		maxAllocsPerOp int64
+	}{
+		{objects: 1000, maxNsPerOp: 50_000_000, maxBytesPerOp: 3_500_000, maxAllocsPerOp: 80_000},
+		{objects: 10000, maxNsPerOp: 500_000_000, maxBytesPerOp: 42_000_000, maxAllocsPerOp: 800_000},
+		{objects: 50000, maxNsPerOp: 2_500_000_000, maxBytesPerOp: 200_000_000, maxAllocsPerOp: 4_000_000},
+	}
+
+	// Sub-tests are not run in parallel: they would compete for the CPU and distort the measures.
+	for _, test := range tests {
+		test := test
+		t.Run(fmt.Sprintf("objects=%d", test.objects), func(t *testing.T) {
+			res := testing.Benchmark(func(b *testing.B) {
+				benchmarkBuildPatch(b, test.objects)
+			})
+
+			assert.LessOrEqual(t, res.NsPerOp(), test.maxNsPerOp, "ns/op")
+			assert.LessOrEqual(t, res.AllocedBytesPerOp(), test.maxBytesPerOp, "B/op")
+			assert.LessOrEqual(t, res.AllocsPerOp(), test.maxAllocsPerOp, "allocs/op")
+		})
+	}
+}
+
+// benchmarkBuildPatch measures the generation of a patch for a topology of the given size in which a single Service
+// changed, which is what happens most of the time.
+func benchmarkBuildPatch(b *testing.B, objects int) {
+	b.Helper()
+
+	cluster := benchmarkCluster(objects)
+
+	lastTopology, err := json.Marshal(cluster)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	s := New(nil, Config{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		svc := cluster.Services[fmt.Sprintf("service-%d@ns-%d", i%(objects/2), i%(objects/2)%100)]
+		svc.ExternalPorts = append(svc.ExternalPorts[:1], 8000+i%2)
+
+		patch, newTopology, err := s.buildPatch(lastTopology, cluster)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if patch == nil {
+			b.Fatal("no patch generated")
+		}
+
+		lastTopology = newTopology
+	}
+}
+
+// benchmarkCluster returns a topology made of the given number of objects, half of them being Services and the other
+// half Ingresses routing to two of these Services.
+func benchmarkCluster(objects int) state.Cluster {
+	cluster := state.Cluster{
+		Services:  make(map[string]*state.Service, objects/2),
+		Ingresses: make(map[string]*state.Ingress, objects/2),
+	}
+
+	for i := 0; i < objects/2; i++ {
+		namespace := fmt.Sprintf("ns-%d", i%100)
+
+		cluster.Services[fmt.Sprintf("service-%d@%s", i, namespace)] = &state.Service{
+			Name:          fmt.Sprintf("service-%d", i),
+			Namespace:     namespace,
+			Type:          corev1.ServiceTypeClusterIP,
+			ExternalPorts: []int{80, 443},
+		}
+
+		cluster.Ingresses[fmt.Sprintf("ingress-%d@%s.ingress.networking.k8s.io", i, namespace)] = &state.Ingress{
+			ResourceMeta: state.ResourceMeta{
+				Kind:      "Ingress",
+				Group:     "networking.k8s.io",
+				Name:      fmt.Sprintf("ingress-%d", i),
+				Namespace: namespace,
+			},
+			IngressMeta: state.IngressMeta{
+				Annotations: map[string]string{"traefik.ingress.kubernetes.io/router.tls": "true"},
+				Labels:      map[string]string{"app": fmt.Sprintf("app-%d", i)},
+			},
+			Services: []string{
+				fmt.Sprintf("service-%d@%s", i, namespace),
+				fmt.Sprintf("service-%d@%s", i+1, namespace),
+			},
+		}
+	}
+
+	return cluster
+}
@@ -344,6 +344,47 @@ func TestStore_Write_fetchAndPatch(t *testing.T) {
 			}`,
 			wantVersion: 2,
 		},
+		{
+			desc:           "update HPA replicas and metrics",
+			fetchedVersion: 1,
+			fetchedTopology: state.Cluster{
+				HPAs: map[string]*state.HPA{
+					"whoami@ns": {
+						Name:            "whoami",
+						Namespace:       "ns",
+						ScaleTargetRef:  state.HPAScaleTarget{APIVersion: "apps/v1", Kind: "Deployment", Name: "whoami"},
+						MinReplicas:     1,
+						MaxReplicas:     10,
+						CurrentReplicas: 2,
+						DesiredReplicas: 2,
+						CurrentMetrics:  []state.HPAMetric{{Type: "Resource", Name: "cpu", AverageValue: "100m"}},
+					},
+				},
+			},
+			newTopology: state.Cluster{
+				HPAs: map[string]*state.HPA{
+					"whoami@ns": {
+						Name:            "whoami",
+						Namespace:       "ns",
+						ScaleTargetRef:  state.HPAScaleTarget{APIVersion: "apps/v1", Kind: "Deployment", Name: "whoami"},
+						MinReplicas:     1,
+						MaxReplicas:     10,
+						CurrentReplicas: 2,
+						DesiredReplicas: 4,
+						CurrentMetrics:  []state.HPAMetric{{Type: "Resource", Name: "cpu", AverageValue: "300m"}},
+					},
+				},
+			},
+			wantPatch: `{
+				"hpas": {
+					"whoami@ns": {
+						"currentMetrics": [{"averageValue":"300m","name":"cpu","type":"Resource"}],
+						"desiredReplicas": 4
+					}
+				}
+			}`,
+			wantVersion: 2,
+		},
 		{
 			desc:           "no different",
 			fetchedVersion: 1,
@@ -390,7 +431,7 @@ func TestStore_Write_fetchAndPatch(t *testing.T) {
 				platformClient.OnPatchTopology(patch, test.fetchedVersion).TypedReturns(test.wantVersion, nil).Once()
 			}
 
-			s := New(platformClient.Parent)
+			s := New(platformClient.Parent, Config{})
 
 			err := s.Write(context.Background(), test.newTopology)
 			require.NoError(t, err)
@@ -416,7 +457,7 @@ func TestStore_Write_alreadyFetched(t *testing.T) {
 
 	var err error
 
-	s := New(platformClient)
+	s := New(platformClient, Config{})
 	s.lastKnownVersion = 1
 	s.lastTopology, err = json.Marshal(state.Cluster{
 		Services: map[string]*state.Service{
@@ -496,7 +537,7 @@ func TestStore_Write_retryOnPatchRetryableFailure(t *testing.T) {
 		}`)), 3).TypedReturns(4, nil).Once().
 		Parent
 
-	s := New(platformClient)
+	s := New(platformClient, Config{})
 
 	newTopology := state.Cluster{
 		Services: map[string]*state.Service{
@@ -529,7 +570,7 @@ func TestStore_Write_doNotRetryOnPatchFatalFailure(t *testing.T) {
 		OnPatchTopology([]byte(`{"services":{"service-1@ns":{"namespace":"default"}}}`), 3).TypedReturns(4, nil).Once().
 		Parent
 
-	s := New(platformClient)
+	s := New(platformClient, Config{})
 
 	newTopology := state.Cluster{
 		Services: map[string]*state.Service{
@@ -572,7 +613,7 @@ func TestStore_Write_abortOnFetchFailure(t *testing.T) {
 		}`)), 1).TypedReturns(2, nil).Once().
 		Parent
 
-	s := New(platformClient)
+	s := New(platformClient, Config{})
 
 	newTopology := state.Cluster{
 		Services: map[string]*state.Service{
@@ -607,7 +648,7 @@ func TestStore_Write_giveUpOnRetryingWhenReachedBackoffLimit(t *testing.T) {
 		OnPatchTopology([]byte(`{"services":{"service-1@ns":{"name":"service-5"}}}`), 4).TypedReturns(5, nil).Once().
 		Parent
 
-	s := New(platformClient)
+	s := New(platformClient, Config{})
 	s.maxPatchRetry = 3
 
 	newTopology := state.Cluster{
@@ -631,3 +672,41 @@ func removeSpaces(s string) string {
 
 	return s
 }
+
+func TestStore_Write_splitsLargePatches(t *testing.T) {
+	platformClient := newPlatformClientMock(t).
+		OnPatchTopology([]byte(`{"services":{"service-1@ns":{"name":"service-1","namespace":"","type":""}}}`), 1).
+		TypedReturns(2, nil).
+		Once().
+		Parent.
+		OnPatchTopology([]byte(`{"services":{"service-2@ns":{"name":"service-2","namespace":"","type":""}}}`), 2).
+		TypedReturns(3, nil).
+		Once().
+		Parent
+
+	var err error
+
+	s := New(platformClient, Config{MaxPatchSize: 100})
+	s.lastKnownVersion = 1
+	s.lastTopology, err = json.Marshal(state.Cluster{})
+	require.NoError(t, err)
+
+	newTopology := state.Cluster{
+		Services: map[string]*state.Service{
+			"service-1@ns": {Name: "service-1"},
+			"service-2@ns": {
+				Name:        "service-2",
+				Annotations: map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+			},
+		},
+	}
+
+	err = s.Write(context.Background(), newTopology)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, s.lastKnownVersion)
+
+	// Truncated annotations are not sent again.
+	err = s.Write(context.Background(), newTopology)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, s.lastKnownVersion)
+}
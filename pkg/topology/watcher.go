@@ -20,6 +20,7 @@ package topology
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -31,22 +32,48 @@ import (
 // current state.
 type ListenerFunc func(ctx context.Context, state *state.Cluster)
 
+// WatcherConfig configures how often the watcher patches the topology.
+type WatcherConfig struct {
+	// MinPatchInterval is the minimum duration between two patches. Changes occurring
+	// in the meantime are batched into the next patch.
+	MinPatchInterval time.Duration
+	// ResyncInterval is the interval at which the topology is written even when no change was notified.
+	ResyncInterval time.Duration
+}
+
 // Watcher is a process from the Hub agent that watches the topology for changes and
 // stores them over time to make them accessible from the SaaS.
 type Watcher struct {
 	k8s   *state.Fetcher
 	store *store.Store
+	cfg   WatcherConfig
 
 	listenersMu sync.Mutex
 	listeners   []ListenerFunc
+
+	// minPatchInterval overrides the configured MinPatchInterval, it can be changed while the watcher runs.
+	minPatchInterval atomic.Int64
+
+	syncMu sync.Mutex
+	// dirty is whether changes were notified since the last sync.
+	dirty atomic.Bool
 }
 
-// NewWatcher instantiates a new watcher that uses a fetcher to periodically get the K8S state and a store to write it.
-func NewWatcher(f *state.Fetcher, s *store.Store) *Watcher {
-	return &Watcher{
+// NewWatcher instantiates a new watcher that uses a fetcher to get the K8S state whenever it changes and a store to write it.
+func NewWatcher(f *state.Fetcher, s *store.Store, cfg WatcherConfig) *Watcher {
+	w := &Watcher{
 		k8s:   f,
 		store: s,
+		cfg:   cfg,
 	}
+	w.minPatchInterval.Store(int64(cfg.MinPatchInterval))
+
+	return w
+}
+
+// SetMinPatchInterval sets the minimum duration between two patches. It applies from the next notified change.
+func (w *Watcher) SetMinPatchInterval(interval time.Duration) {
+	w.minPatchInterval.Store(int64(interval))
 }
 
 // AddListener adds a state listener.
@@ -59,33 +86,77 @@ func (w *Watcher) AddListener(listener ListenerFunc) {
 
 // Start runs the watcher process.
 func (w *Watcher) Start(ctx context.Context) {
-	tick := time.NewTicker(5 * time.Second)
-	defer tick.Stop()
+	resync := time.NewTicker(w.cfg.ResyncInterval)
+	defer resync.Stop()
+
+	w.sync(ctx)
+	lastSync := time.Now()
+
+	// pending fires when the next patch is due. It is nil when no change is waiting to be patched.
+	var pending <-chan time.Time
 
 	for {
 		select {
 		case <-ctx.Done():
 			log.Info().Msg("Stopping topology watcher")
 			return
-		case <-tick.C:
-			s, err := w.k8s.FetchState()
-			if err != nil {
-				log.Error().Err(err).Msg("create state")
-				continue
-			}
-			if s == nil {
+		case <-w.k8s.Changes():
+			w.dirty.Store(true)
+
+			if pending != nil {
 				continue
 			}
 
-			w.listenersMu.Lock()
-			for _, l := range w.listeners {
-				l(ctx, s)
-			}
-			w.listenersMu.Unlock()
+			pending = time.After(time.Until(lastSync.Add(time.Duration(w.minPatchInterval.Load()))))
+		case <-pending:
+			pending = nil
 
-			if err = w.store.Write(ctx, *s); err != nil {
-				log.Error().Err(err).Msg("commit cluster state changes")
+			w.sync(ctx)
+			lastSync = time.Now()
+		case <-resync.C:
+			if pending != nil {
+				continue
 			}
+
+			w.sync(ctx)
+			lastSync = time.Now()
 		}
 	}
 }
+
+// Flush writes the topology if changes are waiting to be patched. It's called once the watcher is stopped, so the last
+// changes aren't lost on shutdown.
+func (w *Watcher) Flush(ctx context.Context) {
+	if !w.dirty.Load() {
+		return
+	}
+
+	log.Info().Msg("Flushing pending topology changes")
+	w.sync(ctx)
+}
+
+func (w *Watcher) sync(ctx context.Context) {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+
+	w.dirty.Store(false)
+
+	s, err := w.k8s.FetchState()
+	if err != nil {
+		log.Error().Err(err).Msg("create state")
+		return
+	}
+	if s == nil {
+		return
+	}
+
+	w.listenersMu.Lock()
+	for _, l := range w.listeners {
+		l(ctx, s)
+	}
+	w.listenersMu.Unlock()
+
+	if err = w.store.Write(ctx, *s); err != nil {
+		log.Error().Err(err).Msg("commit cluster state changes")
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package topology
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikcrdfake "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/store"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kversion "k8s.io/apimachinery/pkg/version"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatcher_Start_patchesOnChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeClient := kubefake.NewSimpleClientset()
+	fakeDiscovery, ok := kubeClient.Discovery().(*discoveryfake.FakeDiscovery)
+	require.True(t, ok)
+	fakeDiscovery.FakedServerVersion = &kversion.Info{GitVersion: "v1.20.1"}
+
+	fetcher, err := state.NewFetcher(ctx, kubeClient, traefikcrdfake.NewSimpleClientset(), hubfake.NewSimpleClientset(), state.NamespaceFilter{}, state.RedactionFilter{})
+	require.NoError(t, err)
+
+	minPatchInterval := 300 * time.Millisecond
+	w := NewWatcher(fetcher, store.New(&platformClientMock{}, store.Config{}), WatcherConfig{
+		MinPatchInterval: minPatchInterval,
+		ResyncInterval:   time.Hour,
+	})
+
+	synced := make(chan int, 10)
+	w.AddListener(func(_ context.Context, s *state.Cluster) {
+		synced <- len(s.Services)
+	})
+
+	go w.Start(ctx)
+
+	assert.Equal(t, 0, waitSync(t, synced))
+
+	createService(t, kubeClient, "svc-0")
+	assert.Equal(t, 1, waitSync(t, synced))
+
+	// Changes happening right after a patch are batched into the next one.
+	start := time.Now()
+	for i := 1; i < 4; i++ {
+		createService(t, kubeClient, fmt.Sprintf("svc-%d", i))
+	}
+
+	assert.Equal(t, 4, waitSync(t, synced))
+	assert.GreaterOrEqual(t, time.Since(start), minPatchInterval/2)
+	assert.Empty(t, synced)
+}
+
+func TestWatcher_Flush(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	fakeDiscovery, ok := kubeClient.Discovery().(*discoveryfake.FakeDiscovery)
+	require.True(t, ok)
+	fakeDiscovery.FakedServerVersion = &kversion.Info{GitVersion: "v1.20.1"}
+
+	fetcher, err := state.NewFetcher(context.Background(), kubeClient, traefikcrdfake.NewSimpleClientset(), hubfake.NewSimpleClientset(), state.NamespaceFilter{}, state.RedactionFilter{})
+	require.NoError(t, err)
+
+	w := NewWatcher(fetcher, store.New(&platformClientMock{}, store.Config{}), WatcherConfig{
+		MinPatchInterval: time.Hour,
+		ResyncInterval:   time.Hour,
+	})
+
+	synced := make(chan int, 10)
+	w.AddListener(func(_ context.Context, s *state.Cluster) {
+		synced <- len(s.Services)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		w.Start(ctx)
+		close(stopped)
+	}()
+
+	assert.Equal(t, 0, waitSync(t, synced))
+
+	// The change is waiting for the next patch when the watcher stops.
+	createService(t, kubeClient, "svc-0")
+	require.Eventually(t, w.dirty.Load, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+	assert.Empty(t, synced)
+
+	w.Flush(context.Background())
+	assert.Equal(t, 1, waitSync(t, synced))
+
+	// Nothing left to flush.
+	w.Flush(context.Background())
+	assert.Empty(t, synced)
+}
+
+func waitSync(t *testing.T, synced <-chan int) int {
+	t.Helper()
+
+	select {
+	case n := <-synced:
+		return n
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "topology not synced")
+		return 0
+	}
+}
+
+func createService(t *testing.T, kubeClient *kubefake.Clientset, name string) {
+	t.Helper()
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+	_, err := kubeClient.CoreV1().Services("ns").Create(context.Background(), svc, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+type platformClientMock struct {
+	version int64
+}
+
+func (p *platformClientMock) FetchTopology(_ context.Context) (state.Cluster, int64, error) {
+	p.version = 1
+	return state.Cluster{}, p.version, nil
+}
+
+func (p *platformClientMock) PatchTopology(_ context.Context, _ []byte, _ int64) (int64, error) {
+	p.version++
+	return p.version, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+// Package tracing traces the requests handled and sent by the agent with the OpenTelemetry SDK, and exports the spans
+// to an OpenTelemetry collector using OTLP over HTTP. The trace context is propagated with the W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// exportTimeout is the timeout of the requests exporting spans.
+	exportTimeout = 10 * time.Second
+	// shutdownTimeout is how long the spans ended before the tracer stops are given to be exported.
+	shutdownTimeout = 5 * time.Second
+)
+
+// Config configures a Tracer.
+type Config struct {
+	// Endpoint is the OTLP HTTP endpoint of the collector, such as "http://otel-collector:4318".
+	Endpoint string
+	// Headers are sent with the exported spans.
+	Headers map[string]string
+	// ServiceName identifies the agent component in the traces.
+	ServiceName string
+	// SampleRatio is the ratio of the traces started by the agent which are sampled. Traces started by a caller
+	// follow the sampling decision of the caller.
+	SampleRatio float64
+}
+
+// Tracer starts spans and exports them once ended. A nil Tracer is valid and traces nothing.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewTracer creates a Tracer. The "/v1/traces" path is used when the endpoint has none.
+func NewTracer(cfg Config) (*Tracer, error) {
+	u, err := url.ParseRequestURI(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("only http and https is supported, %s found", u.Scheme)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("sample ratio must be between 0 and 1, got %v", cfg.SampleRatio)
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(u.Path),
+		otlptracehttp.WithHeaders(cfg.Headers),
+		otlptracehttp.WithTimeout(exportTimeout),
+	}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	// The exporter only connects to the collector when exporting spans.
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.ServiceName),
+			semconv.ServiceVersionKey.String(version.Version()),
+		)),
+	)
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Error().Err(err).Msg("Unable to export spans")
+	}))
+
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer("github.com/traefik/hub-agent-kubernetes/pkg/tracing", trace.WithInstrumentationVersion(version.Version())),
+	}, nil
+}
+
+// Start starts a span, child of the span held by the given context if any. The returned context holds the new span.
+// Spans must be ended. The span is a no-op one if the Tracer is nil.
+func (t *Tracer) Start(ctx context.Context, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+
+	return t.tracer.Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// Run exports the ended spans until the given context is done. Spans ended in the meantime are exported once more
+// before returning.
+func (t *Tracer) Run(ctx context.Context) {
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := t.provider.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Unable to export spans")
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	collectortracev1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracev1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTracer_handlerAndTransport(t *testing.T) {
+	exported := make(chan *collectortracev1.ExportTraceServiceRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v1/traces", req.URL.Path)
+		assert.Equal(t, "secret", req.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var exportReq collectortracev1.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(body, &exportReq))
+
+		exported <- &exportReq
+	}))
+	t.Cleanup(collector.Close)
+
+	tracer, err := NewTracer(Config{
+		Endpoint:    collector.URL,
+		Headers:     map[string]string{"Authorization": "secret"},
+		ServiceName: "test",
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	var upstreamTraceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		upstreamTraceparent = req.Header.Get("traceparent")
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(upstream.Close)
+
+	client := &http.Client{Transport: tracer.Transport(http.DefaultTransport)}
+	handler := tracer.Handler("auth", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		upstreamReq, reqErr := http.NewRequestWithContext(req.Context(), http.MethodGet, upstream.URL+"/keys", http.NoBody)
+		require.NoError(t, reqErr)
+
+		resp, reqErr := client.Do(upstreamReq)
+		require.NoError(t, reqErr)
+		_ = resp.Body.Close()
+
+		rw.WriteHeader(http.StatusForbidden)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/my-acp", http.NoBody)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Regexp(t, "^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$", upstreamTraceparent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		tracer.Run(ctx)
+		close(done)
+	}()
+
+	// Ended spans are exported once more when the tracer stops.
+	cancel()
+	<-done
+
+	var exportReq *collectortracev1.ExportTraceServiceRequest
+	select {
+	case exportReq = <-exported:
+	case <-time.After(time.Second):
+		require.Fail(t, "spans not exported")
+	}
+
+	require.Len(t, exportReq.ResourceSpans, 1)
+	require.Len(t, exportReq.ResourceSpans[0].ScopeSpans, 1)
+
+	spans := exportReq.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	clientSpan, serverSpan := spans[0], spans[1]
+	assert.Equal(t, "HTTP GET", clientSpan.Name)
+	assert.Equal(t, tracev1.Span_SPAN_KIND_CLIENT, clientSpan.Kind)
+	assert.Equal(t, "auth", serverSpan.Name)
+	assert.Equal(t, tracev1.Span_SPAN_KIND_SERVER, serverSpan.Kind)
+
+	// Both spans belong to the trace of the caller, the client span being a child of the server one.
+	assert.Equal(t, serverSpan.TraceId, clientSpan.TraceId)
+	assert.Equal(t, serverSpan.SpanId, clientSpan.ParentSpanId)
+	assert.Equal(t, []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}, serverSpan.ParentSpanId)
+	assert.Contains(t, upstreamTraceparent, "-"+hex.EncodeToString(clientSpan.SpanId)+"-")
+}
+
+func TestTracer_Start_notSampled(t *testing.T) {
+	tracer, err := NewTracer(Config{Endpoint: "http://collector:4318", SampleRatio: 0})
+	require.NoError(t, err)
+
+	ctx, span := tracer.Start(context.Background(), "root", trace.SpanKindInternal)
+	span.End()
+	assert.False(t, span.SpanContext().IsSampled())
+
+	// The sampling decision is propagated to the children.
+	_, child := tracer.Start(ctx, "child", trace.SpanKindInternal)
+	child.End()
+	assert.False(t, child.SpanContext().IsSampled())
+	assert.Equal(t, span.SpanContext().TraceID(), child.SpanContext().TraceID())
+}
+
+func TestTracer_nil(t *testing.T) {
+	var tracer *Tracer
+
+	ctx, span := tracer.Start(context.Background(), "span", trace.SpanKindInternal)
+	assert.False(t, span.SpanContext().IsValid())
+	assert.Equal(t, context.Background(), ctx)
+
+	assert.NotPanics(t, func() {
+		span.SetAttributes(attribute.String("key", "value"))
+		span.RecordError(io.EOF)
+		span.End()
+	})
+}
+
+func TestNewTracer_invalidSampleRatio(t *testing.T) {
+	_, err := NewTracer(Config{Endpoint: "http://collector:4318", SampleRatio: 2})
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package traefik describes the Traefik installations the agent configures routes for.
+package traefik
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Instance describes a Traefik installation of the cluster.
+type Instance struct {
+	// IngressClassName is the ingress class watched by this instance.
+	IngressClassName string `json:"ingressClassName"`
+	// Namespaces are the namespaces whose routes are served by this instance.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// APIEntryPoint is the entry point used by this instance to expose APIs.
+	APIEntryPoint string `json:"apiEntryPoint,omitempty"`
+	// TunnelEntryPoint is the entry point used by this instance to expose tunnels.
+	TunnelEntryPoint string `json:"tunnelEntryPoint,omitempty"`
+}
+
+// Instances holds the additional Traefik instances of the cluster, each one serving routes of a set of namespaces.
+// Routes of the other namespaces are served by the default instance.
+type Instances []Instance
+
+// LoadInstances reads the Traefik instances from the JSON file at the given path.
+func LoadInstances(path string) (Instances, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var instances Instances
+	if err = json.Unmarshal(b, &instances); err != nil {
+		return nil, fmt.Errorf("decode instances: %w", err)
+	}
+
+	if err = instances.Validate(); err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// Validate makes sure each instance has its own ingress class and that namespaces are served by a single instance.
+func (i Instances) Validate() error {
+	ingressClasses := make(map[string]struct{}, len(i))
+	namespaces := make(map[string]string)
+
+	for _, instance := range i {
+		if instance.IngressClassName == "" {
+			return errors.New("missing ingress class name")
+		}
+		if _, ok := ingressClasses[instance.IngressClassName]; ok {
+			return fmt.Errorf("duplicated ingress class name %q", instance.IngressClassName)
+		}
+		ingressClasses[instance.IngressClassName] = struct{}{}
+
+		if len(instance.Namespaces) == 0 {
+			return fmt.Errorf("no namespace served by instance %q", instance.IngressClassName)
+		}
+
+		for _, ns := range instance.Namespaces {
+			if other, ok := namespaces[ns]; ok {
+				return fmt.Errorf("namespace %q served by both instances %q and %q", ns, other, instance.IngressClassName)
+			}
+			namespaces[ns] = instance.IngressClassName
+		}
+	}
+
+	return nil
+}
+
+// ForNamespace returns the instance serving routes of the given namespace. Entry points which are not set on this
+// instance are inherited from the given default instance.
+func (i Instances) ForNamespace(namespace string, defaultInstance Instance) Instance {
+	for _, instance := range i {
+		for _, ns := range instance.Namespaces {
+			if ns != namespace {
+				continue
+			}
+
+			if instance.APIEntryPoint == "" {
+				instance.APIEntryPoint = defaultInstance.APIEntryPoint
+			}
+			if instance.TunnelEntryPoint == "" {
+				instance.TunnelEntryPoint = defaultInstance.TunnelEntryPoint
+			}
+
+			return instance
+		}
+	}
+
+	return defaultInstance
+}
+
+// All returns the default instance followed by the additional ones, with their entry points defaulted.
+func (i Instances) All(defaultInstance Instance) []Instance {
+	all := []Instance{defaultInstance}
+	for _, instance := range i {
+		if len(instance.Namespaces) == 0 {
+			continue
+		}
+
+		all = append(all, i.ForNamespace(instance.Namespaces[0], defaultInstance))
+	}
+
+	return all
+}
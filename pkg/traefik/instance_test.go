@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package traefik
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadInstances(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content string
+		want    Instances
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			desc:    "valid instances",
+			content: `[{"ingressClassName":"traefik-internal","namespaces":["billing","payments"],"tunnelEntryPoint":"internal-tunl"}]`,
+			want: Instances{
+				{IngressClassName: "traefik-internal", Namespaces: []string{"billing", "payments"}, TunnelEntryPoint: "internal-tunl"},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			desc:    "malformed file",
+			content: `{"ingressClassName":`,
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "missing ingress class name",
+			content: `[{"namespaces":["billing"]}]`,
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "missing namespaces",
+			content: `[{"ingressClassName":"traefik-internal"}]`,
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "duplicated ingress class name",
+			content: `[{"ingressClassName":"traefik-internal","namespaces":["billing"]},{"ingressClassName":"traefik-internal","namespaces":["payments"]}]`,
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "namespace served by several instances",
+			content: `[{"ingressClassName":"traefik-a","namespaces":["billing"]},{"ingressClassName":"traefik-b","namespaces":["billing"]}]`,
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "instances.json")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			got, err := LoadInstances(path)
+			test.wantErr(t, err)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestLoadInstances_noFile(t *testing.T) {
+	got, err := LoadInstances("")
+	require.NoError(t, err)
+
+	assert.Empty(t, got)
+}
+
+func TestInstances_ForNamespace(t *testing.T) {
+	defaultInstance := Instance{
+		IngressClassName: "traefik-hub",
+		APIEntryPoint:    "websecure",
+		TunnelEntryPoint: "traefikhub-tunl",
+	}
+
+	instances := Instances{
+		{IngressClassName: "traefik-internal", Namespaces: []string{"billing"}, TunnelEntryPoint: "internal-tunl"},
+	}
+
+	tests := []struct {
+		desc      string
+		namespace string
+		want      Instance
+	}{
+		{
+			desc:      "namespace served by the default instance",
+			namespace: "default",
+			want:      defaultInstance,
+		},
+		{
+			desc:      "namespace served by an additional instance",
+			namespace: "billing",
+			want: Instance{
+				IngressClassName: "traefik-internal",
+				Namespaces:       []string{"billing"},
+				APIEntryPoint:    "websecure",
+				TunnelEntryPoint: "internal-tunl",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, instances.ForNamespace(test.namespace, defaultInstance))
+		})
+	}
+}
@@ -24,9 +24,11 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sync"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 )
@@ -34,13 +36,14 @@ import (
 // Client allows interacting with the tunnel service.
 type Client struct {
 	baseURL *url.URL
+	tokenMu sync.RWMutex
 	token   string
 
 	httpClient *http.Client
 }
 
-// NewClient creates a new client for the tunnel service.
-func NewClient(baseURL, token string) (*Client, error) {
+// NewClient creates a new client for the tunnel service, reaching it through the given egress.
+func NewClient(baseURL, token string, egress httpclient.Egress) (*Client, error) {
 	u, err := url.ParseRequestURI(baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse client url: %w", err)
@@ -49,6 +52,7 @@ func NewClient(baseURL, token string) (*Client, error) {
 	rc := retryablehttp.NewClient()
 	rc.RetryMax = 4
 	rc.Logger = logger.NewRetryableHTTPWrapper(log.Logger.With().Str("component", "tunnel-client").Logger())
+	rc.HTTPClient.Transport = egress.Transport()
 
 	retryClient := rc.StandardClient()
 
@@ -59,6 +63,21 @@ func NewClient(baseURL, token string) (*Client, error) {
 	}, nil
 }
 
+// SetToken sets the token used to authenticate the calls to the tunnel service, e.g. once it's been rotated.
+func (c *Client) SetToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.token = token
+}
+
+func (c *Client) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+
+	return c.token
+}
+
 // APIError represents an error returned by the API.
 type APIError struct {
 	StatusCode int    `json:"statusCode"`
@@ -73,6 +92,13 @@ func (a APIError) Error() string {
 type Endpoint struct {
 	TunnelID       string `json:"tunnelId"`
 	BrokerEndpoint string `json:"brokerEndpoint"`
+	// FallbackBrokerEndpoints are the brokers to fail over to when the broker endpoint is unreachable.
+	FallbackBrokerEndpoints []string `json:"fallbackBrokerEndpoints,omitempty"`
+
+	// Protocol is the protocol of the traffic carried by the tunnel, TCP by default.
+	Protocol string `json:"protocol,omitempty"`
+	// EntryPoint is the Traefik entry point receiving the traffic of UDP tunnels.
+	EntryPoint string `json:"entryPoint,omitempty"`
 }
 
 // ListClusterTunnelEndpoints lists all tunnels the agent needs to open.
@@ -87,7 +113,7 @@ func (c *Client) ListClusterTunnelEndpoints(ctx context.Context) ([]Endpoint, er
 		return nil, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -26,6 +26,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 )
 
 func TestClient_ListClusterTunnelEndpoints(t *testing.T) {
@@ -49,7 +50,7 @@ func TestClient_ListClusterTunnelEndpoints(t *testing.T) {
 	})
 	srv := httptest.NewServer(mux)
 
-	client, err := NewClient(srv.URL, "token")
+	client, err := NewClient(srv.URL, "token", httpclient.DefaultEgress())
 	require.NoError(t, err)
 
 	endpoints, err := client.ListClusterTunnelEndpoints(context.Background())
@@ -67,7 +68,7 @@ func TestClient_ListClusterTunnelEndpoints_handleError(t *testing.T) {
 	})
 	srv := httptest.NewServer(mux)
 
-	client, err := NewClient(srv.URL, "token")
+	client, err := NewClient(srv.URL, "token", httpclient.DefaultEgress())
 	require.NoError(t, err)
 	// We remove the retryable client to not last too long.
 	client.httpClient = http.DefaultClient
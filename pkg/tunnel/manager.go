@@ -19,19 +19,15 @@ package tunnel
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"net"
-	"net/http"
-	"net/url"
-	"path"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/gorilla/websocket"
-	"github.com/hashicorp/yamux"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 )
 
 // Backend is able to call hub-tunnel API.
@@ -42,35 +38,111 @@ type Backend interface {
 // Manager manages tunnels.
 type Manager struct {
 	client            Backend
+	tokenMu           sync.RWMutex
 	token             string
 	traefikTunnelAddr string
+	udpEntryPoints    map[string]string
+	egress            httpclient.Egress
+	metrics           *Metrics
+	connections       int
+	drainTimeout      time.Duration
+	qos               QoSConfig
+	streamIdleTimeout time.Duration
+	quic              bool
 
 	tunnelsMu sync.Mutex
 	tunnels   map[string]*tunnel
+	// listed is whether the tunnel endpoints have been listed at least once, and expected holds the IDs of the
+	// tunnels of the last listing.
+	listed   bool
+	expected map[string]struct{}
 }
 
-type tunnel struct {
-	BrokerEndpoint  string
-	ClusterEndpoint string
-	Client          *closeAwareListener
+// NewManager returns a new manager instance, opening tunnels through the given egress.
+func NewManager(tunnels Backend, traefikTunnelAddr, token string, egress httpclient.Egress) Manager {
+	return Manager{
+		client:            tunnels,
+		traefikTunnelAddr: traefikTunnelAddr,
+		token:             token,
+		egress:            egress,
+		connections:       1,
+		drainTimeout:      defaultDrainTimeout,
+		tunnels:           make(map[string]*tunnel),
+		expected:          make(map[string]struct{}),
+	}
 }
 
-func (t *tunnel) Close() error {
-	if t.Client != nil {
-		return t.Client.Close()
+// SetMetrics sets the collectors the activity of the tunnels is reported to. It must be called before running the
+// manager.
+func (m *Manager) SetMetrics(metrics *Metrics) {
+	m.metrics = metrics
+}
+
+// SetConnections sets the number of connections opened for each tunnel, spread over the brokers of the tunnel. It must
+// be called before running the manager.
+func (m *Manager) SetConnections(connections int) {
+	if connections > 0 {
+		m.connections = connections
 	}
+}
 
-	return nil
+// SetDrainTimeout sets how long the connections of a closed tunnel are kept open for their streams to complete. It
+// must be called before running the manager.
+func (m *Manager) SetDrainTimeout(timeout time.Duration) {
+	m.drainTimeout = timeout
 }
 
-// NewManager returns a new manager instance.
-func NewManager(tunnels Backend, traefikTunnelAddr, token string) Manager {
-	return Manager{
-		client:            tunnels,
-		traefikTunnelAddr: traefikTunnelAddr,
-		token:             token,
-		tunnels:           make(map[string]*tunnel),
+// SetQoS sets the limits of the traffic going through each tunnel. It must be called before running the manager.
+func (m *Manager) SetQoS(cfg QoSConfig) {
+	m.qos = cfg
+}
+
+// SetStreamIdleTimeout sets how long a tunnel stream can go without traffic, in either direction, before being closed.
+// Zero, the default, keeps idle streams open, like long-lived WebSockets or gRPC streams. It must be called before
+// running the manager.
+func (m *Manager) SetStreamIdleTimeout(timeout time.Duration) {
+	m.streamIdleTimeout = timeout
+}
+
+// SetQUIC sets whether the tunnel connections are opened over QUIC to the brokers supporting it, falling back to
+// WebSocket otherwise. It must be called before running the manager.
+func (m *Manager) SetQUIC(enabled bool) {
+	m.quic = enabled
+}
+
+// Ready returns whether all the tunnels of the cluster have at least one connection to a broker. It isn't until the
+// tunnels have been listed once.
+func (m *Manager) Ready() bool {
+	m.tunnelsMu.Lock()
+	defer m.tunnelsMu.Unlock()
+
+	if !m.listed {
+		return false
 	}
+
+	for id := range m.expected {
+		tun, ok := m.tunnels[id]
+		if !ok || tun.connected.Load() == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SetToken sets the token used to open tunnels, e.g. once it's been rotated. Opened tunnels are kept, only the tunnels
+// opened afterwards use the new token.
+func (m *Manager) SetToken(token string) {
+	m.tokenMu.Lock()
+	defer m.tokenMu.Unlock()
+
+	m.token = token
+}
+
+// SetUDPEntryPoints sets the addresses of the Traefik UDP entry points, by name, receiving the traffic of UDP tunnels.
+// It must be called before running the manager.
+func (m *Manager) SetUDPEntryPoints(addrs map[string]string) {
+	m.udpEntryPoints = addrs
 }
 
 // Run runs the manager.
@@ -99,17 +171,18 @@ func (m *Manager) Run(ctx context.Context) {
 	}
 }
 
+// stop closes all the tunnels and waits for their connections to be drained.
 func (m *Manager) stop() {
 	m.tunnelsMu.Lock()
-	defer m.tunnelsMu.Unlock()
+	tunnels := m.tunnels
+	m.tunnels = make(map[string]*tunnel)
+	m.tunnelsMu.Unlock()
 
-	for id, tun := range m.tunnels {
-		if err := tun.Close(); err != nil {
-			log.Error().Err(err).
-				Str("tunnel_id", id).
-				Msg("Unable to close tunnel")
-		}
-		delete(m.tunnels, id)
+	for _, tun := range tunnels {
+		tun.Close()
+	}
+	for _, tun := range tunnels {
+		tun.wait()
 	}
 }
 
@@ -124,129 +197,115 @@ func (m *Manager) updateTunnels(ctx context.Context) error {
 
 	currentTunnels := make(map[string]struct{})
 	for _, endpoint := range endpoints {
-		logger := log.With().
-			Str("broker_endpoint", endpoint.BrokerEndpoint).
-			Str("tunnel_id", endpoint.TunnelID).
-			Logger()
 		currentTunnels[endpoint.TunnelID] = struct{}{}
 
 		tun, found := m.tunnels[endpoint.TunnelID]
-		if !found {
-			m.launchTunnel(endpoint)
-
+		if found && tun.serves(endpoint) {
 			continue
 		}
 
-		if tun.BrokerEndpoint != endpoint.BrokerEndpoint {
-			if err = tun.Close(); err != nil {
-				logger.Error().Err(err).Msg("Unable to close tunnel")
-			}
+		// The connections of the previous tunnel are drained while the new ones are opened.
+		if found {
+			tun.Close()
 			delete(m.tunnels, endpoint.TunnelID)
-
-			m.launchTunnel(endpoint)
 		}
-	}
 
-	for id := range m.tunnels {
-		if _, found := currentTunnels[id]; !found {
-			if err = m.tunnels[id].Close(); err != nil {
-				log.Error().Err(err).Msg("Unable to close tunnel")
-			}
-			delete(m.tunnels, id)
-		}
+		m.launchTunnel(endpoint)
 	}
 
-	return nil
-}
-
-func (m *Manager) launchTunnel(endpoint Endpoint) {
-	t := &tunnel{BrokerEndpoint: endpoint.BrokerEndpoint, ClusterEndpoint: m.traefikTunnelAddr}
-	m.tunnels[endpoint.TunnelID] = t
-
-	go func(t *tunnel, tunnelID string) {
-		err := t.launch(tunnelID, m.token)
-		if err != nil {
-			log.Error().Err(err).Msg("Launch tunnel")
+	for id, tun := range m.tunnels {
+		if _, found := currentTunnels[id]; found {
+			continue
 		}
 
-		m.tunnelsMu.Lock()
-		delete(m.tunnels, tunnelID)
-		m.tunnelsMu.Unlock()
-	}(t, endpoint.TunnelID)
-}
+		tun.Close()
+		delete(m.tunnels, id)
 
-func (t *tunnel) launch(tunnelID, token string) error {
-	u, err := url.Parse(t.BrokerEndpoint)
-	if err != nil {
-		return fmt.Errorf("parse broker endpoint: %w", err)
+		go func(id string, tun *tunnel) {
+			tun.wait()
+			m.metrics.forget(id)
+		}(id, tun)
 	}
-	u.Path = path.Join(u.Path, tunnelID)
 
-	dialer := websocket.Dialer{
-		Proxy:            http.ProxyFromEnvironment,
-		HandshakeTimeout: 30 * time.Second,
-	}
-	connSocket, resp, err := dialer.Dial(u.String(), http.Header{"Authorization": []string{"Bearer " + token}})
-	if err != nil {
-		return fmt.Errorf("dial: %w", err)
-	}
+	m.listed = true
+	m.expected = currentTunnels
 
-	if resp.StatusCode != http.StatusSwitchingProtocols {
-		return fmt.Errorf("expected protocol switching, got: %d", resp.StatusCode)
-	}
+	return nil
+}
 
-	conn := &websocketNetConn{
-		Conn: connSocket,
+func (m *Manager) launchTunnel(endpoint Endpoint) {
+	t := &tunnel{
+		ID:                      endpoint.TunnelID,
+		BrokerEndpoint:          endpoint.BrokerEndpoint,
+		FallbackBrokerEndpoints: endpoint.FallbackBrokerEndpoints,
+		ClusterEndpoint:         m.traefikTunnelAddr,
+		Protocol:                endpoint.Protocol,
+		egress:                  m.egress,
+		metrics:                 m.metrics,
+		drainTimeout:            m.drainTimeout,
+		qos:                     newQoS(m.qos),
+		idleTimeout:             m.streamIdleTimeout,
+		quic:                    m.quic,
 	}
+	if endpoint.Protocol == ProtocolUDP {
+		addr, ok := m.udpEntryPoints[endpoint.EntryPoint]
+		if !ok {
+			log.Error().
+				Str("tunnel_id", endpoint.TunnelID).
+				Str("entry_point", endpoint.EntryPoint).
+				Msg("Unable to launch UDP tunnel: unknown entry point")
+			return
+		}
 
-	cfg := &yamux.Config{
-		AcceptBacklog:          256,
-		EnableKeepAlive:        true,
-		KeepAliveInterval:      30 * time.Second,
-		ConnectionWriteTimeout: 10 * time.Second,
-		MaxStreamWindowSize:    256 * 1024,
-		StreamOpenTimeout:      75 * time.Second,
-		StreamCloseTimeout:     5 * time.Minute,
-		LogOutput:              io.Discard,
-	}
-	client, err := yamux.Client(conn, cfg)
-	if err != nil {
-		return fmt.Errorf("new yamux client: %w", err)
+		t.ClusterEndpoint = addr
 	}
+	m.tunnels[endpoint.TunnelID] = t
 
-	t.Client = &closeAwareListener{Listener: client}
-
-	for {
-		brokerConn, acceptErr := t.Client.Accept()
-		if acceptErr != nil {
-			if errors.Is(acceptErr, errListenerClosed) {
-				return nil
-			}
+	m.tokenMu.RLock()
+	t.token = m.token
+	m.tokenMu.RUnlock()
 
-			return fmt.Errorf("accept: %w", acceptErr)
-		}
-
-		go func(brokerConn net.Conn) {
-			if err = proxy(brokerConn, t.ClusterEndpoint); err != nil {
-				log.Error().Err(err).Msg("Unable to proxy the tunnel traffic to the cluster endpoint")
-			}
-		}(brokerConn)
-	}
+	t.start(m.connections)
 }
 
-func proxy(sourceConn net.Conn, addr string) error {
+// proxy proxies a tunnel stream to the given TCP address. Each direction is shut down on its own, so long-lived streams,
+// such as WebSockets or gRPC streams, keep flowing until both ends are done. The stream is aborted once idle for the
+// given timeout, unless zero.
+func proxy(sourceConn net.Conn, addr string, idleTimeout time.Duration) error {
 	targetConn, err := net.Dial("tcp", addr)
 	if err != nil {
 		return fmt.Errorf("dial: %w", err)
 	}
+	defer func() {
+		_ = sourceConn.Close()
+		_ = targetConn.Close()
+	}()
 
-	errCh := make(chan error)
+	var idle atomic.Bool
+	conn, stop := withIdleTimeout(sourceConn, idleTimeout, func() {
+		idle.Store(true)
+		abort(sourceConn, targetConn)
+	})
+	defer stop()
 
-	go connCopy(errCh, targetConn, sourceConn)
-	go connCopy(errCh, sourceConn, targetConn)
+	errCh := make(chan error, 2)
+
+	go func() { errCh <- connCopy(targetConn, conn) }()
+	go func() { errCh <- connCopy(conn, targetConn) }()
 
 	err = <-errCh
-	<-errCh
+	if err != nil {
+		// The stream is broken, the other direction is stopped as well.
+		abort(sourceConn, targetConn)
+	}
+	if otherErr := <-errCh; err == nil {
+		err = otherErr
+	}
+
+	if idle.Load() {
+		log.Debug().Dur("idle_timeout", idleTimeout).Msg("Tunnel stream closed after being idle")
+		return nil
+	}
 
 	if err != nil {
 		return fmt.Errorf("copy conn: %w", err)
@@ -255,48 +314,14 @@ func proxy(sourceConn net.Conn, addr string) error {
 	return nil
 }
 
-func connCopy(errCh chan<- error, dst io.WriteCloser, src io.Reader) {
-	_, err := io.Copy(dst, src)
-	errCh <- err
-
-	if err = dst.Close(); err != nil {
-		log.Error().Err(err).Msg("Unable to close destination connection")
+func connCopy(dst, src net.Conn) error {
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
 	}
-}
-
-// closeAwareListener provides a listener that triggers an error when the connection is closed. net.Listener use
-// to return a "use of closed network connection" error when the connection is closed. As suggested in
-// https://github.com/golang/go/issues/4373, the wrapper captures the close order and serves a sentinel that
-// can be safely caught.
-type closeAwareListener struct {
-	net.Listener
 
-	closed   bool
-	closedMu sync.RWMutex
-}
-
-var errListenerClosed = errors.New("listener closed")
-
-func (l *closeAwareListener) Accept() (net.Conn, error) {
-	conn, err := l.Listener.Accept()
-	if err != nil {
-		l.closedMu.RLock()
-		defer l.closedMu.RUnlock()
-
-		if l.closed || errors.Is(err, io.EOF) {
-			return nil, errListenerClosed
-		}
-
-		return nil, err
+	if err := closeWrite(dst); err != nil {
+		log.Debug().Err(err).Msg("Unable to close destination connection")
 	}
 
-	return conn, nil
-}
-
-func (l *closeAwareListener) Close() error {
-	l.closedMu.Lock()
-	l.closed = true
-	l.closedMu.Unlock()
-
-	return l.Listener.Close()
+	return nil
 }
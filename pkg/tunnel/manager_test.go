@@ -31,6 +31,7 @@ import (
 	"github.com/hashicorp/yamux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 )
 
 func TestManager_updateTunnels(t *testing.T) {
@@ -71,17 +72,17 @@ func TestManager_updateTunnels(t *testing.T) {
 		},
 	}
 
-	c := fakeClient(t)
-	manager := NewManager(client, ingCtrlServiceURL, "token")
+	manager := NewManager(client, ingCtrlServiceURL, "token", httpclient.DefaultEgress())
+	manager.SetDrainTimeout(100 * time.Millisecond)
 	manager.tunnels["current-tunnel-new-broker"] = &tunnel{
 		BrokerEndpoint:  "old-endpoint",
 		ClusterEndpoint: ingCtrlServiceURL,
-		Client:          &closeAwareListener{Listener: c},
+		conns:           map[int]session{0: fakeClient(t)},
 	}
 	manager.tunnels["unused-tunnel"] = &tunnel{
 		BrokerEndpoint:  "old-endpoint",
 		ClusterEndpoint: ingCtrlServiceURL,
-		Client:          &closeAwareListener{Listener: c},
+		conns:           map[int]session{0: fakeClient(t)},
 	}
 
 	stopped := make(chan struct{})
@@ -118,6 +119,23 @@ func TestManager_updateTunnels(t *testing.T) {
 	manager.tunnelsMu.Unlock()
 }
 
+func TestManager_launchTunnel_unknownUDPEntryPoint(t *testing.T) {
+	manager := NewManager(&clientMock{}, "127.0.0.1:9901", "token", httpclient.DefaultEgress())
+	manager.SetUDPEntryPoints(map[string]string{"dns": "127.0.0.1:5353"})
+
+	manager.tunnelsMu.Lock()
+	defer manager.tunnelsMu.Unlock()
+
+	manager.launchTunnel(Endpoint{
+		TunnelID:       "udp-tunnel",
+		BrokerEndpoint: "ws://127.0.0.1:1",
+		Protocol:       ProtocolUDP,
+		EntryPoint:     "syslog",
+	})
+
+	assert.Empty(t, manager.tunnels)
+}
+
 func Test_proxy(t *testing.T) {
 	echoListener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", "0"))
 	require.NoError(t, err)
@@ -144,7 +162,7 @@ func Test_proxy(t *testing.T) {
 		conn, aerr := proxyListener.Accept()
 		require.NoError(t, aerr)
 
-		perr := proxy(conn, echoListener.Addr().String())
+		perr := proxy(conn, echoListener.Addr().String(), 0)
 		require.NoError(t, perr)
 	}()
 
@@ -197,7 +215,7 @@ func Test_proxy_targetUnreachable(t *testing.T) {
 
 	<-ready
 
-	err = proxy(proxyConn, "127.0.0.1:44444")
+	err = proxy(proxyConn, "127.0.0.1:44444", 0)
 	require.Error(t, err)
 }
 
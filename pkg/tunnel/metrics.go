@@ -0,0 +1,202 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors reporting the activity of the tunnels. A nil Metrics reports nothing.
+type Metrics struct {
+	connections   *prometheus.GaugeVec
+	activeStreams *prometheus.GaugeVec
+	rejected      *prometheus.CounterVec
+	bytes         *prometheus.CounterVec
+	reconnects    *prometheus.CounterVec
+	rtt           *prometheus.GaugeVec
+}
+
+// NewMetrics creates the tunnel collectors and registers them in the given registerer.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "hub_agent",
+			Subsystem: "tunnel",
+			Name:      "connections",
+			Help:      "Number of connections of the tunnel opened to a broker, by tunnel.",
+		}, []string{"tunnel"}),
+		activeStreams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "hub_agent",
+			Subsystem: "tunnel",
+			Name:      "active_streams",
+			Help:      "Number of streams being proxied through the tunnel, by tunnel.",
+		}, []string{"tunnel"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "tunnel",
+			Name:      "rejected_streams_total",
+			Help:      "Number of streams rejected for exceeding a concurrency limit, by tunnel and limit.",
+		}, []string{"tunnel", "reason"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "tunnel",
+			Name:      "bytes_total",
+			Help:      "Number of bytes received from (in) and sent to (out) the broker, by tunnel and direction.",
+		}, []string{"tunnel", "direction"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "tunnel",
+			Name:      "reconnects_total",
+			Help:      "Number of times the tunnel has been opened again after being disconnected from its broker, by tunnel.",
+		}, []string{"tunnel"}),
+		rtt: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "hub_agent",
+			Subsystem: "tunnel",
+			Name:      "rtt_seconds",
+			Help:      "Round-trip time of the last ping sent to the broker, by tunnel.",
+		}, []string{"tunnel"}),
+	}
+
+	if err := reg.Register(m.connections); err != nil {
+		return nil, fmt.Errorf("register connections gauge: %w", err)
+	}
+	if err := reg.Register(m.activeStreams); err != nil {
+		return nil, fmt.Errorf("register active streams gauge: %w", err)
+	}
+	if err := reg.Register(m.rejected); err != nil {
+		return nil, fmt.Errorf("register rejected streams counter: %w", err)
+	}
+	if err := reg.Register(m.bytes); err != nil {
+		return nil, fmt.Errorf("register bytes counter: %w", err)
+	}
+	if err := reg.Register(m.reconnects); err != nil {
+		return nil, fmt.Errorf("register reconnects counter: %w", err)
+	}
+	if err := reg.Register(m.rtt); err != nil {
+		return nil, fmt.Errorf("register rtt gauge: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) connectionOpened(tunnelID string) {
+	if m == nil {
+		return
+	}
+
+	m.connections.WithLabelValues(tunnelID).Inc()
+}
+
+func (m *Metrics) connectionClosed(tunnelID string) {
+	if m == nil {
+		return
+	}
+
+	m.connections.WithLabelValues(tunnelID).Dec()
+}
+
+func (m *Metrics) streamOpened(tunnelID string) {
+	if m == nil {
+		return
+	}
+
+	m.activeStreams.WithLabelValues(tunnelID).Inc()
+}
+
+func (m *Metrics) streamClosed(tunnelID string) {
+	if m == nil {
+		return
+	}
+
+	m.activeStreams.WithLabelValues(tunnelID).Dec()
+}
+
+func (m *Metrics) streamRejected(tunnelID, reason string) {
+	if m == nil {
+		return
+	}
+
+	m.rejected.WithLabelValues(tunnelID, reason).Inc()
+}
+
+func (m *Metrics) reconnected(tunnelID string) {
+	if m == nil {
+		return
+	}
+
+	m.reconnects.WithLabelValues(tunnelID).Inc()
+}
+
+func (m *Metrics) observeRTT(tunnelID string, rtt time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.rtt.WithLabelValues(tunnelID).Set(rtt.Seconds())
+}
+
+// forget drops the series of a tunnel which is no longer served by the cluster. Counters are kept, so their rate
+// doesn't jump if the tunnel comes back.
+func (m *Metrics) forget(tunnelID string) {
+	if m == nil {
+		return
+	}
+
+	m.connections.DeleteLabelValues(tunnelID)
+	m.activeStreams.DeleteLabelValues(tunnelID)
+	m.rtt.DeleteLabelValues(tunnelID)
+}
+
+// countBytes returns a connection counting the bytes going through the given connection to the broker.
+func (m *Metrics) countBytes(tunnelID string, conn net.Conn) net.Conn {
+	if m == nil {
+		return conn
+	}
+
+	return &countingConn{
+		Conn: conn,
+		in:   m.bytes.WithLabelValues(tunnelID, "in"),
+		out:  m.bytes.WithLabelValues(tunnelID, "out"),
+	}
+}
+
+// countingConn counts the bytes read from and written to a connection.
+type countingConn struct {
+	net.Conn
+
+	in  prometheus.Counter
+	out prometheus.Counter
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.in.Add(float64(n))
+
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.out.Add(float64(n))
+
+	return n, err
+}
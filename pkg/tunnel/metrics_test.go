@@ -0,0 +1,137 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+)
+
+func TestManager_Ready(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wait := make(chan struct{})
+	ingCtrlServiceURL := createIngCtrlService(t, wait, "mTunnel")
+
+	broker := buildBroker(t, []byte("mTunnel"), "metrics-tunnel")
+	brokerURL, err := url.Parse(broker.URL)
+	require.NoError(t, err)
+
+	client := &clientMock{
+		listClusterTunnelEndpoints: func() ([]Endpoint, error) {
+			return []Endpoint{
+				{
+					TunnelID:       "metrics-tunnel",
+					BrokerEndpoint: "ws://" + brokerURL.Host,
+				},
+			}, nil
+		},
+	}
+
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	manager := NewManager(client, ingCtrlServiceURL, "token", httpclient.DefaultEgress())
+	manager.SetMetrics(metrics)
+	manager.SetDrainTimeout(100 * time.Millisecond)
+
+	// Not ready until the tunnels have been listed and connected.
+	assert.False(t, manager.Ready())
+
+	stopped := make(chan struct{})
+	go func() {
+		manager.Run(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	case <-wait:
+	}
+
+	require.Eventually(t, manager.Ready, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.connections.WithLabelValues("metrics-tunnel")))
+	assert.Positive(t, testutil.ToFloat64(metrics.bytes.WithLabelValues("metrics-tunnel", "in")))
+	assert.Positive(t, testutil.ToFloat64(metrics.bytes.WithLabelValues("metrics-tunnel", "out")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.reconnects.WithLabelValues("metrics-tunnel")))
+
+	cancel()
+	<-stopped
+
+	// The tunnel of the cluster is closed.
+	assert.False(t, manager.Ready())
+}
+
+func TestManager_reconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The broker drops the connections right after they are opened.
+	broker := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		upgrader := &websocket.Upgrader{}
+		websocketConn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		_ = websocketConn.Close()
+	}))
+	t.Cleanup(broker.Close)
+
+	brokerURL, err := url.Parse(broker.URL)
+	require.NoError(t, err)
+
+	client := &clientMock{
+		listClusterTunnelEndpoints: func() ([]Endpoint, error) {
+			return []Endpoint{{TunnelID: "tunnel", BrokerEndpoint: "ws://" + brokerURL.Host}}, nil
+		},
+	}
+
+	metrics, err := NewMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	manager := NewManager(client, "127.0.0.1:9901", "token", httpclient.DefaultEgress())
+	manager.SetMetrics(metrics)
+
+	stopped := make(chan struct{})
+	go func() {
+		manager.Run(ctx)
+		close(stopped)
+	}()
+
+	// The connection is opened again right away, without waiting for the tunnels to be listed again.
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.reconnects.WithLabelValues("tunnel")) >= 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+}
@@ -0,0 +1,268 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// serverNameTimeout is how long the server name of a stream is waited for.
+const serverNameTimeout = 5 * time.Second
+
+// QoSConfig configures the limits of the traffic going through each tunnel, so a single exposed service can't starve
+// the others sharing the tunnel. Zero values disable the limits.
+type QoSConfig struct {
+	// MaxStreams is the maximum number of streams proxied at once through a tunnel.
+	MaxStreams int
+	// Bandwidth is the maximum number of bytes per second going through a tunnel, in each direction.
+	Bandwidth int64
+
+	// EdgeIngressMaxStreams is the maximum number of streams proxied at once for an EdgeIngress. EdgeIngresses are
+	// identified by the server name their clients request, their domain.
+	EdgeIngressMaxStreams int
+	// EdgeIngressBandwidth is the maximum number of bytes per second going through a tunnel for an EdgeIngress, in each
+	// direction.
+	EdgeIngressBandwidth int64
+}
+
+func (c QoSConfig) limitsEdgeIngresses() bool {
+	return c.EdgeIngressMaxStreams > 0 || c.EdgeIngressBandwidth > 0
+}
+
+// Reasons for rejecting a stream.
+const (
+	rejectTunnelStreams      = "tunnel_max_streams"
+	rejectEdgeIngressStreams = "edge_ingress_max_streams"
+)
+
+// qos enforces the QoS limits of a tunnel. A nil qos doesn't limit anything.
+type qos struct {
+	cfg QoSConfig
+
+	in  *tokenBucket
+	out *tokenBucket
+
+	mu            sync.Mutex
+	streams       int
+	edgeIngresses map[string]*edgeIngressQoS
+}
+
+type edgeIngressQoS struct {
+	streams int
+	in      *tokenBucket
+	out     *tokenBucket
+}
+
+func newQoS(cfg QoSConfig) *qos {
+	return &qos{
+		cfg:           cfg,
+		in:            newTokenBucket(cfg.Bandwidth),
+		out:           newTokenBucket(cfg.Bandwidth),
+		edgeIngresses: make(map[string]*edgeIngressQoS),
+	}
+}
+
+// admit admits a stream opened by the broker. It returns the connection to proxy the stream with, limited to the
+// bandwidth of the tunnel and of its EdgeIngress, and a function to call once the stream is closed. It returns the
+// reason the stream is rejected, if it exceeds a concurrency limit.
+func (q *qos) admit(conn net.Conn, udp bool) (net.Conn, func(), string) {
+	if q == nil {
+		return conn, func() {}, ""
+	}
+
+	var serverName string
+	if !udp && q.cfg.limitsEdgeIngresses() {
+		serverName, conn = peekServerName(conn)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.cfg.MaxStreams > 0 && q.streams >= q.cfg.MaxStreams {
+		return nil, nil, rejectTunnelStreams
+	}
+
+	limited := &limitedConn{Conn: conn, in: []*tokenBucket{q.in}, out: []*tokenBucket{q.out}}
+
+	var edgeIng *edgeIngressQoS
+	if serverName != "" {
+		edgeIng = q.edgeIngresses[serverName]
+		if edgeIng == nil {
+			edgeIng = &edgeIngressQoS{
+				in:  newTokenBucket(q.cfg.EdgeIngressBandwidth),
+				out: newTokenBucket(q.cfg.EdgeIngressBandwidth),
+			}
+		}
+
+		if q.cfg.EdgeIngressMaxStreams > 0 && edgeIng.streams >= q.cfg.EdgeIngressMaxStreams {
+			return nil, nil, rejectEdgeIngressStreams
+		}
+
+		edgeIng.streams++
+		q.edgeIngresses[serverName] = edgeIng
+
+		limited.in = append(limited.in, edgeIng.in)
+		limited.out = append(limited.out, edgeIng.out)
+	}
+	q.streams++
+
+	release := func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		q.streams--
+		if edgeIng == nil {
+			return
+		}
+
+		// EdgeIngresses are forgotten once they have no stream left, so they don't pile up.
+		edgeIng.streams--
+		if edgeIng.streams == 0 {
+			delete(q.edgeIngresses, serverName)
+		}
+	}
+
+	return limited, release, ""
+}
+
+// limitedConn limits the bandwidth of a connection to the given token buckets.
+type limitedConn struct {
+	net.Conn
+
+	in  []*tokenBucket
+	out []*tokenBucket
+}
+
+func (c *limitedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	for _, bucket := range c.in {
+		bucket.wait(n)
+	}
+
+	return n, err
+}
+
+func (c *limitedConn) Write(b []byte) (int, error) {
+	for _, bucket := range c.out {
+		bucket.wait(len(b))
+	}
+
+	return c.Conn.Write(b)
+}
+
+// tokenBucket limits a flow of bytes to a rate, allowing bursts of up to a second of traffic. A nil tokenBucket doesn't
+// limit the flow.
+type tokenBucket struct {
+	rate float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(bytesPerSecond int64) *tokenBucket {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	return &tokenBucket{
+		rate:   float64(bytesPerSecond),
+		tokens: float64(bytesPerSecond),
+		last:   time.Now(),
+	}
+}
+
+// wait takes n tokens from the bucket, waiting for them to be available. Tokens are taken right away, so concurrent
+// callers wait in turn.
+func (b *tokenBucket) wait(n int) {
+	if b == nil || n <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	tokens := b.tokens
+	b.mu.Unlock()
+
+	if tokens < 0 {
+		time.Sleep(time.Duration(-tokens / b.rate * float64(time.Second)))
+	}
+}
+
+var errServerNameRead = errors.New("server name read")
+
+// peekServerName returns the server name requested by the TLS ClientHello the connection starts with, if any, along
+// with a connection replaying the peeked bytes.
+func peekServerName(conn net.Conn) (string, net.Conn) {
+	if err := conn.SetReadDeadline(time.Now().Add(serverNameTimeout)); err != nil {
+		return "", conn
+	}
+
+	var (
+		peeked     bytes.Buffer
+		serverName string
+	)
+	_ = tls.Server(&helloConn{Conn: conn, r: io.TeeReader(conn, &peeked)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, errServerNameRead
+		},
+	}).Handshake()
+
+	_ = conn.SetReadDeadline(time.Time{})
+
+	return serverName, &replayConn{Conn: conn, r: io.MultiReader(&peeked, conn)}
+}
+
+// helloConn is a connection the TLS ClientHello is read from. Nothing is written to it.
+type helloConn struct {
+	net.Conn
+
+	r io.Reader
+}
+
+func (c *helloConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *helloConn) Write(_ []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+// replayConn is a connection replaying the bytes read from it before.
+type replayConn struct {
+	net.Conn
+
+	r io.Reader
+}
+
+func (c *replayConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
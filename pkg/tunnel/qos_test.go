@@ -0,0 +1,202 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_peekServerName(t *testing.T) {
+	tests := []struct {
+		desc       string
+		serverName string
+		plain      []byte
+	}{
+		{
+			desc:       "TLS stream",
+			serverName: "api.example.com",
+		},
+		{
+			desc:  "plain stream",
+			plain: []byte("hello world"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var conn net.Conn
+			var want []byte
+			if test.plain != nil {
+				conn = plainStream(t, test.plain)
+				want = test.plain
+			} else {
+				conn = tlsStream(t, test.serverName)
+				// TLS handshake record.
+				want = []byte{0x16}
+			}
+
+			serverName, conn := peekServerName(conn)
+			assert.Equal(t, test.serverName, serverName)
+
+			// The peeked bytes are replayed.
+			got := make([]byte, len(want))
+			_, err := io.ReadFull(conn, got)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func Test_qos_admit(t *testing.T) {
+	tests := []struct {
+		desc        string
+		cfg         QoSConfig
+		serverNames []string
+		wantReject  []string
+	}{
+		{
+			desc:        "no limit",
+			serverNames: []string{"a.example.com", "a.example.com", "a.example.com"},
+			wantReject:  []string{"", "", ""},
+		},
+		{
+			desc:        "tunnel max streams",
+			cfg:         QoSConfig{MaxStreams: 2},
+			serverNames: []string{"a.example.com", "b.example.com", "c.example.com"},
+			wantReject:  []string{"", "", rejectTunnelStreams},
+		},
+		{
+			desc:        "edge ingress max streams",
+			cfg:         QoSConfig{EdgeIngressMaxStreams: 1},
+			serverNames: []string{"a.example.com", "a.example.com", "b.example.com"},
+			wantReject:  []string{"", rejectEdgeIngressStreams, ""},
+		},
+		{
+			desc:        "tunnel limit reached first",
+			cfg:         QoSConfig{MaxStreams: 1, EdgeIngressMaxStreams: 2},
+			serverNames: []string{"a.example.com", "a.example.com"},
+			wantReject:  []string{"", rejectTunnelStreams},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			q := newQoS(test.cfg)
+
+			var releases []func()
+			for i, serverName := range test.serverNames {
+				_, release, rejected := q.admit(tlsStream(t, serverName), false)
+				assert.Equal(t, test.wantReject[i], rejected)
+
+				if release != nil {
+					releases = append(releases, release)
+				}
+			}
+
+			for _, release := range releases {
+				release()
+			}
+
+			// Released streams no longer count.
+			assert.Equal(t, 0, q.streams)
+			assert.Empty(t, q.edgeIngresses)
+
+			_, _, rejected := q.admit(tlsStream(t, test.serverNames[0]), false)
+			assert.Empty(t, rejected)
+		})
+	}
+}
+
+func Test_qos_admit_udp(t *testing.T) {
+	q := newQoS(QoSConfig{EdgeIngressMaxStreams: 1})
+
+	// UDP streams aren't TLS, they are only limited per tunnel.
+	_, _, rejected := q.admit(plainStream(t, []byte("datagram")), true)
+	assert.Empty(t, rejected)
+	_, _, rejected = q.admit(plainStream(t, []byte("datagram")), true)
+	assert.Empty(t, rejected)
+
+	assert.Empty(t, q.edgeIngresses)
+}
+
+func Test_tokenBucket(t *testing.T) {
+	bucket := newTokenBucket(10000)
+
+	// A second of traffic can go through right away.
+	start := time.Now()
+	bucket.wait(10000)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	start = time.Now()
+	bucket.wait(2000)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	// A nil bucket doesn't limit anything.
+	var unlimited *tokenBucket
+	start = time.Now()
+	unlimited.wait(1 << 30)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Nil(t, newTokenBucket(0))
+}
+
+// tlsStream returns a stream on which a TLS client requesting the given server name starts a handshake.
+func tlsStream(t *testing.T, serverName string) net.Conn {
+	t.Helper()
+
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+
+	go func() {
+		_ = tls.Client(client, &tls.Config{ServerName: serverName}).Handshake()
+	}()
+
+	return server
+}
+
+// plainStream returns a stream on which the given bytes are sent.
+func plainStream(t *testing.T, data []byte) net.Conn {
+	t.Helper()
+
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+
+	go func() {
+		_, _ = client.Write(data)
+	}()
+
+	return server
+}
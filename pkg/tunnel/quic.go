@@ -0,0 +1,237 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+const (
+	// quicALPN is the application protocol negotiated with the brokers accepting tunnel connections over QUIC. Brokers
+	// not supporting it fail the handshake, and the connection is opened over WebSocket instead.
+	quicALPN = "traefik-hub-tunnel"
+
+	quicHandshakeTimeout = 5 * time.Second
+	// quicRetryInterval is how long connections to a broker are opened over WebSocket once opening one over QUIC failed.
+	quicRetryInterval = 10 * time.Minute
+
+	// quicStreamRefused is the error code of the streams refused while the connection is drained.
+	quicStreamRefused quic.StreamErrorCode = 1
+)
+
+// errPingUnsupported is returned when measuring the round-trip time over a QUIC connection, which quic-go doesn't expose.
+var errPingUnsupported = errors.New("ping unsupported over QUIC")
+
+// quicHello is sent by the agent on the first stream of a QUIC connection to authenticate, the broker answers with a
+// quicHelloResponse. It plays the role of the WebSocket upgrade request.
+type quicHello struct {
+	TunnelID string `json:"tunnelId"`
+	Token    string `json:"token"`
+}
+
+type quicHelloResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+// dialQUIC opens a connection to the given broker over QUIC, on the UDP port matching the TCP port of the broker.
+func (t *tunnel) dialQUIC(broker string) (session, error) {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, fmt.Errorf("parse broker endpoint: %w", err)
+	}
+
+	// QUIC can't go through the HTTP proxies the egress may be configured with.
+	if t.egress.Proxy != nil {
+		proxyURL, err := t.egress.Proxy(&http.Request{URL: httpURL(u)})
+		if err != nil {
+			return nil, fmt.Errorf("get proxy: %w", err)
+		}
+		if proxyURL != nil {
+			return nil, errors.New("broker reached through a proxy")
+		}
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS13}
+	if t.egress.TLSConfig != nil {
+		tlsConfig = t.egress.TLSConfig.Clone()
+	}
+	tlsConfig.ServerName = u.Hostname()
+	tlsConfig.NextProtos = []string{quicALPN}
+
+	ctx, cancel := context.WithTimeout(context.Background(), quicHandshakeTimeout)
+	defer cancel()
+
+	conn, err := quic.DialAddr(ctx, net.JoinHostPort(u.Hostname(), port), tlsConfig, &quic.Config{
+		HandshakeIdleTimeout: quicHandshakeTimeout,
+		MaxIdleTimeout:       time.Minute,
+		KeepAlivePeriod:      30 * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+
+	if err = t.authenticateQUIC(ctx, conn); err != nil {
+		_ = conn.CloseWithError(0, "")
+		return nil, err
+	}
+
+	return &quicSession{conn: conn}, nil
+}
+
+func (t *tunnel) authenticateQUIC(ctx context.Context, conn quic.Connection) error {
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return fmt.Errorf("open hello stream: %w", err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+
+	if err = json.NewEncoder(stream).Encode(quicHello{TunnelID: t.ID, Token: t.token}); err != nil {
+		return fmt.Errorf("send hello: %w", err)
+	}
+
+	var resp quicHelloResponse
+	if err = json.NewDecoder(stream).Decode(&resp); err != nil {
+		return fmt.Errorf("read hello response: %w", err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("rejected by broker: %s", resp.Error)
+	}
+
+	return nil
+}
+
+// httpURL returns the HTTP URL matching the given WebSocket URL.
+func httpURL(u *url.URL) *url.URL {
+	httpU := *u
+	switch u.Scheme {
+	case "ws":
+		httpU.Scheme = "http"
+	case "wss":
+		httpU.Scheme = "https"
+	}
+
+	return &httpU
+}
+
+// quicSession is a session over a QUIC connection, each stream opened by the broker being a QUIC stream. Unlike
+// streams multiplexed over a WebSocket, a lost packet only delays the stream it belongs to.
+type quicSession struct {
+	conn quic.Connection
+
+	streams atomic.Int32
+	goAway  atomic.Bool
+}
+
+func (s *quicSession) Accept() (net.Conn, error) {
+	for {
+		stream, err := s.conn.AcceptStream(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		// QUIC has no way to tell the broker to stop opening streams: they are refused instead, and retried by the
+		// broker on another connection.
+		if s.goAway.Load() {
+			stream.CancelRead(quicStreamRefused)
+			stream.CancelWrite(quicStreamRefused)
+			continue
+		}
+
+		s.streams.Add(1)
+
+		return &quicConn{
+			Stream: stream,
+			conn:   s.conn,
+			closed: func() { s.streams.Add(-1) },
+		}, nil
+	}
+}
+
+func (s *quicSession) GoAway() error {
+	s.goAway.Store(true)
+	return nil
+}
+
+func (s *quicSession) NumStreams() int {
+	return int(s.streams.Load())
+}
+
+func (s *quicSession) Ping() (time.Duration, error) {
+	return 0, errPingUnsupported
+}
+
+func (s *quicSession) CloseChan() <-chan struct{} {
+	return s.conn.Context().Done()
+}
+
+func (s *quicSession) Close() error {
+	return s.conn.CloseWithError(0, "")
+}
+
+// quicConn exposes a QUIC stream as a net.Conn.
+type quicConn struct {
+	quic.Stream
+
+	conn      quic.Connection
+	closeOnce sync.Once
+	closed    func()
+}
+
+func (c *quicConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *quicConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// CloseWrite shuts down the writing side of the stream, the broker can still send data.
+func (c *quicConn) CloseWrite() error {
+	return c.Stream.Close()
+}
+
+// Close closes both sides of the stream.
+func (c *quicConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.Stream.CancelRead(0)
+		c.closed()
+	})
+
+	return c.Stream.Close()
+}
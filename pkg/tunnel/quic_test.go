@@ -0,0 +1,221 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+)
+
+func TestManager_quic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wait := make(chan struct{})
+	ingCtrlServiceURL := createIngCtrlService(t, wait, "qTunnel")
+
+	serverTLS, roots := selfSignedTLS(t)
+	serverTLS.NextProtos = []string{quicALPN}
+	broker := buildQUICBroker(t, "127.0.0.1:0", serverTLS, []byte("qTunnel"), "quic-tunnel")
+
+	client := &clientMock{
+		listClusterTunnelEndpoints: func() ([]Endpoint, error) {
+			// Nothing listens on the TCP port: the traffic can only go through QUIC.
+			return []Endpoint{
+				{
+					TunnelID:       "quic-tunnel",
+					BrokerEndpoint: "ws://" + broker.Addr().String(),
+				},
+			}, nil
+		},
+	}
+
+	egress := httpclient.Egress{Proxy: http.ProxyFromEnvironment, TLSConfig: &tls.Config{RootCAs: roots}}
+	manager := NewManager(client, ingCtrlServiceURL, "token", egress)
+	manager.SetQUIC(true)
+	manager.SetDrainTimeout(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		manager.Run(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	case <-wait:
+	}
+
+	cancel()
+	<-stopped
+}
+
+func TestManager_quicFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wait := make(chan struct{})
+	ingCtrlServiceURL := createIngCtrlService(t, wait, "wTunnel")
+
+	broker := buildBroker(t, []byte("wTunnel"), "fallback-tunnel")
+	brokerURL, err := url.Parse(broker.URL)
+	require.NoError(t, err)
+
+	// The broker accepts QUIC connections, but not for tunnels.
+	serverTLS, roots := selfSignedTLS(t)
+	serverTLS.NextProtos = []string{"h3"}
+	buildQUICBroker(t, brokerURL.Host, serverTLS, nil, "")
+
+	client := &clientMock{
+		listClusterTunnelEndpoints: func() ([]Endpoint, error) {
+			return []Endpoint{
+				{
+					TunnelID:       "fallback-tunnel",
+					BrokerEndpoint: "ws://" + brokerURL.Host,
+				},
+			}, nil
+		},
+	}
+
+	egress := httpclient.Egress{Proxy: http.ProxyFromEnvironment, TLSConfig: &tls.Config{RootCAs: roots}}
+	manager := NewManager(client, ingCtrlServiceURL, "token", egress)
+	manager.SetQUIC(true)
+	manager.SetDrainTimeout(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		manager.Run(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	case <-wait:
+	}
+
+	// QUIC isn't tried again for a while.
+	manager.tunnelsMu.Lock()
+	tun := manager.tunnels["fallback-tunnel"]
+	manager.tunnelsMu.Unlock()
+	require.NotNil(t, tun)
+	assert.False(t, tun.quicAvailable("ws://"+brokerURL.Host))
+
+	cancel()
+	<-stopped
+}
+
+// buildQUICBroker starts a broker accepting tunnel connections over QUIC, opening a stream on which the given message is
+// sent repeatedly.
+func buildQUICBroker(t *testing.T, addr string, tlsConfig *tls.Config, message []byte, tunnelID string) *quic.Listener {
+	t.Helper()
+
+	listener, err := quic.ListenAddr(addr, tlsConfig, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept(context.Background())
+			if err != nil {
+				return
+			}
+
+			go serveQUICBroker(t, conn, message, tunnelID)
+		}
+	}()
+
+	return listener
+}
+
+func serveQUICBroker(t *testing.T, conn quic.Connection, message []byte, tunnelID string) {
+	t.Helper()
+
+	hello, err := conn.AcceptStream(context.Background())
+	if err != nil {
+		return
+	}
+
+	var req quicHello
+	assert.NoError(t, json.NewDecoder(hello).Decode(&req))
+	assert.Equal(t, quicHello{TunnelID: tunnelID, Token: "token"}, req)
+	assert.NoError(t, json.NewEncoder(hello).Encode(quicHelloResponse{}))
+	_ = hello.Close()
+
+	stream, err := conn.OpenStreamSync(context.Background())
+	if err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-conn.Context().Done():
+			return
+		default:
+			time.Sleep(time.Millisecond)
+			_, _ = stream.Write(message)
+		}
+	}
+}
+
+// selfSignedTLS returns a TLS configuration serving a self-signed certificate for 127.0.0.1, along with the pool
+// trusting it.
+func selfSignedTLS(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "broker"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}, roots
+}
@@ -0,0 +1,83 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"net"
+	"time"
+)
+
+// idleConn calls a function once no byte went through a connection, in either direction, for the idle timeout.
+type idleConn struct {
+	net.Conn
+
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+// withIdleTimeout returns a connection calling onIdle once the given connection is idle for the given timeout, along
+// with a function to call once done with it. A zero timeout disables it.
+func withIdleTimeout(conn net.Conn, timeout time.Duration, onIdle func()) (net.Conn, func()) {
+	if timeout <= 0 {
+		return conn, func() {}
+	}
+
+	c := &idleConn{
+		Conn:    conn,
+		timeout: timeout,
+		timer:   time.AfterFunc(timeout, onIdle),
+	}
+
+	return c, func() { c.timer.Stop() }
+}
+
+func (c *idleConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.timer.Reset(c.timeout)
+	}
+
+	return n, err
+}
+
+func (c *idleConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.timer.Reset(c.timeout)
+	}
+
+	return n, err
+}
+
+// closeWrite shuts down the writing side of a connection, so its peer reads EOF while it can still write back, like a
+// gRPC server answering once the client is done streaming. Closing a tunnel stream only shuts down its writing side.
+func closeWrite(conn net.Conn) error {
+	if c, ok := conn.(interface{ CloseWrite() error }); ok {
+		return c.CloseWrite()
+	}
+
+	return conn.Close()
+}
+
+// abort interrupts the pending reads and writes of the given connections. Unlike closing a tunnel stream, which only
+// shuts down its writing side, it stops the copies from and to it right away.
+func abort(conns ...net.Conn) {
+	for _, conn := range conns {
+		_ = conn.SetDeadline(time.Now())
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/yamux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func Test_proxy_halfClose(t *testing.T) {
+	// The service answers once the client is done sending, like a gRPC client stream.
+	service, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = service.Close() })
+
+	go func() {
+		conn, aerr := service.Accept()
+		if aerr != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		request, _ := io.ReadAll(conn)
+		for _, part := range strings.Split(string(request), ",") {
+			time.Sleep(20 * time.Millisecond)
+			_, _ = conn.Write([]byte(strings.ToUpper(part)))
+		}
+	}()
+
+	client, err := net.Dial("tcp", proxyListener(t, service.Addr().String(), 0))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	_, err = client.Write([]byte("a,b,c"))
+	require.NoError(t, err)
+	require.NoError(t, client.(*net.TCPConn).CloseWrite())
+
+	// The answer still flows back once the client is done sending.
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(5*time.Second)))
+	response, err := io.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", string(response))
+}
+
+func Test_proxy_idleTimeout(t *testing.T) {
+	service, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = service.Close() })
+
+	go func() {
+		conn, aerr := service.Accept()
+		if aerr != nil {
+			return
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+	}()
+
+	// Neither end sends anything nor closes the stream.
+	_, agentStream := streamPair(t)
+
+	proxyErr := make(chan error, 1)
+	go func() { proxyErr <- proxy(agentStream, service.Addr().String(), 100*time.Millisecond) }()
+
+	select {
+	case err = <-proxyErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func Test_proxy_websocket(t *testing.T) {
+	var upgrader websocket.Upgrader
+	service := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(rw, req, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		for {
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(typ, msg); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(service.Close)
+
+	addr := proxyListener(t, strings.TrimPrefix(service.URL, "http://"), 300*time.Millisecond)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws://"+addr, nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// The connection outlives the idle timeout as long as messages are exchanged.
+	for i := 0; i < 6; i++ {
+		time.Sleep(100 * time.Millisecond)
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("ping")))
+
+		_, msg, rerr := conn.ReadMessage()
+		require.NoError(t, rerr)
+		assert.Equal(t, "ping", string(msg))
+	}
+
+	// Once idle, it's closed.
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseAbnormalClosure))
+}
+
+func Test_proxy_grpcStream(t *testing.T) {
+	service, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthServer := health.NewServer()
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go func() { _ = server.Serve(service) }()
+	t.Cleanup(server.Stop)
+
+	addr := proxyListener(t, service.Addr().String(), 0)
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	watch, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	// The status updates are streamed through the tunnel as they happen.
+	resp, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	resp, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
+
+// streamPair returns both ends of a tunnel stream opened by the broker.
+func streamPair(t *testing.T) (broker, agent net.Conn) {
+	t.Helper()
+
+	clientConn, serverConn := net.Pipe()
+
+	cfg := yamux.DefaultConfig()
+	cfg.LogOutput = io.Discard
+
+	client, err := yamux.Client(clientConn, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	server, err := yamux.Server(serverConn, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+
+	broker, err = server.Open()
+	require.NoError(t, err)
+
+	agent, err = client.Accept()
+	require.NoError(t, err)
+
+	return broker, agent
+}
+
+// proxyListener proxies the connections made to the returned address to the given one.
+func proxyListener(t *testing.T, target string, idleTimeout time.Duration) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() { _ = proxy(conn, target, idleTimeout) }()
+		}
+	}()
+
+	return listener.Addr().String()
+}
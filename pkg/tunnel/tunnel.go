@@ -0,0 +1,405 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/yamux"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// rttInterval is the interval at which the round-trip time to the brokers is measured.
+	rttInterval = 30 * time.Second
+
+	// defaultDrainTimeout is how long the connections of a closed tunnel are kept open for their streams to complete.
+	defaultDrainTimeout = 30 * time.Second
+	drainPollInterval   = 100 * time.Millisecond
+
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// session is a connection opened to a broker, on which the broker opens a stream for each connection to proxy to the
+// cluster.
+type session interface {
+	// Accept waits for the next stream opened by the broker.
+	Accept() (net.Conn, error)
+	// GoAway stops the broker from opening new streams.
+	GoAway() error
+	// NumStreams returns the number of opened streams.
+	NumStreams() int
+	// Ping returns the round-trip time to the broker.
+	Ping() (time.Duration, error)
+	// CloseChan returns a channel closed once the session is closed.
+	CloseChan() <-chan struct{}
+	Close() error
+}
+
+// tunnel forwards the traffic its brokers receive to the cluster. It keeps a set of connections opened to its brokers,
+// spread over them, and reconnects each connection to the next broker when it's lost or the broker is unreachable.
+type tunnel struct {
+	ID             string
+	BrokerEndpoint string
+	// FallbackBrokerEndpoints are the brokers the connections fail over to when the broker endpoint is unreachable.
+	FallbackBrokerEndpoints []string
+	ClusterEndpoint         string
+	Protocol                string
+
+	token        string
+	egress       httpclient.Egress
+	metrics      *Metrics
+	drainTimeout time.Duration
+	qos          *qos
+	idleTimeout  time.Duration
+	quic         bool
+
+	mu     sync.Mutex
+	closed bool
+	stop   chan struct{}
+	conns  map[int]session
+	// quicFailures holds when opening a connection over QUIC last failed, by broker.
+	quicFailures map[string]time.Time
+
+	// connected is the number of connections opened to a broker.
+	connected atomic.Int32
+	wg        sync.WaitGroup
+}
+
+// serves returns whether the tunnel serves the given endpoint, through the same brokers.
+func (t *tunnel) serves(endpoint Endpoint) bool {
+	return t.BrokerEndpoint == endpoint.BrokerEndpoint &&
+		slices.Equal(t.FallbackBrokerEndpoints, endpoint.FallbackBrokerEndpoints)
+}
+
+// start opens the given number of connections to the brokers.
+func (t *tunnel) start(connections int) {
+	t.mu.Lock()
+	t.stop = make(chan struct{})
+	t.conns = make(map[int]session)
+	t.quicFailures = make(map[string]time.Time)
+	t.mu.Unlock()
+
+	for i := 0; i < connections; i++ {
+		t.wg.Add(1)
+		go t.runConnection(i)
+	}
+}
+
+// Close stops the brokers from opening new streams on the tunnel connections, and closes them once their streams are
+// completed or the drain timeout elapsed. It doesn't wait for the connections to be closed.
+func (t *tunnel) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return
+	}
+	t.closed = true
+
+	if t.stop != nil {
+		close(t.stop)
+	}
+
+	for _, sess := range t.conns {
+		t.wg.Add(1)
+		go func(sess session) {
+			defer t.wg.Done()
+			drain(sess, t.drainTimeout)
+		}(sess)
+	}
+}
+
+// wait waits for the connections of a closed tunnel to be closed.
+func (t *tunnel) wait() {
+	t.wg.Wait()
+}
+
+// runConnection keeps the i-th connection of the tunnel opened until the tunnel is closed. Connections start with
+// different brokers, so they are spread over them.
+func (t *tunnel) runConnection(i int) {
+	defer t.wg.Done()
+
+	brokers := append([]string{t.BrokerEndpoint}, t.FallbackBrokerEndpoints...)
+
+	logger := log.With().Str("tunnel_id", t.ID).Int("connection", i).Logger()
+
+	var (
+		opened   bool
+		failures int
+		backoff  = minReconnectBackoff
+	)
+	for {
+		broker := brokers[(i+failures)%len(brokers)]
+
+		session, err := t.dial(broker)
+		if err != nil {
+			logger.Error().Err(err).Str("broker_endpoint", broker).Msg("Unable to open tunnel connection")
+
+			// Fail over to the next broker right away, and wait before trying them again once they all failed.
+			failures++
+			if failures%len(brokers) == 0 {
+				if !t.sleep(backoff) {
+					return
+				}
+				backoff *= 2
+				if backoff > maxReconnectBackoff {
+					backoff = maxReconnectBackoff
+				}
+			}
+			continue
+		}
+
+		if !t.track(i, session) {
+			_ = session.Close()
+			return
+		}
+
+		if opened {
+			t.metrics.reconnected(t.ID)
+		}
+		opened = true
+		failures, backoff = 0, minReconnectBackoff
+
+		err = t.serve(session)
+		t.untrack(i)
+
+		if t.isClosed() {
+			return
+		}
+
+		logger.Warn().Err(err).Str("broker_endpoint", broker).Msg("Tunnel connection lost, reconnecting")
+	}
+}
+
+// dial opens a connection to the given broker, over QUIC if enabled and supported by the broker, over WebSocket
+// otherwise.
+func (t *tunnel) dial(broker string) (session, error) {
+	if t.quic && t.quicAvailable(broker) {
+		session, err := t.dialQUIC(broker)
+		if err == nil {
+			return session, nil
+		}
+
+		log.Debug().
+			Err(err).
+			Str("tunnel_id", t.ID).
+			Str("broker_endpoint", broker).
+			Msg("Unable to open tunnel connection over QUIC, falling back to WebSocket")
+		t.quicFailed(broker)
+	}
+
+	return t.dialWebSocket(broker)
+}
+
+// quicAvailable returns whether opening a connection over QUIC to the given broker is worth trying: it isn't for a
+// while once it failed, so unsupported or blocked QUIC doesn't slow down every reconnection.
+func (t *tunnel) quicAvailable(broker string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	failedAt, ok := t.quicFailures[broker]
+
+	return !ok || time.Since(failedAt) > quicRetryInterval
+}
+
+func (t *tunnel) quicFailed(broker string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.quicFailures[broker] = time.Now()
+}
+
+func (t *tunnel) dialWebSocket(broker string) (session, error) {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, fmt.Errorf("parse broker endpoint: %w", err)
+	}
+	u.Path = path.Join(u.Path, t.ID)
+
+	dialer := websocket.Dialer{
+		Proxy:            t.egress.Proxy,
+		TLSClientConfig:  t.egress.TLSConfig,
+		HandshakeTimeout: 30 * time.Second,
+	}
+	connSocket, resp, err := dialer.Dial(u.String(), http.Header{"Authorization": []string{"Bearer " + t.token}})
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		_ = connSocket.Close()
+		return nil, fmt.Errorf("expected protocol switching, got: %d", resp.StatusCode)
+	}
+
+	conn := t.metrics.countBytes(t.ID, &websocketNetConn{
+		Conn: connSocket,
+	})
+
+	cfg := &yamux.Config{
+		AcceptBacklog:          256,
+		EnableKeepAlive:        true,
+		KeepAliveInterval:      30 * time.Second,
+		ConnectionWriteTimeout: 10 * time.Second,
+		MaxStreamWindowSize:    256 * 1024,
+		StreamOpenTimeout:      75 * time.Second,
+		StreamCloseTimeout:     5 * time.Minute,
+		LogOutput:              io.Discard,
+	}
+	session, err := yamux.Client(conn, cfg)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("new yamux client: %w", err)
+	}
+
+	return session, nil
+}
+
+// serve proxies the streams opened by the broker on the session to the cluster, until the session is closed.
+func (t *tunnel) serve(session session) error {
+	go pingBroker(session, t.ID, t.metrics)
+
+	for {
+		brokerConn, err := session.Accept()
+		if err != nil {
+			return fmt.Errorf("accept: %w", err)
+		}
+
+		go func(brokerConn net.Conn) {
+			udp := t.Protocol == ProtocolUDP
+
+			conn, release, rejected := t.qos.admit(brokerConn, udp)
+			if rejected != "" {
+				log.Debug().
+					Str("tunnel_id", t.ID).
+					Str("reason", rejected).
+					Msg("Stream rejected: too many concurrent streams")
+				t.metrics.streamRejected(t.ID, rejected)
+
+				_ = brokerConn.Close()
+				return
+			}
+			defer release()
+
+			t.metrics.streamOpened(t.ID)
+			defer t.metrics.streamClosed(t.ID)
+
+			proxyConn := proxy
+			if udp {
+				proxyConn = proxyUDP
+			}
+
+			if err := proxyConn(conn, t.ClusterEndpoint, t.idleTimeout); err != nil {
+				log.Error().Err(err).Msg("Unable to proxy the tunnel traffic to the cluster endpoint")
+			}
+		}(brokerConn)
+	}
+}
+
+// track records the opened session of the i-th connection. It returns false if the tunnel has been closed meanwhile.
+func (t *tunnel) track(i int, session session) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return false
+	}
+
+	t.conns[i] = session
+	t.connected.Add(1)
+	t.metrics.connectionOpened(t.ID)
+
+	return true
+}
+
+func (t *tunnel) untrack(i int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.conns, i)
+	t.connected.Add(-1)
+	t.metrics.connectionClosed(t.ID)
+}
+
+func (t *tunnel) isClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.closed
+}
+
+// sleep waits for the given duration. It returns false if the tunnel has been closed meanwhile.
+func (t *tunnel) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-t.stop:
+		return false
+	}
+}
+
+// drain stops the broker from opening new streams on the session, and closes it once its streams are completed or the
+// timeout elapsed.
+func drain(session session, timeout time.Duration) {
+	_ = session.GoAway()
+
+	deadline := time.Now().Add(timeout)
+	for session.NumStreams() > 0 && time.Now().Before(deadline) {
+		time.Sleep(drainPollInterval)
+	}
+
+	_ = session.Close()
+}
+
+// pingBroker measures the round-trip time to the broker until the session is closed.
+func pingBroker(session session, tunnelID string, metrics *Metrics) {
+	if metrics == nil {
+		return
+	}
+
+	ticker := time.NewTicker(rttInterval)
+	defer ticker.Stop()
+
+	for {
+		rtt, err := session.Ping()
+		if err == nil {
+			metrics.observeRTT(tunnelID, rtt)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-session.CloseChan():
+			return
+		}
+	}
+}
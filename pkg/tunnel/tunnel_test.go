@@ -0,0 +1,180 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/yamux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+)
+
+func TestManager_failover(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wait := make(chan struct{})
+	ingCtrlServiceURL := createIngCtrlService(t, wait, "fTunnel")
+
+	fallbackBroker := buildBroker(t, []byte("fTunnel"), "failover-tunnel")
+	fallbackBrokerURL, err := url.Parse(fallbackBroker.URL)
+	require.NoError(t, err)
+
+	client := &clientMock{
+		listClusterTunnelEndpoints: func() ([]Endpoint, error) {
+			return []Endpoint{
+				{
+					TunnelID:                "failover-tunnel",
+					BrokerEndpoint:          "ws://127.0.0.1:1",
+					FallbackBrokerEndpoints: []string{"ws://" + fallbackBrokerURL.Host},
+				},
+			}, nil
+		},
+	}
+
+	manager := NewManager(client, ingCtrlServiceURL, "token", httpclient.DefaultEgress())
+	manager.SetDrainTimeout(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		manager.Run(ctx)
+		close(stopped)
+	}()
+
+	// The traffic goes through the fallback broker, the broker endpoint being unreachable.
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	case <-wait:
+	}
+
+	cancel()
+	<-stopped
+}
+
+func TestManager_multipleConnections(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wait := make(chan struct{})
+	ingCtrlServiceURL := createIngCtrlService(t, wait, "aTunnel", "bTunnel")
+
+	brokerA := buildBroker(t, []byte("aTunnel"), "multi-tunnel")
+	brokerAURL, err := url.Parse(brokerA.URL)
+	require.NoError(t, err)
+
+	brokerB := buildBroker(t, []byte("bTunnel"), "multi-tunnel")
+	brokerBURL, err := url.Parse(brokerB.URL)
+	require.NoError(t, err)
+
+	client := &clientMock{
+		listClusterTunnelEndpoints: func() ([]Endpoint, error) {
+			return []Endpoint{
+				{
+					TunnelID:                "multi-tunnel",
+					BrokerEndpoint:          "ws://" + brokerAURL.Host,
+					FallbackBrokerEndpoints: []string{"ws://" + brokerBURL.Host},
+				},
+			}, nil
+		},
+	}
+
+	manager := NewManager(client, ingCtrlServiceURL, "token", httpclient.DefaultEgress())
+	manager.SetConnections(2)
+	manager.SetDrainTimeout(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		manager.Run(ctx)
+		close(stopped)
+	}()
+
+	// The connections are spread over both brokers.
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	case <-wait:
+	}
+
+	require.Eventually(t, func() bool {
+		manager.tunnelsMu.Lock()
+		defer manager.tunnelsMu.Unlock()
+
+		return manager.tunnels["multi-tunnel"].connected.Load() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+}
+
+func Test_drain(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+
+	cfg := yamux.DefaultConfig()
+	cfg.LogOutput = io.Discard
+
+	client, err := yamux.Client(clientConn, cfg)
+	require.NoError(t, err)
+	server, err := yamux.Server(serverConn, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+
+	stream, err := server.Open()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	brokerConn, err := client.Accept()
+	require.NoError(t, err)
+
+	drained := make(chan struct{})
+	go func() {
+		drain(client, 5*time.Second)
+		close(drained)
+	}()
+
+	// The broker can't open new streams, but the opened one is kept until it's closed.
+	require.Eventually(t, func() bool {
+		_, openErr := server.Open()
+		return openErr != nil
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-drained:
+		t.Fatal("session closed while a stream is opened")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, stream.Close())
+	require.NoError(t, brokerConn.Close())
+
+	select {
+	case <-drained:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	assert.True(t, client.IsClosed())
+}
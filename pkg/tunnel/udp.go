@@ -0,0 +1,113 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// ProtocolUDP is the protocol of the tunnels carrying UDP traffic.
+const ProtocolUDP = "udp"
+
+// maxDatagramSize is the maximum size of a UDP datagram payload.
+const maxDatagramSize = 65535
+
+// proxyUDP proxies the datagrams carried by a tunnel stream to the given UDP address, and the datagrams received in
+// response back to the stream. As a stream carries bytes, each datagram is prefixed with its size on 2 bytes, in
+// big-endian. The stream is aborted once idle for the given timeout, unless zero.
+func proxyUDP(sourceConn net.Conn, addr string, idleTimeout time.Duration) error {
+	targetConn, err := net.Dial("udp", addr)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+
+	var idle atomic.Bool
+	conn, stop := withIdleTimeout(sourceConn, idleTimeout, func() {
+		idle.Store(true)
+		abort(sourceConn, targetConn)
+	})
+	defer stop()
+
+	errCh := make(chan error, 2)
+
+	go func() { errCh <- writeDatagrams(targetConn, conn) }()
+	go func() { errCh <- readDatagrams(conn, targetConn) }()
+
+	err = <-errCh
+
+	// Aborting the stream and closing the UDP connection stops the other copy.
+	abort(sourceConn)
+	_ = sourceConn.Close()
+	_ = targetConn.Close()
+	<-errCh
+
+	if idle.Load() {
+		return nil
+	}
+
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return fmt.Errorf("copy datagrams: %w", err)
+	}
+
+	return nil
+}
+
+// writeDatagrams writes the datagrams framed on src to dst, until src is closed.
+func writeDatagrams(dst io.Writer, src io.Reader) error {
+	buf := make([]byte, maxDatagramSize)
+	for {
+		var size uint16
+		if err := binary.Read(src, binary.BigEndian, &size); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read datagram size: %w", err)
+		}
+
+		if _, err := io.ReadFull(src, buf[:size]); err != nil {
+			return fmt.Errorf("read datagram: %w", err)
+		}
+
+		if _, err := dst.Write(buf[:size]); err != nil {
+			return fmt.Errorf("write datagram: %w", err)
+		}
+	}
+}
+
+// readDatagrams reads the datagrams received on src and writes them framed to dst, until src is closed.
+func readDatagrams(dst io.Writer, src io.Reader) error {
+	buf := make([]byte, 2+maxDatagramSize)
+	for {
+		n, err := src.Read(buf[2:])
+		if err != nil {
+			return fmt.Errorf("read datagram: %w", err)
+		}
+
+		binary.BigEndian.PutUint16(buf[:2], uint16(n))
+
+		if _, err = dst.Write(buf[:2+n]); err != nil {
+			return fmt.Errorf("write datagram: %w", err)
+		}
+	}
+}
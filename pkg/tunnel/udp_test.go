@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_proxyUDP(t *testing.T) {
+	echoConn, err := net.ListenPacket("udp", net.JoinHostPort("127.0.0.1", "0"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = echoConn.Close() })
+
+	// Start a UDP echo server.
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, addr, rerr := echoConn.ReadFrom(buf)
+			if rerr != nil {
+				return
+			}
+
+			if _, werr := echoConn.WriteTo(buf[:n], addr); werr != nil {
+				return
+			}
+		}
+	}()
+
+	streamConn, proxyConn := net.Pipe()
+
+	proxyErr := make(chan error, 1)
+	go func() {
+		proxyErr <- proxyUDP(proxyConn, echoConn.LocalAddr().String(), 0)
+	}()
+
+	err = streamConn.SetDeadline(time.Now().Add(time.Second))
+	require.NoError(t, err)
+
+	for _, message := range []string{"hello", "world"} {
+		frame := binary.BigEndian.AppendUint16(nil, uint16(len(message)))
+		_, err = streamConn.Write(append(frame, message...))
+		require.NoError(t, err)
+
+		var size uint16
+		err = binary.Read(streamConn, binary.BigEndian, &size)
+		require.NoError(t, err)
+
+		received := make([]byte, size)
+		_, err = io.ReadFull(streamConn, received)
+		require.NoError(t, err)
+
+		assert.Equal(t, message, string(received))
+	}
+
+	require.NoError(t, streamConn.Close())
+
+	select {
+	case err = <-proxyErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("proxy did not stop")
+	}
+}
+
+func Test_proxyUDP_invalidAddress(t *testing.T) {
+	streamConn, proxyConn := net.Pipe()
+	t.Cleanup(func() { _ = streamConn.Close() })
+
+	err := proxyUDP(proxyConn, "invalid", 0)
+	require.Error(t, err)
+}
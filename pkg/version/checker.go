@@ -28,8 +28,60 @@ import (
 	"github.com/google/go-github/v47/github"
 	goversion "github.com/hashicorp/go-version"
 	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 )
 
+// Release channels.
+const (
+	// ChannelStable only offers the final releases.
+	ChannelStable = "stable"
+	// ChannelBeta also offers the pre-releases, such as release candidates.
+	ChannelBeta = "beta"
+)
+
+// Update policies, defining the releases the agent is notified of.
+const (
+	// UpdatePolicyMajor notifies of all the newer releases.
+	UpdatePolicyMajor = "major"
+	// UpdatePolicyMinor notifies of the newer releases of the current major version.
+	UpdatePolicyMinor = "minor"
+	// UpdatePolicyPatch notifies of the newer releases of the current minor version.
+	UpdatePolicyPatch = "patch"
+	// UpdatePolicyNone disables the notifications, the version is still reported to the platform.
+	UpdatePolicyNone = "none"
+)
+
+// ReasonUpdateAvailable is the reason of the Kubernetes events recorded when a newer version is available.
+const ReasonUpdateAvailable = "UpdateAvailable"
+
+// CheckerConfig configures a Checker.
+type CheckerConfig struct {
+	// Channel is the release channel the newer versions are looked for in: stable or beta.
+	Channel string
+	// UpdatePolicy defines the newer versions the agent is notified of: major, minor, patch or none.
+	UpdatePolicy string
+}
+
+// Validate validates the configuration.
+func (c CheckerConfig) Validate() error {
+	switch c.Channel {
+	case ChannelStable, ChannelBeta:
+	default:
+		return fmt.Errorf("unsupported release channel %q, must be one of %q or %q", c.Channel, ChannelStable, ChannelBeta)
+	}
+
+	switch c.UpdatePolicy {
+	case UpdatePolicyMajor, UpdatePolicyMinor, UpdatePolicyPatch, UpdatePolicyNone:
+	default:
+		return fmt.Errorf("unsupported update policy %q, must be one of %q, %q, %q or %q",
+			c.UpdatePolicy, UpdatePolicyMajor, UpdatePolicyMinor, UpdatePolicyPatch, UpdatePolicyNone)
+	}
+
+	return nil
+}
+
 type clusterService interface {
 	SetVersionStatus(ctx context.Context, state Status) error
 }
@@ -39,6 +91,7 @@ type Status struct {
 	UpToDate       bool   `json:"upToDate,omitempty"`
 	CurrentVersion string `json:"currentVersion,omitempty"`
 	LatestVersion  string `json:"latestVersion,omitempty"`
+	Channel        string `json:"channel,omitempty"`
 }
 
 // addHeaderTransport allows to add header to http request.
@@ -67,21 +120,33 @@ type Checker struct {
 	cluster clusterService
 	github  *github.Client
 	version string
+	cfg     CheckerConfig
+
+	recorder record.EventRecorder
+	object   runtime.Object
 }
 
 // NewChecker returns a new Checker.
-func NewChecker(cluster clusterService) *Checker {
+func NewChecker(cluster clusterService, cfg CheckerConfig) *Checker {
 	baseURL, _ := url.Parse("https://update.traefik.io/")
 
 	return &Checker{
 		cluster: cluster,
 		github:  newGitHubClient(baseURL),
 		version: version,
+		cfg:     cfg,
 	}
 }
 
+// SetEventRecorder makes the checker record an event on the given object, typically the agent pod, when a newer
+// version is available.
+func (c *Checker) SetEventRecorder(recorder record.EventRecorder, object runtime.Object) {
+	c.recorder = recorder
+	c.object = object
+}
+
 // Start starts the check of the agent version.
-func (c Checker) Start(ctx context.Context) error {
+func (c *Checker) Start(ctx context.Context) error {
 	tick := time.NewTicker(24 * time.Hour)
 	defer tick.Stop()
 
@@ -105,7 +170,7 @@ func (c Checker) Start(ctx context.Context) error {
 }
 
 // check Checks if a new version is available.
-func (c Checker) check(ctx context.Context) error {
+func (c *Checker) check(ctx context.Context) error {
 	if c.version == defaultVersion {
 		return nil
 	}
@@ -120,14 +185,19 @@ func (c Checker) check(ctx context.Context) error {
 		return fmt.Errorf("set version status: %w", err)
 	}
 
-	if !status.UpToDate {
+	if !status.UpToDate && c.cfg.UpdatePolicy != UpdatePolicyNone {
+		if c.recorder != nil {
+			c.recorder.Eventf(c.object, corev1.EventTypeNormal, ReasonUpdateAvailable,
+				"Version %s is available on the %s channel, the agent runs %s", status.LatestVersion, status.Channel, status.CurrentVersion)
+		}
+
 		return fmt.Errorf("you are using %s version of the agent, please consider upgrading to %s", status.CurrentVersion, status.LatestVersion)
 	}
 
 	return nil
 }
 
-func (c Checker) getStatus(ctx context.Context) (Status, error) {
+func (c *Checker) getStatus(ctx context.Context) (Status, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -142,25 +212,29 @@ func (c Checker) getStatus(ctx context.Context) (Status, error) {
 		return Status{}, fmt.Errorf("list tags: %s", string(all))
 	}
 
-	latestVersion, err := goversion.NewSemver(tags[0].GetName())
-	if err != nil {
-		return Status{}, fmt.Errorf("parse version: %w", err)
-	}
+	// not a valid tag, any release of the channel is newer.
+	currentVersion, _ := goversion.NewSemver(c.version)
+
+	latestVersion := c.latestVersion(tags, currentVersion)
+	if latestVersion == nil {
+		if currentVersion == nil {
+			return Status{}, fmt.Errorf("no release found on the %s channel", c.cfg.Channel)
+		}
 
-	currentVersion, err := goversion.NewSemver(c.version)
-	// not a valid tag.
-	if err != nil {
 		return Status{
+			UpToDate:       true,
 			CurrentVersion: c.version,
-			LatestVersion:  latestVersion.Original(),
+			LatestVersion:  c.version,
+			Channel:        c.cfg.Channel,
 		}, nil
 	}
 
 	// outdated version.
-	if latestVersion.GreaterThan(currentVersion) {
+	if currentVersion == nil || latestVersion.GreaterThan(currentVersion) {
 		return Status{
 			CurrentVersion: c.version,
 			LatestVersion:  latestVersion.Original(),
+			Channel:        c.cfg.Channel,
 		}, nil
 	}
 
@@ -168,5 +242,47 @@ func (c Checker) getStatus(ctx context.Context) (Status, error) {
 		UpToDate:       true,
 		CurrentVersion: c.version,
 		LatestVersion:  c.version,
+		Channel:        c.cfg.Channel,
 	}, nil
 }
+
+// latestVersion returns the latest release of the configured channel the update policy allows upgrading the given
+// current version to, nil if there is none. All the releases of the channel are allowed when the current version is
+// unknown.
+func (c *Checker) latestVersion(tags []*github.RepositoryTag, current *goversion.Version) *goversion.Version {
+	var latest *goversion.Version
+	for _, tag := range tags {
+		v, err := goversion.NewSemver(tag.GetName())
+		if err != nil {
+			continue
+		}
+
+		if v.Prerelease() != "" && c.cfg.Channel != ChannelBeta {
+			continue
+		}
+
+		if current != nil && !c.allowed(current, v) {
+			continue
+		}
+
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+
+	return latest
+}
+
+// allowed reports whether the update policy allows upgrading from the current version to the given one.
+func (c *Checker) allowed(current, v *goversion.Version) bool {
+	currentSegments, segments := current.Segments(), v.Segments()
+
+	switch c.cfg.UpdatePolicy {
+	case UpdatePolicyMinor:
+		return segments[0] == currentSegments[0]
+	case UpdatePolicyPatch:
+		return segments[0] == currentSegments[0] && segments[1] == currentSegments[1]
+	default:
+		return true
+	}
+}
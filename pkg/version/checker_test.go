@@ -28,27 +28,151 @@ import (
 	"github.com/google/go-github/v47/github"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestChecker_check(t *testing.T) {
 	tests := []struct {
 		desc string
 
-		version  string
-		upToDate bool
+		version string
+		tags    []string
+		cfg     CheckerConfig
+
+		wantStatus Status
+		wantEvent  string
 	}{
 		{
-			desc:     "Notify Hub platform when cluster is up to date",
-			version:  "v0.5.0",
-			upToDate: true,
+			desc:    "Notify Hub platform when cluster is up to date",
+			version: "v0.5.0",
+			tags:    []string{"v0.5.0", "v0.4.0"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyMajor},
+			wantStatus: Status{
+				UpToDate:       true,
+				CurrentVersion: "v0.5.0",
+				LatestVersion:  "v0.5.0",
+				Channel:        ChannelStable,
+			},
 		},
 		{
 			desc:    "Notify Hub platform when cluster is outdated",
 			version: "v0.4.0",
+			tags:    []string{"v0.5.0", "v0.4.0"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyMajor},
+			wantStatus: Status{
+				CurrentVersion: "v0.4.0",
+				LatestVersion:  "v0.5.0",
+				Channel:        ChannelStable,
+			},
+			wantEvent: "Normal UpdateAvailable Version v0.5.0 is available on the stable channel, the agent runs v0.4.0",
 		},
 		{
 			desc:    "Notify Hub platform when cluster not use a tag",
 			version: "8712d4f",
+			tags:    []string{"v0.5.0", "v0.4.0"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyMajor},
+			wantStatus: Status{
+				CurrentVersion: "8712d4f",
+				LatestVersion:  "v0.5.0",
+				Channel:        ChannelStable,
+			},
+			wantEvent: "Normal UpdateAvailable Version v0.5.0 is available on the stable channel, the agent runs 8712d4f",
+		},
+		{
+			desc:    "Tags are not listed in semver order",
+			version: "v0.4.0",
+			tags:    []string{"v0.9.0", "v0.10.0", "latest"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyMajor},
+			wantStatus: Status{
+				CurrentVersion: "v0.4.0",
+				LatestVersion:  "v0.10.0",
+				Channel:        ChannelStable,
+			},
+			wantEvent: "Normal UpdateAvailable Version v0.10.0 is available on the stable channel, the agent runs v0.4.0",
+		},
+		{
+			desc:    "Pre-releases are ignored on the stable channel",
+			version: "v0.5.0",
+			tags:    []string{"v0.6.0-rc.1", "v0.5.0"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyMajor},
+			wantStatus: Status{
+				UpToDate:       true,
+				CurrentVersion: "v0.5.0",
+				LatestVersion:  "v0.5.0",
+				Channel:        ChannelStable,
+			},
+		},
+		{
+			desc:    "Pre-releases are offered on the beta channel",
+			version: "v0.5.0",
+			tags:    []string{"v0.6.0-rc.1", "v0.5.0"},
+			cfg:     CheckerConfig{Channel: ChannelBeta, UpdatePolicy: UpdatePolicyMajor},
+			wantStatus: Status{
+				CurrentVersion: "v0.5.0",
+				LatestVersion:  "v0.6.0-rc.1",
+				Channel:        ChannelBeta,
+			},
+			wantEvent: "Normal UpdateAvailable Version v0.6.0-rc.1 is available on the beta channel, the agent runs v0.5.0",
+		},
+		{
+			desc:    "Final release is newer than its pre-releases",
+			version: "v0.6.0-rc.1",
+			tags:    []string{"v0.6.0", "v0.6.0-rc.1"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyMajor},
+			wantStatus: Status{
+				CurrentVersion: "v0.6.0-rc.1",
+				LatestVersion:  "v0.6.0",
+				Channel:        ChannelStable,
+			},
+			wantEvent: "Normal UpdateAvailable Version v0.6.0 is available on the stable channel, the agent runs v0.6.0-rc.1",
+		},
+		{
+			desc:    "Minor update policy ignores new major versions",
+			version: "v1.2.0",
+			tags:    []string{"v2.0.0", "v1.3.1", "v1.3.0", "v1.2.0"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyMinor},
+			wantStatus: Status{
+				CurrentVersion: "v1.2.0",
+				LatestVersion:  "v1.3.1",
+				Channel:        ChannelStable,
+			},
+			wantEvent: "Normal UpdateAvailable Version v1.3.1 is available on the stable channel, the agent runs v1.2.0",
+		},
+		{
+			desc:    "Patch update policy ignores new minor versions",
+			version: "v1.2.0",
+			tags:    []string{"v2.0.0", "v1.3.0", "v1.2.1", "v1.2.0"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyPatch},
+			wantStatus: Status{
+				CurrentVersion: "v1.2.0",
+				LatestVersion:  "v1.2.1",
+				Channel:        ChannelStable,
+			},
+			wantEvent: "Normal UpdateAvailable Version v1.2.1 is available on the stable channel, the agent runs v1.2.0",
+		},
+		{
+			desc:    "Patch update policy without newer patch",
+			version: "v1.2.1",
+			tags:    []string{"v2.0.0", "v1.3.0", "v1.2.1"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyPatch},
+			wantStatus: Status{
+				UpToDate:       true,
+				CurrentVersion: "v1.2.1",
+				LatestVersion:  "v1.2.1",
+				Channel:        ChannelStable,
+			},
+		},
+		{
+			desc:    "None update policy reports the version without notifying",
+			version: "v1.2.0",
+			tags:    []string{"v2.0.0", "v1.2.0"},
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyNone},
+			wantStatus: Status{
+				CurrentVersion: "v1.2.0",
+				LatestVersion:  "v2.0.0",
+				Channel:        ChannelStable,
+			},
 		},
 	}
 
@@ -57,13 +181,17 @@ func TestChecker_check(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			latestVersion := "v0.5.0"
-
 			h := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 				assert.Equal(t, version, req.Header.Get("Traefik-Hub-Agent-Version"))
 				assert.Equal(t, "kubernetes", req.Header.Get("Traefik-Hub-Agent-Platform"))
 
-				b, err := json.Marshal([]*github.RepositoryTag{{Name: &latestVersion}})
+				var tags []*github.RepositoryTag
+				for _, tag := range test.tags {
+					tag := tag
+					tags = append(tags, &github.RepositoryTag{Name: &tag})
+				}
+
+				b, err := json.Marshal(tags)
 				if err != nil {
 					http.Error(rw, err.Error(), http.StatusInternalServerError)
 					return
@@ -73,29 +201,75 @@ func TestChecker_check(t *testing.T) {
 			})
 
 			srv := httptest.NewServer(h)
+			t.Cleanup(srv.Close)
+
 			updateURL, err := url.Parse(srv.URL + "/")
 			require.NoError(t, err)
 
 			cluster := newClusterServiceMock(t).
-				OnSetVersionStatus(Status{
-					UpToDate:       test.upToDate,
-					CurrentVersion: test.version,
-					LatestVersion:  latestVersion,
-				}).TypedReturns(nil).Once().
+				OnSetVersionStatus(test.wantStatus).TypedReturns(nil).Once().
 				Parent
 
-			c := NewChecker(cluster)
+			recorder := record.NewFakeRecorder(1)
+
+			c := NewChecker(cluster, test.cfg)
 			c.github = newGitHubClient(updateURL)
 			c.version = test.version
+			c.SetEventRecorder(recorder, &corev1.Pod{})
 
 			err = c.check(context.Background())
 
-			if test.upToDate {
+			if test.wantEvent == "" {
 				require.NoError(t, err)
+				assert.Empty(t, recorder.Events)
 				return
 			}
 
 			require.Error(t, err)
+			require.Len(t, recorder.Events, 1)
+			assert.Equal(t, test.wantEvent, <-recorder.Events)
+		})
+	}
+}
+
+func TestCheckerConfig_Validate(t *testing.T) {
+	tests := []struct {
+		desc    string
+		cfg     CheckerConfig
+		wantErr bool
+	}{
+		{
+			desc: "stable channel",
+			cfg:  CheckerConfig{Channel: ChannelStable, UpdatePolicy: UpdatePolicyMajor},
+		},
+		{
+			desc: "beta channel without checks",
+			cfg:  CheckerConfig{Channel: ChannelBeta, UpdatePolicy: UpdatePolicyNone},
+		},
+		{
+			desc:    "unknown channel",
+			cfg:     CheckerConfig{Channel: "nightly", UpdatePolicy: UpdatePolicyMajor},
+			wantErr: true,
+		},
+		{
+			desc:    "unknown update policy",
+			cfg:     CheckerConfig{Channel: ChannelStable, UpdatePolicy: "auto"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.cfg.Validate()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
 		})
 	}
 }
@@ -81,6 +81,16 @@ func Version() string {
 	return version
 }
 
+// Commit returns the commit the agent was built from.
+func Commit() string {
+	return commit
+}
+
+// BuildDate returns the date the agent was built on.
+func BuildDate() string {
+	return date
+}
+
 // Log logs the full version information.
 func Log() {
 	log.Info().
@@ -0,0 +1,713 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	admv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	kclientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// caValidity is how long the CA signing the serving certificates is valid.
+	caValidity = 2 * 365 * 24 * time.Hour
+	// certificateValidity is how long serving certificates are valid.
+	certificateValidity = 90 * 24 * time.Hour
+)
+
+// secretCAKey is the key of the private key of the CA in the certificate Secret.
+const secretCAKey = "ca.key"
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// CertificateManagerConfig configures a CertificateManager.
+type CertificateManagerConfig struct {
+	// Namespace is the namespace of the agent, in which the certificate Secret is stored.
+	Namespace string
+	// ServiceName is the name of the Service the API server calls the webhooks through.
+	ServiceName string
+	// SecretName is the name of the Secret storing the certificates.
+	SecretName string
+	// CheckInterval is the interval at which the certificates are checked.
+	CheckInterval time.Duration
+}
+
+// CertificateManager manages the serving certificate of the admission webhook server. The certificate, and the CA
+// signing it, are stored in a Secret shared by all the controller replicas. They are renewed once two thirds of their
+// validity elapsed, and the CA bundles of the webhook configurations and CRD conversion webhooks calling the agent
+// Service are patched accordingly.
+type CertificateManager struct {
+	client    kclientset.Interface
+	dynClient dynamic.Interface
+	cfg       CertificateManagerConfig
+	now       func() time.Time
+
+	cert atomic.Pointer[tls.Certificate]
+}
+
+// NewCertificateManager creates a CertificateManager. CRD conversion webhooks aren't patched if the dynamic client is
+// nil.
+func NewCertificateManager(client kclientset.Interface, dynClient dynamic.Interface, cfg CertificateManagerConfig) *CertificateManager {
+	return &CertificateManager{
+		client:    client,
+		dynClient: dynClient,
+		cfg:       cfg,
+		now:       time.Now,
+	}
+}
+
+// GetCertificate returns the serving certificate, it's meant to be used as tls.Config.GetCertificate.
+func (m *CertificateManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := m.cert.Load()
+	if cert == nil {
+		return nil, errors.New("no webhook certificate loaded")
+	}
+
+	return cert, nil
+}
+
+// Check checks the served certificate is currently valid.
+func (m *CertificateManager) Check(_ context.Context) error {
+	cert := m.cert.Load()
+	if cert == nil {
+		return errors.New("no webhook certificate loaded")
+	}
+
+	now := m.now()
+	switch {
+	case now.Before(cert.Leaf.NotBefore):
+		return fmt.Errorf("certificate not valid before %s", cert.Leaf.NotBefore.Format(time.RFC3339))
+	case now.After(cert.Leaf.NotAfter):
+		return fmt.Errorf("certificate expired on %s", cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// Load loads the serving certificate from the Secret, creating it if it doesn't exist yet.
+func (m *CertificateManager) Load(ctx context.Context) error {
+	secret, err := m.client.CoreV1().Secrets(m.cfg.Namespace).Get(ctx, m.cfg.SecretName, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		secret, err = m.createSecret(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("get certificate Secret: %w", err)
+	}
+
+	bundle, err := parseCertificates(secret)
+	if err != nil {
+		// The leader generates new certificates.
+		log.Error().Err(err).Str("secret", m.cfg.SecretName).Msg("Invalid webhook certificates")
+		return nil
+	}
+
+	return m.serve(bundle)
+}
+
+// Run reloads the serving certificate from the Secret, so replicas serve the certificates renewed by the leader.
+func (m *CertificateManager) Run(ctx context.Context) {
+	tick := time.NewTicker(m.cfg.CheckInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if err := m.Load(ctx); err != nil {
+				log.Error().Err(err).Msg("Unable to reload the webhook certificate")
+			}
+		}
+	}
+}
+
+// Rotate renews the certificates before they expire, and keeps the CA bundles of the webhooks up to date. It must
+// only run on the leader replica.
+func (m *CertificateManager) Rotate(ctx context.Context) {
+	tick := time.NewTicker(m.cfg.CheckInterval)
+	defer tick.Stop()
+
+	for {
+		if err := m.reconcile(ctx); err != nil {
+			log.Error().Err(err).Msg("Unable to rotate the webhook certificates")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+func (m *CertificateManager) reconcile(ctx context.Context) error {
+	secret, err := m.client.CoreV1().Secrets(m.cfg.Namespace).Get(ctx, m.cfg.SecretName, metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		secret, err = m.createSecret(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("get certificate Secret: %w", err)
+	}
+
+	bundle, err := parseCertificates(secret)
+	if err != nil {
+		log.Error().Err(err).Str("secret", m.cfg.SecretName).Msg("Invalid webhook certificates, generating new ones")
+		bundle = &certificates{}
+	}
+
+	renewed, err := m.renew(bundle)
+	if err != nil {
+		return err
+	}
+
+	// The CA bundles are patched before the Secret is updated: replicas must not serve a certificate signed by a new
+	// CA before the API server trusts it.
+	if err = m.patchCABundles(ctx, renewed.caPEM); err != nil {
+		return err
+	}
+
+	if renewed != bundle {
+		secret = secret.DeepCopy()
+		secret.Data, err = renewed.data()
+		if err != nil {
+			return err
+		}
+
+		if _, err = m.client.CoreV1().Secrets(m.cfg.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("update certificate Secret: %w", err)
+		}
+
+		log.Info().
+			Time("not_after", renewed.cert.NotAfter).
+			Time("ca_not_after", renewed.cas[0].NotAfter).
+			Msg("Webhook certificate renewed")
+	}
+
+	return m.serve(renewed)
+}
+
+func (m *CertificateManager) createSecret(ctx context.Context) (*corev1.Secret, error) {
+	bundle, err := m.renew(&certificates{})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := bundle.data()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.cfg.SecretName,
+			Namespace: m.cfg.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: data,
+	}
+
+	created, err := m.client.CoreV1().Secrets(m.cfg.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if kerror.IsAlreadyExists(err) {
+		// Another replica created it in the meantime.
+		return m.client.CoreV1().Secrets(m.cfg.Namespace).Get(ctx, m.cfg.SecretName, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create certificate Secret: %w", err)
+	}
+
+	log.Info().Str("secret", m.cfg.SecretName).Msg("Webhook certificate created")
+
+	return created, nil
+}
+
+func (m *CertificateManager) serve(bundle *certificates) error {
+	cert, err := tls.X509KeyPair(bundle.certPEM, bundle.keyPEM)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+	cert.Leaf = bundle.cert
+
+	if current := m.cert.Load(); current == nil || !bytes.Equal(current.Certificate[0], cert.Certificate[0]) {
+		m.cert.Store(&cert)
+	}
+
+	return nil
+}
+
+// renew returns the given certificates with the ones needing it renewed, or the same certificates if none needs to.
+func (m *CertificateManager) renew(bundle *certificates) (*certificates, error) {
+	now := m.now()
+
+	renewCA := len(bundle.cas) == 0 || needsRenewal(bundle.cas[0], now)
+	renewCert := renewCA || bundle.cert == nil || needsRenewal(bundle.cert, now) ||
+		bundle.cert.CheckSignatureFrom(bundle.cas[0]) != nil ||
+		!slices.Equal(bundle.cert.DNSNames, m.dnsNames())
+
+	if !renewCA && !renewCert {
+		return bundle, nil
+	}
+
+	renewed := &certificates{cas: bundle.cas, caKey: bundle.caKey}
+
+	if renewCA {
+		ca, caKey, err := newCA(now)
+		if err != nil {
+			return nil, err
+		}
+
+		// The previous CAs are trusted until they expire, so the certificates they signed stay valid for the API
+		// server until the replicas serve the new one.
+		renewed.cas = []*x509.Certificate{ca}
+		for _, previous := range bundle.cas {
+			if now.Before(previous.NotAfter) {
+				renewed.cas = append(renewed.cas, previous)
+			}
+		}
+		renewed.caKey = caKey
+	}
+
+	cert, certPEM, keyPEM, err := newServingCertificate(renewed.cas[0], renewed.caKey, m.dnsNames(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	renewed.cert = cert
+	renewed.certPEM = certPEM
+	renewed.keyPEM = keyPEM
+
+	for _, ca := range renewed.cas {
+		renewed.caPEM = append(renewed.caPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})...)
+	}
+
+	return renewed, nil
+}
+
+func (m *CertificateManager) dnsNames() []string {
+	svc, ns := m.cfg.ServiceName, m.cfg.Namespace
+
+	return []string{svc, svc + "." + ns, svc + "." + ns + ".svc", svc + "." + ns + ".svc.cluster.local"}
+}
+
+// CABundlePatch is a JSON patch setting the CA bundle of a webhook calling the agent Service.
+type CABundlePatch struct {
+	// Kind is the kind of the patched resource: MutatingWebhookConfiguration, ValidatingWebhookConfiguration or
+	// CustomResourceDefinition.
+	Kind string
+	Name string
+	// Object is the JSON of the resource before the patch.
+	Object []byte
+	Patch  []byte
+}
+
+// RefreshCABundles sets the CA of the certificate Secret on the webhooks calling the agent Service, and returns the
+// patches it applied. Nothing is patched in dry-run mode, the patches which would be applied are returned.
+func (m *CertificateManager) RefreshCABundles(ctx context.Context, dryRun bool) ([]CABundlePatch, error) {
+	secret, err := m.client.CoreV1().Secrets(m.cfg.Namespace).Get(ctx, m.cfg.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get certificate Secret: %w", err)
+	}
+
+	bundle, err := parseCertificates(secret)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate Secret: %w", err)
+	}
+
+	patches, err := m.caBundlePatches(ctx, bundle.caPEM)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return patches, nil
+	}
+
+	if err = m.applyPatches(ctx, patches); err != nil {
+		return nil, err
+	}
+
+	return patches, nil
+}
+
+// patchCABundles sets the given CA bundle on the webhooks calling the agent Service.
+func (m *CertificateManager) patchCABundles(ctx context.Context, caBundle []byte) error {
+	patches, err := m.caBundlePatches(ctx, caBundle)
+	if err != nil {
+		return err
+	}
+
+	return m.applyPatches(ctx, patches)
+}
+
+// caBundlePatches returns the patches setting the given CA bundle on the webhooks calling the agent Service which
+// don't have it yet.
+func (m *CertificateManager) caBundlePatches(ctx context.Context, caBundle []byte) ([]CABundlePatch, error) {
+	mutating, err := m.client.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list mutating webhook configurations: %w", err)
+	}
+
+	var patches []CABundlePatch
+	for _, cfg := range mutating.Items {
+		cfg := cfg
+
+		var ops []patchOp
+		for i, wh := range cfg.Webhooks {
+			if m.callsService(wh.ClientConfig.Service) && !bytes.Equal(wh.ClientConfig.CABundle, caBundle) {
+				ops = append(ops, caBundleOp(fmt.Sprintf("/webhooks/%d/clientConfig/caBundle", i), caBundle))
+			}
+		}
+
+		patch, err := newCABundlePatch("MutatingWebhookConfiguration", cfg.Name, &cfg, ops)
+		if err != nil {
+			return nil, err
+		}
+		if patch != nil {
+			patches = append(patches, *patch)
+		}
+	}
+
+	validating, err := m.client.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list validating webhook configurations: %w", err)
+	}
+
+	for _, cfg := range validating.Items {
+		cfg := cfg
+
+		var ops []patchOp
+		for i, wh := range cfg.Webhooks {
+			if m.callsService(wh.ClientConfig.Service) && !bytes.Equal(wh.ClientConfig.CABundle, caBundle) {
+				ops = append(ops, caBundleOp(fmt.Sprintf("/webhooks/%d/clientConfig/caBundle", i), caBundle))
+			}
+		}
+
+		patch, err := newCABundlePatch("ValidatingWebhookConfiguration", cfg.Name, &cfg, ops)
+		if err != nil {
+			return nil, err
+		}
+		if patch != nil {
+			patches = append(patches, *patch)
+		}
+	}
+
+	crdPatches, err := m.crdPatches(ctx, caBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(patches, crdPatches...), nil
+}
+
+// crdPatches returns the patches setting the given CA bundle on the conversion webhooks of the CRDs calling the agent
+// Service.
+func (m *CertificateManager) crdPatches(ctx context.Context, caBundle []byte) ([]CABundlePatch, error) {
+	if m.dynClient == nil {
+		return nil, nil
+	}
+
+	crds, err := m.dynClient.Resource(crdResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list CRDs: %w", err)
+	}
+
+	var patches []CABundlePatch
+	for _, crd := range crds.Items {
+		crd := crd
+
+		namespace, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service", "namespace")
+		name, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service", "name")
+		if namespace != m.cfg.Namespace || name != m.cfg.ServiceName {
+			continue
+		}
+
+		current, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+		if current == encodeCABundle(caBundle) {
+			continue
+		}
+
+		patch, err := newCABundlePatch("CustomResourceDefinition", crd.GetName(), &crd,
+			[]patchOp{caBundleOp("/spec/conversion/webhook/clientConfig/caBundle", caBundle)})
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, *patch)
+	}
+
+	return patches, nil
+}
+
+func (m *CertificateManager) applyPatches(ctx context.Context, patches []CABundlePatch) error {
+	for _, patch := range patches {
+		var err error
+		switch patch.Kind {
+		case "MutatingWebhookConfiguration":
+			_, err = m.client.AdmissionregistrationV1().MutatingWebhookConfigurations().Patch(ctx, patch.Name, ktypes.JSONPatchType, patch.Patch, metav1.PatchOptions{})
+		case "ValidatingWebhookConfiguration":
+			_, err = m.client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Patch(ctx, patch.Name, ktypes.JSONPatchType, patch.Patch, metav1.PatchOptions{})
+		case "CustomResourceDefinition":
+			_, err = m.dynClient.Resource(crdResource).Patch(ctx, patch.Name, ktypes.JSONPatchType, patch.Patch, metav1.PatchOptions{})
+		default:
+			err = fmt.Errorf("unsupported kind %q", patch.Kind)
+		}
+		if err != nil {
+			return fmt.Errorf("patch %s %q: %w", patch.Kind, patch.Name, err)
+		}
+
+		log.Info().
+			Str("kind", patch.Kind).
+			Str("name", patch.Name).
+			Msg("CA bundle of the webhook updated")
+	}
+
+	return nil
+}
+
+// newCABundlePatch returns the patch of the given resource applying the given operations, nil if there are none.
+func newCABundlePatch(kind, name string, obj any, ops []patchOp) (*CABundlePatch, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s %q: %w", kind, name, err)
+	}
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return nil, fmt.Errorf("marshal patch: %w", err)
+	}
+
+	return &CABundlePatch{Kind: kind, Name: name, Object: raw, Patch: patch}, nil
+}
+
+func (m *CertificateManager) callsService(svc *admv1.ServiceReference) bool {
+	return svc != nil && svc.Namespace == m.cfg.Namespace && svc.Name == m.cfg.ServiceName
+}
+
+type patchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// caBundleOp returns the JSON patch operation setting the CA bundle at the given path, whether it's already set or not.
+func caBundleOp(path string, caBundle []byte) patchOp {
+	return patchOp{Op: "add", Path: path, Value: encodeCABundle(caBundle)}
+}
+
+func encodeCABundle(caBundle []byte) string {
+	return base64.StdEncoding.EncodeToString(caBundle)
+}
+
+// certificates are the certificates stored in the certificate Secret.
+type certificates struct {
+	// cas are the trusted CAs, the first one signing the serving certificate.
+	cas   []*x509.Certificate
+	caKey *ecdsa.PrivateKey
+	caPEM []byte
+
+	cert    *x509.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func parseCertificates(secret *corev1.Secret) (*certificates, error) {
+	bundle := &certificates{
+		caPEM:   secret.Data[corev1.ServiceAccountRootCAKey],
+		certPEM: secret.Data[corev1.TLSCertKey],
+		keyPEM:  secret.Data[corev1.TLSPrivateKeyKey],
+	}
+
+	rest := bundle.caPEM
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		ca, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse CA: %w", err)
+		}
+		bundle.cas = append(bundle.cas, ca)
+	}
+	if len(bundle.cas) == 0 {
+		return nil, errors.New("no CA")
+	}
+
+	caKey, err := parsePrivateKey(secret.Data[secretCAKey])
+	if err != nil {
+		return nil, fmt.Errorf("parse CA key: %w", err)
+	}
+	bundle.caKey = caKey
+
+	block, _ := pem.Decode(bundle.certPEM)
+	if block == nil {
+		return nil, errors.New("no certificate")
+	}
+
+	bundle.cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	if _, err = tls.X509KeyPair(bundle.certPEM, bundle.keyPEM); err != nil {
+		return nil, fmt.Errorf("load key pair: %w", err)
+	}
+
+	return bundle, nil
+}
+
+func (c *certificates) data() (map[string][]byte, error) {
+	keyDER, err := x509.MarshalECPrivateKey(c.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("marshal CA private key: %w", err)
+	}
+
+	return map[string][]byte{
+		corev1.ServiceAccountRootCAKey: c.caPEM,
+		secretCAKey:                    pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		corev1.TLSCertKey:              c.certPEM,
+		corev1.TLSPrivateKeyKey:        c.keyPEM,
+	}, nil
+}
+
+func parsePrivateKey(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no private key")
+	}
+
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// needsRenewal returns whether two thirds of the validity of the given certificate elapsed.
+func needsRenewal(cert *x509.Certificate, now time.Time) bool {
+	validity := cert.NotAfter.Sub(cert.NotBefore)
+
+	return now.After(cert.NotAfter.Add(-validity / 3))
+}
+
+func newCA(now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate CA private key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate serial number: %w", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Traefik Hub Agent"}, CommonName: "Traefik Hub Agent Webhook CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create CA: %w", err)
+	}
+
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse CA: %w", err)
+	}
+
+	return ca, key, nil
+}
+
+func newServingCertificate(ca *x509.Certificate, caKey *ecdsa.PrivateKey, dnsNames []string, now time.Time) (*x509.Certificate, []byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("generate private key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("generate serial number: %w", err)
+	}
+
+	notAfter := now.Add(certificateValidity)
+	if notAfter.After(ca.NotAfter) {
+		notAfter = ca.NotAfter
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Traefik Hub Agent"}, CommonName: dnsNames[0]},
+		DNSNames:              dnsNames,
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("create certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("marshal private key: %w", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return cert, certPEM, keyPEM, nil
+}
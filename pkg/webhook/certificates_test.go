@@ -0,0 +1,282 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCertificateManager_Load(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	manager := NewCertificateManager(client, nil, CertificateManagerConfig{
+		Namespace:   "hub-agent",
+		ServiceName: "hub-agent-controller",
+		SecretName:  "hub-agent-webhook-certificate",
+	})
+
+	_, err := manager.GetCertificate(nil)
+	require.Error(t, err)
+	require.Error(t, manager.Check(context.Background()))
+
+	require.NoError(t, manager.Load(context.Background()))
+
+	secret, err := client.CoreV1().Secrets("hub-agent").Get(context.Background(), "hub-agent-webhook-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeTLS, secret.Type)
+
+	cert, err := manager.GetCertificate(nil)
+	require.NoError(t, err)
+	require.NoError(t, manager.Check(context.Background()))
+
+	assertServes(t, secret.Data[corev1.ServiceAccountRootCAKey], cert.Leaf)
+
+	// Other replicas load the same certificate.
+	other := NewCertificateManager(client, nil, manager.cfg)
+	require.NoError(t, other.Load(context.Background()))
+
+	otherCert, err := other.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, otherCert.Certificate)
+}
+
+func TestCertificateManager_reconcile(t *testing.T) {
+	webhookConfig := &admv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-acp"},
+		Webhooks: []admv1.MutatingWebhook{
+			{
+				Name: "hub-agent.traefik.ingress",
+				ClientConfig: admv1.WebhookClientConfig{
+					Service: &admv1.ServiceReference{Namespace: "hub-agent", Name: "hub-agent-controller"},
+				},
+			},
+			{
+				Name: "other.example.com",
+				ClientConfig: admv1.WebhookClientConfig{
+					Service:  &admv1.ServiceReference{Namespace: "hub-agent", Name: "other"},
+					CABundle: []byte("other"),
+				},
+			},
+		},
+	}
+	validatingConfig := &admv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-acp-validation"},
+		Webhooks: []admv1.ValidatingWebhook{
+			{
+				Name: "hub-agent.traefik.acp",
+				ClientConfig: admv1.WebhookClientConfig{
+					Service:  &admv1.ServiceReference{Namespace: "hub-agent", Name: "hub-agent-controller"},
+					CABundle: []byte("outdated"),
+				},
+			},
+		},
+	}
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "accesscontrolpolicies.hub.traefik.io"},
+		"spec": map[string]interface{}{
+			"conversion": map[string]interface{}{
+				"strategy": "Webhook",
+				"webhook": map[string]interface{}{
+					"clientConfig": map[string]interface{}{
+						"service": map[string]interface{}{"namespace": "hub-agent", "name": "hub-agent-controller"},
+					},
+				},
+			},
+		},
+	}}
+
+	client := kubefake.NewSimpleClientset(webhookConfig, validatingConfig)
+	dynClient := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdResource: "CustomResourceDefinitionList"},
+		crd,
+	)
+
+	now := time.Now()
+	manager := NewCertificateManager(client, dynClient, CertificateManagerConfig{
+		Namespace:   "hub-agent",
+		ServiceName: "hub-agent-controller",
+		SecretName:  "hub-agent-webhook-certificate",
+	})
+	manager.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	require.NoError(t, manager.reconcile(ctx))
+
+	caBundle := assertCABundles(t, client, dynClient)
+	cert, err := manager.GetCertificate(nil)
+	require.NoError(t, err)
+	assertServes(t, caBundle, cert.Leaf)
+
+	// Nothing changes until the certificate needs to be renewed.
+	now = now.Add(50 * 24 * time.Hour)
+	require.NoError(t, manager.reconcile(ctx))
+
+	renewed, err := manager.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, renewed.Certificate)
+
+	// The certificate is renewed with the same CA.
+	now = now.Add(20 * 24 * time.Hour)
+	require.NoError(t, manager.reconcile(ctx))
+
+	renewed, err = manager.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, cert.Certificate, renewed.Certificate)
+	assert.Equal(t, caBundle, assertCABundles(t, client, dynClient))
+	assertServes(t, caBundle, renewed.Leaf)
+
+	// The CA is renewed, the previous one is still trusted until it expires.
+	now = now.Add(16 * 30 * 24 * time.Hour)
+	require.NoError(t, manager.reconcile(ctx))
+
+	renewedCABundle := assertCABundles(t, client, dynClient)
+	assert.NotEqual(t, caBundle, renewedCABundle)
+	assert.Contains(t, string(renewedCABundle), string(caBundle))
+
+	renewed, err = manager.GetCertificate(nil)
+	require.NoError(t, err)
+	assertServes(t, renewedCABundle, renewed.Leaf)
+
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(caBundle))
+	_, err = renewed.Leaf.Verify(x509.VerifyOptions{Roots: pool, CurrentTime: now})
+	assert.Error(t, err, "certificate signed by the previous CA")
+
+	// The other webhooks are left untouched.
+	mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, "hub-acp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("other"), mutating.Webhooks[1].ClientConfig.CABundle)
+}
+
+func TestCertificateManager_RefreshCABundles(t *testing.T) {
+	webhookConfig := &admv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-acp-validation"},
+		Webhooks: []admv1.ValidatingWebhook{
+			{
+				Name: "hub-agent.traefik.acp",
+				ClientConfig: admv1.WebhookClientConfig{
+					Service:  &admv1.ServiceReference{Namespace: "hub-agent", Name: "hub-agent-controller"},
+					CABundle: []byte("outdated"),
+				},
+			},
+		},
+	}
+
+	client := kubefake.NewSimpleClientset(webhookConfig)
+	manager := NewCertificateManager(client, nil, CertificateManagerConfig{
+		Namespace:   "hub-agent",
+		ServiceName: "hub-agent-controller",
+		SecretName:  "hub-agent-webhook-certificate",
+	})
+
+	ctx := context.Background()
+
+	// The certificate Secret is only created by the controller.
+	_, err := manager.RefreshCABundles(ctx, false)
+	assert.True(t, kerror.IsNotFound(err))
+
+	require.NoError(t, manager.Load(ctx))
+
+	// Nothing is patched in dry-run mode.
+	patches, err := manager.RefreshCABundles(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, patches, 1)
+	assert.Equal(t, "ValidatingWebhookConfiguration", patches[0].Kind)
+	assert.Equal(t, "hub-acp-validation", patches[0].Name)
+
+	validating, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, "hub-acp-validation", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("outdated"), validating.Webhooks[0].ClientConfig.CABundle)
+
+	patches, err = manager.RefreshCABundles(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, patches, 1)
+
+	secret, err := client.CoreV1().Secrets("hub-agent").Get(ctx, "hub-agent-webhook-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	validating, err = client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, "hub-acp-validation", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, secret.Data[corev1.ServiceAccountRootCAKey], validating.Webhooks[0].ClientConfig.CABundle)
+
+	// Up-to-date webhooks are left untouched.
+	patches, err = manager.RefreshCABundles(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, patches)
+}
+
+// assertCABundles asserts the webhooks calling the agent have the CA bundle of the certificate Secret, and returns it.
+func assertCABundles(t *testing.T, client *kubefake.Clientset, dynClient *dynfake.FakeDynamicClient) []byte {
+	t.Helper()
+
+	ctx := context.Background()
+
+	secret, err := client.CoreV1().Secrets("hub-agent").Get(ctx, "hub-agent-webhook-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	caBundle := secret.Data[corev1.ServiceAccountRootCAKey]
+	require.NotEmpty(t, caBundle)
+
+	mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, "hub-acp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, caBundle, mutating.Webhooks[0].ClientConfig.CABundle)
+
+	validating, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, "hub-acp-validation", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, caBundle, validating.Webhooks[0].ClientConfig.CABundle)
+
+	crd, err := dynClient.Resource(crdResource).Get(ctx, "accesscontrolpolicies.hub.traefik.io", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	crdCABundle, _, err := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	require.NoError(t, err)
+	assert.Equal(t, encodeCABundle(caBundle), crdCABundle)
+
+	return caBundle
+}
+
+// assertServes asserts the given certificate is trusted by the given CA bundle for the agent Service.
+func assertServes(t *testing.T, caBundle []byte, cert *x509.Certificate) {
+	t.Helper()
+
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(caBundle))
+
+	_, err := cert.Verify(x509.VerifyOptions{
+		DNSName:     "hub-agent-controller.hub-agent.svc",
+		Roots:       pool,
+		CurrentTime: cert.NotBefore.Add(2 * time.Hour),
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	reviews  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+	panics   *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		reviews: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "admission",
+			Name:      "reviews_total",
+			Help:      "Number of reviews handled by the webhook server, partitioned by handler, kind and status code.",
+		}, []string{"handler", "kind", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "hub_agent",
+			Subsystem: "admission",
+			Name:      "review_duration_seconds",
+			Help:      "Time spent handling reviews, including the time spent waiting for a free slot, partitioned by handler and kind.",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		}, []string{"handler", "kind"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "hub_agent",
+			Subsystem: "admission",
+			Name:      "reviews_in_flight",
+			Help:      "Number of reviews currently being handled.",
+		}),
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "hub_agent",
+			Subsystem: "admission",
+			Name:      "review_panics_total",
+			Help:      "Number of reviews which caused their handler to panic, partitioned by handler and kind.",
+		}, []string{"handler", "kind"}),
+	}
+
+	if err := reg.Register(m.reviews); err != nil {
+		return nil, fmt.Errorf("register reviews counter: %w", err)
+	}
+	if err := reg.Register(m.duration); err != nil {
+		return nil, fmt.Errorf("register duration histogram: %w", err)
+	}
+	if err := reg.Register(m.inFlight); err != nil {
+		return nil, fmt.Errorf("register in flight gauge: %w", err)
+	}
+	if err := reg.Register(m.panics); err != nil {
+		return nil, fmt.Errorf("register panics counter: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m *metrics) observe(handler, kind string, code int, seconds float64) {
+	m.reviews.WithLabelValues(handler, kind, strconv.Itoa(code)).Inc()
+	m.duration.WithLabelValues(handler, kind).Observe(seconds)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package webhook provides the plumbing shared by the admission and conversion webhooks served by the agent.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxReviewSize is the maximum size of a review body. The Kubernetes API server limits objects to 3MiB, and
+// conversion reviews may carry several of them.
+const maxReviewSize = 16 << 20
+
+// Pipeline bounds the number of reviews handled concurrently by the webhook server, isolates handler panics
+// to the request which caused them and instruments every review.
+type Pipeline struct {
+	slots   chan struct{}
+	metrics *metrics
+	tracer  *tracing.Tracer
+}
+
+// NewPipeline creates a pipeline handling at most maxConcurrent reviews at once and registers its metrics
+// in the given registerer.
+func NewPipeline(maxConcurrent int, reg prometheus.Registerer) (*Pipeline, error) {
+	if maxConcurrent <= 0 {
+		return nil, fmt.Errorf("max concurrent reviews must be positive, got %d", maxConcurrent)
+	}
+
+	m, err := newMetrics(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pipeline{
+		slots:   make(chan struct{}, maxConcurrent),
+		metrics: m,
+	}, nil
+}
+
+// SetTracer sets the tracer the reviews are traced with.
+func (p *Pipeline) SetTracer(tracer *tracing.Tracer) {
+	p.tracer = tracer
+}
+
+// Wrap returns a handler running the given review handler through the pipeline.
+// The name identifies the handler in metrics, traces and logs.
+func (p *Pipeline) Wrap(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+
+		body, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, maxReviewSize))
+		if err != nil {
+			log.Error().Err(err).Str("handler", name).Msg("Unable to read review")
+			http.Error(rw, "Unable to read review", http.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		kind := reviewedKind(body)
+
+		ctx, span := p.tracer.Start(tracing.Extract(req.Context(), req.Header), "review "+name, trace.SpanKindServer)
+		defer span.End()
+
+		span.SetAttributes(attribute.String("handler", name), attribute.String("kind", kind))
+		req = req.WithContext(ctx)
+
+		select {
+		case p.slots <- struct{}{}:
+		case <-req.Context().Done():
+			// The API server gave up on the review while it was waiting for a free slot.
+			p.metrics.observe(name, kind, http.StatusServiceUnavailable, time.Since(start).Seconds())
+			span.SetAttributes(attribute.Int("http.status_code", http.StatusServiceUnavailable))
+			http.Error(rw, "Review canceled", http.StatusServiceUnavailable)
+			return
+		}
+
+		p.metrics.inFlight.Inc()
+
+		recorder := &statusRecorder{ResponseWriter: rw, code: http.StatusOK}
+		defer func() {
+			<-p.slots
+			p.metrics.inFlight.Dec()
+
+			if r := recover(); r != nil {
+				if errors.Is(asError(r), http.ErrAbortHandler) {
+					panic(r)
+				}
+
+				p.metrics.panics.WithLabelValues(name, kind).Inc()
+				log.Error().
+					Str("handler", name).
+					Str("kind", kind).
+					Interface("panic", r).
+					Bytes("stack", debug.Stack()).
+					Msg("Review handler panicked")
+
+				if !recorder.wroteHeader {
+					http.Error(recorder, "Internal error", http.StatusInternalServerError)
+				}
+			}
+
+			p.metrics.observe(name, kind, recorder.code, time.Since(start).Seconds())
+			span.SetAttributes(attribute.Int("http.status_code", recorder.code))
+		}()
+
+		next.ServeHTTP(recorder, req)
+	})
+}
+
+// reviewedKind returns the kind of the object under review, or the kind of the review itself if it doesn't
+// target a single object, as it is the case for conversion reviews.
+func reviewedKind(body []byte) string {
+	var review struct {
+		Kind    string `json:"kind"`
+		Request struct {
+			Kind struct {
+				Kind string `json:"kind"`
+			} `json:"kind"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(body, &review); err != nil {
+		return "unknown"
+	}
+
+	switch {
+	case review.Request.Kind.Kind != "":
+		return review.Request.Kind.Kind
+	case review.Kind != "":
+		return review.Kind
+	default:
+		return "unknown"
+	}
+}
+
+func asError(r interface{}) error {
+	err, _ := r.(error)
+	return err
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+
+	code        int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+
+	return r.ResponseWriter.Write(b)
+}
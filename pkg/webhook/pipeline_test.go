@@ -0,0 +1,187 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ingressReview = `{"kind":"AdmissionReview","request":{"kind":{"kind":"Ingress"}}}`
+
+func TestPipeline_Wrap(t *testing.T) {
+	tests := []struct {
+		desc     string
+		body     string
+		handler  http.HandlerFunc
+		wantCode int
+		wantKind string
+		wantBody string
+		panics   float64
+	}{
+		{
+			desc: "forwards the review",
+			body: ingressReview,
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				b, _ := io.ReadAll(req.Body)
+				_, _ = rw.Write(b)
+			},
+			wantCode: http.StatusOK,
+			wantKind: "Ingress",
+			wantBody: ingressReview,
+		},
+		{
+			desc: "conversion review",
+			body: `{"kind":"ConversionReview","request":{"desiredAPIVersion":"hub.traefik.io/v1alpha1"}}`,
+			handler: func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusAccepted)
+			},
+			wantCode: http.StatusAccepted,
+			wantKind: "ConversionReview",
+		},
+		{
+			desc: "malformed review",
+			body: "{",
+			handler: func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusBadRequest)
+			},
+			wantCode: http.StatusBadRequest,
+			wantKind: "unknown",
+		},
+		{
+			desc: "handler panic",
+			body: ingressReview,
+			handler: func(http.ResponseWriter, *http.Request) {
+				panic("boom")
+			},
+			wantCode: http.StatusInternalServerError,
+			wantKind: "Ingress",
+			wantBody: "Internal error\n",
+			panics:   1,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewPipeline(1, prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/ingress", strings.NewReader(test.body))
+
+			p.Wrap("ingress", test.handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+			if test.wantBody != "" {
+				assert.Equal(t, test.wantBody, rec.Body.String())
+			}
+
+			assert.Equal(t, 1.0, testutil.ToFloat64(p.metrics.reviews.WithLabelValues("ingress", test.wantKind, strconv.Itoa(test.wantCode))))
+			assert.Equal(t, test.panics, testutil.ToFloat64(p.metrics.panics.WithLabelValues("ingress", test.wantKind)))
+			assert.Equal(t, 0.0, testutil.ToFloat64(p.metrics.inFlight))
+		})
+	}
+}
+
+func TestPipeline_Wrap_boundsConcurrency(t *testing.T) {
+	p, err := NewPipeline(2, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+
+	var (
+		mu      sync.Mutex
+		running int
+		maxSeen int
+	)
+	handler := p.Wrap("ingress", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		mu.Lock()
+		running++
+		if running > maxSeen {
+			maxSeen = running
+		}
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodPost, "/ingress", strings.NewReader(ingressReview))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(p.metrics.inFlight) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 2, maxSeen)
+	assert.Equal(t, 5.0, testutil.ToFloat64(p.metrics.reviews.WithLabelValues("ingress", "Ingress", "200")))
+}
+
+func TestPipeline_Wrap_canceledWhileWaiting(t *testing.T) {
+	p, err := NewPipeline(1, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	// Hold the only slot.
+	p.slots <- struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/ingress", strings.NewReader(ingressReview)).WithContext(ctx)
+
+	p.Wrap("ingress", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		assert.Fail(t, "handler must not be called")
+	})).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestNewPipeline_invalidConcurrency(t *testing.T) {
+	_, err := NewPipeline(0, prometheus.NewRegistry())
+	assert.Error(t, err)
+}
@@ -17,6 +17,7 @@ import { FaencyProvider, globalCss, lightTheme } from '@traefiklabs/faency'
 import PageLayout from 'components/PageLayout'
 import { BrowserRouter, Navigate, Route, Routes as RouterRoutes } from 'react-router-dom'
 import API from 'pages/API'
+import APIChanges from 'pages/APIChanges'
 import { HelmetProvider } from 'react-helmet-async'
 import { QueryClientProvider, QueryClient } from 'react-query'
 import { useAPIs } from 'hooks/use-apis'
@@ -89,6 +90,22 @@ const Routes = () => {
           </PageLayout>
         }
       />
+      <Route
+        path="/apis/:apiName/changes"
+        element={
+          <PageLayout>
+            <APIChanges />
+          </PageLayout>
+        }
+      />
+      <Route
+        path="/collections/:collectionName/apis/:apiName/changes"
+        element={
+          <PageLayout>
+            <APIChanges />
+          </PageLayout>
+        }
+      />
     </RouterRoutes>
   )
 }
@@ -0,0 +1,68 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+import axios from 'axios'
+import { useQuery } from 'react-query'
+import { getInjectedValues } from 'utils/getInjectedValues'
+
+const { portalName } = getInjectedValues()
+
+export type SpecVersion = {
+  id: number
+  digest: string
+  createdAt: string
+}
+
+export type FieldChange = {
+  field: string
+  change: 'added' | 'removed' | 'modified'
+}
+
+export type OperationDiff = {
+  path: string
+  method: string
+  change: 'added' | 'removed' | 'modified'
+  details?: FieldChange[]
+}
+
+export type SpecDiff = {
+  from: SpecVersion
+  to: SpecVersion
+  operations: OperationDiff[]
+  schemas: FieldChange[] | null
+}
+
+const apiUrl = (apiName?: string, collectionName?: string) => {
+  if (collectionName) {
+    return `/api/${portalName}/collections/${collectionName}/apis/${apiName}`
+  }
+
+  return `/api/${portalName}/apis/${apiName}`
+}
+
+export const useAPISpecVersions = (apiName?: string, collectionName?: string) => {
+  const fetchUrl = `${apiUrl(apiName, collectionName)}/versions`
+
+  return useQuery<SpecVersion[]>(fetchUrl, () => axios.get(fetchUrl).then(({ data }) => data))
+}
+
+export const useAPISpecDiff = (apiName?: string, collectionName?: string, from?: number, to?: number) => {
+  const fetchUrl = `${apiUrl(apiName, collectionName)}/diff`
+
+  return useQuery<SpecDiff>(
+    [fetchUrl, from, to],
+    () => axios.get(fetchUrl, { params: { from, to } }).then(({ data }) => data),
+    { enabled: from !== undefined && to !== undefined },
+  )
+}
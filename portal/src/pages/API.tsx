@@ -14,8 +14,8 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 
 import 'components/styles/Swagger.css'
 import React, { useMemo } from 'react'
-import { Box } from '@traefiklabs/faency'
-import { useParams } from 'react-router-dom'
+import { Box, Flex } from '@traefiklabs/faency'
+import { Link as RouterLink, useParams } from 'react-router-dom'
 import { Helmet } from 'react-helmet-async'
 import SwaggerUI from 'swagger-ui-react'
 
@@ -32,6 +32,16 @@ import { getInjectedValues } from 'utils/getInjectedValues'
 //   }
 // }
 
+// Requests sent from the try-it console are flagged as sandbox requests, so they get routed
+// to the sandbox environment of the API when it has one.
+const sandboxRequestInterceptor = (req: { [k: string]: any }) => ({
+  ...req,
+  headers: {
+    ...req.headers,
+    'X-Hub-Sandbox': 'true',
+  },
+})
+
 const API = () => {
   const { portalName } = getInjectedValues()
   const { apiName, collectionName } = useParams()
@@ -49,8 +59,16 @@ const API = () => {
       <Helmet>
         <title>{apiName || 'API Portal'}</title>
       </Helmet>
+      <Flex justify="end" css={{ px: '$4', pt: '$3' }}>
+        <RouterLink to="changes">What changed?</RouterLink>
+      </Flex>
       <Box>
-        <SwaggerUI layout="AugmentedLayout" plugins={[AugmentedLayoutPlugin]} url={specUrl} />
+        <SwaggerUI
+          layout="AugmentedLayout"
+          plugins={[AugmentedLayoutPlugin]}
+          url={specUrl}
+          requestInterceptor={sandboxRequestInterceptor}
+        />
       </Box>
     </Box>
   )
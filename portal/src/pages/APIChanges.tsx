@@ -0,0 +1,136 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+import React, { useEffect, useState } from 'react'
+import { Box, Flex, H1, Text } from '@traefiklabs/faency'
+import { useParams } from 'react-router-dom'
+import { Helmet } from 'react-helmet-async'
+
+import { FieldChange, SpecVersion, useAPISpecDiff, useAPISpecVersions } from 'hooks/use-api-changes'
+
+const changeColors = {
+  added: '$green9',
+  removed: '$red9',
+  modified: '$orange9',
+}
+
+const changeSigns = {
+  added: '+',
+  removed: '-',
+  modified: '~',
+}
+
+const versionLabel = (version: SpecVersion) => `#${version.id} - ${new Date(version.createdAt).toLocaleString()}`
+
+const VersionSelect = ({
+  label,
+  versions,
+  value,
+  onChange,
+}: {
+  label: string
+  versions: SpecVersion[]
+  value?: number
+  onChange: (id: number) => void
+}) => (
+  <Flex direction="column" gap={1}>
+    <Text variant="subtle">{label}</Text>
+    <select value={value} onChange={(e) => onChange(Number(e.target.value))}>
+      {versions.map((version) => (
+        <option key={version.id} value={version.id}>
+          {versionLabel(version)}
+        </option>
+      ))}
+    </select>
+  </Flex>
+)
+
+const ChangeLine = ({ change, children }: { change: FieldChange['change']; children: React.ReactNode }) => (
+  <Text css={{ fontFamily: '$mono', color: changeColors[change] }}>
+    {changeSigns[change]} {children}
+  </Text>
+)
+
+const APIChanges = () => {
+  const { apiName, collectionName } = useParams()
+  const { data: versions, isLoading } = useAPISpecVersions(apiName, collectionName)
+  const [from, setFrom] = useState<number>()
+  const [to, setTo] = useState<number>()
+
+  // Show the changes of the latest release by default.
+  useEffect(() => {
+    if (!versions?.length) {
+      return
+    }
+
+    setTo(versions[versions.length - 1].id)
+    setFrom(versions[Math.max(versions.length - 2, 0)].id)
+  }, [versions])
+
+  const { data: diff } = useAPISpecDiff(apiName, collectionName, from, to)
+
+  if (isLoading) {
+    return null
+  }
+
+  return (
+    <Box css={{ p: '$4' }}>
+      <Helmet>
+        <title>{`${apiName} changes`}</title>
+      </Helmet>
+      <H1 css={{ mb: '$4' }}>What changed in {apiName}</H1>
+
+      {!versions || versions.length < 2 ? (
+        <Text>No previous version of this API has been recorded yet.</Text>
+      ) : (
+        <>
+          <Flex gap={4} css={{ mb: '$4' }}>
+            <VersionSelect label="From" versions={versions} value={from} onChange={setFrom} />
+            <VersionSelect label="To" versions={versions} value={to} onChange={setTo} />
+          </Flex>
+
+          {diff && !diff.operations.length && !diff.schemas?.length && (
+            <Text>These versions have no differences.</Text>
+          )}
+
+          {diff?.operations.map((operation) => (
+            <Box key={`${operation.method} ${operation.path}`} css={{ mb: '$3' }}>
+              <ChangeLine change={operation.change}>
+                {operation.method} {operation.path}
+              </ChangeLine>
+              {operation.details?.map((detail) => (
+                <Box key={detail.field} css={{ pl: '$4' }}>
+                  <ChangeLine change={detail.change}>{detail.field}</ChangeLine>
+                </Box>
+              ))}
+            </Box>
+          ))}
+
+          {!!diff?.schemas?.length && (
+            <Box css={{ mt: '$4' }}>
+              <Text css={{ fontWeight: '$semiBold' }}>Schemas</Text>
+              {diff.schemas.map((schema) => (
+                <ChangeLine key={schema.field} change={schema.change}>
+                  {schema.field}
+                </ChangeLine>
+              ))}
+            </Box>
+          )}
+        </>
+      )}
+    </Box>
+  )
+}
+
+export default APIChanges
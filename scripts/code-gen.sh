@@ -18,6 +18,15 @@ docker run --rm \
            -w "/go/src/${PROJECT_MODULE}" \
            "${IMAGE_NAME}" $cmd
 
+# v1alpha2 is not served yet, only its deepcopy functions are needed.
+cmd="/go/src/k8s.io/code-generator/generate-groups.sh deepcopy $PROJECT_MODULE/pkg/crd/generated/client/hub $PROJECT_MODULE/pkg/crd/api hub:v1alpha2"
+
+echo "Generating Hub v1alpha2 deepcopy functions ..."
+docker run --rm \
+           -v "$(pwd):/go/src/${PROJECT_MODULE}" \
+           -w "/go/src/${PROJECT_MODULE}" \
+           "${IMAGE_NAME}" $cmd
+
 cmd="/go/src/k8s.io/code-generator/generate-groups.sh all $PROJECT_MODULE/pkg/crd/generated/client/traefik $PROJECT_MODULE/pkg/crd/api traefik:v1alpha1"
 
 echo "Generating Traefik clientSet code ..."
@@ -27,7 +36,7 @@ docker run --rm \
            "${IMAGE_NAME}" $cmd
 
 
-cmd="controller-gen crd:crdVersions=v1 paths=./pkg/crd/api/hub/v1alpha1/... output:dir=."
+cmd="controller-gen crd:crdVersions=v1 paths=./pkg/crd/api/hub/v1alpha1/... output:dir=./pkg/crd/install/crds"
 
 echo "Generating the CRD definitions ..."
 docker run --rm \